@@ -0,0 +1,116 @@
+package activity
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSourceInterval is how often polling sources sample their signal
+const DefaultSourceInterval = 30 * time.Second
+
+// ContainerSource reports activity while any container is running on the remote daemon
+type ContainerSource struct {
+	count    func(ctx context.Context) (int, error)
+	interval time.Duration
+}
+
+// NewContainerSource creates a source that polls count and records activity when it is positive
+func NewContainerSource(count func(ctx context.Context) (int, error), interval time.Duration) *ContainerSource {
+	if interval <= 0 {
+		interval = DefaultSourceInterval
+	}
+	return &ContainerSource{count: count, interval: interval}
+}
+
+// Name identifies the source in logs
+func (s *ContainerSource) Name() string { return "containers" }
+
+// Run records activity until ctx is cancelled
+func (s *ContainerSource) Run(ctx context.Context, recorder Recorder) {
+	poll(ctx, s.interval, func() {
+		n, err := s.count(ctx)
+		if err == nil && n > 0 {
+			_ = recorder.Record(ActivityTypeContainerRunning)
+		}
+	})
+}
+
+// SessionSource reports activity while interactive sessions (attached TTYs, exec) are open
+type SessionSource struct {
+	active   atomic.Int32
+	interval time.Duration
+}
+
+// NewSessionSource creates a source for interactive sessions
+func NewSessionSource(interval time.Duration) *SessionSource {
+	if interval <= 0 {
+		interval = DefaultSourceInterval
+	}
+	return &SessionSource{interval: interval}
+}
+
+// Name identifies the source in logs
+func (s *SessionSource) Name() string { return "tty_sessions" }
+
+// Begin marks the start of an interactive session
+func (s *SessionSource) Begin() { s.active.Add(1) }
+
+// End marks the end of an interactive session
+func (s *SessionSource) End() { s.active.Add(-1) }
+
+// Active returns the number of open sessions
+func (s *SessionSource) Active() int { return int(s.active.Load()) }
+
+// Run records activity until ctx is cancelled
+func (s *SessionSource) Run(ctx context.Context, recorder Recorder) {
+	poll(ctx, s.interval, func() {
+		if s.Active() > 0 {
+			_ = recorder.Record(ActivityTypeTTYAttached)
+		}
+	})
+}
+
+// TrafficSource reports activity whenever a byte counter increases, e.g. port-forward traffic
+type TrafficSource struct {
+	bytes    func() int64
+	interval time.Duration
+}
+
+// NewTrafficSource creates a source that records activity when bytes() grows between samples
+func NewTrafficSource(bytes func() int64, interval time.Duration) *TrafficSource {
+	if interval <= 0 {
+		interval = DefaultSourceInterval
+	}
+	return &TrafficSource{bytes: bytes, interval: interval}
+}
+
+// Name identifies the source in logs
+func (s *TrafficSource) Name() string { return "port_forward_traffic" }
+
+// Run records activity until ctx is cancelled
+func (s *TrafficSource) Run(ctx context.Context, recorder Recorder) {
+	last := s.bytes()
+	poll(ctx, s.interval, func() {
+		current := s.bytes()
+		if current > last {
+			_ = recorder.Record(ActivityTypePortForward)
+		}
+		last = current
+	})
+}
+
+// poll calls fn every interval until ctx is cancelled
+func poll(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
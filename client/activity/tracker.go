@@ -14,6 +14,12 @@ type ActivityType string
 const (
 	ActivityTypeDockerCommand    ActivityType = "docker_command"
 	ActivityTypeConnectionActive ActivityType = "connection_active"
+	ActivityTypeAPIRequest       ActivityType = "api_request"
+	ActivityTypeContainerRunning ActivityType = "container_running"
+	ActivityTypeTTYAttached      ActivityType = "tty_attached"
+	ActivityTypePortForward      ActivityType = "port_forward"
+	// ActivityTypeSchedule holds a server prewarmed ahead of the working hours
+	ActivityTypeSchedule ActivityType = "schedule"
 )
 
 // ActivityEvent represents an activity event
@@ -25,10 +31,38 @@ type ActivityEvent struct {
 // ActivityCallback is called when activity events occur
 type ActivityCallback func(event ActivityEvent) error
 
+// Recorder is the minimal interface components use to report activity
+type Recorder interface {
+	// Record records activity of the given type; everything except
+	// ActivityTypeConnectionActive resets the idle clock
+	Record(activityType ActivityType) error
+
+	// RecordAPIActivity records Docker API traffic through the daemon
+	RecordAPIActivity() error
+
+	// RecordContainerActivity records activity inside containers (running workloads, forwarded traffic)
+	RecordContainerActivity() error
+
+	// IdleSince returns the time of the most recent activity that resets the idle clock
+	IdleSince() time.Time
+}
+
+// Source produces activity from some signal (API traffic, containers, TTYs, ...)
+type Source interface {
+	// Name identifies the source in logs
+	Name() string
+
+	// Run reports activity to recorder until ctx is cancelled
+	Run(ctx context.Context, recorder Recorder)
+}
+
 // ActivityTracker interface for tracking Docker commands and connections
 type ActivityTracker interface {
+	Recorder
+
 	Start(ctx context.Context) error
 	Stop() error
+	RegisterSource(source Source)
 	RecordDockerCommand() error
 	RecordConnectionActivity() error
 	GetLastActivity() time.Time
@@ -43,15 +77,19 @@ type Tracker struct {
 	mu        sync.RWMutex
 	lastCmd   time.Time
 	lastConn  time.Time
+	lastBy    map[ActivityType]time.Time
 	callbacks []ActivityCallback
+	sources   []Source
 	ctx       context.Context
 	cancel    context.CancelFunc
+	wg        sync.WaitGroup
 }
 
 // NewTracker creates a new activity tracker
 func NewTracker(config *config.ActivityConfig) *Tracker {
 	return &Tracker{
 		config:    config,
+		lastBy:    make(map[ActivityType]time.Time),
 		callbacks: make([]ActivityCallback, 0),
 	}
 }
@@ -61,62 +99,73 @@ func (t *Tracker) Start(ctx context.Context) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	t.ctx, t.cancel = context.WithCancel(ctx)
-
 	// Initialize timestamps
 	now := time.Now()
 	t.lastCmd = now
 	t.lastConn = now
 
+	// Both the daemon and the lifecycle manager start the tracker; sources only run once
+	if t.ctx != nil && t.ctx.Err() == nil {
+		return nil
+	}
+	t.ctx, t.cancel = context.WithCancel(ctx)
+
+	// Start registered sources
+	for _, source := range t.sources {
+		t.startSource(source)
+	}
+
 	return nil
 }
 
 // Stop stops the activity tracker
 func (t *Tracker) Stop() error {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
 	if t.cancel != nil {
 		t.cancel()
 	}
+	t.mu.Unlock()
 
+	// Wait for sources outside the lock; they record through it
+	t.wg.Wait()
 	return nil
 }
 
-// RecordDockerCommand records a Docker command execution
-func (t *Tracker) RecordDockerCommand() error {
+// RegisterSource registers an activity source. Sources registered after Start begin immediately.
+func (t *Tracker) RegisterSource(source Source) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	now := time.Now()
-	t.lastCmd = now
-	t.lastConn = now // Docker commands also count as connection activity
-
-	event := ActivityEvent{
-		Type:      ActivityTypeDockerCommand,
-		Timestamp: now,
-	}
-
-	// Notify callbacks
-	for _, callback := range t.callbacks {
-		go func(cb ActivityCallback) {
-			_ = cb(event)
-		}(callback)
+	t.sources = append(t.sources, source)
+	if t.ctx != nil && t.ctx.Err() == nil {
+		t.startSource(source)
 	}
+}
 
-	return nil
+// startSource runs a source in the background (must be called with lock held)
+func (t *Tracker) startSource(source Source) {
+	ctx := t.ctx
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		source.Run(ctx, t)
+	}()
 }
 
-// RecordConnectionActivity records connection activity
-func (t *Tracker) RecordConnectionActivity() error {
+// Record records activity of the given type
+func (t *Tracker) Record(activityType ActivityType) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	now := time.Now()
 	t.lastConn = now
+	if activityType != ActivityTypeConnectionActive {
+		t.lastCmd = now // real activity also counts as connection activity
+	}
+	t.lastBy[activityType] = now
 
 	event := ActivityEvent{
-		Type:      ActivityTypeConnectionActive,
+		Type:      activityType,
 		Timestamp: now,
 	}
 
@@ -130,6 +179,45 @@ func (t *Tracker) RecordConnectionActivity() error {
 	return nil
 }
 
+// RecordDockerCommand records a Docker command execution
+func (t *Tracker) RecordDockerCommand() error {
+	return t.Record(ActivityTypeDockerCommand)
+}
+
+// RecordConnectionActivity records connection activity
+func (t *Tracker) RecordConnectionActivity() error {
+	return t.Record(ActivityTypeConnectionActive)
+}
+
+// RecordAPIActivity records Docker API traffic through the daemon
+func (t *Tracker) RecordAPIActivity() error {
+	return t.Record(ActivityTypeAPIRequest)
+}
+
+// RecordContainerActivity records activity inside containers
+func (t *Tracker) RecordContainerActivity() error {
+	return t.Record(ActivityTypeContainerRunning)
+}
+
+// IdleSince returns the time of the most recent activity that resets the idle clock
+func (t *Tracker) IdleSince() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastCmd
+}
+
+// LastActivityBySource returns when each activity type was last recorded
+func (t *Tracker) LastActivityBySource() map[ActivityType]time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[ActivityType]time.Time, len(t.lastBy))
+	for activityType, ts := range t.lastBy {
+		result[activityType] = ts
+	}
+	return result
+}
+
 // GetLastActivity returns the timestamp of the last Docker command
 func (t *Tracker) GetLastActivity() time.Time {
 	t.mu.RLock()
@@ -144,6 +232,18 @@ func (t *Tracker) GetLastConnection() time.Time {
 	return t.lastConn
 }
 
+// SetTimeouts changes the idle and connection timeouts of the tracker
+func (t *Tracker) SetTimeouts(idle, connection time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The configuration may be shared with other trackers
+	updated := *t.config
+	updated.IdleTimeout = idle
+	updated.ConnectionTimeout = connection
+	t.config = &updated
+}
+
 // GetTimeUntilShutdown calculates time until shutdown and the reason
 func (t *Tracker) GetTimeUntilShutdown() (time.Duration, string) {
 	t.mu.RLock()
@@ -181,3 +281,6 @@ func (t *Tracker) RegisterCallback(callback ActivityCallback) {
 	defer t.mu.Unlock()
 	t.callbacks = append(t.callbacks, callback)
 }
+
+// Ensure Tracker implements ActivityTracker
+var _ ActivityTracker = (*Tracker)(nil)
@@ -0,0 +1,80 @@
+package activity
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker() *Tracker {
+	return NewTracker(&config.ActivityConfig{
+		IdleTimeout:       5 * time.Minute,
+		ConnectionTimeout: 30 * time.Minute,
+		GracePeriod:       30 * time.Second,
+	})
+}
+
+func TestTracker_RecordResetsIdleClock(t *testing.T) {
+	tracker := newTestTracker()
+	require.NoError(t, tracker.Start(context.Background()))
+	defer tracker.Stop()
+
+	start := tracker.IdleSince()
+	time.Sleep(5 * time.Millisecond)
+
+	// Connection activity alone does not reset the idle clock
+	require.NoError(t, tracker.RecordConnectionActivity())
+	assert.Equal(t, start, tracker.IdleSince())
+
+	require.NoError(t, tracker.RecordAPIActivity())
+	assert.True(t, tracker.IdleSince().After(start))
+
+	require.NoError(t, tracker.RecordContainerActivity())
+	bySource := tracker.LastActivityBySource()
+	assert.Contains(t, bySource, ActivityTypeAPIRequest)
+	assert.Contains(t, bySource, ActivityTypeContainerRunning)
+	assert.Contains(t, bySource, ActivityTypeConnectionActive)
+}
+
+func TestTracker_Sources(t *testing.T) {
+	tracker := newTestTracker()
+
+	var running atomic.Int32
+	tracker.RegisterSource(NewContainerSource(func(ctx context.Context) (int, error) {
+		return int(running.Load()), nil
+	}, 5*time.Millisecond))
+
+	sessions := NewSessionSource(5 * time.Millisecond)
+	tracker.RegisterSource(sessions)
+
+	var forwarded atomic.Int64
+	tracker.RegisterSource(NewTrafficSource(forwarded.Load, 5*time.Millisecond))
+
+	require.NoError(t, tracker.Start(context.Background()))
+	// A second Start (as done by the lifecycle manager) must not duplicate sources
+	require.NoError(t, tracker.Start(context.Background()))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, tracker.LastActivityBySource(), "no activity expected while idle")
+
+	running.Store(1)
+	sessions.Begin()
+	forwarded.Add(1024)
+
+	assert.Eventually(t, func() bool {
+		bySource := tracker.LastActivityBySource()
+		_, containers := bySource[ActivityTypeContainerRunning]
+		_, tty := bySource[ActivityTypeTTYAttached]
+		_, traffic := bySource[ActivityTypePortForward]
+		return containers && tty && traffic
+	}, time.Second, 5*time.Millisecond)
+
+	sessions.End()
+	assert.Equal(t, 0, sessions.Active())
+	require.NoError(t, tracker.Stop())
+}
@@ -0,0 +1,204 @@
+// Package backup schedules restic backups of Docker volumes on the server to
+// S3-compatible or Backblaze B2 storage, and lists and restores them from the client.
+// A single provider volume holding all Docker state is a single point of failure;
+// backups outlive both the server and the volume.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+const (
+	// ScriptPath is the backup agent installed on the server
+	ScriptPath = "/usr/local/bin/dockbridge-backup"
+
+	// EnvPath holds the repository and its credentials, readable by root only
+	EnvPath = "/etc/dockbridge/backup.env"
+
+	// unit names the systemd service and timer running scheduled backups
+	unit = "dockbridge-backup"
+
+	// firstBackupDelay is how long after boot the first scheduled backup runs
+	firstBackupDelay = 15 * time.Minute
+)
+
+// Host is the restic host name of a context's backups. Servers are replaced all the
+// time, so snapshots are grouped by context rather than by server name.
+func Host(contextName string) string {
+	if contextName == "" {
+		return "dockbridge"
+	}
+	return "dockbridge-" + contextName
+}
+
+// Snapshot is a backup listed by restic
+type Snapshot struct {
+	ID       string    `json:"short_id"`
+	Time     time.Time `json:"time"`
+	Paths    []string  `json:"paths"`
+	Hostname string    `json:"hostname"`
+	Tags     []string  `json:"tags"`
+}
+
+// ParseSnapshots parses the output of ListCommand
+func ParseSnapshots(output []byte) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	if err := json.Unmarshal(output, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// BackupCommand runs a backup right away, followed by the retention policy
+func BackupCommand() string {
+	return ScriptPath + " backup"
+}
+
+// ListCommand prints the context's snapshots as JSON
+func ListCommand() string {
+	return ScriptPath + " snapshots"
+}
+
+// RestoreCommand restores snapshot in place, limited to paths when given. Docker is
+// stopped meanwhile so no container writes to the restored volumes.
+func RestoreCommand(snapshot string, paths []string) string {
+	args := []string{ScriptPath, "restore", shellQuote(snapshot)}
+	for _, path := range paths {
+		args = append(args, shellQuote(path))
+	}
+	return strings.Join(args, " ")
+}
+
+// SetupScript returns a shell snippet for server provisioning that installs restic,
+// the backup agent and a systemd timer running it every cfg.Interval. host is the
+// restic host name of the context, see Host. It returns an empty string when backups
+// are disabled.
+func SetupScript(cfg *config.BackupConfig, host string) string {
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+
+	paths := make([]string, len(cfg.Paths))
+	for i, path := range cfg.Paths {
+		paths[i] = shellQuote(path)
+	}
+
+	return `# Configure scheduled volume backups (managed by DockBridge)
+echo "$(date): Configuring volume backups"
+DEBIAN_FRONTEND=noninteractive apt-get install -y restic
+mkdir -p /etc/dockbridge
+install -m 600 /dev/null ` + EnvPath + `
+cat > ` + EnvPath + ` << 'BACKUPEOF'
+` + environment(cfg) + `BACKUPEOF
+cat > ` + ScriptPath + ` << 'BACKUPEOF'
+#!/bin/bash
+set -euo pipefail
+set -a
+. ` + EnvPath + `
+set +a
+HOST=` + shellQuote(host) + `
+
+case "${1:-backup}" in
+backup)
+    restic cat config >/dev/null 2>&1 || restic init
+    restic backup --host "$HOST" --tag dockbridge ` + strings.Join(paths, " ") + `
+    restic forget --host "$HOST" --prune` + retention(cfg) + `
+    ;;
+snapshots)
+    restic snapshots --host "$HOST" --json
+    ;;
+restore)
+    snapshot="$2"
+    shift 2
+    includes=()
+    for path in "$@"; do
+        includes+=(--include "$path")
+    done
+    systemctl stop docker.socket docker
+    status=0
+    restic restore "$snapshot" --host "$HOST" --target / "${includes[@]}" || status=$?
+    systemctl start docker
+    exit $status
+    ;;
+*)
+    echo "usage: $0 [backup|snapshots|restore <snapshot> [path...]]" >&2
+    exit 2
+    ;;
+esac
+BACKUPEOF
+chmod 700 ` + ScriptPath + `
+cat > /etc/systemd/system/` + unit + `.service << 'BACKUPEOF'
+[Unit]
+Description=DockBridge volume backup
+After=docker.service network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=` + ScriptPath + ` backup
+BACKUPEOF
+cat > /etc/systemd/system/` + unit + `.timer << 'BACKUPEOF'
+[Unit]
+Description=Scheduled DockBridge volume backup
+
+[Timer]
+OnBootSec=` + systemdSeconds(firstBackupDelay) + `
+OnUnitActiveSec=` + systemdSeconds(cfg.Interval) + `
+
+[Install]
+WantedBy=timers.target
+BACKUPEOF
+systemctl daemon-reload
+systemctl enable --now ` + unit + `.timer
+`
+}
+
+// environment returns the restic environment for the repository in cfg
+func environment(cfg *config.BackupConfig) string {
+	var env strings.Builder
+	fmt.Fprintf(&env, "RESTIC_REPOSITORY=%s\n", shellQuote(cfg.Repository))
+	fmt.Fprintf(&env, "RESTIC_PASSWORD=%s\n", shellQuote(cfg.Password))
+	if cfg.AccessKeyID != "" {
+		if strings.HasPrefix(cfg.Repository, "b2:") {
+			fmt.Fprintf(&env, "B2_ACCOUNT_ID=%s\n", shellQuote(cfg.AccessKeyID))
+			fmt.Fprintf(&env, "B2_ACCOUNT_KEY=%s\n", shellQuote(cfg.SecretAccessKey))
+		} else {
+			fmt.Fprintf(&env, "AWS_ACCESS_KEY_ID=%s\n", shellQuote(cfg.AccessKeyID))
+			fmt.Fprintf(&env, "AWS_SECRET_ACCESS_KEY=%s\n", shellQuote(cfg.SecretAccessKey))
+		}
+	}
+	return env.String()
+}
+
+// retention returns the restic forget flags of the retention policy
+func retention(cfg *config.BackupConfig) string {
+	var flags string
+	if cfg.KeepDaily > 0 {
+		flags += fmt.Sprintf(" --keep-daily %d", cfg.KeepDaily)
+	}
+	if cfg.KeepWeekly > 0 {
+		flags += fmt.Sprintf(" --keep-weekly %d", cfg.KeepWeekly)
+	}
+	if cfg.KeepMonthly > 0 {
+		flags += fmt.Sprintf(" --keep-monthly %d", cfg.KeepMonthly)
+	}
+	if flags == "" {
+		// restic refuses to forget without a policy; keep everything
+		flags = " --keep-last 1000000"
+	}
+	return flags
+}
+
+// systemdSeconds formats d as a systemd time span
+func systemdSeconds(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Seconds()))
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() *config.BackupConfig {
+	return &config.BackupConfig{
+		Enabled:         true,
+		Repository:      "s3:s3.amazonaws.com/bucket/dockbridge",
+		Password:        "secret",
+		AccessKeyID:     "AKIA",
+		SecretAccessKey: "key",
+		Paths:           []string{"/var/lib/docker/volumes"},
+		Interval:        6 * time.Hour,
+		KeepDaily:       7,
+		KeepWeekly:      4,
+	}
+}
+
+func TestSetupScript(t *testing.T) {
+	assert.Empty(t, SetupScript(nil, "dockbridge"))
+	assert.Empty(t, SetupScript(&config.BackupConfig{}, "dockbridge"))
+
+	script := SetupScript(testConfig(), Host("work"))
+	assert.Contains(t, script, "apt-get install -y restic")
+	assert.Contains(t, script, "install -m 600 /dev/null "+EnvPath)
+	assert.Contains(t, script, "RESTIC_REPOSITORY='s3:s3.amazonaws.com/bucket/dockbridge'")
+	assert.Contains(t, script, "AWS_ACCESS_KEY_ID='AKIA'")
+	assert.NotContains(t, script, "B2_ACCOUNT_ID")
+	assert.Contains(t, script, "HOST='dockbridge-work'")
+	assert.Contains(t, script, "--tag dockbridge '/var/lib/docker/volumes'")
+	assert.Contains(t, script, "forget --host \"$HOST\" --prune --keep-daily 7 --keep-weekly 4\n")
+	assert.Contains(t, script, "OnUnitActiveSec=21600s")
+	assert.Contains(t, script, "systemctl enable --now dockbridge-backup.timer")
+}
+
+func TestSetupScriptB2(t *testing.T) {
+	cfg := testConfig()
+	cfg.Repository = "b2:bucket:dockbridge"
+	cfg.KeepDaily, cfg.KeepWeekly = 0, 0
+
+	script := SetupScript(cfg, Host(""))
+	assert.Contains(t, script, "B2_ACCOUNT_ID='AKIA'")
+	assert.Contains(t, script, "B2_ACCOUNT_KEY='key'")
+	assert.NotContains(t, script, "AWS_ACCESS_KEY_ID")
+	assert.Contains(t, script, "HOST='dockbridge'")
+	assert.Contains(t, script, "--prune --keep-last 1000000")
+}
+
+func TestParseSnapshots(t *testing.T) {
+	output := `[{"time":"2026-01-02T03:04:05.123456789Z","paths":["/var/lib/docker/volumes"],"hostname":"dockbridge","tags":["dockbridge"],"id":"4a8b2c9d1e","short_id":"4a8b2c9d"}]`
+
+	snapshots, err := ParseSnapshots([]byte(output))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "4a8b2c9d", snapshots[0].ID)
+	assert.Equal(t, []string{"/var/lib/docker/volumes"}, snapshots[0].Paths)
+	assert.Equal(t, 2026, snapshots[0].Time.Year())
+
+	_, err = ParseSnapshots([]byte("Fatal: unable to open config file"))
+	assert.Error(t, err)
+}
+
+func TestRestoreCommand(t *testing.T) {
+	assert.Equal(t, ScriptPath+" restore 'latest'", RestoreCommand("latest", nil))
+
+	cmd := RestoreCommand("4a8b2c9d", []string{"/var/lib/docker/volumes/db", "/it's"})
+	assert.True(t, strings.HasPrefix(cmd, ScriptPath+" restore '4a8b2c9d' "))
+	assert.Contains(t, cmd, `'/it'\''s'`)
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/secrets"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Store provider API tokens outside the configuration file",
+	Long: `Store the API token of the configured provider in the OS keychain (macOS), the
+Secret Service (Linux) or a passphrase-encrypted file, selected by secrets.backend.
+DockBridge reads it from there whenever neither the configuration file nor the
+environment sets a token. With --profile, the token is stored for that profile.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store the provider API token",
+	Long: `Store the API token of the configured provider. The token is read from the
+terminal without echo, or from standard input when it is not a terminal:
+  echo "$TOKEN" | dockbridge auth login`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		manager, key, err := loadAuthConfig(configPath)
+		if err != nil {
+			return err
+		}
+
+		token, err := readSecret(os.Stdin, cmd.ErrOrStderr(), fmt.Sprintf("%s API token: ", manager.GetConfig().Provider))
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return errors.New("no API token given")
+		}
+
+		store, err := manager.SecretStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Set(key, token); err != nil {
+			return fmt.Errorf("failed to store the API token: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "API token stored in the %s secret store\n", store.Name())
+		if manager.GetConfig().ServerSettings().APIToken != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), "Note: the token set in the configuration file or environment still takes precedence")
+		}
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored provider API token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		manager, key, err := loadAuthConfig(configPath)
+		if err != nil {
+			return err
+		}
+		store, err := manager.SecretStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(key); err != nil {
+			return fmt.Errorf("failed to remove the API token: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "API token removed from the %s secret store\n", store.Name())
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the provider API token comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		manager, key, err := loadAuthConfig(configPath)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+
+		if manager.GetConfig().ServerSettings().APIToken != "" {
+			fmt.Fprintln(out, "API token: set in the configuration file or environment")
+			return nil
+		}
+		store, err := manager.SecretStore()
+		if err != nil {
+			return err
+		}
+		switch _, err := store.Get(key); {
+		case errors.Is(err, secrets.ErrNotFound):
+			fmt.Fprintf(out, "API token: not set; run dockbridge auth login (secret store: %s)\n", store.Name())
+		case err != nil:
+			return fmt.Errorf("failed to read the API token: %w", err)
+		default:
+			fmt.Fprintf(out, "API token: stored in the %s secret store as %s\n", store.Name(), key)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authStatusCmd)
+}
+
+// loadAuthConfig loads the configuration without validation, since the token may be
+// missing, and returns the secret key of the provider's token
+func loadAuthConfig(configPath string) (*clientconfig.Manager, string, error) {
+	manager := clientconfig.NewManager()
+	if err := manager.LoadWithoutValidation(configPath); err != nil {
+		return nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := manager.ApplyProfile(); err != nil {
+		return nil, "", err
+	}
+	manager.SetSecretPassphrase(func() (string, error) {
+		if passphrase := os.Getenv(secrets.PassphraseEnv); passphrase != "" {
+			return passphrase, nil
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", fmt.Errorf("set %s to unlock the encrypted secrets file", secrets.PassphraseEnv)
+		}
+		return readSecret(os.Stdin, os.Stderr, "Secrets file passphrase: ")
+	})
+	return manager, manager.APITokenKey(), nil
+}
+
+// readSecret reads a line from the terminal without echo, or from in when it is not a
+// terminal
+func readSecret(in *os.File, prompt io.Writer, message string) (string, error) {
+	if term.IsTerminal(int(in.Fd())) {
+		fmt.Fprint(prompt, message)
+		secret, err := term.ReadPassword(int(in.Fd()))
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
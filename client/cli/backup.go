@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/backup"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore Docker volumes",
+	Long: `Back up and restore the Docker volumes of a context's server with restic.
+Backups go to the S3-compatible or B2 repository configured under "backup" and run
+on the server every backup.interval once enabled; servers provisioned before
+backups were enabled have no backup agent.`,
+}
+
+var backupNowCmd = &cobra.Command{
+	Use:   "now",
+	Short: "Run a backup right away",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackup(cmd, func(ctx context.Context, client ssh.Client, out io.Writer) error {
+			fmt.Fprintln(out, "Backing up volumes...")
+			output, err := client.ExecuteCommand(ctx, backup.BackupCommand())
+			out.Write(output)
+			if err != nil {
+				return fmt.Errorf("backup failed: %w", err)
+			}
+			fmt.Fprintln(out, "Backup completed.")
+			return nil
+		})
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackup(cmd, func(ctx context.Context, client ssh.Client, out io.Writer) error {
+			output, err := client.ExecuteCommand(ctx, backup.ListCommand())
+			if err != nil {
+				return fmt.Errorf("failed to list backups: %w: %s", err, strings.TrimSpace(string(output)))
+			}
+			snapshots, err := backup.ParseSnapshots(output)
+			if err != nil {
+				return err
+			}
+			printSnapshots(out, snapshots)
+			return nil
+		})
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Short: "Restore a backup in place",
+	Long: `Restore a backup onto the server, overwriting the backed up paths, or only the
+paths given with --path. Docker is stopped while restoring, so running containers
+are interrupted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, _ := cmd.Flags().GetStringSlice("path")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if !yes {
+			fmt.Fprintf(cmd.OutOrStdout(), "Restoring %s stops Docker and overwrites the restored files. Continue? (y/N): ", args[0])
+			answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if answer = strings.TrimSpace(answer); answer != "y" && answer != "Y" {
+				fmt.Fprintln(cmd.OutOrStdout(), "Restore cancelled.")
+				return nil
+			}
+		}
+
+		return runBackup(cmd, func(ctx context.Context, client ssh.Client, out io.Writer) error {
+			output, err := client.ExecuteCommand(ctx, backup.RestoreCommand(args[0], paths))
+			out.Write(output)
+			if err != nil {
+				return fmt.Errorf("restore failed: %w", err)
+			}
+			fmt.Fprintf(out, "Restored %s.\n", args[0])
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	// Add subcommands
+	backupCmd.AddCommand(backupNowCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	// Add flags
+	backupCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	backupCmd.PersistentFlags().String("context", "", "Context whose server to use (default: the current context)")
+	backupCmd.PersistentFlags().Duration("timeout", time.Hour, "Maximum duration of the operation")
+	backupRestoreCmd.Flags().StringSlice("path", nil, "Restore only this path (repeatable)")
+	backupRestoreCmd.Flags().BoolP("yes", "y", false, "Restore without confirmation")
+}
+
+// runBackup connects to the running server of the selected context and runs fn
+func runBackup(cmd *cobra.Command, fn func(ctx context.Context, client ssh.Client, out io.Writer) error) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	contextName, _ := cmd.Flags().GetString("context")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+	if !cfg.Backup.Enabled {
+		return fmt.Errorf("backups are not enabled; set backup.enabled and backup.repository in the configuration")
+	}
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	srv, err := backupServer(ctx, cloudProvider, contextName)
+	if err != nil {
+		return err
+	}
+
+	client := newServerSSHClient(&cfg.SSH, srv.IPAddress)
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to server %s: %w", srv.Name, err)
+	}
+	defer client.Close()
+
+	return fn(ctx, client, cmd.OutOrStdout())
+}
+
+// backupServer returns the running server of a context; backups run on the server,
+// so one must be up
+func backupServer(ctx context.Context, cloudProvider provider.CloudProvider, contextName string) (*provider.Server, error) {
+	srv, err := runningServer(ctx, cloudProvider, contextName)
+	if err != nil {
+		return nil, err
+	}
+	if srv == nil {
+		return nil, fmt.Errorf("no running server; run a Docker command to start one first")
+	}
+	return srv, nil
+}
+
+// runningServer returns the running server of a context, or nil when none runs
+func runningServer(ctx context.Context, cloudProvider provider.CloudProvider, contextName string) (*provider.Server, error) {
+	servers, err := provider.NewServerRegistry(cloudProvider).ForContext(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	for _, srv := range servers {
+		if srv.Status != provider.StatusOff && srv.IPAddress != "" {
+			return srv, nil
+		}
+	}
+	return nil, nil
+}
+
+// newServerSSHClient creates a root SSH client for the server at host
+func newServerSSHClient(sshCfg *sharedconfig.SSHConfig, host string) ssh.Client {
+	return ssh.NewClient(&ssh.ClientConfig{
+		Host:            host,
+		Port:            sshCfg.Port,
+		User:            "root",
+		PrivateKeyPath:  expandHomePath(sshCfg.KeyPath),
+		Timeout:         10 * time.Second,
+		UseAgent:        sshCfg.UseAgent,
+		AgentSocket:     sshCfg.AgentSocket,
+		KnownHostsPath:  expandHomePath(sshCfg.KnownHostsPath),
+		HostKeyChecking: sshCfg.HostKeyChecking,
+		JumpHost:        sshCfg.JumpHost,
+	})
+}
+
+// printSnapshots prints backups newest first
+func printSnapshots(out io.Writer, snapshots []backup.Snapshot) {
+	if len(snapshots) == 0 {
+		fmt.Fprintln(out, "No backups yet.")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tPATHS")
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", snapshot.ID, snapshot.Time.Local().Format("2006-01-02 15:04"), strings.Join(snapshot.Paths, ", "))
+	}
+	w.Flush()
+}
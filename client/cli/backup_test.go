@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/backup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintSnapshots(t *testing.T) {
+	var out bytes.Buffer
+	printSnapshots(&out, nil)
+	assert.Equal(t, "No backups yet.\n", out.String())
+
+	out.Reset()
+	printSnapshots(&out, []backup.Snapshot{
+		{ID: "older", Time: time.Now().Add(-time.Hour), Paths: []string{"/a"}},
+		{ID: "newer", Time: time.Now(), Paths: []string{"/a", "/b"}},
+	})
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 3) {
+		assert.Contains(t, string(lines[0]), "ID")
+		assert.Contains(t, string(lines[1]), "newer")
+		assert.Contains(t, string(lines[1]), "/a, /b")
+		assert.Contains(t, string(lines[2]), "older")
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/dockercontext"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/spf13/cobra"
+)
+
+var buildxCmd = &cobra.Command{
+	Use:   "buildx",
+	Short: "Build images on the remote server with buildx",
+	Long: `Manage the buildx builder that runs BuildKit on the DockBridge server, so builds
+use the server's CPU and bandwidth and their cache persists on the Docker data volume.`,
+}
+
+var buildxSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Create a buildx builder on the remote server",
+	Long: `Create a buildx builder running BuildKit in a container on the DockBridge server
+of a context, reached through the context's local socket, and start it. An existing
+builder of the same name is recreated, keeping its build cache.
+
+The cache lives in the builder's state volume on the server's Docker data volume, so
+it survives server replacement. The DockBridge daemon must be running; starting the
+builder provisions a server if none is running.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		name, _ := cmd.Flags().GetString("name")
+		use, _ := cmd.Flags().GetBool("use")
+		return runBuildxSetup(cmd.Context(), configPath, contextName, name, use, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildxCmd)
+	buildxCmd.AddCommand(buildxSetupCmd)
+
+	buildxCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	buildxCmd.PersistentFlags().String("context", "", "Context whose server to build on (default: the current context)")
+	buildxSetupCmd.Flags().String("name", "", "Builder name (default: the context's Docker CLI context name)")
+	buildxSetupCmd.Flags().Bool("use", true, "Make the builder the default for docker build")
+}
+
+// runDocker runs the local Docker CLI with args, writing its output to out; replaced in
+// tests
+var runDocker = func(ctx context.Context, out io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// runBuildxSetup creates the buildx builder of a context
+func runBuildxSetup(ctx context.Context, configPath, contextName, name string, use bool, out io.Writer) error {
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	if contextName == "" {
+		contextName = cfg.CurrentContext
+	}
+	if contextName == "" {
+		contextName = clientconfig.DefaultContextName
+	}
+	socketPath, err := contextSocketPath(cfg, contextName)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = buildxBuilderName(contextName)
+	}
+
+	return setupBuildxBuilder(ctx, name, localsocket.DockerHost(expandHomePath(socketPath)), use, out)
+}
+
+// buildxBuilderName returns the default builder name of a context, which matches its
+// Docker CLI context
+func buildxBuilderName(contextName string) string {
+	if contextName == clientconfig.DefaultContextName {
+		return dockercontext.DefaultName
+	}
+	return dockerContextName(contextName)
+}
+
+// setupBuildxBuilder (re)creates a docker-container builder on endpoint and starts it.
+// The state of an existing builder is kept, as it holds the build cache.
+func setupBuildxBuilder(ctx context.Context, name, endpoint string, use bool, out io.Writer) error {
+	if err := runDocker(ctx, io.Discard, "buildx", "version"); err != nil {
+		return fmt.Errorf("docker buildx is not available locally; install the Docker buildx plugin: %w", err)
+	}
+
+	if err := runDocker(ctx, io.Discard, "buildx", "inspect", name); err == nil {
+		fmt.Fprintf(out, "Recreating builder %s, keeping its build cache\n", name)
+		if err := runDocker(ctx, out, "buildx", "rm", "--keep-state", name); err != nil {
+			return fmt.Errorf("failed to remove builder %s: %w", name, err)
+		}
+	}
+
+	args := []string{"buildx", "create", "--name", name, "--driver", "docker-container", "--bootstrap"}
+	if use {
+		args = append(args, "--use")
+	}
+	args = append(args, endpoint)
+	if err := runDocker(ctx, out, args...); err != nil {
+		return fmt.Errorf("failed to create builder %s: %w", name, err)
+	}
+
+	fmt.Fprintf(out, "Builder %s runs BuildKit on the DockBridge server at %s\n", name, endpoint)
+	if use {
+		fmt.Fprintln(out, "docker build and docker buildx build now use it by default.")
+	} else {
+		fmt.Fprintf(out, "Build with: docker buildx build --builder %s .\n", name)
+	}
+	return nil
+}
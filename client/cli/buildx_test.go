@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDocker replaces the Docker CLI, recording its invocations; fail names the
+// subcommands that exit with an error
+func stubDocker(t *testing.T, fail ...string) *[]string {
+	t.Helper()
+	original := runDocker
+	t.Cleanup(func() { runDocker = original })
+
+	var calls []string
+	runDocker = func(ctx context.Context, out io.Writer, args ...string) error {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		for _, prefix := range fail {
+			if strings.HasPrefix(call, prefix) {
+				return errors.New("exit status 1")
+			}
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestSetupBuildxBuilder(t *testing.T) {
+	calls := stubDocker(t, "buildx inspect")
+
+	var out bytes.Buffer
+	require.NoError(t, setupBuildxBuilder(context.Background(), "dockbridge", "unix:///tmp/dockbridge.sock", true, &out))
+	assert.Equal(t, []string{
+		"buildx version",
+		"buildx inspect dockbridge",
+		"buildx create --name dockbridge --driver docker-container --bootstrap --use unix:///tmp/dockbridge.sock",
+	}, *calls)
+	assert.Contains(t, out.String(), "now use it by default")
+}
+
+func TestSetupBuildxBuilderKeepsCache(t *testing.T) {
+	calls := stubDocker(t)
+
+	var out bytes.Buffer
+	require.NoError(t, setupBuildxBuilder(context.Background(), "dockbridge-gpu", "unix:///tmp/gpu.sock", false, &out))
+	assert.Equal(t, []string{
+		"buildx version",
+		"buildx inspect dockbridge-gpu",
+		"buildx rm --keep-state dockbridge-gpu",
+		"buildx create --name dockbridge-gpu --driver docker-container --bootstrap unix:///tmp/gpu.sock",
+	}, *calls)
+	assert.Contains(t, out.String(), "--builder dockbridge-gpu")
+}
+
+func TestSetupBuildxBuilderWithoutBuildx(t *testing.T) {
+	calls := stubDocker(t, "buildx version")
+
+	err := setupBuildxBuilder(context.Background(), "dockbridge", "unix:///tmp/dockbridge.sock", true, io.Discard)
+	assert.ErrorContains(t, err, "buildx is not available locally")
+	assert.Len(t, *calls, 1)
+}
+
+func TestBuildxBuilderName(t *testing.T) {
+	assert.Equal(t, "dockbridge", buildxBuilderName("default"))
+	assert.Equal(t, "dockbridge-gpu", buildxBuilderName("gpu"))
+}
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/config"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
 	"github.com/spf13/cobra"
 )
 
@@ -26,15 +30,54 @@ var configViewCmd = &cobra.Command{
 }
 
 var configValidateCmd = &cobra.Command{
-	Use:   "validate",
+	Use:   "validate [file]",
 	Short: "Validate configuration",
-	Long:  `Validate the DockBridge configuration for errors.`,
+	Long: `Validate the DockBridge configuration for errors, reporting all of them at once.
+The file defaults to the one given with --config or found in the standard locations.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, _ := cmd.Flags().GetString("config")
+		if len(args) == 1 {
+			configPath = args[0]
+		}
 		return validateConfig(configPath)
 	},
 }
 
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print the effective configuration and where each value comes from",
+	Long: `Print every setting of the effective configuration, with defaults, the
+configuration file, environment variables, the selected profile, the secret store and
+flags merged, along with the source of each value. Secrets are redacted.
+
+The server shape flags of "dockbridge up" show the settings a run with them uses:
+  dockbridge config print --location hel1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		var override sharedconfig.ProfileConfig
+		override.ServerType, _ = cmd.Flags().GetString("server-type")
+		override.Location, _ = cmd.Flags().GetString("location")
+		override.VolumeSize, _ = cmd.Flags().GetInt("volume-size")
+		return printEffectiveConfig(configPath, override, cmd.OutOrStdout())
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Print a JSON Schema of the client configuration file, for completion and
+validation in editors, e.g. with the YAML language server:
+  dockbridge config schema > ~/.dockbridge/client.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(config.Schema())
+	},
+}
+
 var configSetCmd = &cobra.Command{
 	Use:   "set [key] [value]",
 	Short: "Set configuration value",
@@ -53,11 +96,17 @@ func init() {
 	configCmd.AddCommand(configViewCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configSchemaCmd)
 
 	// Add flags
 	configViewCmd.Flags().StringP("config", "c", "", "Path to configuration file")
 	configValidateCmd.Flags().StringP("config", "c", "", "Path to configuration file")
 	configSetCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	configPrintCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	configPrintCmd.Flags().String("server-type", "", "Server type flag of dockbridge up to apply")
+	configPrintCmd.Flags().String("location", "", "Server location flag of dockbridge up to apply")
+	configPrintCmd.Flags().Int("volume-size", 0, "Volume size flag of dockbridge up to apply")
 }
 
 func viewConfig(configPath string) error {
@@ -125,6 +174,51 @@ func validateConfig(configPath string) error {
 	return nil
 }
 
+// printEffectiveConfig prints the effective settings with their sources. An invalid
+// configuration is printed as well, followed by its validation errors.
+func printEffectiveConfig(configPath string, override sharedconfig.ProfileConfig, out io.Writer) error {
+	manager := config.NewManager()
+	loadErr := manager.Load(configPath)
+	if loadErr != nil {
+		manager = config.NewManager()
+		if err := manager.LoadWithoutValidation(configPath); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if err := manager.ApplyProfile(); err != nil {
+			return err
+		}
+		if err := manager.ResolveAPIToken(); err != nil {
+			return err
+		}
+	}
+	if err := manager.ApplyOverride(override); err != nil {
+		return err
+	}
+
+	if path := manager.ConfigFileUsed(); path != "" {
+		fmt.Fprintf(out, "# Configuration file: %s\n", path)
+	} else {
+		fmt.Fprintln(out, "# No configuration file found")
+	}
+	if profile := manager.ActiveProfile(); profile != "" {
+		fmt.Fprintf(out, "# Profile: %s\n", profile)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range manager.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if loadErr != nil {
+		return fmt.Errorf("the configuration is invalid: %w", loadErr)
+	}
+	return nil
+}
+
 // parseDuration parses a duration string
 func parseDuration(value string) time.Duration {
 	duration, err := time.ParseDuration(value)
@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/dockercontext"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage named server contexts",
+	Long: `Manage named contexts (e.g. dev, gpu, ci). Each context runs its own remote
+server with its own shape and is exposed on its own local Docker socket, next to
+the default daemon. Running "dockbridge start" serves all contexts.`,
+}
+
+var contextCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a context",
+	Long:  `Add a context to the configuration. Unset server settings fall back to the provider section.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		socketPath, _ := cmd.Flags().GetString("socket-path")
+		serverType, _ := cmd.Flags().GetString("server-type")
+		location, _ := cmd.Flags().GetString("location")
+		volumeSize, _ := cmd.Flags().GetInt("volume-size")
+
+		contextCfg := sharedconfig.ContextConfig{
+			Name:       args[0],
+			SocketPath: socketPath,
+			ServerType: serverType,
+			Location:   location,
+			VolumeSize: volumeSize,
+		}
+		return createContext(configPath, contextCfg, cmd.OutOrStdout())
+	},
+}
+
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List contexts",
+	Long:    `List the default daemon and all contexts. With --servers the servers of each context are looked up.`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		servers, _ := cmd.Flags().GetBool("servers")
+		return listContexts(cmd.Context(), configPath, servers, cmd.OutOrStdout())
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Select the current context",
+	Long:  `Record the current context and print the DOCKER_HOST that points the Docker CLI at it. Use "default" for the default daemon.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		return useContext(configPath, args[0], cmd.OutOrStdout())
+	},
+}
+
+var contextRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a context",
+	Long: `Remove a context from the configuration. With --destroy its servers are destroyed as well.
+
+Only servers created by this client are destroyed; with --force-foreign servers of the
+context created by other clients (or by DockBridge versions without labels) are too.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		destroy, _ := cmd.Flags().GetBool("destroy")
+		forceForeign, _ := cmd.Flags().GetBool("force-foreign")
+		return removeContext(cmd.Context(), configPath, args[0], destroy, forceForeign, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+
+	// Add subcommands
+	contextCmd.AddCommand(contextCreateCmd)
+	contextCmd.AddCommand(contextListCmd)
+	contextCmd.AddCommand(contextUseCmd)
+	contextCmd.AddCommand(contextRmCmd)
+
+	// Add flags
+	for _, cmd := range []*cobra.Command{contextCreateCmd, contextListCmd, contextUseCmd, contextRmCmd} {
+		cmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	}
+	contextCreateCmd.Flags().String("socket-path", "", "Local Docker socket of the context (default ~/.dockbridge/docker-<name>.sock)")
+	contextCreateCmd.Flags().String("server-type", "", "Server type of the context's server")
+	contextCreateCmd.Flags().String("location", "", "Location of the context's server")
+	contextCreateCmd.Flags().Int("volume-size", 0, "Volume size in GB")
+	contextListCmd.Flags().Bool("servers", false, "Look up the servers of each context")
+	contextRmCmd.Flags().Bool("destroy", false, "Destroy the context's servers")
+	contextRmCmd.Flags().Bool("force-foreign", false, "With --destroy, also destroy servers created by other clients")
+}
+
+// loadContextConfig loads the configuration without validation, so that contexts can
+// be managed before credentials are set up, and returns the file to edit. The selected
+// profile is applied and a stored API token used.
+func loadContextConfig(configPath string) (*clientconfig.Manager, string, error) {
+	manager := clientconfig.NewManager()
+	if err := manager.LoadWithoutValidation(configPath); err != nil {
+		return nil, "", errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to load configuration", err)
+	}
+	if err := manager.ApplyProfile(); err != nil {
+		return nil, "", err
+	}
+	if err := manager.ResolveAPIToken(); err != nil {
+		return nil, "", err
+	}
+
+	path := configPath
+	if path == "" {
+		path = manager.ConfigFileUsed()
+	}
+	if path == "" {
+		var err error
+		if path, err = clientconfig.GetDefaultConfigPath("client"); err != nil {
+			return nil, "", err
+		}
+	}
+	return manager, path, nil
+}
+
+// defaultContextSocket returns the default local socket of a context
+func defaultContextSocket(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		return localsocket.DefaultPipe + "-" + name, nil
+	}
+	return filepath.Join(homeDir, ".dockbridge", "docker-"+name+".sock"), nil
+}
+
+// createContext validates and adds a context to the configuration file
+func createContext(configPath string, contextCfg sharedconfig.ContextConfig, out io.Writer) error {
+	manager, path, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if contextCfg.SocketPath == "" {
+		if contextCfg.SocketPath, err = defaultContextSocket(contextCfg.Name); err != nil {
+			return err
+		}
+	}
+	contextCfg.SocketPath = expandHomePath(contextCfg.SocketPath)
+
+	if err := manager.ValidateNewContext(contextCfg); err != nil {
+		return err
+	}
+	if err := clientconfig.AddContext(path, contextCfg); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Context %q created in %s\n", contextCfg.Name, path)
+	fmt.Fprintf(out, "Its Docker socket is %s; restart \"dockbridge start\" to serve it.\n", contextCfg.SocketPath)
+	return nil
+}
+
+// listContexts prints the default daemon and all contexts, optionally with their servers
+func listContexts(ctx context.Context, configPath string, withServers bool, out io.Writer) error {
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	var servers map[string][]*provider.Server
+	if withServers {
+		cloudProvider, err := newCloudProvider(cfg, cfg.ServerSettings())
+		if err != nil {
+			return err
+		}
+		if servers, err = provider.NewServerRegistry(cloudProvider).List(ctx); err != nil {
+			return err
+		}
+	}
+
+	base := cfg.ServerSettings()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := "CURRENT\tNAME\tSOCKET\tSERVER TYPE\tLOCATION"
+	if withServers {
+		header += "\tSERVERS"
+	}
+	fmt.Fprintln(w, header)
+
+	row := func(name, contextName, socketPath string, settings sharedconfig.ServerSettings) {
+		current := ""
+		if cfg.CurrentContext == contextName || (contextName == "" && cfg.CurrentContext == clientconfig.DefaultContextName) {
+			current = "*"
+		}
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", current, name, socketPath, settings.ServerType, settings.Location)
+		if withServers {
+			line += "\t" + describeServers(servers[contextName])
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	row(clientconfig.DefaultContextName, "", cfg.Docker.SocketPath, base)
+	for _, contextCfg := range cfg.Contexts {
+		row(contextCfg.Name, contextCfg.Name, contextCfg.SocketPath, contextCfg.SettingsFor(base))
+	}
+	return w.Flush()
+}
+
+// describeServers summarizes the servers of a context for listing
+func describeServers(servers []*provider.Server) string {
+	if len(servers) == 0 {
+		return "-"
+	}
+	descriptions := make([]string, 0, len(servers))
+	for _, server := range servers {
+		descriptions = append(descriptions, fmt.Sprintf("%s (%s, %s)", server.Name, server.Status, server.IPAddress))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// contextSocketPath returns the local Docker socket of a context
+func contextSocketPath(cfg *sharedconfig.ClientConfig, name string) (string, error) {
+	if name == clientconfig.DefaultContextName {
+		return cfg.Docker.SocketPath, nil
+	}
+	for _, contextCfg := range cfg.Contexts {
+		if contextCfg.Name == name && contextCfg.SocketPath != "" {
+			return contextCfg.SocketPath, nil
+		}
+	}
+	return "", fmt.Errorf("context '%s' not found", name)
+}
+
+// useContext records the current context and prints how to point the Docker CLI at it
+func useContext(configPath, name string, out io.Writer) error {
+	manager, path, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	socketPath, err := contextSocketPath(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	if err := clientconfig.SetCurrentContext(path, name); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Current context is now %q\n", name)
+	fmt.Fprintf(out, "Point the Docker CLI at it with:\n  export DOCKER_HOST=%s\n", localsocket.DockerHost(socketPath))
+	if cfg.Docker.Context.Register {
+		dockerContext := dockercontext.DefaultName
+		if name != clientconfig.DefaultContextName {
+			dockerContext = dockerContextName(name)
+		}
+		fmt.Fprintf(out, "or, while DockBridge is running:\n  docker context use %s\n", dockerContext)
+	}
+	return nil
+}
+
+// removeContext removes a context from the configuration, optionally destroying its servers
+func removeContext(ctx context.Context, configPath, name string, destroy, forceForeign bool, out io.Writer) error {
+	manager, path, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	var contextCfg *sharedconfig.ContextConfig
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == name {
+			contextCfg = &cfg.Contexts[i]
+		}
+	}
+	if contextCfg == nil {
+		return fmt.Errorf("context '%s' not found", name)
+	}
+
+	if destroy {
+		cloudProvider, err := newCloudProvider(cfg, contextCfg.SettingsFor(cfg.ServerSettings()))
+		if err != nil {
+			return err
+		}
+		destroyed, err := provider.NewServerRegistry(cloudProvider).Destroy(ctx, name, forceForeign)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Destroyed %d server(s) of context %q\n", destroyed, name)
+	}
+
+	if err := clientconfig.RemoveContext(path, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Context %q removed from %s\n", name, path)
+	return nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextCommand(t *testing.T) {
+	assert.Equal(t, "context", contextCmd.Name())
+
+	names := make(map[string]bool)
+	for _, cmd := range contextCmd.Commands() {
+		names[cmd.Name()] = true
+	}
+	for _, name := range []string{"create", "list", "use", "rm"} {
+		assert.True(t, names[name], "context command should have %q subcommand", name)
+	}
+}
+
+func TestContextLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "client.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("docker:\n  socket_path: /var/run/docker.sock\ncontexts: []\n"), 0600))
+
+	var out bytes.Buffer
+	gpuSocket := filepath.Join(dir, "gpu.sock")
+	require.NoError(t, createContext(configPath, sharedconfig.ContextConfig{Name: "gpu", SocketPath: gpuSocket, ServerType: "cpx51"}, &out))
+
+	// Invalid contexts are rejected before the file is touched
+	assert.Error(t, createContext(configPath, sharedconfig.ContextConfig{Name: "ci", SocketPath: gpuSocket}, &out))
+	assert.Error(t, createContext(configPath, sharedconfig.ContextConfig{Name: "big", SocketPath: filepath.Join(dir, "big.sock"), ServerType: "huge"}, &out))
+
+	out.Reset()
+	require.NoError(t, useContext(configPath, "gpu", &out))
+	assert.Contains(t, out.String(), "DOCKER_HOST=unix://"+gpuSocket)
+	assert.Error(t, useContext(configPath, "missing", &out))
+
+	out.Reset()
+	require.NoError(t, listContexts(t.Context(), configPath, false, &out))
+	assert.Regexp(t, `\*\s+gpu\s+`+gpuSocket+`\s+cpx51`, out.String())
+	assert.Contains(t, out.String(), "default")
+
+	require.NoError(t, removeContext(t.Context(), configPath, "gpu", false, false, &out))
+	assert.Error(t, removeContext(t.Context(), configPath, "gpu", false, false, &out))
+
+	out.Reset()
+	require.NoError(t, listContexts(t.Context(), configPath, false, &out))
+	assert.NotContains(t, out.String(), "gpu")
+}
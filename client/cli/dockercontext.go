@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/dockercontext"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+)
+
+// dockerContextEndpoints returns the Docker CLI contexts to register: one for the
+// default daemon and one per configured context. Only Unix sockets are registered.
+func dockerContextEndpoints(cfg *sharedconfig.ClientConfig) []dockercontext.Endpoint {
+	var endpoints []dockercontext.Endpoint
+	if strings.HasPrefix(cfg.Docker.SocketPath, "/") {
+		endpoints = append(endpoints, dockercontext.Endpoint{Name: dockercontext.DefaultName, SocketPath: cfg.Docker.SocketPath})
+	}
+	for _, contextCfg := range cfg.Contexts {
+		endpoints = append(endpoints, dockercontext.Endpoint{Name: dockerContextName(contextCfg.Name), SocketPath: contextCfg.SocketPath})
+	}
+	return endpoints
+}
+
+// dockerContextToUse returns the Docker CLI context to switch to, or "" to leave the selection alone
+func dockerContextToUse(cfg *sharedconfig.ClientConfig) string {
+	if !cfg.Docker.Context.Use {
+		return ""
+	}
+	if cfg.CurrentContext == "" || cfg.CurrentContext == config.DefaultContextName {
+		return dockercontext.DefaultName
+	}
+	return dockerContextName(cfg.CurrentContext)
+}
+
+// registerDockerContexts registers the local sockets as Docker CLI contexts. Failures
+// are logged and never prevent the client from running.
+func registerDockerContexts(cfg *sharedconfig.ClientConfig, store *dockercontext.Store, log logger.LoggerInterface) *dockercontext.Registration {
+	registration, err := dockercontext.Register(store, dockerContextEndpoints(cfg), dockerContextToUse(cfg))
+	if err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to register Docker CLI contexts")
+	}
+	return registration
+}
+
+// unregisterDockerContexts undoes registerDockerContexts on shutdown
+func unregisterDockerContexts(cfg *sharedconfig.ClientConfig, registration *dockercontext.Registration, log logger.LoggerInterface) {
+	if err := registration.Unregister(cfg.Docker.Context.RemoveOnExit); err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to clean up Docker CLI contexts")
+	}
+}
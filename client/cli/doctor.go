@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/docker"
+	"github.com/dockbridge/dockbridge/client/doctor"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/version"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+// maxClockSkew is the clock difference to the server doctor tolerates
+const maxClockSkew = 30 * time.Second
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common setup problems",
+	Long: `Check the DockBridge setup and print how to fix what is wrong: the configuration,
+the cloud provider API and its token's permissions, the SSH key, collisions of the
+local socket with Docker Desktop, the running server and its Docker daemon, clock skew,
+and whether the server's keep-alive port is reachable through firewalls.
+
+Checking the server does not count as Docker activity and never provisions one.
+The command exits with an error when a check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return runDoctor(cmd.Context(), configPath, contextName, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().String("context", "", "Context whose server to check (default: the current context)")
+}
+
+// runDoctor runs all checks and prints their results
+func runDoctor(ctx context.Context, configPath, contextName string, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// An invalid configuration is reported, and the remaining checks run on it anyway
+	configResult := doctor.Result{Name: "Configuration", Status: doctor.Pass}
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		configResult.Status = doctor.Fail
+		configResult.Detail = err.Error()
+		configResult.Fix = "correct the setting named above; dockbridge config validate checks it again"
+		manager = clientconfig.NewManager()
+		if err := manager.LoadWithoutValidation(configPath); err != nil {
+			doctor.Render(out, []doctor.Result{configResult})
+			return fmt.Errorf("the configuration cannot be read")
+		}
+		_ = manager.ApplyProfile()
+		_ = manager.ResolveAPIToken()
+	}
+	cfg := manager.GetConfig()
+	if configResult.Status == doctor.Pass {
+		if path := manager.ConfigFileUsed(); path != "" {
+			configResult.Detail = path
+		} else {
+			configResult.Status = doctor.Warn
+			configResult.Detail = "no configuration file found, using defaults and environment variables"
+			configResult.Fix = "run dockbridge init"
+		}
+	}
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+
+	checks := []doctor.Check{
+		{Name: "Configuration", Run: func(context.Context) doctor.Result { return configResult }},
+		doctor.SSHKey(expandHomePath(cfg.SSH.KeyPath), cfg.SSH.UseAgent),
+		doctor.Socket(expandHomePath(cfg.Docker.SocketPath), homeDir(), os.Getenv("DOCKER_HOST"), daemonRunning(ctx, cfg)),
+	}
+
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		checks = append(checks, doctor.Check{Name: "Provider API", Run: func(context.Context) doctor.Result {
+			return doctor.Result{Status: doctor.Fail, Detail: err.Error(), Fix: "set the provider's API token in the configuration or its environment variable"}
+		}})
+	} else {
+		remote := &doctor.Remote{
+			Find: func(ctx context.Context) (*provider.Server, error) {
+				return runningServer(ctx, cloudProvider, contextName)
+			},
+			Exec: func(ctx context.Context, host, command string) ([]byte, error) {
+				client := newServerSSHClient(&cfg.SSH, host)
+				if err := client.Connect(ctx); err != nil {
+					return nil, err
+				}
+				defer client.Close()
+				return client.ExecuteCommand(ctx, command)
+			},
+		}
+		checks = append(checks,
+			doctor.ProviderAPI(cfg.Provider, cloudProvider),
+			remote.DaemonCheck(),
+			remote.ClockCheck(maxClockSkew),
+			remote.ServerVersionCheck(version.Version),
+			remote.KeepAlivePortCheck(keepAlivePort, cfg.KeepAlive.Transport == docker.KeepAliveTransportSSH),
+		)
+	}
+
+	results := doctor.Run(ctx, checks, doctor.DefaultTimeout)
+	doctor.Render(out, results)
+
+	if failed := doctor.Count(results, doctor.Fail); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	if warnings := doctor.Count(results, doctor.Warn); warnings > 0 {
+		fmt.Fprintf(out, "\nNo failures, %d warnings.\n", warnings)
+		return nil
+	}
+	fmt.Fprintln(out, "\nEverything looks good.")
+	return nil
+}
+
+// daemonRunning reports whether the DockBridge daemon answers on its control API.
+// Without the control API the daemon cannot be told apart from other processes on
+// its socket, so it is assumed to run.
+func daemonRunning(ctx context.Context, cfg *sharedconfig.ClientConfig) bool {
+	if !cfg.Control.Enabled {
+		return true
+	}
+	client, conn, err := dialControl(cfg)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err = client.GetStatus(ctx, &controlv1.GetStatusRequest{})
+	return err == nil
+}
+
+// homeDir returns the user's home directory, or "" when it is unknown
+func homeDir() string {
+	home, _ := os.UserHomeDir()
+	return home
+}
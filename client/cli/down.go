@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/daemonproc"
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Destroy or pause the server of a context and stop the daemon",
+	Long: `Destroy the server of the current context; the Docker data volume is preserved.
+With --pause the server is powered off instead and resumed by the next Docker
+command, which takes about 30 seconds instead of provisioning a new server.
+Providers keep billing powered-off servers.
+
+The running daemon is then stopped, closing its port forwards, unless --keep-daemon
+is given; "dockbridge up" starts it again.
+
+Only servers created by this client are stopped; with --force-foreign servers of the
+context created by other clients (or by DockBridge versions without labels) are too.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		pause, _ := cmd.Flags().GetBool("pause")
+		forceForeign, _ := cmd.Flags().GetBool("force-foreign")
+		keepDaemon, _ := cmd.Flags().GetBool("keep-daemon")
+		if err := runDown(cmd.Context(), configPath, contextName, pause, forceForeign, cmd.OutOrStdout()); err != nil {
+			return err
+		}
+		if keepDaemon {
+			return nil
+		}
+		return stopDaemon(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(downCmd)
+	downCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	downCmd.Flags().String("context", "", "Context whose server to stop (default: the current context)")
+	downCmd.Flags().Bool("pause", false, "Power the server off instead of destroying it")
+	downCmd.Flags().Bool("force-foreign", false, "Also stop servers of the context created by other clients")
+	downCmd.Flags().Bool("keep-daemon", false, "Leave the daemon running")
+}
+
+// daemonStopTimeout is how long down waits for the daemon to shut down
+const daemonStopTimeout = 30 * time.Second
+
+// stopDaemon stops the daemon recorded in the PID file, if it is running
+func stopDaemon(out io.Writer) error {
+	pidPath, err := daemonproc.PIDPath()
+	if err != nil {
+		return err
+	}
+	pid := daemonproc.Running(pidPath)
+	if pid == 0 {
+		return nil
+	}
+	if err := daemonproc.Stop(pid, daemonStopTimeout); err != nil {
+		return errors.NewDaemonError(errors.ErrCodeDaemonStopFailed, "Failed to stop the DockBridge daemon", err)
+	}
+	fmt.Fprintf(out, "DockBridge daemon (PID %d) stopped.\n", pid)
+	return nil
+}
+
+// runDown destroys or pauses the servers of a context
+func runDown(ctx context.Context, configPath, contextName string, pause, forceForeign bool, out io.Writer) error {
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return err
+	}
+	return downContext(ctx, cfg, cloudProvider, contextName, pause, forceForeign, out)
+}
+
+// contextServerSettings resolves a context name (empty means the current context) to
+// its internal name ("" for the default context) and server settings
+func contextServerSettings(cfg *sharedconfig.ClientConfig, name string) (string, sharedconfig.ServerSettings, error) {
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+	if name == "" || name == clientconfig.DefaultContextName {
+		return "", cfg.ServerSettings(), nil
+	}
+
+	for _, contextCfg := range cfg.Contexts {
+		if contextCfg.Name == name {
+			return name, contextCfg.SettingsFor(cfg.ServerSettings()), nil
+		}
+	}
+	return "", sharedconfig.ServerSettings{}, fmt.Errorf("context '%s' not found", name)
+}
+
+// downContext destroys or powers off the servers of a context created by this client, or
+// with forceForeign by any client
+func downContext(ctx context.Context, cfg *sharedconfig.ClientConfig, cloudProvider provider.CloudProvider, contextName string, pause, forceForeign bool, out io.Writer) error {
+	power, canPause := cloudProvider.(provider.PowerController)
+	if pause && !canPause {
+		return fmt.Errorf("provider %s cannot power servers off; run without --pause to destroy them", cfg.Provider)
+	}
+
+	registry := provider.NewServerRegistry(cloudProvider)
+	servers, err := registry.ForContext(ctx, contextName)
+	if err != nil {
+		return err
+	}
+	foreign, err := registry.Foreign(ctx)
+	if err != nil {
+		return err
+	}
+	if forceForeign {
+		servers = append(servers, foreign[contextName]...)
+	} else if len(foreign[contextName]) > 0 {
+		fmt.Fprintf(out, "Skipping %d server(s) created by other clients; use --force-foreign to stop them.\n", len(foreign[contextName]))
+	}
+	if len(servers) == 0 {
+		fmt.Fprintln(out, "No DockBridge servers to stop.")
+		return nil
+	}
+
+	for _, server := range servers {
+		serverID := strconv.FormatInt(server.ID, 10)
+
+		if pause {
+			if server.Status == provider.StatusOff {
+				fmt.Fprintf(out, "Server %s is already powered off.\n", server.Name)
+				continue
+			}
+			if err := power.PowerOffServer(ctx, serverID); err != nil {
+				return fmt.Errorf("failed to power off server %s: %w", server.Name, err)
+			}
+			fmt.Fprintf(out, "Server %s powered off; the next Docker command resumes it.\n", server.Name)
+			continue
+		}
+
+		if err := registry.DestroyServer(ctx, server, forceForeign); err != nil {
+			return err
+		}
+
+		// The address will be reused for other servers
+		if server.IPAddress != "" {
+			_, _ = knownHostsFor(&cfg.SSH).Remove(hostWithPort(server.IPAddress, cfg.SSH.Port))
+		}
+		_ = dockertls.NewStore(expandHomePath(cfg.Docker.TLS.CertDir)).Remove(server.Name)
+		fmt.Fprintf(out, "Server %s destroyed; the Docker data volume is preserved.\n", server.Name)
+	}
+	return nil
+}
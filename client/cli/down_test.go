@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// powerProvider records destroyed and powered-off servers
+type powerProvider struct {
+	listProvider
+	destroyed  []string
+	poweredOff []string
+}
+
+func (p *powerProvider) DestroyServer(ctx context.Context, serverID string) error {
+	p.destroyed = append(p.destroyed, serverID)
+	return nil
+}
+
+func (p *powerProvider) PowerOffServer(ctx context.Context, serverID string) error {
+	p.poweredOff = append(p.poweredOff, serverID)
+	return nil
+}
+
+func (p *powerProvider) PowerOnServer(ctx context.Context, serverID string) (*provider.Server, error) {
+	return nil, nil
+}
+
+func TestDownContext(t *testing.T) {
+	cfg := &sharedconfig.ClientConfig{Provider: "hetzner"}
+	cfg.SSH.KnownHostsPath = filepath.Join(t.TempDir(), "known_hosts")
+	cfg.Docker.TLS.CertDir = t.TempDir()
+
+	newProvider := func() *powerProvider {
+		return &powerProvider{listProvider: listProvider{servers: []*provider.Server{
+			{ID: 1, Name: "dockbridge-1700000000", Status: "running", Labels: ownedLabels("")},
+			{ID: 2, Name: "dockbridge-1700000001", Status: provider.StatusOff, Labels: ownedLabels("")},
+			{ID: 3, Name: "dockbridge-ctx-gpu-1700000000", Status: "running", Labels: ownedLabels("gpu")},
+			{ID: 4, Name: "dockbridge-ctx-gpu-1700000001", Status: "running"},
+		}}}
+	}
+
+	var out bytes.Buffer
+	cloudProvider := newProvider()
+	require.NoError(t, downContext(context.Background(), cfg, cloudProvider, "", true, false, &out))
+	assert.Equal(t, []string{"1"}, cloudProvider.poweredOff)
+	assert.Empty(t, cloudProvider.destroyed)
+	assert.Contains(t, out.String(), "dockbridge-1700000001 is already powered off")
+
+	cloudProvider = newProvider()
+	require.NoError(t, downContext(context.Background(), cfg, cloudProvider, "gpu", false, false, &out))
+	assert.Equal(t, []string{"3"}, cloudProvider.destroyed)
+	assert.Empty(t, cloudProvider.poweredOff)
+	assert.Contains(t, out.String(), "Skipping 1 server(s) created by other clients")
+
+	// Servers without owner labels are only destroyed with forceForeign
+	cloudProvider = newProvider()
+	require.NoError(t, downContext(context.Background(), cfg, cloudProvider, "gpu", false, true, &out))
+	assert.Equal(t, []string{"3", "4"}, cloudProvider.destroyed)
+
+	// Providers without power control cannot pause
+	err := downContext(context.Background(), cfg, &listProvider{}, "", true, false, &out)
+	assert.ErrorContains(t, err, "cannot power servers off")
+}
+
+func TestContextServerSettings(t *testing.T) {
+	cfg := &sharedconfig.ClientConfig{
+		Hetzner:  sharedconfig.HetznerConfig{ServerType: "cpx21", Location: "fsn1"},
+		Contexts: []sharedconfig.ContextConfig{{Name: "gpu", ServerType: "cpx51"}},
+	}
+
+	name, settings, err := contextServerSettings(cfg, "")
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+	assert.Equal(t, "cpx21", settings.ServerType)
+
+	cfg.CurrentContext = "gpu"
+	name, settings, err = contextServerSettings(cfg, "")
+	require.NoError(t, err)
+	assert.Equal(t, "gpu", name)
+	assert.Equal(t, "cpx51", settings.ServerType)
+
+	name, _, err = contextServerSettings(cfg, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	_, _, err = contextServerSettings(cfg, "missing")
+	assert.Error(t, err)
+}
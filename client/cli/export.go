@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/client/provider"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// proxyRemoteSocket is the Docker socket on DockBridge servers
+const proxyRemoteSocket = "/var/run/docker.sock"
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export configuration for other tools",
+}
+
+var exportProxyConfigCmd = &cobra.Command{
+	Use:   "proxy-config",
+	Short: "Write an ssh-docker-proxy configuration for the current server",
+	Long: `Write an ssh-docker-proxy configuration that connects to the running server of the
+selected context, as YAML or as SSH_DOCKER_PROXY_* environment variables.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		localSocket, _ := cmd.Flags().GetString("local-socket")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		if format != "yaml" && format != "env" {
+			return fmt.Errorf("invalid format '%s', must be yaml or env", format)
+		}
+
+		manager, _, err := loadContextConfig(configPath)
+		if err != nil {
+			return err
+		}
+		cfg := manager.GetConfig()
+
+		contextName, settings, err := contextServerSettings(cfg, contextName)
+		if err != nil {
+			return err
+		}
+		cloudProvider, err := newCloudProvider(cfg, settings)
+		if err != nil {
+			return err
+		}
+
+		srv, err := runningServer(cmd.Context(), cloudProvider, contextName)
+		if err != nil {
+			return err
+		}
+		if srv == nil {
+			return fmt.Errorf("no running server; run a Docker command to start one first")
+		}
+
+		if localSocket == "" {
+			if localSocket, err = defaultProxySocket(); err != nil {
+				return err
+			}
+		}
+		proxyCfg := proxyConfigFor(&cfg.SSH, srv, localSocket)
+
+		if output == "" {
+			return writeProxyConfig(cmd.OutOrStdout(), proxyCfg, format)
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0700); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", output, err)
+		}
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) // #nosec G304
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer file.Close()
+		if err := writeProxyConfig(file, proxyCfg, format); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote ssh-docker-proxy configuration for %s to %s\n", srv.Name, output)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportProxyConfigCmd)
+
+	exportCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	exportCmd.PersistentFlags().String("context", "", "Context whose server to use (default: the current context)")
+	exportProxyConfigCmd.Flags().String("local-socket", "", "Socket the proxy listens on (default: ~/.dockbridge/proxy.sock)")
+	exportProxyConfigCmd.Flags().String("format", "yaml", "Output format: yaml or env")
+	exportProxyConfigCmd.Flags().StringP("output", "o", "", "Write to this file instead of standard output")
+}
+
+// proxyConfigFor returns the ssh-docker-proxy configuration connecting to srv with the
+// SSH settings DockBridge uses
+func proxyConfigFor(sshCfg *sharedconfig.SSHConfig, srv *provider.Server, localSocket string) sharedconfig.ProxyConfig {
+	return sharedconfig.ProxyConfig{
+		LocalSocket:  localSocket,
+		SSHUser:      "root",
+		SSHHost:      hostWithPort(srv.IPAddress, sshCfg.Port),
+		SSHKeyPath:   sshCfg.KeyPath,
+		SSHAgent:     sshCfg.UseAgent,
+		SSHAuthSock:  sshCfg.AgentSocket,
+		RemoteSocket: proxyRemoteSocket,
+		Timeout:      sshCfg.Timeout,
+	}
+}
+
+// writeProxyConfig writes cfg as an ssh-docker-proxy configuration file or as
+// environment variables
+func writeProxyConfig(out io.Writer, cfg sharedconfig.ProxyConfig, format string) error {
+	if format == "env" {
+		_, err := fmt.Fprintln(out, strings.Join(cfg.Environ(), "\n"))
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode proxy configuration: %w", err)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// defaultProxySocket returns the socket ssh-docker-proxy listens on by default, next
+// to the sockets of the DockBridge daemon
+func defaultProxySocket() (string, error) {
+	if runtime.GOOS == "windows" {
+		return localsocket.DefaultPipe + "-proxy", nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "proxy.sock"), nil
+}
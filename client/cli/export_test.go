@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProxyConfig(t *testing.T) {
+	sshCfg := &sharedconfig.SSHConfig{
+		KeyPath:  "~/.dockbridge/ssh/id_rsa",
+		Port:     2222,
+		Timeout:  30 * time.Second,
+		UseAgent: true,
+	}
+	srv := &provider.Server{Name: "dockbridge-1", IPAddress: "203.0.113.7"}
+	cfg := proxyConfigFor(sshCfg, srv, "/tmp/proxy.sock")
+
+	var out bytes.Buffer
+	require.NoError(t, writeProxyConfig(&out, cfg, "yaml"))
+	assert.Equal(t, `local_socket: /tmp/proxy.sock
+ssh_user: root
+ssh_host: 203.0.113.7:2222
+ssh_key_path: ~/.dockbridge/ssh/id_rsa
+ssh_agent: true
+remote_socket: /var/run/docker.sock
+timeout: 30s
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeProxyConfig(&out, cfg, "env"))
+	assert.Contains(t, out.String(), "SSH_DOCKER_PROXY_SSH_HOST=203.0.113.7:2222\n")
+	assert.Contains(t, out.String(), "SSH_DOCKER_PROXY_SSH_AGENT=true\n")
+}
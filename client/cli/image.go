@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage golden images",
+	Long: `Manage the golden images new servers boot from when hetzner.golden_image.enabled
+is set. A golden image is a snapshot of a set-up server with Docker preinstalled; it
+is created automatically after the first server is provisioned.`,
+}
+
+var imageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List golden images",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageCommand(cmd, func(ctx context.Context, cfg *sharedconfig.ClientConfig, imager provider.GoldenImager, servers *provider.ServerRegistry, contextName string, out io.Writer) error {
+			return listGoldenImages(ctx, imager, out)
+		})
+	},
+}
+
+var imageInvalidateCmd = &cobra.Command{
+	Use:   "invalidate",
+	Short: "Delete all golden images",
+	Long: `Delete all golden images. The next server is set up from scratch and, if golden
+images are enabled, snapshotted into a new golden image.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageCommand(cmd, func(ctx context.Context, cfg *sharedconfig.ClientConfig, imager provider.GoldenImager, servers *provider.ServerRegistry, contextName string, out io.Writer) error {
+			return invalidateGoldenImages(ctx, imager, out)
+		})
+	},
+}
+
+var imageRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Replace the golden image with a snapshot of the running server",
+	Long: `Delete all golden images and snapshot the running server of the context into a
+new one. The snapshot includes everything on the server's disk, such as pulled
+images. Without a running server this is the same as invalidate.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageCommand(cmd, func(ctx context.Context, cfg *sharedconfig.ClientConfig, imager provider.GoldenImager, servers *provider.ServerRegistry, contextName string, out io.Writer) error {
+			return rebuildGoldenImage(ctx, imager, servers, contextName, out)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+	imageCmd.AddCommand(imageListCmd, imageInvalidateCmd, imageRebuildCmd)
+
+	imageCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	imageCmd.PersistentFlags().String("context", "", "Context whose provider to use (default: the current context)")
+}
+
+// imageAction is the body of an image subcommand
+type imageAction func(ctx context.Context, cfg *sharedconfig.ClientConfig, imager provider.GoldenImager, servers *provider.ServerRegistry, contextName string, out io.Writer) error
+
+// runImageCommand loads the configuration and provider of the selected context and runs action
+func runImageCommand(cmd *cobra.Command, action imageAction) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	contextName, _ := cmd.Flags().GetString("context")
+
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return err
+	}
+	imager, ok := cloudProvider.(provider.GoldenImager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support golden images", cfg.Provider)
+	}
+
+	return action(cmd.Context(), cfg, imager, provider.NewServerRegistry(cloudProvider), contextName, cmd.OutOrStdout())
+}
+
+// listGoldenImages prints the golden images of all versions
+func listGoldenImages(ctx context.Context, imager provider.GoldenImager, out io.Writer) error {
+	images, err := imager.ListGoldenImages(ctx)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		fmt.Fprintln(out, "No golden images.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tARCH\tSIZE\tCREATED\tSTATUS")
+	for _, image := range images {
+		status := "current"
+		if image.Version != provider.GoldenImageVersion {
+			status = "outdated"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.1f GB\t%s\t%s\n", image.ID, image.Name, image.Architecture,
+			image.SizeGB, image.CreatedAt.Local().Format("2006-01-02 15:04"), status)
+	}
+	return w.Flush()
+}
+
+// invalidateGoldenImages deletes all golden images
+func invalidateGoldenImages(ctx context.Context, imager provider.GoldenImager, out io.Writer) error {
+	deleted, err := imager.DeleteGoldenImages(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Deleted %d golden image(s); the next server is set up from scratch.\n", deleted)
+	return nil
+}
+
+// rebuildGoldenImage replaces the golden images with a snapshot of the context's running server
+func rebuildGoldenImage(ctx context.Context, imager provider.GoldenImager, servers *provider.ServerRegistry, contextName string, out io.Writer) error {
+	contextServers, err := servers.ForContext(ctx, contextName)
+	if err != nil {
+		return err
+	}
+
+	var source *provider.Server
+	for _, server := range contextServers {
+		if server.Status == "running" {
+			source = server
+			break
+		}
+	}
+
+	if err := invalidateGoldenImages(ctx, imager, out); err != nil {
+		return err
+	}
+	if source == nil {
+		fmt.Fprintln(out, "No running server to snapshot; the next server provisioned builds the golden image.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Creating golden image from server %s, this takes a few minutes...\n", source.Name)
+	image, err := imager.CreateGoldenImage(ctx, strconv.FormatInt(source.ID, 10))
+	if err != nil {
+		return fmt.Errorf("failed to create golden image: %w", err)
+	}
+	fmt.Fprintf(out, "Golden image %s (ID %d) created.\n", image.Name, image.ID)
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenImager records golden image operations
+type goldenImager struct {
+	images   []*provider.Image
+	deleted  int
+	snapshot string
+}
+
+func (g *goldenImager) GoldenImage(ctx context.Context, serverType string) (*provider.Image, error) {
+	return nil, nil
+}
+
+func (g *goldenImager) CreateGoldenImage(ctx context.Context, serverID string) (*provider.Image, error) {
+	g.snapshot = serverID
+	return &provider.Image{ID: 99, Name: provider.GoldenImageName(provider.GoldenImageVersion)}, nil
+}
+
+func (g *goldenImager) ListGoldenImages(ctx context.Context) ([]*provider.Image, error) {
+	return g.images, nil
+}
+
+func (g *goldenImager) DeleteGoldenImages(ctx context.Context) (int, error) {
+	g.deleted = len(g.images)
+	g.images = nil
+	return g.deleted, nil
+}
+
+func TestListGoldenImages(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, listGoldenImages(context.Background(), &goldenImager{}, &out))
+	assert.Contains(t, out.String(), "No golden images")
+
+	out.Reset()
+	imager := &goldenImager{images: []*provider.Image{
+		{ID: 1, Name: "dockbridge-golden-v0", Architecture: "x86", CreatedAt: time.Now()},
+		{ID: 2, Name: provider.GoldenImageName(provider.GoldenImageVersion), Architecture: "arm", Version: provider.GoldenImageVersion, CreatedAt: time.Now()},
+	}}
+	require.NoError(t, listGoldenImages(context.Background(), imager, &out))
+	assert.Contains(t, out.String(), "outdated")
+	assert.Contains(t, out.String(), "current")
+}
+
+func TestRebuildGoldenImage(t *testing.T) {
+	servers := provider.NewServerRegistry(&listProvider{servers: []*provider.Server{
+		{ID: 1, Name: "dockbridge-1700000000", Status: provider.StatusOff, Labels: ownedLabels("")},
+		{ID: 3, Name: "dockbridge-ctx-gpu-1700000000", Status: "running", Labels: ownedLabels("gpu")},
+	}})
+
+	// The running server of the context is snapshotted after the old images are deleted
+	var out bytes.Buffer
+	imager := &goldenImager{images: []*provider.Image{{ID: 1}}}
+	require.NoError(t, rebuildGoldenImage(context.Background(), imager, servers, "gpu", &out))
+	assert.Equal(t, 1, imager.deleted)
+	assert.Equal(t, "3", imager.snapshot)
+
+	// Without a running server only the old images are deleted
+	imager = &goldenImager{images: []*provider.Image{{ID: 1}}}
+	require.NoError(t, rebuildGoldenImage(context.Background(), imager, servers, "", &out))
+	assert.Equal(t, 1, imager.deleted)
+	assert.Empty(t, imager.snapshot)
+}
@@ -1,45 +1,134 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var logsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "View and manage logs",
-	Long:  `View and manage DockBridge logs.`,
+	Long: `View the logs of the running DockBridge daemon through its control API, or the logs
+of the components on the server over SSH.`,
 }
 
 var logsViewCmd = &cobra.Command{
 	Use:   "view",
 	Short: "View logs",
-	Long:  `View DockBridge logs with optional filtering.`,
+	Long:  `View the recent DockBridge daemon logs with optional filtering.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
 		follow, _ := cmd.Flags().GetBool("follow")
 		lines, _ := cmd.Flags().GetInt("lines")
 		level, _ := cmd.Flags().GetString("level")
-		return viewLogs(follow, lines, level)
+		return viewLogs(cmd.Context(), cmd.OutOrStdout(), configPath, &controlv1.StreamLogsRequest{
+			Tail:   int32(lines), // #nosec G115 -- line counts fit in int32
+			Follow: follow,
+			Level:  level,
+		})
 	},
 }
 
 var logsStreamCmd = &cobra.Command{
 	Use:   "stream",
 	Short: "Stream real-time logs",
-	Long:  `Stream real-time DockBridge logs.`,
+	Long:  `Stream the DockBridge daemon logs as they are written.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
 		level, _ := cmd.Flags().GetString("level")
-		return streamLogs(level)
+		return viewLogs(cmd.Context(), cmd.OutOrStdout(), configPath, &controlv1.StreamLogsRequest{
+			Tail:   -1,
+			Follow: true,
+			Level:  level,
+		})
 	},
 }
 
+var logsRemoteCmd = &cobra.Command{
+	Use:   "remote [setup|server|docker]...",
+	Short: "View the logs of the server's components",
+	Long: `View the logs of the components on the running server of the context over SSH:
+  setup   the provisioning log, /var/log/dockbridge-setup.log
+  server  the dockbridge-server systemd unit
+  docker  the dockerd journal
+All three are shown unless some are named. --since and --until take a duration such as
+10m or an RFC 3339 time and apply to the journal; the setup log has no timestamps, e.g.:
+  dockbridge logs remote setup
+  dockbridge logs remote docker --since 30m -f`,
+	Args:      cobra.OnlyValidArgs,
+	ValidArgs: remoteLogSources,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		opts := remoteLogOptions{Sources: args}
+		opts.Follow, _ = cmd.Flags().GetBool("follow")
+		opts.Lines, _ = cmd.Flags().GetInt("lines")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+
+		var err error
+		now := time.Now()
+		if opts.Since, err = parseLogTime(since, now); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		if opts.Until, err = parseLogTime(until, now); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		return viewRemoteLogs(cmd.Context(), configPath, contextName, opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+// remoteLogSources are the server components whose logs "logs remote" shows
+var remoteLogSources = []string{"setup", "server", "docker"}
+
+// setupLogPath is the log of the provisioning script on servers
+const setupLogPath = "/var/log/dockbridge-setup.log"
+
+// remoteLogUnits are the systemd units of the journal sources
+var remoteLogUnits = map[string]string{
+	"server": "dockbridge-server",
+	"docker": "docker",
+}
+
+// remoteLogOptions selects the server logs to show
+type remoteLogOptions struct {
+	// Sources are the components to show, all when empty
+	Sources []string
+
+	// Follow keeps printing new lines until interrupted
+	Follow bool
+
+	// Lines limits the output to the last lines of each log, 0 for all
+	Lines int
+
+	// Since and Until bound the journal entries shown; zero for no bound
+	Since time.Time
+	Until time.Time
+}
+
 func init() {
 	rootCmd.AddCommand(logsCmd)
 
 	// Add subcommands
 	logsCmd.AddCommand(logsViewCmd)
 	logsCmd.AddCommand(logsStreamCmd)
+	logsCmd.AddCommand(logsRemoteCmd)
 
 	// Add flags
 	logsViewCmd.Flags().BoolP("follow", "f", false, "Follow log output")
@@ -47,38 +136,162 @@ func init() {
 	logsViewCmd.Flags().StringP("level", "l", "info", "Minimum log level to display (debug, info, warn, error, fatal)")
 
 	logsStreamCmd.Flags().StringP("level", "l", "info", "Minimum log level to display (debug, info, warn, error, fatal)")
+
+	logsRemoteCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	logsRemoteCmd.Flags().String("context", "", "Context whose server to read logs from (default: the current context)")
+	logsRemoteCmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	logsRemoteCmd.Flags().IntP("lines", "n", 100, "Number of lines to show of each log (0 for all)")
+	logsRemoteCmd.Flags().String("since", "", "Show journal entries since a duration ago (e.g. 10m) or an RFC 3339 time")
+	logsRemoteCmd.Flags().String("until", "", "Show journal entries until a duration ago or an RFC 3339 time")
 }
 
-func viewLogs(follow bool, lines int, level string) error {
-	fmt.Printf("Viewing last %d log entries with minimum level %s\n", lines, level)
+// viewLogs prints the daemon's log entries selected by req until they end or the
+// command is interrupted
+func viewLogs(ctx context.Context, out io.Writer, configPath string, req *controlv1.StreamLogsRequest) error {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client, conn, err := dialControl(manager.GetConfig())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
-	// Placeholder for actual log viewing logic
-	fmt.Println("2023-07-21T10:15:30Z INFO  Starting DockBridge client")
-	fmt.Println("2023-07-21T10:15:31Z INFO  Loading configuration from /home/user/.dockbridge/client.yaml")
-	fmt.Println("2023-07-21T10:15:32Z INFO  Starting Docker proxy on port 2376")
-	fmt.Println("2023-07-21T10:15:33Z INFO  Starting lock detector")
-	fmt.Println("2023-07-21T10:15:34Z INFO  Starting keep-alive client with interval 30s")
-	fmt.Println("2023-07-21T10:15:35Z INFO  Checking for existing Hetzner server")
-	fmt.Println("2023-07-21T10:15:36Z INFO  No existing server found, provisioning new server")
-	fmt.Println("2023-07-21T10:16:30Z INFO  Server provisioned successfully")
-	fmt.Println("2023-07-21T10:16:31Z INFO  Establishing SSH connection to server")
-	fmt.Println("2023-07-21T10:16:32Z INFO  Docker proxy ready to accept commands")
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if follow {
-		fmt.Println("\nFollowing log output (press Ctrl+C to stop)...")
+	stream, err := client.StreamLogs(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to read daemon logs: %w", daemonError(err))
+	}
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+			return nil
+		}
+		if status.Code(err) == codes.Unavailable {
+			return daemonError(err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read daemon logs: %w", err)
+		}
+		fmt.Fprintln(out, entry.Line)
 	}
+}
 
+// viewRemoteLogs prints the logs of the components on the server of a context until
+// they end or the command is interrupted
+func viewRemoteLogs(ctx context.Context, configPath, contextName string, opts remoteLogOptions, out, errOut io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, _, err := connectServer(ctx, configPath, contextName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	err = client.Run(ctx, remoteLogsCommand(opts), ssh.Stdio{Stdout: out, Stderr: errOut}, nil)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read server logs: %w", remoteExitError(err))
+	}
 	return nil
 }
 
-func streamLogs(level string) error {
-	fmt.Printf("Streaming logs with minimum level %s\n", level)
-	fmt.Println("Press Ctrl+C to stop streaming")
+// remoteLogsCommand returns the shell command printing the server logs selected by opts
+func remoteLogsCommand(opts remoteLogOptions) string {
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources = remoteLogSources
+	}
 
-	// Placeholder for actual log streaming logic
-	fmt.Println("2023-07-21T10:20:00Z INFO  Received keep-alive heartbeat")
-	fmt.Println("2023-07-21T10:20:30Z INFO  Received keep-alive heartbeat")
-	fmt.Println("2023-07-21T10:21:00Z INFO  Received keep-alive heartbeat")
+	var setup bool
+	var units []string
+	for _, source := range remoteLogSources {
+		if !slices.Contains(sources, source) {
+			continue
+		}
+		if source == "setup" {
+			setup = true
+		} else {
+			units = append(units, remoteLogUnits[source])
+		}
+	}
 
-	return nil
+	var tail string
+	if setup {
+		lines := "+1"
+		if opts.Lines > 0 {
+			lines = strconv.Itoa(opts.Lines)
+		}
+		tail = "tail -v -n " + lines
+		if opts.Follow {
+			tail += " -F"
+		}
+		tail += " " + setupLogPath
+	}
+
+	var journal string
+	if len(units) > 0 {
+		args := []string{"journalctl", "--no-pager", "-o", "short-iso"}
+		for _, unit := range units {
+			args = append(args, "-u", unit)
+		}
+		if opts.Lines > 0 {
+			args = append(args, "-n", strconv.Itoa(opts.Lines))
+		}
+		if !opts.Since.IsZero() {
+			args = append(args, "--since", shellQuote(journalTime(opts.Since)))
+		}
+		if !opts.Until.IsZero() {
+			args = append(args, "--until", shellQuote(journalTime(opts.Until)))
+		}
+		if opts.Follow {
+			args = append(args, "-f")
+		}
+		journal = fmt.Sprintf("echo '==> journal: %s <=='; %s", strings.Join(units, ", "), strings.Join(args, " "))
+	}
+
+	switch {
+	case tail == "":
+		return journal
+	case journal == "":
+		return tail
+	case opts.Follow:
+		// Both logs are followed at once; the tail ends with the session
+		return fmt.Sprintf("%s & %s; kill $! 2>/dev/null", tail, journal)
+	default:
+		return fmt.Sprintf("%s; echo; %s", tail, journal)
+	}
+}
+
+// journalTime formats t for journalctl --since and --until
+func journalTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05") + " UTC"
+}
+
+// parseLogTime parses a log time bound given as a duration before now or an RFC 3339
+// time; an empty value is no bound
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor an RFC 3339 time", value)
+	}
+	return t, nil
 }
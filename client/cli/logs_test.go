@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteLogsCommand(t *testing.T) {
+	since := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t,
+		"tail -v -n 100 /var/log/dockbridge-setup.log; echo; "+
+			"echo '==> journal: dockbridge-server, docker <=='; journalctl --no-pager -o short-iso -u dockbridge-server -u docker -n 100",
+		remoteLogsCommand(remoteLogOptions{Lines: 100}))
+
+	assert.Equal(t,
+		"echo '==> journal: docker <=='; journalctl --no-pager -o short-iso -u docker --since '2025-03-01 10:00:00 UTC' -f",
+		remoteLogsCommand(remoteLogOptions{Sources: []string{"docker"}, Since: since, Follow: true}))
+
+	assert.Equal(t,
+		"tail -v -n +1 -F /var/log/dockbridge-setup.log & "+
+			"echo '==> journal: dockbridge-server <=='; journalctl --no-pager -o short-iso -u dockbridge-server -f; kill $! 2>/dev/null",
+		remoteLogsCommand(remoteLogOptions{Sources: []string{"server", "setup"}, Follow: true}))
+}
+
+func TestParseLogTime(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	bound, err := parseLogTime("", now)
+	require.NoError(t, err)
+	assert.True(t, bound.IsZero())
+
+	bound, err = parseLogTime("30m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-30*time.Minute), bound)
+
+	bound, err = parseLogTime("2025-03-01T09:00:00+01:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01 08:00:00 UTC", journalTime(bound))
+
+	_, err = parseLogTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestLogsRemoteCommandRejectsUnknownSources(t *testing.T) {
+	assert.Error(t, logsRemoteCmd.ValidateArgs([]string{"kernel"}))
+	assert.NoError(t, logsRemoteCmd.ValidateArgs([]string{"setup", "docker"}))
+}
@@ -0,0 +1,8 @@
+package cli
+
+// Cloud provider backends selectable with the provider config field. Each backend
+// registers itself with client/provider when imported.
+import (
+	_ "github.com/dockbridge/dockbridge/client/digitalocean"
+	_ "github.com/dockbridge/dockbridge/client/hetzner"
+)
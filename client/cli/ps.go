@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/spf13/cobra"
+)
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List port forwards grouped by docker compose project",
+	Long: `List the port and socket forwards of the running daemon, grouped by the docker
+compose project and service of their containers. Containers started outside compose
+are listed last.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return withControl(configPath, func(client controlv1.ControlServiceClient) error {
+			resp, err := client.ListForwards(cmd.Context(), &controlv1.ListForwardsRequest{Context: contextName})
+			if err != nil {
+				return fmt.Errorf("failed to list forwards: %w", err)
+			}
+			printForwards(cmd.OutOrStdout(), resp.Forwards)
+			return nil
+		})
+	},
+}
+
+var psCloseCmd = &cobra.Command{
+	Use:   "close PROJECT",
+	Short: "Close all port forwards of a docker compose project",
+	Long: `Close all port and socket forwards of a docker compose project at once. The
+forwards are created again when the project's containers are started again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return withControl(configPath, func(client controlv1.ControlServiceClient) error {
+			resp, err := client.CloseProjectForwards(cmd.Context(), &controlv1.CloseProjectForwardsRequest{Context: contextName, Project: args[0]})
+			if err != nil {
+				return fmt.Errorf("failed to close forwards of %s: %w", args[0], err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Closed %d forwards of project %s\n", resp.Closed, args[0])
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(psCmd)
+	psCmd.AddCommand(psCloseCmd)
+
+	psCmd.PersistentFlags().String("context", "", "Context whose forwards to show (default: the default context)")
+}
+
+// withControl loads the configuration and runs fn with a control API client
+func withControl(configPath string, fn func(client controlv1.ControlServiceClient) error) error {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	client, conn, err := dialControl(manager.GetConfig())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return daemonError(fn(client))
+}
+
+// printForwards prints forwards sorted by project, service and container; forwards
+// of containers outside compose come last
+func printForwards(out io.Writer, forwards []*controlv1.Forward) {
+	if len(forwards) == 0 {
+		fmt.Fprintln(out, "No active forwards.")
+		return
+	}
+
+	sorted := append([]*controlv1.Forward(nil), forwards...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a.Project == "") != (b.Project == "") {
+			return a.Project != ""
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.ContainerName != b.ContainerName {
+			return a.ContainerName < b.ContainerName
+		}
+		return a.LocalPort < b.LocalPort
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSERVICE\tCONTAINER\tFORWARD\tSTATUS")
+	for _, forward := range sorted {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", orDash(forward.Project), orDash(forward.Service), forward.ContainerName, describeForward(forward), forward.Status)
+	}
+	w.Flush()
+}
+
+// describeForward returns the local and remote end of a forward
+func describeForward(forward *controlv1.Forward) string {
+	if forward.Type == "unix" {
+		return fmt.Sprintf("%s->%s", forward.LocalSocket, forward.RemoteSocket)
+	}
+	address := forward.BindAddress
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	return fmt.Sprintf("%s:%d->%d/%s", address, forward.LocalPort, forward.RemotePort, forward.Type)
+}
+
+// orDash returns value, or "-" when it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrintForwards(t *testing.T) {
+	var out bytes.Buffer
+	printForwards(&out, []*controlv1.Forward{
+		{Type: "tcp", ContainerName: "redis", LocalPort: 6379, RemotePort: 6379, Status: "active"},
+		{Type: "tcp", ContainerName: "shop-web-1", Project: "shop", Service: "web", BindAddress: "127.0.0.1", LocalPort: 8080, RemotePort: 80, Status: "active"},
+		{Type: "unix", ContainerName: "shop-db-1", Project: "shop", Service: "db", LocalSocket: "/tmp/db.sock", RemoteSocket: "/run/postgresql/.s.PGSQL.5432", Status: "active"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"PROJECT", "SERVICE", "CONTAINER", "FORWARD", "STATUS"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"shop", "db", "shop-db-1", "/tmp/db.sock->/run/postgresql/.s.PGSQL.5432", "active"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"shop", "web", "shop-web-1", "127.0.0.1:8080->80/tcp", "active"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"-", "-", "redis", "127.0.0.1:6379->6379/tcp", "active"}, strings.Fields(lines[3]))
+}
+
+func TestPrintForwardsEmpty(t *testing.T) {
+	var out bytes.Buffer
+	printForwards(&out, nil)
+	assert.Equal(t, "No active forwards.\n", out.String())
+}
+
+func TestDaemonError(t *testing.T) {
+	err := daemonError(status.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(t, errors.ExitDaemon, errors.ExitCode(err))
+	assert.Contains(t, errors.Hint(err), "dockbridge start")
+
+	other := stderrors.New("boom")
+	assert.Equal(t, other, daemonError(other))
+	assert.NoError(t, daemonError(nil))
+}
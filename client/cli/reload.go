@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/docker"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var reloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Apply configuration changes to the running daemon",
+	Long: `Reload the configuration file in the running daemon. The log level, keep-alive
+interval, port forward conflict strategy and activity timeouts are applied right
+away; other changes, like the server type or location, take effect after a restart.
+
+The daemon also reloads when the configuration file is saved or when it receives
+SIGHUP.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+
+		// The daemon validates the new configuration and reports why it is rejected
+		manager := clientconfig.NewManager()
+		if err := manager.LoadWithoutValidation(configPath); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		client, conn, err := dialControl(manager.GetConfig())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := client.Reload(cmd.Context(), &controlv1.ReloadRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to reload configuration: %w", daemonError(err))
+		}
+		printReload(cmd.OutOrStdout(), resp.Applied, resp.Deferred)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reloadCmd)
+}
+
+// printReload prints the settings applied by a reload and those awaiting a restart
+func printReload(out io.Writer, applied, deferred []string) {
+	if len(applied) == 0 && len(deferred) == 0 {
+		fmt.Fprintln(out, "No changes")
+		return
+	}
+	if len(applied) > 0 {
+		fmt.Fprintf(out, "Applied: %s\n", strings.Join(applied, ", "))
+	}
+	if len(deferred) > 0 {
+		fmt.Fprintf(out, "Restart required: %s\n", strings.Join(deferred, ", "))
+	}
+}
+
+// configReloader applies the safe changes of the configuration file to the running daemons
+type configReloader struct {
+	mu      sync.Mutex
+	path    string
+	current *sharedconfig.ClientConfig
+	log     *logger.Logger
+	daemons []*docker.DockBridgeDaemon
+}
+
+// reload loads the configuration file and applies the changed settings that are safe to
+// change at runtime; other changed settings are returned as deferred. An invalid
+// configuration is rejected and the running one kept.
+func (r *configReloader) reload() (applied, deferred []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manager := clientconfig.NewManager()
+	if err := manager.Load(r.path); err != nil {
+		return nil, nil, err
+	}
+	updated := manager.GetConfig()
+
+	for _, change := range clientconfig.Diff(r.current, updated) {
+		if change.Reloadable {
+			applied = append(applied, change.Key)
+		} else {
+			deferred = append(deferred, change.Key)
+		}
+	}
+	if len(applied) > 0 {
+		r.apply(updated)
+	}
+	if len(deferred) > 0 {
+		r.log.WithFields(map[string]any{
+			"settings": strings.Join(deferred, ", "),
+		}).Warn("Configuration changes take effect after a restart")
+	}
+	return applied, deferred, nil
+}
+
+// watchConfigReloads reloads the configuration when its file is saved or the process
+// receives SIGHUP, until ctx is done
+func watchConfigReloads(ctx context.Context, r *configReloader) {
+	reload := func(trigger string) {
+		applied, deferred, err := r.reload()
+		if err != nil {
+			r.log.WithFields(map[string]any{
+				"trigger": trigger,
+				"error":   err.Error(),
+			}).Error("Configuration reload rejected, keeping the running configuration")
+			return
+		}
+		r.log.WithFields(map[string]any{
+			"trigger":  trigger,
+			"applied":  strings.Join(applied, ", "),
+			"deferred": strings.Join(deferred, ", "),
+		}).Info("Configuration reloaded")
+	}
+
+	if r.path != "" {
+		if err := clientconfig.Watch(ctx, r.path, func() { reload("file") }); err != nil {
+			r.log.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Configuration file is not watched for changes")
+		}
+	}
+
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hupCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hupCh:
+				reload("SIGHUP")
+			}
+		}
+	}()
+}
+
+// apply applies the reloadable settings of updated and records them as current
+func (r *configReloader) apply(updated *sharedconfig.ClientConfig) {
+	if level, err := logger.ParseLevel(updated.Logging.Level); err == nil {
+		r.log.SetLevel(level)
+	}
+
+	// Daemons may still read the current configuration, so a copy is changed
+	portForward := r.current.PortForward
+	portForward.ConflictStrategy = updated.PortForward.ConflictStrategy
+
+	for _, d := range r.daemons {
+		d.ApplySettings(docker.Settings{
+			IdleTimeout:       updated.Activity.IdleTimeout,
+			ConnectionTimeout: updated.Activity.ConnectionTimeout,
+			KeepAliveInterval: updated.KeepAlive.Interval,
+			PortForward:       &portForward,
+		})
+	}
+
+	current := *r.current
+	current.Logging.Level = updated.Logging.Level
+	current.KeepAlive.Interval = updated.KeepAlive.Interval
+	current.PortForward.ConflictStrategy = updated.PortForward.ConflictStrategy
+	current.Activity.IdleTimeout = updated.Activity.IdleTimeout
+	current.Activity.ConnectionTimeout = updated.Activity.ConnectionTimeout
+	r.current = &current
+}
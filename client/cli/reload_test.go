@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigReloader(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "client.yaml")
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(configPath, []byte("hetzner:\n  api_token: token\ndocker:\n  socket_path: /var/run/docker.sock\n"+content), 0600))
+	}
+	writeConfig("")
+
+	manager := clientconfig.NewManager()
+	require.NoError(t, manager.Load(configPath))
+	cfg := manager.GetConfig()
+	reloader := &configReloader{path: configPath, current: cfg, log: logger.NewDefault()}
+
+	applied, deferred, err := reloader.reload()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Empty(t, deferred)
+
+	writeConfig("logging:\n  level: debug\nssh:\n  port: 2222\n")
+	applied, deferred, err = reloader.reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logging.level"}, applied)
+	assert.Equal(t, []string{"ssh.port"}, deferred)
+	assert.Equal(t, "debug", reloader.current.Logging.Level)
+	assert.Equal(t, 22, reloader.current.SSH.Port)
+	// The configuration the daemons started with is left untouched
+	assert.NotEqual(t, "debug", cfg.Logging.Level)
+
+	// An invalid configuration is rejected and the running one kept
+	writeConfig("logging:\n  level: loud\n")
+	_, _, err = reloader.reload()
+	assert.Error(t, err)
+	assert.Equal(t, "debug", reloader.current.Logging.Level)
+}
+
+func TestPrintReload(t *testing.T) {
+	var out bytes.Buffer
+	printReload(&out, nil, nil)
+	assert.Equal(t, "No changes\n", out.String())
+
+	out.Reset()
+	printReload(&out, []string{"logging.level", "activity.idle_timeout"}, []string{"hetzner.server_type"})
+	assert.Equal(t, "Applied: logging.level, activity.idle_timeout\nRestart required: hetzner.server_type\n", out.String())
+}
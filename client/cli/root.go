@@ -2,15 +2,19 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/pkg/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	verbose bool
-	rootCmd = &cobra.Command{
+	cfgFile     string
+	verbose     bool
+	profileName string
+	rootCmd     = &cobra.Command{
 		Use:   "dockbridge",
 		Short: "DockBridge client for managing remote Docker operations",
 		Long: `DockBridge client enables seamless Docker development workflows by 
@@ -20,12 +24,17 @@ Hetzner Cloud servers with intelligent lifecycle management.
 The client automatically provisions servers when needed, manages server
 lifecycle based on laptop lock status, and maintains persistent volumes
 for your Docker data.`,
-		Version: "0.1.0",
+		Version: version.Version,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Setup logging based on verbose flag
 			if verbose {
 				fmt.Println("Verbose logging enabled")
 			}
+			// Every configuration loaded by the command, and by processes it starts,
+			// applies the selected profile
+			if profileName != "" {
+				return os.Setenv(clientconfig.ProfileEnv, profileName)
+			}
 			return nil
 		},
 	}
@@ -42,6 +51,7 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.dockbridge/client.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "enable verbose logging")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "configuration profile to apply (default is $DOCKBRIDGE_PROFILE)")
 
 	// Version flag
 	rootCmd.SetVersionTemplate("DockBridge Client v{{.Version}}\n")
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/schedule"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Show and adjust the working hours schedule",
+	Long: `Show and adjust the working hours schedule. With schedule.enabled the daemon shuts
+the server down outside the working hours once the client is idle, and provisions it
+shortly before they start so the first Docker command of the day does not wait.`,
+}
+
+var scheduleShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the working hours of the coming days",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		days, _ := cmd.Flags().GetInt("days")
+
+		manager, _, err := loadContextConfig(configPath)
+		if err != nil {
+			return err
+		}
+		return showSchedule(cmd.OutOrStdout(), manager.GetConfig().Schedule, time.Now(), days)
+	},
+}
+
+var scheduleSkipCmd = &cobra.Command{
+	Use:   "skip <date>",
+	Short: "Mark a date as a holiday",
+	Long: `Add a date (YYYY-MM-DD, "today" or "tomorrow") to schedule.holidays, so the server
+is neither prewarmed nor kept available that day. Restart "dockbridge start" to apply it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		return setHoliday(cmd.OutOrStdout(), configPath, args[0], true)
+	},
+}
+
+var scheduleUnskipCmd = &cobra.Command{
+	Use:   "unskip <date>",
+	Short: "Remove a holiday",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		return setHoliday(cmd.OutOrStdout(), configPath, args[0], false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+
+	// Add subcommands
+	scheduleCmd.AddCommand(scheduleShowCmd)
+	scheduleCmd.AddCommand(scheduleSkipCmd)
+	scheduleCmd.AddCommand(scheduleUnskipCmd)
+
+	// Add flags
+	for _, cmd := range []*cobra.Command{scheduleShowCmd, scheduleSkipCmd, scheduleUnskipCmd} {
+		cmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	}
+	scheduleShowCmd.Flags().Int("days", 7, "Number of days to show")
+}
+
+// showSchedule prints whether the schedule is in effect at now and the working hours of
+// the following days
+func showSchedule(out io.Writer, cfg sharedconfig.ScheduleConfig, now time.Time, days int) error {
+	workingHours, err := schedule.New(cfg)
+	if err != nil {
+		return err
+	}
+	local := now.In(workingHours.Location())
+
+	if cfg.Enabled {
+		fmt.Fprintf(out, "Schedule: enabled, server %s outside working hours after %s idle, prewarmed %s ahead\n",
+			scheduleActionVerb(cfg.Action), cfg.OffHoursIdle, cfg.Prewarm)
+	} else {
+		fmt.Fprintln(out, "Schedule: disabled (set schedule.enabled to apply it)")
+	}
+	fmt.Fprintf(out, "Time zone: %s\n", workingHours.Location())
+
+	switch period, ok := workingHours.Next(now); {
+	case !ok:
+		fmt.Fprintln(out, "Now: off hours, no working hours within a year")
+	case !now.Before(period.Start):
+		fmt.Fprintf(out, "Now: working hours until %s\n", period.End.Format("15:04"))
+	default:
+		fmt.Fprintf(out, "Now: off hours, next working hours %s\n", period.Start.Format("Mon 2006-01-02 15:04"))
+	}
+
+	fmt.Fprintln(out)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	for i := 0; i < days; i++ {
+		day := midnight.AddDate(0, 0, i)
+		hours := "off"
+		if dayHours, ok := workingHours.On(day); ok {
+			hours = dayHours.String()
+		}
+		fmt.Fprintf(out, "%s  %s\n", day.Format("Mon 2006-01-02"), hours)
+	}
+	return nil
+}
+
+// scheduleActionVerb describes the schedule action in user-facing messages
+func scheduleActionVerb(action sharedconfig.LifecyclePolicy) string {
+	if action == sharedconfig.LifecyclePolicyPowerOff {
+		return "powered off"
+	}
+	return "destroyed"
+}
+
+// setHoliday adds or removes a holiday in the configuration file
+func setHoliday(out io.Writer, configPath, date string, holiday bool) error {
+	manager, path, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	workingHours, err := schedule.New(manager.GetConfig().Schedule)
+	if err != nil {
+		return err
+	}
+
+	day, err := parseScheduleDate(date, time.Now().In(workingHours.Location()))
+	if err != nil {
+		return err
+	}
+	if err := clientconfig.SetHoliday(path, day, holiday); err != nil {
+		return err
+	}
+
+	if holiday {
+		fmt.Fprintf(out, "%s is a holiday; restart \"dockbridge start\" to apply it.\n", day)
+	} else {
+		fmt.Fprintf(out, "%s is no longer a holiday; restart \"dockbridge start\" to apply it.\n", day)
+	}
+	return nil
+}
+
+// parseScheduleDate parses "today", "tomorrow" or a YYYY-MM-DD date relative to now
+func parseScheduleDate(date string, now time.Time) (string, error) {
+	switch date {
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD, today or tomorrow", date)
+	}
+	return date, nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowSchedule(t *testing.T) {
+	cfg := sharedconfig.ScheduleConfig{
+		Enabled:      true,
+		Timezone:     "UTC",
+		WorkingHours: "09:00-18:00",
+		Prewarm:      10 * time.Minute,
+		OffHoursIdle: 10 * time.Minute,
+		Action:       sharedconfig.LifecyclePolicyPowerOff,
+		Holidays:     []string{"2026-10-19"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, showSchedule(&out, cfg, time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC), 4))
+	assert.Contains(t, out.String(), "server powered off outside working hours")
+	assert.Contains(t, out.String(), "next working hours Tue 2026-10-20 09:00")
+	assert.Contains(t, out.String(), "Fri 2026-10-16  09:00-18:00")
+	assert.Contains(t, out.String(), "Sat 2026-10-17  off")
+	assert.Contains(t, out.String(), "Mon 2026-10-19  off")
+}
+
+func TestSetHoliday(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("schedule:\n  enabled: true\n  holidays: []\n"), 0600))
+
+	var out bytes.Buffer
+	require.NoError(t, setHoliday(&out, configPath, "2026-12-24", true))
+	require.NoError(t, setHoliday(&out, configPath, "2026-12-25", true))
+	require.NoError(t, setHoliday(&out, configPath, "2026-12-24", false))
+	assert.Error(t, setHoliday(&out, configPath, "2026-12-24", false))
+	assert.Error(t, setHoliday(&out, configPath, "24.12.2026", true))
+
+	manager, _, err := loadContextConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-12-25"}, manager.GetConfig().Schedule.Holidays)
+}
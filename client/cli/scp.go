@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/spf13/cobra"
+)
+
+var scpCmd = &cobra.Command{
+	Use:   "scp <source> <destination>",
+	Short: "Copy files to or from the server",
+	Long: `Copy a file, or a directory with -r, between this machine and the running server
+of the context. Paths on the server start with ":"; relative ones are relative to the
+home directory of root. As with scp, copying into an existing directory puts the
+source inside it, e.g.:
+  dockbridge scp ./docker-compose.yml :/srv/app/
+  dockbridge scp :/var/log/dockbridge-setup.log .
+  dockbridge scp -r :/srv/app/data ./data`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		return runSCP(cmd.Context(), configPath, contextName, args[0], args[1], recursive, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scpCmd)
+	scpCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	scpCmd.Flags().String("context", "", "Context whose server to copy to or from (default: the current context)")
+	scpCmd.Flags().BoolP("recursive", "r", false, "Copy directories recursively")
+}
+
+// runSCP copies source to destination, one of which is on the server of a context
+func runSCP(ctx context.Context, configPath, contextName, source, destination string, recursive bool, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	remoteSource, sourceIsRemote := remotePath(source)
+	remoteDestination, destinationIsRemote := remotePath(destination)
+	if sourceIsRemote == destinationIsRemote {
+		return fmt.Errorf(`exactly one of source and destination must be on the server, e.g. ":/root/file"`)
+	}
+
+	if !sourceIsRemote {
+		info, err := os.Stat(source)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && !recursive {
+			return fmt.Errorf("%s is a directory; use -r to copy it", source)
+		}
+	}
+
+	client, _, err := connectServer(ctx, configPath, contextName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	switch {
+	case sourceIsRemote && recursive:
+		err = downloadDir(ctx, client, remoteSource, destination)
+	case sourceIsRemote:
+		err = downloadFile(ctx, client, remoteSource, destination)
+	case recursive:
+		err = uploadDir(ctx, client, source, remoteDestination)
+	default:
+		err = uploadFile(ctx, client, source, remoteDestination)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Copied %s to %s\n", source, destination)
+	return nil
+}
+
+// remotePath returns the server path of arg and whether arg names one (":path"); an
+// empty path is the home directory
+func remotePath(arg string) (string, bool) {
+	p, ok := strings.CutPrefix(arg, ":")
+	if !ok {
+		return "", false
+	}
+	if p == "" {
+		p = "."
+	}
+	return p, true
+}
+
+// uploadFile copies the local file src to dst on the server, or into dst if it is a
+// directory
+func uploadFile(ctx context.Context, client ssh.Client, src, dst string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	command := fmt.Sprintf(`dst=%s; [ -d "$dst" ] && dst="$dst"/%s; cat > "$dst"`,
+		shellQuote(dst), shellQuote(filepath.Base(src)))
+	return runCopyCommand(ctx, client, command, ssh.Stdio{Stdin: file})
+}
+
+// downloadFile copies the file src on the server to the local dst, or into dst if it is
+// a directory
+func downloadFile(ctx context.Context, client ssh.Client, src, dst string) error {
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	err = runCopyCommand(ctx, client, "cat -- "+shellQuote(src), ssh.Stdio{Stdout: file})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// uploadDir copies the local directory src to dst on the server, or into dst if it is
+// an existing directory
+func uploadDir(ctx context.Context, client ssh.Client, src, dst string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTar(writer, src))
+	}()
+	defer reader.Close()
+
+	// Archive entries start with the name of src, which is dropped when dst is created
+	command := fmt.Sprintf(`dst=%s; if [ -d "$dst" ]; then tar -x -C "$dst"; else mkdir -p "$dst" && tar -x -C "$dst" --strip-components=1; fi`,
+		shellQuote(dst))
+	return runCopyCommand(ctx, client, command, ssh.Stdio{Stdin: reader})
+}
+
+// downloadDir copies the directory src on the server to the local dst, or into dst if
+// it is an existing directory
+func downloadDir(ctx context.Context, client ssh.Client, src, dst string) error {
+	strip := true
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		strip = false
+	}
+
+	reader, writer := io.Pipe()
+	extracted := make(chan error, 1)
+	go func() {
+		err := extractTar(reader, dst, strip)
+		// Unblock the remote tar if extraction stopped early
+		reader.CloseWithError(err)
+		extracted <- err
+	}()
+
+	src = path.Clean(src)
+	command := fmt.Sprintf("cd %s && tar -cf - -- %s", shellQuote(path.Dir(src)), shellQuote(path.Base(src)))
+	err := runCopyCommand(ctx, client, command, ssh.Stdio{Stdout: writer})
+	writer.CloseWithError(err)
+	if extractErr := <-extracted; err == nil {
+		err = extractErr
+	}
+	return err
+}
+
+// runCopyCommand runs a command of a copy, reporting its stderr on failure
+func runCopyCommand(ctx context.Context, client ssh.Client, command string, stdio ssh.Stdio) error {
+	var stderr bytes.Buffer
+	stdio.Stderr = &stderr
+	if err := client.Run(ctx, command, stdio, nil); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("copy failed: %s", msg)
+		}
+		return fmt.Errorf("copy failed: %w", remoteExitError(err))
+	}
+	return nil
+}
+
+// writeTar writes the directory root to w as a tar archive whose entries start with the
+// name of root
+func writeTar(w io.Writer, root string) error {
+	tw := tar.NewWriter(w)
+	base := filepath.Base(filepath.Clean(root))
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			// Sockets and other special files cannot be archived
+			return nil
+		}
+		header.Name = path.Join(base, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts the tar archive read from r into dir, dropping the first path
+// component of the entries when strip is set. Entries, and symlinks extracted earlier,
+// cannot place files outside dir.
+func extractTar(r io.Reader, dir string, strip bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if strip {
+			_, rest, ok := strings.Cut(name, "/")
+			if !ok {
+				continue
+			}
+			name = rest
+		}
+		name = filepath.FromSlash(name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("refusing to extract %s outside %s", header.Name, dir)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirAll(root, name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := mkdirAll(root, filepath.Dir(name)); err != nil {
+				return err
+			}
+			file, err := root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := mkdirAll(root, filepath.Dir(name)); err != nil {
+				return err
+			}
+			// The link is created through the parent, which must resolve inside root
+			if _, err := root.Stat(filepath.Dir(name)); err != nil {
+				return err
+			}
+			_ = root.Remove(name)
+			if err := os.Symlink(header.Linkname, filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// mkdirAll creates the directory name and its parents in root. Existing directories
+// reached through symlinks must lie in root.
+func mkdirAll(root *os.Root, name string) error {
+	if name == "." {
+		return nil
+	}
+	if info, err := root.Stat(name); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", name)
+		}
+		return nil
+	}
+	if err := mkdirAll(root, filepath.Dir(name)); err != nil {
+		return err
+	}
+	return root.Mkdir(name, 0755)
+}
+
+// shellQuote quotes value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
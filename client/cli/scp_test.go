@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyClient records the commands run on it, consuming stdin and answering with output
+type copyClient struct {
+	ssh.Client
+	commands []string
+	stdin    []byte
+	output   []byte
+}
+
+func (c *copyClient) Run(ctx context.Context, command string, stdio ssh.Stdio, pty *ssh.PTY) error {
+	c.commands = append(c.commands, command)
+	if stdio.Stdin != nil {
+		data, err := io.ReadAll(stdio.Stdin)
+		if err != nil {
+			return err
+		}
+		c.stdin = data
+	}
+	if stdio.Stdout != nil {
+		_, err := stdio.Stdout.Write(c.output)
+		return err
+	}
+	return nil
+}
+
+func TestRemotePath(t *testing.T) {
+	p, ok := remotePath(":/srv/app")
+	assert.True(t, ok)
+	assert.Equal(t, "/srv/app", p)
+
+	p, ok = remotePath(":")
+	assert.True(t, ok)
+	assert.Equal(t, ".", p)
+
+	_, ok = remotePath("./local")
+	assert.False(t, ok)
+}
+
+func TestRunSCPNeedsOneRemotePath(t *testing.T) {
+	err := runSCP(context.Background(), "", "", "a", "b", false, io.Discard)
+	assert.ErrorContains(t, err, "exactly one")
+
+	err = runSCP(context.Background(), "", "", ":a", ":b", false, io.Discard)
+	assert.ErrorContains(t, err, "exactly one")
+}
+
+func TestUploadFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "it's.txt")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0644))
+
+	client := &copyClient{}
+	require.NoError(t, uploadFile(context.Background(), client, src, "/srv/app"))
+
+	assert.Equal(t, []byte("hello"), client.stdin)
+	assert.Equal(t, []string{`dst='/srv/app'; [ -d "$dst" ] && dst="$dst"/'it'\''s.txt'; cat > "$dst"`}, client.commands)
+}
+
+func TestDownloadFileIntoDirectory(t *testing.T) {
+	dir := t.TempDir()
+	client := &copyClient{output: []byte("log line\n")}
+	require.NoError(t, downloadFile(context.Background(), client, "/var/log/dockbridge-setup.log", dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "dockbridge-setup.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "log line\n", string(data))
+	assert.Equal(t, []string{"cat -- '/var/log/dockbridge-setup.log'"}, client.commands)
+}
+
+func TestTarRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0600))
+
+	var archive bytes.Buffer
+	require.NoError(t, writeTar(&archive, src))
+
+	// Into an existing directory, the source directory is kept
+	into := t.TempDir()
+	require.NoError(t, extractTar(bytes.NewReader(archive.Bytes()), into, false))
+	data, err := os.ReadFile(filepath.Join(into, "data", "sub", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+
+	// To a new directory, its contents are copied
+	to := filepath.Join(t.TempDir(), "copy")
+	require.NoError(t, extractTar(bytes.NewReader(archive.Bytes()), to, true))
+	data, err = os.ReadFile(filepath.Join(to, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+	info, err := os.Stat(filepath.Join(to, "sub", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestExtractTarStaysInDirectory(t *testing.T) {
+	outside := t.TempDir()
+
+	// A file written through an earlier symlink must not land outside
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data/escape", Typeflag: tar.TypeSymlink, Linkname: outside}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data/escape/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 1}))
+	_, err := tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	assert.Error(t, extractTar(bytes.NewReader(archive.Bytes()), t.TempDir(), true))
+	_, err = os.Stat(filepath.Join(outside, "pwned"))
+	assert.True(t, os.IsNotExist(err))
+
+	archive.Reset()
+	tw = tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data/../../pwned", Typeflag: tar.TypeReg, Mode: 0644}))
+	require.NoError(t, tw.Close())
+	assert.ErrorContains(t, extractTar(bytes.NewReader(archive.Bytes()), t.TempDir(), false), "outside")
+}
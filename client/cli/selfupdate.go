@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+	"github.com/dockbridge/dockbridge/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update DockBridge to the latest release",
+	Long: `Download the latest DockBridge release from GitHub, or the one given with --version,
+verify it against the checksums published with the release and replace this binary
+with it. Restart a running daemon afterwards, e.g. with "dockbridge down && dockbridge up".
+Servers keep their dockbridge-server until "dockbridge server update".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tag, _ := cmd.Flags().GetString("version")
+		check, _ := cmd.Flags().GetBool("check")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return selfUpdate(cmd.Context(), selfupdate.NewClient(), tag, check, yes, cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().String("version", "", "Release to install, e.g. v1.2.3 (default: the latest)")
+	selfUpdateCmd.Flags().Bool("check", false, "Only report whether an update is available")
+	selfUpdateCmd.Flags().BoolP("yes", "y", false, "Update without confirmation")
+}
+
+// selfUpdate replaces the running binary with the release tagged tag, or with the
+// latest release when it is newer
+func selfUpdate(ctx context.Context, updater *selfupdate.Client, tag string, check, yes bool, in io.Reader, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var release *selfupdate.Release
+	var err error
+	if tag != "" {
+		release, err = updater.ByTag(ctx, "v"+strings.TrimPrefix(tag, "v"))
+	} else {
+		release, err = updater.Latest(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if tag == "" && version.Compare(release.Version(), version.Version) <= 0 {
+		fmt.Fprintf(out, "DockBridge %s is up to date.\n", version.Tag())
+		return nil
+	}
+	if check {
+		fmt.Fprintf(out, "DockBridge %s is available (installed: %s). Install it with \"dockbridge self-update\".\n", release.Tag, version.Tag())
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the dockbridge binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	if !yes {
+		fmt.Fprintf(out, "Replace DockBridge %s at %s with %s? (y/N): ", version.Tag(), executable, release.Tag)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "y" && answer != "Y" {
+			fmt.Fprintln(out, "Update cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Fprintf(out, "Downloading DockBridge %s...\n", release.Tag)
+	binary, err := updater.Download(ctx, release, selfupdate.BinaryName(runtime.GOOS, runtime.GOARCH))
+	if err != nil {
+		return err
+	}
+	if updater.PublicKey == "" {
+		fmt.Fprintln(out, "Note: this build has no release signing key; only the checksum was verified.")
+	}
+	if err := selfupdate.Install(executable, binary); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Updated DockBridge %s -> %s. Restart a running daemon to use it.\n", version.Tag(), release.Tag)
+	return nil
+}
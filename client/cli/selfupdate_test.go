@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+	"github.com/dockbridge/dockbridge/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latestReleaseClient returns an updater whose latest release is tagged tag
+func latestReleaseClient(t *testing.T, tag string) *selfupdate.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&selfupdate.Release{Tag: tag})
+	}))
+	t.Cleanup(server.Close)
+	return &selfupdate.Client{HTTPClient: server.Client(), APIURL: server.URL}
+}
+
+func TestSelfUpdateUpToDate(t *testing.T) {
+	var out bytes.Buffer
+	err := selfUpdate(context.Background(), latestReleaseClient(t, version.Tag()), "", false, false, nil, &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "is up to date")
+}
+
+func TestSelfUpdateCheck(t *testing.T) {
+	var out bytes.Buffer
+	err := selfUpdate(context.Background(), latestReleaseClient(t, "v999.0.0"), "", true, false, nil, &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "DockBridge v999.0.0 is available")
+}
+
+func TestSelfUpdateCancelled(t *testing.T) {
+	var out bytes.Buffer
+	err := selfUpdate(context.Background(), latestReleaseClient(t, "v999.0.0"), "", false, false, strings.NewReader("n\n"), &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Update cancelled.")
+}
+
+func TestDescribeServerVersion(t *testing.T) {
+	assert.Equal(t, version.Tag(), describeServerVersion(version.Version))
+	assert.Contains(t, describeServerVersion(""), "unknown, differs")
+	assert.Contains(t, describeServerVersion("0.0.1"), "v0.0.1, differs")
+}
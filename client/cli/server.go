@@ -1,14 +1,30 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/dockertls"
 	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/osupdates"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/client/traffic"
+	"github.com/dockbridge/dockbridge/client/usage"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
 
 	"github.com/dockbridge/dockbridge/pkg/errors"
 	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/pkg/version"
 	"github.com/spf13/cobra"
 )
 
@@ -36,16 +52,19 @@ var serverCreateCmd = &cobra.Command{
 var serverDestroyCmd = &cobra.Command{
 	Use:   "destroy",
 	Short: "Destroy DockBridge servers",
-	Long:  `Destroy all DockBridge servers while preserving volumes for future use.`,
+	Long: `Destroy all DockBridge servers created by this client while preserving volumes for
+future use. With --force-foreign servers created by other clients sharing the project, or
+by DockBridge versions without labels, are destroyed as well.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, _ := cmd.Flags().GetString("config")
 		force, _ := cmd.Flags().GetBool("force")
+		forceForeign, _ := cmd.Flags().GetBool("force-foreign")
 
 		// Initialize logger
 		log := logger.NewDefault()
 		_ = log // Use logger if needed
 
-		return destroyServer(cmd.Context(), configPath, force)
+		return destroyServer(cmd.Context(), configPath, force, forceForeign)
 	},
 }
 
@@ -64,6 +83,60 @@ var serverStatusCmd = &cobra.Command{
 	},
 }
 
+var serverRecommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Recommend a server type based on observed usage",
+	Long: `Recommend a cheaper or larger server type based on CPU, memory and disk usage
+sampled from your servers while the DockBridge client was running.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return recommendServerType(configPath, contextName)
+	},
+}
+
+var serverUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Install this client's dockbridge-server version on the server",
+	Long: `Compare the dockbridge-server binary of the context's running server with this
+client's version and, after confirmation, replace it with the matching release binary.
+The binary is downloaded and verified here, copied over SSH and the service restarted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return updateServerBinary(cmd.Context(), configPath, contextName, yes, cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+var serverShareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Show how other machines can use and keep alive this client's servers",
+	Long: `Print this client's owner ID and how another machine, such as a second laptop or a
+CI runner, joins its servers. A joined machine selects the same servers, fetches their
+keep-alive token over SSH on first connect and sends heartbeats under its own client ID,
+so a server is only destroyed once every machine is gone.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printShareInstructions(cmd.OutOrStdout(), provider.LocalOwner())
+	},
+}
+
+// printShareInstructions writes how another machine joins the servers of owner
+func printShareInstructions(w io.Writer, owner provider.Owner) error {
+	_, err := fmt.Fprintf(w, `Owner ID: %s
+
+To use and keep alive these servers from another machine, run DockBridge there with
+the same configuration and SSH key (ssh.key_path) and:
+
+  export %s=%s
+
+The keep-alive token is fetched over SSH when the machine first connects.
+`, owner.ID, provider.OwnerEnv, owner.ID)
+	return err
+}
+
 func init() {
 	rootCmd.AddCommand(serverCmd)
 
@@ -71,6 +144,9 @@ func init() {
 	serverCmd.AddCommand(serverCreateCmd)
 	serverCmd.AddCommand(serverDestroyCmd)
 	serverCmd.AddCommand(serverStatusCmd)
+	serverCmd.AddCommand(serverRecommendCmd)
+	serverCmd.AddCommand(serverUpdateCmd)
+	serverCmd.AddCommand(serverShareCmd)
 
 	// Add flags
 	serverCreateCmd.Flags().StringP("config", "c", "", "Path to configuration file")
@@ -79,9 +155,17 @@ func init() {
 	serverDestroyCmd.Flags().StringP("config", "c", "", "Path to configuration file")
 	serverDestroyCmd.Flags().String("log-config", "", "Path to logger configuration file")
 	serverDestroyCmd.Flags().BoolP("force", "f", false, "Force destruction without confirmation")
+	serverDestroyCmd.Flags().Bool("force-foreign", false, "Also destroy servers created by other clients")
 
 	serverStatusCmd.Flags().StringP("config", "c", "", "Path to configuration file")
 	serverStatusCmd.Flags().String("log-config", "", "Path to logger configuration file")
+
+	serverRecommendCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	serverRecommendCmd.Flags().String("context", "", "Context to analyze (default context if empty)")
+
+	serverUpdateCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	serverUpdateCmd.Flags().String("context", "", "Context whose server to update (default: the current context)")
+	serverUpdateCmd.Flags().BoolP("yes", "y", false, "Update without confirmation")
 }
 
 func createServer(ctx context.Context, configPath string) error {
@@ -150,10 +234,11 @@ func createServer(ctx context.Context, configPath string) error {
 	return nil
 }
 
-func destroyServer(ctx context.Context, configPath string, force bool) error {
+func destroyServer(ctx context.Context, configPath string, force, forceForeign bool) error {
 	log := logger.GlobalWithFields(map[string]any{
-		"operation": "server_destroy",
-		"force":     force,
+		"operation":     "server_destroy",
+		"force":         force,
+		"force_foreign": forceForeign,
 	})
 
 	log.Info("Starting server destruction process")
@@ -169,7 +254,8 @@ func destroyServer(ctx context.Context, configPath string, force bool) error {
 
 	// Validate Hetzner API token
 	if cfg.Hetzner.APIToken == "" {
-		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Hetzner API token is required", nil)
+		return errors.NewConfigError(errors.ErrCodeMissingAPIToken, "Hetzner API token is required", nil).
+			WithHint(`Run "dockbridge auth login" or set HETZNER_API_TOKEN`)
 	}
 
 	// Create Hetzner client
@@ -186,19 +272,25 @@ func destroyServer(ctx context.Context, configPath string, force bool) error {
 		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to create Hetzner client", err)
 	}
 
-	// List servers to find DockBridge servers
-	servers, err := client.ListServers(ctx)
+	// Find this client's DockBridge servers by their labels
+	registry := provider.NewServerRegistry(client)
+	owned, err := registry.List(ctx)
+	if err != nil {
+		errors.LogError(err, "Failed to list servers")
+		return errors.NewNetworkError("API_ERROR", "Failed to list servers", err, true)
+	}
+	foreign, err := registry.Foreign(ctx)
 	if err != nil {
 		errors.LogError(err, "Failed to list servers")
 		return errors.NewNetworkError("API_ERROR", "Failed to list servers", err, true)
 	}
 
-	// Filter for DockBridge servers
-	var dockbridgeServers []*hetzner.Server
-	for _, server := range servers {
-		if len(server.Name) >= 10 && server.Name[:10] == "dockbridge" {
-			dockbridgeServers = append(dockbridgeServers, server)
-		}
+	dockbridgeServers := flattenServers(owned)
+	foreignServers := flattenServers(foreign)
+	if forceForeign {
+		dockbridgeServers = append(dockbridgeServers, foreignServers...)
+	} else if len(foreignServers) > 0 {
+		fmt.Printf("Skipping %d DockBridge server(s) created by other clients; use --force-foreign to destroy them.\n", len(foreignServers))
 	}
 
 	if len(dockbridgeServers) == 0 {
@@ -242,6 +334,12 @@ func destroyServer(ctx context.Context, configPath string, force bool) error {
 
 		log.WithField("server_id", server.ID).Info("Server destroyed successfully")
 		fmt.Printf("Server %s destroyed successfully.\n", server.Name)
+
+		// The address will be reused for other servers
+		if server.IPAddress != "" {
+			_, _ = knownHostsFor(&cfg.SSH).Remove(hostWithPort(server.IPAddress, cfg.SSH.Port))
+		}
+		_ = dockertls.NewStore(expandHomePath(cfg.Docker.TLS.CertDir)).Remove(server.Name)
 	}
 
 	log.Info("Server destruction process completed, volumes preserved")
@@ -266,7 +364,8 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 
 	// Validate Hetzner API token
 	if cfg.Hetzner.APIToken == "" {
-		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Hetzner API token is required", nil)
+		return errors.NewConfigError(errors.ErrCodeMissingAPIToken, "Hetzner API token is required", nil).
+			WithHint(`Run "dockbridge auth login" or set HETZNER_API_TOKEN`)
 	}
 
 	fmt.Println("Checking server status...")
@@ -285,20 +384,13 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to create Hetzner client", err)
 	}
 
-	// List all servers to find DockBridge servers
-	servers, err := client.ListServers(ctx)
+	// List this client's DockBridge servers by their labels
+	owned, err := provider.NewServerRegistry(client).List(ctx)
 	if err != nil {
 		errors.LogError(err, "Failed to list servers")
 		return errors.NewNetworkError("API_ERROR", "Failed to list servers", err, true)
 	}
-
-	// Filter for DockBridge servers
-	var dockbridgeServers []*hetzner.Server
-	for _, server := range servers {
-		if len(server.Name) >= 10 && server.Name[:10] == "dockbridge" { // Check if server name starts with "dockbridge"
-			dockbridgeServers = append(dockbridgeServers, server)
-		}
-	}
+	dockbridgeServers := flattenServers(owned)
 
 	if len(dockbridgeServers) == 0 {
 		fmt.Println("No DockBridge servers found.")
@@ -306,6 +398,13 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 		return nil
 	}
 
+	// Estimated spend accrued by the daemon; missing state only hides the cost lines
+	month := cost.Month(time.Now())
+	var monthSpend []cost.ServerSpend
+	if path, err := cost.DefaultStorePath(); err == nil {
+		monthSpend, _ = cost.NewStore(path).Month(month)
+	}
+
 	// Display status for each DockBridge server
 	for i, server := range dockbridgeServers {
 		if i > 0 {
@@ -318,6 +417,37 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 		fmt.Printf("  IP Address: %s\n", server.IPAddress)
 		fmt.Printf("  Created: %s\n", server.CreatedAt.Format("2006-01-02 15:04:05"))
 
+		if server.IncludedTraffic > 0 {
+			usage := traffic.Usage{
+				Outgoing: server.OutgoingTraffic,
+				Ingoing:  server.IngoingTraffic,
+				Included: server.IncludedTraffic,
+			}
+			fmt.Printf("  Traffic: %s\n", usage)
+			if level := usage.Level(float64(cfg.Traffic.WarnPercent)/100, float64(cfg.Traffic.CriticalPercent)/100); level != traffic.LevelOK {
+				fmt.Printf("  ⚠️  Traffic %s: outgoing traffic beyond the included allowance is billed as overage\n", level)
+			}
+		}
+
+		for _, spend := range monthSpend {
+			if spend.ServerID == server.ID {
+				fmt.Printf("  Estimated Cost: %.2f %s this month (%.4f %s/hour)\n", spend.Amount, spend.Currency, spend.HourlyRate, spend.Currency)
+			}
+		}
+
+		if server.Status == "running" && server.IPAddress != "" {
+			if status, err := queryRebootStatus(ctx, &cfg.SSH, server.IPAddress); err != nil {
+				fmt.Printf("  OS Updates: unknown (%v)\n", err)
+			} else {
+				fmt.Printf("  OS Updates: %s\n", status)
+			}
+			if info, err := queryServerInfo(ctx, &cfg.SSH, server.IPAddress); err != nil {
+				fmt.Printf("  Server Version: unknown (%v)\n", err)
+			} else {
+				fmt.Printf("  Server Version: %s\n", describeServerVersion(info.Version))
+			}
+		}
+
 		if server.VolumeID != "" {
 			// Get volume information
 			volume, err := client.GetVolume(ctx, server.VolumeID)
@@ -335,14 +465,17 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 		}).Info("Server status retrieved")
 	}
 
+	printMonthSpend(monthSpend, cfg.Budget)
+
 	// List volumes
 	volumes, err := client.ListVolumes(ctx)
 	if err != nil {
 		log.WithFields(map[string]any{"error": err.Error()}).Warn("Failed to list volumes")
 	} else {
+		owner := provider.LocalOwner()
 		var dockbridgeVolumes []*hetzner.Volume
 		for _, volume := range volumes {
-			if len(volume.Name) >= 10 && volume.Name[:10] == "dockbridge" {
+			if owner.Owns(volume.Labels) {
 				dockbridgeVolumes = append(dockbridgeVolumes, volume)
 			}
 		}
@@ -357,3 +490,227 @@ func checkServerStatus(ctx context.Context, configPath string) error {
 
 	return nil
 }
+
+// flattenServers returns the servers of all contexts ordered by name
+func flattenServers(byContext map[string][]*provider.Server) []*provider.Server {
+	var servers []*provider.Server
+	for _, contextServers := range byContext {
+		servers = append(servers, contextServers...)
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	return servers
+}
+
+// printMonthSpend prints the estimated spend of all servers this month against the budget
+func printMonthSpend(spend []cost.ServerSpend, budgetCfg sharedconfig.BudgetConfig) {
+	if len(spend) == 0 {
+		return
+	}
+
+	var total float64
+	for _, entry := range spend {
+		total += entry.Amount
+	}
+	currency := spend[0].Currency
+
+	budget := cost.Budget{Monthly: budgetCfg.Monthly, WarnFraction: float64(budgetCfg.WarnPercent) / 100}
+	if !budget.Enabled() {
+		fmt.Printf("\nEstimated spend this month: %.2f %s\n", total, currency)
+		return
+	}
+	fmt.Printf("\nEstimated spend this month: %.2f of %.2f %s budget (%.0f%%)\n", total, budget.Monthly, currency, total/budget.Monthly*100)
+	switch budget.Level(total) {
+	case cost.LevelExceeded:
+		if budgetCfg.Action == "block" {
+			fmt.Println("  ⚠️  Budget exceeded: new servers will not be provisioned this month")
+		} else {
+			fmt.Println("  ⚠️  Budget exceeded")
+		}
+	case cost.LevelWarning:
+		fmt.Println("  ⚠️  Approaching the monthly budget")
+	}
+}
+
+// queryRebootStatus checks over SSH whether a server needs a reboot to finish applying OS updates
+func queryRebootStatus(ctx context.Context, sshCfg *sharedconfig.SSHConfig, host string) (osupdates.RebootStatus, error) {
+	client := ssh.NewClient(&ssh.ClientConfig{
+		Host:            host,
+		Port:            sshCfg.Port,
+		User:            "root",
+		PrivateKeyPath:  expandHomePath(sshCfg.KeyPath),
+		Timeout:         10 * time.Second,
+		UseAgent:        sshCfg.UseAgent,
+		AgentSocket:     sshCfg.AgentSocket,
+		KnownHostsPath:  expandHomePath(sshCfg.KnownHostsPath),
+		HostKeyChecking: sshCfg.HostKeyChecking,
+		JumpHost:        sshCfg.JumpHost,
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return osupdates.RebootStatus{}, err
+	}
+	defer client.Close()
+
+	output, err := client.ExecuteCommand(ctx, osupdates.RebootStatusCommand)
+	if err != nil {
+		return osupdates.RebootStatus{}, err
+	}
+	return osupdates.ParseRebootStatus(string(output)), nil
+}
+
+// queryServerInfo inspects the dockbridge-server installation of the server at host
+func queryServerInfo(ctx context.Context, sshCfg *sharedconfig.SSHConfig, host string) (*selfupdate.ServerInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	client := newServerSSHClient(sshCfg, host)
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return selfupdate.InspectServer(ctx, client)
+}
+
+// describeServerVersion describes a dockbridge-server version relative to this client
+func describeServerVersion(serverVersion string) string {
+	switch {
+	case serverVersion == version.Version:
+		return "v" + serverVersion
+	case serverVersion == "":
+		return fmt.Sprintf(`unknown, differs from the client's %s (update it with "dockbridge server update")`, version.Tag())
+	default:
+		return fmt.Sprintf(`v%s, differs from the client's %s (update it with "dockbridge server update")`, serverVersion, version.Tag())
+	}
+}
+
+// updateServerBinary replaces the dockbridge-server binary of a context's server with
+// the release of this client's version
+func updateServerBinary(ctx context.Context, configPath, contextName string, yes bool, in io.Reader, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client, srv, err := connectServer(ctx, configPath, contextName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	info, err := selfupdate.InspectServer(ctx, client)
+	if err != nil {
+		return err
+	}
+	if info.Version == version.Version {
+		fmt.Fprintf(out, "dockbridge-server on %s is up to date (%s).\n", srv.Name, version.Tag())
+		return nil
+	}
+
+	fmt.Fprintf(out, "dockbridge-server on %s: %s\n", srv.Name, describeServerVersion(info.Version))
+	if !yes {
+		fmt.Fprintf(out, "Install dockbridge-server %s and restart it? (y/N): ", version.Tag())
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "y" && answer != "Y" {
+			fmt.Fprintln(out, "Update cancelled.")
+			return nil
+		}
+	}
+
+	updater := selfupdate.NewClient()
+	release, err := updater.ByTag(ctx, version.Tag())
+	if err != nil {
+		return err
+	}
+	binary, err := updater.Download(ctx, release, selfupdate.ServerBinaryName(info.Arch))
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.UpdateServer(ctx, client, binary); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Installed dockbridge-server %s on %s.\n", version.Tag(), srv.Name)
+	return nil
+}
+
+func recommendServerType(configPath, contextName string) error {
+	// Load configuration
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		errors.LogError(err, "Failed to load configuration")
+		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to load configuration", err)
+	}
+
+	cfg := manager.GetConfig()
+
+	serverType := cfg.Hetzner.ServerType
+	if contextName != "" {
+		found := false
+		for _, contextCfg := range cfg.Contexts {
+			if contextCfg.Name == contextName {
+				serverType = contextCfg.SettingsFor(cfg.ServerSettings()).ServerType
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.NewConfigError(errors.ErrCodeInvalidConfig, fmt.Sprintf("Unknown context %q", contextName), nil)
+		}
+	}
+
+	path, err := usage.DefaultStorePath()
+	if err != nil {
+		return err
+	}
+	samples, err := usage.NewStore(path).Load()
+	if err != nil {
+		return err
+	}
+
+	summary := usage.Summarize(samples, contextName, serverType, usage.DefaultSampleInterval)
+	printRecommendation(os.Stdout, summary)
+	return nil
+}
+
+// printRecommendation prints the usage summary and server type recommendation
+func printRecommendation(w io.Writer, summary *usage.Summary) {
+	fmt.Fprintf(w, "Server type: %s\n", summary.ServerType)
+
+	rec, err := usage.Recommend(summary)
+	if err != nil {
+		fmt.Fprintf(w, "No recommendation: %v\n", err)
+		if summary.Samples < usage.MinSamplesForRecommendation {
+			fmt.Fprintln(w, "Keep `dockbridge start` running while you work; usage is sampled every few minutes.")
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "Observed: %d samples over %s (~%.0f server hours)\n",
+		summary.Samples, summary.Window.Round(time.Hour), summary.ObservedHours)
+	fmt.Fprintf(w, "  CPU: %.0f%% p95, %.0f%% peak of %d cores\n", summary.P95CPUPercent, summary.PeakCPUPercent, summary.CPUCores)
+	fmt.Fprintf(w, "  Memory: %d MB peak of %d MB\n", summary.PeakMemoryMB, summary.MemoryTotalMB)
+	fmt.Fprintf(w, "  Disk: %.1f GB used of %.1f GB\n", summary.DiskUsedGB, summary.DiskTotalGB)
+	fmt.Fprintln(w)
+
+	switch rec.Action {
+	case usage.ActionKeep:
+		fmt.Fprintf(w, "✅ Keep %s: %s\n", rec.Current.Name, rec.Reason)
+	case usage.ActionDownsize:
+		fmt.Fprintf(w, "💡 Switch to %s: %s\n", rec.Suggested.Name, rec.Reason)
+		fmt.Fprintf(w, "   Estimated savings: €%.2f/month at your observed usage (€%.2f/month if running full time)\n",
+			rec.ObservedMonthlySavings, rec.MonthlySavings)
+	case usage.ActionUpsize:
+		fmt.Fprintf(w, "⚠️  Switch to %s: %s\n", rec.Suggested.Name, rec.Reason)
+		fmt.Fprintf(w, "   Estimated extra cost: €%.2f/month at your observed usage\n", -rec.ObservedMonthlySavings)
+	}
+	if rec.Action != usage.ActionKeep {
+		fmt.Fprintf(w, "   Apply with: dockbridge config set hetzner.server_type %s\n", rec.Suggested.Name)
+	}
+	if rec.DiskWarning != "" {
+		fmt.Fprintf(w, "⚠️  %s\n", rec.DiskWarning)
+	}
+	fmt.Fprintln(w, "Prices are approximate Hetzner list prices excluding VAT.")
+}
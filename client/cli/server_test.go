@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/usage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServerCommand(t *testing.T) {
@@ -56,3 +61,36 @@ func TestServerStatusCommand(t *testing.T) {
 	// Check that the command has the expected flags
 	assert.NotNil(t, serverStatusCmd.Flags().Lookup("config"))
 }
+
+func TestServerRecommendCommand(t *testing.T) {
+	assert.Equal(t, "recommend", serverRecommendCmd.Name())
+	assert.NotNil(t, serverRecommendCmd.Flags().Lookup("config"))
+	assert.NotNil(t, serverRecommendCmd.Flags().Lookup("context"))
+}
+
+func TestPrintShareInstructions(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, printShareInstructions(&buf, provider.Owner{ID: "0123abcd"}))
+	assert.Contains(t, buf.String(), "Owner ID: 0123abcd")
+	assert.Contains(t, buf.String(), "export DOCKBRIDGE_OWNER=0123abcd")
+}
+
+func TestPrintRecommendation(t *testing.T) {
+	var buf bytes.Buffer
+	printRecommendation(&buf, &usage.Summary{ServerType: "cpx21", Samples: 2})
+	assert.Contains(t, buf.String(), "not enough usage data")
+
+	buf.Reset()
+	printRecommendation(&buf, &usage.Summary{
+		ServerType:     "cpx41",
+		Samples:        50,
+		Window:         48 * time.Hour,
+		ObservedHours:  10,
+		CPUCores:       8,
+		P95CPUPercent:  5,
+		PeakCPUPercent: 20,
+		PeakMemoryMB:   2000,
+	})
+	assert.Contains(t, buf.String(), "Switch to")
+	assert.Contains(t, buf.String(), "dockbridge config set hetzner.server_type")
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/service"
+	"github.com/spf13/cobra"
+)
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run the DockBridge daemon as a login service",
+	Long: `Install the DockBridge daemon as a per-user service that starts at login and is
+restarted when it fails: a systemd user unit on Linux, a launchd agent on macOS.`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install and start the service",
+	Long: `Install the service running "dockbridge start" at login and start it now. An
+installed service is replaced and restarted. The service uses the configuration file
+given with --config, or the default one, and the PATH of this shell to find docker.
+
+Output goes to the user journal on Linux and to ~/.dockbridge/logs/dockbridge.log on macOS.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		manager, err := newServiceManager()
+		if err != nil {
+			return err
+		}
+		return runServiceInstall(manager, configPath, cmd.OutOrStdout())
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newServiceManager()
+		if err != nil {
+			return err
+		}
+		if err := manager.Uninstall(); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "DockBridge service removed.")
+		return nil
+	},
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the service is installed and running",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newServiceManager()
+		if err != nil {
+			return err
+		}
+		return printServiceStatus(manager, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceInstallCmd, serviceUninstallCmd, serviceStatusCmd)
+}
+
+// newServiceManager returns the service manager of this platform; replaced in tests
+var newServiceManager = func() (service.Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return service.New(runtime.GOOS, homeDir, service.ExecRunner)
+}
+
+// runServiceInstall installs the service running this binary's start command
+func runServiceInstall(manager service.Manager, configPath string, out io.Writer) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the dockbridge binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	spec := service.Spec{
+		Executable: executable,
+		Args:       []string{"start"},
+		Env:        map[string]string{"PATH": os.Getenv("PATH")},
+	}
+	if configPath != "" {
+		// The service does not run in this directory
+		absPath, err := filepath.Abs(expandHomePath(configPath))
+		if err != nil {
+			return fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		spec.Args = append(spec.Args, "--config", absPath)
+	}
+	// The service runs with the profile selected at install time
+	if profile := os.Getenv(clientconfig.ProfileEnv); profile != "" {
+		spec.Env[clientconfig.ProfileEnv] = profile
+	}
+
+	if err := manager.Install(spec); err != nil {
+		return fmt.Errorf("failed to install service: %w", err)
+	}
+	fmt.Fprintln(out, "DockBridge service installed and started; it starts at every login.")
+	return printServiceStatus(manager, out)
+}
+
+// printServiceStatus prints the state of the service
+func printServiceStatus(manager service.Manager, out io.Writer) error {
+	status, err := manager.Status()
+	if err != nil {
+		return err
+	}
+	if !status.Installed {
+		fmt.Fprintln(out, "Service: not installed (install it with: dockbridge service install)")
+		return nil
+	}
+
+	state := "stopped"
+	if status.Running {
+		state = "running"
+	}
+	fmt.Fprintf(out, "Service: installed (%s)\n", status.Path)
+	fmt.Fprintf(out, "State:   %s\n", state)
+	fmt.Fprintf(out, "Logs:    %s\n", status.Logs)
+	return nil
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+var sshCmd = &cobra.Command{
+	Use:   "ssh [-- command...]",
+	Short: "Open a shell on the server or manage trusted host keys",
+	Long: `Open an interactive shell on the running server of the context, or run a command
+on it. The server address, SSH key, agent, jump host and host key checks are taken from
+the configuration, e.g.:
+  dockbridge ssh
+  dockbridge ssh -- journalctl -u docker --since "10 min ago"
+
+The subcommands manage the host keys DockBridge trusts for its servers. Keys are
+recorded in the known_hosts file on first connection and verified on every reconnect.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		tty, _ := cmd.Flags().GetBool("tty")
+		return runSSH(cmd.Context(), configPath, contextName, strings.Join(args, " "), tty)
+	},
+}
+
+var sshTrustCmd = &cobra.Command{
+	Use:   "trust <host[:port]>",
+	Short: "Fetch and trust a server's host key",
+	Long:  `Connect to a server, show its host key fingerprint and record it as trusted, replacing any previously trusted key.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return trustHostKey(configPath, args[0], yes, cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+var sshResetCmd = &cobra.Command{
+	Use:   "reset [host[:port]]",
+	Short: "Forget trusted host keys",
+	Long:  `Forget the trusted host key of a server, or of all servers with --all. The next connection records the key again.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) == 1) {
+			return fmt.Errorf("specify either a host or --all")
+		}
+		host := ""
+		if len(args) == 1 {
+			host = args[0]
+		}
+		return resetHostKeys(configPath, host, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sshCmd)
+
+	// Add subcommands
+	sshCmd.AddCommand(sshTrustCmd)
+	sshCmd.AddCommand(sshResetCmd)
+
+	// Add flags
+	sshCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	sshCmd.Flags().String("context", "", "Context whose server to connect to (default: the current context)")
+	sshCmd.Flags().BoolP("tty", "t", false, "Run the command in a pseudo-terminal, e.g. for interactive programs")
+
+	sshTrustCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	sshTrustCmd.Flags().BoolP("yes", "y", false, "Trust the key without confirmation")
+
+	sshResetCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	sshResetCmd.Flags().Bool("all", false, "Forget the host keys of all servers")
+}
+
+// connectServer connects to the running server of a context over SSH
+func connectServer(ctx context.Context, configPath, contextName string) (ssh.Client, *provider.Server, error) {
+	manager, _, err := loadContextConfig(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := manager.GetConfig()
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	srv, err := runningServer(ctx, cloudProvider, contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if srv == nil {
+		return nil, nil, errors.NewNotFoundError("No running server", nil).
+			WithHint(`Start one with "dockbridge up" or by running a Docker command`)
+	}
+
+	client := newServerSSHClient(&cfg.SSH, srv.IPAddress)
+	if err := client.Connect(ctx); err != nil {
+		return nil, nil, errors.NewTunnelError(errors.ErrCodeSSHConnect, fmt.Sprintf("Failed to connect to server %s", srv.Name), err).
+			WithHint(`Check the SSH key and host key settings with "dockbridge doctor"`)
+	}
+	return client, srv, nil
+}
+
+// runSSH runs command on the server of a context attached to the terminal, or opens a
+// login shell when command is empty
+func runSSH(ctx context.Context, configPath, contextName, command string, tty bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client, _, err := connectServer(ctx, configPath, contextName)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	stdio := ssh.Stdio{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	stdinFd := int(os.Stdin.Fd())
+	if (command == "" || tty) && term.IsTerminal(stdinFd) {
+		pty := &ssh.PTY{Term: os.Getenv("TERM"), Size: ssh.WindowSize{Width: 80, Height: 24}}
+		if pty.Term == "" {
+			pty.Term = "xterm-256color"
+		}
+		if width, height, err := term.GetSize(stdinFd); err == nil {
+			pty.Size = ssh.WindowSize{Width: width, Height: height}
+		}
+
+		state, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to set up the terminal: %w", err)
+		}
+		defer term.Restore(stdinFd, state)
+
+		resize, stop := watchWindowSize(stdinFd)
+		defer stop()
+		pty.Resize = resize
+		return remoteExitError(client.Run(ctx, command, stdio, pty))
+	}
+	return remoteExitError(client.Run(ctx, command, stdio, nil))
+}
+
+// remoteExitError describes the exit status of a remote command that failed
+func remoteExitError(err error) error {
+	var exitErr *cryptossh.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("remote command exited with status %d", exitErr.ExitStatus())
+	}
+	return err
+}
+
+// loadSSHConfig loads the SSH section of the client configuration
+func loadSSHConfig(configPath string) (*sharedconfig.SSHConfig, error) {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		errors.LogError(err, "Failed to load configuration")
+		return nil, errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to load configuration", err)
+	}
+	return &manager.GetConfig().SSH, nil
+}
+
+// knownHostsFor returns the known hosts store configured in sshCfg
+func knownHostsFor(sshCfg *sharedconfig.SSHConfig) *ssh.KnownHosts {
+	return ssh.NewKnownHosts(expandHomePath(sshCfg.KnownHostsPath))
+}
+
+// hostWithPort adds the configured SSH port to host unless it has one
+func hostWithPort(host string, port int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// trustHostKey fetches the host key of host and records it after confirmation
+func trustHostKey(configPath, host string, yes bool, in io.Reader, out io.Writer) error {
+	sshCfg, err := loadSSHConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	addr := hostWithPort(host, sshCfg.Port)
+	key, err := fetchHostKey(addr, sshCfg.Timeout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Host key for %s:\n  %s %s\n", addr, key.Type(), cryptossh.FingerprintSHA256(key))
+	if !yes {
+		fmt.Fprint(out, "Trust this key? (y/N): ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "y" && answer != "Y" {
+			fmt.Fprintln(out, "Host key not trusted.")
+			return nil
+		}
+	}
+
+	knownHosts := knownHostsFor(sshCfg)
+	if err := knownHosts.Trust(addr, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Trusted host key for %s in %s\n", addr, knownHosts.Path())
+	return nil
+}
+
+// fetchHostKey performs an SSH handshake with addr far enough to capture its host key
+func fetchHostKey(addr string, timeout time.Duration) (cryptossh.PublicKey, error) {
+	var hostKey cryptossh.PublicKey
+	config := &cryptossh.ClientConfig{
+		User: "root",
+		HostKeyCallback: func(hostname string, remote net.Addr, key cryptossh.PublicKey) error {
+			hostKey = key
+			// Abort the handshake: only the key is needed
+			return fmt.Errorf("host key captured")
+		},
+		Timeout: timeout,
+	}
+
+	conn, err := cryptossh.Dial("tcp", addr, config)
+	if conn != nil {
+		conn.Close()
+	}
+	if hostKey == nil {
+		return nil, fmt.Errorf("failed to fetch host key from %s: %w", addr, err)
+	}
+	return hostKey, nil
+}
+
+// resetHostKeys forgets the key of host, or all keys when host is empty
+func resetHostKeys(configPath, host string, out io.Writer) error {
+	sshCfg, err := loadSSHConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	knownHosts := knownHostsFor(sshCfg)
+	if host == "" {
+		if err := knownHosts.Reset(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Forgot all trusted host keys.")
+		return nil
+	}
+
+	addr := hostWithPort(host, sshCfg.Port)
+	removed, err := knownHosts.Remove(addr)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Fprintf(out, "No trusted host key for %s.\n", addr)
+		return nil
+	}
+	fmt.Fprintf(out, "Forgot trusted host key for %s.\n", addr)
+	return nil
+}
+
+// expandHomePath expands a leading ~/ to the user's home directory
+func expandHomePath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return path
+}
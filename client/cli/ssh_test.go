@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func TestSSHCommand(t *testing.T) {
+	assert.Equal(t, "ssh", sshCmd.Name())
+
+	var hasTrust, hasReset bool
+	for _, cmd := range sshCmd.Commands() {
+		switch cmd.Name() {
+		case "trust":
+			hasTrust = true
+		case "reset":
+			hasReset = true
+		}
+	}
+
+	assert.True(t, hasTrust, "ssh command should have 'trust' subcommand")
+	assert.True(t, hasReset, "ssh command should have 'reset' subcommand")
+	assert.NotNil(t, sshResetCmd.Flags().Lookup("all"))
+	assert.NotNil(t, sshTrustCmd.Flags().Lookup("yes"))
+	assert.NotNil(t, sshCmd.Flags().Lookup("tty"))
+	assert.NotNil(t, sshCmd.Flags().Lookup("context"))
+}
+
+func TestRemoteExitError(t *testing.T) {
+	assert.NoError(t, remoteExitError(nil))
+
+	err := remoteExitError(&cryptossh.ExitError{Waitmsg: cryptossh.Waitmsg{}})
+	assert.EqualError(t, err, "remote command exited with status 0")
+
+	other := errors.New("connection lost")
+	assert.Equal(t, other, remoteExitError(other))
+}
+
+func TestHostWithPort(t *testing.T) {
+	assert.Equal(t, "203.0.113.7:22", hostWithPort("203.0.113.7", 22))
+	assert.Equal(t, "203.0.113.7:2222", hostWithPort("203.0.113.7:2222", 22))
+	assert.Equal(t, "[2001:db8::1]:22", hostWithPort("2001:db8::1", 22))
+}
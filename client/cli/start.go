@@ -1,18 +1,32 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/control"
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/daemonproc"
+	"github.com/dockbridge/dockbridge/client/daemonstate"
 	"github.com/dockbridge/dockbridge/client/docker"
-	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/dockercontext"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/client/metrics"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"github.com/dockbridge/dockbridge/client/usage"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/server"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
 	"github.com/spf13/cobra"
 )
 
@@ -20,32 +34,70 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the DockBridge client",
 	Long: `Start the DockBridge client which proxies Docker commands to a remote Hetzner server.
-The client will automatically provision a server if none exists.`,
+The client will automatically provision a server if none exists. It runs in the
+foreground until interrupted; "dockbridge up" runs it in the background instead.
+
+Use --profile (or DOCKBRIDGE_PROFILE) or --server-type/--location/--volume-size to
+run on a different server shape for this run without editing the configuration
+file, e.g.:
+  dockbridge start --server-type ccx33 --location hel1`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, _ := cmd.Flags().GetString("config")
-		return startClient(configPath)
+		opts := startOptions{}
+		opts.Profile, _ = cmd.Flags().GetString("profile")
+		opts.Override.ServerType, _ = cmd.Flags().GetString("server-type")
+		opts.Override.Location, _ = cmd.Flags().GetString("location")
+		opts.Override.VolumeSize, _ = cmd.Flags().GetInt("volume-size")
+		opts.Replace, _ = cmd.Flags().GetBool("replace")
+		return startClient(configPath, opts)
 	},
 }
 
+// startOptions are the per-run server shape overrides of the start command
+type startOptions struct {
+	// Profile names a configured profile; empty uses DOCKBRIDGE_PROFILE, if set
+	Profile string
+
+	// Override takes precedence over the profile; only the shape fields are used
+	Override sharedconfig.ProfileConfig
+
+	// Replace replaces a running server of a different type without asking
+	Replace bool
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	startCmd.Flags().String("server-type", "", "Server type for this run (overrides config and profile)")
+	startCmd.Flags().String("location", "", "Server location for this run (overrides config and profile)")
+	startCmd.Flags().Int("volume-size", 0, "Volume size in GB for this run (overrides config and profile)")
+	startCmd.Flags().Bool("replace", false, "Replace a running server of a different type without asking (the volume is preserved)")
 }
 
-func startClient(configPath string) error {
+func startClient(configPath string, opts startOptions) error {
 	fmt.Println("Starting DockBridge client...")
 
 	// Load configuration
 	manager := config.NewManager()
+	if opts.Profile != "" {
+		manager.SetProfile(opts.Profile)
+	}
 	if err := manager.Load(configPath); err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if profile := manager.ActiveProfile(); profile != "" {
+		fmt.Printf("Using profile %s\n", profile)
+	}
 
 	cfg := manager.GetConfig()
 
-	// Validate required configuration
-	if cfg.Hetzner.APIToken == "" {
-		return fmt.Errorf("hetzner API token is required: set it in the configuration file or via HETZNER_API_TOKEN environment variable")
+	// Server settings of the selected provider with the profile and flags applied
+	settings, err := manager.ServerSettingsFor("", opts.Override)
+	if err != nil {
+		return err
+	}
+	if settings.APIToken == "" {
+		return fmt.Errorf("%s API token is required: set it in the configuration file or via the provider's token environment variable", cfg.Provider)
 	}
 
 	// Create context with cancellation
@@ -63,24 +115,35 @@ func startClient(configPath string) error {
 
 	// Initialize logger
 	log := logger.NewDefault()
+	if level, err := logger.ParseLevel(cfg.Logging.Level); err == nil {
+		log.SetLevel(level)
+	}
+
+	// Keep recent log lines for the control API's StreamLogs
+	var logBuffer *control.LogBuffer
+	if cfg.Control.Enabled {
+		logBuffer = control.NewLogBuffer(control.DefaultLogBufferSize)
+		log.SetOutput(io.MultiWriter(os.Stdout, logBuffer))
+	}
 	log.Info("Initializing DockBridge client")
 
-	// Create Hetzner client
-	hetznerConfig := &hetzner.Config{
-		APIToken:        cfg.Hetzner.APIToken,
-		ServerType:      cfg.Hetzner.ServerType,
-		Location:        cfg.Hetzner.Location,
-		VolumeSize:      cfg.Hetzner.VolumeSize,
-		PreferredImages: cfg.Hetzner.PreferredImages,
+	// Create the cloud provider selected in the config
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return err
 	}
 
-	hetznerClient, err := hetzner.NewClient(hetznerConfig)
+	// Offer to replace a running server that does not match the requested type
+	replace, err := confirmServerReplacement(ctx, cloudProvider, settings.ServerType, opts.Replace, os.Stdin, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("failed to create Hetzner client: %w", err)
+		return err
 	}
 
+	// Server options with the shape of the selected provider
+	serverCfg := cfg.Hetzner.WithServerSettings(settings)
+
 	// Create server manager with enhanced volume management
-	serverManager := server.NewManager(hetznerClient, &cfg.Hetzner)
+	serverManager := server.NewManager(cloudProvider, &serverCfg)
 
 	// Ensure Docker data volume exists
 	fmt.Println("Ensuring Docker data volume exists...")
@@ -91,21 +154,94 @@ func startClient(configPath string) error {
 	fmt.Printf("Docker data volume ready: %s (Size: %dGB, Mount: %s)\n",
 		volume.Name, volume.Size, volume.MountPath)
 
+	// Trace Docker requests through the tunnel when telemetry is enabled
+	shutdownTracing, err := telemetry.Setup(ctx, &cfg.Telemetry, rootCmd.Version)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Failed to flush traces")
+		}
+	}()
+
+	// Usage samples feed `dockbridge server recommend`
+	usageStore := newUsageStore(log)
+
+	// Estimated spend feeds `dockbridge server status` and the monthly budget
+	costStore := newCostStore(log)
+
+	// The connected servers and forwards are resumed by the next daemon
+	stateStore := newStateStore(log)
+
+	// All daemons share one metrics registry; nil when the endpoint is disabled
+	var metricsRegistry *metrics.Registry
+	if cfg.Metrics.Enabled {
+		metricsRegistry = metrics.NewRegistry()
+	}
+
 	// Create DockBridge daemon configuration
 	daemonConfig := &docker.DaemonConfig{
-		SocketPath:     cfg.Docker.SocketPath,
-		HetznerClient:  hetznerClient,
-		SSHConfig:      &cfg.SSH,
-		HetznerConfig:  &cfg.Hetzner,
-		ActivityConfig: &cfg.Activity,
-		Logger:         log,
+		SocketPath:           cfg.Docker.SocketPath,
+		Provider:             cloudProvider,
+		SSHConfig:            &cfg.SSH,
+		HetznerConfig:        &serverCfg,
+		ActivityConfig:       &cfg.Activity,
+		KeepAlive:            &cfg.KeepAlive,
+		CacheTTL:             cfg.Docker.CacheTTL,
+		Notifications:        &cfg.Notifications.Desktop,
+		UsageStore:           usageStore,
+		Traffic:              &cfg.Traffic,
+		CostStore:            costStore,
+		StateStore:           stateStore,
+		Budget:               &cfg.Budget,
+		Backup:               &cfg.Backup,
+		Schedule:             &cfg.Schedule,
+		Provisioning:         &cfg.Provisioning,
+		Hooks:                cfg.Hooks,
+		NotificationSinks:    cfg.Notifications.Sinks,
+		DockerTLS:            &cfg.Docker.TLS,
+		RemoteTransport:      cfg.Docker.RemoteTransport,
+		RequestQueue:         &cfg.Docker.RequestQueue,
+		RegistryAuth:         cfg.Docker.RegistryAuth,
+		RegistryMirror:       &cfg.Docker.RegistryMirror,
+		ServerBinary:         &cfg.ServerBinary,
+		BuildContextSync:     cfg.Docker.BuildContextSync,
+		Compression:          &cfg.Docker.Compression,
+		FileSync:             &cfg.FileSync,
+		LocalSemantics:       cfg.Docker.LocalSemantics,
+		TCPListener:          &cfg.Docker.TCPListener,
+		PortForward:          &cfg.PortForward,
+		ProvisioningObserver: printProvisioningProgress(os.Stdout, ""),
+		Metrics:              metricsRegistry,
+		Logger:               log,
+	}
+	if replace {
+		daemonConfig.ServerReplacement = func(*provider.Server, string) bool { return true }
+	}
+
+	// Additional contexts each get their own daemon, socket, server and lifecycle
+	contextConfigs, err := contextDaemonConfigs(cfg, usageStore, costStore, stateStore, metricsRegistry, log)
+	if err != nil {
+		return err
 	}
 
 	// Create and start DockBridge daemon
 	daemon := docker.NewDockBridgeDaemon()
 
 	fmt.Printf("Starting DockBridge daemon on socket: %s\n", cfg.Docker.SocketPath)
-	fmt.Printf("Using Hetzner server type: %s in location: %s\n", cfg.Hetzner.ServerType, cfg.Hetzner.Location)
+	if listener := cfg.Docker.TCPListener; listener.Enabled {
+		tlsNote := ""
+		if listener.CertFile != "" {
+			tlsNote = " (TLS; set DOCKER_TLS_VERIFY=1 and DOCKER_CERT_PATH for the Docker CLI)"
+		}
+		fmt.Printf("Serving the Docker API on tcp://%s%s\n", listener.Listen, tlsNote)
+	}
+	fmt.Printf("Using %s server type: %s in location: %s\n", cfg.Provider, serverCfg.ServerType, serverCfg.Location)
 	fmt.Printf("Activity-based lifecycle: idle timeout %v, connection timeout %v\n",
 		cfg.Activity.IdleTimeout, cfg.Activity.ConnectionTimeout)
 	fmt.Println("Servers will be provisioned automatically when Docker commands are executed.")
@@ -122,11 +258,94 @@ func startClient(configPath string) error {
 		return fmt.Errorf("failed to start DockBridge daemon: %w", err)
 	}
 
+	// "dockbridge down" finds the daemon by its PID file
+	if pidPath, err := daemonproc.PIDPath(); err == nil {
+		if err := daemonproc.WritePID(pidPath); err != nil {
+			log.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Failed to record daemon PID")
+		}
+		defer daemonproc.RemovePID(pidPath)
+	}
+
+	contextDaemons := make([]*docker.DockBridgeDaemon, 0, len(contextConfigs))
+	defer func() {
+		for i, d := range contextDaemons {
+			if err := d.Stop(); err != nil {
+				log.WithFields(map[string]any{
+					"context": contextConfigs[i].ContextName,
+					"error":   err.Error(),
+				}).Error("Error stopping context daemon")
+			}
+		}
+	}()
+
+	for _, contextConfig := range contextConfigs {
+		fmt.Printf("Starting context %q on socket: %s (server type: %s, location: %s)\n",
+			contextConfig.ContextName, contextConfig.SocketPath,
+			contextConfig.HetznerConfig.ServerType, contextConfig.HetznerConfig.Location)
+
+		contextDaemon := docker.NewDockBridgeDaemon()
+		if err := contextDaemon.Start(ctx, contextConfig); err != nil {
+			_ = daemon.Stop()
+			return fmt.Errorf("failed to start daemon for context %q: %w", contextConfig.ContextName, err)
+		}
+		contextDaemons = append(contextDaemons, contextDaemon)
+
+		if !cfg.Docker.Context.Register {
+			fmt.Printf("  docker context create %s --docker host=%s\n",
+				dockerContextName(contextConfig.ContextName), localsocket.DockerHost(contextConfig.SocketPath))
+		}
+	}
+
+	// Expose the sockets as Docker CLI contexts so no DOCKER_HOST export is needed
+	if cfg.Docker.Context.Register {
+		registration := registerDockerContexts(cfg, dockercontext.NewStore(dockercontext.DefaultConfigDir()), log)
+		defer unregisterDockerContexts(cfg, registration, log)
+		fmt.Printf("Registered Docker contexts: %s\n", strings.Join(registration.Names(), ", "))
+		if switched := registration.Switched(); switched != "" {
+			fmt.Printf("Docker CLI switched to context %q\n", switched)
+		}
+	}
+
+	// Serve the gRPC control API for editors, tray apps and CI tooling
+	allDaemons := append([]*docker.DockBridgeDaemon{daemon}, contextDaemons...)
+	controlServer, err := startControlServer(cfg, allDaemons, logBuffer, log)
+	if err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Control API disabled")
+	}
+
+	// Apply safe configuration changes on save, SIGHUP and "dockbridge reload"
+	reloader := &configReloader{path: manager.ConfigFileUsed(), current: cfg, log: log, daemons: allDaemons}
+	if reloader.path == "" {
+		reloader.path = configPath
+	}
+	if controlServer != nil {
+		controlServer.SetReloadFunc(reloader.reload)
+	}
+	watchConfigReloads(ctx, reloader)
+
+	// Serve Prometheus metrics of all daemons
+	var metricsServer *metrics.Server
+	if metricsRegistry != nil {
+		metricsServer = metrics.NewServer(metricsRegistry, log)
+		if err := metricsServer.Start(cfg.Metrics.Listen); err != nil {
+			log.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Metrics endpoint disabled")
+			metricsServer = nil
+		} else {
+			fmt.Printf("Metrics endpoint: http://%s/metrics\n", cfg.Metrics.Listen)
+		}
+	}
+
 	// Start lock detector (placeholder for actual implementation)
 	fmt.Println("Starting lock detector...")
 
-	// Start keep-alive client (placeholder for actual implementation)
-	fmt.Printf("Starting keep-alive client with interval: %s\n", cfg.KeepAlive.Interval)
+	// Heartbeats are sent by each context's daemon
+	fmt.Printf("Keep-alive heartbeats every %s\n", cfg.KeepAlive.Interval)
 
 	fmt.Println("DockBridge daemon started successfully!")
 	fmt.Println("Docker commands will be executed on remote Hetzner servers (provisioned on-demand)")
@@ -136,6 +355,13 @@ func startClient(configPath string) error {
 	<-ctx.Done()
 	fmt.Println("Shutting down DockBridge daemon...")
 
+	if controlServer != nil {
+		controlServer.Stop()
+	}
+	if metricsServer != nil {
+		metricsServer.Stop()
+	}
+
 	// Stop the daemon
 	if err := daemon.Stop(); err != nil {
 		log.WithFields(map[string]any{
@@ -146,3 +372,210 @@ func startClient(configPath string) error {
 	fmt.Println("DockBridge daemon stopped")
 	return nil
 }
+
+// confirmServerReplacement reports whether a running default-context server of a
+// different type than serverType should be replaced. Without a mismatched server it
+// returns replace; otherwise it asks unless replace is already set.
+func confirmServerReplacement(ctx context.Context, cloudProvider provider.CloudProvider, serverType string, replace bool, in io.Reader, out io.Writer) (bool, error) {
+	servers, err := provider.NewServerRegistry(cloudProvider).ForContext(ctx, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	for _, server := range servers {
+		if server.Status != "running" || !docker.ServerTypeMismatch(server, serverType) {
+			continue
+		}
+
+		fmt.Fprintf(out, "Server %s is running as %s, but %s was requested.\n", server.Name, server.ServerType, serverType)
+		if replace {
+			fmt.Fprintln(out, "It will be replaced; the Docker data volume is preserved.")
+			return true, nil
+		}
+
+		fmt.Fprint(out, "Replace it with a new server? The Docker data volume is preserved. (y/N): ")
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer == "y" || answer == "Y" {
+			return true, nil
+		}
+		fmt.Fprintf(out, "Keeping %s.\n", server.Name)
+		return false, nil
+	}
+
+	return replace, nil
+}
+
+// newCloudProvider creates the configured cloud provider for the given server settings
+func newCloudProvider(cfg *sharedconfig.ClientConfig, settings sharedconfig.ServerSettings) (provider.CloudProvider, error) {
+	cloudProvider, err := provider.New(cfg, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud provider: %w", err)
+	}
+	return cloudProvider, nil
+}
+
+// contextDaemonConfigs builds one daemon configuration per configured context.
+// Contexts share credentials, SSH and activity settings with the default daemon
+// but override the server shape and listen on their own socket.
+func contextDaemonConfigs(cfg *sharedconfig.ClientConfig, usageStore *usage.Store, costStore *cost.Store, stateStore *daemonstate.Store, metricsRegistry *metrics.Registry, log logger.LoggerInterface) ([]*docker.DaemonConfig, error) {
+	configs := make([]*docker.DaemonConfig, 0, len(cfg.Contexts))
+	for _, contextCfg := range cfg.Contexts {
+		settings := contextCfg.SettingsFor(cfg.ServerSettings())
+		hetznerCfg := cfg.Hetzner.WithServerSettings(settings)
+
+		cloudProvider, err := newCloudProvider(cfg, settings)
+		if err != nil {
+			return nil, fmt.Errorf("context %q: %w", contextCfg.Name, err)
+		}
+
+		configs = append(configs, &docker.DaemonConfig{
+			ContextName:          contextCfg.Name,
+			SocketPath:           contextCfg.SocketPath,
+			Provider:             cloudProvider,
+			SSHConfig:            &cfg.SSH,
+			HetznerConfig:        &hetznerCfg,
+			ActivityConfig:       &cfg.Activity,
+			KeepAlive:            &cfg.KeepAlive,
+			CacheTTL:             cfg.Docker.CacheTTL,
+			Notifications:        &cfg.Notifications.Desktop,
+			UsageStore:           usageStore,
+			Traffic:              &cfg.Traffic,
+			CostStore:            costStore,
+			StateStore:           stateStore,
+			Budget:               &cfg.Budget,
+			Backup:               &cfg.Backup,
+			Schedule:             &cfg.Schedule,
+			Provisioning:         &cfg.Provisioning,
+			Hooks:                cfg.Hooks,
+			NotificationSinks:    cfg.Notifications.Sinks,
+			DockerTLS:            &cfg.Docker.TLS,
+			RemoteTransport:      cfg.Docker.RemoteTransport,
+			RequestQueue:         &cfg.Docker.RequestQueue,
+			RegistryAuth:         cfg.Docker.RegistryAuth,
+			RegistryMirror:       &cfg.Docker.RegistryMirror,
+			ServerBinary:         &cfg.ServerBinary,
+			BuildContextSync:     cfg.Docker.BuildContextSync,
+			Compression:          &cfg.Docker.Compression,
+			FileSync:             &cfg.FileSync,
+			LocalSemantics:       cfg.Docker.LocalSemantics,
+			PortForward:          &cfg.PortForward,
+			ProvisioningObserver: printProvisioningProgress(os.Stdout, contextCfg.Name),
+			Metrics:              metricsRegistry,
+			Logger:               log,
+		})
+	}
+	return configs, nil
+}
+
+// startControlServer serves the control API for all daemons; it returns nil when the API is disabled
+func startControlServer(cfg *sharedconfig.ClientConfig, daemons []*docker.DockBridgeDaemon, logBuffer *control.LogBuffer, log logger.LoggerInterface) (*control.Server, error) {
+	if !cfg.Control.Enabled {
+		return nil, nil
+	}
+
+	socketPath := cfg.Control.SocketPath
+	if socketPath == "" {
+		var err error
+		if socketPath, err = control.DefaultSocketPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	controlDaemons := make([]control.Daemon, 0, len(daemons))
+	for _, d := range daemons {
+		controlDaemons = append(controlDaemons, d)
+	}
+
+	controlServer := control.NewServer(controlDaemons, log)
+	if logBuffer != nil {
+		controlServer.SetLogBuffer(logBuffer)
+	}
+	if err := controlServer.Start(socketPath); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Control API listening on: %s\n", socketPath)
+
+	if cfg.Control.HTTPListen != "" {
+		if err := controlServer.StartGateway(cfg.Control.HTTPListen); err != nil {
+			log.WithFields(map[string]any{
+				"address": cfg.Control.HTTPListen,
+				"error":   err.Error(),
+			}).Warn("Control REST API disabled")
+		} else {
+			fmt.Printf("Control REST API listening on: http://%s\n", cfg.Control.HTTPListen)
+		}
+	}
+	return controlServer, nil
+}
+
+// newUsageStore opens the local usage state, or returns nil if its location cannot be determined
+func newUsageStore(log logger.LoggerInterface) *usage.Store {
+	path, err := usage.DefaultStorePath()
+	if err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Usage sampling disabled")
+		return nil
+	}
+	return usage.NewStore(path)
+}
+
+// newCostStore opens the local cost state, or returns nil if its location cannot be determined
+func newCostStore(log logger.LoggerInterface) *cost.Store {
+	path, err := cost.DefaultStorePath()
+	if err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Cost tracking disabled")
+		return nil
+	}
+	return cost.NewStore(path)
+}
+
+// newStateStore opens the local daemon state, or returns nil if its location cannot be determined
+func newStateStore(log logger.LoggerInterface) *daemonstate.Store {
+	path, err := daemonstate.DefaultStorePath()
+	if err != nil {
+		log.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Daemon state disabled, servers are looked up on every start")
+		return nil
+	}
+	return daemonstate.NewStore(path)
+}
+
+// dockerContextName returns the Docker CLI context name suggested for a DockBridge context
+func dockerContextName(contextName string) string {
+	return "dockbridge-" + contextName
+}
+
+// progressBarWidth is the number of cells of the provisioning progress bar
+const progressBarWidth = 20
+
+// printProvisioningProgress prints a progress bar line per provisioning phase, prefixed
+// with the context name if any
+func printProvisioningProgress(out io.Writer, contextName string) provider.ProvisioningObserver {
+	prefix := ""
+	if contextName != "" {
+		prefix = "[" + contextName + "] "
+	}
+
+	var mu sync.Mutex
+	return provider.ProvisioningObserverFunc(func(phase provider.ProvisioningPhase, message string, percent int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if phase == provider.PhaseFailed {
+			fmt.Fprintf(out, "%sProvisioning failed: %s\n", prefix, message)
+			return
+		}
+		fmt.Fprintf(out, "%s%s %3d%% %s\n", prefix, progressBar(percent), percent, message)
+	})
+}
+
+// progressBar renders percent as a fixed-width bar such as [#####...............]
+func progressBar(percent int) string {
+	percent = max(0, min(percent, 100))
+	filled := percent * progressBarWidth / 100
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled) + "]"
+}
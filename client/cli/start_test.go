@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextDaemonConfigs(t *testing.T) {
+	cfg := &sharedconfig.ClientConfig{
+		Hetzner: sharedconfig.HetznerConfig{
+			APIToken:   "test-token",
+			ServerType: "cpx21",
+			Location:   "fsn1",
+			VolumeSize: 10,
+		},
+		Docker: sharedconfig.DockerConfig{SocketPath: "/var/run/docker.sock"},
+		Contexts: []sharedconfig.ContextConfig{
+			{Name: "dev", SocketPath: "/tmp/dockbridge-dev.sock"},
+			{Name: "gpu", SocketPath: "/tmp/dockbridge-gpu.sock", ServerType: "cpx51", Location: "hel1", VolumeSize: 100},
+		},
+	}
+
+	configs, err := contextDaemonConfigs(cfg, nil, nil, nil, nil, logger.NewDefault())
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "dev", configs[0].ContextName)
+	assert.Equal(t, "/tmp/dockbridge-dev.sock", configs[0].SocketPath)
+	assert.Equal(t, "cpx21", configs[0].HetznerConfig.ServerType)
+	assert.Equal(t, "fsn1", configs[0].HetznerConfig.Location)
+
+	assert.Equal(t, "gpu", configs[1].ContextName)
+	assert.Equal(t, "cpx51", configs[1].HetznerConfig.ServerType)
+	assert.Equal(t, "hel1", configs[1].HetznerConfig.Location)
+	assert.Equal(t, 100, configs[1].HetznerConfig.VolumeSize)
+	assert.NotSame(t, configs[0].Provider, configs[1].Provider)
+
+	// The default configuration must not be modified by context overrides
+	assert.Equal(t, "cpx21", cfg.Hetzner.ServerType)
+}
+
+func TestDockerContextName(t *testing.T) {
+	assert.Equal(t, "dockbridge-gpu", dockerContextName("gpu"))
+}
+
+func TestDockerContextEndpoints(t *testing.T) {
+	cfg := &sharedconfig.ClientConfig{
+		Docker: sharedconfig.DockerConfig{SocketPath: "/tmp/dockbridge.sock"},
+		Contexts: []sharedconfig.ContextConfig{
+			{Name: "gpu", SocketPath: "/tmp/dockbridge-gpu.sock"},
+		},
+	}
+
+	endpoints := dockerContextEndpoints(cfg)
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "dockbridge", endpoints[0].Name)
+	assert.Equal(t, "/tmp/dockbridge.sock", endpoints[0].SocketPath)
+	assert.Equal(t, "dockbridge-gpu", endpoints[1].Name)
+
+	assert.Empty(t, dockerContextToUse(cfg), "switching is opt-in")
+
+	cfg.Docker.Context.Use = true
+	assert.Equal(t, "dockbridge", dockerContextToUse(cfg))
+	cfg.CurrentContext = "gpu"
+	assert.Equal(t, "dockbridge-gpu", dockerContextToUse(cfg))
+}
+
+// listProvider is a CloudProvider serving a fixed server list
+type listProvider struct {
+	provider.CloudProvider
+	servers []*provider.Server
+}
+
+func (p *listProvider) ListServers(ctx context.Context) ([]*provider.Server, error) {
+	return p.servers, nil
+}
+
+// ownedLabels returns the labels of a server this machine created for contextName
+func ownedLabels(contextName string) map[string]string {
+	return provider.LocalOwner().ServerLabels(contextName)
+}
+
+func TestConfirmServerReplacement(t *testing.T) {
+	cloudProvider := &listProvider{servers: []*provider.Server{
+		{Name: "dockbridge-1700000000", Status: "running", ServerType: "cpx21", Labels: ownedLabels("")},
+		{Name: "dockbridge-ctx-gpu-1700000000", Status: "running", ServerType: "cpx51", Labels: ownedLabels("gpu")},
+	}}
+
+	tests := []struct {
+		name       string
+		serverType string
+		replace    bool
+		input      string
+		expected   bool
+	}{
+		{name: "matching type", serverType: "cpx21", expected: false},
+		{name: "matching type with replace flag", serverType: "cpx21", replace: true, expected: true},
+		{name: "mismatch declined", serverType: "ccx33", input: "n\n", expected: false},
+		{name: "mismatch confirmed", serverType: "ccx33", input: "y\n", expected: true},
+		{name: "mismatch with replace flag", serverType: "ccx33", replace: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			replace, err := confirmServerReplacement(context.Background(), cloudProvider, tt.serverType, tt.replace, strings.NewReader(tt.input), &out)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, replace)
+			if tt.serverType != "cpx21" {
+				assert.Contains(t, out.String(), "running as cpx21, but ccx33 was requested")
+			}
+		})
+	}
+}
+
+func TestPrintProvisioningProgress(t *testing.T) {
+	var out bytes.Buffer
+	observer := printProvisioningProgress(&out, "gpu")
+
+	observer.OnPhase(provider.PhaseWaitingForCloudInit, "Waiting for cloud-init", 50)
+	observer.OnPhase(provider.PhaseFailed, "timeout", 100)
+
+	assert.Equal(t, "[gpu] [##########..........]  50% Waiting for cloud-init\n[gpu] Provisioning failed: timeout\n", out.String())
+	assert.Equal(t, "[....................]", progressBar(-5))
+	assert.Equal(t, "[####################]", progressBar(120))
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/control"
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/dashboard"
+	"github.com/dockbridge/dockbridge/client/docker"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keepAlivePort is the port of the server-side keep-alive monitor
+const keepAlivePort = 8080
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a live dashboard of the running daemon",
+	Long: `Show a live dashboard of the running DockBridge daemon: remote server state,
+keep-alive countdown, SSH health, active port forwards with traffic, running
+containers, volume usage and estimated cost. Watching the dashboard does not
+count as Docker activity, so it never provisions a server or keeps one alive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		return showStatus(configPath, interval, once)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().Duration("interval", dashboard.DefaultRefreshInterval, "Refresh interval of the dashboard")
+	statusCmd.Flags().Bool("once", false, "Print the status once instead of refreshing it")
+}
+
+// dialControl connects to the control API of the running daemon
+func dialControl(cfg *sharedconfig.ClientConfig) (controlv1.ControlServiceClient, *grpc.ClientConn, error) {
+	socketPath := cfg.Control.SocketPath
+	if socketPath == "" {
+		var err error
+		if socketPath, err = control.DefaultSocketPath(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return control.Dial(socketPath)
+}
+
+// daemonError explains an error of the control API caused by the daemon not running;
+// other errors are returned unchanged
+func daemonError(err error) error {
+	if status.Code(err) != codes.Unavailable {
+		return err
+	}
+	return errors.NewDaemonError(errors.ErrCodeDaemonNotRunning, "DockBridge daemon is not running", err).
+		WithHint(`Start it with "dockbridge start" or "dockbridge service install"`)
+}
+
+// newInspector returns the SSH inspector of the servers of cfg
+func newInspector(cfg *sharedconfig.ClientConfig) *dashboard.Inspector {
+	return &dashboard.Inspector{
+		NewClient: func(host string) ssh.Client {
+			return ssh.NewClient(&ssh.ClientConfig{
+				Host:            host,
+				Port:            cfg.SSH.Port,
+				User:            "root",
+				PrivateKeyPath:  expandHomePath(cfg.SSH.KeyPath),
+				Timeout:         10 * time.Second,
+				UseAgent:        cfg.SSH.UseAgent,
+				AgentSocket:     cfg.SSH.AgentSocket,
+				KnownHostsPath:  expandHomePath(cfg.SSH.KnownHostsPath),
+				HostKeyChecking: cfg.SSH.HostKeyChecking,
+				JumpHost:        cfg.SSH.JumpHost,
+			})
+		},
+	}
+}
+
+// newCollector returns a collector of the daemon's state with the servers' keep-alive
+// status and costs; it does not inspect the servers over SSH
+func newCollector(cfg *sharedconfig.ClientConfig, client controlv1.ControlServiceClient, inspector *dashboard.Inspector) *dashboard.Collector {
+	collector := &dashboard.Collector{
+		Control: client,
+		KeepAlive: func(host, serverName string) (*keepalive.MonitorStatus, error) {
+			if cfg.KeepAlive.Transport == docker.KeepAliveTransportSSH {
+				url := "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(keepAlivePort))
+				return keepalive.NewHeartbeatClient(url).
+					WithAuthToken(docker.KeepAliveToken(serverName)).
+					WithDialer(inspector.Dial(host)).
+					GetStatus()
+			}
+			url := "http://" + net.JoinHostPort(host, strconv.Itoa(keepAlivePort))
+			return keepalive.NewHeartbeatClient(url).WithAuthToken(docker.KeepAliveToken(serverName)).GetStatus()
+		},
+		Budget: cost.Budget{Monthly: cfg.Budget.Monthly, WarnFraction: float64(cfg.Budget.WarnPercent) / 100},
+	}
+	if path, err := cost.DefaultStorePath(); err == nil {
+		collector.Costs = cost.NewStore(path)
+	}
+	return collector
+}
+
+// showStatus shows the dashboard until interrupted, or prints it once
+func showStatus(configPath string, interval time.Duration, once bool) error {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		errors.LogError(err, "Failed to load configuration")
+		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to load configuration", err)
+	}
+	cfg := manager.GetConfig()
+
+	client, conn, err := dialControl(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	inspector := newInspector(cfg)
+	defer inspector.Close()
+
+	collector := newCollector(cfg, client, inspector)
+	collector.Remote = inspector.Inspect
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if once {
+		return dashboard.Render(os.Stdout, collector.Collect(ctx))
+	}
+	if err := dashboard.Run(ctx, collector, os.Stdout, interval); err != nil {
+		return fmt.Errorf("dashboard failed: %w", err)
+	}
+	return nil
+}
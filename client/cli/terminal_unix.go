@@ -0,0 +1,40 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"golang.org/x/term"
+)
+
+// watchWindowSize delivers the size of the terminal fd whenever it is resized, until
+// stop is called
+func watchWindowSize(fd int) (sizes <-chan ssh.WindowSize, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	ch := make(chan ssh.WindowSize, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				if width, height, err := term.GetSize(fd); err == nil {
+					select {
+					case ch <- ssh.WindowSize{Width: width, Height: height}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return ch, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cli
+
+import "github.com/dockbridge/dockbridge/client/ssh"
+
+// watchWindowSize returns no sizes: Windows consoles do not signal resizes
+func watchWindowSize(fd int) (sizes <-chan ssh.WindowSize, stop func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/tray"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/spf13/cobra"
+)
+
+var trayCmd = &cobra.Command{
+	Use:   "tray",
+	Short: "Show the daemon's state in the menu bar",
+	Long: `Print the DockBridge menu for a menu bar plugin host: xbar or SwiftBar on macOS,
+the Argos extension on GNOME. The bar shows whether a paid server is running and when
+it self-destructs; the menu lists every context's server, keep-alive countdown and
+forwards, with actions to provision and destroy servers.
+
+Install the plugin with "dockbridge tray install". Like "dockbridge status", the menu
+never provisions a server or keeps one alive.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		format, err := trayFormat(cmd)
+		if err != nil {
+			return err
+		}
+		return showTray(cmd.Context(), cmd.OutOrStdout(), configPath, format)
+	},
+}
+
+var trayInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the menu bar plugin",
+	Long: `Write the plugin script running "dockbridge tray" into the plugin directory of
+xbar (~/Library/Application Support/xbar/plugins) on macOS or Argos (~/.config/argos)
+on Linux. Use --dir for SwiftBar or another plugin directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		dir, _ := cmd.Flags().GetString("dir")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		format, err := trayFormat(cmd)
+		if err != nil {
+			return err
+		}
+		return runTrayInstall(cmd.OutOrStdout(), configPath, dir, format, interval)
+	},
+}
+
+var trayUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the menu bar plugin",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		dir, err := trayPluginDir(dir)
+		if err != nil {
+			return err
+		}
+		if err := tray.Uninstall(dir); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "DockBridge menu bar plugin removed.")
+		return nil
+	},
+}
+
+var trayProvisionCmd = &cobra.Command{
+	Use:   "provision",
+	Short: "Provision the server of a context through the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return runTrayAction(cmd.Context(), configPath, func(ctx context.Context, client controlv1.ControlServiceClient) error {
+			resp, err := client.Provision(ctx, &controlv1.ProvisionRequest{Context: contextName})
+			if err != nil {
+				return fmt.Errorf("failed to provision server: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Server %s is running at %s\n", resp.GetServer().GetName(), resp.GetServer().GetIpAddress())
+			return nil
+		})
+	},
+}
+
+var trayDestroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Destroy the server of a context through the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return runTrayAction(cmd.Context(), configPath, func(ctx context.Context, client controlv1.ControlServiceClient) error {
+			if _, err := client.Destroy(ctx, &controlv1.DestroyRequest{Context: contextName}); err != nil {
+				return fmt.Errorf("failed to destroy server: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Server destroyed; the Docker data volume is preserved.")
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trayCmd)
+	trayCmd.AddCommand(trayInstallCmd, trayUninstallCmd, trayProvisionCmd, trayDestroyCmd)
+
+	trayCmd.PersistentFlags().String("format", "", "Plugin format, xbar (macOS) or argos (Linux) (default: the platform's)")
+	trayInstallCmd.Flags().String("dir", "", "Plugin directory (default: xbar's on macOS, Argos' on Linux)")
+	trayInstallCmd.Flags().Duration("interval", tray.DefaultInterval, "How often the menu is refreshed")
+	trayUninstallCmd.Flags().String("dir", "", "Plugin directory (default: xbar's on macOS, Argos' on Linux)")
+	trayProvisionCmd.Flags().String("context", "", "Context whose server to provision (default: the default context)")
+	trayDestroyCmd.Flags().String("context", "", "Context whose server to destroy (default: the default context)")
+}
+
+// trayFormat returns the plugin format selected with --format
+func trayFormat(cmd *cobra.Command) (tray.Format, error) {
+	name, _ := cmd.Flags().GetString("format")
+	if name == "" {
+		return tray.DefaultFormat(runtime.GOOS), nil
+	}
+	return tray.ParseFormat(name)
+}
+
+// trayPluginDir returns dir, or the default plugin directory of this platform
+func trayPluginDir(dir string) (string, error) {
+	if dir != "" {
+		return expandHomePath(dir), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return tray.PluginDir(runtime.GOOS, homeDir)
+}
+
+// trayExecutable returns the dockbridge binary and the arguments selecting the
+// configuration file, which the plugin host runs from another directory
+func trayExecutable(configPath string) (string, []string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find the dockbridge binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	if configPath == "" {
+		return executable, nil, nil
+	}
+	absPath, err := filepath.Abs(expandHomePath(configPath))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	return executable, []string{"--config", absPath}, nil
+}
+
+// showTray prints the menu of the daemon's current state
+func showTray(ctx context.Context, out io.Writer, configPath string, format tray.Format) error {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := manager.GetConfig()
+
+	executable, args, err := trayExecutable(configPath)
+	if err != nil {
+		return err
+	}
+	client, conn, err := dialControl(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	inspector := newInspector(cfg)
+	defer inspector.Close()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	menu := &tray.Menu{Executable: executable, Args: args, Format: format}
+	return menu.Render(out, newCollector(cfg, client, inspector).Collect(ctx))
+}
+
+// runTrayInstall installs the plugin printing the menu in format every interval
+func runTrayInstall(out io.Writer, configPath, dir string, format tray.Format, interval time.Duration) error {
+	dir, err := trayPluginDir(dir)
+	if err != nil {
+		return err
+	}
+	executable, args, err := trayExecutable(configPath)
+	if err != nil {
+		return err
+	}
+
+	path, err := tray.Install(dir, executable, append([]string{"--format", string(format)}, args...), interval)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Menu bar plugin installed: %s\n", path)
+	return nil
+}
+
+// runTrayAction runs a menu action against the running daemon's control API
+func runTrayAction(ctx context.Context, configPath string, run func(context.Context, controlv1.ControlServiceClient) error) error {
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client, conn, err := dialControl(manager.GetConfig())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return run(ctx, client)
+}
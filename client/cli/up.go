@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/daemonproc"
+	"github.com/dockbridge/dockbridge/client/dockercontext"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/pkg/errors"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// daemonReadyPollInterval is how often up checks whether a started daemon serves the
+// control API
+const daemonReadyPollInterval = 250 * time.Millisecond
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Start the daemon in the background and bring the server up",
+	Long: `Bring DockBridge up in one step: start the daemon in the background unless it is
+already running, provision the server of the context (or resume a powered-off one),
+wait until Docker on it responds and print how to point Docker at it. The daemon
+forwards the ports of containers as they start.
+
+The background daemon logs to ~/.dockbridge/logs/dockbridge.log; "dockbridge down"
+stops it. --server-type, --location, --volume-size and --replace only apply when this
+command starts the daemon, e.g.:
+  dockbridge up --server-type ccx33 --location hel1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		opts := startOptions{}
+		opts.Override.ServerType, _ = cmd.Flags().GetString("server-type")
+		opts.Override.Location, _ = cmd.Flags().GetString("location")
+		opts.Override.VolumeSize, _ = cmd.Flags().GetInt("volume-size")
+		opts.Replace, _ = cmd.Flags().GetBool("replace")
+		return runUp(cmd.Context(), configPath, contextName, opts, timeout, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upCmd)
+	upCmd.Flags().StringP("config", "c", "", "Path to configuration file")
+	upCmd.Flags().String("context", "", "Context whose server to bring up (default: the current context)")
+	upCmd.Flags().String("server-type", "", "Server type of a started daemon (overrides config and profile)")
+	upCmd.Flags().String("location", "", "Server location of a started daemon (overrides config and profile)")
+	upCmd.Flags().Int("volume-size", 0, "Volume size in GB of a started daemon (overrides config and profile)")
+	upCmd.Flags().Bool("replace", false, "Replace a running server of a different type (the volume is preserved)")
+	upCmd.Flags().Duration("timeout", time.Minute, "How long to wait for a started daemon to serve requests")
+}
+
+// runUp starts the daemon unless it is running, provisions the context's server through
+// it and prints the Docker endpoint
+func runUp(ctx context.Context, configPath, contextName string, opts startOptions, timeout time.Duration, out io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	manager := clientconfig.NewManager()
+	if err := manager.Load(configPath); err != nil {
+		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "Failed to load configuration", err)
+	}
+	cfg := manager.GetConfig()
+	if !cfg.Control.Enabled {
+		return errors.NewConfigError(errors.ErrCodeInvalidConfig, "dockbridge up needs the control API", nil).
+			WithHint(`Set control.enabled, or run "dockbridge start" instead`)
+	}
+
+	contextName, _, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+
+	client, conn, err := dialControl(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = client.GetStatus(ctx, &controlv1.GetStatusRequest{})
+	switch {
+	case status.Code(err) == codes.Unavailable:
+		if err := startDaemon(ctx, client, configPath, opts, timeout, out); err != nil {
+			return err
+		}
+	case err != nil:
+		return daemonError(err)
+	case opts.Override.ServerType != "" || opts.Override.Location != "" || opts.Override.VolumeSize != 0 || opts.Replace:
+		fmt.Fprintln(out, `The daemon is already running; --server-type, --location, --volume-size and --replace apply after "dockbridge down".`)
+	}
+
+	fmt.Fprintln(out, "Bringing the server up; provisioning a new one takes a few minutes...")
+	resp, err := client.Provision(ctx, &controlv1.ProvisionRequest{Context: contextName})
+	if err != nil {
+		return errors.NewProvisioningError(errors.ErrCodeProvisionFailed, "Failed to bring the server up", err, true).
+			WithHint(`See the daemon log for details, e.g. with "dockbridge logs view"`)
+	}
+	fmt.Fprintf(out, "Server %s is running at %s\n", resp.GetServer().GetName(), resp.GetServer().GetIpAddress())
+
+	statusResp, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{})
+	if err != nil {
+		return daemonError(err)
+	}
+	for _, contextStatus := range statusResp.GetContexts() {
+		if contextStatus.GetName() == contextName {
+			printDockerEndpoint(out, cfg, contextName, contextStatus.GetSocketPath())
+		}
+	}
+	return nil
+}
+
+// startDaemon runs "dockbridge start" in the background and waits until it serves the
+// control API
+func startDaemon(ctx context.Context, client controlv1.ControlServiceClient, configPath string, opts startOptions, timeout time.Duration, out io.Writer) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the dockbridge binary: %w", err)
+	}
+	logPath, err := daemonproc.LogPath()
+	if err != nil {
+		return err
+	}
+	args, err := upDaemonArgs(configPath, opts)
+	if err != nil {
+		return err
+	}
+
+	// The profile selected with --profile is passed on in the environment
+	pid, err := daemonproc.Spawn(executable, args, logPath)
+	if err != nil {
+		return errors.NewDaemonError(errors.ErrCodeDaemonStartFailed, "Failed to start the DockBridge daemon", err)
+	}
+	fmt.Fprintf(out, "Started DockBridge daemon (PID %d), logging to %s\n", pid, logPath)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(daemonReadyPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{}); err == nil {
+			return nil
+		}
+		if !daemonproc.Alive(pid) {
+			return errors.NewDaemonError(errors.ErrCodeDaemonStartFailed, "The DockBridge daemon exited during startup", nil).
+				WithHint("See " + logPath)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.NewDaemonError(errors.ErrCodeDaemonStartFailed, fmt.Sprintf("The DockBridge daemon did not start within %s", timeout), ctx.Err()).
+				WithHint("See " + logPath)
+		case <-ticker.C:
+		}
+	}
+}
+
+// upDaemonArgs returns the arguments of the start command run by up
+func upDaemonArgs(configPath string, opts startOptions) ([]string, error) {
+	args := []string{"start"}
+	if configPath != "" {
+		// The daemon is not bound to this directory
+		absPath, err := filepath.Abs(expandHomePath(configPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		args = append(args, "--config", absPath)
+	}
+	if opts.Override.ServerType != "" {
+		args = append(args, "--server-type", opts.Override.ServerType)
+	}
+	if opts.Override.Location != "" {
+		args = append(args, "--location", opts.Override.Location)
+	}
+	if opts.Override.VolumeSize != 0 {
+		args = append(args, "--volume-size", strconv.Itoa(opts.Override.VolumeSize))
+	}
+	if opts.Replace {
+		args = append(args, "--replace")
+	}
+	return args, nil
+}
+
+// printDockerEndpoint prints how to point the Docker CLI at the socket of a context
+func printDockerEndpoint(out io.Writer, cfg *sharedconfig.ClientConfig, contextName, socketPath string) {
+	if cfg.Docker.Context.Register {
+		dockerContext := dockercontext.DefaultName
+		if contextName != "" {
+			dockerContext = dockerContextName(contextName)
+		}
+		fmt.Fprintf(out, "Docker context: %s (docker context use %s)\n", dockerContext, dockerContext)
+	}
+	fmt.Fprintf(out, "export DOCKER_HOST=%s\n", localsocket.DockerHost(socketPath))
+}
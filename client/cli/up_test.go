@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpDaemonArgs(t *testing.T) {
+	args, err := upDaemonArgs("", startOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start"}, args)
+
+	opts := startOptions{Replace: true}
+	opts.Override.ServerType = "ccx33"
+	opts.Override.Location = "hel1"
+	opts.Override.VolumeSize = 40
+	args, err = upDaemonArgs("client.yaml", opts)
+	require.NoError(t, err)
+
+	absPath, err := filepath.Abs("client.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "--config", absPath, "--server-type", "ccx33", "--location", "hel1", "--volume-size", "40", "--replace"}, args)
+}
+
+func TestPrintDockerEndpoint(t *testing.T) {
+	cfg := &sharedconfig.ClientConfig{}
+
+	var out bytes.Buffer
+	printDockerEndpoint(&out, cfg, "", "/tmp/dockbridge.sock")
+	assert.Equal(t, "export DOCKER_HOST=unix:///tmp/dockbridge.sock\n", out.String())
+
+	cfg.Docker.Context.Register = true
+	out.Reset()
+	printDockerEndpoint(&out, cfg, "gpu", "/tmp/dockbridge-gpu.sock")
+	assert.Equal(t, "Docker context: dockbridge-gpu (docker context use dockbridge-gpu)\nexport DOCKER_HOST=unix:///tmp/dockbridge-gpu.sock\n", out.String())
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	clientconfig "github.com/dockbridge/dockbridge/client/config"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/spf13/cobra"
+)
+
+// dockerDataMount is where servers mount the Docker data volume
+const dockerDataMount = "/var/lib/docker"
+
+// dockerVolumeName identifies Docker data volumes by name
+const dockerVolumeName = "dockbridge-docker-data"
+
+// gib is the unit providers size volumes in
+const gib = 1 << 30
+
+var volumeCmd = &cobra.Command{
+	Use:   "volume",
+	Short: "Manage the Docker data volume",
+	Long:  `Manage the persistent volume holding Docker images, containers and volumes.`,
+}
+
+var volumeResizeCmd = &cobra.Command{
+	Use:   "resize <size>",
+	Short: "Grow the Docker data volume",
+	Long: `Grow the Docker data volume of a context to <size> GB (e.g. 40 or 40GB).
+The volume is resized with the provider, the filesystem is extended on the running
+server over SSH and the new capacity is verified. The new size is recorded in the
+configuration. Volumes can only grow.
+
+A volume not attached to a server is resized with the provider only; its filesystem
+is extended the next time a server mounts it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		size, err := parseVolumeSize(args[0])
+		if err != nil {
+			return err
+		}
+		configPath, _ := cmd.Flags().GetString("config")
+		contextName, _ := cmd.Flags().GetString("context")
+		return runVolumeResize(cmd.Context(), configPath, contextName, size, cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(volumeCmd)
+	volumeCmd.AddCommand(volumeResizeCmd)
+
+	volumeCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	volumeCmd.PersistentFlags().String("context", "", "Context whose volume to manage (default: the current context)")
+}
+
+// parseVolumeSize parses a size in GB such as "40", "40G" or "40GB"
+func parseVolumeSize(value string) (int, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "G")
+	size, err := strconv.Atoi(trimmed)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid volume size %q, expected GB such as 40 or 40GB", value)
+	}
+	return size, nil
+}
+
+// runVolumeResize grows the Docker data volume of a context and records the new size
+func runVolumeResize(ctx context.Context, configPath, contextName string, size int, out io.Writer) error {
+	manager, path, err := loadContextConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cfg := manager.GetConfig()
+
+	contextName, settings, err := contextServerSettings(cfg, contextName)
+	if err != nil {
+		return err
+	}
+	cloudProvider, err := newCloudProvider(cfg, settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	grow := func(ctx context.Context, host string) ([]byte, error) {
+		client := newServerSSHClient(&cfg.SSH, host)
+		if err := client.Connect(ctx); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+		}
+		defer client.Close()
+		return client.ExecuteCommand(ctx, growFilesystemCommand(dockerDataMount))
+	}
+
+	if err := resizeContextVolume(ctx, cloudProvider, contextName, settings.Location, size, grow, out); err != nil {
+		return err
+	}
+
+	if err := clientconfig.SetVolumeSize(path, cfg.Provider, contextName, size); err != nil {
+		return fmt.Errorf("volume resized, but failed to record its size: %w", err)
+	}
+	fmt.Fprintf(out, "Recorded volume size %d GB in %s\n", size, path)
+	return nil
+}
+
+// resizeContextVolume grows the Docker data volume of a context to size GB. When the
+// volume is attached to a running server, grow extends the filesystem on it and its
+// output is verified.
+func resizeContextVolume(ctx context.Context, cloudProvider provider.CloudProvider, contextName, location string, size int,
+	grow func(ctx context.Context, host string) ([]byte, error), out io.Writer) error {
+	resizer, ok := cloudProvider.(provider.VolumeResizer)
+	if !ok {
+		return fmt.Errorf("the configured provider cannot resize volumes")
+	}
+
+	volume, server, err := findDockerVolume(ctx, cloudProvider, contextName, location)
+	if err != nil {
+		return err
+	}
+	if size < volume.Size {
+		return fmt.Errorf("volume %s is %d GB; volumes can only grow", volume.Name, volume.Size)
+	}
+	if size == volume.Size {
+		fmt.Fprintf(out, "Volume %s is already %d GB\n", volume.Name, size)
+		return nil
+	}
+	if server != nil && server.Status == provider.StatusOff {
+		return fmt.Errorf("server %s is powered off; resume it with any Docker command first", server.Name)
+	}
+
+	fmt.Fprintf(out, "Resizing volume %s from %d GB to %d GB...\n", volume.Name, volume.Size, size)
+	if _, err := resizer.ResizeVolume(ctx, volume.ID, size); err != nil {
+		return err
+	}
+
+	if server == nil {
+		fmt.Fprintln(out, "The volume is not attached; its filesystem grows when a server next mounts it.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Growing the filesystem on %s...\n", server.Name)
+	output, err := grow(ctx, server.IPAddress)
+	if err != nil {
+		return fmt.Errorf("volume resized, but growing the filesystem failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	device, filesystem, err := parseCapacity(output)
+	if err != nil {
+		return err
+	}
+	if err := verifyCapacity(device, filesystem, size); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s now has %.1f GB of %d GB available to Docker\n", dockerDataMount, float64(filesystem)/gib, size)
+	return nil
+}
+
+// findDockerVolume returns the Docker data volume of a context with the server it is
+// attached to, or an unattached Docker data volume of this client in location with a
+// nil server
+func findDockerVolume(ctx context.Context, cloudProvider provider.CloudProvider, contextName, location string) (*provider.Volume, *provider.Server, error) {
+	servers, err := provider.NewServerRegistry(cloudProvider).ForContext(ctx, contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, server := range servers {
+		if server.VolumeID == "" {
+			continue
+		}
+		volume, err := cloudProvider.GetVolume(ctx, server.VolumeID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get volume of %s: %w", server.Name, err)
+		}
+		return volume, server, nil
+	}
+
+	volumes, err := cloudProvider.ListVolumes(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	for _, volume := range volumes {
+		if volume.Location == location && strings.HasPrefix(volume.Name, dockerVolumeName) && volume.Status == "available" && provider.LocalOwner().Owns(volume.Labels) {
+			return volume, nil, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no Docker data volume found for this context in %s", location)
+}
+
+// growFilesystemCommand extends the ext4 filesystem mounted at mount to its device
+// and prints the device and filesystem sizes in bytes. An encrypted volume's LUKS
+// mapping is grown to the volume first.
+func growFilesystemCommand(mount string) string {
+	return `set -e
+dev=$(findmnt -n -o SOURCE --mountpoint ` + mount + `)
+if [ -z "$dev" ]; then echo "no volume mounted at ` + mount + `" >&2; exit 1; fi
+block=/sys/class/block/$(basename "$(readlink -f "$dev")")
+if [ "$(lsblk -ndo TYPE "$dev")" = crypt ]; then
+  for slave in "$block"/slaves/*; do
+    if [ -w "$slave/device/rescan" ]; then echo 1 > "$slave/device/rescan"; fi
+  done
+  cryptsetup resize "$(basename "$dev")"
+elif [ -w "$block/device/rescan" ]; then
+  echo 1 > "$block/device/rescan"
+fi
+resize2fs "$dev" >&2
+lsblk -bndo SIZE "$dev"
+df -B1 --output=size ` + mount + ` | tail -n 1`
+}
+
+// parseCapacity parses the device and filesystem sizes printed by growFilesystemCommand
+func parseCapacity(output []byte) (device, filesystem uint64, err error) {
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return 0, 0, fmt.Errorf("unexpected capacity output: %q", strings.TrimSpace(string(output)))
+	}
+	if device, err = strconv.ParseUint(fields[len(fields)-2], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("unexpected device size %q", fields[len(fields)-2])
+	}
+	if filesystem, err = strconv.ParseUint(fields[len(fields)-1], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("unexpected filesystem size %q", fields[len(fields)-1])
+	}
+	return device, filesystem, nil
+}
+
+// verifyCapacity checks that the device reached size GB and the filesystem spans it.
+// ext4 metadata takes a few percent of the device, so the filesystem is smaller.
+func verifyCapacity(device, filesystem uint64, size int) error {
+	if device < uint64(size)*gib {
+		return fmt.Errorf("the server still sees a %.1f GB device; reboot it to pick up the new size", float64(device)/gib)
+	}
+	if filesystem < device/100*95 {
+		return fmt.Errorf("the filesystem spans %.1f GB of the %.1f GB device; run resize2fs on the server", float64(filesystem)/gib, float64(device)/gib)
+	}
+	return nil
+}
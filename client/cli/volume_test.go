@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// volumeProvider serves volumes and records resizes
+type volumeProvider struct {
+	listProvider
+	volumes []*provider.Volume
+	resized map[string]int
+}
+
+func (p *volumeProvider) GetVolume(ctx context.Context, volumeID string) (*provider.Volume, error) {
+	for _, volume := range p.volumes {
+		if volume.ID == volumeID {
+			return volume, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (p *volumeProvider) ListVolumes(ctx context.Context) ([]*provider.Volume, error) {
+	return p.volumes, nil
+}
+
+func (p *volumeProvider) ResizeVolume(ctx context.Context, volumeID string, size int) (*provider.Volume, error) {
+	if p.resized == nil {
+		p.resized = make(map[string]int)
+	}
+	p.resized[volumeID] = size
+	return &provider.Volume{ID: volumeID, Size: size}, nil
+}
+
+func TestParseVolumeSize(t *testing.T) {
+	for _, value := range []string{"40", "40G", "40GB", "40gb"} {
+		size, err := parseVolumeSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, 40, size)
+	}
+	for _, value := range []string{"", "0", "-5", "40TB", "big"} {
+		_, err := parseVolumeSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestGrowFilesystemCommand(t *testing.T) {
+	command := growFilesystemCommand("/var/lib/docker")
+	assert.Contains(t, command, "findmnt -n -o SOURCE --mountpoint /var/lib/docker")
+	assert.Contains(t, command, `cryptsetup resize "$(basename "$dev")"`, "encrypted volumes grow their mapping")
+	assert.Contains(t, command, `resize2fs "$dev"`)
+}
+
+func TestVerifyCapacity(t *testing.T) {
+	device, filesystem, err := parseCapacity([]byte("Resizing the filesystem on /dev/sdb to 10485760 (4k) blocks.\n42949672960\n42278584320\n"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(40*gib), device)
+	assert.NoError(t, verifyCapacity(device, filesystem, 40))
+
+	assert.ErrorContains(t, verifyCapacity(20*gib, 19*gib, 40), "reboot")
+	assert.ErrorContains(t, verifyCapacity(40*gib, 19*gib, 40), "resize2fs")
+
+	_, _, err = parseCapacity([]byte("no volume mounted at /var/lib/docker"))
+	assert.Error(t, err)
+}
+
+func TestResizeContextVolume(t *testing.T) {
+	grown := func(ctx context.Context, host string) ([]byte, error) {
+		assert.Equal(t, "203.0.113.7", host)
+		return []byte("42949672960\n42278584320\n"), nil
+	}
+
+	newProvider := func(status string) *volumeProvider {
+		return &volumeProvider{
+			listProvider: listProvider{servers: []*provider.Server{
+				{ID: 1, Name: "dockbridge-1700000000", Status: status, IPAddress: "203.0.113.7", VolumeID: "10", Labels: ownedLabels("")},
+			}},
+			volumes: []*provider.Volume{
+				{ID: "10", Name: "dockbridge-docker-data-1", Size: 10, Location: "fsn1", Status: "attached"},
+				{ID: "11", Name: "dockbridge-docker-data-2", Size: 10, Location: "fsn1", Status: "available", Labels: provider.LocalOwner().Labels()},
+			},
+		}
+	}
+
+	var out bytes.Buffer
+	cloudProvider := newProvider("running")
+	require.NoError(t, resizeContextVolume(context.Background(), cloudProvider, "", "fsn1", 40, grown, &out))
+	assert.Equal(t, map[string]int{"10": 40}, cloudProvider.resized)
+	assert.Contains(t, out.String(), "available to Docker")
+
+	// Volumes cannot shrink, and a powered-off server cannot grow its filesystem
+	assert.ErrorContains(t, resizeContextVolume(context.Background(), newProvider("running"), "", "fsn1", 5, grown, &out), "only grow")
+	assert.ErrorContains(t, resizeContextVolume(context.Background(), newProvider(provider.StatusOff), "", "fsn1", 40, grown, &out), "powered off")
+
+	// Without a server the unattached volume is resized only
+	cloudProvider = newProvider("running")
+	cloudProvider.servers = nil
+	out.Reset()
+	require.NoError(t, resizeContextVolume(context.Background(), cloudProvider, "", "fsn1", 40, nil, &out))
+	assert.Equal(t, map[string]int{"11": 40}, cloudProvider.resized)
+	assert.Contains(t, out.String(), "next mounts it")
+
+	// A failed filesystem check is reported
+	failing := func(ctx context.Context, host string) ([]byte, error) {
+		return []byte("20000000000\n19000000000\n"), nil
+	}
+	assert.ErrorContains(t, resizeContextVolume(context.Background(), newProvider("running"), "", "fsn1", 40, failing, &out), "reboot")
+
+	// Providers that cannot resize are rejected
+	assert.ErrorContains(t, resizeContextVolume(context.Background(), &listProvider{}, "", "fsn1", 40, grown, &out), "cannot resize")
+}
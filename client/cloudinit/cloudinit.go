@@ -0,0 +1,338 @@
+// Package cloudinit renders the cloud-config user data DockBridge servers are
+// provisioned with. The document is built from text/template templates over a typed
+// model, and every rendered document is validated before it is handed to a provider.
+package cloudinit
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/dockbridge/dockbridge/client/dockertls"
+)
+
+// Defaults applied to zero values of Config
+const (
+	DefaultDataRoot      = "/var/lib/docker"
+	DefaultKeepAlivePort = 8080
+	DefaultDockerAPIPort = 2376
+	DefaultSSHPort       = 22
+)
+
+// Paths on the server the client relies on
+const (
+	// SetupLog receives the output of the setup
+	SetupLog = "/var/log/dockbridge-setup.log"
+
+	// SetupMarker is written with Config.ServerName as the last step of the setup
+	SetupMarker = "/var/lib/dockbridge/setup-complete"
+
+	// EncryptedVolumeDir records the device of each encrypted volume, named after the
+	// volume, for the client to unlock
+	EncryptedVolumeDir = "/etc/dockbridge/volumes"
+)
+
+// dockerDataVolume names the volume holding Docker's data root
+const dockerDataVolume = "docker-data"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates holds the cloud-config template and the scripts it embeds
+var templates = template.Must(newTemplate().ParseFS(templateFS, "templates/*.tmpl"))
+
+// Config is the typed model of a server's cloud-config
+type Config struct {
+	// InstallDocker installs Docker CE from Docker's apt repository, for images that do
+	// not ship with Docker
+	InstallDocker bool
+
+	// Packages are installed in addition to the base packages
+	Packages []string
+
+	// SSHAuthorizedKeys are authorized for the default user
+	SSHAuthorizedKeys []string
+
+	// Users are created with sudo rights and Docker access
+	Users []string
+
+	// DataRoot is Docker's data directory (default DefaultDataRoot)
+	DataRoot string
+
+	// DataVolume, when set, is the Docker data volume mounted at DataRoot. Without a
+	// device, the first extra block device is used.
+	DataVolume *Volume
+
+	// Volumes are mounted in addition to the Docker data volume
+	Volumes []Volume
+
+	// DockerAPIPort is the TCP port of the Docker API (default DefaultDockerAPIPort)
+	DockerAPIPort int
+
+	// DockerTLS, when set, installs the server certificates and makes dockerd require
+	// client certificates; otherwise the API is plaintext behind the tunnel
+	DockerTLS *dockertls.Bundle
+
+	// DockerUnixOnly keeps dockerd off TCP; the client reaches its socket through SSH
+	DockerUnixOnly bool
+
+	// RegistryMirror is the URL of the pull-through cache dockerd pulls Docker Hub
+	// images through (optional)
+	RegistryMirror string
+
+	// RegistryMirrorSetup is the shell script starting the cache once Docker runs
+	RegistryMirrorSetup string
+
+	// Buildx installs the buildx plugin where the image lacks it
+	Buildx bool
+
+	// SSHPort is the SSH port opened in the firewall (default DefaultSSHPort)
+	SSHPort int
+
+	// ServerVersion is the release tag of the dockbridge-server binary installed, e.g.
+	// v1.2.3, so that servers run the version of the client that provisions them; the
+	// latest release when empty
+	ServerVersion string
+
+	// UploadServer leaves the dockbridge-server binary to the client, which uploads it
+	// over SSH once the server is up, so that setup does not download it
+	UploadServer bool
+
+	// KeepAlivePort is the port of the keep-alive monitor (default DefaultKeepAlivePort)
+	KeepAlivePort int
+
+	// KeepAliveToken, when set, makes the keep-alive monitor reject heartbeats that are
+	// not signed with it
+	KeepAliveToken string
+
+	// KeepAliveOverSSH binds the keep-alive monitor to loopback and keeps its port
+	// closed, for heartbeats delivered through the SSH connection
+	KeepAliveOverSSH bool
+
+	// WriteFiles are written before any command runs
+	WriteFiles []File
+
+	// PreDocker commands run before Docker is installed or configured
+	PreDocker []string
+
+	// PostDocker commands run once Docker and the DockBridge server are running
+	PostDocker []string
+
+	// OSUpdates is the shell script configuring unattended OS upgrades (optional)
+	OSUpdates string
+
+	// Backup is the shell script scheduling volume backups (optional); it runs after
+	// the post-docker steps
+	Backup string
+
+	// ServerName, when set, is written to SetupMarker once the setup completed
+	ServerName string
+}
+
+// File is a file written on the server
+type File struct {
+	Path    string
+	Content []byte
+
+	// Permissions in octal, e.g. "0644"; cloud-init's default when empty
+	Permissions string
+
+	// Owner as user:group; root when empty
+	Owner string
+}
+
+// Base64 returns the file's content encoded for write_files, which keeps binary
+// content and YAML-significant characters intact
+func (f File) Base64() string {
+	return base64.StdEncoding.EncodeToString(f.Content)
+}
+
+// Volume is a persistent volume mounted on the server
+type Volume struct {
+	Name  string
+	Mount string
+
+	// Device is the volume's stable device path, e.g. /dev/disk/by-id/scsi-0HC_Volume_1
+	Device string
+
+	// Encrypted volumes are only recorded in EncryptedVolumeDir; the client formats,
+	// unlocks and mounts them over SSH with keys the server never stores
+	Encrypted bool
+}
+
+// EncryptedVolumeDir returns where the device of an encrypted volume is recorded
+func (v Volume) EncryptedVolumeDir() string {
+	return EncryptedVolumeDir
+}
+
+// Label returns the volume's filesystem label; ext4 labels are limited to 16 bytes
+func (v Volume) Label() string {
+	if len(v.Name) > 16 {
+		return v.Name[:16]
+	}
+	return v.Name
+}
+
+// BackupDir returns where data already in the mount point is kept while the volume
+// is mounted over it
+func (v Volume) BackupDir() string {
+	if v.Name == dockerDataVolume {
+		return "/tmp/docker-backup"
+	}
+	return "/tmp/volume-backup-" + v.Name
+}
+
+// Subject describes the volume's contents in comments
+func (v Volume) Subject() string {
+	if v.Name == dockerDataVolume {
+		return "Docker data"
+	}
+	return "volume " + v.Name
+}
+
+// Packages installed on images with Docker, and on images Docker is installed on
+var (
+	basePackages          = []string{"e2fsprogs", "parted", "htop", "vim"}
+	dockerInstallPackages = []string{
+		"apt-transport-https", "ca-certificates", "curl", "gnupg", "lsb-release",
+		"software-properties-common", "unzip", "wget", "htop", "vim", "e2fsprogs", "parted",
+	}
+)
+
+// document is the data the templates render: the configuration with defaults applied
+type document struct {
+	Config
+	AllPackages []string
+	AllVolumes  []Volume
+}
+
+// SetupLog returns where the output of the setup is kept
+func (d *document) SetupLog() string {
+	return SetupLog
+}
+
+// SetupMarker returns the file marking the setup as complete
+func (d *document) SetupMarker() string {
+	return SetupMarker
+}
+
+// SetupMarkerDir returns the directory of SetupMarker
+func (d *document) SetupMarkerDir() string {
+	return path.Dir(SetupMarker)
+}
+
+// TLSCACert returns where the daemon's CA certificate is installed
+func (d *document) TLSCACert() string {
+	return dockertls.RemoteCACert
+}
+
+// TLSCert returns where the daemon's certificate is installed
+func (d *document) TLSCert() string {
+	return dockertls.RemoteServerCert
+}
+
+// TLSKey returns where the daemon's key is installed
+func (d *document) TLSKey() string {
+	return dockertls.RemoteServerKey
+}
+
+// newDocument applies defaults to config
+func newDocument(config Config) *document {
+	if config.DataRoot == "" {
+		config.DataRoot = DefaultDataRoot
+	}
+	if config.DockerAPIPort == 0 {
+		config.DockerAPIPort = DefaultDockerAPIPort
+	}
+	if config.KeepAlivePort == 0 {
+		config.KeepAlivePort = DefaultKeepAlivePort
+	}
+	if config.SSHPort == 0 {
+		config.SSHPort = DefaultSSHPort
+	}
+
+	var volumes []Volume
+	if config.DataVolume != nil {
+		dataVolume := *config.DataVolume
+		if dataVolume.Name == "" {
+			dataVolume.Name = dockerDataVolume
+		}
+		dataVolume.Mount = config.DataRoot
+		volumes = append(volumes, dataVolume)
+	}
+	volumes = append(volumes, config.Volumes...)
+
+	packages := basePackages
+	if config.InstallDocker {
+		packages = dockerInstallPackages
+	}
+
+	return &document{
+		Config:      config,
+		AllPackages: append(append([]string(nil), packages...), config.Packages...),
+		AllVolumes:  volumes,
+	}
+}
+
+// Render renders the cloud-config of config and validates it
+func Render(config Config) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "cloud-config", newDocument(config)); err != nil {
+		return "", fmt.Errorf("failed to render cloud-config: %w", err)
+	}
+
+	output := buf.String()
+	if err := Validate(output); err != nil {
+		return "", fmt.Errorf("rendered an invalid cloud-config: %w", err)
+	}
+	return output, nil
+}
+
+// newTemplate returns the root template with the functions the templates use
+func newTemplate() *template.Template {
+	root := template.New("cloudinit")
+	return root.Funcs(template.FuncMap{
+		// include renders a named template to a string, so it can be piped to indent
+		"include": func(name string, data any) (string, error) {
+			var buf bytes.Buffer
+			err := root.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+		"indent":     indent,
+		"quote":      quote,
+		"shellquote": shellQuote,
+	})
+}
+
+// indent prefixes every non-empty line of text with spaces, for embedding scripts in
+// YAML block scalars
+func indent(spaces int, text string) string {
+	prefix := strings.Repeat(" ", spaces)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote returns value as a YAML double-quoted scalar; JSON strings are valid YAML.
+// Shell redirections are left readable rather than escaped for HTML.
+func quote(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// shellQuote returns value as a single-quoted shell word
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
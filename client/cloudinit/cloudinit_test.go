@@ -0,0 +1,188 @@
+package cloudinit
+
+import (
+	"encoding/base64"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name:   "docker-image",
+			config: Config{},
+		},
+		{
+			name:   "install-docker",
+			config: Config{InstallDocker: true, Packages: []string{"jq"}},
+		},
+		{
+			name: "full",
+			config: Config{
+				SSHAuthorizedKeys:   []string{"ssh-ed25519 AAAAC3Nza test@example.com"},
+				Users:               []string{"alice"},
+				DataVolume:          &Volume{Device: "/dev/disk/by-id/scsi-0HC_Volume_1"},
+				Volumes:             []Volume{{Name: "data", Mount: "/data", Device: "/dev/disk/by-id/scsi-0HC_Volume_2"}},
+				DockerAPIPort:       2377,
+				DockerTLS:           &dockertls.Bundle{CACert: []byte("CA\n"), ServerCert: []byte("CERT\n"), ServerKey: []byte("KEY\n")},
+				ServerVersion:       "v1.2.3",
+				KeepAlivePort:       9090,
+				KeepAliveToken:      "secret",
+				KeepAliveOverSSH:    true,
+				WriteFiles:          []File{{Path: "/etc/agent.conf", Content: []byte("key=1\n"), Permissions: "0600"}},
+				PreDocker:           []string{"/var/lib/dockbridge/provisioning/pre-docker-01"},
+				PostDocker:          []string{"/var/lib/dockbridge/provisioning/post-docker-01"},
+				RegistryMirror:      "http://127.0.0.1:5000",
+				RegistryMirrorSetup: "docker run -d --name registry-cache registry:2\n",
+				Buildx:              true,
+				SSHPort:             2222,
+				OSUpdates:           "systemctl enable --now unattended-upgrades\n",
+				Backup:              "systemctl enable --now dockbridge-backup.timer\n",
+				ServerName:          "dockbridge-1700000000",
+			},
+		},
+		{
+			name: "unix-transport",
+			config: Config{
+				InstallDocker:  true,
+				DockerUnixOnly: true,
+				DockerTLS:      &dockertls.Bundle{CACert: []byte("CA\n"), ServerCert: []byte("CERT\n"), ServerKey: []byte("KEY\n")},
+				Volumes: []Volume{
+					{Name: "data", Mount: "/data", Device: "/dev/disk/by-id/scsi-0HC_Volume_2", Encrypted: true},
+				},
+				UploadServer: true,
+				ServerName:   "dockbridge-work-1700000000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := Render(tt.config)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(output), 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), output, "run go test -update to accept changes")
+		})
+	}
+}
+
+func TestRenderQuotesValues(t *testing.T) {
+	output, err := Render(Config{SSHAuthorizedKeys: []string{`ssh-ed25519 AAAA me: "laptop" # work`}})
+	require.NoError(t, err)
+
+	var config struct {
+		Keys []string `yaml:"ssh_authorized_keys"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &config))
+	assert.Equal(t, []string{`ssh-ed25519 AAAA me: "laptop" # work`}, config.Keys)
+}
+
+func TestRenderUploadServer(t *testing.T) {
+	output, err := Render(Config{UploadServer: true})
+	require.NoError(t, err)
+
+	assert.NotContains(t, output, "releases/", "the binary is not downloaded")
+	assert.NotContains(t, output, "placeholder", "no placeholder stands in for the binary")
+	assert.Contains(t, output, "ConditionFileIsExecutable=/usr/local/bin/dockbridge-server")
+	assert.Contains(t, output, "systemctl enable dockbridge-server")
+}
+
+func TestRenderProvisioning(t *testing.T) {
+	output, err := Render(Config{
+		WriteFiles: []File{{Path: "/etc/agent.conf", Content: []byte("key: \"value\"\n"), Permissions: "0600", Owner: "root:root"}},
+		PreDocker:  []string{"/var/lib/dockbridge/provisioning/pre-docker-01"},
+		PostDocker: []string{"bash /var/lib/dockbridge/provisioning/post-docker-01"},
+	})
+	require.NoError(t, err)
+
+	var config struct {
+		RunCmd     []any `yaml:"runcmd"`
+		WriteFiles []struct {
+			Path        string `yaml:"path"`
+			Content     string `yaml:"content"`
+			Encoding    string `yaml:"encoding"`
+			Permissions string `yaml:"permissions"`
+			Owner       string `yaml:"owner"`
+		} `yaml:"write_files"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &config))
+
+	require.NotEmpty(t, config.RunCmd)
+	assert.Equal(t, "/var/lib/dockbridge/provisioning/pre-docker-01", config.RunCmd[0], "pre-docker steps run first")
+	assert.Equal(t, "bash /var/lib/dockbridge/provisioning/post-docker-01", config.RunCmd[len(config.RunCmd)-1], "post-docker steps run last")
+
+	file := config.WriteFiles[len(config.WriteFiles)-1]
+	assert.Equal(t, "/etc/agent.conf", file.Path)
+	assert.Equal(t, "b64", file.Encoding)
+	assert.Equal(t, "0600", file.Permissions)
+	assert.Equal(t, "root:root", file.Owner)
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	require.NoError(t, err)
+	assert.Equal(t, "key: \"value\"\n", string(content))
+}
+
+func TestRenderScriptsAreIndented(t *testing.T) {
+	output, err := Render(Config{})
+	require.NoError(t, err)
+
+	var config struct {
+		RunCmd []any `yaml:"runcmd"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &config))
+
+	// Scripts embedded in a command, like the placeholder server, stay part of it
+	var install string
+	for _, command := range config.RunCmd {
+		if script, ok := command.(string); ok && strings.Contains(script, "<< 'SCRIPT'") {
+			install = script
+		}
+	}
+	require.NotEmpty(t, install, "placeholder server command")
+	assert.Contains(t, install, "<< 'SCRIPT'\n#!/bin/bash\n")
+	assert.Contains(t, install, "\nSCRIPT\n", "the heredoc ends at the start of a line")
+}
+
+func TestValidate(t *testing.T) {
+	valid := "#cloud-config\npackages:\n  - vim\nruncmd:\n  - echo hi\n  - [ls, -l]\nwrite_files:\n  - path: /etc/motd\n    content: hi\n    permissions: '0644'\n"
+	assert.NoError(t, Validate(valid))
+
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{"missing header", "packages: []\n", "missing \"#cloud-config\" header"},
+		{"invalid YAML", "#cloud-config\nruncmd:\n  - |\n    echo\nSCRIPT body\n", "invalid YAML"},
+		{"unknown key", "#cloud-config\nruncmds:\n  - echo hi\n", `unknown key "runcmds"`},
+		{"wrong type", "#cloud-config\npackage_update: yes please\n", "package_update: expected a boolean"},
+		{"command list", "#cloud-config\nruncmd: echo hi\n", "runcmd: expected a list"},
+		{"file without path", "#cloud-config\nwrite_files:\n  - content: hi\n", "write_files: item 0: path"},
+		{"numeric permissions", "#cloud-config\nwrite_files:\n  - path: /etc/motd\n    permissions: 0644\n", "permissions: expected a string"},
+		{"user without name", "#cloud-config\nusers:\n  - groups: sudo\n", "users: item 0: name"},
+		{"unknown output stage", "#cloud-config\noutput:\n  boot: /var/log/setup.log\n", `output: unknown stage "boot"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, Validate(tt.document), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package cloudinit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// header must start every cloud-config document
+const header = "#cloud-config\n"
+
+// checker validates the value of a cloud-config key
+type checker func(value any) error
+
+// schema holds the cloud-config modules DockBridge uses, following the cloud-init
+// schema for their keys. Keys outside it are rejected, as they are typos or modules
+// cloud-init would silently ignore.
+var schema = map[string]checker{
+	"package_update":      boolean,
+	"package_upgrade":     boolean,
+	"packages":            listOf(scalar),
+	"ssh_authorized_keys": listOf(scalar),
+	"users":               listOf(user),
+	"runcmd":              listOf(command),
+	"write_files":         listOf(writeFile),
+	"final_message":       scalar,
+	"output":              output,
+}
+
+// Validate checks that document is a cloud-config cloud-init accepts: the header, valid
+// YAML and the schema of the keys it sets
+func Validate(document string) error {
+	if !strings.HasPrefix(document, header) {
+		return fmt.Errorf("missing %q header", strings.TrimSpace(header))
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(document), &config); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		check, ok := schema[key]
+		if !ok {
+			return fmt.Errorf("unknown key %q", key)
+		}
+		if err := check(config[key]); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// boolean accepts true and false
+func boolean(value any) error {
+	if _, ok := value.(bool); !ok {
+		return fmt.Errorf("expected a boolean, got %T", value)
+	}
+	return nil
+}
+
+// scalar accepts strings
+func scalar(value any) error {
+	if _, ok := value.(string); !ok {
+		return fmt.Errorf("expected a string, got %T", value)
+	}
+	return nil
+}
+
+// listOf accepts lists whose items pass check
+func listOf(check checker) checker {
+	return func(value any) error {
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("expected a list, got %T", value)
+		}
+		for i, item := range items {
+			if err := check(item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+}
+
+// command accepts a shell command or an argument list
+func command(value any) error {
+	if _, ok := value.([]any); ok {
+		return listOf(scalar)(value)
+	}
+	return scalar(value)
+}
+
+// output accepts the destinations of cloud-init's output by stage
+func output(value any) error {
+	destinations, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected destinations by stage, got %T", value)
+	}
+	for stage, destination := range destinations {
+		switch stage {
+		case "all", "init", "config", "final":
+		default:
+			return fmt.Errorf("unknown stage %q", stage)
+		}
+		if err := scalar(destination); err != nil {
+			return fmt.Errorf("%s: %w", stage, err)
+		}
+	}
+	return nil
+}
+
+// user accepts a user name or a user definition with a name
+func user(value any) error {
+	if _, ok := value.(string); ok {
+		return nil
+	}
+	definition, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected a name or a user definition, got %T", value)
+	}
+	if err := scalar(definition["name"]); err != nil {
+		return fmt.Errorf("name: %w", err)
+	}
+	return nil
+}
+
+// writeFile accepts a file definition with a path and string content and permissions;
+// unquoted permissions such as 0644 would be read as a number
+func writeFile(value any) error {
+	definition, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected a file definition, got %T", value)
+	}
+	if err := scalar(definition["path"]); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+	for _, key := range []string{"content", "permissions", "owner", "encoding"} {
+		if value, ok := definition[key]; ok {
+			if err := scalar(value); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
@@ -1,13 +1,29 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dockbridge/dockbridge/client/digitalocean"
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/client/osupdates"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/schedule"
+	"github.com/dockbridge/dockbridge/client/secrets"
+	sshclient "github.com/dockbridge/dockbridge/client/ssh"
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
 	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/spf13/viper"
 )
@@ -16,14 +32,28 @@ import (
 type Manager struct {
 	viper  *viper.Viper
 	config *config.ClientConfig
+
+	// profile is applied to the server settings on Load; empty applies none
+	profile string
+
+	// secretStore holds API tokens not set in the configuration; created on first use
+	secretStore secrets.Store
+	// sources records the settings changed after loading, by profile, secret store or flag
+	sources map[string]Source
+	// secretPassphrase unlocks the encrypted file backend; nil reads DOCKBRIDGE_SECRETS_PASSPHRASE
+	secretPassphrase func() (string, error)
+
+	// serverTypes validates Hetzner server types; created on first use
+	serverTypes *hetzner.ServerTypeCatalog
 }
 
 // NewManager creates a new configuration manager
 func NewManager() *Manager {
 	v := viper.New()
 	return &Manager{
-		viper:  v,
-		config: &config.ClientConfig{},
+		viper:   v,
+		config:  &config.ClientConfig{},
+		profile: os.Getenv(ProfileEnv),
 	}
 }
 
@@ -55,14 +85,38 @@ func (m *Manager) Load(configPath string) error {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := m.ApplyProfile(); err != nil {
+		return err
+	}
+	if err := m.ResolveAPIToken(); err != nil {
+		return err
+	}
+
 	// Validate configuration
 	if err := m.validate(); err != nil {
-		return fmt.Errorf("configuration validation failed: %w", err)
+		err = fmt.Errorf("configuration validation failed: %w", err)
+		if m.missingAPIToken() {
+			return dberrors.NewConfigError(dberrors.ErrCodeMissingAPIToken, "No cloud API token configured", err).
+				WithHint(`Run "dockbridge auth login", or set the API token in the environment or the config file`)
+		}
+		return err
 	}
 
 	return nil
 }
 
+// missingAPIToken reports whether the API token of the configured provider is unset
+func (m *Manager) missingAPIToken() bool {
+	switch m.providerName() {
+	case hetzner.ProviderName:
+		return m.config.Hetzner.APIToken == ""
+	case digitalocean.ProviderName:
+		return m.config.DigitalOcean.APIToken == ""
+	default:
+		return false
+	}
+}
+
 // GetConfig returns the loaded configuration
 func (m *Manager) GetConfig() *config.ClientConfig {
 	return m.config
@@ -99,40 +153,95 @@ func (m *Manager) setupViper(configPath string) {
 	m.viper.AutomaticEnv()
 
 	// Bind specific environment variables
-	m.viper.BindEnv("hetzner.api_token", "HETZNER_API_TOKEN")
-	m.viper.BindEnv("docker.socket_path", "DOCKER_SOCKET_PATH")
-	m.viper.BindEnv("logging.level", "LOG_LEVEL")
+	for key, env := range envBindings {
+		m.viper.BindEnv(key, env)
+	}
+}
+
+// envBindings are the environment variables read for settings besides DOCKBRIDGE_<KEY>
+var envBindings = map[string]string{
+	"hetzner.api_token":      "HETZNER_API_TOKEN",
+	"digitalocean.api_token": "DIGITALOCEAN_TOKEN",
+	"docker.socket_path":     "DOCKER_SOCKET_PATH",
+	"logging.level":          "LOG_LEVEL",
 }
 
 // setDefaults sets default configuration values
 func (m *Manager) setDefaults() {
 	// Hetzner defaults
+	m.viper.SetDefault("provider", hetzner.ProviderName)
+	m.viper.SetDefault("digitalocean.region", "fra1")
+	m.viper.SetDefault("digitalocean.size", "s-2vcpu-4gb")
+	m.viper.SetDefault("digitalocean.volume_size", 10)
+	m.viper.SetDefault("digitalocean.image", "ubuntu-24-04-x64")
 	m.viper.SetDefault("hetzner.server_type", "cpx21")
 	m.viper.SetDefault("hetzner.location", "fsn1")
 	m.viper.SetDefault("hetzner.volume_size", 10)
+	m.viper.SetDefault("hetzner.os_updates.enabled", false)
+	m.viper.SetDefault("hetzner.os_updates.security_only", true)
+	m.viper.SetDefault("hetzner.os_updates.auto_reboot", true)
+	m.viper.SetDefault("hetzner.os_updates.reboot_window", "03:00-05:00")
+	m.viper.SetDefault("hetzner.golden_image.enabled", false)
+	m.viper.SetDefault("hetzner.public_network", provider.PublicNetworkDual)
 
 	// Docker defaults
-	m.viper.SetDefault("docker.socket_path", "/var/run/docker.sock")
+	if runtime.GOOS == "windows" {
+		m.viper.SetDefault("docker.socket_path", localsocket.DefaultPipe)
+	} else {
+		m.viper.SetDefault("docker.socket_path", "/var/run/docker.sock")
+	}
 	m.viper.SetDefault("docker.proxy_port", 2376)
+	m.viper.SetDefault("docker.cache_ttl", "2s")
+	m.viper.SetDefault("docker.build_context_sync", false)
+	m.viper.SetDefault("docker.compression.enabled", false)
+	m.viper.SetDefault("docker.compression.level", 1)
+	m.viper.SetDefault("docker.registry_mirror.enabled", false)
+	m.viper.SetDefault("docker.registry_mirror.port", 5000)
+	m.viper.SetDefault("docker.registry_mirror.image", "registry:2")
+	m.viper.SetDefault("docker.tls.mode", "mtls")
+	m.viper.SetDefault("docker.remote_transport", "tcp")
+	m.viper.SetDefault("docker.local_semantics", string(config.LocalSemanticsWarn))
+	m.viper.SetDefault("docker.tcp_listener.enabled", false)
+	m.viper.SetDefault("docker.tcp_listener.listen", "127.0.0.1:2375")
+	m.viper.SetDefault("docker.request_queue.depth", 64)
+	m.viper.SetDefault("docker.request_queue.max_wait", "10m")
+	m.viper.SetDefault("docker.context.register", true)
+	m.viper.SetDefault("docker.context.use", false)
+	m.viper.SetDefault("docker.context.remove_on_exit", true)
 
 	// Activity defaults - Reasonable production values
 	m.viper.SetDefault("activity.idle_timeout", "5m")
 	m.viper.SetDefault("activity.connection_timeout", "30m")
 	m.viper.SetDefault("activity.grace_period", "30s")
+	m.viper.SetDefault("activity.lifecycle_policy", string(config.LifecyclePolicyDestroy))
 
 	// Keep-alive defaults
 	m.viper.SetDefault("keepalive.interval", "30s")
 	m.viper.SetDefault("keepalive.timeout", "5m")
 	m.viper.SetDefault("keepalive.retry_interval", "5s")
 	m.viper.SetDefault("keepalive.max_retries", 3)
+	m.viper.SetDefault("keepalive.sleep_hint", "10m")
+	m.viper.SetDefault("keepalive.transport", "http")
+	m.viper.SetDefault("keepalive.reprovision_on_wake", true)
 
 	// SSH defaults
 	homeDir, _ := os.UserHomeDir()
 	defaultKeyPath := filepath.Join(homeDir, ".dockbridge", "ssh", "id_rsa")
 	m.viper.SetDefault("ssh.key_path", defaultKeyPath)
+	m.viper.SetDefault("docker.tls.cert_dir", filepath.Join(homeDir, ".dockbridge", "certs"))
 	m.viper.SetDefault("ssh.port", 22)
 	m.viper.SetDefault("ssh.timeout", "30s")
 	m.viper.SetDefault("ssh.keep_alive", "30s")
+	m.viper.SetDefault("ssh.use_agent", true)
+	m.viper.SetDefault("ssh.key_type", "ed25519")
+	m.viper.SetDefault("ssh.known_hosts_path", filepath.Join(homeDir, ".dockbridge", "known_hosts"))
+	m.viper.SetDefault("ssh.host_key_checking", "tofu")
+	m.viper.SetDefault("ssh.reconnect.enabled", true)
+	m.viper.SetDefault("ssh.reconnect.max_missed", 2)
+	m.viper.SetDefault("ssh.reconnect.max_attempts", 5)
+	m.viper.SetDefault("ssh.channel_pool.connections", 4)
+	m.viper.SetDefault("ssh.channel_pool.max_channels", 32)
+	m.viper.SetDefault("ssh.channel_pool.acquire_timeout", "30s")
 
 	// Logging defaults
 	m.viper.SetDefault("logging.level", "info")
@@ -141,17 +250,93 @@ func (m *Manager) setDefaults() {
 
 	// Port forwarding defaults
 	m.viper.SetDefault("port_forward.enabled", true)
-	m.viper.SetDefault("port_forward.conflict_strategy", "increment")
+	m.viper.SetDefault("port_forward.conflict_strategy", "auto")
 	m.viper.SetDefault("port_forward.monitor_interval", "30s")
+	m.viper.SetDefault("port_forward.socket_dir", filepath.Join(homeDir, ".dockbridge", "sockets"))
+	m.viper.SetDefault("port_forward.proxy_protocol", false)
+	m.viper.SetDefault("port_forward.bind_address", "127.0.0.1")
+	m.viper.SetDefault("port_forward.allow_external", false)
+
+	// Desktop notification defaults
+	m.viper.SetDefault("notifications.desktop.enabled", true)
+	m.viper.SetDefault("notifications.desktop.server_ready", true)
+	m.viper.SetDefault("notifications.desktop.provisioning_failed", true)
+	m.viper.SetDefault("notifications.desktop.keepalive_warning", true)
+	m.viper.SetDefault("notifications.desktop.self_destruct", true)
+	m.viper.SetDefault("notifications.desktop.budget_threshold", true)
+	m.viper.SetDefault("notifications.desktop.traffic_warning", true)
+
+	// Traffic warning defaults
+	m.viper.SetDefault("traffic.warn_percent", 80)
+	m.viper.SetDefault("traffic.critical_percent", 95)
+
+	// Secret storage defaults
+	m.viper.SetDefault("secrets.backend", "auto")
+	m.viper.SetDefault("secrets.file", "~/.dockbridge/secrets.enc")
+
+	// Control API defaults
+	m.viper.SetDefault("control.enabled", true)
+	m.viper.SetDefault("control.socket_path", "")
+	m.viper.SetDefault("control.http_listen", "")
+
+	// Metrics endpoint defaults
+	m.viper.SetDefault("metrics.enabled", false)
+	m.viper.SetDefault("metrics.listen", "127.0.0.1:9466")
+
+	// Tracing defaults
+	m.viper.SetDefault("telemetry.enabled", false)
+	m.viper.SetDefault("telemetry.endpoint", "")
+	m.viper.SetDefault("telemetry.insecure", true)
+	m.viper.SetDefault("telemetry.service_name", "dockbridge")
+	m.viper.SetDefault("telemetry.sample_ratio", 1.0)
+
+	// Budget defaults
+	m.viper.SetDefault("budget.monthly", 0.0)
+	m.viper.SetDefault("budget.warn_percent", 80)
+	m.viper.SetDefault("budget.action", "warn")
+
+	// Volume backup defaults
+	m.viper.SetDefault("backup.enabled", false)
+	m.viper.SetDefault("backup.paths", []string{"/var/lib/docker/volumes"})
+	m.viper.SetDefault("backup.interval", "6h")
+	m.viper.SetDefault("backup.keep_daily", 7)
+	m.viper.SetDefault("backup.keep_weekly", 4)
+	m.viper.SetDefault("backup.keep_monthly", 6)
+
+	// File sync defaults
+	m.viper.SetDefault("file_sync.enabled", false)
+	m.viper.SetDefault("file_sync.exclude", []string{".git"})
+	m.viper.SetDefault("file_sync.interval", "2s")
+	m.viper.SetDefault("file_sync.remote_root", "/var/lib/docker/dockbridge-sync")
+
+	// dockbridge-server binary defaults
+	m.viper.SetDefault("server_binary.source", "download")
+
+	// Working hours schedule defaults
+	m.viper.SetDefault("schedule.enabled", false)
+	m.viper.SetDefault("schedule.working_hours", "09:00-18:00")
+	m.viper.SetDefault("schedule.days", []string{"mon", "tue", "wed", "thu", "fri"})
+	m.viper.SetDefault("schedule.prewarm", "10m")
+	m.viper.SetDefault("schedule.off_hours_idle", "10m")
+	m.viper.SetDefault("schedule.action", string(config.LifecyclePolicyDestroy))
 }
 
 // validate performs comprehensive configuration validation
 func (m *Manager) validate() error {
 	var errors []string
 
-	// Validate Hetzner configuration
-	if err := m.validateHetzner(); err != nil {
-		errors = append(errors, fmt.Sprintf("hetzner: %v", err))
+	// Validate the cloud provider; the hetzner section is only checked when it is used
+	switch providerName := m.providerName(); {
+	case !provider.IsRegistered(providerName):
+		errors = append(errors, fmt.Sprintf("provider: unknown provider '%s', must be one of: %s", providerName, strings.Join(provider.Registered(), ", ")))
+	case providerName == hetzner.ProviderName:
+		if err := m.validateHetzner(); err != nil {
+			errors = append(errors, fmt.Sprintf("hetzner: %v", err))
+		}
+	case providerName == digitalocean.ProviderName:
+		if err := m.validateDigitalOcean(); err != nil {
+			errors = append(errors, fmt.Sprintf("digitalocean: %v", err))
+		}
 	}
 
 	// Validate Docker configuration
@@ -184,6 +369,84 @@ func (m *Manager) validate() error {
 		errors = append(errors, fmt.Sprintf("port_forward: %v", err))
 	}
 
+	// Validate traffic warning thresholds
+	if err := m.validateTraffic(); err != nil {
+		errors = append(errors, fmt.Sprintf("traffic: %v", err))
+	}
+
+	// Validate additional daemon contexts
+	if err := m.validateContexts(); err != nil {
+		errors = append(errors, fmt.Sprintf("contexts: %v", err))
+	}
+
+	// Validate server profiles
+	if err := m.validateProfiles(); err != nil {
+		errors = append(errors, fmt.Sprintf("profiles: %v", err))
+	}
+
+	// Validate control API configuration
+	if socketPath := m.config.Control.SocketPath; socketPath != "" && !filepath.IsAbs(socketPath) {
+		errors = append(errors, fmt.Sprintf("control: socket_path must be an absolute path, got '%s'", socketPath))
+	}
+	if err := m.validateControlHTTP(); err != nil {
+		errors = append(errors, fmt.Sprintf("control: %v", err))
+	}
+
+	// Validate metrics endpoint configuration
+	if err := m.validateMetrics(); err != nil {
+		errors = append(errors, fmt.Sprintf("metrics: %v", err))
+	}
+
+	// Validate tracing configuration
+	if err := m.validateTelemetry(); err != nil {
+		errors = append(errors, fmt.Sprintf("telemetry: %v", err))
+	}
+
+	// Validate budget configuration
+	if err := m.validateBudget(); err != nil {
+		errors = append(errors, fmt.Sprintf("budget: %v", err))
+	}
+
+	// Validate volume backups
+	if err := m.validateBackup(); err != nil {
+		errors = append(errors, fmt.Sprintf("backup: %v", err))
+	}
+
+	// Validate lifecycle hooks
+	if err := m.validateHooks(); err != nil {
+		errors = append(errors, fmt.Sprintf("hooks: %v", err))
+	}
+
+	// Validate notification sinks
+	if err := m.validateNotificationSinks(); err != nil {
+		errors = append(errors, fmt.Sprintf("notifications: %v", err))
+	}
+
+	// Validate user-supplied provisioning
+	if err := m.validateProvisioning(); err != nil {
+		errors = append(errors, fmt.Sprintf("provisioning: %v", err))
+	}
+
+	// Validate bind mount file sync
+	if err := m.validateFileSync(); err != nil {
+		errors = append(errors, fmt.Sprintf("file_sync: %v", err))
+	}
+
+	// Validate the working hours schedule
+	if err := validateSchedule(m.config.Schedule); err != nil {
+		errors = append(errors, fmt.Sprintf("schedule: %v", err))
+	}
+
+	// Validate the secrets backend
+	if backend := m.config.Secrets.Backend; !slices.Contains(secretBackends, backend) {
+		errors = append(errors, fmt.Sprintf("secrets: invalid backend '%s', must be one of: %s", backend, strings.Join(secretBackends, ", ")))
+	}
+
+	// Validate how servers get dockbridge-server
+	if err := m.validateServerBinary(); err != nil {
+		errors = append(errors, fmt.Sprintf("server_binary: %v", err))
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors:\n  - %s", strings.Join(errors, "\n  - "))
 	}
@@ -191,23 +454,27 @@ func (m *Manager) validate() error {
 	return nil
 }
 
+// validLocations lists the Hetzner locations accepted in configuration
+var validLocations = []string{"fsn1", "nbg1", "hel1", "ash", "hil"}
+
+// contextNamePattern restricts context names to what is safe in server names and socket file names
+var contextNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,30}$`)
+
 // validateHetzner validates Hetzner-specific configuration
 func (m *Manager) validateHetzner() error {
 	hetzner := &m.config.Hetzner
 
 	// API token is required
 	if hetzner.APIToken == "" {
-		return fmt.Errorf("api_token is required (set via HETZNER_API_TOKEN environment variable or config file)")
+		return fmt.Errorf("api_token is required (set via HETZNER_API_TOKEN environment variable, dockbridge auth login or config file)")
 	}
 
 	// Validate server type
-	validServerTypes := []string{"cx11", "cpx11", "cx21", "cx23", "cpx21", "cx31", "cpx31", "cx41", "cpx41", "cx51", "cpx51"}
-	if !slices.Contains(validServerTypes, hetzner.ServerType) {
-		return fmt.Errorf("invalid server_type '%s', must be one of: %s", hetzner.ServerType, strings.Join(validServerTypes, ", "))
+	if err := m.validateServerType(hetzner.ServerType); err != nil {
+		return err
 	}
 
 	// Validate location
-	validLocations := []string{"fsn1", "nbg1", "hel1", "ash", "hil"}
 	if !slices.Contains(validLocations, hetzner.Location) {
 		return fmt.Errorf("invalid location '%s', must be one of: %s", hetzner.Location, strings.Join(validLocations, ", "))
 	}
@@ -217,6 +484,94 @@ func (m *Manager) validateHetzner() error {
 		return fmt.Errorf("volume_size must be between 10 and 10000 GB, got %d", hetzner.VolumeSize)
 	}
 
+	if err := validateVolumes(hetzner.Volumes); err != nil {
+		return fmt.Errorf("volumes: %w", err)
+	}
+	if hetzner.EncryptVolumes && len(hetzner.Volumes) == 0 {
+		return fmt.Errorf("encrypt_volumes requires volumes to be configured")
+	}
+
+	if err := validateFirewall(hetzner.Firewall); err != nil {
+		return fmt.Errorf("firewall: %w", err)
+	}
+
+	if err := m.validatePublicNetwork(); err != nil {
+		return err
+	}
+
+	// Validate OS update reboot window
+	if hetzner.OSUpdates.Enabled && hetzner.OSUpdates.AutoReboot {
+		if _, err := osupdates.ParseWindow(hetzner.OSUpdates.RebootWindow); err != nil {
+			return fmt.Errorf("os_updates: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validatePublicNetwork validates the public addresses of servers and, for servers
+// without any, how the client reaches them
+func (m *Manager) validatePublicNetwork() error {
+	hetzner := &m.config.Hetzner
+	switch hetzner.PublicNetwork {
+	case "", provider.PublicNetworkDual, provider.PublicNetworkIPv6:
+		return nil
+	case provider.PublicNetworkNone:
+	default:
+		return fmt.Errorf("public_network must be 'dual', 'ipv6' or 'none', got '%s'", hetzner.PublicNetwork)
+	}
+
+	// Servers without public addresses are only reachable through their private network
+	if hetzner.Network == "" {
+		return fmt.Errorf("public_network 'none' requires network")
+	}
+	if m.config.SSH.JumpHost == "" {
+		return fmt.Errorf("public_network 'none' requires ssh.jump_host inside the network")
+	}
+	if m.config.KeepAlive.Transport != "ssh" {
+		return fmt.Errorf("public_network 'none' requires keepalive.transport 'ssh'")
+	}
+	return nil
+}
+
+// validateFirewall validates the cloud firewall of servers
+func validateFirewall(firewall config.FirewallConfig) error {
+	if firewall.Name == "" && len(firewall.SourceIPs) > 0 {
+		return fmt.Errorf("source_ips requires a name")
+	}
+	for _, source := range firewall.SourceIPs {
+		if _, _, err := net.ParseCIDR(source); err != nil {
+			return fmt.Errorf("invalid source IP range '%s', must be a CIDR such as 203.0.113.0/24", source)
+		}
+	}
+	return nil
+}
+
+// validateVolumes validates named persistent volumes
+func validateVolumes(volumes []config.VolumeConfig) error {
+	names := make(map[string]bool, len(volumes))
+	mounts := make(map[string]bool, len(volumes))
+	for _, volume := range volumes {
+		if !contextNamePattern.MatchString(volume.Name) {
+			return fmt.Errorf("invalid volume name '%s', must match %s", volume.Name, contextNamePattern.String())
+		}
+		if names[volume.Name] {
+			return fmt.Errorf("duplicate volume name '%s'", volume.Name)
+		}
+		names[volume.Name] = true
+
+		if volume.Size < 10 || volume.Size > 10000 {
+			return fmt.Errorf("volume '%s': size must be between 10 and 10000 GB, got %d", volume.Name, volume.Size)
+		}
+
+		if !filepath.IsAbs(volume.Mount) || filepath.Clean(volume.Mount) != volume.Mount || volume.Mount == "/" {
+			return fmt.Errorf("volume '%s': mount must be a clean absolute path other than /, got '%s'", volume.Name, volume.Mount)
+		}
+		if mounts[volume.Mount] {
+			return fmt.Errorf("volume '%s': mount %s is used by another volume", volume.Name, volume.Mount)
+		}
+		mounts[volume.Mount] = true
+	}
 	return nil
 }
 
@@ -225,7 +580,7 @@ func (m *Manager) validateDocker() error {
 	docker := &m.config.Docker
 
 	// Validate socket path directory exists and is writable (DockBridge will create the socket)
-	if docker.SocketPath != "/var/run/docker.sock" && docker.SocketPath != "tcp" && !strings.HasPrefix(docker.SocketPath, ":") {
+	if docker.SocketPath != "/var/run/docker.sock" && docker.SocketPath != "tcp" && !strings.HasPrefix(docker.SocketPath, ":") && !localsocket.IsNamedPipe(docker.SocketPath) {
 		// Check if the directory exists and is writable
 		dir := filepath.Dir(docker.SocketPath)
 		if _, err := os.Stat(dir); err != nil {
@@ -248,6 +603,101 @@ func (m *Manager) validateDocker() error {
 		return fmt.Errorf("proxy_port must be between 1024 and 65535, got %d", docker.ProxyPort)
 	}
 
+	// Validate response cache TTL
+	if docker.CacheTTL < 0 || docker.CacheTTL > time.Minute {
+		return fmt.Errorf("cache_ttl must be between 0 and 1m, got %v", docker.CacheTTL)
+	}
+
+	switch docker.TLS.Mode {
+	case "", "mtls", "off":
+	default:
+		return fmt.Errorf("tls.mode must be 'mtls' or 'off', got '%s'", docker.TLS.Mode)
+	}
+
+	if docker.Compression.Enabled && (docker.Compression.Level < 1 || docker.Compression.Level > 9) {
+		return fmt.Errorf("compression.level must be between 1 and 9, got %d", docker.Compression.Level)
+	}
+
+	if mirror := docker.RegistryMirror; mirror.Enabled {
+		if mirror.Port < 1024 || mirror.Port > 65535 || mirror.Port == 2376 {
+			return fmt.Errorf("registry_mirror.port must be between 1024 and 65535 and not the Docker API port 2376, got %d", mirror.Port)
+		}
+		if mirror.Image == "" {
+			return fmt.Errorf("registry_mirror.image is required when the registry mirror is enabled")
+		}
+	}
+
+	switch docker.RemoteTransport {
+	case "", "tcp", "unix":
+	default:
+		return fmt.Errorf("remote_transport must be 'tcp' or 'unix', got '%s'", docker.RemoteTransport)
+	}
+
+	switch docker.LocalSemantics {
+	case "", config.LocalSemanticsWarn, config.LocalSemanticsBlock, config.LocalSemanticsIgnore:
+	default:
+		return fmt.Errorf("local_semantics must be 'warn', 'block' or 'ignore', got '%s'", docker.LocalSemantics)
+	}
+
+	if err := validateTCPListener(&docker.TCPListener); err != nil {
+		return fmt.Errorf("tcp_listener: %w", err)
+	}
+
+	if docker.RequestQueue.Depth < 0 || docker.RequestQueue.Depth > 10000 {
+		return fmt.Errorf("request_queue.depth must be between 0 and 10000, got %d", docker.RequestQueue.Depth)
+	}
+	if docker.RequestQueue.Depth > 0 && (docker.RequestQueue.MaxWait < time.Second || docker.RequestQueue.MaxWait > time.Hour) {
+		return fmt.Errorf("request_queue.max_wait must be between 1s and 1h, got %v", docker.RequestQueue.MaxWait)
+	}
+
+	for i, auth := range docker.RegistryAuth {
+		if auth.Registry == "" || strings.Contains(auth.Registry, "/") {
+			return fmt.Errorf("registry_auth[%d]: registry must be a registry host such as 'registry.example.com', got '%s'", i, auth.Registry)
+		}
+		if auth.Username == "" && auth.Password != "" {
+			return fmt.Errorf("registry_auth[%d]: password requires a username", i)
+		}
+	}
+
+	return nil
+}
+
+// validateTCPListener validates the daemon's TCP listener; listening beyond loopback
+// requires client certificates
+func validateTCPListener(listener *config.TCPListenerConfig) error {
+	if !listener.Enabled {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(listener.Listen)
+	if err != nil {
+		return fmt.Errorf("invalid listen address '%s': %w", listener.Listen, err)
+	}
+	if (listener.CertFile == "") != (listener.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if listener.ClientCAFile != "" && listener.CertFile == "" {
+		return fmt.Errorf("client_ca_file requires cert_file and key_file")
+	}
+	if ip := net.ParseIP(host); (ip == nil || !ip.IsLoopback()) && host != "localhost" && listener.ClientCAFile == "" {
+		return fmt.Errorf("listen address '%s' is not a loopback address; set client_ca_file to require client certificates", listener.Listen)
+	}
+	return nil
+}
+
+// validateServerType checks a Hetzner server type against the API, falling back to
+// cached or built-in server types when offline
+func (m *Manager) validateServerType(serverType string) error {
+	if m.serverTypes == nil {
+		m.serverTypes = hetzner.NewAPIServerTypeCatalog(hetzner.DefaultServerTypeCachePath(), m.config.Hetzner.APIToken)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, ok := m.serverTypes.Lookup(ctx, serverType); !ok {
+		names := hetzner.ServerTypeNames(m.serverTypes.Offline())
+		return fmt.Errorf("invalid server_type '%s', must be one of: %s", serverType, strings.Join(names, ", "))
+	}
 	return nil
 }
 
@@ -275,6 +725,12 @@ func (m *Manager) validateActivity() error {
 		return fmt.Errorf("connection_timeout (%v) must be greater than idle_timeout (%v)", activity.ConnectionTimeout, activity.IdleTimeout)
 	}
 
+	// Validate lifecycle policy
+	validPolicies := []string{string(config.LifecyclePolicyDestroy), string(config.LifecyclePolicyPowerOff)}
+	if !slices.Contains(validPolicies, string(activity.LifecyclePolicy)) {
+		return fmt.Errorf("invalid lifecycle_policy '%s', must be one of: %s", activity.LifecyclePolicy, strings.Join(validPolicies, ", "))
+	}
+
 	return nil
 }
 
@@ -300,6 +756,20 @@ func (m *Manager) validateKeepAlive() error {
 		return fmt.Errorf("max_retries must be between 0 and 10, got %d", keepAlive.MaxRetries)
 	}
 
+	if keepAlive.SleepHint < 0 {
+		return fmt.Errorf("sleep_hint must not be negative, got %v", keepAlive.SleepHint)
+	}
+
+	switch keepAlive.Transport {
+	case "", "http", "ssh":
+	default:
+		return fmt.Errorf("transport must be 'http' or 'ssh', got '%s'", keepAlive.Transport)
+	}
+
+	if id := keepAlive.ClientID; len(id) > 255 || strings.ContainsFunc(id, func(r rune) bool { return r <= ' ' || r > '~' }) {
+		return fmt.Errorf("client_id must be at most 255 printable ASCII characters without spaces, got '%s'", id)
+	}
+
 	return nil
 }
 
@@ -327,6 +797,44 @@ func (m *Manager) validateSSH() error {
 		return fmt.Errorf("keep_alive must be at least 1 second, got %v", ssh.KeepAlive)
 	}
 
+	switch ssh.KeyType {
+	case "", "ed25519", "rsa":
+	default:
+		return fmt.Errorf("key_type must be 'ed25519' or 'rsa', got '%s'", ssh.KeyType)
+	}
+
+	switch ssh.HostKeyChecking {
+	case "", "tofu", "strict", "off":
+	default:
+		return fmt.Errorf("host_key_checking must be 'tofu', 'strict' or 'off', got '%s'", ssh.HostKeyChecking)
+	}
+
+	if ssh.Reconnect.Enabled {
+		if ssh.Reconnect.MaxMissed < 1 {
+			return fmt.Errorf("reconnect.max_missed must be at least 1, got %d", ssh.Reconnect.MaxMissed)
+		}
+		if ssh.Reconnect.MaxAttempts < 0 {
+			return fmt.Errorf("reconnect.max_attempts must not be negative, got %d", ssh.Reconnect.MaxAttempts)
+		}
+	}
+
+	pool := &ssh.ChannelPool
+	if pool.Connections < 0 || pool.Connections > 16 {
+		return fmt.Errorf("channel_pool.connections must be between 0 and 16, got %d", pool.Connections)
+	}
+	if pool.MaxChannels < 0 {
+		return fmt.Errorf("channel_pool.max_channels must not be negative, got %d", pool.MaxChannels)
+	}
+	if pool.AcquireTimeout < 0 {
+		return fmt.Errorf("channel_pool.acquire_timeout must not be negative, got %v", pool.AcquireTimeout)
+	}
+
+	if ssh.JumpHost != "" {
+		if _, _, err := sshclient.ParseJumpHost(ssh.JumpHost, "root"); err != nil {
+			return fmt.Errorf("jump_host: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -355,12 +863,396 @@ func (m *Manager) validateLogging() error {
 	return nil
 }
 
+// validateTraffic validates traffic warning thresholds
+func (m *Manager) validateTraffic() error {
+	traffic := &m.config.Traffic
+
+	if traffic.WarnPercent < 1 || traffic.WarnPercent > 100 {
+		return fmt.Errorf("warn_percent must be between 1 and 100, got %d", traffic.WarnPercent)
+	}
+	if traffic.CriticalPercent < traffic.WarnPercent || traffic.CriticalPercent > 100 {
+		return fmt.Errorf("critical_percent must be between warn_percent and 100, got %d", traffic.CriticalPercent)
+	}
+
+	return nil
+}
+
+// validateControlHTTP validates the REST endpoint of the control API, which has no
+// authentication and therefore only listens on loopback addresses
+func (m *Manager) validateControlHTTP() error {
+	listen := m.config.Control.HTTPListen
+	if listen == "" {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return fmt.Errorf("invalid http_listen address '%s': %w", listen, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("http_listen must be a loopback address, got '%s'", listen)
+	}
+	return nil
+}
+
+// validateMetrics validates the metrics endpoint configuration
+func (m *Manager) validateMetrics() error {
+	metrics := &m.config.Metrics
+	if !metrics.Enabled {
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(metrics.Listen); err != nil {
+		return fmt.Errorf("invalid listen address '%s': %w", metrics.Listen, err)
+	}
+	return nil
+}
+
+// validateTelemetry validates the tracing configuration
+func (m *Manager) validateTelemetry() error {
+	telemetry := &m.config.Telemetry
+	if !telemetry.Enabled {
+		return nil
+	}
+
+	if telemetry.Endpoint != "" {
+		if _, _, err := net.SplitHostPort(telemetry.Endpoint); err != nil {
+			return fmt.Errorf("endpoint must be host:port, got '%s'", telemetry.Endpoint)
+		}
+	}
+	if telemetry.ServiceName == "" {
+		return fmt.Errorf("service_name is required")
+	}
+	if telemetry.SampleRatio < 0 || telemetry.SampleRatio > 1 {
+		return fmt.Errorf("sample_ratio must be between 0 and 1, got %v", telemetry.SampleRatio)
+	}
+	return nil
+}
+
+// validateBudget validates the monthly spending limit
+func (m *Manager) validateBudget() error {
+	budget := &m.config.Budget
+
+	if budget.Monthly < 0 {
+		return fmt.Errorf("monthly must not be negative, got %v", budget.Monthly)
+	}
+	if budget.WarnPercent < 1 || budget.WarnPercent > 100 {
+		return fmt.Errorf("warn_percent must be between 1 and 100, got %d", budget.WarnPercent)
+	}
+	if budget.Action != "warn" && budget.Action != "block" {
+		return fmt.Errorf("action must be 'warn' or 'block', got '%s'", budget.Action)
+	}
+	return nil
+}
+
+// validateBackup validates scheduled volume backups
+func (m *Manager) validateBackup() error {
+	backup := &m.config.Backup
+	if !backup.Enabled {
+		return nil
+	}
+
+	if !strings.HasPrefix(backup.Repository, "s3:") && !strings.HasPrefix(backup.Repository, "b2:") {
+		return fmt.Errorf("repository must be an 's3:' or 'b2:' restic repository, got '%s'", backup.Repository)
+	}
+	if backup.Password == "" {
+		return fmt.Errorf("password is required to encrypt the repository")
+	}
+	if (backup.AccessKeyID == "") != (backup.SecretAccessKey == "") {
+		return fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+	if len(backup.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	for _, path := range backup.Paths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("path '%s' must be absolute", path)
+		}
+	}
+	if backup.Interval < time.Hour {
+		return fmt.Errorf("interval must be at least 1 hour, got %v", backup.Interval)
+	}
+	if backup.KeepDaily < 0 || backup.KeepWeekly < 0 || backup.KeepMonthly < 0 {
+		return fmt.Errorf("keep_daily, keep_weekly and keep_monthly must not be negative")
+	}
+	return nil
+}
+
+// validateFileSync validates bind mount file sync
+func (m *Manager) validateFileSync() error {
+	sync := &m.config.FileSync
+	if !sync.Enabled {
+		return nil
+	}
+
+	if len(sync.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	for _, path := range sync.Paths {
+		if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/") {
+			return fmt.Errorf("path '%s' must be absolute", path)
+		}
+	}
+	if !strings.HasPrefix(sync.RemoteRoot, "/") || strings.Trim(sync.RemoteRoot, "/") == "" {
+		return fmt.Errorf("remote_root must be an absolute directory other than /, got '%s'", sync.RemoteRoot)
+	}
+	if sync.Interval < 500*time.Millisecond {
+		return fmt.Errorf("interval must be at least 500ms, got %v", sync.Interval)
+	}
+	return nil
+}
+
+// validateServerBinary validates the source of the dockbridge-server binary
+func (m *Manager) validateServerBinary() error {
+	binary := &m.config.ServerBinary
+	switch binary.Source {
+	case "", "download":
+		if binary.Path != "" {
+			return fmt.Errorf("path requires source 'upload'")
+		}
+	case "upload":
+	default:
+		return fmt.Errorf("source must be 'download' or 'upload', got '%s'", binary.Source)
+	}
+	return nil
+}
+
+// validateSchedule validates a working hours schedule
+func validateSchedule(cfg config.ScheduleConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if _, err := schedule.New(cfg); err != nil {
+		return err
+	}
+	if cfg.Prewarm < 0 {
+		return fmt.Errorf("prewarm must not be negative, got %v", cfg.Prewarm)
+	}
+	if cfg.OffHoursIdle < 0 {
+		return fmt.Errorf("off_hours_idle must not be negative, got %v", cfg.OffHoursIdle)
+	}
+	validPolicies := []string{string(config.LifecyclePolicyDestroy), string(config.LifecyclePolicyPowerOff)}
+	if !slices.Contains(validPolicies, string(cfg.Action)) {
+		return fmt.Errorf("invalid action '%s', must be one of: %s", cfg.Action, strings.Join(validPolicies, ", "))
+	}
+	return nil
+}
+
+// validateNotificationSinks validates the sinks receiving lifecycle events
+func (m *Manager) validateNotificationSinks() error {
+	for i, sink := range m.config.Notifications.Sinks {
+		if !hooks.IsKnownSinkType(sink.Type) {
+			return fmt.Errorf("sinks[%d]: unknown type '%s', must be log, webhook, slack or desktop", i, sink.Type)
+		}
+		if len(sink.Events) == 0 {
+			return fmt.Errorf("sinks[%d]: at least one event is required", i)
+		}
+		for _, event := range sink.Events {
+			if !hooks.IsKnownEvent(event) {
+				return fmt.Errorf("sinks[%d]: unknown event '%s'", i, event)
+			}
+		}
+
+		switch sink.Type {
+		case hooks.SinkWebhook, hooks.SinkSlack:
+			u, err := url.Parse(sink.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("sinks[%d]: invalid url '%s', must be an http(s) URL", i, sink.URL)
+			}
+			if sink.Type == hooks.SinkSlack && u.Scheme != "https" {
+				return fmt.Errorf("sinks[%d]: the Slack webhook url must use https", i)
+			}
+		default:
+			if sink.URL != "" {
+				return fmt.Errorf("sinks[%d]: url is only used by webhook and slack sinks", i)
+			}
+		}
+
+		if sink.Timeout < 0 {
+			return fmt.Errorf("sinks[%d]: timeout cannot be negative", i)
+		}
+	}
+
+	return nil
+}
+
+// validateHooks validates lifecycle hook definitions
+func (m *Manager) validateHooks() error {
+	for i, hook := range m.config.Hooks {
+		if err := hooks.Validate(hook); err != nil {
+			return fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateProvisioning validates user-supplied provisioning steps and files
+func (m *Manager) validateProvisioning() error {
+	provisioning := &m.config.Provisioning
+
+	for _, stage := range []struct {
+		name  string
+		steps []config.ProvisioningStep
+	}{
+		{"pre_docker", provisioning.PreDocker},
+		{"post_docker", provisioning.PostDocker},
+	} {
+		for i, step := range stage.steps {
+			if (step.Run == "") == (step.Script == "") {
+				return fmt.Errorf("%s[%d]: set exactly one of run or script", stage.name, i)
+			}
+		}
+	}
+
+	for i, file := range provisioning.WriteFiles {
+		if !filepath.IsAbs(file.Path) {
+			return fmt.Errorf("write_files[%d]: path '%s' must be absolute", i, file.Path)
+		}
+		if file.Content != "" && file.Source != "" {
+			return fmt.Errorf("write_files[%d]: set either content or source, not both", i)
+		}
+		if file.Permissions != "" {
+			if _, err := strconv.ParseUint(file.Permissions, 8, 32); err != nil {
+				return fmt.Errorf("write_files[%d]: permissions '%s' must be octal, e.g. '0644'", i, file.Permissions)
+			}
+		}
+	}
+
+	return nil
+}
+
+// providerName returns the configured provider, falling back to the default
+func (m *Manager) providerName() string {
+	if m.config.Provider == "" {
+		return provider.DefaultName
+	}
+	return m.config.Provider
+}
+
+// validateDigitalOcean validates DigitalOcean configuration
+func (m *Manager) validateDigitalOcean() error {
+	do := &m.config.DigitalOcean
+
+	if do.APIToken == "" {
+		return fmt.Errorf("api_token is required (set via DIGITALOCEAN_TOKEN environment variable, dockbridge auth login or config file)")
+	}
+	if do.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+	if do.Size == "" {
+		return fmt.Errorf("size is required")
+	}
+	if do.Image == "" {
+		return fmt.Errorf("image is required")
+	}
+	if do.VolumeSize < 1 || do.VolumeSize > 16384 {
+		return fmt.Errorf("volume_size must be between 1 and 16384 GB, got %d", do.VolumeSize)
+	}
+
+	return nil
+}
+
+// validateContexts validates additional daemon contexts
+func (m *Manager) validateContexts() error {
+	names := make(map[string]bool)
+	sockets := map[string]bool{m.config.Docker.SocketPath: true}
+
+	for i, ctx := range m.config.Contexts {
+		if ctx.Name == DefaultContextName {
+			return fmt.Errorf("contexts[%d]: name '%s' is reserved", i, ctx.Name)
+		}
+		if !contextNamePattern.MatchString(ctx.Name) {
+			return fmt.Errorf("contexts[%d]: invalid name '%s', must match %s", i, ctx.Name, contextNamePattern.String())
+		}
+		if names[ctx.Name] {
+			return fmt.Errorf("contexts[%d]: duplicate name '%s'", i, ctx.Name)
+		}
+		names[ctx.Name] = true
+
+		if !strings.HasPrefix(ctx.SocketPath, "/") && !localsocket.IsNamedPipe(ctx.SocketPath) {
+			return fmt.Errorf("context '%s': socket_path must be an absolute Unix socket path or a Windows named pipe", ctx.Name)
+		}
+		if sockets[ctx.SocketPath] {
+			return fmt.Errorf("context '%s': socket_path '%s' is already used", ctx.Name, ctx.SocketPath)
+		}
+		sockets[ctx.SocketPath] = true
+
+		if err := m.validateServerShape(ctx.ServerType, ctx.Location, ctx.VolumeSize); err != nil {
+			return fmt.Errorf("context '%s': %w", ctx.Name, err)
+		}
+	}
+
+	if current := m.config.CurrentContext; current != "" && current != DefaultContextName && !names[current] {
+		return fmt.Errorf("current_context '%s' is not a configured context", current)
+	}
+
+	return nil
+}
+
+// validateProfiles validates the named server profiles
+func (m *Manager) validateProfiles() error {
+	names := make(map[string]bool)
+
+	for i, profile := range m.config.Profiles {
+		if !contextNamePattern.MatchString(profile.Name) {
+			return fmt.Errorf("profiles[%d]: invalid name '%s', must match %s", i, profile.Name, contextNamePattern.String())
+		}
+		if names[profile.Name] {
+			return fmt.Errorf("profiles[%d]: duplicate name '%s'", i, profile.Name)
+		}
+		names[profile.Name] = true
+
+		if err := m.validateServerShape(profile.ServerType, profile.Location, profile.VolumeSize); err != nil {
+			return fmt.Errorf("profile '%s': %w", profile.Name, err)
+		}
+		if profile.Schedule != nil {
+			if err := validateSchedule(*profile.Schedule); err != nil {
+				return fmt.Errorf("profile '%s': schedule: %w", profile.Name, err)
+			}
+		}
+	}
+
+	// Inherited profiles must exist and not inherit from each other in a cycle
+	for _, profile := range m.config.Profiles {
+		if _, err := m.Profile(profile.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateServerShape validates optional server type, location and volume size
+// overrides. Empty values are not checked; they inherit the validated defaults.
+func (m *Manager) validateServerShape(serverType, location string, volumeSize int) error {
+	// Server types and locations are provider specific; only Hetzner's are known here
+	if m.providerName() != hetzner.ProviderName {
+		return nil
+	}
+	if serverType != "" {
+		if err := m.validateServerType(serverType); err != nil {
+			return err
+		}
+	}
+	if location != "" && !slices.Contains(validLocations, location) {
+		return fmt.Errorf("invalid location '%s', must be one of: %s", location, strings.Join(validLocations, ", "))
+	}
+	if volumeSize != 0 && (volumeSize < 10 || volumeSize > 10000) {
+		return fmt.Errorf("volume_size must be between 10 and 10000 GB, got %d", volumeSize)
+	}
+	return nil
+}
+
 // validatePortForward validates port forwarding configuration
 func (m *Manager) validatePortForward() error {
 	portForward := &m.config.PortForward
 
 	// Validate conflict strategy
-	validStrategies := []string{"increment", "fail"}
+	validStrategies := []string{"auto", "increment", "fail", "replace"}
 	if !slices.Contains(validStrategies, string(portForward.ConflictStrategy)) {
 		return fmt.Errorf("invalid conflict_strategy '%s', must be one of: %s", portForward.ConflictStrategy, strings.Join(validStrategies, ", "))
 	}
@@ -370,5 +1262,14 @@ func (m *Manager) validatePortForward() error {
 		return fmt.Errorf("monitor_interval must be at least 1 second, got %v", portForward.MonitorInterval)
 	}
 
+	// Validate bind address; exposing forwards beyond loopback requires an explicit opt-in
+	bindIP := net.ParseIP(portForward.BindAddress)
+	if bindIP == nil {
+		return fmt.Errorf("invalid bind_address '%s', must be an IP address", portForward.BindAddress)
+	}
+	if !bindIP.IsLoopback() && !portForward.AllowExternal {
+		return fmt.Errorf("bind_address '%s' is not a loopback address; set allow_external: true to expose forwards on the network", portForward.BindAddress)
+	}
+
 	return nil
 }
@@ -3,13 +3,25 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestManager returns a manager whose server type catalog never calls the API
+// and caches under a per-test directory, so validation ignores the user's cache
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager := NewManager()
+	manager.serverTypes = hetzner.NewServerTypeCatalog(filepath.Join(t.TempDir(), "hetzner-server-types.json"), nil)
+	return manager
+}
+
 func TestNewManager(t *testing.T) {
 	manager := NewManager()
 	assert.NotNil(t, manager)
@@ -25,7 +37,7 @@ func TestLoadWithDefaults(t *testing.T) {
 	os.Setenv("HETZNER_API_TOKEN", "test-token")
 	defer os.Unsetenv("HETZNER_API_TOKEN")
 
-	manager := NewManager()
+	manager := newTestManager(t)
 
 	// Load with non-existent config file (should use defaults)
 	err := manager.Load(filepath.Join(tempDir, "nonexistent.yaml"))
@@ -78,7 +90,7 @@ logging:
 	err := os.WriteFile(configFile, []byte(configContent), 0644)
 	require.NoError(t, err)
 
-	manager := NewManager()
+	manager := newTestManager(t)
 	err = manager.Load(configFile)
 	require.NoError(t, err)
 
@@ -109,7 +121,7 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 		os.Unsetenv("LOG_LEVEL")
 	}()
 
-	manager := NewManager()
+	manager := newTestManager(t)
 	err := manager.Load("")
 	require.NoError(t, err)
 
@@ -160,6 +172,26 @@ func TestValidateHetzner(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid server_type",
 		},
+		{
+			name: "ARM server type",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cax21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+			},
+			expectError: false,
+		},
+		{
+			name: "dedicated server type",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "ccx33"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+			},
+			expectError: false,
+		},
 		{
 			name: "invalid location",
 			setupConfig: func(m *Manager) {
@@ -193,11 +225,74 @@ func TestValidateHetzner(t *testing.T) {
 			expectError: true,
 			errorMsg:    "volume_size must be between 10 and 10000",
 		},
+		{
+			name: "encryption without volumes",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cpx21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+				m.config.Hetzner.EncryptVolumes = true
+			},
+			expectError: true,
+			errorMsg:    "encrypt_volumes requires volumes",
+		},
+		{
+			name: "encrypted volumes",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cpx21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+				m.config.Hetzner.Volumes = []config.VolumeConfig{{Name: "data", Size: 10, Mount: "/data"}}
+				m.config.Hetzner.EncryptVolumes = true
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid public network",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cpx21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+				m.config.Hetzner.PublicNetwork = "ipv4"
+			},
+			expectError: true,
+			errorMsg:    "public_network must be",
+		},
+		{
+			name: "no public network without jump host",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cpx21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+				m.config.Hetzner.PublicNetwork = "none"
+				m.config.Hetzner.Network = "office"
+			},
+			expectError: true,
+			errorMsg:    "requires ssh.jump_host",
+		},
+		{
+			name: "no public network through jump host",
+			setupConfig: func(m *Manager) {
+				m.config.Hetzner.APIToken = "valid-token"
+				m.config.Hetzner.ServerType = "cpx21"
+				m.config.Hetzner.Location = "fsn1"
+				m.config.Hetzner.VolumeSize = 10
+				m.config.Hetzner.PublicNetwork = "none"
+				m.config.Hetzner.Network = "office"
+				m.config.SSH.JumpHost = "admin@bastion.example.com"
+				m.config.KeepAlive.Transport = "ssh"
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			manager := NewManager()
+			manager := newTestManager(t)
 			tt.setupConfig(manager)
 
 			err := manager.validateHetzner()
@@ -245,11 +340,122 @@ func TestValidateDocker(t *testing.T) {
 			expectError: true,
 			errorMsg:    "proxy_port must be between 1024 and 65535",
 		},
+		{
+			name: "invalid tls mode",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.TLS.Mode = "tls"
+			},
+			expectError: true,
+			errorMsg:    "tls.mode must be 'mtls' or 'off'",
+		},
+		{
+			name: "invalid remote transport",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.RemoteTransport = "ssh"
+			},
+			expectError: true,
+			errorMsg:    "remote_transport must be 'tcp' or 'unix'",
+		},
+		{
+			name: "invalid local semantics policy",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.LocalSemantics = "deny"
+			},
+			expectError: true,
+			errorMsg:    "local_semantics must be 'warn', 'block' or 'ignore'",
+		},
+		{
+			name: "valid TCP listener with TLS",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.TCPListener = config.TCPListenerConfig{Enabled: true, Listen: "127.0.0.1:2376", CertFile: "cert.pem", KeyFile: "key.pem"}
+			},
+			expectError: false,
+		},
+		{
+			name: "TCP listener beyond loopback without client certificates",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.TCPListener = config.TCPListenerConfig{Enabled: true, Listen: "0.0.0.0:2376", CertFile: "cert.pem", KeyFile: "key.pem"}
+			},
+			expectError: true,
+			errorMsg:    "tcp_listener: listen address '0.0.0.0:2376' is not a loopback address",
+		},
+		{
+			name: "TCP listener with a certificate but no key",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.TCPListener = config.TCPListenerConfig{Enabled: true, Listen: "127.0.0.1:2376", CertFile: "cert.pem"}
+			},
+			expectError: true,
+			errorMsg:    "tcp_listener: cert_file and key_file must be set together",
+		},
+		{
+			name: "invalid compression level",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.Compression = config.CompressionConfig{Enabled: true, Level: 12}
+			},
+			expectError: true,
+			errorMsg:    "compression.level must be between 1 and 9",
+		},
+		{
+			name: "registry mirror on the Docker API port",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.RegistryMirror = config.RegistryMirrorConfig{Enabled: true, Port: 2376, Image: "registry:2"}
+			},
+			expectError: true,
+			errorMsg:    "registry_mirror.port must be between 1024 and 65535",
+		},
+		{
+			name: "valid registry auth",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.RegistryAuth = []config.RegistryAuthConfig{
+					{Registry: "registry.example.com:5000"},
+					{Registry: "ghcr.io", Username: "ci", Password: "token"},
+				}
+			},
+			expectError: false,
+		},
+		{
+			name: "registry auth with URL",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.RegistryAuth = []config.RegistryAuthConfig{{Registry: "https://registry.example.com/v2/"}}
+			},
+			expectError: true,
+			errorMsg:    "registry_auth[0]: registry must be a registry host",
+		},
+		{
+			name: "registry auth password without username",
+			setupConfig: func(m *Manager) {
+				m.config.Docker.SocketPath = "/var/run/docker.sock"
+				m.config.Docker.ProxyPort = 2376
+				m.config.Docker.RegistryAuth = []config.RegistryAuthConfig{{Registry: "ghcr.io", Password: "token"}}
+			},
+			expectError: true,
+			errorMsg:    "password requires a username",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			manager := NewManager()
+			manager := newTestManager(t)
 			tt.setupConfig(manager)
 
 			err := manager.validateDocker()
@@ -325,11 +531,33 @@ func TestValidateKeepAlive(t *testing.T) {
 			expectError: true,
 			errorMsg:    "max_retries must be between 0 and 10",
 		},
+		{
+			name: "unknown transport",
+			setupConfig: func(m *Manager) {
+				m.config.KeepAlive.Interval = 30 * time.Second
+				m.config.KeepAlive.Timeout = 5 * time.Minute
+				m.config.KeepAlive.RetryInterval = 5 * time.Second
+				m.config.KeepAlive.Transport = "udp"
+			},
+			expectError: true,
+			errorMsg:    "transport must be 'http' or 'ssh'",
+		},
+		{
+			name: "client ID with spaces",
+			setupConfig: func(m *Manager) {
+				m.config.KeepAlive.Interval = 30 * time.Second
+				m.config.KeepAlive.Timeout = 5 * time.Minute
+				m.config.KeepAlive.RetryInterval = 5 * time.Second
+				m.config.KeepAlive.ClientID = "my laptop"
+			},
+			expectError: true,
+			errorMsg:    "client_id must be",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			manager := NewManager()
+			manager := newTestManager(t)
 			tt.setupConfig(manager)
 
 			err := manager.validateKeepAlive()
@@ -361,6 +589,30 @@ func TestValidateSSH(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "invalid key type",
+			setupConfig: func(m *Manager, tempDir string) {
+				m.config.SSH.KeyPath = filepath.Join(tempDir, "ssh", "id_rsa")
+				m.config.SSH.Port = 22
+				m.config.SSH.Timeout = 30 * time.Second
+				m.config.SSH.KeepAlive = 30 * time.Second
+				m.config.SSH.KeyType = "dsa"
+			},
+			expectError: true,
+			errorMsg:    "key_type must be 'ed25519' or 'rsa'",
+		},
+		{
+			name: "invalid host key checking",
+			setupConfig: func(m *Manager, tempDir string) {
+				m.config.SSH.KeyPath = filepath.Join(tempDir, "ssh", "id_rsa")
+				m.config.SSH.Port = 22
+				m.config.SSH.Timeout = 30 * time.Second
+				m.config.SSH.KeepAlive = 30 * time.Second
+				m.config.SSH.HostKeyChecking = "ask"
+			},
+			expectError: true,
+			errorMsg:    "host_key_checking must be 'tofu', 'strict' or 'off'",
+		},
 		{
 			name: "invalid port - too low",
 			setupConfig: func(m *Manager, tempDir string) {
@@ -410,7 +662,7 @@ func TestValidateSSH(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tempDir := t.TempDir()
-			manager := NewManager()
+			manager := newTestManager(t)
 			tt.setupConfig(manager, tempDir)
 
 			err := manager.validateSSH()
@@ -484,7 +736,7 @@ func TestValidateLogging(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			manager := NewManager()
+			manager := newTestManager(t)
 			tt.setupConfig(manager)
 
 			err := manager.validateLogging()
@@ -499,9 +751,594 @@ func TestValidateLogging(t *testing.T) {
 	}
 }
 
+func TestValidatePortForward(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupConfig func(*Manager)
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "loopback bind address",
+			setupConfig: func(m *Manager) {},
+			expectError: false,
+		},
+		{
+			name: "external bind address without opt-in",
+			setupConfig: func(m *Manager) {
+				m.config.PortForward.BindAddress = "0.0.0.0"
+			},
+			expectError: true,
+			errorMsg:    "allow_external",
+		},
+		{
+			name: "external bind address with opt-in",
+			setupConfig: func(m *Manager) {
+				m.config.PortForward.BindAddress = "0.0.0.0"
+				m.config.PortForward.AllowExternal = true
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid bind address",
+			setupConfig: func(m *Manager) {
+				m.config.PortForward.BindAddress = "localhost"
+			},
+			expectError: true,
+			errorMsg:    "invalid bind_address",
+		},
+		{
+			name: "replace conflict strategy",
+			setupConfig: func(m *Manager) {
+				m.config.PortForward.ConflictStrategy = "replace"
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown conflict strategy",
+			setupConfig: func(m *Manager) {
+				m.config.PortForward.ConflictStrategy = "random"
+			},
+			expectError: true,
+			errorMsg:    "invalid conflict_strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			manager.config.PortForward.ConflictStrategy = "increment"
+			manager.config.PortForward.MonitorInterval = 30 * time.Second
+			manager.config.PortForward.BindAddress = "127.0.0.1"
+			tt.setupConfig(manager)
+
+			err := manager.validatePortForward()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHetznerOSUpdates(t *testing.T) {
+	manager := newTestManager(t)
+	manager.config.Hetzner.APIToken = "test-token"
+	manager.config.Hetzner.ServerType = "cpx21"
+	manager.config.Hetzner.Location = "fsn1"
+	manager.config.Hetzner.VolumeSize = 10
+	manager.config.Hetzner.OSUpdates = config.OSUpdatesConfig{
+		Enabled:      true,
+		AutoReboot:   true,
+		RebootWindow: "03:00-05:00",
+	}
+	assert.NoError(t, manager.validateHetzner())
+
+	manager.config.Hetzner.OSUpdates.RebootWindow = "late at night"
+	err := manager.validateHetzner()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "os_updates")
+
+	manager.config.Hetzner.OSUpdates.AutoReboot = false
+	assert.NoError(t, manager.validateHetzner(), "window is ignored without auto_reboot")
+}
+
+func TestValidateTraffic(t *testing.T) {
+	manager := newTestManager(t)
+	manager.config.Traffic.WarnPercent = 80
+	manager.config.Traffic.CriticalPercent = 95
+	assert.NoError(t, manager.validateTraffic())
+
+	manager.config.Traffic.CriticalPercent = 70
+	assert.Error(t, manager.validateTraffic(), "critical below warn")
+
+	manager.config.Traffic.WarnPercent = 0
+	assert.Error(t, manager.validateTraffic())
+}
+
+func TestValidateServerBinary(t *testing.T) {
+	manager := NewManager()
+	manager.config.ServerBinary.Source = "download"
+	assert.NoError(t, manager.validateServerBinary())
+
+	manager.config.ServerBinary.Path = "./bin/dockbridge-server"
+	assert.Error(t, manager.validateServerBinary(), "path is only uploaded")
+
+	manager.config.ServerBinary.Source = "upload"
+	assert.NoError(t, manager.validateServerBinary())
+
+	manager.config.ServerBinary.Source = "sftp"
+	assert.Error(t, manager.validateServerBinary())
+}
+
+func TestValidateMetrics(t *testing.T) {
+	manager := NewManager()
+	manager.config.Metrics.Listen = "localhost"
+	assert.NoError(t, manager.validateMetrics(), "ignored while disabled")
+
+	manager.config.Metrics.Enabled = true
+	assert.Error(t, manager.validateMetrics())
+
+	manager.config.Metrics.Listen = "127.0.0.1:9466"
+	assert.NoError(t, manager.validateMetrics())
+}
+
+func TestValidateTelemetry(t *testing.T) {
+	manager := NewManager()
+	manager.config.Telemetry = config.TelemetryConfig{Enabled: true, ServiceName: "dockbridge", SampleRatio: 1}
+	assert.NoError(t, manager.validateTelemetry())
+
+	manager.config.Telemetry.Endpoint = "http://collector:4318"
+	assert.Error(t, manager.validateTelemetry(), "endpoint with scheme")
+
+	manager.config.Telemetry.Endpoint = "collector:4318"
+	manager.config.Telemetry.SampleRatio = 1.5
+	assert.Error(t, manager.validateTelemetry())
+}
+
+func TestValidateBudget(t *testing.T) {
+	manager := NewManager()
+	manager.config.Budget = config.BudgetConfig{Monthly: 50, WarnPercent: 80, Action: "block"}
+	assert.NoError(t, manager.validateBudget())
+
+	manager.config.Budget.Action = "stop"
+	assert.Error(t, manager.validateBudget())
+
+	manager.config.Budget.Action = "warn"
+	manager.config.Budget.Monthly = -1
+	assert.Error(t, manager.validateBudget())
+}
+
+func TestValidateBackup(t *testing.T) {
+	manager := NewManager()
+	assert.NoError(t, manager.validateBackup(), "disabled backups are not validated")
+
+	manager.config.Backup = config.BackupConfig{
+		Enabled:     true,
+		Repository:  "s3:https://s3.eu-central-003.backblazeb2.com/my-bucket/dockbridge",
+		Password:    "secret",
+		Paths:       []string{"/var/lib/docker/volumes"},
+		Interval:    6 * time.Hour,
+		KeepDaily:   7,
+		KeepWeekly:  4,
+		KeepMonthly: 6,
+	}
+	assert.NoError(t, manager.validateBackup())
+
+	manager.config.Backup.AccessKeyID = "key-id"
+	assert.ErrorContains(t, manager.validateBackup(), "set together")
+	manager.config.Backup.SecretAccessKey = "key"
+	assert.NoError(t, manager.validateBackup())
+
+	manager.config.Backup.Repository = "/srv/restic"
+	assert.ErrorContains(t, manager.validateBackup(), "repository")
+	manager.config.Backup.Repository = "b2:my-bucket:dockbridge"
+
+	manager.config.Backup.Paths = []string{"volumes"}
+	assert.ErrorContains(t, manager.validateBackup(), "absolute")
+	manager.config.Backup.Paths = []string{"/var/lib/docker/volumes"}
+
+	manager.config.Backup.Interval = time.Minute
+	assert.ErrorContains(t, manager.validateBackup(), "interval")
+}
+
+func TestValidateSchedule(t *testing.T) {
+	assert.NoError(t, validateSchedule(config.ScheduleConfig{Timezone: "Mars/Olympus"}), "disabled schedules are not validated")
+
+	schedule := config.ScheduleConfig{
+		Enabled:      true,
+		Timezone:     "Europe/Berlin",
+		WorkingHours: "09:00-18:00",
+		Days:         []string{"mon", "tue", "wed", "thu", "fri"},
+		Prewarm:      10 * time.Minute,
+		OffHoursIdle: 10 * time.Minute,
+		Action:       config.LifecyclePolicyDestroy,
+	}
+	assert.NoError(t, validateSchedule(schedule))
+
+	schedule.Timezone = "Mars/Olympus"
+	assert.ErrorContains(t, validateSchedule(schedule), "timezone")
+	schedule.Timezone = ""
+
+	schedule.Action = "hibernate"
+	assert.ErrorContains(t, validateSchedule(schedule), "action")
+	schedule.Action = config.LifecyclePolicyPowerOff
+
+	schedule.Prewarm = -time.Minute
+	assert.ErrorContains(t, validateSchedule(schedule), "prewarm")
+}
+
+func TestValidateFileSync(t *testing.T) {
+	manager := NewManager()
+	assert.NoError(t, manager.validateFileSync(), "disabled file sync is not validated")
+
+	manager.config.FileSync = config.FileSyncConfig{
+		Enabled:    true,
+		Paths:      []string{"~/src", "/work"},
+		Exclude:    []string{".git"},
+		Interval:   2 * time.Second,
+		RemoteRoot: "/var/lib/docker/dockbridge-sync",
+	}
+	assert.NoError(t, manager.validateFileSync())
+
+	manager.config.FileSync.Paths = nil
+	assert.ErrorContains(t, manager.validateFileSync(), "at least one path")
+	manager.config.FileSync.Paths = []string{"src"}
+	assert.ErrorContains(t, manager.validateFileSync(), "absolute")
+	manager.config.FileSync.Paths = []string{"/work"}
+
+	manager.config.FileSync.RemoteRoot = "/"
+	assert.ErrorContains(t, manager.validateFileSync(), "remote_root")
+	manager.config.FileSync.RemoteRoot = "/var/lib/docker/dockbridge-sync"
+
+	manager.config.FileSync.Interval = 100 * time.Millisecond
+	assert.ErrorContains(t, manager.validateFileSync(), "interval")
+}
+
+func TestValidateProvisioning(t *testing.T) {
+	manager := NewManager()
+	assert.NoError(t, manager.validateProvisioning(), "no provisioning is valid")
+
+	manager.config.Provisioning = config.ProvisioningConfig{
+		PreDocker:  []config.ProvisioningStep{{Run: "update-ca-certificates"}},
+		PostDocker: []config.ProvisioningStep{{Script: "~/agent.sh"}},
+		WriteFiles: []config.ProvisioningFile{
+			{Path: "/usr/local/share/ca-certificates/corp.crt", Source: "~/corp.crt", Permissions: "0644"},
+			{Path: "/etc/agent.conf", Content: "key=1\n", Owner: "root:root"},
+		},
+	}
+	assert.NoError(t, manager.validateProvisioning())
+
+	manager.config.Provisioning.PostDocker[0].Run = "true"
+	assert.ErrorContains(t, manager.validateProvisioning(), "post_docker[0]: set exactly one of run or script")
+	manager.config.Provisioning.PostDocker[0] = config.ProvisioningStep{}
+	assert.ErrorContains(t, manager.validateProvisioning(), "post_docker[0]")
+	manager.config.Provisioning.PostDocker[0].Script = "~/agent.sh"
+
+	manager.config.Provisioning.WriteFiles[1].Path = "agent.conf"
+	assert.ErrorContains(t, manager.validateProvisioning(), "absolute")
+	manager.config.Provisioning.WriteFiles[1].Path = "/etc/agent.conf"
+
+	manager.config.Provisioning.WriteFiles[0].Content = "inline"
+	assert.ErrorContains(t, manager.validateProvisioning(), "not both")
+	manager.config.Provisioning.WriteFiles[0].Content = ""
+
+	manager.config.Provisioning.WriteFiles[0].Permissions = "rw-r--r--"
+	assert.ErrorContains(t, manager.validateProvisioning(), "octal")
+}
+
+func TestValidateVolumes(t *testing.T) {
+	assert.NoError(t, validateVolumes(nil))
+
+	valid := []config.VolumeConfig{
+		{Name: "docker-data", Size: 20, Mount: "/var/lib/docker"},
+		{Name: "data", Size: 50, Mount: "/data"},
+	}
+	assert.NoError(t, validateVolumes(valid))
+
+	tests := []struct {
+		name    string
+		volume  config.VolumeConfig
+		wantErr string
+	}{
+		{"invalid name", config.VolumeConfig{Name: "My Data", Size: 10, Mount: "/srv"}, "invalid volume name"},
+		{"duplicate name", config.VolumeConfig{Name: "data", Size: 10, Mount: "/srv"}, "duplicate volume name"},
+		{"too small", config.VolumeConfig{Name: "srv", Size: 5, Mount: "/srv"}, "size"},
+		{"relative mount", config.VolumeConfig{Name: "srv", Size: 10, Mount: "srv"}, "absolute"},
+		{"root mount", config.VolumeConfig{Name: "srv", Size: 10, Mount: "/"}, "absolute"},
+		{"shared mount", config.VolumeConfig{Name: "srv", Size: 10, Mount: "/data"}, "used by another volume"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateVolumes(append(slices.Clone(valid), tt.volume)), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateFirewall(t *testing.T) {
+	assert.NoError(t, validateFirewall(config.FirewallConfig{}))
+	assert.NoError(t, validateFirewall(config.FirewallConfig{Name: "dockbridge"}))
+	assert.NoError(t, validateFirewall(config.FirewallConfig{Name: "dockbridge", SourceIPs: []string{"203.0.113.0/24", "2001:db8::/32"}}))
+
+	assert.ErrorContains(t, validateFirewall(config.FirewallConfig{SourceIPs: []string{"203.0.113.0/24"}}), "requires a name")
+	assert.ErrorContains(t, validateFirewall(config.FirewallConfig{Name: "dockbridge", SourceIPs: []string{"203.0.113.7"}}), "invalid source IP range")
+}
+
+func TestValidateContexts(t *testing.T) {
+	tests := []struct {
+		name        string
+		contexts    []config.ContextConfig
+		current     string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "no contexts",
+			expectError: false,
+		},
+		{
+			name: "valid contexts",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "/tmp/dockbridge-dev.sock"},
+				{Name: "gpu", SocketPath: "/tmp/dockbridge-gpu.sock", ServerType: "cpx51", Location: "hel1"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid name",
+			contexts: []config.ContextConfig{
+				{Name: "Dev_1", SocketPath: "/tmp/dockbridge-dev.sock"},
+			},
+			expectError: true,
+			errorMsg:    "invalid name",
+		},
+		{
+			name: "duplicate name",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "/tmp/a.sock"},
+				{Name: "dev", SocketPath: "/tmp/b.sock"},
+			},
+			expectError: true,
+			errorMsg:    "duplicate name",
+		},
+		{
+			name: "socket shared with default daemon",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "/var/run/docker.sock"},
+			},
+			expectError: true,
+			errorMsg:    "already used",
+		},
+		{
+			name: "windows named pipe",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "//./pipe/dockbridge-dev"},
+			},
+			expectError: false,
+		},
+		{
+			name: "relative socket path",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "dev.sock"},
+			},
+			expectError: true,
+			errorMsg:    "absolute",
+		},
+		{
+			name: "invalid server type",
+			contexts: []config.ContextConfig{
+				{Name: "gpu", SocketPath: "/tmp/gpu.sock", ServerType: "huge"},
+			},
+			expectError: true,
+			errorMsg:    "invalid server_type",
+		},
+		{
+			name: "reserved name",
+			contexts: []config.ContextConfig{
+				{Name: "default", SocketPath: "/tmp/default.sock"},
+			},
+			expectError: true,
+			errorMsg:    "reserved",
+		},
+		{
+			name: "current context",
+			contexts: []config.ContextConfig{
+				{Name: "dev", SocketPath: "/tmp/dockbridge-dev.sock"},
+			},
+			current:     "dev",
+			expectError: false,
+		},
+		{
+			name:        "unknown current context",
+			current:     "gpu",
+			expectError: true,
+			errorMsg:    "current_context 'gpu'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			manager.config.Docker.SocketPath = "/var/run/docker.sock"
+			manager.config.Contexts = tt.contexts
+			manager.config.CurrentContext = tt.current
+
+			err := manager.validateContexts()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateNotificationSinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		sinks       []config.NotificationSinkConfig
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "no sinks",
+			expectError: false,
+		},
+		{
+			name: "valid sinks",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "log", Events: []string{"*"}},
+				{Type: "slack", Events: []string{"idle_shutdown", "heartbeat_lost"}, URL: "https://hooks.slack.com/services/T0/B0/x"},
+				{Type: "webhook", Events: []string{"volume_attached"}, URL: "http://localhost:8080/events"},
+				{Type: "desktop", Events: []string{"server_provisioned"}},
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown type",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "pager", Events: []string{"*"}},
+			},
+			expectError: true,
+			errorMsg:    "unknown type",
+		},
+		{
+			name: "unknown event",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "log", Events: []string{"server_exploded"}},
+			},
+			expectError: true,
+			errorMsg:    "unknown event",
+		},
+		{
+			name: "no events",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "log"},
+			},
+			expectError: true,
+			errorMsg:    "at least one event",
+		},
+		{
+			name: "slack without https",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "slack", Events: []string{"*"}, URL: "http://hooks.slack.com/services/T0/B0/x"},
+			},
+			expectError: true,
+			errorMsg:    "must use https",
+		},
+		{
+			name: "url on a desktop sink",
+			sinks: []config.NotificationSinkConfig{
+				{Type: "desktop", Events: []string{"*"}, URL: "https://example.com"},
+			},
+			expectError: true,
+			errorMsg:    "only used by webhook and slack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager()
+			manager.config.Notifications.Sinks = tt.sinks
+
+			err := manager.validateNotificationSinks()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		hooks       []config.HookConfig
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "no hooks",
+			expectError: false,
+		},
+		{
+			name: "valid command and webhook hooks",
+			hooks: []config.HookConfig{
+				{Name: "dns", Events: []string{"server_provisioned"}, Command: []string{"/bin/true"}},
+				{Name: "chat", Events: []string{"*"}, URL: "https://example.com/hook"},
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown event",
+			hooks: []config.HookConfig{
+				{Events: []string{"server_exploded"}, Command: []string{"/bin/true"}},
+			},
+			expectError: true,
+			errorMsg:    "unknown event",
+		},
+		{
+			name: "no events",
+			hooks: []config.HookConfig{
+				{Command: []string{"/bin/true"}},
+			},
+			expectError: true,
+			errorMsg:    "at least one event",
+		},
+		{
+			name: "command and url",
+			hooks: []config.HookConfig{
+				{Events: []string{"forward_added"}, Command: []string{"/bin/true"}, URL: "https://example.com"},
+			},
+			expectError: true,
+			errorMsg:    "not both",
+		},
+		{
+			name: "neither command nor url",
+			hooks: []config.HookConfig{
+				{Events: []string{"forward_added"}},
+			},
+			expectError: true,
+			errorMsg:    "command or url is required",
+		},
+		{
+			name: "invalid url",
+			hooks: []config.HookConfig{
+				{Events: []string{"forward_added"}, URL: "ftp://example.com"},
+			},
+			expectError: true,
+			errorMsg:    "invalid url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			manager.config.Hooks = tt.hooks
+
+			err := manager.validateHooks()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestFullValidation(t *testing.T) {
 	// Test that full validation catches multiple errors
-	manager := NewManager()
+	manager := newTestManager(t)
 
 	// Set up invalid configuration
 	manager.config.Hetzner.APIToken = "" // Missing token
@@ -517,3 +1354,58 @@ func TestFullValidation(t *testing.T) {
 	assert.Contains(t, err.Error(), "docker:")
 	assert.Contains(t, err.Error(), "logging:")
 }
+
+func TestValidateProvider(t *testing.T) {
+	manager := newTestManager(t)
+	manager.config.Provider = "aws"
+	manager.config.Hetzner.APIToken = "" // Not checked for other providers
+
+	err := manager.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown provider 'aws'")
+	assert.NotContains(t, err.Error(), "hetzner:")
+}
+
+func TestValidateDigitalOcean(t *testing.T) {
+	manager := newTestManager(t)
+	manager.config.DigitalOcean = config.DigitalOceanConfig{
+		APIToken:   "do-token",
+		Region:     "fra1",
+		Size:       "s-2vcpu-4gb",
+		VolumeSize: 10,
+		Image:      "ubuntu-24-04-x64",
+	}
+	assert.NoError(t, manager.validateDigitalOcean())
+
+	manager.config.DigitalOcean.VolumeSize = 0
+	err := manager.validateDigitalOcean()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "volume_size must be between 1 and 16384 GB")
+
+	manager.config.DigitalOcean.APIToken = ""
+	err = manager.validateDigitalOcean()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DIGITALOCEAN_TOKEN")
+}
+
+func TestServerSettingsDigitalOcean(t *testing.T) {
+	cfg := &config.ClientConfig{
+		Provider: "digitalocean",
+		Hetzner:  config.HetznerConfig{APIToken: "hetzner-token", ServerType: "cpx21"},
+		DigitalOcean: config.DigitalOceanConfig{
+			APIToken:   "do-token",
+			Region:     "ams3",
+			Size:       "s-4vcpu-8gb",
+			VolumeSize: 20,
+		},
+	}
+
+	settings := cfg.ServerSettings()
+	assert.Equal(t, "do-token", settings.APIToken)
+	assert.Equal(t, "s-4vcpu-8gb", settings.ServerType)
+	assert.Equal(t, "ams3", settings.Location)
+	assert.Equal(t, 20, settings.VolumeSize)
+
+	cfg.Provider = "hetzner"
+	assert.Equal(t, "cpx21", cfg.ServerSettings().ServerType)
+}
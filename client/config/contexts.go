@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultContextName refers to the top-level daemon (docker.socket_path and the provider section)
+const DefaultContextName = "default"
+
+// ConfigFileUsed returns the path of the loaded configuration file, or "" if none was found
+func (m *Manager) ConfigFileUsed() string {
+	return m.viper.ConfigFileUsed()
+}
+
+// ValidateContextName checks that name can be used for a new context
+func ValidateContextName(name string) error {
+	if name == DefaultContextName {
+		return fmt.Errorf("context name '%s' is reserved", DefaultContextName)
+	}
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid context name '%s', must match %s", name, contextNamePattern.String())
+	}
+	return nil
+}
+
+// ValidateNewContext checks ctx against the loaded configuration before it is added
+func (m *Manager) ValidateNewContext(ctx config.ContextConfig) error {
+	if err := ValidateContextName(ctx.Name); err != nil {
+		return err
+	}
+
+	existing := m.config.Contexts
+	m.config.Contexts = append(slices.Clone(existing), ctx)
+	defer func() { m.config.Contexts = existing }()
+	return m.validateContexts()
+}
+
+// AddContext appends a context to the configuration file at path, keeping the
+// rest of the file (including comments) intact. The file is created if missing.
+func AddContext(path string, ctx config.ContextConfig) error {
+	if err := ValidateContextName(ctx.Name); err != nil {
+		return err
+	}
+
+	return editConfigFile(path, func(root *yaml.Node) error {
+		contexts := mappingValue(root, "contexts")
+		if contexts == nil || contexts.Kind != yaml.SequenceNode {
+			contexts = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			setMappingValue(root, "contexts", contexts)
+		}
+		if contextIndex(contexts, ctx.Name) >= 0 {
+			return fmt.Errorf("context '%s' already exists", ctx.Name)
+		}
+
+		// Appending to "contexts: []" must not keep the flow style
+		contexts.Style = 0
+		contexts.Content = append(contexts.Content, contextNode(ctx))
+		return nil
+	})
+}
+
+// RemoveContext removes a context from the configuration file at path. The current
+// context is reset to the default if it was the removed one.
+func RemoveContext(path, name string) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		contexts := mappingValue(root, "contexts")
+		i := -1
+		if contexts != nil {
+			i = contextIndex(contexts, name)
+		}
+		if i < 0 {
+			return fmt.Errorf("context '%s' not found", name)
+		}
+		contexts.Content = append(contexts.Content[:i], contexts.Content[i+1:]...)
+
+		if current := mappingValue(root, "current_context"); current != nil && current.Value == name {
+			current.Value = ""
+		}
+		return nil
+	})
+}
+
+// SetCurrentContext records name as the current context in the configuration file at
+// path. DefaultContextName or "" selects the top-level daemon.
+func SetCurrentContext(path, name string) error {
+	if name == DefaultContextName {
+		name = ""
+	}
+
+	return editConfigFile(path, func(root *yaml.Node) error {
+		if name != "" {
+			contexts := mappingValue(root, "contexts")
+			if contexts == nil || contextIndex(contexts, name) < 0 {
+				return fmt.Errorf("context '%s' not found", name)
+			}
+		}
+		setMappingValue(root, "current_context", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+		return nil
+	})
+}
+
+// SetVolumeSize records size (GB) as the Docker data volume size of the named context
+// in the configuration file at path. The default context's size lives in the section
+// of providerName.
+func SetVolumeSize(path, providerName, contextName string, size int) error {
+	setSize := func(mapping *yaml.Node) {
+		// Update an existing value in place to keep its comments
+		if existing := mappingValue(mapping, "volume_size"); existing != nil && existing.Kind == yaml.ScalarNode {
+			existing.Tag, existing.Value = "!!int", strconv.Itoa(size)
+			return
+		}
+		setMappingValue(mapping, "volume_size", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(size)})
+	}
+
+	return editConfigFile(path, func(root *yaml.Node) error {
+		if contextName == "" || contextName == DefaultContextName {
+			section := "hetzner"
+			if providerName == "digitalocean" {
+				section = providerName
+			}
+			mapping := mappingValue(root, section)
+			if mapping == nil || mapping.Kind != yaml.MappingNode {
+				mapping = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setMappingValue(root, section, mapping)
+			}
+			setSize(mapping)
+			return nil
+		}
+
+		contexts := mappingValue(root, "contexts")
+		i := -1
+		if contexts != nil {
+			i = contextIndex(contexts, contextName)
+		}
+		if i < 0 {
+			return fmt.Errorf("context '%s' not found", contextName)
+		}
+		setSize(contexts.Content[i])
+		return nil
+	})
+}
+
+// editConfigFile applies edit to the top-level mapping of the YAML file at path
+func editConfigFile(path string, edit func(root *yaml.Node) error) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path) // #nosec G304 -- user supplied config path
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a YAML mapping", path)
+	}
+
+	if err := edit(doc.Content[0]); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key in a mapping node, appending it if missing
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// contextIndex returns the index of the named context in a contexts sequence, or -1
+func contextIndex(contexts *yaml.Node, name string) int {
+	for i, item := range contexts.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if v := mappingValue(item, "name"); v != nil && v.Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// contextNode encodes a context, leaving out fields inherited from the top-level config
+func contextNode(ctx config.ContextConfig) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	add := func(key, value, tag string) {
+		setMappingValue(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+	}
+
+	add("name", ctx.Name, "!!str")
+	add("socket_path", ctx.SocketPath, "!!str")
+	if ctx.ServerType != "" {
+		add("server_type", ctx.ServerType, "!!str")
+	}
+	if ctx.Location != "" {
+		add("location", ctx.Location, "!!str")
+	}
+	if ctx.VolumeSize != 0 {
+		add("volume_size", strconv.Itoa(ctx.VolumeSize), "!!int")
+	}
+	return node
+}
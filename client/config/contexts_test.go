@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextFileEditing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	original := `# Cloud credentials
+hetzner:
+  api_token: "token" # keep me
+
+contexts: []
+`
+	require.NoError(t, os.WriteFile(path, []byte(original), 0600))
+
+	require.NoError(t, AddContext(path, config.ContextConfig{Name: "gpu", SocketPath: "/tmp/gpu.sock", ServerType: "cpx51"}))
+	require.NoError(t, AddContext(path, config.ContextConfig{Name: "ci", SocketPath: "/tmp/ci.sock"}))
+	assert.ErrorContains(t, AddContext(path, config.ContextConfig{Name: "ci", SocketPath: "/tmp/ci2.sock"}), "already exists")
+	assert.ErrorContains(t, AddContext(path, config.ContextConfig{Name: "default", SocketPath: "/tmp/d.sock"}), "reserved")
+
+	require.NoError(t, SetCurrentContext(path, "gpu"))
+	assert.ErrorContains(t, SetCurrentContext(path, "missing"), "not found")
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.LoadWithoutValidation(path))
+	cfg := manager.GetConfig()
+	require.Len(t, cfg.Contexts, 2)
+	assert.Equal(t, config.ContextConfig{Name: "gpu", SocketPath: "/tmp/gpu.sock", ServerType: "cpx51"}, cfg.Contexts[0])
+	assert.Equal(t, "gpu", cfg.CurrentContext)
+	assert.Equal(t, "token", cfg.Hetzner.APIToken)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Cloud credentials")
+	assert.Contains(t, string(data), "# keep me")
+
+	// Removing the current context resets it to the default
+	require.NoError(t, RemoveContext(path, "gpu"))
+	assert.ErrorContains(t, RemoveContext(path, "gpu"), "not found")
+
+	manager = newTestManager(t)
+	require.NoError(t, manager.LoadWithoutValidation(path))
+	cfg = manager.GetConfig()
+	require.Len(t, cfg.Contexts, 1)
+	assert.Equal(t, "ci", cfg.Contexts[0].Name)
+	assert.Empty(t, cfg.CurrentContext)
+}
+
+func TestAddContextCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configs", "client.yaml")
+	require.NoError(t, AddContext(path, config.ContextConfig{Name: "dev", SocketPath: "/tmp/dev.sock", VolumeSize: 20}))
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.LoadWithoutValidation(path))
+	require.Len(t, manager.GetConfig().Contexts, 1)
+	assert.Equal(t, 20, manager.GetConfig().Contexts[0].VolumeSize)
+}
+
+func TestSetVolumeSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	original := `hetzner:
+  volume_size: 10 # grown with 'dockbridge volume resize'
+contexts:
+  - name: gpu
+    socket_path: /tmp/gpu.sock
+`
+	require.NoError(t, os.WriteFile(path, []byte(original), 0600))
+
+	require.NoError(t, SetVolumeSize(path, "hetzner", "", 40))
+	require.NoError(t, SetVolumeSize(path, "hetzner", "gpu", 80))
+	require.NoError(t, SetVolumeSize(path, "digitalocean", DefaultContextName, 25))
+	assert.ErrorContains(t, SetVolumeSize(path, "hetzner", "missing", 20), "not found")
+
+	manager := NewManager()
+	require.NoError(t, manager.LoadWithoutValidation(path))
+	cfg := manager.GetConfig()
+	assert.Equal(t, 40, cfg.Hetzner.VolumeSize)
+	assert.Equal(t, 25, cfg.DigitalOcean.VolumeSize)
+	require.Len(t, cfg.Contexts, 1)
+	assert.Equal(t, 80, cfg.Contexts[0].VolumeSize)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# grown with")
+}
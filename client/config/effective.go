@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Source tells where the effective value of a setting comes from
+type Source string
+
+const (
+	SourceDefault     Source = "default"
+	SourceFile        Source = "file"
+	SourceEnv         Source = "env"
+	SourceProfile     Source = "profile"
+	SourceSecretStore Source = "secret-store"
+	SourceFlag        Source = "flag"
+)
+
+// redacted replaces the values of secret settings
+const redacted = "****"
+
+// secretKeys are the names of settings whose values are never printed
+var secretKeys = []string{"api_token", "password", "secret_access_key", "headers"}
+
+// Setting is the effective value of a configuration key
+type Setting struct {
+	Key string
+	// Value is the formatted value; secrets are redacted
+	Value  string
+	Source Source
+}
+
+// Settings returns every setting of the loaded configuration with its effective value
+// and source, sorted by key as declared
+func (m *Manager) Settings() []Setting {
+	var settings []Setting
+	walkValues("", reflect.ValueOf(*m.config), func(key string, value reflect.Value) {
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  formatValue(key, value.Interface()),
+			Source: m.source(key),
+		})
+	})
+	return settings
+}
+
+// ApplyOverride applies per-command server shape flags, like those of "dockbridge up",
+// to the server settings of the selected provider
+func (m *Manager) ApplyOverride(override config.ProfileConfig) error {
+	if err := m.validateServerShape(override.ServerType, override.Location, override.VolumeSize); err != nil {
+		return err
+	}
+	override.APIToken = ""
+	m.trackChanges(SourceFlag, func() {
+		m.config.SetServerSettings(override.SettingsFor(m.config.ServerSettings()))
+	})
+	return nil
+}
+
+// trackChanges runs apply and records source as the origin of the settings it changed
+func (m *Manager) trackChanges(source Source, apply func()) {
+	before := *m.config
+	apply()
+	for _, change := range Diff(&before, m.config) {
+		if m.sources == nil {
+			m.sources = make(map[string]Source)
+		}
+		m.sources[change.Key] = source
+	}
+}
+
+// source returns where the value of key comes from, following viper's precedence of
+// environment over file over defaults
+func (m *Manager) source(key string) Source {
+	if source, ok := m.sources[key]; ok {
+		return source
+	}
+	if os.Getenv(envName(key)) != "" {
+		return SourceEnv
+	}
+	if m.viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// envName returns the environment variable read for key
+func envName(key string) string {
+	if env, ok := envBindings[key]; ok {
+		return env
+	}
+	return "DOCKBRIDGE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// walkValues calls fn with the dotted key and value of every setting below v; lists
+// and maps are single settings
+func walkValues(prefix string, v reflect.Value, fn func(key string, value reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if field := v.Field(i); field.Kind() == reflect.Struct {
+			walkValues(key, field, fn)
+		} else {
+			fn(key, field)
+		}
+	}
+}
+
+// formatValue formats a setting on one line, lists and maps in YAML flow style, with
+// secrets redacted
+func formatValue(key string, value any) string {
+	if isSecretKey(key) {
+		if reflect.ValueOf(value).IsZero() {
+			return ""
+		}
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Duration:
+		return v.String()
+	case bool, int, int64, float64:
+		return fmt.Sprint(v)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	redactNode(&node)
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// redactNode redacts the secrets of a list or map and switches it to flow style
+func redactNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 1 && isSecretKey(node.Content[i-1].Value) {
+			*child = yaml.Node{Kind: yaml.ScalarNode, Value: redacted}
+			continue
+		}
+		redactNode(child)
+	}
+}
+
+// isSecretKey reports whether the setting named by the last element of key is secret
+func isSecretKey(key string) bool {
+	return slices.Contains(secretKeys, key[strings.LastIndex(key, ".")+1:])
+}
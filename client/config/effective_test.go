@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`hetzner:
+  api_token: file-token
+  location: hel1
+docker:
+  socket_path: /var/run/docker.sock
+backup:
+  password: restic-password
+hooks:
+  - name: notify
+    events: ["server_provisioned"]
+    url: https://example.com/hook
+    headers:
+      Authorization: Bearer secret
+profiles:
+  - name: big
+    volume_size: 50
+`), 0600))
+	t.Setenv("HETZNER_API_TOKEN", "")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv(ProfileEnv, "big")
+
+	manager := newTestManager(t)
+	manager.serverTypes = hetzner.NewServerTypeCatalog("", nil)
+	require.NoError(t, manager.Load(path))
+	require.NoError(t, manager.ApplyOverride(config.ProfileConfig{ServerType: "cpx31"}))
+
+	settings := make(map[string]Setting)
+	for _, setting := range manager.Settings() {
+		settings[setting.Key] = setting
+	}
+
+	assert.Equal(t, Setting{Key: "hetzner.location", Value: "hel1", Source: SourceFile}, settings["hetzner.location"])
+	assert.Equal(t, Setting{Key: "hetzner.server_type", Value: "cpx31", Source: SourceFlag}, settings["hetzner.server_type"])
+	assert.Equal(t, Setting{Key: "hetzner.volume_size", Value: "50", Source: SourceProfile}, settings["hetzner.volume_size"])
+	assert.Equal(t, Setting{Key: "logging.level", Value: "debug", Source: SourceEnv}, settings["logging.level"])
+	assert.Equal(t, Setting{Key: "activity.idle_timeout", Value: "5m0s", Source: SourceDefault}, settings["activity.idle_timeout"])
+
+	// Secrets are redacted, including inside lists
+	assert.Equal(t, "****", settings["hetzner.api_token"].Value)
+	assert.Equal(t, "****", settings["backup.password"].Value)
+	assert.Equal(t, "", settings["digitalocean.api_token"].Value)
+	assert.Contains(t, settings["hooks"].Value, "https://example.com/hook")
+	assert.NotContains(t, settings["hooks"].Value, "Bearer")
+
+	assert.ErrorContains(t, manager.ApplyOverride(config.ProfileConfig{Location: "mars1"}), "invalid location")
+}
+
+func TestSchema(t *testing.T) {
+	data, err := json.Marshal(Schema())
+	require.NoError(t, err)
+
+	var schema struct {
+		Properties map[string]struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Type    string `json:"type"`
+				Default any    `json:"default"`
+				Items   *struct {
+					Type string `json:"type"`
+				} `json:"items"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	hetznerSchema := schema.Properties["hetzner"]
+	assert.Equal(t, "object", hetznerSchema.Type)
+	assert.Equal(t, "string", hetznerSchema.Properties["server_type"].Type)
+	assert.Equal(t, "cpx21", hetznerSchema.Properties["server_type"].Default)
+	assert.Equal(t, "integer", hetznerSchema.Properties["volume_size"].Type)
+	assert.Equal(t, float64(10), hetznerSchema.Properties["volume_size"].Default)
+	assert.Equal(t, "array", hetznerSchema.Properties["preferred_images"].Type)
+	assert.Equal(t, "string", hetznerSchema.Properties["preferred_images"].Items.Type)
+	assert.Equal(t, []any{"docker-ce", "ubuntu-22.04"}, hetznerSchema.Properties["preferred_images"].Default)
+
+	keepAlive := schema.Properties["keepalive"]
+	assert.Equal(t, "string", keepAlive.Properties["interval"].Type)
+	assert.Equal(t, "30s", keepAlive.Properties["interval"].Default)
+	assert.Equal(t, true, keepAlive.Properties["reprovision_on_wake"].Default)
+	assert.Equal(t, "array", schema.Properties["contexts"].Type)
+}
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/localsocket"
 )
 
 // InitializeDefaultConfig creates the default configuration directory and files in the user's home directory
@@ -88,6 +92,11 @@ docker:
   
   # Port for Docker proxy to listen on
   proxy_port: 2376
+  
+  # Cache /_ping, /version and image list responses locally for this long so
+  # IDE integrations polling every second don't round-trip over the tunnel.
+  # Set to 0 to disable; send "Cache-Control: no-cache" to bypass per request.
+  cache_ttl: "2s"
 
 # Keep-alive configuration
 keepalive:
@@ -102,6 +111,9 @@ keepalive:
   
   # Maximum number of retry attempts
   max_retries: 3
+  
+  # Expected sleep announced to the server before the laptop suspends
+  sleep_hint: "10m"
 
 # SSH configuration
 ssh:
@@ -129,6 +141,11 @@ logging:
   output: "stdout"
 `
 
+	// Docker clients on Windows connect through a named pipe
+	if runtime.GOOS == "windows" {
+		content = strings.Replace(content, `socket_path: "/var/run/docker.sock"`, `socket_path: "`+localsocket.DefaultPipe+`"`, 1)
+	}
+
 	return os.WriteFile(path, []byte(content), 0600)
 }
 
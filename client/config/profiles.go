@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// ProfileEnv selects the profile applied when no --profile flag is given
+const ProfileEnv = "DOCKBRIDGE_PROFILE"
+
+// SetProfile selects the profile applied to the configuration when it is loaded; empty
+// applies none. By default the profile named by DOCKBRIDGE_PROFILE is applied.
+func (m *Manager) SetProfile(name string) {
+	m.profile = name
+}
+
+// ActiveProfile returns the name of the profile applied to the configuration, or ""
+func (m *Manager) ActiveProfile() string {
+	return m.profile
+}
+
+// ApplyProfile applies the selected profile to the server settings of the selected
+// provider and, if it has one, the working hours schedule. Load applies it;
+// configurations loaded without validation are left as written so that they can be
+// saved back.
+func (m *Manager) ApplyProfile() error {
+	if m.profile == "" {
+		return nil
+	}
+
+	profile, err := m.Profile(m.profile)
+	if err != nil {
+		return err
+	}
+	m.trackChanges(SourceProfile, func() {
+		m.config.SetServerSettings(profile.SettingsFor(m.config.ServerSettings()))
+		if profile.Schedule != nil {
+			m.config.Schedule = *profile.Schedule
+		}
+	})
+	return nil
+}
+
+// Profile returns the server profile with the given name, with the settings it
+// inherits from other profiles applied
+func (m *Manager) Profile(name string) (*config.ProfileConfig, error) {
+	profile, err := m.findProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *profile
+	seen := map[string]bool{name: true}
+	for parentName := profile.Inherits; parentName != ""; {
+		if seen[parentName] {
+			return nil, fmt.Errorf("profile '%s': inheritance cycle through '%s'", name, parentName)
+		}
+		seen[parentName] = true
+
+		parent, err := m.findProfile(parentName)
+		if err != nil {
+			return nil, fmt.Errorf("profile '%s' inherits: %w", name, err)
+		}
+		resolved = resolved.Inherit(*parent)
+		parentName = parent.Inherits
+	}
+	return &resolved, nil
+}
+
+// findProfile returns the configured profile with the given name, without inheritance
+func (m *Manager) findProfile(name string) (*config.ProfileConfig, error) {
+	names := make([]string, 0, len(m.config.Profiles))
+	for i := range m.config.Profiles {
+		if m.config.Profiles[i].Name == name {
+			return &m.config.Profiles[i], nil
+		}
+		names = append(names, m.config.Profiles[i].Name)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("profile '%s' not found: no profiles are configured", name)
+	}
+	return nil, fmt.Errorf("profile '%s' not found, must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// ServerSettingsFor returns the server settings of the selected provider with the
+// named profile (if any) applied, followed by the per-command override. Only the
+// override's server shape fields are used.
+func (m *Manager) ServerSettingsFor(profileName string, override config.ProfileConfig) (config.ServerSettings, error) {
+	settings := m.config.ServerSettings()
+
+	if profileName != "" {
+		profile, err := m.Profile(profileName)
+		if err != nil {
+			return config.ServerSettings{}, err
+		}
+		settings = profile.SettingsFor(settings)
+	}
+
+	if err := m.validateServerShape(override.ServerType, override.Location, override.VolumeSize); err != nil {
+		return config.ServerSettings{}, err
+	}
+	override.APIToken = ""
+	return override.SettingsFor(settings), nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerSettingsFor(t *testing.T) {
+	manager := newTestManager(t)
+	manager.serverTypes = hetzner.NewServerTypeCatalog("", nil)
+	manager.config.Hetzner = config.HetznerConfig{
+		APIToken:   "token",
+		ServerType: "cpx21",
+		Location:   "fsn1",
+		VolumeSize: 10,
+	}
+	manager.config.Profiles = []config.ProfileConfig{
+		{Name: "build", ServerType: "ccx33", VolumeSize: 50},
+	}
+
+	settings, err := manager.ServerSettingsFor("", config.ProfileConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "cpx21", settings.ServerType)
+
+	settings, err = manager.ServerSettingsFor("build", config.ProfileConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "ccx33", settings.ServerType)
+	assert.Equal(t, "fsn1", settings.Location)
+	assert.Equal(t, 50, settings.VolumeSize)
+	assert.Equal(t, "token", settings.APIToken)
+
+	// Flags override the profile
+	settings, err = manager.ServerSettingsFor("build", config.ProfileConfig{ServerType: "cax31", Location: "hel1"})
+	require.NoError(t, err)
+	assert.Equal(t, "cax31", settings.ServerType)
+	assert.Equal(t, "hel1", settings.Location)
+	assert.Equal(t, 50, settings.VolumeSize)
+
+	_, err = manager.ServerSettingsFor("missing", config.ProfileConfig{})
+	assert.ErrorContains(t, err, "must be one of: build")
+
+	_, err = manager.ServerSettingsFor("", config.ProfileConfig{ServerType: "cx99"})
+	assert.ErrorContains(t, err, "invalid server_type 'cx99'")
+
+	_, err = manager.ServerSettingsFor("", config.ProfileConfig{Location: "mars1"})
+	assert.ErrorContains(t, err, "invalid location 'mars1'")
+}
+
+func TestValidateProfiles(t *testing.T) {
+	manager := newTestManager(t)
+	manager.serverTypes = hetzner.NewServerTypeCatalog("", nil)
+
+	manager.config.Profiles = []config.ProfileConfig{{Name: "build", ServerType: "ccx33"}, {Name: "arm", ServerType: "cax21"}}
+	assert.NoError(t, manager.validateProfiles())
+
+	manager.config.Profiles = []config.ProfileConfig{{Name: "build"}, {Name: "build"}}
+	assert.ErrorContains(t, manager.validateProfiles(), "duplicate name 'build'")
+
+	manager.config.Profiles = []config.ProfileConfig{{Name: "Build"}}
+	assert.ErrorContains(t, manager.validateProfiles(), "invalid name 'Build'")
+
+	manager.config.Profiles = []config.ProfileConfig{{Name: "big", VolumeSize: 5}}
+	assert.ErrorContains(t, manager.validateProfiles(), "profile 'big': volume_size")
+}
+
+func TestProfileInheritance(t *testing.T) {
+	manager := NewManager()
+	manager.config.Profiles = []config.ProfileConfig{
+		{Name: "work", APIToken: "work-token", Location: "nbg1"},
+		{Name: "work-build", Inherits: "work", ServerType: "ccx33"},
+		{Name: "work-big", Inherits: "work-build", VolumeSize: 100, Location: "hel1"},
+	}
+
+	profile, err := manager.Profile("work-big")
+	require.NoError(t, err)
+	assert.Equal(t, "work-token", profile.APIToken)
+	assert.Equal(t, "ccx33", profile.ServerType)
+	assert.Equal(t, "hel1", profile.Location)
+	assert.Equal(t, 100, profile.VolumeSize)
+
+	manager.config.Profiles = append(manager.config.Profiles,
+		config.ProfileConfig{Name: "a", Inherits: "b"},
+		config.ProfileConfig{Name: "b", Inherits: "a"},
+		config.ProfileConfig{Name: "orphan", Inherits: "missing"},
+	)
+	_, err = manager.Profile("a")
+	assert.ErrorContains(t, err, "inheritance cycle")
+	_, err = manager.Profile("orphan")
+	assert.ErrorContains(t, err, "profile 'missing' not found")
+	assert.Error(t, manager.validateProfiles())
+}
+
+func TestLoadAppliesProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`hetzner:
+  api_token: personal-token
+  server_type: cpx21
+docker:
+  socket_path: /var/run/docker.sock
+profiles:
+  - name: work
+    api_token: work-token
+    location: nbg1
+`), 0600))
+
+	manager := newTestManager(t)
+	require.NoError(t, manager.Load(path))
+	assert.Equal(t, "personal-token", manager.GetConfig().Hetzner.APIToken)
+
+	t.Setenv(ProfileEnv, "work")
+	manager = newTestManager(t)
+	require.NoError(t, manager.Load(path))
+	assert.Equal(t, "work", manager.ActiveProfile())
+	assert.Equal(t, "work-token", manager.GetConfig().Hetzner.APIToken)
+	assert.Equal(t, "nbg1", manager.GetConfig().Hetzner.Location)
+	assert.Equal(t, "cpx21", manager.GetConfig().Hetzner.ServerType)
+
+	// Configurations loaded to be edited are left as written
+	manager = newTestManager(t)
+	require.NoError(t, manager.LoadWithoutValidation(path))
+	assert.Equal(t, "personal-token", manager.GetConfig().Hetzner.APIToken)
+
+	manager = newTestManager(t)
+	manager.SetProfile("missing")
+	assert.ErrorContains(t, manager.Load(path), "profile 'missing' not found")
+}
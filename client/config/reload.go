@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// reloadableKeys are the settings a running daemon applies without a restart
+var reloadableKeys = []string{
+	"logging.level",
+	"keepalive.interval",
+	"port_forward.conflict_strategy",
+	"activity.idle_timeout",
+	"activity.connection_timeout",
+}
+
+// Change is a setting that differs between two configurations
+type Change struct {
+	// Key is the dotted configuration key, like "activity.idle_timeout"
+	Key string
+	// Reloadable reports whether a running daemon applies the change without a restart
+	Reloadable bool
+}
+
+// Diff returns the settings that differ between two configurations. The current
+// context is ignored: it only selects the context of CLI commands.
+func Diff(old, new *config.ClientConfig) []Change {
+	var changes []Change
+	diffValues("", reflect.ValueOf(*old), reflect.ValueOf(*new), func(key string) {
+		if key == "current_context" {
+			return
+		}
+		changes = append(changes, Change{Key: key, Reloadable: slices.Contains(reloadableKeys, key)})
+	})
+	return changes
+}
+
+// diffValues walks the fields of two structs, reporting the keys of the values that differ
+func diffValues(prefix string, old, new reflect.Value, report func(key string)) {
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		oldField, newField := old.Field(i), new.Field(i)
+		if oldField.Kind() == reflect.Struct {
+			diffValues(key, oldField, newField, report)
+			continue
+		}
+		if !reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			report(key)
+		}
+	}
+}
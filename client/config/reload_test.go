@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	old := &config.ClientConfig{
+		Logging:  config.LoggingConfig{Level: "info"},
+		Activity: config.ActivityConfig{IdleTimeout: 5 * time.Minute},
+		Hetzner:  config.HetznerConfig{ServerType: "cpx21"},
+	}
+	updated := *old
+	assert.Empty(t, Diff(old, &updated))
+
+	updated.Logging.Level = "debug"
+	updated.Activity.IdleTimeout = 10 * time.Minute
+	updated.Hetzner.ServerType = "cpx31"
+	updated.CurrentContext = "gpu"
+	updated.Contexts = []config.ContextConfig{{Name: "gpu"}}
+
+	assert.ElementsMatch(t, []Change{
+		{Key: "logging.level", Reloadable: true},
+		{Key: "activity.idle_timeout", Reloadable: true},
+		{Key: "hetzner.server_type"},
+		{Key: "contexts"},
+	}, Diff(old, &updated))
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("logging:\n  level: info\n"), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 10)
+	require.NoError(t, Watch(ctx, path, func() { changed <- struct{}{} }))
+
+	// Other files in the directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x"), 0600))
+	select {
+	case <-changed:
+		t.Fatal("change of another file reported")
+	case <-time.After(2 * watchDebounce):
+	}
+
+	// Several writes in a row are reported once
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(path, []byte("logging:\n  level: debug\n"), 0600))
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+	select {
+	case <-changed:
+		t.Fatal("change reported twice")
+	case <-time.After(2 * watchDebounce):
+	}
+}
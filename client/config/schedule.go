@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetHoliday adds date ("YYYY-MM-DD") to the holidays of the working hours schedule in
+// the configuration file at path, or removes it if holiday is false
+func SetHoliday(path, date string, holiday bool) error {
+	return editConfigFile(path, func(root *yaml.Node) error {
+		schedule := mappingValue(root, "schedule")
+		if schedule == nil || schedule.Kind != yaml.MappingNode {
+			schedule = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(root, "schedule", schedule)
+		}
+		holidays := mappingValue(schedule, "holidays")
+		if holidays == nil || holidays.Kind != yaml.SequenceNode {
+			holidays = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			setMappingValue(schedule, "holidays", holidays)
+		}
+
+		i := slices.IndexFunc(holidays.Content, func(node *yaml.Node) bool { return node.Value == date })
+		switch {
+		case holiday && i < 0:
+			// Appending to "holidays: []" must not keep the flow style
+			holidays.Style = 0
+			holidays.Content = append(holidays.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: date})
+		case !holiday && i >= 0:
+			holidays.Content = slices.Delete(holidays.Content, i, i+1)
+		case !holiday:
+			return fmt.Errorf("%s is not a holiday", date)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// durationPattern matches Go durations like "30s" or "1h30m"
+const durationPattern = `^(-?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// Schema returns a JSON Schema of the client configuration file, for editor completion
+// and validation of its structure. Value constraints are checked by Load.
+func Schema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(config.ClientConfig{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "DockBridge client configuration"
+	return schema
+}
+
+// typeSchema returns the schema of values of type t
+func typeSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" || key == "-" {
+				continue
+			}
+			property := typeSchema(field.Type)
+			if def, ok := field.Tag.Lookup("default"); ok {
+				if value, ok := parseDefault(field.Type, def); ok {
+					property["default"] = value
+				}
+			}
+			properties[key] = property
+		}
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return map[string]any{}
+	}
+}
+
+// parseDefault converts a default struct tag to a value of the field's schema type
+func parseDefault(t reflect.Type, def string) (any, bool) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return def, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return def, true
+	case reflect.Bool:
+		value, err := strconv.ParseBool(def)
+		return value, err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseInt(def, 10, 64)
+		return value, err == nil
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(def, 64)
+		return value, err == nil
+	default:
+		// Lists and maps are written as JSON, e.g. ["docker-ce"]
+		var value any
+		err := json.Unmarshal([]byte(def), &value)
+		return value, err == nil
+	}
+}
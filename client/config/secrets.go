@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/secrets"
+)
+
+// secretBackends lists the accepted secrets.backend values
+var secretBackends = []string{
+	secrets.BackendAuto,
+	secrets.BackendKeychain,
+	secrets.BackendSecretService,
+	secrets.BackendFile,
+	secrets.BackendNone,
+}
+
+// SetSecretPassphrase sets how the passphrase of the encrypted file backend is read,
+// e.g. by prompting for it; by default it is read from DOCKBRIDGE_SECRETS_PASSPHRASE
+func (m *Manager) SetSecretPassphrase(passphrase func() (string, error)) {
+	m.secretPassphrase = passphrase
+	m.secretStore = nil
+}
+
+// SecretStore returns the store configured in the secrets section
+func (m *Manager) SecretStore() (secrets.Store, error) {
+	if m.secretStore != nil {
+		return m.secretStore, nil
+	}
+
+	file := m.config.Secrets.File
+	if strings.HasPrefix(file, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		file = filepath.Join(homeDir, file[2:])
+	}
+
+	store, err := secrets.New(secrets.Options{
+		Backend:    m.config.Secrets.Backend,
+		File:       file,
+		Passphrase: m.secretPassphrase,
+		GOOS:       runtime.GOOS,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.secretStore = store
+	return store, nil
+}
+
+// APITokenKey returns the secret key of the selected provider's API token for the
+// active profile
+func (m *Manager) APITokenKey() string {
+	return secrets.APITokenKey(m.providerName(), m.profile)
+}
+
+// ResolveAPIToken reads the API token of the selected provider from the secret store
+// when neither the configuration nor the environment sets it. A token stored for the
+// active profile takes precedence over the provider's.
+func (m *Manager) ResolveAPIToken() error {
+	settings := m.config.ServerSettings()
+	if settings.APIToken != "" || m.config.Secrets.Backend == secrets.BackendNone {
+		return nil
+	}
+
+	store, err := m.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{secrets.APITokenKey(m.providerName(), "")}
+	if m.profile != "" {
+		keys = append([]string{m.APITokenKey()}, keys...)
+	}
+	for _, key := range keys {
+		token, err := store.Get(key)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the API token from the %s secret store: %w", store.Name(), err)
+		}
+		settings.APIToken = token
+		m.trackChanges(SourceSecretStore, func() {
+			m.config.SetServerSettings(settings)
+		})
+		return nil
+	}
+	return nil
+}
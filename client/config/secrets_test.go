@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/secrets"
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAPIToken(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`docker:
+  socket_path: /var/run/docker.sock
+secrets:
+  backend: file
+  file: `+filepath.Join(dir, "secrets.enc")+`
+profiles:
+  - name: work
+`), 0600))
+	t.Setenv("HETZNER_API_TOKEN", "")
+	t.Setenv(secrets.PassphraseEnv, "passphrase")
+
+	manager := NewManager()
+	err := manager.Load(path)
+	assert.ErrorContains(t, err, "api_token is required")
+	assert.Equal(t, dberrors.ExitMissingAPIToken, dberrors.ExitCode(err))
+
+	store, err := manager.SecretStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Set("hetzner/api_token", "stored-token"))
+	require.NoError(t, store.Set("hetzner/work/api_token", "work-token"))
+
+	manager = NewManager()
+	require.NoError(t, manager.Load(path))
+	assert.Equal(t, "stored-token", manager.GetConfig().Hetzner.APIToken)
+
+	manager = NewManager()
+	manager.SetProfile("work")
+	require.NoError(t, manager.Load(path))
+	assert.Equal(t, "work-token", manager.GetConfig().Hetzner.APIToken)
+	assert.Equal(t, "hetzner/work/api_token", manager.APITokenKey())
+
+	// Tokens set in the environment take precedence
+	t.Setenv("HETZNER_API_TOKEN", "env-token")
+	manager = NewManager()
+	require.NoError(t, manager.Load(path))
+	assert.Equal(t, "env-token", manager.GetConfig().Hetzner.APIToken)
+}
+
+func TestValidateSecretsBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("hetzner:\n  api_token: token\ndocker:\n  socket_path: /var/run/docker.sock\nsecrets:\n  backend: vault\n"), 0600))
+	assert.ErrorContains(t, NewManager().Load(path), "secrets: invalid backend 'vault'")
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce collapses the bursts of events editors produce when saving a file
+const watchDebounce = 500 * time.Millisecond
+
+// Watch calls onChange whenever the configuration file at path is written, until ctx is
+// done. The file's directory is watched so that editors replacing the file on save and
+// files created after the watch started are noticed.
+func Watch(ctx context.Context, path string, onChange func()) error {
+	path = filepath.Clean(path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != path || !(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+					continue
+				}
+				debounce.Reset(watchDebounce)
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-debounce.C:
+				onChange()
+			}
+		}
+	}()
+
+	return nil
+}
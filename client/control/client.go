@@ -0,0 +1,19 @@
+package control
+
+import (
+	"fmt"
+
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Dial connects to the control API on socketPath. The Unix socket is only accessible
+// to its owner, so no transport security is used. Callers close the returned connection.
+func Dial(socketPath string) (controlv1.ControlServiceClient, *grpc.ClientConn, error) {
+	conn, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to control socket %s: %w", socketPath, err)
+	}
+	return controlv1.NewControlServiceClient(conn), conn, nil
+}
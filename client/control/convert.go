@@ -0,0 +1,64 @@
+package control
+
+import (
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toProtoServer converts a Hetzner server; nil stays nil
+func toProtoServer(srv *provider.Server) *controlv1.Server {
+	if srv == nil {
+		return nil
+	}
+	return &controlv1.Server{
+		Id:        srv.ID,
+		Name:      srv.Name,
+		Status:    srv.Status,
+		IpAddress: srv.IPAddress,
+		CreatedAt: timestamppb.New(srv.CreatedAt),
+	}
+}
+
+// toProtoForward converts a port or socket forward
+func toProtoForward(forward *portforward.PortForward) *controlv1.Forward {
+	return &controlv1.Forward{
+		Id:               forward.ID,
+		Type:             string(forward.Type),
+		ContainerId:      forward.ContainerID,
+		ContainerName:    forward.ContainerName,
+		Project:          forward.Project,
+		Service:          forward.Service,
+		LocalPort:        int32(forward.LocalPort),  // #nosec G115 -- ports fit in int32
+		RemotePort:       int32(forward.RemotePort), // #nosec G115 -- ports fit in int32
+		LocalSocket:      forward.LocalSocket,
+		RemoteSocket:     forward.RemoteSocket,
+		BindAddress:      forward.BindAddress,
+		Status:           string(forward.Status),
+		BytesTransferred: forward.BytesTransferred,
+		CreatedAt:        timestamppb.New(forward.CreatedAt),
+	}
+}
+
+// toProtoLogEntry converts a log entry
+func toProtoLogEntry(entry LogEntry) *controlv1.LogEntry {
+	return &controlv1.LogEntry{
+		Time:  timestamppb.New(entry.Time),
+		Level: strings.ToLower(entry.Level.String()),
+		Line:  entry.Line,
+	}
+}
+
+// toProtoEvent converts a lifecycle event
+func toProtoEvent(event hooks.Event) *controlv1.Event {
+	return &controlv1.Event{
+		Type:    string(event.Type),
+		Time:    timestamppb.New(event.Time),
+		Context: event.Context,
+		Data:    event.Data,
+	}
+}
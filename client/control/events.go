@@ -0,0 +1,49 @@
+package control
+
+import (
+	"sync"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+)
+
+// eventBuffer is how many events a slow stream may lag behind before events are dropped
+const eventBuffer = 64
+
+// eventHub fans lifecycle events out to open StreamEvents calls. It listens on each
+// daemon's hook bus, so streams receive events in the order they were published.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan hooks.Event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan hooks.Event]struct{})}
+}
+
+// publish delivers the event to all subscribers without blocking on slow ones
+func (h *eventHub) publish(event hooks.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving events and a function that closes the subscription
+func (h *eventHub) subscribe() (<-chan hooks.Event, func()) {
+	ch := make(chan hooks.Event, eventBuffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
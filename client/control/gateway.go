@@ -0,0 +1,59 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// gatewayReadHeaderTimeout bounds slow REST requests
+const gatewayReadHeaderTimeout = 10 * time.Second
+
+// StartGateway serves the REST/JSON gateway of the API on addr, e.g. 127.0.0.1:7780,
+// forwarding each request to the gRPC API on the control socket. Streaming calls are
+// sent as newline-delimited JSON. Start must be called first.
+func (s *Server) StartGateway(addr string) error {
+	if s.grpcServer == nil {
+		return errors.New("control API is not running")
+	}
+
+	conn, err := grpc.NewClient("unix://"+s.socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect gateway to control socket: %w", err)
+	}
+	mux := runtime.NewServeMux()
+	if err := controlv1.RegisterControlServiceHandler(context.Background(), mux, conn); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to register REST gateway: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to listen for REST requests on %s: %w", addr, err)
+	}
+
+	s.gatewayAddr = listener.Addr().String()
+	s.httpServer = &http.Server{Handler: mux, ReadHeaderTimeout: gatewayReadHeaderTimeout}
+	go func() {
+		defer conn.Close()
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Error("Control API gateway stopped")
+		}
+	}()
+
+	s.logger.WithFields(map[string]any{
+		"address": s.gatewayAddr,
+	}).Info("Control API REST gateway listening")
+	return nil
+}
@@ -0,0 +1,38 @@
+package control
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerGateway(t *testing.T) {
+	def := &fakeDaemon{server: &hetzner.Server{ID: 7, Name: "dockbridge-7", Status: "running"}}
+	server := NewServer([]Daemon{def}, logger.NewDefault())
+	require.Error(t, server.StartGateway("127.0.0.1:0"), "the gateway needs the gRPC API")
+	require.NoError(t, server.Start(filepath.Join(t.TempDir(), "control.sock")))
+	t.Cleanup(server.Stop)
+	require.NoError(t, server.StartGateway("127.0.0.1:0"))
+
+	resp, err := http.Get("http://" + server.gatewayAddr + "/v1/status")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Contexts []struct {
+			Server struct {
+				Name string `json:"name"`
+			} `json:"server"`
+		} `json:"contexts"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Contexts, 1)
+	assert.Equal(t, "dockbridge-7", body.Contexts[0].Server.Name)
+}
@@ -0,0 +1,123 @@
+package control
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+// DefaultLogBufferSize is how many recent log entries a LogBuffer keeps
+const DefaultLogBufferSize = 1000
+
+// ansiEscape matches the terminal color sequences of colored log output
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// LogEntry is one line written by the daemon's logger
+type LogEntry struct {
+	Time  time.Time
+	Level logger.Level
+	Line  string
+}
+
+// LogBuffer keeps the recent lines of the daemon's log output for the StreamLogs call
+// and fans new lines out to followers. It is an io.Writer placed next to the logger's
+// output.
+type LogBuffer struct {
+	mu          sync.Mutex
+	size        int
+	entries     []LogEntry
+	partial     []byte
+	subscribers map[chan LogEntry]struct{}
+}
+
+// NewLogBuffer returns a buffer of the last size log entries
+func NewLogBuffer(size int) *LogBuffer {
+	return &LogBuffer{size: size, subscribers: make(map[chan LogEntry]struct{})}
+}
+
+// Write records the complete lines of p; a trailing partial line waits for the next write
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := ansiEscape.ReplaceAllString(string(b.partial[:i]), "")
+		b.partial = b.partial[i+1:]
+		if line != "" {
+			b.add(LogEntry{Time: time.Now(), Level: lineLevel(line), Line: line})
+		}
+	}
+	return len(p), nil
+}
+
+// add appends an entry, dropping the oldest beyond the size, and delivers it to
+// followers without blocking on slow ones
+func (b *LogBuffer) add(entry LogEntry) {
+	if len(b.entries) == b.size {
+		b.entries = append(b.entries[:0], b.entries[1:]...)
+	}
+	b.entries = append(b.entries, entry)
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// Tail returns the last n entries, all of them when n is 0 and none when it is negative
+func (b *LogBuffer) Tail(n int) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tail(n)
+}
+
+// tail copies the last n entries; the caller holds b.mu
+func (b *LogBuffer) tail(n int) []LogEntry {
+	entries := b.entries
+	if n < 0 {
+		return nil
+	}
+	if n > 0 && n < len(entries) {
+		entries = entries[len(entries)-n:]
+	}
+	return append([]LogEntry(nil), entries...)
+}
+
+// Follow returns the last n entries and a channel receiving the entries written after
+// them, with a function that ends the subscription
+func (b *LogBuffer) Follow(n int) ([]LogEntry, <-chan LogEntry, func()) {
+	ch := make(chan LogEntry, eventBuffer)
+
+	b.mu.Lock()
+	entries := b.tail(n)
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return entries, ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// lineLevel returns the level of a log line, the word following its timestamp
+func lineLevel(line string) logger.Level {
+	fields := strings.Fields(line)
+	if len(fields) > 1 {
+		if level, err := logger.ParseLevel(fields[1]); err == nil {
+			return level
+		}
+	}
+	return logger.Info
+}
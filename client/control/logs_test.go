@@ -0,0 +1,34 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBuffer(t *testing.T) {
+	logs := NewLogBuffer(2)
+
+	_, _ = logs.Write([]byte("2025-01-01T10:00:00.000Z \x1b[31mERROR\x1b[0m first\n2025-01-01T10:00:01.000Z INFO sec"))
+	entries := logs.Tail(0)
+	require.Len(t, entries, 1, "a partial line waits for its newline")
+	assert.Equal(t, "2025-01-01T10:00:00.000Z ERROR first", entries[0].Line)
+	assert.Equal(t, logger.Error, entries[0].Level)
+
+	_, _ = logs.Write([]byte("ond\nthird without level\n"))
+	entries = logs.Tail(0)
+	require.Len(t, entries, 2, "the oldest entries are dropped")
+	assert.Equal(t, "2025-01-01T10:00:01.000Z INFO second", entries[0].Line)
+	assert.Equal(t, logger.Info, entries[1].Level)
+	assert.Empty(t, logs.Tail(-1))
+
+	recent, follow, unsubscribe := logs.Follow(1)
+	defer unsubscribe()
+	require.Len(t, recent, 1)
+	assert.Equal(t, "third without level", recent[0].Line)
+	_, _ = logs.Write([]byte("2025-01-01T10:00:03.000Z WARN fourth\n"))
+	entry := <-follow
+	assert.Equal(t, logger.Warn, entry.Level)
+}
@@ -0,0 +1,410 @@
+// Package control serves the versioned gRPC control API (dockbridge.control.v1) of the
+// client daemon over a local Unix socket, so editors, tray apps and CI tooling can
+// manage DockBridge programmatically instead of scraping CLI output.
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/lifecycle"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Daemon is the part of a context daemon driven by the control API.
+// docker.DockBridgeDaemon satisfies this interface.
+type Daemon interface {
+	ContextName() string
+	SocketPath() string
+	IsRunning() bool
+	CurrentServer() *provider.Server
+	Provision(ctx context.Context) (*provider.Server, error)
+	Destroy(reason string) error
+	PortForwards() []*portforward.PortForward
+	AddForward(containerID string, localPort, remotePort int, protocol string) (*portforward.PortForward, error)
+	RemoveForward(id string) error
+	CloseProjectForwards(project string) (int, error)
+	SubscribeEvents(listener hooks.Listener)
+}
+
+// DefaultSocketPath returns the default location of the control API socket
+func DefaultSocketPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "control.sock"), nil
+}
+
+// ReloadFunc re-reads the configuration and applies it to the running daemons. It
+// returns the keys whose new values were applied and those deferred to the next start.
+type ReloadFunc func() (applied, deferred []string, err error)
+
+// Server implements controlv1.ControlServiceServer on top of the context daemons
+type Server struct {
+	controlv1.UnimplementedControlServiceServer
+
+	daemons map[string]Daemon
+	events  *eventHub
+	logs    *LogBuffer
+	logger  logger.LoggerInterface
+	reload  ReloadFunc
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+	socketPath string
+	// gatewayAddr is the address of the REST gateway, once started
+	gatewayAddr string
+}
+
+// NewServer creates a control server for the given daemons and subscribes to their events
+func NewServer(daemons []Daemon, logger logger.LoggerInterface) *Server {
+	s := &Server{
+		daemons: make(map[string]Daemon, len(daemons)),
+		events:  newEventHub(),
+		logger:  logger,
+	}
+	for _, d := range daemons {
+		s.daemons[d.ContextName()] = d
+		d.SubscribeEvents(s.events.publish)
+	}
+	return s
+}
+
+// SetReloadFunc sets how the Reload call reloads the configuration; without it Reload
+// is unimplemented
+func (s *Server) SetReloadFunc(reload ReloadFunc) {
+	s.reload = reload
+}
+
+// SetLogBuffer sets the buffer of daemon log lines served by StreamLogs; without it
+// StreamLogs is unimplemented
+func (s *Server) SetLogBuffer(logs *LogBuffer) {
+	s.logs = logs
+}
+
+// Start listens on socketPath and serves the API in the background
+func (s *Server) Start(socketPath string) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	// Remove a stale socket left behind by a previous run
+	if err := os.RemoveAll(socketPath); err != nil {
+		return fmt.Errorf("failed to remove stale control socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket %s: %w", socketPath, err)
+	}
+	// Only the owner may manage servers
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to set control socket permissions: %w", err)
+	}
+
+	s.socketPath = socketPath
+	s.grpcServer = grpc.NewServer()
+	controlv1.RegisterControlServiceServer(s.grpcServer, s)
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Error("Control API server stopped")
+		}
+	}()
+
+	s.logger.WithFields(map[string]any{
+		"socket_path": socketPath,
+	}).Info("Control API listening")
+	return nil
+}
+
+// Stop stops serving, ending open event streams, and removes the socket
+func (s *Server) Stop() {
+	if s.grpcServer == nil {
+		return
+	}
+	if s.httpServer != nil {
+		_ = s.httpServer.Close()
+	}
+	s.grpcServer.Stop()
+	_ = os.RemoveAll(s.socketPath)
+}
+
+// GetStatus reports the daemon and server state of one or all contexts
+func (s *Server) GetStatus(ctx context.Context, req *controlv1.GetStatusRequest) (*controlv1.GetStatusResponse, error) {
+	var daemons []Daemon
+	if req.GetAll() {
+		daemons = s.sortedDaemons()
+	} else {
+		d, err := s.daemon(req.GetContext())
+		if err != nil {
+			return nil, err
+		}
+		daemons = []Daemon{d}
+	}
+
+	resp := &controlv1.GetStatusResponse{}
+	for _, d := range daemons {
+		resp.Contexts = append(resp.Contexts, &controlv1.ContextStatus{
+			Name:       d.ContextName(),
+			SocketPath: d.SocketPath(),
+			Running:    d.IsRunning(),
+			Server:     toProtoServer(d.CurrentServer()),
+		})
+	}
+	return resp, nil
+}
+
+// Provision connects to the context's server, provisioning one if none is running
+func (s *Server) Provision(ctx context.Context, req *controlv1.ProvisionRequest) (*controlv1.ProvisionResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := d.Provision(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to provision server: %v", err)
+	}
+	return &controlv1.ProvisionResponse{Server: toProtoServer(srv)}, nil
+}
+
+// Destroy destroys the context's running server; the Docker data volume is kept
+func (s *Server) Destroy(ctx context.Context, req *controlv1.DestroyRequest) (*controlv1.DestroyResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Destroy("control API request"); err != nil {
+		return nil, status.Errorf(destroyErrorCode(err), "failed to destroy server: %v", err)
+	}
+	return &controlv1.DestroyResponse{}, nil
+}
+
+// destroyErrorCode maps a Destroy failure to the gRPC status code reported to clients
+func destroyErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, lifecycle.ErrNoServer):
+		return codes.NotFound
+	case errors.Is(err, lifecycle.ErrShutdownInProgress):
+		return codes.FailedPrecondition
+	default:
+		return codes.Unavailable
+	}
+}
+
+// ListForwards lists the active port and socket forwards of a context
+func (s *Server) ListForwards(ctx context.Context, req *controlv1.ListForwardsRequest) (*controlv1.ListForwardsResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &controlv1.ListForwardsResponse{}
+	for _, forward := range d.PortForwards() {
+		resp.Forwards = append(resp.Forwards, toProtoForward(forward))
+	}
+	return resp, nil
+}
+
+// CreateForward forwards a local port to a port of a running container
+func (s *Server) CreateForward(ctx context.Context, req *controlv1.CreateForwardRequest) (*controlv1.CreateForwardResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetContainerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "container_id is required")
+	}
+	if req.GetRemotePort() <= 0 || req.GetRemotePort() > 65535 || req.GetLocalPort() < 0 || req.GetLocalPort() > 65535 {
+		return nil, status.Error(codes.InvalidArgument, "ports must be between 1 and 65535")
+	}
+	protocol := req.GetProtocol()
+	if protocol == "" {
+		protocol = string(portforward.ForwardTypeTCP)
+	}
+	if protocol != string(portforward.ForwardTypeTCP) && protocol != string(portforward.ForwardTypeUDP) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported protocol %q, must be tcp or udp", protocol)
+	}
+
+	localPort := int(req.GetLocalPort())
+	if localPort == 0 {
+		localPort = int(req.GetRemotePort())
+	}
+	forward, err := d.AddForward(req.GetContainerId(), localPort, int(req.GetRemotePort()), protocol)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to create forward: %v", err)
+	}
+	return &controlv1.CreateForwardResponse{Forward: toProtoForward(forward)}, nil
+}
+
+// DeleteForward closes a port or socket forward
+func (s *Server) DeleteForward(ctx context.Context, req *controlv1.DeleteForwardRequest) (*controlv1.DeleteForwardResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := d.RemoveForward(req.GetId()); err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to delete forward: %v", err)
+	}
+	return &controlv1.DeleteForwardResponse{}, nil
+}
+
+// CloseProjectForwards closes all forwards of a docker compose project
+func (s *Server) CloseProjectForwards(ctx context.Context, req *controlv1.CloseProjectForwardsRequest) (*controlv1.CloseProjectForwardsResponse, error) {
+	d, err := s.daemon(req.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetProject() == "" {
+		return nil, status.Error(codes.InvalidArgument, "project is required")
+	}
+
+	closed, err := d.CloseProjectForwards(req.GetProject())
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to close forwards: %v", err)
+	}
+	return &controlv1.CloseProjectForwardsResponse{Closed: int32(closed)}, nil // #nosec G115 -- forward counts fit in int32
+}
+
+// StreamEvents streams lifecycle events until the client cancels the call
+func (s *Server) StreamEvents(req *controlv1.StreamEventsRequest, stream grpc.ServerStreamingServer[controlv1.Event]) error {
+	if !req.GetAll() {
+		if _, err := s.daemon(req.GetContext()); err != nil {
+			return err
+		}
+	}
+	for _, t := range req.GetTypes() {
+		if !hooks.IsKnownEvent(t) {
+			return status.Errorf(codes.InvalidArgument, "unknown event type %q", t)
+		}
+	}
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if !req.GetAll() && event.Context != req.GetContext() {
+				continue
+			}
+			if types := req.GetTypes(); len(types) > 0 && !slices.Contains(types, string(event.Type)) && !slices.Contains(types, string(hooks.EventAll)) {
+				continue
+			}
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamLogs sends the recent log entries of the daemon and, when following, new ones
+// until the client cancels the call
+func (s *Server) StreamLogs(req *controlv1.StreamLogsRequest, stream grpc.ServerStreamingServer[controlv1.LogEntry]) error {
+	if s.logs == nil {
+		return status.Error(codes.Unimplemented, "daemon logs are not available")
+	}
+	minLevel := logger.Debug
+	if req.GetLevel() != "" {
+		level, err := logger.ParseLevel(req.GetLevel())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "unknown log level %q", req.GetLevel())
+		}
+		minLevel = level
+	}
+	send := func(entry LogEntry) error {
+		if entry.Level < minLevel {
+			return nil
+		}
+		return stream.Send(toProtoLogEntry(entry))
+	}
+
+	if !req.GetFollow() {
+		for _, entry := range s.logs.Tail(int(req.GetTail())) {
+			if err := send(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	recent, entries, unsubscribe := s.logs.Follow(int(req.GetTail()))
+	defer unsubscribe()
+	for _, entry := range recent {
+		if err := send(entry); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case entry := <-entries:
+			if err := send(entry); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Reload re-reads the configuration file and applies the settings that can change
+// while the daemon runs
+func (s *Server) Reload(ctx context.Context, req *controlv1.ReloadRequest) (*controlv1.ReloadResponse, error) {
+	if s.reload == nil {
+		return nil, status.Error(codes.Unimplemented, "configuration reload is not available")
+	}
+	applied, deferred, err := s.reload()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to reload configuration: %v", err)
+	}
+	return &controlv1.ReloadResponse{Applied: applied, Deferred: deferred}, nil
+}
+
+// daemon returns the daemon of the named context
+func (s *Server) daemon(name string) (Daemon, error) {
+	d, ok := s.daemons[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown context %q", name)
+	}
+	return d, nil
+}
+
+// sortedDaemons returns all daemons, the default context first
+func (s *Server) sortedDaemons() []Daemon {
+	daemons := make([]Daemon, 0, len(s.daemons))
+	for _, d := range s.daemons {
+		daemons = append(daemons, d)
+	}
+	slices.SortFunc(daemons, func(a, b Daemon) int {
+		return strings.Compare(a.ContextName(), b.ContextName())
+	})
+	return daemons
+}
+
+// Ensure Server implements ControlServiceServer
+var _ controlv1.ControlServiceServer = (*Server)(nil)
@@ -0,0 +1,340 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/lifecycle"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDaemon is an in-memory Daemon
+type fakeDaemon struct {
+	name         string
+	server       *hetzner.Server
+	forwards     []*portforward.PortForward
+	provisionErr error
+	destroyErr   error
+	destroyed    []string
+	listeners    []hooks.Listener
+}
+
+func (d *fakeDaemon) ContextName() string            { return d.name }
+func (d *fakeDaemon) SocketPath() string             { return "/tmp/" + d.name + ".sock" }
+func (d *fakeDaemon) IsRunning() bool                { return true }
+func (d *fakeDaemon) CurrentServer() *hetzner.Server { return d.server }
+func (d *fakeDaemon) PortForwards() []*portforward.PortForward {
+	return d.forwards
+}
+func (d *fakeDaemon) CloseProjectForwards(project string) (int, error) {
+	kept := d.forwards[:0]
+	for _, forward := range d.forwards {
+		if forward.Project != project {
+			kept = append(kept, forward)
+		}
+	}
+	closed := len(d.forwards) - len(kept)
+	d.forwards = kept
+	return closed, nil
+}
+func (d *fakeDaemon) AddForward(containerID string, localPort, remotePort int, protocol string) (*portforward.PortForward, error) {
+	forward := &portforward.PortForward{
+		ID:          fmt.Sprintf("%s-%d", containerID, remotePort),
+		Type:        portforward.ForwardType(protocol),
+		ContainerID: containerID,
+		LocalPort:   localPort,
+		RemotePort:  remotePort,
+		Status:      portforward.ForwardStatusActive,
+	}
+	d.forwards = append(d.forwards, forward)
+	return forward, nil
+}
+func (d *fakeDaemon) RemoveForward(id string) error {
+	for i, forward := range d.forwards {
+		if forward.ID == id {
+			d.forwards = append(d.forwards[:i], d.forwards[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("forward %s not found", id)
+}
+func (d *fakeDaemon) SubscribeEvents(listener hooks.Listener) {
+	d.listeners = append(d.listeners, listener)
+}
+
+func (d *fakeDaemon) Provision(ctx context.Context) (*hetzner.Server, error) {
+	if d.provisionErr != nil {
+		return nil, d.provisionErr
+	}
+	d.server = &hetzner.Server{ID: 7, Name: "dockbridge-7", IPAddress: "10.0.0.7", Status: "running"}
+	return d.server, nil
+}
+
+func (d *fakeDaemon) Destroy(reason string) error {
+	if d.destroyErr != nil {
+		return d.destroyErr
+	}
+	d.destroyed = append(d.destroyed, reason)
+	d.server = nil
+	return nil
+}
+
+func (d *fakeDaemon) publish(event hooks.Event) {
+	event.Context = d.name
+	for _, listener := range d.listeners {
+		listener(event)
+	}
+}
+
+func startTestServer(t *testing.T, daemons ...*fakeDaemon) controlv1.ControlServiceClient {
+	t.Helper()
+
+	controlDaemons := make([]Daemon, 0, len(daemons))
+	for _, d := range daemons {
+		controlDaemons = append(controlDaemons, d)
+	}
+
+	server := NewServer(controlDaemons, logger.NewDefault())
+	require.NoError(t, server.Start(filepath.Join(t.TempDir(), "control.sock")))
+	t.Cleanup(server.Stop)
+
+	client, conn, err := Dial(server.socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return client
+}
+
+func TestServerStatusProvisionDestroy(t *testing.T) {
+	def := &fakeDaemon{}
+	gpu := &fakeDaemon{name: "gpu"}
+	client := startTestServer(t, def, gpu)
+	ctx := context.Background()
+
+	status, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{All: true})
+	require.NoError(t, err)
+	require.Len(t, status.Contexts, 2)
+	assert.Equal(t, "", status.Contexts[0].Name)
+	assert.Equal(t, "gpu", status.Contexts[1].Name)
+	assert.Nil(t, status.Contexts[1].Server)
+
+	provisioned, err := client.Provision(ctx, &controlv1.ProvisionRequest{Context: "gpu"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), provisioned.Server.Id)
+	assert.Equal(t, "10.0.0.7", provisioned.Server.IpAddress)
+
+	status, err = client.GetStatus(ctx, &controlv1.GetStatusRequest{Context: "gpu"})
+	require.NoError(t, err)
+	require.Len(t, status.Contexts, 1)
+	assert.Equal(t, "dockbridge-7", status.Contexts[0].Server.Name)
+
+	_, err = client.Destroy(ctx, &controlv1.DestroyRequest{Context: "gpu"})
+	require.NoError(t, err)
+	assert.Len(t, gpu.destroyed, 1)
+	assert.Empty(t, def.destroyed)
+}
+
+func TestServerErrors(t *testing.T) {
+	client := startTestServer(t, &fakeDaemon{provisionErr: errors.New("quota exceeded")})
+	ctx := context.Background()
+
+	_, err := client.GetStatus(ctx, &controlv1.GetStatusRequest{Context: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Provision(ctx, &controlv1.ProvisionRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, err.Error(), "quota exceeded")
+}
+
+func TestServerDestroyErrors(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{lifecycle.ErrNoServer, codes.NotFound},
+		{lifecycle.ErrShutdownInProgress, codes.FailedPrecondition},
+		{errors.New("api unavailable"), codes.Unavailable},
+	} {
+		client := startTestServer(t, &fakeDaemon{destroyErr: tt.err})
+		_, err := client.Destroy(ctx, &controlv1.DestroyRequest{})
+		assert.Equal(t, tt.code, status.Code(err), tt.err.Error())
+	}
+}
+
+func TestServerListForwards(t *testing.T) {
+	client := startTestServer(t, &fakeDaemon{forwards: []*portforward.PortForward{
+		{ID: "abc-80", Type: portforward.ForwardTypeTCP, ContainerName: "web", LocalPort: 8080, RemotePort: 80},
+	}})
+
+	resp, err := client.ListForwards(context.Background(), &controlv1.ListForwardsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Forwards, 1)
+	assert.Equal(t, "tcp", resp.Forwards[0].Type)
+	assert.Equal(t, int32(8080), resp.Forwards[0].LocalPort)
+}
+
+func TestServerCloseProjectForwards(t *testing.T) {
+	client := startTestServer(t, &fakeDaemon{forwards: []*portforward.PortForward{
+		{ID: "abc-80", Type: portforward.ForwardTypeTCP, ContainerName: "shop-web-1", Project: "shop", Service: "web", LocalPort: 8080, RemotePort: 80},
+		{ID: "def-5432", Type: portforward.ForwardTypeTCP, ContainerName: "shop-db-1", Project: "shop", Service: "db", LocalPort: 5432, RemotePort: 5432},
+		{ID: "ghi-6379", Type: portforward.ForwardTypeTCP, ContainerName: "redis", LocalPort: 6379, RemotePort: 6379},
+	}})
+	ctx := context.Background()
+
+	_, err := client.CloseProjectForwards(ctx, &controlv1.CloseProjectForwardsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := client.CloseProjectForwards(ctx, &controlv1.CloseProjectForwardsRequest{Project: "shop"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Closed)
+
+	forwards, err := client.ListForwards(ctx, &controlv1.ListForwardsRequest{})
+	require.NoError(t, err)
+	require.Len(t, forwards.Forwards, 1)
+	assert.Equal(t, "redis", forwards.Forwards[0].ContainerName)
+}
+
+func TestServerStreamEvents(t *testing.T) {
+	def := &fakeDaemon{}
+	client := startTestServer(t, def)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamEvents(ctx, &controlv1.StreamEventsRequest{Types: []string{"server_provisioned"}})
+	require.NoError(t, err)
+
+	// The subscription is registered when the server handles the call; publish until it arrives
+	received := make(chan *controlv1.Event, 1)
+	go func() {
+		event, err := stream.Recv()
+		if err == nil {
+			received <- event
+		}
+	}()
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-received:
+			assert.Equal(t, "server_provisioned", event.Type)
+			assert.Equal(t, "dockbridge-1", event.Data["server_name"])
+			return
+		case <-ticker.C:
+			def.publish(hooks.NewEvent(hooks.EventForwardAdded, nil))
+			def.publish(hooks.NewEvent(hooks.EventServerProvisioned, map[string]string{"server_name": "dockbridge-1"}))
+		case <-ctx.Done():
+			t.Fatal("no event received")
+		}
+	}
+}
+
+func TestServerStreamEventsRejectsUnknownType(t *testing.T) {
+	client := startTestServer(t, &fakeDaemon{})
+
+	stream, err := client.StreamEvents(context.Background(), &controlv1.StreamEventsRequest{Types: []string{"nope"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServerReload(t *testing.T) {
+	server := NewServer([]Daemon{&fakeDaemon{}}, logger.NewDefault())
+	require.NoError(t, server.Start(filepath.Join(t.TempDir(), "control.sock")))
+	t.Cleanup(server.Stop)
+	client, conn, err := Dial(server.socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	ctx := context.Background()
+
+	_, err = client.Reload(ctx, &controlv1.ReloadRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	server.SetReloadFunc(func() ([]string, []string, error) {
+		return []string{"logging.level"}, []string{"hetzner.server_type"}, nil
+	})
+	resp, err := client.Reload(ctx, &controlv1.ReloadRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logging.level"}, resp.Applied)
+	assert.Equal(t, []string{"hetzner.server_type"}, resp.Deferred)
+
+	server.SetReloadFunc(func() ([]string, []string, error) {
+		return nil, nil, errors.New("idle_timeout must be at least 30 seconds")
+	})
+	_, err = client.Reload(ctx, &controlv1.ReloadRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestServerCreateDeleteForward(t *testing.T) {
+	def := &fakeDaemon{}
+	client := startTestServer(t, def)
+	ctx := context.Background()
+
+	created, err := client.CreateForward(ctx, &controlv1.CreateForwardRequest{ContainerId: "web", RemotePort: 80})
+	require.NoError(t, err)
+	assert.Equal(t, int32(80), created.Forward.LocalPort, "local port defaults to the remote port")
+	assert.Equal(t, "tcp", created.Forward.Type)
+
+	_, err = client.CreateForward(ctx, &controlv1.CreateForwardRequest{ContainerId: "web", RemotePort: 53, Protocol: "sctp"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateForward(ctx, &controlv1.CreateForwardRequest{RemotePort: 80})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.DeleteForward(ctx, &controlv1.DeleteForwardRequest{Id: created.Forward.Id})
+	require.NoError(t, err)
+	assert.Empty(t, def.forwards)
+
+	_, err = client.DeleteForward(ctx, &controlv1.DeleteForwardRequest{Id: created.Forward.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServerStreamLogs(t *testing.T) {
+	server := NewServer([]Daemon{&fakeDaemon{}}, logger.NewDefault())
+	require.NoError(t, server.Start(filepath.Join(t.TempDir(), "control.sock")))
+	t.Cleanup(server.Stop)
+	client, conn, err := Dial(server.socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamLogs(ctx, &controlv1.StreamLogsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	logs := NewLogBuffer(10)
+	server.SetLogBuffer(logs)
+	_, _ = logs.Write([]byte("2025-01-01T10:00:00.000Z DEBUG tunnel opened\n2025-01-01T10:00:01.000Z WARN heartbeat late\n"))
+
+	stream, err = client.StreamLogs(ctx, &controlv1.StreamLogsRequest{Level: "info", Follow: true})
+	require.NoError(t, err)
+	entry, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "warn", entry.Level)
+	assert.Equal(t, "2025-01-01T10:00:01.000Z WARN heartbeat late", entry.Line)
+
+	require.Eventually(t, func() bool {
+		logs.mu.Lock()
+		defer logs.mu.Unlock()
+		return len(logs.subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+	_, _ = logs.Write([]byte("2025-01-01T10:00:02.000Z INFO server ready\n"))
+	entry, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-01T10:00:02.000Z INFO server ready", entry.Line)
+}
@@ -0,0 +1,83 @@
+package cost
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateOf(t *testing.T) {
+	rate := RateOf(&provider.Pricing{Currency: "EUR", ServerHourly: 0.01, VolumeGBMonthly: 0.073}, 10)
+	assert.Equal(t, "EUR", rate.Currency)
+	assert.InDelta(t, 0.011, rate.Hourly, 1e-9)
+}
+
+func TestStoreAccrue(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state", "cost.json"))
+	created := time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)
+	server := ServerInfo{ID: 7, Name: "dockbridge-7", ServerType: "cpx21", CreatedAt: created}
+	rate := Rate{Currency: "EUR", Hourly: 1}
+
+	// The first accrual covers the time since creation, split at the month boundary
+	require.NoError(t, store.Accrue(server, rate, created.Add(5*time.Hour)))
+	march, err := store.Total("2026-03")
+	require.NoError(t, err)
+	assert.InDelta(t, 2, march, 1e-9)
+	april, err := store.Total("2026-04")
+	require.NoError(t, err)
+	assert.InDelta(t, 3, april, 1e-9)
+
+	// Later accruals continue where the last one stopped
+	require.NoError(t, store.Accrue(server, rate, created.Add(6*time.Hour)))
+	april, err = store.Total("2026-04")
+	require.NoError(t, err)
+	assert.InDelta(t, 4, april, 1e-9)
+
+	spend, err := store.Month("2026-04")
+	require.NoError(t, err)
+	require.Len(t, spend, 1)
+	assert.Equal(t, "cpx21", spend[0].ServerType)
+	assert.Equal(t, "EUR", spend[0].Currency)
+}
+
+func TestBudget(t *testing.T) {
+	budget := Budget{Monthly: 100, WarnFraction: 0.8}
+	assert.Equal(t, LevelOK, budget.Level(79))
+	assert.Equal(t, LevelWarning, budget.Level(80))
+	assert.Equal(t, LevelExceeded, budget.Level(100))
+	assert.NoError(t, budget.Check(150), "warn-only budgets never block")
+
+	budget.Block = true
+	assert.NoError(t, budget.Check(99))
+	assert.Error(t, budget.Check(100))
+
+	assert.Equal(t, LevelOK, Budget{}.Level(1000), "a zero budget is disabled")
+}
+
+func TestMeterAlertsOncePerLevel(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	server := &ServerInfo{ID: 1, CreatedAt: now.Add(-50 * time.Hour)}
+
+	var alerts []Level
+	meter := &Meter{
+		Current: func(context.Context) (*ServerInfo, *Rate, error) {
+			return server, &Rate{Currency: "EUR", Hourly: 1}, nil
+		},
+		Alert:  func(level Level, spent float64, budget Budget) { alerts = append(alerts, level) },
+		Store:  NewStore(filepath.Join(t.TempDir(), "cost.json")),
+		Budget: Budget{Monthly: 100, WarnFraction: 0.5},
+		now:    func() time.Time { return now },
+	}
+
+	meter.check(context.Background())
+	meter.check(context.Background())
+	now = now.Add(60 * time.Hour)
+	meter.check(context.Background())
+
+	assert.Equal(t, []Level{LevelWarning, LevelExceeded}, alerts)
+}
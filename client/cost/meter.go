@@ -0,0 +1,151 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+)
+
+// DefaultAccrueInterval is how often the spend of the connected server is accrued
+const DefaultAccrueInterval = 5 * time.Minute
+
+// RateOf returns the hourly rate of a server with a volumeGB data volume at pricing
+func RateOf(pricing *provider.Pricing, volumeGB int) Rate {
+	return Rate{
+		Currency: pricing.Currency,
+		Hourly:   pricing.ServerHourly + float64(volumeGB)*pricing.VolumeGBMonthly/hoursPerMonth,
+	}
+}
+
+// Level is how much of the monthly budget has been spent
+type Level int
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelExceeded
+)
+
+// String returns the level's name
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelExceeded:
+		return "exceeded"
+	default:
+		return "ok"
+	}
+}
+
+// Budget is a monthly spending limit
+type Budget struct {
+	// Monthly is the limit in the provider's currency; zero disables the budget
+	Monthly float64
+	// WarnFraction of Monthly at which a warning is raised
+	WarnFraction float64
+	// Block refuses to provision new servers once the budget is exceeded
+	Block bool
+}
+
+// Enabled reports whether a limit is set
+func (b Budget) Enabled() bool {
+	return b.Monthly > 0
+}
+
+// Level returns the budget level reached by spent
+func (b Budget) Level(spent float64) Level {
+	switch {
+	case !b.Enabled():
+		return LevelOK
+	case spent >= b.Monthly:
+		return LevelExceeded
+	case spent >= b.Monthly*b.WarnFraction:
+		return LevelWarning
+	default:
+		return LevelOK
+	}
+}
+
+// Check returns an error if provisioning a server would go against the budget
+func (b Budget) Check(spent float64) error {
+	if b.Block && b.Level(spent) == LevelExceeded {
+		return fmt.Errorf("monthly budget of %.2f exceeded (estimated spend %.2f); raise budget.monthly to provision new servers", b.Monthly, spent)
+	}
+	return nil
+}
+
+// Meter periodically accrues the spend of the connected server and reports each month
+// once per level when the budget is approached or exceeded
+type Meter struct {
+	// Current returns the connected server and its rate, or nil if no server is connected
+	Current func(ctx context.Context) (*ServerInfo, *Rate, error)
+
+	// Alert is called when the month's spend reaches a new budget level
+	Alert func(level Level, spent float64, budget Budget)
+
+	Store         *Store
+	Budget        Budget
+	CheckInterval time.Duration
+
+	now    func() time.Time
+	warned map[string]Level
+}
+
+// Run accrues periodically until ctx is cancelled
+func (m *Meter) Run(ctx context.Context) {
+	interval := m.CheckInterval
+	if interval <= 0 {
+		interval = DefaultAccrueInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check accrues the connected server's spend and alerts if a new level was reached
+func (m *Meter) check(ctx context.Context) {
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+
+	server, rate, err := m.Current(ctx)
+	if err != nil {
+		return
+	}
+	if server != nil && rate != nil {
+		if err := m.Store.Accrue(*server, *rate, now); err != nil {
+			return
+		}
+	}
+
+	if !m.Budget.Enabled() {
+		return
+	}
+	month := Month(now)
+	spent, err := m.Store.Total(month)
+	if err != nil {
+		return
+	}
+
+	if m.warned == nil {
+		m.warned = make(map[string]Level)
+	}
+	level := m.Budget.Level(spent)
+	if level > m.warned[month] {
+		m.warned[month] = level
+		m.Alert(level, spent, m.Budget)
+	}
+}
@@ -0,0 +1,215 @@
+// Package cost estimates what remote servers cost from provider list prices, keeps the
+// estimated spend per server and month in local state and enforces a monthly budget.
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// hoursPerMonth converts monthly prices to hourly ones, as providers bill at most 730 hours
+const hoursPerMonth = 730
+
+// monthFormat keys spend by calendar month in UTC
+const monthFormat = "2006-01"
+
+// Month returns the month key of t
+func Month(t time.Time) string {
+	return t.UTC().Format(monthFormat)
+}
+
+// Rate is what one server costs per hour, including its Docker data volume
+type Rate struct {
+	Currency string  `json:"currency"`
+	Hourly   float64 `json:"hourly"`
+}
+
+// ServerInfo identifies the server spend is accrued for
+type ServerInfo struct {
+	ID         int64
+	Name       string
+	Context    string
+	ServerType string
+	// CreatedAt is when billing of the server started
+	CreatedAt time.Time
+}
+
+// ServerSpend is the estimated spend of one server in one month
+type ServerSpend struct {
+	ServerID    int64     `json:"server_id"`
+	ServerName  string    `json:"server_name"`
+	Context     string    `json:"context,omitempty"`
+	ServerType  string    `json:"server_type"`
+	Month       string    `json:"month"`
+	Currency    string    `json:"currency"`
+	HourlyRate  float64   `json:"hourly_rate"`
+	Amount      float64   `json:"amount"`
+	LastAccrued time.Time `json:"last_accrued"`
+}
+
+// state is the on-disk format of the cost store
+type state struct {
+	Spend []ServerSpend `json:"spend"`
+}
+
+// Store persists estimated spend in a local JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStorePath returns the default location of the cost state file
+func DefaultStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "state", "cost.json"), nil
+}
+
+// Accrue adds the spend of server at rate up to now. A server seen for the first time
+// accrues from its creation, so spend while no daemon was running is included. Spend
+// is split at month boundaries.
+func (s *Store) Accrue(server ServerInfo, rate Rate, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	from := server.CreatedAt
+	for _, spend := range st.Spend {
+		if spend.ServerID == server.ID && spend.LastAccrued.After(from) {
+			from = spend.LastAccrued
+		}
+	}
+	if from.IsZero() || from.After(now) {
+		from = now
+	}
+
+	for {
+		month := Month(from)
+		end := monthStart(from).AddDate(0, 1, 0)
+		if end.After(now) {
+			end = now
+		}
+
+		spend := st.find(server.ID, month)
+		if spend == nil {
+			st.Spend = append(st.Spend, ServerSpend{ServerID: server.ID, Month: month})
+			spend = &st.Spend[len(st.Spend)-1]
+		}
+		spend.ServerName = server.Name
+		spend.Context = server.Context
+		spend.ServerType = server.ServerType
+		spend.Currency = rate.Currency
+		spend.HourlyRate = rate.Hourly
+		spend.Amount += end.Sub(from).Hours() * rate.Hourly
+		spend.LastAccrued = end
+
+		if !end.Before(now) {
+			break
+		}
+		from = end
+	}
+
+	return s.write(st)
+}
+
+// Month returns the spend of all servers in month
+func (s *Store) Month(month string) ([]ServerSpend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var spend []ServerSpend
+	for _, entry := range st.Spend {
+		if entry.Month == month {
+			spend = append(spend, entry)
+		}
+	}
+	return spend, nil
+}
+
+// Total returns the summed spend of month
+func (s *Store) Total(month string) (float64, error) {
+	spend, err := s.Month(month)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, entry := range spend {
+		total += entry.Amount
+	}
+	return total, nil
+}
+
+// find returns the spend of a server in month, or nil
+func (st *state) find(serverID int64, month string) *ServerSpend {
+	for i := range st.Spend {
+		if st.Spend[i].ServerID == serverID && st.Spend[i].Month == month {
+			return &st.Spend[i]
+		}
+	}
+	return nil
+}
+
+// monthStart returns the first instant of t's month in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// read loads the state file
+func (s *Store) read() (*state, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return &state{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cost state: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse cost state: %w", err)
+	}
+	return &st, nil
+}
+
+// write atomically replaces the state file
+func (s *Store) write(st *state) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create cost state directory: %w", err)
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode cost state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cost state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace cost state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package daemonproc runs the DockBridge daemon in the background and finds it again:
+// the daemon records its process ID in a file that "dockbridge down" uses to stop it.
+package daemonproc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stopPollInterval is how often Stop checks whether the daemon has exited
+const stopPollInterval = 100 * time.Millisecond
+
+// PIDPath returns the file the running daemon records its process ID in
+func PIDPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "dockbridge.pid"), nil
+}
+
+// LogPath returns the log file of a daemon started in the background
+func LogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "logs", "dockbridge.log"), nil
+}
+
+// WritePID records the process ID of this process at path
+func WritePID(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create PID directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// RemovePID removes the PID file at path if it still names this process, so that a
+// daemon started in the meantime keeps its file
+func RemovePID(path string) {
+	if pid, err := ReadPID(path); err == nil && pid == os.Getpid() {
+		_ = os.Remove(path)
+	}
+}
+
+// ReadPID returns the process ID recorded at path, or 0 if there is none
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid PID file %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// Running returns the process ID of the daemon recorded at path if it is still
+// running, or 0
+func Running(path string) int {
+	pid, err := ReadPID(path)
+	if err != nil || pid <= 0 || !alive(pid) {
+		return 0
+	}
+	return pid
+}
+
+// Spawn starts executable with args detached from this process and its terminal, with
+// its output appended to logPath, and returns its process ID
+func Spawn(executable string, args []string, logPath string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		return 0, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detached()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := cmd.Process.Pid
+	// The daemon outlives this process; it is never waited for
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// Alive reports whether the process pid is running
+func Alive(pid int) bool {
+	return alive(pid)
+}
+
+// Stop asks the process pid to shut down and waits until it has exited or timeout
+// has passed
+func Stop(pid int, timeout time.Duration) error {
+	if err := terminate(pid); err != nil {
+		return fmt.Errorf("failed to stop daemon (PID %d): %w", pid, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for alive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("daemon (PID %d) did not exit within %s", pid, timeout)
+		}
+		time.Sleep(stopPollInterval)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package daemonproc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dockbridge.pid")
+
+	pid, err := ReadPID(path)
+	require.NoError(t, err)
+	assert.Zero(t, pid)
+	assert.Zero(t, Running(path))
+
+	require.NoError(t, WritePID(path))
+	pid, err = ReadPID(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+	assert.Equal(t, os.Getpid(), Running(path))
+
+	RemovePID(path)
+	assert.NoFileExists(t, path)
+}
+
+func TestRemovePIDOfOtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dockbridge.pid")
+	require.NoError(t, os.WriteFile(path, []byte("1\n"), 0600))
+
+	// A daemon started in the meantime keeps its file
+	RemovePID(path)
+	assert.FileExists(t, path)
+}
+
+func TestReadPIDInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dockbridge.pid")
+	require.NoError(t, os.WriteFile(path, []byte("not a pid"), 0600))
+
+	_, err := ReadPID(path)
+	assert.Error(t, err)
+	assert.Zero(t, Running(path))
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package daemonproc
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detached starts the daemon in its own session, so closing the terminal does not
+// stop it
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+func alive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminate asks the daemon to shut down gracefully, as Ctrl+C does
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
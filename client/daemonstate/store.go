@@ -0,0 +1,151 @@
+// Package daemonstate persists what a daemon was connected to, per context: the server,
+// its volumes and host key fingerprint, and the active forwards. A restarted daemon
+// resumes that server without listing the provider's servers and cleaning up strays,
+// and brings back the forwards of containers that kept running.
+package daemonstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Server is the server a context was last connected to
+type Server struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	IPAddress  string    `json:"ip_address"`
+	ServerType string    `json:"server_type,omitempty"`
+	VolumeIDs  []string  `json:"volume_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Fingerprint is the SHA256 fingerprint of the server's SSH host key
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Forward is a forward that was active when the state was saved
+type Forward struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	LocalPort     int    `json:"local_port,omitempty"`
+	RemotePort    int    `json:"remote_port,omitempty"`
+	LocalSocket   string `json:"local_socket,omitempty"`
+	RemoteSocket  string `json:"remote_socket,omitempty"`
+}
+
+// Context is the saved state of one context
+type Context struct {
+	Server    *Server   `json:"server,omitempty"`
+	Forwards  []Forward `json:"forwards,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// state is the on-disk format of the store, keyed by context name ("" is the default)
+type state struct {
+	Contexts map[string]*Context `json:"contexts"`
+}
+
+// Store persists the daemon state in a local JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStorePath returns the default location of the daemon state file
+func DefaultStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "state", "daemon.json"), nil
+}
+
+// Load returns the saved state of contextName, or nil if there is none
+func (s *Store) Load(contextName string) (*Context, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return st.Contexts[contextName], nil
+}
+
+// Save replaces the saved state of contextName, stamped with now
+func (s *Store) Save(contextName string, ctx Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	ctx.UpdatedAt = now
+	st.Contexts[contextName] = &ctx
+	return s.write(st)
+}
+
+// Clear forgets the saved state of contextName
+func (s *Store) Clear(contextName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := st.Contexts[contextName]; !ok {
+		return nil
+	}
+	delete(st.Contexts, contextName)
+	return s.write(st)
+}
+
+// read loads the state file; a missing file is empty
+func (s *Store) read() (*state, error) {
+	st := &state{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read daemon state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("failed to parse daemon state: %w", err)
+		}
+	}
+	if st.Contexts == nil {
+		st.Contexts = make(map[string]*Context)
+	}
+	return st, nil
+}
+
+// write atomically replaces the state file
+func (s *Store) write(st *state) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create daemon state directory: %w", err)
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode daemon state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace daemon state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package daemonstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSaveLoadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "daemon.json")
+	store := NewStore(path)
+
+	saved, err := store.Load("")
+	require.NoError(t, err)
+	assert.Nil(t, saved, "missing state file should yield no state")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.Save("", Context{
+		Server: &Server{ID: 42, Name: "dockbridge-1", IPAddress: "203.0.113.7", VolumeIDs: []string{"7"}, Fingerprint: "SHA256:abc"},
+		Forwards: []Forward{
+			{ID: "abc-80", Type: "tcp", ContainerID: "abc", ContainerName: "web", LocalPort: 8080, RemotePort: 80},
+		},
+	}, now))
+	require.NoError(t, store.Save("gpu", Context{Server: &Server{ID: 43, Name: "dockbridge-gpu-1"}}, now))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	saved, err = store.Load("")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	require.NotNil(t, saved.Server)
+	assert.Equal(t, int64(42), saved.Server.ID)
+	assert.Equal(t, "SHA256:abc", saved.Server.Fingerprint)
+	assert.Equal(t, []string{"7"}, saved.Server.VolumeIDs)
+	require.Len(t, saved.Forwards, 1)
+	assert.Equal(t, 8080, saved.Forwards[0].LocalPort)
+	assert.True(t, now.Equal(saved.UpdatedAt))
+
+	require.NoError(t, store.Clear(""))
+	saved, err = store.Load("")
+	require.NoError(t, err)
+	assert.Nil(t, saved)
+
+	// Other contexts are kept
+	saved, err = store.Load("gpu")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, int64(43), saved.Server.ID)
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+	_, err := NewStore(path).Load("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+)
+
+// Collector gathers dashboard state from the control API, the servers' keep-alive
+// endpoints, SSH and the local cost state
+type Collector struct {
+	// Control is the daemon's control API
+	Control controlv1.ControlServiceClient
+
+	// KeepAlive fetches the keep-alive status of the server named serverName at host;
+	// nil skips it
+	KeepAlive func(host, serverName string) (*keepalive.MonitorStatus, error)
+
+	// Remote inspects the server at host over SSH; nil skips it
+	Remote func(ctx context.Context, host string) (*Remote, error)
+
+	// Costs holds the estimated spend accrued by the daemon; nil skips costs
+	Costs  *cost.Store
+	Budget cost.Budget
+
+	// Timeout bounds the queries of one refresh
+	Timeout time.Duration
+}
+
+// Collect returns the current state of all contexts
+func (c *Collector) Collect(ctx context.Context) State {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	state := State{Time: time.Now(), Budget: c.Budget.Monthly}
+
+	var spend []cost.ServerSpend
+	if c.Costs != nil {
+		spend, _ = c.Costs.Month(cost.Month(state.Time))
+		for _, entry := range spend {
+			state.MonthSpend += entry.Amount
+			state.Currency = entry.Currency
+		}
+	}
+
+	status, err := c.Control.GetStatus(ctx, &controlv1.GetStatusRequest{All: true})
+	if err != nil {
+		state.Err = fmt.Errorf("daemon not reachable (is `dockbridge start` running?): %w", err)
+		return state
+	}
+
+	for _, contextStatus := range status.GetContexts() {
+		state.Contexts = append(state.Contexts, c.collectContext(ctx, contextStatus, spend))
+	}
+	return state
+}
+
+// collectContext gathers the state of one context
+func (c *Collector) collectContext(ctx context.Context, status *controlv1.ContextStatus, spend []cost.ServerSpend) ContextState {
+	contextState := ContextState{
+		Name:    status.GetName(),
+		Running: status.GetRunning(),
+	}
+
+	if forwards, err := c.Control.ListForwards(ctx, &controlv1.ListForwardsRequest{Context: status.GetName()}); err == nil {
+		for _, forward := range forwards.GetForwards() {
+			contextState.Forwards = append(contextState.Forwards, toForward(forward))
+		}
+	}
+
+	srv := status.GetServer()
+	if srv == nil {
+		return contextState
+	}
+	contextState.Server = &Server{
+		ID:        srv.GetId(),
+		Name:      srv.GetName(),
+		Status:    srv.GetStatus(),
+		IPAddress: srv.GetIpAddress(),
+		CreatedAt: srv.GetCreatedAt().AsTime(),
+	}
+
+	for _, entry := range spend {
+		if entry.ServerID == srv.GetId() {
+			contextState.Cost = &Cost{Amount: entry.Amount, Hourly: entry.HourlyRate, Currency: entry.Currency}
+		}
+	}
+
+	if srv.GetIpAddress() == "" {
+		return contextState
+	}
+	if c.KeepAlive != nil {
+		if keepAliveStatus, err := c.KeepAlive(srv.GetIpAddress(), srv.GetName()); err == nil {
+			contextState.KeepAlive = toKeepAlive(keepAliveStatus)
+		}
+	}
+	if c.Remote != nil {
+		contextState.Remote, contextState.RemoteErr = c.Remote(ctx, srv.GetIpAddress())
+	}
+	return contextState
+}
+
+// toForward converts a forward reported by the control API
+func toForward(forward *controlv1.Forward) Forward {
+	local := forward.GetLocalSocket()
+	remote := forward.GetRemoteSocket()
+	if forward.GetType() != "unix" {
+		local = net.JoinHostPort(forward.GetBindAddress(), strconv.Itoa(int(forward.GetLocalPort())))
+		remote = strconv.Itoa(int(forward.GetRemotePort()))
+	}
+	return Forward{
+		Type:      forward.GetType(),
+		Local:     local,
+		Remote:    remote,
+		Container: forward.GetContainerName(),
+		Status:    forward.GetStatus(),
+		Bytes:     forward.GetBytesTransferred(),
+	}
+}
+
+// toKeepAlive converts a keep-alive status, or returns nil if its durations are malformed
+func toKeepAlive(status *keepalive.MonitorStatus) *KeepAlive {
+	since, err := time.ParseDuration(status.TimeSinceHeartbeat)
+	if err != nil {
+		return nil
+	}
+	until, err := time.ParseDuration(status.TimeUntilShutdown)
+	if err != nil {
+		return nil
+	}
+	clients := 0
+	for _, client := range status.Clients {
+		if !client.IsTimedOut {
+			clients++
+		}
+	}
+	return &KeepAlive{SinceHeartbeat: since, UntilShutdown: until, TimedOut: status.IsTimedOut, Clients: clients}
+}
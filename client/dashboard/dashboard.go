@@ -0,0 +1,113 @@
+// Package dashboard shows a live terminal dashboard of the running DockBridge contexts:
+// their servers, keep-alive countdown, SSH health, forwards, containers, volume usage
+// and estimated cost. Data comes from the daemon's control API and read-only queries of
+// the server, so watching the dashboard never provisions a server or keeps it alive.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultRefreshInterval is how often the dashboard is refreshed
+const DefaultRefreshInterval = 2 * time.Second
+
+// State is one snapshot of everything the dashboard shows
+type State struct {
+	Time     time.Time
+	Contexts []ContextState
+	// Err is set when the daemon could not be queried at all
+	Err error
+
+	// MonthSpend is the estimated spend of all servers this month
+	MonthSpend float64
+	Currency   string
+	// Budget is the monthly budget; zero when disabled
+	Budget float64
+}
+
+// ContextState is the state of one context's daemon and server
+type ContextState struct {
+	Name    string
+	Running bool
+	// Server is nil while no server is connected
+	Server *Server
+
+	KeepAlive *KeepAlive
+	// RemoteErr is why the server could not be inspected over SSH, if it could not
+	RemoteErr error
+	Remote    *Remote
+
+	Forwards []Forward
+	Cost     *Cost
+}
+
+// Server describes the connected server
+type Server struct {
+	ID        int64
+	Name      string
+	Status    string
+	IPAddress string
+	CreatedAt time.Time
+}
+
+// KeepAlive is the server-side keep-alive state
+type KeepAlive struct {
+	SinceHeartbeat time.Duration
+	UntilShutdown  time.Duration
+	TimedOut       bool
+	// Clients counts the clients whose heartbeats keep the server alive
+	Clients int
+}
+
+// Forward is an active port or socket forward
+type Forward struct {
+	// Type is tcp, udp or unix
+	Type      string
+	Local     string
+	Remote    string
+	Container string
+	Status    string
+	Bytes     int64
+}
+
+// Cost is the estimated spend of a server this month
+type Cost struct {
+	Amount   float64
+	Hourly   float64
+	Currency string
+}
+
+// Run refreshes the dashboard on out every interval until ctx is cancelled. The
+// terminal's alternate screen is used so the previous output is restored on exit.
+func Run(ctx context.Context, collector *Collector, out io.Writer, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+
+	// Switch to the alternate screen and hide the cursor; undo both on exit
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		state := collector.Collect(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Fprint(out, "\x1b[H\x1b[2J")
+		if err := Render(out, state); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeControl serves fixed status and forwards
+type fakeControl struct {
+	controlv1.ControlServiceClient
+	status   *controlv1.GetStatusResponse
+	forwards map[string][]*controlv1.Forward
+	err      error
+}
+
+func (f *fakeControl) GetStatus(context.Context, *controlv1.GetStatusRequest, ...grpc.CallOption) (*controlv1.GetStatusResponse, error) {
+	return f.status, f.err
+}
+
+func (f *fakeControl) ListForwards(_ context.Context, req *controlv1.ListForwardsRequest, _ ...grpc.CallOption) (*controlv1.ListForwardsResponse, error) {
+	return &controlv1.ListForwardsResponse{Forwards: f.forwards[req.GetContext()]}, nil
+}
+
+func TestParseRemote(t *testing.T) {
+	remote := ParseRemote("web\tnginx:latest\tUp 5 minutes\ndb\tpostgres:16\tUp 1 hour\n---\n10000000000 2500000000\n")
+	require.Len(t, remote.Containers, 2)
+	assert.Equal(t, Container{Name: "db", Image: "postgres:16", Status: "Up 1 hour"}, remote.Containers[1])
+	assert.Equal(t, &Volume{Size: 10000000000, Used: 2500000000}, remote.Volume)
+
+	remote = ParseRemote("---\ndf: /var/lib/docker: No such file or directory\n")
+	assert.Empty(t, remote.Containers)
+	assert.Nil(t, remote.Volume)
+}
+
+func TestCollectAndRender(t *testing.T) {
+	store := cost.NewStore(filepath.Join(t.TempDir(), "cost.json"))
+	now := time.Now()
+	require.NoError(t, store.Accrue(cost.ServerInfo{ID: 7, CreatedAt: now.Add(-2 * time.Hour)}, cost.Rate{Currency: "EUR", Hourly: 0.5}, now))
+
+	collector := &Collector{
+		Control: &fakeControl{
+			status: &controlv1.GetStatusResponse{Contexts: []*controlv1.ContextStatus{
+				{Running: true, Server: &controlv1.Server{Id: 7, Name: "dockbridge-7", Status: "running", IpAddress: "192.0.2.7", CreatedAt: timestamppb.New(now.Add(-2 * time.Hour))}},
+				{Name: "gpu", Running: true},
+			}},
+			forwards: map[string][]*controlv1.Forward{
+				"": {{Type: "tcp", BindAddress: "127.0.0.1", LocalPort: 8080, RemotePort: 80, ContainerName: "web", Status: "active", BytesTransferred: 2048}},
+			},
+		},
+		KeepAlive: func(host, serverName string) (*keepalive.MonitorStatus, error) {
+			return &keepalive.MonitorStatus{TimeSinceHeartbeat: "12s", TimeUntilShutdown: "4m48s"}, nil
+		},
+		Remote: func(ctx context.Context, host string) (*Remote, error) {
+			return ParseRemote("web\tnginx:latest\tUp 5 minutes\n---\n1000 250\n"), nil
+		},
+		Costs:  store,
+		Budget: cost.Budget{Monthly: 10},
+	}
+
+	state := collector.Collect(context.Background())
+	require.NoError(t, state.Err)
+	require.Len(t, state.Contexts, 2)
+	assert.Nil(t, state.Contexts[1].Server)
+
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, state))
+	text := out.String()
+	assert.Contains(t, text, "dockbridge-7 (running) 192.0.2.7")
+	assert.Contains(t, text, "shutdown in 4m48s")
+	assert.Contains(t, text, "127.0.0.1:8080 → 80")
+	assert.Contains(t, text, "2.0 KiB")
+	assert.Contains(t, text, "nginx:latest")
+	assert.Contains(t, text, "(25%)")
+	assert.Contains(t, text, "1.00 EUR this month")
+	assert.Contains(t, text, "1.00 of 10.00 EUR budget")
+	assert.Contains(t, text, "Context: gpu")
+}
+
+func TestCollectDaemonUnreachable(t *testing.T) {
+	collector := &Collector{Control: &fakeControl{err: errors.New("connection refused")}}
+
+	state := collector.Collect(context.Background())
+	require.Error(t, state.Err)
+
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, state))
+	assert.Contains(t, out.String(), "daemon not reachable")
+}
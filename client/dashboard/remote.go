@@ -0,0 +1,149 @@
+package dashboard
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// remoteCommand lists the running containers and the usage of the Docker data volume
+const remoteCommand = `docker ps --format '{{.Names}}\t{{.Image}}\t{{.Status}}'; ` +
+	`echo ---; df -B1 --output=size,used /var/lib/docker | tail -n 1`
+
+// Remote is what the dashboard inspects on the server itself
+type Remote struct {
+	Containers []Container
+	// Volume is nil when the Docker data volume could not be read
+	Volume *Volume
+	// Latency is the round trip of the SSH command, a measure of the tunnel's health
+	Latency time.Duration
+}
+
+// Container is a running container
+type Container struct {
+	Name   string
+	Image  string
+	Status string
+}
+
+// Volume is the usage of the Docker data volume in bytes
+type Volume struct {
+	Size uint64
+	Used uint64
+}
+
+// Inspector inspects servers over SSH, keeping one connection per server between
+// refreshes. SSH sessions are not Docker API activity, so they do not keep the
+// server alive.
+type Inspector struct {
+	// NewClient creates an SSH client for host
+	NewClient func(host string) ssh.Client
+
+	mu      sync.Mutex
+	clients map[string]ssh.Client
+}
+
+// Inspect returns the containers and volume usage of the server at host
+func (i *Inspector) Inspect(ctx context.Context, host string) (*Remote, error) {
+	client, err := i.client(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	output, err := client.ExecuteCommand(ctx, remoteCommand)
+	if err != nil {
+		// Reconnect on the next refresh
+		i.drop(host)
+		return nil, err
+	}
+
+	remote := ParseRemote(string(output))
+	remote.Latency = time.Since(start)
+	return remote, nil
+}
+
+// Dial returns a dialer that connects to addresses as seen from the server at host,
+// through its SSH connection
+func (i *Inspector) Dial(host string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		client, err := i.client(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := client.Dial(network, addr)
+		if err != nil {
+			i.drop(host)
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// Close closes all SSH connections
+func (i *Inspector) Close() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for host, client := range i.clients {
+		client.Close()
+		delete(i.clients, host)
+	}
+}
+
+// client returns the connected SSH client of host, connecting if needed
+func (i *Inspector) client(ctx context.Context, host string) (ssh.Client, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if client, ok := i.clients[host]; ok {
+		return client, nil
+	}
+	client := i.NewClient(host)
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	if i.clients == nil {
+		i.clients = make(map[string]ssh.Client)
+	}
+	i.clients[host] = client
+	return client, nil
+}
+
+// drop closes and forgets the SSH client of host
+func (i *Inspector) drop(host string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if client, ok := i.clients[host]; ok {
+		client.Close()
+		delete(i.clients, host)
+	}
+}
+
+// ParseRemote parses the output of the remote inspection command
+func ParseRemote(output string) *Remote {
+	containersOut, volumeOut, _ := strings.Cut(output, "---\n")
+
+	remote := &Remote{}
+	for _, line := range strings.Split(strings.TrimSpace(containersOut), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		remote.Containers = append(remote.Containers, Container{Name: fields[0], Image: fields[1], Status: fields[2]})
+	}
+
+	fields := strings.Fields(volumeOut)
+	if len(fields) == 2 {
+		size, sizeErr := strconv.ParseUint(fields[0], 10, 64)
+		used, usedErr := strconv.ParseUint(fields[1], 10, 64)
+		if sizeErr == nil && usedErr == nil {
+			remote.Volume = &Volume{Size: size, Used: used}
+		}
+	}
+	return remote
+}
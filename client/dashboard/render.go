@@ -0,0 +1,124 @@
+package dashboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/traffic"
+)
+
+// Render writes state as text to w
+func Render(w io.Writer, state State) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "DockBridge status — %s (Ctrl+C to exit)\n", state.Time.Format("2006-01-02 15:04:05"))
+
+	if state.Err != nil {
+		fmt.Fprintf(out, "\n❌ %v\n", state.Err)
+	}
+	for _, contextState := range state.Contexts {
+		fmt.Fprintln(out)
+		renderContext(out, contextState, state.Time)
+	}
+
+	if state.Currency != "" {
+		fmt.Fprintln(out)
+		if state.Budget > 0 {
+			fmt.Fprintf(out, "Estimated spend this month: %.2f of %.2f %s budget (%.0f%%)\n",
+				state.MonthSpend, state.Budget, state.Currency, state.MonthSpend/state.Budget*100)
+		} else {
+			fmt.Fprintf(out, "Estimated spend this month: %.2f %s\n", state.MonthSpend, state.Currency)
+		}
+	}
+
+	return out.Flush()
+}
+
+// renderContext writes the state of one context
+func renderContext(out io.Writer, state ContextState, now time.Time) {
+	name := state.Name
+	if name == "" {
+		name = "default"
+	}
+	daemon := "running"
+	if !state.Running {
+		daemon = "stopped"
+	}
+	fmt.Fprintf(out, "Context: %s (daemon %s)\n", name, daemon)
+
+	if state.Server == nil {
+		fmt.Fprintln(out, "  Server:     none (provisioned on the next Docker command)")
+	} else {
+		srv := state.Server
+		fmt.Fprintf(out, "  Server:     %s (%s) %s, up %s\n", srv.Name, srv.Status, srv.IPAddress, FormatDuration(now.Sub(srv.CreatedAt)))
+
+		switch {
+		case state.KeepAlive == nil:
+			fmt.Fprintln(out, "  Keep-alive: unknown")
+		case state.KeepAlive.TimedOut:
+			fmt.Fprintf(out, "  Keep-alive: ⚠️  timed out, last heartbeat %s ago\n", FormatDuration(state.KeepAlive.SinceHeartbeat))
+		case state.KeepAlive.Clients > 1:
+			fmt.Fprintf(out, "  Keep-alive: %d clients, last heartbeat %s ago, shutdown in %s\n", state.KeepAlive.Clients,
+				FormatDuration(state.KeepAlive.SinceHeartbeat), FormatDuration(state.KeepAlive.UntilShutdown))
+		default:
+			fmt.Fprintf(out, "  Keep-alive: last heartbeat %s ago, shutdown in %s\n",
+				FormatDuration(state.KeepAlive.SinceHeartbeat), FormatDuration(state.KeepAlive.UntilShutdown))
+		}
+
+		switch {
+		case state.RemoteErr != nil:
+			fmt.Fprintf(out, "  SSH:        ❌ %v\n", state.RemoteErr)
+		case state.Remote != nil:
+			fmt.Fprintf(out, "  SSH:        ✅ healthy (%s)\n", state.Remote.Latency.Round(time.Millisecond))
+		}
+
+		if state.Remote != nil && state.Remote.Volume != nil && state.Remote.Volume.Size > 0 {
+			volume := state.Remote.Volume
+			fmt.Fprintf(out, "  Volume:     %s of %s used (%.0f%%)\n", traffic.FormatBytes(volume.Used),
+				traffic.FormatBytes(volume.Size), float64(volume.Used)/float64(volume.Size)*100)
+		}
+
+		if state.Cost != nil {
+			fmt.Fprintf(out, "  Cost:       %.2f %s this month (%.4f %s/hour)\n",
+				state.Cost.Amount, state.Cost.Currency, state.Cost.Hourly, state.Cost.Currency)
+		}
+	}
+
+	if len(state.Forwards) > 0 {
+		fmt.Fprintf(out, "  Forwards (%d):\n", len(state.Forwards))
+		table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, forward := range state.Forwards {
+			fmt.Fprintf(table, "    %s → %s\t%s\t%s\t%s\n", forward.Local, forward.Remote,
+				forward.Container, forward.Status, traffic.FormatBytes(uint64(max(forward.Bytes, 0))))
+		}
+		table.Flush()
+	}
+
+	if state.Remote != nil {
+		fmt.Fprintf(out, "  Containers (%d):\n", len(state.Remote.Containers))
+		table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, container := range state.Remote.Containers {
+			fmt.Fprintf(table, "    %s\t%s\t%s\n", container.Name, container.Image, container.Status)
+		}
+		table.Flush()
+	}
+}
+
+// FormatDuration formats d rounded to seconds, without zero units
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,234 @@
+// Package digitalocean implements the cloud provider interface with DigitalOcean
+// droplets and block storage volumes, using the public REST API (v2).
+package digitalocean
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// ProviderName is the name the DigitalOcean backend is registered under
+const ProviderName = "digitalocean"
+
+// DefaultBaseURL is the DigitalOcean API endpoint
+const DefaultBaseURL = "https://api.digitalocean.com/v2"
+
+// dockbridgeTag marks droplets and volumes created by DockBridge
+const dockbridgeTag = "dockbridge"
+
+// perPage is the page size used for list requests
+const perPage = 200
+
+func init() {
+	provider.Register(ProviderName, func(cfg *config.ClientConfig, server config.ServerSettings) (provider.CloudProvider, error) {
+		return NewClient(&Config{
+			APIToken:   server.APIToken,
+			Image:      cfg.DigitalOcean.Image,
+			VolumeSize: server.VolumeSize,
+			Owner:      provider.LocalOwner(),
+		})
+	})
+}
+
+// Config holds the DigitalOcean client configuration
+type Config struct {
+	APIToken   string
+	Image      string
+	VolumeSize int
+
+	// Owner tags the volumes the client creates; only volumes of this owner are reused.
+	// A zero owner disables both.
+	Owner provider.Owner
+
+	// BaseURL overrides DefaultBaseURL (used in tests)
+	BaseURL string
+}
+
+// Client implements provider.CloudProvider for DigitalOcean
+type Client struct {
+	config     *Config
+	baseURL    string
+	httpClient *http.Client
+
+	// pollInterval is how often droplet and action status is polled
+	pollInterval time.Duration
+}
+
+// NewClient creates a new DigitalOcean client instance
+func NewClient(config *Config) (*Client, error) {
+	if config.APIToken == "" {
+		return nil, errors.New("DigitalOcean API token is required")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		config:       config,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+		pollInterval: 5 * time.Second,
+	}, nil
+}
+
+// apiError is the error body returned by the DigitalOcean API
+type apiError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// do performs an API request, encoding body as JSON and decoding the response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	reqURL := path
+	if !strings.HasPrefix(path, "http") {
+		reqURL = c.baseURL + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.APIToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s failed", method, path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("%s %s: %s (status %d)", method, path, apiErr.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
+
+// links holds pagination links of list responses
+type links struct {
+	Pages struct {
+		Next string `json:"next"`
+	} `json:"pages"`
+}
+
+// listPath appends the page size to a list path
+func listPath(path string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("per_page", fmt.Sprint(perPage))
+	return path + "?" + query.Encode()
+}
+
+// action is an asynchronous DigitalOcean action
+type action struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// waitForAction polls an action until it completes
+func (c *Client) waitForAction(ctx context.Context, actionID int64) error {
+	for {
+		var resp struct {
+			Action action `json:"action"`
+		}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/actions/%d", actionID), nil, &resp); err != nil {
+			return errors.Wrap(err, "failed to get action status")
+		}
+
+		switch resp.Action.Status {
+		case "completed":
+			return nil
+		case "errored":
+			return fmt.Errorf("action %d failed", actionID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// Ensure Client implements provider.CloudProvider
+var _ provider.CloudProvider = (*Client)(nil)
+
+// invalidTagChars matches what DigitalOcean tags may not contain
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// labelTags encodes labels as "key:value" tags, as DigitalOcean has no labels
+func labelTags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for key, value := range labels {
+		// An empty value, such as the default context, reads back as a missing label
+		if value == "" {
+			continue
+		}
+		tags = append(tags, key+":"+invalidTagChars.ReplaceAllString(value, "_"))
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// tagLabels decodes the "key:value" tags of a droplet or volume into labels
+func tagLabels(tags []string) map[string]string {
+	labels := make(map[string]string)
+	for _, tag := range tags {
+		if key, value, ok := strings.Cut(tag, ":"); ok {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// ownerTags returns the owner tags of a new volume, or none without an owner
+func (c *Client) ownerTags() []string {
+	if c.config.Owner.ID == "" {
+		return nil
+	}
+	return labelTags(c.config.Owner.Labels())
+}
+
+// owns reports whether a volume with labels may be reused; without an owner all may
+func (c *Client) owns(labels map[string]string) bool {
+	return c.config.Owner.ID == "" || c.config.Owner.Owns(labels)
+}
@@ -0,0 +1,346 @@
+package digitalocean
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(&Config{APIToken: "test-token", Image: "ubuntu-24-04-x64", VolumeSize: 10, BaseURL: srv.URL})
+	require.NoError(t, err)
+	client.pollInterval = time.Millisecond
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(&Config{})
+	assert.Error(t, err)
+
+	client, err := NewClient(&Config{APIToken: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+}
+
+func TestRegistered(t *testing.T) {
+	assert.True(t, provider.IsRegistered(ProviderName))
+}
+
+func TestProvisionServer(t *testing.T) {
+	var polls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /droplets", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "dockbridge-test", body["name"])
+		assert.Equal(t, "fra1", body["region"])
+		assert.Equal(t, "s-2vcpu-4gb", body["size"])
+		assert.Equal(t, "ubuntu-24-04-x64", body["image"])
+		assert.Equal(t, []any{"dockbridge"}, body["tags"])
+		assert.Equal(t, []any{float64(42)}, body["ssh_keys"])
+		assert.Equal(t, []any{"vol-1"}, body["volumes"])
+
+		writeJSON(w, http.StatusAccepted, map[string]any{"droplet": map[string]any{"id": 7, "status": "new"}})
+	})
+	mux.HandleFunc("GET /droplets/7", func(w http.ResponseWriter, r *http.Request) {
+		d := map[string]any{"id": 7, "name": "dockbridge-test", "status": "new"}
+		if polls.Add(1) > 1 {
+			d["status"] = "active"
+			d["volume_ids"] = []string{"vol-1"}
+			d["networks"] = map[string]any{"v4": []map[string]any{
+				{"ip_address": "10.0.0.2", "type": "private"},
+				{"ip_address": "203.0.113.7", "type": "public"},
+			}}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"droplet": d})
+	})
+
+	client := newTestClient(t, mux)
+	server, err := client.ProvisionServer(context.Background(), &provider.ServerConfig{
+		Name:       "dockbridge-test",
+		ServerType: "s-2vcpu-4gb",
+		Location:   "fra1",
+		SSHKeyID:   42,
+		VolumeID:   "vol-1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7), server.ID)
+	assert.Equal(t, "running", server.Status)
+	assert.Equal(t, "203.0.113.7", server.IPAddress)
+	assert.Equal(t, "vol-1", server.VolumeID)
+	assert.GreaterOrEqual(t, polls.Load(), int32(2))
+}
+
+func TestListServersPaginates(t *testing.T) {
+	var srvURL string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /droplets", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "dockbridge", r.URL.Query().Get("tag_name"))
+
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, http.StatusOK, map[string]any{"droplets": []map[string]any{{"id": 2, "status": "off"}}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"droplets": []map[string]any{{"id": 1, "status": "active"}},
+			"links":    map[string]any{"pages": map[string]any{"next": srvURL + "/droplets?tag_name=dockbridge&page=2"}},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	srvURL = srv.URL
+
+	client, err := NewClient(&Config{APIToken: "test-token", BaseURL: srv.URL})
+	require.NoError(t, err)
+
+	servers, err := client.ListServers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+	assert.Equal(t, "running", servers[0].Status)
+	assert.Equal(t, "off", servers[1].Status)
+}
+
+func TestFindOrCreateDockerVolume(t *testing.T) {
+	var created atomic.Bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /volumes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"volumes": []map[string]any{
+			{"id": "attached", "name": "dockbridge-docker-data-1", "droplet_ids": []int{9}, "region": map[string]any{"slug": "fra1"}},
+			{"id": "other-region", "name": "dockbridge-docker-data-2", "region": map[string]any{"slug": "ams3"}},
+		}})
+	})
+	mux.HandleFunc("POST /volumes", func(w http.ResponseWriter, r *http.Request) {
+		created.Store(true)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, float64(10), body["size_gigabytes"])
+		assert.Equal(t, "ext4", body["filesystem_type"])
+
+		writeJSON(w, http.StatusCreated, map[string]any{"volume": map[string]any{
+			"id": "new", "name": body["name"], "size_gigabytes": 10, "region": map[string]any{"slug": "fra1"},
+		}})
+	})
+
+	client := newTestClient(t, mux)
+
+	volume, err := client.FindOrCreateDockerVolume(context.Background(), "ams3")
+	require.NoError(t, err)
+	assert.Equal(t, "other-region", volume.ID)
+	assert.Equal(t, "available", volume.Status)
+	assert.Equal(t, "/dev/disk/by-id/scsi-0DO_Volume_dockbridge-docker-data-2", volume.Device)
+	assert.False(t, created.Load())
+
+	volume, err = client.FindOrCreateDockerVolume(context.Background(), "fra1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", volume.ID)
+	assert.True(t, created.Load())
+}
+
+func TestFindOrCreateNamedVolume(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /volumes", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"volumes": []map[string]any{
+			{"id": "docker", "name": "dockbridge-docker-data-1", "region": map[string]any{"slug": "fra1"}},
+			{"id": "data", "name": "dockbridge-data-1", "region": map[string]any{"slug": "fra1"}},
+		}})
+	})
+	mux.HandleFunc("POST /volumes", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Contains(t, body["name"], "dockbridge-cache-")
+		assert.Equal(t, float64(50), body["size_gigabytes"])
+
+		writeJSON(w, http.StatusCreated, map[string]any{"volume": map[string]any{
+			"id": "new", "name": body["name"], "size_gigabytes": 50, "region": map[string]any{"slug": "fra1"},
+		}})
+	})
+
+	client := newTestClient(t, mux)
+
+	volume, err := client.FindOrCreateNamedVolume(context.Background(), "data", 50, "fra1")
+	require.NoError(t, err)
+	assert.Equal(t, "data", volume.ID, "the data volume is reused, not the Docker data volume")
+	assert.Equal(t, "/dev/disk/by-id/scsi-0DO_Volume_dockbridge-data-1", volume.Device)
+
+	volume, err = client.FindOrCreateNamedVolume(context.Background(), "cache", 50, "fra1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", volume.ID)
+}
+
+func TestAttachVolumeWaitsForAction(t *testing.T) {
+	var actionPolls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /volumes/vol-1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"volume": map[string]any{"id": "vol-1", "region": map[string]any{"slug": "fra1"}}})
+	})
+	mux.HandleFunc("POST /volumes/vol-1/actions", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "attach", body["type"])
+		assert.Equal(t, float64(7), body["droplet_id"])
+		assert.Equal(t, "fra1", body["region"])
+
+		writeJSON(w, http.StatusAccepted, map[string]any{"action": map[string]any{"id": 99, "status": "in-progress"}})
+	})
+	mux.HandleFunc("GET /actions/99", func(w http.ResponseWriter, r *http.Request) {
+		status := "in-progress"
+		if actionPolls.Add(1) > 1 {
+			status = "completed"
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"action": map[string]any{"id": 99, "status": status}})
+	})
+
+	client := newTestClient(t, mux)
+	require.NoError(t, client.AttachVolume(context.Background(), "7", "vol-1"))
+	assert.Equal(t, int32(2), actionPolls.Load())
+}
+
+func TestResizeVolume(t *testing.T) {
+	var resized atomic.Bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /volumes/vol-1", func(w http.ResponseWriter, r *http.Request) {
+		size := 10
+		if resized.Load() {
+			size = 40
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"volume": map[string]any{"id": "vol-1", "size_gigabytes": size, "region": map[string]any{"slug": "fra1"}}})
+	})
+	mux.HandleFunc("POST /volumes/vol-1/actions", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "resize", body["type"])
+		assert.Equal(t, float64(40), body["size_gigabytes"])
+		assert.Equal(t, "fra1", body["region"])
+
+		resized.Store(true)
+		writeJSON(w, http.StatusAccepted, map[string]any{"action": map[string]any{"id": 99, "status": "completed"}})
+	})
+	mux.HandleFunc("GET /actions/99", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"action": map[string]any{"id": 99, "status": "completed"}})
+	})
+
+	client := newTestClient(t, mux)
+	_, err := client.ResizeVolume(context.Background(), "vol-1", 5)
+	assert.ErrorContains(t, err, "cannot shrink")
+
+	volume, err := client.ResizeVolume(context.Background(), "vol-1", 40)
+	require.NoError(t, err)
+	assert.Equal(t, 40, volume.Size)
+}
+
+func TestPowerOnServer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /droplets/7/actions", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "power_on", body["type"])
+
+		writeJSON(w, http.StatusCreated, map[string]any{"action": map[string]any{"id": 5, "status": "in-progress"}})
+	})
+	mux.HandleFunc("GET /actions/5", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"action": map[string]any{"id": 5, "status": "completed"}})
+	})
+	mux.HandleFunc("GET /droplets/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"droplet": map[string]any{
+			"id": 7, "name": "dockbridge-1", "status": "active", "size_slug": "s-2vcpu-4gb",
+			"networks": map[string]any{"v4": []map[string]any{{"ip_address": "203.0.113.7", "type": "public"}}},
+		}})
+	})
+
+	client := newTestClient(t, mux)
+	server, err := client.PowerOnServer(context.Background(), "7")
+	require.NoError(t, err)
+	assert.Equal(t, "running", server.Status)
+	assert.Equal(t, "203.0.113.7", server.IPAddress)
+	assert.Equal(t, "s-2vcpu-4gb", server.ServerType)
+}
+
+func TestPowerOffServerSkipsStoppedDroplet(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /droplets/7", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"droplet": map[string]any{"id": 7, "status": "off"}})
+	})
+	mux.HandleFunc("POST /droplets/7/actions", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected action for a stopped droplet")
+	})
+
+	client := newTestClient(t, mux)
+	require.NoError(t, client.PowerOffServer(context.Background(), "7"))
+}
+
+func TestManageSSHKeysReusesExisting(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /account/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"ssh_keys": []map[string]any{
+			{"id": 5, "name": "laptop", "public_key": "ssh-ed25519 AAAAkey old-comment"},
+		}})
+	})
+	mux.HandleFunc("POST /account/keys", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected key creation")
+	})
+
+	client := newTestClient(t, mux)
+	key, err := client.ManageSSHKeys(context.Background(), "ssh-ed25519 AAAAkey user@host\n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), key.ID)
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /droplets/1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]any{"id": "not_found", "message": "The resource you were accessing could not be found."})
+	})
+
+	client := newTestClient(t, mux)
+	_, err := client.GetServer(context.Background(), "1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not be found")
+	assert.Contains(t, err.Error(), "status 404")
+
+	_, err = client.GetServer(context.Background(), "abc")
+	assert.Error(t, err)
+}
+
+func TestLabelTags(t *testing.T) {
+	owner := provider.Owner{ID: "laptop.local", Profile: "work", Version: "0.1.0"}
+	labels := owner.ServerLabels("gpu")
+
+	tags := labelTags(labels)
+	assert.Contains(t, tags, "dockbridge-owner:laptop-local")
+	assert.Contains(t, tags, "dockbridge-context:gpu")
+
+	// Labels survive the round trip through droplet tags
+	decoded := tagLabels(append(tags, dockbridgeTag))
+	assert.Equal(t, labels, decoded)
+	assert.True(t, owner.Owns(decoded))
+	assert.False(t, provider.Owner{ID: "teammate"}.Owns(decoded))
+}
@@ -0,0 +1,193 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// droplet is the API representation of a droplet
+type droplet struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	VolumeIDs []string  `json:"volume_ids"`
+	SizeSlug  string    `json:"size_slug"`
+	Tags      []string  `json:"tags"`
+	Networks  struct {
+		V4 []struct {
+			IPAddress string `json:"ip_address"`
+			Type      string `json:"type"`
+		} `json:"v4"`
+	} `json:"networks"`
+}
+
+// publicIPv4 returns the droplet's public IPv4 address, if assigned
+func (d *droplet) publicIPv4() string {
+	for _, network := range d.Networks.V4 {
+		if network.Type == "public" {
+			return network.IPAddress
+		}
+	}
+	return ""
+}
+
+// dropletStatus maps droplet states onto the provider-neutral status vocabulary,
+// in which "running" marks a usable server
+func dropletStatus(status string) string {
+	switch status {
+	case "new":
+		return "initializing"
+	case "active":
+		return "running"
+	case "archive":
+		return "deleting"
+	default:
+		return status
+	}
+}
+
+// convertDroplet converts a droplet to the provider-neutral server type
+func convertDroplet(d *droplet) *provider.Server {
+	var volumeID string
+	if len(d.VolumeIDs) > 0 {
+		volumeID = d.VolumeIDs[0]
+	}
+
+	return &provider.Server{
+		ID:        d.ID,
+		Name:      d.Name,
+		Status:    dropletStatus(d.Status),
+		IPAddress: d.publicIPv4(),
+		VolumeID:  volumeID,
+		VolumeIDs: d.VolumeIDs,
+		CreatedAt: d.CreatedAt,
+
+		ServerType: d.SizeSlug,
+
+		Labels: tagLabels(d.Tags),
+	}
+}
+
+// ProvisionServer creates a droplet and waits until it is active with a public IP
+func (c *Client) ProvisionServer(ctx context.Context, config *provider.ServerConfig) (*provider.Server, error) {
+	image := config.ImageName
+	if image == "" {
+		image = c.config.Image
+	}
+
+	body := map[string]any{
+		"name":      config.Name,
+		"region":    config.Location,
+		"size":      config.ServerType,
+		"image":     image,
+		"user_data": config.UserData,
+		"tags":      append([]string{dockbridgeTag}, labelTags(config.Labels)...),
+	}
+	if config.SSHKeyID > 0 {
+		body["ssh_keys"] = []int64{config.SSHKeyID}
+	}
+	var volumes []string
+	if config.VolumeID != "" {
+		volumes = append(volumes, config.VolumeID)
+	}
+	for _, volume := range config.Volumes {
+		volumes = append(volumes, volume.ID)
+	}
+	if len(volumes) > 0 {
+		body["volumes"] = volumes
+	}
+
+	var resp struct {
+		Droplet droplet `json:"droplet"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/droplets", body, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to create droplet")
+	}
+
+	return c.waitForDroplet(ctx, resp.Droplet.ID)
+}
+
+// waitForDroplet polls a droplet until it is active and has a public IPv4 address
+func (c *Client) waitForDroplet(ctx context.Context, id int64) (*provider.Server, error) {
+	for {
+		d, err := c.getDroplet(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to wait for droplet creation")
+		}
+		if d.Status == "active" && d.publicIPv4() != "" {
+			return convertDroplet(d), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "failed to wait for droplet creation")
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// getDroplet fetches a droplet by ID
+func (c *Client) getDroplet(ctx context.Context, id int64) (*droplet, error) {
+	var resp struct {
+		Droplet droplet `json:"droplet"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/droplets/%d", id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Droplet, nil
+}
+
+// DestroyServer deletes a droplet; attached volumes are detached and preserved
+func (c *Client) DestroyServer(ctx context.Context, serverID string) error {
+	id, err := strconv.ParseInt(serverID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid droplet ID %q", serverID)
+	}
+	if err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/droplets/%d", id), nil, nil); err != nil {
+		return errors.Wrap(err, "failed to delete droplet")
+	}
+	return nil
+}
+
+// GetServer retrieves droplet information
+func (c *Client) GetServer(ctx context.Context, serverID string) (*provider.Server, error) {
+	id, err := strconv.ParseInt(serverID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid droplet ID %q", serverID)
+	}
+	d, err := c.getDroplet(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get droplet")
+	}
+	return convertDroplet(d), nil
+}
+
+// ListServers lists the droplets created by DockBridge
+func (c *Client) ListServers(ctx context.Context) ([]*provider.Server, error) {
+	var servers []*provider.Server
+
+	path := listPath("/droplets", url.Values{"tag_name": {dockbridgeTag}})
+	for path != "" {
+		var resp struct {
+			Droplets []droplet `json:"droplets"`
+			Links    links     `json:"links"`
+		}
+		if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, errors.Wrap(err, "failed to list droplets")
+		}
+		for i := range resp.Droplets {
+			servers = append(servers, convertDroplet(&resp.Droplets[i]))
+		}
+		path = resp.Links.Pages.Next
+	}
+
+	return servers, nil
+}
@@ -0,0 +1,86 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// sshKey is the API representation of an account SSH key
+type sshKey struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	PublicKey   string `json:"public_key"`
+}
+
+func convertSSHKey(k *sshKey) *provider.SSHKey {
+	return &provider.SSHKey{
+		ID:          k.ID,
+		Name:        k.Name,
+		Fingerprint: k.Fingerprint,
+		PublicKey:   k.PublicKey,
+	}
+}
+
+// ManageSSHKeys returns the account key matching publicKey, registering it if needed
+func (c *Client) ManageSSHKeys(ctx context.Context, publicKey string) (*provider.SSHKey, error) {
+	existing, err := c.findSSHKey(ctx, publicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search for existing SSH key")
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	body := map[string]any{
+		"name":       fmt.Sprintf("dockbridge-key-%d", time.Now().Unix()),
+		"public_key": strings.TrimSpace(publicKey),
+	}
+
+	var resp struct {
+		SSHKey sshKey `json:"ssh_key"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/account/keys", body, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to create SSH key")
+	}
+	return convertSSHKey(&resp.SSHKey), nil
+}
+
+// findSSHKey searches the account keys for one with the same key material
+func (c *Client) findSSHKey(ctx context.Context, publicKey string) (*provider.SSHKey, error) {
+	want := keyMaterial(publicKey)
+
+	path := listPath("/account/keys", nil)
+	for path != "" {
+		var resp struct {
+			SSHKeys []sshKey `json:"ssh_keys"`
+			Links   links    `json:"links"`
+		}
+		if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		for i := range resp.SSHKeys {
+			if keyMaterial(resp.SSHKeys[i].PublicKey) == want {
+				return convertSSHKey(&resp.SSHKeys[i]), nil
+			}
+		}
+		path = resp.Links.Pages.Next
+	}
+
+	return nil, nil
+}
+
+// keyMaterial returns the type and base64 blob of an authorized_keys line, ignoring the comment
+func keyMaterial(publicKey string) string {
+	fields := strings.Fields(publicKey)
+	if len(fields) >= 2 {
+		return fields[0] + " " + fields[1]
+	}
+	return strings.TrimSpace(publicKey)
+}
@@ -0,0 +1,57 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// PowerOffServer gracefully shuts a droplet down
+func (c *Client) PowerOffServer(ctx context.Context, serverID string) error {
+	id, err := strconv.ParseInt(serverID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid droplet ID %q", serverID)
+	}
+
+	d, err := c.getDroplet(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get droplet")
+	}
+	if d.Status == provider.StatusOff {
+		return nil
+	}
+
+	return c.dropletAction(ctx, id, "shutdown")
+}
+
+// PowerOnServer starts a stopped droplet and returns it once it is active
+func (c *Client) PowerOnServer(ctx context.Context, serverID string) (*provider.Server, error) {
+	id, err := strconv.ParseInt(serverID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid droplet ID %q", serverID)
+	}
+
+	if err := c.dropletAction(ctx, id, "power_on"); err != nil {
+		return nil, err
+	}
+	return c.waitForDroplet(ctx, id)
+}
+
+// dropletAction starts a droplet action and waits for it to complete
+func (c *Client) dropletAction(ctx context.Context, id int64, actionType string) error {
+	var resp struct {
+		Action action `json:"action"`
+	}
+	body := map[string]any{"type": actionType}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/droplets/%d/actions", id), body, &resp); err != nil {
+		return errors.Wrapf(err, "failed to %s droplet", actionType)
+	}
+	return c.waitForAction(ctx, resp.Action.ID)
+}
+
+// Ensure Client can power droplets off and on
+var _ provider.PowerController = (*Client)(nil)
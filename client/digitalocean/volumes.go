@@ -0,0 +1,224 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// dockerVolumePrefix is the name prefix of Docker data volumes
+const dockerVolumePrefix = "dockbridge-docker-data"
+
+// volume is the API representation of a block storage volume
+type volume struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	SizeGigabytes int      `json:"size_gigabytes"`
+	DropletIDs    []int64  `json:"droplet_ids"`
+	Tags          []string `json:"tags"`
+	Region        struct {
+		Slug string `json:"slug"`
+	} `json:"region"`
+}
+
+// convertVolume converts a volume to the provider-neutral type. Unattached volumes
+// are "available" so that they can be reused for new droplets.
+func convertVolume(v *volume) *provider.Volume {
+	status := "available"
+	if len(v.DropletIDs) > 0 {
+		status = "attached"
+	}
+
+	return &provider.Volume{
+		ID:       v.ID,
+		Name:     v.Name,
+		Size:     v.SizeGigabytes,
+		Location: v.Region.Slug,
+		Status:   status,
+		Device:   "/dev/disk/by-id/scsi-0DO_Volume_" + v.Name,
+		Labels:   tagLabels(v.Tags),
+	}
+}
+
+// CreateVolume creates a new ext4 volume for Docker data
+func (c *Client) CreateVolume(ctx context.Context, size int, location string) (*provider.Volume, error) {
+	return c.createVolume(ctx, dockerVolumePrefix, "DockBridge Docker data", size, location)
+}
+
+// FindOrCreateNamedVolume reuses an unattached volume created for the named volume
+// configuration in the region, or creates one
+func (c *Client) FindOrCreateNamedVolume(ctx context.Context, name string, size int, location string) (*provider.Volume, error) {
+	volumes, err := c.ListVolumes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	prefix := "dockbridge-" + name + "-"
+	for _, v := range volumes {
+		if v.Location == location && strings.HasPrefix(v.Name, prefix) && v.Status == "available" && c.owns(v.Labels) {
+			return v, nil
+		}
+	}
+
+	return c.createVolume(ctx, "dockbridge-"+name, "DockBridge volume "+name, size, location)
+}
+
+// createVolume creates a new ext4 volume named after prefix
+func (c *Client) createVolume(ctx context.Context, prefix, description string, size int, location string) (*provider.Volume, error) {
+	body := map[string]any{
+		"name":            fmt.Sprintf("%s-%d", prefix, time.Now().Unix()),
+		"size_gigabytes":  size,
+		"region":          location,
+		"filesystem_type": "ext4",
+		"description":     description,
+		"tags":            append([]string{dockbridgeTag}, c.ownerTags()...),
+	}
+
+	var resp struct {
+		Volume volume `json:"volume"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/volumes", body, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to create volume")
+	}
+	return convertVolume(&resp.Volume), nil
+}
+
+// FindOrCreateDockerVolume reuses an unattached Docker data volume in the region or creates one
+func (c *Client) FindOrCreateDockerVolume(ctx context.Context, location string) (*provider.Volume, error) {
+	volumes, err := c.ListVolumes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	for _, v := range volumes {
+		if v.Location == location && strings.HasPrefix(v.Name, dockerVolumePrefix) && v.Status == "available" && c.owns(v.Labels) {
+			return v, nil
+		}
+	}
+
+	return c.CreateVolume(ctx, c.config.VolumeSize, location)
+}
+
+// AttachVolume attaches a volume to a droplet
+func (c *Client) AttachVolume(ctx context.Context, serverID, volumeID string) error {
+	dropletID, err := strconv.ParseInt(serverID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid droplet ID %q", serverID)
+	}
+
+	v, err := c.getVolume(ctx, volumeID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get volume")
+	}
+
+	return c.volumeAction(ctx, volumeID, map[string]any{
+		"type":       "attach",
+		"droplet_id": dropletID,
+		"region":     v.Region.Slug,
+	})
+}
+
+// DetachVolume detaches a volume from the droplet it is attached to
+func (c *Client) DetachVolume(ctx context.Context, volumeID string) error {
+	v, err := c.getVolume(ctx, volumeID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get volume")
+	}
+	if len(v.DropletIDs) == 0 {
+		return nil
+	}
+
+	return c.volumeAction(ctx, volumeID, map[string]any{
+		"type":       "detach",
+		"droplet_id": v.DropletIDs[0],
+		"region":     v.Region.Slug,
+	})
+}
+
+// ResizeVolume grows a volume to size GB. DigitalOcean volumes cannot shrink.
+func (c *Client) ResizeVolume(ctx context.Context, volumeID string, size int) (*provider.Volume, error) {
+	v, err := c.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get volume")
+	}
+	if size < v.SizeGigabytes {
+		return nil, fmt.Errorf("volume %s is %d GB and cannot shrink to %d GB", volumeID, v.SizeGigabytes, size)
+	}
+
+	if size > v.SizeGigabytes {
+		if err := c.volumeAction(ctx, volumeID, map[string]any{
+			"type":           "resize",
+			"size_gigabytes": size,
+			"region":         v.Region.Slug,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.GetVolume(ctx, volumeID)
+}
+
+// volumeAction starts a volume action and waits for it to complete
+func (c *Client) volumeAction(ctx context.Context, volumeID string, body map[string]any) error {
+	var resp struct {
+		Action action `json:"action"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/volumes/"+volumeID+"/actions", body, &resp); err != nil {
+		return errors.Wrapf(err, "failed to %s volume", body["type"])
+	}
+	return c.waitForAction(ctx, resp.Action.ID)
+}
+
+// getVolume fetches a volume by ID
+func (c *Client) getVolume(ctx context.Context, volumeID string) (*volume, error) {
+	var resp struct {
+		Volume volume `json:"volume"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/volumes/"+volumeID, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Volume, nil
+}
+
+// GetVolume retrieves volume information
+func (c *Client) GetVolume(ctx context.Context, volumeID string) (*provider.Volume, error) {
+	v, err := c.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get volume")
+	}
+	return convertVolume(v), nil
+}
+
+// ListVolumes lists all volumes of the account
+func (c *Client) ListVolumes(ctx context.Context) ([]*provider.Volume, error) {
+	var volumes []*provider.Volume
+
+	path := listPath("/volumes", nil)
+	for path != "" {
+		var resp struct {
+			Volumes []volume `json:"volumes"`
+			Links   links    `json:"links"`
+		}
+		if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, errors.Wrap(err, "failed to list volumes")
+		}
+		for i := range resp.Volumes {
+			volumes = append(volumes, convertVolume(&resp.Volumes[i]))
+		}
+		path = resp.Links.Pages.Next
+	}
+
+	return volumes, nil
+}
+
+// Ensure Client can grow volumes
+var _ provider.VolumeResizer = (*Client)(nil)
+
+// Ensure Client keeps named volumes across droplets
+var _ provider.NamedVolumeProvider = (*Client)(nil)
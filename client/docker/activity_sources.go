@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/activity"
+	"github.com/pkg/errors"
+)
+
+// registerActivitySources wires the built-in activity sources into the tracker
+func (d *DockBridgeDaemon) registerActivitySources() {
+	d.sessionSource = activity.NewSessionSource(activity.DefaultSourceInterval)
+
+	d.activityTracker.RegisterSource(activity.NewContainerSource(d.countRunningContainers, activity.DefaultSourceInterval))
+	d.activityTracker.RegisterSource(d.sessionSource)
+	d.activityTracker.RegisterSource(activity.NewTrafficSource(d.forwardedBytes, activity.DefaultSourceInterval))
+}
+
+// countRunningContainers counts running containers on the remote daemon. It only uses an
+// already established tunnel so that polling never provisions or reconnects a server.
+func (d *DockBridgeDaemon) countRunningContainers(ctx context.Context) (int, error) {
+	tunnel := d.clientManager.GetTunnel()
+	if tunnel == nil || !tunnel.IsActive() {
+		return 0, nil
+	}
+
+	httpClient := newTunnelHTTPClient(d.clientManager.DialDocker)
+	defer httpClient.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/json", nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create container list request")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list containers")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("container list returned status %d", resp.StatusCode)
+	}
+
+	var containers []struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return 0, errors.Wrap(err, "failed to decode container list")
+	}
+
+	return len(containers), nil
+}
+
+// forwardedBytes returns the total bytes carried by port forwards
+func (d *DockBridgeDaemon) forwardedBytes() int64 {
+	pfm := d.clientManager.GetPortForwardManager()
+	if pfm == nil {
+		return 0
+	}
+
+	forwards, err := pfm.ListPortForwards()
+	if err != nil {
+		return 0
+	}
+
+	var total int64
+	for _, forward := range forwards {
+		total += forward.BytesTransferred
+	}
+	return total
+}
+
+// isInteractiveRequest reports whether an HTTP request line opens an interactive
+// session (container attach, including its websocket variant, or exec start), which
+// keeps a TTY attached.
+func isInteractiveRequest(requestLine string) bool {
+	fields := strings.Fields(requestLine)
+	if len(fields) < 2 {
+		return false
+	}
+
+	path, _, _ := strings.Cut(fields[1], "?")
+	switch fields[0] {
+	case http.MethodPost:
+		return (strings.Contains(path, "/containers/") && strings.HasSuffix(path, "/attach")) ||
+			(strings.Contains(path, "/exec/") && strings.HasSuffix(path, "/start"))
+	case http.MethodGet:
+		return isWebSocketAttach(path)
+	default:
+		return false
+	}
+}
+
+// isWebSocketAttach reports whether path, with or without API version prefix, is
+// /containers/{id}/attach/ws. Its websocket upgrade and frames are relayed as they are,
+// like the hijacked connection of a regular attach.
+func isWebSocketAttach(path string) bool {
+	return strings.Contains(path, "/containers/") && strings.HasSuffix(path, "/attach/ws")
+}
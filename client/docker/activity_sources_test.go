@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsInteractiveRequest(t *testing.T) {
+	tests := map[string]bool{
+		"POST /v1.43/containers/abc/attach?stream=1&stdin=1 HTTP/1.1\r\n":   true,
+		"POST /v1.43/exec/abc123/start HTTP/1.1\r\n":                        true,
+		"POST /containers/abc/attach HTTP/1.1\r\n":                          true,
+		"GET /v1.43/containers/abc/attach/ws?stream=1&stdin=1 HTTP/1.1\r\n": true,
+		"POST /v1.43/containers/abc/attach/ws HTTP/1.1\r\n":                 false,
+		"GET /v1.43/containers/json HTTP/1.1\r\n":                           false,
+		"POST /v1.43/containers/abc/start HTTP/1.1\r\n":                     false,
+		"POST /v1.43/containers/abc/exec HTTP/1.1\r\n":                      false,
+		"": false,
+	}
+
+	for line, expected := range tests {
+		assert.Equal(t, expected, isInteractiveRequest(line), line)
+	}
+}
@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+)
+
+// provisioningRetryAfter is the Retry-After sent to Docker clients while a server is provisioned
+const provisioningRetryAfter = 10 * time.Second
+
+// dockerAPIError is the error body of the Docker Engine API; the Docker CLI prints its
+// message as "Error response from daemon: <message>"
+type dockerAPIError struct {
+	Message string `json:"message"`
+}
+
+// provisioningResponse is the body of the 503 answered while a server is provisioned: a
+// Docker API error extended with the progress
+type provisioningResponse struct {
+	Message string                     `json:"message"`
+	Phase   provider.ProvisioningPhase `json:"phase"`
+	Step    string                     `json:"step"`
+	Percent int                        `json:"percent"`
+	Elapsed string                     `json:"elapsed"`
+}
+
+// writeProvisioningResponse answers a Docker API request with 503, Retry-After and the progress
+func writeProvisioningResponse(w io.Writer, progress provisioningProgress) error {
+	header := http.Header{}
+	header.Set("Retry-After", strconv.Itoa(int(provisioningRetryAfter.Seconds())))
+
+	return writeJSONResponse(w, http.StatusServiceUnavailable, header, provisioningResponse{
+		Message: fmt.Sprintf("DockBridge is preparing the remote server: %s (%d%%), retry shortly", progress.Message, progress.Percent),
+		Phase:   progress.Phase,
+		Step:    progress.Message,
+		Percent: progress.Percent,
+		Elapsed: time.Since(progress.Started).Round(time.Second).String(),
+	})
+}
+
+// writeBusyResponse answers with 503 and Retry-After while another request connects to
+// the remote server and the request could not be queued
+func writeBusyResponse(w io.Writer) error {
+	header := http.Header{}
+	header.Set("Retry-After", strconv.Itoa(int(provisioningRetryAfter.Seconds())))
+	return writeJSONResponse(w, http.StatusServiceUnavailable, header, dockerAPIError{
+		Message: "DockBridge is still connecting to the remote server, retry shortly",
+	})
+}
+
+// writeDockerError answers a Docker API request with an error envelope
+func writeDockerError(w io.Writer, status int, message string) error {
+	return writeJSONResponse(w, status, nil, dockerAPIError{Message: message})
+}
+
+// writeJSONResponse writes an HTTP/1.1 response with a JSON body and asks the client to
+// close the connection, as the rest of the request is never read
+func writeJSONResponse(w io.Writer, status int, header http.Header, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	payload = append(payload, '\n')
+
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+
+	resp := &http.Response{
+		StatusCode:    status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		ContentLength: int64(len(payload)),
+		Body:          io.NopCloser(bytes.NewReader(payload)),
+		Close:         true,
+	}
+	return resp.Write(w)
+}
+
+// connectionErrorMessage describes a failed connection attempt; before is the state
+// the daemon was in when the attempt started
+func connectionErrorMessage(before connectionState, err error) string {
+	if before == stateReady {
+		return fmt.Sprintf("DockBridge lost the connection to the remote server and could not reconnect: %v", err)
+	}
+	return fmt.Sprintf("DockBridge could not provision or connect to a remote server: %v", err)
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readJSONResponse parses a response written to buf and decodes its JSON body
+func readJSONResponse(t *testing.T, buf *bytes.Buffer) (*http.Response, map[string]any) {
+	t.Helper()
+
+	resp, err := http.ReadResponse(bufio.NewReader(buf), nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.True(t, resp.Close)
+	return resp, body
+}
+
+func TestWriteProvisioningResponse(t *testing.T) {
+	var buf bytes.Buffer
+	progress := provisioningProgress{
+		Phase:   provider.PhaseWaitingForCloudInit,
+		Message: "Waiting for cloud-init",
+		Percent: 50,
+		Started: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, writeProvisioningResponse(&buf, progress))
+
+	resp, body := readJSONResponse(t, &buf)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "10", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "waiting_for_cloud_init", body["phase"])
+	assert.Equal(t, float64(50), body["percent"])
+	assert.Contains(t, body["message"], "Waiting for cloud-init (50%)")
+	assert.Equal(t, "Waiting for cloud-init", body["step"])
+	assert.Equal(t, "1m0s", body["elapsed"])
+}
+
+func TestWriteDockerError(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeDockerError(&buf, http.StatusBadGateway, "DockBridge could not reach the remote Docker daemon"))
+
+	resp, body := readJSONResponse(t, &buf)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, map[string]any{"message": "DockBridge could not reach the remote Docker daemon"}, body)
+}
+
+func TestConnectionErrorMessage(t *testing.T) {
+	err := errors.New("ssh: connection refused")
+	assert.Contains(t, connectionErrorMessage(stateReady, err), "lost the connection")
+	assert.Contains(t, connectionErrorMessage(stateIdle, err), "could not provision or connect")
+	assert.Contains(t, connectionErrorMessage(stateFailed, err), "ssh: connection refused")
+}
@@ -0,0 +1,441 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// minSyncedFileSize is the size from which files of a build context are synced by
+// content; smaller files cost less to send than to look up
+const minSyncedFileSize = 4 << 10
+
+// buildSyncScript runs on the server for a synced build. It reads from stdin the
+// digests of the context's files and answers with those missing from its store, then
+// receives the missing files, the head of the build request and the context as a list
+// of raw byte ranges and stored files. It sends the request with the reassembled
+// context to the Docker socket and relays the response to stdout. Failures before the
+// response are reported as a single "error: " line.
+const buildSyncScript = `
+import hashlib, os, socket, sys, time
+
+STORE = "/var/cache/dockbridge/build-context"
+MAX_AGE = 7 * 86400
+inp, out = sys.stdin.buffer, sys.stdout.buffer
+
+def readline():
+    line = inp.readline()
+    if not line:
+        raise EOFError("unexpected end of input")
+    return line
+
+def lines(end):
+    while True:
+        line = readline()
+        if line == end:
+            return
+        yield line
+
+def copy(src, write, n):
+    while n > 0:
+        chunk = src.read(min(n, 1 << 20))
+        if not chunk:
+            raise EOFError("unexpected end of input")
+        write(chunk)
+        n -= len(chunk)
+
+def prune():
+    now = time.time()
+    for name in os.listdir(STORE):
+        path = os.path.join(STORE, name)
+        try:
+            if now - os.stat(path).st_mtime > MAX_AGE:
+                os.remove(path)
+        except OSError:
+            pass
+
+def receive(digest, size):
+    tmp = os.path.join(STORE, ".upload-%d" % os.getpid())
+    hasher = hashlib.sha256()
+    with open(tmp, "wb") as f:
+        def write(chunk):
+            hasher.update(chunk)
+            f.write(chunk)
+        copy(inp, write, size)
+    if hasher.hexdigest() != digest:
+        os.remove(tmp)
+        raise ValueError("corrupt upload of " + digest)
+    os.replace(tmp, os.path.join(STORE, digest))
+
+def main():
+    os.makedirs(STORE, exist_ok=True)
+    prune()
+
+    missing = []
+    for line in lines(b"\n"):
+        digest = line.strip().decode()
+        path = os.path.join(STORE, digest)
+        if os.path.exists(path):
+            os.utime(path)
+        else:
+            missing.append(digest)
+    out.write("".join(digest + "\n" for digest in missing).encode() + b"\n")
+    out.flush()
+
+    for line in lines(b"\n"):
+        digest, size = line.split()
+        receive(digest.decode(), int(size))
+
+    head = inp.read(int(readline()))
+    conn = socket.socket(socket.AF_UNIX)
+    conn.connect("/var/run/docker.sock")
+    try:
+        conn.sendall(head)
+        for line in lines(b"e\n"):
+            fields = line.split()
+            if fields[0] == b"r":
+                copy(inp, conn.sendall, int(fields[1]))
+            else:
+                with open(os.path.join(STORE, fields[1].decode()), "rb") as f:
+                    copy(f, conn.sendall, int(fields[2]))
+    except OSError:
+        # The daemon answered early, e.g. with an error; relay its response
+        pass
+    return conn
+
+try:
+    conn = main()
+except Exception as e:
+    out.write(("error: %s\n" % e).encode())
+    out.flush()
+    sys.exit(1)
+
+while True:
+    chunk = conn.recv(1 << 16)
+    if not chunk:
+        break
+    out.write(chunk)
+    out.flush()
+`
+
+// buildSyncCommand runs buildSyncScript; the script contains no single quotes
+const buildSyncCommand = "python3 -c '" + buildSyncScript + "'"
+
+// buildSegment is a byte range of a build context; ranges with a digest are regular
+// files sent by content
+type buildSegment struct {
+	offset int64
+	length int64
+	digest string
+}
+
+// isBuildSyncTarget reports whether a request is a build whose context is sent in the
+// request body. BuildKit builds (version=2) and builds of remote contexts are not.
+func isBuildSyncTarget(method, target string) bool {
+	if method != http.MethodPost || interceptPath(target) != "/build" {
+		return false
+	}
+	_, rawQuery, _ := strings.Cut(target, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return false
+	}
+	return query.Get("version") != "2" && query.Get("remote") == ""
+}
+
+// buildContextSyncEnabled reports whether build contexts are synced incrementally
+func (d *DockBridgeDaemon) buildContextSyncEnabled() bool {
+	return d.config != nil && d.config.BuildContextSync
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// planBuildContext splits a build context into raw byte ranges and the regular files
+// worth syncing by content. A context that is not a plain tar archive, e.g. one
+// compressed by "docker build --compress", is a single raw range.
+func planBuildContext(buildContext io.ReaderAt, size int64) []buildSegment {
+	whole := []buildSegment{{offset: 0, length: size}}
+
+	counter := &countingReader{r: io.NewSectionReader(buildContext, 0, size)}
+	tr := tar.NewReader(counter)
+	var segments []buildSegment
+	var last int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return whole
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size < minSyncedFileSize || isSparse(hdr) {
+			continue
+		}
+
+		offset := counter.n
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, tr); err != nil || counter.n != offset+hdr.Size {
+			return whole
+		}
+		if offset > last {
+			segments = append(segments, buildSegment{offset: last, length: offset - last})
+		}
+		segments = append(segments, buildSegment{offset: offset, length: hdr.Size, digest: hex.EncodeToString(hasher.Sum(nil))})
+		last = offset + hdr.Size
+	}
+	if last < size {
+		segments = append(segments, buildSegment{offset: last, length: size - last})
+	}
+	return segments
+}
+
+// isSparse reports whether a tar entry is a PAX sparse file, whose data is shorter than
+// its size
+func isSparse(hdr *tar.Header) bool {
+	for key := range hdr.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRequestHead returns the head of a build request with a body of size bytes. The
+// connection is closed after the response, as the body is no longer chunked.
+func buildRequestHead(req *http.Request, size int64) []byte {
+	header := req.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Del("Content-Length")
+	header.Set("Connection", "close")
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "%s %s HTTP/1.1\r\nHost: docker\r\nContent-Length: %d\r\n", req.Method, req.URL.RequestURI(), size)
+	_ = header.Write(&head)
+	head.WriteString("\r\n")
+	return head.Bytes()
+}
+
+// serveBuildSync serves a build request by syncing its context to the server's store of
+// build context files, so files sent by earlier builds are not sent again, then runs
+// the build there with the reassembled context. Without the helper on the server, the
+// build is forwarded as is.
+func (d *DockBridgeDaemon) serveBuildSync(ctx context.Context, localConn net.Conn, reader io.Reader, connID string) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to parse build request")
+		return
+	}
+
+	// The context is spooled to disk; it is read several times and may be large
+	buildContext, err := os.CreateTemp("", "dockbridge-build-*.tar")
+	if err != nil {
+		_ = writeDockerError(localConn, http.StatusInternalServerError, fmt.Sprintf("DockBridge could not spool the build context: %v", err))
+		return
+	}
+	defer func() {
+		buildContext.Close()
+		os.Remove(buildContext.Name())
+	}()
+	size, err := io.Copy(buildContext, req.Body)
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to read build context")
+		return
+	}
+
+	before := d.connState.current().state
+	if err := d.ensureConnection(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to ensure connection to remote server")
+		d.writeConnectionError(localConn, connID, before, err)
+		return
+	}
+
+	head := buildRequestHead(req, size)
+	segments := planBuildContext(buildContext, size)
+
+	stream, err := d.clientManager.OpenRemoteStream(ctx, buildSyncCommand)
+	if err == nil {
+		defer stream.Close()
+		var uploaded int64
+		uploaded, err = syncBuildContext(stream, buildContext, segments, head, localConn)
+		if err == nil {
+			d.metrics.RequestProxied("remote")
+			d.logger.WithFields(map[string]any{
+				"conn_id":        connID,
+				"context_bytes":  size,
+				"uploaded_bytes": uploaded,
+			}).Info("Build context synced")
+			return
+		}
+		if !errors.Is(err, errBuildSyncUnavailable) {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"error":   err.Error(),
+			}).Error("❌ Synced build failed")
+			_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not run the build on the remote server: %v", err))
+			return
+		}
+	}
+
+	d.logger.WithFields(map[string]any{
+		"conn_id": connID,
+		"error":   err.Error(),
+	}).Warn("Build context sync unavailable, sending the whole context")
+	if err := d.forwardBuild(ctx, localConn, io.NewSectionReader(buildContext, 0, size), head); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to forward build request")
+		_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+	}
+}
+
+// errBuildSyncUnavailable reports that the server cannot sync build contexts, e.g.
+// because python3 is missing; nothing has reached the Docker daemon yet
+var errBuildSyncUnavailable = errors.New("build context sync unavailable")
+
+// syncBuildContext runs the buildSyncScript protocol over stream and relays the build
+// response to out. It returns the number of context bytes sent.
+func syncBuildContext(stream io.ReadWriter, buildContext io.ReaderAt, segments []buildSegment, head []byte, out io.Writer) (int64, error) {
+	files := make(map[string]buildSegment)
+	var digests []string
+	for _, segment := range segments {
+		if segment.digest == "" {
+			continue
+		}
+		if _, ok := files[segment.digest]; !ok {
+			files[segment.digest] = segment
+			digests = append(digests, segment.digest)
+		}
+	}
+
+	// Ask which files the server is missing
+	var query bytes.Buffer
+	for _, digest := range digests {
+		query.WriteString(digest + "\n")
+	}
+	query.WriteString("\n")
+	if _, err := stream.Write(query.Bytes()); err != nil {
+		return 0, errors.Wrap(errBuildSyncUnavailable, err.Error())
+	}
+
+	response := bufio.NewReader(stream)
+	var missing []string
+	for {
+		line, err := response.ReadString('\n')
+		if err != nil {
+			return 0, errors.Wrap(errBuildSyncUnavailable, "the server did not answer, is python3 installed?")
+		}
+		if message, ok := strings.CutPrefix(line, "error: "); ok {
+			return 0, errors.Wrap(errBuildSyncUnavailable, strings.TrimSpace(message))
+		}
+		digest := strings.TrimSpace(line)
+		if digest == "" {
+			break
+		}
+		if _, ok := files[digest]; !ok {
+			return 0, errors.Wrapf(errBuildSyncUnavailable, "unexpected answer %q", digest)
+		}
+		missing = append(missing, digest)
+	}
+
+	// Send the missing files and the build while the response is relayed; the daemon
+	// may answer before it has read the whole context
+	sent := make(chan error, 1)
+	var uploaded int64
+	go func() {
+		sent <- func() error {
+			w := bufio.NewWriterSize(stream, 1<<16)
+			for _, digest := range missing {
+				file := files[digest]
+				fmt.Fprintf(w, "%s %d\n", digest, file.length)
+				if _, err := io.Copy(w, io.NewSectionReader(buildContext, file.offset, file.length)); err != nil {
+					return err
+				}
+				uploaded += file.length
+			}
+			fmt.Fprintf(w, "\n%d\n", len(head))
+			w.Write(head)
+			for _, segment := range segments {
+				if segment.digest != "" {
+					fmt.Fprintf(w, "f %s %d\n", segment.digest, segment.length)
+					continue
+				}
+				fmt.Fprintf(w, "r %d\n", segment.length)
+				if _, err := io.Copy(w, io.NewSectionReader(buildContext, segment.offset, segment.length)); err != nil {
+					return err
+				}
+				uploaded += segment.length
+			}
+			w.WriteString("e\n")
+			return w.Flush()
+		}()
+	}()
+
+	// The response is an HTTP response, or an error line if the helper failed
+	prefix, err := response.Peek(len("HTTP/"))
+	if err != nil || string(prefix) != "HTTP/" {
+		line, _ := response.ReadString('\n')
+		if message, ok := strings.CutPrefix(line, "error: "); ok {
+			return 0, errors.New(strings.TrimSpace(message))
+		}
+		if sendErr := <-sent; sendErr != nil {
+			return 0, errors.Wrap(sendErr, "failed to send the build context")
+		}
+		return 0, errors.New("the server closed the build without a response")
+	}
+	if _, err := io.Copy(out, response); err != nil {
+		return 0, errors.Wrap(err, "failed to relay the build response")
+	}
+	// The helper may have stopped reading early, after an error response
+	<-sent
+	return uploaded, nil
+}
+
+// forwardBuild sends the build request with the whole context to the remote daemon
+// and relays the response
+func (d *DockBridgeDaemon) forwardBuild(ctx context.Context, localConn net.Conn, buildContext io.Reader, head []byte) error {
+	remoteConn, err := d.clientManager.DialDocker(ctx)
+	if err != nil {
+		return err
+	}
+	defer remoteConn.Close()
+
+	go func() {
+		_, _ = remoteConn.Write(head)
+		_, _ = io.Copy(remoteConn, buildContext)
+	}()
+	d.metrics.RequestProxied("remote")
+	_, err = io.Copy(localConn, remoteConn)
+	return err
+}
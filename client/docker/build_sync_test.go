@@ -0,0 +1,180 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildContextTar returns a build context with the given files
+func buildContextTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"Dockerfile", "small.txt", "large.bin", "copy.bin"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestIsBuildSyncTarget(t *testing.T) {
+	assert.True(t, isBuildSyncTarget("POST", "/v1.43/build?t=app&dockerfile=Dockerfile"))
+	assert.True(t, isBuildSyncTarget("POST", "/build"))
+	assert.False(t, isBuildSyncTarget("POST", "/v1.43/build?version=2&session=abc"), "BuildKit builds send no context")
+	assert.False(t, isBuildSyncTarget("POST", "/build?remote=https://github.com/org/repo.git"))
+	assert.False(t, isBuildSyncTarget("POST", "/build/prune"))
+	assert.False(t, isBuildSyncTarget("GET", "/build"))
+}
+
+func TestPlanBuildContext(t *testing.T) {
+	large := strings.Repeat("x", minSyncedFileSize+100)
+	context := buildContextTar(t, map[string]string{
+		"Dockerfile": "FROM scratch\n",
+		"large.bin":  large,
+		"copy.bin":   large,
+	})
+
+	segments := planBuildContext(bytes.NewReader(context), int64(len(context)))
+
+	var total int64
+	var files []buildSegment
+	for _, segment := range segments {
+		assert.Equal(t, total, segment.offset, "segments are contiguous")
+		total += segment.length
+		if segment.digest != "" {
+			files = append(files, segment)
+		}
+	}
+	assert.Equal(t, int64(len(context)), total)
+	require.Len(t, files, 2, "only large files are synced by content")
+	assert.Equal(t, files[0].digest, files[1].digest)
+	assert.Equal(t, large, string(context[files[0].offset:files[0].offset+files[0].length]))
+}
+
+func TestPlanBuildContextNotTar(t *testing.T) {
+	compressed := []byte("\x1f\x8b\x08\x00 not a tar archive")
+	assert.Equal(t, []buildSegment{{offset: 0, length: int64(len(compressed))}}, planBuildContext(bytes.NewReader(compressed), int64(len(compressed))))
+}
+
+func TestBuildRequestHead(t *testing.T) {
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader("POST /v1.43/build?t=app HTTP/1.1\r\nHost: api.moby.localhost\r\nTransfer-Encoding: chunked\r\nContent-Type: application/x-tar\r\nX-Registry-Config: e30=\r\n\r\n0\r\n\r\n")))
+	require.NoError(t, err)
+
+	head := string(buildRequestHead(req, 2048))
+	assert.True(t, strings.HasPrefix(head, "POST /v1.43/build?t=app HTTP/1.1\r\n"))
+	assert.Contains(t, head, "Content-Length: 2048\r\n")
+	assert.Contains(t, head, "Connection: close\r\n")
+	assert.Contains(t, head, "X-Registry-Config: e30=\r\n")
+	assert.NotContains(t, head, "chunked")
+	assert.True(t, strings.HasSuffix(head, "\r\n\r\n"))
+}
+
+// pipeStream joins a command's stdout and stdin
+type pipeStream struct {
+	io.Reader
+	io.Writer
+}
+
+func TestSyncBuildContext(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	assert.NotContains(t, buildSyncScript, "'", "the script is passed in single quotes")
+
+	// Unix socket paths are limited in length
+	dir, err := os.MkdirTemp("", "dbsync")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	store := filepath.Join(dir, "store")
+	socket := filepath.Join(dir, "docker.sock")
+
+	// A Docker daemon answering builds with the context it received
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			req, err := http.ReadRequest(bufio.NewReader(conn))
+			if err == nil {
+				body, _ := io.ReadAll(req.Body)
+				resp := &http.Response{StatusCode: http.StatusOK, ProtoMajor: 1, ProtoMinor: 1, ContentLength: int64(len(body)), Body: io.NopCloser(bytes.NewReader(body))}
+				_ = resp.Write(conn)
+			}
+			conn.Close()
+		}
+	}()
+
+	script := strings.NewReplacer("/var/cache/dockbridge/build-context", store, "/var/run/docker.sock", socket).Replace(buildSyncScript)
+	build := func(context []byte) (int64, []byte) {
+		cmd := exec.Command(python, "-c", script)
+		stdin, err := cmd.StdinPipe()
+		require.NoError(t, err)
+		stdout, err := cmd.StdoutPipe()
+		require.NoError(t, err)
+		require.NoError(t, cmd.Start())
+		defer cmd.Wait()
+		defer stdin.Close()
+
+		req, err := http.NewRequest("POST", "/v1.43/build?t=app", nil)
+		require.NoError(t, err)
+		head := buildRequestHead(req, int64(len(context)))
+
+		var out bytes.Buffer
+		uploaded, err := syncBuildContext(pipeStream{stdout, stdin}, bytes.NewReader(context), planBuildContext(bytes.NewReader(context), int64(len(context))), head, &out)
+		require.NoError(t, err)
+
+		resp, err := http.ReadResponse(bufio.NewReader(&out), nil)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return uploaded, body
+	}
+
+	large := strings.Repeat("0123456789", 10000)
+	context := buildContextTar(t, map[string]string{"Dockerfile": "FROM scratch\n", "large.bin": large, "copy.bin": large})
+	uploaded, received := build(context)
+	assert.Equal(t, context, received, "the daemon receives the context byte for byte")
+	assert.Less(t, uploaded, int64(len(context)), "duplicate files are sent once")
+	assert.Greater(t, uploaded, int64(len(large)))
+
+	changed := buildContextTar(t, map[string]string{"Dockerfile": "FROM scratch\nCOPY . /\n", "large.bin": large, "copy.bin": large})
+	uploaded, received = build(changed)
+	assert.Equal(t, changed, received)
+	assert.Less(t, uploaded, int64(len(large)), "files sent before are not sent again")
+}
+
+func TestSyncBuildContextUnavailable(t *testing.T) {
+	context := buildContextTar(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+	segments := planBuildContext(bytes.NewReader(context), int64(len(context)))
+
+	// python3 missing: the command exits without answering
+	_, err := syncBuildContext(pipeStream{strings.NewReader(""), io.Discard}, bytes.NewReader(context), segments, nil, io.Discard)
+	assert.ErrorIs(t, err, errBuildSyncUnavailable)
+
+	_, err = syncBuildContext(pipeStream{strings.NewReader("error: read-only file system\n"), io.Discard}, bytes.NewReader(context), segments, nil, io.Discard)
+	assert.ErrorIs(t, err, errBuildSyncUnavailable)
+	assert.ErrorContains(t, err, "read-only file system")
+}
@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// channelPoolConfig returns how Docker API connections are spread over SSH connections
+func (dcm *dockerClientManagerImpl) channelPoolConfig() ssh.ChannelPoolConfig {
+	pool := dcm.sshConfig.ChannelPool
+	return ssh.ChannelPoolConfig{
+		Connections:    pool.Connections,
+		MaxChannels:    pool.MaxChannels,
+		AcquireTimeout: pool.AcquireTimeout,
+		OnBackpressure: dcm.logChannelBackpressure,
+	}
+}
+
+// logChannelBackpressure warns when a Docker API connection had to wait for a free
+// SSH channel, which means the channel pool is too small for the workload
+func (dcm *dockerClientManagerImpl) logChannelBackpressure(stats ssh.ChannelPoolStats, waited time.Duration) {
+	dcm.logger.WithFields(map[string]any{
+		"waited":         waited.String(),
+		"connections":    stats.Connections,
+		"open_channels":  stats.OpenChannels,
+		"max_channels":   stats.MaxChannels,
+		"per_connection": stats.PerConnection,
+		"waits":          stats.Waited,
+		"rejected":       stats.Rejected,
+	}).Warn("All SSH channels busy, Docker connection waited for a free channel")
+}
@@ -2,23 +2,36 @@ package docker
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
+	"strconv"
+	"sync/atomic"
 	"time"
 
-	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/activity"
+	"github.com/dockbridge/dockbridge/client/backup"
+	"github.com/dockbridge/dockbridge/client/daemonstate"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/metrics"
 	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/osupdates"
 	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/provisioning"
+	"github.com/dockbridge/dockbridge/client/provisionlock"
+	"github.com/dockbridge/dockbridge/client/readiness"
 	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/docker/docker/client"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DockerContainerCreateResponse represents a Docker container creation response
@@ -55,42 +68,188 @@ type DockerClientManager interface {
 	// Tunnel access for direct socket forwarding
 	GetTunnel() ssh.TunnelInterface
 
+	// RevalidateConnection actively probes the SSH connection and reconnects if it is dead
+	RevalidateConnection(ctx context.Context) error
+
+	// Disconnect drops the current connection, e.g. after the server was destroyed or
+	// powered off for inactivity; the next request reconnects
+	Disconnect()
+
+	// CurrentServer returns the server the manager is connected to, or nil
+	CurrentServer() *provider.Server
+
+	// ExecuteRemoteCommand runs a command over the existing SSH connection without connecting or provisioning
+	ExecuteRemoteCommand(ctx context.Context, command string) ([]byte, error)
+
+	// ExecuteRemoteCommandWithInput runs a command over the existing SSH connection with
+	// input as its stdin
+	ExecuteRemoteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error)
+
+	// OpenRemoteStream starts a command over the existing SSH connection and returns its
+	// stdin and stdout; closing the stream ends the command
+	OpenRemoteStream(ctx context.Context, command string) (io.ReadWriteCloser, error)
+
 	// Port forwarding integration
+	SetPortForwardConfig(cfg *config.PortForwardConfig)
 	RegisterContainerEventHandler(handler monitor.ContainerEventHandler) error
 	StartPortForwarding(ctx context.Context) error
 	StopPortForwarding() error
 	GetPortForwardManager() portforward.PortForwardManager
 
-	// Docker API response interception
-	InterceptDockerResponse(response []byte) ([]byte, error)
+	// InterceptDockerResponse rewrites published ports in container create, inspect and
+	// list responses to the local ports they are forwarded on
+	InterceptDockerResponse(method, path string, requestBody, response []byte) ([]byte, error)
+
+	// SetHooks sets the publisher for server, container and forward hook events
+	SetHooks(publisher hooks.Publisher)
+
+	// SetDockerTLS configures mutual TLS with the remote Docker daemon; nil disables it
+	SetDockerTLS(cfg *config.DockerTLSConfig)
+
+	// DialDocker opens a connection to the remote Docker API, using mutual TLS when available
+	DialDocker(ctx context.Context) (net.Conn, error)
+
+	// SetRemoteTransport selects how the remote Docker API is reached: "tcp" or "unix"
+	SetRemoteTransport(transport string)
+
+	// SetKeepAliveTransport selects how heartbeats reach new servers: "http" or "ssh"
+	SetKeepAliveTransport(transport string)
+
+	// SetServerBinary selects how new servers get dockbridge-server; nil leaves it to
+	// their setup
+	SetServerBinary(cfg *config.ServerBinaryConfig)
+
+	// SetBackup configures scheduled volume backups on new servers; nil disables them
+	SetBackup(cfg *config.BackupConfig)
+
+	// SetProvisioning configures user-supplied steps and files merged into the setup
+	// of new servers; nil disables them
+	SetProvisioning(cfg *config.ProvisioningConfig)
+
+	// SetRegistryAuth selects the registries whose credentials are forwarded to the
+	// server on every connection
+	SetRegistryAuth(entries []config.RegistryAuthConfig)
+
+	// SetRegistryMirror configures the pull-through registry cache run on new servers
+	SetRegistryMirror(cfg *config.RegistryMirrorConfig)
+
+	// DialRemote opens a connection to addr as seen from the connected server, through
+	// the SSH connection
+	DialRemote(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// SetServerReplacement decides whether a running server of a different type than
+	// requested is replaced (its volume is preserved); nil keeps the running server
+	SetServerReplacement(confirm ServerReplaceFunc)
+
+	// SetReadinessProgress sets a callback receiving per-phase progress while a new or
+	// resumed server becomes ready
+	SetReadinessProgress(progress readiness.ProgressFunc)
+
+	// SetProvisioningObserver sets the observer notified of the phases of provisioning
+	// or resuming a server, ending with PhaseReady or PhaseFailed
+	SetProvisioningObserver(observer provider.ProvisioningObserver)
+
+	// SetMetrics sets the recorder counting SSH reconnects; nil records nothing
+	SetMetrics(recorder *metrics.Recorder)
+
+	// SetProvisionGuard sets the check run before a new server is provisioned, such as
+	// the monthly budget; nil allows all. Resuming an existing server is not checked.
+	SetProvisionGuard(guard ProvisionGuardFunc)
+
+	// SetStateStore sets the store the connected server and its forwards are saved in,
+	// so that a restarted daemon resumes them; nil disables saving
+	SetStateStore(store *daemonstate.Store)
 }
 
 // dockerClientManagerImpl implements DockerClientManager
 type dockerClientManagerImpl struct {
-	hetznerClient hetzner.HetznerClient
+	cloudProvider provider.CloudProvider
 	sshConfig     *config.SSHConfig
 	hetznerConfig *config.HetznerConfig
 	logger        logger.LoggerInterface
 
+	// contextName scopes server selection to one DockBridge context ("" is the default)
+	contextName string
+
 	// Current connection state
-	currentServer *hetzner.Server
+	currentServer *provider.Server
 	sshClient     ssh.Client
 	tunnel        ssh.TunnelInterface
 	dockerClient  *client.Client
 
+	// reconnect re-establishes the SSH connection and tunnel when keep-alive probes find
+	// them dead (optional)
+	reconnect ssh.ReconnectManager
+
 	// Port forwarding components
 	containerMonitor   monitor.ContainerMonitor
 	portForwardManager portforward.PortForwardManager
 	portForwardConfig  *config.PortForwardConfig
 
 	// Activity tracking (optional)
-	activityTracker any
+	activityTracker activity.Recorder
+
+	// Lifecycle hooks (optional)
+	hooks hooks.Publisher
+
+	// Mutual TLS with the remote Docker daemon (optional); tlsConfig is set for the
+	// current server when it has certificates
+	dockerTLS *config.DockerTLSConfig
+	tlsConfig *tls.Config
+
+	// remoteTransport is "tcp" (default) or "unix"
+	remoteTransport string
+
+	// keepAliveTransport is "http" (default) or "ssh"
+	keepAliveTransport string
+
+	// serverBinary selects how new servers get dockbridge-server (optional)
+	serverBinary *config.ServerBinaryConfig
+
+	// backup configures scheduled volume backups on new servers; nil disables them
+	backup *config.BackupConfig
+
+	// provisioningSteps holds user-supplied steps and files for new servers (optional)
+	provisioningSteps *config.ProvisioningConfig
+
+	// registryAuth selects the registries whose credentials are forwarded (optional)
+	registryAuth []config.RegistryAuthConfig
+
+	// registryMirror configures the pull-through registry cache (optional)
+	registryMirror *config.RegistryMirrorConfig
+
+	// confirmReplace decides whether a server of the wrong type is replaced (optional)
+	confirmReplace ServerReplaceFunc
+
+	// readinessProgress receives per-phase progress while a server becomes ready (optional)
+	readinessProgress readiness.ProgressFunc
+
+	// provisioningObserver receives provisioning phases (optional); provisioning is set
+	// while a provisioning or resume is in progress
+	provisioningObserver provider.ProvisioningObserver
+	provisioning         bool
+
+	// metrics counts SSH reconnects (optional)
+	metrics *metrics.Recorder
+
+	// provisionGuard may refuse to provision a new server (optional)
+	provisionGuard ProvisionGuardFunc
+
+	// goldenBuilding is set while a golden image is being created from a new server
+	goldenBuilding atomic.Bool
+
+	// stateStore saves the connected server and its forwards across restarts (optional)
+	stateStore *daemonstate.Store
+
+	// owner labels new servers; only servers it owns are used or cleaned up. The zero
+	// value stands for provider.LocalOwner.
+	owner provider.Owner
 }
 
 // NewDockerClientManager creates a new Docker client manager
-func NewDockerClientManager(hetznerClient hetzner.HetznerClient, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, logger logger.LoggerInterface) DockerClientManager {
+func NewDockerClientManager(cloudProvider provider.CloudProvider, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, logger logger.LoggerInterface) DockerClientManager {
 	return &dockerClientManagerImpl{
-		hetznerClient: hetznerClient,
+		cloudProvider: cloudProvider,
 		sshConfig:     sshConfig,
 		hetznerConfig: hetznerConfig,
 		logger:        logger,
@@ -98,9 +257,9 @@ func NewDockerClientManager(hetznerClient hetzner.HetznerClient, sshConfig *conf
 }
 
 // NewDockerClientManagerWithPortForwarding creates a new Docker client manager with port forwarding support
-func NewDockerClientManagerWithPortForwarding(hetznerClient hetzner.HetznerClient, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, portForwardConfig *config.PortForwardConfig, logger logger.LoggerInterface) DockerClientManager {
+func NewDockerClientManagerWithPortForwarding(cloudProvider provider.CloudProvider, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, portForwardConfig *config.PortForwardConfig, logger logger.LoggerInterface) DockerClientManager {
 	return &dockerClientManagerImpl{
-		hetznerClient:     hetznerClient,
+		cloudProvider:     cloudProvider,
 		sshConfig:         sshConfig,
 		hetznerConfig:     hetznerConfig,
 		portForwardConfig: portForwardConfig,
@@ -109,9 +268,16 @@ func NewDockerClientManagerWithPortForwarding(hetznerClient hetzner.HetznerClien
 }
 
 // NewDockerClientManagerWithActivity creates a new Docker client manager with activity tracking support
-func NewDockerClientManagerWithActivity(hetznerClient hetzner.HetznerClient, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, logger logger.LoggerInterface, activityTracker any) DockerClientManager {
+func NewDockerClientManagerWithActivity(cloudProvider provider.CloudProvider, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, logger logger.LoggerInterface, activityTracker activity.Recorder) DockerClientManager {
+	return NewDockerClientManagerForContext("", cloudProvider, sshConfig, hetznerConfig, logger, activityTracker)
+}
+
+// NewDockerClientManagerForContext creates a Docker client manager that only uses servers
+// belonging to the named context
+func NewDockerClientManagerForContext(contextName string, cloudProvider provider.CloudProvider, sshConfig *config.SSHConfig, hetznerConfig *config.HetznerConfig, logger logger.LoggerInterface, activityTracker activity.Recorder) DockerClientManager {
 	return &dockerClientManagerImpl{
-		hetznerClient:   hetznerClient,
+		contextName:     contextName,
+		cloudProvider:   cloudProvider,
 		sshConfig:       sshConfig,
 		hetznerConfig:   hetznerConfig,
 		logger:          logger,
@@ -136,6 +302,8 @@ func (dcm *dockerClientManagerImpl) GetClient(ctx context.Context) (*client.Clie
 		return nil, errors.New("no SSH tunnel available")
 	}
 
+	// With mTLS the transport performs the handshake over the tunnel connection
+	// and the Docker client switches to https
 	dockerClient, err := client.NewClientWithOpts(
 		client.WithHost(fmt.Sprintf("tcp://%s", dcm.tunnel.LocalAddr())),
 		client.WithAPIVersionNegotiation(),
@@ -149,6 +317,7 @@ func (dcm *dockerClientManagerImpl) GetClient(ctx context.Context) (*client.Clie
 					}
 					return dialer.DialContext(ctx, "tcp", dcm.tunnel.LocalAddr())
 				},
+				TLSClientConfig:       dcm.tlsConfig,
 				MaxIdleConns:          10,
 				IdleConnTimeout:       60 * time.Second,
 				TLSHandshakeTimeout:   10 * time.Second,
@@ -165,49 +334,79 @@ func (dcm *dockerClientManagerImpl) GetClient(ctx context.Context) (*client.Clie
 	dcm.logger.WithFields(map[string]any{
 		"tunnel_addr": dcm.tunnel.LocalAddr(),
 		"server_ip":   dcm.currentServer.IPAddress,
+		"mtls":        dcm.tlsConfig != nil,
 	}).Info("Docker client created successfully")
 
 	return dcm.dockerClient, nil
 }
 
 // EnsureConnection ensures we have an active connection to a remote server
-func (dcm *dockerClientManagerImpl) EnsureConnection(ctx context.Context) error {
+func (dcm *dockerClientManagerImpl) EnsureConnection(ctx context.Context) (err error) {
 	// Check if we already have an active connection
 	if dcm.isConnectionHealthy() {
 		dcm.logger.Debug("Using existing connection")
 		return nil
 	}
 
+	// Report the outcome of a provisioning or resume started below
+	defer func() {
+		if !dcm.provisioning {
+			return
+		}
+		if err != nil {
+			dcm.reportPhase(provider.PhaseFailed, err.Error(), 100)
+		} else {
+			dcm.reportPhase(provider.PhaseReady, "Remote Docker ready", 100)
+		}
+	}()
+
 	dcm.logger.Info("Establishing connection to remote server")
 
+	ctx, span := telemetry.Start(ctx, "dockbridge.connect", attribute.String("dockbridge.context", dcm.contextName))
+	defer func() { telemetry.End(span, err) }()
+
 	// Clean up any existing connection
 	dcm.cleanup()
 
 	// Get or provision a server
-	server, err := dcm.getOrProvisionServer(ctx)
+	serverCtx, serverSpan := telemetry.Start(ctx, "dockbridge.server.acquire")
+	server, err := dcm.getOrProvisionServer(serverCtx)
+	telemetry.End(serverSpan, err)
 	if err != nil {
 		return errors.Wrap(err, "failed to get or provision server")
 	}
+	span.SetAttributes(attribute.String("dockbridge.server", server.Name))
 
 	dcm.currentServer = server
+	if dcm.provisioning {
+		dcm.reportPhase(provider.PhaseConnecting, "Connecting to Docker", 95)
+	}
 
 	// Create SSH client
 	sshKeyPath := expandPath(dcm.sshConfig.KeyPath)
 	sshConfig := &ssh.ClientConfig{
-		Host:           server.IPAddress,
-		Port:           dcm.sshConfig.Port,
-		User:           "root",
-		PrivateKeyPath: sshKeyPath,
-		Timeout:        60 * time.Second,
+		Host:            server.IPAddress,
+		Port:            dcm.sshConfig.Port,
+		User:            "root",
+		PrivateKeyPath:  sshKeyPath,
+		Timeout:         60 * time.Second,
+		UseAgent:        dcm.sshConfig.UseAgent,
+		AgentSocket:     dcm.sshConfig.AgentSocket,
+		KnownHostsPath:  expandPath(dcm.sshConfig.KnownHostsPath),
+		HostKeyChecking: dcm.sshConfig.HostKeyChecking,
+		Channels:        dcm.channelPoolConfig(),
+		JumpHost:        dcm.sshConfig.JumpHost,
 	}
 
 	dcm.sshClient = ssh.NewClient(sshConfig)
 
 	// Connect to SSH server with retry logic
+	sshCtx, sshSpan := telemetry.Start(ctx, "dockbridge.ssh.connect")
 	var connectErr error
 	maxRetries := 3
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		connectCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		sshSpan.SetAttributes(attribute.Int("dockbridge.attempts", attempt))
+		connectCtx, cancel := context.WithTimeout(sshCtx, 45*time.Second)
 		connectErr = dcm.sshClient.Connect(connectCtx)
 		cancel()
 
@@ -227,33 +426,60 @@ func (dcm *dockerClientManagerImpl) EnsureConnection(ctx context.Context) error
 		}
 	}
 
+	telemetry.End(sshSpan, connectErr)
 	if connectErr != nil {
-		return errors.Wrap(connectErr, "failed to connect to SSH server after retries")
+		return dberrors.NewTunnelError(dberrors.ErrCodeSSHConnect, "Failed to connect to SSH server after retries", connectErr).
+			WithHint(`Check that the server's SSH port is reachable from this network, or run "dockbridge doctor"`)
 	}
 
 	dcm.logger.WithFields(map[string]any{
 		"server_ip": server.IPAddress,
 	}).Info("SSH connection established successfully")
+	go dcm.checkServerVersion(dcm.sshClient)
 
-	// Create SSH tunnel for Docker API
-	localAddr := "127.0.0.1:0" // Use random available port
-	remoteAddr := "127.0.0.1:2376"
+	// Encrypted volumes stay locked until the client hands over their keys
+	if err := dcm.unlockVolumes(ctx); err != nil {
+		dcm.cleanup()
+		return err
+	}
 
+	// Missing registry credentials only fail pulls of private images, so they do not
+	// keep the connection from being used
+	if err := dcm.syncRegistryAuth(ctx); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to forward registry credentials")
+	}
+
+	dcm.syncKeepAliveToken(ctx, server)
+
+	// Create SSH tunnel for Docker API
 	tunnelCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
 	defer cancel()
 
-	dcm.tunnel, err = dcm.sshClient.CreateTunnel(tunnelCtx, localAddr, remoteAddr)
+	tunnelCtx, tunnelSpan := telemetry.Start(tunnelCtx, "dockbridge.tunnel.create")
+	dcm.tunnel, err = dcm.createDockerTunnel(tunnelCtx)
+	telemetry.End(tunnelSpan, err)
 	if err != nil {
 		dcm.cleanup()
-		return errors.Wrap(err, "failed to create SSH tunnel")
+		return dberrors.NewTunnelError(dberrors.ErrCodeTunnelFailed, "Failed to create SSH tunnel", err)
 	}
 
 	dcm.logger.WithFields(map[string]any{
 		"local_addr":  dcm.tunnel.LocalAddr(),
-		"remote_addr": remoteAddr,
+		"remote_addr": dcm.tunnel.RemoteAddr(),
 		"server_ip":   server.IPAddress,
 	}).Info("SSH tunnel established")
 
+	dcm.loadServerTLS(server.Name)
+	dcm.startReconnectManager()
+
+	// Bring back the forwards of the previous daemon, then save what is connected now
+	savedForwards := dcm.savedForwards(server)
+	dcm.startPortForwardingForServer()
+	dcm.restoreForwards(ctx, savedForwards)
+	dcm.saveState(server, dcm.portForwardManager)
+
 	return nil
 }
 
@@ -261,6 +487,9 @@ func (dcm *dockerClientManagerImpl) EnsureConnection(ctx context.Context) error
 func (dcm *dockerClientManagerImpl) Close() error {
 	dcm.logger.Info("Closing Docker client manager")
 
+	// Save the forwards as they are now, including those removed by hand
+	dcm.saveState(dcm.currentServer, dcm.portForwardManager)
+
 	// Stop port forwarding first
 	if err := dcm.StopPortForwarding(); err != nil {
 		dcm.logger.WithFields(map[string]any{
@@ -278,6 +507,104 @@ func (dcm *dockerClientManagerImpl) GetTunnel() ssh.TunnelInterface {
 	return dcm.tunnel
 }
 
+// RevalidateConnection actively probes the SSH connection and reconnects if it is dead.
+// Unlike EnsureConnection it does not trust cached state, which may be stale after
+// the client was suspended or changed networks.
+func (dcm *dockerClientManagerImpl) RevalidateConnection(ctx context.Context) error {
+	if dcm.sshClient == nil || dcm.tunnel == nil {
+		// Nothing established yet; the next Docker request connects lazily
+		return nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	_, err := dcm.sshClient.ExecuteCommand(probeCtx, "true")
+	cancel()
+	if err == nil {
+		dcm.logger.Debug("Connection revalidated")
+		return nil
+	}
+
+	dcm.logger.WithFields(map[string]any{
+		"error": err.Error(),
+	}).Warn("Connection probe failed, reconnecting")
+
+	dcm.cleanup()
+	return dcm.EnsureConnection(ctx)
+}
+
+// Disconnect drops the current connection without touching the server, so that the
+// next request reconnects, resuming or provisioning a server as needed
+func (dcm *dockerClientManagerImpl) Disconnect() {
+	dcm.logger.Info("Dropping connection to remote server")
+	dcm.cleanup()
+}
+
+// CurrentServer returns the server the manager is connected to, or nil
+func (dcm *dockerClientManagerImpl) CurrentServer() *provider.Server {
+	return dcm.currentServer
+}
+
+// SetBackup configures scheduled volume backups on new servers; nil disables them
+func (dcm *dockerClientManagerImpl) SetBackup(cfg *config.BackupConfig) {
+	dcm.backup = cfg
+}
+
+// SetProvisioning configures user-supplied steps and files merged into the setup of
+// new servers; nil disables them
+func (dcm *dockerClientManagerImpl) SetProvisioning(cfg *config.ProvisioningConfig) {
+	dcm.provisioningSteps = cfg
+}
+
+// SetRegistryAuth selects the registries whose credentials are forwarded to the server
+// on every connection
+func (dcm *dockerClientManagerImpl) SetRegistryAuth(entries []config.RegistryAuthConfig) {
+	dcm.registryAuth = entries
+}
+
+// SetRegistryMirror configures the pull-through registry cache run on new servers
+func (dcm *dockerClientManagerImpl) SetRegistryMirror(cfg *config.RegistryMirrorConfig) {
+	dcm.registryMirror = cfg
+}
+
+// backupScript returns the provisioning snippet for volume backups, if enabled
+func (dcm *dockerClientManagerImpl) backupScript() string {
+	return backup.SetupScript(dcm.backup, backup.Host(dcm.contextName))
+}
+
+// osUpdatesScript returns the provisioning snippet for managed OS updates, if enabled
+func (dcm *dockerClientManagerImpl) osUpdatesScript() string {
+	if dcm.hetznerConfig == nil {
+		return ""
+	}
+	return osupdates.SetupScript(&dcm.hetznerConfig.OSUpdates)
+}
+
+// ExecuteRemoteCommand runs a command over the existing SSH connection
+func (dcm *dockerClientManagerImpl) ExecuteRemoteCommand(ctx context.Context, command string) ([]byte, error) {
+	if dcm.sshClient == nil || !dcm.sshClient.IsConnected() {
+		return nil, errors.New("not connected to a remote server")
+	}
+	return dcm.sshClient.ExecuteCommand(ctx, command)
+}
+
+// ExecuteRemoteCommandWithInput runs a command over the existing SSH connection with
+// input as its stdin
+func (dcm *dockerClientManagerImpl) ExecuteRemoteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error) {
+	if dcm.sshClient == nil || !dcm.sshClient.IsConnected() {
+		return nil, errors.New("not connected to a remote server")
+	}
+	return dcm.sshClient.ExecuteCommandWithInput(ctx, command, input)
+}
+
+// OpenRemoteStream starts a command over the existing SSH connection and returns its
+// stdin and stdout
+func (dcm *dockerClientManagerImpl) OpenRemoteStream(ctx context.Context, command string) (io.ReadWriteCloser, error) {
+	if dcm.sshClient == nil || !dcm.sshClient.IsConnected() {
+		return nil, errors.New("not connected to a remote server")
+	}
+	return dcm.sshClient.OpenStream(ctx, command)
+}
+
 // isConnectionHealthy checks if the current connection is healthy
 func (dcm *dockerClientManagerImpl) isConnectionHealthy() bool {
 	if dcm.sshClient == nil || !dcm.sshClient.IsConnected() || dcm.tunnel == nil {
@@ -303,6 +630,12 @@ func (dcm *dockerClientManagerImpl) isConnectionHealthy() bool {
 
 // cleanup closes all connections without locking
 func (dcm *dockerClientManagerImpl) cleanup() {
+	// Stop reconnecting and forwarding before the connection is closed underneath them
+	dcm.stopReconnectManager()
+	if dcm.portForwardManager != nil {
+		_ = dcm.StopPortForwarding()
+	}
+
 	if dcm.dockerClient != nil {
 		dcm.dockerClient.Close()
 		dcm.dockerClient = nil
@@ -319,41 +652,88 @@ func (dcm *dockerClientManagerImpl) cleanup() {
 	}
 
 	dcm.currentServer = nil
+	dcm.tlsConfig = nil
 }
 
 // getOrProvisionServer gets an existing server or provisions a new one
-func (dcm *dockerClientManagerImpl) getOrProvisionServer(ctx context.Context) (*hetzner.Server, error) {
-	// First, try to find an existing DockBridge server
-	servers, err := dcm.hetznerClient.ListServers(ctx)
+func (dcm *dockerClientManagerImpl) getOrProvisionServer(ctx context.Context) (*provider.Server, error) {
+	// The server of the previous daemon is used as is while it keeps running
+	if server := dcm.savedServer(ctx); server != nil {
+		return server, nil
+	}
+
+	// Only one local process looks up and provisions the context's server at a time;
+	// the others wait and then find the server it created
+	lock, err := dcm.lockProvisioning(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		defer func() { _ = lock.Release() }()
+	}
+	return dcm.findOrProvisionServer(ctx, lock)
+}
+
+// findOrProvisionServer gets an existing server, completes a provisioning interrupted
+// under lock, or provisions a new server. lock may be nil if locking is unavailable.
+func (dcm *dockerClientManagerImpl) findOrProvisionServer(ctx context.Context, lock *provisionlock.Lock) (*provider.Server, error) {
+	// Try to find an existing DockBridge server of this context; servers of
+	// other contexts are left alone
+	registry := provider.NewServerRegistryForOwner(dcm.cloudProvider, dcm.serverOwner())
+	servers, err := registry.ForContext(ctx, dcm.contextName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list servers")
 	}
 
 	dcm.logger.WithFields(map[string]any{
-		"total_servers": len(servers),
-	}).Debug("Listed servers from Hetzner")
+		"context":         dcm.contextName,
+		"context_servers": len(servers),
+	}).Debug("Listed context servers")
 
-	// Look for running DockBridge servers
-	var runningServers []*hetzner.Server
-	var staleServers []*hetzner.Server
+	// Look for running DockBridge servers, and powered-off ones the provider can resume
+	var runningServers []*provider.Server
+	var stoppedServers []*provider.Server
+	var staleServers []*provider.Server
+	var pendingServer *provider.Server
+	canResume := dcm.powerController() != nil
 
 	for _, server := range servers {
-		if strings.HasPrefix(server.Name, "dockbridge-") {
-			dcm.logger.WithFields(map[string]any{
-				"server_id":   server.ID,
-				"server_name": server.Name,
-				"server_ip":   server.IPAddress,
-				"status":      server.Status,
-			}).Debug("Found DockBridge server")
-
-			if server.Status == "running" {
-				runningServers = append(runningServers, server)
-			} else {
-				staleServers = append(staleServers, server)
-			}
+		dcm.logger.WithFields(map[string]any{
+			"server_id":   server.ID,
+			"server_name": server.Name,
+			"server_ip":   server.IPAddress,
+			"status":      server.Status,
+		}).Debug("Found DockBridge server")
+
+		switch {
+		case isPendingProvisioning(server, lock):
+			pendingServer = server
+		case provisioningElsewhere(server, time.Now()):
+			// Another process without access to the lock is still setting it up
+		case server.Status == "running":
+			runningServers = append(runningServers, server)
+		case server.Status == provider.StatusOff && canResume:
+			stoppedServers = append(stoppedServers, server)
+		default:
+			staleServers = append(staleServers, server)
 		}
 	}
 
+	// A server whose provisioning was interrupted is completed instead of creating another
+	if pendingServer != nil {
+		go dcm.cleanupStaleServers(context.Background(), staleServers)
+		return dcm.completeProvisioning(ctx, pendingServer, lock)
+	}
+
+	// A running server wins; otherwise one powered-off server is resumed
+	if len(runningServers) > 0 {
+		staleServers = append(staleServers, stoppedServers...)
+		stoppedServers = nil
+	} else if len(stoppedServers) > 1 {
+		staleServers = append(staleServers, stoppedServers[1:]...)
+		stoppedServers = stoppedServers[:1]
+	}
+
 	// Clean up stale servers in background
 	if len(staleServers) > 0 {
 		go dcm.cleanupStaleServers(context.Background(), staleServers)
@@ -368,6 +748,14 @@ func (dcm *dockerClientManagerImpl) getOrProvisionServer(ctx context.Context) (*
 			go dcm.cleanupStaleServers(context.Background(), runningServers[1:])
 		}
 
+		replaced, err := dcm.replaceIfMismatched(ctx, selectedServer)
+		if err != nil {
+			return nil, err
+		}
+		if replaced {
+			return dcm.provisionNewServer(ctx, lock)
+		}
+
 		dcm.logger.WithFields(map[string]any{
 			"server_id": selectedServer.ID,
 			"server_ip": selectedServer.IPAddress,
@@ -376,15 +764,36 @@ func (dcm *dockerClientManagerImpl) getOrProvisionServer(ctx context.Context) (*
 		return selectedServer, nil
 	}
 
+	// Resume a powered-off server unless it has to be replaced
+	if len(stoppedServers) > 0 {
+		replaced, err := dcm.replaceIfMismatched(ctx, stoppedServers[0])
+		if err != nil {
+			return nil, err
+		}
+		if !replaced {
+			return dcm.resumeServer(ctx, stoppedServers[0])
+		}
+	}
+
 	// No running server found, provision a new one
 	dcm.logger.Info("No running server found, provisioning new server")
-	return dcm.provisionNewServer(ctx)
+	return dcm.provisionNewServer(ctx, lock)
 }
 
-// provisionNewServer creates a new Hetzner server with Docker CE
-func (dcm *dockerClientManagerImpl) provisionNewServer(ctx context.Context) (*hetzner.Server, error) {
+// provisionNewServer creates a new Hetzner server with Docker CE. Its idempotency token
+// is recorded in lock (if not nil) until the server is ready.
+func (dcm *dockerClientManagerImpl) provisionNewServer(ctx context.Context, lock *provisionlock.Lock) (_ *provider.Server, err error) {
+	ctx, span := telemetry.Start(ctx, "dockbridge.server.provision")
+	defer func() { telemetry.End(span, err) }()
+
+	if dcm.provisionGuard != nil {
+		if err := dcm.provisionGuard(ctx); err != nil {
+			return nil, errors.Wrap(err, "provisioning refused")
+		}
+	}
+
 	// Generate server name with timestamp
-	serverName := fmt.Sprintf("dockbridge-%d", time.Now().Unix())
+	serverName := provider.NewServerName(dcm.contextName)
 
 	// Get SSH public key
 	sshKeyPath := expandPath(dcm.sshConfig.KeyPath)
@@ -397,94 +806,100 @@ func (dcm *dockerClientManagerImpl) provisionNewServer(ctx context.Context) (*he
 
 	publicKeyContent := string(publicKeyBytes)
 
-	// Create cloud-init script for Docker CE installation
-	cloudInitScript := fmt.Sprintf(`#!/bin/bash
-set -e
-
-# Log all output
-exec > >(tee -a /var/log/dockbridge-setup.log)
-exec 2>&1
-
-echo "$(date): Starting DockBridge server setup"
-
-# Update system
-echo "$(date): Updating system packages"
-apt-get update
-apt-get upgrade -y
-
-# Add SSH public key to root user
-echo "$(date): Setting up SSH access"
-mkdir -p /root/.ssh
-echo "%s" >> /root/.ssh/authorized_keys
-chmod 600 /root/.ssh/authorized_keys
-chmod 700 /root/.ssh
-
-# Install Docker CE
-echo "$(date): Installing Docker CE"
-curl -fsSL https://get.docker.com -o get-docker.sh
-sh get-docker.sh
-
-# Enable Docker service
-echo "$(date): Enabling Docker service"
-systemctl enable docker
-systemctl start docker
-
-# Wait for Docker to be ready
-echo "$(date): Waiting for Docker daemon to be ready"
-for i in {1..30}; do
-    if docker version >/dev/null 2>&1; then
-        echo "$(date): Docker daemon is ready"
-        break
-    fi
-    echo "$(date): Waiting for Docker daemon... attempt $i/30"
-    sleep 2
-done
-
-# Configure Docker daemon to listen on TCP port 2376
-echo "$(date): Configuring Docker daemon for TCP access"
-mkdir -p /etc/systemd/system/docker.service.d
-cat > /etc/systemd/system/docker.service.d/override.conf << EOF
-[Service]
-ExecStart=
-ExecStart=/usr/bin/dockerd -H fd:// -H tcp://0.0.0.0:2376
-EOF
-
-# Reload systemd and restart Docker
-echo "$(date): Restarting Docker with new configuration"
-systemctl daemon-reload
-systemctl restart docker
-
-# Wait for Docker to be ready again
-echo "$(date): Waiting for Docker daemon to be ready after restart"
-for i in {1..30}; do
-    if docker version >/dev/null 2>&1; then
-        echo "$(date): Docker daemon is ready after restart"
-        break
-    fi
-    echo "$(date): Waiting for Docker daemon after restart... attempt $i/30"
-    sleep 2
-done
-
-echo "$(date): DockBridge server setup completed successfully"
-`, publicKeyContent)
+	// Certificates for mutual TLS with the Docker daemon, if enabled
+	tlsBundle, err := dcm.prepareServerTLS(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret the server's keep-alive monitor requires heartbeats to be signed with
+	keepAliveToken, err := prepareKeepAliveToken(serverName)
+	if err != nil {
+		dcm.removeServerTLS(serverName)
+		return nil, err
+	}
+
+	// A golden image already has Docker installed; only the per-server setup runs
+	goldenImage := dcm.goldenImageFor(ctx)
+
+	// Named volumes left behind by earlier servers are attached again
+	volumes, err := dcm.prepareVolumes(ctx)
+	if err != nil {
+		dcm.removeServerTLS(serverName)
+		dcm.removeKeepAliveToken(serverName)
+		return nil, err
+	}
+
+	// User-supplied steps and files, read from local files now so a missing script
+	// fails before the server is paid for
+	steps, err := provisioning.Resolve(dcm.provisioningSteps)
+	if err != nil {
+		dcm.removeServerTLS(serverName)
+		dcm.removeKeepAliveToken(serverName)
+		return nil, errors.Wrap(err, "failed to prepare provisioning steps")
+	}
+
+	userData, err := dcm.userData(serverSetup{
+		Name:           serverName,
+		PublicKey:      publicKeyContent,
+		InstallDocker:  goldenImage == nil,
+		Volumes:        volumes,
+		TLS:            tlsBundle,
+		KeepAliveToken: keepAliveToken,
+		Steps:          steps,
+	})
+	if err != nil {
+		dcm.removeServerTLS(serverName)
+		dcm.removeKeepAliveToken(serverName)
+		return nil, err
+	}
 
 	// Upload SSH key to Hetzner
-	sshKey, err := dcm.hetznerClient.ManageSSHKeys(ctx, publicKeyContent)
+	sshKey, err := dcm.cloudProvider.ManageSSHKeys(ctx, publicKeyContent)
 	if err != nil {
+		dcm.removeServerTLS(serverName)
+		dcm.removeKeepAliveToken(serverName)
 		return nil, errors.Wrap(err, "failed to manage SSH key with Hetzner")
 	}
 
-	serverConfig := &hetzner.ServerConfig{
+	token := provisionlock.NewToken()
+	labels := dcm.serverOwner().ServerLabels(dcm.contextName)
+	labels[provider.LabelProvisionToken] = token
+
+	serverConfig := &provider.ServerConfig{
 		Name:       serverName,
 		ServerType: dcm.hetznerConfig.ServerType,
 		Location:   dcm.hetznerConfig.Location,
-		UserData:   cloudInitScript,
+		UserData:   userData,
 		SSHKeyID:   sshKey.ID,
+		Volumes:    volumes,
+		Labels:     labels,
+
+		Network:        dcm.hetznerConfig.Network,
+		Firewall:       provider.NewFirewall(dcm.hetznerConfig.Firewall, dcm.firewallPorts()...),
+		PlacementGroup: dcm.hetznerConfig.PlacementGroup,
+		PublicNetwork:  dcm.hetznerConfig.PublicNetwork,
+	}
+	if goldenImage != nil {
+		serverConfig.ImageID = goldenImage.ID
 	}
 
-	server, err := dcm.hetznerClient.ProvisionServer(ctx, serverConfig)
+	// Until the server is ready, the next process taking the lock completes this
+	// provisioning if it is interrupted
+	if lock != nil {
+		if err := lock.Begin(token, time.Now()); err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Failed to record provisioning in progress")
+		}
+	}
+
+	dcm.reportPhase(provider.PhaseCreatingServer, fmt.Sprintf("Creating %s server in %s", serverConfig.ServerType, serverConfig.Location), 10)
+	server, err := dcm.cloudProvider.ProvisionServer(ctx, serverConfig)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to provision server")
+		dcm.removeServerTLS(serverName)
+		dcm.removeKeepAliveToken(serverName)
+		return nil, dberrors.NewProvisioningError(dberrors.ErrCodeProvisionFailed, "Failed to provision server", err, true)
 	}
 
 	dcm.logger.WithFields(map[string]any{
@@ -493,16 +908,61 @@ echo "$(date): DockBridge server setup completed successfully"
 		"server_ip":   server.IPAddress,
 	}).Info("New server provisioned successfully")
 
+	// The IP may have belonged to an earlier server; its key must not be trusted
+	dcm.forgetHostKey(server.IPAddress)
+
 	// Wait for server to be ready
-	if err := dcm.waitForServerReady(ctx, server); err != nil {
+	err = dcm.waitForServerReady(ctx, server, true)
+	finishProvisioning(lock)
+	if err != nil {
 		// Clean up failed server in background
-		go dcm.cleanupStaleServers(context.Background(), []*hetzner.Server{server})
-		return nil, errors.Wrap(err, "server provisioned but not ready")
+		go dcm.cleanupStaleServers(context.Background(), []*provider.Server{server})
+		return nil, errServerNotReady(err)
+	}
+
+	dcm.publish(hooks.NewEvent(hooks.EventServerProvisioned, map[string]string{
+		"server_id":   strconv.FormatInt(server.ID, 10),
+		"server_name": server.Name,
+		"server_ip":   server.IPAddress,
+		"server_type": dcm.hetznerConfig.ServerType,
+		"location":    dcm.hetznerConfig.Location,
+	}))
+	for _, volume := range volumes {
+		dcm.publish(hooks.NewEvent(hooks.EventVolumeAttached, map[string]string{
+			"server_id":   strconv.FormatInt(server.ID, 10),
+			"server_name": server.Name,
+			"volume_id":   volume.ID,
+			"volume_name": volume.Name,
+			"mount":       volume.Mount,
+		}))
+	}
+
+	if goldenImage == nil {
+		dcm.startGoldenImageBuild(server)
 	}
 
 	return server, nil
 }
 
+// forgetHostKey drops any host key recorded for ip. Cloud providers recycle addresses,
+// so a new server must not be verified against the key of a previous one.
+func (dcm *dockerClientManagerImpl) forgetHostKey(ip string) {
+	addr := net.JoinHostPort(ip, strconv.Itoa(dcm.sshConfig.Port))
+	removed, err := ssh.NewKnownHosts(expandPath(dcm.sshConfig.KnownHostsPath)).Remove(addr)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_ip": ip,
+			"error":     err.Error(),
+		}).Warn("Failed to remove stale host key")
+		return
+	}
+	if removed {
+		dcm.logger.WithFields(map[string]any{
+			"server_ip": ip,
+		}).Info("Removed stale host key for recycled server address")
+	}
+}
+
 // readOrGenerateSSHKey reads existing SSH key or generates a new one
 func (dcm *dockerClientManagerImpl) readOrGenerateSSHKey(privateKeyPath, publicKeyPath string) ([]byte, error) {
 	// Try to read existing public key
@@ -525,123 +985,48 @@ func (dcm *dockerClientManagerImpl) readOrGenerateSSHKey(privateKeyPath, publicK
 	return os.ReadFile(publicKeyPath) // #nosec G304
 }
 
-// generateSSHKey generates an SSH key pair
+// generateSSHKey generates an SSH key pair of the configured type natively,
+// so that no OpenSSH installation is required
 func (dcm *dockerClientManagerImpl) generateSSHKey(keyPath string) error {
-	// Create directory if it doesn't exist
-	keyDir := filepath.Dir(keyPath)
-	if err := os.MkdirAll(keyDir, 0700); err != nil {
-		return errors.Wrap(err, "failed to create SSH key directory")
+	keyType := dcm.sshConfig.KeyType
+	if keyType == "" {
+		keyType = ssh.DefaultKeyType
 	}
 
-	// Generate SSH key using ssh-keygen
-	cmd := exec.Command("ssh-keygen", "-t", "rsa", "-b", "4096", "-f", keyPath, "-N", "")
-	if err := cmd.Run(); err != nil {
+	if err := ssh.NewKeyManager().GenerateKeyPair(keyPath, keyType, ssh.DefaultKeyBits); err != nil {
 		return errors.Wrap(err, "failed to generate SSH key")
 	}
 
 	dcm.logger.WithFields(map[string]any{
 		"private_key": keyPath,
 		"public_key":  keyPath + ".pub",
+		"key_type":    keyType,
 	}).Info("Generated SSH key pair")
 
 	return nil
 }
 
-// waitForServerReady waits for the server to be fully configured and Docker to be running
-func (dcm *dockerClientManagerImpl) waitForServerReady(ctx context.Context, server *hetzner.Server) error {
-	dcm.logger.WithFields(map[string]any{
-		"server_id": server.ID,
-	}).Info("Waiting for server to be ready")
-
-	timeout := time.After(8 * time.Minute)
-	ticker := time.NewTicker(15 * time.Second)
-	defer ticker.Stop()
-
-	attempt := 0
-	startTime := time.Now()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-timeout:
-			elapsed := time.Since(startTime)
-			dcm.logger.WithFields(map[string]any{
-				"server_id": server.ID,
-				"attempts":  attempt,
-				"elapsed":   elapsed.String(),
-			}).Error("Timeout waiting for server to be ready")
-			return errors.New("timeout waiting for server to be ready after 8 minutes")
-		case <-ticker.C:
-			attempt++
-			elapsed := time.Since(startTime)
-			dcm.logger.WithFields(map[string]any{
-				"server_id": server.ID,
-				"attempt":   attempt,
-				"elapsed":   elapsed.String(),
-			}).Info("Checking server readiness")
-
-			if dcm.checkServerReady(ctx, server) {
-				dcm.logger.WithFields(map[string]any{
-					"server_id": server.ID,
-					"attempts":  attempt,
-					"elapsed":   elapsed.String(),
-				}).Info("Server is ready")
-				return nil
-			}
-		}
-	}
-}
-
-// checkServerReady checks if the server is ready by attempting to connect and verify Docker
-func (dcm *dockerClientManagerImpl) checkServerReady(ctx context.Context, server *hetzner.Server) bool {
-	sshKeyPath := expandPath(dcm.sshConfig.KeyPath)
-	sshConfig := &ssh.ClientConfig{
-		Host:           server.IPAddress,
-		Port:           dcm.sshConfig.Port,
-		User:           "root",
-		PrivateKeyPath: sshKeyPath,
-		Timeout:        15 * time.Second,
-	}
-
-	tempSSHClient := ssh.NewClient(sshConfig)
-	defer tempSSHClient.Close()
-
-	checkCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
-	defer cancel()
-
-	// Try to connect
-	if err := tempSSHClient.Connect(checkCtx); err != nil {
-		dcm.logger.WithFields(map[string]any{
-			"server_id": server.ID,
-			"server_ip": server.IPAddress,
-			"error":     err.Error(),
-		}).Debug("Server not ready - SSH connection failed")
-		return false
-	}
-
-	// Check if Docker is running
-	output, err := tempSSHClient.ExecuteCommand(checkCtx, "docker version --format '{{.Server.Version}}'")
-	if err != nil {
-		dcm.logger.WithFields(map[string]any{
-			"server_id": server.ID,
-			"error":     err.Error(),
-		}).Debug("Server not ready - Docker daemon not responding")
-		return false
+// serverOwner returns the owner of the servers the manager creates and cleans up
+func (dcm *dockerClientManagerImpl) serverOwner() provider.Owner {
+	if dcm.owner.ID != "" {
+		return dcm.owner
 	}
-
-	dockerVersion := strings.TrimSpace(string(output))
-	dcm.logger.WithFields(map[string]any{
-		"server_id":      server.ID,
-		"docker_version": dockerVersion,
-	}).Info("Server ready - Docker daemon is responding")
-
-	return true
+	return provider.LocalOwner()
 }
 
-// cleanupStaleServers removes stale or duplicate servers in the background
-func (dcm *dockerClientManagerImpl) cleanupStaleServers(ctx context.Context, servers []*hetzner.Server) {
+// cleanupStaleServers removes stale or duplicate servers in the background; servers
+// created by other clients are never removed
+func (dcm *dockerClientManagerImpl) cleanupStaleServers(ctx context.Context, servers []*provider.Server) {
 	for _, server := range servers {
+		if err := provider.CheckOwned(dcm.serverOwner(), server); err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"server_id":   server.ID,
+				"server_name": server.Name,
+				"error":       err.Error(),
+			}).Warn("Not cleaning up server of another client")
+			continue
+		}
+
 		dcm.logger.WithFields(map[string]any{
 			"server_id":   server.ID,
 			"server_name": server.Name,
@@ -649,12 +1034,14 @@ func (dcm *dockerClientManagerImpl) cleanupStaleServers(ctx context.Context, ser
 		}).Info("Cleaning up stale DockBridge server")
 
 		serverID := fmt.Sprintf("%d", server.ID)
-		if err := dcm.hetznerClient.DestroyServer(ctx, serverID); err != nil {
+		if err := dcm.cloudProvider.DestroyServer(ctx, serverID); err != nil {
 			dcm.logger.WithFields(map[string]any{
 				"server_id": server.ID,
 				"error":     err.Error(),
 			}).Error("Failed to cleanup stale server")
 		} else {
+			dcm.removeServerTLS(server.Name)
+			dcm.removeKeepAliveToken(server.Name)
 			dcm.logger.WithFields(map[string]any{
 				"server_id": server.ID,
 			}).Info("Successfully cleaned up stale server")
@@ -691,11 +1078,18 @@ func (dcm *dockerClientManagerImpl) StartPortForwarding(ctx context.Context) err
 		return errors.Wrap(err, "failed to get Docker client for port forwarding")
 	}
 
-	// Initialize container monitor
+	// Initialize container monitor; it follows Docker events and only polls while the
+	// events stream is down
 	dcm.containerMonitor = monitor.NewContainerMonitor(dockerClient, dcm.logger)
+	if dcm.portForwardConfig.MonitorInterval > 0 {
+		_ = dcm.containerMonitor.SetPollingInterval(dcm.portForwardConfig.MonitorInterval)
+	}
 
-	// Initialize port forward manager
+	// Initialize port forward manager, tunnelling forwards through the SSH connection
 	dcm.portForwardManager = portforward.NewPortForwardManager(dcm.portForwardConfig, dcm.logger)
+	if dcm.sshClient != nil {
+		dcm.portForwardManager.SetSSHClient(dcm.sshClient)
+	}
 
 	// Register port forward manager as container event handler
 	err = dcm.containerMonitor.RegisterContainerEventHandler(dcm.portForwardManager)
@@ -703,6 +1097,17 @@ func (dcm *dockerClientManagerImpl) StartPortForwarding(ctx context.Context) err
 		return errors.Wrap(err, "failed to register port forward manager as event handler")
 	}
 
+	// Publish container and forward events to lifecycle hooks
+	if err := dcm.containerMonitor.RegisterContainerEventHandler(&containerHookHandler{publish: dcm.publish}); err != nil {
+		return errors.Wrap(err, "failed to register container hook handler")
+	}
+	server, manager := dcm.currentServer, dcm.portForwardManager
+	dcm.portForwardManager.SetForwardAddedCallback(func(forward *portforward.PortForward) {
+		dcm.publishForwardAdded(forward)
+		// The callback runs with the manager locked; save once it is released
+		go dcm.saveState(server, manager)
+	})
+
 	// Start port forward manager
 	err = dcm.portForwardManager.Start(ctx)
 	if err != nil {
@@ -725,6 +1130,32 @@ func (dcm *dockerClientManagerImpl) StartPortForwarding(ctx context.Context) err
 	return nil
 }
 
+// SetPortForwardConfig enables forwarding published container ports to local listeners;
+// nil disables it. Enabling or disabling takes effect on the next connection; other
+// settings also apply to the forwards of the current one.
+func (dcm *dockerClientManagerImpl) SetPortForwardConfig(cfg *config.PortForwardConfig) {
+	dcm.portForwardConfig = cfg
+	if dcm.portForwardManager != nil && cfg != nil {
+		_ = dcm.portForwardManager.SetConfig(cfg)
+	}
+}
+
+// startPortForwardingForServer starts forwarding the ports of the connected server's
+// containers; failures are logged and leave the Docker connection usable
+func (dcm *dockerClientManagerImpl) startPortForwardingForServer() {
+	if dcm.portForwardConfig == nil || !dcm.portForwardConfig.Enabled || dcm.portForwardManager != nil {
+		return
+	}
+
+	// Forwards live as long as the connection, not the request that established it
+	if err := dcm.StartPortForwarding(context.Background()); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Error("Failed to start port forwarding")
+		_ = dcm.StopPortForwarding()
+	}
+}
+
 // StopPortForwarding stops the port forwarding system
 func (dcm *dockerClientManagerImpl) StopPortForwarding() error {
 	var errors []error
@@ -759,28 +1190,58 @@ func (dcm *dockerClientManagerImpl) GetPortForwardManager() portforward.PortForw
 	return dcm.portForwardManager
 }
 
-// InterceptDockerResponse intercepts and modifies Docker API responses for port forwarding
-func (dcm *dockerClientManagerImpl) InterceptDockerResponse(response []byte) ([]byte, error) {
+// InterceptDockerResponse rewrites a successful Docker API response so that published
+// ports show the local addresses they are forwarded on. path is the request path without
+// API version prefix and query. Container list and inspect responses get the actual
+// local host IP and port; container create responses are checked against the conflict strategy, which
+// adds a warning when a port will be remapped and returns a *portforward.DockerAPIError
+// when the strategy is fail.
+func (dcm *dockerClientManagerImpl) InterceptDockerResponse(method, path string, requestBody, response []byte) ([]byte, error) {
 	// If port forwarding is not enabled, return response unchanged
 	if dcm.portForwardConfig == nil || !dcm.portForwardConfig.Enabled {
 		return response, nil
 	}
 
 	// If port forward manager is not initialized, return response unchanged
-	if dcm.portForwardManager == nil {
+	manager := dcm.portForwardManager
+	if manager == nil {
 		return response, nil
 	}
 
-	// For now, this is a placeholder implementation
-	// In a full implementation, this would:
-	// 1. Parse JSON responses to detect container creation
-	// 2. Extract port mapping information
-	// 3. Check for port conflicts using the port conflict resolver
-	// 4. Modify the response to reflect actual assigned ports
-	// 5. Return Docker-compatible error responses for conflicts (fail strategy)
+	switch {
+	case method == http.MethodGet && path == "/containers/json":
+		return portforward.RewriteContainerList(response, manager.LocalBinding)
+	case method == http.MethodGet && isContainerInspectPath(path):
+		return portforward.RewriteContainerInspect(response, manager.LocalBinding)
+	case method == http.MethodPost && path == "/containers/create":
+		return dcm.checkCreatePorts(manager, requestBody, response)
+	default:
+		return response, nil
+	}
+}
 
-	dcm.logger.Debug("Docker API response interception called (placeholder implementation)")
+// checkCreatePorts applies the conflict strategy to the host ports a container create
+// request publishes
+func (dcm *dockerClientManagerImpl) checkCreatePorts(manager portforward.PortForwardManager, requestBody, response []byte) ([]byte, error) {
+	requested, err := portforward.RequestedHostPorts(requestBody)
+	if err != nil {
+		return response, nil
+	}
 
-	// Return response unchanged for now
-	return response, nil
+	var warnings []string
+	for _, port := range requested {
+		localPort, err := manager.CheckLocalPort(port.HostPort, port.Protocol)
+		if err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"host_port": port.HostPort,
+				"protocol":  port.Protocol,
+				"error":     err.Error(),
+			}).Warn("Published port conflicts with a local port")
+			return nil, err
+		}
+		if localPort != port.HostPort {
+			warnings = append(warnings, fmt.Sprintf("Local port %d/%s is in use, DockBridge forwards it on local port %d", port.HostPort, port.Protocol, localPort))
+		}
+	}
+	return portforward.AddCreateWarnings(response, warnings)
 }
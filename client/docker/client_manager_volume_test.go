@@ -108,7 +108,7 @@ func TestDockerClientManagerVolumeIntegration(t *testing.T) {
 		require.NotNil(t, dcmImpl, "Docker client manager implementation should not be nil")
 
 		// Verify the manager was initialized with correct configs
-		assert.NotNil(t, dcmImpl.hetznerClient, "Hetzner client should be set")
+		assert.NotNil(t, dcmImpl.cloudProvider, "Hetzner client should be set")
 		assert.NotNil(t, dcmImpl.sshConfig, "SSH config should be set")
 		assert.NotNil(t, dcmImpl.hetznerConfig, "Hetzner config should be set")
 		assert.NotNil(t, dcmImpl.logger, "Logger should be set")
@@ -119,7 +119,7 @@ func TestDockerClientManagerVolumeIntegration(t *testing.T) {
 		mockHetzner.ExpectedCalls = nil // Reset expectations
 
 		expectedVolume := &hetzner.Volume{
-			ID:       99999,
+			ID:       "99999",
 			Name:     "test-volume",
 			Size:     10,
 			Location: "fsn1",
@@ -147,7 +147,9 @@ func TestCloudInitScriptIntegration(t *testing.T) {
 			VolumeMount:  "/var/lib/docker",
 		}
 
-		script := hetzner.GenerateCloudInitScript(config)
+		script, err := hetzner.GenerateCloudInitScript(config)
+
+		require.NoError(t, err)
 
 		// Verify the script contains volume-specific operations
 		assert.Contains(t, script, "/var/lib/docker", "Script should mount volume at Docker data directory")
@@ -166,7 +168,7 @@ func TestVolumeReuseLogic(t *testing.T) {
 	t.Run("ReuseExistingVolume", func(t *testing.T) {
 		// Mock finding an existing available volume
 		existingVolume := &hetzner.Volume{
-			ID:       11111,
+			ID:       "11111",
 			Name:     "dockbridge-docker-data-existing",
 			Size:     10,
 			Location: "fsn1",
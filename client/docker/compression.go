@@ -0,0 +1,403 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The SSH library has no transport compression, so bulk transfers are compressed by
+// the daemon: uploads are gzipped, which the Docker daemon decompresses by itself, and
+// downloads are compressed on the server by compressRelayScript.
+
+// compressionSample is how much of an upload is test-compressed to decide whether
+// compressing it is worth it
+const compressionSample = 256 << 10
+
+// minCompressionRatio is the compressed size relative to the original above which data
+// is considered compressed already
+const minCompressionRatio = 0.9
+
+// compressedMagics are the leading bytes of compressed formats Docker accepts
+var compressedMagics = [][]byte{
+	{0x1f, 0x8b},                     // gzip
+	[]byte("BZh"),                    // bzip2
+	{0xfd, '7', 'z', 'X', 'Z', 0x00}, // xz
+	{0x28, 0xb5, 0x2f, 0xfd},         // zstd
+}
+
+// compressRelayScript runs on the server for a compressed download. It reads the
+// compression level and the request head from stdin, sends the request to the Docker
+// socket and writes the response as frames of a type byte, a big-endian length and
+// the data: "z" frames hold zlib-compressed data, "r" frames data that did not
+// compress, such as compressed image layers. A failure to send the request is reported
+// as a single "error: " line.
+const compressRelayScript = `
+import socket, struct, sys, zlib
+
+inp, out = sys.stdin.buffer, sys.stdout.buffer
+try:
+    level, size = map(int, inp.readline().split())
+    head = inp.read(size)
+    conn = socket.socket(socket.AF_UNIX)
+    conn.connect("/var/run/docker.sock")
+    conn.sendall(head)
+except Exception as e:
+    out.write(("error: %s\n" % e).encode())
+    out.flush()
+    sys.exit(1)
+
+while True:
+    chunk = conn.recv(1 << 18)
+    if not chunk:
+        break
+    packed = zlib.compress(chunk, level)
+    if len(packed) < len(chunk) * 0.9:
+        out.write(b"z" + struct.pack(">I", len(packed)) + packed)
+    else:
+        out.write(b"r" + struct.pack(">I", len(chunk)) + chunk)
+    out.flush()
+`
+
+// compressRelayCommand runs compressRelayScript; the script contains no single quotes
+const compressRelayCommand = "python3 -c '" + compressRelayScript + "'"
+
+// errCompressionUnavailable reports that the server cannot compress downloads, e.g.
+// because python3 is missing; the request has not reached the Docker daemon
+var errCompressionUnavailable = errors.New("compression unavailable")
+
+// isCompressedUpload reports whether a request uploads an archive the Docker daemon
+// accepts compressed: build contexts, image loads and imports, and files copied into
+// containers
+func isCompressedUpload(method, target string) bool {
+	path := interceptPath(target)
+	switch method {
+	case http.MethodPost:
+		if path == "/images/load" {
+			return true
+		}
+		_, rawQuery, _ := strings.Cut(target, "?")
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return false
+		}
+		switch path {
+		case "/build":
+			return query.Get("version") != "2" && query.Get("remote") == ""
+		case "/images/create":
+			return query.Get("fromSrc") == "-"
+		}
+		return false
+	case http.MethodPut:
+		return isContainerPath(path, "/archive")
+	default:
+		return false
+	}
+}
+
+// isCompressedDownload reports whether a request downloads an archive: image saves,
+// container exports and files copied out of containers
+func isCompressedDownload(method, target string) bool {
+	if method != http.MethodGet {
+		return false
+	}
+	path := interceptPath(target)
+	if path == "/images/get" || (strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/get")) {
+		return true
+	}
+	return isContainerPath(path, "/export") || isContainerPath(path, "/archive")
+}
+
+// isContainerPath reports whether path is /containers/{id}<suffix>
+func isContainerPath(path, suffix string) bool {
+	id, ok := strings.CutPrefix(path, "/containers/")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, suffix)
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+// compressionEnabled reports whether bulk transfers through the tunnel are compressed
+func (d *DockBridgeDaemon) compressionEnabled() bool {
+	return d.config != nil && d.config.Compression != nil && d.config.Compression.Enabled
+}
+
+// compressionLevel returns the configured compression level
+func (d *DockBridgeDaemon) compressionLevel() int {
+	if level := d.config.Compression.Level; level >= flate.BestSpeed && level <= flate.BestCompression {
+		return level
+	}
+	return flate.BestSpeed
+}
+
+// worthCompressing reports whether data starting with sample compresses: it is not in
+// a compressed format and a test compression of the sample saves enough
+func worthCompressing(sample []byte, level int) bool {
+	if len(sample) == 0 {
+		return false
+	}
+	for _, magic := range compressedMagics {
+		if bytes.HasPrefix(sample, magic) {
+			return false
+		}
+	}
+
+	var compressed countingWriter
+	w, err := flate.NewWriter(&compressed, level)
+	if err != nil {
+		return false
+	}
+	_, _ = w.Write(sample)
+	_ = w.Close()
+	return float64(compressed.n) < float64(len(sample))*minCompressionRatio
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// proxyRequestHead returns the head of req for the remote daemon, with the given body
+// framing. The connection is closed after the response.
+func proxyRequestHead(req *http.Request, chunked bool) []byte {
+	header := req.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Del("Content-Length")
+	header.Set("Connection", "close")
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "%s %s HTTP/1.1\r\nHost: docker\r\n", req.Method, req.URL.RequestURI())
+	if chunked {
+		head.WriteString("Transfer-Encoding: chunked\r\n")
+	}
+	_ = header.Write(&head)
+	head.WriteString("\r\n")
+	return head.Bytes()
+}
+
+// serveCompressedUpload forwards an upload with its body gzipped, unless the body is
+// compressed already, and relays the response
+func (d *DockBridgeDaemon) serveCompressedUpload(ctx context.Context, localConn net.Conn, reader io.Reader, connID string) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to parse upload request")
+		return
+	}
+
+	before := d.connState.current().state
+	if err := d.ensureConnection(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to ensure connection to remote server")
+		d.writeConnectionError(localConn, connID, before, err)
+		return
+	}
+
+	remoteConn, err := d.clientManager.DialDocker(ctx)
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to connect to remote Docker daemon via SSH tunnel")
+		_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+		return
+	}
+	defer remoteConn.Close()
+
+	body := bufio.NewReaderSize(req.Body, compressionSample)
+	sample, _ := body.Peek(compressionSample)
+	compress := worthCompressing(sample, d.compressionLevel())
+
+	go func() {
+		var raw, sent countingWriter
+		err := func() error {
+			if _, err := remoteConn.Write(proxyRequestHead(req, true)); err != nil {
+				return err
+			}
+			chunked := httputil.NewChunkedWriter(io.MultiWriter(remoteConn, &sent))
+			if compress {
+				gz, err := gzip.NewWriterLevel(chunked, d.compressionLevel())
+				if err != nil {
+					return err
+				}
+				if _, err := io.Copy(io.MultiWriter(gz, &raw), body); err != nil {
+					return err
+				}
+				if err := gz.Close(); err != nil {
+					return err
+				}
+			} else if _, err := io.Copy(io.MultiWriter(chunked, &raw), body); err != nil {
+				return err
+			}
+			if err := chunked.Close(); err != nil {
+				return err
+			}
+			_, err := io.WriteString(remoteConn, "\r\n")
+			return err
+		}()
+		if err != nil {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"error":   err.Error(),
+			}).Debug("Failed to send upload")
+			return
+		}
+		d.logger.WithFields(map[string]any{
+			"conn_id":    connID,
+			"compressed": compress,
+			"bytes":      raw.n,
+			"sent_bytes": sent.n,
+		}).Debug("Upload sent")
+	}()
+
+	d.metrics.RequestProxied("remote")
+	_, _ = io.Copy(localConn, remoteConn)
+}
+
+// serveCompressedDownload relays a download compressed on the server. Without the
+// relay on the server, the request is forwarded as is.
+func (d *DockBridgeDaemon) serveCompressedDownload(ctx context.Context, localConn net.Conn, reader io.Reader, connID string) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to parse download request")
+		return
+	}
+
+	before := d.connState.current().state
+	if err := d.ensureConnection(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to ensure connection to remote server")
+		d.writeConnectionError(localConn, connID, before, err)
+		return
+	}
+
+	head := proxyRequestHead(req, false)
+	stream, err := d.clientManager.OpenRemoteStream(ctx, compressRelayCommand)
+	if err == nil {
+		defer stream.Close()
+		var size, transferred int64
+		size, transferred, err = relayCompressed(stream, head, d.compressionLevel(), localConn)
+		if err == nil {
+			d.metrics.RequestProxied("remote")
+			d.logger.WithFields(map[string]any{
+				"conn_id":        connID,
+				"bytes":          size,
+				"received_bytes": transferred,
+			}).Debug("Compressed download relayed")
+			return
+		}
+		if !errors.Is(err, errCompressionUnavailable) {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"error":   err.Error(),
+			}).Error("❌ Compressed download failed")
+			return
+		}
+	}
+
+	d.logger.WithFields(map[string]any{
+		"conn_id": connID,
+		"error":   err.Error(),
+	}).Warn("Compression unavailable on the server, downloading uncompressed")
+	remoteConn, err := d.clientManager.DialDocker(ctx)
+	if err != nil {
+		_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+		return
+	}
+	defer remoteConn.Close()
+	if _, err := remoteConn.Write(head); err != nil {
+		return
+	}
+	d.metrics.RequestProxied("remote")
+	_, _ = io.Copy(localConn, remoteConn)
+}
+
+// relayCompressed runs the compressRelayScript protocol over stream and writes the
+// decompressed response to out. It returns the response size and the bytes received.
+func relayCompressed(stream io.ReadWriter, head []byte, level int, out io.Writer) (int64, int64, error) {
+	if _, err := fmt.Fprintf(stream, "%d %d\n%s", level, len(head), head); err != nil {
+		return 0, 0, errors.Wrap(errCompressionUnavailable, err.Error())
+	}
+
+	frames := bufio.NewReaderSize(stream, 1<<16)
+	first, err := frames.Peek(1)
+	if err != nil {
+		return 0, 0, errors.Wrap(errCompressionUnavailable, "the server did not answer, is python3 installed?")
+	}
+	if first[0] == 'e' {
+		line, _ := frames.ReadString('\n')
+		return 0, 0, errors.Wrap(errCompressionUnavailable, strings.TrimSpace(strings.TrimPrefix(line, "error: ")))
+	}
+
+	var written, received int64
+	var frame [5]byte
+	for {
+		if _, err := io.ReadFull(frames, frame[:]); err != nil {
+			if err == io.EOF {
+				return written, received, nil
+			}
+			return written, received, errors.Wrap(err, "truncated response")
+		}
+		length := int64(binary.BigEndian.Uint32(frame[1:]))
+		received += int64(len(frame)) + length
+		data := io.LimitReader(frames, length)
+
+		switch frame[0] {
+		case 'r':
+		case 'z':
+			inflated, err := zlib.NewReader(data)
+			if err != nil {
+				return written, received, errors.Wrap(err, "invalid compressed frame")
+			}
+			n, err := io.Copy(out, inflated)
+			written += n
+			if err != nil {
+				return written, received, errors.Wrap(err, "failed to relay response")
+			}
+			// Skip the zlib checksum the reader leaves unread at the end of a frame
+			if _, err := io.Copy(io.Discard, data); err != nil {
+				return written, received, errors.Wrap(err, "truncated response")
+			}
+			continue
+		default:
+			return written, received, errors.Errorf("invalid frame type %q", frame[0])
+		}
+
+		n, err := io.Copy(out, data)
+		written += n
+		if err != nil {
+			return written, received, errors.Wrap(err, "failed to relay response")
+		}
+		if n != length {
+			return written, received, errors.New("truncated response")
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCompressedUpload(t *testing.T) {
+	assert.True(t, isCompressedUpload("POST", "/v1.43/build?t=app"))
+	assert.True(t, isCompressedUpload("POST", "/v1.43/images/load?quiet=1"))
+	assert.True(t, isCompressedUpload("POST", "/images/create?fromSrc=-&repo=app"))
+	assert.True(t, isCompressedUpload("PUT", "/v1.43/containers/web/archive?path=/srv"))
+	assert.False(t, isCompressedUpload("POST", "/v1.43/build?version=2&session=abc"), "BuildKit builds send no context")
+	assert.False(t, isCompressedUpload("POST", "/images/create?fromImage=nginx&tag=latest"), "pulls send no body")
+	assert.False(t, isCompressedUpload("GET", "/containers/web/archive?path=/srv"))
+	assert.False(t, isCompressedUpload("POST", "/containers/create"))
+}
+
+func TestIsCompressedDownload(t *testing.T) {
+	assert.True(t, isCompressedDownload("GET", "/v1.43/images/get?names=app"))
+	assert.True(t, isCompressedDownload("GET", "/v1.43/images/registry.example.com/app:1/get"))
+	assert.True(t, isCompressedDownload("GET", "/containers/web/export"))
+	assert.True(t, isCompressedDownload("GET", "/v1.43/containers/web/archive?path=/srv"))
+	assert.False(t, isCompressedDownload("HEAD", "/containers/web/archive?path=/srv"))
+	assert.False(t, isCompressedDownload("GET", "/containers/web/logs?follow=1"))
+	assert.False(t, isCompressedDownload("GET", "/images/json"))
+}
+
+func TestWorthCompressing(t *testing.T) {
+	assert.True(t, worthCompressing([]byte(strings.Repeat("FROM scratch\n", 1000)), 1))
+	assert.False(t, worthCompressing(nil, 1))
+
+	random := make([]byte, 64<<10)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+	assert.False(t, worthCompressing(random, 1), "random data does not compress")
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte(strings.Repeat("layer", 1000)))
+	require.NoError(t, gz.Close())
+	assert.False(t, worthCompressing(gzipped.Bytes(), 1), "gzipped data is sent as is")
+}
+
+func TestProxyRequestHead(t *testing.T) {
+	req, err := http.ReadRequest(bufio.NewReader(strings.NewReader("PUT /v1.43/containers/web/archive?path=/srv HTTP/1.1\r\nHost: api.moby.localhost\r\nContent-Length: 3\r\nContent-Type: application/x-tar\r\n\r\nabc")))
+	require.NoError(t, err)
+
+	head := string(proxyRequestHead(req, true))
+	assert.True(t, strings.HasPrefix(head, "PUT /v1.43/containers/web/archive?path=/srv HTTP/1.1\r\n"))
+	assert.Contains(t, head, "Transfer-Encoding: chunked\r\n")
+	assert.Contains(t, head, "Connection: close\r\n")
+	assert.NotContains(t, head, "Content-Length")
+	assert.True(t, strings.HasSuffix(head, "\r\n\r\n"))
+}
+
+func TestRelayCompressed(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	assert.NotContains(t, compressRelayScript, "'", "the script is passed in single quotes")
+
+	// Unix socket paths are limited in length
+	dir, err := os.MkdirTemp("", "dbzip")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	// A Docker daemon answering with a compressible and an incompressible part
+	random := make([]byte, 1<<20)
+	_, err = rand.Read(random)
+	require.NoError(t, err)
+	body := append([]byte(strings.Repeat("0123456789", 100000)), random...)
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err == nil {
+			resp := &http.Response{StatusCode: http.StatusOK, ProtoMajor: 1, ProtoMinor: 1, ContentLength: int64(len(body)), Body: io.NopCloser(bytes.NewReader(body))}
+			_ = resp.Write(conn)
+		}
+	}()
+
+	cmd := exec.Command(python, "-c", strings.ReplaceAll(compressRelayScript, "/var/run/docker.sock", socket))
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer cmd.Wait()
+	defer stdin.Close()
+
+	req, err := http.NewRequest("GET", "/v1.43/images/get?names=app", nil)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	written, received, err := relayCompressed(pipeStream{stdout, stdin}, proxyRequestHead(req, false), 1, &out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), written)
+	assert.Less(t, received, written-int64(len(random))/2, "the compressible part is compressed")
+
+	resp, err := http.ReadResponse(bufio.NewReader(&out), nil)
+	require.NoError(t, err)
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, got)
+}
+
+func TestRelayCompressedUnavailable(t *testing.T) {
+	// python3 missing: the command exits without answering
+	_, _, err := relayCompressed(pipeStream{strings.NewReader(""), io.Discard}, nil, 1, io.Discard)
+	assert.ErrorIs(t, err, errCompressionUnavailable)
+
+	_, _, err = relayCompressed(pipeStream{strings.NewReader("error: [Errno 13] Permission denied\n"), io.Discard}, nil, 1, io.Discard)
+	assert.ErrorIs(t, err, errCompressionUnavailable)
+	assert.ErrorContains(t, err, "Permission denied")
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/metrics"
+	"github.com/dockbridge/dockbridge/client/provider"
+)
+
+// connectionState is where the daemon stands with its remote server
+type connectionState int
+
+const (
+	// stateIdle means no server is connected, e.g. before the first request or after an
+	// idle shutdown
+	stateIdle connectionState = iota
+	// stateProvisioning means a server is being provisioned or resumed
+	stateProvisioning
+	// stateReady means the remote Docker daemon is connected
+	stateReady
+	// stateFailed means the last attempt to connect or provision failed
+	stateFailed
+)
+
+// String returns the state's name
+func (s connectionState) String() string {
+	switch s {
+	case stateProvisioning:
+		return "provisioning"
+	case stateReady:
+		return "ready"
+	case stateFailed:
+		return "failed"
+	default:
+		return "idle"
+	}
+}
+
+// provisioningProgress is the latest phase of a provisioning in progress
+type provisioningProgress struct {
+	Phase   provider.ProvisioningPhase
+	Message string
+	Percent int
+	Started time.Time
+}
+
+// connectionSnapshot is the tracker's state at one point in time
+type connectionSnapshot struct {
+	state connectionState
+	// progress is set while provisioning
+	progress provisioningProgress
+	// failure is the error of the last attempt while failed
+	failure string
+}
+
+// connectionTracker is the daemon's connection state machine. Provisioning phases move
+// it to provisioning and on to ready or failed; the daemon additionally marks it ready
+// or failed after each connection attempt and idle when the server is shut down.
+// Phases are forwarded to the next observer.
+type connectionTracker struct {
+	mu       sync.Mutex
+	snapshot connectionSnapshot
+	next     provider.ProvisioningObserver
+	// metrics records how long provisionings took (optional)
+	metrics *metrics.Recorder
+}
+
+// newConnectionTracker creates an idle tracker forwarding phases to next, which may be nil
+func newConnectionTracker(next provider.ProvisioningObserver) *connectionTracker {
+	return &connectionTracker{next: next}
+}
+
+// OnPhase applies a provisioning phase
+func (t *connectionTracker) OnPhase(phase provider.ProvisioningPhase, message string, percent int) {
+	t.mu.Lock()
+	if phase.Done() && t.snapshot.state == stateProvisioning {
+		result := "ready"
+		if phase == provider.PhaseFailed {
+			result = "failed"
+		}
+		t.metrics.Provisioned(result, time.Since(t.snapshot.progress.Started))
+	}
+	switch {
+	case phase == provider.PhaseReady:
+		t.snapshot = connectionSnapshot{state: stateReady}
+	case phase == provider.PhaseFailed:
+		t.snapshot = connectionSnapshot{state: stateFailed, failure: message}
+	case t.snapshot.state != stateProvisioning:
+		t.snapshot = connectionSnapshot{
+			state:    stateProvisioning,
+			progress: provisioningProgress{Phase: phase, Message: message, Percent: percent, Started: time.Now()},
+		}
+	default:
+		t.snapshot.progress.Phase = phase
+		t.snapshot.progress.Message = message
+		t.snapshot.progress.Percent = percent
+	}
+	t.mu.Unlock()
+
+	if t.next != nil {
+		t.next.OnPhase(phase, message, percent)
+	}
+}
+
+// markReady records a successful connection
+func (t *connectionTracker) markReady() {
+	t.set(connectionSnapshot{state: stateReady})
+}
+
+// markFailed records a failed connection attempt
+func (t *connectionTracker) markFailed(err error) {
+	t.set(connectionSnapshot{state: stateFailed, failure: err.Error()})
+}
+
+// markIdle records that the server was shut down
+func (t *connectionTracker) markIdle() {
+	t.set(connectionSnapshot{state: stateIdle})
+}
+
+// set replaces the snapshot
+func (t *connectionTracker) set(snapshot connectionSnapshot) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.snapshot = snapshot
+}
+
+// current returns the tracker's state; a nil tracker is idle
+func (t *connectionTracker) current() connectionSnapshot {
+	if t == nil {
+		return connectionSnapshot{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.snapshot
+}
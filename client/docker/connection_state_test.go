@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionTracker(t *testing.T) {
+	var forwarded []provider.ProvisioningPhase
+	tracker := newConnectionTracker(provider.ProvisioningObserverFunc(func(phase provider.ProvisioningPhase, message string, percent int) {
+		forwarded = append(forwarded, phase)
+	}))
+	assert.Equal(t, stateIdle, tracker.current().state)
+
+	tracker.OnPhase(provider.PhaseCreatingServer, "Creating cpx21 server in fsn1", 10)
+	first := tracker.current()
+	require.Equal(t, stateProvisioning, first.state)
+
+	tracker.OnPhase(provider.PhaseWaitingForCloudInit, "Waiting for cloud-init", 50)
+	current := tracker.current()
+	assert.Equal(t, provider.PhaseWaitingForCloudInit, current.progress.Phase)
+	assert.Equal(t, 50, current.progress.Percent)
+	assert.Equal(t, first.progress.Started, current.progress.Started)
+
+	tracker.OnPhase(provider.PhaseFailed, "server provisioned but not ready", 100)
+	assert.Equal(t, connectionSnapshot{state: stateFailed, failure: "server provisioned but not ready"}, tracker.current())
+
+	// A new attempt starts a new provisioning
+	tracker.OnPhase(provider.PhaseResumingServer, "Resuming stopped server", 10)
+	assert.Equal(t, stateProvisioning, tracker.current().state)
+	tracker.OnPhase(provider.PhaseReady, "Remote Docker ready", 100)
+	assert.Equal(t, stateReady, tracker.current().state)
+	assert.Len(t, forwarded, 5)
+
+	tracker.markIdle()
+	assert.Equal(t, stateIdle, tracker.current().state)
+	tracker.markFailed(errors.New("failed to list servers"))
+	assert.Equal(t, "failed to list servers", tracker.current().failure)
+	tracker.markReady()
+	assert.Equal(t, stateReady, tracker.current().state)
+
+	// A daemon without a tracker is always idle
+	var missing *connectionTracker
+	missing.markReady()
+	assert.Equal(t, stateIdle, missing.current().state)
+}
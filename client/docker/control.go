@@ -0,0 +1,129 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// ContextName returns the DockBridge context served by the daemon ("" is the default)
+func (d *DockBridgeDaemon) ContextName() string {
+	return d.config.ContextName
+}
+
+// SocketPath returns the local Docker socket served by the daemon
+func (d *DockBridgeDaemon) SocketPath() string {
+	return d.config.SocketPath
+}
+
+// CurrentServer returns the server the daemon is connected to, or nil
+func (d *DockBridgeDaemon) CurrentServer() *provider.Server {
+	return d.clientManager.CurrentServer()
+}
+
+// Provision connects to the context's server, provisioning one if none is running
+func (d *DockBridgeDaemon) Provision(ctx context.Context) (*provider.Server, error) {
+	if err := d.ensureConnection(ctx); err != nil {
+		return nil, err
+	}
+	return d.clientManager.CurrentServer(), nil
+}
+
+// Destroy destroys the running server; the Docker data volume is preserved
+func (d *DockBridgeDaemon) Destroy(reason string) error {
+	if d.lifecycleManager == nil {
+		return errors.New("daemon is not running")
+	}
+	return d.lifecycleManager.ShutdownNow(reason)
+}
+
+// PortForwards returns the active port and socket forwards
+func (d *DockBridgeDaemon) PortForwards() []*portforward.PortForward {
+	pfm := d.clientManager.GetPortForwardManager()
+	if pfm == nil {
+		return nil
+	}
+
+	forwards, err := pfm.ListPortForwards()
+	if err != nil {
+		return nil
+	}
+	return forwards
+}
+
+// AddForward forwards localPort to remotePort of a container; protocol is "tcp" or "udp"
+func (d *DockBridgeDaemon) AddForward(containerID string, localPort, remotePort int, protocol string) (*portforward.PortForward, error) {
+	pfm := d.clientManager.GetPortForwardManager()
+	if pfm == nil {
+		return nil, errors.New("port forwarding is not active")
+	}
+
+	var err error
+	forwardType := portforward.ForwardType(protocol)
+	switch forwardType {
+	case portforward.ForwardTypeTCP:
+		err = pfm.AddPortForward(containerID, localPort, remotePort)
+	case portforward.ForwardTypeUDP:
+		err = pfm.AddUDPPortForward(containerID, localPort, remotePort)
+	default:
+		return nil, errors.Errorf("unsupported protocol %q", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	forwards, err := pfm.ListPortForwards()
+	if err != nil {
+		return nil, err
+	}
+	for _, forward := range forwards {
+		if forward.ContainerID == containerID && forward.Type == forwardType && forward.RemotePort == remotePort {
+			return forward, nil
+		}
+	}
+	return nil, errors.Errorf("forward of container %s port %d was not created", containerID, remotePort)
+}
+
+// RemoveForward closes the port or socket forward with the given ID
+func (d *DockBridgeDaemon) RemoveForward(id string) error {
+	pfm := d.clientManager.GetPortForwardManager()
+	if pfm == nil {
+		return errors.New("port forwarding is not active")
+	}
+
+	forwards, err := pfm.ListPortForwards()
+	if err != nil {
+		return err
+	}
+	for _, forward := range forwards {
+		if forward.ID != id {
+			continue
+		}
+		switch forward.Type {
+		case portforward.ForwardTypeUDP:
+			return pfm.RemoveUDPPortForward(forward.ContainerID, forward.LocalPort)
+		case portforward.ForwardTypeUnix:
+			return pfm.RemoveSocketForward(forward.ContainerID, forward.LocalSocket)
+		default:
+			return pfm.RemovePortForward(forward.ContainerID, forward.LocalPort)
+		}
+	}
+	return errors.Errorf("no forward with ID %s", id)
+}
+
+// CloseProjectForwards closes all forwards of a docker compose project
+func (d *DockBridgeDaemon) CloseProjectForwards(project string) (int, error) {
+	pfm := d.clientManager.GetPortForwardManager()
+	if pfm == nil {
+		return 0, errors.New("port forwarding is not active")
+	}
+	return pfm.RemoveProjectForwards(project)
+}
+
+// SubscribeEvents delivers every lifecycle event of the daemon to listener, in order
+func (d *DockBridgeDaemon) SubscribeEvents(listener hooks.Listener) {
+	d.hooks.Listen(listener)
+}
@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// ProvisionGuardFunc decides whether a new server may be provisioned; an error refuses it
+type ProvisionGuardFunc func(ctx context.Context) error
+
+// SetProvisionGuard sets the check run before a new server is provisioned; nil allows all
+func (dcm *dockerClientManagerImpl) SetProvisionGuard(guard ProvisionGuardFunc) {
+	dcm.provisionGuard = guard
+}
+
+// costBudget returns the configured monthly budget
+func (d *DockBridgeDaemon) costBudget() cost.Budget {
+	if d.config.Budget == nil {
+		return cost.Budget{}
+	}
+	return cost.Budget{
+		Monthly:      d.config.Budget.Monthly,
+		WarnFraction: float64(d.config.Budget.WarnPercent) / 100,
+		Block:        d.config.Budget.Action == "block",
+	}
+}
+
+// newCostMeter creates the meter accruing the spend of the daemon's server
+func (d *DockBridgeDaemon) newCostMeter() *cost.Meter {
+	rates := make(map[string]*cost.Rate)
+	meter := &cost.Meter{
+		Current: func(ctx context.Context) (*cost.ServerInfo, *cost.Rate, error) {
+			return d.currentCost(ctx, rates)
+		},
+		Alert:  d.alertBudget,
+		Store:  d.config.CostStore,
+		Budget: d.costBudget(),
+	}
+	// The budget covers the spend of all contexts, so only the default context alerts
+	if d.config.ContextName != "" {
+		meter.Budget = cost.Budget{}
+	}
+	return meter
+}
+
+// currentCost returns the connected server and its hourly rate, or nil if no server is
+// connected or the provider does not publish prices. Rates are cached per server type.
+func (d *DockBridgeDaemon) currentCost(ctx context.Context, rates map[string]*cost.Rate) (*cost.ServerInfo, *cost.Rate, error) {
+	srv := d.clientManager.CurrentServer()
+	if srv == nil {
+		return nil, nil, nil
+	}
+	pricer, ok := d.config.Provider.(provider.PricingProvider)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	serverType := srv.ServerType
+	if serverType == "" {
+		serverType = d.config.HetznerConfig.ServerType
+	}
+
+	rate, ok := rates[serverType]
+	if !ok {
+		pricing, err := pricer.ServerPricing(ctx, serverType, d.config.HetznerConfig.Location)
+		if err != nil {
+			d.logger.WithFields(map[string]any{
+				"server_type": serverType,
+				"error":       err.Error(),
+			}).Warn("Failed to get server pricing, spend is not tracked")
+			return nil, nil, errors.Wrap(err, "failed to get server pricing")
+		}
+		r := cost.RateOf(pricing, d.config.HetznerConfig.DataVolumeSize())
+		rate = &r
+		rates[serverType] = rate
+	}
+
+	return &cost.ServerInfo{
+		ID:         srv.ID,
+		Name:       srv.Name,
+		Context:    d.config.ContextName,
+		ServerType: serverType,
+		CreatedAt:  srv.CreatedAt,
+	}, rate, nil
+}
+
+// alertBudget reports that the month's estimated spend approached or exceeded the budget
+func (d *DockBridgeDaemon) alertBudget(level cost.Level, spent float64, budget cost.Budget) {
+	d.logger.WithFields(map[string]any{
+		"level":   level.String(),
+		"spent":   fmt.Sprintf("%.2f", spent),
+		"budget":  fmt.Sprintf("%.2f", budget.Monthly),
+		"blocked": budget.Block && level == cost.LevelExceeded,
+	}).Warn("Estimated spend reached a monthly budget threshold")
+
+	message := fmt.Sprintf("Estimated spend this month is %.2f of the %.2f budget", spent, budget.Monthly)
+	if budget.Block && level == cost.LevelExceeded {
+		message += "; new servers will not be provisioned"
+	}
+	d.notifier.Notify(notify.EventBudgetThreshold, d.notificationTitle("Budget "+level.String()), message)
+
+	if level == cost.LevelExceeded && d.hooks != nil {
+		d.hooks.Publish(hooks.NewEvent(hooks.EventBudgetExceeded, map[string]string{
+			"spent":  strconv.FormatFloat(spent, 'f', 2, 64),
+			"budget": strconv.FormatFloat(budget.Monthly, 'f', 2, 64),
+			"action": d.config.Budget.Action,
+		}))
+	}
+}
+
+// checkBudget refuses provisioning once the month's estimated spend exceeds a blocking budget
+func (d *DockBridgeDaemon) checkBudget(ctx context.Context) error {
+	spent, err := d.config.CostStore.Total(cost.Month(time.Now()))
+	if err != nil {
+		d.logger.WithFields(map[string]any{"error": err.Error()}).Warn("Failed to read estimated spend, budget not enforced")
+		return nil
+	}
+	return d.costBudget().Check(spent)
+}
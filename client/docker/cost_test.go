@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvisionGuardRefusesNewServer(t *testing.T) {
+	mockProvider := &MockHetznerClient{}
+	dcm := NewDockerClientManager(mockProvider, &config.SSHConfig{}, &config.HetznerConfig{}, logger.NewDefault()).(*dockerClientManagerImpl)
+	dcm.SetProvisionGuard(func(context.Context) error { return errors.New("monthly budget exceeded") })
+
+	_, err := dcm.provisionNewServer(context.Background(), nil)
+	assert.ErrorContains(t, err, "monthly budget exceeded")
+	// Nothing is created at the provider
+	mockProvider.AssertExpectations(t)
+}
+
+func TestAlertBudgetPublishesExceeded(t *testing.T) {
+	bus := hooks.NewBus("", logger.NewDefault())
+	var events []hooks.Event
+	bus.Listen(func(event hooks.Event) { events = append(events, event) })
+
+	d := &DockBridgeDaemon{
+		config:   &DaemonConfig{Budget: &config.BudgetConfig{Monthly: 50, Action: "block"}},
+		logger:   logger.NewDefault(),
+		notifier: notify.NopNotifier{},
+		hooks:    bus,
+	}
+	budget := cost.Budget{Monthly: 50, Block: true}
+
+	// Warnings only notify
+	d.alertBudget(cost.LevelWarning, 41, budget)
+	assert.Empty(t, events)
+
+	d.alertBudget(cost.LevelExceeded, 52.5, budget)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, hooks.EventBudgetExceeded, events[0].Type)
+		assert.Equal(t, map[string]string{"spent": "52.50", "budget": "50.00", "action": "block"}, events[0].Data)
+	}
+}
@@ -1,50 +1,157 @@
 package docker
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/user"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dockbridge/dockbridge/client/activity"
-	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/cost"
+	"github.com/dockbridge/dockbridge/client/daemonstate"
+	"github.com/dockbridge/dockbridge/client/filesync"
+	"github.com/dockbridge/dockbridge/client/heartbeat"
+	"github.com/dockbridge/dockbridge/client/hooks"
 	"github.com/dockbridge/dockbridge/client/lifecycle"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"github.com/dockbridge/dockbridge/client/metrics"
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/client/osupdates"
+	"github.com/dockbridge/dockbridge/client/power"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/readiness"
+	"github.com/dockbridge/dockbridge/client/schedule"
 	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"github.com/dockbridge/dockbridge/client/traffic"
+	"github.com/dockbridge/dockbridge/client/usage"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/server"
+	"github.com/dockbridge/dockbridge/server/keepalive"
 	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DockBridgeDaemon represents the main DockBridge daemon that forwards Docker socket over SSH
 type DockBridgeDaemon struct {
 	config           *DaemonConfig
 	listener         net.Listener
+	tcpListener      net.Listener
 	running          bool
 	mu               sync.RWMutex
 	logger           logger.LoggerInterface
 	clientManager    DockerClientManager
 	activityTracker  *activity.Tracker
+	sessionSource    *activity.SessionSource
+	responseCache    *responseCache
+	connState        *connectionTracker
+	requestQueue     *requestQueue
+	notifier         notify.Notifier
+	hooks            *hooks.Bus
+	readyServerID    int64
+	readyMu          sync.Mutex
+	trafficCounter   traffic.Counter
 	lifecycleManager *lifecycle.Manager
 	serverManager    *server.Manager
+	powerWatcher     power.Watcher
+	keepAlive        heartbeat.KeepAliveService
+	recoverMu        sync.Mutex // serializes recoverConnection
+	metrics          *metrics.Recorder
+	fileSync         *filesync.Manager
 	ctx              context.Context
 	cancel           context.CancelFunc
 }
 
 // DaemonConfig holds configuration for the DockBridge daemon
 type DaemonConfig struct {
+	// ContextName identifies the DockBridge context served by this daemon ("" is the default)
+	ContextName    string
 	SocketPath     string
-	HetznerClient  hetzner.HetznerClient
+	Provider       provider.CloudProvider
 	SSHConfig      *config.SSHConfig
 	HetznerConfig  *config.HetznerConfig
 	ActivityConfig *config.ActivityConfig
-	Logger         logger.LoggerInterface
+	KeepAlive      *config.KeepAliveConfig
+	// CacheTTL is how long responses of hot read endpoints are cached; zero disables caching
+	CacheTTL time.Duration
+	// Notifications configures desktop notifications; nil disables them
+	Notifications *config.DesktopNotificationsConfig
+	// UsageStore receives periodic resource usage samples of the server; nil disables sampling
+	UsageStore *usage.Store
+	// Traffic configures included-traffic warnings; nil disables them
+	Traffic *config.TrafficConfig
+	// CostStore accrues the estimated spend of the server; nil disables cost tracking
+	CostStore *cost.Store
+	// StateStore saves the connected server and its forwards so that a restarted daemon
+	// resumes them; nil disables saving
+	StateStore *daemonstate.Store
+	// Budget limits the monthly estimated spend of all contexts; nil disables it
+	Budget *config.BudgetConfig
+	// Backup configures scheduled volume backups on new servers; nil disables them
+	Backup *config.BackupConfig
+	// Schedule shuts the server down outside working hours and prewarms it before they
+	// start; nil disables it
+	Schedule *config.ScheduleConfig
+	// Provisioning holds user-supplied steps and files merged into new servers' setup
+	Provisioning *config.ProvisioningConfig
+	// Hooks are lifecycle hooks run on server, container and forward events
+	Hooks []config.HookConfig
+	// NotificationSinks receive lifecycle events in the log, webhooks, Slack or the desktop
+	NotificationSinks []config.NotificationSinkConfig
+	// DockerTLS configures mutual TLS with the remote Docker daemon; nil disables it
+	DockerTLS *config.DockerTLSConfig
+	// RemoteTransport is how the remote Docker API is reached: "tcp" (default) or "unix"
+	RemoteTransport string
+	// ServerReplacement decides whether a running server of a different type than
+	// requested is replaced; nil keeps it
+	ServerReplacement ServerReplaceFunc
+	// ReadinessProgress receives per-phase progress while a server becomes ready; nil only logs it
+	ReadinessProgress readiness.ProgressFunc
+	// ProvisioningObserver receives the phases of provisioning or resuming a server; nil
+	// disables it. Docker requests that cannot be queued meanwhile are answered with 503
+	// and the progress.
+	ProvisioningObserver provider.ProvisioningObserver
+	// PortForward forwards the published ports of remote containers to local listeners;
+	// nil disables it
+	PortForward *config.PortForwardConfig
+	// RequestQueue bounds the requests held while the server is connected, provisioned or
+	// resumed; nil answers them with 503 right away
+	RequestQueue *config.RequestQueueConfig
+	// RegistryAuth selects the registries whose credentials are forwarded to the server
+	RegistryAuth []config.RegistryAuthConfig
+
+	// RegistryMirror configures the pull-through registry cache run on new servers
+	RegistryMirror *config.RegistryMirrorConfig
+	// ServerBinary selects how new servers get dockbridge-server; nil leaves it to their
+	// setup
+	ServerBinary *config.ServerBinaryConfig
+	// BuildContextSync sends build contexts by content, skipping files sent before
+	BuildContextSync bool
+
+	// Compression compresses archives sent through the tunnel; nil disables it
+	Compression *config.CompressionConfig
+	// FileSync syncs bind mounts of local directories to the server; nil disables it
+	FileSync *config.FileSyncConfig
+	// LocalSemantics handles containers relying on bind mounts of local paths or host
+	// networking; empty disables the checks
+	LocalSemantics config.LocalSemanticsPolicy
+	// TCPListener also serves the Docker API on a TCP address; nil disables it
+	TCPListener *config.TCPListenerConfig
+	// Metrics receives the daemon's Prometheus metrics; nil disables them
+	Metrics *metrics.Registry
+	Logger  logger.LoggerInterface
 }
 
 // NewDockBridgeDaemon creates a new DockBridge daemon
@@ -94,10 +201,69 @@ func (d *DockBridgeDaemon) Start(ctx context.Context, config *DaemonConfig) erro
 	}
 	d.logger.Info("Lifecycle manager started successfully")
 
+	// Watch for suspend/resume so the tunnel and keep-alive recover deliberately
+	if err := d.powerWatcher.Start(d.ctx); err != nil {
+		return errors.Wrap(err, "failed to start power watcher")
+	}
+
+	if err := d.keepAlive.Start(d.ctx); err != nil {
+		return errors.Wrap(err, "failed to start keep-alive service")
+	}
+
+	// Sample server resource usage for server type recommendations
+	if d.config.UsageStore != nil {
+		collector := usage.NewCollector(d.sampleUsage, d.config.UsageStore, usage.DefaultSampleInterval, d.logger)
+		go collector.Run(d.ctx)
+	}
+
+	// Warn before the server's included traffic is used up
+	if d.config.Traffic != nil {
+		monitor := &traffic.Monitor{
+			Fetch:            d.serverTraffic,
+			Warn:             d.warnTraffic,
+			WarnFraction:     float64(d.config.Traffic.WarnPercent) / 100,
+			CriticalFraction: float64(d.config.Traffic.CriticalPercent) / 100,
+		}
+		go monitor.Run(d.ctx)
+	}
+
+	// Track the estimated spend of the server against the monthly budget
+	if d.config.CostStore != nil {
+		go d.newCostMeter().Run(d.ctx)
+	}
+
+	// Reboot idle servers inside the reboot window once OS updates require it
+	if osUpdates := d.config.HetznerConfig.OSUpdates; osUpdates.Enabled && osUpdates.AutoReboot {
+		window, err := osupdates.ParseWindow(osUpdates.RebootWindow)
+		if err != nil {
+			return errors.Wrap(err, "invalid OS update reboot window")
+		}
+		scheduler := &osupdates.RebootScheduler{
+			Exec:    d.clientManager.ExecuteRemoteCommand,
+			IdleFor: func() time.Duration { return time.Since(d.activityTracker.IdleSince()) },
+			Window:  window,
+			Logger:  d.logger,
+		}
+		go scheduler.Run(d.ctx)
+	}
+
+	// Keep the server available during working hours only
+	if d.config.Schedule != nil && d.config.Schedule.Enabled {
+		scheduler, err := d.newScheduler(d.config.Schedule)
+		if err != nil {
+			return errors.Wrap(err, "invalid schedule")
+		}
+		go scheduler.Run(d.ctx)
+	}
+
 	// Set up the Unix socket listener
 	if err := d.setupListener(); err != nil {
 		return errors.Wrap(err, "failed to setup listener")
 	}
+	if err := d.setupTCPListener(); err != nil {
+		d.listener.Close()
+		return errors.Wrap(err, "failed to setup TCP listener")
+	}
 
 	// Start accepting connections in a goroutine
 	go func() {
@@ -105,8 +271,11 @@ func (d *DockBridgeDaemon) Start(ctx context.Context, config *DaemonConfig) erro
 			"socket_path": d.config.SocketPath,
 		}).Info("Starting DockBridge daemon with direct socket forwarding")
 
-		d.acceptConnections()
+		d.acceptConnections(d.listener)
 	}()
+	if d.tcpListener != nil {
+		go d.acceptConnections(d.tcpListener)
+	}
 
 	d.running = true
 	d.logger.Info("DockBridge daemon started successfully")
@@ -133,6 +302,24 @@ func (d *DockBridgeDaemon) Stop() error {
 	if d.listener != nil {
 		d.listener.Close()
 	}
+	if d.tcpListener != nil {
+		d.tcpListener.Close()
+	}
+
+	// Stop syncing bind mounts; the server keeps its copies
+	if d.fileSync != nil {
+		d.fileSync.Stop()
+	}
+
+	// Stop power watcher
+	if d.powerWatcher != nil {
+		d.powerWatcher.Stop()
+	}
+
+	// Stop sending heartbeats
+	if d.keepAlive != nil {
+		d.keepAlive.Stop()
+	}
 
 	// Stop lifecycle manager
 	if d.lifecycleManager != nil {
@@ -161,12 +348,19 @@ func (d *DockBridgeDaemon) Stop() error {
 		}
 	}
 
-	// Clean up socket file
-	if err := os.RemoveAll(d.config.SocketPath); err != nil {
-		d.logger.WithFields(map[string]any{
-			"error":       err.Error(),
-			"socket_path": d.config.SocketPath,
-		}).Warn("Failed to remove socket file")
+	// Let running hooks (e.g. server_destroyed on shutdown) finish
+	if d.hooks != nil {
+		d.hooks.Wait()
+	}
+
+	// Clean up socket file; a named pipe goes away with its listener
+	if !localsocket.IsNamedPipe(d.config.SocketPath) {
+		if err := os.RemoveAll(d.config.SocketPath); err != nil {
+			d.logger.WithFields(map[string]any{
+				"error":       err.Error(),
+				"socket_path": d.config.SocketPath,
+			}).Warn("Failed to remove socket file")
+		}
 	}
 
 	d.running = false
@@ -187,7 +381,17 @@ func (d *DockBridgeDaemon) initializeComponents() error {
 	d.activityTracker = activity.NewTracker(d.config.ActivityConfig)
 
 	// Create server manager
-	d.serverManager = server.NewManager(d.config.HetznerClient, d.config.HetznerConfig)
+	d.serverManager = server.NewManagerForContext(d.config.Provider, d.config.HetznerConfig, d.config.ContextName)
+
+	// Create desktop notifier for lifecycle events
+	d.notifier = notify.NewDesktopNotifier(d.config.Notifications, d.logger)
+
+	// Create lifecycle hook bus
+	bus, err := hooks.NewBusFromConfig(d.config.ContextName, d.config.Hooks, d.config.NotificationSinks, d.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure lifecycle hooks")
+	}
+	d.hooks = bus
 
 	// Create lifecycle manager
 	d.lifecycleManager = lifecycle.NewManager(
@@ -196,28 +400,289 @@ func (d *DockBridgeDaemon) initializeComponents() error {
 		d.config.ActivityConfig,
 		d.logger,
 	)
+	d.lifecycleManager.SetNotifier(d.notifier)
+	d.lifecycleManager.SetHooks(d.hooks)
 
 	// Create Docker client manager with activity tracking
-	d.clientManager = NewDockerClientManagerWithActivity(
-		d.config.HetznerClient,
+	d.clientManager = NewDockerClientManagerForContext(
+		d.config.ContextName,
+		d.config.Provider,
 		d.config.SSHConfig,
 		d.config.HetznerConfig,
 		d.logger,
 		d.activityTracker,
 	)
+	d.clientManager.SetHooks(d.hooks)
+	if d.config.CostStore != nil && d.costBudget().Block {
+		d.clientManager.SetProvisionGuard(d.checkBudget)
+	}
+	d.clientManager.SetDockerTLS(d.config.DockerTLS)
+	d.clientManager.SetPortForwardConfig(d.config.PortForward)
+	d.clientManager.SetRemoteTransport(d.config.RemoteTransport)
+	if d.config.KeepAlive != nil {
+		d.clientManager.SetKeepAliveTransport(d.config.KeepAlive.Transport)
+	}
+	d.clientManager.SetServerBinary(d.config.ServerBinary)
+	d.clientManager.SetBackup(d.config.Backup)
+	d.clientManager.SetProvisioning(d.config.Provisioning)
+	d.clientManager.SetRegistryAuth(d.config.RegistryAuth)
+	d.clientManager.SetRegistryMirror(d.config.RegistryMirror)
+	d.clientManager.SetServerReplacement(d.config.ServerReplacement)
+	d.clientManager.SetReadinessProgress(d.config.ReadinessProgress)
+	d.clientManager.SetStateStore(d.config.StateStore)
+	d.connState = newConnectionTracker(d.config.ProvisioningObserver)
+	d.clientManager.SetProvisioningObserver(d.connState)
+
+	// Export requests, reconnects and provisioning durations when metrics are enabled
+	d.metrics = d.config.Metrics.Register(d.config.ContextName, d)
+	d.clientManager.SetMetrics(d.metrics)
+	d.connState.metrics = d.metrics
+	d.requestQueue = newRequestQueue(d.config.RequestQueue)
+
+	// Cache hot read endpoints polled by IDE integrations
+	d.responseCache = newResponseCache(d.config.CacheTTL)
+
+	// Emulate bind mounts of local directories with directories synced to the server
+	if d.config.FileSync != nil && d.config.FileSync.Enabled {
+		d.fileSync = filesync.NewManager(d.config.FileSync, d.clientManager, d.logger)
+	}
+
+	// Forget the idle server so the next Docker command re-provisions or resumes one
+	d.lifecycleManager.SetOnShutdown(d.handleIdleShutdown)
 
+	// Feed containers, TTY sessions and port-forward traffic into idle tracking
+	d.registerActivitySources()
+
+	// Create power watcher reacting to suspend/resume and network changes
+	sleepHint := 10 * time.Minute
+	if d.config.KeepAlive != nil {
+		sleepHint = d.config.KeepAlive.SleepHint
+	}
+	d.powerWatcher = power.NewWatcher(power.DefaultWatcherConfig(), d.logger)
+	d.powerWatcher.RegisterHandler(&power.KeepAliveResponder{
+		Heartbeat:  d.heartbeatSender,
+		Revalidate: d.recoverConnection,
+		SleepHint:  sleepHint,
+		// Recovery may provision a replacement for a server that destroyed itself
+		Timeout: 10 * time.Minute,
+		Logger:  d.logger,
+	})
+
+	// Send periodic heartbeats so the server's keep-alive monitor keeps it alive
+	d.keepAlive = d.newKeepAliveService()
+
+	return nil
+}
+
+// newScheduler creates the scheduler of the working hours in cfg
+func (d *DockBridgeDaemon) newScheduler(cfg *config.ScheduleConfig) (*schedule.Scheduler, error) {
+	workingHours, err := schedule.New(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule.Scheduler{
+		Schedule:     workingHours,
+		Prewarm:      cfg.Prewarm,
+		OffHoursIdle: cfg.OffHoursIdle,
+		Connected:    func() bool { return d.clientManager.CurrentServer() != nil },
+		IdleFor:      func() time.Duration { return time.Since(d.activityTracker.IdleSince()) },
+		Warm:         d.ensureConnection,
+		Hold:         func() { _ = d.activityTracker.Record(activity.ActivityTypeSchedule) },
+		Shutdown: func(reason string) error {
+			err := d.lifecycleManager.ShutdownNowWith(reason, cfg.Action)
+			if errors.Is(err, lifecycle.ErrNoServer) {
+				// Already gone, e.g. destroyed for inactivity
+				return nil
+			}
+			return err
+		},
+		Logger: d.logger,
+	}, nil
+}
+
+// handleIdleShutdown drops state tied to a server that was destroyed or powered off for inactivity
+func (d *DockBridgeDaemon) handleIdleShutdown() {
+	// The server is gone on purpose; heartbeats must not report it lost
+	d.keepAlive.Pause()
+	d.clientManager.Disconnect()
+	if d.responseCache != nil {
+		d.responseCache.invalidate()
+	}
+	if d.fileSync != nil {
+		d.fileSync.Stop()
+	}
+
+	// A resumed server keeps its ID but should still be announced as ready
+	d.readyMu.Lock()
+	d.readyServerID = 0
+	d.readyMu.Unlock()
+
+	d.connState.markIdle()
+}
+
+// ensureConnection connects to the remote server, provisioning one if needed. Requests
+// arriving while another one connects are queued and proceed once it is done.
+func (d *DockBridgeDaemon) ensureConnection(ctx context.Context) (err error) {
+	ctx, span := telemetry.Start(ctx, "dockbridge.ensure_connection",
+		attribute.String("dockbridge.connection_state", d.connState.current().state.String()))
+	defer func() { telemetry.End(span, err) }()
+
+	return d.requestQueue.run(ctx, func() error {
+		return d.connect(ctx)
+	})
+}
+
+// connect connects to the remote server, provisioning one if needed, and notifies the
+// user when a new server becomes ready or provisioning fails
+func (d *DockBridgeDaemon) connect(ctx context.Context) error {
+	if err := d.clientManager.EnsureConnection(ctx); err != nil {
+		d.connState.markFailed(err)
+		d.notifier.Notify(notify.EventProvisioningFailed, d.notificationTitle("Remote server unavailable"),
+			fmt.Sprintf("Failed to connect to or provision a remote server: %v", err))
+		return err
+	}
+
+	d.connState.markReady()
+	d.keepAlive.Resume()
+
+	srv := d.clientManager.CurrentServer()
+	if srv == nil {
+		return nil
+	}
+
+	d.readyMu.Lock()
+	isNew := d.readyServerID != srv.ID
+	d.readyServerID = srv.ID
+	d.readyMu.Unlock()
+
+	if isNew {
+		d.notifier.Notify(notify.EventServerReady, d.notificationTitle("Remote server ready"),
+			fmt.Sprintf("Server %s (%s) is ready for Docker commands", srv.Name, srv.IPAddress))
+		if d.fileSync != nil {
+			go d.resumeFileSync()
+		}
+	}
 	return nil
 }
 
-// setupListener configures the Unix socket listener
+// sampleUsage samples resource usage of the connected server; it returns nil when no
+// server is connected so sampling never provisions or reconnects
+func (d *DockBridgeDaemon) sampleUsage(ctx context.Context) (*usage.Sample, error) {
+	if d.clientManager.CurrentServer() == nil {
+		return nil, nil
+	}
+
+	sampleCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	output, err := d.clientManager.ExecuteRemoteCommand(sampleCtx, usage.SampleCommand)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to run usage sampling command")
+	}
+
+	sample, err := usage.ParseSample(string(output))
+	if err != nil {
+		return nil, err
+	}
+	sample.Context = d.config.ContextName
+	sample.ServerType = d.config.HetznerConfig.ServerType
+	return sample, nil
+}
+
+// serverTraffic returns the billing-period traffic of the connected server, or nil if none
+func (d *DockBridgeDaemon) serverTraffic(ctx context.Context) (*traffic.Usage, error) {
+	srv := d.clientManager.CurrentServer()
+	if srv == nil {
+		return nil, nil
+	}
+
+	fresh, err := d.config.Provider.GetServer(ctx, strconv.FormatInt(srv.ID, 10))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get server traffic")
+	}
+
+	return &traffic.Usage{
+		ServerID:   fresh.ID,
+		ServerName: fresh.Name,
+		Outgoing:   fresh.OutgoingTraffic,
+		Ingoing:    fresh.IngoingTraffic,
+		Included:   fresh.IncludedTraffic,
+	}, nil
+}
+
+// warnTraffic reports that a server is approaching its included traffic
+func (d *DockBridgeDaemon) warnTraffic(level traffic.Level, usage traffic.Usage) {
+	d.logger.WithFields(map[string]any{
+		"server_name":     usage.ServerName,
+		"level":           level.String(),
+		"outgoing":        traffic.FormatBytes(usage.Outgoing),
+		"included":        traffic.FormatBytes(usage.Included),
+		"tunnel_sent":     traffic.FormatBytes(d.trafficCounter.Sent()),
+		"tunnel_received": traffic.FormatBytes(d.trafficCounter.Received()),
+	}).Warn("Server is approaching its included traffic allowance")
+
+	d.notifier.Notify(notify.EventTrafficWarning, d.notificationTitle("Traffic allowance "+level.String()),
+		fmt.Sprintf("Server %s used %s; overage is billed beyond the included traffic", usage.ServerName, usage))
+}
+
+// notificationTitle prefixes a notification title with the daemon's context, if any
+func (d *DockBridgeDaemon) notificationTitle(title string) string {
+	if d.config.ContextName == "" {
+		return "DockBridge: " + title
+	}
+	return fmt.Sprintf("DockBridge [%s]: %s", d.config.ContextName, title)
+}
+
+// heartbeatSender returns a heartbeat client for the connected server, or nil
+func (d *DockBridgeDaemon) heartbeatSender() power.HeartbeatSender {
+	srv := d.clientManager.CurrentServer()
+	if srv == nil || srv.IPAddress == "" {
+		return nil
+	}
+	var client *keepalive.HeartbeatClient
+	if d.config.KeepAlive != nil && d.config.KeepAlive.Transport == KeepAliveTransportSSH {
+		// The monitor only listens on the server's loopback interface
+		client = keepalive.NewHeartbeatClient(fmt.Sprintf("http://127.0.0.1:%d", defaultKeepAlivePort)).WithDialer(d.clientManager.DialRemote)
+	} else {
+		client = keepalive.NewHeartbeatClient(fmt.Sprintf("http://%s", net.JoinHostPort(srv.IPAddress, strconv.Itoa(defaultKeepAlivePort))))
+	}
+	return client.WithAuthToken(KeepAliveToken(srv.Name)).WithClientID(d.heartbeatClientID())
+}
+
+// heartbeatClientID returns the ID the server tells this client's heartbeats apart by.
+// The host name is sanitized, as the server rejects heartbeats with invalid IDs.
+func (d *DockBridgeDaemon) heartbeatClientID() string {
+	if d.config.KeepAlive != nil && d.config.KeepAlive.ClientID != "" {
+		return d.config.KeepAlive.ClientID
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return keepalive.SanitizeClientID(hostname)
+}
+
+// defaultKeepAlivePort is the port the server-side keep-alive monitor listens on
+const defaultKeepAlivePort = 8080
+
+// setupListener configures the Unix socket listener, or the named pipe listener on Windows
 func (d *DockBridgeDaemon) setupListener() error {
+	// Named pipes are only accessible to the current user, administrators and the system
+	var err error
+	if localsocket.IsNamedPipe(d.config.SocketPath) {
+		d.listener, err = localsocket.ListenPipe(d.config.SocketPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to create listener")
+		}
+		return nil
+	}
+
 	// Remove existing socket file if it exists
 	if err := os.RemoveAll(d.config.SocketPath); err != nil {
 		return errors.Wrap(err, "failed to remove existing socket file")
 	}
 
 	// Create listener for Unix socket
-	var err error
 	if strings.HasPrefix(d.config.SocketPath, "/") {
 		// Unix socket
 		d.listener, err = net.Listen("unix", d.config.SocketPath)
@@ -233,16 +698,16 @@ func (d *DockBridgeDaemon) setupListener() error {
 			}).Warn("Failed to set socket permissions")
 		}
 	} else {
-		return errors.New("only Unix socket paths are supported for daemon mode")
+		return errors.New("only Unix socket paths and Windows named pipes are supported for daemon mode")
 	}
 
 	return nil
 }
 
-// acceptConnections accepts and handles incoming connections
-func (d *DockBridgeDaemon) acceptConnections() {
+// acceptConnections accepts and handles incoming connections on a listener
+func (d *DockBridgeDaemon) acceptConnections(listener net.Listener) {
 	for {
-		conn, err := d.listener.Accept()
+		conn, err := listener.Accept()
 		if err != nil {
 			select {
 			case <-d.ctx.Done():
@@ -277,16 +742,81 @@ func (d *DockBridgeDaemon) handleConnection(localConn net.Conn) {
 		"remote":  localConn.RemoteAddr(),
 	}).Info("🐳 New Docker connection - establishing remote server connection...")
 
+	// Every Docker API connection counts as activity
+	if err := d.activityTracker.RecordAPIActivity(); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to record API activity")
+	}
+
+	// Read the first request line to classify the connection; it is replayed to the remote side
+	localReader := bufio.NewReader(localConn)
+	requestLine, err := localReader.ReadString('\n')
+	if err != nil && requestLine == "" {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Connection closed before sending a request")
+		return
+	}
+	if isInteractiveRequest(requestLine) {
+		d.sessionSource.Begin()
+		defer d.sessionSource.End()
+	}
+
+	// The state before connecting tells a lost connection from a failed provisioning
+	before := d.connState.current().state
+
+	// Trace the request from the local socket through the tunnel to the remote daemon
+	method, target := parseRequestLine(requestLine)
+	ctx, span := d.startRequestSpan(method, target)
+	defer span.End()
+
+	// Serve hot read endpoints from the cache; anything that may change remote state invalidates it
+	if d.responseCache != nil {
+		if isCacheableTarget(method, target) {
+			d.serveCacheable(ctx, localConn, io.MultiReader(strings.NewReader(requestLine), localReader), connID)
+			return
+		}
+		if isMutatingMethod(method) {
+			d.responseCache.invalidate()
+		}
+	}
+
+	// Build contexts only send the files the server does not have yet
+	if d.buildContextSyncEnabled() && isBuildSyncTarget(method, target) {
+		d.serveBuildSync(ctx, localConn, io.MultiReader(strings.NewReader(requestLine), localReader), connID)
+		return
+	}
+
+	// Archives are compressed through the tunnel
+	if d.compressionEnabled() && isCompressedUpload(method, target) {
+		d.serveCompressedUpload(ctx, localConn, io.MultiReader(strings.NewReader(requestLine), localReader), connID)
+		return
+	}
+	if d.compressionEnabled() && isCompressedDownload(method, target) {
+		d.serveCompressedDownload(ctx, localConn, io.MultiReader(strings.NewReader(requestLine), localReader), connID)
+		return
+	}
+
+	// Responses naming published ports report the local ports they are forwarded on, bind
+	// mounts of local directories are synced to the server and containers relying on this
+	// machine are warned about
+	if d.isInterceptedRequest(method, target) {
+		d.serveIntercepted(ctx, localConn, io.MultiReader(strings.NewReader(requestLine), localReader), connID)
+		return
+	}
+
 	// Ensure we have a connection to remote server
-	if err := d.clientManager.EnsureConnection(d.ctx); err != nil {
+	if err := d.ensureConnection(ctx); err != nil {
 		d.logger.WithFields(map[string]any{
 			"conn_id": connID,
 			"error":   err.Error(),
 		}).Error("❌ Failed to ensure connection to remote server")
+		span.SetStatus(codes.Error, err.Error())
 
-		// Send a helpful error message to the client
-		errorMsg := fmt.Sprintf("Failed to connect to remote Docker server: %v\n", err)
-		localConn.Write([]byte(errorMsg))
+		d.writeConnectionError(localConn, connID, before, err)
 		return
 	}
 
@@ -297,21 +827,30 @@ func (d *DockBridgeDaemon) handleConnection(localConn net.Conn) {
 			"conn_id": connID,
 			"error":   err.Error(),
 		}).Error("Failed to get SSH tunnel")
+		span.SetStatus(codes.Error, err.Error())
+		d.writeError(localConn, connID, func(w io.Writer) error {
+			return writeDockerError(w, http.StatusBadGateway, fmt.Sprintf("DockBridge has no tunnel to the remote server: %v", err))
+		})
 		return
 	}
 
 	// Create connection to remote Docker daemon via SSH tunnel
-	remoteConn, err := net.Dial("tcp", tunnel.LocalAddr())
+	dialCtx, cancelDial := context.WithTimeout(ctx, 30*time.Second)
+	dialCtx, dialSpan := telemetry.Start(dialCtx, "dockbridge.tunnel.dial")
+	remoteConn, err := d.clientManager.DialDocker(dialCtx)
+	telemetry.End(dialSpan, err)
+	cancelDial()
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		d.logger.WithFields(map[string]any{
 			"conn_id":     connID,
 			"tunnel_addr": tunnel.LocalAddr(),
 			"error":       err.Error(),
 		}).Error("❌ Failed to connect to remote Docker daemon via SSH tunnel")
 
-		// Send a helpful error message to the client
-		errorMsg := fmt.Sprintf("Failed to connect to Docker daemon: %v\n", err)
-		localConn.Write([]byte(errorMsg))
+		d.writeError(localConn, connID, func(w io.Writer) error {
+			return writeDockerError(w, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+		})
 		return
 	}
 	defer func() {
@@ -325,23 +864,131 @@ func (d *DockBridgeDaemon) handleConnection(localConn net.Conn) {
 		"conn_id":     connID,
 		"tunnel_addr": tunnel.LocalAddr(),
 	}).Info("Connected to remote Docker daemon via SSH tunnel")
+	d.metrics.RequestProxied("remote")
+
+	// Only this request was classified; the connection ends with its response
+	request, err := closingRequest(requestLine, localReader)
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to read request headers")
+		return
+	}
 
 	// Relay traffic bidirectionally using pure byte copying
-	d.relayTraffic(localConn, remoteConn, connID)
+	d.relayTraffic(ctx, localConn, request, remoteConn, connID)
 
 	d.logger.WithFields(map[string]any{
 		"conn_id": connID,
 	}).Info("Docker connection terminated")
 }
 
-// relayTraffic performs bidirectional byte copying between connections
-func (d *DockBridgeDaemon) relayTraffic(local, remote net.Conn, connID string) {
-	done := make(chan struct{}, 2)
+// writeConnectionError answers a request whose connection attempt failed with a Docker
+// API error. Requests that could not be queued get the provisioning progress if any.
+func (d *DockBridgeDaemon) writeConnectionError(localConn net.Conn, connID string, before connectionState, err error) {
+	if !isQueueError(err) {
+		d.writeError(localConn, connID, func(w io.Writer) error {
+			return writeDockerError(w, http.StatusServiceUnavailable, connectionErrorMessage(before, err))
+		})
+		return
+	}
+
+	snapshot := d.connState.current()
+	d.logger.WithFields(map[string]any{
+		"conn_id": connID,
+		"state":   snapshot.state.String(),
+		"waiting": d.requestQueue.waiting(),
+		"reason":  err.Error(),
+	}).Info("Request not queued, asking client to retry")
+
+	d.writeError(localConn, connID, func(w io.Writer) error {
+		if snapshot.state == stateProvisioning {
+			return writeProvisioningResponse(w, snapshot.progress)
+		}
+		return writeBusyResponse(w)
+	})
+}
+
+// writeError answers a local connection with an error response, logging write failures
+func (d *DockBridgeDaemon) writeError(localConn net.Conn, connID string, write func(w io.Writer) error) {
+	if err := write(localConn); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to write error response")
+	}
+}
+
+// maxRequestHeaderBytes bounds the request headers read by closingRequest
+const maxRequestHeaderBytes = 1 << 20
+
+// closingRequest returns the request starting with requestLine, whose headers and body
+// follow in localReader, for relaying to the remote daemon. Connections are classified
+// by their first request only, so the request asks the daemon to close the connection
+// after its response: the client sends its next request, such as a container create
+// that must be intercepted, on a new connection. Requests upgrading the connection to a
+// raw stream, as attach and exec do, are relayed unchanged.
+func closingRequest(requestLine string, localReader *bufio.Reader) (io.Reader, error) {
+	var sent, relayed strings.Builder
+	sent.WriteString(requestLine)
+	relayed.WriteString(requestLine)
+	upgrade := false
+	for {
+		line, err := localReader.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read request headers")
+		}
+		if sent.Len()+len(line) > maxRequestHeaderBytes {
+			return nil, errors.New("request headers too large")
+		}
+		sent.WriteString(line)
+		if line == "\r\n" || line == "\n" {
+			break
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, "Upgrade"):
+			upgrade = true
+		case strings.EqualFold(name, "Connection"):
+			for _, token := range strings.Split(value, ",") {
+				upgrade = upgrade || strings.EqualFold(strings.TrimSpace(token), "upgrade")
+			}
+			continue
+		case strings.EqualFold(name, "Keep-Alive"):
+			continue
+		}
+		relayed.WriteString(line)
+	}
+
+	if upgrade {
+		return io.MultiReader(strings.NewReader(sent.String()), localReader), nil
+	}
+	relayed.WriteString("Connection: close\r\n\r\n")
+	return io.MultiReader(strings.NewReader(relayed.String()), localReader), nil
+}
+
+// relayTraffic performs bidirectional byte copying between connections.
+// localReader supplies the local side's data, including any bytes already consumed from local.
+// The bytes relayed and the arrival of the first response byte are recorded on the span in ctx.
+//
+// Hijacked attach and exec connections carry stdin and the output of the container in
+// both directions: when the client closes its sending side at the end of stdin, the
+// close is passed on to the remote daemon and the relay continues until the remaining
+// output has been delivered.
+func (d *DockBridgeDaemon) relayTraffic(ctx context.Context, local net.Conn, localReader io.Reader, remote net.Conn, connID string) {
+	localDone := make(chan struct{})
+	remoteDone := make(chan struct{})
+	halfClosed := false
+	span := trace.SpanFromContext(ctx)
 
 	// Copy from local to remote
 	go func() {
-		defer func() { done <- struct{}{} }()
-		bytes, err := io.Copy(remote, local)
+		defer close(localDone)
+		bytes, err := io.Copy(d.trafficCounter.SendWriter(remote), localReader)
+		span.SetAttributes(attribute.Int64("dockbridge.bytes_sent", bytes))
 		if err != nil && err != io.EOF {
 			d.logger.WithFields(map[string]any{
 				"conn_id": connID,
@@ -353,13 +1000,15 @@ func (d *DockBridgeDaemon) relayTraffic(local, remote net.Conn, connID string) {
 				"conn_id": connID,
 				"bytes":   bytes,
 			}).Debug("Local->Remote copy completed")
+			halfClosed = closeWrite(remote)
 		}
 	}()
 
 	// Copy from remote to local
 	go func() {
-		defer func() { done <- struct{}{} }()
-		bytes, err := io.Copy(local, remote)
+		defer close(remoteDone)
+		bytes, err := io.Copy(&firstByteWriter{Writer: d.trafficCounter.ReceiveWriter(local), span: span}, remote)
+		span.SetAttributes(attribute.Int64("dockbridge.bytes_received", bytes))
 		if err != nil && err != io.EOF {
 			d.logger.WithFields(map[string]any{
 				"conn_id": connID,
@@ -374,13 +1023,35 @@ func (d *DockBridgeDaemon) relayTraffic(local, remote net.Conn, connID string) {
 		}
 	}()
 
-	// Wait for either direction to complete
-	<-done
+	// Wait for the remote side to finish; the local side finishing first only ends the
+	// relay when its end could not be passed on
+	select {
+	case <-remoteDone:
+	case <-localDone:
+		if halfClosed {
+			select {
+			case <-remoteDone:
+			case <-ctx.Done():
+			}
+		}
+	}
 	d.logger.WithFields(map[string]any{
 		"conn_id": connID,
 	}).Debug("Traffic relay completed")
 }
 
+// closeWriter is a connection whose sending side can be closed on its own
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// closeWrite closes the sending side of conn when supported, so the peer reads EOF
+// while its output can still be received. It reports whether the side was closed.
+func closeWrite(conn net.Conn) bool {
+	cw, ok := conn.(closeWriter)
+	return ok && cw.CloseWrite() == nil
+}
+
 // getTunnelFromClientManager extracts the SSH tunnel from the client manager
 func (d *DockBridgeDaemon) getTunnelFromClientManager() (TunnelInterface, error) {
 	tunnel := d.clientManager.GetTunnel()
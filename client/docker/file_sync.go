@@ -0,0 +1,47 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// isFileSyncTarget reports whether a request creates or removes a container, whose bind
+// mounts of local directories are synced to the server
+func isFileSyncTarget(method, target string) bool {
+	path := interceptPath(target)
+	switch method {
+	case http.MethodPost:
+		return path == "/containers/create"
+	case http.MethodDelete:
+		id, ok := strings.CutPrefix(path, "/containers/")
+		return ok && id != "" && !strings.Contains(id, "/")
+	default:
+		return false
+	}
+}
+
+// createdContainerID returns the ID of a container create response
+func createdContainerID(response []byte) string {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.Unmarshal(response, &created); err != nil {
+		return ""
+	}
+	return created.ID
+}
+
+// resumeFileSync resumes syncing the bind mounts of containers on a newly connected server
+func (d *DockBridgeDaemon) resumeFileSync() {
+	ctx, cancel := context.WithTimeout(d.ctx, time.Minute)
+	defer cancel()
+
+	if err := d.fileSync.Resume(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to resume file sync")
+	}
+}
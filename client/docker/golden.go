@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+)
+
+// goldenImageTimeout bounds how long snapshotting a new server may take
+const goldenImageTimeout = 30 * time.Minute
+
+// clearKeepAliveStateCommand removes the heartbeat state of dockbridge-server, which
+// servers booted from the image must not inherit
+const clearKeepAliveStateCommand = "rm -f /var/lib/dockbridge/keepalive-state.json && sync"
+
+// goldenImager returns the provider's golden image support, or nil if golden images
+// are disabled or unsupported
+func (dcm *dockerClientManagerImpl) goldenImager() provider.GoldenImager {
+	if dcm.hetznerConfig == nil || !dcm.hetznerConfig.GoldenImage.Enabled {
+		return nil
+	}
+	imager, _ := dcm.cloudProvider.(provider.GoldenImager)
+	return imager
+}
+
+// goldenImageFor returns the golden image new servers boot from, or nil to run the
+// full installation. Lookup errors are logged rather than failing provisioning.
+func (dcm *dockerClientManagerImpl) goldenImageFor(ctx context.Context) *provider.Image {
+	imager := dcm.goldenImager()
+	if imager == nil {
+		return nil
+	}
+
+	image, err := imager.GoldenImage(ctx, dcm.hetznerConfig.ServerType)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to look up golden image, installing Docker from scratch")
+		return nil
+	}
+	if image != nil {
+		dcm.logger.WithFields(map[string]any{
+			"image_id":   image.ID,
+			"image_name": image.Name,
+		}).Info("Provisioning server from golden image")
+	}
+	return image
+}
+
+// startGoldenImageBuild snapshots a freshly set-up server in the background so that
+// later servers can boot from it. At most one build runs at a time.
+func (dcm *dockerClientManagerImpl) startGoldenImageBuild(server *provider.Server) {
+	imager := dcm.goldenImager()
+	if imager == nil || !dcm.goldenBuilding.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer dcm.goldenBuilding.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), goldenImageTimeout)
+		defer cancel()
+
+		// Another client may have built one in the meantime
+		if existing, err := imager.GoldenImage(ctx, dcm.hetznerConfig.ServerType); err == nil && existing != nil {
+			return
+		}
+
+		dcm.logger.WithFields(map[string]any{
+			"server_id":   server.ID,
+			"server_name": server.Name,
+		}).Info("Creating golden image from new server")
+
+		dcm.clearKeepAliveState(ctx, server)
+
+		image, err := imager.CreateGoldenImage(ctx, strconv.FormatInt(server.ID, 10))
+		if err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"server_id": server.ID,
+				"error":     err.Error(),
+			}).Warn("Failed to create golden image")
+			return
+		}
+
+		dcm.logger.WithFields(map[string]any{
+			"image_id":   image.ID,
+			"image_name": image.Name,
+		}).Info("Golden image created")
+	}()
+}
+
+// clearKeepAliveState deletes the keep-alive state file of server before it is
+// snapshotted. dockbridge-server also discards state saved on another server or boot, so
+// a failure only costs the image a stale file.
+func (dcm *dockerClientManagerImpl) clearKeepAliveState(ctx context.Context, server *provider.Server) {
+	session := &probeSession{config: dcm.readinessSSHConfig(server)}
+	defer session.Close()
+
+	if _, err := session.run(ctx, clearKeepAliveStateCommand); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_id": server.ID,
+			"error":     err.Error(),
+		}).Warn("Failed to clear keep-alive state before creating golden image")
+	}
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenProvider is a provider with golden image support backed by the mock
+type goldenProvider struct {
+	*MockHetznerClient
+	image   *provider.Image
+	created chan string
+}
+
+func (p *goldenProvider) GoldenImage(ctx context.Context, serverType string) (*provider.Image, error) {
+	return p.image, nil
+}
+
+func (p *goldenProvider) CreateGoldenImage(ctx context.Context, serverID string) (*provider.Image, error) {
+	p.created <- serverID
+	return &provider.Image{ID: 9, Name: provider.GoldenImageName(provider.GoldenImageVersion)}, nil
+}
+
+func (p *goldenProvider) ListGoldenImages(ctx context.Context) ([]*provider.Image, error) {
+	return nil, nil
+}
+
+func (p *goldenProvider) DeleteGoldenImages(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestGoldenImageFor(t *testing.T) {
+	image := &provider.Image{ID: 7, Name: "dockbridge-golden-v1"}
+	cloudProvider := &goldenProvider{MockHetznerClient: &MockHetznerClient{}, image: image}
+
+	// Golden images are opt-in
+	dcm := NewDockerClientManager(cloudProvider, &config.SSHConfig{}, &config.HetznerConfig{ServerType: "cpx21"}, logger.NewDefault()).(*dockerClientManagerImpl)
+	assert.Nil(t, dcm.goldenImageFor(context.Background()))
+
+	dcm.hetznerConfig.GoldenImage.Enabled = true
+	assert.Equal(t, image, dcm.goldenImageFor(context.Background()))
+
+	// Providers without golden image support always install from scratch
+	dcm.cloudProvider = &MockHetznerClient{}
+	assert.Nil(t, dcm.goldenImageFor(context.Background()))
+}
+
+func TestStartGoldenImageBuild(t *testing.T) {
+	cloudProvider := &goldenProvider{MockHetznerClient: &MockHetznerClient{}, created: make(chan string, 1)}
+	hetznerConfig := &config.HetznerConfig{ServerType: "cpx21", GoldenImage: config.GoldenImageConfig{Enabled: true}}
+	dcm := NewDockerClientManager(cloudProvider, &config.SSHConfig{}, hetznerConfig, logger.NewDefault()).(*dockerClientManagerImpl)
+
+	dcm.startGoldenImageBuild(&provider.Server{ID: 42, Name: "dockbridge-1"})
+
+	select {
+	case serverID := <-cloudProvider.created:
+		assert.Equal(t, "42", serverID)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "golden image was not created")
+	}
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/portforward"
+)
+
+// SetHooks sets the publisher for server, container and forward hook events
+func (dcm *dockerClientManagerImpl) SetHooks(publisher hooks.Publisher) {
+	dcm.hooks = publisher
+}
+
+// publish sends an event to the configured hooks, if any
+func (dcm *dockerClientManagerImpl) publish(event hooks.Event) {
+	if dcm.hooks != nil {
+		dcm.hooks.Publish(event)
+	}
+}
+
+// publishForwardAdded emits the forward_added hook event
+func (dcm *dockerClientManagerImpl) publishForwardAdded(forward *portforward.PortForward) {
+	data := map[string]string{
+		"forward_id":     forward.ID,
+		"forward_type":   string(forward.Type),
+		"container_id":   forward.ContainerID,
+		"container_name": forward.ContainerName,
+	}
+	if forward.Type == portforward.ForwardTypeUnix {
+		data["local_socket"] = forward.LocalSocket
+		data["remote_socket"] = forward.RemoteSocket
+	} else {
+		data["local_port"] = strconv.Itoa(forward.LocalPort)
+		data["remote_port"] = strconv.Itoa(forward.RemotePort)
+	}
+	dcm.publish(hooks.NewEvent(hooks.EventForwardAdded, data))
+}
+
+// containerHookHandler emits container_created hook events from the container monitor
+type containerHookHandler struct {
+	publish func(event hooks.Event)
+}
+
+// OnContainerCreated emits the container_created hook event
+func (h *containerHookHandler) OnContainerCreated(container *monitor.ContainerInfo) error {
+	h.publish(hooks.NewEvent(hooks.EventContainerCreated, map[string]string{
+		"container_id":   container.ID,
+		"container_name": container.Name,
+		"image":          container.Image,
+	}))
+	return nil
+}
+
+// OnContainerStopped is a no-op; there is no hook event for stopped containers
+func (h *containerHookHandler) OnContainerStopped(containerID string) error { return nil }
+
+// OnContainerRemoved is a no-op; there is no hook event for removed containers
+func (h *containerHookHandler) OnContainerRemoved(containerID string) error { return nil }
+
+// OnContainerHealthChanged is a no-op; there is no hook event for container health
+func (h *containerHookHandler) OnContainerHealthChanged(containerID string, health monitor.HealthStatus) error {
+	return nil
+}
+
+// OnContainerRestarted is a no-op; there is no hook event for restarted containers
+func (h *containerHookHandler) OnContainerRestarted(container *monitor.ContainerInfo) error {
+	return nil
+}
+
+// Ensure containerHookHandler implements ContainerEventHandler
+var _ monitor.ContainerEventHandler = (*containerHookHandler)(nil)
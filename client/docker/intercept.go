@@ -0,0 +1,191 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/filesync"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// maxInterceptedRequestBody bounds the container create request read into memory
+const maxInterceptedRequestBody = 4 << 20
+
+// isInterceptedRequest reports whether a request is served by serveIntercepted
+func (d *DockBridgeDaemon) isInterceptedRequest(method, target string) bool {
+	switch {
+	case d.portForwardingEnabled() && isInterceptedTarget(method, target):
+	case d.fileSync != nil && isFileSyncTarget(method, target):
+	case d.localSemantics() != config.LocalSemanticsIgnore && isContainerCreate(method, target):
+	default:
+		return false
+	}
+	return true
+}
+
+// isInterceptedTarget reports whether the response to a request names published ports,
+// which must be rewritten to the local ports they are forwarded on
+func isInterceptedTarget(method, target string) bool {
+	path := interceptPath(target)
+	switch method {
+	case http.MethodGet:
+		return path == "/containers/json" || isContainerInspectPath(path)
+	case http.MethodPost:
+		return path == "/containers/create"
+	default:
+		return false
+	}
+}
+
+// interceptPath strips the API version prefix and query of a request target
+func interceptPath(target string) string {
+	path, _, _ := strings.Cut(target, "?")
+	return apiVersionPrefix.ReplaceAllString(path, "/")
+}
+
+// isContainerInspectPath reports whether path is /containers/{id}/json
+func isContainerInspectPath(path string) bool {
+	id, ok := strings.CutPrefix(path, "/containers/")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, "/json")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+// portForwardingEnabled reports whether published ports are forwarded locally
+func (d *DockBridgeDaemon) portForwardingEnabled() bool {
+	return d.config != nil && d.config.PortForward != nil && d.config.PortForward.Enabled
+}
+
+// serveIntercepted answers a single container create, inspect, list or remove request
+// from the remote daemon with published ports rewritten by the port forwarding conflict
+// strategy, bind mounts of synced local directories replaced by their copies on the
+// server and containers relying on the local machine warned about or rejected, then closes the connection so the client starts a fresh one for its next request.
+func (d *DockBridgeDaemon) serveIntercepted(ctx context.Context, localConn net.Conn, reader io.Reader, connID string) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to parse intercepted request")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxInterceptedRequestBody))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to read intercepted request body")
+		return
+	}
+
+	before := d.connState.current().state
+	if err := d.ensureConnection(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to ensure connection to remote server")
+		d.writeConnectionError(localConn, connID, before, err)
+		return
+	}
+
+	path := interceptPath(req.URL.RequestURI())
+	create := isContainerCreate(req.Method, req.URL.RequestURI())
+	var synced []*filesync.Session
+	if d.fileSync != nil && create {
+		if body, synced, err = d.fileSync.Prepare(ctx, body); err != nil {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"error":   err.Error(),
+			}).Error("❌ Failed to sync bind mounts to remote server")
+			_ = writeDockerError(localConn, http.StatusInternalServerError, fmt.Sprintf("DockBridge could not sync bind mounts to the remote server: %v", err))
+			return
+		}
+	}
+
+	// Containers relying on this machine are explained or rejected before they are created
+	var warnings []string
+	if policy := d.localSemantics(); create && policy != config.LocalSemanticsIgnore {
+		sources := make(map[string]bool, len(synced))
+		for _, s := range synced {
+			sources[s.Mount().Source()] = true
+		}
+		warnings = localSemanticsIssues(body, sources, d.fileSync != nil)
+		if len(warnings) > 0 && policy == config.LocalSemanticsBlock {
+			if len(synced) > 0 {
+				d.fileSync.Discard(ctx, synced)
+			}
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"issues":  warnings,
+			}).Warn("Blocked container relying on the local machine")
+			_ = writeDockerError(localConn, http.StatusBadRequest, fmt.Sprintf("DockBridge blocked this container (docker.local_semantics is 'block'): %s", strings.Join(warnings, "; ")))
+			return
+		}
+	}
+
+	resp, err := d.fetchFromRemote(ctx, req, body)
+	if err != nil {
+		if len(synced) > 0 {
+			d.fileSync.Discard(ctx, synced)
+		}
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Error("❌ Failed to fetch Docker API response from remote server")
+		_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+		return
+	}
+	d.metrics.RequestProxied("remote")
+
+	succeeded := resp.statusCode >= http.StatusOK && resp.statusCode < http.StatusMultipleChoices
+	if d.fileSync != nil {
+		switch {
+		case len(synced) > 0 && succeeded:
+			d.fileSync.Attach(createdContainerID(resp.body), req.URL.Query().Get("name"), synced)
+		case len(synced) > 0:
+			d.fileSync.Discard(ctx, synced)
+		case req.Method == http.MethodDelete && succeeded:
+			// Copy back what the container wrote last once the client has its answer
+			defer d.fileSync.Remove(d.ctx, strings.TrimPrefix(path, "/containers/"))
+		}
+	}
+
+	if succeeded {
+		rewritten, err := d.clientManager.InterceptDockerResponse(req.Method, path, body, resp.body)
+		var apiErr *portforward.DockerAPIError
+		switch {
+		case errors.As(err, &apiErr):
+			_ = writeDockerError(localConn, http.StatusInternalServerError, apiErr.Message)
+			return
+		case err != nil:
+			// Serve the response as the remote daemon sent it rather than failing the request
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"error":   err.Error(),
+			}).Warn("Failed to rewrite published ports")
+		default:
+			resp.body = rewritten
+		}
+
+		if len(warnings) > 0 {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"issues":  warnings,
+			}).Warn("Container relies on the local machine")
+			if withWarnings, err := portforward.AddCreateWarnings(resp.body, warnings); err == nil {
+				resp.body = withWarnings
+			}
+		}
+	}
+	_ = writeCachedResponse(localConn, req, resp)
+}
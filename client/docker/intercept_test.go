@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsInterceptedTarget(t *testing.T) {
+	assert.True(t, isInterceptedTarget(http.MethodGet, "/v1.43/containers/json?all=1"))
+	assert.True(t, isInterceptedTarget(http.MethodGet, "/containers/abc/json"))
+	assert.True(t, isInterceptedTarget(http.MethodPost, "/v1.43/containers/create?name=web"))
+	assert.False(t, isInterceptedTarget(http.MethodGet, "/containers/abc/logs"))
+	assert.False(t, isInterceptedTarget(http.MethodPost, "/containers/abc/start"))
+	assert.False(t, isInterceptedTarget(http.MethodGet, "/images/json"))
+}
+
+// interceptManager returns a client manager whose forwards only claim local ports
+func interceptManager(t *testing.T, strategy config.ConflictStrategy) *dockerClientManagerImpl {
+	t.Helper()
+	cfg := &config.PortForwardConfig{Enabled: true, ConflictStrategy: strategy}
+	manager := portforward.NewPortForwardManager(cfg, logger.NewDefault())
+	require.NoError(t, manager.Start(context.Background()))
+	t.Cleanup(func() { manager.Stop() })
+
+	return &dockerClientManagerImpl{
+		logger:             logger.NewDefault(),
+		portForwardConfig:  cfg,
+		portForwardManager: manager,
+	}
+}
+
+func TestInterceptDockerResponse_RewritesInspect(t *testing.T) {
+	dcm := interceptManager(t, config.ConflictStrategyAuto)
+	port := 18080
+
+	// Both containers publish the same port, so the second forward moves to another one
+	for _, id := range []string{"first", "abc"} {
+		require.NoError(t, dcm.portForwardManager.OnContainerCreated(&monitor.ContainerInfo{
+			ID:    id,
+			Ports: []monitor.PortMapping{{ContainerPort: 80, HostPort: port, Protocol: "tcp"}},
+		}))
+	}
+	local, ok := dcm.portForwardManager.LocalBinding("abc", 80, "tcp")
+	require.True(t, ok)
+	require.NotEqual(t, port, local.HostPort)
+
+	body := []byte(`{"Id":"abc","NetworkSettings":{"Ports":{"80/tcp":[{"HostIp":"0.0.0.0","HostPort":"` + strconv.Itoa(port) + `"}]}}}`)
+	rewritten, err := dcm.InterceptDockerResponse(http.MethodGet, "/containers/abc/json", nil, body)
+	require.NoError(t, err)
+	assert.Contains(t, string(rewritten), `{"HostIp":"127.0.0.1","HostPort":"`+strconv.Itoa(local.HostPort)+`"}`)
+}
+
+func TestInterceptDockerResponse_CreateConflict(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	request := []byte(`{"HostConfig":{"PortBindings":{"80/tcp":[{"HostPort":"` + strconv.Itoa(port) + `"}]}}}`)
+	response := []byte(`{"Id":"abc","Warnings":[]}`)
+
+	auto := interceptManager(t, config.ConflictStrategyAuto)
+	rewritten, err := auto.InterceptDockerResponse(http.MethodPost, "/containers/create", request, response)
+	require.NoError(t, err)
+	assert.Contains(t, string(rewritten), "DockBridge forwards it on local port")
+
+	fail := interceptManager(t, config.ConflictStrategyFail)
+	_, err = fail.InterceptDockerResponse(http.MethodPost, "/containers/create", request, response)
+	var apiErr *portforward.DockerAPIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Contains(t, apiErr.Message, "address already in use")
+}
@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+	"github.com/pkg/errors"
+)
+
+// keepAliveTokenCommand prints the keep-alive secret from the server's environment file,
+// or nothing for servers provisioned before heartbeats were authenticated
+const keepAliveTokenCommand = `sed -n 's/^DOCKBRIDGE_AUTH_TOKEN=//p' /etc/dockbridge/env 2>/dev/null || true`
+
+// keepAliveTokenDir returns the directory holding the keep-alive tokens of servers
+func keepAliveTokenDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dockbridge", "keepalive")
+	}
+	return filepath.Join(homeDir, ".dockbridge", "keepalive")
+}
+
+// keepAliveTokenPath returns the file holding the keep-alive token of serverName
+func keepAliveTokenPath(serverName string) string {
+	return filepath.Join(keepAliveTokenDir(), serverName+".token")
+}
+
+// KeepAliveToken returns the secret heartbeats to serverName are signed with, or ""
+// for servers provisioned before heartbeats were authenticated
+func KeepAliveToken(serverName string) string {
+	token, err := os.ReadFile(keepAliveTokenPath(serverName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(token))
+}
+
+// prepareKeepAliveToken generates the keep-alive secret of a server about to be
+// provisioned and stores it locally
+func prepareKeepAliveToken(serverName string) (string, error) {
+	token, err := keepalive.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	if err := storeKeepAliveToken(serverName, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// storeKeepAliveToken keeps the keep-alive secret of serverName locally
+func storeKeepAliveToken(serverName, token string) error {
+	if err := os.MkdirAll(keepAliveTokenDir(), 0700); err != nil {
+		return errors.Wrap(err, "failed to create keep-alive token directory")
+	}
+	if err := os.WriteFile(keepAliveTokenPath(serverName), []byte(token), 0600); err != nil {
+		return errors.Wrap(err, "failed to store keep-alive token")
+	}
+	return nil
+}
+
+// fetchKeepAliveToken reads the keep-alive secret of serverName over client and keeps it
+// locally, unless this machine has it already. This lets machines that did not provision
+// the server, such as a second laptop or a CI runner, sign their heartbeats.
+func fetchKeepAliveToken(ctx context.Context, client ssh.Client, serverName string) (string, error) {
+	if token := KeepAliveToken(serverName); token != "" {
+		return token, nil
+	}
+
+	output, err := client.ExecuteCommand(ctx, keepAliveTokenCommand)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read keep-alive token from the server")
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" || strings.ContainsAny(token, " \t\n/") {
+		// The server accepts unsigned heartbeats
+		return "", nil
+	}
+	if err := storeKeepAliveToken(serverName, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// syncKeepAliveToken fetches the keep-alive secret of the connected server if it was
+// provisioned elsewhere. Without it heartbeats are rejected, so a failure is only logged.
+func (dcm *dockerClientManagerImpl) syncKeepAliveToken(ctx context.Context, server *provider.Server) {
+	hadToken := KeepAliveToken(server.Name) != ""
+	token, err := fetchKeepAliveToken(ctx, dcm.sshClient, server.Name)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_name": server.Name,
+			"error":       err.Error(),
+		}).Warn("Failed to fetch keep-alive token; heartbeats will be rejected")
+		return
+	}
+	if !hadToken && token != "" {
+		dcm.logger.WithFields(map[string]any{
+			"server_name": server.Name,
+		}).Info("Fetched keep-alive token of server provisioned by another client")
+	}
+}
+
+// removeKeepAliveToken deletes the locally stored keep-alive secret of a server
+func (dcm *dockerClientManagerImpl) removeKeepAliveToken(serverName string) {
+	if err := os.Remove(keepAliveTokenPath(serverName)); err != nil && !os.IsNotExist(err) {
+		dcm.logger.WithFields(map[string]any{
+			"server_name": serverName,
+			"error":       err.Error(),
+		}).Warn("Failed to remove keep-alive token")
+	}
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envFileSSHClient answers keepAliveTokenCommand with the token of the server's
+// environment file
+type envFileSSHClient struct {
+	ssh.Client
+	token    string
+	commands int
+}
+
+func (c *envFileSSHClient) ExecuteCommand(ctx context.Context, command string) ([]byte, error) {
+	c.commands++
+	if command != keepAliveTokenCommand {
+		return nil, fmt.Errorf("unexpected command %q", command)
+	}
+	return []byte(c.token + "\n"), nil
+}
+
+func TestFetchKeepAliveTokenForTwoClients(t *testing.T) {
+	token, err := keepalive.GenerateToken()
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	config := keepalive.DefaultConfig()
+	config.Port = port
+	config.LoopbackOnly = true
+	config.AuthToken = token
+	monitor := keepalive.NewMonitor(config, nil)
+	require.NoError(t, monitor.Start(context.Background()))
+	defer monitor.Stop()
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	// Each machine has its own home directory and fetches the token over SSH
+	heartbeat := func(clientID string) error {
+		t.Setenv("HOME", t.TempDir())
+		require.Empty(t, KeepAliveToken("dockbridge-shared"))
+
+		server := &envFileSSHClient{token: token}
+		fetched, err := fetchKeepAliveToken(context.Background(), server, "dockbridge-shared")
+		require.NoError(t, err)
+		assert.Equal(t, token, fetched)
+		assert.Equal(t, token, KeepAliveToken("dockbridge-shared"), "the token is kept locally")
+
+		// A kept token is not fetched again
+		_, err = fetchKeepAliveToken(context.Background(), server, "dockbridge-shared")
+		require.NoError(t, err)
+		assert.Equal(t, 1, server.commands)
+
+		return keepalive.NewHeartbeatClient(url).WithAuthToken(KeepAliveToken("dockbridge-shared")).WithClientID(clientID).SendHeartbeat()
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := keepalive.NewHeartbeatClient(url).WithAuthToken(token).GetStatus()
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	require.NoError(t, heartbeat("laptop"))
+	require.NoError(t, heartbeat("ci-runner"))
+
+	status, err := keepalive.NewHeartbeatClient(url).WithAuthToken(token).GetStatus()
+	require.NoError(t, err)
+	require.Len(t, status.Clients, 2)
+	assert.Equal(t, "ci-runner", status.Clients[0].ClientID)
+	assert.Equal(t, "laptop", status.Clients[1].ClientID)
+
+	t.Run("server without token", func(t *testing.T) {
+		t.Setenv("HOME", t.TempDir())
+		fetched, err := fetchKeepAliveToken(context.Background(), &envFileSSHClient{}, "dockbridge-old")
+		require.NoError(t, err)
+		assert.Empty(t, fetched)
+		assert.Empty(t, KeepAliveToken("dockbridge-old"))
+	})
+}
@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/heartbeat"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/notify"
+)
+
+// newKeepAliveService creates the heartbeat loop of the daemon's server
+func (d *DockBridgeDaemon) newKeepAliveService() heartbeat.KeepAliveService {
+	service := heartbeat.NewKeepAliveService(d.config.KeepAlive, func() heartbeat.Sender {
+		return d.heartbeatSender()
+	}, d.logger)
+	service.RegisterHandler(&keepAliveHandler{daemon: d})
+	return service
+}
+
+// keepAliveHandler reacts to degrading heartbeats on behalf of the daemon
+type keepAliveHandler struct {
+	daemon *DockBridgeDaemon
+}
+
+// OnHeartbeatMissed warns the user on the first missed heartbeat of an outage
+func (h *keepAliveHandler) OnHeartbeatMissed(missed int, err error) {
+	if missed != 1 {
+		return
+	}
+	d := h.daemon
+	d.notifier.Notify(notify.EventKeepAliveWarning, d.notificationTitle("Keep-alive failing"),
+		fmt.Sprintf("Heartbeats to the remote server are failing (%v); it destroys itself if they do not recover", err))
+}
+
+// OnServerLost warns that the server is destroying itself after the keep-alive timeout
+// and recovers the connection, replacing the server if it is gone
+func (h *keepAliveHandler) OnServerLost(lastHeartbeat time.Time) {
+	d := h.daemon
+	d.notifier.Notify(notify.EventSelfDestruct, d.notificationTitle("Remote server lost"),
+		fmt.Sprintf("No heartbeat reached the remote server since %s; it is destroying itself",
+			lastHeartbeat.Format(time.Kitchen)))
+	if d.hooks != nil {
+		data := map[string]string{"last_heartbeat": lastHeartbeat.UTC().Format(time.RFC3339)}
+		if srv := d.CurrentServer(); srv != nil {
+			data["server_id"] = strconv.FormatInt(srv.ID, 10)
+			data["server_name"] = srv.Name
+		}
+		d.hooks.Publish(hooks.NewEvent(hooks.EventHeartbeatLost, data))
+	}
+
+	go func() {
+		if err := d.recoverConnection(d.ctx); err != nil {
+			d.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Warn("Failed to recover connection after losing the server")
+		}
+	}()
+}
@@ -0,0 +1,35 @@
+package docker
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Keep-alive transports
+const (
+	// KeepAliveTransportHTTP sends heartbeats to the server's public keep-alive port
+	KeepAliveTransportHTTP = "http"
+
+	// KeepAliveTransportSSH sends heartbeats through the SSH connection to the keep-alive
+	// monitor on the server's loopback interface; the port is not exposed publicly
+	KeepAliveTransportSSH = "ssh"
+)
+
+// SetKeepAliveTransport selects how heartbeats reach new servers; empty means HTTP
+func (dcm *dockerClientManagerImpl) SetKeepAliveTransport(transport string) {
+	dcm.keepAliveTransport = transport
+}
+
+// DialRemote opens a connection to addr as seen from the connected server, through
+// the SSH connection
+func (dcm *dockerClientManagerImpl) DialRemote(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dcm.sshClient == nil {
+		return nil, errors.New("no SSH connection available")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return dcm.sshClient.Dial(network, addr)
+}
@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// remoteDockerSockets are bind mount sources that exist on the server as they do locally
+var remoteDockerSockets = map[string]bool{
+	"/var/run/docker.sock": true,
+	"/run/docker.sock":     true,
+}
+
+// isContainerCreate reports whether a request creates a container
+func isContainerCreate(method, target string) bool {
+	return method == http.MethodPost && interceptPath(target) == "/containers/create"
+}
+
+// localSemantics returns how containers relying on the local machine are handled
+func (d *DockBridgeDaemon) localSemantics() config.LocalSemanticsPolicy {
+	if d.config == nil || d.config.LocalSemantics == "" {
+		return config.LocalSemanticsIgnore
+	}
+	return d.config.LocalSemantics
+}
+
+// localSemanticsIssues explains the parts of a container create request that rely on
+// the local machine and behave differently on the remote server. Bind mounts whose
+// source is in synced are served by file sync and not reported.
+func localSemanticsIssues(body []byte, synced map[string]bool, fileSync bool) []string {
+	var request struct {
+		HostConfig struct {
+			Binds  []string
+			Mounts []struct {
+				Type   string
+				Source string
+			}
+			NetworkMode string
+		}
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil
+	}
+
+	hint := "enable file_sync and add it to file_sync.paths"
+	if fileSync {
+		hint = "add it to file_sync.paths"
+	}
+	var issues []string
+	bindIssue := func(source string) {
+		source = filepath.Clean(source)
+		if !filepath.IsAbs(source) || synced[source] || remoteDockerSockets[source] {
+			return
+		}
+		issues = append(issues, fmt.Sprintf("Bind mount source %s refers to a path on the remote DockBridge server, not on this machine, so the container will not see your local files; %s or use a named volume", source, hint))
+	}
+	for _, bind := range request.HostConfig.Binds {
+		if source, _, ok := strings.Cut(bind, ":"); ok {
+			bindIssue(source)
+		}
+	}
+	for _, mount := range request.HostConfig.Mounts {
+		if mount.Type == "bind" {
+			bindIssue(mount.Source)
+		}
+	}
+
+	if request.HostConfig.NetworkMode == "host" {
+		issues = append(issues, "Host networking uses the network of the remote DockBridge server, so the container's ports are not reachable on this machine's localhost and are not forwarded; publish them with -p instead")
+	}
+	return issues
+}
@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalSemanticsIssues(t *testing.T) {
+	body := []byte(`{"Image":"nginx","HostConfig":{` +
+		`"Binds":["/home/me/src:/app:ro","data:/data","/var/run/docker.sock:/var/run/docker.sock","/var/lib/docker/dockbridge-sync/ab12:/synced"],` +
+		`"Mounts":[{"Type":"bind","Source":"/home/me/nginx.conf","Target":"/etc/nginx/nginx.conf"},{"Type":"volume","Source":"cache","Target":"/cache"}],` +
+		`"NetworkMode":"host"}}`)
+
+	issues := localSemanticsIssues(body, map[string]bool{"/var/lib/docker/dockbridge-sync/ab12": true}, false)
+	if assert.Len(t, issues, 3) {
+		assert.Contains(t, issues[0], "/home/me/src")
+		assert.Contains(t, issues[0], "enable file_sync")
+		assert.Contains(t, issues[1], "/home/me/nginx.conf")
+		assert.Contains(t, issues[2], "Host networking")
+	}
+
+	issues = localSemanticsIssues([]byte(`{"HostConfig":{"Binds":["/srv:/srv"]}}`), nil, true)
+	if assert.Len(t, issues, 1) {
+		assert.Contains(t, issues[0], "add it to file_sync.paths")
+	}
+
+	assert.Empty(t, localSemanticsIssues([]byte(`{"Image":"nginx","HostConfig":{"NetworkMode":"bridge"}}`), nil, false))
+	assert.Empty(t, localSemanticsIssues([]byte(`not json`), nil, false))
+}
+
+func TestIsInterceptedRequest(t *testing.T) {
+	d := &DockBridgeDaemon{config: &DaemonConfig{}}
+	assert.False(t, d.isInterceptedRequest(http.MethodPost, "/v1.43/containers/create"))
+
+	d.config.LocalSemantics = config.LocalSemanticsWarn
+	assert.True(t, d.isInterceptedRequest(http.MethodPost, "/v1.43/containers/create?name=web"))
+	assert.False(t, d.isInterceptedRequest(http.MethodGet, "/containers/json"))
+	assert.False(t, d.isInterceptedRequest(http.MethodDelete, "/containers/web"))
+
+	d.config.LocalSemantics = config.LocalSemanticsIgnore
+	assert.False(t, d.isInterceptedRequest(http.MethodPost, "/containers/create"))
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/metrics"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/server/keepalive"
+)
+
+// SetMetrics sets the recorder counting SSH reconnects; nil records nothing
+func (dcm *dockerClientManagerImpl) SetMetrics(recorder *metrics.Recorder) {
+	dcm.metrics = recorder
+}
+
+// MetricsSnapshot samples the daemon's tunnel traffic, forwards and the keep-alive
+// state of the connected server. It never provisions or reconnects a server.
+func (d *DockBridgeDaemon) MetricsSnapshot(ctx context.Context) metrics.Snapshot {
+	snapshot := metrics.Snapshot{
+		TunnelSent:     d.trafficCounter.Sent(),
+		TunnelReceived: d.trafficCounter.Received(),
+	}
+
+	for _, forward := range d.PortForwards() {
+		if forward.Status == portforward.ForwardStatusActive {
+			snapshot.ActiveForwards++
+		}
+	}
+
+	if sender, ok := d.heartbeatSender().(*keepalive.HeartbeatClient); ok && ctx.Err() == nil {
+		snapshot.KeepAlive = keepAliveStatus(sender)
+	}
+	return snapshot
+}
+
+// keepAliveStatus fetches the keep-alive state of a server, or nil if it is unreachable
+func keepAliveStatus(client *keepalive.HeartbeatClient) *metrics.KeepAliveStatus {
+	status, err := client.GetStatus()
+	if err != nil {
+		return nil
+	}
+
+	since, err := time.ParseDuration(status.TimeSinceHeartbeat)
+	if err != nil {
+		return nil
+	}
+	until, err := time.ParseDuration(status.TimeUntilShutdown)
+	if err != nil {
+		return nil
+	}
+	// The server destroys itself once the grace period after the timeout has passed
+	grace, err := time.ParseDuration(status.GracePeriod)
+	if err != nil {
+		return nil
+	}
+	return &metrics.KeepAliveStatus{SinceHeartbeat: since, UntilSelfDestruct: until + grace}
+}
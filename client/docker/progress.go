@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/readiness"
+)
+
+// SetProvisioningObserver sets the observer notified while a server is provisioned or resumed
+func (dcm *dockerClientManagerImpl) SetProvisioningObserver(observer provider.ProvisioningObserver) {
+	dcm.provisioningObserver = observer
+}
+
+// reportPhase notifies the provisioning observer and tracks whether a provisioning is
+// in progress, so that EnsureConnection can report its outcome
+func (dcm *dockerClientManagerImpl) reportPhase(phase provider.ProvisioningPhase, message string, percent int) {
+	dcm.provisioning = !phase.Done()
+	if dcm.provisioningObserver != nil {
+		dcm.provisioningObserver.OnPhase(phase, message, percent)
+	}
+}
+
+// reportReadinessPhase translates readiness progress into provisioning phases
+func (dcm *dockerClientManagerImpl) reportReadinessPhase(progress readiness.Progress) {
+	if progress.Status != readiness.StatusReady {
+		return
+	}
+
+	switch progress.Phase {
+	case readiness.PhaseSSH:
+		dcm.reportPhase(provider.PhaseWaitingForCloudInit, "Waiting for cloud-init", 50)
+	case readiness.PhaseCloudInit:
+		dcm.reportPhase(provider.PhaseWaitingForDocker, "Waiting for Docker", 75)
+	}
+}
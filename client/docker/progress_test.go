@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/readiness"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportReadinessPhase(t *testing.T) {
+	dcm := NewDockerClientManager(&MockHetznerClient{}, &config.SSHConfig{}, &config.HetznerConfig{}, logger.NewDefault()).(*dockerClientManagerImpl)
+
+	var phases []provider.ProvisioningPhase
+	dcm.SetProvisioningObserver(provider.ProvisioningObserverFunc(func(phase provider.ProvisioningPhase, message string, percent int) {
+		phases = append(phases, phase)
+	}))
+
+	dcm.reportReadinessPhase(readiness.Progress{Phase: readiness.PhaseSSH, Status: readiness.StatusWaiting})
+	dcm.reportReadinessPhase(readiness.Progress{Phase: readiness.PhaseSSH, Status: readiness.StatusReady})
+	dcm.reportReadinessPhase(readiness.Progress{Phase: readiness.PhaseCloudInit, Status: readiness.StatusReady})
+	assert.Equal(t, []provider.ProvisioningPhase{provider.PhaseWaitingForCloudInit, provider.PhaseWaitingForDocker}, phases)
+	assert.True(t, dcm.provisioning)
+
+	dcm.reportPhase(provider.PhaseFailed, "timeout", 100)
+	assert.False(t, dcm.provisioning)
+}
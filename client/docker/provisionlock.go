@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/provisionlock"
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
+	"github.com/pkg/errors"
+)
+
+// provisioningGrace is how long a server that is not running yet is left alone when it
+// carries a provisioning token: another process may still be setting it up
+const provisioningGrace = 15 * time.Minute
+
+// lockProvisioning takes the provisioning lock of the context, waiting while another
+// local process provisions its server. It returns nil without error if the lock cannot
+// be used, in which case provisioning proceeds unserialized as before.
+func (dcm *dockerClientManagerImpl) lockProvisioning(ctx context.Context) (*provisionlock.Lock, error) {
+	path, err := provisionlock.Path(dcm.contextName)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Provisioning lock unavailable, proceeding without it")
+		return nil, nil
+	}
+
+	lock, err := provisionlock.Acquire(ctx, path, func(holder int) {
+		dcm.logger.WithFields(map[string]any{
+			"holder_pid": holder,
+			"lock":       path,
+		}).Info("Another process is provisioning the server, waiting for it")
+		dcm.reportPhase(provider.PhaseWaitingForLock, fmt.Sprintf("Waiting for process %d to finish provisioning", holder), 5)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.Wrap(err, "failed to wait for provisioning lock")
+		}
+		dcm.logger.WithFields(map[string]any{
+			"lock":  path,
+			"error": err.Error(),
+		}).Warn("Provisioning lock unavailable, proceeding without it")
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// completeProvisioning waits for a server created by an interrupted provisioning of an
+// earlier lock holder, rather than creating a second one
+func (dcm *dockerClientManagerImpl) completeProvisioning(ctx context.Context, server *provider.Server, lock *provisionlock.Lock) (*provider.Server, error) {
+	dcm.logger.WithFields(map[string]any{
+		"server_id":   server.ID,
+		"server_name": server.Name,
+		"status":      server.Status,
+	}).Info("Completing interrupted provisioning of server")
+
+	err := dcm.waitForServerReady(ctx, server, true)
+	finishProvisioning(lock)
+	if err != nil {
+		go dcm.cleanupStaleServers(context.Background(), []*provider.Server{server})
+		return nil, errServerNotReady(err)
+	}
+	return server, nil
+}
+
+// errServerNotReady is the error of a provisioned server that did not become ready; the
+// server is cleaned up
+func errServerNotReady(err error) error {
+	return dberrors.NewProvisioningError(dberrors.ErrCodeServerNotReady, "Server provisioned but not ready", err, true).
+		WithHint(`The server is being removed; retry the command, or run "dockbridge doctor" if it keeps failing`)
+}
+
+// isPendingProvisioning reports whether server was created by the provisioning in
+// progress recorded in lock
+func isPendingProvisioning(server *provider.Server, lock *provisionlock.Lock) bool {
+	if lock == nil || lock.PendingToken() == "" {
+		return false
+	}
+	return server.Labels[provider.LabelProvisionToken] == lock.PendingToken()
+}
+
+// provisioningElsewhere reports whether server is still being provisioned by a process
+// that does not share the lock, such as one on another machine, so it must not be
+// cleaned up as stale
+func provisioningElsewhere(server *provider.Server, now time.Time) bool {
+	if server.Status == "running" || server.Labels[provider.LabelProvisionToken] == "" {
+		return false
+	}
+	if server.Status == provider.StatusOff {
+		return false
+	}
+	return now.Sub(server.CreatedAt) < provisioningGrace
+}
+
+// finishProvisioning clears the provisioning in progress recorded in lock, if any
+func finishProvisioning(lock *provisionlock.Lock) {
+	if lock == nil {
+		return
+	}
+	_ = lock.Finish()
+}
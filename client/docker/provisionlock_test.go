@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/provisionlock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPendingProvisioning(t *testing.T) {
+	lock, err := provisionlock.TryAcquire(filepath.Join(t.TempDir(), "provision.lock"))
+	require.NoError(t, err)
+	defer lock.Release()
+
+	server := &provider.Server{Labels: map[string]string{provider.LabelProvisionToken: "abc"}}
+	assert.False(t, isPendingProvisioning(server, nil))
+	assert.False(t, isPendingProvisioning(server, lock))
+
+	require.NoError(t, lock.Begin("abc", time.Now()))
+	assert.True(t, isPendingProvisioning(server, lock))
+	assert.False(t, isPendingProvisioning(&provider.Server{}, lock))
+}
+
+func TestProvisioningElsewhere(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	token := map[string]string{provider.LabelProvisionToken: "abc"}
+
+	tests := []struct {
+		name   string
+		server *provider.Server
+		want   bool
+	}{
+		{"initializing with token", &provider.Server{Status: "initializing", Labels: token, CreatedAt: now.Add(-time.Minute)}, true},
+		{"initializing without token", &provider.Server{Status: "initializing", CreatedAt: now.Add(-time.Minute)}, false},
+		{"past grace period", &provider.Server{Status: "initializing", Labels: token, CreatedAt: now.Add(-time.Hour)}, false},
+		{"running", &provider.Server{Status: "running", Labels: token, CreatedAt: now.Add(-time.Minute)}, false},
+		{"powered off", &provider.Server{Status: provider.StatusOff, Labels: token, CreatedAt: now.Add(-time.Minute)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, provisioningElsewhere(tt.server, now))
+		})
+	}
+}
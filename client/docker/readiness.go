@@ -0,0 +1,280 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/readiness"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// setupMarkerPath is written with the server's name as the last step of the setup.
+// The name is checked rather than the file's existence because golden images carry the
+// marker of the server they were taken from.
+const setupMarkerPath = cloudinit.SetupMarker
+
+// SetReadinessProgress sets a callback receiving per-phase progress while a new or
+// resumed server becomes ready; nil only logs progress
+func (dcm *dockerClientManagerImpl) SetReadinessProgress(progress readiness.ProgressFunc) {
+	dcm.readinessProgress = progress
+}
+
+// waitForServerReady waits until cloud-init finished and Docker responds. setupMarker
+// additionally requires the marker of this server's setup script, which only servers
+// provisioned by this client have.
+func (dcm *dockerClientManagerImpl) waitForServerReady(ctx context.Context, server *provider.Server, setupMarker bool) (err error) {
+	ctx, span := telemetry.Start(ctx, "dockbridge.server.wait_ready", attribute.String("dockbridge.server", server.Name))
+	defer func() { telemetry.End(span, err) }()
+
+	dcm.logger.WithFields(map[string]any{
+		"server_id": server.ID,
+	}).Info("Waiting for server to be ready")
+	dcm.reportPhase(provider.PhaseWaitingForSSH, "Waiting for SSH", 30)
+
+	session := &probeSession{config: dcm.readinessSSHConfig(server)}
+	defer session.Close()
+
+	probes := []readiness.Probe{
+		{Phase: readiness.PhaseCloudInit, Check: session.cloudInitDone},
+		{Phase: readiness.PhaseDocker, Check: session.dockerResponding},
+		{Phase: readiness.PhaseKeepAlive, Check: session.keepAliveResponding, Optional: true},
+	}
+	if setupMarker {
+		probes = append(probes, readiness.Probe{
+			Phase: readiness.PhaseSetup,
+			Check: func(ctx context.Context) error { return session.setupCompleted(ctx, server.Name) },
+		})
+	}
+
+	cfg := readiness.DefaultConfig()
+	cfg.OnProgress = func(progress readiness.Progress) {
+		if progress.Status != readiness.StatusWaiting {
+			span.AddEvent("readiness "+string(progress.Phase)+" "+string(progress.Status),
+				trace.WithAttributes(attribute.Int("dockbridge.attempt", progress.Attempt)))
+		}
+		dcm.reportReadiness(server, progress)
+		dcm.reportReadinessPhase(progress)
+	}
+
+	startTime := time.Now()
+	if err := readiness.Wait(ctx, cfg, []readiness.Probe{{Phase: readiness.PhaseSSH, Check: session.connect}}, probes); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_id": server.ID,
+			"elapsed":   time.Since(startTime).String(),
+			"error":     err.Error(),
+		}).Error("Server did not become ready")
+		return err
+	}
+
+	// Without its binary the server would run without the keep-alive monitor that
+	// destroys it once the client is gone
+	if setupMarker && dcm.uploadsServerBinary() {
+		dcm.reportPhase(provider.PhaseUploadingServer, "Uploading dockbridge-server", 85)
+		client, err := session.connected(ctx)
+		if err == nil {
+			err = dcm.deployServerBinary(ctx, client)
+		}
+		if err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"server_id": server.ID,
+				"error":     err.Error(),
+			}).Error("Failed to upload dockbridge-server")
+			return errors.Wrap(err, "failed to upload dockbridge-server")
+		}
+	}
+
+	dcm.logger.WithFields(map[string]any{
+		"server_id": server.ID,
+		"elapsed":   time.Since(startTime).String(),
+	}).Info("Server is ready")
+	dcm.reportPhase(provider.PhaseDockerReady, "Docker ready", 90)
+	return nil
+}
+
+// reportReadiness logs a readiness update and forwards it to the progress callback
+func (dcm *dockerClientManagerImpl) reportReadiness(server *provider.Server, progress readiness.Progress) {
+	fields := map[string]any{
+		"server_id": server.ID,
+		"phase":     string(progress.Phase),
+		"attempt":   progress.Attempt,
+		"elapsed":   progress.Elapsed.Round(time.Second).String(),
+	}
+	if progress.Err != nil {
+		fields["error"] = progress.Err.Error()
+	}
+
+	switch progress.Status {
+	case readiness.StatusReady:
+		dcm.logger.WithFields(fields).Info("Server readiness phase completed")
+	case readiness.StatusFailed:
+		dcm.logger.WithFields(fields).Error("Server readiness phase failed")
+	default:
+		dcm.logger.WithFields(fields).Debug("Server readiness phase pending")
+	}
+
+	if dcm.readinessProgress != nil {
+		dcm.readinessProgress(progress)
+	}
+}
+
+// readinessSSHConfig returns the SSH settings for probing a server. Probes only run for
+// servers this client provisioned or resumed, so their host key is recorded on first use
+// even under strict checking; later connections verify against it.
+func (dcm *dockerClientManagerImpl) readinessSSHConfig(server *provider.Server) *ssh.ClientConfig {
+	hostKeyChecking := dcm.sshConfig.HostKeyChecking
+	if hostKeyChecking != ssh.HostKeyCheckingOff {
+		hostKeyChecking = ssh.HostKeyCheckingTOFU
+	}
+
+	return &ssh.ClientConfig{
+		Host:            server.IPAddress,
+		Port:            dcm.sshConfig.Port,
+		User:            "root",
+		PrivateKeyPath:  expandPath(dcm.sshConfig.KeyPath),
+		Timeout:         15 * time.Second,
+		UseAgent:        dcm.sshConfig.UseAgent,
+		AgentSocket:     dcm.sshConfig.AgentSocket,
+		KnownHostsPath:  expandPath(dcm.sshConfig.KnownHostsPath),
+		HostKeyChecking: hostKeyChecking,
+		JumpHost:        dcm.sshConfig.JumpHost,
+	}
+}
+
+// probeSession shares one SSH connection between parallel probes and reconnects when
+// the connection is lost, e.g. while sshd restarts during setup
+type probeSession struct {
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client ssh.Client
+}
+
+// connect establishes the SSH connection unless it is already up
+func (s *probeSession) connect(ctx context.Context) error {
+	_, err := s.connected(ctx)
+	return err
+}
+
+// connected returns a live SSH client, connecting if necessary
+func (s *probeSession) connected(ctx context.Context) (ssh.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil && s.client.IsConnected() {
+		return s.client, nil
+	}
+
+	client := ssh.NewClient(s.config)
+	connectCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	if err := client.Connect(connectCtx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	s.client = client
+	return client, nil
+}
+
+// run executes a command, dropping the connection if it failed for another reason than
+// the command's exit status
+func (s *probeSession) run(ctx context.Context, command string) (string, error) {
+	client, err := s.connected(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.ExecuteCommand(ctx, command)
+	if err != nil {
+		var exitErr *gossh.ExitError
+		if !errors.As(err, &exitErr) {
+			s.drop(client)
+		}
+	}
+	return strings.TrimSpace(string(output)), err
+}
+
+// drop closes client if it is still the session's connection
+func (s *probeSession) drop(client ssh.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == client {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// Close closes the SSH connection
+func (s *probeSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+// cloudInitDone blocks until cloud-init finished; a failed setup fails readiness at once
+func (s *probeSession) cloudInitDone(ctx context.Context) error {
+	output, err := s.run(ctx, "cloud-init status --wait")
+	return cloudInitResult(output, err)
+}
+
+// cloudInitResult interprets the output of "cloud-init status --wait"
+func cloudInitResult(output string, err error) error {
+	switch {
+	case strings.Contains(output, "status: error"):
+		return readiness.Permanent(fmt.Errorf("cloud-init failed, see /var/log/dockbridge-setup.log: %s", output))
+	case strings.HasSuffix(output, "done"), strings.Contains(output, "status: disabled"):
+		// "status: degraded done" exits non-zero but setup completed
+		return nil
+	case strings.Contains(output, "command not found"):
+		// Images without cloud-init are covered by the other probes
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("unexpected cloud-init status: %s", output)
+	}
+}
+
+// dockerResponding checks that the Docker daemon answers
+func (s *probeSession) dockerResponding(ctx context.Context) error {
+	version, err := s.run(ctx, "docker version --format '{{.Server.Version}}'")
+	if err != nil {
+		return errors.Wrap(err, "docker daemon not responding")
+	}
+	if version == "" {
+		return errors.New("docker daemon reported no version")
+	}
+	return nil
+}
+
+// keepAliveResponding checks the server-side keep-alive monitor, where installed
+func (s *probeSession) keepAliveResponding(ctx context.Context) error {
+	_, err := s.run(ctx, fmt.Sprintf("curl -fsS -m 3 http://127.0.0.1:%d/health", defaultKeepAlivePort))
+	return err
+}
+
+// setupCompleted checks that the setup script of serverName ran to the end
+func (s *probeSession) setupCompleted(ctx context.Context, serverName string) error {
+	marker, err := s.run(ctx, "cat "+setupMarkerPath)
+	if err != nil {
+		return errors.Wrap(err, "setup script still running")
+	}
+	if marker != serverName {
+		return fmt.Errorf("setup marker belongs to %q", marker)
+	}
+	return nil
+}
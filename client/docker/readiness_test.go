@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/readiness"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudInitResult(t *testing.T) {
+	exitErr := errors.New("command execution failed: Process exited with status 1")
+
+	assert.NoError(t, cloudInitResult("status: done", nil))
+	assert.NoError(t, cloudInitResult("status: degraded done", exitErr))
+	assert.NoError(t, cloudInitResult("bash: cloud-init: command not found", exitErr))
+
+	// A failed setup script ends the wait instead of retrying until the timeout
+	err := cloudInitResult("status: error", exitErr)
+	var permanent interface{ Unwrap() error }
+	assert.ErrorAs(t, err, &permanent)
+	assert.ErrorContains(t, err, "cloud-init failed")
+
+	assert.ErrorIs(t, cloudInitResult("", exitErr), exitErr)
+	assert.Error(t, cloudInitResult("status: running", nil))
+}
+
+func TestReportReadinessForwardsProgress(t *testing.T) {
+	dcm := NewDockerClientManager(&MockHetznerClient{}, &config.SSHConfig{}, &config.HetznerConfig{}, logger.NewDefault()).(*dockerClientManagerImpl)
+
+	// Without a callback progress is only logged
+	dcm.reportReadiness(&provider.Server{ID: 1}, readiness.Progress{Phase: readiness.PhaseDocker, Status: readiness.StatusReady})
+
+	var received []readiness.Progress
+	dcm.SetReadinessProgress(func(progress readiness.Progress) {
+		received = append(received, progress)
+	})
+	dcm.reportReadiness(&provider.Server{ID: 1}, readiness.Progress{Phase: readiness.PhaseCloudInit, Status: readiness.StatusWaiting, Err: errors.New("running")})
+	dcm.reportReadiness(&provider.Server{ID: 1}, readiness.Progress{Phase: readiness.PhaseCloudInit, Status: readiness.StatusReady, Attempt: 2})
+
+	assert.Len(t, received, 2)
+	assert.Equal(t, readiness.StatusReady, received[1].Status)
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// reconnectProbeTimeout bounds a single SSH keep-alive probe
+const reconnectProbeTimeout = 10 * time.Second
+
+// reconnectConfig returns the SSH reconnect settings, or nil when reconnection is disabled
+func (dcm *dockerClientManagerImpl) reconnectConfig() *ssh.ReconnectConfig {
+	if dcm.sshConfig == nil || !dcm.sshConfig.Reconnect.Enabled {
+		return nil
+	}
+
+	cfg := ssh.DefaultReconnectConfig()
+	if dcm.sshConfig.KeepAlive > 0 {
+		cfg.Interval = dcm.sshConfig.KeepAlive
+	}
+	cfg.Timeout = min(reconnectProbeTimeout, cfg.Interval)
+	cfg.MaxMissed = dcm.sshConfig.Reconnect.MaxMissed
+	cfg.MaxAttempts = dcm.sshConfig.Reconnect.MaxAttempts
+	return cfg
+}
+
+// startReconnectManager watches the SSH connection of the current server and
+// re-establishes it and the Docker tunnel when it drops
+func (dcm *dockerClientManagerImpl) startReconnectManager() {
+	cfg := dcm.reconnectConfig()
+	if cfg == nil || dcm.sshClient == nil {
+		return
+	}
+
+	dcm.reconnect = ssh.NewReconnectManager(dcm.sshClient, cfg, dcm.handleReconnectEvent)
+	dcm.reconnect.Start(context.Background())
+}
+
+// stopReconnectManager stops watching the SSH connection
+func (dcm *dockerClientManagerImpl) stopReconnectManager() {
+	if dcm.reconnect != nil {
+		dcm.reconnect.Stop()
+		dcm.reconnect = nil
+	}
+}
+
+// handleReconnectEvent logs connection changes and, once the SSH connection is back,
+// swaps the Docker client's transport onto it
+func (dcm *dockerClientManagerImpl) handleReconnectEvent(event ssh.ReconnectEvent, err error) {
+	fields := map[string]any{
+		"event": string(event),
+	}
+	if server := dcm.currentServer; server != nil {
+		fields["server_ip"] = server.IPAddress
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	switch event {
+	case ssh.ReconnectLost:
+		dcm.logger.WithFields(fields).Warn("SSH connection lost, reconnecting")
+	case ssh.ReconnectRestored:
+		dcm.swapDockerTransport()
+		dcm.metrics.Reconnected("restored")
+		dcm.logger.WithFields(fields).Info("SSH connection restored")
+	case ssh.ReconnectFailed:
+		dcm.metrics.Reconnected("failed")
+		dcm.logger.WithFields(fields).Error("Failed to restore SSH connection, reconnecting on next request")
+	}
+}
+
+// swapDockerTransport drops the Docker client's pooled connections, which went through
+// the dead SSH connection. The tunnel keeps its local address, so the client itself is
+// kept and its next request dials over the new connection.
+func (dcm *dockerClientManagerImpl) swapDockerTransport() {
+	if dcm.dockerClient != nil {
+		dcm.dockerClient.HTTPClient().CloseIdleConnections()
+	}
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+func TestReconnectConfig(t *testing.T) {
+	dcm := &dockerClientManagerImpl{sshConfig: &config.SSHConfig{
+		KeepAlive: 5 * time.Second,
+		Reconnect: config.SSHReconnectConfig{Enabled: true, MaxMissed: 3, MaxAttempts: 0},
+	}}
+
+	cfg := dcm.reconnectConfig()
+	require.NotNil(t, cfg)
+	assert.Equal(t, 5*time.Second, cfg.Interval)
+	assert.Equal(t, 5*time.Second, cfg.Timeout, "probe timeout is capped at the interval")
+	assert.Equal(t, 3, cfg.MaxMissed)
+	assert.Zero(t, cfg.MaxAttempts)
+
+	dcm.sshConfig.Reconnect.Enabled = false
+	assert.Nil(t, dcm.reconnectConfig())
+}
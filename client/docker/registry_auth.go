@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// dockerHubAuthKey is the key Docker stores Docker Hub credentials under
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// registryAuthCommand replaces the Docker config of the SSH user on the server with the
+// one read from stdin. The file is written under a restrictive umask and renamed into
+// place, so it is never readable by others or left half-written.
+const registryAuthCommand = `set -e
+umask 077
+mkdir -p "$HOME/.docker"
+cat > "$HOME/.docker/config.json.dockbridge"
+mv "$HOME/.docker/config.json.dockbridge" "$HOME/.docker/config.json"`
+
+// dockerConfigFile is the part of a Docker CLI config.json holding credentials
+type dockerConfigFile struct {
+	Auths       map[string]registryAuth `json:"auths"`
+	CredsStore  string                  `json:"credsStore,omitempty"`
+	CredHelpers map[string]string       `json:"credHelpers,omitempty"`
+}
+
+// registryAuth is the credentials of a registry in a Docker config
+type registryAuth struct {
+	// Auth is base64 of "username:password"
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// runCredentialHelper runs "docker-credential-<helper> get" for serverURL; replaced in
+// tests
+var runCredentialHelper = func(ctx context.Context, helper, serverURL string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	return cmd.Output()
+}
+
+// localDockerConfigPath returns the config.json of the local Docker CLI
+func localDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".docker", "config.json")
+	}
+	return filepath.Join(homeDir, ".docker", "config.json")
+}
+
+// registryAuthKey returns the key Docker stores the registry's credentials under
+func registryAuthKey(registry string) string {
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return dockerHubAuthKey
+	}
+	return registry
+}
+
+// resolveRegistryAuth returns the credentials of the selected registries, keyed like
+// a Docker config. Registries without configured credentials are looked up in the
+// local Docker config at path.
+func resolveRegistryAuth(ctx context.Context, entries []config.RegistryAuthConfig, path string) (map[string]registryAuth, error) {
+	var local *dockerConfigFile
+	auths := make(map[string]registryAuth, len(entries))
+	for _, entry := range entries {
+		key := registryAuthKey(entry.Registry)
+		if entry.Username != "" {
+			credentials := entry.Username + ":" + entry.Password
+			auths[key] = registryAuth{Auth: base64.StdEncoding.EncodeToString([]byte(credentials))}
+			continue
+		}
+
+		if local == nil {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to read local Docker config")
+			}
+			local = &dockerConfigFile{}
+			if err := json.Unmarshal(data, local); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse local Docker config %s", path)
+			}
+		}
+
+		auth, err := local.credentials(ctx, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "registry %s", entry.Registry)
+		}
+		auths[key] = auth
+	}
+	return auths, nil
+}
+
+// credentials looks up the credentials of a registry the way the Docker CLI does: its
+// credential helper, else the default credential store, else the config itself
+func (c *dockerConfigFile) credentials(ctx context.Context, key string) (registryAuth, error) {
+	helper := c.CredHelpers[key]
+	if helper == "" {
+		helper = c.CredsStore
+	}
+	if helper != "" {
+		output, err := runCredentialHelper(ctx, helper, key)
+		if err != nil {
+			return registryAuth{}, errors.Wrapf(err, "credential helper docker-credential-%s failed", helper)
+		}
+		var credentials struct {
+			Username string
+			Secret   string
+		}
+		if err := json.Unmarshal(output, &credentials); err != nil {
+			return registryAuth{}, errors.Wrapf(err, "invalid output of docker-credential-%s", helper)
+		}
+		// Helpers return identity tokens under this username
+		if credentials.Username == "<token>" {
+			return registryAuth{IdentityToken: credentials.Secret}, nil
+		}
+		auth := credentials.Username + ":" + credentials.Secret
+		return registryAuth{Auth: base64.StdEncoding.EncodeToString([]byte(auth))}, nil
+	}
+
+	for _, candidate := range []string{key, "https://" + key, "http://" + key} {
+		if auth, ok := c.Auths[candidate]; ok && (auth.Auth != "" || auth.IdentityToken != "") {
+			return auth, nil
+		}
+	}
+	return registryAuth{}, errors.New("no credentials in the local Docker config, run 'docker login' first")
+}
+
+// syncRegistryAuth copies the credentials of the selected registries to the Docker
+// config on the connected server. It runs on every connection, so new servers and
+// renewed local logins are picked up without provisioning.
+func (dcm *dockerClientManagerImpl) syncRegistryAuth(ctx context.Context) error {
+	if len(dcm.registryAuth) == 0 {
+		return nil
+	}
+
+	auths, err := resolveRegistryAuth(ctx, dcm.registryAuth, localDockerConfigPath())
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve registry credentials")
+	}
+	data, err := json.MarshalIndent(dockerConfigFile{Auths: auths}, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode registry credentials")
+	}
+
+	output, err := dcm.sshClient.ExecuteCommandWithInput(ctx, registryAuthCommand, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write registry credentials: %s", strings.TrimSpace(string(output)))
+	}
+
+	registries := make([]string, 0, len(auths))
+	for registry := range auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	dcm.logger.WithFields(map[string]any{
+		"registries": strings.Join(registries, ", "),
+	}).Info("Registry credentials forwarded to server")
+	return nil
+}
@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRegistryAuth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"auths": {
+			"https://registry.example.com": {"auth": "dXNlcjpwYXNz"},
+			"ghcr.io": {},
+			"https://index.docker.io/v1/": {}
+		},
+		"credHelpers": {"ghcr.io": "gh"},
+		"credsStore": "desktop"
+	}`), 0600))
+
+	helper := runCredentialHelper
+	t.Cleanup(func() { runCredentialHelper = helper })
+	var calls []string
+	runCredentialHelper = func(_ context.Context, helper, serverURL string) ([]byte, error) {
+		calls = append(calls, helper+" "+serverURL)
+		if helper == "gh" {
+			return []byte(`{"ServerURL":"ghcr.io","Username":"<token>","Secret":"identity"}`), nil
+		}
+		return []byte(`{"ServerURL":"https://index.docker.io/v1/","Username":"hub","Secret":"secret"}`), nil
+	}
+
+	auths, err := resolveRegistryAuth(context.Background(), []config.RegistryAuthConfig{
+		{Registry: "ghcr.io"},
+		{Registry: "docker.io"},
+		{Registry: "quay.io", Username: "robot", Password: "pw"},
+	}, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]registryAuth{
+		"ghcr.io":        {IdentityToken: "identity"},
+		dockerHubAuthKey: {Auth: base64.StdEncoding.EncodeToString([]byte("hub:secret"))},
+		"quay.io":        {Auth: base64.StdEncoding.EncodeToString([]byte("robot:pw"))},
+	}, auths)
+	assert.Equal(t, []string{"gh ghcr.io", "desktop " + dockerHubAuthKey}, calls, "registry helpers take precedence over the store")
+}
+
+func TestResolveRegistryAuthFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths": {"https://registry.example.com": {"auth": "dXNlcjpwYXNz"}, "ghcr.io": {}}}`), 0600))
+
+	auths, err := resolveRegistryAuth(context.Background(), []config.RegistryAuthConfig{{Registry: "registry.example.com"}}, path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]registryAuth{"registry.example.com": {Auth: "dXNlcjpwYXNz"}}, auths)
+
+	_, err = resolveRegistryAuth(context.Background(), []config.RegistryAuthConfig{{Registry: "ghcr.io"}}, path)
+	assert.ErrorContains(t, err, "registry ghcr.io: no credentials")
+
+	// Configured credentials do not need a local Docker config
+	_, err = resolveRegistryAuth(context.Background(), []config.RegistryAuthConfig{{Registry: "ghcr.io", Username: "ci"}}, filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, err)
+}
+
+func TestRegistryAuthCommand(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	home := t.TempDir()
+	cmd := exec.Command(bash, "-c", registryAuthCommand)
+	cmd.Env = []string{"HOME=" + home}
+	cmd.Stdin = bytes.NewReader([]byte(`{"auths":{}}`))
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	path := filepath.Join(home, ".docker", "config.json")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"auths":{}}`, string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRelayTrafficHalfClose checks that the output of a hijacked exec still reaches the
+// client after it closed stdin
+func TestRelayTrafficHalfClose(t *testing.T) {
+	remoteListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer remoteListener.Close()
+
+	go func() {
+		conn, err := remoteListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		input, _ := io.ReadAll(conn)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = conn.Write([]byte(strings.ToUpper(string(input))))
+	}()
+
+	localListener, err := net.Listen("unix", t.TempDir()+"/docker.sock")
+	require.NoError(t, err)
+	defer localListener.Close()
+
+	d := &DockBridgeDaemon{logger: logger.NewDefault()}
+	go func() {
+		local, err := localListener.Accept()
+		if err != nil {
+			return
+		}
+		defer local.Close()
+		remote, err := net.Dial("tcp", remoteListener.Addr().String())
+		if err != nil {
+			return
+		}
+		defer remote.Close()
+		d.relayTraffic(context.Background(), local, local, remote, "test")
+	}()
+
+	client, err := net.Dial("unix", localListener.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("echo hello\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.UnixConn).CloseWrite())
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	output, err := io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "ECHO HELLO\n", string(output))
+}
+
+// TestRelayClosesAfterEachRequest checks that a client sending two requests on one
+// connection sends the second on a new connection, so that it is classified, and
+// intercepted, on its own
+func TestRelayClosesAfterEachRequest(t *testing.T) {
+	remoteConns := 0
+	var mu sync.Mutex
+	remote := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.Path)
+	}))
+	remote.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			remoteConns++
+			mu.Unlock()
+		}
+	}
+	remote.Start()
+	defer remote.Close()
+
+	localListener, err := net.Listen("unix", t.TempDir()+"/docker.sock")
+	require.NoError(t, err)
+	defer localListener.Close()
+
+	// Each connection is classified by its first request line, as handleConnection does
+	var classified []string
+	d := &DockBridgeDaemon{logger: logger.NewDefault()}
+	go func() {
+		for {
+			local, err := localListener.Accept()
+			if err != nil {
+				return
+			}
+			reader := bufio.NewReader(local)
+			requestLine, err := reader.ReadString('\n')
+			if err != nil {
+				local.Close()
+				continue
+			}
+			mu.Lock()
+			classified = append(classified, strings.TrimSpace(requestLine))
+			mu.Unlock()
+
+			request, err := closingRequest(requestLine, reader)
+			if err != nil {
+				local.Close()
+				continue
+			}
+			remoteConn, err := net.Dial("tcp", remote.Listener.Addr().String())
+			if err != nil {
+				local.Close()
+				continue
+			}
+			d.relayTraffic(context.Background(), local, request, remoteConn, "test")
+			remoteConn.Close()
+			local.Close()
+		}
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", localListener.Addr().String())
+		},
+	}}
+	for _, request := range []struct{ method, path string }{
+		{http.MethodGet, "/v1.43/version"},
+		{http.MethodPost, "/v1.43/containers/create"},
+	} {
+		req, err := http.NewRequest(request.method, "http://docker"+request.path, strings.NewReader("{}"))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, request.method+" "+request.path, string(body))
+		assert.True(t, resp.Close, "the response closes the connection")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"GET /v1.43/version HTTP/1.1", "POST /v1.43/containers/create HTTP/1.1"}, classified)
+	assert.Equal(t, 2, remoteConns)
+}
+
+func TestClosingRequest(t *testing.T) {
+	read := func(request string) string {
+		reader := bufio.NewReader(strings.NewReader(request))
+		requestLine, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		relayed, err := closingRequest(requestLine, reader)
+		require.NoError(t, err)
+		out, err := io.ReadAll(relayed)
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	assert.Equal(t, "GET /version HTTP/1.1\r\nHost: docker\r\nConnection: close\r\n\r\n",
+		read("GET /version HTTP/1.1\r\nHost: docker\r\nConnection: keep-alive\r\nKeep-Alive: timeout=5\r\n\r\n"))
+
+	assert.Equal(t, "POST /containers/create HTTP/1.1\r\nContent-Length: 2\r\nConnection: close\r\n\r\n{}",
+		read("POST /containers/create HTTP/1.1\r\nContent-Length: 2\r\n\r\n{}"))
+
+	attach := "POST /containers/abc/attach?stream=1 HTTP/1.1\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nstdin"
+	assert.Equal(t, attach, read(attach), "upgrades are relayed unchanged")
+
+	_, err := closingRequest("GET / HTTP/1.1\r\n", bufio.NewReader(strings.NewReader("Host: docker\r\n")))
+	assert.Error(t, err, "truncated headers")
+}
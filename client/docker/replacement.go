@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// ServerReplaceFunc decides whether a running server whose type differs from the
+// requested one is replaced by a new server of the requested type
+type ServerReplaceFunc func(server *provider.Server, requestedType string) bool
+
+// SetServerReplacement sets the decision for servers of the wrong type; nil keeps them
+func (dcm *dockerClientManagerImpl) SetServerReplacement(confirm ServerReplaceFunc) {
+	dcm.confirmReplace = confirm
+}
+
+// ServerTypeMismatch reports whether server runs on a different type than requested.
+// Servers of an unknown type are never considered mismatched.
+func ServerTypeMismatch(server *provider.Server, requestedType string) bool {
+	return server.ServerType != "" && requestedType != "" && !strings.EqualFold(server.ServerType, requestedType)
+}
+
+// replaceIfMismatched destroys server when it runs on the wrong type and replacement is
+// confirmed. It reports whether the server was destroyed.
+func (dcm *dockerClientManagerImpl) replaceIfMismatched(ctx context.Context, server *provider.Server) (bool, error) {
+	requestedType := dcm.hetznerConfig.ServerType
+	if !ServerTypeMismatch(server, requestedType) {
+		return false, nil
+	}
+
+	fields := map[string]any{
+		"server_id":      server.ID,
+		"server_name":    server.Name,
+		"server_type":    server.ServerType,
+		"requested_type": requestedType,
+	}
+
+	if dcm.confirmReplace == nil || !dcm.confirmReplace(server, requestedType) {
+		dcm.logger.WithFields(fields).Warn("Running server type differs from the requested type, keeping it")
+		return false, nil
+	}
+
+	dcm.logger.WithFields(fields).Info("Replacing server with the requested server type")
+
+	// Detach the Docker data and other volumes first so they survive the server
+	for _, volumeID := range server.AttachedVolumes() {
+		if err := dcm.cloudProvider.DetachVolume(ctx, volumeID); err != nil {
+			return false, errors.Wrap(err, "failed to detach volume before replacing server")
+		}
+	}
+
+	if err := dcm.cloudProvider.DestroyServer(ctx, strconv.FormatInt(server.ID, 10)); err != nil {
+		return false, errors.Wrap(err, "failed to destroy server for replacement")
+	}
+	dcm.removeServerTLS(server.Name)
+	dcm.removeKeepAliveToken(server.Name)
+
+	dcm.publish(hooks.NewEvent(hooks.EventServerDestroyed, map[string]string{
+		"server_id":   strconv.FormatInt(server.ID, 10),
+		"server_name": server.Name,
+		"reason":      "server_type_changed",
+	}))
+
+	return true, nil
+}
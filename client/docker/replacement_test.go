@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerTypeMismatch(t *testing.T) {
+	assert.False(t, ServerTypeMismatch(&provider.Server{ServerType: "cpx21"}, "cpx21"))
+	assert.False(t, ServerTypeMismatch(&provider.Server{ServerType: "CPX21"}, "cpx21"))
+	assert.False(t, ServerTypeMismatch(&provider.Server{}, "ccx33"))
+	assert.True(t, ServerTypeMismatch(&provider.Server{ServerType: "cpx21"}, "ccx33"))
+}
+
+func TestReplaceIfMismatched(t *testing.T) {
+	server := &provider.Server{ID: 42, Name: "dockbridge-1", ServerType: "cpx21", VolumeID: "7"}
+
+	newManager := func(mockProvider *MockHetznerClient, serverType string) *dockerClientManagerImpl {
+		return NewDockerClientManager(mockProvider, &config.SSHConfig{}, &config.HetznerConfig{ServerType: serverType}, logger.NewDefault()).(*dockerClientManagerImpl)
+	}
+
+	t.Run("matching type is kept", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		dcm := newManager(mockProvider, "cpx21")
+		dcm.SetServerReplacement(func(*provider.Server, string) bool { return true })
+
+		replaced, err := dcm.replaceIfMismatched(context.Background(), server)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("declined replacement keeps the server", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		dcm := newManager(mockProvider, "ccx33")
+
+		replaced, err := dcm.replaceIfMismatched(context.Background(), server)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+
+		var offered string
+		dcm.SetServerReplacement(func(s *provider.Server, requested string) bool {
+			offered = requested
+			return false
+		})
+		replaced, err = dcm.replaceIfMismatched(context.Background(), server)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+		assert.Equal(t, "ccx33", offered)
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("confirmed replacement preserves the volume", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		mockProvider.On("DetachVolume", mock.Anything, "7").Return(nil).Once()
+		mockProvider.On("DestroyServer", mock.Anything, "42").Return(nil).Once()
+		dcm := newManager(mockProvider, "ccx33")
+		dcm.SetServerReplacement(func(*provider.Server, string) bool { return true })
+
+		replaced, err := dcm.replaceIfMismatched(context.Background(), server)
+		require.NoError(t, err)
+		assert.True(t, replaced)
+		mockProvider.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+var (
+	// errRequestQueueFull is returned when a request finds the queue full
+	errRequestQueueFull = errors.New("too many requests waiting for the remote server")
+	// errRequestQueueTimeout is returned when a request waited longer than the queue's max wait
+	errRequestQueueTimeout = errors.New("timed out waiting for the remote server")
+)
+
+// requestQueue holds Docker API requests while another request connects to, provisions
+// or resumes the remote server. Connection attempts are serialized by a single slot;
+// requests finding it taken wait for it, bounded in number and in time, and then find
+// the connection up.
+type requestQueue struct {
+	slot chan struct{}
+
+	// waiters bounds the requests waiting for the slot; nil disables waiting
+	waiters chan struct{}
+	maxWait time.Duration
+}
+
+// newRequestQueue creates a queue; a nil or zero-depth config disables waiting
+func newRequestQueue(cfg *config.RequestQueueConfig) *requestQueue {
+	q := &requestQueue{slot: make(chan struct{}, 1)}
+	if cfg != nil && cfg.Depth > 0 {
+		q.waiters = make(chan struct{}, cfg.Depth)
+		q.maxWait = cfg.MaxWait
+	}
+	return q
+}
+
+// run calls connect while holding the slot, waiting for it if another request holds it.
+// It fails with errRequestQueueFull or errRequestQueueTimeout instead of waiting when
+// the queue is full or the wait exceeds the max wait.
+func (q *requestQueue) run(ctx context.Context, connect func() error) error {
+	if q == nil {
+		return connect()
+	}
+
+	select {
+	case q.slot <- struct{}{}:
+		defer q.release()
+		return connect()
+	default:
+	}
+
+	if q.waiters == nil {
+		return errRequestQueueFull
+	}
+	select {
+	case q.waiters <- struct{}{}:
+		defer func() { <-q.waiters }()
+	default:
+		return errRequestQueueFull
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, q.maxWait)
+	defer cancel()
+
+	select {
+	case q.slot <- struct{}{}:
+		defer q.release()
+		return connect()
+	case <-waitCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errRequestQueueTimeout
+	}
+}
+
+// release frees the slot for the next request
+func (q *requestQueue) release() {
+	<-q.slot
+}
+
+// waiting returns the number of requests waiting for the slot
+func (q *requestQueue) waiting() int {
+	return len(q.waiters)
+}
+
+// isQueueError reports whether err means the request was not let through the queue
+func isQueueError(err error) bool {
+	return errors.Is(err, errRequestQueueFull) || errors.Is(err, errRequestQueueTimeout)
+}
@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+func TestRequestQueueReplaysWaitingRequests(t *testing.T) {
+	q := newRequestQueue(&config.RequestQueueConfig{Depth: 4, MaxWait: time.Second})
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	var connects atomic.Int32
+	first := make(chan error, 1)
+	go func() {
+		first <- q.run(context.Background(), func() error {
+			connects.Add(1)
+			close(started)
+			<-finish
+			return nil
+		})
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		second <- q.run(context.Background(), func() error {
+			connects.Add(1)
+			return nil
+		})
+	}()
+
+	require.Eventually(t, func() bool { return q.waiting() == 1 }, time.Second, 5*time.Millisecond)
+	close(finish)
+
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+	assert.Equal(t, int32(2), connects.Load())
+	assert.Equal(t, 0, q.waiting())
+}
+
+func TestRequestQueueRejectsWhenFull(t *testing.T) {
+	q := newRequestQueue(&config.RequestQueueConfig{Depth: 1, MaxWait: time.Second})
+	finish := holdSlot(t, q)
+	defer close(finish)
+
+	waiter := make(chan error, 1)
+	go func() {
+		waiter <- q.run(context.Background(), func() error { return nil })
+	}()
+	require.Eventually(t, func() bool { return q.waiting() == 1 }, time.Second, 5*time.Millisecond)
+
+	err := q.run(context.Background(), func() error { return nil })
+	assert.ErrorIs(t, err, errRequestQueueFull)
+	assert.True(t, isQueueError(err))
+}
+
+func TestRequestQueueTimesOut(t *testing.T) {
+	q := newRequestQueue(&config.RequestQueueConfig{Depth: 1, MaxWait: 20 * time.Millisecond})
+	finish := holdSlot(t, q)
+	defer close(finish)
+
+	err := q.run(context.Background(), func() error { return nil })
+	assert.ErrorIs(t, err, errRequestQueueTimeout)
+	assert.Equal(t, 0, q.waiting())
+}
+
+func TestRequestQueueHonorsRequestDeadline(t *testing.T) {
+	q := newRequestQueue(&config.RequestQueueConfig{Depth: 1, MaxWait: time.Minute})
+	finish := holdSlot(t, q)
+	defer close(finish)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := q.run(ctx, func() error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, isQueueError(err))
+}
+
+func TestRequestQueueDisabled(t *testing.T) {
+	q := newRequestQueue(&config.RequestQueueConfig{Depth: 0})
+	finish := holdSlot(t, q)
+	defer close(finish)
+
+	err := q.run(context.Background(), func() error { return nil })
+	assert.ErrorIs(t, err, errRequestQueueFull)
+}
+
+// holdSlot takes the queue's slot until the returned channel is closed
+func holdSlot(t *testing.T, q *requestQueue) chan struct{} {
+	t.Helper()
+	held := make(chan struct{})
+	finish := make(chan struct{})
+	go func() {
+		_ = q.run(context.Background(), func() error {
+			close(held)
+			<-finish
+			return nil
+		})
+	}()
+	<-held
+	return finish
+}
@@ -0,0 +1,264 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheBypassHeader is the request header that forces a cacheable request to the remote daemon.
+// Sending "Cache-Control: no-cache" has the same effect.
+const CacheBypassHeader = "X-DockBridge-No-Cache"
+
+// apiVersionPrefix matches the optional /vX.Y prefix of Docker API paths
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9]+(\.[0-9]+)?/`)
+
+// cacheablePaths are cheap read endpoints that IDE integrations poll constantly
+var cacheablePaths = map[string]bool{
+	"/_ping":       true,
+	"/version":     true,
+	"/images/json": true,
+}
+
+// cachedResponse is a stored response from the remote Docker daemon
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	expires    time.Time
+}
+
+// responseCache caches responses of hot read endpoints for a short TTL so that
+// frequent polling does not add a round-trip over the tunnel every time
+type responseCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// newResponseCache creates a response cache; a non-positive ttl disables caching
+func newResponseCache(ttl time.Duration) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &responseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// get returns a fresh cached response for key
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+// put stores a response for key
+func (c *responseCache) put(key string, resp *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp.expires = c.now().Add(c.ttl)
+	c.entries[key] = resp
+}
+
+// invalidate drops all cached responses
+func (c *responseCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// parseRequestLine splits an HTTP request line into method and target
+func parseRequestLine(requestLine string) (method, target string) {
+	fields := strings.Fields(requestLine)
+	if len(fields) < 2 {
+		return "", ""
+	}
+	return fields[0], fields[1]
+}
+
+// isCacheableTarget reports whether a request to target may be served from the cache
+func isCacheableTarget(method, target string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	path, _, _ := strings.Cut(target, "?")
+	path = apiVersionPrefix.ReplaceAllString(path, "/")
+	return cacheablePaths[path]
+}
+
+// isMutatingMethod reports whether a request may change remote state (and so stale the cache)
+func isMutatingMethod(method string) bool {
+	return method != "" && method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+// bypassesCache reports whether the client asked for a fresh response
+func bypassesCache(req *http.Request) bool {
+	if req.Header.Get(CacheBypassHeader) != "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(req.Header.Get("Cache-Control")), "no-cache")
+}
+
+// cacheKey identifies a cached response
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.RequestURI()
+}
+
+// serveCacheable answers a single cacheable request, from the cache when possible and
+// otherwise from the remote daemon, then closes the connection so the client starts a
+// fresh one for its next request.
+func (d *DockBridgeDaemon) serveCacheable(ctx context.Context, localConn net.Conn, reader io.Reader, connID string) {
+	req, err := http.ReadRequest(bufio.NewReader(reader))
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"error":   err.Error(),
+		}).Debug("Failed to parse cacheable request")
+		return
+	}
+
+	key := cacheKey(req)
+	if !bypassesCache(req) {
+		if cached, ok := d.responseCache.get(key); ok {
+			d.logger.WithFields(map[string]any{
+				"conn_id": connID,
+				"request": key,
+			}).Debug("Serving Docker API response from cache")
+			d.metrics.RequestProxied("cache")
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("dockbridge.cache_hit", true))
+			_ = writeCachedResponse(localConn, req, cached)
+			return
+		}
+	}
+
+	before := d.connState.current().state
+	if err := d.ensureConnection(ctx); err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"request": key,
+			"error":   err.Error(),
+		}).Error("❌ Failed to ensure connection to remote server")
+		d.writeConnectionError(localConn, connID, before, err)
+		return
+	}
+
+	resp, err := d.fetchFromRemote(ctx, req, nil)
+	if err != nil {
+		d.logger.WithFields(map[string]any{
+			"conn_id": connID,
+			"request": key,
+			"error":   err.Error(),
+		}).Error("❌ Failed to fetch Docker API response from remote server")
+		_ = writeDockerError(localConn, http.StatusBadGateway, fmt.Sprintf("DockBridge could not reach the remote Docker daemon: %v", err))
+		return
+	}
+	d.metrics.RequestProxied("remote")
+
+	if resp.statusCode == http.StatusOK {
+		d.responseCache.put(key, resp)
+	}
+	_ = writeCachedResponse(localConn, req, resp)
+}
+
+// fetchFromRemote performs req with body against the remote Docker daemon through the
+// tunnel; the connection must have been ensured
+func (d *DockBridgeDaemon) fetchFromRemote(ctx context.Context, req *http.Request, body []byte) (_ *cachedResponse, err error) {
+	ctx, span := telemetry.Start(ctx, "dockbridge.remote_request")
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := d.getTunnelFromClientManager(); err != nil {
+		return nil, err
+	}
+
+	httpClient := newTunnelHTTPClient(d.clientManager.DialDocker)
+	defer httpClient.CloseIdleConnections()
+
+	var reqBody io.Reader
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	}
+	outReq, err := http.NewRequestWithContext(ctx, req.Method, "http://docker"+req.URL.RequestURI(), reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create remote request")
+	}
+	outReq.Header = req.Header.Clone()
+	outReq.Header.Del(CacheBypassHeader)
+
+	outReq.Header.Del("Content-Length")
+	outReq.Header.Del("Transfer-Encoding")
+
+	resp, err := httpClient.Do(outReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "remote request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read remote response")
+	}
+
+	return &cachedResponse{
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       respBody,
+	}, nil
+}
+
+// writeCachedResponse writes resp to the local client and asks it to close the connection
+func writeCachedResponse(w io.Writer, req *http.Request, resp *cachedResponse) error {
+	header := resp.header.Clone()
+	header.Del("Connection")
+	header.Del("Transfer-Encoding")
+
+	out := &http.Response{
+		StatusCode:    resp.statusCode,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(string(resp.body))),
+		ContentLength: int64(len(resp.body)),
+		Close:         true,
+		Request:       req,
+	}
+	return out.Write(w)
+}
+
+// newTunnelHTTPClient returns an HTTP client whose connections are all opened by dial,
+// which reaches the remote Docker API through the tunnel (with mTLS when enabled)
+func newTunnelHTTPClient(dial func(ctx context.Context) (net.Conn, error)) *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DisableCompression: true,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dial(ctx)
+			},
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCacheableTarget(t *testing.T) {
+	tests := []struct {
+		method   string
+		target   string
+		expected bool
+	}{
+		{http.MethodGet, "/_ping", true},
+		{http.MethodHead, "/_ping", true},
+		{http.MethodGet, "/v1.43/version", true},
+		{http.MethodGet, "/v1.43/images/json?all=false", true},
+		{http.MethodGet, "/v1.43/containers/json", false},
+		{http.MethodPost, "/v1.43/images/json", false},
+		{http.MethodGet, "/v1.43/images/abc/json", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isCacheableTarget(tt.method, tt.target), "%s %s", tt.method, tt.target)
+	}
+}
+
+func TestIsMutatingMethod(t *testing.T) {
+	assert.False(t, isMutatingMethod(http.MethodGet))
+	assert.False(t, isMutatingMethod(http.MethodHead))
+	assert.True(t, isMutatingMethod(http.MethodPost))
+	assert.True(t, isMutatingMethod(http.MethodDelete))
+}
+
+func TestResponseCache(t *testing.T) {
+	assert.Nil(t, newResponseCache(0), "zero TTL disables caching")
+
+	now := time.Now()
+	cache := newResponseCache(2 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	cache.put("GET /_ping", &cachedResponse{statusCode: http.StatusOK, body: []byte("OK")})
+
+	cached, ok := cache.get("GET /_ping")
+	require.True(t, ok)
+	assert.Equal(t, []byte("OK"), cached.body)
+
+	now = now.Add(3 * time.Second)
+	_, ok = cache.get("GET /_ping")
+	assert.False(t, ok, "entry should expire after the TTL")
+
+	cache.put("GET /version", &cachedResponse{statusCode: http.StatusOK})
+	cache.invalidate()
+	_, ok = cache.get("GET /version")
+	assert.False(t, ok, "invalidate should drop all entries")
+}
+
+func TestBypassesCache(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://docker/_ping", nil)
+	assert.False(t, bypassesCache(req))
+
+	req.Header.Set("Cache-Control", "no-cache")
+	assert.True(t, bypassesCache(req))
+
+	req.Header.Del("Cache-Control")
+	req.Header.Set(CacheBypassHeader, "1")
+	assert.True(t, bypassesCache(req))
+}
+
+func TestWriteCachedResponse(t *testing.T) {
+	cached := &cachedResponse{
+		statusCode: http.StatusOK,
+		header:     http.Header{"Api-Version": {"1.43"}, "Connection": {"keep-alive"}},
+		body:       []byte("OK"),
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		t.Run(method, func(t *testing.T) {
+			req, _ := http.NewRequest(method, "http://docker/_ping", nil)
+
+			var buf bytes.Buffer
+			require.NoError(t, writeCachedResponse(&buf, req, cached))
+
+			resp, err := http.ReadResponse(bufio.NewReader(&buf), req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "1.43", resp.Header.Get("Api-Version"))
+			assert.True(t, resp.Close)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if method == http.MethodHead {
+				assert.Empty(t, body)
+			} else {
+				assert.Equal(t, "OK", string(body))
+			}
+		})
+	}
+}
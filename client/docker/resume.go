@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// powerController returns the provider's power control, or nil if servers cannot be stopped
+func (dcm *dockerClientManagerImpl) powerController() provider.PowerController {
+	power, _ := dcm.cloudProvider.(provider.PowerController)
+	return power
+}
+
+// resumeServer powers on a server that was powered off and waits until Docker responds
+func (dcm *dockerClientManagerImpl) resumeServer(ctx context.Context, server *provider.Server) (_ *provider.Server, err error) {
+	ctx, span := telemetry.Start(ctx, "dockbridge.server.resume", attribute.String("dockbridge.server", server.Name))
+	defer func() { telemetry.End(span, err) }()
+
+	dcm.logger.WithFields(map[string]any{
+		"server_id":   server.ID,
+		"server_name": server.Name,
+	}).Info("Resuming stopped DockBridge server")
+	dcm.reportPhase(provider.PhaseResumingServer, fmt.Sprintf("Resuming stopped server %s", server.Name), 10)
+
+	resumed, err := dcm.powerController().PowerOnServer(ctx, strconv.FormatInt(server.ID, 10))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resume server")
+	}
+
+	if err := dcm.waitForServerReady(ctx, resumed, false); err != nil {
+		return nil, errors.Wrap(err, "server resumed but not ready")
+	}
+	return resumed, nil
+}
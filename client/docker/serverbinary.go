@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/version"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// Sources of the dockbridge-server binary of new servers
+const (
+	// ServerBinaryDownload has the server's setup download the release binary
+	ServerBinaryDownload = "download"
+
+	// ServerBinaryUpload has the client upload the binary over SSH once the server is
+	// up, so that servers need no access to the internet
+	ServerBinaryUpload = "upload"
+)
+
+// SetServerBinary selects how new servers get dockbridge-server; nil leaves it to their
+// setup
+func (dcm *dockerClientManagerImpl) SetServerBinary(cfg *config.ServerBinaryConfig) {
+	dcm.serverBinary = cfg
+}
+
+// uploadsServerBinary reports whether the client installs dockbridge-server on new
+// servers
+func (dcm *dockerClientManagerImpl) uploadsServerBinary() bool {
+	return dcm.serverBinary != nil && dcm.serverBinary.Source == ServerBinaryUpload
+}
+
+// serverBinaryCacheDir returns the directory holding release binaries of dockbridge-server
+// downloaded for upload
+func serverBinaryCacheDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dockbridge", "server-binaries")
+	}
+	return filepath.Join(homeDir, ".dockbridge", "server-binaries")
+}
+
+// deployServerBinary uploads dockbridge-server to the server client is connected to and
+// starts it
+func (dcm *dockerClientManagerImpl) deployServerBinary(ctx context.Context, client ssh.Client) error {
+	info, err := selfupdate.InspectServer(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	binary, err := dcm.serverBinaryFor(ctx, info.Arch)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.DeployServer(ctx, client, binary); err != nil {
+		return err
+	}
+
+	dcm.logger.WithFields(map[string]any{
+		"arch": info.Arch,
+		"size": len(binary),
+	}).Info("Uploaded dockbridge-server to the server")
+	return nil
+}
+
+// serverBinaryFor returns the dockbridge-server binary uploaded to servers of arch: the
+// configured local binary, or the release binary of this client's version, which the
+// client downloads instead of the server
+func (dcm *dockerClientManagerImpl) serverBinaryFor(ctx context.Context, arch string) ([]byte, error) {
+	path := dcm.serverBinary.Path
+	if path == "" {
+		binary, err := selfupdate.NewClient().ServerBinary(ctx, version.Tag(), arch, serverBinaryCacheDir())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the dockbridge-server release binary; set server_binary.path to upload a local build")
+		}
+		return binary, nil
+	}
+
+	binary, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read dockbridge-server binary")
+	}
+	if err := selfupdate.CheckServerBinary(binary, arch); err != nil {
+		return nil, errors.Wrapf(err, "cannot upload %s", path)
+	}
+	return binary, nil
+}
@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/version"
+)
+
+// serverVersionTimeout bounds the inspection of the server's dockbridge-server
+const serverVersionTimeout = 30 * time.Second
+
+// checkServerVersion warns when the dockbridge-server binary of the server is not the
+// version of this client, e.g. on servers provisioned by an older client
+func (dcm *dockerClientManagerImpl) checkServerVersion(client ssh.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), serverVersionTimeout)
+	defer cancel()
+
+	info, err := selfupdate.InspectServer(ctx, client)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Debug("Failed to check the dockbridge-server version")
+		return
+	}
+	if info.Version == version.Version {
+		return
+	}
+
+	serverVersion := info.Version
+	if serverVersion == "" {
+		serverVersion = "unknown"
+	}
+	dcm.logger.WithFields(map[string]any{
+		"server_version": serverVersion,
+		"client_version": version.Version,
+	}).Warn(`dockbridge-server on the server differs from this client; update it with "dockbridge server update"`)
+}
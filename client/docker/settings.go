@@ -0,0 +1,42 @@
+package docker
+
+import (
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// Settings are the daemon settings that can change while it runs
+type Settings struct {
+	IdleTimeout       time.Duration
+	ConnectionTimeout time.Duration
+	// KeepAliveInterval is the interval between heartbeats; zero uses the default
+	KeepAliveInterval time.Duration
+	// PortForward replaces the port forwarding configuration; nil keeps it
+	PortForward *config.PortForwardConfig
+}
+
+// ApplySettings applies changed settings to the running daemon's components
+func (d *DockBridgeDaemon) ApplySettings(s Settings) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.running {
+		return
+	}
+
+	d.activityTracker.SetTimeouts(s.IdleTimeout, s.ConnectionTimeout)
+	if d.keepAlive != nil {
+		d.keepAlive.SetInterval(s.KeepAliveInterval)
+	}
+	if s.PortForward != nil {
+		d.clientManager.SetPortForwardConfig(s.PortForward)
+	}
+
+	d.logger.WithFields(map[string]any{
+		"context":            d.config.ContextName,
+		"idle_timeout":       s.IdleTimeout,
+		"connection_timeout": s.ConnectionTimeout,
+		"keepalive_interval": s.KeepAliveInterval,
+	}).Info("Applied reloaded settings")
+}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/daemonstate"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// SetStateStore sets the store the connected server and its forwards are saved in, so
+// that a restarted daemon resumes them; nil disables saving
+func (dcm *dockerClientManagerImpl) SetStateStore(store *daemonstate.Store) {
+	dcm.stateStore = store
+}
+
+// loadState returns the saved state of this context, or nil
+func (dcm *dockerClientManagerImpl) loadState() *daemonstate.Context {
+	if dcm.stateStore == nil {
+		return nil
+	}
+	saved, err := dcm.stateStore.Load(dcm.contextName)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to load daemon state")
+		return nil
+	}
+	return saved
+}
+
+// savedServer returns the server of the saved state if it is still running as it was
+// saved, without listing the provider's servers. A saved server that is gone is
+// forgotten; one that was stopped or must be replaced is left to the full lookup.
+func (dcm *dockerClientManagerImpl) savedServer(ctx context.Context) *provider.Server {
+	saved := dcm.loadState()
+	if saved == nil || saved.Server == nil {
+		return nil
+	}
+
+	fields := map[string]any{
+		"server_id":   saved.Server.ID,
+		"server_name": saved.Server.Name,
+	}
+
+	server, err := dcm.cloudProvider.GetServer(ctx, strconv.FormatInt(saved.Server.ID, 10))
+	if err != nil || server == nil || server.Name != saved.Server.Name || !dcm.serverOwner().Owns(server.Labels) {
+		dcm.logger.WithFields(fields).Info("Saved server no longer exists, looking up servers")
+		dcm.clearState()
+		return nil
+	}
+	if server.Status != "running" || ServerTypeMismatch(server, dcm.hetznerConfig.ServerType) {
+		return nil
+	}
+
+	// A different host key behind the saved address is not the saved server
+	if saved.Server.Fingerprint != "" {
+		if fingerprint := dcm.hostKeyFingerprint(server.IPAddress); fingerprint != "" && fingerprint != saved.Server.Fingerprint {
+			fields["server_ip"] = server.IPAddress
+			dcm.logger.WithFields(fields).Warn("Host key of the saved server changed, looking up servers")
+			return nil
+		}
+	}
+
+	fields["server_ip"] = server.IPAddress
+	dcm.logger.WithFields(fields).Info("Resuming saved DockBridge server")
+	return server
+}
+
+// savedForwards returns the forwards saved while connected to server
+func (dcm *dockerClientManagerImpl) savedForwards(server *provider.Server) []daemonstate.Forward {
+	saved := dcm.loadState()
+	if saved == nil || saved.Server == nil || saved.Server.ID != server.ID {
+		return nil
+	}
+	return saved.Forwards
+}
+
+// restoreForwards re-creates the saved forwards of containers that are still running:
+// the forwards of their published ports, and forwards added by hand
+func (dcm *dockerClientManagerImpl) restoreForwards(ctx context.Context, forwards []daemonstate.Forward) {
+	if len(forwards) == 0 || dcm.portForwardManager == nil || dcm.containerMonitor == nil {
+		return
+	}
+
+	containers, err := dcm.containerMonitor.ListRunningContainers(ctx)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to list containers, saved forwards not restored")
+		return
+	}
+
+	wanted := make(map[string]bool)
+	for _, forward := range forwards {
+		wanted[forward.ContainerID] = true
+	}
+	for _, container := range containers {
+		if wanted[container.ID] {
+			_ = dcm.portForwardManager.OnContainerCreated(container)
+		}
+	}
+
+	active, err := dcm.portForwardManager.ListPortForwards()
+	if err != nil {
+		return
+	}
+	exists := make(map[string]bool, len(active))
+	for _, forward := range active {
+		exists[forwardKey(forward.ContainerID, string(forward.Type), forward.RemotePort)] = true
+	}
+
+	for _, forward := range forwards {
+		if exists[forwardKey(forward.ContainerID, forward.Type, forward.RemotePort)] {
+			continue
+		}
+
+		var err error
+		switch portforward.ForwardType(forward.Type) {
+		case portforward.ForwardTypeTCP:
+			err = dcm.portForwardManager.AddPortForward(forward.ContainerID, forward.LocalPort, forward.RemotePort)
+		case portforward.ForwardTypeUDP:
+			err = dcm.portForwardManager.AddUDPPortForward(forward.ContainerID, forward.LocalPort, forward.RemotePort)
+		default:
+			continue
+		}
+		if err != nil {
+			dcm.logger.WithFields(map[string]any{
+				"forward_id": forward.ID,
+				"error":      err.Error(),
+			}).Debug("Saved forward not restored")
+		}
+	}
+
+	if restored, err := dcm.portForwardManager.ListPortForwards(); err == nil {
+		dcm.logger.WithFields(map[string]any{
+			"saved":    len(forwards),
+			"restored": len(restored),
+		}).Info("Restored saved forwards")
+	}
+}
+
+// forwardKey identifies a forward across restarts; forward IDs are not stable for
+// forwards added by hand
+func forwardKey(containerID, forwardType string, remotePort int) string {
+	return containerID + "/" + forwardType + "/" + strconv.Itoa(remotePort)
+}
+
+// saveState saves the connected server and the forwards of manager, if any
+func (dcm *dockerClientManagerImpl) saveState(server *provider.Server, manager portforward.PortForwardManager) {
+	if dcm.stateStore == nil || server == nil {
+		return
+	}
+
+	saved := daemonstate.Context{
+		Server: &daemonstate.Server{
+			ID:          server.ID,
+			Name:        server.Name,
+			IPAddress:   server.IPAddress,
+			ServerType:  server.ServerType,
+			VolumeIDs:   server.AttachedVolumes(),
+			CreatedAt:   server.CreatedAt,
+			Fingerprint: dcm.hostKeyFingerprint(server.IPAddress),
+		},
+	}
+	if manager != nil {
+		if forwards, err := manager.ListPortForwards(); err == nil {
+			for _, forward := range forwards {
+				saved.Forwards = append(saved.Forwards, daemonstate.Forward{
+					ID:            forward.ID,
+					Type:          string(forward.Type),
+					ContainerID:   forward.ContainerID,
+					ContainerName: forward.ContainerName,
+					LocalPort:     forward.LocalPort,
+					RemotePort:    forward.RemotePort,
+					LocalSocket:   forward.LocalSocket,
+					RemoteSocket:  forward.RemoteSocket,
+				})
+			}
+		}
+	}
+
+	if err := dcm.stateStore.Save(dcm.contextName, saved, time.Now()); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to save daemon state")
+	}
+}
+
+// clearState forgets the saved state of this context
+func (dcm *dockerClientManagerImpl) clearState() {
+	if dcm.stateStore == nil {
+		return
+	}
+	if err := dcm.stateStore.Clear(dcm.contextName); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to clear daemon state")
+	}
+}
+
+// hostKeyFingerprint returns the fingerprint of the trusted host key of ip, or ""
+func (dcm *dockerClientManagerImpl) hostKeyFingerprint(ip string) string {
+	addr := net.JoinHostPort(ip, strconv.Itoa(dcm.sshConfig.Port))
+	fingerprint, err := ssh.NewKnownHosts(expandPath(dcm.sshConfig.KnownHostsPath)).Fingerprint(addr)
+	if err != nil {
+		return ""
+	}
+	return fingerprint
+}
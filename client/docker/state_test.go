@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/daemonstate"
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestSavedServer(t *testing.T) {
+	owner := provider.Owner{ID: "me"}
+	server := &provider.Server{ID: 42, Name: "dockbridge-1", Status: "running", IPAddress: "203.0.113.7", ServerType: "cpx21", VolumeID: "7", Labels: owner.ServerLabels("")}
+
+	newManager := func(t *testing.T, mockProvider *MockHetznerClient) *dockerClientManagerImpl {
+		dir := t.TempDir()
+		sshConfig := &config.SSHConfig{Port: 22, KnownHostsPath: filepath.Join(dir, "known_hosts")}
+		dcm := NewDockerClientManager(mockProvider, sshConfig, &config.HetznerConfig{ServerType: "cpx21"}, logger.NewDefault()).(*dockerClientManagerImpl)
+		dcm.SetStateStore(daemonstate.NewStore(filepath.Join(dir, "daemon.json")))
+		dcm.owner = owner
+		return dcm
+	}
+
+	t.Run("running server is resumed without listing servers", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		mockProvider.On("GetServer", mock.Anything, "42").Return(server, nil).Once()
+		dcm := newManager(t, mockProvider)
+		dcm.saveState(server, nil)
+
+		resumed, err := dcm.getOrProvisionServer(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, server, resumed)
+		mockProvider.AssertExpectations(t)
+		mockProvider.AssertNotCalled(t, "ListServers", mock.Anything)
+	})
+
+	t.Run("gone server is forgotten", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		mockProvider.On("GetServer", mock.Anything, "42").Return((*hetzner.Server)(nil), errors.New("not found")).Once()
+		dcm := newManager(t, mockProvider)
+		dcm.saveState(server, nil)
+
+		assert.Nil(t, dcm.savedServer(context.Background()))
+		assert.Nil(t, dcm.loadState())
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("stopped server is left to the full lookup", func(t *testing.T) {
+		stopped := *server
+		stopped.Status = provider.StatusOff
+		mockProvider := &MockHetznerClient{}
+		mockProvider.On("GetServer", mock.Anything, "42").Return(&stopped, nil).Once()
+		dcm := newManager(t, mockProvider)
+		dcm.saveState(server, nil)
+
+		assert.Nil(t, dcm.savedServer(context.Background()))
+		assert.NotNil(t, dcm.loadState(), "a stopped server is still the context's server")
+	})
+
+	t.Run("changed host key is not trusted", func(t *testing.T) {
+		mockProvider := &MockHetznerClient{}
+		mockProvider.On("GetServer", mock.Anything, "42").Return(server, nil).Once()
+		dcm := newManager(t, mockProvider)
+		require.NoError(t, dcm.stateStore.Save("", daemonstate.Context{
+			Server: &daemonstate.Server{ID: 42, Name: "dockbridge-1", Fingerprint: "SHA256:old"},
+		}, time.Now()))
+
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		key, err := gossh.NewPublicKey(public)
+		require.NoError(t, err)
+		require.NoError(t, ssh.NewKnownHosts(dcm.sshConfig.KnownHostsPath).Trust("203.0.113.7:22", key))
+
+		assert.Nil(t, dcm.savedServer(context.Background()))
+	})
+}
@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// setupTCPListener serves the Docker API on the configured TCP address as well, over
+// TLS when a certificate is configured. It does nothing when the listener is disabled.
+func (d *DockBridgeDaemon) setupTCPListener() error {
+	cfg := d.config.TCPListener
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return errors.Wrap(err, "failed to create TCP listener")
+	}
+
+	tlsConfig, err := tcpListenerTLSConfig(cfg)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	d.tcpListener = listener
+	d.logger.WithFields(map[string]any{
+		"address": listener.Addr().String(),
+		"tls":     tlsConfig != nil,
+	}).Info("Serving the Docker API on TCP")
+	return nil
+}
+
+// tcpListenerTLSConfig loads the listener's certificate and, when a client CA is set,
+// requires clients to present a certificate it signed. It returns nil without a certificate.
+func tcpListenerTLSConfig(cfg *config.TCPListenerConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(expandPath(cfg.CertFile), expandPath(cfg.KeyFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load TCP listener certificate")
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(expandPath(cfg.ClientCAFile)) // #nosec G304
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read TCP listener client CA")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTCPListenerRequiresClientCertificates(t *testing.T) {
+	bundle, err := dockertls.Generate(0)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{"ca.pem": bundle.CACert, "cert.pem": bundle.ServerCert, "key.pem": bundle.ServerKey} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o600))
+	}
+
+	d := &DockBridgeDaemon{logger: logger.NewDefault(), config: &DaemonConfig{TCPListener: &config.TCPListenerConfig{
+		Enabled:      true,
+		Listen:       "127.0.0.1:0",
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}}}
+	require.NoError(t, d.setupTCPListener())
+	defer d.tcpListener.Close()
+
+	// Complete server handshakes in the background
+	go func() {
+		for {
+			conn, err := d.tcpListener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(bundle.CACert)
+	address := d.tcpListener.Addr().String()
+
+	clientConfig, err := bundle.ClientTLSConfig()
+	require.NoError(t, err)
+	conn, err := tls.Dial("tcp", address, clientConfig)
+	require.NoError(t, err)
+	assert.NoError(t, conn.Handshake())
+	conn.Close()
+
+	// Without a client certificate the server aborts the handshake on the first read
+	conn, err = tls.Dial("tcp", address, &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS13})
+	if err == nil {
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	assert.Error(t, err)
+}
+
+func TestSetupTCPListenerDisabled(t *testing.T) {
+	d := &DockBridgeDaemon{logger: logger.NewDefault(), config: &DaemonConfig{TCPListener: &config.TCPListenerConfig{Listen: "127.0.0.1:0"}}}
+	require.NoError(t, d.setupTCPListener())
+	assert.Nil(t, d.tcpListener)
+}
@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+// SetDockerTLS configures mutual TLS with the remote Docker daemon; nil disables it
+func (dcm *dockerClientManagerImpl) SetDockerTLS(cfg *config.DockerTLSConfig) {
+	dcm.dockerTLS = cfg
+}
+
+// mtlsEnabled reports whether new servers get certificates and connections use mTLS.
+// The remote Docker socket is reached without TLS, so it never applies to the unix transport.
+func (dcm *dockerClientManagerImpl) mtlsEnabled() bool {
+	return dcm.dockerTLS != nil && dcm.dockerTLS.Mode != "off" && !dcm.unixTransport()
+}
+
+// certStore returns the store holding per-server client certificates
+func (dcm *dockerClientManagerImpl) certStore() *dockertls.Store {
+	return dockertls.NewStore(expandPath(dcm.dockerTLS.CertDir))
+}
+
+// prepareServerTLS generates the certificates of a server about to be provisioned and
+// stores its client side locally. It returns nil when mTLS is disabled.
+func (dcm *dockerClientManagerImpl) prepareServerTLS(serverName string) (*dockertls.Bundle, error) {
+	if !dcm.mtlsEnabled() {
+		return nil, nil
+	}
+
+	bundle, err := dockertls.Generate(dockertls.DefaultValidity)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate Docker TLS certificates")
+	}
+	if err := dcm.certStore().Save(serverName, bundle); err != nil {
+		return nil, errors.Wrap(err, "failed to store Docker TLS certificates")
+	}
+	return bundle, nil
+}
+
+// removeServerTLS deletes the locally stored certificates of a server
+func (dcm *dockerClientManagerImpl) removeServerTLS(serverName string) {
+	if dcm.dockerTLS == nil {
+		return
+	}
+	if err := dcm.certStore().Remove(serverName); err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_name": serverName,
+			"error":       err.Error(),
+		}).Warn("Failed to remove Docker TLS certificates")
+	}
+}
+
+// loadServerTLS loads the client TLS configuration for server. Servers provisioned
+// without certificates fall back to plaintext over the SSH tunnel.
+func (dcm *dockerClientManagerImpl) loadServerTLS(serverName string) {
+	dcm.tlsConfig = nil
+	if !dcm.mtlsEnabled() {
+		return
+	}
+
+	tlsConfig, err := dcm.certStore().ClientTLSConfig(serverName)
+	if err != nil {
+		dcm.logger.WithFields(map[string]any{
+			"server_name": serverName,
+			"error":       err.Error(),
+		}).Warn("No Docker TLS certificates for server, using plaintext over the SSH tunnel")
+		return
+	}
+
+	dcm.tlsConfig = tlsConfig
+	dcm.logger.WithFields(map[string]any{
+		"server_name": serverName,
+	}).Info("Using mutual TLS for the remote Docker API")
+}
+
+// DialDocker opens a connection to the remote Docker API through the SSH tunnel,
+// performing the mutual TLS handshake when the server has certificates
+func (dcm *dockerClientManagerImpl) DialDocker(ctx context.Context) (net.Conn, error) {
+	if dcm.tunnel == nil {
+		return nil, errors.New("no SSH tunnel available")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", dcm.tunnel.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	if dcm.tlsConfig == nil {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, dcm.tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Docker TLS handshake failed")
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startRequestSpan starts the span covering one Docker API connection of the daemon
+func (d *DockBridgeDaemon) startRequestSpan(method, target string) (context.Context, trace.Span) {
+	path := interceptPath(target)
+	return telemetry.Tracer().Start(d.ctx, "docker "+method+" "+path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", path),
+			attribute.String("dockbridge.context", d.config.ContextName),
+			attribute.Bool("dockbridge.streaming", isStreamingRequest(method, path)),
+		))
+}
+
+// isStreamingRequest reports whether a Docker API request on path streams its body or
+// response, such as builds, image pulls, logs, attach and events, rather than being a
+// regular request-response exchange
+func isStreamingRequest(method, path string) bool {
+	switch {
+	case method == http.MethodPost && (path == "/build" || path == "/images/create" || path == "/images/load"):
+		return true
+	case path == "/events":
+		return true
+	case method == http.MethodPost && strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/push"):
+		return true
+	case strings.HasPrefix(path, "/containers/") && (strings.HasSuffix(path, "/logs") ||
+		strings.HasSuffix(path, "/attach") || strings.HasSuffix(path, "/stats") ||
+		strings.HasSuffix(path, "/archive") || strings.HasSuffix(path, "/export")):
+		return true
+	case method == http.MethodGet && isWebSocketAttach(path):
+		return true
+	case method == http.MethodPost && strings.HasPrefix(path, "/exec/") && strings.HasSuffix(path, "/start"):
+		return true
+	}
+	return false
+}
+
+// firstByteWriter records an event on span when the first response byte arrives, which
+// tells the latency of the tunnel and remote daemon apart from the transfer time
+type firstByteWriter struct {
+	io.Writer
+	span trace.Span
+	seen bool
+}
+
+// Write records the first byte and writes p
+func (w *firstByteWriter) Write(p []byte) (int, error) {
+	if !w.seen && len(p) > 0 {
+		w.seen = true
+		w.span.AddEvent("first response byte")
+	}
+	return w.Writer.Write(p)
+}
@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStreamingRequest(t *testing.T) {
+	tests := []struct {
+		method   string
+		target   string
+		expected bool
+	}{
+		{http.MethodPost, "/v1.43/build?t=app", true},
+		{http.MethodPost, "/v1.43/images/create?fromImage=nginx", true},
+		{http.MethodPost, "/images/registry.example.com/app/push", true},
+		{http.MethodGet, "/v1.43/containers/abc/logs?follow=1", true},
+		{http.MethodPost, "/v1.43/exec/abc/start", true},
+		{http.MethodGet, "/v1.43/containers/abc/attach/ws?stream=1", true},
+		{http.MethodGet, "/events", true},
+		{http.MethodGet, "/v1.43/containers/json", false},
+		{http.MethodPost, "/v1.43/containers/create", false},
+		{http.MethodGet, "/images/abc/json", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isStreamingRequest(tt.method, interceptPath(tt.target)), tt.method+" "+tt.target)
+	}
+}
@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// Remote transports for the Docker API
+const (
+	// RemoteTransportTCP forwards to dockerd listening on TCP 2376 on the server
+	RemoteTransportTCP = "tcp"
+
+	// RemoteTransportUnix forwards to the Docker socket; dockerd does not listen on TCP
+	RemoteTransportUnix = "unix"
+)
+
+const (
+	remoteDockerAPIAddr = "127.0.0.1:2376"
+	remoteDockerSocket  = "/var/run/docker.sock"
+)
+
+// SetRemoteTransport selects how the remote Docker API is reached; empty means TCP
+func (dcm *dockerClientManagerImpl) SetRemoteTransport(transport string) {
+	dcm.remoteTransport = transport
+}
+
+// unixTransport reports whether the Docker API is reached through the remote socket
+func (dcm *dockerClientManagerImpl) unixTransport() bool {
+	return dcm.remoteTransport == RemoteTransportUnix
+}
+
+// createDockerTunnel opens the SSH tunnel to the remote Docker API on a random local port
+func (dcm *dockerClientManagerImpl) createDockerTunnel(ctx context.Context) (ssh.TunnelInterface, error) {
+	localAddr := "127.0.0.1:0" // Use random available port
+	if dcm.unixTransport() {
+		return dcm.sshClient.CreateRemoteUnixTunnel(ctx, localAddr, remoteDockerSocket)
+	}
+	return dcm.sshClient.CreateTunnel(ctx, localAddr, remoteDockerAPIAddr)
+}
+
+// firewallPorts returns the server ports the client reaches over the network: SSH, and
+// the keep-alive port unless heartbeats travel over SSH. The Docker API is tunneled.
+func (dcm *dockerClientManagerImpl) firewallPorts() []int {
+	sshPort := dcm.sshConfig.Port
+	if sshPort == 0 {
+		sshPort = 22
+	}
+	ports := []int{sshPort}
+	if dcm.keepAliveTransport != KeepAliveTransportSSH {
+		ports = append(ports, defaultKeepAlivePort)
+	}
+	return ports
+}
@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnixTransportDisablesMTLS(t *testing.T) {
+	dcm := &dockerClientManagerImpl{}
+	dcm.SetDockerTLS(&config.DockerTLSConfig{Mode: "mtls"})
+	assert.True(t, dcm.mtlsEnabled())
+
+	dcm.SetRemoteTransport(RemoteTransportUnix)
+	assert.False(t, dcm.mtlsEnabled())
+}
+
+func TestFirewallPorts(t *testing.T) {
+	dcm := &dockerClientManagerImpl{sshConfig: &config.SSHConfig{Port: 2222}}
+	assert.Equal(t, []int{2222, defaultKeepAlivePort}, dcm.firewallPorts())
+
+	dcm.SetKeepAliveTransport(KeepAliveTransportSSH)
+	assert.Equal(t, []int{2222}, dcm.firewallPorts())
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/provisioning"
+	"github.com/dockbridge/dockbridge/client/registrymirror"
+	"github.com/dockbridge/dockbridge/pkg/version"
+	"github.com/pkg/errors"
+)
+
+// serverSetup describes what the user data of a new server sets up
+type serverSetup struct {
+	// Name of the server, written to the setup marker once the setup completed
+	Name string
+
+	// PublicKey is authorized for root, which the client connects as
+	PublicKey string
+
+	// InstallDocker is false for servers booted from a golden image
+	InstallDocker bool
+
+	// Volumes are the persistent volumes attached to the server
+	Volumes []provider.VolumeAttachment
+
+	// TLS holds the server certificates when mutual TLS is enabled
+	TLS *dockertls.Bundle
+
+	// KeepAliveToken signs heartbeats to the server
+	KeepAliveToken string
+
+	// Steps are the user-supplied provisioning steps and files
+	Steps *provisioning.Steps
+}
+
+// userData renders the cloud-config a new server is provisioned with
+func (dcm *dockerClientManagerImpl) userData(setup serverSetup) (string, error) {
+	sshPort := cloudinit.DefaultSSHPort
+	if dcm.sshConfig != nil && dcm.sshConfig.Port != 0 {
+		sshPort = dcm.sshConfig.Port
+	}
+
+	model := cloudinit.Config{
+		InstallDocker:       setup.InstallDocker,
+		SSHAuthorizedKeys:   []string{setup.PublicKey},
+		DockerTLS:           setup.TLS,
+		DockerUnixOnly:      dcm.unixTransport(),
+		RegistryMirror:      registrymirror.URL(dcm.registryMirror),
+		RegistryMirrorSetup: registrymirror.SetupScript(dcm.registryMirror),
+		Buildx:              true,
+		SSHPort:             sshPort,
+		ServerVersion:       version.Tag(),
+		UploadServer:        dcm.uploadsServerBinary(),
+		KeepAlivePort:       defaultKeepAlivePort,
+		KeepAliveToken:      setup.KeepAliveToken,
+		KeepAliveOverSSH:    dcm.keepAliveTransport == KeepAliveTransportSSH,
+		OSUpdates:           dcm.osUpdatesScript(),
+		Backup:              dcm.backupScript(),
+		ServerName:          setup.Name,
+	}
+	encrypted := dcm.encryptVolumes()
+	for _, volume := range setup.Volumes {
+		model.Volumes = append(model.Volumes, cloudinit.Volume{
+			Name:      volume.Name,
+			Mount:     volume.Mount,
+			Device:    volume.Device,
+			Encrypted: encrypted,
+		})
+	}
+	setup.Steps.Apply(&model)
+
+	userData, err := cloudinit.Render(model)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render cloud-init")
+	}
+	return userData, nil
+}
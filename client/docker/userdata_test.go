@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/provisioning"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserData(t *testing.T) {
+	bundle, err := dockertls.Generate(0)
+	require.NoError(t, err)
+
+	dcm := &dockerClientManagerImpl{
+		sshConfig: &config.SSHConfig{Port: 2222},
+		hetznerConfig: &config.HetznerConfig{
+			EncryptVolumes: true,
+			Volumes:        []config.VolumeConfig{{Name: "data", Size: 10, Mount: "/data"}},
+		},
+	}
+	dcm.SetRegistryMirror(&config.RegistryMirrorConfig{Enabled: true, Port: 5001, Image: "registry:2"})
+	setup := serverSetup{
+		Name:          "dockbridge-20260101-120000",
+		PublicKey:     "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5 dev@laptop",
+		InstallDocker: true,
+		Volumes: []provider.VolumeAttachment{
+			{ID: "2", Name: "data", Mount: "/data", Device: "/dev/disk/by-id/scsi-0HC_Volume_2"},
+		},
+		TLS:            bundle,
+		KeepAliveToken: "abc123",
+		Steps:          &provisioning.Steps{PostDocker: []string{"bash " + provisioning.StepDir + "/post-docker-01"}},
+	}
+
+	userData, err := dcm.userData(setup)
+	require.NoError(t, err)
+	require.NoError(t, cloudinit.Validate(userData))
+
+	assert.Contains(t, userData, `grep -qxF 'ssh-ed25519 AAAAC3NzaC1lZDI1NTE5 dev@laptop' /root/.ssh/authorized_keys`)
+	assert.Contains(t, userData, `"tcp://0.0.0.0:2376"`)
+	assert.Contains(t, userData, `"tlsverify": true`)
+	assert.Contains(t, userData, dockertls.RemoteServerKey)
+	assert.Contains(t, userData, `"registry-mirrors": ["http://127.0.0.1:5001"]`)
+	assert.Contains(t, userData, "-p 127.0.0.1:5001:5000")
+	assert.Contains(t, userData, "docker buildx version")
+	assert.Contains(t, userData, `echo "/dev/disk/by-id/scsi-0HC_Volume_2" > /etc/dockbridge/volumes/data.device`)
+	assert.NotContains(t, userData, `mkfs.ext4 -F -L "data"`, "encrypted volumes are formatted once unlocked")
+	assert.Contains(t, userData, "DOCKBRIDGE_AUTH_TOKEN=abc123")
+	assert.Contains(t, userData, "ufw allow 2222/tcp")
+	assert.Contains(t, userData, "ufw allow 8080/tcp")
+	assert.Contains(t, userData, `- "bash `+provisioning.StepDir+`/post-docker-01"`)
+	assert.Contains(t, userData, `echo 'dockbridge-20260101-120000' > `+setupMarkerPath)
+
+	t.Run("unix transport", func(t *testing.T) {
+		dcm.SetRemoteTransport(RemoteTransportUnix)
+		dcm.SetKeepAliveTransport(KeepAliveTransportSSH)
+		defer dcm.SetRemoteTransport("")
+		defer dcm.SetKeepAliveTransport("")
+
+		userData, err := dcm.userData(setup)
+		require.NoError(t, err)
+		assert.Contains(t, userData, `"hosts": ["unix:///var/run/docker.sock"],`)
+		assert.NotContains(t, userData, "tcp://0.0.0.0")
+		assert.NotContains(t, userData, `"tlsverify"`)
+		assert.Contains(t, userData, "DOCKBRIDGE_LOOPBACK_ONLY=true")
+		assert.Contains(t, userData, "ufw deny 8080/tcp")
+	})
+
+	t.Run("golden image", func(t *testing.T) {
+		setup := setup
+		setup.InstallDocker = false
+		userData, err := dcm.userData(setup)
+		require.NoError(t, err)
+		assert.NotContains(t, userData, "apt-get install -y docker-ce")
+	})
+}
@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/pkg/errors"
+)
+
+// volumeDeviceDir is where servers record the devices of encrypted volumes
+const volumeDeviceDir = cloudinit.EncryptedVolumeDir
+
+// volumeKeyDir returns the directory holding the encryption keys of volumes
+func volumeKeyDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dockbridge", "volume-keys")
+	}
+	return filepath.Join(homeDir, ".dockbridge", "volume-keys")
+}
+
+// volumeKeyPath returns the file holding the encryption key of the named volume
+func volumeKeyPath(name string) string {
+	return filepath.Join(volumeKeyDir(), name+".key")
+}
+
+// volumeKey returns the encryption key of the named volume, generating and storing one
+// when the volume has none yet. Keys never leave the client except over SSH, so losing
+// one loses the data on its volume.
+func volumeKey(name string) ([]byte, error) {
+	path := volumeKeyPath(name)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return bytes.TrimSpace(key), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to read volume key")
+	}
+
+	random := make([]byte, 64)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.Wrap(err, "failed to generate volume key")
+	}
+	key = []byte(base64.RawStdEncoding.EncodeToString(random))
+	if err := os.MkdirAll(volumeKeyDir(), 0700); err != nil {
+		return nil, errors.Wrap(err, "failed to create volume key directory")
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, errors.Wrap(err, "failed to store volume key")
+	}
+	return key, nil
+}
+
+// encryptVolumes reports whether the configured volumes are encrypted
+func (dcm *dockerClientManagerImpl) encryptVolumes() bool {
+	return dcm.hetznerConfig != nil && dcm.hetznerConfig.EncryptVolumes && len(dcm.hetznerConfig.Volumes) > 0
+}
+
+// unlockVolumes unlocks and mounts the encrypted volumes of the connected server with
+// keys held by the client. It runs on every connection, since a server that was
+// replaced or rebooted cannot unlock its volumes by itself.
+func (dcm *dockerClientManagerImpl) unlockVolumes(ctx context.Context) error {
+	if !dcm.encryptVolumes() {
+		return nil
+	}
+
+	for _, volumeCfg := range dcm.hetznerConfig.Volumes {
+		key, err := volumeKey(volumeName(dcm.contextName, volumeCfg.Name))
+		if err != nil {
+			return err
+		}
+
+		output, err := dcm.sshClient.ExecuteCommandWithInput(ctx, unlockVolumeCommand(volumeCfg.Name, volumeCfg.Mount), key)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unlock volume %s: %s", volumeCfg.Name, strings.TrimSpace(string(output)))
+		}
+
+		dcm.logger.WithFields(map[string]any{
+			"volume_name": volumeCfg.Name,
+			"mount":       volumeCfg.Mount,
+			"output":      strings.TrimSpace(string(output)),
+		}).Info("Encrypted volume ready")
+	}
+	return nil
+}
+
+// unlockVolumeCommand returns the command unlocking and mounting an encrypted volume
+// with the key read from stdin. A blank volume is encrypted first; a volume already
+// holding an unencrypted filesystem is refused rather than wiped. The key is only
+// passed through pipes, never written to the server's disk.
+func unlockVolumeCommand(name, mount string) string {
+	return fmt.Sprintf(`set -e
+key=$(cat)
+record=%[4]s/%[1]s.device
+mapper=dockbridge-%[1]s
+if [ ! -f "$record" ]; then
+    echo "volume %[1]s is not encrypted on this server"
+    exit 0
+fi
+dev=$(cat "$record")
+if [ "$(findmnt -n -o SOURCE --mountpoint %[2]s)" = "/dev/mapper/$mapper" ]; then
+    echo "volume %[1]s is unlocked"
+    exit 0
+fi
+for i in {1..60}; do
+    [ -e "$dev" ] && break
+    sleep 2
+done
+if ! cryptsetup isLuks "$dev"; then
+    if blkid "$dev" >/dev/null 2>&1; then
+        echo "volume %[1]s holds an unencrypted filesystem; refusing to encrypt it" >&2
+        exit 1
+    fi
+    printf '%%s' "$key" | cryptsetup luksFormat --type luks2 --batch-mode --key-file=- "$dev"
+fi
+[ -e "/dev/mapper/$mapper" ] || printf '%%s' "$key" | cryptsetup open --key-file=- "$dev" "$mapper"
+blkid "/dev/mapper/$mapper" >/dev/null 2>&1 || mkfs.ext4 -F -L "%[3]s" "/dev/mapper/$mapper"
+systemctl stop docker.socket docker 2>/dev/null || true
+mkdir -p %[2]s
+mount "/dev/mapper/$mapper" %[2]s
+resize2fs "/dev/mapper/$mapper" || true
+systemctl start docker.socket docker 2>/dev/null || true
+echo "volume %[1]s unlocked at %[2]s"`, name, mount, volumeLabel(name), volumeDeviceDir)
+}
@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	key, err := volumeKey("gpu-data")
+	require.NoError(t, err)
+	assert.Len(t, key, 86, "64 random bytes, base64 encoded")
+
+	info, err := os.Stat(volumeKeyPath("gpu-data"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := volumeKey("gpu-data")
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the stored key is reused")
+
+	other, err := volumeKey("gpu-docker-data")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other, "each volume has its own key")
+}
+
+func TestUnlockVolumeCommand(t *testing.T) {
+	command := unlockVolumeCommand("docker-data", "/var/lib/docker")
+
+	assert.Contains(t, command, "key=$(cat)", "the key is read from stdin")
+	assert.Contains(t, command, "record=/etc/dockbridge/volumes/docker-data.device")
+	assert.Contains(t, command, `printf '%s' "$key" | cryptsetup luksFormat --type luks2 --batch-mode --key-file=- "$dev"`)
+	assert.Contains(t, command, `printf '%s' "$key" | cryptsetup open --key-file=- "$dev" "$mapper"`)
+	assert.Contains(t, command, "refusing to encrypt it", "unencrypted data is never wiped")
+	assert.Contains(t, command, `mount "/dev/mapper/$mapper" /var/lib/docker`)
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// prepareVolumes finds or creates the named volumes configured in hetzner.volumes for
+// a new server. Volumes left behind by earlier servers of the context are reused, so
+// their data survives server replacement. Without configured volumes it returns nil.
+func (dcm *dockerClientManagerImpl) prepareVolumes(ctx context.Context) ([]provider.VolumeAttachment, error) {
+	if dcm.hetznerConfig == nil || len(dcm.hetznerConfig.Volumes) == 0 {
+		return nil, nil
+	}
+
+	named, ok := dcm.cloudProvider.(provider.NamedVolumeProvider)
+	if !ok {
+		return nil, errors.New("the configured provider does not support named volumes")
+	}
+
+	attachments := make([]provider.VolumeAttachment, 0, len(dcm.hetznerConfig.Volumes))
+	for _, volumeCfg := range dcm.hetznerConfig.Volumes {
+		volume, err := named.FindOrCreateNamedVolume(ctx, volumeName(dcm.contextName, volumeCfg.Name), volumeCfg.Size, dcm.hetznerConfig.Location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prepare volume %s", volumeCfg.Name)
+		}
+
+		dcm.logger.WithFields(map[string]any{
+			"volume_id":   volume.ID,
+			"volume_name": volume.Name,
+			"mount":       volumeCfg.Mount,
+		}).Info("Attaching volume to new server")
+
+		attachments = append(attachments, provider.VolumeAttachment{
+			ID:     volume.ID,
+			Name:   volumeCfg.Name,
+			Mount:  volumeCfg.Mount,
+			Device: volume.Device,
+		})
+	}
+	return attachments, nil
+}
+
+// volumeName scopes a volume name to a context so that contexts in the same location
+// do not share volumes
+func volumeName(contextName, name string) string {
+	if contextName == "" {
+		return name
+	}
+	return contextName + "-" + name
+}
+
+// volumeLabel returns the filesystem label of a volume; ext4 labels are limited to 16 bytes
+func volumeLabel(name string) string {
+	if len(name) > 16 {
+		return name[:16]
+	}
+	return name
+}
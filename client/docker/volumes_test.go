@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedVolumeProvider returns a volume per name, recording the requests
+type namedVolumeProvider struct {
+	provider.CloudProvider
+	requested []string
+}
+
+func (p *namedVolumeProvider) FindOrCreateNamedVolume(ctx context.Context, name string, size int, location string) (*provider.Volume, error) {
+	p.requested = append(p.requested, name)
+	return &provider.Volume{ID: "vol-" + name, Name: "dockbridge-" + name, Size: size, Location: location, Device: "/dev/disk/by-id/" + name}, nil
+}
+
+func TestPrepareVolumes(t *testing.T) {
+	hetznerConfig := &config.HetznerConfig{Location: "fsn1"}
+	cloudProvider := &namedVolumeProvider{}
+	dcm := NewDockerClientManager(cloudProvider, &config.SSHConfig{}, hetznerConfig, logger.NewDefault()).(*dockerClientManagerImpl)
+
+	volumes, err := dcm.prepareVolumes(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, volumes, "no volumes without configuration")
+
+	hetznerConfig.Volumes = []config.VolumeConfig{
+		{Name: "docker-data", Size: 20, Mount: "/var/lib/docker"},
+		{Name: "data", Size: 50, Mount: "/data"},
+	}
+	dcm.contextName = "gpu"
+	volumes, err = dcm.prepareVolumes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu-docker-data", "gpu-data"}, cloudProvider.requested, "volumes are scoped to the context")
+	require.Len(t, volumes, 2)
+	assert.Equal(t, provider.VolumeAttachment{ID: "vol-gpu-data", Name: "data", Mount: "/data", Device: "/dev/disk/by-id/gpu-data"}, volumes[1])
+
+	// Providers without named volumes cannot honour the configuration
+	dcm.cloudProvider = &MockHetznerClient{}
+	_, err = dcm.prepareVolumes(context.Background())
+	assert.ErrorContains(t, err, "does not support named volumes")
+}
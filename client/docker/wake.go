@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/pkg/errors"
+)
+
+// recoverConnection re-checks the server in use after the client resumed, changed
+// networks or lost its heartbeats. A server that destroyed itself meanwhile is
+// forgotten and, since it was in use, replaced right away; a server that still exists
+// is probed and reconnected. Heartbeats resume either way.
+func (d *DockBridgeDaemon) recoverConnection(ctx context.Context) error {
+	d.recoverMu.Lock()
+	defer d.recoverMu.Unlock()
+
+	srv := d.clientManager.CurrentServer()
+	if srv == nil {
+		// No server in use; the next Docker command provisions or resumes one
+		return nil
+	}
+
+	exists, err := d.serverExists(ctx, srv.ID)
+	if err != nil {
+		// The provider may be unreachable until the network is back; probing the
+		// connection is still worthwhile
+		d.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to check whether the server still exists")
+		exists = true
+	}
+
+	if exists {
+		if err := d.clientManager.RevalidateConnection(ctx); err != nil {
+			return err
+		}
+		d.keepAlive.Resume()
+		return nil
+	}
+
+	d.logger.WithFields(map[string]any{
+		"server_id":   srv.ID,
+		"server_name": srv.Name,
+	}).Warn("Server destroyed itself while the client was away")
+	d.handleIdleShutdown()
+
+	if d.config.KeepAlive == nil || !d.config.KeepAlive.ReprovisionOnWake {
+		return nil
+	}
+
+	d.notifier.Notify(notify.EventSelfDestruct, d.notificationTitle("Replacing remote server"),
+		fmt.Sprintf("Server %s destroyed itself while the client was away; provisioning a new one", srv.Name))
+	return d.ensureConnection(ctx)
+}
+
+// serverExists reports whether the provider still lists the server with id
+func (d *DockBridgeDaemon) serverExists(ctx context.Context, id int64) (bool, error) {
+	if d.config.Provider == nil {
+		return true, nil
+	}
+	servers, err := d.config.Provider.ListServers(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list servers")
+	}
+	for _, srv := range servers {
+		if srv.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/heartbeat"
+	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recoveringClientManager records revalidations and disconnects
+type recoveringClientManager struct {
+	DockerClientManager
+	server       *provider.Server
+	revalidated  int
+	disconnected int
+}
+
+func (m *recoveringClientManager) CurrentServer() *provider.Server { return m.server }
+
+func (m *recoveringClientManager) RevalidateConnection(context.Context) error {
+	m.revalidated++
+	return nil
+}
+
+func (m *recoveringClientManager) Disconnect() {
+	m.disconnected++
+	m.server = nil
+}
+
+func newRecoveringDaemon(p provider.CloudProvider, dcm DockerClientManager) *DockBridgeDaemon {
+	log := logger.NewDefault()
+	return &DockBridgeDaemon{
+		config:        &DaemonConfig{Provider: p, KeepAlive: &config.KeepAliveConfig{}},
+		logger:        log,
+		clientManager: dcm,
+		connState:     newConnectionTracker(nil),
+		notifier:      notify.NopNotifier{},
+		keepAlive:     heartbeat.NewKeepAliveService(nil, func() heartbeat.Sender { return nil }, log),
+	}
+}
+
+func TestRecoverConnectionRevalidatesExistingServer(t *testing.T) {
+	mockProvider := &MockHetznerClient{}
+	mockProvider.On("ListServers", mock.Anything).Return([]*hetzner.Server{{ID: 7}}, nil)
+	dcm := &recoveringClientManager{server: &provider.Server{ID: 7, Name: "dockbridge-7"}}
+	d := newRecoveringDaemon(mockProvider, dcm)
+	d.keepAlive.Pause()
+
+	assert.NoError(t, d.recoverConnection(context.Background()))
+	assert.Equal(t, 1, dcm.revalidated)
+	assert.Zero(t, dcm.disconnected)
+	assert.False(t, d.keepAlive.Status().Paused, "heartbeats resume")
+}
+
+func TestRecoverConnectionForgetsDestroyedServer(t *testing.T) {
+	mockProvider := &MockHetznerClient{}
+	mockProvider.On("ListServers", mock.Anything).Return([]*hetzner.Server{}, nil)
+	dcm := &recoveringClientManager{server: &provider.Server{ID: 7, Name: "dockbridge-7"}}
+	d := newRecoveringDaemon(mockProvider, dcm)
+
+	// With reprovision_on_wake off the server is only forgotten
+	assert.NoError(t, d.recoverConnection(context.Background()))
+	assert.Zero(t, dcm.revalidated)
+	assert.Equal(t, 1, dcm.disconnected)
+	assert.True(t, d.keepAlive.Status().Paused, "no heartbeats for a destroyed server")
+
+	// Nothing to recover once no server is in use
+	assert.NoError(t, d.recoverConnection(context.Background()))
+	mockProvider.AssertNumberOfCalls(t, "ListServers", 1)
+}
@@ -0,0 +1,176 @@
+// Package dockercontext registers DockBridge sockets as Docker CLI contexts. It writes
+// the Docker CLI's context store directly, so the docker binary is not required.
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultName is the name of the context registered for the default daemon
+const DefaultName = "dockbridge"
+
+// DefaultConfigDir returns the Docker CLI configuration directory ($DOCKER_CONFIG or ~/.docker)
+func DefaultConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(homeDir, ".docker")
+}
+
+// Store manages contexts in a Docker CLI configuration directory
+type Store struct {
+	configDir string
+}
+
+// NewStore creates a store for the given Docker CLI configuration directory
+func NewStore(configDir string) *Store {
+	return &Store{configDir: configDir}
+}
+
+// metadata is the meta.json document of a Docker CLI context
+type metadata struct {
+	Name      string              `json:"Name"`
+	Metadata  contextMetadata     `json:"Metadata"`
+	Endpoints map[string]endpoint `json:"Endpoints"`
+}
+
+type contextMetadata struct {
+	Description string `json:"Description,omitempty"`
+}
+
+type endpoint struct {
+	Host          string `json:"Host"`
+	SkipTLSVerify bool   `json:"SkipTLSVerify"`
+}
+
+// metaDir returns the directory of a context; the Docker CLI names it by the
+// SHA-256 digest of the context name
+func (s *Store) metaDir(name string) string {
+	digest := sha256.Sum256([]byte(name))
+	return filepath.Join(s.configDir, "contexts", "meta", hex.EncodeToString(digest[:]))
+}
+
+// Create creates or updates a context whose Docker endpoint is host (e.g. unix:///path)
+func (s *Store) Create(name, host, description string) error {
+	dir := s.metaDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create context directory: %w", err)
+	}
+
+	data, err := json.Marshal(metadata{
+		Name:      name,
+		Metadata:  contextMetadata{Description: description},
+		Endpoints: map[string]endpoint{"docker": {Host: host}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode context metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write context metadata: %w", err)
+	}
+	return nil
+}
+
+// Host returns the Docker endpoint of a context; the error wraps os.ErrNotExist if
+// the context does not exist
+func (s *Store) Host(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.metaDir(name), "meta.json")) // #nosec G304
+	if err != nil {
+		return "", fmt.Errorf("failed to read context %s: %w", name, err)
+	}
+
+	var meta metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return "", fmt.Errorf("failed to parse context %s: %w", name, err)
+	}
+	return meta.Endpoints["docker"].Host, nil
+}
+
+// Remove deletes a context; removing a missing context is not an error
+func (s *Store) Remove(name string) error {
+	if err := os.RemoveAll(s.metaDir(name)); err != nil {
+		return fmt.Errorf("failed to remove context %s: %w", name, err)
+	}
+	return nil
+}
+
+// Current returns the context selected in the Docker CLI configuration, or "" for the default
+func (s *Store) Current() (string, error) {
+	cfg, err := s.readConfig()
+	if err != nil {
+		return "", err
+	}
+
+	var current string
+	if raw, ok := cfg["currentContext"]; ok {
+		if err := json.Unmarshal(raw, &current); err != nil {
+			return "", fmt.Errorf("failed to parse currentContext: %w", err)
+		}
+	}
+	return current, nil
+}
+
+// Use selects a context in the Docker CLI configuration; "" or "default" selects the
+// default context. Other settings in config.json are preserved.
+func (s *Store) Use(name string) error {
+	cfg, err := s.readConfig()
+	if err != nil {
+		return err
+	}
+
+	if name == "" || name == "default" {
+		delete(cfg, "currentContext")
+	} else {
+		raw, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		cfg["currentContext"] = raw
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode Docker CLI config: %w", err)
+	}
+	if err := os.MkdirAll(s.configDir, 0700); err != nil {
+		return fmt.Errorf("failed to create Docker CLI config directory: %w", err)
+	}
+	if err := os.WriteFile(s.configPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write Docker CLI config: %w", err)
+	}
+	return nil
+}
+
+// configPath returns the path of the Docker CLI config.json
+func (s *Store) configPath() string {
+	return filepath.Join(s.configDir, "config.json")
+}
+
+// readConfig reads config.json as raw fields so unknown settings survive a rewrite
+func (s *Store) readConfig() (map[string]json.RawMessage, error) {
+	cfg := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(s.configPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read Docker CLI config: %w", err)
+	}
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker CLI config: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,111 @@
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreContexts(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.NoError(t, store.Create("dockbridge", "unix:///tmp/a.sock", "test"))
+	host, err := store.Host("dockbridge")
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///tmp/a.sock", host)
+
+	// Creating again updates the endpoint
+	require.NoError(t, store.Create("dockbridge", "unix:///tmp/b.sock", "test"))
+	host, err = store.Host("dockbridge")
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///tmp/b.sock", host)
+
+	// The Docker CLI names context directories by the SHA-256 digest of the name
+	digest := sha256.Sum256([]byte("dockbridge"))
+	data, err := os.ReadFile(filepath.Join(store.configDir, "contexts", "meta", hex.EncodeToString(digest[:]), "meta.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"dockbridge","Metadata":{"Description":"test"},"Endpoints":{"docker":{"Host":"unix:///tmp/b.sock","SkipTLSVerify":false}}}`, string(data))
+
+	require.NoError(t, store.Remove("dockbridge"))
+	_, err = store.Host("dockbridge")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	require.NoError(t, store.Remove("dockbridge"))
+}
+
+func TestStoreUsePreservesConfig(t *testing.T) {
+	dir := t.TempDir()
+	original := `{"auths":{"registry.example.com":{"auth":"secret"}},"currentContext":"colima"}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(original), 0600))
+	store := NewStore(dir)
+
+	current, err := store.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "colima", current)
+
+	require.NoError(t, store.Use("dockbridge"))
+	current, err = store.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "dockbridge", current)
+
+	require.NoError(t, store.Use("default"))
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	var cfg map[string]any
+	require.NoError(t, json.Unmarshal(data, &cfg))
+	assert.NotContains(t, cfg, "currentContext")
+	assert.Contains(t, cfg, "auths")
+}
+
+func TestRegistration(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"colima"}`), 0600))
+	store := NewStore(dir)
+
+	endpoints := []Endpoint{
+		{Name: "dockbridge", SocketPath: "/tmp/dockbridge.sock"},
+		{Name: "dockbridge-gpu", SocketPath: "/tmp/dockbridge-gpu.sock"},
+	}
+	registration, err := Register(store, endpoints, "dockbridge-gpu")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dockbridge", "dockbridge-gpu"}, registration.Names())
+	assert.Equal(t, "dockbridge-gpu", registration.Switched())
+
+	current, err := store.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "dockbridge-gpu", current)
+
+	// Shutdown restores the previous selection and removes the contexts
+	require.NoError(t, registration.Unregister(true))
+	current, err = store.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "colima", current)
+	_, err = store.Host("dockbridge-gpu")
+	assert.Error(t, err)
+
+	_, err = Register(store, endpoints, "unknown")
+	assert.Error(t, err)
+}
+
+func TestRegistrationKeepsUserSelection(t *testing.T) {
+	store := NewStore(t.TempDir())
+	registration, err := Register(store, []Endpoint{{Name: "dockbridge", SocketPath: "/tmp/d.sock"}}, "dockbridge")
+	require.NoError(t, err)
+
+	// The user switched away while the client was running
+	require.NoError(t, store.Use("colima"))
+	require.NoError(t, registration.Unregister(false))
+
+	current, err := store.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "colima", current)
+	host, err := store.Host("dockbridge")
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///tmp/d.sock", host)
+}
@@ -0,0 +1,90 @@
+package dockercontext
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dockbridge/dockbridge/client/localsocket"
+)
+
+// Endpoint is a local DockBridge socket to expose as a Docker CLI context
+type Endpoint struct {
+	Name       string
+	SocketPath string
+}
+
+// Registration tracks contexts registered by a running client so they can be
+// cleaned up on shutdown
+type Registration struct {
+	store    *Store
+	names    []string
+	switched string // context selected by Register, if any
+	previous string // context selected before switching
+}
+
+// Register creates or updates a context for each endpoint. If use names one of the
+// endpoints, the Docker CLI is switched to it and the previous selection is remembered.
+func Register(store *Store, endpoints []Endpoint, use string) (*Registration, error) {
+	r := &Registration{store: store}
+	for _, ep := range endpoints {
+		if err := store.Create(ep.Name, localsocket.DockerHost(ep.SocketPath), "DockBridge remote Docker daemon"); err != nil {
+			return r, err
+		}
+		r.names = append(r.names, ep.Name)
+	}
+
+	if use == "" {
+		return r, nil
+	}
+	if !slices.Contains(r.names, use) {
+		return r, fmt.Errorf("context %s is not registered by DockBridge", use)
+	}
+
+	previous, err := store.Current()
+	if err != nil {
+		return r, err
+	}
+	if previous == use {
+		return r, nil
+	}
+	if err := store.Use(use); err != nil {
+		return r, err
+	}
+	r.switched, r.previous = use, previous
+	return r, nil
+}
+
+// Names returns the registered context names
+func (r *Registration) Names() []string {
+	return r.names
+}
+
+// Switched returns the context the Docker CLI was switched to, or "" if it was left alone
+func (r *Registration) Switched() string {
+	return r.switched
+}
+
+// Unregister restores the Docker CLI's previous context if DockBridge switched it and
+// the user has not switched since, and removes the registered contexts if remove is set.
+func (r *Registration) Unregister(remove bool) error {
+	var errs []error
+
+	current, err := r.store.Current()
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case r.switched != "" && current == r.switched:
+		errs = append(errs, r.store.Use(r.previous))
+	case remove && slices.Contains(r.names, current):
+		// Never leave the Docker CLI pointing at a context that no longer exists
+		errs = append(errs, r.store.Use(""))
+	}
+
+	if remove {
+		for _, name := range r.names {
+			errs = append(errs, r.store.Remove(name))
+		}
+	}
+	return errors.Join(errs...)
+}
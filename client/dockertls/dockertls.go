@@ -0,0 +1,184 @@
+// Package dockertls generates and stores the certificates used for mutual TLS between
+// the client and the remote Docker daemon. A private CA is created per server; the
+// daemon only accepts clients presenting a certificate signed by it.
+package dockertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// ServerName is the name the daemon certificate is issued for. Clients connect through
+// tunnels and forwarded ports, so the certificate is not tied to an address.
+const ServerName = "dockbridge-docker"
+
+// DefaultValidity is how long generated certificates are valid
+const DefaultValidity = 5 * 365 * 24 * time.Hour
+
+// Remote paths of the daemon's TLS material
+const (
+	RemoteDir        = "/etc/docker/tls"
+	RemoteCACert     = RemoteDir + "/ca.pem"
+	RemoteServerCert = RemoteDir + "/server-cert.pem"
+	RemoteServerKey  = RemoteDir + "/server-key.pem"
+)
+
+// Bundle holds PEM encoded certificates and keys for one server
+type Bundle struct {
+	CACert     []byte
+	ServerCert []byte
+	ServerKey  []byte
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// Generate creates a CA and a server and client certificate signed by it
+func Generate(validity time.Duration) (*Bundle, error) {
+	if validity <= 0 {
+		validity = DefaultValidity
+	}
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(validity)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "DockBridge CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caDER, caCert, err := sign(caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	serverCert, serverKey, err := issue(caCert, caKey, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: ServerName},
+		DNSNames:    []string{ServerName, "localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server certificate: %w", err)
+	}
+
+	clientCert, clientKey, err := issue(caCert, caKey, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "dockbridge-client"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client certificate: %w", err)
+	}
+
+	return &Bundle{
+		CACert:     pemBlock("CERTIFICATE", caDER),
+		ServerCert: serverCert,
+		ServerKey:  serverKey,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+	}, nil
+}
+
+// ClientTLSConfig returns the TLS configuration for connecting to the daemon
+func (b *Bundle) ClientTLSConfig() (*tls.Config, error) {
+	return clientTLSConfig(b.CACert, b.ClientCert, b.ClientKey)
+}
+
+// clientTLSConfig builds a client configuration that verifies the daemon against caPEM
+func clientTLSConfig(caPEM, certPEM, keyPEM []byte) (*tls.Config, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("invalid CA certificate")
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// issue creates a key pair and a certificate for it signed by the CA
+func issue(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, template *x509.Certificate) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, _, err := sign(template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pemBlock("CERTIFICATE", der), pemBlock("EC PRIVATE KEY", keyDER), nil
+}
+
+// sign creates a certificate from template signed by parent
+func sign(template, parent *x509.Certificate, pub *ecdsa.PublicKey, signer *ecdsa.PrivateKey) ([]byte, *x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return der, cert, nil
+}
+
+func pemBlock(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// InstallScript returns a shell snippet that installs the daemon's CA, certificate and key
+func (b *Bundle) InstallScript() string {
+	return fmt.Sprintf(`mkdir -p %[1]s
+chmod 700 %[1]s
+cat > %[2]s << 'DOCKBRIDGE_TLS_EOF'
+%[3]sDOCKBRIDGE_TLS_EOF
+cat > %[4]s << 'DOCKBRIDGE_TLS_EOF'
+%[5]sDOCKBRIDGE_TLS_EOF
+cat > %[6]s << 'DOCKBRIDGE_TLS_EOF'
+%[7]sDOCKBRIDGE_TLS_EOF
+chmod 600 %[6]s
+`, RemoteDir, RemoteCACert, b.CACert, RemoteServerCert, b.ServerCert, RemoteServerKey, b.ServerKey)
+}
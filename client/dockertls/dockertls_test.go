@@ -0,0 +1,153 @@
+package dockertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// serverTLSConfig mirrors dockerd --tlsverify using the bundle's server side
+func serverTLSConfig(t *testing.T, b *Bundle) *tls.Config {
+	t.Helper()
+	cert, err := tls.X509KeyPair(b.ServerCert, b.ServerKey)
+	if err != nil {
+		t.Fatalf("failed to load server key pair: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b.CACert) {
+		t.Fatal("failed to parse CA certificate")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// handshake connects a client to a TLS server on loopback and exchanges one message.
+// It returns the first error seen by either side.
+func handshake(t *testing.T, serverCfg, clientCfg *tls.Config) error {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		if err := conn.(*tls.Conn).Handshake(); err != nil {
+			serverErr <- err
+			return
+		}
+		_, err = conn.Write([]byte("pong"))
+		serverErr <- err
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientCfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// With TLS 1.3 a rejected client certificate only surfaces on the first read
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	return <-serverErr
+}
+
+func TestGenerateMutualTLS(t *testing.T) {
+	bundle, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	clientCfg, err := bundle.ClientTLSConfig()
+	if err != nil {
+		t.Fatalf("ClientTLSConfig() error = %v", err)
+	}
+	if err := handshake(t, serverTLSConfig(t, bundle), clientCfg); err != nil {
+		t.Fatalf("handshake with matching certificates failed: %v", err)
+	}
+}
+
+func TestGenerateRejectsForeignClient(t *testing.T) {
+	bundle, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	other, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// A client certificate of another server's CA must not be accepted
+	clientCfg, err := clientTLSConfig(bundle.CACert, other.ClientCert, other.ClientKey)
+	if err != nil {
+		t.Fatalf("clientTLSConfig() error = %v", err)
+	}
+	if err := handshake(t, serverTLSConfig(t, bundle), clientCfg); err == nil {
+		t.Fatal("handshake with a foreign client certificate succeeded")
+	}
+}
+
+func TestInstallScript(t *testing.T) {
+	bundle, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	script := bundle.InstallScript()
+	for _, want := range []string{RemoteCACert, RemoteServerCert, RemoteServerKey, string(bundle.ServerKey)} {
+		if !strings.Contains(script, want) {
+			t.Errorf("install script does not contain %q", want)
+		}
+	}
+	if strings.Contains(script, string(bundle.ClientKey)) {
+		t.Error("install script must not contain the client key")
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if _, err := store.ClientTLSConfig("dockbridge-1"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("ClientTLSConfig() for missing server error = %v, want os.ErrNotExist", err)
+	}
+
+	bundle, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := store.Save("dockbridge-1", bundle); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	clientCfg, err := store.ClientTLSConfig("dockbridge-1")
+	if err != nil {
+		t.Fatalf("ClientTLSConfig() error = %v", err)
+	}
+	if err := handshake(t, serverTLSConfig(t, bundle), clientCfg); err != nil {
+		t.Fatalf("handshake with stored certificates failed: %v", err)
+	}
+
+	if err := store.Remove("dockbridge-1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(store.Dir("dockbridge-1")); !os.IsNotExist(err) {
+		t.Errorf("certificate directory still exists after Remove()")
+	}
+}
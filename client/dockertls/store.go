@@ -0,0 +1,88 @@
+package dockertls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Local file names of a server's client TLS material
+const (
+	caFile   = "ca.pem"
+	certFile = "cert.pem"
+	keyFile  = "key.pem"
+)
+
+// DefaultDir returns the default directory client certificates are stored in
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".dockbridge", "certs")
+	}
+	return filepath.Join(homeDir, ".dockbridge", "certs")
+}
+
+// Store keeps the client certificates of each server in its own directory, laid out
+// like DOCKER_CERT_PATH so that the docker CLI can use them directly
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory holding the certificates of serverName
+func (s *Store) Dir(serverName string) string {
+	return filepath.Join(s.dir, serverName)
+}
+
+// Save stores the client side of bundle for serverName
+func (s *Store) Save(serverName string, bundle *Bundle) error {
+	dir := s.Dir(serverName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	files := map[string][]byte{
+		caFile:   bundle.CACert,
+		certFile: bundle.ClientCert,
+		keyFile:  bundle.ClientKey,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ClientTLSConfig loads the client TLS configuration of serverName. The error wraps
+// os.ErrNotExist when no certificates are stored for the server.
+func (s *Store) ClientTLSConfig(serverName string) (*tls.Config, error) {
+	dir := s.Dir(serverName)
+
+	var data [3][]byte
+	for i, name := range []string{caFile, certFile, keyFile} {
+		content, err := os.ReadFile(filepath.Join(dir, name)) // #nosec G304
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		data[i] = content
+	}
+
+	return clientTLSConfig(data[0], data[1], data[2])
+}
+
+// Remove deletes the certificates of serverName
+func (s *Store) Remove(serverName string) error {
+	if err := os.RemoveAll(s.Dir(serverName)); err != nil {
+		return fmt.Errorf("failed to remove certificates: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package doctor diagnoses common problems of a DockBridge setup, from the local
+// configuration to the remote server, and suggests how to fix them.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	Pass Status = iota // Nothing to do
+	Warn               // Works, but likely to cause trouble
+	Fail               // Broken; DockBridge will not work until it is fixed
+	Skip               // Not applicable or depends on a failed check
+)
+
+// String returns the label printed for the status
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "ok"
+	case Warn:
+		return "warn"
+	case Fail:
+		return "FAIL"
+	default:
+		return "skip"
+	}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name   string
+	Status Status
+	// Detail says what was found
+	Detail string
+	// Fix says how to resolve a warning or failure
+	Fix string
+}
+
+// Check is one diagnostic. Run fills in everything but the name.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// DefaultTimeout bounds each check
+const DefaultTimeout = 20 * time.Second
+
+// Run runs the checks in order, each bounded by timeout
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		result := check.Run(checkCtx)
+		cancel()
+		result.Name = check.Name
+		results = append(results, result)
+	}
+	return results
+}
+
+// Render prints the results with their fixes
+func Render(w io.Writer, results []Result) {
+	for _, result := range results {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", result.Status, result.Name, result.Detail)
+		if result.Fix != "" && (result.Status == Warn || result.Status == Fail) {
+			fmt.Fprintf(w, "       fix: %s\n", result.Fix)
+		}
+	}
+}
+
+// Count returns how many results have the status
+func Count(results []Result, status Status) int {
+	n := 0
+	for _, result := range results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// pass, warn, fail and skip build results
+func pass(format string, args ...any) Result {
+	return Result{Status: Pass, Detail: fmt.Sprintf(format, args...)}
+}
+
+func warn(fix, format string, args ...any) Result {
+	return Result{Status: Warn, Detail: fmt.Sprintf(format, args...), Fix: fix}
+}
+
+func fail(fix, format string, args ...any) Result {
+	return Result{Status: Fail, Detail: fmt.Sprintf(format, args...), Fix: fix}
+}
+
+func skip(format string, args ...any) Result {
+	return Result{Status: Skip, Detail: fmt.Sprintf(format, args...)}
+}
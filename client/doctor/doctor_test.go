@@ -0,0 +1,180 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func run(t *testing.T, check Check) Result {
+	t.Helper()
+	return Run(context.Background(), []Check{check}, time.Second)[0]
+}
+
+func TestSSHKey(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+
+	assert.Equal(t, Pass, run(t, SSHKey(keyPath, false)).Status, "missing keys are generated")
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o644))
+
+	result := run(t, SSHKey(keyPath, false))
+	assert.Equal(t, Warn, result.Status)
+	assert.Equal(t, "chmod 600 "+keyPath, result.Fix)
+
+	require.NoError(t, os.Chmod(keyPath, 0o600))
+	assert.Equal(t, Warn, run(t, SSHKey(keyPath, false)).Status, "public key missing")
+
+	require.NoError(t, os.WriteFile(keyPath+".pub", []byte("ssh-ed25519 AAAA"), 0o644))
+	assert.Equal(t, Pass, run(t, SSHKey(keyPath, false)).Status)
+
+	block, err = ssh.MarshalPrivateKeyWithPassphrase(private, "", []byte("secret"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	assert.Equal(t, Fail, run(t, SSHKey(keyPath, false)).Status)
+	assert.Equal(t, Pass, run(t, SSHKey(keyPath, true)).Status, "the agent holds passphrase-protected keys")
+}
+
+func TestSocket(t *testing.T) {
+	home := t.TempDir()
+
+	result := run(t, Socket("/var/run/docker.sock", home, "", true))
+	assert.Equal(t, Fail, result.Status)
+	assert.Equal(t, Fail, run(t, Socket(filepath.Join(home, ".docker", "run", "docker.sock"), home, "", true)).Status)
+
+	socketPath := filepath.Join(home, "dockbridge.sock")
+	assert.Equal(t, Pass, run(t, Socket(socketPath, home, "unix://"+socketPath, false)).Status)
+	assert.Equal(t, Warn, run(t, Socket(socketPath, home, "unix:///var/run/docker.sock", true)).Status)
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	assert.Equal(t, Fail, run(t, Socket(socketPath, home, "", false)).Status, "another process owns the socket")
+	assert.Equal(t, Pass, run(t, Socket(socketPath, home, "", true)).Status)
+}
+
+// fakeProvider answers server listings; other methods are not used
+type fakeProvider struct {
+	provider.CloudProvider
+	err      error
+	writable bool
+}
+
+func (f *fakeProvider) ListServers(context.Context) ([]*provider.Server, error) {
+	return []*provider.Server{{Name: "dockbridge-1"}}, f.err
+}
+
+func (f *fakeProvider) CheckWriteAccess(context.Context) (bool, error) {
+	return f.writable, nil
+}
+
+func TestProviderAPI(t *testing.T) {
+	assert.Equal(t, Pass, run(t, ProviderAPI("hetzner", &fakeProvider{writable: true})).Status)
+
+	result := run(t, ProviderAPI("hetzner", &fakeProvider{}))
+	assert.Equal(t, Fail, result.Status)
+	assert.Contains(t, result.Detail, "read-only")
+
+	assert.Equal(t, Fail, run(t, ProviderAPI("hetzner", &fakeProvider{err: errors.New("unauthorized")})).Status)
+}
+
+func TestRemote(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	server := &provider.Server{Name: "dockbridge-1", IPAddress: "192.0.2.10"}
+	execs := 0
+	newRemote := func(output string) *Remote {
+		return &Remote{
+			Find: func(context.Context) (*provider.Server, error) { return server, nil },
+			Exec: func(_ context.Context, host, command string) ([]byte, error) {
+				execs++
+				return []byte(output), nil
+			},
+			Dial: func(_ context.Context, _, address string) (net.Conn, error) {
+				return nil, errors.New("connection timed out")
+			},
+			Now: func() time.Time { return now },
+		}
+	}
+
+	remote := newRemote("1700000090\n28.3.3\n")
+	results := Run(context.Background(), []Check{
+		remote.DaemonCheck(),
+		remote.ClockCheck(30 * time.Second),
+		remote.KeepAlivePortCheck(8080, false),
+	}, time.Second)
+
+	assert.Equal(t, Pass, results[0].Status)
+	assert.Contains(t, results[0].Detail, "Docker 28.3.3")
+	assert.Equal(t, Warn, results[1].Status)
+	assert.Contains(t, results[1].Detail, "1m30s")
+	assert.Equal(t, Fail, results[2].Status)
+	assert.Contains(t, results[2].Fix, "keep_alive.transport: ssh")
+	assert.Equal(t, 1, execs, "the server is probed once")
+
+	remote = newRemote("1700000001\nCannot connect to the Docker daemon at unix:///var/run/docker.sock\n")
+	assert.Equal(t, Fail, run(t, remote.DaemonCheck()).Status)
+	assert.Equal(t, Skip, run(t, remote.KeepAlivePortCheck(8080, true)).Status)
+
+	server = nil
+	assert.Equal(t, Skip, run(t, newRemote("").DaemonCheck()).Status)
+}
+
+func TestRemoteServerVersionCheck(t *testing.T) {
+	server := &provider.Server{Name: "dockbridge-1", IPAddress: "192.0.2.10"}
+	newRemote := func(inspect string) *Remote {
+		return &Remote{
+			Find: func(context.Context) (*provider.Server, error) { return server, nil },
+			Exec: func(_ context.Context, host, command string) ([]byte, error) {
+				if command == remoteProbeCommand {
+					return []byte("1700000000\n28.3.3\n"), nil
+				}
+				return []byte(inspect), nil
+			},
+		}
+	}
+
+	result := run(t, newRemote("x86_64\nDockBridge Server v1.2.0\n").ServerVersionCheck("1.2.0"))
+	assert.Equal(t, Pass, result.Status)
+
+	result = run(t, newRemote("x86_64\nDockBridge Server v1.1.0\n").ServerVersionCheck("1.2.0"))
+	assert.Equal(t, Warn, result.Status)
+	assert.Contains(t, result.Detail, "v1.1.0, this client is v1.2.0")
+	assert.Contains(t, result.Fix, "dockbridge server update")
+
+	result = run(t, newRemote("x86_64\n").ServerVersionCheck("1.2.0"))
+	assert.Equal(t, Warn, result.Status)
+	assert.Contains(t, result.Detail, "does not report its version")
+}
+
+func TestRender(t *testing.T) {
+	var out bytes.Buffer
+	Render(&out, []Result{
+		{Name: "SSH key", Status: Pass, Detail: "~/.ssh/id_rsa", Fix: "unused"},
+		{Name: "Local socket", Status: Fail, Detail: "collides", Fix: "change docker.socket_path"},
+	})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"[ok  ] SSH key: ~/.ssh/id_rsa",
+		"[FAIL] Local socket: collides",
+		"       fix: change docker.socket_path",
+	}, lines)
+}
@@ -0,0 +1,99 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKey checks that the SSH key exists, is private to the user and can be used
+// without a passphrase prompt
+func SSHKey(keyPath string, useAgent bool) Check {
+	return Check{Name: "SSH key", Run: func(context.Context) Result {
+		info, err := os.Stat(keyPath)
+		if os.IsNotExist(err) {
+			return pass("%s does not exist yet; DockBridge generates it on the first connection", keyPath)
+		}
+		if err != nil {
+			return fail("check ssh.key_path and the permissions of its directory", "cannot read %s: %v", keyPath, err)
+		}
+
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+			return warn("chmod 600 "+keyPath, "%s is readable by other users (mode %v); ssh refuses such keys", keyPath, info.Mode().Perm())
+		}
+
+		data, err := os.ReadFile(keyPath) // #nosec G304
+		if err != nil {
+			return fail("check the permissions of "+keyPath, "cannot read %s: %v", keyPath, err)
+		}
+		if _, err := ssh.ParsePrivateKey(data); err != nil {
+			var missing *ssh.PassphraseMissingError
+			switch {
+			case errors.As(err, &missing) && useAgent:
+				// The agent signs with the key, selected by its public half
+			case errors.As(err, &missing):
+				return fail("set ssh.use_agent: true and add the key to your SSH agent, or use a key without a passphrase",
+					"%s is protected by a passphrase", keyPath)
+			default:
+				return fail("point ssh.key_path at a private key, or remove it to let DockBridge generate one",
+					"%s is not a valid private key: %v", keyPath, err)
+			}
+		}
+
+		if _, err := os.Stat(keyPath + ".pub"); err != nil {
+			return warn("ssh-keygen -y -f "+keyPath+" > "+keyPath+".pub", "public key %s.pub is missing", keyPath)
+		}
+		return pass("%s", keyPath)
+	}}
+}
+
+// dockerDesktopSockets are the sockets of Docker Desktop and local Docker daemons, which
+// DockBridge must not replace
+func dockerDesktopSockets(home string) []string {
+	return []string{
+		"/var/run/docker.sock",
+		"/run/docker.sock",
+		filepath.Join(home, ".docker", "run", "docker.sock"),
+		filepath.Join(home, ".docker", "desktop", "docker.sock"),
+		"//./pipe/docker_engine",
+		"//./pipe/dockerDesktopLinuxEngine",
+	}
+}
+
+// Socket checks that the daemon's socket does not collide with Docker Desktop or
+// another process, and that DOCKER_HOST does not bypass DockBridge. daemonRunning
+// tells whether the DockBridge daemon answered on its control API.
+func Socket(socketPath, home, dockerHost string, daemonRunning bool) Check {
+	return Check{Name: "Local socket", Run: func(ctx context.Context) Result {
+		for _, desktop := range dockerDesktopSockets(home) {
+			if socketPath == desktop {
+				return fail("set docker.socket_path to a path of its own, such as /tmp/dockbridge.sock",
+					"%s is the socket of Docker Desktop or the local Docker daemon; DockBridge would replace it", socketPath)
+			}
+		}
+
+		if !daemonRunning && !localsocket.IsNamedPipe(socketPath) {
+			// Anyone answering on the socket while the daemon is down is another process
+			dialer := net.Dialer{Timeout: 2 * time.Second}
+			if conn, err := dialer.DialContext(ctx, "unix", socketPath); err == nil {
+				conn.Close()
+				return fail("stop the process listening on "+socketPath+" or choose another docker.socket_path",
+					"another process listens on %s while the DockBridge daemon is not running", socketPath)
+			}
+		}
+
+		if dockerHost != "" && !strings.HasSuffix(dockerHost, socketPath) {
+			return warn("unset DOCKER_HOST or set it to the DockBridge socket, e.g. export DOCKER_HOST=unix://"+socketPath,
+				"DOCKER_HOST=%s does not point at DockBridge (%s)", dockerHost, socketPath)
+		}
+		return pass("%s", socketPath)
+	}}
+}
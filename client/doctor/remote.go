@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/client/selfupdate"
+)
+
+// WriteAccessChecker is implemented by providers that can tell whether their API token
+// may create resources, not just read them
+type WriteAccessChecker interface {
+	CheckWriteAccess(ctx context.Context) (bool, error)
+}
+
+// ProviderAPI checks that the provider's API is reachable with the configured token
+// and, where the provider can tell, that the token may create servers
+func ProviderAPI(name string, cloud provider.CloudProvider) Check {
+	return Check{Name: "Provider API", Run: func(ctx context.Context) Result {
+		servers, err := cloud.ListServers(ctx)
+		if err != nil {
+			return fail(fmt.Sprintf("check the %s API token and your network connection", name),
+				"cannot reach the %s API: %v", name, err)
+		}
+
+		if checker, ok := cloud.(WriteAccessChecker); ok {
+			writable, err := checker.CheckWriteAccess(ctx)
+			switch {
+			case err != nil:
+				return warn("", "%s API reachable, but the token's permissions could not be checked: %v", name, err)
+			case !writable:
+				return fail(fmt.Sprintf("create a token with Read & Write permission in the %s console", name),
+					"the %s API token is read-only; DockBridge cannot create servers", name)
+			}
+		}
+		return pass("%s API reachable, %d servers in the project", name, len(servers))
+	}}
+}
+
+// remoteProbeCommand prints the server's clock and the version of its Docker daemon,
+// or why the daemon cannot be reached
+const remoteProbeCommand = `date +%s; docker version --format '{{.Server.Version}}' 2>&1 || true`
+
+// Remote runs the checks of the running server of a context. The server is looked up
+// and probed once, on the first check that needs it.
+type Remote struct {
+	// Find returns the running server of the context, or nil when none runs
+	Find func(ctx context.Context) (*provider.Server, error)
+	// Exec runs a command on the server at host over SSH
+	Exec func(ctx context.Context, host, command string) ([]byte, error)
+	// Dial connects to a TCP address from this machine; nil uses net.Dialer
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// Now returns the local time; nil uses time.Now
+	Now func() time.Time
+
+	once   sync.Once
+	server *provider.Server
+	// findErr, probeErr and probe are the outcomes of looking up and probing the server
+	findErr  error
+	probeErr error
+	probe    []string
+	skew     time.Duration
+}
+
+// load looks the server up and probes it
+func (r *Remote) load(ctx context.Context) {
+	r.once.Do(func() {
+		r.server, r.findErr = r.Find(ctx)
+		if r.findErr != nil || r.server == nil {
+			return
+		}
+
+		now := time.Now
+		if r.Now != nil {
+			now = r.Now
+		}
+		start := now()
+		output, err := r.Exec(ctx, r.server.IPAddress, remoteProbeCommand)
+		if err != nil {
+			r.probeErr = err
+			return
+		}
+		r.probe = strings.Split(strings.TrimSpace(string(output)), "\n")
+
+		// Compare with the local time halfway through the round trip
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(r.probe[0]), 10, 64); err == nil {
+			end := now()
+			local := start.Add(end.Sub(start) / 2)
+			r.skew = time.Unix(seconds, 0).Sub(local).Round(time.Second)
+		} else {
+			r.probeErr = fmt.Errorf("unexpected output %q", output)
+		}
+	})
+}
+
+// unavailable returns the result of a check that needs the probed server, or nil when
+// it is available
+func (r *Remote) unavailable(ctx context.Context) *Result {
+	r.load(ctx)
+	switch {
+	case r.findErr != nil:
+		result := skip("cannot look up the server: %v", r.findErr)
+		return &result
+	case r.server == nil:
+		result := skip("no server running; one is provisioned on the first Docker command")
+		return &result
+	case r.probeErr != nil:
+		result := fail("run dockbridge ssh to debug the connection, or dockbridge ssh reset if the server's host key changed",
+			"cannot run commands on %s (%s) over SSH: %v", r.server.Name, r.server.IPAddress, r.probeErr)
+		return &result
+	}
+	return nil
+}
+
+// DaemonCheck checks that the server's Docker daemon answers
+func (r *Remote) DaemonCheck() Check {
+	return Check{Name: "Remote Docker daemon", Run: func(ctx context.Context) Result {
+		if result := r.unavailable(ctx); result != nil {
+			return *result
+		}
+		version := ""
+		if len(r.probe) > 1 {
+			version = strings.TrimSpace(r.probe[1])
+		}
+		if version == "" || strings.ContainsAny(version, " :") {
+			return fail("run dockbridge ssh and check systemctl status docker; destroying the server with dockbridge down provisions a fresh one",
+				"Docker on %s is not answering: %s", r.server.Name, strings.Join(r.probe[1:], " "))
+		}
+		return pass("Docker %s on %s (%s)", version, r.server.Name, r.server.IPAddress)
+	}}
+}
+
+// ClockCheck checks that the server's clock is within maxSkew of the local clock
+func (r *Remote) ClockCheck(maxSkew time.Duration) Check {
+	return Check{Name: "Clock skew", Run: func(ctx context.Context) Result {
+		if result := r.unavailable(ctx); result != nil {
+			return *result
+		}
+		skew := r.skew
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew {
+			return warn("enable time synchronization on this machine (e.g. timedatectl set-ntp true) and on the server",
+				"clocks differ by %v; certificates and signed heartbeats may be rejected", r.skew)
+		}
+		return pass("clocks differ by %v", r.skew)
+	}}
+}
+
+// ServerVersionCheck checks that the server runs the dockbridge-server version of this
+// client
+func (r *Remote) ServerVersionCheck(clientVersion string) Check {
+	return Check{Name: "Server version", Run: func(ctx context.Context) Result {
+		if result := r.unavailable(ctx); result != nil {
+			return *result
+		}
+		output, err := r.Exec(ctx, r.server.IPAddress, selfupdate.InspectCommand)
+		if err != nil {
+			return warn("", "cannot read the dockbridge-server version: %v", err)
+		}
+		info, err := selfupdate.ParseServerInfo(output)
+		if err != nil {
+			return warn("", "cannot read the dockbridge-server version: %v", err)
+		}
+		switch info.Version {
+		case clientVersion:
+			return pass("dockbridge-server v%s on %s", info.Version, r.server.Name)
+		case "":
+			return warn("run dockbridge server update",
+				"dockbridge-server on %s does not report its version; this client is v%s", r.server.Name, clientVersion)
+		default:
+			return warn("run dockbridge server update",
+				"dockbridge-server on %s is v%s, this client is v%s", r.server.Name, info.Version, clientVersion)
+		}
+	}}
+}
+
+// KeepAlivePortCheck checks that this machine reaches the keep-alive port of the
+// server. It is skipped when heartbeats go through SSH.
+func (r *Remote) KeepAlivePortCheck(port int, viaSSH bool) Check {
+	return Check{Name: "Keep-alive port", Run: func(ctx context.Context) Result {
+		if viaSSH {
+			return skip("heartbeats are sent through SSH")
+		}
+		r.load(ctx)
+		if r.findErr != nil || r.server == nil {
+			return *r.unavailable(ctx)
+		}
+
+		dial := r.Dial
+		if dial == nil {
+			dialer := net.Dialer{Timeout: 5 * time.Second}
+			dial = dialer.DialContext
+		}
+		address := net.JoinHostPort(r.server.IPAddress, strconv.Itoa(port))
+		conn, err := dial(ctx, "tcp", address)
+		if err != nil {
+			return fail("allow outgoing TCP to port "+strconv.Itoa(port)+" in your firewall, or set keep_alive.transport: ssh",
+				"cannot reach %s: %v; without heartbeats the server destroys itself", address, err)
+		}
+		conn.Close()
+		return pass("%s reachable", address)
+	}}
+}
@@ -0,0 +1,81 @@
+// Package filelock provides exclusive advisory locks on files, shared between local
+// processes. A lock is released by the operating system when its holder exits, so a
+// crashed process never leaves it held.
+package filelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often Acquire retries a busy lock
+const pollInterval = 50 * time.Millisecond
+
+// ErrLocked is returned by TryAcquire while another process holds the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// Lock is an exclusive lock on an open lock file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock on the file at path, creating it if needed, and waits while
+// another process holds it until ctx is done
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := TryAcquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for lock %s: %w", path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquire takes the lock on the file at path without waiting; it returns ErrLocked
+// while another process holds it
+func TryAcquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600) // #nosec G304 -- lock files are chosen by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	locked, err := lockFile(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if !locked {
+		_ = file.Close()
+		return nil, ErrLocked
+	}
+	return &Lock{file: file}, nil
+}
+
+// File returns the locked file, for holders that keep state in it
+func (l *Lock) File() *os.File {
+	return l.file
+}
+
+// Release unlocks and closes the lock file
+func (l *Lock) Release() error {
+	if err := unlockFile(l.file); err != nil {
+		_ = l.file.Close()
+		return fmt.Errorf("failed to unlock %s: %w", l.file.Name(), err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,38 @@
+package filelock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "state.lock")
+
+	lock, err := TryAcquire(path)
+	require.NoError(t, err)
+
+	_, err = TryAcquire(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = Acquire(ctx, path)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A waiting caller gets the lock once it is released
+	acquired := make(chan error, 1)
+	go func() {
+		waiter, err := Acquire(context.Background(), path)
+		if err == nil {
+			err = waiter.Release()
+		}
+		acquired <- err
+	}()
+	require.NoError(t, lock.Release())
+	require.NoError(t, <-acquired)
+}
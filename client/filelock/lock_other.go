@@ -0,0 +1,16 @@
+//go:build !darwin && !linux && !freebsd && !netbsd && !openbsd && !dragonfly && !windows
+
+package filelock
+
+import "os"
+
+// lockFile always succeeds: file locks are not supported on this platform, so callers
+// are only serialized within the process
+func lockFile(file *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile does nothing
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package filesync emulates bind mounts of local directories for containers on the
+// remote server. The remote daemon cannot see the local filesystem, so a bind mount
+// source is replaced by a directory on the server's Docker data volume that is kept in
+// sync with the local directory in both directions.
+//
+// Changes are detected by comparing size and modification time of files on both sides
+// with the state of the previous sync; a file changed on both sides since then is
+// resolved in favour of the newer one.
+package filesync
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Entry describes a file or symbolic link in a synced directory
+type Entry struct {
+	Size int64
+
+	// ModTime is the modification time in nanoseconds since the epoch
+	ModTime int64
+
+	Link bool
+}
+
+// sameFile reports whether two entries from different sides hold the same file. Times
+// are compared in seconds, the precision transfers preserve everywhere.
+func sameFile(a, b Entry) bool {
+	return a.Size == b.Size && a.Link == b.Link && a.ModTime/1e9 == b.ModTime/1e9
+}
+
+// Snapshot maps slash-separated paths relative to a synced directory to their entries
+type Snapshot map[string]Entry
+
+// State is the state of both sides after the previous sync
+type State struct {
+	Local  Snapshot
+	Remote Snapshot
+}
+
+// Plan lists the paths a sync transfers or deletes
+type Plan struct {
+	Upload       []string
+	Download     []string
+	DeleteLocal  []string
+	DeleteRemote []string
+}
+
+// Empty reports whether the plan changes nothing
+func (p Plan) Empty() bool {
+	return len(p.Upload)+len(p.Download)+len(p.DeleteLocal)+len(p.DeleteRemote) == 0
+}
+
+// Reconcile plans the sync of local and remote from the state of the previous sync.
+// With localWins, used when a container is created, the local side is copied as is.
+func Reconcile(base State, local, remote Snapshot, localWins bool) Plan {
+	paths := make(map[string]bool, len(local)+len(remote))
+	for _, snapshot := range []Snapshot{local, remote, base.Local, base.Remote} {
+		for p := range snapshot {
+			paths[p] = true
+		}
+	}
+
+	var plan Plan
+	for p := range paths {
+		l, inLocal := local[p]
+		r, inRemote := remote[p]
+
+		if localWins {
+			switch {
+			case inLocal && (!inRemote || !sameFile(l, r)):
+				plan.Upload = append(plan.Upload, p)
+			case !inLocal && inRemote:
+				plan.DeleteRemote = append(plan.DeleteRemote, p)
+			}
+			continue
+		}
+
+		localChanged := changed(base.Local, p, l, inLocal)
+		remoteChanged := changed(base.Remote, p, r, inRemote)
+		switch {
+		case !localChanged && !remoteChanged:
+		case localChanged && !remoteChanged:
+			if inLocal {
+				plan.Upload = append(plan.Upload, p)
+			} else if inRemote {
+				plan.DeleteRemote = append(plan.DeleteRemote, p)
+			}
+		case !localChanged && remoteChanged:
+			if inRemote {
+				plan.Download = append(plan.Download, p)
+			} else if inLocal {
+				plan.DeleteLocal = append(plan.DeleteLocal, p)
+			}
+		default:
+			// Changed on both sides: an edit wins over a deletion, the newer edit over
+			// the older one
+			switch {
+			case inLocal && inRemote:
+				if sameFile(l, r) {
+					continue
+				}
+				if r.ModTime > l.ModTime {
+					plan.Download = append(plan.Download, p)
+				} else {
+					plan.Upload = append(plan.Upload, p)
+				}
+			case inLocal:
+				plan.Upload = append(plan.Upload, p)
+			case inRemote:
+				plan.Download = append(plan.Download, p)
+			}
+		}
+	}
+
+	for _, list := range [][]string{plan.Upload, plan.Download, plan.DeleteLocal, plan.DeleteRemote} {
+		sort.Strings(list)
+	}
+	return plan
+}
+
+// changed reports whether path differs from its entry in base
+func changed(base Snapshot, path string, entry Entry, exists bool) bool {
+	previous, existed := base[path]
+	return exists != existed || (exists && previous != entry)
+}
+
+// Excluded reports whether the slash-separated relative path matches an exclusion
+// pattern. Patterns without a slash, like ".git" or "*.log", match any path element;
+// patterns with one, like "build/cache", match the path or one of its parents.
+func Excluded(rel string, patterns []string) bool {
+	elements := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if !strings.Contains(pattern, "/") {
+			for _, element := range elements {
+				if matched, _ := path.Match(pattern, element); matched {
+					return true
+				}
+			}
+			continue
+		}
+		for i := range elements {
+			if matched, _ := path.Match(pattern, strings.Join(elements[:i+1], "/")); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScanLocal returns the files and symbolic links under root that are not excluded.
+// With only set, just that entry of root is scanned.
+func ScanLocal(root, only string, exclude []string) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	start := root
+	if only != "" {
+		start = filepath.Join(root, only)
+	}
+
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p != start {
+				// Removed while scanning
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if Excluded(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !(d.Type().IsRegular() || d.Type()&fs.ModeSymlink != 0) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snapshot[rel] = Entry{
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+			Link:    d.Type()&fs.ModeSymlink != 0,
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return snapshot, nil
+	}
+	return snapshot, err
+}
+
+// ParseRemoteListing parses the output of listCommand, dropping excluded paths
+func ParseRemoteListing(output []byte, exclude []string) Snapshot {
+	snapshot := make(Snapshot)
+	for _, record := range strings.Split(string(output), "\x00") {
+		fields := strings.SplitN(record, "\t", 4)
+		if len(fields) != 4 || fields[3] == "" || Excluded(fields[3], exclude) {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		snapshot[fields[3]] = Entry{
+			Size:    size,
+			ModTime: parseFindTime(fields[2]),
+			Link:    fields[0] == "l",
+		}
+	}
+	return snapshot
+}
+
+// parseFindTime converts a find %T@ time, seconds with a fraction, to nanoseconds
+func parseFindTime(value string) int64 {
+	secs, frac, _ := strings.Cut(value, ".")
+	seconds, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return 0
+	}
+	frac = (frac + "000000000")[:9]
+	nanos, _ := strconv.ParseInt(frac, 10, 64)
+	return seconds*1e9 + nanos
+}
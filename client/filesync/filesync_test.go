@@ -0,0 +1,308 @@
+package filesync
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+func TestReconcile(t *testing.T) {
+	old := Entry{Size: 1, ModTime: 1e9}
+	edited := Entry{Size: 2, ModTime: 5e9}
+	newer := Entry{Size: 3, ModTime: 9e9}
+
+	tests := []struct {
+		name      string
+		base      State
+		local     Snapshot
+		remote    Snapshot
+		localWins bool
+		want      Plan
+	}{
+		{
+			name:   "unchanged",
+			base:   State{Local: Snapshot{"a": old}, Remote: Snapshot{"a": old}},
+			local:  Snapshot{"a": old},
+			remote: Snapshot{"a": old},
+		},
+		{
+			name:   "local edit is uploaded",
+			base:   State{Local: Snapshot{"a": old}, Remote: Snapshot{"a": old}},
+			local:  Snapshot{"a": edited},
+			remote: Snapshot{"a": old},
+			want:   Plan{Upload: []string{"a"}},
+		},
+		{
+			name:   "remote file is downloaded",
+			base:   State{Local: Snapshot{}, Remote: Snapshot{}},
+			local:  Snapshot{},
+			remote: Snapshot{"out/log": edited},
+			want:   Plan{Download: []string{"out/log"}},
+		},
+		{
+			name:   "deletions are propagated",
+			base:   State{Local: Snapshot{"a": old, "b": old}, Remote: Snapshot{"a": old, "b": old}},
+			local:  Snapshot{"b": old},
+			remote: Snapshot{"a": old},
+			want:   Plan{DeleteLocal: []string{"b"}, DeleteRemote: []string{"a"}},
+		},
+		{
+			name:   "newer edit wins a conflict",
+			base:   State{Local: Snapshot{"a": old, "b": old}, Remote: Snapshot{"a": old, "b": old}},
+			local:  Snapshot{"a": newer, "b": edited},
+			remote: Snapshot{"a": edited, "b": newer},
+			want:   Plan{Upload: []string{"a"}, Download: []string{"b"}},
+		},
+		{
+			name:   "edit wins over deletion",
+			base:   State{Local: Snapshot{"a": old}, Remote: Snapshot{"a": old}},
+			local:  Snapshot{},
+			remote: Snapshot{"a": edited},
+			want:   Plan{Download: []string{"a"}},
+		},
+		{
+			name:   "matching files without a base are left alone",
+			local:  Snapshot{"a": old},
+			remote: Snapshot{"a": {Size: 1, ModTime: 1e9 + 500}},
+		},
+		{
+			name:      "local wins",
+			base:      State{Local: Snapshot{"c": old}, Remote: Snapshot{"c": old}},
+			local:     Snapshot{"a": old, "b": edited},
+			remote:    Snapshot{"a": old, "b": newer, "c": old},
+			localWins: true,
+			want:      Plan{Upload: []string{"b"}, DeleteRemote: []string{"c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Reconcile(tt.base, tt.local, tt.remote, tt.localWins)
+			if !reflect.DeepEqual(normalize(got), normalize(tt.want)) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// normalize makes empty and nil lists compare equal
+func normalize(p Plan) Plan {
+	for _, list := range []*[]string{&p.Upload, &p.Download, &p.DeleteLocal, &p.DeleteRemote} {
+		if len(*list) == 0 {
+			*list = nil
+		}
+	}
+	return p
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{".git", "*.log", "build/cache/"}
+	tests := map[string]bool{
+		".git/HEAD":         true,
+		"sub/.git/config":   true,
+		"app.log":           true,
+		"logs/app.log":      true,
+		"build/cache/x.bin": true,
+		"build/out.bin":     false,
+		"src/main.go":       false,
+		"sub/build/cache/x": false,
+	}
+	for rel, want := range tests {
+		if got := Excluded(rel, patterns); got != want {
+			t.Errorf("Excluded(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestParseRemoteListing(t *testing.T) {
+	output := "f\t12\t1700000000.5000000000\tsrc/main.go\x00" +
+		"l\t6\t1700000001.0000000000\tlink\x00" +
+		"f\t3\t1700000002.0000000000\t.git/HEAD\x00" +
+		"garbage\x00"
+
+	got := ParseRemoteListing([]byte(output), []string{".git"})
+	want := Snapshot{
+		"src/main.go": {Size: 12, ModTime: 1700000000_500000000},
+		"link":        {Size: 6, ModTime: 1700000001_000000000, Link: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRemoteListing() = %+v, want %+v", got, want)
+	}
+}
+
+func TestScanLocal(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "src/main.go", "package main")
+	writeTestFile(t, root, ".git/HEAD", "ref")
+	writeTestFile(t, root, "config.yml", "a: b")
+	if err := os.Symlink("config.yml", filepath.Join(root, "link.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ScanLocal(root, "", []string{".git"})
+	if err != nil {
+		t.Fatalf("ScanLocal() error = %v", err)
+	}
+	if len(got) != 3 || got["src/main.go"].Size != 12 || !got["link.yml"].Link {
+		t.Errorf("ScanLocal() = %+v", got)
+	}
+
+	got, err = ScanLocal(root, "config.yml", nil)
+	if err != nil {
+		t.Fatalf("ScanLocal() error = %v", err)
+	}
+	if _, ok := got["config.yml"]; !ok || len(got) != 1 {
+		t.Errorf("ScanLocal() with only = %+v", got)
+	}
+
+	got, err = ScanLocal(filepath.Join(root, "missing"), "", nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("ScanLocal() of a missing directory = %+v, %v", got, err)
+	}
+}
+
+func TestExtractTar(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	archive := func(name string) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o640, Size: 5, ModTime: modTime, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("hello"))
+		_ = tw.Close()
+		return &buf
+	}
+
+	root := t.TempDir()
+	if err := extractTar(archive("out/result.txt"), root); err != nil {
+		t.Fatalf("extractTar() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(root, "out", "result.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 || !info.ModTime().Equal(modTime) {
+		t.Errorf("extracted file mode %v, time %v", info.Mode().Perm(), info.ModTime())
+	}
+
+	if err := extractTar(archive("../escape.txt"), root); err == nil {
+		t.Error("extractTar() accepted a path leaving the directory")
+	}
+}
+
+// fakeRemote records commands and answers listings with an empty directory
+type fakeRemote struct {
+	commands []string
+	uploaded []string
+}
+
+func (f *fakeRemote) ExecuteRemoteCommand(_ context.Context, command string) ([]byte, error) {
+	f.commands = append(f.commands, command)
+	return nil, nil
+}
+
+func (f *fakeRemote) ExecuteRemoteCommandWithInput(_ context.Context, command string, input []byte) ([]byte, error) {
+	f.commands = append(f.commands, command)
+	tr := tar.NewReader(bytes.NewReader(input))
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		f.uploaded = append(f.uploaded, header.Name)
+	}
+	return nil, nil
+}
+
+func TestManagerPrepare(t *testing.T) {
+	project := t.TempDir()
+	writeTestFile(t, project, "src/main.go", "package main")
+	writeTestFile(t, project, "nginx.conf", "events {}")
+	other := t.TempDir()
+
+	remote := &fakeRemote{}
+	manager := NewManager(&config.FileSyncConfig{
+		Paths:      []string{project},
+		Interval:   time.Second,
+		RemoteRoot: "/var/lib/docker/dockbridge-sync/",
+	}, remote, logger.NewDefault())
+
+	body := `{"Image":"nginx","Labels":{"app":"web"},"HostConfig":{"Binds":["` +
+		filepath.Join(project, "src") + `:/app:ro","` + other + `:/other","data:/data"],` +
+		`"Mounts":[{"Type":"bind","Source":"` + filepath.Join(project, "nginx.conf") + `","Target":"/etc/nginx/nginx.conf"}],` +
+		`"Memory":0}}`
+
+	rewritten, sessions, err := manager.Prepare(context.Background(), []byte(body))
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Prepare() returned %d sessions, want 2", len(sessions))
+	}
+
+	var request struct {
+		Image      string
+		Labels     map[string]string
+		HostConfig struct {
+			Binds  []string
+			Mounts []struct{ Type, Source, Target string }
+			Memory *int
+		}
+	}
+	if err := json.Unmarshal(rewritten, &request); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, file := sessions[0].Mount(), sessions[1].Mount()
+	if !strings.HasPrefix(dir.Remote, "/var/lib/docker/dockbridge-sync/") {
+		t.Errorf("remote directory = %q", dir.Remote)
+	}
+	wantBinds := []string{dir.Remote + ":/app:ro", other + ":/other", "data:/data"}
+	if !reflect.DeepEqual(request.HostConfig.Binds, wantBinds) {
+		t.Errorf("Binds = %v, want %v", request.HostConfig.Binds, wantBinds)
+	}
+	if file.Only != "nginx.conf" || request.HostConfig.Mounts[0].Source != file.Remote+"/nginx.conf" {
+		t.Errorf("Mounts = %+v, file mount %+v", request.HostConfig.Mounts, file)
+	}
+	if request.Image != "nginx" || request.HostConfig.Memory == nil || request.Labels["app"] != "web" {
+		t.Errorf("unrelated fields changed: %s", rewritten)
+	}
+
+	var recorded []Mount
+	if err := json.Unmarshal([]byte(request.Labels[Label]), &recorded); err != nil || len(recorded) != 2 {
+		t.Errorf("label %q = %q", Label, request.Labels[Label])
+	}
+	if !reflect.DeepEqual(remote.uploaded, []string{"main.go", "nginx.conf"}) {
+		t.Errorf("uploaded %v", remote.uploaded)
+	}
+}
+
+func TestManagerPrepareWithoutSyncedMounts(t *testing.T) {
+	manager := NewManager(&config.FileSyncConfig{Paths: []string{t.TempDir()}}, &fakeRemote{}, logger.NewDefault())
+
+	body := []byte(`{"Image":"nginx","HostConfig":{"Binds":["/srv/data:/data"]}}`)
+	rewritten, sessions, err := manager.Prepare(context.Background(), body)
+	if err != nil || sessions != nil || !bytes.Equal(rewritten, body) {
+		t.Errorf("Prepare() = %s, %v, %v; want the body unchanged", rewritten, sessions, err)
+	}
+}
+
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,447 @@
+package filesync
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// Label is the container label recording the synced mounts of a container, so syncing
+// resumes after the daemon restarts
+const Label = "dockbridge.sync"
+
+// listSyncedCommand lists the containers with synced mounts as ID, name and label
+const listSyncedCommand = `docker ps -a --no-trunc --filter label=` + Label + ` --format '{{.ID}}\t{{.Names}}\t{{.Label "` + Label + `"}}'`
+
+// Manager emulates bind mounts of local directories for containers on the server
+type Manager struct {
+	paths    []string
+	exclude  []string
+	interval time.Duration
+	root     string
+	remote   Remote
+	logger   logger.LoggerInterface
+
+	mu         sync.Mutex
+	containers map[string]*container
+}
+
+// container is a container whose mounts are synced
+type container struct {
+	id       string
+	name     string
+	sessions []*Session
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewManager creates a manager syncing bind mounts below the configured paths
+func NewManager(cfg *config.FileSyncConfig, remote Remote, log logger.LoggerInterface) *Manager {
+	paths := make([]string, 0, len(cfg.Paths))
+	for _, p := range cfg.Paths {
+		if strings.HasPrefix(p, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, p[2:])
+			}
+		}
+		paths = append(paths, filepath.Clean(p))
+	}
+	return &Manager{
+		paths:      paths,
+		exclude:    cfg.Exclude,
+		interval:   cfg.Interval,
+		root:       strings.TrimRight(cfg.RemoteRoot, "/"),
+		remote:     remote,
+		logger:     log,
+		containers: make(map[string]*container),
+	}
+}
+
+// synced reports whether a local path is below one of the synced paths
+func (m *Manager) synced(local string) bool {
+	for _, p := range m.paths {
+		if local == p || strings.HasPrefix(local, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prepare rewrites the bind mounts of synced local paths in a container create request
+// to directories on the server and copies the local files there. It returns the body
+// unchanged and no sessions when the container mounts nothing to sync.
+func (m *Manager) Prepare(ctx context.Context, body []byte) ([]byte, []*Session, error) {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(body, &request); err != nil {
+		return body, nil, nil
+	}
+	var hostConfig map[string]json.RawMessage
+	if raw, ok := request["HostConfig"]; !ok || json.Unmarshal(raw, &hostConfig) != nil {
+		return body, nil, nil
+	}
+
+	sessions := make(map[string]*Session)
+	var ordered []*Session
+	source := func(local string, create bool) (string, error) {
+		local = filepath.Clean(local)
+		if !filepath.IsAbs(local) || !m.synced(local) {
+			return "", nil
+		}
+		if s, ok := sessions[local]; ok {
+			return s.Mount().Source(), nil
+		}
+
+		mount, err := m.newMount(local, create)
+		if err != nil || mount == nil {
+			return "", err
+		}
+		s := NewSession(*mount, m.exclude, m.remote)
+		sessions[local] = s
+		ordered = append(ordered, s)
+		return mount.Source(), nil
+	}
+
+	changed, err := m.rewriteBinds(hostConfig, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	changedMounts, err := m.rewriteMounts(hostConfig, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !changed && !changedMounts {
+		return body, nil, nil
+	}
+
+	if err := m.labelRequest(request, hostConfig, ordered); err != nil {
+		return nil, nil, err
+	}
+	rewritten, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, s := range ordered {
+		if _, err := s.Sync(ctx, true); err != nil {
+			m.Discard(ctx, ordered)
+			return nil, nil, fmt.Errorf("failed to sync %s to the server: %w", s.Mount().Local, err)
+		}
+	}
+	return rewritten, ordered, nil
+}
+
+// newMount creates the mount of a local bind mount source. Missing sources of binds are
+// created like Docker does on a local host; missing mount sources are left to Docker to
+// reject.
+func (m *Manager) newMount(local string, create bool) (*Mount, error) {
+	info, err := os.Stat(local)
+	switch {
+	case os.IsNotExist(err) && create:
+		if err := os.MkdirAll(local, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create bind mount source %s: %w", local, err)
+		}
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	mount := &Mount{Local: local, Remote: path.Join(m.root, hex.EncodeToString(id))}
+	if info != nil && !info.IsDir() {
+		mount.Local, mount.Only = filepath.Dir(local), filepath.Base(local)
+	}
+	return mount, nil
+}
+
+// rewriteBinds rewrites the sources of HostConfig.Binds entries, "source:target[:options]"
+func (m *Manager) rewriteBinds(hostConfig map[string]json.RawMessage, source func(string, bool) (string, error)) (bool, error) {
+	raw, ok := hostConfig["Binds"]
+	if !ok {
+		return false, nil
+	}
+	var binds []string
+	if err := json.Unmarshal(raw, &binds); err != nil {
+		return false, nil
+	}
+
+	changed := false
+	for i, bind := range binds {
+		local, rest, ok := strings.Cut(bind, ":")
+		if !ok {
+			continue
+		}
+		remote, err := source(local, true)
+		if err != nil {
+			return false, err
+		}
+		if remote != "" {
+			binds[i] = remote + ":" + rest
+			changed = true
+		}
+	}
+	if changed {
+		if hostConfig["Binds"], ok = marshal(binds); !ok {
+			return false, fmt.Errorf("failed to encode binds")
+		}
+	}
+	return changed, nil
+}
+
+// rewriteMounts rewrites the sources of HostConfig.Mounts entries of type bind
+func (m *Manager) rewriteMounts(hostConfig map[string]json.RawMessage, source func(string, bool) (string, error)) (bool, error) {
+	raw, ok := hostConfig["Mounts"]
+	if !ok {
+		return false, nil
+	}
+	var mounts []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &mounts); err != nil {
+		return false, nil
+	}
+
+	changed := false
+	for _, mount := range mounts {
+		var kind, local string
+		if json.Unmarshal(mount["Type"], &kind) != nil || kind != "bind" || json.Unmarshal(mount["Source"], &local) != nil {
+			continue
+		}
+		remote, err := source(local, false)
+		if err != nil {
+			return false, err
+		}
+		if remote != "" {
+			mount["Source"], _ = marshal(remote)
+			changed = true
+		}
+	}
+	if changed {
+		if hostConfig["Mounts"], ok = marshal(mounts); !ok {
+			return false, fmt.Errorf("failed to encode mounts")
+		}
+	}
+	return changed, nil
+}
+
+// labelRequest stores the rewritten host config and records the mounts in the Label
+// label of the request
+func (m *Manager) labelRequest(request, hostConfig map[string]json.RawMessage, sessions []*Session) error {
+	labels := make(map[string]string)
+	if raw, ok := request["Labels"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &labels); err != nil {
+			return fmt.Errorf("failed to decode labels: %w", err)
+		}
+	}
+
+	mounts := make([]Mount, len(sessions))
+	for i, s := range sessions {
+		mounts[i] = s.Mount()
+	}
+	encoded, err := json.Marshal(mounts)
+	if err != nil {
+		return err
+	}
+	labels[Label] = string(encoded)
+
+	var ok bool
+	if request["Labels"], ok = marshal(labels); !ok {
+		return fmt.Errorf("failed to encode labels")
+	}
+	if request["HostConfig"], ok = marshal(hostConfig); !ok {
+		return fmt.Errorf("failed to encode host config")
+	}
+	return nil
+}
+
+// marshal encodes a value, reporting success
+func marshal(value any) (json.RawMessage, bool) {
+	data, err := json.Marshal(value)
+	return data, err == nil
+}
+
+// Attach keeps the mounts of a created container in sync until it is removed
+func (m *Manager) Attach(id, name string, sessions []*Session) {
+	if len(sessions) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &container{id: id, name: strings.TrimPrefix(name, "/"), sessions: sessions, cancel: cancel, done: make(chan struct{})}
+
+	m.mu.Lock()
+	previous := m.containers[id]
+	m.containers[id] = c
+	m.mu.Unlock()
+	if previous != nil {
+		previous.stop()
+	}
+
+	go m.run(ctx, c)
+}
+
+// run syncs the mounts of a container every interval
+func (m *Manager) run(ctx context.Context, c *container) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, s := range c.sessions {
+			plan, err := s.Sync(ctx, false)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				return
+			case err != nil:
+				fields := map[string]any{
+					"container": c.name,
+					"local":     s.Mount().Local,
+					"error":     err.Error(),
+				}
+				if failing {
+					m.logger.WithFields(fields).Debug("File sync failed")
+				} else {
+					m.logger.WithFields(fields).Warn("⚠️ File sync failed, retrying")
+				}
+				failing = true
+			case !plan.Empty():
+				m.logger.WithFields(map[string]any{
+					"container":      c.name,
+					"local":          s.Mount().Local,
+					"uploaded":       len(plan.Upload),
+					"downloaded":     len(plan.Download),
+					"deleted_local":  len(plan.DeleteLocal),
+					"deleted_remote": len(plan.DeleteRemote),
+				}).Debug("Synced files")
+				fallthrough
+			default:
+				if failing {
+					m.logger.WithFields(map[string]any{
+						"container": c.name,
+					}).Info("File sync recovered")
+				}
+				failing = false
+			}
+		}
+	}
+}
+
+// stop ends the sync loop of a container and waits for it to exit
+func (c *container) stop() {
+	c.cancel()
+	<-c.done
+}
+
+// Remove syncs the mounts of a removed container a last time and deletes their copies
+// on the server. idOrName is the container reference of the remove request.
+func (m *Manager) Remove(ctx context.Context, idOrName string) {
+	m.mu.Lock()
+	var c *container
+	for id, candidate := range m.containers {
+		if candidate.name == idOrName || (len(idOrName) >= 4 && strings.HasPrefix(id, idOrName)) {
+			c = candidate
+			delete(m.containers, id)
+			break
+		}
+	}
+	m.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	c.stop()
+	for _, s := range c.sessions {
+		if _, err := s.Sync(ctx, false); err != nil {
+			m.logger.WithFields(map[string]any{
+				"container": c.name,
+				"local":     s.Mount().Local,
+				"error":     err.Error(),
+			}).Warn("Failed to sync files of removed container")
+		}
+	}
+	m.Discard(ctx, c.sessions)
+}
+
+// Discard deletes the copies of sessions on the server, e.g. when the container they
+// were prepared for could not be created
+func (m *Manager) Discard(ctx context.Context, sessions []*Session) {
+	for _, s := range sessions {
+		if err := s.Cleanup(ctx); err != nil {
+			m.logger.WithFields(map[string]any{
+				"remote": s.Mount().Remote,
+				"error":  err.Error(),
+			}).Warn("Failed to remove synced files from the server")
+		}
+	}
+}
+
+// Resume replaces the synced containers by those on the server carrying the Label
+// label, e.g. after the daemon restarted or connected to another server
+func (m *Manager) Resume(ctx context.Context) error {
+	m.Stop()
+
+	output, err := m.remote.ExecuteRemoteCommand(ctx, listSyncedCommand)
+	if err != nil {
+		return fmt.Errorf("failed to list containers with synced files: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		var mounts []Mount
+		if err := json.Unmarshal([]byte(fields[2]), &mounts); err != nil {
+			continue
+		}
+
+		var sessions []*Session
+		for _, mount := range mounts {
+			if !m.synced(mount.Local) || !strings.HasPrefix(mount.Remote, m.root+"/") {
+				continue
+			}
+			sessions = append(sessions, NewSession(mount, m.exclude, m.remote))
+		}
+		if len(sessions) == 0 {
+			continue
+		}
+		m.Attach(fields[0], fields[1], sessions)
+		m.logger.WithFields(map[string]any{
+			"container": fields[1],
+			"mounts":    len(sessions),
+		}).Info("Resumed file sync")
+	}
+	return nil
+}
+
+// Stop ends the sync of all containers; their copies on the server are kept
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	containers := m.containers
+	m.containers = make(map[string]*container)
+	m.mu.Unlock()
+
+	for _, c := range containers {
+		c.stop()
+	}
+}
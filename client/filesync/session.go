@@ -0,0 +1,122 @@
+package filesync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Mount is a local path synced to a directory on the server
+type Mount struct {
+	// Local is the synced local directory
+	Local string `json:"local"`
+
+	// Remote is the directory on the server the local one is synced to
+	Remote string `json:"remote"`
+
+	// Only, when set, limits the sync to this entry of Local, for bind mounts of a
+	// single file
+	Only string `json:"only,omitempty"`
+}
+
+// Source returns the path on the server that replaces the bind mount source
+func (m Mount) Source() string {
+	if m.Only == "" {
+		return m.Remote
+	}
+	return m.Remote + "/" + m.Only
+}
+
+// Session keeps a local directory and its copy on the server in sync
+type Session struct {
+	mount   Mount
+	exclude []string
+	remote  Remote
+	base    State
+	synced  bool
+}
+
+// NewSession creates the session of a mount; nothing is transferred until Sync
+func NewSession(mount Mount, exclude []string, remote Remote) *Session {
+	return &Session{mount: mount, exclude: exclude, remote: remote}
+}
+
+// Mount returns the session's mount
+func (s *Session) Mount() Mount {
+	return s.mount
+}
+
+// Sync transfers the changes of both sides since the previous sync. With localWins,
+// the server's copy is made identical to the local directory instead.
+func (s *Session) Sync(ctx context.Context, localWins bool) (Plan, error) {
+	local, err := ScanLocal(s.mount.Local, s.mount.Only, s.exclude)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to scan %s: %w", s.mount.Local, err)
+	}
+	remote, err := s.listRemote(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	// Without an earlier sync, e.g. after the daemon restarted, files that match are
+	// taken as synced and the rest goes to the newer side
+	if !s.synced && !localWins {
+		s.base = State{Local: make(Snapshot), Remote: make(Snapshot)}
+	}
+
+	plan := Reconcile(s.base, local, remote, localWins)
+	if plan.Empty() {
+		s.base = State{Local: local, Remote: remote}
+		s.synced = true
+		return plan, nil
+	}
+
+	if err := upload(ctx, s.remote, s.mount.Local, s.mount.Remote, plan.Upload); err != nil {
+		return plan, err
+	}
+	if err := deleteRemote(ctx, s.remote, s.mount.Remote, plan.DeleteRemote); err != nil {
+		return plan, err
+	}
+	if err := download(ctx, s.remote, s.mount.Remote, s.mount.Local, plan.Download, remote); err != nil {
+		return plan, err
+	}
+	if err := deleteLocal(s.mount.Local, plan.DeleteLocal); err != nil {
+		return plan, err
+	}
+
+	// Record both sides as they are after the transfers
+	if local, err = ScanLocal(s.mount.Local, s.mount.Only, s.exclude); err != nil {
+		return plan, fmt.Errorf("failed to scan %s: %w", s.mount.Local, err)
+	}
+	if remote, err = s.listRemote(ctx); err != nil {
+		return plan, err
+	}
+	s.base = State{Local: local, Remote: remote}
+	s.synced = true
+	return plan, nil
+}
+
+// listRemote lists the server's copy
+func (s *Session) listRemote(ctx context.Context) (Snapshot, error) {
+	output, err := s.remote.ExecuteRemoteCommand(ctx, listCommand(s.mount.Remote))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s on the server: %w", s.mount.Remote, err)
+	}
+	snapshot := ParseRemoteListing(output, s.exclude)
+	if s.mount.Only != "" {
+		for p := range snapshot {
+			if p != s.mount.Only && !strings.HasPrefix(p, s.mount.Only+"/") {
+				delete(snapshot, p)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// Cleanup removes the server's copy
+func (s *Session) Cleanup(ctx context.Context) error {
+	if output, err := s.remote.ExecuteRemoteCommand(ctx, "rm -rf -- "+shellQuote(s.mount.Remote)); err != nil {
+		return fmt.Errorf("failed to remove %s on the server: %w: %s", s.mount.Remote, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
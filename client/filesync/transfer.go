@@ -0,0 +1,230 @@
+package filesync
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxBatchSize bounds the data of a single transfer, which is held in memory
+const maxBatchSize = 32 << 20
+
+// maxBatchPaths bounds the paths named in a single remote command
+const maxBatchPaths = 500
+
+// Remote runs commands on the server
+type Remote interface {
+	ExecuteRemoteCommand(ctx context.Context, command string) ([]byte, error)
+	ExecuteRemoteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error)
+}
+
+// listCommand lists the files and symbolic links under dir as NUL-terminated records
+// of type, size, modification time and path, creating dir if needed
+func listCommand(dir string) string {
+	return fmt.Sprintf("mkdir -p %s && cd %s && find . \\( -type f -o -type l \\) -printf '%%y\\t%%s\\t%%T@\\t%%P\\0' 2>/dev/null; true",
+		shellQuote(dir), shellQuote(dir))
+}
+
+// upload copies local files to the remote directory, preserving modes, times and
+// numeric owners so containers running as the local user can write them
+func upload(ctx context.Context, remote Remote, localRoot, remoteDir string, paths []string) error {
+	for len(paths) > 0 {
+		var archive bytes.Buffer
+		tw := tar.NewWriter(&archive)
+		n := 0
+		for n < len(paths) && n < maxBatchPaths && archive.Len() < maxBatchSize {
+			if err := addToTar(tw, localRoot, paths[n]); err != nil {
+				return err
+			}
+			n++
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+
+		command := fmt.Sprintf("mkdir -p %s && tar -x --numeric-owner -C %s -f -", shellQuote(remoteDir), shellQuote(remoteDir))
+		if output, err := remote.ExecuteRemoteCommandWithInput(ctx, command, archive.Bytes()); err != nil {
+			return fmt.Errorf("failed to upload files: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
+
+// addToTar adds a local file or symbolic link; files removed since the scan are skipped
+func addToTar(tw *tar.Writer, root, rel string) error {
+	full := filepath.Join(root, filepath.FromSlash(rel))
+	info, err := os.Lstat(full)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(full); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = rel
+	header.Format = tar.FormatPAX
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	// #nosec G304 -- paths come from scanning the synced directory
+	file, err := os.Open(full)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.CopyN(tw, file, header.Size)
+	return err
+}
+
+// download copies remote files to the local directory
+func download(ctx context.Context, remote Remote, remoteDir, localRoot string, paths []string, sizes Snapshot) error {
+	for len(paths) > 0 {
+		n, size := 0, int64(0)
+		for n < len(paths) && n < maxBatchPaths && size < maxBatchSize {
+			size += sizes[paths[n]].Size
+			n++
+		}
+
+		quoted := make([]string, n)
+		for i, p := range paths[:n] {
+			quoted[i] = shellQuote(p)
+		}
+		// Files removed since the listing make tar fail; the others are still archived
+		command := fmt.Sprintf("cd %s && tar -c -f - -- %s 2>/dev/null; true", shellQuote(remoteDir), strings.Join(quoted, " "))
+		output, err := remote.ExecuteRemoteCommand(ctx, command)
+		if err != nil {
+			return fmt.Errorf("failed to download files: %w", err)
+		}
+		if err := extractTar(bytes.NewReader(output), localRoot); err != nil {
+			return fmt.Errorf("failed to extract downloaded files: %w", err)
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
+
+// extractTar writes the files and symbolic links of an archive below root. Files are
+// replaced atomically so readers never see partial content.
+func extractTar(r io.Reader, root string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := localPath(root, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			if err := writeFile(target, tr, header); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			_ = os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeFile atomically replaces target with the content of r
+func writeFile(target string, r io.Reader, header *tar.Header) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".dockbridge-sync-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), os.FileMode(header.Mode).Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), time.Now(), header.ModTime); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// localPath resolves a slash-separated relative path below root, rejecting paths that
+// leave it
+func localPath(root, rel string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q leaves the synced directory", rel)
+	}
+	return filepath.Join(root, cleaned), nil
+}
+
+// deleteLocal removes local files
+func deleteLocal(root string, paths []string) error {
+	for _, p := range paths {
+		target, err := localPath(root, p)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRemote removes remote files
+func deleteRemote(ctx context.Context, remote Remote, remoteDir string, paths []string) error {
+	for len(paths) > 0 {
+		n := min(len(paths), maxBatchPaths)
+		quoted := make([]string, n)
+		for i, p := range paths[:n] {
+			quoted[i] = shellQuote(p)
+		}
+		command := fmt.Sprintf("cd %s && rm -f -- %s", shellQuote(remoteDir), strings.Join(quoted, " "))
+		if output, err := remote.ExecuteRemoteCommand(ctx, command); err != nil {
+			return fmt.Errorf("failed to delete files: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
+
+// shellQuote quotes value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,326 @@
+// Package heartbeat sends the client's periodic keep-alive heartbeats to the remote
+// server for as long as the daemon runs, so the server's keep-alive monitor does not
+// destroy it while the client is still using it.
+package heartbeat
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultInterval is the interval between heartbeats when none is configured
+	DefaultInterval = 30 * time.Second
+
+	// DefaultTimeout is the server's keep-alive timeout when none is configured
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Sender sends a heartbeat to the current server.
+// keepalive.HeartbeatClient satisfies this interface.
+type Sender interface {
+	SendHeartbeat() error
+}
+
+// EventHandler is notified when heartbeats degrade
+type EventHandler interface {
+	// OnHeartbeatMissed is called when a heartbeat failed after all retries, with the
+	// number of consecutive heartbeats missed
+	OnHeartbeatMissed(missed int, err error)
+
+	// OnServerLost is called once when no heartbeat was delivered for the keep-alive
+	// timeout, after which the server destroys itself
+	OnServerLost(lastHeartbeat time.Time)
+}
+
+// KeepAliveService sends heartbeats at the configured interval, retrying failed ones
+type KeepAliveService interface {
+	Start(ctx context.Context) error
+	Stop() error
+
+	// Pause stops sending heartbeats, e.g. while the server is intentionally destroyed
+	Pause()
+	// Resume sends heartbeats again, starting a new keep-alive period
+	Resume()
+
+	RegisterHandler(handler EventHandler)
+	Status() Status
+
+	// SetInterval changes the interval between heartbeats of the running loop; zero
+	// restores the default
+	SetInterval(interval time.Duration)
+}
+
+// Status is the state of the heartbeat loop
+type Status struct {
+	Paused        bool
+	LastHeartbeat time.Time
+	// Missed counts consecutive heartbeats that failed after all retries
+	Missed int
+	// Lost is set once no heartbeat was delivered for the keep-alive timeout
+	Lost bool
+}
+
+// keepAliveServiceImpl implements KeepAliveService
+type keepAliveServiceImpl struct {
+	config *config.KeepAliveConfig
+	sender func() Sender
+	logger logger.LoggerInterface
+
+	mu       sync.Mutex
+	handlers []EventHandler
+	status   Status
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// intervalOverride replaces the configured interval once SetInterval was called
+	intervalOverride atomic.Int64
+	// intervalChanged wakes the loop to apply a new interval
+	intervalChanged chan struct{}
+}
+
+// NewKeepAliveService creates a service sending heartbeats through the sender returned
+// by sender, which returns nil while no server is connected
+func NewKeepAliveService(cfg *config.KeepAliveConfig, sender func() Sender, log logger.LoggerInterface) KeepAliveService {
+	if cfg == nil {
+		cfg = &config.KeepAliveConfig{}
+	}
+	return &keepAliveServiceImpl{
+		config:          cfg,
+		sender:          sender,
+		logger:          log,
+		status:          Status{LastHeartbeat: time.Now()},
+		intervalChanged: make(chan struct{}, 1),
+	}
+}
+
+// Start sends a heartbeat right away and then at every interval until ctx is
+// cancelled or Stop is called
+func (s *keepAliveServiceImpl) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return errors.New("keep-alive service already running")
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	s.status.LastHeartbeat = time.Now()
+	go s.run(ctx, s.done)
+
+	s.logger.WithFields(map[string]any{
+		"interval":    s.interval(),
+		"max_retries": s.config.MaxRetries,
+	}).Info("Keep-alive service started")
+	return nil
+}
+
+// Stop stops sending heartbeats and waits for the loop to exit
+func (s *keepAliveServiceImpl) Stop() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// Pause stops sending heartbeats until Resume is called
+func (s *keepAliveServiceImpl) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.status.Paused {
+		s.status.Paused = true
+		s.logger.Info("Keep-alive heartbeats paused")
+	}
+}
+
+// Resume sends heartbeats again after Pause; the keep-alive timeout counts from now
+func (s *keepAliveServiceImpl) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.status.Paused {
+		return
+	}
+	s.status = Status{LastHeartbeat: time.Now()}
+	s.logger.Info("Keep-alive heartbeats resumed")
+}
+
+// RegisterHandler adds a handler notified when heartbeats degrade
+func (s *keepAliveServiceImpl) RegisterHandler(handler EventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// SetInterval changes the interval between heartbeats; the next heartbeat is due one
+// new interval from now
+func (s *keepAliveServiceImpl) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = -1
+	}
+	s.intervalOverride.Store(int64(interval))
+	select {
+	case s.intervalChanged <- struct{}{}:
+	default:
+	}
+	s.logger.WithFields(map[string]any{
+		"interval": s.interval(),
+	}).Info("Keep-alive interval changed")
+}
+
+// Status returns the current state of the heartbeat loop
+func (s *keepAliveServiceImpl) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// run sends heartbeats until ctx is cancelled
+func (s *keepAliveServiceImpl) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval())
+	defer ticker.Stop()
+
+	s.beat(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.beat(ctx)
+		case <-s.intervalChanged:
+			ticker.Reset(s.interval())
+		}
+	}
+}
+
+// beat sends one heartbeat with retries and records the outcome
+func (s *keepAliveServiceImpl) beat(ctx context.Context) {
+	if s.Status().Paused {
+		return
+	}
+
+	sender := s.sender()
+	if sender == nil {
+		// No server is connected, so there is nothing to keep alive
+		s.mu.Lock()
+		s.status.LastHeartbeat = time.Now()
+		s.status.Missed = 0
+		s.status.Lost = false
+		s.mu.Unlock()
+		return
+	}
+
+	err := s.send(ctx, sender)
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.status.Paused {
+		// Paused while sending: the outcome belongs to a server being destroyed
+		s.mu.Unlock()
+		return
+	}
+	if err == nil {
+		if s.status.Missed > 0 {
+			s.logger.WithFields(map[string]any{
+				"missed": s.status.Missed,
+			}).Info("Keep-alive heartbeats restored")
+		}
+		s.status.LastHeartbeat = time.Now()
+		s.status.Missed = 0
+		s.status.Lost = false
+		s.mu.Unlock()
+		return
+	}
+
+	s.status.Missed++
+	status := s.status
+	lost := !s.status.Lost && time.Since(s.status.LastHeartbeat) >= s.timeout()
+	if lost {
+		s.status.Lost = true
+	}
+	handlers := append([]EventHandler(nil), s.handlers...)
+	s.mu.Unlock()
+
+	s.logger.WithFields(map[string]any{
+		"missed":         status.Missed,
+		"last_heartbeat": status.LastHeartbeat,
+		"error":          err.Error(),
+	}).Warn("Keep-alive heartbeat missed")
+
+	for _, handler := range handlers {
+		handler.OnHeartbeatMissed(status.Missed, err)
+	}
+	if lost {
+		s.logger.WithFields(map[string]any{
+			"last_heartbeat": status.LastHeartbeat,
+			"timeout":        s.timeout(),
+		}).Error("Keep-alive timeout exceeded, server considered lost")
+		for _, handler := range handlers {
+			handler.OnServerLost(status.LastHeartbeat)
+		}
+	}
+}
+
+// send sends a heartbeat, retrying up to MaxRetries times with exponential backoff
+// starting at RetryInterval and capped at the heartbeat interval
+func (s *keepAliveServiceImpl) send(ctx context.Context, sender Sender) error {
+	backoff := s.config.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err := sender.SendHeartbeat()
+		if err == nil || attempt >= s.config.MaxRetries || s.Status().Paused {
+			return err
+		}
+
+		s.logger.WithFields(map[string]any{
+			"attempt": attempt + 1,
+			"backoff": backoff,
+			"error":   err.Error(),
+		}).Debug("Heartbeat failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.interval())
+	}
+}
+
+// interval returns the configured heartbeat interval or the default
+func (s *keepAliveServiceImpl) interval() time.Duration {
+	if override := time.Duration(s.intervalOverride.Load()); override != 0 {
+		if override > 0 {
+			return override
+		}
+		return DefaultInterval
+	}
+	if s.config.Interval > 0 {
+		return s.config.Interval
+	}
+	return DefaultInterval
+}
+
+// timeout returns the configured keep-alive timeout or the default
+func (s *keepAliveServiceImpl) timeout() time.Duration {
+	if s.config.Timeout > 0 {
+		return s.config.Timeout
+	}
+	return DefaultTimeout
+}
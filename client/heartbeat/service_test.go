@@ -0,0 +1,152 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender counts heartbeats and fails while err is set
+type fakeSender struct {
+	calls atomic.Int32
+	mu    sync.Mutex
+	err   error
+}
+
+func (f *fakeSender) SendHeartbeat() error {
+	f.calls.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *fakeSender) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// recordingHandler records degradation events
+type recordingHandler struct {
+	mu     sync.Mutex
+	missed []int
+	lost   int
+}
+
+func (h *recordingHandler) OnHeartbeatMissed(missed int, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.missed = append(h.missed, missed)
+}
+
+func (h *recordingHandler) OnServerLost(time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lost++
+}
+
+func (h *recordingHandler) counts() (int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.missed), h.lost
+}
+
+func TestKeepAliveServiceSendsHeartbeats(t *testing.T) {
+	sender := &fakeSender{}
+	service := NewKeepAliveService(&config.KeepAliveConfig{Interval: 10 * time.Millisecond},
+		func() Sender { return sender }, logger.NewDefault())
+
+	require.NoError(t, service.Start(context.Background()))
+	assert.Error(t, service.Start(context.Background()), "starting twice fails")
+
+	assert.Eventually(t, func() bool { return sender.calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, service.Stop())
+
+	status := service.Status()
+	assert.Zero(t, status.Missed)
+	assert.WithinDuration(t, time.Now(), status.LastHeartbeat, time.Second)
+}
+
+func TestKeepAliveServiceSetInterval(t *testing.T) {
+	sender := &fakeSender{}
+	service := NewKeepAliveService(&config.KeepAliveConfig{Interval: time.Hour},
+		func() Sender { return sender }, logger.NewDefault())
+
+	require.NoError(t, service.Start(context.Background()))
+	defer service.Stop()
+	assert.Eventually(t, func() bool { return sender.calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	service.SetInterval(10 * time.Millisecond)
+	assert.Eventually(t, func() bool { return sender.calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+}
+
+func TestKeepAliveServiceRetriesAndReportsDegradation(t *testing.T) {
+	sender := &fakeSender{}
+	sender.fail(errors.New("connection refused"))
+	handler := &recordingHandler{}
+
+	service := NewKeepAliveService(&config.KeepAliveConfig{
+		Interval:      20 * time.Millisecond,
+		Timeout:       50 * time.Millisecond,
+		RetryInterval: time.Millisecond,
+		MaxRetries:    2,
+	}, func() Sender { return sender }, logger.NewDefault())
+	service.RegisterHandler(handler)
+
+	impl := service.(*keepAliveServiceImpl)
+	ctx := context.Background()
+	impl.beat(ctx)
+	assert.Equal(t, int32(3), sender.calls.Load(), "one attempt plus max_retries")
+	missed, lost := handler.counts()
+	assert.Equal(t, 1, missed)
+	assert.Zero(t, lost)
+
+	time.Sleep(60 * time.Millisecond)
+	impl.beat(ctx)
+	impl.beat(ctx)
+	missed, lost = handler.counts()
+	assert.Equal(t, 3, missed)
+	assert.Equal(t, 1, lost, "the server is reported lost once")
+	assert.True(t, service.Status().Lost)
+
+	sender.fail(nil)
+	impl.beat(ctx)
+	status := service.Status()
+	assert.Zero(t, status.Missed)
+	assert.False(t, status.Lost)
+}
+
+func TestKeepAliveServicePause(t *testing.T) {
+	sender := &fakeSender{}
+	var current atomic.Pointer[fakeSender]
+	current.Store(sender)
+	service := NewKeepAliveService(&config.KeepAliveConfig{}, func() Sender {
+		if s := current.Load(); s != nil {
+			return s
+		}
+		return nil
+	}, logger.NewDefault())
+	impl := service.(*keepAliveServiceImpl)
+
+	service.Pause()
+	impl.beat(context.Background())
+	assert.Zero(t, sender.calls.Load(), "no heartbeats while paused")
+	assert.True(t, service.Status().Paused)
+
+	service.Resume()
+	impl.beat(context.Background())
+	assert.Equal(t, int32(1), sender.calls.Load())
+
+	// Without a connected server nothing is sent and nothing is missed
+	current.Store(nil)
+	impl.beat(context.Background())
+	assert.Zero(t, service.Status().Missed)
+}
@@ -6,23 +6,37 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 	"github.com/pkg/errors"
 )
 
 // HetznerClient defines the interface for Hetzner Cloud operations
-type HetznerClient interface {
-	ProvisionServer(ctx context.Context, config *ServerConfig) (*Server, error)
-	DestroyServer(ctx context.Context, serverID string) error
-	CreateVolume(ctx context.Context, size int, location string) (*Volume, error)
-	FindOrCreateDockerVolume(ctx context.Context, location string) (*Volume, error)
-	AttachVolume(ctx context.Context, serverID, volumeID string) error
-	DetachVolume(ctx context.Context, volumeID string) error
-	ManageSSHKeys(ctx context.Context, publicKey string) (*SSHKey, error)
-	GetServer(ctx context.Context, serverID string) (*Server, error)
-	ListServers(ctx context.Context) ([]*Server, error)
-	GetVolume(ctx context.Context, volumeID string) (*Volume, error)
-	ListVolumes(ctx context.Context) ([]*Volume, error)
+type HetznerClient = provider.CloudProvider
+
+// Provider-neutral types shared with the other backends
+type (
+	ServerConfig = provider.ServerConfig
+	Server       = provider.Server
+	Volume       = provider.Volume
+	SSHKey       = provider.SSHKey
+)
+
+// ProviderName is the name the Hetzner backend is registered under
+const ProviderName = "hetzner"
+
+func init() {
+	provider.Register(ProviderName, func(cfg *config.ClientConfig, server config.ServerSettings) (provider.CloudProvider, error) {
+		return NewClient(&Config{
+			APIToken:        server.APIToken,
+			ServerType:      server.ServerType,
+			Location:        server.Location,
+			VolumeSize:      server.VolumeSize,
+			PreferredImages: cfg.Hetzner.PreferredImages,
+			Owner:           provider.LocalOwner(),
+		})
+	})
 }
 
 // Client implements the HetznerClient interface
@@ -38,6 +52,10 @@ type Config struct {
 	Location        string
 	VolumeSize      int
 	PreferredImages []string
+
+	// Owner labels the volumes and images the client creates; only volumes and images
+	// of this owner are reused or deleted. A zero owner disables both.
+	Owner provider.Owner
 }
 
 // NewClient creates a new Hetzner client instance
@@ -46,7 +64,7 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, errors.New("Hetzner API token is required")
 	}
 
-	hcloudClient := hcloud.NewClient(hcloud.WithToken(config.APIToken))
+	hcloudClient := newHCloudClient(config.APIToken)
 
 	return &Client{
 		hcloud: hcloudClient,
@@ -54,50 +72,12 @@ func NewClient(config *Config) (*Client, error) {
 	}, nil
 }
 
-// ServerConfig defines configuration for server provisioning
-type ServerConfig struct {
-	Name       string
-	ServerType string
-	Location   string
-	SSHKeyID   int64
-	VolumeID   string
-	UserData   string
-	ImageName  string // Added to track which image is being used
-}
-
-// Server represents a Hetzner Cloud server
-type Server struct {
-	ID        int64
-	Name      string
-	Status    string
-	IPAddress string
-	VolumeID  string
-	CreatedAt time.Time
-}
-
-// Volume represents a Hetzner Cloud volume
-type Volume struct {
-	ID       int64
-	Name     string
-	Size     int
-	Location string
-	Status   string
-}
-
-// SSHKey represents a Hetzner Cloud SSH key
-type SSHKey struct {
-	ID          int64
-	Name        string
-	Fingerprint string
-	PublicKey   string
-}
-
 // ProvisionServer creates a new server with Docker CE and cloud-init configuration
 func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Server, error) {
 	// Get server type
 	serverType, _, err := c.hcloud.ServerType.GetByName(ctx, config.ServerType)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get server type")
+		return nil, classifyError(errors.Wrap(err, "failed to get server type"))
 	}
 	if serverType == nil {
 		return nil, fmt.Errorf("server type %s not found", config.ServerType)
@@ -125,10 +105,27 @@ func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Se
 		fmt.Printf("Using configured preferred images: %v\n", preferredImages)
 	}
 
+	// Images are per architecture; ARM (CAX) server types need ARM images
+	architecture := architectureOf(serverType)
+
+	// An explicit image (a golden snapshot) takes precedence over the preferred images
+	if config.ImageID > 0 {
+		image, _, err = c.hcloud.Image.GetByID(ctx, config.ImageID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get image")
+		}
+		if image == nil {
+			return nil, fmt.Errorf("image %d not found", config.ImageID)
+		}
+		imageName = image.Description
+		fmt.Printf("✓ Using Hetzner image: %s (ID: %d)\n", imageName, image.ID)
+		preferredImages = nil
+	}
+
 	// Try each preferred image in order
 	for _, preferredImage := range preferredImages {
-		fmt.Printf("Trying to get Hetzner image: %s\n", preferredImage)
-		image, _, err = c.hcloud.Image.GetByNameAndArchitecture(ctx, preferredImage, hcloud.ArchitectureX86)
+		fmt.Printf("Trying to get Hetzner image: %s (%s)\n", preferredImage, architecture)
+		image, _, err = c.hcloud.Image.GetByNameAndArchitecture(ctx, preferredImage, architecture)
 		if err == nil && image != nil {
 			imageName = preferredImage
 			fmt.Printf("✓ Successfully selected Hetzner image: %s (ID: %d)\n", imageName, image.ID)
@@ -139,7 +136,7 @@ func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Se
 
 	// If no preferred image was found, return error
 	if image == nil {
-		return nil, errors.New("no suitable server image found from preferred list")
+		return nil, fmt.Errorf("no suitable %s server image found from preferred list", architecture)
 	}
 
 	// Store the image name in config for cloud-init optimization
@@ -150,7 +147,12 @@ func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Se
 		// Generate default cloud-init configuration optimized for the selected image
 		cloudInitConfig := GetDefaultCloudInitConfig()
 		cloudInitConfig.VolumeID = config.VolumeID
-		config.UserData = GenerateCloudInitForImage(cloudInitConfig, imageName)
+		cloudInitConfig.Volumes = config.Volumes
+		userData, err := GenerateCloudInitForImage(cloudInitConfig, imageName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate cloud-init")
+		}
+		config.UserData = userData
 	}
 
 	// Prepare server creation options
@@ -159,6 +161,7 @@ func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Se
 		ServerType: serverType,
 		Image:      image,
 		Location:   location,
+		Labels:     config.Labels,
 	}
 
 	// Add UserData (now guaranteed to be set)
@@ -180,11 +183,19 @@ func (c *Client) ProvisionServer(ctx context.Context, config *ServerConfig) (*Se
 	} else {
 		fmt.Printf("DEBUG: No volume ID provided for server creation\n")
 	}
+	for _, volume := range config.Volumes {
+		opts.Volumes = append(opts.Volumes, &hcloud.Volume{ID: parseVolumeID(volume.ID)})
+	}
+
+	// Attach the server to the configured network, firewall and placement group
+	if err := c.applyProjectOptions(ctx, config, &opts); err != nil {
+		return nil, err
+	}
 
 	// Create the server
 	result, _, err := c.hcloud.Server.Create(ctx, opts)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create server")
+		return nil, classifyError(errors.Wrap(err, "failed to create server"))
 	}
 
 	// Wait for server to be running
@@ -231,8 +242,42 @@ func (c *Client) DestroyServer(ctx context.Context, serverID string) error {
 	return nil
 }
 
+// volumeLabel marks volumes with the name of their volume configuration
+const volumeLabel = "dockbridge-volume"
+
 // CreateVolume creates a new persistent volume for Docker data
 func (c *Client) CreateVolume(ctx context.Context, size int, location string) (*Volume, error) {
+	return c.createVolume(ctx, config.DockerDataVolume, size, location)
+}
+
+// FindOrCreateNamedVolume reuses an unattached volume created for the named volume
+// configuration in location, or creates one. Reusing volumes by name keeps their
+// data across servers.
+func (c *Client) FindOrCreateNamedVolume(ctx context.Context, name string, size int, location string) (*Volume, error) {
+	volumes, err := c.hcloud.Volume.All(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	for _, volume := range volumes {
+		if volume.Server != nil || volume.Location == nil || volume.Location.Name != location {
+			continue
+		}
+		if !c.owns(volume.Labels) {
+			continue
+		}
+		// Docker data volumes created before named volumes carry no label
+		legacy := name == config.DockerDataVolume && strings.HasPrefix(volume.Name, "dockbridge-docker-data")
+		if volume.Labels[volumeLabel] == name || legacy {
+			return convertVolume(volume), nil
+		}
+	}
+
+	return c.createVolume(ctx, name, size, location)
+}
+
+// createVolume creates an ext4 volume for the named volume configuration
+func (c *Client) createVolume(ctx context.Context, name string, size int, location string) (*Volume, error) {
 	// Get location
 	loc, _, err := c.hcloud.Location.GetByName(ctx, location)
 	if err != nil {
@@ -242,24 +287,31 @@ func (c *Client) CreateVolume(ctx context.Context, size int, location string) (*
 		return nil, fmt.Errorf("location %s not found", location)
 	}
 
-	// Generate unique volume name with Docker data identifier
-	volumeName := fmt.Sprintf("dockbridge-docker-data-%d", time.Now().Unix())
+	// Generate unique volume name with the volume configuration's name
+	volumeName := fmt.Sprintf("dockbridge-%s-%d", name, time.Now().Unix())
+
+	purpose := "data"
+	if name == config.DockerDataVolume {
+		purpose = "docker-data"
+	}
+
+	labels := c.ownerLabels()
+	labels["purpose"] = purpose
+	labels["created-by"] = "dockbridge"
+	labels[volumeLabel] = name
 
-	// Create volume with ext4 filesystem for Docker data
+	// Create volume with ext4 filesystem
 	opts := hcloud.VolumeCreateOpts{
 		Name:     volumeName,
 		Size:     size,
 		Location: loc,
 		Format:   hcloud.Ptr("ext4"),
-		Labels: map[string]string{
-			"purpose":    "docker-data",
-			"created-by": "dockbridge",
-		},
+		Labels:   labels,
 	}
 
 	result, _, err := c.hcloud.Volume.Create(ctx, opts)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create volume")
+		return nil, classifyError(errors.Wrap(err, "failed to create volume"))
 	}
 
 	// Wait for volume creation to complete
@@ -280,7 +332,7 @@ func (c *Client) FindOrCreateDockerVolume(ctx context.Context, location string)
 
 	// Look for existing Docker data volume in the same location
 	for _, volume := range volumes {
-		if volume.Location == location && strings.Contains(volume.Name, "dockbridge-docker-data") {
+		if volume.Location == location && strings.Contains(volume.Name, "dockbridge-docker-data") && c.owns(volume.Labels) {
 			// Check if volume is available (not attached to another server)
 			if volume.Status == "available" {
 				return volume, nil
@@ -346,6 +398,32 @@ func (c *Client) DetachVolume(ctx context.Context, volumeID string) error {
 	return nil
 }
 
+// ResizeVolume grows a volume to size GB. Hetzner volumes cannot shrink.
+func (c *Client) ResizeVolume(ctx context.Context, volumeID string, size int) (*Volume, error) {
+	volume, _, err := c.hcloud.Volume.GetByID(ctx, parseVolumeID(volumeID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get volume")
+	}
+	if volume == nil {
+		return nil, fmt.Errorf("volume %s not found", volumeID)
+	}
+	if size < volume.Size {
+		return nil, fmt.Errorf("volume %s is %d GB and cannot shrink to %d GB", volumeID, volume.Size, size)
+	}
+
+	if size > volume.Size {
+		action, _, err := c.hcloud.Volume.Resize(ctx, volume, size)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resize volume")
+		}
+		if err := c.hcloud.Action.WaitFor(ctx, action); err != nil {
+			return nil, errors.Wrap(err, "failed to wait for volume resize")
+		}
+	}
+
+	return c.GetVolume(ctx, volumeID)
+}
+
 // ManageSSHKeys uploads and manages SSH keys, reusing existing keys if they match
 func (c *Client) ManageSSHKeys(ctx context.Context, publicKey string) (*SSHKey, error) {
 	// First, try to find an existing SSH key with the same public key
@@ -438,7 +516,7 @@ func (c *Client) GetServer(ctx context.Context, serverID string) (*Server, error
 func (c *Client) ListServers(ctx context.Context) ([]*Server, error) {
 	servers, err := c.hcloud.Server.All(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list servers")
+		return nil, classifyError(errors.Wrap(err, "failed to list servers"))
 	}
 
 	result := make([]*Server, 0, len(servers))
@@ -480,3 +558,9 @@ func (c *Client) ListVolumes(ctx context.Context) ([]*Volume, error) {
 
 	return result, nil
 }
+
+// Ensure Client can grow volumes
+var _ provider.VolumeResizer = (*Client)(nil)
+
+// Ensure Client keeps named volumes across servers
+var _ provider.NamedVolumeProvider = (*Client)(nil)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -79,7 +80,10 @@ func (suite *HetznerClientTestSuite) TestConvertServer() {
 				IP: ip,
 			},
 		},
-		Volumes: []*hcloud.Volume{volume},
+		Volumes:         []*hcloud.Volume{volume},
+		IncludedTraffic: 20 << 40,
+		OutgoingTraffic: 1 << 30,
+		IngoingTraffic:  2 << 30,
 	}
 
 	server := convertServer(hcloudServer)
@@ -89,6 +93,34 @@ func (suite *HetznerClientTestSuite) TestConvertServer() {
 	suite.Equal("running", server.Status)
 	suite.Equal("192.168.1.1", server.IPAddress)
 	suite.Equal("67890", server.VolumeID)
+	suite.Equal([]string{"67890"}, server.VolumeIDs)
+	suite.Equal(uint64(20<<40), server.IncludedTraffic)
+	suite.Equal(uint64(1<<30), server.OutgoingTraffic)
+	suite.Equal(uint64(2<<30), server.IngoingTraffic)
+}
+
+func (suite *HetznerClientTestSuite) TestConvertServerWithoutIPv4() {
+	_, ipv6Network, _ := net.ParseCIDR("2001:db8:1:2::/64")
+	hcloudServer := &hcloud.Server{
+		ID:   12345,
+		Name: "test-server",
+		PublicNet: hcloud.ServerPublicNet{
+			IPv6: hcloud.ServerPublicNetIPv6{IP: ipv6Network.IP, Network: ipv6Network},
+		},
+		PrivateNet: []hcloud.ServerPrivateNet{{IP: net.ParseIP("10.0.0.5")}},
+	}
+
+	// IPv6-only servers are reached on the first address of their network
+	server := convertServer(hcloudServer)
+	suite.Equal("2001:db8:1:2::1", server.IPAddress)
+	suite.Equal("2001:db8:1:2::1", server.IPv6Address)
+	suite.Equal("10.0.0.5", server.PrivateIPAddress)
+
+	// Servers without public addresses are reached over their network
+	hcloudServer.PublicNet = hcloud.ServerPublicNet{}
+	server = convertServer(hcloudServer)
+	suite.Equal("10.0.0.5", server.IPAddress)
+	suite.Empty(server.IPv6Address)
 }
 
 func (suite *HetznerClientTestSuite) TestConvertServerNil() {
@@ -111,11 +143,12 @@ func (suite *HetznerClientTestSuite) TestConvertVolume() {
 
 	volume := convertVolume(hcloudVolume)
 
-	suite.Equal(int64(67890), volume.ID)
+	suite.Equal("67890", volume.ID)
 	suite.Equal("test-volume", volume.Name)
 	suite.Equal(10, volume.Size)
 	suite.Equal("fsn1", volume.Location)
 	suite.Equal("available", volume.Status)
+	suite.Equal("/dev/disk/by-id/scsi-0HC_Volume_67890", volume.Device)
 }
 
 func (suite *HetznerClientTestSuite) TestConvertSSHKey() {
@@ -172,7 +205,9 @@ func TestGenerateCloudInitScript(t *testing.T) {
 		DockerAPIPort: 2376,
 	}
 
-	script := GenerateCloudInitScript(config)
+	script, err := GenerateCloudInitScript(config)
+
+	require.NoError(t, err)
 
 	assert.Contains(t, script, "#cloud-config")
 	assert.Contains(t, script, "ssh-rsa AAAAB3NzaC1yc2E...")
@@ -186,7 +221,8 @@ func TestGenerateCloudInitScript(t *testing.T) {
 }
 
 func TestGenerateCloudInitScriptDefaults(t *testing.T) {
-	script := GenerateCloudInitScript(nil)
+	script, err := GenerateCloudInitScript(nil)
+	require.NoError(t, err)
 
 	assert.Contains(t, script, "#cloud-config")
 	assert.Contains(t, script, "/var/lib/docker")
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/client/provider"
 )
 
 func TestGenerateCloudInitForImage(t *testing.T) {
@@ -38,7 +41,10 @@ func TestGenerateCloudInitForImage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			script := GenerateCloudInitForImage(config, tt.imageName)
+			script, err := GenerateCloudInitForImage(config, tt.imageName)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if tt.expectOpt {
 				// Optimized script should skip package updates
@@ -83,7 +89,13 @@ func TestGenerateOptimizedCloudInitScript(t *testing.T) {
 		Packages:      []string{"curl", "wget"},
 	}
 
-	script := generateOptimizedCloudInitScript(config)
+	script, err := GenerateCloudInitScript(config)
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
 
 	// Check optimized characteristics
 	if !strings.Contains(script, "package_update: false") {
@@ -123,7 +135,13 @@ func TestGenerateFullDockerInstallScript(t *testing.T) {
 		DockerAPIPort: 2376,
 	}
 
-	script := generateFullDockerInstallScript(config)
+	script, err := GenerateCloudInitForImage(config, "ubuntu-22.04")
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
 
 	// Check full installation characteristics
 	if !strings.Contains(script, "package_update: true") {
@@ -188,7 +206,13 @@ func TestGenerateVolumeSetupScriptWithVolumeID(t *testing.T) {
 		VolumeMount: "/var/lib/docker",
 	}
 
-	script := generateVolumeSetupScript(config)
+	script, err := GenerateCloudInitScript(config)
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
 
 	// Check for deterministic Volume ID path
 	expectedPath := fmt.Sprintf("/dev/disk/by-id/scsi-0HC_Volume_%s", volumeID)
@@ -204,6 +228,45 @@ func TestGenerateVolumeSetupScriptWithVolumeID(t *testing.T) {
 	if !strings.Contains(script, "readlink -f \"$EXPECTED_DEVICE\"") {
 		t.Error("Expected script to resolve symlink for device")
 	}
+
+	// Volumes grown while detached are extended on mount
+	if !strings.Contains(script, "resize2fs \"$VOLUME_DEVICE\"") {
+		t.Error("Expected script to grow the filesystem after mounting")
+	}
+}
+
+func TestGenerateVolumeSetupScriptWithNamedVolumes(t *testing.T) {
+	config := &CloudInitConfig{
+		VolumeID:    "111",
+		VolumeMount: "/var/lib/docker",
+		Volumes: []provider.VolumeAttachment{
+			{ID: "222", Name: "data", Mount: "/data"},
+		},
+	}
+
+	script, err := GenerateCloudInitScript(config)
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+
+	for _, expected := range []string{
+		"/dev/disk/by-id/scsi-0HC_Volume_111",
+		"/dev/disk/by-id/scsi-0HC_Volume_222",
+		"mount \"$VOLUME_DEVICE\" /var/lib/docker",
+		"mount \"$VOLUME_DEVICE\" /data",
+		"mkfs.ext4 -F -L \"data\"",
+		"/tmp/volume-backup-data",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("Expected script to contain %q", expected)
+		}
+	}
+	if strings.Index(script, "scsi-0HC_Volume_111") > strings.Index(script, "scsi-0HC_Volume_222") {
+		t.Error("Expected the Docker data volume to be set up first")
+	}
 }
 
 func TestGenerateVolumeSetupScriptWithoutVolumeID(t *testing.T) {
@@ -212,7 +275,13 @@ func TestGenerateVolumeSetupScriptWithoutVolumeID(t *testing.T) {
 		VolumeMount: "/var/lib/docker",
 	}
 
-	script := generateVolumeSetupScript(config)
+	script, err := GenerateCloudInitScript(config)
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
 
 	// Should not contain by-id path
 	if strings.Contains(script, "/dev/disk/by-id/scsi-0HC_Volume_") {
@@ -224,3 +293,73 @@ func TestGenerateVolumeSetupScriptWithoutVolumeID(t *testing.T) {
 		t.Error("Expected legacy device detection loop")
 	}
 }
+
+func TestGenerateDockerConfigurationScriptWithTLS(t *testing.T) {
+	plain, err := GenerateCloudInitScript(&CloudInitConfig{VolumeMount: "/var/lib/docker", DockerAPIPort: 2376})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plain, `"tls": false`) {
+		t.Error("Expected plaintext Docker API without TLS bundle")
+	}
+
+	bundle, err := dockertls.Generate(0)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	config := &CloudInitConfig{VolumeMount: "/var/lib/docker", DockerAPIPort: 2376, DockerTLS: bundle}
+	script, err := GenerateCloudInitScript(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"tls": true`, `"tlsverify": true`, dockertls.RemoteCACert, dockertls.RemoteServerKey} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected %q in script", want)
+		}
+	}
+
+	// Certificate lines must be indented into the runcmd block
+	for _, line := range strings.Split(strings.TrimSpace(string(bundle.ServerCert)), "\n") {
+		if !strings.Contains(script, "\n    "+line+"\n") {
+			t.Fatalf("Expected indented certificate line %q in script", line)
+		}
+	}
+}
+
+func TestGenerateDockBridgeServerScriptWithKeepAliveToken(t *testing.T) {
+	script, err := GenerateCloudInitScript(&CloudInitConfig{VolumeMount: "/var/lib/docker", KeepAlivePort: 8080, KeepAliveToken: "abc123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, "DOCKBRIDGE_AUTH_TOKEN=abc123\n") {
+		t.Error("Expected keep-alive token in the server environment file")
+	}
+	if !strings.Contains(script, "chmod 600 /etc/dockbridge/env") {
+		t.Error("Expected the environment file to be readable by root only")
+	}
+}
+
+func TestGenerateDockBridgeServerScriptWithKeepAliveOverSSH(t *testing.T) {
+	script, err := GenerateCloudInitScript(&CloudInitConfig{VolumeMount: "/var/lib/docker", KeepAlivePort: 8080, KeepAliveOverSSH: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, "DOCKBRIDGE_LOOPBACK_ONLY=true\n") {
+		t.Error("Expected the keep-alive monitor to listen on loopback only")
+	}
+	if strings.Contains(script, "ufw allow 8080/tcp") {
+		t.Error("Expected the keep-alive port to stay closed")
+	}
+
+	script, err = GenerateCloudInitScript(&CloudInitConfig{VolumeMount: "/var/lib/docker", KeepAlivePort: 8080})
+
+	if err != nil {
+
+		t.Fatal(err)
+
+	}
+	if !strings.Contains(script, "ufw allow 8080/tcp") {
+		t.Error("Expected the keep-alive port to be opened")
+	}
+}
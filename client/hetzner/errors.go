@@ -0,0 +1,32 @@
+package hetzner
+
+import (
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+)
+
+// classifyError turns API errors the user has to act on into DockBridge errors with a
+// remediation hint, so the CLI exits with a distinct code; other errors are returned
+// unchanged
+func classifyError(err error) error {
+	var rateLimitErr *RateLimitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rateLimitErr):
+		return dberrors.NewRateLimitError("Hetzner API rate limit exceeded", err).
+			WithHint("Wait a few minutes, or use fewer DockBridge clients and API tokens in the same Hetzner project")
+	case hcloud.IsError(err, hcloud.ErrorCodeResourceLimitExceeded):
+		return dberrors.NewQuotaExceededError("Hetzner project limit reached", err).
+			WithHint("Delete unused servers or volumes, or request a limit increase in the Hetzner Cloud Console")
+	case hcloud.IsError(err, hcloud.ErrorCodeUnauthorized):
+		return dberrors.NewError(dberrors.ErrCategoryAuth, dberrors.ErrCodeUnauthorized, "Hetzner API token is invalid", err, false).
+			WithHint(`Create a new API token in the Hetzner Cloud Console and run "dockbridge auth login"`)
+	case hcloud.IsError(err, hcloud.ErrorCodeForbidden):
+		return dberrors.NewError(dberrors.ErrCategoryAuth, dberrors.ErrCodeForbidden, "Hetzner API token may not do this", err, false).
+			WithHint(`Use an API token with "Read & Write" permission`)
+	default:
+		return err
+	}
+}
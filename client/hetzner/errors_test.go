@@ -0,0 +1,39 @@
+package hetzner
+
+import (
+	"testing"
+
+	dberrors "github.com/dockbridge/dockbridge/pkg/errors"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	apiError := func(code hcloud.ErrorCode) error {
+		return errors.Wrap(hcloud.Error{Code: code, Message: string(code)}, "failed to create server")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"quota", apiError(hcloud.ErrorCodeResourceLimitExceeded), dberrors.ExitQuotaExceeded},
+		{"invalid token", apiError(hcloud.ErrorCodeUnauthorized), dberrors.ExitAuth},
+		{"read-only token", apiError(hcloud.ErrorCodeForbidden), dberrors.ExitAuth},
+		{"rate limited", errors.Wrap(&RateLimitError{Limit: 3600}, "failed to list servers"), dberrors.ExitRateLimited},
+		{"other", apiError(hcloud.ErrorCodeServerError), dberrors.ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			assert.Equal(t, tt.want, dberrors.ExitCode(err))
+			assert.ErrorIs(t, err, errors.Cause(tt.err))
+			if tt.want != dberrors.ExitFailure {
+				assert.NotEmpty(t, dberrors.Hint(err))
+			}
+		})
+	}
+	assert.NoError(t, classifyError(nil))
+}
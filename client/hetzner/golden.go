@@ -0,0 +1,182 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+)
+
+// goldenImageLabel marks golden snapshots; its value is the image version ("v1")
+const goldenImageLabel = "dockbridge-golden"
+
+// GoldenImage returns the newest current-version golden snapshot that a server of
+// serverType can boot from, or nil if there is none
+func (c *Client) GoldenImage(ctx context.Context, serverType string) (*provider.Image, error) {
+	hcloudType, _, err := c.hcloud.ServerType.GetByName(ctx, serverType)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get server type")
+	}
+	if hcloudType == nil {
+		return nil, fmt.Errorf("server type %s not found", serverType)
+	}
+
+	images, err := c.hcloud.Image.AllWithOpts(ctx, hcloud.ImageListOpts{
+		ListOpts: hcloud.ListOpts{
+			LabelSelector: fmt.Sprintf("%s=%s", goldenImageLabel, goldenImageLabelValue(provider.GoldenImageVersion)),
+		},
+		Type:         []hcloud.ImageType{hcloud.ImageTypeSnapshot},
+		Status:       []hcloud.ImageStatus{hcloud.ImageStatusAvailable},
+		Architecture: []hcloud.Architecture{architectureOf(hcloudType)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list golden images")
+	}
+
+	image := pickGoldenImage(images, hcloudType.Disk)
+	if image == nil {
+		return nil, nil
+	}
+	return convertImage(image), nil
+}
+
+// CreateGoldenImage snapshots a server into a current-version golden image
+func (c *Client) CreateGoldenImage(ctx context.Context, serverID string) (*provider.Image, error) {
+	server, err := c.getServerByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	architecture := hcloud.ArchitectureX86
+	if server.ServerType != nil {
+		architecture = architectureOf(server.ServerType)
+	}
+
+	labels := c.ownerLabels()
+	labels[goldenImageLabel] = goldenImageLabelValue(provider.GoldenImageVersion)
+	labels["architecture"] = string(architecture)
+	labels["created-by"] = "dockbridge"
+
+	result, _, err := c.hcloud.Server.CreateImage(ctx, server, &hcloud.ServerCreateImageOpts{
+		Type:        hcloud.ImageTypeSnapshot,
+		Description: hcloud.Ptr(provider.GoldenImageName(provider.GoldenImageVersion)),
+		Labels:      labels,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create golden image")
+	}
+	if err := c.hcloud.Action.WaitFor(ctx, result.Action); err != nil {
+		return nil, errors.Wrap(err, "failed to wait for golden image")
+	}
+
+	image, _, err := c.hcloud.Image.GetByID(ctx, result.Image.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get golden image")
+	}
+	if image == nil {
+		return nil, errors.New("created golden image not found")
+	}
+	return convertImage(image), nil
+}
+
+// ListGoldenImages returns the golden snapshots of all versions
+func (c *Client) ListGoldenImages(ctx context.Context) ([]*provider.Image, error) {
+	images, err := c.allGoldenImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*provider.Image, 0, len(images))
+	for _, image := range images {
+		result = append(result, convertImage(image))
+	}
+	return result, nil
+}
+
+// DeleteGoldenImages deletes the golden snapshots of all versions
+func (c *Client) DeleteGoldenImages(ctx context.Context) (int, error) {
+	images, err := c.allGoldenImages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, image := range images {
+		if _, err := c.hcloud.Image.Delete(ctx, image); err != nil {
+			return deleted, errors.Wrapf(err, "failed to delete golden image %d", image.ID)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// allGoldenImages lists the golden snapshots of all versions
+func (c *Client) allGoldenImages(ctx context.Context) ([]*hcloud.Image, error) {
+	selector := goldenImageLabel
+	if c.config.Owner.ID != "" {
+		selector += "," + provider.LabelOwner + "=" + provider.LabelValue(c.config.Owner.ID)
+	}
+	images, err := c.hcloud.Image.AllWithOpts(ctx, hcloud.ImageListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: selector},
+		Type:     []hcloud.ImageType{hcloud.ImageTypeSnapshot},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list golden images")
+	}
+	return images, nil
+}
+
+// pickGoldenImage returns the newest image that fits on a disk of diskGB
+func pickGoldenImage(images []*hcloud.Image, diskGB int) *hcloud.Image {
+	var newest *hcloud.Image
+	for _, image := range images {
+		if diskGB > 0 && image.DiskSize > float32(diskGB) {
+			continue
+		}
+		if newest == nil || image.Created.After(newest.Created) {
+			newest = image
+		}
+	}
+	return newest
+}
+
+// convertImage converts an hcloud image to the provider-neutral type
+func convertImage(image *hcloud.Image) *provider.Image {
+	return &provider.Image{
+		ID:           image.ID,
+		Name:         image.Description,
+		Architecture: string(image.Architecture),
+		Version:      parseGoldenImageVersion(image.Labels[goldenImageLabel]),
+		SizeGB:       image.ImageSize,
+		CreatedAt:    image.Created,
+	}
+}
+
+// goldenImageLabelValue returns the label value of a golden image version
+func goldenImageLabelValue(version int) string {
+	return "v" + strconv.Itoa(version)
+}
+
+// parseGoldenImageVersion parses a golden image label value, returning 0 if it is invalid
+func parseGoldenImageVersion(value string) int {
+	version, err := strconv.Atoi(strings.TrimPrefix(value, "v"))
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// architectureOf returns the CPU architecture of a server type
+func architectureOf(serverType *hcloud.ServerType) hcloud.Architecture {
+	if serverType.Architecture == "" {
+		return hcloud.ArchitectureX86
+	}
+	return serverType.Architecture
+}
+
+// Ensure Client can build golden images
+var _ provider.GoldenImager = (*Client)(nil)
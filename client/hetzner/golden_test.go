@@ -0,0 +1,38 @@
+package hetzner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickGoldenImage(t *testing.T) {
+	now := time.Now()
+	older := &hcloud.Image{ID: 1, DiskSize: 40, Created: now.Add(-time.Hour)}
+	newer := &hcloud.Image{ID: 2, DiskSize: 40, Created: now}
+	large := &hcloud.Image{ID: 3, DiskSize: 160, Created: now.Add(time.Hour)}
+
+	// The newest image wins unless it does not fit on the server's disk
+	assert.Equal(t, newer, pickGoldenImage([]*hcloud.Image{older, newer, large}, 80))
+	assert.Equal(t, large, pickGoldenImage([]*hcloud.Image{older, newer, large}, 160))
+	assert.Nil(t, pickGoldenImage([]*hcloud.Image{large}, 40))
+	assert.Nil(t, pickGoldenImage(nil, 40))
+}
+
+func TestConvertImage(t *testing.T) {
+	image := convertImage(&hcloud.Image{
+		ID:           42,
+		Description:  "dockbridge-golden-v3",
+		Architecture: hcloud.ArchitectureARM,
+		ImageSize:    2.5,
+		Labels:       map[string]string{goldenImageLabel: goldenImageLabelValue(3)},
+	})
+
+	assert.Equal(t, int64(42), image.ID)
+	assert.Equal(t, "dockbridge-golden-v3", image.Name)
+	assert.Equal(t, "arm", image.Architecture)
+	assert.Equal(t, 3, image.Version)
+	assert.Equal(t, 0, parseGoldenImageVersion("latest"))
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dockbridge/dockbridge/client/dockertls"
+	"github.com/dockbridge/dockbridge/client/provider"
+	sharedconfig "github.com/dockbridge/dockbridge/shared/config"
 	"github.com/pkg/errors"
 )
 
@@ -20,9 +23,13 @@ func NewLifecycleManager(client *Client) *LifecycleManager {
 	}
 }
 
-// ProvisionServerWithVolume provisions a server with persistent volume and proper cleanup
+// ProvisionServerWithVolume provisions a server with persistent volumes and proper cleanup.
+// Volumes are reused by name when an earlier server left them behind, and are never
+// deleted on failure since they may hold that server's data.
 func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, config *ServerProvisionConfig) (*ServerWithVolume, error) {
 	var volume *Volume
+	var volumes []*Volume
+	var attachments []provider.VolumeAttachment
 	var server *Server
 	var sshKey *SSHKey
 	var err error
@@ -35,9 +42,9 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 		}
 	}
 
-	// Create volume if size is specified
+	// Find or create the Docker data volume if size is specified
 	if config.VolumeSize > 0 {
-		volume, err = lm.client.CreateVolume(ctx, config.VolumeSize, config.Location)
+		volume, err = lm.client.FindOrCreateNamedVolume(ctx, sharedconfig.DockerDataVolume, config.VolumeSize, config.Location)
 		if err != nil {
 			// Cleanup SSH key if volume creation fails
 			if sshKey != nil {
@@ -47,19 +54,44 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 		}
 	}
 
+	// Find or create the additional named volumes
+	for _, volumeCfg := range config.Volumes {
+		named, err := lm.client.FindOrCreateNamedVolume(ctx, volumeCfg.Name, volumeCfg.Size, config.Location)
+		if err != nil {
+			if sshKey != nil {
+				lm.cleanupSSHKey(ctx, sshKey.ID)
+			}
+			return nil, errors.Wrapf(err, "failed to create volume %s", volumeCfg.Name)
+		}
+		volumes = append(volumes, named)
+		attachments = append(attachments, provider.VolumeAttachment{ID: named.ID, Name: volumeCfg.Name, Mount: volumeCfg.Mount})
+	}
+
 	// Generate cloud-init script
 	cloudInitConfig := &CloudInitConfig{
 		SSHPublicKey:  config.SSHPublicKey,
 		VolumeMount:   config.VolumeMount,
+		Volumes:       attachments,
 		KeepAlivePort: config.KeepAlivePort,
 		DockerAPIPort: config.DockerAPIPort,
+		DockerTLS:     config.DockerTLS,
+
+		KeepAliveToken:   config.KeepAliveToken,
+		KeepAliveOverSSH: config.KeepAliveOverSSH,
+		UploadServer:     config.UploadServer,
 	}
 
 	if volume != nil {
-		cloudInitConfig.VolumeID = fmt.Sprintf("%d", volume.ID)
+		cloudInitConfig.VolumeID = volume.ID
 	}
 
-	userDataScript := GenerateCloudInitScript(cloudInitConfig)
+	userDataScript, err := GenerateCloudInitScript(cloudInitConfig)
+	if err != nil {
+		if sshKey != nil {
+			lm.cleanupSSHKey(ctx, sshKey.ID)
+		}
+		return nil, errors.Wrap(err, "failed to generate cloud-init")
+	}
 
 	// Prepare server configuration
 	serverConfig := &ServerConfig{
@@ -67,6 +99,7 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 		ServerType: config.ServerType,
 		Location:   config.Location,
 		UserData:   userDataScript,
+		Volumes:    attachments,
 	}
 
 	if sshKey != nil {
@@ -74,16 +107,13 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 	}
 
 	if volume != nil {
-		serverConfig.VolumeID = fmt.Sprintf("%d", volume.ID)
+		serverConfig.VolumeID = volume.ID
 	}
 
 	// Create server
 	server, err = lm.client.ProvisionServer(ctx, serverConfig)
 	if err != nil {
-		// Cleanup resources if server creation fails
-		if volume != nil {
-			lm.cleanupVolume(ctx, volume.ID)
-		}
+		// Cleanup resources if server creation fails; volumes are kept
 		if sshKey != nil {
 			lm.cleanupSSHKey(ctx, sshKey.ID)
 		}
@@ -93,11 +123,8 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 	// Wait for server to be fully ready
 	err = lm.waitForServerReady(ctx, server.ID, 5*time.Minute)
 	if err != nil {
-		// Cleanup all resources if server doesn't become ready
+		// Cleanup all resources if server doesn't become ready; volumes are kept
 		lm.cleanupServer(ctx, server.ID)
-		if volume != nil {
-			lm.cleanupVolume(ctx, volume.ID)
-		}
 		if sshKey != nil {
 			lm.cleanupSSHKey(ctx, sshKey.ID)
 		}
@@ -105,9 +132,10 @@ func (lm *LifecycleManager) ProvisionServerWithVolume(ctx context.Context, confi
 	}
 
 	return &ServerWithVolume{
-		Server: server,
-		Volume: volume,
-		SSHKey: sshKey,
+		Server:  server,
+		Volume:  volume,
+		Volumes: volumes,
+		SSHKey:  sshKey,
 	}, nil
 }
 
@@ -119,12 +147,13 @@ func (lm *LifecycleManager) DestroyServerWithCleanup(ctx context.Context, server
 		return errors.Wrap(err, "failed to get server details")
 	}
 
-	// Detach volume if present and we want to preserve it
-	if server.VolumeID != "" && preserveVolume {
-		err = lm.client.DetachVolume(ctx, server.VolumeID)
-		if err != nil {
-			// Log error but continue with server destruction
-			fmt.Printf("Warning: failed to detach volume %s: %v\n", server.VolumeID, err)
+	// Detach volumes if present and we want to preserve them
+	if preserveVolume {
+		for _, volumeID := range server.AttachedVolumes() {
+			if err := lm.client.DetachVolume(ctx, volumeID); err != nil {
+				// Log error but continue with server destruction
+				fmt.Printf("Warning: failed to detach volume %s: %v\n", volumeID, err)
+			}
 		}
 	}
 
@@ -134,12 +163,13 @@ func (lm *LifecycleManager) DestroyServerWithCleanup(ctx context.Context, server
 		return errors.Wrap(err, "failed to destroy server")
 	}
 
-	// Clean up volume if not preserving
-	if server.VolumeID != "" && !preserveVolume {
-		err = lm.cleanupVolume(ctx, parseVolumeID(server.VolumeID))
-		if err != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Warning: failed to cleanup volume %s: %v\n", server.VolumeID, err)
+	// Clean up volumes if not preserving
+	if !preserveVolume {
+		for _, volumeID := range server.AttachedVolumes() {
+			if err := lm.cleanupVolume(ctx, volumeID); err != nil {
+				// Log error but don't fail the operation
+				fmt.Printf("Warning: failed to cleanup volume %s: %v\n", volumeID, err)
+			}
 		}
 	}
 
@@ -179,7 +209,7 @@ func (lm *LifecycleManager) cleanupServer(ctx context.Context, serverID int64) {
 }
 
 // cleanupVolume removes a volume without error handling
-func (lm *LifecycleManager) cleanupVolume(ctx context.Context, volumeID int64) error {
+func (lm *LifecycleManager) cleanupVolume(ctx context.Context, volumeID string) error {
 	// Note: Hetzner doesn't have a direct volume delete in the interface we defined
 	// This would need to be implemented in the client if needed
 	return nil
@@ -201,13 +231,25 @@ type ServerProvisionConfig struct {
 	SSHPublicKey  string
 	KeepAlivePort int
 	DockerAPIPort int
+	// DockerTLS enables mutual TLS on the Docker API (optional)
+	DockerTLS *dockertls.Bundle
+	// KeepAliveToken is the secret heartbeats must be signed with (optional)
+	KeepAliveToken string
+	// KeepAliveOverSSH keeps the keep-alive port closed; heartbeats arrive through SSH
+	KeepAliveOverSSH bool
+	// UploadServer leaves dockbridge-server to the client, which uploads it over SSH
+	UploadServer bool
+	// Volumes are named volumes mounted in addition to the Docker data volume (optional)
+	Volumes []sharedconfig.VolumeConfig
 }
 
 // ServerWithVolume represents a server with its associated resources
 type ServerWithVolume struct {
 	Server *Server
 	Volume *Volume
-	SSHKey *SSHKey
+	// Volumes are the additional named volumes, in configuration order
+	Volumes []*Volume
+	SSHKey  *SSHKey
 }
 
 // GetDefaultProvisionConfig returns a default server provision configuration
@@ -182,7 +182,7 @@ func TestServerWithVolume(t *testing.T) {
 	}
 
 	volume := &Volume{
-		ID:       67890,
+		ID:       "67890",
 		Name:     "test-volume",
 		Size:     10,
 		Location: "fsn1",
@@ -0,0 +1,99 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+)
+
+// shutdownTimeout bounds how long a graceful shutdown may take before the server is powered off
+const shutdownTimeout = 2 * time.Minute
+
+// PowerOffServer shuts a server down via ACPI and powers it off if it does not stop in time
+func (c *Client) PowerOffServer(ctx context.Context, serverID string) error {
+	server, err := c.getServerByID(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	if server.Status == hcloud.ServerStatusOff {
+		return nil
+	}
+
+	action, _, err := c.hcloud.Server.Shutdown(ctx, server)
+	if err != nil {
+		return errors.Wrap(err, "failed to shut down server")
+	}
+	if err := c.hcloud.Action.WaitFor(ctx, action); err != nil {
+		return errors.Wrap(err, "failed to wait for server shutdown")
+	}
+
+	// The shutdown action completes once the ACPI request is sent; wait for the server to stop
+	deadline := time.Now().Add(shutdownTimeout)
+	for time.Now().Before(deadline) {
+		server, err = c.getServerByID(ctx, serverID)
+		if err != nil {
+			return err
+		}
+		if server.Status == hcloud.ServerStatusOff {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "failed to wait for server shutdown")
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	action, _, err = c.hcloud.Server.Poweroff(ctx, server)
+	if err != nil {
+		return errors.Wrap(err, "failed to power off server")
+	}
+	if err := c.hcloud.Action.WaitFor(ctx, action); err != nil {
+		return errors.Wrap(err, "failed to wait for server power off")
+	}
+	return nil
+}
+
+// PowerOnServer starts a stopped server and returns it once it is running
+func (c *Client) PowerOnServer(ctx context.Context, serverID string) (*Server, error) {
+	server, err := c.getServerByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if server.Status != hcloud.ServerStatusRunning {
+		action, _, err := c.hcloud.Server.Poweron(ctx, server)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to power on server")
+		}
+		if err := c.hcloud.Action.WaitFor(ctx, action); err != nil {
+			return nil, errors.Wrap(err, "failed to wait for server power on")
+		}
+
+		if server, err = c.getServerByID(ctx, serverID); err != nil {
+			return nil, err
+		}
+	}
+
+	return convertServer(server), nil
+}
+
+// getServerByID fetches a server and fails if it does not exist
+func (c *Client) getServerByID(ctx context.Context, serverID string) (*hcloud.Server, error) {
+	server, _, err := c.hcloud.Server.GetByID(ctx, parseServerID(serverID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get server")
+	}
+	if server == nil {
+		return nil, fmt.Errorf("server %s not found", serverID)
+	}
+	return server, nil
+}
+
+// Ensure Client can power servers off and on
+var _ provider.PowerController = (*Client)(nil)
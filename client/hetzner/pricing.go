@@ -0,0 +1,43 @@
+package hetzner
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/pkg/errors"
+)
+
+// ServerPricing returns the net prices of serverType in location from the pricing API
+func (c *Client) ServerPricing(ctx context.Context, serverType, location string) (*provider.Pricing, error) {
+	pricing, _, err := c.hcloud.Pricing.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pricing")
+	}
+
+	volumeGBMonthly, err := strconv.ParseFloat(pricing.Volume.PerGBMonthly.Net, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse volume price")
+	}
+
+	for _, typePricing := range pricing.ServerTypes {
+		if typePricing.ServerType == nil || typePricing.ServerType.Name != serverType {
+			continue
+		}
+		for _, locationPricing := range typePricing.Pricings {
+			if locationPricing.Location == nil || locationPricing.Location.Name != location {
+				continue
+			}
+			hourly, err := strconv.ParseFloat(locationPricing.Hourly.Net, 64)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse server price")
+			}
+			return &provider.Pricing{
+				Currency:        locationPricing.Hourly.Currency,
+				ServerHourly:    hourly,
+				VolumeGBMonthly: volumeGBMonthly,
+			}, nil
+		}
+	}
+	return nil, errors.Errorf("no price for server type %s in %s", serverType, location)
+}
@@ -0,0 +1,144 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+)
+
+// anywhere are the source ranges of firewall rules without configured source IPs
+var anywhere = []string{"0.0.0.0/0", "::/0"}
+
+// applyProjectOptions attaches a new server to the private network, firewall and
+// placement group of config, so that it lives inside existing Hetzner infrastructure,
+// and leaves out the public addresses it should not have
+func (c *Client) applyProjectOptions(ctx context.Context, config *ServerConfig, opts *hcloud.ServerCreateOpts) error {
+	if config.Network != "" {
+		network, _, err := c.hcloud.Network.Get(ctx, config.Network)
+		if err != nil {
+			return errors.Wrap(err, "failed to get network")
+		}
+		if network == nil {
+			return fmt.Errorf("network %s not found", config.Network)
+		}
+		opts.Networks = []*hcloud.Network{network}
+	}
+
+	if config.Firewall != nil {
+		firewall, err := c.ensureFirewall(ctx, config.Firewall)
+		if err != nil {
+			return err
+		}
+		opts.Firewalls = []*hcloud.ServerCreateFirewall{{Firewall: *firewall}}
+	}
+
+	switch config.PublicNetwork {
+	case provider.PublicNetworkIPv6:
+		opts.PublicNet = &hcloud.ServerCreatePublicNet{EnableIPv6: true}
+	case provider.PublicNetworkNone:
+		if len(opts.Networks) == 0 {
+			return errors.New("servers without public addresses need a network")
+		}
+		opts.PublicNet = &hcloud.ServerCreatePublicNet{}
+	}
+
+	if config.PlacementGroup != "" {
+		group, err := c.ensurePlacementGroup(ctx, config.PlacementGroup)
+		if err != nil {
+			return err
+		}
+		opts.PlacementGroup = group
+	}
+	return nil
+}
+
+// ensureFirewall returns the firewall of the given name, creating it with inbound
+// rules for its ports if it does not exist. Existing firewalls are not changed.
+func (c *Client) ensureFirewall(ctx context.Context, firewall *provider.Firewall) (*hcloud.Firewall, error) {
+	existing, _, err := c.hcloud.Firewall.Get(ctx, firewall.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get firewall")
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	rules, err := firewallRules(firewall)
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := c.hcloud.Firewall.Create(ctx, hcloud.FirewallCreateOpts{
+		Name:   firewall.Name,
+		Labels: c.ownerLabels(),
+		Rules:  rules,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create firewall")
+	}
+	if len(result.Actions) > 0 {
+		if err := c.hcloud.Action.WaitFor(ctx, result.Actions...); err != nil {
+			return nil, errors.Wrap(err, "failed to wait for firewall creation")
+		}
+	}
+	return result.Firewall, nil
+}
+
+// firewallRules returns the inbound TCP rules of a created firewall
+func firewallRules(firewall *provider.Firewall) ([]hcloud.FirewallRule, error) {
+	sources := firewall.SourceIPs
+	if len(sources) == 0 {
+		sources = anywhere
+	}
+	sourceIPs := make([]net.IPNet, 0, len(sources))
+	for _, source := range sources {
+		_, ipNet, err := net.ParseCIDR(source)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid firewall source %s", source)
+		}
+		sourceIPs = append(sourceIPs, *ipNet)
+	}
+
+	rules := make([]hcloud.FirewallRule, 0, len(firewall.Ports))
+	for _, port := range firewall.Ports {
+		rules = append(rules, hcloud.FirewallRule{
+			Direction:   hcloud.FirewallRuleDirectionIn,
+			Protocol:    hcloud.FirewallRuleProtocolTCP,
+			Port:        hcloud.Ptr(strconv.Itoa(port)),
+			SourceIPs:   sourceIPs,
+			Description: hcloud.Ptr("DockBridge"),
+		})
+	}
+	return rules, nil
+}
+
+// ensurePlacementGroup returns the placement group of the given name, creating a
+// spread group if it does not exist
+func (c *Client) ensurePlacementGroup(ctx context.Context, name string) (*hcloud.PlacementGroup, error) {
+	group, _, err := c.hcloud.PlacementGroup.Get(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get placement group")
+	}
+	if group != nil {
+		return group, nil
+	}
+
+	result, _, err := c.hcloud.PlacementGroup.Create(ctx, hcloud.PlacementGroupCreateOpts{
+		Name:   name,
+		Labels: c.ownerLabels(),
+		Type:   hcloud.PlacementGroupTypeSpread,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create placement group")
+	}
+	if result.Action != nil {
+		if err := c.hcloud.Action.WaitFor(ctx, result.Action); err != nil {
+			return nil, errors.Wrap(err, "failed to wait for placement group creation")
+		}
+	}
+	return result.PlacementGroup, nil
+}
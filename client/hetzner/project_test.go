@@ -0,0 +1,93 @@
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProjectOptions(t *testing.T) {
+	var created map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /networks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "office", r.URL.Query().Get("name"))
+		_, _ = w.Write([]byte(`{"networks":[{"id":7,"name":"office"}]}`))
+	})
+	mux.HandleFunc("GET /firewalls", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"firewalls":[]}`))
+	})
+	mux.HandleFunc("POST /firewalls", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"firewall":{"id":9,"name":"dockbridge"},"actions":[]}`))
+	})
+	mux.HandleFunc("GET /placement_groups", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"placement_groups":[{"id":11,"name":"builders","type":"spread"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &Client{
+		hcloud: hcloud.NewClient(hcloud.WithToken("token"), hcloud.WithEndpoint(server.URL)),
+		config: &Config{Owner: provider.Owner{ID: "me"}},
+	}
+
+	var opts hcloud.ServerCreateOpts
+	err := client.applyProjectOptions(context.Background(), &ServerConfig{
+		Network:        "office",
+		Firewall:       &provider.Firewall{Name: "dockbridge", Ports: []int{22, 8080}, SourceIPs: []string{"203.0.113.0/24"}},
+		PlacementGroup: "builders",
+		PublicNetwork:  provider.PublicNetworkIPv6,
+	}, &opts)
+	require.NoError(t, err)
+
+	require.NotNil(t, opts.PublicNet)
+	assert.False(t, opts.PublicNet.EnableIPv4)
+	assert.True(t, opts.PublicNet.EnableIPv6)
+
+	require.Len(t, opts.Networks, 1)
+	assert.Equal(t, int64(7), opts.Networks[0].ID)
+	require.Len(t, opts.Firewalls, 1)
+	assert.Equal(t, int64(9), opts.Firewalls[0].Firewall.ID)
+	require.NotNil(t, opts.PlacementGroup)
+	assert.Equal(t, int64(11), opts.PlacementGroup.ID)
+
+	// The missing firewall is created with a rule per port, labeled with the owner
+	assert.Equal(t, "dockbridge", created["name"])
+	assert.Len(t, created["rules"], 2)
+	assert.Equal(t, "me", created["labels"].(map[string]any)[provider.LabelOwner])
+}
+
+func TestApplyProjectOptionsMissingNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"networks":[]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{hcloud: hcloud.NewClient(hcloud.WithToken("token"), hcloud.WithEndpoint(server.URL)), config: &Config{}}
+	err := client.applyProjectOptions(context.Background(), &ServerConfig{Network: "office"}, &hcloud.ServerCreateOpts{})
+	assert.ErrorContains(t, err, "network office not found")
+
+	// Without public addresses a network is required
+	err = client.applyProjectOptions(context.Background(), &ServerConfig{PublicNetwork: provider.PublicNetworkNone}, &hcloud.ServerCreateOpts{})
+	assert.ErrorContains(t, err, "need a network")
+}
+
+func TestFirewallRules(t *testing.T) {
+	rules, err := firewallRules(&provider.Firewall{Name: "dockbridge", Ports: []int{22}})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "22", *rules[0].Port)
+	assert.Equal(t, hcloud.FirewallRuleDirectionIn, rules[0].Direction)
+	assert.Len(t, rules[0].SourceIPs, 2, "rules without source IPs allow anywhere")
+
+	_, err = firewallRules(&provider.Firewall{Name: "dockbridge", Ports: []int{22}, SourceIPs: []string{"nope"}})
+	assert.Error(t, err)
+}
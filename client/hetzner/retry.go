@@ -0,0 +1,224 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+const (
+	// maxRetries is how many times a failed API request is retried
+	maxRetries = 5
+
+	// retryBaseDelay is the backoff before the first retry; it doubles with every retry
+	retryBaseDelay = time.Second
+
+	// retryMaxDelay caps the backoff between retries
+	retryMaxDelay = 30 * time.Second
+
+	// maxRateLimitWait is the longest a request waits for the rate limit to reset;
+	// beyond it, the request fails with a RateLimitError instead
+	maxRateLimitWait = time.Minute
+)
+
+// RateLimitError is returned when the Hetzner API keeps refusing requests because the
+// project's rate limit is exceeded
+type RateLimitError struct {
+	// Limit is the number of requests allowed per hour, 0 if unknown
+	Limit int
+
+	// Reset is when the limit is fully replenished, zero if unknown
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "Hetzner API rate limit exceeded"
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(" (%d requests per hour)", e.Limit)
+	}
+	if !e.Reset.IsZero() {
+		msg += fmt.Sprintf(", resets at %s", e.Reset.Local().Format("15:04:05"))
+	}
+	return msg
+}
+
+// newHCloudClient creates an hcloud client whose requests are retried by retryTransport.
+// The retries of hcloud itself are disabled so that failures are not retried twice.
+func newHCloudClient(apiToken string, options ...hcloud.ClientOption) *hcloud.Client {
+	options = append([]hcloud.ClientOption{
+		hcloud.WithToken(apiToken),
+		hcloud.WithHTTPClient(&http.Client{Transport: &retryTransport{next: http.DefaultTransport}}),
+		hcloud.WithRetryOpts(hcloud.RetryOpts{MaxRetries: 0}),
+	}, options...)
+	return hcloud.NewClient(options...)
+}
+
+// retryTransport retries API requests failing with 429, 5xx or network errors, with
+// jittered exponential backoff. Rate limited requests wait as long as the Retry-After and
+// RateLimit-Reset headers ask, and fail with a RateLimitError if that is too long or the
+// retries run out. Requests that may have been carried out, such as a POST whose
+// connection broke, are not retried.
+type retryTransport struct {
+	next http.RoundTripper
+
+	// sleep waits for d unless ctx is done (replaceable in tests)
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// now returns the current time (replaceable in tests)
+	now func() time.Time
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		var rateLimitErr *RateLimitError
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			rateLimitErr = rateLimit(resp.Header)
+			if wait, ok := t.rateLimitWait(resp.Header); ok {
+				delay = wait
+			}
+		}
+
+		if attempt >= maxRetries || delay > maxRateLimitWait || req.Body != nil && req.GetBody == nil {
+			if rateLimitErr != nil {
+				drain(resp)
+				return nil, rateLimitErr
+			}
+			return resp, err
+		}
+
+		drain(resp)
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimitWait returns how long the headers of a 429 response ask to wait
+func (t *retryTransport) rateLimitWait(header http.Header) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	// Hetzner replenishes one request per second, so the reset time only bounds the
+	// wait when it is near
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	if reset := rateLimit(header).Reset; !reset.IsZero() {
+		if wait := reset.Sub(now()); wait < backoff(0) {
+			return max(wait, 0), true
+		}
+	}
+	return 0, false
+}
+
+func (t *retryTransport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable reports whether a request that returned resp or err should be retried
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// The request may have reached the API before the connection broke
+		var netErr net.Error
+		return idempotent(req.Method) && (errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// The API refused the request without carrying it out
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether repeating a request with method has no further effect
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the delay before retry attempt+1: exponential from retryBaseDelay up
+// to retryMaxDelay, with jitter so that concurrent clients do not retry in lockstep
+func backoff(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 16 {
+		delay = min(retryBaseDelay<<attempt, retryMaxDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// rateLimit reads the rate limit headers of a response
+func rateLimit(header http.Header) *RateLimitError {
+	err := &RateLimitError{}
+	err.Limit, _ = strconv.Atoi(header.Get("RateLimit-Limit"))
+	if reset, parseErr := strconv.ParseInt(header.Get("RateLimit-Reset"), 10, 64); parseErr == nil {
+		err.Reset = time.Unix(reset, 0)
+	}
+	return err
+}
+
+// rewind returns req for the given attempt, with a fresh body for retries
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// drain discards and closes the body of a response that is not returned, so that its
+// connection can be reused
+func drain(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
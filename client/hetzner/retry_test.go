@@ -0,0 +1,147 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client of the API served by handler, which records the
+// delays it would have waited in delays
+func newTestClient(t *testing.T, handler http.HandlerFunc, delays *[]time.Duration) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	transport := &retryTransport{
+		next: http.DefaultTransport,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return nil
+		},
+	}
+	return &Client{hcloud: newHCloudClient("test-token",
+		hcloud.WithEndpoint(server.URL),
+		hcloud.WithHTTPClient(&http.Client{Transport: transport}),
+	)}
+}
+
+const emptyServerList = `{"servers": [], "meta": {"pagination": {"page": 1, "per_page": 50, "last_page": 1, "total_entries": 0}}}`
+
+func TestRetryRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	var delays []time.Duration
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error": {"code": "rate_limit_exceeded", "message": "limit reached"}}`)
+			return
+		}
+		fmt.Fprint(w, emptyServerList)
+	}, &delays)
+
+	servers, err := client.ListServers(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []time.Duration{2 * time.Second}, delays)
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	var delays []time.Duration
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, emptyServerList)
+	}, &delays)
+
+	_, err := client.ListServers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, delays, 2)
+	assert.GreaterOrEqual(t, delays[1], retryBaseDelay)
+}
+
+func TestRetryRateLimitExhausted(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var calls atomic.Int32
+	var delays []time.Duration
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("RateLimit-Limit", "3600")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, &delays)
+
+	_, err := client.ListServers(context.Background())
+	var rateLimitErr *RateLimitError
+	require.True(t, errors.As(err, &rateLimitErr), "got %v", err)
+	assert.Equal(t, 3600, rateLimitErr.Limit)
+	assert.True(t, reset.Equal(rateLimitErr.Reset))
+	assert.Equal(t, int32(maxRetries+1), calls.Load())
+	assert.Contains(t, err.Error(), "Hetzner API rate limit exceeded (3600 requests per hour)")
+}
+
+func TestRetryRateLimitWaitTooLong(t *testing.T) {
+	var calls atomic.Int32
+	var delays []time.Duration
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, &delays)
+
+	_, err := client.ListServers(context.Background())
+	var rateLimitErr *RateLimitError
+	assert.True(t, errors.As(err, &rateLimitErr), "got %v", err)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Empty(t, delays)
+}
+
+func TestRetryableRequests(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		want   bool
+	}{
+		{http.MethodGet, http.StatusTooManyRequests, true},
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodGet, http.StatusInternalServerError, true},
+		{http.MethodPost, http.StatusInternalServerError, false},
+		{http.MethodPost, http.StatusServiceUnavailable, true},
+		{http.MethodDelete, http.StatusGatewayTimeout, true},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodGet, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.method, tt.status), func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/servers", strings.NewReader(""))
+			assert.Equal(t, tt.want, retryable(req, &http.Response{StatusCode: tt.status}, nil))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 20; attempt++ {
+		delay := backoff(attempt)
+		ceiling := min(retryBaseDelay<<min(attempt, 16), retryMaxDelay)
+		assert.GreaterOrEqual(t, delay, ceiling/2)
+		assert.LessOrEqual(t, delay, ceiling)
+	}
+}
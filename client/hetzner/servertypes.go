@@ -0,0 +1,208 @@
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Server architectures
+const (
+	ArchitectureX86 = string(hcloud.ArchitectureX86)
+	ArchitectureARM = string(hcloud.ArchitectureARM)
+)
+
+// ServerTypeCacheTTL is how long server types fetched from the API are trusted
+const ServerTypeCacheTTL = 24 * time.Hour
+
+// ServerTypeInfo describes a Hetzner server type
+type ServerTypeInfo struct {
+	Name         string  `json:"name"`
+	Architecture string  `json:"architecture"`
+	Cores        int     `json:"cores"`
+	MemoryGB     float32 `json:"memory_gb"`
+	Deprecated   bool    `json:"deprecated,omitempty"`
+}
+
+// builtinServerTypes is the offline fallback used until the API has been queried:
+// shared x86 (CX, CPX), shared ARM (CAX) and dedicated vCPU (CCX) lines
+var builtinServerTypes = []ServerTypeInfo{
+	{Name: "cx11", Architecture: ArchitectureX86, Cores: 1, MemoryGB: 2, Deprecated: true},
+	{Name: "cx21", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 4, Deprecated: true},
+	{Name: "cx31", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 8, Deprecated: true},
+	{Name: "cx41", Architecture: ArchitectureX86, Cores: 4, MemoryGB: 16, Deprecated: true},
+	{Name: "cx51", Architecture: ArchitectureX86, Cores: 8, MemoryGB: 32, Deprecated: true},
+	{Name: "cx22", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 4},
+	{Name: "cx23", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 4},
+	{Name: "cx32", Architecture: ArchitectureX86, Cores: 4, MemoryGB: 8},
+	{Name: "cx42", Architecture: ArchitectureX86, Cores: 8, MemoryGB: 16},
+	{Name: "cx52", Architecture: ArchitectureX86, Cores: 16, MemoryGB: 32},
+	{Name: "cpx11", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 2},
+	{Name: "cpx21", Architecture: ArchitectureX86, Cores: 3, MemoryGB: 4},
+	{Name: "cpx31", Architecture: ArchitectureX86, Cores: 4, MemoryGB: 8},
+	{Name: "cpx41", Architecture: ArchitectureX86, Cores: 8, MemoryGB: 16},
+	{Name: "cpx51", Architecture: ArchitectureX86, Cores: 16, MemoryGB: 32},
+	{Name: "cax11", Architecture: ArchitectureARM, Cores: 2, MemoryGB: 4},
+	{Name: "cax21", Architecture: ArchitectureARM, Cores: 4, MemoryGB: 8},
+	{Name: "cax31", Architecture: ArchitectureARM, Cores: 8, MemoryGB: 16},
+	{Name: "cax41", Architecture: ArchitectureARM, Cores: 16, MemoryGB: 32},
+	{Name: "ccx13", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 8},
+	{Name: "ccx23", Architecture: ArchitectureX86, Cores: 4, MemoryGB: 16},
+	{Name: "ccx33", Architecture: ArchitectureX86, Cores: 8, MemoryGB: 32},
+	{Name: "ccx43", Architecture: ArchitectureX86, Cores: 16, MemoryGB: 64},
+	{Name: "ccx53", Architecture: ArchitectureX86, Cores: 32, MemoryGB: 128},
+	{Name: "ccx63", Architecture: ArchitectureX86, Cores: 48, MemoryGB: 192},
+}
+
+// DefaultServerTypeCachePath returns where server types fetched from the API are cached
+func DefaultServerTypeCachePath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".dockbridge", "cache", "hetzner-server-types.json")
+}
+
+// serverTypeCache is the on-disk cache document
+type serverTypeCache struct {
+	FetchedAt   time.Time        `json:"fetched_at"`
+	ServerTypes []ServerTypeInfo `json:"server_types"`
+}
+
+// ServerTypeCatalog answers which server types exist, preferring the Hetzner API,
+// then a local cache of an earlier API response, then a built-in list
+type ServerTypeCatalog struct {
+	cachePath string
+	fetch     func(ctx context.Context) ([]ServerTypeInfo, error)
+}
+
+// NewServerTypeCatalog creates a catalog caching at cachePath ("" disables the cache).
+// fetch queries the API; nil makes the catalog offline-only.
+func NewServerTypeCatalog(cachePath string, fetch func(ctx context.Context) ([]ServerTypeInfo, error)) *ServerTypeCatalog {
+	return &ServerTypeCatalog{cachePath: cachePath, fetch: fetch}
+}
+
+// NewAPIServerTypeCatalog creates a catalog backed by the API using apiToken
+func NewAPIServerTypeCatalog(cachePath, apiToken string) *ServerTypeCatalog {
+	if apiToken == "" {
+		return NewServerTypeCatalog(cachePath, nil)
+	}
+	client := &Client{hcloud: newHCloudClient(apiToken)}
+	return NewServerTypeCatalog(cachePath, client.ServerTypes)
+}
+
+// Offline returns the cached server types, or the built-in list when nothing is cached
+func (c *ServerTypeCatalog) Offline() []ServerTypeInfo {
+	if cache, ok := c.readCache(); ok {
+		return cache.ServerTypes
+	}
+	return builtinServerTypes
+}
+
+// Refresh fetches the server types from the API and updates the cache
+func (c *ServerTypeCatalog) Refresh(ctx context.Context) ([]ServerTypeInfo, error) {
+	if c.fetch == nil {
+		return nil, fmt.Errorf("no Hetzner API token configured")
+	}
+
+	types, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.writeCache(serverTypeCache{FetchedAt: time.Now(), ServerTypes: types})
+	return types, nil
+}
+
+// Lookup finds a server type. Unknown names are checked against the API (when the
+// cache is stale or missing) so newly launched types are accepted without a release.
+func (c *ServerTypeCatalog) Lookup(ctx context.Context, name string) (ServerTypeInfo, bool) {
+	if info, ok := findServerType(c.Offline(), name); ok {
+		return info, true
+	}
+
+	if cache, ok := c.readCache(); ok && time.Since(cache.FetchedAt) < ServerTypeCacheTTL {
+		return ServerTypeInfo{}, false
+	}
+	types, err := c.Refresh(ctx)
+	if err != nil {
+		return ServerTypeInfo{}, false
+	}
+	return findServerType(types, name)
+}
+
+// ServerTypeNames returns the names of types, sorted
+func ServerTypeNames(types []ServerTypeInfo) []string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServerTypes lists the server types offered by the API
+func (c *Client) ServerTypes(ctx context.Context) ([]ServerTypeInfo, error) {
+	serverTypes, err := c.hcloud.ServerType.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server types: %w", err)
+	}
+
+	types := make([]ServerTypeInfo, 0, len(serverTypes))
+	for _, st := range serverTypes {
+		types = append(types, ServerTypeInfo{
+			Name:         st.Name,
+			Architecture: string(st.Architecture),
+			Cores:        st.Cores,
+			MemoryGB:     st.Memory,
+			Deprecated:   st.IsDeprecated(),
+		})
+	}
+	return types, nil
+}
+
+// findServerType returns the type called name
+func findServerType(types []ServerTypeInfo, name string) (ServerTypeInfo, bool) {
+	for _, t := range types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return ServerTypeInfo{}, false
+}
+
+// readCache loads the cache file
+func (c *ServerTypeCatalog) readCache() (serverTypeCache, bool) {
+	var cache serverTypeCache
+	if c.cachePath == "" {
+		return cache, false
+	}
+	data, err := os.ReadFile(c.cachePath)
+	if err != nil {
+		return cache, false
+	}
+	if err := json.Unmarshal(data, &cache); err != nil || len(cache.ServerTypes) == 0 {
+		return cache, false
+	}
+	return cache, true
+}
+
+// writeCache stores the cache file; failures only cost a later API call
+func (c *ServerTypeCatalog) writeCache(cache serverTypeCache) {
+	if c.cachePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.cachePath), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath, data, 0600)
+}
@@ -0,0 +1,69 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerTypeCatalogOffline(t *testing.T) {
+	catalog := NewServerTypeCatalog("", nil)
+
+	arm, ok := catalog.Lookup(context.Background(), "cax21")
+	require.True(t, ok)
+	assert.Equal(t, ArchitectureARM, arm.Architecture)
+
+	dedicated, ok := catalog.Lookup(context.Background(), "ccx13")
+	require.True(t, ok)
+	assert.Equal(t, ArchitectureX86, dedicated.Architecture)
+
+	_, ok = catalog.Lookup(context.Background(), "cax99")
+	assert.False(t, ok)
+}
+
+func TestServerTypeCatalogRefresh(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "server-types.json")
+	calls := 0
+	catalog := NewServerTypeCatalog(cachePath, func(ctx context.Context) ([]ServerTypeInfo, error) {
+		calls++
+		return []ServerTypeInfo{
+			{Name: "cpx22", Architecture: ArchitectureX86, Cores: 2, MemoryGB: 4},
+			{Name: "cax11", Architecture: ArchitectureARM, Cores: 2, MemoryGB: 4},
+		}, nil
+	})
+
+	// A type missing from the built-in list is looked up in the API and cached
+	info, ok := catalog.Lookup(context.Background(), "cpx22")
+	require.True(t, ok)
+	assert.Equal(t, 2, info.Cores)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"cax11", "cpx22"}, ServerTypeNames(catalog.Offline()))
+
+	// A fresh cache answers without another API call, also for unknown types
+	_, ok = catalog.Lookup(context.Background(), "cpx22")
+	assert.True(t, ok)
+	_, ok = catalog.Lookup(context.Background(), "unknown")
+	assert.False(t, ok)
+	assert.Equal(t, 1, calls)
+
+	// A new catalog reads the cache written by the first
+	offline := NewServerTypeCatalog(cachePath, nil)
+	_, ok = offline.Lookup(context.Background(), "cpx22")
+	assert.True(t, ok)
+}
+
+func TestServerTypeCatalogAPIFailure(t *testing.T) {
+	catalog := NewServerTypeCatalog(filepath.Join(t.TempDir(), "server-types.json"), func(ctx context.Context) ([]ServerTypeInfo, error) {
+		return nil, errors.New("offline")
+	})
+
+	// Built-in types stay usable when the API cannot be reached
+	_, ok := catalog.Lookup(context.Background(), "cpx21")
+	assert.True(t, ok)
+	_, ok = catalog.Lookup(context.Background(), "cpx22")
+	assert.False(t, ok)
+}
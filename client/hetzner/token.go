@@ -0,0 +1,30 @@
+package hetzner
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/pkg/errors"
+)
+
+// CheckWriteAccess reports whether the API token may create resources. It requests
+// an SSH key without a name, which the API refuses as forbidden for read-only tokens
+// and as invalid input otherwise, so nothing is created.
+func (c *Client) CheckWriteAccess(ctx context.Context) (bool, error) {
+	req, err := c.hcloud.NewRequest(ctx, http.MethodPost, "/ssh_keys", strings.NewReader("{}"))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create request")
+	}
+
+	_, err = c.hcloud.Do(req, nil)
+	switch {
+	case err == nil, hcloud.IsError(err, hcloud.ErrorCodeInvalidInput):
+		return true, nil
+	case hcloud.IsError(err, hcloud.ErrorCodeForbidden):
+		return false, nil
+	default:
+		return false, errors.Wrap(err, "failed to check token permissions")
+	}
+}
@@ -0,0 +1,47 @@
+package hetzner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWriteAccess(t *testing.T) {
+	tests := map[string]struct {
+		code     string
+		status   int
+		writable bool
+		wantErr  bool
+	}{
+		"read and write": {code: "invalid_input", status: http.StatusUnprocessableEntity, writable: true},
+		"read only":      {code: "forbidden", status: http.StatusForbidden},
+		"invalid token":  {code: "unauthorized", status: http.StatusUnauthorized, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/ssh_keys", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error":{"code":"` + tt.code + `","message":"refused"}}`))
+			}))
+			defer server.Close()
+
+			client := &Client{hcloud: hcloud.NewClient(hcloud.WithToken("token"), hcloud.WithEndpoint(server.URL))}
+			writable, err := client.CheckWriteAccess(context.Background())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.writable, writable)
+		})
+	}
+}
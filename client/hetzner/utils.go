@@ -1,6 +1,7 @@
 package hetzner
 
 import (
+	"net"
 	"strconv"
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
@@ -12,14 +13,37 @@ func convertServer(server *hcloud.Server) *Server {
 		return nil
 	}
 
+	var ipv6Address, privateIPAddress string
+	if !server.PublicNet.IPv6.IsUnspecified() {
+		ipv6Address = ipv6HostAddress(server.PublicNet.IPv6.IP).String()
+	}
+	if len(server.PrivateNet) > 0 && server.PrivateNet[0].IP != nil {
+		privateIPAddress = server.PrivateNet[0].IP.String()
+	}
+
+	// Servers without a public IPv4 address are reached over IPv6 or their network
 	var ipAddress string
-	if server.PublicNet.IPv4.IP != nil {
+	switch {
+	case !server.PublicNet.IPv4.IsUnspecified():
 		ipAddress = server.PublicNet.IPv4.IP.String()
+	case ipv6Address != "":
+		ipAddress = ipv6Address
+	default:
+		ipAddress = privateIPAddress
+	}
+
+	var serverType string
+	if server.ServerType != nil {
+		serverType = server.ServerType.Name
 	}
 
 	var volumeID string
-	if len(server.Volumes) > 0 {
-		volumeID = strconv.FormatInt(server.Volumes[0].ID, 10)
+	volumeIDs := make([]string, 0, len(server.Volumes))
+	for _, volume := range server.Volumes {
+		volumeIDs = append(volumeIDs, strconv.FormatInt(volume.ID, 10))
+	}
+	if len(volumeIDs) > 0 {
+		volumeID = volumeIDs[0]
 	}
 
 	return &Server{
@@ -28,19 +52,51 @@ func convertServer(server *hcloud.Server) *Server {
 		Status:    string(server.Status),
 		IPAddress: ipAddress,
 		VolumeID:  volumeID,
+		VolumeIDs: volumeIDs,
 		CreatedAt: server.Created,
+
+		IPv6Address:      ipv6Address,
+		PrivateIPAddress: privateIPAddress,
+
+		ServerType: serverType,
+
+		IncludedTraffic: server.IncludedTraffic,
+		OutgoingTraffic: server.OutgoingTraffic,
+		IngoingTraffic:  server.IngoingTraffic,
+
+		Labels: server.Labels,
 	}
 }
 
+// ipv6HostAddress returns the address of a server in its public IPv6 network: Hetzner
+// reports the /64 network, and servers listen on its first address (::1)
+func ipv6HostAddress(network net.IP) net.IP {
+	address := make(net.IP, net.IPv6len)
+	copy(address, network.To16())
+	address[net.IPv6len-1] = 1
+	return address
+}
+
 // convertVolume converts hcloud.Volume to our Volume type
 func convertVolume(volume *hcloud.Volume) *Volume {
 	return &Volume{
-		ID:       volume.ID,
+		ID:       strconv.FormatInt(volume.ID, 10),
 		Name:     volume.Name,
 		Size:     volume.Size,
 		Location: volume.Location.Name,
 		Status:   string(volume.Status),
+		Device:   volumeDevicePath(strconv.FormatInt(volume.ID, 10)),
+		Labels:   volume.Labels,
+	}
+}
+
+// volumeDevicePath returns the stable device path servers see a volume at, or "" for
+// an unknown volume
+func volumeDevicePath(volumeID string) string {
+	if volumeID == "" {
+		return ""
 	}
+	return "/dev/disk/by-id/scsi-0HC_Volume_" + volumeID
 }
 
 // convertSSHKey converts hcloud.SSHKey to our SSHKey type
@@ -70,3 +126,18 @@ func parseVolumeID(volumeID string) int64 {
 	}
 	return id
 }
+
+// ownerLabels returns the owner labels of a new volume or image, or empty labels
+// without an owner
+func (c *Client) ownerLabels() map[string]string {
+	if c.config.Owner.ID == "" {
+		return make(map[string]string)
+	}
+	return c.config.Owner.Labels()
+}
+
+// owns reports whether a volume or image with labels may be reused; without an owner
+// all may
+func (c *Client) owns(labels map[string]string) bool {
+	return c.config.Owner.ID == "" || c.config.Owner.Owns(labels)
+}
@@ -2,7 +2,6 @@ package hetzner
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"testing"
 
@@ -57,7 +56,7 @@ func TestDockerStatePersistence(t *testing.T) {
 			// But for testing, we clean up to avoid accumulating test volumes
 			if volume1 != nil {
 				// First detach if attached
-				client.DetachVolume(ctx, fmt.Sprintf("%d", volume1.ID))
+				client.DetachVolume(ctx, volume1.ID)
 				// Note: Hetzner doesn't provide volume deletion in the basic API
 				// Volumes need to be deleted manually through the web interface
 			}
@@ -72,7 +71,9 @@ func TestDockerStatePersistence(t *testing.T) {
 			VolumeMount:  "/var/lib/docker",
 		}
 
-		script := GenerateCloudInitScript(cloudInitConfig)
+		script, err := GenerateCloudInitScript(cloudInitConfig)
+
+		require.NoError(t, err)
 
 		// Verify script contains essential components
 		assert.Contains(t, script, "#cloud-config")
@@ -129,7 +130,7 @@ func TestVolumeManagementMethods(t *testing.T) {
 		// Clean up
 		defer func() {
 			// Detach volume if needed
-			client.DetachVolume(ctx, fmt.Sprintf("%d", volume.ID))
+			client.DetachVolume(ctx, volume.ID)
 		}()
 	})
 
@@ -150,6 +151,8 @@ func BenchmarkCloudInitGeneration(b *testing.B) {
 	}
 
 	for b.Loop() {
-		_ = GenerateCloudInitScript(config)
+		if _, err := GenerateCloudInitScript(config); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
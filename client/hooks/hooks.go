@@ -0,0 +1,292 @@
+// Package hooks implements a lifecycle hook bus for the client daemon. Hooks are
+// configured in YAML and run either a local command or a webhook when events such
+// as server provisioning or port forward creation happen, so users can automate
+// around DockBridge without forking it.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// EventType identifies a lifecycle event
+type EventType string
+
+const (
+	EventServerProvisioned EventType = "server_provisioned"
+	EventServerDestroyed   EventType = "server_destroyed"
+	EventServerStopped     EventType = "server_stopped"
+	EventContainerCreated  EventType = "container_created"
+	EventForwardAdded      EventType = "forward_added"
+	EventBudgetExceeded    EventType = "budget_exceeded"
+	EventHeartbeatLost     EventType = "heartbeat_lost"
+	EventIdleShutdown      EventType = "idle_shutdown"
+	EventVolumeAttached    EventType = "volume_attached"
+
+	// EventAll subscribes a hook to every event
+	EventAll EventType = "*"
+)
+
+// knownEvents lists the events hooks can subscribe to
+var knownEvents = []EventType{
+	EventServerProvisioned,
+	EventServerDestroyed,
+	EventServerStopped,
+	EventContainerCreated,
+	EventForwardAdded,
+	EventBudgetExceeded,
+	EventHeartbeatLost,
+	EventIdleShutdown,
+	EventVolumeAttached,
+}
+
+// IsKnownEvent reports whether name is a valid event subscription
+func IsKnownEvent(name string) bool {
+	if EventType(name) == EventAll {
+		return true
+	}
+	for _, e := range knownEvents {
+		if string(e) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTimeout bounds a single hook run
+const DefaultTimeout = 30 * time.Second
+
+// Event is delivered to hooks
+type Event struct {
+	Type    EventType         `json:"type"`
+	Time    time.Time         `json:"time"`
+	Context string            `json:"context,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// NewEvent creates an event of the given type stamped with the current time
+func NewEvent(eventType EventType, data map[string]string) Event {
+	return Event{Type: eventType, Time: time.Now(), Data: data}
+}
+
+// Publisher publishes lifecycle events
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Runner executes a hook for an event
+type Runner interface {
+	Name() string
+	Run(ctx context.Context, event Event) error
+}
+
+// Listener observes every published event. Listeners are called synchronously and in
+// publication order, so they must return quickly and must not publish themselves.
+type Listener func(event Event)
+
+// subscription binds a runner to the events it handles
+type subscription struct {
+	events  map[EventType]bool
+	runner  Runner
+	timeout time.Duration
+}
+
+// Bus dispatches events to subscribed hook runners in the background
+type Bus struct {
+	context string
+	logger  logger.LoggerInterface
+
+	mu            sync.RWMutex
+	subscriptions []subscription
+	wg            sync.WaitGroup
+
+	// listenMu serializes listener calls so every listener sees the same event order
+	listenMu  sync.Mutex
+	listeners []Listener
+}
+
+// NewBus creates a bus; events published on it are tagged with contextName
+func NewBus(contextName string, logger logger.LoggerInterface) *Bus {
+	return &Bus{context: contextName, logger: logger}
+}
+
+// NewBusFromConfig creates a bus with exec and webhook runners from the hooks
+// configuration and the runners of notification sinks
+func NewBusFromConfig(contextName string, hooks []config.HookConfig, sinks []config.NotificationSinkConfig, logger logger.LoggerInterface) (*Bus, error) {
+	bus := NewBus(contextName, logger)
+	for i, hook := range hooks {
+		if err := Validate(hook); err != nil {
+			return nil, fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+		runner, err := newRunner(hook)
+		if err != nil {
+			return nil, fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+
+		events := make([]EventType, 0, len(hook.Events))
+		for _, name := range hook.Events {
+			events = append(events, EventType(name))
+		}
+		bus.Subscribe(runner, hook.Timeout, events...)
+	}
+
+	for i, sink := range sinks {
+		runner, err := newSinkRunner(sink, logger)
+		if err != nil {
+			return nil, fmt.Errorf("notifications.sinks[%d]: %w", i, err)
+		}
+
+		events, err := eventTypes(runner.Name(), sink.Events)
+		if err != nil {
+			return nil, err
+		}
+		bus.Subscribe(runner, sink.Timeout, events...)
+	}
+	return bus, nil
+}
+
+// eventTypes converts the event subscriptions of the hook or sink named name
+func eventTypes(name string, names []string) ([]EventType, error) {
+	events := make([]EventType, 0, len(names))
+	for _, event := range names {
+		if !IsKnownEvent(event) {
+			return nil, fmt.Errorf("hook %q: unknown event %q", name, event)
+		}
+		events = append(events, EventType(event))
+	}
+	return events, nil
+}
+
+// Validate checks a hook configuration; the configuration is validated with it before
+// the daemon starts
+func Validate(hook config.HookConfig) error {
+	if len(hook.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range hook.Events {
+		if !IsKnownEvent(event) {
+			return fmt.Errorf("unknown event '%s'", event)
+		}
+	}
+
+	switch {
+	case len(hook.Command) > 0 && hook.URL != "":
+		return errors.New("set either command or url, not both")
+	case len(hook.Command) == 0 && hook.URL == "":
+		return errors.New("command or url is required")
+	case hook.URL != "":
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url '%s', must be an http(s) URL", hook.URL)
+		}
+	}
+
+	if hook.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	return nil
+}
+
+// newRunner creates the runner described by a valid hook configuration
+func newRunner(hook config.HookConfig) (Runner, error) {
+	if len(hook.Command) > 0 {
+		return NewExecRunner(hook.Name, hook.Command), nil
+	}
+	return NewWebhookRunner(hook.Name, hook.URL, hook.Headers)
+}
+
+// Subscribe runs runner for the given events; a non-positive timeout uses DefaultTimeout
+func (b *Bus) Subscribe(runner Runner, timeout time.Duration, events ...EventType) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	set := make(map[EventType]bool, len(events))
+	for _, e := range events {
+		set[e] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{events: set, runner: runner, timeout: timeout})
+}
+
+// Listen calls listener for every event published on the bus, in publication order
+func (b *Bus) Listen(listener Listener) {
+	b.listenMu.Lock()
+	defer b.listenMu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// Publish notifies listeners and then runs all hooks subscribed to the event without
+// waiting for them
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Context == "" {
+		event.Context = b.context
+	}
+
+	b.listenMu.Lock()
+	for _, listener := range b.listeners {
+		listener(event)
+	}
+	b.listenMu.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscriptions {
+		if !sub.events[event.Type] && !sub.events[EventAll] {
+			continue
+		}
+
+		b.wg.Add(1)
+		go func(sub subscription) {
+			defer b.wg.Done()
+			b.run(sub, event)
+		}(sub)
+	}
+}
+
+// run executes a single hook and logs the outcome
+func (b *Bus) run(sub subscription, event Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), sub.timeout)
+	defer cancel()
+
+	if err := sub.runner.Run(ctx, event); err != nil {
+		b.logger.WithFields(map[string]any{
+			"hook":  sub.runner.Name(),
+			"event": string(event.Type),
+			"error": err.Error(),
+		}).Warn("Lifecycle hook failed")
+		return
+	}
+
+	b.logger.WithFields(map[string]any{
+		"hook":  sub.runner.Name(),
+		"event": string(event.Type),
+	}).Debug("Lifecycle hook completed")
+}
+
+// Wait blocks until all running hooks have finished
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+// NopPublisher discards all events
+type NopPublisher struct{}
+
+// Publish does nothing
+func (NopPublisher) Publish(Event) {}
+
+// Ensure Bus implements Publisher
+var _ Publisher = (*Bus)(nil)
@@ -0,0 +1,173 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingRunner records the events it receives
+type recordingRunner struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (r *recordingRunner) Name() string { return "recorder" }
+
+func (r *recordingRunner) Run(ctx context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func (r *recordingRunner) types() []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var types []EventType
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestIsKnownEvent(t *testing.T) {
+	assert.True(t, IsKnownEvent("server_provisioned"))
+	assert.True(t, IsKnownEvent("forward_added"))
+	assert.True(t, IsKnownEvent("budget_exceeded"))
+	assert.True(t, IsKnownEvent("*"))
+	assert.False(t, IsKnownEvent("server_exploded"))
+}
+
+func TestBusDispatchesSubscribedEvents(t *testing.T) {
+	bus := NewBus("dev", logger.NewDefault())
+	provisioned := &recordingRunner{}
+	all := &recordingRunner{err: errors.New("boom")}
+	bus.Subscribe(provisioned, 0, EventServerProvisioned)
+	bus.Subscribe(all, 0, EventAll)
+
+	bus.Publish(NewEvent(EventServerProvisioned, nil))
+	bus.Publish(NewEvent(EventForwardAdded, nil))
+	bus.Wait()
+
+	assert.Equal(t, []EventType{EventServerProvisioned}, provisioned.types())
+	assert.ElementsMatch(t, []EventType{EventServerProvisioned, EventForwardAdded}, all.types())
+	assert.Equal(t, "dev", provisioned.events[0].Context)
+}
+
+func TestBusListenersSeeEventsInOrder(t *testing.T) {
+	bus := NewBus("gpu", logger.NewDefault())
+
+	var got []EventType
+	bus.Listen(func(event Event) {
+		assert.Equal(t, "gpu", event.Context)
+		got = append(got, event.Type)
+	})
+
+	want := []EventType{EventServerProvisioned, EventForwardAdded, EventContainerCreated, EventServerDestroyed}
+	for _, e := range want {
+		bus.Publish(NewEvent(e, nil))
+	}
+
+	// Listeners run before Publish returns, so no Wait is needed
+	assert.Equal(t, want, got)
+}
+
+func TestNewBusFromConfigRejectsInvalidHooks(t *testing.T) {
+	_, err := NewBusFromConfig("", []config.HookConfig{{Events: []string{"nope"}, Command: []string{"true"}}}, nil, logger.NewDefault())
+	assert.Error(t, err)
+
+	_, err = NewBusFromConfig("", []config.HookConfig{{Events: []string{"*"}}}, nil, logger.NewDefault())
+	assert.Error(t, err)
+
+	_, err = NewBusFromConfig("", []config.HookConfig{{Events: []string{"*"}, URL: "not a url"}}, nil, logger.NewDefault())
+	assert.Error(t, err)
+}
+
+func TestExecRunnerPassesEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	out := filepath.Join(t.TempDir(), "event")
+	runner := NewExecRunner("test", []string{"sh", "-c", `cat > "$0"; echo >> "$0"; echo "$DOCKBRIDGE_EVENT $DOCKBRIDGE_SERVER_NAME" >> "$0"`, out})
+
+	event := NewEvent(EventServerProvisioned, map[string]string{"server_name": "dockbridge-1"})
+	require.NoError(t, runner.Run(context.Background(), event))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	require.Len(t, lines, 2)
+
+	var got Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, EventServerProvisioned, got.Type)
+	assert.Equal(t, "server_provisioned dockbridge-1", lines[1])
+}
+
+func TestExecRunnerReportsFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	runner := NewExecRunner("", []string{"sh", "-c", "echo nope >&2; exit 3"})
+	err := runner.Run(context.Background(), NewEvent(EventForwardAdded, nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope")
+	assert.Equal(t, "sh", runner.Name())
+}
+
+func TestWebhookRunner(t *testing.T) {
+	received := make(chan Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	runner, err := NewWebhookRunner("chat", server.URL, map[string]string{"Authorization": "Bearer secret"})
+	require.NoError(t, err)
+	require.NoError(t, runner.Run(context.Background(), NewEvent(EventServerDestroyed, map[string]string{"server_id": "42"})))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, EventServerDestroyed, event.Type)
+		assert.Equal(t, "42", event.Data["server_id"])
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestWebhookRunnerNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	runner, err := NewWebhookRunner("", server.URL, nil)
+	require.NoError(t, err)
+	err = runner.Run(context.Background(), NewEvent(EventForwardAdded, nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
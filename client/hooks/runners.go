@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExecRunner runs a local command. The event is passed as JSON on stdin and as
+// DOCKBRIDGE_* environment variables.
+type ExecRunner struct {
+	name    string
+	command []string
+}
+
+// NewExecRunner creates a runner for command (argv form, no shell)
+func NewExecRunner(name string, command []string) *ExecRunner {
+	if name == "" {
+		name = command[0]
+	}
+	return &ExecRunner{name: name, command: command}
+}
+
+// Name identifies the hook in logs
+func (r *ExecRunner) Name() string { return r.name }
+
+// Run executes the command
+func (r *ExecRunner) Run(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	// #nosec G204 -- the command comes from the user's own configuration
+	cmd := exec.CommandContext(ctx, r.command[0], r.command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), eventEnv(event)...)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// eventEnv returns the environment variables describing event
+func eventEnv(event Event) []string {
+	env := []string{
+		"DOCKBRIDGE_EVENT=" + string(event.Type),
+		"DOCKBRIDGE_EVENT_TIME=" + event.Time.UTC().Format("2006-01-02T15:04:05Z"),
+		"DOCKBRIDGE_CONTEXT=" + event.Context,
+	}
+	for key, value := range event.Data {
+		env = append(env, "DOCKBRIDGE_"+envName(key)+"="+value)
+	}
+	return env
+}
+
+// envName converts a data key such as "server_id" into "SERVER_ID"
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// WebhookRunner POSTs the event as JSON to a URL
+type WebhookRunner struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookRunner creates a runner posting to rawURL with extra headers
+func NewWebhookRunner(name, rawURL string, headers map[string]string) (*WebhookRunner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("hook %q: invalid webhook url %q", name, rawURL)
+	}
+	if name == "" {
+		name = u.Host
+	}
+	return &WebhookRunner{
+		name:    name,
+		url:     rawURL,
+		headers: headers,
+		client:  &http.Client{},
+	}, nil
+}
+
+// Name identifies the hook in logs
+func (r *WebhookRunner) Name() string { return r.name }
+
+// Run posts the event
+func (r *WebhookRunner) Run(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DockBridge-Hooks")
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
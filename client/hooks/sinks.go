@@ -0,0 +1,196 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// Notification sink types of the notifications.sinks configuration
+const (
+	SinkLog     = "log"
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+	SinkDesktop = "desktop"
+)
+
+// IsKnownSinkType reports whether name is a notification sink type
+func IsKnownSinkType(name string) bool {
+	switch name {
+	case SinkLog, SinkWebhook, SinkSlack, SinkDesktop:
+		return true
+	default:
+		return false
+	}
+}
+
+// newSinkRunner creates the runner of a notification sink
+func newSinkRunner(sink config.NotificationSinkConfig, logger logger.LoggerInterface) (Runner, error) {
+	name := sink.Name
+	if name == "" {
+		name = sink.Type
+	}
+
+	switch sink.Type {
+	case SinkLog:
+		return NewLogRunner(name, logger), nil
+	case SinkWebhook:
+		return NewWebhookRunner(name, sink.URL, sink.Headers)
+	case SinkSlack:
+		return NewSlackRunner(name, sink.URL)
+	case SinkDesktop:
+		return NewDesktopRunner(name), nil
+	default:
+		return nil, fmt.Errorf("sink %q: unknown type %q, must be log, webhook, slack or desktop", name, sink.Type)
+	}
+}
+
+// Summary describes an event in one human-readable sentence, prefixed with its
+// context unless it is the default one
+func Summary(event Event) string {
+	data := event.Data
+	var text string
+	switch event.Type {
+	case EventServerProvisioned:
+		text = fmt.Sprintf("Server %s provisioned at %s", data["server_name"], data["server_ip"])
+	case EventServerDestroyed:
+		text = fmt.Sprintf("Server %s destroyed (%s)", data["server_name"], data["reason"])
+	case EventServerStopped:
+		text = fmt.Sprintf("Server %s powered off (%s)", data["server_name"], data["reason"])
+	case EventIdleShutdown:
+		text = fmt.Sprintf("Server %s shut down after being idle (%s)", data["server_name"], data["reason"])
+	case EventHeartbeatLost:
+		text = fmt.Sprintf("Heartbeats stopped reaching server %s since %s; it is destroying itself", data["server_name"], data["last_heartbeat"])
+	case EventVolumeAttached:
+		text = fmt.Sprintf("Volume %s attached to server %s at %s", data["volume_name"], data["server_name"], data["mount"])
+	case EventContainerCreated:
+		text = fmt.Sprintf("Container %s created from %s", data["container_name"], data["image"])
+	case EventForwardAdded:
+		if data["forward_type"] == "unix" {
+			text = fmt.Sprintf("Forward %s → %s:%s added", data["local_socket"], data["container_name"], data["remote_socket"])
+		} else {
+			text = fmt.Sprintf("Forward localhost:%s → %s:%s added", data["local_port"], data["container_name"], data["remote_port"])
+		}
+	case EventBudgetExceeded:
+		text = fmt.Sprintf("Estimated spend %s exceeded the monthly budget of %s", data["spent"], data["budget"])
+	default:
+		text = string(event.Type)
+	}
+
+	if event.Context != "" {
+		return "[" + event.Context + "] " + text
+	}
+	return text
+}
+
+// LogRunner writes events to the daemon log
+type LogRunner struct {
+	name   string
+	logger logger.LoggerInterface
+}
+
+// NewLogRunner creates a runner logging events with logger
+func NewLogRunner(name string, logger logger.LoggerInterface) *LogRunner {
+	return &LogRunner{name: name, logger: logger}
+}
+
+// Name identifies the sink in logs
+func (r *LogRunner) Name() string { return r.name }
+
+// Run logs the event with its data as fields
+func (r *LogRunner) Run(ctx context.Context, event Event) error {
+	fields := make(map[string]any, len(event.Data)+2)
+	for key, value := range event.Data {
+		fields[key] = value
+	}
+	fields["event"] = string(event.Type)
+	if event.Context != "" {
+		fields["context"] = event.Context
+	}
+	r.logger.WithFields(fields).Info(Summary(event))
+	return nil
+}
+
+// SlackRunner posts events to a Slack channel through an incoming webhook
+type SlackRunner struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewSlackRunner creates a runner posting to the Slack incoming webhook rawURL
+func NewSlackRunner(name, rawURL string) (*SlackRunner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("sink %q: invalid Slack webhook url %q, must be an https URL", name, rawURL)
+	}
+	return &SlackRunner{name: name, url: rawURL, client: &http.Client{}}, nil
+}
+
+// Name identifies the sink in logs
+func (r *SlackRunner) Name() string { return r.name }
+
+// Run posts the event's summary as a Slack message
+func (r *SlackRunner) Run(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(map[string]string{"text": ":whale: DockBridge: " + Summary(event)})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DockBridge-Hooks")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// DesktopRunner shows events as desktop notifications
+type DesktopRunner struct {
+	name string
+
+	// show displays a notification (replaceable in tests)
+	show func(title, message string) error
+}
+
+// NewDesktopRunner creates a runner showing desktop notifications
+func NewDesktopRunner(name string) *DesktopRunner {
+	return &DesktopRunner{name: name, show: notify.Show}
+}
+
+// Name identifies the sink in logs
+func (r *DesktopRunner) Name() string { return r.name }
+
+// Run shows the event's summary
+func (r *DesktopRunner) Run(ctx context.Context, event Event) error {
+	title := "DockBridge"
+	if event.Context != "" {
+		title += " (" + event.Context + ")"
+	}
+	text := Summary(event)
+	if event.Context != "" {
+		text = strings.TrimPrefix(text, "["+event.Context+"] ")
+	}
+	return r.show(title, text)
+}
@@ -0,0 +1,78 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	event := NewEvent(EventIdleShutdown, map[string]string{"server_name": "dockbridge-7", "reason": "idle timeout"})
+	assert.Equal(t, "Server dockbridge-7 shut down after being idle (idle timeout)", Summary(event))
+
+	event.Context = "gpu"
+	assert.Equal(t, "[gpu] Server dockbridge-7 shut down after being idle (idle timeout)", Summary(event))
+
+	event = NewEvent(EventForwardAdded, map[string]string{"forward_type": "tcp", "local_port": "8080", "container_name": "web", "remote_port": "80"})
+	assert.Equal(t, "Forward localhost:8080 → web:80 added", Summary(event))
+}
+
+func TestSlackRunner(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		received <- message
+	}))
+	defer server.Close()
+
+	_, err := NewSlackRunner("slack", "http://hooks.slack.com/services/x")
+	require.Error(t, err, "Slack webhooks use https")
+
+	runner, err := NewSlackRunner("slack", server.URL)
+	require.NoError(t, err)
+	runner.client = server.Client()
+
+	event := NewEvent(EventHeartbeatLost, map[string]string{"server_name": "dockbridge-7", "last_heartbeat": "2025-01-01T10:00:00Z"})
+	require.NoError(t, runner.Run(context.Background(), event))
+	assert.Equal(t, ":whale: DockBridge: Heartbeats stopped reaching server dockbridge-7 since 2025-01-01T10:00:00Z; it is destroying itself", (<-received)["text"])
+}
+
+func TestDesktopRunner(t *testing.T) {
+	var title, message string
+	runner := NewDesktopRunner("desktop")
+	runner.show = func(t, m string) error {
+		title, message = t, m
+		return nil
+	}
+
+	event := NewEvent(EventVolumeAttached, map[string]string{"volume_name": "data", "server_name": "dockbridge-7", "mount": "/data"})
+	event.Context = "gpu"
+	require.NoError(t, runner.Run(context.Background(), event))
+	assert.Equal(t, "DockBridge (gpu)", title)
+	assert.Equal(t, "Volume data attached to server dockbridge-7 at /data", message)
+}
+
+func TestNewBusFromConfigSinks(t *testing.T) {
+	bus, err := NewBusFromConfig("", nil, []config.NotificationSinkConfig{
+		{Type: SinkLog, Events: []string{"*"}},
+		{Type: SinkDesktop, Events: []string{"idle_shutdown"}},
+	}, logger.NewDefault())
+	require.NoError(t, err)
+	require.Len(t, bus.subscriptions, 2)
+	assert.Equal(t, "log", bus.subscriptions[0].runner.Name())
+	assert.True(t, bus.subscriptions[1].events[EventIdleShutdown])
+
+	_, err = NewBusFromConfig("", nil, []config.NotificationSinkConfig{{Type: "pager", Events: []string{"*"}}}, logger.NewDefault())
+	assert.ErrorContains(t, err, "unknown type")
+
+	_, err = NewBusFromConfig("", nil, []config.NotificationSinkConfig{{Type: SinkSlack, Events: []string{"*"}}}, logger.NewDefault())
+	assert.Error(t, err)
+}
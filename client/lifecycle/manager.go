@@ -2,12 +2,15 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/activity"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/notify"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/server"
 	"github.com/dockbridge/dockbridge/shared/config"
@@ -19,6 +22,9 @@ type Manager struct {
 	serverManager      server.ServerManager
 	config             *config.ActivityConfig
 	logger             logger.LoggerInterface
+	notifier           notify.Notifier
+	hooks              hooks.Publisher
+	onShutdown         func()
 	ctx                context.Context
 	cancel             context.CancelFunc
 	shutdownTimer      *time.Timer
@@ -40,9 +46,27 @@ func NewManager(
 		serverManager:   serverManager,
 		config:          config,
 		logger:          logger,
+		notifier:        notify.NopNotifier{},
+		hooks:           hooks.NopPublisher{},
 	}
 }
 
+// SetNotifier sets the notifier used for shutdown warnings and server destruction
+func (m *Manager) SetNotifier(notifier notify.Notifier) {
+	m.notifier = notifier
+}
+
+// SetHooks sets the publisher that receives server_destroyed hook events
+func (m *Manager) SetHooks(publisher hooks.Publisher) {
+	m.hooks = publisher
+}
+
+// SetOnShutdown sets a callback run after an idle server was destroyed or powered off,
+// e.g. to drop connections so the next Docker command re-provisions or resumes it
+func (m *Manager) SetOnShutdown(callback func()) {
+	m.onShutdown = callback
+}
+
 // Start starts the lifecycle manager
 func (m *Manager) Start(ctx context.Context) error {
 	m.ctx, m.cancel = context.WithCancel(ctx)
@@ -115,6 +139,7 @@ func (m *Manager) Stop() error {
 				m.logger.WithFields(map[string]any{
 					"server_id": srv.ID,
 				}).Info("Server destroyed successfully during shutdown")
+				m.publishServerDestroyed(srv, "shutdown")
 			}
 		}
 	}
@@ -235,6 +260,9 @@ func (m *Manager) checkAndScheduleShutdown() {
 			"grace_period":        m.config.GracePeriod,
 		}).Info("⏰ Scheduling server shutdown due to inactivity")
 
+		m.notifier.Notify(notify.EventKeepAliveWarning, "DockBridge server shutting down soon",
+			fmt.Sprintf("The remote server will be %s in %s due to inactivity", m.lifecyclePolicyVerb(), timeUntilShutdown.Round(time.Second)))
+
 		m.shutdownTimer = time.AfterFunc(timeUntilShutdown, func() {
 			// Check if shutdown is already in progress
 			m.mu.Lock()
@@ -254,8 +282,45 @@ func (m *Manager) checkAndScheduleShutdown() {
 	}
 }
 
-// shutdownServer shuts down the server
-func (m *Manager) shutdownServer(reason string) {
+var (
+	// ErrNoServer is returned when there is no running server to shut down
+	ErrNoServer = errors.New("no running server")
+	// ErrShutdownInProgress is returned when another shutdown has not finished yet
+	ErrShutdownInProgress = errors.New("server shutdown already in progress")
+)
+
+// ShutdownNow destroys the running server immediately, e.g. on an explicit user request
+func (m *Manager) ShutdownNow(reason string) error {
+	return m.ShutdownNowWith(reason, m.lifecyclePolicy())
+}
+
+// ShutdownNowWith shuts the running server down immediately, destroying or powering it
+// off as policy says regardless of the configured lifecycle policy
+func (m *Manager) ShutdownNowWith(reason string, policy config.LifecyclePolicy) error {
+	m.mu.Lock()
+	if m.shutdownInProgress {
+		m.mu.Unlock()
+		return ErrShutdownInProgress
+	}
+	m.shutdownInProgress = true
+	if m.shutdownTimer != nil {
+		m.shutdownTimer.Stop()
+		m.shutdownTimer = nil
+	}
+	m.mu.Unlock()
+
+	return m.shutdownServerWith(reason, policy)
+}
+
+// shutdownServer shuts down the server as the lifecycle policy says
+func (m *Manager) shutdownServer(reason string) error {
+	return m.shutdownServerWith(reason, m.lifecyclePolicy())
+}
+
+// shutdownServerWith shuts down the most recent running server, destroying or powering
+// it off as policy says; failures are logged as well as returned, since background
+// shutdowns have nobody to report them to
+func (m *Manager) shutdownServerWith(reason string, policy config.LifecyclePolicy) error {
 	// Ensure we reset the shutdown flag when done
 	defer func() {
 		m.mu.Lock()
@@ -276,7 +341,7 @@ func (m *Manager) shutdownServer(reason string) {
 		m.logger.WithFields(map[string]any{
 			"error": err.Error(),
 		}).Error("Failed to list servers for shutdown")
-		return
+		return fmt.Errorf("failed to list servers: %w", err)
 	}
 
 	// Find the most recent running server (no verbose logging)
@@ -291,20 +356,31 @@ func (m *Manager) shutdownServer(reason string) {
 
 	if serverToShutdown == nil {
 		m.logger.Info("No running server found to shutdown (may have been destroyed already)")
-		return
+		return ErrNoServer
 	}
 
 	m.logger.WithFields(map[string]any{
-		"server_id":   serverToShutdown.ID,
-		"server_name": serverToShutdown.Name,
-		"reason":      reason,
+		"server_id":        serverToShutdown.ID,
+		"server_name":      serverToShutdown.Name,
+		"reason":           reason,
+		"lifecycle_policy": string(policy),
 	}).Info("Shutting down server due to inactivity")
 
+	// Power the server off if configured; providers without power control fall back to destroying it
+	if policy == config.LifecyclePolicyPowerOff {
+		if m.powerOffServer(serverToShutdown, reason) {
+			m.publishIdleShutdown(serverToShutdown, reason, "powered_off")
+			m.afterShutdown()
+			return nil
+		}
+	}
+
 	// Destroy the server (this preserves the volume)
 	m.logger.WithFields(map[string]any{
 		"server_id":   serverToShutdown.ID,
 		"server_name": serverToShutdown.Name,
-	}).Info("💥 DESTROYING SERVER due to inactivity")
+		"reason":      reason,
+	}).Info("💥 DESTROYING SERVER")
 
 	if err := m.serverManager.DestroyServer(m.ctx, serverToShutdown.ID); err != nil {
 		// Check if the error is "server not found" - this means it was already destroyed
@@ -317,7 +393,7 @@ func (m *Manager) shutdownServer(reason string) {
 				"server_id": serverToShutdown.ID,
 				"error":     err.Error(),
 			}).Error("❌ FAILED to destroy server")
-			return
+			return fmt.Errorf("failed to destroy server %s: %w", serverToShutdown.Name, err)
 		}
 	} else {
 		m.logger.WithFields(map[string]any{
@@ -326,10 +402,66 @@ func (m *Manager) shutdownServer(reason string) {
 		}).Info("✅ Server destroyed successfully, volume preserved for future use")
 	}
 
-	// Reset shutdown timer and update cache
+	m.notifier.Notify(notify.EventSelfDestruct, "DockBridge server destroyed",
+		fmt.Sprintf("Server %s was destroyed (%s); the Docker data volume was preserved", serverToShutdown.Name, reason))
+	m.publishServerDestroyed(serverToShutdown, reason)
+	m.publishIdleShutdown(serverToShutdown, reason, "destroyed")
+
+	m.afterShutdown()
+	return nil
+}
+
+// powerOffServer powers off an idle server and reports whether it succeeded
+func (m *Manager) powerOffServer(srv *server.ServerInfo, reason string) bool {
+	if err := m.serverManager.StopServer(m.ctx, srv.ID); err != nil {
+		m.logger.WithFields(map[string]any{
+			"server_id": srv.ID,
+			"error":     err.Error(),
+		}).Warn("Failed to power off server, destroying it instead")
+		return false
+	}
+
+	m.logger.WithFields(map[string]any{
+		"server_id":   srv.ID,
+		"server_name": srv.Name,
+	}).Info("Server powered off, it will be resumed on the next Docker command")
+
+	m.notifier.Notify(notify.EventSelfDestruct, "DockBridge server powered off",
+		fmt.Sprintf("Server %s was powered off (%s); it resumes on the next Docker command", srv.Name, reason))
+	m.hooks.Publish(hooks.NewEvent(hooks.EventServerStopped, map[string]string{
+		"server_id":   srv.ID,
+		"server_name": srv.Name,
+		"reason":      reason,
+	}))
+	return true
+}
+
+// afterShutdown resets the shutdown timer and server cache once the idle server is gone,
+// then runs the shutdown callback
+func (m *Manager) afterShutdown() {
 	m.shutdownTimer = nil
-	m.hasServers = false // We just destroyed the server
+	m.hasServers = false // We just destroyed or powered off the server
 	m.lastServerCheck = time.Now()
+
+	if m.onShutdown != nil {
+		m.onShutdown()
+	}
+}
+
+// lifecyclePolicy returns the configured lifecycle policy, defaulting to destroy
+func (m *Manager) lifecyclePolicy() config.LifecyclePolicy {
+	if m.config.LifecyclePolicy == config.LifecyclePolicyPowerOff {
+		return config.LifecyclePolicyPowerOff
+	}
+	return config.LifecyclePolicyDestroy
+}
+
+// lifecyclePolicyVerb describes the lifecycle policy in user-facing messages
+func (m *Manager) lifecyclePolicyVerb() string {
+	if m.lifecyclePolicy() == config.LifecyclePolicyPowerOff {
+		return "powered off"
+	}
+	return "destroyed"
 }
 
 // hasRunningServers checks if there are any running servers with caching to avoid API spam
@@ -364,3 +496,22 @@ func isServerNotFoundError(err error) bool {
 		strings.Contains(errStr, "not found") ||
 		strings.Contains(errStr, "not_found")
 }
+
+// publishIdleShutdown emits the idle_shutdown hook event; action is destroyed or powered_off
+func (m *Manager) publishIdleShutdown(srv *server.ServerInfo, reason, action string) {
+	m.hooks.Publish(hooks.NewEvent(hooks.EventIdleShutdown, map[string]string{
+		"server_id":   srv.ID,
+		"server_name": srv.Name,
+		"reason":      reason,
+		"action":      action,
+	}))
+}
+
+// publishServerDestroyed emits the server_destroyed hook event
+func (m *Manager) publishServerDestroyed(srv *server.ServerInfo, reason string) {
+	m.hooks.Publish(hooks.NewEvent(hooks.EventServerDestroyed, map[string]string{
+		"server_id":   srv.ID,
+		"server_name": srv.Name,
+		"reason":      reason,
+	}))
+}
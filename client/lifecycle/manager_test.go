@@ -2,10 +2,13 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/activity"
+	"github.com/dockbridge/dockbridge/client/notify"
+	"github.com/dockbridge/dockbridge/client/provider"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/server"
 	"github.com/dockbridge/dockbridge/shared/config"
@@ -29,12 +32,19 @@ func (m *MockActivityTracker) GetTimeUntilShutdown() (time.Duration, string) {
 	return time.Hour, "none"
 }
 func (m *MockActivityTracker) RegisterCallback(callback activity.ActivityCallback) {}
+func (m *MockActivityTracker) RegisterSource(source activity.Source)               {}
+func (m *MockActivityTracker) Record(activityType activity.ActivityType) error     { return nil }
+func (m *MockActivityTracker) RecordAPIActivity() error                            { return nil }
+func (m *MockActivityTracker) RecordContainerActivity() error                      { return nil }
+func (m *MockActivityTracker) IdleSince() time.Time                                { return time.Now() }
 
 // MockServerManager implements server.ServerManager for testing
 type MockServerManager struct {
 	servers             []*server.ServerInfo
 	destroyServerCalled bool
 	destroyedServerID   string
+	stoppedServerID     string
+	stopErr             error
 }
 
 func (m *MockServerManager) EnsureServer(ctx context.Context) (*server.ServerInfo, error) {
@@ -47,6 +57,14 @@ func (m *MockServerManager) DestroyServer(ctx context.Context, serverID string)
 	return nil
 }
 
+func (m *MockServerManager) StopServer(ctx context.Context, serverID string) error {
+	if m.stopErr != nil {
+		return m.stopErr
+	}
+	m.stoppedServerID = serverID
+	return nil
+}
+
 func (m *MockServerManager) GetServerStatus(ctx context.Context) (*server.ServerStatus, error) {
 	status := server.StatusRunning
 	return &status, nil
@@ -60,6 +78,8 @@ func (m *MockServerManager) EnsureVolume(ctx context.Context) (*server.VolumeInf
 	return nil, nil
 }
 
+func (m *MockServerManager) SetProvisioningObserver(observer provider.ProvisioningObserver) {}
+
 func TestManager_Stop(t *testing.T) {
 	// Setup mocks
 	activityTracker := &MockActivityTracker{}
@@ -142,3 +162,141 @@ func TestManager_Stop_NoRunningServers(t *testing.T) {
 		t.Error("ServerManager.DestroyServer() should NOT have been called")
 	}
 }
+
+// MockNotifier records notifications for testing
+type MockNotifier struct {
+	events []notify.Event
+}
+
+func (m *MockNotifier) Notify(event notify.Event, title, message string) {
+	m.events = append(m.events, event)
+}
+
+func TestManager_ShutdownServerNotifies(t *testing.T) {
+	serverManager := &MockServerManager{
+		servers: []*server.ServerInfo{{
+			ID:     "server-123",
+			Name:   "dockbridge-test",
+			Status: server.StatusRunning,
+		}},
+	}
+	notifier := &MockNotifier{}
+
+	manager := NewManager(&MockActivityTracker{}, serverManager, &config.ActivityConfig{}, logger.NewDefault())
+	manager.SetNotifier(notifier)
+	manager.ctx = context.Background()
+
+	if err := manager.shutdownServer("idle timeout"); err != nil {
+		t.Fatalf("shutdownServer() error = %v", err)
+	}
+
+	if !serverManager.destroyServerCalled {
+		t.Fatal("ServerManager.DestroyServer() was not called")
+	}
+	if len(notifier.events) != 1 || notifier.events[0] != notify.EventSelfDestruct {
+		t.Errorf("Expected a single self_destruct notification, got %v", notifier.events)
+	}
+}
+
+func TestManager_ShutdownNowWithoutServer(t *testing.T) {
+	serverManager := &MockServerManager{}
+
+	manager := NewManager(&MockActivityTracker{}, serverManager, &config.ActivityConfig{}, logger.NewDefault())
+	manager.ctx = context.Background()
+
+	if err := manager.ShutdownNow("user request"); !errors.Is(err, ErrNoServer) {
+		t.Fatalf("ShutdownNow() error = %v, want ErrNoServer", err)
+	}
+	if serverManager.destroyServerCalled {
+		t.Error("ServerManager.DestroyServer() should not be called without a running server")
+	}
+
+	// A failed shutdown must not block the next one
+	serverManager.servers = []*server.ServerInfo{{ID: "server-123", Name: "dockbridge-test", Status: server.StatusRunning}}
+	if err := manager.ShutdownNow("user request"); err != nil {
+		t.Fatalf("ShutdownNow() error = %v", err)
+	}
+	if serverManager.destroyedServerID != "server-123" {
+		t.Errorf("destroyed server = %q, want server-123", serverManager.destroyedServerID)
+	}
+}
+
+func TestManager_ShutdownServerRunsCallback(t *testing.T) {
+	serverManager := &MockServerManager{}
+
+	manager := NewManager(&MockActivityTracker{}, serverManager, &config.ActivityConfig{}, logger.NewDefault())
+	manager.ctx = context.Background()
+	shutdowns := 0
+	manager.SetOnShutdown(func() { shutdowns++ })
+
+	if err := manager.shutdownServer("idle timeout"); !errors.Is(err, ErrNoServer) {
+		t.Fatalf("shutdownServer() error = %v, want ErrNoServer", err)
+	}
+	if shutdowns != 0 {
+		t.Errorf("Expected no shutdown callback without a server, got %d", shutdowns)
+	}
+
+	serverManager.servers = []*server.ServerInfo{{ID: "server-123", Name: "dockbridge-test", Status: server.StatusRunning}}
+	if err := manager.shutdownServer("idle timeout"); err != nil {
+		t.Fatalf("shutdownServer() error = %v", err)
+	}
+	if shutdowns != 1 {
+		t.Errorf("Expected the shutdown callback to run once, got %d", shutdowns)
+	}
+}
+
+func TestManager_ShutdownServerPowersOff(t *testing.T) {
+	serverManager := &MockServerManager{
+		servers: []*server.ServerInfo{{
+			ID:     "server-123",
+			Name:   "dockbridge-test",
+			Status: server.StatusRunning,
+		}},
+	}
+
+	manager := NewManager(&MockActivityTracker{}, serverManager, &config.ActivityConfig{LifecyclePolicy: config.LifecyclePolicyPowerOff}, logger.NewDefault())
+	manager.ctx = context.Background()
+	shutdowns := 0
+	manager.SetOnShutdown(func() { shutdowns++ })
+
+	if err := manager.shutdownServer("idle timeout"); err != nil {
+		t.Fatalf("shutdownServer() error = %v", err)
+	}
+
+	if serverManager.stoppedServerID != "server-123" {
+		t.Errorf("Expected server-123 to be stopped, got '%s'", serverManager.stoppedServerID)
+	}
+	if serverManager.destroyServerCalled {
+		t.Error("ServerManager.DestroyServer() should NOT have been called")
+	}
+	if shutdowns != 1 {
+		t.Errorf("Expected the shutdown callback to run once, got %d", shutdowns)
+	}
+}
+
+func TestManager_ShutdownServerPowerOffFallsBackToDestroy(t *testing.T) {
+	serverManager := &MockServerManager{
+		servers: []*server.ServerInfo{{
+			ID:     "server-123",
+			Name:   "dockbridge-test",
+			Status: server.StatusRunning,
+		}},
+		stopErr: errors.New("provider does not support stopping servers"),
+	}
+
+	manager := NewManager(&MockActivityTracker{}, serverManager, &config.ActivityConfig{LifecyclePolicy: config.LifecyclePolicyPowerOff}, logger.NewDefault())
+	manager.ctx = context.Background()
+	shutdowns := 0
+	manager.SetOnShutdown(func() { shutdowns++ })
+
+	if err := manager.shutdownServer("idle timeout"); err != nil {
+		t.Fatalf("shutdownServer() error = %v", err)
+	}
+
+	if serverManager.destroyedServerID != "server-123" {
+		t.Errorf("Expected server-123 to be destroyed, got '%s'", serverManager.destroyedServerID)
+	}
+	if shutdowns != 1 {
+		t.Errorf("Expected the shutdown callback to run once, got %d", shutdowns)
+	}
+}
@@ -0,0 +1,22 @@
+// Package localsocket provides the local endpoints Docker clients connect to: Unix
+// sockets, and named pipes on Windows.
+package localsocket
+
+import "strings"
+
+// DefaultPipe is the named pipe the daemon listens on by default on Windows
+const DefaultPipe = "//./pipe/dockbridge"
+
+// IsNamedPipe reports whether path names a Windows named pipe, written as
+// //./pipe/<name> or \\.\pipe\<name>
+func IsNamedPipe(path string) bool {
+	return strings.HasPrefix(path, "//./pipe/") || strings.HasPrefix(path, `\\.\pipe\`)
+}
+
+// DockerHost returns the DOCKER_HOST URL of a local endpoint
+func DockerHost(path string) string {
+	if IsNamedPipe(path) {
+		return "npipe://" + strings.ReplaceAll(path, `\`, "/")
+	}
+	return "unix://" + path
+}
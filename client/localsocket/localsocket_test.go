@@ -0,0 +1,29 @@
+package localsocket
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNamedPipe(t *testing.T) {
+	assert.True(t, IsNamedPipe("//./pipe/dockbridge"))
+	assert.True(t, IsNamedPipe(`\\.\pipe\dockbridge`))
+	assert.False(t, IsNamedPipe("/tmp/dockbridge.sock"))
+	assert.False(t, IsNamedPipe("//server/share/pipe"))
+}
+
+func TestDockerHost(t *testing.T) {
+	assert.Equal(t, "unix:///tmp/dockbridge.sock", DockerHost("/tmp/dockbridge.sock"))
+	assert.Equal(t, "npipe:////./pipe/dockbridge", DockerHost("//./pipe/dockbridge"))
+	assert.Equal(t, "npipe:////./pipe/dockbridge", DockerHost(`\\.\pipe\dockbridge`))
+}
+
+func TestListenPipeUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("named pipes are supported on Windows")
+	}
+	_, err := ListenPipe(DefaultPipe)
+	assert.ErrorContains(t, err, "only supported on Windows")
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package localsocket
+
+import (
+	"fmt"
+	"net"
+)
+
+// ListenPipe is not supported on this platform
+func ListenPipe(path string) (net.Listener, error) {
+	return nil, fmt.Errorf("named pipe %s: named pipes are only supported on Windows", path)
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package localsocket
+
+import (
+	"fmt"
+	"net"
+	"os/user"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// ListenPipe listens on a named pipe that only the current user, administrators and
+// the system can open, like the pipe of Docker Desktop
+func ListenPipe(path string) (net.Listener, error) {
+	current, err := user.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the current user: %w", err)
+	}
+
+	// On Windows the user ID is the user's security identifier
+	config := &winio.PipeConfig{
+		SecurityDescriptor: fmt.Sprintf("D:P(A;;GA;;;BA)(A;;GA;;;SY)(A;;GA;;;%s)", current.Uid),
+		InputBufferSize:    65536,
+		OutputBufferSize:   65536,
+	}
+	listener, err := winio.ListenPipe(path, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on named pipe %s: %w", path, err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,233 @@
+// Package metrics exposes Prometheus metrics of the client daemons on an opt-in HTTP
+// endpoint, so a local Prometheus can alert on tunnel problems or a remote server that
+// is about to self-destruct.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes all metric names
+const namespace = "dockbridge"
+
+// sampleTimeout bounds how long a scrape waits for a daemon's snapshot
+const sampleTimeout = 5 * time.Second
+
+// Snapshot is the state of a daemon sampled on every scrape
+type Snapshot struct {
+	// TunnelSent and TunnelReceived are the bytes moved over the tunnel since start
+	TunnelSent     uint64
+	TunnelReceived uint64
+	// ActiveForwards is the number of port and socket forwards
+	ActiveForwards int
+	// KeepAlive is the keep-alive state reported by the connected server; nil when no
+	// server is connected or it could not be reached
+	KeepAlive *KeepAliveStatus
+}
+
+// KeepAliveStatus is the keep-alive state of a remote server
+type KeepAliveStatus struct {
+	SinceHeartbeat    time.Duration
+	UntilSelfDestruct time.Duration
+}
+
+// Source is a daemon whose state is sampled on every scrape.
+// docker.DockBridgeDaemon satisfies this interface.
+type Source interface {
+	MetricsSnapshot(ctx context.Context) Snapshot
+}
+
+// Registry holds the metrics of all daemons served on one endpoint; every metric is
+// labelled with the daemon's context ("" is the default context)
+type Registry struct {
+	registry *prometheus.Registry
+
+	requests     *prometheus.CounterVec
+	reconnects   *prometheus.CounterVec
+	provisioning *prometheus.HistogramVec
+
+	mu      sync.Mutex
+	sources map[string]Source
+
+	tunnelBytes       *prometheus.Desc
+	activeForwards    *prometheus.Desc
+	sinceHeartbeat    *prometheus.Desc
+	untilSelfDestruct *prometheus.Desc
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "docker_requests_total",
+			Help:      "Docker API connections proxied to the remote daemon or answered locally.",
+		}, []string{"context", "source"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ssh_reconnects_total",
+			Help:      "Attempts to restore a dropped SSH connection, by result.",
+		}, []string{"context", "result"}),
+		provisioning: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "provisioning_duration_seconds",
+			Help:      "Time until a new or resumed remote server was ready for Docker commands, by result.",
+			Buckets:   []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300},
+		}, []string{"context", "result"}),
+		sources: make(map[string]Source),
+		tunnelBytes: prometheus.NewDesc(namespace+"_tunnel_bytes_total",
+			"Bytes moved over the SSH tunnel, by direction.", []string{"context", "direction"}, nil),
+		activeForwards: prometheus.NewDesc(namespace+"_port_forwards_active",
+			"Port and socket forwards of remote containers.", []string{"context"}, nil),
+		sinceHeartbeat: prometheus.NewDesc(namespace+"_server_seconds_since_heartbeat",
+			"Seconds since the remote server received the last keep-alive heartbeat.", []string{"context"}, nil),
+		untilSelfDestruct: prometheus.NewDesc(namespace+"_server_seconds_until_self_destruct",
+			"Seconds until the remote server destroys itself without another heartbeat.", []string{"context"}, nil),
+	}
+
+	r.registry.MustRegister(r.requests, r.reconnects, r.provisioning, r,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return r
+}
+
+// Register adds a daemon's state to the registry and returns the recorder for its events.
+// A nil registry returns a nil recorder, which records nothing.
+func (r *Registry) Register(contextName string, source Source) *Recorder {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[contextName] = source
+	return &Recorder{registry: r, context: contextName}
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+// Describe implements prometheus.Collector for the sampled daemon state
+func (r *Registry) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.tunnelBytes
+	ch <- r.activeForwards
+	ch <- r.sinceHeartbeat
+	ch <- r.untilSelfDestruct
+}
+
+// Collect implements prometheus.Collector by sampling every daemon
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	r.mu.Lock()
+	sources := make(map[string]Source, len(r.sources))
+	for name, source := range r.sources {
+		sources[name] = source
+	}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), sampleTimeout)
+	defer cancel()
+
+	for name, source := range sources {
+		snapshot := source.MetricsSnapshot(ctx)
+		ch <- prometheus.MustNewConstMetric(r.tunnelBytes, prometheus.CounterValue, float64(snapshot.TunnelSent), name, "sent")
+		ch <- prometheus.MustNewConstMetric(r.tunnelBytes, prometheus.CounterValue, float64(snapshot.TunnelReceived), name, "received")
+		ch <- prometheus.MustNewConstMetric(r.activeForwards, prometheus.GaugeValue, float64(snapshot.ActiveForwards), name)
+		if snapshot.KeepAlive != nil {
+			ch <- prometheus.MustNewConstMetric(r.sinceHeartbeat, prometheus.GaugeValue, snapshot.KeepAlive.SinceHeartbeat.Seconds(), name)
+			ch <- prometheus.MustNewConstMetric(r.untilSelfDestruct, prometheus.GaugeValue, max(snapshot.KeepAlive.UntilSelfDestruct.Seconds(), 0), name)
+		}
+	}
+}
+
+// Recorder records the events of one daemon; a nil recorder records nothing
+type Recorder struct {
+	registry *Registry
+	context  string
+}
+
+// RequestProxied counts a Docker API connection; source is "remote" or "cache"
+func (r *Recorder) RequestProxied(source string) {
+	if r == nil {
+		return
+	}
+	r.registry.requests.WithLabelValues(r.context, source).Inc()
+}
+
+// Reconnected counts an attempt to restore the SSH connection; result is "restored" or "failed"
+func (r *Recorder) Reconnected(result string) {
+	if r == nil {
+		return
+	}
+	r.registry.reconnects.WithLabelValues(r.context, result).Inc()
+}
+
+// Provisioned records how long it took until a server was ready; result is "ready" or "failed"
+func (r *Recorder) Provisioned(result string, duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.registry.provisioning.WithLabelValues(r.context, result).Observe(duration.Seconds())
+}
+
+// Server serves a registry on /metrics
+type Server struct {
+	registry *Registry
+	logger   logger.LoggerInterface
+	server   *http.Server
+}
+
+// NewServer creates a metrics server for registry
+func NewServer(registry *Registry, logger logger.LoggerInterface) *Server {
+	return &Server{registry: registry, logger: logger}
+}
+
+// Start listens on addr and serves /metrics in the background
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.registry.Handler())
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Error("Metrics server stopped")
+		}
+	}()
+
+	s.logger.WithFields(map[string]any{
+		"address": listener.Addr().String(),
+	}).Info("Metrics endpoint listening")
+	return nil
+}
+
+// Stop stops serving
+func (s *Server) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+}
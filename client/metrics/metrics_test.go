@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource Snapshot
+
+func (s staticSource) MetricsSnapshot(context.Context) Snapshot {
+	return Snapshot(s)
+}
+
+// scrape returns the exposition text of registry
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestRegistry_Collect(t *testing.T) {
+	registry := NewRegistry()
+	recorder := registry.Register("", staticSource{
+		TunnelSent:     100,
+		TunnelReceived: 250,
+		ActiveForwards: 3,
+		KeepAlive:      &KeepAliveStatus{SinceHeartbeat: 20 * time.Second, UntilSelfDestruct: -time.Second},
+	})
+	registry.Register("gpu", staticSource{})
+
+	recorder.RequestProxied("remote")
+	recorder.RequestProxied("remote")
+	recorder.RequestProxied("cache")
+	recorder.Reconnected("restored")
+	recorder.Provisioned("ready", 42*time.Second)
+
+	body := scrape(t, registry)
+	assert.Contains(t, body, `dockbridge_docker_requests_total{context="",source="remote"} 2`)
+	assert.Contains(t, body, `dockbridge_docker_requests_total{context="",source="cache"} 1`)
+	assert.Contains(t, body, `dockbridge_ssh_reconnects_total{context="",result="restored"} 1`)
+	assert.Contains(t, body, `dockbridge_provisioning_duration_seconds_sum{context="",result="ready"} 42`)
+	assert.Contains(t, body, `dockbridge_tunnel_bytes_total{context="",direction="received"} 250`)
+	assert.Contains(t, body, `dockbridge_port_forwards_active{context=""} 3`)
+	assert.Contains(t, body, `dockbridge_port_forwards_active{context="gpu"} 0`)
+	assert.Contains(t, body, `dockbridge_server_seconds_since_heartbeat{context=""} 20`)
+	assert.Contains(t, body, `dockbridge_server_seconds_until_self_destruct{context=""} 0`)
+	// Contexts without a connected server report no keep-alive state
+	assert.NotContains(t, body, `dockbridge_server_seconds_since_heartbeat{context="gpu"}`)
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var registry *Registry
+	recorder := registry.Register("", staticSource{})
+	assert.Nil(t, recorder)
+
+	assert.NotPanics(t, func() {
+		recorder.RequestProxied("remote")
+		recorder.Reconnected("failed")
+		recorder.Provisioned("failed", time.Second)
+	})
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(NewRegistry(), logger.NewDefault())
+	require.NoError(t, server.Start("127.0.0.1:0"))
+	defer server.Stop()
+
+	assert.Error(t, NewServer(NewRegistry(), logger.NewDefault()).Start("invalid"))
+}
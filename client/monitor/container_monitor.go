@@ -3,11 +3,15 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/pkg/errors"
 )
 
@@ -32,8 +36,25 @@ type ContainerEventHandler interface {
 	OnContainerCreated(container *ContainerInfo) error
 	OnContainerStopped(containerID string) error
 	OnContainerRemoved(containerID string) error
+
+	// OnContainerHealthChanged is called when the health check of a running container
+	// changes its status
+	OnContainerHealthChanged(containerID string, health HealthStatus) error
+
+	// OnContainerRestarted is called after a container was restarted, with its current
+	// info; published host ports may have changed
+	OnContainerRestarted(container *ContainerInfo) error
 }
 
+// HealthStatus is the status of a container's health check
+type HealthStatus string
+
+const (
+	HealthStarting  HealthStatus = "starting"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
 // ContainerInfo represents container information for monitoring
 type ContainerInfo struct {
 	ID      string            `json:"id"`
@@ -43,6 +64,24 @@ type ContainerInfo struct {
 	Ports   []PortMapping     `json:"ports"`
 	Labels  map[string]string `json:"labels"`
 	Created time.Time         `json:"created"`
+	Health  HealthStatus      `json:"health,omitempty"`
+}
+
+// Labels docker compose sets on the containers of a project
+const (
+	ComposeProjectLabel = "com.docker.compose.project"
+	ComposeServiceLabel = "com.docker.compose.service"
+)
+
+// ComposeProject returns the docker compose project of the container, or an empty
+// string when compose did not create it
+func (c *ContainerInfo) ComposeProject() string {
+	return c.Labels[ComposeProjectLabel]
+}
+
+// ComposeService returns the docker compose service of the container
+func (c *ContainerInfo) ComposeService() string {
+	return c.Labels[ComposeServiceLabel]
 }
 
 // PortMapping represents a container port mapping
@@ -57,6 +96,7 @@ type PortMapping struct {
 type ContainerAPIClient interface {
 	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
 	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
 }
 
 // containerMonitorImpl implements ContainerMonitor
@@ -64,7 +104,7 @@ type containerMonitorImpl struct {
 	dockerClient ContainerAPIClient
 	logger       logger.LoggerInterface
 
-	// Configuration
+	// Configuration; polling is only used while the Docker events stream is down
 	pollingInterval time.Duration
 
 	// Event handlers
@@ -102,7 +142,9 @@ func (cm *containerMonitorImpl) Start(ctx context.Context) error {
 	cm.ctx, cm.cancel = context.WithCancel(ctx)
 	cm.running = true
 
-	// Initialize known containers state
+	// Initialize known containers state; the events stream replays anything that
+	// happens from here on
+	since := time.Now()
 	if err := cm.initializeKnownContainers(); err != nil {
 		cm.logger.WithFields(map[string]any{
 			"error": err.Error(),
@@ -113,7 +155,7 @@ func (cm *containerMonitorImpl) Start(ctx context.Context) error {
 	cm.wg.Add(1)
 	go func() {
 		defer cm.wg.Done()
-		cm.monitorContainers()
+		cm.monitorContainers(since)
 	}()
 
 	cm.logger.WithFields(map[string]any{
@@ -127,20 +169,21 @@ func (cm *containerMonitorImpl) Start(ctx context.Context) error {
 // Stop stops the container monitor
 func (cm *containerMonitorImpl) Stop() error {
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
-
 	if !cm.running {
+		cm.mu.Unlock()
 		return nil
 	}
-
 	cm.cancel()
 	cm.running = false
+	cm.mu.Unlock()
 
-	// Wait for monitoring goroutine to finish
+	// Wait for monitoring goroutine to finish; it takes the lock to dispatch events
 	cm.wg.Wait()
 
 	// Clear state
+	cm.mu.Lock()
 	cm.knownContainers = make(map[string]*ContainerInfo)
+	cm.mu.Unlock()
 
 	cm.logger.Info("Container monitor stopped")
 	return nil
@@ -227,21 +270,229 @@ func (cm *containerMonitorImpl) initializeKnownContainers() error {
 	return nil
 }
 
-// monitorContainers runs the main monitoring loop
-func (cm *containerMonitorImpl) monitorContainers() {
-	ticker := time.NewTicker(cm.pollingInterval)
-	defer ticker.Stop()
+// monitorContainers follows the Docker events stream from since. While the stream is
+// down, containers are polled every polling interval until it can be re-established.
+func (cm *containerMonitorImpl) monitorContainers(since time.Time) {
+	for {
+		resubscribed := time.Now()
+		err := cm.watchEvents(since)
+		if cm.ctx.Err() != nil {
+			return
+		}
+
+		cm.logger.WithFields(map[string]any{
+			"error":            err.Error(),
+			"polling_interval": cm.pollingInterval,
+		}).Warn("Docker events stream dropped, polling containers until it is restored")
+
+		// Catch up on changes the stream missed, then replay events from this poll on
+		since = time.Now()
+		if err := cm.checkContainerChanges(); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Error("Error checking container changes")
+		}
+
+		// A stream that dropped right away is retried after the polling interval
+		if time.Since(resubscribed) < cm.pollingInterval {
+			select {
+			case <-cm.ctx.Done():
+				return
+			case <-time.After(cm.pollingInterval):
+			}
+		}
+	}
+}
+
+// watchEvents dispatches container start, stop, die, destroy, restart and health events
+// from since until the stream fails or the monitor stops
+func (cm *containerMonitorImpl) watchEvents(since time.Time) error {
+	ctx, cancel := context.WithCancel(cm.ctx)
+	defer cancel()
+
+	messages, errs := cm.dockerClient.Events(ctx, events.ListOptions{
+		Since: strconv.FormatInt(since.Unix(), 10),
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("event", string(events.ActionStart)),
+			filters.Arg("event", string(events.ActionStop)),
+			filters.Arg("event", string(events.ActionDie)),
+			filters.Arg("event", string(events.ActionDestroy)),
+			filters.Arg("event", string(events.ActionRestart)),
+			filters.Arg("event", string(events.ActionHealthStatus)),
+		),
+	})
 
 	for {
 		select {
 		case <-cm.ctx.Done():
-			return
-		case <-ticker.C:
-			if err := cm.checkContainerChanges(); err != nil {
-				cm.logger.WithFields(map[string]any{
-					"error": err.Error(),
-				}).Error("Error checking container changes")
+			return nil
+		case err := <-errs:
+			if err == nil {
+				err = errors.New("events stream closed")
+			}
+			return err
+		case message, ok := <-messages:
+			if !ok {
+				return errors.New("events stream closed")
 			}
+			cm.handleEvent(message)
+		}
+	}
+}
+
+// handleEvent updates the known containers for a container event and notifies handlers
+func (cm *containerMonitorImpl) handleEvent(message events.Message) {
+	containerID := message.Actor.ID
+
+	// Health events carry the new status in the action, e.g. "health_status: unhealthy"
+	if status, ok := strings.CutPrefix(string(message.Action), string(events.ActionHealthStatus)+":"); ok {
+		cm.handleHealthChanged(containerID, HealthStatus(strings.TrimSpace(status)))
+		return
+	}
+
+	switch message.Action {
+	case events.ActionStart:
+		// Published ports are only known once the container runs
+		info, err := cm.GetContainer(cm.ctx, containerID)
+		if err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": containerID,
+				"error":        err.Error(),
+			}).Warn("Failed to inspect started container")
+			return
+		}
+
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+		if _, exists := cm.knownContainers[containerID]; exists {
+			return
+		}
+		cm.knownContainers[containerID] = info
+		cm.logger.WithFields(map[string]any{
+			"container_id":   containerID,
+			"container_name": info.Name,
+			"image":          info.Image,
+		}).Debug("Container started")
+		cm.notifyCreated(info)
+
+	case events.ActionStop, events.ActionDie:
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+		if _, exists := cm.knownContainers[containerID]; !exists {
+			return
+		}
+		delete(cm.knownContainers, containerID)
+		cm.logger.WithFields(map[string]any{
+			"container_id": containerID,
+			"event":        string(message.Action),
+		}).Debug("Container stopped")
+		cm.notifyStopped(containerID)
+
+	case events.ActionDestroy:
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+		delete(cm.knownContainers, containerID)
+		cm.logger.WithFields(map[string]any{
+			"container_id": containerID,
+		}).Debug("Container removed")
+		cm.notifyRemoved(containerID)
+
+	case events.ActionRestart:
+		info, err := cm.GetContainer(cm.ctx, containerID)
+		if err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": containerID,
+				"error":        err.Error(),
+			}).Warn("Failed to inspect restarted container")
+			return
+		}
+
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+		cm.knownContainers[containerID] = info
+		cm.logger.WithFields(map[string]any{
+			"container_id":   containerID,
+			"container_name": info.Name,
+		}).Debug("Container restarted")
+		cm.notifyRestarted(info)
+	}
+}
+
+// handleHealthChanged records the health of a container and notifies handlers
+func (cm *containerMonitorImpl) handleHealthChanged(containerID string, health HealthStatus) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if known, exists := cm.knownContainers[containerID]; exists {
+		if known.Health == health {
+			return
+		}
+		// Handlers may hold on to the previous info
+		updated := *known
+		updated.Health = health
+		cm.knownContainers[containerID] = &updated
+	}
+
+	cm.logger.WithFields(map[string]any{
+		"container_id": containerID,
+		"health":       string(health),
+	}).Debug("Container health changed")
+
+	for _, handler := range cm.handlers {
+		if err := handler.OnContainerHealthChanged(containerID, health); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": containerID,
+				"error":        err.Error(),
+			}).Error("Handler failed to process container health event")
+		}
+	}
+}
+
+// notifyRestarted notifies handlers about a restarted container (must be called with lock held)
+func (cm *containerMonitorImpl) notifyRestarted(container *ContainerInfo) {
+	for _, handler := range cm.handlers {
+		if err := handler.OnContainerRestarted(container); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": container.ID,
+				"error":        err.Error(),
+			}).Error("Handler failed to process container restarted event")
+		}
+	}
+}
+
+// notifyCreated notifies handlers about a running container (must be called with lock held)
+func (cm *containerMonitorImpl) notifyCreated(container *ContainerInfo) {
+	for _, handler := range cm.handlers {
+		if err := handler.OnContainerCreated(container); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": container.ID,
+				"error":        err.Error(),
+			}).Error("Handler failed to process container created event")
+		}
+	}
+}
+
+// notifyStopped notifies handlers about a stopped container (must be called with lock held)
+func (cm *containerMonitorImpl) notifyStopped(containerID string) {
+	for _, handler := range cm.handlers {
+		if err := handler.OnContainerStopped(containerID); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": containerID,
+				"error":        err.Error(),
+			}).Error("Handler failed to process container stopped event")
+		}
+	}
+}
+
+// notifyRemoved notifies handlers about a removed container (must be called with lock held)
+func (cm *containerMonitorImpl) notifyRemoved(containerID string) {
+	for _, handler := range cm.handlers {
+		if err := handler.OnContainerRemoved(containerID); err != nil {
+			cm.logger.WithFields(map[string]any{
+				"container_id": containerID,
+				"error":        err.Error(),
+			}).Error("Handler failed to process container removed event")
 		}
 	}
 }
@@ -273,14 +524,7 @@ func (cm *containerMonitorImpl) checkContainerChanges() error {
 			}).Debug("New container detected")
 
 			// Notify handlers about container creation
-			for _, handler := range cm.handlers {
-				if err := handler.OnContainerCreated(container); err != nil {
-					cm.logger.WithFields(map[string]any{
-						"container_id": containerID,
-						"error":        err.Error(),
-					}).Error("Handler failed to process container created event")
-				}
-			}
+			cm.notifyCreated(container)
 
 			cm.knownContainers[containerID] = container
 		}
@@ -303,14 +547,7 @@ func (cm *containerMonitorImpl) checkContainerChanges() error {
 					"container_id": containerID,
 				}).Debug("Container was removed")
 
-				for _, handler := range cm.handlers {
-					if err := handler.OnContainerRemoved(containerID); err != nil {
-						cm.logger.WithFields(map[string]any{
-							"container_id": containerID,
-							"error":        err.Error(),
-						}).Error("Handler failed to process container removed event")
-					}
-				}
+				cm.notifyRemoved(containerID)
 			} else if !containerJSON.State.Running {
 				// Container exists but is not running - it was stopped
 				cm.logger.WithFields(map[string]any{
@@ -318,14 +555,7 @@ func (cm *containerMonitorImpl) checkContainerChanges() error {
 					"status":       containerJSON.State.Status,
 				}).Debug("Container was stopped")
 
-				for _, handler := range cm.handlers {
-					if err := handler.OnContainerStopped(containerID); err != nil {
-						cm.logger.WithFields(map[string]any{
-							"container_id": containerID,
-							"error":        err.Error(),
-						}).Error("Handler failed to process container stopped event")
-					}
-				}
+				cm.notifyStopped(containerID)
 			}
 
 			delete(cm.knownContainers, containerID)
@@ -409,6 +639,11 @@ func (cm *containerMonitorImpl) convertInspectToContainerInfo(resp container.Ins
 		createdTime = time.Now() // Fallback to current time
 	}
 
+	var health HealthStatus
+	if resp.State != nil && resp.State.Health != nil {
+		health = HealthStatus(resp.State.Health.Status)
+	}
+
 	return &ContainerInfo{
 		ID:      resp.ID,
 		Name:    name,
@@ -417,5 +652,6 @@ func (cm *containerMonitorImpl) convertInspectToContainerInfo(resp container.Ins
 		Ports:   ports,
 		Labels:  resp.Config.Labels,
 		Created: createdTime,
+		Health:  health,
 	}, nil
 }
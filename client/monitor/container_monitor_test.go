@@ -3,11 +3,13 @@ package monitor
 import (
 	"context"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -29,6 +31,16 @@ func (m *MockDockerClient) ContainerInspect(ctx context.Context, containerID str
 	return args.Get(0).(container.InspectResponse), args.Error(1)
 }
 
+func (m *MockDockerClient) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	args := m.Called(ctx, options)
+	return args.Get(0).(chan events.Message), args.Get(1).(chan error)
+}
+
+// eventStream returns the channels of a mocked Docker events stream
+func eventStream() (chan events.Message, chan error) {
+	return make(chan events.Message), make(chan error, 1)
+}
+
 // createTestLogger creates a simple test logger that discards output
 func createTestLogger() logger.LoggerInterface {
 	testLogger := logger.NewDefault()
@@ -56,6 +68,16 @@ func (m *MockEventHandler) OnContainerRemoved(containerID string) error {
 	return args.Error(0)
 }
 
+func (m *MockEventHandler) OnContainerHealthChanged(containerID string, health HealthStatus) error {
+	args := m.Called(containerID, health)
+	return args.Error(0)
+}
+
+func (m *MockEventHandler) OnContainerRestarted(container *ContainerInfo) error {
+	args := m.Called(container)
+	return args.Error(0)
+}
+
 // Test helper functions
 func createTestContainer(id, name, image string, ports []container.Port) container.Summary {
 	return container.Summary{
@@ -101,8 +123,10 @@ func TestContainerMonitor_BasicLifecycle(t *testing.T) {
 	// Test initial state
 	assert.False(t, monitor.(*containerMonitorImpl).running)
 
-	// Mock initial container list (empty)
+	// Mock initial container list (empty) and an idle events stream
 	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{}, nil)
+	messages, errs := eventStream()
+	mockClient.On("Events", mock.Anything, mock.Anything).Return(messages, errs)
 
 	// Start monitor
 	ctx := t.Context()
@@ -248,3 +272,157 @@ func TestContainerMonitor_SetPollingInterval(t *testing.T) {
 	impl := monitor.(*containerMonitorImpl)
 	assert.Equal(t, newInterval, impl.pollingInterval)
 }
+
+func TestContainerMonitor_EventsStream(t *testing.T) {
+	mockClient := &MockDockerClient{}
+	monitor := NewContainerMonitor(mockClient, createTestLogger())
+
+	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{}, nil)
+	messages, errs := eventStream()
+	mockClient.On("Events", mock.Anything, mock.MatchedBy(func(opts events.ListOptions) bool {
+		return opts.Since != "" && opts.Filters.ExactMatch("type", string(events.ContainerEventType)) &&
+			opts.Filters.ExactMatch("event", string(events.ActionDestroy))
+	})).Return(messages, errs)
+	mockClient.On("ContainerInspect", mock.Anything, "container1").Return(createTestContainerJSON("container1", "nginx", "nginx:latest", nat.PortMap{
+		"80/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "8080"}},
+	}), nil)
+
+	created := make(chan *ContainerInfo, 1)
+	stopped := make(chan string, 1)
+	removed := make(chan string, 1)
+	handler := &MockEventHandler{}
+	handler.On("OnContainerCreated", mock.Anything).Run(func(args mock.Arguments) {
+		created <- args.Get(0).(*ContainerInfo)
+	}).Return(nil)
+	handler.On("OnContainerStopped", mock.Anything).Run(func(args mock.Arguments) {
+		stopped <- args.String(0)
+	}).Return(nil)
+	handler.On("OnContainerRemoved", mock.Anything).Run(func(args mock.Arguments) {
+		removed <- args.String(0)
+	}).Return(nil)
+	require.NoError(t, monitor.RegisterContainerEventHandler(handler))
+
+	require.NoError(t, monitor.Start(t.Context()))
+	defer monitor.Stop()
+
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionStart, Actor: events.Actor{ID: "container1"}}
+	select {
+	case info := <-created:
+		assert.Equal(t, "container1", info.ID)
+		assert.Equal(t, 8080, info.Ports[0].HostPort)
+	case <-time.After(time.Second):
+		t.Fatal("start event was not dispatched")
+	}
+
+	// die and stop both arrive for a stopped container; handlers hear about it once
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionDie, Actor: events.Actor{ID: "container1"}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionStop, Actor: events.Actor{ID: "container1"}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionDestroy, Actor: events.Actor{ID: "container1"}}
+	select {
+	case id := <-stopped:
+		assert.Equal(t, "container1", id)
+	case <-time.After(time.Second):
+		t.Fatal("stop event was not dispatched")
+	}
+	select {
+	case id := <-removed:
+		assert.Equal(t, "container1", id)
+	case <-time.After(time.Second):
+		t.Fatal("destroy event was not dispatched")
+	}
+	handler.AssertNumberOfCalls(t, "OnContainerStopped", 1)
+}
+
+func TestContainerMonitor_PollsWhileEventsStreamIsDown(t *testing.T) {
+	mockClient := &MockDockerClient{}
+	monitor := NewContainerMonitor(mockClient, createTestLogger())
+	require.NoError(t, monitor.SetPollingInterval(10*time.Millisecond))
+
+	// The first listing initializes the monitor; the container shows up in the poll
+	// that follows the dropped stream
+	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{}, nil).Once()
+	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{
+		createTestContainer("container1", "nginx", "nginx:latest", nil),
+	}, nil)
+	dropped, droppedErrs := eventStream()
+	droppedErrs <- io.ErrUnexpectedEOF
+	mockClient.On("Events", mock.Anything, mock.Anything).Return(dropped, droppedErrs).Once()
+	messages, errs := eventStream()
+	resubscribed := make(chan struct{})
+	var once sync.Once
+	mockClient.On("Events", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		once.Do(func() { close(resubscribed) })
+	}).Return(messages, errs)
+
+	created := make(chan *ContainerInfo, 1)
+	handler := &MockEventHandler{}
+	handler.On("OnContainerCreated", mock.Anything).Run(func(args mock.Arguments) {
+		created <- args.Get(0).(*ContainerInfo)
+	}).Return(nil)
+	require.NoError(t, monitor.RegisterContainerEventHandler(handler))
+
+	require.NoError(t, monitor.Start(t.Context()))
+	defer monitor.Stop()
+
+	select {
+	case info := <-created:
+		assert.Equal(t, "container1", info.ID)
+	case <-time.After(time.Second):
+		t.Fatal("container was not picked up by polling")
+	}
+
+	// The stream is re-established after the polling interval
+	select {
+	case <-resubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("events stream was not re-established")
+	}
+}
+
+func TestContainerMonitor_HealthAndRestartEvents(t *testing.T) {
+	mockClient := &MockDockerClient{}
+	monitor := NewContainerMonitor(mockClient, createTestLogger())
+
+	mockClient.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{
+		createTestContainer("container1", "nginx", "nginx:latest", nil),
+	}, nil)
+	messages, errs := eventStream()
+	mockClient.On("Events", mock.Anything, mock.Anything).Return(messages, errs)
+	mockClient.On("ContainerInspect", mock.Anything, "container1").Return(createTestContainerJSON("container1", "nginx", "nginx:latest", nil), nil)
+
+	health := make(chan HealthStatus, 2)
+	restarted := make(chan *ContainerInfo, 1)
+	handler := &MockEventHandler{}
+	handler.On("OnContainerHealthChanged", "container1", mock.Anything).Run(func(args mock.Arguments) {
+		health <- args.Get(1).(HealthStatus)
+	}).Return(nil)
+	handler.On("OnContainerRestarted", mock.Anything).Run(func(args mock.Arguments) {
+		restarted <- args.Get(0).(*ContainerInfo)
+	}).Return(nil)
+	require.NoError(t, monitor.RegisterContainerEventHandler(handler))
+
+	require.NoError(t, monitor.Start(t.Context()))
+	defer monitor.Stop()
+
+	// Repeated health events with the same status are reported once
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionHealthStatusUnhealthy, Actor: events.Actor{ID: "container1"}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionHealthStatusUnhealthy, Actor: events.Actor{ID: "container1"}}
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionHealthStatusHealthy, Actor: events.Actor{ID: "container1"}}
+	for _, want := range []HealthStatus{HealthUnhealthy, HealthHealthy} {
+		select {
+		case got := <-health:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("health event was not dispatched")
+		}
+	}
+
+	messages <- events.Message{Type: events.ContainerEventType, Action: events.ActionRestart, Actor: events.Actor{ID: "container1"}}
+	select {
+	case info := <-restarted:
+		assert.Equal(t, "container1", info.ID)
+	case <-time.After(time.Second):
+		t.Fatal("restart event was not dispatched")
+	}
+	handler.AssertNumberOfCalls(t, "OnContainerHealthChanged", 2)
+}
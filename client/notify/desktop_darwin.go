@@ -0,0 +1,28 @@
+//go:build darwin
+
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// sendDesktopNotification shows a notification in Notification Center via osascript
+func sendDesktopNotification(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+
+	// #nosec G204 -- the script only embeds escaped string literals
+	cmd := exec.Command("osascript", "-e", script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript failed: %w: %s", err, out)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
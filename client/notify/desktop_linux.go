@@ -0,0 +1,23 @@
+//go:build linux
+
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sendDesktopNotification shows a notification through notify-send (libnotify)
+func sendDesktopNotification(title, message string) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("notify-send not found: %w", err)
+	}
+
+	// #nosec G204 -- title and message are passed as separate arguments, not through a shell
+	cmd := exec.Command(path, "--app-name=DockBridge", title, message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify-send failed: %w: %s", err, out)
+	}
+	return nil
+}
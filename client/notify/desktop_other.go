@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package notify
+
+import "errors"
+
+// sendDesktopNotification is not implemented on this platform
+func sendDesktopNotification(title, message string) error {
+	return errors.New("desktop notifications are not supported on this platform")
+}
@@ -0,0 +1,119 @@
+// Package notify delivers native desktop notifications for key lifecycle events
+// so users notice servers coming and going without watching the daemon logs.
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// Event identifies a notifiable lifecycle event
+type Event string
+
+const (
+	EventServerReady        Event = "server_ready"
+	EventProvisioningFailed Event = "provisioning_failed"
+	EventKeepAliveWarning   Event = "keepalive_warning"
+	EventSelfDestruct       Event = "self_destruct"
+	EventBudgetThreshold    Event = "budget_threshold"
+	EventTrafficWarning     Event = "traffic_warning"
+)
+
+// DefaultMinInterval is the minimum time between two notifications of the same event
+const DefaultMinInterval = time.Minute
+
+// Notifier delivers notifications for lifecycle events
+type Notifier interface {
+	// Notify shows a notification for event unless it is disabled or was shown very recently
+	Notify(event Event, title, message string)
+}
+
+// desktopNotifier implements Notifier using the platform notification service
+type desktopNotifier struct {
+	config      *config.DesktopNotificationsConfig
+	logger      logger.LoggerInterface
+	minInterval time.Duration
+
+	// send displays a notification (replaceable in tests)
+	send func(title, message string) error
+
+	mu       sync.Mutex
+	lastSent map[Event]time.Time
+}
+
+// NewDesktopNotifier creates a notifier that shows native desktop notifications
+func NewDesktopNotifier(cfg *config.DesktopNotificationsConfig, logger logger.LoggerInterface) Notifier {
+	if cfg == nil || !cfg.Enabled {
+		return NopNotifier{}
+	}
+	return &desktopNotifier{
+		config:      cfg,
+		logger:      logger,
+		minInterval: DefaultMinInterval,
+		send:        sendDesktopNotification,
+		lastSent:    make(map[Event]time.Time),
+	}
+}
+
+// Notify shows a desktop notification in the background
+func (n *desktopNotifier) Notify(event Event, title, message string) {
+	if !n.eventEnabled(event) || !n.claim(event) {
+		return
+	}
+
+	go func() {
+		if err := n.send(title, message); err != nil {
+			n.logger.WithFields(map[string]any{
+				"event": string(event),
+				"error": err.Error(),
+			}).Debug("Failed to show desktop notification")
+		}
+	}()
+}
+
+// claim records that event is being shown and reports whether enough time passed since the last one
+func (n *desktopNotifier) claim(event Event) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := n.lastSent[event]; ok && now.Sub(last) < n.minInterval {
+		return false
+	}
+	n.lastSent[event] = now
+	return true
+}
+
+// eventEnabled reports whether notifications for event are turned on
+func (n *desktopNotifier) eventEnabled(event Event) bool {
+	switch event {
+	case EventServerReady:
+		return n.config.ServerReady
+	case EventProvisioningFailed:
+		return n.config.ProvisioningFailed
+	case EventKeepAliveWarning:
+		return n.config.KeepAliveWarning
+	case EventSelfDestruct:
+		return n.config.SelfDestruct
+	case EventBudgetThreshold:
+		return n.config.BudgetThreshold
+	case EventTrafficWarning:
+		return n.config.TrafficWarning
+	default:
+		return false
+	}
+}
+
+// Show displays a desktop notification right away, regardless of the per-event settings
+func Show(title, message string) error {
+	return sendDesktopNotification(title, message)
+}
+
+// NopNotifier discards all notifications
+type NopNotifier struct{}
+
+// Notify does nothing
+func (NopNotifier) Notify(Event, string, string) {}
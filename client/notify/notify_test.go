@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNotifier(cfg *config.DesktopNotificationsConfig) (*desktopNotifier, chan string) {
+	sent := make(chan string, 10)
+	n := NewDesktopNotifier(cfg, logger.NewDefault()).(*desktopNotifier)
+	n.send = func(title, message string) error {
+		sent <- title
+		return nil
+	}
+	return n, sent
+}
+
+func receive(t *testing.T, sent chan string) (string, bool) {
+	t.Helper()
+	select {
+	case title := <-sent:
+		return title, true
+	case <-time.After(200 * time.Millisecond):
+		return "", false
+	}
+}
+
+func TestNewDesktopNotifierDisabled(t *testing.T) {
+	assert.IsType(t, NopNotifier{}, NewDesktopNotifier(nil, logger.NewDefault()))
+	assert.IsType(t, NopNotifier{}, NewDesktopNotifier(&config.DesktopNotificationsConfig{Enabled: false}, logger.NewDefault()))
+}
+
+func TestDesktopNotifierPerEventToggle(t *testing.T) {
+	n, sent := newTestNotifier(&config.DesktopNotificationsConfig{
+		Enabled:      true,
+		ServerReady:  true,
+		SelfDestruct: false,
+	})
+
+	n.Notify(EventSelfDestruct, "destroyed", "")
+	_, ok := receive(t, sent)
+	assert.False(t, ok, "disabled event should not be shown")
+
+	n.Notify(EventServerReady, "ready", "")
+	title, ok := receive(t, sent)
+	assert.True(t, ok)
+	assert.Equal(t, "ready", title)
+}
+
+func TestDesktopNotifierRateLimit(t *testing.T) {
+	n, sent := newTestNotifier(&config.DesktopNotificationsConfig{
+		Enabled:            true,
+		ProvisioningFailed: true,
+	})
+
+	n.Notify(EventProvisioningFailed, "first", "")
+	n.Notify(EventProvisioningFailed, "second", "")
+
+	title, ok := receive(t, sent)
+	assert.True(t, ok)
+	assert.Equal(t, "first", title)
+
+	_, ok = receive(t, sent)
+	assert.False(t, ok, "repeated event within the minimum interval should be suppressed")
+
+	n.minInterval = 0
+	n.Notify(EventProvisioningFailed, "third", "")
+	title, ok = receive(t, sent)
+	assert.True(t, ok)
+	assert.Equal(t, "third", title)
+}
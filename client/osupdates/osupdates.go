@@ -0,0 +1,132 @@
+// Package osupdates manages unattended OS upgrades on long-lived servers and
+// reboots them inside a configured window once they are idle.
+package osupdates
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// RebootStatusCommand prints "reboot-required" followed by the packages that need
+// it when the server has a pending reboot, and nothing otherwise
+const RebootStatusCommand = "if [ -f /var/run/reboot-required ]; then echo reboot-required; " +
+	"cat /var/run/reboot-required.pkgs 2>/dev/null; fi; true"
+
+// RebootCommand reboots the server without waiting for the SSH session to close
+const RebootCommand = "systemd-run --on-active=5 /bin/systemctl reboot"
+
+// RebootStatus describes whether a server needs a reboot to finish applying updates
+type RebootStatus struct {
+	Required bool
+	Packages []string
+}
+
+// ParseRebootStatus parses the output of RebootStatusCommand
+func ParseRebootStatus(output string) RebootStatus {
+	lines := strings.Fields(output)
+	if len(lines) == 0 || lines[0] != "reboot-required" {
+		return RebootStatus{}
+	}
+	return RebootStatus{Required: true, Packages: lines[1:]}
+}
+
+// String returns a short human-readable description
+func (s RebootStatus) String() string {
+	if !s.Required {
+		return "up to date, no reboot pending"
+	}
+	if len(s.Packages) == 0 {
+		return "reboot pending"
+	}
+	return fmt.Sprintf("reboot pending (%s)", strings.Join(s.Packages, ", "))
+}
+
+// Window is a daily time range in the client's local time; it may wrap past midnight
+type Window struct {
+	Start time.Duration // offset from midnight
+	End   time.Duration
+}
+
+// ParseWindow parses a window in "HH:MM-HH:MM" form
+func ParseWindow(s string) (Window, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid reboot window %q, expected HH:MM-HH:MM", s)
+	}
+
+	start, err := parseClock(startStr)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid reboot window %q: %w", s, err)
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid reboot window %q: %w", s, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid reboot window %q: start and end are equal", s)
+	}
+
+	return Window{Start: start, End: end}, nil
+}
+
+// Contains reports whether the wall-clock time of t falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// SetupScript returns a shell snippet for server provisioning that configures
+// unattended-upgrades. Reboots are never performed by unattended-upgrades itself;
+// the client schedules them so they can be coordinated with idle detection.
+// It returns an empty string when OS update management is disabled.
+func SetupScript(cfg *config.OSUpdatesConfig) string {
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+
+	origins := `    "${distro_id}:${distro_codename}-security";
+    "${distro_id}ESMApps:${distro_codename}-apps-security";
+    "${distro_id}ESM:${distro_codename}-infra-security";`
+	if !cfg.SecurityOnly {
+		origins += `
+    "${distro_id}:${distro_codename}";
+    "${distro_id}:${distro_codename}-updates";`
+	}
+
+	return `# Configure unattended OS upgrades (managed by DockBridge)
+echo "$(date): Configuring unattended-upgrades"
+DEBIAN_FRONTEND=noninteractive apt-get install -y unattended-upgrades
+cat > /etc/apt/apt.conf.d/20auto-upgrades << 'APTEOF'
+APT::Periodic::Update-Package-Lists "1";
+APT::Periodic::Unattended-Upgrade "1";
+APT::Periodic::AutocleanInterval "7";
+APTEOF
+cat > /etc/apt/apt.conf.d/52dockbridge-unattended-upgrades << 'APTEOF'
+Unattended-Upgrade::Allowed-Origins {
+` + origins + `
+};
+Unattended-Upgrade::Package-Blacklist {
+    "docker-ce";
+    "docker-ce-cli";
+    "containerd.io";
+};
+Unattended-Upgrade::Automatic-Reboot "false";
+APTEOF
+systemctl enable --now unattended-upgrades
+`
+}
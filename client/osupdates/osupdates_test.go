@@ -0,0 +1,61 @@
+package osupdates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRebootStatus(t *testing.T) {
+	assert.False(t, ParseRebootStatus("").Required)
+
+	status := ParseRebootStatus("reboot-required\nlinux-image-generic\nlibc6\n")
+	assert.True(t, status.Required)
+	assert.Equal(t, []string{"linux-image-generic", "libc6"}, status.Packages)
+	assert.Contains(t, status.String(), "linux-image-generic")
+}
+
+func TestParseWindow(t *testing.T) {
+	w, err := ParseWindow("03:00-05:30")
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Hour, w.Start)
+	assert.Equal(t, 5*time.Hour+30*time.Minute, w.End)
+
+	for _, invalid := range []string{"", "03:00", "3-5", "25:00-05:00", "04:00-04:00"} {
+		_, err := ParseWindow(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	w, _ := ParseWindow("03:00-05:00")
+	assert.True(t, w.Contains(at(3, 0)))
+	assert.True(t, w.Contains(at(4, 59)))
+	assert.False(t, w.Contains(at(5, 0)))
+	assert.False(t, w.Contains(at(2, 59)))
+
+	wrap, _ := ParseWindow("23:00-01:00")
+	assert.True(t, wrap.Contains(at(23, 30)))
+	assert.True(t, wrap.Contains(at(0, 30)))
+	assert.False(t, wrap.Contains(at(12, 0)))
+}
+
+func TestSetupScript(t *testing.T) {
+	assert.Empty(t, SetupScript(nil))
+	assert.Empty(t, SetupScript(&config.OSUpdatesConfig{Enabled: false}))
+
+	security := SetupScript(&config.OSUpdatesConfig{Enabled: true, SecurityOnly: true})
+	assert.Contains(t, security, "-security")
+	assert.Contains(t, security, `Automatic-Reboot "false"`)
+	assert.NotContains(t, security, "-updates")
+
+	all := SetupScript(&config.OSUpdatesConfig{Enabled: true, SecurityOnly: false})
+	assert.Contains(t, all, "-updates")
+}
@@ -0,0 +1,100 @@
+package osupdates
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+const (
+	// DefaultCheckInterval is how often the scheduler checks for a pending reboot
+	DefaultCheckInterval = 15 * time.Minute
+
+	// DefaultMinIdle is how long the client must be idle before a reboot is allowed
+	DefaultMinIdle = 15 * time.Minute
+)
+
+// RebootScheduler reboots a server with pending updates inside the reboot window,
+// but only while the client is idle so builds and sessions are never interrupted
+type RebootScheduler struct {
+	// Exec runs a command on the connected server; it must not connect or provision
+	Exec func(ctx context.Context, command string) ([]byte, error)
+
+	// IdleFor returns how long the client has been idle
+	IdleFor func() time.Duration
+
+	Window        Window
+	MinIdle       time.Duration
+	CheckInterval time.Duration
+	Logger        logger.LoggerInterface
+
+	// now returns the current time (replaceable in tests)
+	now func() time.Time
+}
+
+// Run checks periodically until ctx is cancelled
+func (s *RebootScheduler) Run(ctx context.Context) {
+	interval := s.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check reboots the server if a reboot is pending, the window is open and the client is idle.
+// It reports whether a reboot was triggered.
+func (s *RebootScheduler) check(ctx context.Context) bool {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	if !s.Window.Contains(now()) {
+		return false
+	}
+
+	minIdle := s.MinIdle
+	if minIdle <= 0 {
+		minIdle = DefaultMinIdle
+	}
+	if idle := s.IdleFor(); idle < minIdle {
+		s.Logger.WithFields(map[string]any{
+			"idle_for": idle,
+			"min_idle": minIdle,
+		}).Debug("Reboot window open but client is active, deferring reboot")
+		return false
+	}
+
+	output, err := s.Exec(ctx, RebootStatusCommand)
+	if err != nil {
+		// Not connected or the server is gone; nothing to do
+		return false
+	}
+
+	status := ParseRebootStatus(string(output))
+	if !status.Required {
+		return false
+	}
+
+	s.Logger.WithFields(map[string]any{
+		"packages": status.Packages,
+	}).Info("Rebooting idle server to finish applying OS updates")
+
+	if _, err := s.Exec(ctx, RebootCommand); err != nil {
+		s.Logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to schedule server reboot")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package osupdates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebootSchedulerCheck(t *testing.T) {
+	window, _ := ParseWindow("03:00-05:00")
+	inWindow := time.Date(2024, 1, 1, 4, 0, 0, 0, time.Local)
+	outsideWindow := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		idle         time.Duration
+		statusOutput string
+		expectReboot bool
+	}{
+		{"reboot pending, idle, in window", inWindow, time.Hour, "reboot-required\n", true},
+		{"outside window", outsideWindow, time.Hour, "reboot-required\n", false},
+		{"client active", inWindow, time.Minute, "reboot-required\n", false},
+		{"no reboot pending", inWindow, time.Hour, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []string
+			scheduler := &RebootScheduler{
+				Exec: func(ctx context.Context, command string) ([]byte, error) {
+					commands = append(commands, command)
+					if command == RebootStatusCommand {
+						return []byte(tt.statusOutput), nil
+					}
+					return nil, nil
+				},
+				IdleFor: func() time.Duration { return tt.idle },
+				Window:  window,
+				Logger:  logger.NewDefault(),
+				now:     func() time.Time { return tt.now },
+			}
+
+			rebooted := scheduler.check(context.Background())
+
+			assert.Equal(t, tt.expectReboot, rebooted)
+			assert.Equal(t, tt.expectReboot, len(commands) == 2 && commands[1] == RebootCommand)
+		})
+	}
+}
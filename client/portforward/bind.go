@@ -0,0 +1,46 @@
+package portforward
+
+import (
+	"net"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+)
+
+// DefaultBindAddress is the address forwards listen on unless configured otherwise
+const DefaultBindAddress = "127.0.0.1"
+
+// BindAddressLabel is the container label that overrides the bind address for a
+// container's forwards. Non-loopback values are only honored when
+// port_forward.allow_external is enabled.
+const BindAddressLabel = "dockbridge.bind_address"
+
+// bindAddressFor returns the local address the container's forwards should bind to.
+// Forwards never leave loopback unless external binding was explicitly allowed.
+func (pfm *portForwardManagerImpl) bindAddressFor(container *monitor.ContainerInfo) string {
+	address := pfm.config.BindAddress
+	if label, ok := container.Labels[BindAddressLabel]; ok {
+		address = label
+	}
+	if address == "" {
+		return DefaultBindAddress
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		pfm.logger.WithFields(map[string]any{
+			"container_id": container.ID,
+			"bind_address": address,
+		}).Warn("Ignoring invalid bind address, using loopback")
+		return DefaultBindAddress
+	}
+
+	if !ip.IsLoopback() && !pfm.config.AllowExternal {
+		pfm.logger.WithFields(map[string]any{
+			"container_id": container.ID,
+			"bind_address": address,
+		}).Warn("Refusing non-loopback bind address without port_forward.allow_external, using loopback")
+		return DefaultBindAddress
+	}
+
+	return address
+}
@@ -0,0 +1,44 @@
+package portforward
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindAddressFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		bindAddress   string
+		allowExternal bool
+		labels        map[string]string
+		expected      string
+	}{
+		{name: "default", expected: "127.0.0.1"},
+		{name: "global loopback", bindAddress: "::1", expected: "::1"},
+		{name: "global external without opt-in", bindAddress: "0.0.0.0", expected: "127.0.0.1"},
+		{name: "global external with opt-in", bindAddress: "0.0.0.0", allowExternal: true, expected: "0.0.0.0"},
+		{name: "label without opt-in", labels: map[string]string{BindAddressLabel: "192.168.1.5"}, expected: "127.0.0.1"},
+		{name: "label with opt-in", allowExternal: true, labels: map[string]string{BindAddressLabel: "192.168.1.5"}, expected: "192.168.1.5"},
+		{name: "invalid label", allowExternal: true, labels: map[string]string{BindAddressLabel: "lan"}, expected: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.PortForwardConfig{
+				Enabled:          true,
+				ConflictStrategy: config.ConflictStrategyIncrement,
+				MonitorInterval:  30 * time.Second,
+				BindAddress:      tt.bindAddress,
+				AllowExternal:    tt.allowExternal,
+			}
+			pfm := NewPortForwardManager(cfg, createTestLogger()).(*portForwardManagerImpl)
+
+			address := pfm.bindAddressFor(&monitor.ContainerInfo{ID: "abc", Labels: tt.labels})
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
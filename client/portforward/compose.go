@@ -0,0 +1,64 @@
+package portforward
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+)
+
+// projectAllowed reports whether the forwards of a container are created under the
+// configured compose project filter. Containers outside compose are always forwarded.
+func (pfm *portForwardManagerImpl) projectAllowed(container *monitor.ContainerInfo) bool {
+	project := container.ComposeProject()
+	if project == "" {
+		return true
+	}
+	filter := pfm.config.Projects
+	if matchesAny(filter.Deny, project) {
+		return false
+	}
+	return len(filter.Allow) == 0 || matchesAny(filter.Allow, project)
+}
+
+// matchesAny reports whether name matches one of the shell patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveProjectForwards closes all forwards of a docker compose project. They are
+// created again when the project's containers are started again.
+func (pfm *portForwardManagerImpl) RemoveProjectForwards(project string) (int, error) {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+
+	if !pfm.running {
+		return 0, fmt.Errorf("port forward manager is not running")
+	}
+	if project == "" {
+		return 0, fmt.Errorf("project name is required")
+	}
+
+	var forwardIDs []string
+	for forwardID, forward := range pfm.forwards {
+		if forward.Project == project {
+			forwardIDs = append(forwardIDs, forwardID)
+		}
+	}
+	for _, forwardID := range forwardIDs {
+		if err := pfm.removePortForward(forwardID); err != nil {
+			return 0, err
+		}
+	}
+
+	pfm.logger.WithFields(map[string]any{
+		"project":  project,
+		"forwards": len(forwardIDs),
+	}).Info("Compose project forwards closed")
+	return len(forwardIDs), nil
+}
@@ -0,0 +1,87 @@
+package portforward
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// composeContainer returns a container of a compose service publishing port
+func composeContainer(id, project, service string, port int) *monitor.ContainerInfo {
+	labels := map[string]string{}
+	if project != "" {
+		labels[monitor.ComposeProjectLabel] = project
+		labels[monitor.ComposeServiceLabel] = service
+	}
+	return &monitor.ContainerInfo{
+		ID:     id,
+		Name:   project + "-" + service + "-1",
+		Status: "running",
+		Ports:  []monitor.PortMapping{{ContainerPort: port, HostPort: port, Protocol: "tcp"}},
+		Labels: labels,
+	}
+}
+
+func TestProjectAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   config.ProjectFilterConfig
+		project  string
+		expected bool
+	}{
+		{name: "no filter", project: "shop", expected: true},
+		{name: "outside compose", filter: config.ProjectFilterConfig{Allow: []string{"shop"}}, expected: true},
+		{name: "allowed", filter: config.ProjectFilterConfig{Allow: []string{"shop-*"}}, project: "shop-dev", expected: true},
+		{name: "not allowed", filter: config.ProjectFilterConfig{Allow: []string{"shop-*"}}, project: "blog", expected: false},
+		{name: "denied", filter: config.ProjectFilterConfig{Deny: []string{"*-ci"}}, project: "shop-ci", expected: false},
+		{name: "deny wins", filter: config.ProjectFilterConfig{Allow: []string{"shop-*"}, Deny: []string{"shop-ci"}}, project: "shop-ci", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.PortForwardConfig{Enabled: true, Projects: tt.filter}
+			pfm := NewPortForwardManager(cfg, createTestLogger()).(*portForwardManagerImpl)
+			assert.Equal(t, tt.expected, pfm.projectAllowed(composeContainer("abc", tt.project, "web", 80)))
+		})
+	}
+}
+
+func TestRemoveProjectForwards(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+		Projects:         config.ProjectFilterConfig{Deny: []string{"blog"}},
+	}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	require.NoError(t, manager.OnContainerCreated(composeContainer("web-container", "shop", "web", 8080)))
+	require.NoError(t, manager.OnContainerCreated(composeContainer("db-container", "shop", "db", 5432)))
+	require.NoError(t, manager.OnContainerCreated(composeContainer("blog-container", "blog", "web", 8081)))
+	require.NoError(t, manager.OnContainerCreated(composeContainer("redis-container", "", "", 6379)))
+
+	forwards, err := manager.ListPortForwards()
+	require.NoError(t, err)
+	assert.Len(t, forwards, 3, "denied projects are not forwarded")
+
+	forward, err := manager.GetPortForward("web-container", 8080)
+	require.NoError(t, err)
+	assert.Equal(t, "shop", forward.Project)
+	assert.Equal(t, "web", forward.Service)
+
+	closed, err := manager.RemoveProjectForwards("shop")
+	require.NoError(t, err)
+	assert.Equal(t, 2, closed)
+
+	forwards, err = manager.ListPortForwards()
+	require.NoError(t, err)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, "redis-container", forwards[0].ContainerID)
+}
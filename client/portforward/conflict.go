@@ -0,0 +1,90 @@
+package portforward
+
+import (
+	"fmt"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// claimLocalPort settles a conflict with another forward holding the local port of
+// forward, according to the conflict strategy: auto moves forward to a free port, fail
+// returns a Docker-compatible error and replace evicts the older forward. Ports held by
+// other processes are left to the resolver when the listener opens. Must be called with
+// lock held.
+func (pfm *portForwardManagerImpl) claimLocalPort(forward *PortForward) error {
+	portMap := pfm.portMapFor(forward.Type)
+	ownerID, taken := portMap[forward.LocalPort]
+	if !taken {
+		return nil
+	}
+
+	switch pfm.config.ConflictStrategy {
+	case config.ConflictStrategyReplace:
+		pfm.logger.WithFields(map[string]any{
+			"forward_id":  forward.ID,
+			"replaced_id": ownerID,
+			"local_port":  forward.LocalPort,
+		}).Warn("Replacing older port forward holding the local port")
+		return pfm.removePortForward(ownerID)
+	case config.ConflictStrategyFail:
+		return NewPortInUseError(forward.LocalPort)
+	default:
+		port, err := pfm.nextFreePort(forward.Type, forward.LocalPort)
+		if err != nil {
+			return err
+		}
+		forward.LocalPort = port
+		return nil
+	}
+}
+
+// nextFreePort returns the first port after port that no forward of forwardType holds
+// and, for TCP, nothing else listens on (must be called with lock held)
+func (pfm *portForwardManagerImpl) nextFreePort(forwardType ForwardType, port int) (int, error) {
+	portMap := pfm.portMapFor(forwardType)
+	for candidate := port + 1; candidate <= 65535; candidate++ {
+		if _, taken := portMap[candidate]; taken {
+			continue
+		}
+		if forwardType == ForwardTypeTCP && !pfm.resolver.IsPortAvailable(candidate) {
+			continue
+		}
+		return candidate, nil
+	}
+	return 0, fmt.Errorf("no free local port above %d", port)
+}
+
+// CheckLocalPort returns the local port a new forward of port would get under the
+// conflict strategy, or a Docker-compatible error when the strategy is fail and the
+// port is taken. Replace only evicts DockBridge's own forwards.
+func (pfm *portForwardManagerImpl) CheckLocalPort(port int, protocol string) (int, error) {
+	forwardType, err := forwardTypeForProtocol(protocol)
+	if err != nil {
+		return 0, err
+	}
+
+	pfm.mu.RLock()
+	defer pfm.mu.RUnlock()
+
+	_, owned := pfm.portMapFor(forwardType)[port]
+	inUse := !owned && forwardType == ForwardTypeTCP && !pfm.resolver.IsPortAvailable(port)
+	if !owned && !inUse {
+		return port, nil
+	}
+
+	switch pfm.config.ConflictStrategy {
+	case config.ConflictStrategyReplace:
+		if owned {
+			return port, nil
+		}
+		return 0, NewPortInUseError(port)
+	case config.ConflictStrategyFail:
+		return 0, NewPortInUseError(port)
+	default:
+		if inUse {
+			// The listener resolves ports held by other processes the same way
+			return pfm.resolver.ResolvePortConflict(port, config.ConflictStrategyAuto)
+		}
+		return pfm.nextFreePort(forwardType, port)
+	}
+}
@@ -0,0 +1,99 @@
+package portforward
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConflictManager starts a manager without SSH client, so forwards only claim ports
+func startConflictManager(t *testing.T, strategy config.ConflictStrategy) PortForwardManager {
+	t.Helper()
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: strategy,
+	}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	t.Cleanup(func() { manager.Stop() })
+	return manager
+}
+
+func publishing(id string, containerPort, hostPort int) *monitor.ContainerInfo {
+	return &monitor.ContainerInfo{
+		ID:   id,
+		Name: id,
+		Ports: []monitor.PortMapping{
+			{ContainerPort: containerPort, HostPort: hostPort, Protocol: "tcp"},
+		},
+	}
+}
+
+func TestConflictStrategies(t *testing.T) {
+	port := freePort(t)
+
+	t.Run("auto", func(t *testing.T) {
+		manager := startConflictManager(t, config.ConflictStrategyAuto)
+		require.NoError(t, manager.OnContainerCreated(publishing("first", 80, port)))
+		require.NoError(t, manager.OnContainerCreated(publishing("second", 80, port)))
+
+		first, ok := manager.LocalBinding("first", 80, "tcp")
+		require.True(t, ok)
+		second, ok := manager.LocalBinding("second", 80, "tcp")
+		require.True(t, ok)
+		assert.Equal(t, port, first.HostPort)
+		assert.Greater(t, second.HostPort, port)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		manager := startConflictManager(t, config.ConflictStrategyFail)
+		require.NoError(t, manager.OnContainerCreated(publishing("first", 80, port)))
+		require.NoError(t, manager.OnContainerCreated(publishing("second", 80, port)))
+
+		_, ok := manager.LocalBinding("second", 80, "tcp")
+		assert.False(t, ok)
+		_, err := manager.CheckLocalPort(port, "tcp")
+		var apiErr *DockerAPIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "port_already_allocated", apiErr.Code)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		manager := startConflictManager(t, config.ConflictStrategyReplace)
+		require.NoError(t, manager.OnContainerCreated(publishing("first", 80, port)))
+		require.NoError(t, manager.OnContainerCreated(publishing("second", 80, port)))
+
+		_, ok := manager.LocalBinding("first", 80, "tcp")
+		assert.False(t, ok)
+		second, ok := manager.LocalBinding("second", 80, "tcp")
+		require.True(t, ok)
+		assert.Equal(t, port, second.HostPort)
+	})
+}
+
+func TestCheckLocalPort_PortHeldByOtherProcess(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	auto := startConflictManager(t, config.ConflictStrategyAuto)
+	localPort, err := auto.CheckLocalPort(port, "tcp")
+	require.NoError(t, err)
+	assert.NotEqual(t, port, localPort)
+
+	// Replace cannot evict processes DockBridge does not own
+	replace := startConflictManager(t, config.ConflictStrategyReplace)
+	_, err = replace.CheckLocalPort(port, "tcp")
+	assert.Error(t, err)
+
+	free := freePort(t)
+	localPort, err = replace.CheckLocalPort(free, "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, free, localPort)
+}
@@ -0,0 +1,117 @@
+package portforward
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// forwardListener is the local end of a TCP or UDP forward
+type forwardListener interface {
+	Stop() error
+	GetStats() *ProxyStats
+}
+
+// SetSSHClient sets the SSH connection forwards are tunnelled through
+func (pfm *portForwardManagerImpl) SetSSHClient(sshClient ssh.Client) {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+	pfm.sshClient = sshClient
+}
+
+// startListener opens the local listener of a TCP or UDP forward, tunnelled to the port
+// the container publishes on the server. A TCP port taken locally is resolved with the
+// configured conflict strategy and forward.LocalPort updated. Must be called with lock held.
+func (pfm *portForwardManagerImpl) startListener(container *monitor.ContainerInfo, portMapping monitor.PortMapping, forward *PortForward) error {
+	if pfm.sshClient == nil {
+		return nil
+	}
+
+	remoteAddr := publishedAddr(container, portMapping)
+
+	var listener forwardListener
+	switch forward.Type {
+	case ForwardTypeUDP:
+		proxy := NewLocalUDPProxyServer(pfm.sshClient, pfm.logger)
+		proxy.SetBindAddress(forward.BindAddress)
+		if err := proxy.Start(pfm.ctx, forward.LocalPort, remoteAddr); err != nil {
+			return err
+		}
+		listener = proxy
+	default:
+		localPort, err := pfm.resolver.ResolvePortConflict(forward.LocalPort, pfm.config.ConflictStrategy)
+		if err != nil {
+			return err
+		}
+		proxy := NewLocalProxyServer(pfm.sshClient, pfm.logger)
+		proxy.SetBindAddress(forward.BindAddress)
+		proxy.SetProxyProtocol(forward.ProxyProtocol)
+		if err := proxy.Start(pfm.ctx, localPort, remoteAddr); err != nil {
+			return err
+		}
+		if localPort != forward.LocalPort {
+			pfm.logger.WithFields(map[string]any{
+				"forward_id":     forward.ID,
+				"requested_port": forward.LocalPort,
+				"local_port":     localPort,
+			}).Warn("Local port in use, forwarding on another port")
+		}
+		forward.LocalPort = proxy.GetStats().LocalPort
+		listener = proxy
+	}
+
+	pfm.listeners[forward.ID] = listener
+	return nil
+}
+
+// stopListener closes the local listener of a forward, if any (must be called with lock held)
+func (pfm *portForwardManagerImpl) stopListener(forwardID string) {
+	listener, ok := pfm.listeners[forwardID]
+	if !ok {
+		return
+	}
+	delete(pfm.listeners, forwardID)
+
+	if err := listener.Stop(); err != nil {
+		pfm.logger.WithFields(map[string]any{
+			"forward_id": forwardID,
+			"error":      err.Error(),
+		}).Error("Failed to stop forward listener")
+	}
+}
+
+// snapshot returns a copy of forward with the traffic of its listener (must be called
+// with lock held)
+func (pfm *portForwardManagerImpl) snapshot(forward *PortForward) *PortForward {
+	copied := *forward
+	if listener, ok := pfm.listeners[forward.ID]; ok {
+		stats := listener.GetStats()
+		copied.BytesTransferred = stats.BytesTransferred
+		if stats.LastActivity.After(copied.LastUsed) {
+			copied.LastUsed = stats.LastActivity
+		}
+	}
+	return &copied
+}
+
+// publishedAddr returns the address on the server the container port is published
+// on; unspecified host IPs are reached over loopback
+func publishedAddr(container *monitor.ContainerInfo, portMapping monitor.PortMapping) string {
+	published := portMapping
+	for _, port := range container.Ports {
+		if port.ContainerPort == portMapping.ContainerPort && port.HostPort != 0 &&
+			strings.EqualFold(port.Protocol, portMapping.Protocol) {
+			published = port
+			break
+		}
+	}
+
+	host := published.HostIP
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(published.HostPort))
+}
@@ -0,0 +1,168 @@
+package portforward
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialSSHClient connects every SSH channel to a local echo server and records the
+// addresses dialled on the "server"
+type dialSSHClient struct {
+	ssh.Client
+	echoAddr string
+
+	mu     sync.Mutex
+	dialed []string
+}
+
+func (c *dialSSHClient) IsConnected() bool { return true }
+
+func (c *dialSSHClient) Dial(network, addr string) (net.Conn, error) {
+	c.mu.Lock()
+	c.dialed = append(c.dialed, addr)
+	c.mu.Unlock()
+	return net.Dial(network, c.echoAddr)
+}
+
+func (c *dialSSHClient) dialedAddrs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.dialed...)
+}
+
+// freePort returns a local TCP port that is currently unused
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestPortForwardManager_DataPlane(t *testing.T) {
+	sshClient := &dialSSHClient{echoAddr: startTCPEchoServer(t)}
+
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+	}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	manager.SetSSHClient(sshClient)
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	port := freePort(t)
+	container := &monitor.ContainerInfo{
+		ID:   "web-container-1234567",
+		Name: "web",
+		Ports: []monitor.PortMapping{
+			{ContainerPort: 80, HostPort: port, Protocol: "tcp", HostIP: "0.0.0.0"},
+			{ContainerPort: 81, Protocol: "tcp"},
+		},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	forwards, err := manager.ListPortForwards()
+	require.NoError(t, err)
+	require.Len(t, forwards, 1, "unpublished ports are not forwarded")
+
+	localAddr := net.JoinHostPort(DefaultBindAddress, strconv.Itoa(port))
+	conn, err := net.Dial("tcp", localAddr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+	conn.Close()
+
+	assert.Equal(t, []string{net.JoinHostPort("127.0.0.1", strconv.Itoa(port))}, sshClient.dialedAddrs())
+	require.Eventually(t, func() bool {
+		forward, err := manager.GetPortForward(container.ID, 80)
+		return err == nil && forward.BytesTransferred == 10
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Stopping the container closes the listener
+	require.NoError(t, manager.OnContainerStopped(container.ID))
+	_, err = net.Dial("tcp", localAddr)
+	assert.Error(t, err)
+}
+
+func TestPortForwardManager_DataPlaneResolvesPortConflicts(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+	port := taken.Addr().(*net.TCPAddr).Port
+
+	cfg := &config.PortForwardConfig{Enabled: true, ConflictStrategy: config.ConflictStrategyIncrement}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	manager.SetSSHClient(&dialSSHClient{echoAddr: startTCPEchoServer(t)})
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	container := &monitor.ContainerInfo{
+		ID:    "api-container-1234567",
+		Ports: []monitor.PortMapping{{ContainerPort: 8080, HostPort: port, Protocol: "tcp"}},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	forward, err := manager.GetPortForward(container.ID, 8080)
+	require.NoError(t, err)
+	assert.NotEqual(t, port, forward.LocalPort)
+
+	cfg.ConflictStrategy = config.ConflictStrategyFail
+	other := &monitor.ContainerInfo{
+		ID:    "db-container-12345678",
+		Ports: []monitor.PortMapping{{ContainerPort: 5432, HostPort: port, Protocol: "tcp"}},
+	}
+	require.NoError(t, manager.OnContainerCreated(other))
+	_, err = manager.GetPortForward(other.ID, 5432)
+	assert.Error(t, err, "the fail strategy leaves the port unforwarded")
+}
+
+func TestPublishedAddr(t *testing.T) {
+	container := &monitor.ContainerInfo{Ports: []monitor.PortMapping{
+		{ContainerPort: 53, HostPort: 5353, Protocol: "udp", HostIP: "0.0.0.0"},
+		{ContainerPort: 80, HostPort: 8080, Protocol: "tcp", HostIP: "::"},
+		{ContainerPort: 443, HostPort: 8443, Protocol: "tcp", HostIP: "10.0.0.2"},
+	}}
+
+	assert.Equal(t, "127.0.0.1:5353", publishedAddr(container, monitor.PortMapping{ContainerPort: 53, HostPort: 53, Protocol: "udp"}))
+	assert.Equal(t, "127.0.0.1:8080", publishedAddr(container, container.Ports[1]))
+	assert.Equal(t, "10.0.0.2:8443", publishedAddr(container, container.Ports[2]))
+}
+
+// startTCPEchoServer starts a TCP echo server and returns its address
+func startTCPEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				io.Copy(c, c)
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
@@ -3,10 +3,14 @@ package portforward
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/client/ssh"
 	"github.com/dockbridge/dockbridge/pkg/logger"
 	"github.com/dockbridge/dockbridge/shared/config"
 )
@@ -20,30 +24,87 @@ type PortForwardManager interface {
 	OnContainerCreated(container *monitor.ContainerInfo) error
 	OnContainerStopped(containerID string) error
 	OnContainerRemoved(containerID string) error
+	OnContainerHealthChanged(containerID string, health monitor.HealthStatus) error
+	OnContainerRestarted(container *monitor.ContainerInfo) error
 
 	// Manual port management
 	AddPortForward(containerID string, localPort, remotePort int) error
 	RemovePortForward(containerID string, localPort int) error
 
+	// Manual UDP port management
+	AddUDPPortForward(containerID string, localPort, remotePort int) error
+	RemoveUDPPortForward(containerID string, localPort int) error
+
+	// Manual Unix socket management
+	AddSocketForward(containerID string, mapping SocketMapping) error
+	RemoveSocketForward(containerID string, localPath string) error
+
+	// RemoveProjectForwards closes all forwards of a docker compose project and returns
+	// how many were closed
+	RemoveProjectForwards(project string) (int, error)
+
 	// Status and information
 	ListPortForwards() ([]*PortForward, error)
 	GetPortForward(containerID string, remotePort int) (*PortForward, error)
 
+	// LocalBinding returns the local address a published container port is forwarded on
+	LocalBinding(containerID string, containerPort int, protocol string) (LocalBinding, bool)
+
+	// CheckLocalPort returns the local port a new forward of port would get under the
+	// conflict strategy, or a *DockerAPIError when the strategy rejects the conflict
+	CheckLocalPort(port int, protocol string) (int, error)
+
 	// Configuration
 	SetConfig(config *config.PortForwardConfig) error
+
+	// SetSSHClient sets the SSH connection forwards are tunnelled through. Without one,
+	// forwards are only recorded and no local listeners are opened.
+	SetSSHClient(sshClient ssh.Client)
+
+	// SetForwardAddedCallback registers a callback invoked after a forward is created.
+	// It runs with the manager lock held and must not block or call back into the manager.
+	SetForwardAddedCallback(callback func(forward *PortForward))
 }
 
 // PortForward represents an active port forward
 type PortForward struct {
 	ID               string        `json:"id"`
+	Type             ForwardType   `json:"type"`
 	ContainerID      string        `json:"container_id"`
 	ContainerName    string        `json:"container_name"`
+	Project          string        `json:"project,omitempty"`
+	Service          string        `json:"service,omitempty"`
 	LocalPort        int           `json:"local_port"`
 	RemotePort       int           `json:"remote_port"`
+	LocalSocket      string        `json:"local_socket,omitempty"`
+	RemoteSocket     string        `json:"remote_socket,omitempty"`
 	Status           ForwardStatus `json:"status"`
 	CreatedAt        time.Time     `json:"created_at"`
 	LastUsed         time.Time     `json:"last_used"`
 	BytesTransferred int64         `json:"bytes_transferred"`
+	ProxyProtocol    bool          `json:"proxy_protocol"`
+	BindAddress      string        `json:"bind_address,omitempty"`
+}
+
+// ForwardType represents the kind of endpoint being forwarded
+type ForwardType string
+
+const (
+	ForwardTypeTCP  ForwardType = "tcp"
+	ForwardTypeUDP  ForwardType = "udp"
+	ForwardTypeUnix ForwardType = "unix"
+)
+
+// forwardTypeForProtocol returns the forward type for a Docker port protocol
+func forwardTypeForProtocol(protocol string) (ForwardType, error) {
+	switch strings.ToLower(protocol) {
+	case "", "tcp":
+		return ForwardTypeTCP, nil
+	case "udp":
+		return ForwardTypeUDP, nil
+	default:
+		return "", fmt.Errorf("unsupported port protocol %q", protocol)
+	}
 }
 
 // ForwardStatus represents the status of a port forward
@@ -63,7 +124,17 @@ type portForwardManagerImpl struct {
 	// State management
 	forwards   map[string]*PortForward           // forwardID -> PortForward
 	containers map[string]*monitor.ContainerInfo // containerID -> ContainerInfo
-	portMap    map[int]string                    // localPort -> forwardID
+	portMap    map[int]string                    // local TCP port -> forwardID
+	udpPortMap map[int]string                    // local UDP port -> forwardID
+	socketMap  map[string]string                 // local socket path -> forwardID
+
+	// onForwardAdded is notified about new forwards (optional)
+	onForwardAdded func(forward *PortForward)
+
+	// Data plane: local listeners of TCP and UDP forwards, tunnelled through sshClient
+	sshClient ssh.Client
+	listeners map[string]forwardListener // forwardID -> listener
+	resolver  PortConflictResolver
 
 	// Synchronization
 	mu      sync.RWMutex
@@ -80,6 +151,10 @@ func NewPortForwardManager(config *config.PortForwardConfig, logger logger.Logge
 		forwards:   make(map[string]*PortForward),
 		containers: make(map[string]*monitor.ContainerInfo),
 		portMap:    make(map[int]string),
+		udpPortMap: make(map[int]string),
+		socketMap:  make(map[string]string),
+		listeners:  make(map[string]forwardListener),
+		resolver:   NewPortConflictResolver(),
 	}
 }
 
@@ -130,12 +205,15 @@ func (pfm *portForwardManagerImpl) Stop() error {
 				"remote_port":  forward.RemotePort,
 			}).Debug("Cleaning up port forward on shutdown")
 		}
+		pfm.stopListener(forward.ID)
 	}
 
 	// Clear state
 	pfm.forwards = make(map[string]*PortForward)
 	pfm.containers = make(map[string]*monitor.ContainerInfo)
 	pfm.portMap = make(map[int]string)
+	pfm.udpPortMap = make(map[int]string)
+	pfm.socketMap = make(map[string]string)
 
 	pfm.logger.Info("Port forward manager stopped")
 	return nil
@@ -156,9 +234,24 @@ func (pfm *portForwardManagerImpl) OnContainerCreated(container *monitor.Contain
 		"ports":          len(container.Ports),
 	}).Debug("Container created event received")
 
+	pfm.createContainerForwards(container)
+	return nil
+}
+
+// createContainerForwards records the container and creates its port and socket
+// forwards (must be called with lock held)
+func (pfm *portForwardManagerImpl) createContainerForwards(container *monitor.ContainerInfo) {
 	// Store container info
 	pfm.containers[container.ID] = container
 
+	if !pfm.projectAllowed(container) {
+		pfm.logger.WithFields(map[string]any{
+			"container_id": container.ID,
+			"project":      container.ComposeProject(),
+		}).Debug("Compose project excluded from port forwarding")
+		return
+	}
+
 	// Create port forwards for exposed ports
 	for _, portMapping := range container.Ports {
 		if err := pfm.createPortForward(container, portMapping); err != nil {
@@ -170,7 +263,24 @@ func (pfm *portForwardManagerImpl) OnContainerCreated(container *monitor.Contain
 		}
 	}
 
-	return nil
+	// Create Unix socket forwards requested via labels
+	mappings, err := ParseSocketLabels(container, pfm.config.SocketDir)
+	if err != nil {
+		pfm.logger.WithFields(map[string]any{
+			"container_id": container.ID,
+			"error":        err.Error(),
+		}).Error("Invalid socket forward labels")
+		return
+	}
+	for _, mapping := range mappings {
+		if err := pfm.createSocketForward(container, mapping); err != nil {
+			pfm.logger.WithFields(map[string]any{
+				"container_id": container.ID,
+				"socket":       mapping.Name,
+				"error":        err.Error(),
+			}).Error("Failed to create socket forward")
+		}
+	}
 }
 
 // OnContainerStopped handles container stopped events
@@ -211,8 +321,96 @@ func (pfm *portForwardManagerImpl) OnContainerRemoved(containerID string) error
 	return nil
 }
 
-// AddPortForward manually adds a port forward
+// OnContainerHealthChanged marks the forwards of an unhealthy container as failing and
+// restores them once it is healthy again
+func (pfm *portForwardManagerImpl) OnContainerHealthChanged(containerID string, health monitor.HealthStatus) error {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+
+	if !pfm.running {
+		return nil
+	}
+
+	var status ForwardStatus
+	switch health {
+	case monitor.HealthUnhealthy:
+		status = ForwardStatusError
+	case monitor.HealthHealthy:
+		status = ForwardStatusActive
+	default:
+		return nil
+	}
+
+	for _, forward := range pfm.forwards {
+		if forward.ContainerID == containerID && forward.Status != status {
+			forward.Status = status
+			pfm.logger.WithFields(map[string]any{
+				"forward_id":   forward.ID,
+				"container_id": containerID,
+				"health":       string(health),
+			}).Info("Port forward status follows container health")
+		}
+	}
+	return nil
+}
+
+// OnContainerRestarted recreates the forwards of a restarted container whose published
+// ports changed, e.g. ephemeral host ports that were reassigned
+func (pfm *portForwardManagerImpl) OnContainerRestarted(container *monitor.ContainerInfo) error {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+
+	if !pfm.running || !pfm.config.Enabled {
+		return nil
+	}
+
+	if previous, exists := pfm.containers[container.ID]; exists && samePorts(previous.Ports, container.Ports) {
+		pfm.containers[container.ID] = container
+		return nil
+	}
+
+	pfm.logger.WithFields(map[string]any{
+		"container_id":   container.ID,
+		"container_name": container.Name,
+	}).Info("Published ports changed on restart, recreating port forwards")
+
+	if err := pfm.cleanupContainerForwards(container.ID); err != nil {
+		return err
+	}
+	pfm.createContainerForwards(container)
+	return nil
+}
+
+// samePorts reports whether two port lists hold the same mappings in any order
+func samePorts(a, b []monitor.PortMapping) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[monitor.PortMapping]int, len(a))
+	for _, port := range a {
+		counts[port]++
+	}
+	for _, port := range b {
+		if counts[port] == 0 {
+			return false
+		}
+		counts[port]--
+	}
+	return true
+}
+
+// AddPortForward manually adds a TCP port forward
 func (pfm *portForwardManagerImpl) AddPortForward(containerID string, localPort, remotePort int) error {
+	return pfm.addPortForward(containerID, localPort, remotePort, "tcp")
+}
+
+// AddUDPPortForward manually adds a UDP port forward
+func (pfm *portForwardManagerImpl) AddUDPPortForward(containerID string, localPort, remotePort int) error {
+	return pfm.addPortForward(containerID, localPort, remotePort, "udp")
+}
+
+// addPortForward manually adds a port forward for protocol
+func (pfm *portForwardManagerImpl) addPortForward(containerID string, localPort, remotePort int, protocol string) error {
 	pfm.mu.Lock()
 	defer pfm.mu.Unlock()
 
@@ -228,15 +426,25 @@ func (pfm *portForwardManagerImpl) AddPortForward(containerID string, localPort,
 	portMapping := monitor.PortMapping{
 		ContainerPort: remotePort,
 		HostPort:      localPort,
-		Protocol:      "tcp",
+		Protocol:      protocol,
 		HostIP:        "0.0.0.0",
 	}
 
 	return pfm.createPortForward(container, portMapping)
 }
 
-// RemovePortForward manually removes a port forward
+// RemovePortForward manually removes a TCP port forward
 func (pfm *portForwardManagerImpl) RemovePortForward(containerID string, localPort int) error {
+	return pfm.removeManualPortForward(containerID, localPort, ForwardTypeTCP)
+}
+
+// RemoveUDPPortForward manually removes a UDP port forward
+func (pfm *portForwardManagerImpl) RemoveUDPPortForward(containerID string, localPort int) error {
+	return pfm.removeManualPortForward(containerID, localPort, ForwardTypeUDP)
+}
+
+// removeManualPortForward manually removes a port forward of the given type
+func (pfm *portForwardManagerImpl) removeManualPortForward(containerID string, localPort int, forwardType ForwardType) error {
 	pfm.mu.Lock()
 	defer pfm.mu.Unlock()
 
@@ -244,9 +452,9 @@ func (pfm *portForwardManagerImpl) RemovePortForward(containerID string, localPo
 		return fmt.Errorf("port forward manager is not running")
 	}
 
-	forwardID, exists := pfm.portMap[localPort]
+	forwardID, exists := pfm.portMapFor(forwardType)[localPort]
 	if !exists {
-		return fmt.Errorf("no port forward found for local port %d", localPort)
+		return fmt.Errorf("no %s port forward found for local port %d", forwardType, localPort)
 	}
 
 	forward, exists := pfm.forwards[forwardID]
@@ -257,6 +465,57 @@ func (pfm *portForwardManagerImpl) RemovePortForward(containerID string, localPo
 	return pfm.removePortForward(forwardID)
 }
 
+// AddSocketForward manually adds a Unix socket forward
+func (pfm *portForwardManagerImpl) AddSocketForward(containerID string, mapping SocketMapping) error {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+
+	if !pfm.running {
+		return fmt.Errorf("port forward manager is not running")
+	}
+
+	container, exists := pfm.containers[containerID]
+	if !exists {
+		return fmt.Errorf("container %s not found", containerID)
+	}
+
+	if !filepath.IsAbs(mapping.RemotePath) {
+		return fmt.Errorf("remote socket path %q must be absolute", mapping.RemotePath)
+	}
+	if mapping.Name == "" {
+		mapping.Name = filepath.Base(mapping.RemotePath)
+	}
+	if mapping.LocalPath == "" {
+		mapping.LocalPath = filepath.Join(pfm.config.SocketDir, fmt.Sprintf("%s-%s.sock", socketContainerName(container), mapping.Name))
+	}
+	mapping.LocalPath = expandHome(mapping.LocalPath)
+
+	return pfm.createSocketForward(container, mapping)
+}
+
+// RemoveSocketForward manually removes a Unix socket forward
+func (pfm *portForwardManagerImpl) RemoveSocketForward(containerID string, localPath string) error {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+
+	if !pfm.running {
+		return fmt.Errorf("port forward manager is not running")
+	}
+
+	localPath = expandHome(localPath)
+	forwardID, exists := pfm.socketMap[localPath]
+	if !exists {
+		return fmt.Errorf("no socket forward found for local path %s", localPath)
+	}
+
+	forward, exists := pfm.forwards[forwardID]
+	if !exists || forward.ContainerID != containerID {
+		return fmt.Errorf("socket forward mismatch for container %s and path %s", containerID, localPath)
+	}
+
+	return pfm.removePortForward(forwardID)
+}
+
 // ListPortForwards returns all active port forwards
 func (pfm *portForwardManagerImpl) ListPortForwards() ([]*PortForward, error) {
 	pfm.mu.RLock()
@@ -264,7 +523,7 @@ func (pfm *portForwardManagerImpl) ListPortForwards() ([]*PortForward, error) {
 
 	forwards := make([]*PortForward, 0, len(pfm.forwards))
 	for _, forward := range pfm.forwards {
-		forwards = append(forwards, forward)
+		forwards = append(forwards, pfm.snapshot(forward))
 	}
 
 	return forwards, nil
@@ -277,7 +536,7 @@ func (pfm *portForwardManagerImpl) GetPortForward(containerID string, remotePort
 
 	for _, forward := range pfm.forwards {
 		if forward.ContainerID == containerID && forward.RemotePort == remotePort {
-			return forward, nil
+			return pfm.snapshot(forward), nil
 		}
 	}
 
@@ -302,12 +561,25 @@ func (pfm *portForwardManagerImpl) SetConfig(config *config.PortForwardConfig) e
 
 // createPortForward creates a new port forward (must be called with lock held)
 func (pfm *portForwardManagerImpl) createPortForward(container *monitor.ContainerInfo, portMapping monitor.PortMapping) error {
+	forwardType, err := forwardTypeForProtocol(portMapping.Protocol)
+	if err != nil {
+		return err
+	}
+	if portMapping.HostPort == 0 {
+		// Exposed but not published: nothing on the server to tunnel to
+		return nil
+	}
+
 	// Use first 12 characters of container ID, or full ID if shorter
 	containerIDPrefix := container.ID
 	if len(containerIDPrefix) > 12 {
 		containerIDPrefix = containerIDPrefix[:12]
 	}
 	forwardID := fmt.Sprintf("%s-%d", containerIDPrefix, portMapping.ContainerPort)
+	if forwardType == ForwardTypeUDP {
+		// The same port number may be published for TCP and UDP, e.g. DNS
+		forwardID += "-udp"
+	}
 
 	// Check if forward already exists
 	if _, exists := pfm.forwards[forwardID]; exists {
@@ -319,33 +591,128 @@ func (pfm *portForwardManagerImpl) createPortForward(container *monitor.Containe
 		return nil
 	}
 
-	// For now, just create the forward entry without actual proxy server
-	// The proxy server will be implemented in subsequent tasks
 	forward := &PortForward{
 		ID:            forwardID,
+		Type:          forwardType,
 		ContainerID:   container.ID,
 		ContainerName: container.Name,
+		Project:       container.ComposeProject(),
+		Service:       container.ComposeService(),
 		LocalPort:     portMapping.HostPort,
 		RemotePort:    portMapping.ContainerPort,
+		ProxyProtocol: pfm.proxyProtocolEnabled(container),
+		BindAddress:   pfm.bindAddressFor(container),
 		Status:        ForwardStatusActive,
 		CreatedAt:     time.Now(),
 		LastUsed:      time.Now(),
 	}
 
+	// Settle conflicts with other forwards, then open the local listener tunnelled to
+	// the port published on the server
+	if err := pfm.claimLocalPort(forward); err != nil {
+		return err
+	}
+	if err := pfm.startListener(container, portMapping, forward); err != nil {
+		return err
+	}
+
 	pfm.forwards[forwardID] = forward
-	pfm.portMap[forward.LocalPort] = forwardID
+	pfm.portMapFor(forwardType)[forward.LocalPort] = forwardID
 
 	pfm.logger.WithFields(map[string]any{
 		"forward_id":     forwardID,
+		"protocol":       string(forwardType),
 		"container_id":   container.ID,
 		"container_name": container.Name,
+		"bind_address":   forward.BindAddress,
 		"local_port":     forward.LocalPort,
 		"remote_port":    forward.RemotePort,
 	}).Info("Port forward created")
 
+	pfm.notifyForwardAdded(forward)
+	return nil
+}
+
+// createSocketForward creates a new Unix socket forward (must be called with lock held)
+func (pfm *portForwardManagerImpl) createSocketForward(container *monitor.ContainerInfo, mapping SocketMapping) error {
+	containerIDPrefix := container.ID
+	if len(containerIDPrefix) > 12 {
+		containerIDPrefix = containerIDPrefix[:12]
+	}
+	forwardID := fmt.Sprintf("%s-%s", containerIDPrefix, mapping.Name)
+
+	if _, exists := pfm.forwards[forwardID]; exists {
+		pfm.logger.WithFields(map[string]any{
+			"forward_id":   forwardID,
+			"container_id": container.ID,
+			"socket":       mapping.Name,
+		}).Debug("Socket forward already exists")
+		return nil
+	}
+
+	// Two containers must never share a local socket path
+	if existingID, exists := pfm.socketMap[mapping.LocalPath]; exists {
+		return fmt.Errorf("local socket %s is already used by forward %s", mapping.LocalPath, existingID)
+	}
+
+	forward := &PortForward{
+		ID:            forwardID,
+		Type:          ForwardTypeUnix,
+		ContainerID:   container.ID,
+		ContainerName: container.Name,
+		Project:       container.ComposeProject(),
+		Service:       container.ComposeService(),
+		LocalSocket:   mapping.LocalPath,
+		RemoteSocket:  mapping.RemotePath,
+		Status:        ForwardStatusActive,
+		CreatedAt:     time.Now(),
+		LastUsed:      time.Now(),
+	}
+
+	pfm.forwards[forwardID] = forward
+	pfm.socketMap[forward.LocalSocket] = forwardID
+
+	pfm.logger.WithFields(map[string]any{
+		"forward_id":     forwardID,
+		"container_id":   container.ID,
+		"container_name": container.Name,
+		"local_socket":   forward.LocalSocket,
+		"remote_socket":  forward.RemoteSocket,
+	}).Info("Socket forward created")
+
+	pfm.notifyForwardAdded(forward)
 	return nil
 }
 
+// SetForwardAddedCallback registers a callback invoked after a forward is created
+func (pfm *portForwardManagerImpl) SetForwardAddedCallback(callback func(forward *PortForward)) {
+	pfm.mu.Lock()
+	defer pfm.mu.Unlock()
+	pfm.onForwardAdded = callback
+}
+
+// notifyForwardAdded invokes the forward-added callback (must be called with lock held)
+func (pfm *portForwardManagerImpl) notifyForwardAdded(forward *PortForward) {
+	if pfm.onForwardAdded != nil {
+		pfm.onForwardAdded(forward)
+	}
+}
+
+// proxyProtocolEnabled reports whether forwards for the container should carry
+// PROXY protocol v2 headers; the container label overrides the global setting.
+func (pfm *portForwardManagerImpl) proxyProtocolEnabled(container *monitor.ContainerInfo) bool {
+	if value, ok := container.Labels[ProxyProtocolLabel]; ok {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			return enabled
+		}
+		pfm.logger.WithFields(map[string]any{
+			"container_id": container.ID,
+			"value":        value,
+		}).Warn("Ignoring invalid proxy protocol label")
+	}
+	return pfm.config.ProxyProtocol
+}
+
 // removePortForward removes a port forward (must be called with lock held)
 func (pfm *portForwardManagerImpl) removePortForward(forwardID string) error {
 	forward, exists := pfm.forwards[forwardID]
@@ -353,20 +720,34 @@ func (pfm *portForwardManagerImpl) removePortForward(forwardID string) error {
 		return fmt.Errorf("port forward %s not found", forwardID)
 	}
 
-	// Remove from maps
+	// Close the local listener and remove from maps
+	pfm.stopListener(forwardID)
 	delete(pfm.forwards, forwardID)
-	delete(pfm.portMap, forward.LocalPort)
+	if forward.Type == ForwardTypeUnix {
+		delete(pfm.socketMap, forward.LocalSocket)
+	} else {
+		delete(pfm.portMapFor(forward.Type), forward.LocalPort)
+	}
 
 	pfm.logger.WithFields(map[string]any{
 		"forward_id":   forwardID,
 		"container_id": forward.ContainerID,
 		"local_port":   forward.LocalPort,
 		"remote_port":  forward.RemotePort,
+		"local_socket": forward.LocalSocket,
 	}).Info("Port forward removed")
 
 	return nil
 }
 
+// portMapFor returns the local port map of a TCP or UDP forward type
+func (pfm *portForwardManagerImpl) portMapFor(forwardType ForwardType) map[int]string {
+	if forwardType == ForwardTypeUDP {
+		return pfm.udpPortMap
+	}
+	return pfm.portMap
+}
+
 // cleanupContainerForwards removes all forwards for a container (must be called with lock held)
 func (pfm *portForwardManagerImpl) cleanupContainerForwards(containerID string) error {
 	var forwardsToRemove []string
@@ -111,6 +111,36 @@ func TestPortForwardManager_ContainerEvents(t *testing.T) {
 	assert.Empty(t, forwards)
 }
 
+func TestPortForwardManager_ForwardAddedCallback(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+	}
+
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	var added []*PortForward
+	manager.SetForwardAddedCallback(func(forward *PortForward) {
+		added = append(added, forward)
+	})
+
+	container := &monitor.ContainerInfo{
+		ID:    "test-container-456",
+		Name:  "web",
+		Ports: []monitor.PortMapping{{ContainerPort: 80, HostPort: 8081, Protocol: "tcp"}},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+	// A duplicate event must not report the forward twice
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	require.Len(t, added, 1)
+	assert.Equal(t, 8081, added[0].LocalPort)
+	assert.Equal(t, "web", added[0].ContainerName)
+}
+
 func TestPortForwardManager_ManualPortManagement(t *testing.T) {
 	// Create test configuration
 	cfg := &config.PortForwardConfig{
@@ -242,3 +272,94 @@ func TestPortForwardManager_ConfigurationUpdate(t *testing.T) {
 	// Configuration update should succeed
 	// (Actual behavior changes would be tested in integration tests)
 }
+
+func TestPortForwardManager_UDPPorts(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+	}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	container := &monitor.ContainerInfo{
+		ID:   "dns-container-1234567",
+		Name: "dns",
+		Ports: []monitor.PortMapping{
+			{ContainerPort: 53, HostPort: 5353, Protocol: "tcp", HostIP: "0.0.0.0"},
+			{ContainerPort: 53, HostPort: 5353, Protocol: "udp", HostIP: "0.0.0.0"},
+			{ContainerPort: 9899, HostPort: 9899, Protocol: "sctp", HostIP: "0.0.0.0"},
+		},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	forwards, err := manager.ListPortForwards()
+	require.NoError(t, err)
+	require.Len(t, forwards, 2, "TCP and UDP on the same port are separate forwards; SCTP is skipped")
+
+	types := map[string]ForwardType{}
+	for _, forward := range forwards {
+		types[forward.ID] = forward.Type
+	}
+	assert.Equal(t, map[string]ForwardType{
+		"dns-containe-53":     ForwardTypeTCP,
+		"dns-containe-53-udp": ForwardTypeUDP,
+	}, types)
+
+	require.NoError(t, manager.RemoveUDPPortForward(container.ID, 5353))
+	forwards, err = manager.ListPortForwards()
+	require.NoError(t, err)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, ForwardTypeTCP, forwards[0].Type)
+	assert.Error(t, manager.RemoveUDPPortForward(container.ID, 5353))
+
+	require.NoError(t, manager.AddUDPPortForward(container.ID, 5454, 53))
+	forward, err := manager.GetPortForward(container.ID, 53)
+	require.NoError(t, err)
+	assert.Equal(t, 53, forward.RemotePort)
+}
+
+func TestPortForwardManager_HealthAndRestart(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+	}
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	container := &monitor.ContainerInfo{
+		ID:    "web-container-1234567",
+		Name:  "web",
+		Ports: []monitor.PortMapping{{ContainerPort: 80, HostPort: 32768, Protocol: "tcp"}},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	// Forwards follow the container's health
+	require.NoError(t, manager.OnContainerHealthChanged(container.ID, monitor.HealthUnhealthy))
+	forward, err := manager.GetPortForward(container.ID, 80)
+	require.NoError(t, err)
+	assert.Equal(t, ForwardStatusError, forward.Status)
+
+	require.NoError(t, manager.OnContainerHealthChanged(container.ID, monitor.HealthHealthy))
+	forward, err = manager.GetPortForward(container.ID, 80)
+	require.NoError(t, err)
+	assert.Equal(t, ForwardStatusActive, forward.Status)
+
+	// A restart that reassigned the ephemeral host port recreates the forward
+	restarted := *container
+	restarted.Ports = []monitor.PortMapping{{ContainerPort: 80, HostPort: 32769, Protocol: "tcp"}}
+	require.NoError(t, manager.OnContainerRestarted(&restarted))
+	forward, err = manager.GetPortForward(container.ID, 80)
+	require.NoError(t, err)
+	assert.Equal(t, 32769, forward.LocalPort)
+
+	// An unchanged restart keeps it
+	created := forward.CreatedAt
+	require.NoError(t, manager.OnContainerRestarted(&restarted))
+	forward, err = manager.GetPortForward(container.ID, 80)
+	require.NoError(t, err)
+	assert.Equal(t, created, forward.CreatedAt)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,6 +21,12 @@ type LocalProxyServer interface {
 	Stop() error
 	GetStats() *ProxyStats
 	IsRunning() bool
+
+	// SetProxyProtocol enables PROXY protocol v2 headers on new upstream connections
+	SetProxyProtocol(enabled bool)
+
+	// SetBindAddress sets the local address to listen on; takes effect on the next Start
+	SetBindAddress(address string)
 }
 
 // ProxyStats contains statistics about the proxy server
@@ -39,8 +46,10 @@ type localProxyServerImpl struct {
 	logger    logger.LoggerInterface
 
 	// Configuration
-	localPort  int
-	remoteAddr string
+	localPort     int
+	remoteAddr    string
+	proxyProtocol bool
+	bindAddress   string
 
 	// Network components
 	listener net.Listener
@@ -87,9 +96,13 @@ func (lps *localProxyServerImpl) Start(ctx context.Context, localPort int, remot
 	lps.remoteAddr = remoteAddr
 
 	// Create local listener
-	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", localPort))
+	bindAddress := lps.bindAddress
+	if bindAddress == "" {
+		bindAddress = DefaultBindAddress
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(bindAddress, strconv.Itoa(localPort)))
 	if err != nil {
-		return errors.Wrapf(err, "failed to listen on port %d", localPort)
+		return errors.Wrapf(err, "failed to listen on %s port %d", bindAddress, localPort)
 	}
 	lps.listener = listener
 
@@ -112,8 +125,9 @@ func (lps *localProxyServerImpl) Start(ctx context.Context, localPort int, remot
 	}()
 
 	lps.logger.WithFields(map[string]any{
-		"local_port":  localPort,
-		"remote_addr": remoteAddr,
+		"bind_address": bindAddress,
+		"local_port":   localPort,
+		"remote_addr":  remoteAddr,
 	}).Info("Local proxy server started")
 
 	return nil
@@ -186,6 +200,20 @@ func (lps *localProxyServerImpl) IsRunning() bool {
 	return lps.running
 }
 
+// SetProxyProtocol enables PROXY protocol v2 headers on new upstream connections
+func (lps *localProxyServerImpl) SetProxyProtocol(enabled bool) {
+	lps.mu.Lock()
+	defer lps.mu.Unlock()
+	lps.proxyProtocol = enabled
+}
+
+// SetBindAddress sets the local address to listen on; takes effect on the next Start
+func (lps *localProxyServerImpl) SetBindAddress(address string) {
+	lps.mu.Lock()
+	defer lps.mu.Unlock()
+	lps.bindAddress = address
+}
+
 // acceptConnections accepts incoming connections and handles them
 func (lps *localProxyServerImpl) acceptConnections() {
 	for {
@@ -237,28 +265,31 @@ func (lps *localProxyServerImpl) handleConnection(localConn net.Conn) {
 		"target":      lps.remoteAddr,
 	}).Debug("Handling new connection")
 
-	// Create SSH tunnel to remote address
-	tunnel, err := lps.sshClient.CreateTunnel(lps.ctx, "localhost:0", lps.remoteAddr)
+	// Open an SSH channel to the remote address
+	remoteConn, err := lps.sshClient.Dial("tcp", lps.remoteAddr)
 	if err != nil {
 		lps.logger.WithFields(map[string]any{
 			"error":       err.Error(),
 			"remote_addr": lps.remoteAddr,
-		}).Error("Failed to create SSH tunnel")
+		}).Error("Failed to open SSH channel")
 		return
 	}
-	defer tunnel.Close()
+	defer remoteConn.Close()
 
-	// Get the tunnel's local address and connect to it
-	tunnelAddr := tunnel.LocalAddr()
-	remoteConn, err := net.Dial("tcp", tunnelAddr)
-	if err != nil {
-		lps.logger.WithFields(map[string]any{
-			"error":       err.Error(),
-			"tunnel_addr": tunnelAddr,
-		}).Error("Failed to connect to SSH tunnel")
-		return
+	// Tell the upstream service who the original client was
+	lps.mu.RLock()
+	proxyProtocol := lps.proxyProtocol
+	lps.mu.RUnlock()
+	if proxyProtocol {
+		header := buildProxyProtocolV2Header(localConn.RemoteAddr(), localConn.LocalAddr())
+		if _, err := remoteConn.Write(header); err != nil {
+			lps.logger.WithFields(map[string]any{
+				"error":       err.Error(),
+				"remote_addr": lps.remoteAddr,
+			}).Error("Failed to write PROXY protocol header")
+			return
+		}
 	}
-	defer remoteConn.Close()
 
 	// Proxy data bidirectionally
 	lps.proxyData(localConn, remoteConn)
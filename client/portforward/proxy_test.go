@@ -59,15 +59,69 @@ func (m *mockSSHClient) CreateTunnel(ctx context.Context, localAddr, remoteAddr
 	return tunnel, nil
 }
 
+func (m *mockSSHClient) CreateUnixTunnel(ctx context.Context, localPath, remotePath string) (ssh.TunnelInterface, error) {
+	args := m.Called(ctx, localPath, remotePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(ssh.TunnelInterface), args.Error(1)
+}
+
+func (m *mockSSHClient) CreateRemoteUnixTunnel(ctx context.Context, localAddr, remotePath string) (ssh.TunnelInterface, error) {
+	args := m.Called(ctx, localAddr, remotePath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(ssh.TunnelInterface), args.Error(1)
+}
+
+func (m *mockSSHClient) Dial(network, addr string) (net.Conn, error) {
+	args := m.Called(network, addr)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(net.Conn), args.Error(1)
+}
+
 func (m *mockSSHClient) ExecuteCommand(ctx context.Context, command string) ([]byte, error) {
 	args := m.Called(ctx, command)
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *mockSSHClient) ExecuteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error) {
+	args := m.Called(ctx, command, input)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *mockSSHClient) OpenStream(ctx context.Context, command string) (io.ReadWriteCloser, error) {
+	args := m.Called(ctx, command)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadWriteCloser), args.Error(1)
+}
+
+func (m *mockSSHClient) Run(ctx context.Context, command string, stdio ssh.Stdio, pty *ssh.PTY) error {
+	args := m.Called(ctx, command, stdio, pty)
+	return args.Error(0)
+}
+
 func (m *mockSSHClient) IsConnected() bool {
 	return m.connected
 }
 
+func (m *mockSSHClient) KeepAlive(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockSSHClient) Reconnect(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockSSHClient) ChannelStats() ssh.ChannelPoolStats {
+	return ssh.ChannelPoolStats{}
+}
+
 // mockTunnel implements ssh.TunnelInterface for testing
 type mockTunnel struct {
 	localAddr  string
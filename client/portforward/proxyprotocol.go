@@ -0,0 +1,52 @@
+package portforward
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// ProxyProtocolLabel is the container label that enables PROXY protocol v2 for a
+// container's forwards regardless of the global port_forward.proxy_protocol setting.
+const ProxyProtocolLabel = "dockbridge.proxy_protocol"
+
+// proxyProtocolV2Signature is the fixed 12-byte PROXY protocol v2 preamble
+var proxyProtocolV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+const (
+	proxyProtocolV2CmdLocal = 0x20 // version 2, LOCAL command
+	proxyProtocolV2CmdProxy = 0x21 // version 2, PROXY command
+	proxyProtocolFamUnspec  = 0x00
+	proxyProtocolFamTCP4    = 0x11
+	proxyProtocolFamTCP6    = 0x21
+)
+
+// buildProxyProtocolV2Header builds a binary PROXY protocol v2 header describing a
+// connection from src to dst. Non-TCP addresses produce a LOCAL header, which tells
+// the receiver to use the real connection endpoints.
+func buildProxyProtocolV2Header(src, dst net.Addr) []byte {
+	header := make([]byte, 0, 16+36)
+	header = append(header, proxyProtocolV2Signature...)
+
+	srcTCP, srcOK := src.(*net.TCPAddr)
+	dstTCP, dstOK := dst.(*net.TCPAddr)
+	if !srcOK || !dstOK {
+		return append(header, proxyProtocolV2CmdLocal, proxyProtocolFamUnspec, 0, 0)
+	}
+
+	var addrs []byte
+	family := byte(proxyProtocolFamTCP6)
+	if src4, dst4 := srcTCP.IP.To4(), dstTCP.IP.To4(); src4 != nil && dst4 != nil {
+		family = proxyProtocolFamTCP4
+		addrs = append(addrs, src4...)
+		addrs = append(addrs, dst4...)
+	} else {
+		addrs = append(addrs, srcTCP.IP.To16()...)
+		addrs = append(addrs, dstTCP.IP.To16()...)
+	}
+	addrs = binary.BigEndian.AppendUint16(addrs, uint16(srcTCP.Port)) // #nosec G115 -- ports fit in uint16
+	addrs = binary.BigEndian.AppendUint16(addrs, uint16(dstTCP.Port)) // #nosec G115 -- ports fit in uint16
+
+	header = append(header, proxyProtocolV2CmdProxy, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(addrs))) // #nosec G115 -- at most 36 bytes
+	return append(header, addrs...)
+}
@@ -0,0 +1,81 @@
+package portforward
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProxyProtocolV2Header_IPv4(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("192.168.1.10"), Port: 51234}
+	dst := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}
+
+	header := buildProxyProtocolV2Header(src, dst)
+
+	expected := append([]byte{}, proxyProtocolV2Signature...)
+	expected = append(expected,
+		0x21, 0x11, 0x00, 0x0C, // PROXY, TCP over IPv4, 12 bytes
+		192, 168, 1, 10, // source address
+		127, 0, 0, 1, // destination address
+		0xC8, 0x22, // source port 51234
+		0x1F, 0x90, // destination port 8080
+	)
+	assert.Equal(t, expected, header)
+}
+
+func TestBuildProxyProtocolV2Header_IPv6(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1000}
+	dst := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 2000}
+
+	header := buildProxyProtocolV2Header(src, dst)
+
+	require.Len(t, header, 16+36)
+	assert.Equal(t, byte(0x21), header[12])
+	assert.Equal(t, byte(0x21), header[13])
+	assert.Equal(t, []byte{0x00, 0x24}, header[14:16])
+	assert.Equal(t, []byte(net.ParseIP("2001:db8::1")), header[16:32])
+}
+
+func TestBuildProxyProtocolV2Header_Local(t *testing.T) {
+	src := &net.UnixAddr{Name: "/tmp/a.sock", Net: "unix"}
+
+	header := buildProxyProtocolV2Header(src, src)
+
+	assert.Equal(t, append(append([]byte{}, proxyProtocolV2Signature...), 0x20, 0x00, 0x00, 0x00), header)
+}
+
+func TestPortForwardManager_ProxyProtocolLabel(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+	}
+
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	require.NoError(t, manager.OnContainerCreated(&monitor.ContainerInfo{
+		ID:     "withlabel",
+		Labels: map[string]string{ProxyProtocolLabel: "true"},
+		Ports:  []monitor.PortMapping{{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"}},
+	}))
+	require.NoError(t, manager.OnContainerCreated(&monitor.ContainerInfo{
+		ID:    "withoutlabel",
+		Ports: []monitor.PortMapping{{ContainerPort: 80, HostPort: 8081, Protocol: "tcp"}},
+	}))
+
+	withLabel, err := manager.GetPortForward("withlabel", 80)
+	require.NoError(t, err)
+	assert.True(t, withLabel.ProxyProtocol)
+
+	withoutLabel, err := manager.GetPortForward("withoutlabel", 80)
+	require.NoError(t, err)
+	assert.False(t, withoutLabel.ProxyProtocol)
+}
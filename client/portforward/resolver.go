@@ -45,12 +45,13 @@ func (pcr *portConflictResolverImpl) ResolvePortConflict(requestedPort int, stra
 		return requestedPort, nil
 	}
 
-	// Port is not available, apply strategy
+	// Port is not available, apply strategy. Replace can only evict DockBridge's own
+	// forwards, which the manager does before resolving; any other process keeps the port.
 	switch strategy {
-	case config.ConflictStrategyIncrement:
+	case config.ConflictStrategyAuto, config.ConflictStrategyIncrement:
 		return pcr.resolveWithIncrementStrategy(requestedPort)
-	case config.ConflictStrategyFail:
-		return 0, pcr.createDockerCompatibleError(requestedPort)
+	case config.ConflictStrategyFail, config.ConflictStrategyReplace:
+		return 0, NewPortInUseError(requestedPort)
 	default:
 		return 0, fmt.Errorf("unknown conflict strategy: %s", strategy)
 	}
@@ -136,8 +137,8 @@ func (pcr *portConflictResolverImpl) resolveWithIncrementStrategy(requestedPort
 	return pcr.GetNextAvailablePort(requestedPort + 1)
 }
 
-// createDockerCompatibleError creates a Docker-compatible error for port conflicts
-func (pcr *portConflictResolverImpl) createDockerCompatibleError(port int) error {
+// NewPortInUseError creates a Docker-compatible error for a local port conflict
+func NewPortInUseError(port int) error {
 	return &DockerAPIError{
 		Message: fmt.Sprintf("driver failed programming external connectivity on endpoint: Error starting userland proxy: listen tcp 0.0.0.0:%d: bind: address already in use (local machine)", port),
 		Code:    "port_already_allocated",
@@ -0,0 +1,233 @@
+package portforward
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LocalBinding is the local address a published container port is forwarded on
+type LocalBinding struct {
+	HostIP   string
+	HostPort int
+}
+
+// LocalBindingLookup returns the local binding of a published container port
+type LocalBindingLookup func(containerID string, containerPort int, protocol string) (LocalBinding, bool)
+
+// RequestedPort is a host port a container create request asks to publish
+type RequestedPort struct {
+	HostPort int
+	Protocol string
+}
+
+// listedPort is a port of a container in a GET /containers/json response
+type listedPort struct {
+	IP          string `json:"IP,omitempty"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+// portBinding is a host binding of a container port in inspect and create payloads
+type portBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// LocalBinding returns the local address a published container port is forwarded on
+func (pfm *portForwardManagerImpl) LocalBinding(containerID string, containerPort int, protocol string) (LocalBinding, bool) {
+	forwardType, err := forwardTypeForProtocol(protocol)
+	if err != nil {
+		return LocalBinding{}, false
+	}
+
+	pfm.mu.RLock()
+	defer pfm.mu.RUnlock()
+
+	for _, forward := range pfm.forwards {
+		if forward.ContainerID == containerID && forward.RemotePort == containerPort && forward.Type == forwardType {
+			hostIP := forward.BindAddress
+			if hostIP == "" {
+				hostIP = DefaultBindAddress
+			}
+			return LocalBinding{HostIP: hostIP, HostPort: forward.LocalPort}, true
+		}
+	}
+	return LocalBinding{}, false
+}
+
+// RewriteContainerList replaces the published ports of a GET /containers/json response
+// with the local addresses they are forwarded on, so docker ps shows where to connect.
+// The IPv4 and IPv6 bindings of a port collapse into one local binding.
+func RewriteContainerList(body []byte, lookup LocalBindingLookup) ([]byte, error) {
+	var containers []map[string]json.RawMessage
+	if err := json.Unmarshal(body, &containers); err != nil {
+		return nil, errors.Wrap(err, "failed to parse container list")
+	}
+
+	changed := false
+	for _, container := range containers {
+		var id string
+		var ports []listedPort
+		if err := json.Unmarshal(container["Id"], &id); err != nil || container["Ports"] == nil {
+			continue
+		}
+		if err := json.Unmarshal(container["Ports"], &ports); err != nil {
+			return nil, errors.Wrap(err, "failed to parse container ports")
+		}
+
+		rewritten := make([]listedPort, 0, len(ports))
+		for _, port := range ports {
+			if port.PublicPort != 0 {
+				if local, ok := lookup(id, port.PrivatePort, port.Type); ok {
+					port.IP = local.HostIP
+					port.PublicPort = local.HostPort
+				}
+			}
+			if !slices.Contains(rewritten, port) {
+				rewritten = append(rewritten, port)
+			}
+		}
+		if slices.Equal(rewritten, ports) {
+			continue
+		}
+
+		encoded, err := json.Marshal(rewritten)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode container ports")
+		}
+		container["Ports"] = encoded
+		changed = true
+	}
+
+	if !changed {
+		return body, nil
+	}
+	return json.Marshal(containers)
+}
+
+// RewriteContainerInspect replaces the host bindings in NetworkSettings.Ports of a
+// GET /containers/{id}/json response with the local addresses they are forwarded on
+func RewriteContainerInspect(body []byte, lookup LocalBindingLookup) ([]byte, error) {
+	var container map[string]json.RawMessage
+	if err := json.Unmarshal(body, &container); err != nil {
+		return nil, errors.Wrap(err, "failed to parse container")
+	}
+
+	var id string
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(container["Id"], &id); err != nil {
+		return body, nil
+	}
+	if err := json.Unmarshal(container["NetworkSettings"], &settings); err != nil || settings["Ports"] == nil {
+		return body, nil
+	}
+
+	var ports map[string][]portBinding
+	if err := json.Unmarshal(settings["Ports"], &ports); err != nil {
+		return nil, errors.Wrap(err, "failed to parse container ports")
+	}
+
+	changed := false
+	for key, bindings := range ports {
+		containerPort, protocol, ok := parsePortKey(key)
+		if !ok || len(bindings) == 0 {
+			continue
+		}
+		local, ok := lookup(id, containerPort, protocol)
+		if !ok {
+			continue
+		}
+		forwarded := []portBinding{{HostIP: local.HostIP, HostPort: strconv.Itoa(local.HostPort)}}
+		if !slices.Equal(bindings, forwarded) {
+			ports[key] = forwarded
+			changed = true
+		}
+	}
+	if !changed {
+		return body, nil
+	}
+
+	encoded, err := json.Marshal(ports)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode container ports")
+	}
+	settings["Ports"] = encoded
+	if container["NetworkSettings"], err = json.Marshal(settings); err != nil {
+		return nil, errors.Wrap(err, "failed to encode network settings")
+	}
+	return json.Marshal(container)
+}
+
+// RequestedHostPorts returns the fixed host ports a POST /containers/create request
+// publishes; ephemeral ports and port ranges are left to the remote daemon
+func RequestedHostPorts(body []byte) ([]RequestedPort, error) {
+	var request struct {
+		HostConfig struct {
+			PortBindings map[string][]portBinding `json:"PortBindings"`
+		} `json:"HostConfig"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, errors.Wrap(err, "failed to parse container create request")
+	}
+
+	var requested []RequestedPort
+	for key, bindings := range request.HostConfig.PortBindings {
+		_, protocol, ok := parsePortKey(key)
+		if !ok {
+			continue
+		}
+		for _, binding := range bindings {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil || hostPort == 0 {
+				continue
+			}
+			requested = append(requested, RequestedPort{HostPort: hostPort, Protocol: protocol})
+		}
+	}
+	return requested, nil
+}
+
+// AddCreateWarnings appends warnings to a POST /containers/create response, which the
+// Docker CLI prints to the user
+func AddCreateWarnings(body []byte, warnings []string) ([]byte, error) {
+	if len(warnings) == 0 {
+		return body, nil
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to parse container create response")
+	}
+
+	var existing []string
+	if raw := response["Warnings"]; raw != nil {
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, errors.Wrap(err, "failed to parse container create warnings")
+		}
+	}
+
+	encoded, err := json.Marshal(append(existing, warnings...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode container create warnings")
+	}
+	response["Warnings"] = encoded
+	return json.Marshal(response)
+}
+
+// parsePortKey splits a Docker port key such as "80/tcp"; the protocol defaults to tcp
+func parsePortKey(key string) (int, string, bool) {
+	portPart, protocol, found := strings.Cut(key, "/")
+	if !found {
+		protocol = "tcp"
+	}
+	port, err := strconv.Atoi(portPart)
+	if err != nil {
+		return 0, "", false
+	}
+	return port, protocol, true
+}
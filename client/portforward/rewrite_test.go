@@ -0,0 +1,105 @@
+package portforward
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remapped forwards port 80/tcp of container "abc" on 127.0.0.1:8080
+func remapped(containerID string, containerPort int, protocol string) (LocalBinding, bool) {
+	if containerID == "abc" && containerPort == 80 && protocol == "tcp" {
+		return LocalBinding{HostIP: "127.0.0.1", HostPort: 8080}, true
+	}
+	return LocalBinding{}, false
+}
+
+func TestRewriteContainerList(t *testing.T) {
+	body := []byte(`[{"Id":"abc","Names":["/web"],"Created":1700000000,"Ports":[` +
+		`{"IP":"0.0.0.0","PrivatePort":80,"PublicPort":80,"Type":"tcp"},` +
+		`{"IP":"::","PrivatePort":80,"PublicPort":80,"Type":"tcp"},` +
+		`{"PrivatePort":443,"Type":"tcp"}]},` +
+		`{"Id":"def","Ports":[{"IP":"0.0.0.0","PrivatePort":80,"PublicPort":81,"Type":"tcp"}]}]`)
+
+	rewritten, err := RewriteContainerList(body, remapped)
+	require.NoError(t, err)
+
+	var containers []struct {
+		ID      string       `json:"Id"`
+		Names   []string     `json:"Names"`
+		Created int64        `json:"Created"`
+		Ports   []listedPort `json:"Ports"`
+	}
+	require.NoError(t, json.Unmarshal(rewritten, &containers))
+	require.Len(t, containers, 2)
+	assert.Equal(t, []string{"/web"}, containers[0].Names)
+	assert.Equal(t, int64(1700000000), containers[0].Created)
+	// The IPv4 and IPv6 bindings collapse into the single local forward
+	assert.Equal(t, []listedPort{
+		{IP: "127.0.0.1", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+		{PrivatePort: 443, Type: "tcp"},
+	}, containers[0].Ports)
+	assert.Equal(t, []listedPort{{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 81, Type: "tcp"}}, containers[1].Ports)
+}
+
+func TestRewriteContainerList_Unchanged(t *testing.T) {
+	body := []byte(`[{"Id":"def","Ports":[]}]`)
+
+	rewritten, err := RewriteContainerList(body, remapped)
+	require.NoError(t, err)
+	assert.Equal(t, body, rewritten)
+}
+
+func TestRewriteContainerInspect(t *testing.T) {
+	body := []byte(`{"Id":"abc","Name":"/web","NetworkSettings":{"IPAddress":"172.17.0.2","Ports":{` +
+		`"80/tcp":[{"HostIp":"0.0.0.0","HostPort":"80"},{"HostIp":"::","HostPort":"80"}],` +
+		`"443/tcp":null}}}`)
+
+	rewritten, err := RewriteContainerInspect(body, remapped)
+	require.NoError(t, err)
+
+	var container struct {
+		Name            string `json:"Name"`
+		NetworkSettings struct {
+			IPAddress string                   `json:"IPAddress"`
+			Ports     map[string][]portBinding `json:"Ports"`
+		} `json:"NetworkSettings"`
+	}
+	require.NoError(t, json.Unmarshal(rewritten, &container))
+	assert.Equal(t, "/web", container.Name)
+	assert.Equal(t, "172.17.0.2", container.NetworkSettings.IPAddress)
+	assert.Equal(t, []portBinding{{HostIP: "127.0.0.1", HostPort: "8080"}}, container.NetworkSettings.Ports["80/tcp"])
+	assert.Contains(t, container.NetworkSettings.Ports, "443/tcp")
+	assert.Nil(t, container.NetworkSettings.Ports["443/tcp"])
+}
+
+func TestRequestedHostPorts(t *testing.T) {
+	body := []byte(`{"Image":"nginx","HostConfig":{"PortBindings":{` +
+		`"80/tcp":[{"HostIp":"","HostPort":"8080"}],` +
+		`"53/udp":[{"HostPort":"5353"}],` +
+		`"9000/tcp":[{"HostPort":""}]}}}`)
+
+	requested, err := RequestedHostPorts(body)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []RequestedPort{
+		{HostPort: 8080, Protocol: "tcp"},
+		{HostPort: 5353, Protocol: "udp"},
+	}, requested)
+}
+
+func TestAddCreateWarnings(t *testing.T) {
+	body := []byte(`{"Id":"abc","Warnings":["existing"]}`)
+
+	rewritten, err := AddCreateWarnings(body, []string{"remapped"})
+	require.NoError(t, err)
+
+	var response struct {
+		ID       string   `json:"Id"`
+		Warnings []string `json:"Warnings"`
+	}
+	require.NoError(t, json.Unmarshal(rewritten, &response))
+	assert.Equal(t, "abc", response.ID)
+	assert.Equal(t, []string{"existing", "remapped"}, response.Warnings)
+}
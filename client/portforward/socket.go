@@ -0,0 +1,89 @@
+package portforward
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+)
+
+// SocketLabelPrefix is the container label prefix used to request Unix socket forwarding.
+// The label value has the form "<remote path>[:<local path>]", for example:
+//
+//	dockbridge.socket.postgres=/var/run/postgresql/.s.PGSQL.5432:/tmp/pg.sock
+//
+// The remote path refers to the socket on the remote host (typically bind-mounted
+// out of the container). When the local path is omitted, the socket is created in
+// the configured socket directory as "<container name>-<name>.sock".
+const SocketLabelPrefix = "dockbridge.socket."
+
+// SocketMapping describes a Unix socket forward requested by a container label
+type SocketMapping struct {
+	Name       string `json:"name"`
+	RemotePath string `json:"remote_path"`
+	LocalPath  string `json:"local_path"`
+}
+
+// ParseSocketLabels extracts Unix socket forwards from container labels.
+// Mappings are returned sorted by name so results are deterministic.
+func ParseSocketLabels(container *monitor.ContainerInfo, socketDir string) ([]SocketMapping, error) {
+	var mappings []SocketMapping
+
+	for key, value := range container.Labels {
+		if !strings.HasPrefix(key, SocketLabelPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, SocketLabelPrefix)
+		if name == "" || strings.ContainsAny(name, "/\\") {
+			return nil, fmt.Errorf("invalid socket label %q: name must be non-empty and must not contain path separators", key)
+		}
+
+		remotePath, localPath, _ := strings.Cut(value, ":")
+		if !filepath.IsAbs(remotePath) {
+			return nil, fmt.Errorf("invalid socket label %q: remote path %q must be absolute", key, remotePath)
+		}
+
+		if localPath == "" {
+			localPath = filepath.Join(socketDir, fmt.Sprintf("%s-%s.sock", socketContainerName(container), name))
+		}
+		localPath = expandHome(localPath)
+
+		mappings = append(mappings, SocketMapping{
+			Name:       name,
+			RemotePath: remotePath,
+			LocalPath:  localPath,
+		})
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].Name < mappings[j].Name
+	})
+
+	return mappings, nil
+}
+
+// socketContainerName returns a file-name friendly identifier for the container
+func socketContainerName(container *monitor.ContainerInfo) string {
+	name := strings.TrimPrefix(container.Name, "/")
+	if name == "" {
+		name = container.ID
+		if len(name) > 12 {
+			name = name[:12]
+		}
+	}
+	return name
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,86 @@
+package portforward
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/monitor"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSocketLabels(t *testing.T) {
+	container := &monitor.ContainerInfo{
+		ID:   "abcdef1234567890",
+		Name: "/db",
+		Labels: map[string]string{
+			"dockbridge.socket.pg":    "/var/run/postgresql/.s.PGSQL.5432:/tmp/pg.sock",
+			"dockbridge.socket.redis": "/var/run/redis/redis.sock",
+			"com.example.other":       "ignored",
+		},
+	}
+
+	mappings, err := ParseSocketLabels(container, "/sockets")
+	require.NoError(t, err)
+	require.Len(t, mappings, 2)
+
+	assert.Equal(t, SocketMapping{Name: "pg", RemotePath: "/var/run/postgresql/.s.PGSQL.5432", LocalPath: "/tmp/pg.sock"}, mappings[0])
+	assert.Equal(t, SocketMapping{Name: "redis", RemotePath: "/var/run/redis/redis.sock", LocalPath: filepath.Join("/sockets", "db-redis.sock")}, mappings[1])
+}
+
+func TestParseSocketLabels_Invalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"relative remote path": {"dockbridge.socket.app": "run/app.sock"},
+		"empty name":           {"dockbridge.socket.": "/run/app.sock"},
+		"name with separator":  {"dockbridge.socket.a/b": "/run/app.sock"},
+	}
+
+	for name, labels := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSocketLabels(&monitor.ContainerInfo{ID: "abc", Labels: labels}, "/sockets")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPortForwardManager_SocketForwards(t *testing.T) {
+	cfg := &config.PortForwardConfig{
+		Enabled:          true,
+		ConflictStrategy: config.ConflictStrategyIncrement,
+		MonitorInterval:  30 * time.Second,
+		SocketDir:        "/sockets",
+	}
+
+	manager := NewPortForwardManager(cfg, createTestLogger())
+	require.NoError(t, manager.Start(context.Background()))
+	defer manager.Stop()
+
+	container := &monitor.ContainerInfo{
+		ID:     "container123456789",
+		Name:   "db",
+		Labels: map[string]string{"dockbridge.socket.pg": "/var/run/pg.sock"},
+	}
+	require.NoError(t, manager.OnContainerCreated(container))
+
+	forwards, err := manager.ListPortForwards()
+	require.NoError(t, err)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, ForwardTypeUnix, forwards[0].Type)
+	assert.Equal(t, "/var/run/pg.sock", forwards[0].RemoteSocket)
+	assert.Equal(t, filepath.Join("/sockets", "db-pg.sock"), forwards[0].LocalSocket)
+
+	// A manual forward to the same local path must be rejected
+	err = manager.AddSocketForward(container.ID, SocketMapping{Name: "other", RemotePath: "/var/run/other.sock", LocalPath: forwards[0].LocalSocket})
+	assert.Error(t, err)
+
+	require.NoError(t, manager.AddSocketForward(container.ID, SocketMapping{RemotePath: "/var/run/app.sock", LocalPath: "/tmp/app.sock"}))
+	require.NoError(t, manager.RemoveSocketForward(container.ID, "/tmp/app.sock"))
+
+	require.NoError(t, manager.OnContainerRemoved(container.ID))
+	forwards, err = manager.ListPortForwards()
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+}
@@ -0,0 +1,415 @@
+package portforward
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	// udpSessionIdleTimeout closes the relay of a local client that sent nothing for a while
+	udpSessionIdleTimeout = 2 * time.Minute
+
+	// maxDatagramSize is the largest UDP payload that can be relayed
+	maxDatagramSize = 65535
+)
+
+// udpRelayScript relays datagrams between its stdin/stdout and a UDP address on the
+// remote host. Every datagram is framed with a 2-byte big-endian length. It exits when
+// stdin is closed, i.e. when the SSH session ends.
+const udpRelayScript = `import os,socket,struct,sys,threading
+a=socket.getaddrinfo(sys.argv[1],int(sys.argv[2]),0,socket.SOCK_DGRAM)[0]
+s=socket.socket(a[0],socket.SOCK_DGRAM);s.connect(a[4])
+i=sys.stdin.buffer;o=sys.stdout.buffer
+def up():
+ while True:
+  h=i.read(2)
+  if len(h)<2:os._exit(0)
+  try:s.send(i.read(struct.unpack(">H",h)[0]))
+  except OSError:pass
+threading.Thread(target=up,daemon=True).start()
+while True:
+ try:d=s.recv(65535)
+ except OSError:continue
+ o.write(struct.pack(">H",len(d))+d);o.flush()`
+
+// udpRelayCommand returns the remote command relaying datagrams to remoteAddr
+func udpRelayCommand(remoteAddr string) (string, error) {
+	host, port, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid remote address %q", remoteAddr)
+	}
+	if _, err := strconv.Atoi(port); err != nil || strings.ContainsAny(host, "'\\ ") {
+		return "", fmt.Errorf("invalid remote address %q", remoteAddr)
+	}
+	return fmt.Sprintf("python3 -u -c '%s' '%s' %s", udpRelayScript, host, port), nil
+}
+
+// writeDatagram writes a length-framed datagram
+func writeDatagram(w io.Writer, payload []byte) error {
+	if len(payload) > maxDatagramSize {
+		return fmt.Errorf("datagram of %d bytes exceeds %d bytes", len(payload), maxDatagramSize)
+	}
+	frame := make([]byte, 2+len(payload))
+	binary.BigEndian.PutUint16(frame, uint16(len(payload)))
+	copy(frame[2:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readDatagram reads a length-framed datagram into buf, which must hold maxDatagramSize bytes
+func readDatagram(r io.Reader, buf []byte) ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint16(header[:]))
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+// LocalUDPProxyServer defines the interface for local UDP proxy servers. Each local
+// client address gets its own relay session on the remote host, so replies reach the
+// client that sent the request.
+type LocalUDPProxyServer interface {
+	Start(ctx context.Context, localPort int, remoteAddr string) error
+	Stop() error
+	GetStats() *ProxyStats
+	IsRunning() bool
+
+	// SetBindAddress sets the local address to listen on; takes effect on the next Start
+	SetBindAddress(address string)
+}
+
+// udpSession relays the datagrams of one local client
+type udpSession struct {
+	client   net.Addr
+	stream   io.ReadWriteCloser
+	lastUsed atomic.Int64 // Unix nano timestamp
+	writeMu  sync.Mutex
+}
+
+// localUDPProxyServerImpl implements LocalUDPProxyServer
+type localUDPProxyServerImpl struct {
+	sshClient ssh.Client
+	logger    logger.LoggerInterface
+
+	// Configuration
+	localPort   int
+	remoteAddr  string
+	command     string
+	bindAddress string
+
+	// Network components
+	conn net.PacketConn
+
+	// State management
+	running   bool
+	startTime time.Time
+	sessions  map[string]*udpSession
+	mu        sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	// Statistics (using atomic operations for thread safety)
+	totalSessions    int64
+	bytesTransferred int64
+	lastActivity     int64 // Unix timestamp
+}
+
+// NewLocalUDPProxyServer creates a new local UDP proxy server
+func NewLocalUDPProxyServer(sshClient ssh.Client, logger logger.LoggerInterface) LocalUDPProxyServer {
+	return &localUDPProxyServerImpl{
+		sshClient: sshClient,
+		logger:    logger,
+	}
+}
+
+// Start listens on the specified local UDP port, relaying datagrams to remoteAddr
+func (ups *localUDPProxyServerImpl) Start(ctx context.Context, localPort int, remoteAddr string) error {
+	ups.mu.Lock()
+	defer ups.mu.Unlock()
+
+	if ups.running {
+		return fmt.Errorf("proxy server is already running")
+	}
+
+	if !ups.sshClient.IsConnected() {
+		return fmt.Errorf("SSH client is not connected")
+	}
+
+	command, err := udpRelayCommand(remoteAddr)
+	if err != nil {
+		return err
+	}
+
+	bindAddress := ups.bindAddress
+	if bindAddress == "" {
+		bindAddress = DefaultBindAddress
+	}
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(bindAddress, strconv.Itoa(localPort)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s UDP port %d", bindAddress, localPort)
+	}
+
+	ups.conn = conn
+	ups.localPort = localPort
+	if udpAddr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		ups.localPort = udpAddr.Port
+	}
+	ups.remoteAddr = remoteAddr
+	ups.command = command
+	ups.sessions = make(map[string]*udpSession)
+	ups.ctx, ups.cancel = context.WithCancel(ctx)
+	ups.running = true
+	ups.startTime = time.Now()
+	atomic.StoreInt64(&ups.lastActivity, time.Now().Unix())
+
+	ups.wg.Add(2)
+	go func() {
+		defer ups.wg.Done()
+		ups.readDatagrams()
+	}()
+	go func() {
+		defer ups.wg.Done()
+		ups.expireSessions()
+	}()
+
+	ups.logger.WithFields(map[string]any{
+		"bind_address": bindAddress,
+		"local_port":   ups.localPort,
+		"remote_addr":  remoteAddr,
+	}).Info("Local UDP proxy server started")
+
+	return nil
+}
+
+// Stop stops the proxy server and closes all relay sessions
+func (ups *localUDPProxyServerImpl) Stop() error {
+	ups.mu.Lock()
+	if !ups.running {
+		ups.mu.Unlock()
+		return nil
+	}
+
+	ups.cancel()
+	if err := ups.conn.Close(); err != nil {
+		ups.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Error("Error closing UDP socket")
+	}
+	for key, session := range ups.sessions {
+		session.stream.Close()
+		delete(ups.sessions, key)
+	}
+	ups.running = false
+	ups.mu.Unlock()
+
+	// Session readers take the lock when they end, so wait without holding it
+	ups.wg.Wait()
+
+	ups.logger.WithFields(map[string]any{
+		"local_port":        ups.localPort,
+		"remote_addr":       ups.remoteAddr,
+		"total_sessions":    atomic.LoadInt64(&ups.totalSessions),
+		"bytes_transferred": atomic.LoadInt64(&ups.bytesTransferred),
+		"uptime":            time.Since(ups.startTime),
+	}).Info("Local UDP proxy server stopped")
+
+	return nil
+}
+
+// GetStats returns current proxy statistics; active connections are relay sessions
+func (ups *localUDPProxyServerImpl) GetStats() *ProxyStats {
+	ups.mu.RLock()
+	defer ups.mu.RUnlock()
+
+	var uptime time.Duration
+	if ups.running {
+		uptime = time.Since(ups.startTime)
+	}
+
+	return &ProxyStats{
+		LocalPort:         ups.localPort,
+		RemoteAddr:        ups.remoteAddr,
+		ActiveConnections: int32(len(ups.sessions)),
+		TotalConnections:  atomic.LoadInt64(&ups.totalSessions),
+		BytesTransferred:  atomic.LoadInt64(&ups.bytesTransferred),
+		LastActivity:      time.Unix(atomic.LoadInt64(&ups.lastActivity), 0),
+		Uptime:            uptime,
+	}
+}
+
+// IsRunning returns true if the proxy server is currently running
+func (ups *localUDPProxyServerImpl) IsRunning() bool {
+	ups.mu.RLock()
+	defer ups.mu.RUnlock()
+	return ups.running
+}
+
+// SetBindAddress sets the local address to listen on; takes effect on the next Start
+func (ups *localUDPProxyServerImpl) SetBindAddress(address string) {
+	ups.mu.Lock()
+	defer ups.mu.Unlock()
+	ups.bindAddress = address
+}
+
+// readDatagrams reads datagrams from local clients and sends them through their sessions
+func (ups *localUDPProxyServerImpl) readDatagrams() {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, client, err := ups.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			ups.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Error("Error reading UDP datagram")
+			continue
+		}
+
+		session, err := ups.sessionFor(client)
+		if err != nil {
+			ups.logger.WithFields(map[string]any{
+				"client":      client.String(),
+				"remote_addr": ups.remoteAddr,
+				"error":       err.Error(),
+			}).Error("Failed to open UDP relay session")
+			continue
+		}
+
+		session.writeMu.Lock()
+		err = writeDatagram(session.stream, buf[:n])
+		session.writeMu.Unlock()
+		if err != nil {
+			ups.closeSession(client.String(), session)
+			continue
+		}
+		ups.recordTraffic(session, n)
+	}
+}
+
+// sessionFor returns the relay session of a local client, opening one if needed
+func (ups *localUDPProxyServerImpl) sessionFor(client net.Addr) (*udpSession, error) {
+	key := client.String()
+
+	ups.mu.RLock()
+	session, ok := ups.sessions[key]
+	ups.mu.RUnlock()
+	if ok {
+		return session, nil
+	}
+
+	stream, err := ups.sshClient.OpenStream(ups.ctx, ups.command)
+	if err != nil {
+		return nil, err
+	}
+	session = &udpSession{client: client, stream: stream}
+	session.lastUsed.Store(time.Now().UnixNano())
+
+	ups.mu.Lock()
+	if !ups.running {
+		ups.mu.Unlock()
+		stream.Close()
+		return nil, fmt.Errorf("proxy server is stopped")
+	}
+	ups.sessions[key] = session
+	ups.wg.Add(1)
+	ups.mu.Unlock()
+	atomic.AddInt64(&ups.totalSessions, 1)
+
+	go func() {
+		defer ups.wg.Done()
+		ups.relayReplies(key, session)
+	}()
+
+	ups.logger.WithFields(map[string]any{
+		"client":      key,
+		"remote_addr": ups.remoteAddr,
+	}).Debug("Opened UDP relay session")
+
+	return session, nil
+}
+
+// relayReplies sends datagrams from the remote relay back to the local client
+func (ups *localUDPProxyServerImpl) relayReplies(key string, session *udpSession) {
+	defer ups.closeSession(key, session)
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		payload, err := readDatagram(session.stream, buf)
+		if err != nil {
+			return
+		}
+		if _, err := ups.conn.WriteTo(payload, session.client); err != nil {
+			return
+		}
+		ups.recordTraffic(session, len(payload))
+	}
+}
+
+// expireSessions closes sessions whose clients have been idle for udpSessionIdleTimeout
+func (ups *localUDPProxyServerImpl) expireSessions() {
+	ticker := time.NewTicker(udpSessionIdleTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ups.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cutoff := time.Now().Add(-udpSessionIdleTimeout).UnixNano()
+		ups.mu.RLock()
+		var idle []string
+		for key, session := range ups.sessions {
+			if session.lastUsed.Load() < cutoff {
+				idle = append(idle, key)
+			}
+		}
+		ups.mu.RUnlock()
+
+		for _, key := range idle {
+			ups.mu.RLock()
+			session := ups.sessions[key]
+			ups.mu.RUnlock()
+			if session != nil {
+				ups.closeSession(key, session)
+			}
+		}
+	}
+}
+
+// closeSession closes a relay session and forgets it
+func (ups *localUDPProxyServerImpl) closeSession(key string, session *udpSession) {
+	ups.mu.Lock()
+	if ups.sessions[key] == session {
+		delete(ups.sessions, key)
+	}
+	ups.mu.Unlock()
+	session.stream.Close()
+}
+
+// recordTraffic updates the statistics for n relayed bytes
+func (ups *localUDPProxyServerImpl) recordTraffic(session *udpSession, n int) {
+	now := time.Now()
+	session.lastUsed.Store(now.UnixNano())
+	atomic.AddInt64(&ups.bytesTransferred, int64(n))
+	atomic.StoreInt64(&ups.lastActivity, now.Unix())
+}
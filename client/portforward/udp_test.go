@@ -0,0 +1,137 @@
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatagramFraming(t *testing.T) {
+	var stream bytes.Buffer
+	require.NoError(t, writeDatagram(&stream, []byte("query")))
+	require.NoError(t, writeDatagram(&stream, nil))
+
+	buf := make([]byte, maxDatagramSize)
+	payload, err := readDatagram(&stream, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "query", string(payload))
+
+	payload, err = readDatagram(&stream, buf)
+	require.NoError(t, err)
+	assert.Empty(t, payload)
+
+	_, err = readDatagram(&stream, buf)
+	assert.ErrorIs(t, err, io.EOF)
+
+	assert.Error(t, writeDatagram(&stream, make([]byte, maxDatagramSize+1)))
+}
+
+func TestUDPRelayCommand(t *testing.T) {
+	command, err := udpRelayCommand("127.0.0.1:53")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(command, "python3 -u -c '"))
+	assert.True(t, strings.HasSuffix(command, "' '127.0.0.1' 53"))
+
+	_, err = udpRelayCommand("127.0.0.1")
+	assert.Error(t, err)
+	_, err = udpRelayCommand("x';reboot;':53")
+	assert.Error(t, err)
+}
+
+// relaySSHClient runs the UDP relay in-process instead of on a remote host
+type relaySSHClient struct {
+	ssh.Client
+	target string
+}
+
+func (c *relaySSHClient) IsConnected() bool { return true }
+
+func (c *relaySSHClient) OpenStream(ctx context.Context, command string) (io.ReadWriteCloser, error) {
+	local, remote := net.Pipe()
+	conn, err := net.Dial("udp", c.target)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer conn.Close()
+		buf := make([]byte, maxDatagramSize)
+		for {
+			payload, err := readDatagram(remote, buf)
+			if err != nil {
+				return
+			}
+			if _, err := conn.Write(payload); err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			if err := writeDatagram(remote, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+	return local, nil
+}
+
+func TestLocalUDPProxyServer(t *testing.T) {
+	// A UDP echo server stands in for the container port
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echo.Close()
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := echo.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = echo.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	proxy := NewLocalUDPProxyServer(&relaySSHClient{target: echo.LocalAddr().String()}, createTestLogger())
+	require.NoError(t, proxy.Start(context.Background(), 0, echo.LocalAddr().String()))
+	defer proxy.Stop()
+
+	localAddr := net.JoinHostPort(DefaultBindAddress, strconv.Itoa(proxy.GetStats().LocalPort))
+
+	for _, message := range []string{"first", "second"} {
+		client, err := net.Dial("udp", localAddr)
+		require.NoError(t, err)
+
+		_, err = client.Write([]byte(message))
+		require.NoError(t, err)
+
+		buf := make([]byte, 64)
+		require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+		n, err := client.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, message, string(buf[:n]))
+		client.Close()
+	}
+
+	stats := proxy.GetStats()
+	assert.Equal(t, int64(2), stats.TotalConnections, "each client gets its own relay session")
+	assert.Equal(t, int64(len("first")+len("second"))*2, stats.BytesTransferred)
+
+	require.NoError(t, proxy.Stop())
+	assert.False(t, proxy.IsRunning())
+	assert.Zero(t, proxy.GetStats().ActiveConnections)
+}
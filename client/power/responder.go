@@ -0,0 +1,106 @@
+package power
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+// HeartbeatSender sends keep-alive heartbeats to the remote server.
+// keepalive.HeartbeatClient satisfies this interface.
+type HeartbeatSender interface {
+	SendHeartbeat() error
+	SendHeartbeatWithSleepHint(expectedSleep time.Duration) error
+}
+
+// KeepAliveResponder reacts to power events on behalf of the keep-alive and tunnel logic:
+// it flushes a heartbeat with a sleep hint before suspend, and after resume or a network
+// change it re-validates the tunnel and sends a heartbeat right away.
+type KeepAliveResponder struct {
+	// Heartbeat returns the sender for the current server, or nil if none is connected
+	Heartbeat func() HeartbeatSender
+
+	// Revalidate checks the tunnel and reconnects it if needed
+	Revalidate func(ctx context.Context) error
+
+	// SleepHint is the expected sleep duration announced before suspend
+	SleepHint time.Duration
+
+	// Timeout bounds the revalidation performed after wake
+	Timeout time.Duration
+
+	Logger logger.LoggerInterface
+}
+
+// OnSleep flushes a final heartbeat with an expected-sleep hint
+func (r *KeepAliveResponder) OnSleep() {
+	sender := r.sender()
+	if sender == nil {
+		return
+	}
+	if err := sender.SendHeartbeatWithSleepHint(r.SleepHint); err != nil {
+		r.Logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to send pre-suspend heartbeat")
+		return
+	}
+	r.Logger.WithFields(map[string]any{
+		"sleep_hint": r.SleepHint,
+	}).Info("Sent pre-suspend heartbeat")
+}
+
+// OnWake re-validates the tunnel and sends a heartbeat immediately
+func (r *KeepAliveResponder) OnWake(sleptFor time.Duration) {
+	r.refresh("wake")
+}
+
+// OnNetworkChange re-validates the tunnel and sends a heartbeat immediately
+func (r *KeepAliveResponder) OnNetworkChange() {
+	r.refresh("network_change")
+}
+
+// refresh revalidates the connection and then sends a heartbeat
+func (r *KeepAliveResponder) refresh(reason string) {
+	if r.Revalidate != nil {
+		timeout := r.Timeout
+		if timeout == 0 {
+			timeout = 60 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := r.Revalidate(ctx)
+		cancel()
+		if err != nil {
+			r.Logger.WithFields(map[string]any{
+				"reason": reason,
+				"error":  err.Error(),
+			}).Warn("Failed to revalidate tunnel")
+		}
+	}
+
+	sender := r.sender()
+	if sender == nil {
+		return
+	}
+	if err := sender.SendHeartbeat(); err != nil {
+		r.Logger.WithFields(map[string]any{
+			"reason": reason,
+			"error":  err.Error(),
+		}).Warn("Failed to send heartbeat")
+		return
+	}
+	r.Logger.WithFields(map[string]any{
+		"reason": reason,
+	}).Info("Heartbeat sent after power event")
+}
+
+// sender returns the current heartbeat sender, if any
+func (r *KeepAliveResponder) sender() HeartbeatSender {
+	if r.Heartbeat == nil {
+		return nil
+	}
+	return r.Heartbeat()
+}
+
+// Ensure KeepAliveResponder implements EventHandler
+var _ EventHandler = (*KeepAliveResponder)(nil)
@@ -0,0 +1,70 @@
+//go:build darwin
+
+package power
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// sysctlPollInterval is how often the kernel's wake time is read
+const sysctlPollInterval = 2 * time.Second
+
+// timevalPattern matches the seconds of a timeval printed by sysctl, e.g.
+// "{ sec = 1700000000, usec = 123456 } Tue Nov 14 22:13:20 2023"
+var timevalPattern = regexp.MustCompile(`sec = (\d+)`)
+
+// watchSleepSignals follows the kernel's last sleep and wake times (kern.sleeptime and
+// kern.waketime), which change on every suspend and resume. Suspend itself cannot be
+// announced this way, so onSleep is never called. It blocks until ctx is cancelled and
+// returns an error if the times cannot be read.
+func watchSleepSignals(ctx context.Context, onSleep func(), onWake func(time.Duration)) error {
+	lastWake, err := sysctlTime(ctx, "kern.waketime")
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(sysctlPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			wake, err := sysctlTime(ctx, "kern.waketime")
+			if err != nil || !wake.After(lastWake) {
+				continue
+			}
+			lastWake = wake
+
+			var sleptFor time.Duration
+			if slept, err := sysctlTime(ctx, "kern.sleeptime"); err == nil && wake.After(slept) {
+				sleptFor = wake.Sub(slept)
+			}
+			onWake(sleptFor)
+		}
+	}
+}
+
+// sysctlTime reads a timeval sysctl
+func sysctlTime(ctx context.Context, name string) (time.Time, error) {
+	// #nosec G204 -- fixed arguments
+	output, err := exec.CommandContext(ctx, "sysctl", "-n", name).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	match := timevalPattern.FindSubmatch(output)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("unexpected %s value %q", name, output)
+	}
+	sec, err := strconv.ParseInt(string(match[1]), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected %s value %q", name, output)
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,55 @@
+//go:build linux
+
+package power
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// watchSleepSignals follows systemd-logind's PrepareForSleep signal via dbus-monitor.
+// The signal carries "true" before suspend and "false" after resume. It blocks until
+// ctx is cancelled and returns an error if the signal cannot be observed.
+func watchSleepSignals(ctx context.Context, onSleep func(), onWake func(time.Duration)) error {
+	path, err := exec.LookPath("dbus-monitor")
+	if err != nil {
+		return fmt.Errorf("dbus-monitor not found: %w", err)
+	}
+
+	// #nosec G204 -- fixed arguments
+	cmd := exec.CommandContext(ctx, path, "--system",
+		"type='signal',interface='org.freedesktop.login1.Manager',member='PrepareForSleep'")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to attach to dbus-monitor: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start dbus-monitor: %w", err)
+	}
+
+	var suspendedAt time.Time
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "boolean true":
+			suspendedAt = time.Now()
+			onSleep()
+		case "boolean false":
+			var sleptFor time.Duration
+			if !suspendedAt.IsZero() {
+				sleptFor = time.Now().Round(0).Sub(suspendedAt.Round(0))
+			}
+			onWake(sleptFor)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("dbus-monitor exited: %w", err)
+	}
+	return nil
+}
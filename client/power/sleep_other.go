@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package power
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// watchSleepSignals is not implemented on this platform; resume is still detected
+// from clock gaps by the poll loop.
+func watchSleepSignals(ctx context.Context, onSleep func(), onWake func(time.Duration)) error {
+	return errors.New("suspend notifications are not supported on this platform")
+}
@@ -0,0 +1,245 @@
+// Package power detects laptop suspend/resume and network changes on the client so
+// that keep-alive and tunnel logic can react deliberately instead of relying on
+// timers that did not run while the machine was asleep.
+package power
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+// EventHandler receives power and network events
+type EventHandler interface {
+	// OnSleep is called when the OS announces an imminent suspend (where supported)
+	OnSleep()
+
+	// OnWake is called after resume; sleptFor is the best estimate of time spent suspended
+	OnWake(sleptFor time.Duration)
+
+	// OnNetworkChange is called when the set of local interface addresses changes
+	OnNetworkChange()
+}
+
+// Watcher watches for suspend/resume and network changes
+type Watcher interface {
+	Start(ctx context.Context) error
+	Stop() error
+	RegisterHandler(handler EventHandler)
+}
+
+// WatcherConfig holds configuration for the power watcher
+type WatcherConfig struct {
+	// CheckInterval is how often clocks and interfaces are sampled
+	CheckInterval time.Duration
+
+	// WakeThreshold is the minimum unexplained clock gap treated as a suspend
+	WakeThreshold time.Duration
+}
+
+// DefaultWatcherConfig returns the default watcher configuration
+func DefaultWatcherConfig() *WatcherConfig {
+	return &WatcherConfig{
+		CheckInterval: 5 * time.Second,
+		WakeThreshold: 15 * time.Second,
+	}
+}
+
+// watcherImpl implements Watcher
+type watcherImpl struct {
+	config   *WatcherConfig
+	logger   logger.LoggerInterface
+	handlers []EventHandler
+
+	// listAddrs returns a fingerprint of local interface addresses (replaceable in tests)
+	listAddrs func() (string, error)
+
+	mu      sync.RWMutex
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	// lastWake is when the last wake was emitted, to report a resume seen by both the
+	// OS and the clock gap detection once
+	lastWake time.Time
+}
+
+// NewWatcher creates a new power watcher
+func NewWatcher(config *WatcherConfig, logger logger.LoggerInterface) Watcher {
+	if config == nil {
+		config = DefaultWatcherConfig()
+	}
+	return &watcherImpl{
+		config:    config,
+		logger:    logger,
+		listAddrs: interfaceFingerprint,
+	}
+}
+
+// Start begins watching for power and network events
+func (w *watcherImpl) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return fmt.Errorf("power watcher is already running")
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.running = true
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.pollLoop(ctx)
+	}()
+
+	// Platform-specific suspend notifications (best effort)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := watchSleepSignals(ctx, w.emitSleep, w.emitWake); err != nil {
+			w.logger.WithFields(map[string]any{
+				"error": err.Error(),
+			}).Debug("OS suspend notifications unavailable, relying on clock gap detection")
+		}
+	}()
+
+	w.logger.WithFields(map[string]any{
+		"check_interval": w.config.CheckInterval,
+		"wake_threshold": w.config.WakeThreshold,
+	}).Info("Power watcher started")
+
+	return nil
+}
+
+// Stop stops the watcher
+func (w *watcherImpl) Stop() error {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return nil
+	}
+	w.cancel()
+	w.running = false
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	w.logger.Info("Power watcher stopped")
+	return nil
+}
+
+// RegisterHandler registers a handler for power events
+func (w *watcherImpl) RegisterHandler(handler EventHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// pollLoop samples the clocks and interface addresses
+func (w *watcherImpl) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	lastAddrs, _ := w.listAddrs()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+
+			// The monotonic clock stops while suspended but the wall clock does not,
+			// so a gap between them means we were asleep.
+			wallElapsed := now.Round(0).Sub(last.Round(0))
+			monoElapsed := now.Sub(last)
+			if gap := suspendGap(wallElapsed, monoElapsed, w.config.CheckInterval); gap >= w.config.WakeThreshold {
+				w.emitWake(gap)
+			}
+			last = now
+
+			addrs, err := w.listAddrs()
+			if err == nil && addrs != lastAddrs {
+				lastAddrs = addrs
+				w.emitNetworkChange()
+			}
+		}
+	}
+}
+
+// suspendGap estimates how long the process was suspended between two samples.
+// It considers both the wall/monotonic divergence and ticks that arrived late.
+func suspendGap(wallElapsed, monoElapsed, interval time.Duration) time.Duration {
+	return max(wallElapsed-monoElapsed, monoElapsed-interval, 0)
+}
+
+// snapshotHandlers returns a copy of the registered handlers
+func (w *watcherImpl) snapshotHandlers() []EventHandler {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return append([]EventHandler(nil), w.handlers...)
+}
+
+func (w *watcherImpl) emitSleep() {
+	w.logger.Info("System is about to suspend")
+	for _, h := range w.snapshotHandlers() {
+		h.OnSleep()
+	}
+}
+
+func (w *watcherImpl) emitWake(sleptFor time.Duration) {
+	w.mu.Lock()
+	now := time.Now().Round(0)
+	duplicate := !w.lastWake.IsZero() && now.Sub(w.lastWake) < 2*w.config.CheckInterval
+	w.lastWake = now
+	w.mu.Unlock()
+	if duplicate {
+		return
+	}
+
+	w.logger.WithFields(map[string]any{
+		"slept_for": sleptFor,
+	}).Info("System resumed from suspend")
+	for _, h := range w.snapshotHandlers() {
+		h.OnWake(sleptFor)
+	}
+}
+
+func (w *watcherImpl) emitNetworkChange() {
+	w.logger.Info("Network interfaces changed")
+	for _, h := range w.snapshotHandlers() {
+		h.OnNetworkChange()
+	}
+}
+
+// interfaceFingerprint returns a stable string describing all up, non-loopback interface addresses
+func interfaceFingerprint() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	var entries []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			entries = append(entries, iface.Name+"="+addr.String())
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ","), nil
+}
@@ -0,0 +1,147 @@
+package power
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestLogger() logger.LoggerInterface {
+	testLogger := logger.NewDefault()
+	testLogger.SetOutput(io.Discard)
+	return testLogger
+}
+
+type recordingHandler struct {
+	mu             sync.Mutex
+	sleeps         int
+	wakes          []time.Duration
+	networkChanges int
+}
+
+func (h *recordingHandler) OnSleep() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sleeps++
+}
+
+func (h *recordingHandler) OnWake(sleptFor time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.wakes = append(h.wakes, sleptFor)
+}
+
+func (h *recordingHandler) OnNetworkChange() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.networkChanges++
+}
+
+func TestSuspendGap(t *testing.T) {
+	interval := 5 * time.Second
+
+	assert.Equal(t, time.Duration(0), suspendGap(5*time.Second, 5*time.Second, interval))
+	assert.Equal(t, 10*time.Minute, suspendGap(10*time.Minute+5*time.Second, 5*time.Second, interval))
+	assert.Equal(t, 55*time.Second, suspendGap(time.Minute, time.Minute, interval))
+	assert.Equal(t, time.Duration(0), suspendGap(4*time.Second, 5*time.Second, interval))
+}
+
+func TestWatcher_NetworkChange(t *testing.T) {
+	w := NewWatcher(&WatcherConfig{CheckInterval: 10 * time.Millisecond, WakeThreshold: time.Hour}, createTestLogger()).(*watcherImpl)
+
+	var calls atomic.Int32
+	w.listAddrs = func() (string, error) {
+		if calls.Add(1) <= 2 {
+			return "eth0=10.0.0.2/24", nil
+		}
+		return "wlan0=192.168.1.20/24", nil
+	}
+
+	handler := &recordingHandler{}
+	w.RegisterHandler(handler)
+
+	require.NoError(t, w.Start(context.Background()))
+	assert.Eventually(t, func() bool {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+		return handler.networkChanges == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Error(t, w.Start(context.Background()), "starting twice should fail")
+	require.NoError(t, w.Stop())
+}
+
+type mockHeartbeat struct {
+	plain  int
+	hinted []time.Duration
+	err    error
+}
+
+func (m *mockHeartbeat) SendHeartbeat() error {
+	m.plain++
+	return m.err
+}
+
+func (m *mockHeartbeat) SendHeartbeatWithSleepHint(expectedSleep time.Duration) error {
+	m.hinted = append(m.hinted, expectedSleep)
+	return m.err
+}
+
+func TestKeepAliveResponder(t *testing.T) {
+	hb := &mockHeartbeat{}
+	var revalidations int
+	responder := &KeepAliveResponder{
+		Heartbeat: func() HeartbeatSender { return hb },
+		Revalidate: func(ctx context.Context) error {
+			revalidations++
+			return nil
+		},
+		SleepHint: 10 * time.Minute,
+		Logger:    createTestLogger(),
+	}
+
+	responder.OnSleep()
+	assert.Equal(t, []time.Duration{10 * time.Minute}, hb.hinted)
+	assert.Equal(t, 0, revalidations)
+
+	responder.OnWake(time.Hour)
+	assert.Equal(t, 1, revalidations)
+	assert.Equal(t, 1, hb.plain)
+
+	// Heartbeat is still attempted when revalidation fails
+	responder.Revalidate = func(ctx context.Context) error { return errors.New("tunnel down") }
+	responder.OnNetworkChange()
+	assert.Equal(t, 2, hb.plain)
+}
+
+func TestKeepAliveResponder_NoServer(t *testing.T) {
+	responder := &KeepAliveResponder{
+		Heartbeat: func() HeartbeatSender { return nil },
+		Logger:    createTestLogger(),
+	}
+
+	// Must not panic when no server is connected
+	responder.OnSleep()
+	responder.OnWake(time.Minute)
+}
+
+func TestWatcher_WakeReportedOnce(t *testing.T) {
+	w := NewWatcher(&WatcherConfig{CheckInterval: time.Minute, WakeThreshold: time.Second}, createTestLogger()).(*watcherImpl)
+	handler := &recordingHandler{}
+	w.RegisterHandler(handler)
+
+	// The OS signal and the clock gap detection report the same resume
+	w.emitWake(8 * time.Hour)
+	w.emitWake(8*time.Hour + 5*time.Second)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Equal(t, []time.Duration{8 * time.Hour}, handler.wakes)
+}
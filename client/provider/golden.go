@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GoldenImageVersion is the version of the server setup baked into golden images.
+// Bump it whenever provisioning changes in a way existing images lack; images of
+// other versions are then ignored until they are rebuilt.
+const GoldenImageVersion = 1
+
+// GoldenImageName returns the name of the golden image of the given version
+func GoldenImageName(version int) string {
+	return fmt.Sprintf("dockbridge-golden-v%d", version)
+}
+
+// Image is a provider image (snapshot) servers can boot from
+type Image struct {
+	ID           int64
+	Name         string
+	Architecture string
+	Version      int
+	SizeGB       float32
+	CreatedAt    time.Time
+}
+
+// GoldenImager is implemented by providers that can snapshot a set-up server into a
+// golden image and boot later servers from it (see ServerConfig.ImageID)
+type GoldenImager interface {
+	// GoldenImage returns the current-version golden image a server of serverType can
+	// boot from, or nil if there is none
+	GoldenImage(ctx context.Context, serverType string) (*Image, error)
+
+	// CreateGoldenImage snapshots a server and returns the image once it is available
+	CreateGoldenImage(ctx context.Context, serverID string) (*Image, error)
+
+	// ListGoldenImages returns the golden images of all versions
+	ListGoldenImages(ctx context.Context) ([]*Image, error)
+
+	// DeleteGoldenImages deletes all golden images and returns how many were deleted
+	DeleteGoldenImages(ctx context.Context) (int, error)
+}
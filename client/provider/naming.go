@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// serverNamePrefix is the prefix shared by all DockBridge servers
+	serverNamePrefix = "dockbridge-"
+
+	// contextServerPrefix marks servers owned by a named context
+	contextServerPrefix = serverNamePrefix + "ctx-"
+)
+
+// NewServerName generates a server name for the given context. The default
+// context (empty name) keeps the historical "dockbridge-<unix>" form.
+func NewServerName(contextName string) string {
+	return fmt.Sprintf("%s%d", contextNamePrefix(contextName), time.Now().Unix())
+}
+
+// ServerBelongsToContext reports whether a server name was created for the given
+// context, so that several daemons can share one cloud project without adopting
+// or cleaning up each other's servers.
+func ServerBelongsToContext(name, contextName string) bool {
+	serverContext, ok := ServerContext(name)
+	return ok && serverContext == contextName
+}
+
+// ServerContext returns the context a DockBridge server name was created for ("" for
+// the default context). ok is false for servers not created by DockBridge.
+func ServerContext(name string) (contextName string, ok bool) {
+	if !strings.HasPrefix(name, serverNamePrefix) {
+		return "", false
+	}
+	rest, isContext := strings.CutPrefix(name, contextServerPrefix)
+	if !isContext {
+		return "", true
+	}
+
+	// "<context>-<unix timestamp>"; context names may themselves contain dashes
+	i := strings.LastIndex(rest, "-")
+	if i <= 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// contextNamePrefix returns the server name prefix used by a context
+func contextNamePrefix(contextName string) string {
+	if contextName == "" {
+		return serverNamePrefix
+	}
+	return contextServerPrefix + contextName + "-"
+}
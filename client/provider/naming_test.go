@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerName(t *testing.T) {
+	assert.Regexp(t, `^dockbridge-\d+$`, NewServerName(""))
+	assert.Regexp(t, `^dockbridge-ctx-gpu-\d+$`, NewServerName("gpu"))
+}
+
+func TestServerBelongsToContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverName  string
+		contextName string
+		expected    bool
+	}{
+		{"default owns legacy names", "dockbridge-1700000000", "", true},
+		{"default ignores context servers", "dockbridge-ctx-gpu-1700000000", "", false},
+		{"default ignores foreign servers", "other-server", "", false},
+		{"context owns its servers", "dockbridge-ctx-gpu-1700000000", "gpu", true},
+		{"context ignores default servers", "dockbridge-1700000000", "gpu", false},
+		{"context ignores other contexts", "dockbridge-ctx-dev-1700000000", "gpu", false},
+		{"context name is not a prefix match", "dockbridge-ctx-gpu2-1700000000", "gpu", false},
+		{"context ignores dashed context with its prefix", "dockbridge-ctx-dev-2-1700000000", "dev", false},
+		{"dashed context owns its servers", "dockbridge-ctx-dev-2-1700000000", "dev-2", true},
+		{"dashed context ignores its prefix context", "dockbridge-ctx-dev-1700000000", "dev-2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ServerBelongsToContext(tt.serverName, tt.contextName))
+		})
+	}
+}
+
+func TestServerContext(t *testing.T) {
+	tests := []struct {
+		serverName  string
+		contextName string
+		ok          bool
+	}{
+		{"dockbridge-1700000000", "", true},
+		{"dockbridge-ctx-gpu-1700000000", "gpu", true},
+		{"dockbridge-ctx-dev-2-1700000000", "dev-2", true},
+		{"dockbridge-ctx-", "", false},
+		{"web-1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverName, func(t *testing.T) {
+			contextName, ok := ServerContext(tt.serverName)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.contextName, contextName)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dockbridge/dockbridge/pkg/version"
+)
+
+// Labels DockBridge puts on the servers and volumes it creates. Servers and volumes are
+// only adopted or cleaned up by the client whose owner label they carry, so that several
+// people can share one cloud project.
+const (
+	LabelManagedBy = "managed-by"
+	LabelOwner     = "dockbridge-owner"
+	LabelContext   = "dockbridge-context"
+	LabelProfile   = "dockbridge-profile"
+	LabelVersion   = "dockbridge-version"
+
+	// LabelProvisionToken carries the idempotency token of the provisioning that created
+	// a server, so that a provisioning interrupted in one process is completed by the
+	// next instead of creating a second server
+	LabelProvisionToken = "dockbridge-provision"
+
+	// ManagedByDockBridge is the value of LabelManagedBy
+	ManagedByDockBridge = "dockbridge"
+)
+
+// profileEnv selects the configuration profile; it mirrors client/config.ProfileEnv,
+// which imports this package
+const profileEnv = "DOCKBRIDGE_PROFILE"
+
+// OwnerEnv overrides the owner ID of this machine, so that another machine, such as a
+// CI runner, uses and keeps alive the servers of the machine whose ID it is given
+const OwnerEnv = "DOCKBRIDGE_OWNER"
+
+// Owner identifies the DockBridge client that creates resources
+type Owner struct {
+	// ID identifies the machine, see LocalOwner
+	ID string
+	// Profile is the configuration profile in use, if any
+	Profile string
+	// Version is the DockBridge version
+	Version string
+}
+
+// Labels returns the labels of a resource created by the owner, such as a volume
+func (o Owner) Labels() map[string]string {
+	labels := map[string]string{
+		LabelManagedBy: ManagedByDockBridge,
+		LabelOwner:     LabelValue(o.ID),
+		LabelVersion:   LabelValue(o.Version),
+	}
+	if o.Profile != "" {
+		labels[LabelProfile] = LabelValue(o.Profile)
+	}
+	return labels
+}
+
+// ServerLabels returns the labels of a server created by the owner for contextName (""
+// is the default context)
+func (o Owner) ServerLabels(contextName string) map[string]string {
+	labels := o.Labels()
+	labels[LabelContext] = LabelValue(contextName)
+	return labels
+}
+
+// Owns reports whether a resource with labels was created by the owner. Resources
+// without labels, e.g. created by DockBridge versions before labels, are not owned.
+func (o Owner) Owns(labels map[string]string) bool {
+	return labels[LabelManagedBy] == ManagedByDockBridge && labels[LabelOwner] == LabelValue(o.ID)
+}
+
+var (
+	localOwnerOnce sync.Once
+	localOwner     Owner
+)
+
+// LocalOwner returns the owner of this machine. Its ID is generated once and kept in
+// ~/.dockbridge/owner-id; if that file cannot be used, the host name identifies the
+// machine instead. OwnerEnv takes precedence over both.
+func LocalOwner() Owner {
+	localOwnerOnce.Do(func() {
+		id, err := loadOrCreateOwnerID()
+		if err != nil {
+			id, _ = os.Hostname()
+		}
+		localOwner = Owner{ID: id, Version: version.Version}
+	})
+
+	owner := localOwner
+	if id := strings.TrimSpace(os.Getenv(OwnerEnv)); id != "" {
+		owner.ID = id
+	}
+	owner.Profile = os.Getenv(profileEnv)
+	return owner
+}
+
+// OwnerIDPath returns the location of the file keeping this machine's owner ID
+func OwnerIDPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "owner-id"), nil
+}
+
+// loadOrCreateOwnerID reads the owner ID, generating it on first use
+func loadOrCreateOwnerID() (string, error) {
+	path, err := OwnerIDPath()
+	if err != nil {
+		return "", err
+	}
+	// #nosec G304 -- fixed location under the user's home directory
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	}
+
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate owner ID: %w", err)
+	}
+	id := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create owner ID directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write owner ID: %w", err)
+	}
+	return id, nil
+}
+
+// invalidLabelChars matches what label values may not contain on every provider;
+// DigitalOcean tags, which carry labels there, do not allow '.'
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// LabelValue makes s a valid label value: at most 63 letters, digits, '-' and '_',
+// starting and ending with a letter or digit
+func LabelValue(s string) string {
+	s = invalidLabelChars.ReplaceAllString(s, "-")
+	if len(s) > 63 {
+		s = s[:63]
+	}
+	return strings.Trim(s, "-_")
+}
+
+// ForeignResourceError is returned when DockBridge refuses to destroy a resource it did
+// not create on this machine
+type ForeignResourceError struct {
+	Kind  string
+	Name  string
+	Owner string
+}
+
+func (e *ForeignResourceError) Error() string {
+	owner := e.Owner
+	if owner == "" {
+		owner = "an unknown client"
+	}
+	return fmt.Sprintf("%s %s is owned by %s, not this client; use --force-foreign to destroy it anyway", e.Kind, e.Name, owner)
+}
+
+// CheckOwned returns a ForeignResourceError if server was not created by owner
+func CheckOwned(owner Owner, server *Server) error {
+	if owner.Owns(server.Labels) {
+		return nil
+	}
+	return &ForeignResourceError{Kind: "server", Name: server.Name, Owner: server.Labels[LabelOwner]}
+}
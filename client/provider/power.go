@@ -0,0 +1,17 @@
+package provider
+
+import "context"
+
+// StatusOff is the provider-neutral status of a powered-off server
+const StatusOff = "off"
+
+// PowerController is implemented by providers that can power servers off and on.
+// A stopped server keeps its disk, volume and address, so resuming it is faster
+// than provisioning a new one, but providers may still bill it.
+type PowerController interface {
+	// PowerOffServer shuts a server down, gracefully where possible
+	PowerOffServer(ctx context.Context, serverID string) error
+
+	// PowerOnServer starts a stopped server and returns it once it is running
+	PowerOnServer(ctx context.Context, serverID string) (*Server, error)
+}
@@ -0,0 +1,18 @@
+package provider
+
+import "context"
+
+// Pricing is what a server and its Docker data volume cost, as list prices excluding VAT
+type Pricing struct {
+	Currency string
+	// ServerHourly is the hourly price of the server type in the server's location
+	ServerHourly float64
+	// VolumeGBMonthly is the monthly price of one GB of block storage
+	VolumeGBMonthly float64
+}
+
+// PricingProvider is implemented by providers that can report their prices
+type PricingProvider interface {
+	// ServerPricing returns the prices of serverType in location
+	ServerPricing(ctx context.Context, serverType, location string) (*Pricing, error)
+}
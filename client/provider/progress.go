@@ -0,0 +1,42 @@
+package provider
+
+// ProvisioningPhase is a step of bringing up a server, reported to a ProvisioningObserver
+type ProvisioningPhase string
+
+const (
+	PhaseWaitingForLock      ProvisioningPhase = "waiting_for_lock"
+	PhaseEnsuringVolume      ProvisioningPhase = "ensuring_volume"
+	PhaseCreatingServer      ProvisioningPhase = "creating_server"
+	PhaseResumingServer      ProvisioningPhase = "resuming_server"
+	PhaseWaitingForSSH       ProvisioningPhase = "waiting_for_ssh"
+	PhaseWaitingForCloudInit ProvisioningPhase = "waiting_for_cloud_init"
+	PhaseMountingVolume      ProvisioningPhase = "mounting_volume"
+	PhaseWaitingForDocker    ProvisioningPhase = "waiting_for_docker"
+	PhaseUploadingServer     ProvisioningPhase = "uploading_server"
+	PhaseDockerReady         ProvisioningPhase = "docker_ready"
+	PhaseConnecting          ProvisioningPhase = "connecting"
+
+	// PhaseReady and PhaseFailed end a provisioning; both report 100 percent
+	PhaseReady  ProvisioningPhase = "ready"
+	PhaseFailed ProvisioningPhase = "failed"
+)
+
+// Done reports whether the phase ends a provisioning
+func (p ProvisioningPhase) Done() bool {
+	return p == PhaseReady || p == PhaseFailed
+}
+
+// ProvisioningObserver receives progress while a server is provisioned or resumed, e.g.
+// to show a progress bar. message is human readable ("Waiting for cloud-init") and
+// percent is an estimate between 0 and 100. Calls may come from several goroutines.
+type ProvisioningObserver interface {
+	OnPhase(phase ProvisioningPhase, message string, percent int)
+}
+
+// ProvisioningObserverFunc adapts a function to a ProvisioningObserver
+type ProvisioningObserverFunc func(phase ProvisioningPhase, message string, percent int)
+
+// OnPhase calls f
+func (f ProvisioningObserverFunc) OnPhase(phase ProvisioningPhase, message string, percent int) {
+	f(phase, message, percent)
+}
@@ -0,0 +1,228 @@
+// Package provider defines the cloud provider abstraction used to provision the
+// remote Docker servers. Backends such as client/hetzner register themselves under
+// the name selected by the client config's provider field.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// CloudProvider provisions servers, volumes and SSH keys with a cloud backend
+type CloudProvider interface {
+	ProvisionServer(ctx context.Context, config *ServerConfig) (*Server, error)
+	DestroyServer(ctx context.Context, serverID string) error
+	CreateVolume(ctx context.Context, size int, location string) (*Volume, error)
+	FindOrCreateDockerVolume(ctx context.Context, location string) (*Volume, error)
+	AttachVolume(ctx context.Context, serverID, volumeID string) error
+	DetachVolume(ctx context.Context, volumeID string) error
+	ManageSSHKeys(ctx context.Context, publicKey string) (*SSHKey, error)
+	GetServer(ctx context.Context, serverID string) (*Server, error)
+	ListServers(ctx context.Context) ([]*Server, error)
+	GetVolume(ctx context.Context, volumeID string) (*Volume, error)
+	ListVolumes(ctx context.Context) ([]*Volume, error)
+}
+
+// ServerConfig defines configuration for server provisioning
+type ServerConfig struct {
+	Name       string
+	ServerType string
+	Location   string
+	SSHKeyID   int64
+	VolumeID   string
+	UserData   string
+	ImageName  string // Added to track which image is being used
+
+	// ImageID boots the server from this image (e.g. a golden image) instead of the
+	// provider's preferred images
+	ImageID int64
+
+	// Volumes are attached at creation in addition to VolumeID
+	Volumes []VolumeAttachment
+
+	// Labels are put on the server, see Owner.Labels
+	Labels map[string]string
+
+	// Network attaches the server to an existing private network, by name or ID
+	Network string
+
+	// Firewall is applied to the server, if set
+	Firewall *Firewall
+
+	// PlacementGroup adds the server to a spread placement group of this name
+	PlacementGroup string
+
+	// PublicNetwork selects the server's public addresses, see PublicNetworkDual
+	PublicNetwork string
+}
+
+// Public networks of servers
+const (
+	// PublicNetworkDual gives servers a public IPv4 and IPv6 address (the default)
+	PublicNetworkDual = "dual"
+	// PublicNetworkIPv6 gives servers only a public IPv6 address
+	PublicNetworkIPv6 = "ipv6"
+	// PublicNetworkNone gives servers no public address; they are reached over their
+	// private network
+	PublicNetworkNone = "none"
+)
+
+// Firewall is a cloud firewall applied to a new server. A missing firewall is created
+// allowing inbound TCP traffic to Ports from SourceIPs, or from anywhere without them.
+type Firewall struct {
+	Name      string
+	Ports     []int
+	SourceIPs []string
+}
+
+// NewFirewall returns the firewall configured in cfg allowing ports, or nil if none is
+// configured
+func NewFirewall(cfg config.FirewallConfig, ports ...int) *Firewall {
+	if cfg.Name == "" {
+		return nil
+	}
+	return &Firewall{Name: cfg.Name, Ports: ports, SourceIPs: cfg.SourceIPs}
+}
+
+// VolumeAttachment is a volume attached to a new server and where it is mounted
+type VolumeAttachment struct {
+	ID    string
+	Name  string
+	Mount string
+
+	// Device is the volume's block device path on the server, see Volume.Device
+	Device string
+}
+
+// Server represents a cloud server
+type Server struct {
+	ID     int64
+	Name   string
+	Status string
+	// IPAddress is the address the client connects to: the public IPv4 address or,
+	// for servers without one, the public IPv6 or private network address
+	IPAddress string
+	VolumeID  string
+	CreatedAt time.Time
+
+	// IPv6Address and PrivateIPAddress are the server's public IPv6 address and its
+	// address in a private network, if any
+	IPv6Address      string
+	PrivateIPAddress string
+
+	// VolumeIDs are all volumes attached to the server; VolumeID is the first
+	VolumeIDs []string
+
+	// ServerType is the provider's instance type (size) the server runs on
+	ServerType string
+
+	// Traffic counters for the current billing period, in bytes
+	IncludedTraffic uint64
+	OutgoingTraffic uint64
+	IngoingTraffic  uint64
+
+	// Labels identify the owner and context of servers created by DockBridge
+	Labels map[string]string
+}
+
+// AttachedVolumes returns the IDs of all volumes attached to the server
+func (s *Server) AttachedVolumes() []string {
+	if len(s.VolumeIDs) > 0 {
+		return s.VolumeIDs
+	}
+	if s.VolumeID != "" {
+		return []string{s.VolumeID}
+	}
+	return nil
+}
+
+// Volume represents a cloud block storage volume. IDs are strings because not all
+// providers use numeric volume IDs.
+type Volume struct {
+	ID       string
+	Name     string
+	Size     int
+	Location string
+	Status   string
+
+	// Device is the stable path the volume appears at on the server it is attached to;
+	// each provider names its block devices differently
+	Device string
+
+	// Labels identify the owner of volumes created by DockBridge
+	Labels map[string]string
+}
+
+// SSHKey represents an SSH key registered with the provider
+type SSHKey struct {
+	ID          int64
+	Name        string
+	Fingerprint string
+	PublicKey   string
+}
+
+// DefaultName is the provider used when the config does not select one
+const DefaultName = "hetzner"
+
+// Factory creates a provider from the client configuration. Backends read their own
+// config section for backend specific options; the server shape (credentials, type,
+// location, volume size) is passed in server so that contexts can override it.
+type Factory func(cfg *config.ClientConfig, server config.ServerSettings) (CloudProvider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a provider backend available under name. It is intended to be
+// called from a backend's init function and panics on duplicate names.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("provider %q is already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Registered returns the names of all registered providers, sorted
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the provider selected by cfg.Provider using the given server settings
+func New(cfg *config.ClientConfig, server config.ServerSettings) (CloudProvider, error) {
+	name := cfg.Provider
+	if name == "" {
+		name = DefaultName
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q, available: %v", name, Registered())
+	}
+	return factory(cfg, server)
+}
+
+// IsRegistered reports whether a provider with the given name is registered
+func IsRegistered(name string) bool {
+	return slices.Contains(Registered(), name)
+}
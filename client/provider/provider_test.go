@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider is a CloudProvider that only records its server settings
+type stubProvider struct {
+	CloudProvider
+	serverType string
+}
+
+func TestRegistry(t *testing.T) {
+	Register("stub", func(cfg *config.ClientConfig, server config.ServerSettings) (CloudProvider, error) {
+		return &stubProvider{serverType: server.ServerType}, nil
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "stub")
+		registryMu.Unlock()
+	})
+
+	assert.True(t, IsRegistered("stub"))
+	assert.Contains(t, Registered(), "stub")
+	assert.Panics(t, func() {
+		Register("stub", nil)
+	})
+
+	p, err := New(&config.ClientConfig{Provider: "stub"}, config.ServerSettings{ServerType: "large"})
+	require.NoError(t, err)
+	assert.Equal(t, "large", p.(*stubProvider).serverType)
+
+	_, err = New(&config.ClientConfig{Provider: "missing"}, config.ServerSettings{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown provider")
+}
+
+func TestNewUsesDefaultProvider(t *testing.T) {
+	var called bool
+	registryMu.Lock()
+	previous, hadDefault := registry[DefaultName]
+	registry[DefaultName] = func(cfg *config.ClientConfig, server config.ServerSettings) (CloudProvider, error) {
+		called = true
+		return &stubProvider{}, nil
+	}
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		if hadDefault {
+			registry[DefaultName] = previous
+		} else {
+			delete(registry, DefaultName)
+		}
+		registryMu.Unlock()
+	})
+
+	_, err := New(&config.ClientConfig{}, config.ServerSettings{})
+	require.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ServerRegistry groups the DockBridge servers of a cloud project by context, so that
+// several named contexts (e.g. dev, gpu, ci) can each run their own server side by side
+// without adopting or cleaning up each other's servers. Servers are selected by their
+// labels: only those created by the registry's owner are listed, so that people sharing
+// a project never use or destroy each other's servers.
+type ServerRegistry struct {
+	provider CloudProvider
+	owner    Owner
+}
+
+// NewServerRegistry creates a registry of this machine's servers backed by the
+// provider's server list
+func NewServerRegistry(provider CloudProvider) *ServerRegistry {
+	return NewServerRegistryForOwner(provider, LocalOwner())
+}
+
+// NewServerRegistryForOwner creates a registry of the servers created by owner
+func NewServerRegistryForOwner(provider CloudProvider, owner Owner) *ServerRegistry {
+	return &ServerRegistry{provider: provider, owner: owner}
+}
+
+// List returns the owner's DockBridge servers keyed by context name ("" is the default
+// context). Servers of each context are ordered oldest first.
+func (r *ServerRegistry) List(ctx context.Context) (map[string][]*Server, error) {
+	owned, _, err := r.list(ctx)
+	return owned, err
+}
+
+// Foreign returns the DockBridge servers of other clients keyed by context name,
+// including servers without labels created by DockBridge versions before labels
+func (r *ServerRegistry) Foreign(ctx context.Context) (map[string][]*Server, error) {
+	_, foreign, err := r.list(ctx)
+	return foreign, err
+}
+
+// list splits the provider's DockBridge servers into the owner's and foreign ones
+func (r *ServerRegistry) list(ctx context.Context) (owned, foreign map[string][]*Server, err error) {
+	servers, err := r.provider.ListServers(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	owned = make(map[string][]*Server)
+	foreign = make(map[string][]*Server)
+	for _, server := range servers {
+		contextName, ok := serverContext(server)
+		switch {
+		case !ok:
+			continue
+		case r.owner.Owns(server.Labels):
+			owned[contextName] = append(owned[contextName], server)
+		default:
+			foreign[contextName] = append(foreign[contextName], server)
+		}
+	}
+	for _, byContext := range []map[string][]*Server{owned, foreign} {
+		for _, contextServers := range byContext {
+			sort.SliceStable(contextServers, func(i, j int) bool {
+				return contextServers[i].Name < contextServers[j].Name
+			})
+		}
+	}
+	return owned, foreign, nil
+}
+
+// serverContext returns the context of a DockBridge server, from its labels or, for
+// servers without labels, its name. ok is false for servers not created by DockBridge.
+func serverContext(server *Server) (contextName string, ok bool) {
+	if server.Labels[LabelManagedBy] == ManagedByDockBridge {
+		return server.Labels[LabelContext], true
+	}
+	return ServerContext(server.Name)
+}
+
+// ForContext returns the owner's servers of one context, oldest first
+func (r *ServerRegistry) ForContext(ctx context.Context, contextName string) ([]*Server, error) {
+	byContext, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return byContext[contextName], nil
+}
+
+// Destroy destroys the owner's servers of a context and returns how many were
+// destroyed. With forceForeign, servers of the context created by other clients are
+// destroyed as well.
+func (r *ServerRegistry) Destroy(ctx context.Context, contextName string, forceForeign bool) (int, error) {
+	owned, foreign, err := r.list(ctx)
+	if err != nil {
+		return 0, err
+	}
+	servers := owned[contextName]
+	if forceForeign {
+		servers = append(servers, foreign[contextName]...)
+	}
+
+	destroyed := 0
+	for _, server := range servers {
+		if err := r.DestroyServer(ctx, server, forceForeign); err != nil {
+			return destroyed, err
+		}
+		destroyed++
+	}
+	return destroyed, nil
+}
+
+// DestroyServer destroys server, refusing with a ForeignResourceError if the owner did
+// not create it unless forceForeign is set
+func (r *ServerRegistry) DestroyServer(ctx context.Context, server *Server, forceForeign bool) error {
+	if !forceForeign {
+		if err := CheckOwned(r.owner, server); err != nil {
+			return err
+		}
+	}
+	if err := r.provider.DestroyServer(ctx, fmt.Sprintf("%d", server.ID)); err != nil {
+		return fmt.Errorf("failed to destroy server %s: %w", server.Name, err)
+	}
+	return nil
+}
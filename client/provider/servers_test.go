@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listProvider is a CloudProvider serving a fixed server list
+type listProvider struct {
+	CloudProvider
+	servers   []*Server
+	destroyed []string
+}
+
+func (p *listProvider) ListServers(ctx context.Context) ([]*Server, error) {
+	return p.servers, nil
+}
+
+func (p *listProvider) DestroyServer(ctx context.Context, serverID string) error {
+	p.destroyed = append(p.destroyed, serverID)
+	return nil
+}
+
+func TestServerRegistry(t *testing.T) {
+	me := Owner{ID: "me", Version: "1.0.0"}
+	teammate := Owner{ID: "teammate"}
+	p := &listProvider{servers: []*Server{
+		{ID: 1, Name: "dockbridge-1700000200", Labels: me.ServerLabels("")},
+		{ID: 2, Name: "dockbridge-ctx-gpu-1700000100", Labels: me.ServerLabels("gpu")},
+		{ID: 3, Name: "unrelated"},
+		{ID: 4, Name: "dockbridge-1700000100", Labels: me.ServerLabels("")},
+		{ID: 5, Name: "dockbridge-ctx-ci-1700000100", Labels: me.ServerLabels("ci")},
+		{ID: 6, Name: "dockbridge-1700000300", Labels: teammate.ServerLabels("")},
+		{ID: 7, Name: "dockbridge-ctx-ci-1700000000"},
+	}}
+	registry := NewServerRegistryForOwner(p, me)
+
+	byContext, err := registry.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, byContext, 3)
+	require.Len(t, byContext[""], 2)
+	assert.Equal(t, int64(4), byContext[""][0].ID, "servers are ordered oldest first")
+
+	gpu, err := registry.ForContext(context.Background(), "gpu")
+	require.NoError(t, err)
+	require.Len(t, gpu, 1)
+	assert.Equal(t, int64(2), gpu[0].ID)
+
+	foreign, err := registry.Foreign(context.Background())
+	require.NoError(t, err)
+	require.Len(t, foreign[""], 1)
+	assert.Equal(t, int64(6), foreign[""][0].ID, "servers of other owners are foreign")
+	require.Len(t, foreign["ci"], 1)
+	assert.Equal(t, int64(7), foreign["ci"][0].ID, "servers without labels are foreign")
+
+	n, err := registry.Destroy(context.Background(), "ci", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"5"}, p.destroyed)
+
+	var foreignErr *ForeignResourceError
+	require.ErrorAs(t, registry.DestroyServer(context.Background(), foreign[""][0], false), &foreignErr)
+	assert.Equal(t, "teammate", foreignErr.Owner)
+	assert.Equal(t, []string{"5"}, p.destroyed, "foreign servers are not destroyed")
+
+	n, err = registry.Destroy(context.Background(), "ci", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"5", "5", "7"}, p.destroyed)
+}
+
+func TestOwnerLabels(t *testing.T) {
+	owner := Owner{ID: "a1b2", Profile: "work", Version: "0.1.0+dirty"}
+	labels := owner.ServerLabels("gpu")
+	assert.Equal(t, map[string]string{
+		LabelManagedBy: ManagedByDockBridge,
+		LabelOwner:     "a1b2",
+		LabelContext:   "gpu",
+		LabelProfile:   "work",
+		LabelVersion:   "0-1-0-dirty",
+	}, labels)
+
+	assert.True(t, owner.Owns(labels))
+	assert.False(t, Owner{ID: "other"}.Owns(labels))
+	assert.False(t, owner.Owns(nil))
+	assert.Equal(t, "", LabelValue("--"))
+}
+
+func TestLocalOwnerOverride(t *testing.T) {
+	t.Setenv(OwnerEnv, "")
+	own := LocalOwner().ID
+
+	t.Setenv(OwnerEnv, "shared-owner")
+	assert.Equal(t, "shared-owner", LocalOwner().ID, "another machine joins the servers of the given owner")
+
+	t.Setenv(OwnerEnv, "")
+	assert.Equal(t, own, LocalOwner().ID)
+}
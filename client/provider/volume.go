@@ -0,0 +1,18 @@
+package provider
+
+import "context"
+
+// NamedVolumeProvider is implemented by providers that keep named volumes across
+// servers. A server replacing another reuses the volumes it left behind.
+type NamedVolumeProvider interface {
+	// FindOrCreateNamedVolume returns an unattached volume created for name in
+	// location, creating one of size GB if there is none
+	FindOrCreateNamedVolume(ctx context.Context, name string, size int, location string) (*Volume, error)
+}
+
+// VolumeResizer is implemented by providers that can grow volumes in place. Only
+// the block device grows; the filesystem on it has to be extended separately.
+type VolumeResizer interface {
+	// ResizeVolume grows a volume to size GB and returns it once resized
+	ResizeVolume(ctx context.Context, volumeID string, size int) (*Volume, error)
+}
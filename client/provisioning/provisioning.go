@@ -0,0 +1,121 @@
+// Package provisioning merges user-supplied steps and files into the setup of new
+// servers, e.g. to install company CA certificates or a monitoring agent. Steps run as
+// root before Docker is set up or once it is running; files are written first.
+package provisioning
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+const (
+	// StepDir holds the steps on the server, kept for inspection after setup
+	StepDir = "/var/lib/dockbridge/provisioning"
+
+	// defaultPermissions apply to files without permissions
+	defaultPermissions = "0644"
+
+	// stepPermissions apply to steps, which are run as root only
+	stepPermissions = "0700"
+)
+
+// Steps is a provisioning configuration resolved against the local files it refers
+// to, ready to be rendered into a server's setup
+type Steps struct {
+	// Files are written before any step runs; they include the steps themselves
+	Files []cloudinit.File
+
+	// PreDocker and PostDocker are the commands running the steps
+	PreDocker  []string
+	PostDocker []string
+}
+
+// Resolve reads the local scripts and files cfg refers to. It returns nil when cfg
+// declares nothing.
+func Resolve(cfg *config.ProvisioningConfig) (*Steps, error) {
+	if cfg == nil || len(cfg.PreDocker)+len(cfg.PostDocker)+len(cfg.WriteFiles) == 0 {
+		return nil, nil
+	}
+
+	steps := &Steps{}
+	for i, file := range cfg.WriteFiles {
+		content := []byte(file.Content)
+		if file.Source != "" {
+			var err error
+			if content, err = os.ReadFile(expandHome(file.Source)); err != nil {
+				return nil, fmt.Errorf("write_files[%d]: failed to read source: %w", i, err)
+			}
+		}
+
+		permissions := file.Permissions
+		if permissions == "" {
+			permissions = defaultPermissions
+		}
+		steps.Files = append(steps.Files, cloudinit.File{
+			Path:        file.Path,
+			Content:     content,
+			Permissions: permissions,
+			Owner:       file.Owner,
+		})
+	}
+
+	var err error
+	if steps.PreDocker, err = steps.addSteps("pre-docker", cfg.PreDocker); err != nil {
+		return nil, err
+	}
+	if steps.PostDocker, err = steps.addSteps("post-docker", cfg.PostDocker); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// addSteps adds the step files of stage and returns the commands running them
+func (s *Steps) addSteps(stage string, steps []config.ProvisioningStep) ([]string, error) {
+	var commands []string
+	for i, step := range steps {
+		content := []byte("#!/bin/bash\nset -e\n" + step.Run + "\n")
+		if step.Script != "" {
+			var err error
+			if content, err = os.ReadFile(expandHome(step.Script)); err != nil {
+				return nil, fmt.Errorf("%s[%d]: failed to read script: %w", stage, i, err)
+			}
+		}
+
+		path := fmt.Sprintf("%s/%s-%02d", StepDir, stage, i+1)
+		s.Files = append(s.Files, cloudinit.File{Path: path, Content: content, Permissions: stepPermissions})
+
+		// Scripts without an interpreter line are shell scripts
+		if bytes.HasPrefix(content, []byte("#!")) {
+			commands = append(commands, path)
+		} else {
+			commands = append(commands, "bash "+path)
+		}
+	}
+	return commands, nil
+}
+
+// Apply merges the steps into a cloud-config; nil steps leave it unchanged
+func (s *Steps) Apply(model *cloudinit.Config) {
+	if s == nil {
+		return
+	}
+	model.WriteFiles = append(model.WriteFiles, s.Files...)
+	model.PreDocker = append(model.PreDocker, s.PreDocker...)
+	model.PostDocker = append(model.PostDocker, s.PostDocker...)
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
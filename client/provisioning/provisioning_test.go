@@ -0,0 +1,76 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/cloudinit"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	steps, err := Resolve(nil)
+	require.NoError(t, err)
+	assert.Nil(t, steps)
+	steps, err = Resolve(&config.ProvisioningConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, steps)
+
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "corp.crt")
+	require.NoError(t, os.WriteFile(cert, []byte("CERT\n"), 0600))
+	agent := filepath.Join(dir, "agent.sh")
+	require.NoError(t, os.WriteFile(agent, []byte("curl -fsSL https://example.com/agent | sh\n"), 0600))
+	python := filepath.Join(dir, "check.py")
+	require.NoError(t, os.WriteFile(python, []byte("#!/usr/bin/env python3\nprint('ok')\n"), 0600))
+
+	steps, err = Resolve(&config.ProvisioningConfig{
+		PreDocker:  []config.ProvisioningStep{{Run: "update-ca-certificates"}},
+		PostDocker: []config.ProvisioningStep{{Script: agent}, {Script: python}},
+		WriteFiles: []config.ProvisioningFile{
+			{Path: "/usr/local/share/ca-certificates/corp.crt", Source: cert},
+			{Path: "/etc/agent.conf", Content: "key=1\n", Permissions: "0600", Owner: "nobody:nogroup"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []cloudinit.File{
+		{Path: "/usr/local/share/ca-certificates/corp.crt", Content: []byte("CERT\n"), Permissions: "0644"},
+		{Path: "/etc/agent.conf", Content: []byte("key=1\n"), Permissions: "0600", Owner: "nobody:nogroup"},
+		{Path: StepDir + "/pre-docker-01", Content: []byte("#!/bin/bash\nset -e\nupdate-ca-certificates\n"), Permissions: "0700"},
+		{Path: StepDir + "/post-docker-01", Content: []byte("curl -fsSL https://example.com/agent | sh\n"), Permissions: "0700"},
+		{Path: StepDir + "/post-docker-02", Content: []byte("#!/usr/bin/env python3\nprint('ok')\n"), Permissions: "0700"},
+	}, steps.Files)
+	assert.Equal(t, []string{StepDir + "/pre-docker-01"}, steps.PreDocker)
+	assert.Equal(t, []string{"bash " + StepDir + "/post-docker-01", StepDir + "/post-docker-02"}, steps.PostDocker)
+}
+
+func TestResolveMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	_, err := Resolve(&config.ProvisioningConfig{PostDocker: []config.ProvisioningStep{{Script: missing}}})
+	assert.ErrorContains(t, err, "post-docker[0]: failed to read script")
+
+	_, err = Resolve(&config.ProvisioningConfig{WriteFiles: []config.ProvisioningFile{{Path: "/etc/x", Source: missing}}})
+	assert.ErrorContains(t, err, "write_files[0]: failed to read source")
+}
+
+func TestApply(t *testing.T) {
+	model := cloudinit.Config{PostDocker: []string{"true"}}
+	var none *Steps
+	none.Apply(&model)
+	assert.Equal(t, cloudinit.Config{PostDocker: []string{"true"}}, model)
+
+	steps := &Steps{
+		Files:      []cloudinit.File{{Path: "/etc/x"}},
+		PreDocker:  []string{"a"},
+		PostDocker: []string{"b"},
+	}
+	steps.Apply(&model)
+	assert.Equal(t, []cloudinit.File{{Path: "/etc/x"}}, model.WriteFiles)
+	assert.Equal(t, []string{"a"}, model.PreDocker)
+	assert.Equal(t, []string{"true", "b"}, model.PostDocker)
+}
@@ -0,0 +1,166 @@
+// Package provisionlock serializes provisioning of a context's server across local
+// processes, so that two terminals running Docker commands on a fresh setup do not
+// both create a server and then clean up each other's.
+package provisionlock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/filelock"
+)
+
+// pollInterval is how often a waiting process retries the lock
+const pollInterval = 250 * time.Millisecond
+
+// ErrLocked is returned by TryAcquire while another process holds the lock
+var ErrLocked = errors.New("provisioning lock is held by another process")
+
+// Lock is an exclusive lock on provisioning the server of one context. It is released
+// by the operating system when the process exits, so a crashed process never leaves
+// it held.
+type Lock struct {
+	lock  *filelock.Lock
+	state state
+}
+
+// state is the content of the lock file. Token is the idempotency token of a
+// provisioning in progress: it is set before the server is created and cleared once it
+// is ready, so a token left behind marks a provisioning that was interrupted.
+type state struct {
+	PID     int       `json:"pid"`
+	Token   string    `json:"token,omitempty"`
+	Started time.Time `json:"started,omitzero"`
+}
+
+// Path returns the lock file of the named context ("" is the default context)
+func Path(contextName string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	name := "provision.lock"
+	if contextName != "" {
+		name = "provision-" + contextName + ".lock"
+	}
+	return filepath.Join(homeDir, ".dockbridge", "locks", name), nil
+}
+
+// Acquire takes the lock at path, waiting while another process holds it until ctx is
+// done. onWait, if not nil, is called once with the holder's process ID when the lock
+// is busy.
+func Acquire(ctx context.Context, path string, onWait func(holder int)) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	waiting := false
+	for {
+		lock, err := TryAcquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+		if !waiting && onWait != nil {
+			onWait(holderPID(path))
+		}
+		waiting = true
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for provisioning by another process: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquire takes the lock at path without waiting; it returns ErrLocked while another
+// process holds it
+func TryAcquire(path string) (*Lock, error) {
+	fileLock, err := filelock.TryAcquire(path)
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{lock: fileLock}
+	if data, err := io.ReadAll(fileLock.File()); err == nil && len(data) > 0 {
+		_ = json.Unmarshal(data, &lock.state)
+	}
+	lock.state.PID = os.Getpid()
+	if err := lock.write(); err != nil {
+		_ = lock.Release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// PendingToken returns the token of a provisioning that an earlier holder started but
+// did not finish, or ""
+func (l *Lock) PendingToken() string {
+	return l.state.Token
+}
+
+// Begin records token as the provisioning in progress, before the server is created
+func (l *Lock) Begin(token string, now time.Time) error {
+	l.state.Token = token
+	l.state.Started = now
+	return l.write()
+}
+
+// Finish clears the provisioning in progress once its server is ready or gone
+func (l *Lock) Finish() error {
+	l.state.Token = ""
+	l.state.Started = time.Time{}
+	return l.write()
+}
+
+// Release releases the lock; the provisioning in progress, if any, is kept for the
+// next holder
+func (l *Lock) Release() error {
+	return l.lock.Release()
+}
+
+// write replaces the content of the lock file with the lock's state
+func (l *Lock) write() error {
+	data, err := json.Marshal(l.state)
+	if err != nil {
+		return err
+	}
+	file := l.lock.File()
+	if err := file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// holderPID returns the process ID recorded by the holder of the lock at path, or 0
+func holderPID(path string) int {
+	data, err := os.ReadFile(path) // #nosec G304 -- fixed location under the user's home directory
+	if err != nil {
+		return 0
+	}
+	var st state
+	if json.Unmarshal(data, &st) != nil {
+		return 0
+	}
+	return st.PID
+}
+
+// NewToken returns a random idempotency token for a provisioning, valid as a label value
+func NewToken() string {
+	raw := make([]byte, 8)
+	_, _ = rand.Read(raw)
+	return hex.EncodeToString(raw)
+}
@@ -0,0 +1,57 @@
+package provisionlock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "provision.lock")
+
+	lock, err := TryAcquire(path)
+	require.NoError(t, err)
+
+	_, err = TryAcquire(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	// A waiting process learns who holds the lock and gives up with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	holder := -1
+	_, err = Acquire(ctx, path, func(pid int) { holder = pid })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, os.Getpid(), holder)
+
+	require.NoError(t, lock.Release())
+	lock, err = Acquire(context.Background(), path, nil)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestLockPendingToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provision.lock")
+
+	lock, err := TryAcquire(path)
+	require.NoError(t, err)
+	assert.Empty(t, lock.PendingToken())
+	require.NoError(t, lock.Begin("abc123", time.Now()))
+	require.NoError(t, lock.Release())
+
+	// A provisioning interrupted before Finish is handed to the next holder
+	lock, err = TryAcquire(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", lock.PendingToken())
+	require.NoError(t, lock.Finish())
+	require.NoError(t, lock.Release())
+
+	lock, err = TryAcquire(path)
+	require.NoError(t, err)
+	assert.Empty(t, lock.PendingToken())
+	require.NoError(t, lock.Release())
+}
@@ -0,0 +1,258 @@
+// Package readiness waits for a newly provisioned or resumed server to become usable.
+// Probes are grouped into stages that run in order; the probes of a stage run in
+// parallel, each retried with exponential backoff, and report per-phase progress.
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase names one readiness check
+type Phase string
+
+const (
+	// PhaseSSH waits for the SSH server to accept connections
+	PhaseSSH Phase = "ssh"
+	// PhaseCloudInit waits for cloud-init to finish
+	PhaseCloudInit Phase = "cloud_init"
+	// PhaseSetup waits for the marker written at the end of the setup script
+	PhaseSetup Phase = "setup"
+	// PhaseDocker waits for the Docker daemon to respond
+	PhaseDocker Phase = "docker"
+	// PhaseKeepAlive waits for the server-side keep-alive monitor
+	PhaseKeepAlive Phase = "keepalive"
+)
+
+// Status is the state of a phase reported in Progress
+type Status string
+
+const (
+	// StatusWaiting means the last probe of the phase failed and it is retried
+	StatusWaiting Status = "waiting"
+	// StatusReady means the phase's probe succeeded
+	StatusReady Status = "ready"
+	// StatusFailed means the phase failed permanently
+	StatusFailed Status = "failed"
+	// StatusSkipped means an optional phase was abandoned once the server was ready
+	StatusSkipped Status = "skipped"
+)
+
+// Progress reports the state of one phase
+type Progress struct {
+	Phase   Phase
+	Status  Status
+	Attempt int
+	// Elapsed is the time since Wait started
+	Elapsed time.Duration
+	// Err is the last probe error while waiting or failed
+	Err error
+}
+
+// ProgressFunc receives progress updates; it may be called from several goroutines
+type ProgressFunc func(Progress)
+
+// Probe checks one phase; a nil error means the phase is ready
+type Probe struct {
+	Phase Phase
+	Check func(ctx context.Context) error
+
+	// Optional probes are reported but do not hold up readiness; they are abandoned
+	// once all required probes of their stage succeeded
+	Optional bool
+}
+
+// Config controls timeouts and backoff
+type Config struct {
+	// Timeout bounds the whole wait
+	Timeout time.Duration
+	// InitialBackoff is the delay after a probe's first failure; it doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// OnProgress receives phase updates (optional)
+	OnProgress ProgressFunc
+}
+
+// DefaultConfig returns the defaults used for new servers
+func DefaultConfig() Config {
+	return Config{
+		Timeout:        8 * time.Minute,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     15 * time.Second,
+	}
+}
+
+// permanentError stops retries of a probe
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a probe error as final, failing the wait without further retries
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Wait runs the stages in order and returns once every required probe succeeded. It
+// fails when a probe returns a Permanent error, ctx ends or the timeout passes.
+func Wait(ctx context.Context, cfg Config, stages ...[]Probe) error {
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	w := &waiter{config: cfg, start: time.Now(), lastErr: make(map[Phase]error), ready: make(map[Phase]bool)}
+	for _, stage := range stages {
+		if err := w.runStage(ctx, stage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waiter holds the state of one Wait call
+type waiter struct {
+	config Config
+	start  time.Time
+
+	mu      sync.Mutex
+	lastErr map[Phase]error
+	ready   map[Phase]bool
+}
+
+// runStage runs the probes of a stage in parallel
+func (w *waiter) runStage(ctx context.Context, probes []Probe) error {
+	stageCtx, cancelStage := context.WithCancel(ctx)
+	defer cancelStage()
+	optionalCtx, cancelOptional := context.WithCancel(stageCtx)
+	defer cancelOptional()
+
+	var required, optional sync.WaitGroup
+	errs := make(chan error, len(probes))
+	pending := make(map[Phase]bool)
+
+	for _, probe := range probes {
+		probeCtx, group := stageCtx, &required
+		if probe.Optional {
+			probeCtx, group = optionalCtx, &optional
+		} else {
+			pending[probe.Phase] = true
+		}
+
+		group.Add(1)
+		go func(probe Probe) {
+			defer group.Done()
+			if err := w.runProbe(probeCtx, probe); err != nil && !probe.Optional {
+				errs <- err
+				cancelStage()
+			}
+		}(probe)
+	}
+
+	required.Wait()
+	cancelOptional()
+	optional.Wait()
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		return err
+	}
+	if ctx.Err() != nil {
+		return w.timeoutError(ctx, pending)
+	}
+	return nil
+}
+
+// runProbe retries a probe with exponential backoff until it succeeds or fails permanently
+func (w *waiter) runProbe(ctx context.Context, probe Probe) error {
+	delay := w.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := probe.Check(ctx)
+		if err == nil {
+			w.mu.Lock()
+			w.ready[probe.Phase] = true
+			w.mu.Unlock()
+			w.report(Progress{Phase: probe.Phase, Status: StatusReady, Attempt: attempt})
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			w.report(Progress{Phase: probe.Phase, Status: StatusFailed, Attempt: attempt, Err: permanent.err})
+			return fmt.Errorf("%s: %w", probe.Phase, permanent.err)
+		}
+
+		w.mu.Lock()
+		w.lastErr[probe.Phase] = err
+		w.mu.Unlock()
+
+		if ctx.Err() != nil {
+			if probe.Optional {
+				w.report(Progress{Phase: probe.Phase, Status: StatusSkipped, Attempt: attempt, Err: err})
+			}
+			return nil
+		}
+		w.report(Progress{Phase: probe.Phase, Status: StatusWaiting, Attempt: attempt, Err: err})
+
+		select {
+		case <-ctx.Done():
+			if probe.Optional {
+				w.report(Progress{Phase: probe.Phase, Status: StatusSkipped, Attempt: attempt, Err: err})
+			}
+			return nil
+		case <-time.After(delay):
+		}
+		delay = nextBackoff(delay, w.config.MaxBackoff)
+	}
+}
+
+// timeoutError describes the required phases that were not ready when ctx ended
+func (w *waiter) timeoutError(ctx context.Context, pending map[Phase]bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var details []string
+	for phase := range pending {
+		if w.ready[phase] {
+			continue
+		}
+		if err, ok := w.lastErr[phase]; ok {
+			details = append(details, fmt.Sprintf("%s: %v", phase, err))
+		}
+	}
+	sort.Strings(details)
+
+	if len(details) == 0 {
+		return fmt.Errorf("server not ready after %s: %w", time.Since(w.start).Round(time.Second), ctx.Err())
+	}
+	return fmt.Errorf("server not ready after %s (%s): %w", time.Since(w.start).Round(time.Second), strings.Join(details, "; "), ctx.Err())
+}
+
+// report sends a progress update, stamping the elapsed time
+func (w *waiter) report(progress Progress) {
+	if w.config.OnProgress == nil {
+		return
+	}
+	progress.Elapsed = time.Since(w.start)
+	w.config.OnProgress(progress)
+}
+
+// nextBackoff doubles delay up to max
+func nextBackoff(delay, max time.Duration) time.Duration {
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	delay *= 2
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,117 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(progress *[]Progress, mu *sync.Mutex) Config {
+	return Config{
+		Timeout:        2 * time.Second,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		OnProgress: func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			*progress = append(*progress, p)
+		},
+	}
+}
+
+// succeedAfter returns a check that fails until it was called n times
+func succeedAfter(n int32) func(context.Context) error {
+	var calls atomic.Int32
+	return func(context.Context) error {
+		if calls.Add(1) < n {
+			return errors.New("not yet")
+		}
+		return nil
+	}
+}
+
+func TestWaitRunsStagesInOrder(t *testing.T) {
+	var progress []Progress
+	var mu sync.Mutex
+
+	var sshReady atomic.Bool
+	err := Wait(context.Background(), testConfig(&progress, &mu),
+		[]Probe{{Phase: PhaseSSH, Check: func(ctx context.Context) error {
+			if err := succeedAfter(1)(ctx); err != nil {
+				return err
+			}
+			sshReady.Store(true)
+			return nil
+		}}},
+		[]Probe{
+			{Phase: PhaseDocker, Check: func(ctx context.Context) error {
+				if !sshReady.Load() {
+					return Permanent(errors.New("ran before ssh"))
+				}
+				return nil
+			}},
+			{Phase: PhaseCloudInit, Check: succeedAfter(3)},
+		},
+	)
+	require.NoError(t, err)
+
+	ready := map[Phase]int{}
+	for _, p := range progress {
+		if p.Status == StatusReady {
+			ready[p.Phase] = p.Attempt
+		}
+	}
+	assert.Equal(t, map[Phase]int{PhaseSSH: 1, PhaseDocker: 1, PhaseCloudInit: 3}, ready)
+}
+
+func TestWaitSkipsOptionalProbes(t *testing.T) {
+	var progress []Progress
+	var mu sync.Mutex
+
+	err := Wait(context.Background(), testConfig(&progress, &mu), []Probe{
+		{Phase: PhaseDocker, Check: succeedAfter(2)},
+		{Phase: PhaseKeepAlive, Optional: true, Check: func(context.Context) error { return errors.New("connection refused") }},
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	last := progress[len(progress)-1]
+	assert.Equal(t, PhaseKeepAlive, last.Phase)
+	assert.Equal(t, StatusSkipped, last.Status)
+}
+
+func TestWaitPermanentError(t *testing.T) {
+	var progress []Progress
+	var mu sync.Mutex
+
+	err := Wait(context.Background(), testConfig(&progress, &mu), []Probe{
+		{Phase: PhaseCloudInit, Check: func(context.Context) error { return Permanent(errors.New("status: error")) }},
+		{Phase: PhaseDocker, Check: func(context.Context) error { return errors.New("not yet") }},
+	})
+	assert.EqualError(t, err, "cloud_init: status: error")
+}
+
+func TestWaitTimeout(t *testing.T) {
+	cfg := Config{Timeout: 50 * time.Millisecond, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	err := Wait(context.Background(), cfg, []Probe{
+		{Phase: PhaseSSH, Check: succeedAfter(1)},
+		{Phase: PhaseDocker, Check: func(context.Context) error { return errors.New("daemon not running") }},
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "docker: daemon not running")
+	assert.NotContains(t, err.Error(), "ssh")
+}
+
+func TestNextBackoff(t *testing.T) {
+	assert.Equal(t, 4*time.Second, nextBackoff(2*time.Second, 15*time.Second))
+	assert.Equal(t, 15*time.Second, nextBackoff(10*time.Second, 15*time.Second))
+}
@@ -0,0 +1,49 @@
+// Package registrymirror runs a pull-through cache of Docker Hub on DockBridge servers.
+// The cache keeps its storage in a Docker volume on the Docker data volume, so images
+// pulled by earlier servers are served locally after a server is recreated.
+package registrymirror
+
+import (
+	"fmt"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+// ContainerName is the name of the cache's container on the server
+const ContainerName = "dockbridge-registry-cache"
+
+// VolumeName is the Docker volume holding the cached blobs
+const VolumeName = "dockbridge-registry-cache"
+
+// upstreamURL is the registry the cache pulls from. dockerd only consults mirrors
+// for Docker Hub images.
+const upstreamURL = "https://registry-1.docker.io"
+
+// URL returns the address dockerd reaches the cache at, configured as its registry
+// mirror, or an empty string when the cache is disabled. Loopback registries need no TLS.
+func URL(cfg *config.RegistryMirrorConfig) string {
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", cfg.Port)
+}
+
+// SetupScript returns a shell snippet for server provisioning that starts the cache
+// once dockerd runs. Until it is up, and whenever it fails, dockerd pulls from Docker
+// Hub directly. It returns an empty string when the cache is disabled.
+func SetupScript(cfg *config.RegistryMirrorConfig) string {
+	if URL(cfg) == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`# Start the pull-through registry cache
+echo "$(date): Starting the registry cache"
+docker rm -f %[1]s >/dev/null 2>&1 || true
+docker run -d --name %[1]s --restart always \
+    -p 127.0.0.1:%[2]d:5000 \
+    -v %[3]s:/var/lib/registry \
+    -e REGISTRY_PROXY_REMOTEURL=%[4]s \
+    %[5]s || echo "$(date): Failed to start the registry cache, pulling from Docker Hub directly"
+
+`, ContainerName, cfg.Port, VolumeName, upstreamURL, cfg.Image)
+}
@@ -0,0 +1,25 @@
+package registrymirror
+
+import (
+	"testing"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisabled(t *testing.T) {
+	assert.Empty(t, SetupScript(nil))
+	assert.Empty(t, URL(&config.RegistryMirrorConfig{Enabled: false, Port: 5000}))
+}
+
+func TestSetupScript(t *testing.T) {
+	cfg := &config.RegistryMirrorConfig{Enabled: true, Port: 5001, Image: "registry:2.8"}
+
+	assert.Equal(t, "http://127.0.0.1:5001", URL(cfg))
+
+	script := SetupScript(cfg)
+	assert.Contains(t, script, "-p 127.0.0.1:5001:5000")
+	assert.Contains(t, script, "-v dockbridge-registry-cache:/var/lib/registry")
+	assert.Contains(t, script, "REGISTRY_PROXY_REMOTEURL=https://registry-1.docker.io")
+	assert.Contains(t, script, "registry:2.8 ||")
+}
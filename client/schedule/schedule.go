@@ -0,0 +1,205 @@
+// Package schedule keeps the server available during working hours only: it shuts the
+// server down outside them once the client is idle and provisions it again shortly
+// before they start.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+)
+
+const (
+	// DefaultWorkingHours are used when the configuration leaves them unset
+	DefaultWorkingHours = "09:00-18:00"
+
+	// dateLayout is the format of holidays and exception dates
+	dateLayout = "2006-01-02"
+
+	// lookahead bounds how many days Next searches for working hours
+	lookahead = 366
+)
+
+// defaultDays are the working days when the configuration leaves them unset
+var defaultDays = []string{"mon", "tue", "wed", "thu", "fri"}
+
+// weekdays maps the configured day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Hours is a daily range of working hours; unlike reboot windows it does not wrap past
+// midnight
+type Hours struct {
+	Start time.Duration // offset from midnight
+	End   time.Duration
+}
+
+// ParseHours parses working hours in "HH:MM-HH:MM" form
+func ParseHours(s string) (Hours, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return Hours{}, fmt.Errorf("invalid working hours %q, expected HH:MM-HH:MM", s)
+	}
+
+	start, err := parseClock(startStr)
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid working hours %q: %w", s, err)
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid working hours %q: %w", s, err)
+	}
+	if start >= end {
+		return Hours{}, fmt.Errorf("invalid working hours %q: start must be before end", s)
+	}
+	return Hours{Start: start, End: end}, nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight; "24:00" ends a day
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the hours in "HH:MM-HH:MM" form
+func (h Hours) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(h.Start) + "-" + clock(h.End)
+}
+
+// Period is one stretch of working hours
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Schedule answers when the working hours are, in their time zone
+type Schedule struct {
+	location   *time.Location
+	hours      Hours
+	days       map[time.Weekday]bool
+	holidays   map[string]bool
+	exceptions map[string]Hours
+}
+
+// New parses a schedule configuration. Unset working hours and days default to
+// DefaultWorkingHours on weekdays.
+func New(cfg config.ScheduleConfig) (*Schedule, error) {
+	location := time.Local
+	if cfg.Timezone != "" {
+		loaded, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		location = loaded
+	}
+
+	workingHours := cfg.WorkingHours
+	if workingHours == "" {
+		workingHours = DefaultWorkingHours
+	}
+	hours, err := ParseHours(workingHours)
+	if err != nil {
+		return nil, err
+	}
+
+	days := cfg.Days
+	if len(days) == 0 {
+		days = defaultDays
+	}
+	s := &Schedule{
+		location:   location,
+		hours:      hours,
+		days:       make(map[time.Weekday]bool, len(days)),
+		holidays:   make(map[string]bool, len(cfg.Holidays)),
+		exceptions: make(map[string]Hours, len(cfg.Exceptions)),
+	}
+	for _, day := range days {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q, must be one of mon, tue, wed, thu, fri, sat, sun", day)
+		}
+		s.days[weekday] = true
+	}
+
+	for _, holiday := range cfg.Holidays {
+		if _, err := time.Parse(dateLayout, holiday); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q, expected YYYY-MM-DD", holiday)
+		}
+		s.holidays[holiday] = true
+	}
+	for _, exception := range cfg.Exceptions {
+		if _, err := time.Parse(dateLayout, exception.Date); err != nil {
+			return nil, fmt.Errorf("invalid exception date %q, expected YYYY-MM-DD", exception.Date)
+		}
+		hours, err := ParseHours(exception.Hours)
+		if err != nil {
+			return nil, fmt.Errorf("exception %s: %w", exception.Date, err)
+		}
+		s.exceptions[exception.Date] = hours
+	}
+	return s, nil
+}
+
+// Location returns the time zone of the working hours
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// On returns the working hours of the date of t in the schedule's time zone, and false
+// if it has none. Exceptions take precedence over holidays and working days.
+func (s *Schedule) On(t time.Time) (Hours, bool) {
+	date := t.In(s.location).Format(dateLayout)
+	if hours, ok := s.exceptions[date]; ok {
+		return hours, true
+	}
+	if s.holidays[date] || !s.days[t.In(s.location).Weekday()] {
+		return Hours{}, false
+	}
+	return s.hours, true
+}
+
+// Working reports whether t falls inside the working hours
+func (s *Schedule) Working(t time.Time) bool {
+	period, ok := s.Next(t)
+	return ok && !t.Before(period.Start)
+}
+
+// Next returns the working hours in progress at t or, outside them, the next ones. It
+// reports false if there are none within a year.
+func (s *Schedule) Next(t time.Time) (Period, bool) {
+	local := t.In(s.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	for i := 0; i < lookahead; i++ {
+		day := midnight.AddDate(0, 0, i)
+		hours, ok := s.On(day)
+		if !ok {
+			continue
+		}
+		period := Period{Start: at(day, hours.Start), End: at(day, hours.End)}
+		if t.Before(period.End) {
+			return period, true
+		}
+	}
+	return Period{}, false
+}
+
+// at returns the wall-clock time offset from midnight of day, so that working hours
+// keep their clock time across daylight saving changes
+func at(day time.Time, offset time.Duration) time.Time {
+	hour, minute := int(offset/time.Hour), int(offset%time.Hour/time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHours(t *testing.T) {
+	hours, err := ParseHours("08:30-17:00")
+	require.NoError(t, err)
+	assert.Equal(t, 8*time.Hour+30*time.Minute, hours.Start)
+	assert.Equal(t, 17*time.Hour, hours.End)
+	assert.Equal(t, "08:30-17:00", hours.String())
+
+	hours, err = ParseHours("20:00-24:00")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, hours.End)
+
+	for _, invalid := range []string{"", "09:00", "18:00-09:00", "09:00-09:00", "9am-5pm"} {
+		_, err := ParseHours(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	s, err := New(config.ScheduleConfig{
+		Timezone:     "Europe/Berlin",
+		WorkingHours: "09:00-18:00",
+		Days:         []string{"mon", "tue", "wed", "thu", "fri"},
+		Holidays:     []string{"2026-10-19"},
+		Exceptions:   []config.ScheduleException{{Date: "2026-10-17", Hours: "10:00-14:00"}},
+	})
+	require.NoError(t, err)
+
+	// Friday during working hours
+	friday := time.Date(2026, 10, 16, 12, 0, 0, 0, berlin)
+	assert.True(t, s.Working(friday))
+	period, ok := s.Next(friday)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 10, 16, 18, 0, 0, 0, berlin), period.End)
+
+	// Friday evening: the exception makes Saturday a working day
+	evening := time.Date(2026, 10, 16, 20, 0, 0, 0, berlin)
+	assert.False(t, s.Working(evening))
+	period, _ = s.Next(evening)
+	assert.Equal(t, time.Date(2026, 10, 17, 10, 0, 0, 0, berlin), period.Start)
+
+	// Saturday afternoon: Sunday is off and Monday a holiday
+	saturday := time.Date(2026, 10, 17, 15, 0, 0, 0, berlin)
+	period, _ = s.Next(saturday)
+	assert.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, berlin), period.Start)
+
+	// Time zones other than the schedule's are converted
+	assert.True(t, s.Working(time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestScheduleDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	s, err := New(config.ScheduleConfig{Timezone: "Europe/Berlin", Days: []string{"mon"}})
+	require.NoError(t, err)
+
+	// Clocks go back on Sunday 2026-10-25; Monday still starts at 09:00 wall-clock time
+	period, ok := s.Next(time.Date(2026, 10, 24, 12, 0, 0, 0, berlin))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 10, 26, 9, 0, 0, 0, berlin), period.Start)
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ScheduleConfig
+	}{
+		{"timezone", config.ScheduleConfig{Timezone: "Mars/Olympus"}},
+		{"working hours", config.ScheduleConfig{WorkingHours: "18:00-09:00"}},
+		{"day", config.ScheduleConfig{Days: []string{"monday"}}},
+		{"holiday", config.ScheduleConfig{Holidays: []string{"25.12.2026"}}},
+		{"exception hours", config.ScheduleConfig{Exceptions: []config.ScheduleException{{Date: "2026-12-24", Hours: "off"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+// DefaultCheckInterval is how often the scheduler compares the time with the schedule
+const DefaultCheckInterval = time.Minute
+
+// Scheduler provisions the server shortly before the working hours and shuts it down
+// outside them. A server used after hours is only shut down once the client has been
+// idle for OffHoursIdle, so late work is never interrupted.
+type Scheduler struct {
+	Schedule *Schedule
+
+	// Prewarm is how long before the working hours the server is provisioned; 0 disables it
+	Prewarm time.Duration
+
+	// OffHoursIdle is how long the client must be idle outside the working hours
+	OffHoursIdle time.Duration
+
+	// Connected reports whether the client is connected to a running server
+	Connected func() bool
+
+	// IdleFor returns how long the client has been idle
+	IdleFor func() time.Duration
+
+	// Warm connects to the server, provisioning or resuming it if needed
+	Warm func(ctx context.Context) error
+
+	// Hold keeps a prewarmed server from being shut down as idle before the working hours
+	Hold func()
+
+	// Shutdown destroys or powers off the server
+	Shutdown func(reason string) error
+
+	CheckInterval time.Duration
+	Logger        logger.LoggerInterface
+
+	// warmed is the start of the working hours the server was last prewarmed for
+	warmed time.Time
+
+	// now returns the current time (replaceable in tests)
+	now func() time.Time
+}
+
+// Run checks periodically until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	interval := s.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check prewarms or shuts down the server depending on the time
+func (s *Scheduler) check(ctx context.Context) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now()
+
+	period, ok := s.Schedule.Next(t)
+	if ok && !t.Before(period.Start) {
+		// Working hours; the idle timeout applies as usual
+		return
+	}
+
+	if ok && s.Prewarm > 0 && !t.Before(period.Start.Add(-s.Prewarm)) {
+		s.prewarm(ctx, period)
+		return
+	}
+
+	if !s.Connected() {
+		return
+	}
+	if idle := s.IdleFor(); idle < s.OffHoursIdle {
+		s.Logger.WithFields(map[string]any{
+			"idle_for":       idle,
+			"off_hours_idle": s.OffHoursIdle,
+		}).Debug("Outside working hours but client is active, keeping server")
+		return
+	}
+
+	s.Logger.WithFields(map[string]any{
+		"next_start": period.Start,
+	}).Info("Shutting down idle server outside working hours")
+	if err := s.Shutdown("off_hours"); err != nil {
+		s.Logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to shut down server outside working hours")
+	}
+}
+
+// prewarm provisions the server for the working hours starting with period, once, and
+// keeps it from being shut down as idle until they start
+func (s *Scheduler) prewarm(ctx context.Context, period Period) {
+	if s.Connected() {
+		if s.Hold != nil {
+			s.Hold()
+		}
+		return
+	}
+	if s.warmed.Equal(period.Start) {
+		// Already prewarmed; the server was shut down on purpose since
+		return
+	}
+	s.warmed = period.Start
+
+	s.Logger.WithFields(map[string]any{
+		"working_hours_start": period.Start,
+	}).Info("Provisioning server ahead of working hours")
+	if err := s.Warm(ctx); err != nil {
+		s.Logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to provision server ahead of working hours")
+		return
+	}
+	if s.Hold != nil {
+		s.Hold()
+	}
+}
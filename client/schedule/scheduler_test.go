@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerCheck(t *testing.T) {
+	s, err := New(config.ScheduleConfig{Timezone: "UTC"})
+	require.NoError(t, err)
+
+	working := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	beforeWork := time.Date(2026, 10, 16, 8, 55, 0, 0, time.UTC)
+	evening := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		now            time.Time
+		connected      bool
+		idle           time.Duration
+		expectWarm     bool
+		expectHold     bool
+		expectShutdown bool
+	}{
+		{"working hours", working, true, time.Hour, false, false, false},
+		{"prewarm", beforeWork, false, time.Hour, true, true, false},
+		{"prewarmed server held", beforeWork, true, time.Hour, false, true, false},
+		{"idle after hours", evening, true, time.Hour, false, false, true},
+		{"active after hours", evening, true, time.Minute, false, false, false},
+		{"no server after hours", evening, false, time.Hour, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warmed, held, shutdown bool
+			scheduler := &Scheduler{
+				Schedule:     s,
+				Prewarm:      10 * time.Minute,
+				OffHoursIdle: 10 * time.Minute,
+				Connected:    func() bool { return tt.connected },
+				IdleFor:      func() time.Duration { return tt.idle },
+				Warm:         func(ctx context.Context) error { warmed = true; return nil },
+				Hold:         func() { held = true },
+				Shutdown:     func(reason string) error { shutdown = true; return nil },
+				Logger:       logger.NewDefault(),
+				now:          func() time.Time { return tt.now },
+			}
+
+			scheduler.check(context.Background())
+
+			assert.Equal(t, tt.expectWarm, warmed)
+			assert.Equal(t, tt.expectHold, held)
+			assert.Equal(t, tt.expectShutdown, shutdown)
+		})
+	}
+}
+
+func TestSchedulerPrewarmsOnce(t *testing.T) {
+	s, err := New(config.ScheduleConfig{Timezone: "UTC"})
+	require.NoError(t, err)
+
+	warms := 0
+	scheduler := &Scheduler{
+		Schedule:  s,
+		Prewarm:   10 * time.Minute,
+		Connected: func() bool { return false },
+		Warm:      func(ctx context.Context) error { warms++; return nil },
+		Logger:    logger.NewDefault(),
+		now:       func() time.Time { return time.Date(2026, 10, 16, 8, 55, 0, 0, time.UTC) },
+	}
+
+	// A server shut down on purpose after prewarming is not provisioned again
+	scheduler.check(context.Background())
+	scheduler.check(context.Background())
+	assert.Equal(t, 1, warms)
+}
@@ -0,0 +1,193 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/filelock"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// fileMagic starts the encrypted secrets file and versions its format
+var fileMagic = []byte("dockbridge-secrets-v1\n")
+
+// File format: magic, scrypt salt, XChaCha20-Poly1305 nonce, then the sealed JSON
+// object of all secrets
+const (
+	saltSize = 16
+	// scryptN is the scrypt work factor, about 100ms on a laptop
+	scryptN = 1 << 15
+)
+
+// lockTimeout bounds waiting for another process updating the secrets file
+const lockTimeout = 10 * time.Second
+
+// fileStore keeps secrets in a file encrypted with a key derived from a passphrase
+type fileStore struct {
+	path       string
+	passphrase func() (string, error)
+}
+
+func (s *fileStore) Name() string { return BackendFile }
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+// lock takes the lock file of the secrets file for a read-modify-write cycle, so that
+// concurrent updates do not lose each other's secrets; the returned function releases it
+func (s *fileStore) lock() (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+
+	lock, err := filelock.Acquire(ctx, s.path+".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock secrets file: %w", err)
+	}
+	return func() { _ = lock.Release() }, nil
+}
+
+// load decrypts the secrets of the file; a missing file holds none
+func (s *fileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	if !bytes.HasPrefix(data, fileMagic) || len(data) < len(fileMagic)+saltSize+chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("%s is not a DockBridge secrets file", s.path)
+	}
+	data = data[len(fileMagic):]
+	salt, nonce, sealed := data[:saltSize], data[saltSize:saltSize+chacha20poly1305.NonceSizeX], data[saltSize+chacha20poly1305.NonceSizeX:]
+
+	aead, err := s.newAEAD(salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, sealed, fileMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: wrong passphrase or corrupted file", s.path)
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets with a fresh salt and nonce and replaces the file
+func (s *fileStore) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	aead, err := s.newAEAD(salt)
+	if err != nil {
+		return err
+	}
+
+	data := append(append(append(append([]byte{}, fileMagic...), salt...), nonce...), aead.Seal(nil, nonce, plaintext, fileMagic)...)
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	// A file of its own, created readable only by the user, replaces the secrets file
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create secrets file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace secrets file: %w", err)
+	}
+	return nil
+}
+
+// newAEAD derives the file key from the passphrase and salt
+func (s *fileStore) newAEAD(salt []byte) (cipher.AEAD, error) {
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, errors.New("the secrets passphrase cannot be empty")
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return chacha20poly1305.NewX(key)
+}
@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainStore keeps secrets in the macOS login keychain with the security tool
+type keychainStore struct {
+	run Runner
+}
+
+func (s *keychainStore) Name() string { return BackendKeychain }
+
+func (s *keychainStore) Get(key string) (string, error) {
+	out, err := s.run("", "security", "find-generic-password", "-s", service, "-a", key, "-w")
+	if err != nil {
+		if keychainNotFound(err) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (s *keychainStore) Set(key, value string) error {
+	// Commands read from stdin keep the secret out of the process list; -X takes it
+	// hex encoded so that it needs no quoting
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", service, key, hex.EncodeToString([]byte(value)))
+	_, err := s.run(command, "security", "-i")
+	return err
+}
+
+func (s *keychainStore) Delete(key string) error {
+	if _, err := s.run("", "security", "delete-generic-password", "-s", service, "-a", key); err != nil && !keychainNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// keychainNotFound reports whether the security tool failed because the item is missing
+func keychainNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return true
+	}
+	return strings.Contains(err.Error(), "could not be found")
+}
+
+// secretServiceStore keeps secrets in the Secret Service (GNOME Keyring, KWallet) with
+// libsecret's secret-tool
+type secretServiceStore struct {
+	run Runner
+}
+
+func (s *secretServiceStore) Name() string { return BackendSecretService }
+
+func (s *secretServiceStore) Get(key string) (string, error) {
+	out, err := s.run("", "secret-tool", "lookup", "service", service, "key", key)
+	// secret-tool fails without output when nothing is stored under the attributes
+	if len(out) == 0 {
+		if err == nil || isExitError(err) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (s *secretServiceStore) Set(key, value string) error {
+	_, err := s.run(value, "secret-tool", "store", "--label=DockBridge "+key, "service", service, "key", key)
+	return err
+}
+
+func (s *secretServiceStore) Delete(key string) error {
+	// Clearing attributes that match nothing succeeds
+	_, err := s.run("", "secret-tool", "clear", "service", service, "key", key)
+	return err
+}
+
+// isExitError reports whether err is a command exiting with a non-zero status
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
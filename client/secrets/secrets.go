@@ -0,0 +1,180 @@
+// Package secrets stores credentials such as provider API tokens outside the
+// configuration file: in the macOS Keychain, the Linux Secret Service or a
+// passphrase-encrypted file.
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Backend names
+const (
+	BackendAuto          = "auto"
+	BackendKeychain      = "keychain"
+	BackendSecretService = "secret-service"
+	BackendFile          = "file"
+	BackendNone          = "none"
+)
+
+// PassphraseEnv holds the passphrase of the encrypted file backend
+const PassphraseEnv = "DOCKBRIDGE_SECRETS_PASSPHRASE"
+
+// service is the name the secrets are stored under in OS keyrings
+const service = "dockbridge"
+
+// ErrNotFound is returned when no secret is stored under a key
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps secrets by key
+type Store interface {
+	// Name is the backend name, e.g. "keychain"
+	Name() string
+	// Get returns the secret stored under key, or ErrNotFound
+	Get(key string) (string, error)
+	// Set stores value under key, replacing a stored secret
+	Set(key, value string) error
+	// Delete removes the secret stored under key; deleting a missing secret is not an error
+	Delete(key string) error
+}
+
+// Runner runs a command with stdin as its input and returns its standard output
+type Runner func(stdin, name string, args ...string) ([]byte, error)
+
+// ExecRunner runs commands with os/exec
+func ExecRunner(stdin, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...) // #nosec G204
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Options select and configure the backend
+type Options struct {
+	// Backend is one of the backend names; auto picks the OS keyring when one is
+	// available and the encrypted file otherwise
+	Backend string
+	// File is the path of the encrypted file backend
+	File string
+	// Passphrase returns the passphrase of the encrypted file; nil reads it from
+	// DOCKBRIDGE_SECRETS_PASSPHRASE
+	Passphrase func() (string, error)
+	// GOOS selects the OS keyring of auto
+	GOOS string
+	// Run runs the keyring commands; nil uses ExecRunner
+	Run Runner
+	// LookPath finds keyring commands; nil uses exec.LookPath
+	LookPath func(file string) (string, error)
+}
+
+// New returns the store selected by opts
+func New(opts Options) (Store, error) {
+	if opts.Run == nil {
+		opts.Run = ExecRunner
+	}
+	if opts.LookPath == nil {
+		opts.LookPath = exec.LookPath
+	}
+	if opts.Passphrase == nil {
+		opts.Passphrase = envPassphrase
+	}
+
+	backend := opts.Backend
+	if backend == "" || backend == BackendAuto {
+		backend = autoBackend(opts)
+	}
+
+	switch backend {
+	case BackendKeychain:
+		return &keychainStore{run: opts.Run}, nil
+	case BackendSecretService:
+		return &secretServiceStore{run: opts.Run}, nil
+	case BackendFile:
+		if opts.File == "" {
+			return nil, errors.New("the file backend requires a file path")
+		}
+		return &fileStore{path: opts.File, passphrase: rememberPassphrase(opts.Passphrase)}, nil
+	case BackendNone:
+		return noneStore{}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets backend '%s'", opts.Backend)
+	}
+}
+
+// autoBackend returns the OS keyring when its command is installed, and the encrypted
+// file otherwise
+func autoBackend(opts Options) string {
+	switch opts.GOOS {
+	case "darwin":
+		if _, err := opts.LookPath("security"); err == nil {
+			return BackendKeychain
+		}
+	case "linux", "freebsd", "openbsd":
+		if _, err := opts.LookPath("secret-tool"); err == nil {
+			return BackendSecretService
+		}
+	}
+	return BackendFile
+}
+
+// envPassphrase reads the passphrase of the encrypted file from the environment
+func envPassphrase() (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	return "", fmt.Errorf("set %s to unlock the encrypted secrets file", PassphraseEnv)
+}
+
+// rememberPassphrase asks for the passphrase once, not on every read and write; failures
+// are not remembered
+func rememberPassphrase(passphrase func() (string, error)) func() (string, error) {
+	var (
+		mu         sync.Mutex
+		remembered string
+	)
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if remembered != "" {
+			return remembered, nil
+		}
+		value, err := passphrase()
+		if err != nil {
+			return "", err
+		}
+		remembered = value
+		return value, nil
+	}
+}
+
+// APITokenKey returns the key of a provider's API token; profiles may have tokens of
+// their own, e.g. for another project
+func APITokenKey(provider, profile string) string {
+	if profile == "" {
+		return provider + "/api_token"
+	}
+	return provider + "/" + profile + "/api_token"
+}
+
+// noneStore stores nothing
+type noneStore struct{}
+
+func (noneStore) Name() string { return BackendNone }
+
+func (noneStore) Get(key string) (string, error) { return "", ErrNotFound }
+
+func (noneStore) Set(key, value string) error {
+	return errors.New("secret storage is disabled (secrets.backend: none)")
+}
+
+func (noneStore) Delete(key string) error { return nil }
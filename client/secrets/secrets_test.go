@@ -0,0 +1,179 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passphrase(value string) func() (string, error) {
+	return func() (string, error) { return value, nil }
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := New(Options{Backend: BackendFile, File: path, Passphrase: passphrase("correct horse")})
+	require.NoError(t, err)
+
+	_, err = store.Get("hetzner/api_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("hetzner/api_token", "secret-token"))
+	require.NoError(t, store.Set("digitalocean/api_token", "other-token"))
+	value, err := store.Get("hetzner/api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", value)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	wrong, err := New(Options{Backend: BackendFile, File: path, Passphrase: passphrase("wrong")})
+	require.NoError(t, err)
+	_, err = wrong.Get("hetzner/api_token")
+	assert.ErrorContains(t, err, "wrong passphrase")
+
+	require.NoError(t, store.Delete("hetzner/api_token"))
+	require.NoError(t, store.Delete("hetzner/api_token"))
+	_, err = store.Get("hetzner/api_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	value, err = store.Get("digitalocean/api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "other-token", value)
+}
+
+func TestFileStoreConcurrentSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.enc")
+
+	// Each writer is a store of its own, as in separate processes
+	var wg sync.WaitGroup
+	for i := range 3 {
+		store, err := New(Options{Backend: BackendFile, File: path, Passphrase: passphrase("correct horse")})
+		require.NoError(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Set(fmt.Sprintf("key-%d", i), "value"))
+		}()
+	}
+	wg.Wait()
+
+	store, err := New(Options{Backend: BackendFile, File: path, Passphrase: passphrase("correct horse")})
+	require.NoError(t, err)
+	for i := range 3 {
+		_, err := store.Get(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err, "no update is lost")
+	}
+
+	temporary, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, temporary)
+}
+
+func TestFileStorePassphraseFromEnv(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	store, err := New(Options{Backend: BackendFile, File: filepath.Join(t.TempDir(), "secrets.enc")})
+	require.NoError(t, err)
+	assert.ErrorContains(t, store.Set("key", "value"), PassphraseEnv)
+
+	t.Setenv(PassphraseEnv, "from-env")
+	require.NoError(t, store.Set("key", "value"))
+}
+
+// fakeKeyring records commands and answers them from a map of stored secrets
+type fakeKeyring struct {
+	commands []string
+	stdin    []string
+	stored   map[string]string
+}
+
+func (f *fakeKeyring) run(stdin, name string, args ...string) ([]byte, error) {
+	f.commands = append(f.commands, name+" "+strings.Join(args, " "))
+	f.stdin = append(f.stdin, stdin)
+	key := args[len(args)-1]
+	if name == "security" && args[0] == "find-generic-password" {
+		key = args[4]
+	}
+	value, ok := f.stored[key]
+	switch {
+	case name == "security" && args[0] == "find-generic-password" && !ok:
+		return nil, errors.New("security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain.")
+	case name == "secret-tool" && args[0] == "lookup" && !ok:
+		return nil, &exec.ExitError{}
+	case ok:
+		return []byte(value + "\n"), nil
+	}
+	return nil, nil
+}
+
+func TestKeychainStore(t *testing.T) {
+	keyring := &fakeKeyring{stored: map[string]string{"hetzner/api_token": "token"}}
+	store, err := New(Options{Backend: BackendKeychain, Run: keyring.run})
+	require.NoError(t, err)
+
+	value, err := store.Get("hetzner/api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "token", value)
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("hetzner/api_token", "new token"))
+	assert.Equal(t, "security -i", keyring.commands[2])
+	assert.Equal(t, "add-generic-password -U -s dockbridge -a hetzner/api_token -X 6e657720746f6b656e\n", keyring.stdin[2])
+}
+
+func TestSecretServiceStore(t *testing.T) {
+	keyring := &fakeKeyring{stored: map[string]string{"hetzner/api_token": "token"}}
+	store, err := New(Options{Backend: BackendSecretService, Run: keyring.run})
+	require.NoError(t, err)
+
+	value, err := store.Get("hetzner/api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "token", value)
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("digitalocean/api_token", "secret"))
+	assert.Equal(t, "secret-tool store --label=DockBridge digitalocean/api_token service dockbridge key digitalocean/api_token", keyring.commands[2])
+	assert.Equal(t, "secret", keyring.stdin[2])
+}
+
+func TestAutoBackend(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/tool", nil }
+	missing := func(string) (string, error) { return "", exec.ErrNotFound }
+
+	for _, tc := range []struct {
+		goos     string
+		lookPath func(string) (string, error)
+		want     string
+	}{
+		{"darwin", found, BackendKeychain},
+		{"linux", found, BackendSecretService},
+		{"linux", missing, BackendFile},
+		{"windows", found, BackendFile},
+	} {
+		store, err := New(Options{GOOS: tc.goos, LookPath: tc.lookPath, File: "/tmp/secrets.enc"})
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, store.Name(), tc.goos)
+	}
+
+	_, err := New(Options{Backend: "vault"})
+	assert.ErrorContains(t, err, "unknown secrets backend 'vault'")
+}
+
+func TestAPITokenKey(t *testing.T) {
+	assert.Equal(t, "hetzner/api_token", APITokenKey("hetzner", ""))
+	assert.Equal(t, "hetzner/work/api_token", APITokenKey("hetzner", "work"))
+}
@@ -0,0 +1,262 @@
+// Package selfupdate downloads DockBridge release binaries from GitHub, verifies them
+// against the checksums published with each release, and installs them in place of
+// the running client or the dockbridge-server binary of a server.
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Repository is the GitHub repository DockBridge is released from
+const Repository = "dockbridge/dockbridge"
+
+const (
+	defaultAPIURL = "https://api.github.com"
+
+	// checksumsAsset lists the SHA-256 checksums of a release's binaries, as written by
+	// sha256sum
+	checksumsAsset = "checksums.txt"
+
+	// signatureAsset is the ed25519 signature of checksumsAsset
+	signatureAsset = "checksums.txt.sig"
+
+	// maxAssetSize bounds downloads, so that a broken response cannot fill the disk
+	maxAssetSize = 256 << 20
+)
+
+// PublicKey is the base64 ed25519 key release checksums are signed with. Release builds
+// set it with -ldflags; builds without it verify checksums only.
+var PublicKey = ""
+
+// Release is a published DockBridge release
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the version of the release without the leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// asset returns the asset called name
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// BinaryName returns the release asset of the client for an OS and architecture
+func BinaryName(goos, goarch string) string {
+	return fmt.Sprintf("dockbridge-%s-%s", goos, goarch)
+}
+
+// ServerBinaryName returns the release asset of dockbridge-server for an architecture
+func ServerBinaryName(goarch string) string {
+	return "dockbridge-server-linux-" + goarch
+}
+
+// Client looks up releases and downloads their verified binaries
+type Client struct {
+	HTTPClient *http.Client
+
+	// APIURL is the base URL of the GitHub API
+	APIURL string
+
+	// PublicKey verifies the signature of the checksums; empty skips the signature
+	PublicKey string
+}
+
+// NewClient returns a client of the GitHub releases of DockBridge
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute},
+		APIURL:     defaultAPIURL,
+		PublicKey:  PublicKey,
+	}
+}
+
+// Latest returns the latest release, pre-releases excluded
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	return c.release(ctx, "/repos/"+Repository+"/releases/latest")
+}
+
+// ByTag returns the release tagged tag, e.g. v1.2.3
+func (c *Client) ByTag(ctx context.Context, tag string) (*Release, error) {
+	return c.release(ctx, "/repos/"+Repository+"/releases/tags/"+tag)
+}
+
+func (c *Client) release(ctx context.Context, path string) (*Release, error) {
+	body, err := c.get(ctx, c.APIURL+path, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	return &release, nil
+}
+
+// Download downloads the asset called name of release and verifies it against the
+// release's checksums, whose signature is verified first when the client has a
+// public key
+func (c *Client) Download(ctx context.Context, release *Release, name string) ([]byte, error) {
+	checksums, err := c.download(ctx, release, checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	if c.PublicKey != "" {
+		signature, err := c.download(ctx, release, signatureAsset)
+		if err != nil {
+			return nil, fmt.Errorf("release %s is not signed: %w", release.Tag, err)
+		}
+		if err := verifySignature(c.PublicKey, checksums, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	expected, ok := parseChecksums(checksums)[name]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no checksum for %s", release.Tag, name)
+	}
+
+	data, err := c.download(ctx, release, name)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s of release %s", name, release.Tag)
+	}
+	return data, nil
+}
+
+// download fetches the asset called name of release
+func (c *Client) download(ctx context.Context, release *Release, name string) ([]byte, error) {
+	asset, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.Tag, name)
+	}
+	data, err := c.get(ctx, asset.URL, maxAssetSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// get returns the body of a GET of url, at most limit bytes
+func (c *Client) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s: response larger than %d bytes", url, limit)
+	}
+	return body, nil
+}
+
+// verifySignature verifies the ed25519 signature of message with the base64 key
+func verifySignature(publicKey string, message, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release signing key")
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), message, signature) {
+		return fmt.Errorf("invalid signature of the release checksums")
+	}
+	return nil
+}
+
+// parseChecksums parses sha256sum output into checksums by file name
+func parseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// Binary mode marks the name with a leading *
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// Install replaces the executable at path with data. The new binary is written next to
+// it and renamed over it, so that path is never left half-written.
+func Install(path string, data []byte) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dockbridge-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		// A running executable cannot be replaced, but it can be moved aside
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to replace %s: %w", path, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
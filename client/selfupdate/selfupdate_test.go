@@ -0,0 +1,126 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseServer serves a release with the given assets from the GitHub API paths
+func releaseServer(t *testing.T, tag string, assets map[string][]byte) (*httptest.Server, *Release) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	release := &Release{Tag: tag}
+	for name, data := range assets {
+		release.Assets = append(release.Assets, Asset{Name: name, URL: server.URL + "/download/" + name})
+		mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(data)
+		})
+	}
+	mux.HandleFunc("/repos/"+Repository+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release)
+	})
+	mux.HandleFunc("/repos/"+Repository+"/releases/tags/"+tag, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release)
+	})
+	return server, release
+}
+
+func checksumLine(name string, data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+}
+
+func TestLatest(t *testing.T) {
+	server, _ := releaseServer(t, "v1.2.0", map[string][]byte{"dockbridge-linux-amd64": []byte("bin")})
+	client := &Client{HTTPClient: server.Client(), APIURL: server.URL}
+
+	release, err := client.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", release.Tag)
+	assert.Equal(t, "1.2.0", release.Version())
+	require.Len(t, release.Assets, 1)
+}
+
+func TestDownloadVerifiesChecksum(t *testing.T) {
+	binary := []byte("new dockbridge")
+	server, release := releaseServer(t, "v1.2.0", map[string][]byte{
+		"dockbridge-linux-amd64":  binary,
+		"dockbridge-darwin-arm64": []byte("tampered"),
+		checksumsAsset: append(checksumLine("dockbridge-linux-amd64", binary),
+			checksumLine("dockbridge-darwin-arm64", []byte("original"))...),
+	})
+	client := &Client{HTTPClient: server.Client(), APIURL: server.URL}
+
+	data, err := client.Download(context.Background(), release, "dockbridge-linux-amd64")
+	require.NoError(t, err)
+	assert.Equal(t, binary, data)
+
+	_, err = client.Download(context.Background(), release, "dockbridge-darwin-arm64")
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = client.Download(context.Background(), release, "dockbridge-windows-amd64")
+	assert.ErrorContains(t, err, "no checksum")
+}
+
+func TestDownloadVerifiesSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	binary := []byte("new dockbridge")
+	checksums := checksumLine("dockbridge-linux-amd64", binary)
+	server, release := releaseServer(t, "v1.2.0", map[string][]byte{
+		"dockbridge-linux-amd64": binary,
+		checksumsAsset:           checksums,
+		signatureAsset:           ed25519.Sign(privateKey, checksums),
+	})
+
+	client := &Client{HTTPClient: server.Client(), APIURL: server.URL, PublicKey: base64.StdEncoding.EncodeToString(publicKey)}
+	_, err = client.Download(context.Background(), release, "dockbridge-linux-amd64")
+	require.NoError(t, err)
+
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	client.PublicKey = base64.StdEncoding.EncodeToString(otherKey)
+	_, err = client.Download(context.Background(), release, "dockbridge-linux-amd64")
+	assert.ErrorContains(t, err, "invalid signature")
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksums := parseChecksums([]byte("ABC123  dockbridge-linux-amd64\ndef456 *checksums-binary\n\nmalformed\n"))
+	assert.Equal(t, map[string]string{
+		"dockbridge-linux-amd64": "abc123",
+		"checksums-binary":       "def456",
+	}, checksums)
+}
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dockbridge")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0750))
+
+	require.NoError(t, Install(path, []byte("new")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
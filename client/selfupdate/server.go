@@ -0,0 +1,193 @@
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+)
+
+// ServerBinaryPath is where servers have the dockbridge-server binary installed
+const ServerBinaryPath = "/usr/local/bin/dockbridge-server"
+
+// serverUnit is the systemd unit running dockbridge-server
+const serverUnit = "dockbridge-server"
+
+// serverUnitPath is where the systemd unit of dockbridge-server is installed
+const serverUnitPath = "/etc/systemd/system/" + serverUnit + ".service"
+
+// serverUnitFile is the systemd unit written by DeployServer on servers whose setup did
+// not create one. Settings come from the environment file written during setup.
+const serverUnitFile = `[Unit]
+Description=DockBridge Keep-Alive Server
+After=docker.service network.target
+Requires=docker.service
+ConditionFileIsExecutable=` + ServerBinaryPath + `
+
+[Service]
+Type=simple
+User=root
+EnvironmentFile=-/etc/dockbridge/env
+ExecStart=` + ServerBinaryPath + `
+Restart=always
+RestartSec=10
+StandardOutput=journal
+StandardError=journal
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// InspectCommand prints the server's architecture and the version of dockbridge-server.
+// The placeholder script installed when no release binary could be downloaded ignores
+// its arguments and runs forever, so scripts are not asked.
+const InspectCommand = `uname -m; if [ "$(head -c 2 ` + ServerBinaryPath + ` 2>/dev/null)" != "#!" ]; then timeout 10 ` + ServerBinaryPath + ` --version 2>/dev/null; fi; true`
+
+// serverVersionPattern matches the version line of dockbridge-server
+var serverVersionPattern = regexp.MustCompile(`DockBridge Server v(\S+)`)
+
+// ServerInfo describes the dockbridge-server installation of a server
+type ServerInfo struct {
+	// Arch is the server's architecture as in release assets, e.g. amd64
+	Arch string
+
+	// Version is the version of dockbridge-server, empty for binaries that predate
+	// version reporting and for the placeholder script
+	Version string
+}
+
+// InspectServer returns the architecture and dockbridge-server version of the server
+// client is connected to
+func InspectServer(ctx context.Context, client ssh.Client) (*ServerInfo, error) {
+	output, err := client.ExecuteCommand(ctx, InspectCommand)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect the server: %w", err)
+	}
+	return ParseServerInfo(output)
+}
+
+// ParseServerInfo parses the output of InspectCommand
+func ParseServerInfo(output []byte) (*ServerInfo, error) {
+	machine, rest, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	info := &ServerInfo{}
+	switch strings.TrimSpace(machine) {
+	case "x86_64":
+		info.Arch = "amd64"
+	case "aarch64", "arm64":
+		info.Arch = "arm64"
+	default:
+		return nil, fmt.Errorf("unsupported server architecture %q", machine)
+	}
+	if match := serverVersionPattern.FindStringSubmatch(rest); match != nil {
+		info.Version = match[1]
+	}
+	return info, nil
+}
+
+// UpdateServer installs binary as dockbridge-server on the server client is connected
+// to and restarts it
+func UpdateServer(ctx context.Context, client ssh.Client, binary []byte) error {
+	command := fmt.Sprintf("cat > %[1]s.new && chmod 0755 %[1]s.new && mv -f %[1]s.new %[1]s && systemctl restart %[2]s",
+		ServerBinaryPath, serverUnit)
+	var stderr bytes.Buffer
+	err := client.Run(ctx, command, ssh.Stdio{Stdin: bytes.NewReader(binary), Stderr: &stderr}, nil)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("failed to install dockbridge-server: %s", msg)
+		}
+		return fmt.Errorf("failed to install dockbridge-server: %w", err)
+	}
+	return nil
+}
+
+// DeployServer installs binary as dockbridge-server on a server that may not have it
+// yet, e.g. one whose setup left the download to the client. The upload is verified
+// against the binary's SHA-256 on the server before it is installed, the systemd unit
+// is created unless setup wrote one, and the service is enabled and (re)started.
+func DeployServer(ctx context.Context, client ssh.Client, binary []byte) error {
+	sum := sha256.Sum256(binary)
+	var stderr bytes.Buffer
+	err := client.Run(ctx, deployCommand(hex.EncodeToString(sum[:])), ssh.Stdio{Stdin: bytes.NewReader(binary), Stderr: &stderr}, nil)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("failed to deploy dockbridge-server: %s", msg)
+		}
+		return fmt.Errorf("failed to deploy dockbridge-server: %w", err)
+	}
+	return nil
+}
+
+// deployCommand returns the script run by DeployServer with the binary on its stdin;
+// sum is the binary's hex SHA-256
+func deployCommand(sum string) string {
+	return fmt.Sprintf(`set -e
+mkdir -p %[2]s
+cat > %[1]s.new
+if ! echo '%[3]s  %[1]s.new' | sha256sum -c --status -; then
+  rm -f %[1]s.new
+  echo "checksum mismatch of the uploaded dockbridge-server" >&2
+  exit 1
+fi
+chmod 0755 %[1]s.new
+mv -f %[1]s.new %[1]s
+if [ ! -f %[4]s ]; then
+  cat > %[4]s << 'EOF'
+%[5]sEOF
+fi
+systemctl daemon-reload
+systemctl enable %[6]s
+systemctl restart %[6]s
+`, ServerBinaryPath, filepath.Dir(ServerBinaryPath), sum, serverUnitPath, serverUnitFile, serverUnit)
+}
+
+// CheckServerBinary returns an error unless data is a linux executable for arch, e.g.
+// amd64, so that a locally built binary for the wrong platform is not deployed
+func CheckServerBinary(data []byte, arch string) error {
+	file, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not a linux executable: %w", err)
+	}
+	defer file.Close()
+
+	machines := map[string]elf.Machine{
+		"amd64": elf.EM_X86_64,
+		"arm64": elf.EM_AARCH64,
+	}
+	if machine, ok := machines[arch]; !ok || file.Machine != machine {
+		return fmt.Errorf("binary is built for %s, the server is %s", file.Machine, arch)
+	}
+	return nil
+}
+
+// ServerBinary returns the dockbridge-server binary of the release tagged tag for arch,
+// downloaded and verified once and then read from cacheDir
+func (c *Client) ServerBinary(ctx context.Context, tag, arch, cacheDir string) ([]byte, error) {
+	name := ServerBinaryName(arch)
+	cached := filepath.Join(cacheDir, tag, name)
+	if data, err := os.ReadFile(cached); err == nil {
+		return data, nil
+	}
+
+	release, err := c.ByTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.Download(ctx, release, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write only costs another download
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err == nil {
+		_ = Install(cached, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,94 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/client/ssh"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	info, err := ParseServerInfo([]byte("x86_64\nDockBridge Server v1.2.3\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &ServerInfo{Arch: "amd64", Version: "1.2.3"}, info)
+
+	// Binaries that predate --version and the placeholder script report nothing
+	info, err = ParseServerInfo([]byte("aarch64\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &ServerInfo{Arch: "arm64"}, info)
+
+	_, err = ParseServerInfo([]byte("riscv64\n"))
+	assert.Error(t, err)
+}
+
+// runClient records the command run through it and what it was sent on stdin
+type runClient struct {
+	ssh.Client
+	command string
+	stdin   []byte
+}
+
+func (c *runClient) Run(ctx context.Context, command string, stdio ssh.Stdio, pty *ssh.PTY) error {
+	c.command = command
+	var err error
+	c.stdin, err = io.ReadAll(stdio.Stdin)
+	return err
+}
+
+func TestDeployServer(t *testing.T) {
+	binary := []byte("dockbridge-server")
+	client := &runClient{}
+	require.NoError(t, DeployServer(context.Background(), client, binary))
+
+	sum := sha256.Sum256(binary)
+	assert.Equal(t, binary, client.stdin)
+	assert.Contains(t, client.command, "echo '"+hex.EncodeToString(sum[:])+"  "+ServerBinaryPath+".new' | sha256sum -c")
+	assert.Contains(t, client.command, "ConditionFileIsExecutable="+ServerBinaryPath)
+	assert.Contains(t, client.command, "systemctl restart dockbridge-server")
+}
+
+func TestCheckServerBinary(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the test binary is only an ELF executable on linux")
+	}
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	data, err := os.ReadFile(executable)
+	require.NoError(t, err)
+
+	assert.NoError(t, CheckServerBinary(data, runtime.GOARCH))
+	other := "arm64"
+	if runtime.GOARCH == "arm64" {
+		other = "amd64"
+	}
+	assert.ErrorContains(t, CheckServerBinary(data, other), "built for")
+	assert.ErrorContains(t, CheckServerBinary([]byte("#!/bin/bash\n"), "amd64"), "not a linux executable")
+}
+
+func TestServerBinaryIsCached(t *testing.T) {
+	binary := []byte("dockbridge-server")
+	server, _ := releaseServer(t, "v1.2.0", map[string][]byte{
+		"dockbridge-server-linux-amd64": binary,
+		checksumsAsset:                  checksumLine("dockbridge-server-linux-amd64", binary),
+	})
+	client := &Client{HTTPClient: server.Client(), APIURL: server.URL}
+	cacheDir := t.TempDir()
+
+	data, err := client.ServerBinary(context.Background(), "v1.2.0", "amd64", cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, binary, data)
+
+	// Later uploads do not need GitHub
+	server.Close()
+	data, err = client.ServerBinary(context.Background(), "v1.2.0", "amd64", cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, binary, data)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// launchdLabel identifies the launchd agent
+const launchdLabel = "com.dockbridge.client"
+
+// launchdManager manages a launchd agent in the user's GUI domain; output goes to a log file
+type launchdManager struct {
+	path    string
+	logPath string
+	domain  string
+	run     Runner
+}
+
+func (m *launchdManager) Install(spec Spec) error {
+	if err := os.MkdirAll(filepath.Dir(m.logPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(m.logPath), err)
+	}
+	if err := writeFile(m.path, []byte(LaunchdPlist(spec, m.logPath))); err != nil {
+		return err
+	}
+	// Unload an installed agent so the new definition is used; it fails if none is loaded
+	_, _ = m.run("launchctl", "bootout", m.domain+"/"+launchdLabel)
+	if output, err := m.run("launchctl", "bootstrap", m.domain, m.path); err != nil {
+		return commandError("launchctl bootstrap", output, err)
+	}
+	return nil
+}
+
+func (m *launchdManager) Uninstall() error {
+	if !exists(m.path) {
+		return nil
+	}
+	_, _ = m.run("launchctl", "bootout", m.domain+"/"+launchdLabel)
+	if err := os.Remove(m.path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", m.path, err)
+	}
+	return nil
+}
+
+func (m *launchdManager) Status() (Status, error) {
+	status := Status{
+		Installed: exists(m.path),
+		Path:      m.path,
+		Logs:      m.logPath,
+	}
+	if !status.Installed {
+		return status, nil
+	}
+	output, err := m.run("launchctl", "print", m.domain+"/"+launchdLabel)
+	status.Running = err == nil && strings.Contains(string(output), "state = running")
+	return status, nil
+}
+
+// LaunchdPlist returns the agent definition running spec at login, restarting it
+// unless it exits successfully and writing its output to logPath
+func LaunchdPlist(spec Spec, logPath string) string {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	plistString(&b, "Label", launchdLabel)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range append([]string{spec.Executable}, spec.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("\t</array>\n")
+
+	if len(spec.Env) > 0 {
+		names := make([]string, 0, len(spec.Env))
+		for name := range spec.Env {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", xmlEscape(name), xmlEscape(spec.Env[name]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>5</integer>\n")
+	plistString(&b, "StandardOutPath", logPath)
+	plistString(&b, "StandardErrorPath", logPath)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// plistString writes a string entry of the top-level dictionary
+func plistString(b *bytes.Buffer, key, value string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", key, xmlEscape(value))
+}
+
+// xmlEscape escapes text for an XML element
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
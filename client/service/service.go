@@ -0,0 +1,91 @@
+// Package service installs the DockBridge client daemon as a per-user service started
+// at login: a systemd user unit on Linux and a launchd agent on macOS.
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Spec describes the command the service runs
+type Spec struct {
+	// Executable is the absolute path of the dockbridge binary
+	Executable string
+	// Args are the arguments passed to it, e.g. start --config <path>
+	Args []string
+	// Env holds environment variables of the daemon, such as the PATH used to find docker
+	Env map[string]string
+}
+
+// Status is the state of the installed service
+type Status struct {
+	Installed bool
+	Running   bool
+	// Path is the unit or property list file of the service
+	Path string
+	// Logs tells where the daemon's output goes
+	Logs string
+}
+
+// Manager installs and controls the service with the platform's service manager
+type Manager interface {
+	// Install writes the service definition, enables it at login and starts it,
+	// replacing an installed service
+	Install(spec Spec) error
+	// Uninstall stops the service and removes its definition
+	Uninstall() error
+	// Status reports whether the service is installed and running
+	Status() (Status, error)
+}
+
+// Runner runs a service manager command and returns its combined output
+type Runner func(name string, args ...string) ([]byte, error)
+
+// ExecRunner runs commands with os/exec
+func ExecRunner(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput() // #nosec G204
+}
+
+// New returns the manager for goos, keeping its files under home
+func New(goos, home string, run Runner) (Manager, error) {
+	switch goos {
+	case "linux":
+		return &systemdManager{
+			path: filepath.Join(home, ".config", "systemd", "user", systemdUnit),
+			run:  run,
+		}, nil
+	case "darwin":
+		return &launchdManager{
+			path:    filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"),
+			logPath: filepath.Join(home, ".dockbridge", "logs", "dockbridge.log"),
+			domain:  fmt.Sprintf("gui/%d", os.Getuid()),
+			run:     run,
+		}, nil
+	default:
+		return nil, fmt.Errorf("services are only supported on Linux (systemd) and macOS (launchd), not %s", goos)
+	}
+}
+
+// writeFile writes a service definition, creating its directory
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil { // #nosec G306 -- service definitions are not secret
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// exists reports whether the service definition at path is present
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// commandError adds the output of a failed service manager command to its error
+func commandError(command string, output []byte, err error) error {
+	return fmt.Errorf("%s failed: %w: %s", command, err, output)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner records commands and answers them from outputs, keyed by the joined command
+type fakeRunner struct {
+	commands []string
+	outputs  map[string]string
+}
+
+func (f *fakeRunner) run(name string, args ...string) ([]byte, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	f.commands = append(f.commands, command)
+	if output, ok := f.outputs[command]; ok {
+		return []byte(output), nil
+	}
+	return nil, errors.New("exit status 1")
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := SystemdUnit(Spec{
+		Executable: "/opt/dock bridge/dockbridge",
+		Args:       []string{"start", "--config", "/home/me/100%.yaml"},
+		Env:        map[string]string{"PATH": "/usr/bin:$HOME/bin"},
+	})
+
+	assert.Contains(t, unit, `ExecStart="/opt/dock bridge/dockbridge" start --config /home/me/100%%.yaml`+"\n")
+	assert.Contains(t, unit, `Environment=PATH=/usr/bin:$$HOME/bin`+"\n")
+	assert.Contains(t, unit, "Restart=on-failure\n")
+	assert.Contains(t, unit, "WantedBy=default.target\n")
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	plist := LaunchdPlist(Spec{
+		Executable: "/usr/local/bin/dockbridge",
+		Args:       []string{"start", "--config", "/Users/me/a&b.yaml"},
+		Env:        map[string]string{"PATH": "/usr/bin"},
+	}, "/Users/me/.dockbridge/logs/dockbridge.log")
+
+	assert.Contains(t, plist, "<string>com.dockbridge.client</string>")
+	assert.Contains(t, plist, "\t\t<string>/usr/local/bin/dockbridge</string>\n\t\t<string>start</string>")
+	assert.Contains(t, plist, "<string>/Users/me/a&amp;b.yaml</string>")
+	assert.Contains(t, plist, "<key>PATH</key>\n\t\t<string>/usr/bin</string>")
+	assert.Contains(t, plist, "<key>StandardErrorPath</key>\n\t<string>/Users/me/.dockbridge/logs/dockbridge.log</string>")
+}
+
+func TestSystemdManager(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{outputs: map[string]string{
+		"systemctl --user daemon-reload":                    "",
+		"systemctl --user enable dockbridge.service":        "",
+		"systemctl --user restart dockbridge.service":       "",
+		"systemctl --user is-active dockbridge.service":     "active\n",
+		"systemctl --user disable --now dockbridge.service": "",
+	}}
+	manager, err := New("linux", home, runner.run)
+	require.NoError(t, err)
+
+	status, err := manager.Status()
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+
+	require.NoError(t, manager.Install(Spec{Executable: "/usr/bin/dockbridge", Args: []string{"start"}}))
+	unitPath := filepath.Join(home, ".config", "systemd", "user", "dockbridge.service")
+	assert.FileExists(t, unitPath)
+
+	status, err = manager.Status()
+	require.NoError(t, err)
+	assert.Equal(t, Status{Installed: true, Running: true, Path: unitPath, Logs: "journalctl --user -u dockbridge.service -f"}, status)
+
+	require.NoError(t, manager.Uninstall())
+	_, err = os.Stat(unitPath)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable dockbridge.service",
+		"systemctl --user restart dockbridge.service",
+		"systemctl --user is-active dockbridge.service",
+		"systemctl --user disable --now dockbridge.service",
+		"systemctl --user daemon-reload",
+	}, runner.commands)
+}
+
+func TestLaunchdManagerInstall(t *testing.T) {
+	home := t.TempDir()
+	runner := &fakeRunner{outputs: map[string]string{}}
+	manager, err := New("darwin", home, runner.run)
+	require.NoError(t, err)
+	domain := manager.(*launchdManager).domain
+	plistPath := filepath.Join(home, "Library", "LaunchAgents", "com.dockbridge.client.plist")
+	runner.outputs["launchctl bootstrap "+domain+" "+plistPath] = ""
+
+	// bootout fails when no agent is loaded yet, which install ignores
+	require.NoError(t, manager.Install(Spec{Executable: "/usr/local/bin/dockbridge", Args: []string{"start"}}))
+	assert.FileExists(t, plistPath)
+	assert.DirExists(t, filepath.Join(home, ".dockbridge", "logs"))
+
+	status, err := manager.Status()
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.False(t, status.Running)
+}
+
+func TestNewUnsupportedPlatform(t *testing.T) {
+	_, err := New("windows", t.TempDir(), ExecRunner)
+	assert.Error(t, err)
+}
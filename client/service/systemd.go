@@ -0,0 +1,111 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// systemdUnit is the name of the systemd user unit
+const systemdUnit = "dockbridge.service"
+
+// systemdManager manages a systemd user unit; output goes to the journal
+type systemdManager struct {
+	path string
+	run  Runner
+}
+
+func (m *systemdManager) Install(spec Spec) error {
+	if err := writeFile(m.path, []byte(SystemdUnit(spec))); err != nil {
+		return err
+	}
+	if output, err := m.run("systemctl", "--user", "daemon-reload"); err != nil {
+		return commandError("systemctl --user daemon-reload", output, err)
+	}
+	// Restart an installed service so it runs the new definition
+	if output, err := m.run("systemctl", "--user", "enable", systemdUnit); err != nil {
+		return commandError("systemctl --user enable", output, err)
+	}
+	if output, err := m.run("systemctl", "--user", "restart", systemdUnit); err != nil {
+		return commandError("systemctl --user restart", output, err)
+	}
+	return nil
+}
+
+func (m *systemdManager) Uninstall() error {
+	if !exists(m.path) {
+		return nil
+	}
+	// A unit that is not loaded cannot be disabled, which is fine
+	_, _ = m.run("systemctl", "--user", "disable", "--now", systemdUnit)
+	if err := os.Remove(m.path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", m.path, err)
+	}
+	if output, err := m.run("systemctl", "--user", "daemon-reload"); err != nil {
+		return commandError("systemctl --user daemon-reload", output, err)
+	}
+	return nil
+}
+
+func (m *systemdManager) Status() (Status, error) {
+	status := Status{
+		Installed: exists(m.path),
+		Path:      m.path,
+		Logs:      "journalctl --user -u " + systemdUnit + " -f",
+	}
+	if !status.Installed {
+		return status, nil
+	}
+	// is-active exits non-zero for inactive units; its output tells the state
+	output, _ := m.run("systemctl", "--user", "is-active", systemdUnit)
+	status.Running = strings.TrimSpace(string(output)) == "active"
+	return status, nil
+}
+
+// SystemdUnit returns the user unit running spec at login and restarting it on failure
+func SystemdUnit(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=DockBridge client daemon\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommand(append([]string{spec.Executable}, spec.Args...)))
+	names := make([]string, 0, len(spec.Env))
+	for name := range spec.Env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(name+"="+spec.Env[name]))
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n")
+	b.WriteString("StandardOutput=journal\n")
+	b.WriteString("StandardError=journal\n\n")
+
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdCommand quotes a command line for ExecStart
+func systemdCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// systemdQuote quotes a word for a unit file, escaping specifiers and variable expansion
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	if s == "" || strings.ContainsAny(s, " \t'\"\\") {
+		return `"` + s + `"`
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package ssh
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AgentSocketEnv is the environment variable that points at the local ssh-agent
+const AgentSocketEnv = "SSH_AUTH_SOCK"
+
+// authSigners collects the signers used for public key authentication: the agent's
+// keys first, then the private key file. The returned function releases the agent
+// connection.
+func authSigners(config *ClientConfig) ([]ssh.Signer, func(), error) {
+	var (
+		signers []ssh.Signer
+		errs    []string
+	)
+	cleanup := func() {}
+
+	if config.UseAgent {
+		agentSigners, closeAgent, err := agentSignersFor(config.AgentSocket, config.PrivateKeyPath)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			signers = append(signers, agentSigners...)
+			cleanup = closeAgent
+		}
+	}
+
+	if config.PrivateKeyPath != "" {
+		signer, err := loadKeyFile(config.PrivateKeyPath)
+		switch {
+		case err == nil:
+			signers = appendUniqueSigner(signers, signer)
+		case len(signers) == 0:
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(signers) == 0 {
+		cleanup()
+		if len(errs) == 0 {
+			return nil, nil, errors.New("no SSH authentication method available: set a private key path or enable the SSH agent")
+		}
+		return nil, nil, errors.Errorf("no usable SSH key: %s", strings.Join(errs, "; "))
+	}
+
+	return signers, cleanup, nil
+}
+
+// agentSignersFor connects to the agent and returns its signers, narrowed to the key
+// matching keyPath's public key when the agent holds it
+func agentSignersFor(socket, keyPath string) ([]ssh.Signer, func(), error) {
+	if socket == "" {
+		socket = os.Getenv(AgentSocketEnv)
+	}
+	if socket == "" {
+		return nil, nil, errors.Errorf("SSH agent requested but %s is not set", AgentSocketEnv)
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to connect to SSH agent")
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, nil, errors.Wrap(err, "failed to list SSH agent keys")
+	}
+
+	if preferred := preferredPublicKey(keyPath); preferred != nil {
+		signers = selectSigners(signers, preferred)
+	}
+
+	if len(signers) == 0 {
+		conn.Close()
+		return nil, nil, errors.New("SSH agent holds no keys")
+	}
+
+	return signers, func() { conn.Close() }, nil
+}
+
+// preferredPublicKey loads the public key next to the configured private key, if any
+func preferredPublicKey(keyPath string) ssh.PublicKey {
+	if keyPath == "" {
+		return nil
+	}
+
+	publicKey, err := NewKeyManager().LoadPublicKey(keyPath)
+	if err != nil {
+		return nil
+	}
+	return publicKey
+}
+
+// selectSigners returns only the signer matching preferred, or all signers if none match.
+// sshd counts every offered key as a failed attempt, so an agent with many unrelated
+// keys would otherwise get the connection closed before the right key is tried.
+func selectSigners(signers []ssh.Signer, preferred ssh.PublicKey) []ssh.Signer {
+	want := preferred.Marshal()
+	for _, signer := range signers {
+		if bytes.Equal(signer.PublicKey().Marshal(), want) {
+			return []ssh.Signer{signer}
+		}
+	}
+	return signers
+}
+
+// loadKeyFile reads and parses a private key file
+func loadKeyFile(keyPath string) (ssh.Signer, error) {
+	key, err := os.ReadFile(keyPath) // #nosec G304
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read private key")
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+	return signer, nil
+}
+
+// appendUniqueSigner appends signer unless a signer with the same public key is already present
+func appendUniqueSigner(signers []ssh.Signer, signer ssh.Signer) []ssh.Signer {
+	want := signer.PublicKey().Marshal()
+	for _, existing := range signers {
+		if bytes.Equal(existing.PublicKey().Marshal(), want) {
+			return signers
+		}
+	}
+	return append(signers, signer)
+}
@@ -0,0 +1,130 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// startTestAgent serves an in-memory keyring holding keys on a Unix socket
+func startTestAgent(t *testing.T, keys ...ed25519.PrivateKey) string {
+	t.Helper()
+
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	}
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	return socket
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+// writeKeyFiles writes key as an OpenSSH private key and .pub file; the private
+// half is skipped when publicOnly is set, as for keys kept only in the agent
+func writeKeyFiles(t *testing.T, key ed25519.PrivateKey, publicOnly bool) string {
+	t.Helper()
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath+".pub", ssh.MarshalAuthorizedKey(signer.PublicKey()), 0600))
+
+	if !publicOnly {
+		block, err := ssh.MarshalPrivateKey(key, "")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600))
+	}
+	return keyPath
+}
+
+func TestAuthSigners_AgentPrefersConfiguredKey(t *testing.T) {
+	other, configured := newEd25519Key(t), newEd25519Key(t)
+	socket := startTestAgent(t, other, configured)
+	keyPath := writeKeyFiles(t, configured, true)
+
+	signers, cleanup, err := authSigners(&ClientConfig{UseAgent: true, AgentSocket: socket, PrivateKeyPath: keyPath})
+	require.NoError(t, err)
+	defer cleanup()
+
+	want, err := ssh.NewPublicKey(configured.Public())
+	require.NoError(t, err)
+	require.Len(t, signers, 1)
+	assert.Equal(t, want.Marshal(), signers[0].PublicKey().Marshal())
+}
+
+func TestAuthSigners_AgentOffersAllKeysWithoutMatch(t *testing.T) {
+	socket := startTestAgent(t, newEd25519Key(t), newEd25519Key(t))
+
+	signers, cleanup, err := authSigners(&ClientConfig{UseAgent: true, AgentSocket: socket})
+	require.NoError(t, err)
+	defer cleanup()
+
+	assert.Len(t, signers, 2)
+}
+
+func TestAuthSigners_FallsBackToKeyFile(t *testing.T) {
+	key := newEd25519Key(t)
+	keyPath := writeKeyFiles(t, key, false)
+
+	// Agent unavailable
+	signers, cleanup, err := authSigners(&ClientConfig{
+		UseAgent:       true,
+		AgentSocket:    filepath.Join(t.TempDir(), "missing.sock"),
+		PrivateKeyPath: keyPath,
+	})
+	require.NoError(t, err)
+	defer cleanup()
+	require.Len(t, signers, 1)
+
+	// Agent holds the same key: the file signer is deduplicated
+	socket := startTestAgent(t, key)
+	signers, cleanup2, err := authSigners(&ClientConfig{UseAgent: true, AgentSocket: socket, PrivateKeyPath: keyPath})
+	require.NoError(t, err)
+	defer cleanup2()
+	assert.Len(t, signers, 1)
+}
+
+func TestAuthSigners_NoMethod(t *testing.T) {
+	t.Setenv(AgentSocketEnv, "")
+
+	_, _, err := authSigners(&ClientConfig{UseAgent: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), AgentSocketEnv)
+
+	_, _, err = authSigners(&ClientConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no SSH authentication method")
+}
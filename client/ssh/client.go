@@ -1,9 +1,13 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"net"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -21,11 +25,43 @@ type Client interface {
 	// CreateTunnel creates an SSH tunnel from local to remote
 	CreateTunnel(ctx context.Context, localAddr, remoteAddr string) (TunnelInterface, error)
 
+	// CreateUnixTunnel creates an SSH tunnel from a local Unix socket to a remote Unix socket
+	CreateUnixTunnel(ctx context.Context, localPath, remotePath string) (TunnelInterface, error)
+
+	// CreateRemoteUnixTunnel creates an SSH tunnel from a local TCP address to a remote Unix socket
+	CreateRemoteUnixTunnel(ctx context.Context, localAddr, remotePath string) (TunnelInterface, error)
+
+	// Dial opens a connection to addr as seen from the remote server, through the
+	// channel pool shared with tunnels
+	Dial(network, addr string) (net.Conn, error)
+
 	// ExecuteCommand runs a command on the remote server
 	ExecuteCommand(ctx context.Context, command string) ([]byte, error)
 
+	// ExecuteCommandWithInput runs a command on the remote server with input as its
+	// stdin, e.g. to hand over secrets without putting them on the command line
+	ExecuteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error)
+
+	// OpenStream starts a command on the remote server and returns its stdin and stdout
+	// as a stream; closing the stream closes stdin and ends the session
+	OpenStream(ctx context.Context, command string) (io.ReadWriteCloser, error)
+
+	// Run runs a command, or the login shell when command is empty, attached to stdio
+	// and waits for it to exit; with pty, it runs in a pseudo-terminal
+	Run(ctx context.Context, command string, stdio Stdio, pty *PTY) error
+
 	// IsConnected returns true if the client has an active connection
 	IsConnected() bool
+
+	// KeepAlive sends a keepalive request and waits for the server's reply
+	KeepAlive(ctx context.Context) error
+
+	// Reconnect replaces the SSH connection with a new one. Tunnels keep their local
+	// listeners and forward new connections over the new SSH connection.
+	Reconnect(ctx context.Context) error
+
+	// ChannelStats returns a snapshot of the channels tunnels opened
+	ChannelStats() ChannelPoolStats
 }
 
 // ClientConfig holds the configuration for an SSH client
@@ -35,6 +71,28 @@ type ClientConfig struct {
 	User           string
 	PrivateKeyPath string
 	Timeout        time.Duration
+
+	// UseAgent authenticates with keys from the local ssh-agent before falling back
+	// to PrivateKeyPath
+	UseAgent bool
+
+	// AgentSocket overrides the agent socket from SSH_AUTH_SOCK
+	AgentSocket string
+
+	// KnownHostsPath is the file trusted host keys are stored in (DefaultKnownHostsPath if empty)
+	KnownHostsPath string
+
+	// HostKeyChecking is the host key policy: HostKeyCheckingTOFU (default),
+	// HostKeyCheckingStrict or HostKeyCheckingOff
+	HostKeyChecking string
+
+	// Channels spreads tunnel connections over additional SSH connections and bounds the
+	// channels open on each; the zero value uses one connection without a limit
+	Channels ChannelPoolConfig
+
+	// JumpHost ([user@]host[:port]) relays the connection to servers the client cannot
+	// reach directly, e.g. servers with only a private network address
+	JumpHost string
 }
 
 // DefaultClientConfig returns a default SSH client configuration
@@ -46,52 +104,74 @@ func DefaultClientConfig() *ClientConfig {
 	}
 }
 
+// keepAliveRequest is the global request OpenSSH clients send as keepalive
+const keepAliveRequest = "keepalive@openssh.com"
+
 // clientImpl implements the Client interface
 type clientImpl struct {
 	config    *ClientConfig
 	sshClient *ssh.Client
 	connected bool
 	tunnels   []*Tunnel
+	mu        sync.Mutex
+
+	// pool opens the channels of tunnels over sshClient and additional connections
+	pool *channelPool
 }
 
 // NewClient creates a new SSH client with the given configuration
 func NewClient(config *ClientConfig) Client {
-	return &clientImpl{
+	c := &clientImpl{
 		config:  config,
 		tunnels: make([]*Tunnel, 0),
 	}
+	c.pool = newChannelPool(config.Channels, func() (sshClientConn, error) {
+		return c.dial(context.Background())
+	})
+	return c
 }
 
 // Connect establishes an SSH connection to the remote server
 func (c *clientImpl) Connect(ctx context.Context) error {
-	if c.connected && c.sshClient != nil {
+	if c.IsConnected() {
 		return nil
 	}
 
-	// Read private key
-	key, err := os.ReadFile(c.config.PrivateKeyPath) // #nosec G304
+	client, err := c.dial(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to read private key")
+		return err
 	}
 
-	// Parse private key
-	signer, err := ssh.ParsePrivateKey(key)
+	c.mu.Lock()
+	c.sshClient = client
+	c.connected = true
+	c.mu.Unlock()
+	c.pool.reset(client)
+	return nil
+}
+
+// dial opens a new SSH connection to the configured server
+func (c *clientImpl) dial(ctx context.Context) (*ssh.Client, error) {
+	// Collect agent and key file signers
+	signers, closeAgent, err := authSigners(c.config)
 	if err != nil {
-		return errors.Wrap(err, "failed to parse private key")
+		return nil, err
 	}
+	// Agent signers are only needed during the handshake
+	defer closeAgent()
 
 	// Create SSH client config
 	config := &ssh.ClientConfig{
 		User: c.config.User,
 		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
+			ssh.PublicKeys(signers...),
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106 // TODO: Implement proper host key verification, use ssh.FixedHostKey() or ssh.KnownHosts()
+		HostKeyCallback: NewKnownHosts(c.config.KnownHostsPath).HostKeyCallback(c.config.HostKeyChecking),
 		Timeout:         c.config.Timeout,
 	}
 
-	// Connect to SSH server
-	addr := fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)
+	// Connect to SSH server; IPv6 addresses are bracketed
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
 
 	// Create a context with timeout for the connection
 	connectCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
@@ -105,6 +185,11 @@ func (c *clientImpl) Connect(ctx context.Context) error {
 
 	ch := make(chan connectResult, 1)
 	go func() {
+		if c.config.JumpHost != "" {
+			client, err := dialViaJumpHost(c.config.JumpHost, addr, config)
+			ch <- connectResult{client, err}
+			return
+		}
 		client, err := ssh.Dial("tcp", addr, config)
 		ch <- connectResult{client, err}
 	}()
@@ -112,20 +197,20 @@ func (c *clientImpl) Connect(ctx context.Context) error {
 	// Wait for connection or timeout
 	select {
 	case <-connectCtx.Done():
-		return errors.New("connection timeout")
+		return nil, errors.New("connection timeout")
 	case res := <-ch:
 		if res.err != nil {
-			return errors.Wrap(res.err, "failed to connect to SSH server")
+			return nil, errors.Wrap(res.err, "failed to connect to SSH server")
 		}
-		c.sshClient = res.client
+		return res.client, nil
 	}
-
-	c.connected = true
-	return nil
 }
 
 // Close terminates the SSH connection and all tunnels
 func (c *clientImpl) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.connected || c.sshClient == nil {
 		return nil
 	}
@@ -138,6 +223,7 @@ func (c *clientImpl) Close() error {
 		}
 	}
 	c.tunnels = make([]*Tunnel, 0)
+	c.pool.close()
 
 	// Close SSH client
 	if err := c.sshClient.Close(); err != nil {
@@ -151,31 +237,92 @@ func (c *clientImpl) Close() error {
 
 // CreateTunnel creates an SSH tunnel from local to remote
 func (c *clientImpl) CreateTunnel(ctx context.Context, localAddr, remoteAddr string) (TunnelInterface, error) {
-	if !c.connected || c.sshClient == nil {
+	sshClient := c.current()
+	if sshClient == nil {
 		return nil, errors.New("not connected to SSH server")
 	}
 
-	tunnel := NewTunnel(c.sshClient, localAddr, remoteAddr)
+	tunnel := NewTunnel(sshClient, localAddr, remoteAddr)
+	tunnel.setDialer(c.pool)
 	if err := tunnel.Start(ctx); err != nil {
 		return nil, err
 	}
 
+	c.mu.Lock()
 	c.tunnels = append(c.tunnels, tunnel)
+	c.mu.Unlock()
 	return tunnel, nil
 }
 
+// CreateUnixTunnel creates an SSH tunnel from a local Unix socket to a remote Unix socket
+func (c *clientImpl) CreateUnixTunnel(ctx context.Context, localPath, remotePath string) (TunnelInterface, error) {
+	sshClient := c.current()
+	if sshClient == nil {
+		return nil, errors.New("not connected to SSH server")
+	}
+
+	tunnel := NewUnixTunnel(sshClient, localPath, remotePath)
+	tunnel.setDialer(c.pool)
+	if err := tunnel.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.tunnels = append(c.tunnels, tunnel)
+	c.mu.Unlock()
+	return tunnel, nil
+}
+
+// CreateRemoteUnixTunnel creates an SSH tunnel from a local TCP address to a remote Unix socket
+func (c *clientImpl) CreateRemoteUnixTunnel(ctx context.Context, localAddr, remotePath string) (TunnelInterface, error) {
+	sshClient := c.current()
+	if sshClient == nil {
+		return nil, errors.New("not connected to SSH server")
+	}
+
+	tunnel := NewRemoteUnixTunnel(sshClient, localAddr, remotePath)
+	tunnel.setDialer(c.pool)
+	if err := tunnel.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.tunnels = append(c.tunnels, tunnel)
+	c.mu.Unlock()
+	return tunnel, nil
+}
+
+// Dial opens a connection to addr as seen from the remote server
+func (c *clientImpl) Dial(network, addr string) (net.Conn, error) {
+	return c.pool.Dial(network, addr)
+}
+
 // ExecuteCommand runs a command on the remote server
 func (c *clientImpl) ExecuteCommand(ctx context.Context, command string) ([]byte, error) {
-	if !c.connected || c.sshClient == nil {
+	return c.execute(ctx, command, nil)
+}
+
+// ExecuteCommandWithInput runs a command on the remote server with input as its stdin
+func (c *clientImpl) ExecuteCommandWithInput(ctx context.Context, command string, input []byte) ([]byte, error) {
+	return c.execute(ctx, command, bytes.NewReader(input))
+}
+
+// execute runs a command in a new session, feeding it stdin when not nil
+func (c *clientImpl) execute(ctx context.Context, command string, stdin io.Reader) ([]byte, error) {
+	sshClient := c.current()
+	if sshClient == nil {
 		return nil, errors.New("not connected to SSH server")
 	}
 
 	// Create a session
-	session, err := c.sshClient.NewSession()
+	session, err := sshClient.NewSession()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create SSH session")
 	}
 	defer session.Close()
+	if stdin != nil {
+		session.Stdin = stdin
+	}
 
 	// Execute the command with timeout
 	type cmdResult struct {
@@ -201,7 +348,138 @@ func (c *clientImpl) ExecuteCommand(ctx context.Context, command string) ([]byte
 	}
 }
 
+// OpenStream starts a command on the remote server and returns its stdin and stdout
+func (c *clientImpl) OpenStream(ctx context.Context, command string) (io.ReadWriteCloser, error) {
+	sshClient := c.current()
+	if sshClient == nil {
+		return nil, errors.New("not connected to SSH server")
+	}
+
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create SSH session")
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "failed to open stdin")
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "failed to open stdout")
+	}
+	if err := session.Start(command); err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "failed to start command")
+	}
+
+	stream := &sessionStream{Reader: stdout, stdin: stdin, session: session, done: make(chan struct{})}
+	// End the session with the caller's context
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-stream.done:
+		}
+	}()
+	return stream, nil
+}
+
+// sessionStream is the stdin and stdout of a running SSH session
+type sessionStream struct {
+	io.Reader
+	stdin   io.WriteCloser
+	session *ssh.Session
+	once    sync.Once
+	done    chan struct{}
+}
+
+// Write writes to the command's stdin
+func (s *sessionStream) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// Close closes stdin and the session
+func (s *sessionStream) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		_ = s.stdin.Close()
+		err = s.session.Close()
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	})
+	return err
+}
+
 // IsConnected returns true if the client has an active connection
 func (c *clientImpl) IsConnected() bool {
-	return c.connected && c.sshClient != nil
+	return c.current() != nil
+}
+
+// KeepAlive sends a keepalive request and waits for the server's reply. Servers answer
+// unknown global requests with a failure, which still proves the connection is alive.
+func (c *clientImpl) KeepAlive(ctx context.Context) error {
+	sshClient := c.current()
+	if sshClient == nil {
+		return errors.New("not connected to SSH server")
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		_, _, err := sshClient.SendRequest(keepAliveRequest, true, nil)
+		ch <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		return errors.New("keepalive timeout")
+	case err := <-ch:
+		if err != nil {
+			return errors.Wrap(err, "keepalive failed")
+		}
+		return nil
+	}
+}
+
+// Reconnect dials a new SSH connection, moves the tunnels onto it and closes the old one
+func (c *clientImpl) Reconnect(ctx context.Context) error {
+	client, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.sshClient
+	c.sshClient = client
+	c.connected = true
+	c.mu.Unlock()
+
+	// Tunnels dial through the pool, which now opens channels on the new connection
+	c.pool.reset(client)
+
+	if old != nil {
+		// The old connection is usually dead already
+		_ = old.Close()
+	}
+	return nil
+}
+
+// ChannelStats returns a snapshot of the channels tunnels opened
+func (c *clientImpl) ChannelStats() ChannelPoolStats {
+	return c.pool.stats()
+}
+
+// current returns the SSH connection, or nil when not connected
+func (c *clientImpl) current() *ssh.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil
+	}
+	return c.sshClient
 }
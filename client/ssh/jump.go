@@ -0,0 +1,75 @@
+package ssh
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// ParseJumpHost splits a jump host of the form [user@]host[:port] into its user and
+// address. The user defaults to defaultUser and the port to 22; IPv6 hosts with a port
+// are written in brackets, e.g. [2001:db8::1]:2222.
+func ParseJumpHost(jumpHost, defaultUser string) (user, addr string, err error) {
+	user = defaultUser
+	hostPort := jumpHost
+	if at := strings.LastIndex(jumpHost, "@"); at >= 0 {
+		user, hostPort = jumpHost[:at], jumpHost[at+1:]
+	}
+	if user == "" || hostPort == "" {
+		return "", "", errors.Errorf("invalid jump host %q, must be [user@]host[:port]", jumpHost)
+	}
+
+	if host, port, err := net.SplitHostPort(hostPort); err == nil {
+		if host == "" {
+			return "", "", errors.Errorf("invalid jump host %q, must be [user@]host[:port]", jumpHost)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", "", errors.Errorf("invalid jump host port %q", port)
+		}
+		return user, hostPort, nil
+	}
+
+	// A bare host, possibly an IPv6 address without brackets
+	return user, net.JoinHostPort(strings.Trim(hostPort, "[]"), "22"), nil
+}
+
+// dialViaJumpHost connects to addr through an SSH connection to the jump host, for
+// servers without a public address the client can reach. The jump host is
+// authenticated with the same keys and host key policy as the server.
+func dialViaJumpHost(jumpHost, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	user, jumpAddr, err := ParseJumpHost(jumpHost, config.User)
+	if err != nil {
+		return nil, err
+	}
+
+	jumpConfig := *config
+	jumpConfig.User = user
+	jump, err := ssh.Dial("tcp", jumpAddr, &jumpConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to jump host %s", jumpAddr)
+	}
+
+	conn, err := jump.Dial("tcp", addr)
+	if err != nil {
+		_ = jump.Close()
+		return nil, errors.Wrapf(err, "failed to reach %s through jump host %s", addr, jumpAddr)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		_ = jump.Close()
+		return nil, err
+	}
+
+	client := ssh.NewClient(clientConn, chans, reqs)
+	// The jump connection lives as long as the connection through it
+	go func() {
+		_ = client.Wait()
+		_ = jump.Close()
+	}()
+	return client, nil
+}
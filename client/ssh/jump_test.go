@@ -0,0 +1,35 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJumpHost(t *testing.T) {
+	tests := []struct {
+		jumpHost string
+		user     string
+		addr     string
+	}{
+		{"bastion.example.com", "root", "bastion.example.com:22"},
+		{"admin@bastion.example.com", "admin", "bastion.example.com:22"},
+		{"admin@bastion.example.com:2222", "admin", "bastion.example.com:2222"},
+		{"2001:db8::1", "root", "[2001:db8::1]:22"},
+		{"admin@[2001:db8::1]:2222", "admin", "[2001:db8::1]:2222"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jumpHost, func(t *testing.T) {
+			user, addr, err := ParseJumpHost(tt.jumpHost, "root")
+			require.NoError(t, err)
+			assert.Equal(t, tt.user, user)
+			assert.Equal(t, tt.addr, addr)
+		})
+	}
+
+	for _, invalid := range []string{"", "admin@", "@bastion", "bastion:0", "bastion:ssh", ":22"} {
+		_, _, err := ParseJumpHost(invalid, "root")
+		assert.Error(t, err, invalid)
+	}
+}
@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -14,6 +16,15 @@ import (
 )
 
 const (
+	// KeyTypeEd25519 selects Ed25519 keys
+	KeyTypeEd25519 = "ed25519"
+
+	// KeyTypeRSA selects RSA keys
+	KeyTypeRSA = "rsa"
+
+	// DefaultKeyType is the key type generated when none is configured
+	DefaultKeyType = KeyTypeEd25519
+
 	// DefaultKeyBits is the default number of bits for RSA keys
 	DefaultKeyBits = 4096
 
@@ -26,9 +37,12 @@ const (
 
 // KeyManager handles SSH key operations
 type KeyManager interface {
-	// GenerateKeys generates a new SSH key pair
+	// GenerateKeys generates a new RSA SSH key pair
 	GenerateKeys(keyPath string, bits int) error
 
+	// GenerateKeyPair generates a new SSH key pair of the given type (KeyTypeEd25519 or KeyTypeRSA)
+	GenerateKeyPair(keyPath, keyType string, bits int) error
+
 	// LoadPublicKey loads a public key from a file
 	LoadPublicKey(keyPath string) (ssh.PublicKey, error)
 
@@ -47,10 +61,34 @@ func NewKeyManager() KeyManager {
 	return &keyManagerImpl{}
 }
 
-// GenerateKeys generates a new SSH key pair
+// GenerateKeys generates a new RSA SSH key pair
 func (km *keyManagerImpl) GenerateKeys(keyPath string, bits int) error {
-	if bits <= 0 {
-		bits = DefaultKeyBits
+	return km.GenerateKeyPair(keyPath, KeyTypeRSA, bits)
+}
+
+// GenerateKeyPair generates a new SSH key pair of the given type. bits only applies to RSA keys.
+func (km *keyManagerImpl) GenerateKeyPair(keyPath, keyType string, bits int) error {
+	if keyType == "" {
+		keyType = DefaultKeyType
+	}
+
+	var (
+		privateKey crypto.Signer
+		err        error
+	)
+	switch keyType {
+	case KeyTypeEd25519:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case KeyTypeRSA:
+		if bits <= 0 {
+			bits = DefaultKeyBits
+		}
+		privateKey, err = rsa.GenerateKey(rand.Reader, bits)
+	default:
+		return errors.Errorf("unsupported SSH key type %q", keyType)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate %s key", keyType)
 	}
 
 	// Create directory if it doesn't exist
@@ -59,16 +97,24 @@ func (km *keyManagerImpl) GenerateKeys(keyPath string, bits int) error {
 		return errors.Wrapf(err, "failed to create directory %s", keyDir)
 	}
 
-	// Generate private key
-	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
-	if err != nil {
-		return errors.Wrap(err, "failed to generate RSA key")
-	}
+	return writeKeyPair(keyPath, privateKey)
+}
 
-	// Encode private key to PEM
-	privateKeyPEM := &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+// writeKeyPair writes privateKey to keyPath and its public key to keyPath.pub.
+// RSA keys keep the PKCS#1 PEM encoding; other keys use the OpenSSH format.
+func writeKeyPair(keyPath string, privateKey crypto.Signer) error {
+	var privateKeyPEM *pem.Block
+	if rsaKey, ok := privateKey.(*rsa.PrivateKey); ok {
+		privateKeyPEM = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		}
+	} else {
+		block, err := ssh.MarshalPrivateKey(privateKey, "")
+		if err != nil {
+			return errors.Wrap(err, "failed to encode private key")
+		}
+		privateKeyPEM = block
 	}
 
 	// Write private key to file
@@ -83,7 +129,7 @@ func (km *keyManagerImpl) GenerateKeys(keyPath string, bits int) error {
 	}
 
 	// Generate public key
-	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
 	if err != nil {
 		return errors.Wrap(err, "failed to generate public key")
 	}
@@ -100,3 +100,37 @@ func TestKeyManager_KeyExists(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, km.KeyExists(keyPath))
 }
+
+func TestKeyManager_GenerateKeyPair(t *testing.T) {
+	tempDir := t.TempDir()
+	km := NewKeyManager()
+
+	tests := []struct {
+		keyType  string
+		wantType string
+	}{
+		{keyType: KeyTypeEd25519, wantType: "ssh-ed25519"},
+		{keyType: "", wantType: "ssh-ed25519"},
+		{keyType: KeyTypeRSA, wantType: "ssh-rsa"},
+	}
+
+	for _, tt := range tests {
+		keyPath := filepath.Join(tempDir, "key-"+tt.keyType)
+		require.NoError(t, km.GenerateKeyPair(keyPath, tt.keyType, 2048))
+
+		// The private key parses and matches the public key file
+		signer, err := km.LoadPrivateKey(keyPath)
+		require.NoError(t, err)
+		publicKey, err := km.LoadPublicKey(keyPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, tt.wantType, publicKey.Type())
+		assert.Equal(t, publicKey.Marshal(), signer.PublicKey().Marshal())
+
+		info, err := os.Stat(keyPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(DefaultKeyPermissions), info.Mode().Perm())
+	}
+
+	assert.Error(t, km.GenerateKeyPair(filepath.Join(tempDir, "dsa"), "dsa", 0))
+}
@@ -0,0 +1,390 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/filelock"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Host key checking policies
+const (
+	// HostKeyCheckingTOFU trusts and records the key of a host seen for the first time
+	// and rejects later connections presenting a different key
+	HostKeyCheckingTOFU = "tofu"
+
+	// HostKeyCheckingStrict only accepts hosts whose key was trusted beforehand
+	HostKeyCheckingStrict = "strict"
+
+	// HostKeyCheckingOff disables host key verification
+	HostKeyCheckingOff = "off"
+)
+
+// DefaultKnownHostsPath returns the default location of the DockBridge known_hosts file
+func DefaultKnownHostsPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".dockbridge", "known_hosts")
+	}
+	return filepath.Join(homeDir, ".dockbridge", "known_hosts")
+}
+
+// HostKeyMismatchError is returned when a host presents a key that differs from the trusted one
+type HostKeyMismatchError struct {
+	Host string
+	Want []string // fingerprints of the trusted keys
+	Got  string   // fingerprint of the presented key
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key mismatch for %s: got %s, trusted %s (possible man-in-the-middle attack; "+
+		"if the server was replaced run 'dockbridge ssh reset %s')", e.Host, e.Got, strings.Join(e.Want, ", "), e.Host)
+}
+
+// UnknownHostError is returned in strict mode for hosts without a trusted key
+type UnknownHostError struct {
+	Host string
+	Got  string
+}
+
+func (e *UnknownHostError) Error() string {
+	return fmt.Sprintf("unknown host key for %s (%s): run 'dockbridge ssh trust %s' to trust it", e.Host, e.Got, e.Host)
+}
+
+// KnownHost is a trusted host key entry
+type KnownHost struct {
+	Hosts       []string
+	Key         ssh.PublicKey
+	Fingerprint string
+
+	// Marker is "cert-authority" or "revoked" for lines carrying a marker, whose keys
+	// are not trusted as host keys
+	Marker string
+
+	// line is the entry as read, for marker lines, comments and lines that cannot be
+	// parsed, which are written back unchanged
+	line string
+}
+
+// lockTimeout bounds waiting for another process updating the known hosts file
+const lockTimeout = 10 * time.Second
+
+// KnownHosts stores trusted host keys in an OpenSSH known_hosts formatted file. Updates
+// hold a lock file next to it, so that processes recording keys at the same time do not
+// drop each other's entries.
+type KnownHosts struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewKnownHosts creates a known hosts store backed by path
+func NewKnownHosts(path string) *KnownHosts {
+	if path == "" {
+		path = DefaultKnownHostsPath()
+	}
+	return &KnownHosts{path: path}
+}
+
+// Path returns the backing file path
+func (k *KnownHosts) Path() string {
+	return k.path
+}
+
+// HostKeyCallback returns a callback that verifies host keys according to policy
+func (k *KnownHosts) HostKeyCallback(policy string) ssh.HostKeyCallback {
+	if policy == HostKeyCheckingOff {
+		return ssh.InsecureIgnoreHostKey() // #nosec G106 -- explicitly disabled by configuration
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		return k.verify(hostname, key, policy != HostKeyCheckingStrict)
+	}
+}
+
+// verify checks key against the trusted keys of hostname, recording it when trustNew is set
+func (k *KnownHosts) verify(hostname string, key ssh.PublicKey, trustNew bool) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	unlock, err := k.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	host := knownhosts.Normalize(hostname)
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+
+	got := ssh.FingerprintSHA256(key)
+	for _, entry := range entries {
+		if entry.Marker == markerRevoked && entry.matches(host) && bytes.Equal(entry.Key.Marshal(), key.Marshal()) {
+			return fmt.Errorf("host key %s of %s is revoked in %s", got, host, k.path)
+		}
+	}
+
+	var want []string
+	for _, entry := range entries {
+		if !entry.trusted() || !entry.matches(host) {
+			continue
+		}
+		if bytes.Equal(entry.Key.Marshal(), key.Marshal()) {
+			return nil
+		}
+		want = append(want, entry.Fingerprint)
+	}
+
+	if len(want) > 0 {
+		return &HostKeyMismatchError{Host: host, Want: want, Got: got}
+	}
+	if !trustNew {
+		return &UnknownHostError{Host: host, Got: got}
+	}
+
+	return k.write(append(entries, newKnownHost(host, key)))
+}
+
+// Trust records key as the trusted key of hostname, replacing any previous key
+func (k *KnownHosts) Trust(hostname string, key ssh.PublicKey) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	unlock, err := k.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	host := knownhosts.Normalize(hostname)
+	entries, err := k.load()
+	if err != nil {
+		return err
+	}
+
+	return k.write(append(removeHost(entries, host), newKnownHost(host, key)))
+}
+
+// Remove forgets the keys of hostname and reports whether any were stored. It is
+// called when a server is provisioned or destroyed, since cloud providers reuse IPs.
+func (k *KnownHosts) Remove(hostname string) (bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	unlock, err := k.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	host := knownhosts.Normalize(hostname)
+	entries, err := k.load()
+	if err != nil {
+		return false, err
+	}
+
+	remaining := removeHost(entries, host)
+	if len(remaining) == len(entries) {
+		return false, nil
+	}
+	return true, k.write(remaining)
+}
+
+// Fingerprint returns the SHA256 fingerprint of the trusted key of hostname, or "" if
+// none is stored
+func (k *KnownHosts) Fingerprint(hostname string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	host := knownhosts.Normalize(hostname)
+	entries, err := k.load()
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.trusted() && entry.matches(host) {
+			return entry.Fingerprint, nil
+		}
+	}
+	return "", nil
+}
+
+// Reset forgets all trusted host keys
+func (k *KnownHosts) Reset() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	unlock, err := k.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(k.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove known hosts file")
+	}
+	return nil
+}
+
+// List returns all trusted host keys
+func (k *KnownHosts) List() ([]KnownHost, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entries, err := k.load()
+	if err != nil {
+		return nil, err
+	}
+	var trusted []KnownHost
+	for _, entry := range entries {
+		if entry.trusted() {
+			trusted = append(trusted, entry)
+		}
+	}
+	return trusted, nil
+}
+
+// lock takes the lock file of the known hosts file for a read-modify-write cycle; the
+// returned function releases it
+func (k *KnownHosts) lock() (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+
+	lock, err := filelock.Acquire(ctx, k.path+".lock")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock known hosts file")
+	}
+	return func() { _ = lock.Release() }, nil
+}
+
+// load parses the known hosts file; a missing file is empty. Lines that cannot be
+// parsed are skipped but kept, so that rewriting the file does not lose them.
+func (k *KnownHosts) load() ([]KnownHost, error) {
+	data, err := os.ReadFile(k.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read known hosts file")
+	}
+
+	var entries []KnownHost
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		marker, hosts, key, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err != nil {
+			// io.EOF for comments; other errors for lines this parser does not understand
+			entries = append(entries, KnownHost{line: line})
+			continue
+		}
+		entry := KnownHost{Hosts: hosts, Key: key, Fingerprint: ssh.FingerprintSHA256(key), Marker: marker}
+		if marker != "" {
+			entry.line = line
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// write replaces the known hosts file atomically
+func (k *KnownHosts) write(entries []KnownHost) error {
+	dir := filepath.Dir(k.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "failed to create known hosts directory")
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	for _, entry := range entries {
+		if entry.line != "" {
+			fmt.Fprintln(w, entry.line)
+			continue
+		}
+		fmt.Fprintln(w, knownhosts.Line(entry.Hosts, entry.Key))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// A file of its own, so that clients writing concurrently do not clobber each other
+	tmp, err := os.CreateTemp(dir, filepath.Base(k.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create known hosts file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write known hosts file")
+	}
+	if err := os.Rename(tmp.Name(), k.path); err != nil {
+		return errors.Wrap(err, "failed to replace known hosts file")
+	}
+	return nil
+}
+
+func newKnownHost(host string, key ssh.PublicKey) KnownHost {
+	return KnownHost{Hosts: []string{host}, Key: key, Fingerprint: ssh.FingerprintSHA256(key)}
+}
+
+// markerRevoked marks a key that must be rejected for the hosts of its line
+const markerRevoked = "revoked"
+
+// trusted reports whether the entry is a host key, rather than a marker line, comment
+// or unparsed line
+func (h KnownHost) trusted() bool {
+	return h.Key != nil && h.Marker == ""
+}
+
+// matches reports whether the entry applies to the normalized host
+func (h KnownHost) matches(host string) bool {
+	for _, candidate := range h.Hosts {
+		if candidate == host {
+			return true
+		}
+	}
+	return false
+}
+
+// removeHost drops host from all host key entries, removing entries left without hosts.
+// Marker lines, such as revocations, are kept.
+func removeHost(entries []KnownHost, host string) []KnownHost {
+	remaining := make([]KnownHost, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.trusted() {
+			remaining = append(remaining, entry)
+			continue
+		}
+		var hosts []string
+		for _, candidate := range entry.Hosts {
+			if candidate != host {
+				hosts = append(hosts, candidate)
+			}
+		}
+		if len(hosts) == 0 {
+			continue
+		}
+		entry.Hosts = hosts
+		remaining = append(remaining, entry)
+	}
+	return remaining
+}
@@ -0,0 +1,185 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	return key
+}
+
+var testRemote = &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 22}
+
+func TestKnownHosts_TOFU(t *testing.T) {
+	knownHosts := NewKnownHosts(filepath.Join(t.TempDir(), "known_hosts"))
+	callback := knownHosts.HostKeyCallback(HostKeyCheckingTOFU)
+	key, otherKey := newHostKey(t), newHostKey(t)
+
+	// First use records the key
+	require.NoError(t, callback("203.0.113.7:22", testRemote, key))
+	entries, err := knownHosts.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"203.0.113.7"}, entries[0].Hosts)
+
+	// Same key is accepted, a different key is rejected
+	require.NoError(t, callback("203.0.113.7:22", testRemote, key))
+	err = callback("203.0.113.7:22", testRemote, otherKey)
+	var mismatch *HostKeyMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "203.0.113.7", mismatch.Host)
+	assert.Contains(t, err.Error(), "dockbridge ssh reset")
+
+	// Non-default ports are distinct hosts
+	require.NoError(t, callback("203.0.113.7:2222", testRemote, otherKey))
+
+	// After forgetting the host, a new key is trusted again
+	removed, err := knownHosts.Remove("203.0.113.7:22")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	require.NoError(t, callback("203.0.113.7:22", testRemote, otherKey))
+}
+
+func TestKnownHosts_Strict(t *testing.T) {
+	knownHosts := NewKnownHosts(filepath.Join(t.TempDir(), "known_hosts"))
+	callback := knownHosts.HostKeyCallback(HostKeyCheckingStrict)
+	key := newHostKey(t)
+
+	var unknown *UnknownHostError
+	require.ErrorAs(t, callback("203.0.113.7:22", testRemote, key), &unknown)
+
+	require.NoError(t, knownHosts.Trust("203.0.113.7:22", key))
+	assert.NoError(t, callback("203.0.113.7:22", testRemote, key))
+}
+
+func TestKnownHosts_TrustReplacesAndReset(t *testing.T) {
+	knownHosts := NewKnownHosts(filepath.Join(t.TempDir(), "known_hosts"))
+	callback := knownHosts.HostKeyCallback(HostKeyCheckingTOFU)
+	key, newKey := newHostKey(t), newHostKey(t)
+
+	require.NoError(t, callback("203.0.113.7:22", testRemote, key))
+	require.NoError(t, knownHosts.Trust("203.0.113.7", newKey))
+	assert.NoError(t, callback("203.0.113.7:22", testRemote, newKey))
+	assert.Error(t, callback("203.0.113.7:22", testRemote, key))
+
+	fingerprint, err := knownHosts.Fingerprint("203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, ssh.FingerprintSHA256(newKey), fingerprint)
+
+	require.NoError(t, knownHosts.Reset())
+	entries, err := knownHosts.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	fingerprint, err = knownHosts.Fingerprint("203.0.113.7")
+	require.NoError(t, err)
+	assert.Empty(t, fingerprint)
+
+	removed, err := knownHosts.Remove("203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestKnownHosts_Off(t *testing.T) {
+	knownHosts := NewKnownHosts(filepath.Join(t.TempDir(), "known_hosts"))
+	callback := knownHosts.HostKeyCallback(HostKeyCheckingOff)
+
+	assert.NoError(t, callback("203.0.113.7:22", testRemote, newHostKey(t)))
+	entries, err := knownHosts.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestKnownHosts_KeepsLinesItDoesNotManage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	key, caKey, revokedKey, newKey := newHostKey(t), newHostKey(t), newHostKey(t), newHostKey(t)
+	unmanaged := []string{
+		"# servers of the team",
+		"@cert-authority *.example.com " + string(bytes.TrimSpace(ssh.MarshalAuthorizedKey(caKey))),
+		"@revoked 203.0.113.9 " + string(bytes.TrimSpace(ssh.MarshalAuthorizedKey(revokedKey))),
+		"203.0.113.8 ssh-ed25519 not-base64",
+	}
+	content := strings.Join(unmanaged, "\n") + "\n" + knownhosts.Line([]string{"203.0.113.7"}, key) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	knownHosts := NewKnownHosts(path)
+	callback := knownHosts.HostKeyCallback(HostKeyCheckingTOFU)
+
+	// Entries after a line that cannot be parsed are still trusted
+	require.NoError(t, callback("203.0.113.7:22", testRemote, key))
+	var mismatch *HostKeyMismatchError
+	require.ErrorAs(t, callback("203.0.113.7:22", testRemote, newKey), &mismatch)
+
+	// Revoked keys are rejected rather than trusted on first use
+	assert.ErrorContains(t, callback("203.0.113.9:22", testRemote, revokedKey), "revoked")
+
+	entries, err := knownHosts.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only host keys are listed")
+
+	// Rewriting the file keeps the lines DockBridge does not manage
+	require.NoError(t, callback("203.0.113.10:22", testRemote, newKey))
+	removed, err := knownHosts.Remove("203.0.113.9:22")
+	require.NoError(t, err)
+	assert.False(t, removed, "revocations are not forgotten")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, line := range unmanaged {
+		assert.Contains(t, string(data), line+"\n")
+	}
+	assert.Contains(t, string(data), knownhosts.Line([]string{"203.0.113.10"}, newKey))
+
+	// No temporary files are left next to the file; the lock file stays
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.ElementsMatch(t, []string{"known_hosts", "known_hosts.lock"}, names)
+}
+
+func TestKnownHosts_ConcurrentStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+
+	// Several processes may record keys at once; none of their entries may be lost
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		key := newHostKey(t)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- NewKnownHosts(path).Trust(fmt.Sprintf("203.0.113.%d:22", i+1), key)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	entries, err := NewKnownHosts(path).List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 8)
+}
@@ -0,0 +1,284 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// growRetryInterval is how long the pool uses its existing connections after failing
+// to open an additional one
+const growRetryInterval = 30 * time.Second
+
+// ErrChannelPoolExhausted is returned when no channel became free within the acquire timeout
+var ErrChannelPoolExhausted = errors.New("all SSH channels are busy")
+
+// ChannelPoolConfig configures how forwarded connections are spread over SSH connections
+type ChannelPoolConfig struct {
+	// Connections is the maximum number of SSH connections channels are spread over;
+	// additional connections are opened once the existing ones are full (default 1)
+	Connections int
+
+	// MaxChannels is the maximum number of concurrently open channels per connection;
+	// 0 is unlimited
+	MaxChannels int
+
+	// AcquireTimeout bounds how long a dial waits for a free channel; 0 waits forever
+	AcquireTimeout time.Duration
+
+	// OnBackpressure is called when a dial had to wait for a free channel (optional)
+	OnBackpressure func(stats ChannelPoolStats, waited time.Duration)
+}
+
+// ChannelPoolStats is a snapshot of a channel pool
+type ChannelPoolStats struct {
+	// Connections is the number of SSH connections in the pool
+	Connections int
+	// OpenChannels is the number of channels currently open over all connections
+	OpenChannels int
+	// PerConnection is the number of open channels of each connection
+	PerConnection []int
+	// MaxChannels is the per-connection limit; 0 is unlimited
+	MaxChannels int
+	// Opened is the number of channels opened since the pool was created
+	Opened uint64
+	// Waited is the number of dials that had to wait for a free channel
+	Waited uint64
+	// Rejected is the number of dials that gave up waiting
+	Rejected uint64
+}
+
+// pooledConn is one SSH connection of a channel pool
+type pooledConn struct {
+	dialer sshDialer
+	// closer closes connections opened by the pool; nil for the client's own connection
+	closer io.Closer
+	open   int
+}
+
+// channelPool opens channels over a bounded set of SSH connections, waiting for a free
+// channel when every connection is at its limit
+type channelPool struct {
+	config ChannelPoolConfig
+	// connect opens an additional SSH connection
+	connect func() (sshClientConn, error)
+
+	mu      sync.Mutex
+	conns   []*pooledConn
+	dialing int
+	// growFailed is when opening an additional connection last failed
+	growFailed time.Time
+	// released is closed and replaced whenever a channel or connection becomes available
+	released chan struct{}
+
+	opened   atomic.Uint64
+	waited   atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// sshClientConn is an SSH connection that can open channels and be closed
+type sshClientConn interface {
+	sshDialer
+	io.Closer
+}
+
+// newChannelPool creates an empty pool; connect opens additional SSH connections
+func newChannelPool(config ChannelPoolConfig, connect func() (sshClientConn, error)) *channelPool {
+	if config.Connections < 1 {
+		config.Connections = 1
+	}
+	return &channelPool{
+		config:   config,
+		connect:  connect,
+		released: make(chan struct{}),
+	}
+}
+
+// Dial opens a channel to addr on the least busy connection, waiting for a free channel
+// when all connections are at their limit
+func (p *channelPool) Dial(network, addr string) (net.Conn, error) {
+	conn, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := conn.dialer.Dial(network, addr)
+	if err != nil {
+		p.release(conn)
+		return nil, err
+	}
+	p.opened.Add(1)
+	return &pooledChannel{Conn: channel, release: func() { p.release(conn) }}, nil
+}
+
+// acquire reserves a channel on a connection
+func (p *channelPool) acquire() (*pooledConn, error) {
+	var deadline <-chan time.Time
+	var start time.Time
+	waited := false
+
+	for {
+		p.mu.Lock()
+		if len(p.conns) == 0 {
+			p.mu.Unlock()
+			return nil, errors.New("not connected to SSH server")
+		}
+		if conn := p.leastBusy(); conn != nil {
+			conn.open++
+			p.mu.Unlock()
+			if waited {
+				p.reportBackpressure(time.Since(start))
+			}
+			return conn, nil
+		}
+
+		// Every connection is full: open another one if allowed, otherwise wait
+		grow := len(p.conns)+p.dialing < p.config.Connections &&
+			time.Since(p.growFailed) > growRetryInterval
+		if grow {
+			p.dialing++
+		}
+		released := p.released
+		p.mu.Unlock()
+
+		if grow {
+			p.grow()
+			continue
+		}
+
+		if !waited {
+			waited = true
+			start = time.Now()
+			p.waited.Add(1)
+			if p.config.AcquireTimeout > 0 {
+				timer := time.NewTimer(p.config.AcquireTimeout)
+				defer timer.Stop()
+				deadline = timer.C
+			}
+		}
+
+		select {
+		case <-released:
+		case <-deadline:
+			p.rejected.Add(1)
+			return nil, ErrChannelPoolExhausted
+		}
+	}
+}
+
+// leastBusy returns the connection with the fewest open channels that is below the
+// limit, or nil when all are full. The caller holds mu.
+func (p *channelPool) leastBusy() *pooledConn {
+	var best *pooledConn
+	for _, conn := range p.conns {
+		if p.config.MaxChannels > 0 && conn.open >= p.config.MaxChannels {
+			continue
+		}
+		if best == nil || conn.open < best.open {
+			best = conn
+		}
+	}
+	return best
+}
+
+// grow opens an additional SSH connection; failures leave the pool as it is
+func (p *channelPool) grow() {
+	client, err := p.connect()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dialing--
+	switch {
+	case err != nil:
+		p.growFailed = time.Now()
+	case len(p.conns) == 0:
+		// The pool was closed meanwhile
+		_ = client.Close()
+	default:
+		p.conns = append(p.conns, &pooledConn{dialer: client, closer: client})
+	}
+	p.broadcast()
+}
+
+// release frees a channel of conn
+func (p *channelPool) release(conn *pooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn.open--
+	p.broadcast()
+}
+
+// broadcast wakes up waiting dials. The caller holds mu.
+func (p *channelPool) broadcast() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// reset replaces all connections with primary, e.g. after reconnecting. Connections the
+// pool opened are closed; channels open on them fail and are released by their users.
+func (p *channelPool) reset(primary sshDialer) {
+	p.mu.Lock()
+	old := p.conns
+	p.conns = nil
+	if primary != nil {
+		p.conns = []*pooledConn{{dialer: primary}}
+	}
+	p.broadcast()
+	p.mu.Unlock()
+
+	for _, conn := range old {
+		if conn.closer != nil {
+			_ = conn.closer.Close()
+		}
+	}
+}
+
+// close closes the connections opened by the pool
+func (p *channelPool) close() {
+	p.reset(nil)
+}
+
+// stats returns a snapshot of the pool
+func (p *channelPool) stats() ChannelPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := ChannelPoolStats{
+		Connections:   len(p.conns),
+		PerConnection: make([]int, 0, len(p.conns)),
+		MaxChannels:   p.config.MaxChannels,
+		Opened:        p.opened.Load(),
+		Waited:        p.waited.Load(),
+		Rejected:      p.rejected.Load(),
+	}
+	for _, conn := range p.conns {
+		stats.OpenChannels += conn.open
+		stats.PerConnection = append(stats.PerConnection, conn.open)
+	}
+	return stats
+}
+
+// reportBackpressure tells the configured callback that a dial waited for a channel
+func (p *channelPool) reportBackpressure(waited time.Duration) {
+	if p.config.OnBackpressure != nil {
+		p.config.OnBackpressure(p.stats(), waited)
+	}
+}
+
+// pooledChannel is a channel that frees its pool slot when closed
+type pooledChannel struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close closes the channel and frees its slot
+func (c *pooledChannel) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
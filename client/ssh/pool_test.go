@@ -0,0 +1,142 @@
+package ssh
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeDialer opens in-memory channels and counts them
+type pipeDialer struct {
+	mu     sync.Mutex
+	dials  int
+	closed bool
+}
+
+func (d *pipeDialer) Dial(network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, errors.New("connection closed")
+	}
+	d.dials++
+	local, remote := net.Pipe()
+	go remote.Close()
+	return local, nil
+}
+
+func (d *pipeDialer) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func TestChannelPoolSpreadsOverConnections(t *testing.T) {
+	var extra []*pipeDialer
+	pool := newChannelPool(ChannelPoolConfig{Connections: 2, MaxChannels: 2}, func() (sshClientConn, error) {
+		d := &pipeDialer{}
+		extra = append(extra, d)
+		return d, nil
+	})
+	primary := &pipeDialer{}
+	pool.reset(primary)
+
+	var channels []net.Conn
+	for range 4 {
+		conn, err := pool.Dial("tcp", "remote:2376")
+		require.NoError(t, err)
+		channels = append(channels, conn)
+	}
+
+	stats := pool.stats()
+	assert.Equal(t, 2, stats.Connections)
+	assert.Equal(t, 4, stats.OpenChannels)
+	assert.Equal(t, []int{2, 2}, stats.PerConnection)
+	assert.Equal(t, uint64(4), stats.Opened)
+	require.Len(t, extra, 1)
+	assert.Equal(t, 2, primary.dials)
+
+	for _, conn := range channels {
+		require.NoError(t, conn.Close())
+	}
+	assert.Zero(t, pool.stats().OpenChannels)
+
+	pool.close()
+	assert.True(t, extra[0].closed, "connections opened by the pool are closed")
+	assert.False(t, primary.closed, "the client's own connection is left to the client")
+}
+
+func TestChannelPoolBackpressure(t *testing.T) {
+	var waited time.Duration
+	pool := newChannelPool(ChannelPoolConfig{
+		Connections: 1,
+		MaxChannels: 1,
+		OnBackpressure: func(stats ChannelPoolStats, d time.Duration) {
+			waited = d
+		},
+	}, nil)
+	pool.reset(&pipeDialer{})
+
+	first, err := pool.Dial("tcp", "remote:2376")
+	require.NoError(t, err)
+
+	second := make(chan net.Conn, 1)
+	go func() {
+		conn, err := pool.Dial("tcp", "remote:2376")
+		assert.NoError(t, err)
+		second <- conn
+	}()
+
+	require.Eventually(t, func() bool { return pool.stats().Waited == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, first.Close())
+
+	conn := <-second
+	require.NotNil(t, conn)
+	defer conn.Close()
+	assert.GreaterOrEqual(t, waited, 10*time.Millisecond)
+	assert.Equal(t, 1, pool.stats().OpenChannels)
+}
+
+func TestChannelPoolAcquireTimeout(t *testing.T) {
+	pool := newChannelPool(ChannelPoolConfig{MaxChannels: 1, AcquireTimeout: 10 * time.Millisecond}, nil)
+	pool.reset(&pipeDialer{})
+
+	conn, err := pool.Dial("tcp", "remote:2376")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = pool.Dial("tcp", "remote:2376")
+	assert.ErrorIs(t, err, ErrChannelPoolExhausted)
+	assert.Equal(t, uint64(1), pool.stats().Rejected)
+}
+
+func TestChannelPoolKeepsExistingConnectionWhenGrowingFails(t *testing.T) {
+	attempts := 0
+	pool := newChannelPool(ChannelPoolConfig{Connections: 2, MaxChannels: 1, AcquireTimeout: 10 * time.Millisecond}, func() (sshClientConn, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	})
+	pool.reset(&pipeDialer{})
+
+	conn, err := pool.Dial("tcp", "remote:2376")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = pool.Dial("tcp", "remote:2376")
+	assert.ErrorIs(t, err, ErrChannelPoolExhausted)
+	assert.Equal(t, 1, attempts, "a failed connection is not retried right away")
+	assert.Equal(t, 1, pool.stats().Connections)
+}
+
+func TestChannelPoolNotConnected(t *testing.T) {
+	pool := newChannelPool(ChannelPoolConfig{}, nil)
+	_, err := pool.Dial("tcp", "remote:2376")
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package ssh
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ReconnectEvent describes a change of the connection watched by a ReconnectManager
+type ReconnectEvent string
+
+const (
+	// ReconnectLost is reported when keepalive probes found the connection dead
+	ReconnectLost ReconnectEvent = "lost"
+	// ReconnectRestored is reported once the connection and its tunnels were re-established
+	ReconnectRestored ReconnectEvent = "restored"
+	// ReconnectFailed is reported when every reconnect attempt failed; the manager stops
+	ReconnectFailed ReconnectEvent = "failed"
+)
+
+// ReconnectFunc receives connection events; err is the probe or reconnect error, nil for
+// ReconnectRestored
+type ReconnectFunc func(event ReconnectEvent, err error)
+
+// ReconnectConfig configures how a ReconnectManager detects and repairs dead connections
+type ReconnectConfig struct {
+	// Interval between keepalive probes
+	Interval time.Duration
+	// Timeout of a single keepalive probe
+	Timeout time.Duration
+	// MaxMissed is the number of consecutive failed probes after which the connection is
+	// considered dead
+	MaxMissed int
+	// MaxAttempts bounds the reconnect attempts; 0 retries until stopped
+	MaxAttempts int
+	// InitialBackoff and MaxBackoff bound the exponential delay between attempts
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultReconnectConfig returns the default reconnect configuration
+func DefaultReconnectConfig() *ReconnectConfig {
+	return &ReconnectConfig{
+		Interval:       30 * time.Second,
+		Timeout:        10 * time.Second,
+		MaxMissed:      2,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// ReconnectManager watches an SSH connection with keepalive probes and re-establishes
+// it and its tunnels when it dies, e.g. after a Wi-Fi change or VPN flap
+type ReconnectManager interface {
+	// Start begins probing the connection until ctx is cancelled or Stop is called
+	Start(ctx context.Context)
+
+	// Stop stops probing and waits for a reconnect in progress to give up
+	Stop()
+}
+
+// reconnectManagerImpl implements ReconnectManager
+type reconnectManagerImpl struct {
+	client  Client
+	config  *ReconnectConfig
+	onEvent ReconnectFunc
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReconnectManager creates a reconnect manager for client; onEvent may be nil
+func NewReconnectManager(client Client, config *ReconnectConfig, onEvent ReconnectFunc) ReconnectManager {
+	if config == nil {
+		config = DefaultReconnectConfig()
+	}
+	if onEvent == nil {
+		onEvent = func(ReconnectEvent, error) {}
+	}
+	return &reconnectManagerImpl{
+		client:  client,
+		config:  config,
+		onEvent: onEvent,
+	}
+}
+
+// Start begins probing the connection in the background
+func (r *reconnectManagerImpl) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		r.run(ctx)
+	}(r.done)
+}
+
+// Stop stops probing and waits for the background goroutine to exit
+func (r *reconnectManagerImpl) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run probes the connection every interval and reconnects after MaxMissed failed probes
+func (r *reconnectManagerImpl) run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	missed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+		err := r.client.KeepAlive(probeCtx)
+		cancel()
+		if err == nil {
+			missed = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		missed++
+		if missed < r.config.MaxMissed {
+			continue
+		}
+
+		r.onEvent(ReconnectLost, err)
+		if !r.reconnect(ctx) {
+			return
+		}
+		missed = 0
+	}
+}
+
+// reconnect re-establishes the connection with exponential backoff. It returns false
+// when it gave up or was stopped.
+func (r *reconnectManagerImpl) reconnect(ctx context.Context) bool {
+	backoff := r.config.InitialBackoff
+	var err error
+	for attempt := 1; r.config.MaxAttempts == 0 || attempt <= r.config.MaxAttempts; attempt++ {
+		if err = r.client.Reconnect(ctx); err == nil {
+			r.onEvent(ReconnectRestored, nil)
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, r.config.MaxBackoff)
+	}
+
+	r.onEvent(ReconnectFailed, err)
+	return false
+}
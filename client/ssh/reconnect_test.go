@@ -0,0 +1,173 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reconnectClient is a Client whose keepalive and reconnect results are scripted
+type reconnectClient struct {
+	Client
+
+	mu             sync.Mutex
+	alive          bool
+	reconnectErrs  []error
+	reconnectCalls int
+}
+
+func (c *reconnectClient) KeepAlive(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.alive {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (c *reconnectClient) Reconnect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconnectCalls++
+	if len(c.reconnectErrs) > 0 {
+		err := c.reconnectErrs[0]
+		c.reconnectErrs = c.reconnectErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	c.alive = true
+	return nil
+}
+
+func (c *reconnectClient) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reconnectCalls
+}
+
+// recordEvents collects reconnect events
+func recordEvents() (ReconnectFunc, func() []ReconnectEvent) {
+	var mu sync.Mutex
+	var events []ReconnectEvent
+	return func(event ReconnectEvent, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}, func() []ReconnectEvent {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]ReconnectEvent(nil), events...)
+		}
+}
+
+func testReconnectConfig() *ReconnectConfig {
+	return &ReconnectConfig{
+		Interval:       5 * time.Millisecond,
+		Timeout:        5 * time.Millisecond,
+		MaxMissed:      2,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+}
+
+func TestReconnectManagerRestoresDeadConnection(t *testing.T) {
+	client := &reconnectClient{reconnectErrs: []error{errors.New("network unreachable"), nil}}
+	onEvent, events := recordEvents()
+
+	manager := NewReconnectManager(client, testReconnectConfig(), onEvent)
+	manager.Start(context.Background())
+	defer manager.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(events()) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []ReconnectEvent{ReconnectLost, ReconnectRestored}, events())
+	assert.Equal(t, 2, client.calls())
+}
+
+func TestReconnectManagerGivesUp(t *testing.T) {
+	failure := errors.New("host unreachable")
+	client := &reconnectClient{reconnectErrs: []error{failure, failure, failure}}
+	onEvent, events := recordEvents()
+
+	manager := NewReconnectManager(client, testReconnectConfig(), onEvent)
+	manager.Start(context.Background())
+	defer manager.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(events()) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []ReconnectEvent{ReconnectLost, ReconnectFailed}, events())
+	assert.Equal(t, 3, client.calls())
+}
+
+func TestReconnectManagerLeavesHealthyConnection(t *testing.T) {
+	client := &reconnectClient{alive: true}
+	onEvent, events := recordEvents()
+
+	manager := NewReconnectManager(client, testReconnectConfig(), onEvent)
+	manager.Start(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	manager.Stop()
+
+	assert.Empty(t, events())
+	assert.Zero(t, client.calls())
+}
+
+// TestTunnelSetDialer verifies a tunnel keeps its local address when its SSH connection
+// is replaced and forwards new connections over the new one
+func TestTunnelSetDialer(t *testing.T) {
+	// The first connection is dead: nothing listens on its address
+	live := startEchoServer(t)
+
+	tunnel := NewTunnelWithDialer(&mockSSHClient{echoServerAddr: "127.0.0.1:1"}, "127.0.0.1:0", "remote:2376")
+	require.NoError(t, tunnel.Start(context.Background()))
+	defer tunnel.Close()
+	localAddr := tunnel.LocalAddr()
+
+	tunnel.setDialer(&mockSSHClient{echoServerAddr: live})
+	assert.Equal(t, localAddr, tunnel.LocalAddr())
+
+	conn, err := net.Dial("tcp", localAddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buffer := make([]byte, 4)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = io.ReadFull(conn, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buffer))
+}
+
+// startEchoServer starts a TCP echo server and returns its address
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				io.Copy(c, c)
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
@@ -0,0 +1,97 @@
+package ssh
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Stdio holds the standard streams a remote command is attached to; nil streams are
+// left unconnected
+type Stdio struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// WindowSize is the size of a terminal in characters
+type WindowSize struct {
+	Width  int
+	Height int
+}
+
+// PTY describes the pseudo-terminal requested for an interactive remote command
+type PTY struct {
+	// Term is the terminal type, e.g. the local TERM
+	Term string
+
+	// Size is the initial size of the terminal
+	Size WindowSize
+
+	// Resize delivers the new size whenever the local terminal is resized
+	Resize <-chan WindowSize
+}
+
+// Run runs command on the remote server attached to stdio, or the login shell when
+// command is empty, and waits for it to exit. With pty, the command runs in a
+// pseudo-terminal. A command exiting with a non-zero status returns *ssh.ExitError.
+func (c *clientImpl) Run(ctx context.Context, command string, stdio Stdio, pty *PTY) error {
+	sshClient := c.current()
+	if sshClient == nil {
+		return errors.New("not connected to SSH server")
+	}
+
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "failed to create SSH session")
+	}
+	defer session.Close()
+	session.Stdin = stdio.Stdin
+	session.Stdout = stdio.Stdout
+	session.Stderr = stdio.Stderr
+
+	var resize <-chan WindowSize
+	if pty != nil {
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		if err := session.RequestPty(pty.Term, pty.Size.Height, pty.Size.Width, modes); err != nil {
+			return errors.Wrap(err, "failed to request pseudo-terminal")
+		}
+		resize = pty.Resize
+	}
+
+	if command == "" {
+		err = session.Shell()
+	} else {
+		err = session.Start(command)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to start command")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			// Exit errors are returned as is so that callers can read the status
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case size, ok := <-resize:
+			if !ok {
+				resize = nil
+				continue
+			}
+			_ = session.WindowChange(size.Height, size.Width)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -27,31 +28,54 @@ type TunnelInterface interface {
 
 // Tunnel represents an SSH tunnel from a local address to a remote address
 type Tunnel struct {
-	sshClient  *ssh.Client
-	localAddr  string
-	remoteAddr string
-	listener   net.Listener
-	active     bool
-	mu         sync.Mutex
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	dialer     sshDialer // Interface for dialing, used for testing
+	sshClient     *ssh.Client
+	localAddr     string
+	remoteAddr    string
+	localNetwork  string // "tcp" or "unix"
+	remoteNetwork string // "tcp" or "unix"
+	listener      net.Listener
+	active        bool
+	mu            sync.Mutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	dialer        sshDialer // Interface for dialing, used for testing
+
+	// dialerMu guards dialer separately from mu, which Close holds while waiting for
+	// connection handlers
+	dialerMu sync.RWMutex
 }
 
 // NewTunnel creates a new SSH tunnel
 func NewTunnel(sshClient *ssh.Client, localAddr, remoteAddr string) *Tunnel {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Tunnel{
-		sshClient:  sshClient,
-		localAddr:  localAddr,
-		remoteAddr: remoteAddr,
-		ctx:        ctx,
-		cancel:     cancel,
-		dialer:     sshClient, // SSH client implements the Dial method
+		sshClient:     sshClient,
+		localAddr:     localAddr,
+		remoteAddr:    remoteAddr,
+		localNetwork:  "tcp",
+		remoteNetwork: "tcp",
+		ctx:           ctx,
+		cancel:        cancel,
+		dialer:        sshClient, // SSH client implements the Dial method
 	}
 }
 
+// NewUnixTunnel creates a new SSH tunnel from a local Unix socket to a remote Unix socket
+func NewUnixTunnel(sshClient *ssh.Client, localPath, remotePath string) *Tunnel {
+	tunnel := NewTunnel(sshClient, localPath, remotePath)
+	tunnel.localNetwork = "unix"
+	tunnel.remoteNetwork = "unix"
+	return tunnel
+}
+
+// NewRemoteUnixTunnel creates a new SSH tunnel from a local TCP address to a remote Unix socket
+func NewRemoteUnixTunnel(sshClient *ssh.Client, localAddr, remotePath string) *Tunnel {
+	tunnel := NewTunnel(sshClient, localAddr, remotePath)
+	tunnel.remoteNetwork = "unix"
+	return tunnel
+}
+
 // Start begins listening on the local address and forwarding connections to the remote address
 func (t *Tunnel) Start(ctx context.Context) error {
 	t.mu.Lock()
@@ -61,8 +85,15 @@ func (t *Tunnel) Start(ctx context.Context) error {
 		return nil
 	}
 
+	// Remove a stale socket file left behind by a previous run
+	if t.localNetwork == "unix" {
+		if err := removeStaleSocket(t.localAddr); err != nil {
+			return err
+		}
+	}
+
 	// Start local listener
-	listener, err := net.Listen("tcp", t.localAddr)
+	listener, err := net.Listen(networkOrTCP(t.localNetwork), t.localAddr)
 	if err != nil {
 		return errors.Wrapf(err, "failed to listen on %s", t.localAddr)
 	}
@@ -113,8 +144,12 @@ func (t *Tunnel) acceptConnections() {
 
 // handleConnection forwards a single connection from local to remote
 func (t *Tunnel) handleConnection(localConn net.Conn) {
+	t.dialerMu.RLock()
+	dialer := t.dialer
+	t.dialerMu.RUnlock()
+
 	// Open a connection to the remote address via the SSH client or dialer
-	remoteConn, err := t.dialer.Dial("tcp", t.remoteAddr)
+	remoteConn, err := dialer.Dial(networkOrTCP(t.remoteNetwork), t.remoteAddr)
 	if err != nil {
 		fmt.Printf("Error dialing remote address %s: %v\n", t.remoteAddr, err)
 		return
@@ -122,25 +157,35 @@ func (t *Tunnel) handleConnection(localConn net.Conn) {
 	defer remoteConn.Close()
 
 	// Copy data in both directions
-	errCh := make(chan error, 2)
+	localDone := make(chan error, 1)
+	remoteDone := make(chan error, 1)
 	go func() {
 		_, err := io.Copy(remoteConn, localConn)
-		errCh <- err
+		localDone <- err
 	}()
 	go func() {
 		_, err := io.Copy(localConn, remoteConn)
-		errCh <- err
+		remoteDone <- err
 	}()
 
-	// Wait for either direction to finish or for the tunnel to be closed
+	// Wait for the remote side to finish or for the tunnel to be closed. When the local
+	// side closes its sending half, e.g. at the end of the stdin of an interactive exec,
+	// the close is passed on and the remaining output still delivered.
 	select {
-	case err := <-errCh:
-		if err != nil && !errors.Is(err, io.EOF) {
-			fmt.Printf("Error in tunnel connection: %v\n", err)
+	case err = <-remoteDone:
+	case err = <-localDone:
+		if cw, ok := remoteConn.(interface{ CloseWrite() error }); ok && err == nil && cw.CloseWrite() == nil {
+			select {
+			case err = <-remoteDone:
+			case <-t.ctx.Done():
+			}
 		}
 	case <-t.ctx.Done():
 		// Tunnel is being closed
 	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		fmt.Printf("Error in tunnel connection: %v\n", err)
+	}
 }
 
 // Close stops the tunnel and closes all connections
@@ -165,10 +210,27 @@ func (t *Tunnel) Close() error {
 	// Wait for all goroutines to finish
 	t.wg.Wait()
 
+	// Clean up the socket file for Unix listeners
+	if t.localNetwork == "unix" {
+		_ = os.Remove(t.localAddr)
+	}
+
 	t.active = false
 	return nil
 }
 
+// setDialer forwards new connections through dialer, e.g. after the SSH connection was
+// re-established; connections already forwarded keep their dialer
+func (t *Tunnel) setDialer(dialer sshDialer) {
+	t.dialerMu.Lock()
+	defer t.dialerMu.Unlock()
+
+	if client, ok := dialer.(*ssh.Client); ok {
+		t.sshClient = client
+	}
+	t.dialer = dialer
+}
+
 // IsActive returns true if the tunnel is active
 func (t *Tunnel) IsActive() bool {
 	t.mu.Lock()
@@ -197,12 +259,55 @@ func (t *Tunnel) RemoteAddr() string {
 func NewTunnelWithDialer(dialer sshDialer, localAddr, remoteAddr string) *Tunnel {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Tunnel{
-		localAddr:  localAddr,
-		remoteAddr: remoteAddr,
-		ctx:        ctx,
-		cancel:     cancel,
-		dialer:     dialer,
+		localAddr:     localAddr,
+		remoteAddr:    remoteAddr,
+		localNetwork:  "tcp",
+		remoteNetwork: "tcp",
+		ctx:           ctx,
+		cancel:        cancel,
+		dialer:        dialer,
+	}
+}
+
+// NewUnixTunnelWithDialer creates a new Unix socket tunnel with a custom dialer for testing
+func NewUnixTunnelWithDialer(dialer sshDialer, localPath, remotePath string) *Tunnel {
+	tunnel := NewTunnelWithDialer(dialer, localPath, remotePath)
+	tunnel.localNetwork = "unix"
+	tunnel.remoteNetwork = "unix"
+	return tunnel
+}
+
+// NewRemoteUnixTunnelWithDialer creates a new TCP-to-Unix socket tunnel with a custom dialer for testing
+func NewRemoteUnixTunnelWithDialer(dialer sshDialer, localAddr, remotePath string) *Tunnel {
+	tunnel := NewTunnelWithDialer(dialer, localAddr, remotePath)
+	tunnel.remoteNetwork = "unix"
+	return tunnel
+}
+
+// networkOrTCP returns network, defaulting to "tcp" when unset
+func networkOrTCP(network string) string {
+	if network == "" {
+		return "tcp"
 	}
+	return network
+}
+
+// removeStaleSocket removes an existing socket file at path, refusing to touch non-socket files
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to stat socket path %s", path)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return errors.Errorf("path %s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.Wrapf(err, "failed to remove stale socket %s", path)
+	}
+	return nil
 }
 
 // Ensure Tunnel implements TunnelInterface
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -113,3 +115,140 @@ func NewTunnelWithClient(client sshDialer, localAddr, remoteAddr string) *Tunnel
 }
 
 // The sshDialer interface is defined in tunnel.go
+
+// TestUnixTunnel tests forwarding between a local and a "remote" Unix socket
+func TestUnixTunnel(t *testing.T) {
+	dir := t.TempDir()
+	remotePath := filepath.Join(dir, "remote.sock")
+	localPath := filepath.Join(dir, "local.sock")
+
+	// Create a Unix echo server standing in for the remote socket
+	echoListener, err := net.Listen("unix", remotePath)
+	require.NoError(t, err)
+	defer echoListener.Close()
+
+	go func() {
+		for {
+			conn, err := echoListener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				io.Copy(c, c)
+			}(conn)
+		}
+	}()
+
+	tunnel := NewUnixTunnelWithDialer(&mockSSHClient{echoServerAddr: remotePath}, localPath, remotePath)
+	require.NoError(t, tunnel.Start(context.Background()))
+	assert.Equal(t, localPath, tunnel.LocalAddr())
+
+	conn, err := net.Dial("unix", localPath)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	testData := []byte("Hello, Socket!")
+	_, err = conn.Write(testData)
+	require.NoError(t, err)
+
+	buffer := make([]byte, len(testData))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	n, err := io.ReadFull(conn, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, testData, buffer[:n])
+
+	require.NoError(t, tunnel.Close())
+	_, err = os.Stat(localPath)
+	assert.True(t, os.IsNotExist(err), "local socket file should be removed on close")
+}
+
+// TestRemoteUnixTunnel verifies forwarding from a local TCP port to a remote Unix socket
+func TestRemoteUnixTunnel(t *testing.T) {
+	remotePath := filepath.Join(t.TempDir(), "docker.sock")
+
+	echoListener, err := net.Listen("unix", remotePath)
+	require.NoError(t, err)
+	defer echoListener.Close()
+
+	go func() {
+		for {
+			conn, err := echoListener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				io.Copy(c, c)
+			}(conn)
+		}
+	}()
+
+	tunnel := NewRemoteUnixTunnelWithDialer(&mockSSHClient{echoServerAddr: remotePath}, "127.0.0.1:0", remotePath)
+	require.NoError(t, tunnel.Start(context.Background()))
+	defer tunnel.Close()
+	assert.Equal(t, remotePath, tunnel.RemoteAddr())
+
+	conn, err := net.Dial("tcp", tunnel.LocalAddr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	testData := []byte("GET /_ping")
+	_, err = conn.Write(testData)
+	require.NoError(t, err)
+
+	buffer := make([]byte, len(testData))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = io.ReadFull(conn, buffer)
+	require.NoError(t, err)
+	assert.Equal(t, testData, buffer)
+}
+
+// TestUnixTunnelRefusesRegularFile verifies the tunnel never deletes non-socket files
+func TestUnixTunnelRefusesRegularFile(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "not-a-socket")
+	require.NoError(t, os.WriteFile(localPath, []byte("data"), 0600))
+
+	tunnel := NewUnixTunnelWithDialer(&mockSSHClient{}, localPath, "/var/run/app.sock")
+	err := tunnel.Start(context.Background())
+	assert.Error(t, err)
+
+	_, statErr := os.Stat(localPath)
+	assert.NoError(t, statErr)
+}
+
+// TestTunnelHalfClose checks that output sent after the client closed its sending side,
+// like the rest of an exec after the end of stdin, still reaches the client
+func TestTunnelHalfClose(t *testing.T) {
+	remoteListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer remoteListener.Close()
+
+	go func() {
+		conn, err := remoteListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		input, _ := io.ReadAll(conn)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = conn.Write(append([]byte("got "), input...))
+	}()
+
+	tunnel := NewTunnelWithClient(&mockSSHClient{echoServerAddr: remoteListener.Addr().String()}, "127.0.0.1:0", remoteListener.Addr().String())
+	require.NoError(t, tunnel.Start(context.Background()))
+	defer tunnel.Close()
+
+	conn, err := net.Dial("tcp", tunnel.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("stdin"))
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	output, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "got stdin", string(output))
+}
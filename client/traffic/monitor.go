@@ -0,0 +1,69 @@
+package traffic
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultCheckInterval is how often server traffic is checked
+	DefaultCheckInterval = 15 * time.Minute
+
+	// DefaultWarnFraction and DefaultCriticalFraction are the default warning thresholds
+	DefaultWarnFraction     = 0.8
+	DefaultCriticalFraction = 0.95
+)
+
+// Monitor periodically fetches server traffic and warns once per server each time
+// a higher level is reached
+type Monitor struct {
+	// Fetch returns the traffic of the current server, or nil if no server is connected
+	Fetch func(ctx context.Context) (*Usage, error)
+
+	// Warn is called when a server reaches a new warning level
+	Warn func(level Level, usage Usage)
+
+	WarnFraction     float64
+	CriticalFraction float64
+	CheckInterval    time.Duration
+
+	warned map[int64]Level
+}
+
+// Run checks periodically until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	interval := m.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check fetches the current usage and warns if a new level was reached
+func (m *Monitor) check(ctx context.Context) {
+	usage, err := m.Fetch(ctx)
+	if err != nil || usage == nil {
+		return
+	}
+
+	if m.warned == nil {
+		m.warned = make(map[int64]Level)
+	}
+
+	level := usage.Level(m.WarnFraction, m.CriticalFraction)
+	if level > m.warned[usage.ServerID] {
+		m.warned[usage.ServerID] = level
+		m.Warn(level, *usage)
+	}
+}
@@ -0,0 +1,114 @@
+// Package traffic accounts for bytes moved through remote servers and warns before
+// the Hetzner included-traffic allowance is used up and overage charges apply.
+package traffic
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Usage is the traffic of one server in the current billing period. Hetzner bills
+// outgoing traffic beyond the included allowance; ingoing traffic is free.
+type Usage struct {
+	ServerID   int64
+	ServerName string
+	Outgoing   uint64
+	Ingoing    uint64
+	Included   uint64
+}
+
+// Fraction returns the share of the included allowance that has been used
+func (u Usage) Fraction() float64 {
+	if u.Included == 0 {
+		return 0
+	}
+	return float64(u.Outgoing) / float64(u.Included)
+}
+
+// Level classifies how close a server is to its traffic allowance
+type Level int
+
+const (
+	LevelOK Level = iota
+	LevelWarning
+	LevelCritical
+)
+
+// String returns the level name
+func (l Level) String() string {
+	switch l {
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// Level returns the level of u for the given warning and critical fractions
+func (u Usage) Level(warn, critical float64) Level {
+	switch f := u.Fraction(); {
+	case f >= critical:
+		return LevelCritical
+	case f >= warn:
+		return LevelWarning
+	default:
+		return LevelOK
+	}
+}
+
+// String returns a short human-readable summary
+func (u Usage) String() string {
+	return fmt.Sprintf("%s out / %s included (%.1f%%), %s in",
+		FormatBytes(u.Outgoing), FormatBytes(u.Included), u.Fraction()*100, FormatBytes(u.Ingoing))
+}
+
+// FormatBytes formats a byte count with a binary unit
+func FormatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// Counter counts bytes relayed through the tunnel in each direction
+type Counter struct {
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+// Sent returns the bytes sent from the client to the remote server
+func (c *Counter) Sent() uint64 { return c.sent.Load() }
+
+// Received returns the bytes received from the remote server
+func (c *Counter) Received() uint64 { return c.received.Load() }
+
+// SendWriter wraps w so that bytes written to it count as sent
+func (c *Counter) SendWriter(w io.Writer) io.Writer {
+	return &countingWriter{w: w, n: &c.sent}
+}
+
+// ReceiveWriter wraps w so that bytes written to it count as received
+func (c *Counter) ReceiveWriter(w io.Writer) io.Writer {
+	return &countingWriter{w: w, n: &c.received}
+}
+
+// countingWriter adds the bytes written through it to a counter
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Uint64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n.Add(uint64(n)) // #nosec G115 -- n is never negative
+	return n, err
+}
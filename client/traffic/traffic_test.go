@@ -0,0 +1,86 @@
+package traffic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageLevel(t *testing.T) {
+	const tb = 1 << 40
+
+	tests := []struct {
+		outgoing uint64
+		expected Level
+	}{
+		{0, LevelOK},
+		{15 * tb, LevelOK},
+		{16 * tb, LevelWarning},
+		{19 * tb, LevelCritical},
+		{25 * tb, LevelCritical},
+	}
+
+	for _, tt := range tests {
+		usage := Usage{Outgoing: tt.outgoing, Included: 20 * tb}
+		assert.Equal(t, tt.expected, usage.Level(DefaultWarnFraction, DefaultCriticalFraction), FormatBytes(tt.outgoing))
+	}
+
+	assert.Equal(t, LevelOK, Usage{Outgoing: tb}.Level(0.8, 0.95), "unknown allowance never warns")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "20.0 TiB", FormatBytes(20<<40))
+}
+
+func TestCounter(t *testing.T) {
+	var counter Counter
+	var sent, received bytes.Buffer
+
+	_, _ = counter.SendWriter(&sent).Write([]byte("hello"))
+	_, _ = counter.ReceiveWriter(&received).Write([]byte("hi"))
+	_, _ = counter.ReceiveWriter(&received).Write([]byte("!"))
+
+	assert.Equal(t, uint64(5), counter.Sent())
+	assert.Equal(t, uint64(3), counter.Received())
+	assert.Equal(t, "hello", sent.String())
+}
+
+func TestMonitorWarnsOncePerLevel(t *testing.T) {
+	usage := &Usage{ServerID: 1, Included: 100}
+	var fetchErr error
+	var warnings []Level
+
+	monitor := &Monitor{
+		Fetch: func(ctx context.Context) (*Usage, error) {
+			return usage, fetchErr
+		},
+		Warn: func(level Level, u Usage) {
+			warnings = append(warnings, level)
+		},
+		WarnFraction:     0.8,
+		CriticalFraction: 0.95,
+	}
+
+	ctx := context.Background()
+	usage.Outgoing = 50
+	monitor.check(ctx)
+	usage.Outgoing = 85
+	monitor.check(ctx)
+	monitor.check(ctx)
+	fetchErr = errors.New("api unavailable")
+	monitor.check(ctx)
+	fetchErr = nil
+	usage.Outgoing = 99
+	monitor.check(ctx)
+
+	// A new server gets its own warnings
+	usage.ServerID = 2
+	monitor.check(ctx)
+
+	assert.Equal(t, []Level{LevelWarning, LevelCritical, LevelCritical}, warnings)
+}
@@ -0,0 +1,78 @@
+package tray
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often the plugin host refreshes the menu
+const DefaultInterval = 10 * time.Second
+
+// pluginName is the base name of the installed plugin script
+const pluginName = "dockbridge"
+
+// PluginDir returns the plugin directory of the plugin host for goos: xbar's on macOS
+// and Argos' on Linux
+func PluginDir(goos, home string) (string, error) {
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "xbar", "plugins"), nil
+	case "linux":
+		return filepath.Join(home, ".config", "argos"), nil
+	default:
+		return "", fmt.Errorf("the tray is only supported on macOS (xbar, SwiftBar) and Linux (Argos), not %s", goos)
+	}
+}
+
+// PluginFile returns the file name of the plugin refreshed every interval, which plugin
+// hosts read from the name, e.g. dockbridge.10s.sh
+func PluginFile(interval time.Duration) string {
+	seconds := int(interval.Round(time.Second) / time.Second)
+	switch {
+	case seconds < 1:
+		seconds = 1
+	case seconds%60 == 0:
+		return fmt.Sprintf("%s.%dm.sh", pluginName, seconds/60)
+	}
+	return fmt.Sprintf("%s.%ds.sh", pluginName, seconds)
+}
+
+// Install writes the plugin script running "dockbridge tray" with args into dir,
+// replacing plugins of other intervals, and returns its path
+func Install(dir, executable string, args []string, interval time.Duration) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := Uninstall(dir); err != nil {
+		return "", err
+	}
+
+	words := []string{shellQuote(executable), "tray"}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	script := fmt.Sprintf("#!/bin/sh\n# DockBridge menu, written by \"dockbridge tray install\"\nexec %s\n", strings.Join(words, " "))
+
+	path := filepath.Join(dir, PluginFile(interval))
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil { // #nosec G306 -- plugin hosts run the script
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Uninstall removes the plugin scripts from dir
+func Uninstall(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, pluginName+".*.sh"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
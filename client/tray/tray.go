@@ -0,0 +1,227 @@
+// Package tray shows the state of the running DockBridge contexts in the macOS menu bar
+// or the Linux top bar: whether a paid server is running, its keep-alive countdown and
+// active forwards, with actions to provision and destroy servers. The menu is rendered
+// for menu bar plugin hosts, xbar or SwiftBar on macOS and Argos on GNOME, which run
+// "dockbridge tray" at an interval and call back into dockbridge for the actions.
+package tray
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/dockbridge/dockbridge/client/dashboard"
+)
+
+// Format is the plugin protocol a menu is rendered for
+type Format string
+
+const (
+	// FormatXbar is the plugin format of xbar and SwiftBar on macOS
+	FormatXbar Format = "xbar"
+	// FormatArgos is the plugin format of the Argos GNOME Shell extension
+	FormatArgos Format = "argos"
+)
+
+// ParseFormat returns the format named name
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatXbar, FormatArgos:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown tray format %q, must be xbar or argos", name)
+	}
+}
+
+// DefaultFormat returns the plugin format of the platform goos
+func DefaultFormat(goos string) Format {
+	if goos == "linux" {
+		return FormatArgos
+	}
+	return FormatXbar
+}
+
+// Menu renders the tray menu of a state
+type Menu struct {
+	// Executable is the dockbridge binary run by the menu's actions
+	Executable string
+	// Args are appended to the arguments of every action, e.g. --config <path>
+	Args   []string
+	Format Format
+}
+
+// action is a dockbridge command run when a menu item is clicked
+type action struct {
+	args []string
+	// terminal runs the command in a terminal window, for commands that keep running
+	terminal bool
+}
+
+// Render writes the menu of state to w: the title shown in the bar, then the items
+// of every context
+func (m *Menu) Render(w io.Writer, state dashboard.State) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintln(out, title(state))
+	fmt.Fprintln(out, "---")
+
+	if state.Err != nil {
+		m.item(out, "DockBridge daemon not running", "color=red")
+		m.item(out, "Start daemon", m.params(action{args: []string{"start"}, terminal: true}))
+	}
+	for i, contextState := range state.Contexts {
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		m.renderContext(out, contextState)
+	}
+
+	fmt.Fprintln(out, "---")
+	if state.Currency != "" {
+		if state.Budget > 0 {
+			m.item(out, fmt.Sprintf("This month: %.2f of %.2f %s", state.MonthSpend, state.Budget, state.Currency), "")
+		} else {
+			m.item(out, fmt.Sprintf("This month: %.2f %s", state.MonthSpend, state.Currency), "")
+		}
+	}
+	if state.Err == nil {
+		m.item(out, "Open dashboard", m.params(action{args: []string{"status"}, terminal: true}))
+	}
+	m.item(out, "Refresh", "refresh=true")
+
+	return out.Flush()
+}
+
+// renderContext writes the items of one context
+func (m *Menu) renderContext(out io.Writer, state dashboard.ContextState) {
+	name := state.Name
+	var contextArgs []string
+	if name == "" {
+		name = "default"
+	} else {
+		contextArgs = []string{"--context", name}
+	}
+
+	if state.Server == nil {
+		m.item(out, fmt.Sprintf("%s: no server", name), "")
+		if state.Running {
+			m.item(out, "Provision server", m.params(action{args: append([]string{"tray", "provision"}, contextArgs...)}))
+		}
+	} else {
+		srv := state.Server
+		m.item(out, fmt.Sprintf("%s: %s %s", name, srv.Name, srv.Status), "color=green")
+		if srv.IPAddress != "" {
+			m.item(out, "--IP "+srv.IPAddress, "")
+		}
+		if state.Cost != nil {
+			m.item(out, fmt.Sprintf("--Cost so far %.2f %s (%.4f %s/h)", state.Cost.Amount, state.Cost.Currency, state.Cost.Hourly, state.Cost.Currency), "")
+		}
+
+		switch {
+		case state.KeepAlive == nil:
+			m.item(out, "Keep-alive status unknown", "")
+		case state.KeepAlive.TimedOut:
+			m.item(out, fmt.Sprintf("Keep-alive timed out, last heartbeat %s ago", dashboard.FormatDuration(state.KeepAlive.SinceHeartbeat)), "color=red")
+		default:
+			m.item(out, fmt.Sprintf("Self-destructs in %s without Docker activity", dashboard.FormatDuration(state.KeepAlive.UntilShutdown)), "")
+		}
+		m.item(out, "Destroy server", m.params(action{args: append([]string{"tray", "destroy"}, contextArgs...)}))
+	}
+
+	if len(state.Forwards) > 0 {
+		m.item(out, fmt.Sprintf("Forwards (%d)", len(state.Forwards)), "")
+		for _, forward := range state.Forwards {
+			m.item(out, fmt.Sprintf("--%s → %s:%s", forward.Local, forward.Container, forward.Remote), forwardLink(forward))
+		}
+	}
+}
+
+// forwardLink returns the parameter opening a TCP forward in the browser, or "" for
+// other forwards
+func forwardLink(forward dashboard.Forward) string {
+	if forward.Type != "tcp" {
+		return ""
+	}
+	host, port, err := net.SplitHostPort(forward.Local)
+	if err != nil {
+		return ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "href=http://" + net.JoinHostPort(host, port)
+}
+
+// title is the text shown in the bar: whether any server is running and the nearest
+// keep-alive shutdown
+func title(state dashboard.State) string {
+	if state.Err != nil {
+		return "🐳 ⚠"
+	}
+
+	running := 0
+	var until *dashboard.KeepAlive
+	for _, contextState := range state.Contexts {
+		if contextState.Server == nil {
+			continue
+		}
+		running++
+		keepAlive := contextState.KeepAlive
+		if keepAlive != nil && !keepAlive.TimedOut && (until == nil || keepAlive.UntilShutdown < until.UntilShutdown) {
+			until = keepAlive
+		}
+	}
+
+	switch {
+	case running == 0:
+		return "🐳 ○"
+	case until != nil:
+		return "🐳 ● " + dashboard.FormatDuration(until.UntilShutdown)
+	default:
+		return "🐳 ●"
+	}
+}
+
+// item writes a menu item with its plugin parameters; the text must not contain "|"
+func (m *Menu) item(out io.Writer, text, params string) {
+	text = strings.ReplaceAll(text, "|", "/")
+	if params == "" {
+		fmt.Fprintln(out, text)
+		return
+	}
+	fmt.Fprintf(out, "%s | %s\n", text, params)
+}
+
+// params returns the plugin parameters running a dockbridge action and refreshing the
+// menu afterwards
+func (m *Menu) params(a action) string {
+	args := append(append([]string(nil), a.args...), m.Args...)
+	terminal := fmt.Sprintf("terminal=%t", a.terminal)
+
+	if m.Format == FormatArgos {
+		// Argos runs the bash parameter as a command line
+		words := []string{shellQuote(m.Executable)}
+		for _, arg := range args {
+			words = append(words, shellQuote(arg))
+		}
+		return fmt.Sprintf("bash='%s' %s refresh=true", strings.Join(words, " "), terminal)
+	}
+
+	// xbar and SwiftBar run the bash parameter as an executable with numbered arguments
+	params := []string{fmt.Sprintf("bash=%q", m.Executable)}
+	for i, arg := range args {
+		params = append(params, fmt.Sprintf("param%d=%q", i+1, arg))
+	}
+	return strings.Join(append(params, terminal, "refresh=true"), " ")
+}
+
+// shellQuote quotes s as one word of a shell command line, with double quotes so the
+// line can be single-quoted in turn
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s) + `"`
+}
@@ -0,0 +1,104 @@
+package tray
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/dashboard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testState has a running server in the default context and none in "gpu"
+func testState() dashboard.State {
+	return dashboard.State{
+		Contexts: []dashboard.ContextState{
+			{
+				Running:   true,
+				Server:    &dashboard.Server{Name: "dockbridge-7", Status: "running", IPAddress: "192.0.2.7"},
+				KeepAlive: &dashboard.KeepAlive{SinceHeartbeat: 12 * time.Second, UntilShutdown: 4*time.Minute + 48*time.Second},
+				Forwards: []dashboard.Forward{
+					{Type: "tcp", Local: "127.0.0.1:8080", Remote: "80", Container: "web"},
+					{Type: "unix", Local: "/tmp/agent.sock", Remote: "/run/agent.sock", Container: "agent"},
+				},
+			},
+			{Name: "gpu", Running: true},
+		},
+		MonthSpend: 1.5,
+		Currency:   "EUR",
+	}
+}
+
+func TestRenderXbar(t *testing.T) {
+	menu := &Menu{Executable: "/usr/local/bin/dockbridge", Args: []string{"--config", "/home/me/client.yaml"}, Format: FormatXbar}
+	var out bytes.Buffer
+	require.NoError(t, menu.Render(&out, testState()))
+	lines := strings.Split(out.String(), "\n")
+
+	assert.Equal(t, "🐳 ● 4m48s", lines[0])
+	assert.Equal(t, "---", lines[1])
+	assert.Contains(t, lines, "default: dockbridge-7 running | color=green")
+	assert.Contains(t, lines, "Self-destructs in 4m48s without Docker activity")
+	assert.Contains(t, lines, `Destroy server | bash="/usr/local/bin/dockbridge" param1="tray" param2="destroy" param3="--config" param4="/home/me/client.yaml" terminal=false refresh=true`)
+	assert.Contains(t, lines, "--127.0.0.1:8080 → web:80 | href=http://127.0.0.1:8080")
+	assert.Contains(t, lines, "--/tmp/agent.sock → agent:/run/agent.sock")
+	assert.Contains(t, lines, "gpu: no server")
+	assert.Contains(t, lines, `Provision server | bash="/usr/local/bin/dockbridge" param1="tray" param2="provision" param3="--context" param4="gpu" param5="--config" param6="/home/me/client.yaml" terminal=false refresh=true`)
+	assert.Contains(t, lines, "This month: 1.50 EUR")
+}
+
+func TestRenderArgos(t *testing.T) {
+	menu := &Menu{Executable: "/opt/dock bridge/dockbridge", Format: FormatArgos}
+	var out bytes.Buffer
+	require.NoError(t, menu.Render(&out, testState()))
+
+	assert.Contains(t, out.String(), `Provision server | bash='"/opt/dock bridge/dockbridge" tray provision --context gpu' terminal=false refresh=true`+"\n")
+	assert.Contains(t, out.String(), `Open dashboard | bash='"/opt/dock bridge/dockbridge" status' terminal=true refresh=true`+"\n")
+}
+
+func TestRenderTitle(t *testing.T) {
+	var out bytes.Buffer
+	menu := &Menu{Executable: "dockbridge", Format: FormatXbar}
+
+	require.NoError(t, menu.Render(&out, dashboard.State{Err: errors.New("connection refused")}))
+	assert.True(t, strings.HasPrefix(out.String(), "🐳 ⚠\n---\nDockBridge daemon not running | color=red\n"))
+
+	out.Reset()
+	require.NoError(t, menu.Render(&out, dashboard.State{Contexts: []dashboard.ContextState{{Running: true}}}))
+	assert.True(t, strings.HasPrefix(out.String(), "🐳 ○\n"))
+
+	out.Reset()
+	require.NoError(t, menu.Render(&out, dashboard.State{Contexts: []dashboard.ContextState{{Running: true, Server: &dashboard.Server{Name: "dockbridge-7"}}}}))
+	assert.True(t, strings.HasPrefix(out.String(), "🐳 ●\n"))
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dockbridge.5s.sh"), nil, 0o755))
+
+	path, err := Install(dir, "/opt/dock bridge/dockbridge", []string{"--format", "xbar"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "dockbridge.1m.sh"), path)
+	assert.NoFileExists(t, filepath.Join(dir, "dockbridge.5s.sh"), "plugins of other intervals are replaced")
+
+	script, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "exec \"/opt/dock bridge/dockbridge\" tray --format xbar\n")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "the plugin is executable")
+
+	require.NoError(t, Uninstall(dir))
+	assert.NoFileExists(t, path)
+}
+
+func TestPluginFile(t *testing.T) {
+	assert.Equal(t, "dockbridge.10s.sh", PluginFile(DefaultInterval))
+	assert.Equal(t, "dockbridge.90s.sh", PluginFile(90*time.Second))
+	assert.Equal(t, "dockbridge.1s.sh", PluginFile(0))
+}
@@ -0,0 +1,172 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+)
+
+// DefaultSampleInterval is how often the remote server is sampled
+const DefaultSampleInterval = 5 * time.Minute
+
+// SampleCommand prints core count, two /proc/stat CPU lines one second apart, memory
+// totals and Docker data disk usage (both in MiB) on the remote server
+const SampleCommand = "nproc; head -n1 /proc/stat; sleep 1; head -n1 /proc/stat; " +
+	"free -m | awk '/^Mem:/{print $2, $3}'; " +
+	"df -B1M --output=size,used /var/lib/docker | tail -n1"
+
+// ParseSample parses the output of SampleCommand
+func ParseSample(output string) (*Sample, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 5 {
+		return nil, fmt.Errorf("unexpected usage output: got %d lines, want 5", len(lines))
+	}
+
+	cores, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid core count: %w", err)
+	}
+
+	cpuPercent, err := cpuBusyPercent(lines[1], lines[2])
+	if err != nil {
+		return nil, err
+	}
+
+	mem, err := parseInts(lines[3], 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory usage: %w", err)
+	}
+
+	disk, err := parseInts(lines[4], 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid disk usage: %w", err)
+	}
+
+	return &Sample{
+		CPUCores:      cores,
+		CPUPercent:    cpuPercent,
+		MemoryTotalMB: mem[0],
+		MemoryUsedMB:  mem[1],
+		DiskTotalGB:   float64(disk[0]) / 1024,
+		DiskUsedGB:    float64(disk[1]) / 1024,
+	}, nil
+}
+
+// cpuBusyPercent computes overall CPU utilisation between two /proc/stat "cpu" lines
+func cpuBusyPercent(before, after string) (float64, error) {
+	total1, idle1, err := parseCPULine(before)
+	if err != nil {
+		return 0, err
+	}
+	total2, idle2, err := parseCPULine(after)
+	if err != nil {
+		return 0, err
+	}
+
+	deltaTotal := total2 - total1
+	if deltaTotal <= 0 {
+		return 0, nil
+	}
+	return float64(deltaTotal-(idle2-idle1)) / float64(deltaTotal) * 100, nil
+}
+
+// parseCPULine returns total and idle (idle + iowait) jiffies of a /proc/stat "cpu" line
+func parseCPULine(line string) (total, idle int64, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("invalid /proc/stat line: %q", line)
+	}
+
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid /proc/stat value %q: %w", field, err)
+		}
+		total += v
+		if i == 3 || i == 4 { // idle, iowait
+			idle += v
+		}
+	}
+	return total, idle, nil
+}
+
+// parseInts parses exactly n whitespace-separated integers
+func parseInts(line string, n int) ([]int, error) {
+	fields := strings.Fields(line)
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d values in %q", n, line)
+	}
+	values := make([]int, n)
+	for i, field := range fields {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Collector periodically samples the remote server and appends the result to a Store
+type Collector struct {
+	sample   func(ctx context.Context) (*Sample, error)
+	store    *Store
+	interval time.Duration
+	logger   logger.LoggerInterface
+}
+
+// NewCollector creates a collector. sample may return a nil sample when no server is
+// connected, in which case nothing is recorded.
+func NewCollector(sample func(ctx context.Context) (*Sample, error), store *Store, interval time.Duration, logger logger.LoggerInterface) *Collector {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	return &Collector{
+		sample:   sample,
+		store:    store,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run samples until ctx is cancelled
+func (c *Collector) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.collect(ctx)
+		}
+	}
+}
+
+// collect takes and stores one sample
+func (c *Collector) collect(ctx context.Context) {
+	sample, err := c.sample(ctx)
+	if err != nil {
+		c.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Debug("Failed to sample server usage")
+		return
+	}
+	if sample == nil {
+		return
+	}
+
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+	if err := c.store.Append(*sample); err != nil {
+		c.logger.WithFields(map[string]any{
+			"error": err.Error(),
+		}).Warn("Failed to store server usage sample")
+	}
+}
@@ -0,0 +1,64 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSample(t *testing.T) {
+	output := `4
+cpu  1000 0 1000 8000 0 0 0 0 0 0
+cpu  1300 0 1100 8600 0 0 0 0 0 0
+7821 3120
+40960 10240
+`
+	sample, err := ParseSample(output)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, sample.CPUCores)
+	assert.InDelta(t, 40.0, sample.CPUPercent, 0.01) // 400 busy of 1000 jiffies
+	assert.Equal(t, 7821, sample.MemoryTotalMB)
+	assert.Equal(t, 3120, sample.MemoryUsedMB)
+	assert.InDelta(t, 40.0, sample.DiskTotalGB, 0.01)
+	assert.InDelta(t, 10.0, sample.DiskUsedGB, 0.01)
+}
+
+func TestParseSampleInvalid(t *testing.T) {
+	_, err := ParseSample("4\n")
+	assert.Error(t, err)
+
+	_, err = ParseSample("4\nintr 1 2 3 4\ncpu 1 2 3 4 5\n1 2\n1 2\n")
+	assert.Error(t, err)
+}
+
+func TestCollectorCollect(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "usage.json"))
+
+	var next *Sample
+	var nextErr error
+	collector := NewCollector(func(ctx context.Context) (*Sample, error) {
+		return next, nextErr
+	}, store, 0, logger.NewDefault())
+
+	// No server connected: nothing is stored
+	collector.collect(context.Background())
+
+	// Sampling error: nothing is stored
+	nextErr = errors.New("ssh failed")
+	collector.collect(context.Background())
+
+	next, nextErr = &Sample{ServerType: "cx21"}, nil
+	collector.collect(context.Background())
+
+	samples, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "cx21", samples[0].ServerType)
+	assert.False(t, samples[0].Time.IsZero(), "collector should timestamp samples")
+}
@@ -0,0 +1,213 @@
+package usage
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MinSamplesForRecommendation is the number of samples needed before recommending a server type
+const MinSamplesForRecommendation = 12
+
+const (
+	// cpuHeadroom and memoryHeadroom are the safety factors applied to observed usage
+	cpuHeadroom    = 1.25
+	memoryHeadroom = 1.2
+
+	// hoursPerMonth is the number of hours Hetzner bills as a full month
+	hoursPerMonth = 730
+)
+
+// ServerTypeSpec describes a Hetzner server type
+type ServerTypeSpec struct {
+	Name         string
+	Cores        int
+	MemoryGB     int
+	MonthlyPrice float64 // EUR, list price excluding VAT
+}
+
+// HourlyPrice returns the hourly list price
+func (s ServerTypeSpec) HourlyPrice() float64 {
+	return s.MonthlyPrice / hoursPerMonth
+}
+
+// serverTypeCatalog lists the server types DockBridge can provision. Prices are
+// approximate list prices and only used to estimate relative savings.
+var serverTypeCatalog = []ServerTypeSpec{
+	{Name: "cx11", Cores: 1, MemoryGB: 2, MonthlyPrice: 3.29},
+	{Name: "cpx11", Cores: 2, MemoryGB: 2, MonthlyPrice: 4.35},
+	{Name: "cx21", Cores: 2, MemoryGB: 4, MonthlyPrice: 5.83},
+	{Name: "cx23", Cores: 2, MemoryGB: 4, MonthlyPrice: 3.49},
+	{Name: "cpx21", Cores: 3, MemoryGB: 4, MonthlyPrice: 7.55},
+	{Name: "cx31", Cores: 2, MemoryGB: 8, MonthlyPrice: 10.59},
+	{Name: "cpx31", Cores: 4, MemoryGB: 8, MonthlyPrice: 13.60},
+	{Name: "cx41", Cores: 4, MemoryGB: 16, MonthlyPrice: 18.92},
+	{Name: "cpx41", Cores: 8, MemoryGB: 16, MonthlyPrice: 25.20},
+	{Name: "cx51", Cores: 8, MemoryGB: 32, MonthlyPrice: 35.58},
+	{Name: "cpx51", Cores: 16, MemoryGB: 32, MonthlyPrice: 54.90},
+}
+
+// LookupServerType returns the catalog entry for name
+func LookupServerType(name string) (ServerTypeSpec, bool) {
+	for _, spec := range serverTypeCatalog {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return ServerTypeSpec{}, false
+}
+
+// Summary aggregates the samples observed for one server type
+type Summary struct {
+	ServerType         string
+	Samples            int
+	Window             time.Duration
+	ObservedHours      float64
+	CPUCores           int
+	PeakCPUPercent     float64
+	P95CPUPercent      float64
+	PeakMemoryMB       int
+	MemoryTotalMB      int
+	DiskUsedGB         float64
+	DiskTotalGB        float64
+	DiskGrowthGBPerDay float64
+}
+
+// Summarize aggregates the samples taken for serverType in context (empty is the
+// default context). sampleInterval is used to estimate how long servers ran.
+func Summarize(samples []Sample, contextName, serverType string, sampleInterval time.Duration) *Summary {
+	var matched []Sample
+	for _, s := range samples {
+		if s.Context == contextName && s.ServerType == serverType {
+			matched = append(matched, s)
+		}
+	}
+
+	summary := &Summary{ServerType: serverType, Samples: len(matched)}
+	if len(matched) == 0 {
+		return summary
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Time.Before(matched[j].Time) })
+	first, last := matched[0], matched[len(matched)-1]
+
+	cpu := make([]float64, 0, len(matched))
+	for _, s := range matched {
+		cpu = append(cpu, s.CPUPercent)
+		summary.PeakCPUPercent = max(summary.PeakCPUPercent, s.CPUPercent)
+		summary.PeakMemoryMB = max(summary.PeakMemoryMB, s.MemoryUsedMB)
+	}
+	slices.Sort(cpu)
+	summary.P95CPUPercent = cpu[int(math.Ceil(0.95*float64(len(cpu))))-1]
+
+	summary.Window = last.Time.Sub(first.Time)
+	summary.ObservedHours = float64(len(matched)) * sampleInterval.Hours()
+	summary.CPUCores = last.CPUCores
+	summary.MemoryTotalMB = last.MemoryTotalMB
+	summary.DiskUsedGB = last.DiskUsedGB
+	summary.DiskTotalGB = last.DiskTotalGB
+	if days := summary.Window.Hours() / 24; days >= 1 {
+		summary.DiskGrowthGBPerDay = (last.DiskUsedGB - first.DiskUsedGB) / days
+	}
+
+	return summary
+}
+
+// Action is the outcome of a recommendation
+type Action string
+
+const (
+	ActionKeep     Action = "keep"
+	ActionDownsize Action = "downsize"
+	ActionUpsize   Action = "upsize"
+)
+
+// Recommendation suggests a server type for the observed workload
+type Recommendation struct {
+	Action    Action
+	Current   ServerTypeSpec
+	Suggested ServerTypeSpec
+	Reason    string
+
+	// MonthlySavings is the list price difference for a server running all month
+	// (negative when the suggestion costs more)
+	MonthlySavings float64
+
+	// ObservedMonthlySavings prorates the savings to the observed running hours per month
+	ObservedMonthlySavings float64
+
+	// DiskWarning is set when the Docker data disk is filling up
+	DiskWarning string
+}
+
+// Recommend picks the cheapest server type that fits the observed peak memory and
+// 95th percentile CPU with some headroom
+func Recommend(summary *Summary) (*Recommendation, error) {
+	current, ok := LookupServerType(summary.ServerType)
+	if !ok {
+		return nil, fmt.Errorf("unknown server type %q", summary.ServerType)
+	}
+	if summary.Samples < MinSamplesForRecommendation {
+		return nil, fmt.Errorf("not enough usage data yet: %d of %d samples collected", summary.Samples, MinSamplesForRecommendation)
+	}
+
+	cores := summary.CPUCores
+	if cores == 0 {
+		cores = current.Cores
+	}
+	neededCores := summary.P95CPUPercent / 100 * float64(cores) * cpuHeadroom
+	neededMemoryMB := float64(summary.PeakMemoryMB) * memoryHeadroom
+
+	fits := func(spec ServerTypeSpec) bool {
+		return float64(spec.Cores) >= neededCores && float64(spec.MemoryGB*1024) >= neededMemoryMB
+	}
+
+	candidates := slices.Clone(serverTypeCatalog)
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].MonthlyPrice < candidates[j].MonthlyPrice })
+
+	suggested := candidates[len(candidates)-1]
+	for _, spec := range candidates {
+		if fits(spec) {
+			suggested = spec
+			break
+		}
+	}
+
+	rec := &Recommendation{
+		Current:   current,
+		Suggested: suggested,
+	}
+
+	switch {
+	case !fits(current):
+		rec.Action = ActionUpsize
+		rec.Reason = fmt.Sprintf("observed peaks (%.0f%% CPU p95 on %d cores, %d MB RAM) exceed %s with headroom",
+			summary.P95CPUPercent, cores, summary.PeakMemoryMB, current.Name)
+	case suggested.Name != current.Name && suggested.MonthlyPrice < current.MonthlyPrice:
+		rec.Action = ActionDownsize
+		rec.Reason = fmt.Sprintf("%s (%d vCPU, %d GB) covers the observed peaks (%.0f%% CPU p95, %d MB RAM)",
+			suggested.Name, suggested.Cores, suggested.MemoryGB, summary.P95CPUPercent, summary.PeakMemoryMB)
+	default:
+		rec.Action = ActionKeep
+		rec.Suggested = current
+		rec.Reason = fmt.Sprintf("%s fits the observed workload", current.Name)
+	}
+
+	rec.MonthlySavings = rec.Current.MonthlyPrice - rec.Suggested.MonthlyPrice
+	if summary.Window > 0 {
+		hoursPerObservedMonth := summary.ObservedHours / summary.Window.Hours() * hoursPerMonth
+		rec.ObservedMonthlySavings = (rec.Current.HourlyPrice() - rec.Suggested.HourlyPrice()) * min(hoursPerObservedMonth, hoursPerMonth)
+	}
+
+	if summary.DiskGrowthGBPerDay > 0 && summary.DiskTotalGB > 0 {
+		daysLeft := (summary.DiskTotalGB - summary.DiskUsedGB) / summary.DiskGrowthGBPerDay
+		if daysLeft < 30 {
+			rec.DiskWarning = fmt.Sprintf("Docker data disk grows %.1f GB/day and will be full in about %.0f days; consider a larger volume_size or pruning images",
+				summary.DiskGrowthGBPerDay, daysLeft)
+		}
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,100 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeSamples builds n hourly samples with the given usage
+func makeSamples(n int, serverType string, cores int, cpu float64, memMB int) []Sample {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := make([]Sample, n)
+	for i := range samples {
+		samples[i] = Sample{
+			Time:          start.Add(time.Duration(i) * time.Hour),
+			ServerType:    serverType,
+			CPUCores:      cores,
+			CPUPercent:    cpu,
+			MemoryUsedMB:  memMB,
+			MemoryTotalMB: 16000,
+			DiskUsedGB:    10,
+			DiskTotalGB:   100,
+		}
+	}
+	return samples
+}
+
+func TestSummarize(t *testing.T) {
+	samples := makeSamples(20, "cpx31", 4, 10, 1000)
+	samples[5].CPUPercent = 90
+	samples[6].MemoryUsedMB = 3000
+	samples = append(samples, Sample{ServerType: "cx21", CPUPercent: 100})
+	samples = append(samples, Sample{Context: "gpu", ServerType: "cpx31", CPUPercent: 100})
+
+	summary := Summarize(samples, "", "cpx31", time.Hour)
+
+	assert.Equal(t, 20, summary.Samples, "other server types and contexts are excluded")
+	assert.Equal(t, 90.0, summary.PeakCPUPercent)
+	assert.Equal(t, 10.0, summary.P95CPUPercent, "a single spike should not drive the p95")
+	assert.Equal(t, 3000, summary.PeakMemoryMB)
+	assert.Equal(t, 19*time.Hour, summary.Window)
+	assert.Equal(t, 20.0, summary.ObservedHours)
+}
+
+func TestRecommend(t *testing.T) {
+	t.Run("not enough samples", func(t *testing.T) {
+		summary := Summarize(makeSamples(3, "cpx31", 4, 10, 1000), "", "cpx31", time.Hour)
+		_, err := Recommend(summary)
+		assert.Error(t, err)
+	})
+
+	t.Run("downsize idle server", func(t *testing.T) {
+		summary := Summarize(makeSamples(24, "cpx41", 8, 5, 2000), "", "cpx41", time.Hour)
+		rec, err := Recommend(summary)
+		require.NoError(t, err)
+
+		assert.Equal(t, ActionDownsize, rec.Action)
+		assert.Equal(t, "cx23", rec.Suggested.Name)
+		assert.Greater(t, rec.MonthlySavings, 0.0)
+		assert.Greater(t, rec.ObservedMonthlySavings, 0.0)
+	})
+
+	t.Run("upsize overloaded server", func(t *testing.T) {
+		summary := Summarize(makeSamples(24, "cx23", 2, 95, 3800), "", "cx23", time.Hour)
+		rec, err := Recommend(summary)
+		require.NoError(t, err)
+
+		assert.Equal(t, ActionUpsize, rec.Action)
+		assert.GreaterOrEqual(t, rec.Suggested.Cores, 3)
+		assert.GreaterOrEqual(t, rec.Suggested.MemoryGB, 8)
+		assert.Less(t, rec.MonthlySavings, 0.0)
+	})
+
+	t.Run("keep right-sized server", func(t *testing.T) {
+		summary := Summarize(makeSamples(24, "cx23", 2, 60, 2500), "", "cx23", time.Hour)
+		rec, err := Recommend(summary)
+		require.NoError(t, err)
+
+		assert.Equal(t, ActionKeep, rec.Action)
+		assert.Equal(t, "cx23", rec.Suggested.Name)
+		assert.Zero(t, rec.MonthlySavings)
+	})
+
+	t.Run("disk filling up", func(t *testing.T) {
+		samples := makeSamples(48, "cx23", 2, 60, 2500)
+		for i := range samples {
+			samples[i].DiskUsedGB = 50 + float64(i)
+		}
+		rec, err := Recommend(Summarize(samples, "", "cx23", time.Hour))
+		require.NoError(t, err)
+		assert.NotEmpty(t, rec.DiskWarning)
+	})
+
+	t.Run("unknown server type", func(t *testing.T) {
+		_, err := Recommend(&Summary{ServerType: "huge", Samples: 100})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,126 @@
+// Package usage collects resource usage of remote servers into local state and
+// recommends a server type that fits the observed workload.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSamples bounds the number of samples kept in local state (one week at the default interval)
+const DefaultMaxSamples = 2016
+
+// Sample is a single resource usage observation of a remote server. It holds no
+// container, image or host identifiers.
+type Sample struct {
+	Time          time.Time `json:"time"`
+	Context       string    `json:"context,omitempty"`
+	ServerType    string    `json:"server_type"`
+	CPUCores      int       `json:"cpu_cores"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryUsedMB  int       `json:"memory_used_mb"`
+	MemoryTotalMB int       `json:"memory_total_mb"`
+	DiskUsedGB    float64   `json:"disk_used_gb"`
+	DiskTotalGB   float64   `json:"disk_total_gb"`
+}
+
+// state is the on-disk format of the usage store
+type state struct {
+	Samples []Sample `json:"samples"`
+}
+
+// Store persists usage samples in a local JSON file
+type Store struct {
+	path       string
+	maxSamples int
+	mu         sync.Mutex
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{
+		path:       path,
+		maxSamples: DefaultMaxSamples,
+	}
+}
+
+// DefaultStorePath returns the default location of the usage state file
+func DefaultStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dockbridge", "state", "usage.json"), nil
+}
+
+// Load returns all stored samples; a missing file yields no samples
+func (s *Store) Load() ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return st.Samples, nil
+}
+
+// Append adds a sample, dropping the oldest ones beyond the retention limit
+func (s *Store) Append(sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	st.Samples = append(st.Samples, sample)
+	if len(st.Samples) > s.maxSamples {
+		st.Samples = st.Samples[len(st.Samples)-s.maxSamples:]
+	}
+
+	return s.write(st)
+}
+
+// read loads the state file
+func (s *Store) read() (*state, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return &state{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage state: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse usage state: %w", err)
+	}
+	return &st, nil
+}
+
+// write atomically replaces the state file
+func (s *Store) write(st *state) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create usage state directory: %w", err)
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write usage state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace usage state: %w", err)
+	}
+	return nil
+}
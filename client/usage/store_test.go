@@ -0,0 +1,43 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAppendAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state", "usage.json"))
+
+	samples, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, samples, "missing state file should yield no samples")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.Append(Sample{Time: now, ServerType: "cpx21", CPUPercent: 42}))
+
+	samples, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "cpx21", samples[0].ServerType)
+	assert.Equal(t, 42.0, samples[0].CPUPercent)
+	assert.True(t, now.Equal(samples[0].Time))
+}
+
+func TestStoreRetention(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "usage.json"))
+	store.maxSamples = 3
+
+	for i := range 5 {
+		require.NoError(t, store.Append(Sample{CPUCores: i}))
+	}
+
+	samples, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	assert.Equal(t, 2, samples[0].CPUCores, "oldest samples should be dropped first")
+	assert.Equal(t, 4, samples[2].CPUCores)
+}
@@ -5,12 +5,16 @@ import (
 	"os"
 
 	"github.com/dockbridge/dockbridge/client/cli"
+	"github.com/dockbridge/dockbridge/pkg/errors"
 )
 
 func main() {
 	// Execute the root command
 	if err := cli.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if hint := errors.Hint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+		os.Exit(errors.ExitCode(err))
 	}
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/readiness"
 	"github.com/dockbridge/dockbridge/server"
 	"github.com/dockbridge/dockbridge/shared/config"
 )
@@ -68,7 +71,9 @@ func main() {
 
 	fmt.Println("\n3. Waiting for server to be fully ready...")
 	fmt.Println("   (This includes Docker installation and volume mounting)")
-	time.Sleep(3 * time.Minute)
+	if err := waitForServer(ctx, server.IPAddress); err != nil {
+		fmt.Printf("⚠ Server not fully ready: %v\n", err)
+	}
 
 	fmt.Println("\n4. Checking server status...")
 	status, err := serverManager.GetServerStatus(ctx)
@@ -154,3 +159,41 @@ func main() {
 	fmt.Println("- Volume reuse when recreating servers")
 	fmt.Println("- Enhanced cloud-init script with robust volume management")
 }
+
+// waitForServer waits for SSH and then the keep-alive monitor, which starts at the end
+// of the server setup, printing each phase as it becomes ready
+func waitForServer(ctx context.Context, ip string) error {
+	cfg := readiness.DefaultConfig()
+	cfg.OnProgress = func(progress readiness.Progress) {
+		if progress.Status == readiness.StatusReady {
+			fmt.Printf("   ✓ %s ready after %s\n", progress.Phase, progress.Elapsed.Round(time.Second))
+		}
+	}
+
+	sshProbe := readiness.Probe{Phase: readiness.PhaseSSH, Check: func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, "22"))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}}
+
+	keepAliveProbe := readiness.Probe{Phase: readiness.PhaseKeepAlive, Check: func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/health", net.JoinHostPort(ip, "8080")), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("health check returned %s", resp.Status)
+		}
+		return nil
+	}}
+
+	return readiness.Wait(ctx, cfg, []readiness.Probe{sshProbe}, []readiness.Probe{keepAliveProbe})
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/dockbridge/dockbridge/pkg/version"
 	"github.com/dockbridge/dockbridge/server/keepalive"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,14 +34,36 @@ the configured timeout, the server self-destructs to avoid ongoing costs.
 The server exposes HTTP endpoints for:
   - /heartbeat (POST/PUT) - Record a heartbeat from the client
   - /status (GET) - Get current monitor status
+  - /postpone (POST) - Extend the timeout once, until the next heartbeat
   - /health (GET) - Simple health check
+  - /metrics (GET) - Prometheus metrics, when started with --metrics
+
+Heartbeat and status requests must be signed with the shared secret in
+DOCKBRIDGE_AUTH_TOKEN (or auth_token in the config file) when one is set.
+Postponing requires the secret.
+
+Before self-destructing the server warns logged-in users with wall and posts
+the warning as JSON to --warning-webhook, at each offset of --warn-before.
+It then stops running containers so their writes reach the persistent
+volume, commits containers labelled dockbridge.commit=<image> to that image
+and syncs the volume.
+
+The time of the last heartbeat is kept in --state-file, so that a restart
+of the server neither grants the client a new timeout nor restarts the
+grace period; /status reports how often it was restarted. The state only
+carries over within one boot of the same server, so a server powered on
+again or booted from a snapshot starts a fresh timeout.
 `,
-	Run: runServer,
+	Version: version.Version,
+	Run:     runServer,
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	// Clients parse this line to detect version skew
+	rootCmd.SetVersionTemplate("DockBridge Server v{{.Version}}\n")
+
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file path")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "enable verbose logging")
@@ -49,11 +73,33 @@ func init() {
 	rootCmd.Flags().Int("port", 8080, "HTTP port for keep-alive server")
 	rootCmd.Flags().Duration("timeout", 5*time.Minute, "timeout before self-destruction")
 	rootCmd.Flags().Duration("grace-period", 30*time.Second, "grace period before destruction")
+	rootCmd.Flags().Duration("max-sleep-hint", 15*time.Minute, "maximum extra timeout granted to clients announcing suspend (0 disables)")
+	rootCmd.Flags().Bool("metrics", false, "serve Prometheus metrics on /metrics")
+	rootCmd.Flags().Bool("loopback-only", false, "listen on 127.0.0.1 only, for heartbeats delivered through SSH")
+	rootCmd.Flags().String("warn-before", "2m,30s", "comma-separated times before self-destruction at which to warn (empty disables)")
+	rootCmd.Flags().String("warning-webhook", "", "URL warnings are posted to as JSON")
+	rootCmd.Flags().Duration("postpone-by", 15*time.Minute, "extra timeout granted once by /postpone (0 disables)")
+	rootCmd.Flags().Bool("preserve-state", true, "stop containers, commit labelled ones and sync the volume before self-destruction")
+	rootCmd.Flags().Duration("stop-timeout", 20*time.Second, "time containers get to exit when stopped before self-destruction")
+	rootCmd.Flags().String("commit-label", "dockbridge.commit", "label marking containers to commit, whose value names the image")
+	rootCmd.Flags().String("sync-path", "/var/lib/docker", "path on the persistent volume to sync before self-destruction")
+	rootCmd.Flags().String("state-file", "/var/lib/dockbridge/keepalive-state.json", "file keeping the heartbeat state across restarts (empty disables)")
 
 	// Bind flags to viper
 	viper.BindPFlag("port", rootCmd.Flags().Lookup("port"))
 	viper.BindPFlag("timeout", rootCmd.Flags().Lookup("timeout"))
 	viper.BindPFlag("grace_period", rootCmd.Flags().Lookup("grace-period"))
+	viper.BindPFlag("max_sleep_hint", rootCmd.Flags().Lookup("max-sleep-hint"))
+	viper.BindPFlag("metrics", rootCmd.Flags().Lookup("metrics"))
+	viper.BindPFlag("loopback_only", rootCmd.Flags().Lookup("loopback-only"))
+	viper.BindPFlag("warn_before", rootCmd.Flags().Lookup("warn-before"))
+	viper.BindPFlag("warning_webhook", rootCmd.Flags().Lookup("warning-webhook"))
+	viper.BindPFlag("postpone_by", rootCmd.Flags().Lookup("postpone-by"))
+	viper.BindPFlag("preserve_state", rootCmd.Flags().Lookup("preserve-state"))
+	viper.BindPFlag("stop_timeout", rootCmd.Flags().Lookup("stop-timeout"))
+	viper.BindPFlag("commit_label", rootCmd.Flags().Lookup("commit-label"))
+	viper.BindPFlag("sync_path", rootCmd.Flags().Lookup("sync-path"))
+	viper.BindPFlag("state_file", rootCmd.Flags().Lookup("state-file"))
 	viper.BindPFlag("server_id", rootCmd.PersistentFlags().Lookup("server-id"))
 }
 
@@ -98,11 +144,29 @@ func runServer(cmd *cobra.Command, args []string) {
 		"timeout", viper.GetDuration("timeout"),
 	)
 
+	warnBefore, err := parseDurations(viper.GetString("warn_before"))
+	if err != nil {
+		log.Error("Invalid warn-before", "error", err)
+		os.Exit(1)
+	}
+
 	// Create keep-alive config
 	config := &keepalive.Config{
 		Port:            viper.GetInt("port"),
 		Timeout:         viper.GetDuration("timeout"),
 		GracePeriod:     viper.GetDuration("grace_period"),
+		MaxSleepHint:    viper.GetDuration("max_sleep_hint"),
+		Metrics:         viper.GetBool("metrics"),
+		LoopbackOnly:    viper.GetBool("loopback_only"),
+		AuthToken:       viper.GetString("auth_token"),
+		WarnBefore:      warnBefore,
+		WarningWebhook:  viper.GetString("warning_webhook"),
+		PostponeBy:      viper.GetDuration("postpone_by"),
+		PreserveState:   viper.GetBool("preserve_state"),
+		StopTimeout:     viper.GetDuration("stop_timeout"),
+		CommitLabel:     viper.GetString("commit_label"),
+		SyncPath:        viper.GetString("sync_path"),
+		StateFile:       viper.GetString("state_file"),
 		ServerID:        serverID,
 		HetznerAPIToken: os.Getenv("HETZNER_API_TOKEN"),
 	}
@@ -151,6 +215,23 @@ func runServer(cmd *cobra.Command, args []string) {
 	log.Info("DockBridge server stopped")
 }
 
+// parseDurations parses a comma-separated list of durations
+func parseDurations(value string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
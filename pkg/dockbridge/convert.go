@@ -0,0 +1,49 @@
+package dockbridge
+
+import (
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+)
+
+// fromProtoServer converts a server of the control API; nil stays nil
+func fromProtoServer(srv *controlv1.Server) *Server {
+	if srv == nil {
+		return nil
+	}
+	return &Server{
+		ID:        srv.GetId(),
+		Name:      srv.GetName(),
+		Status:    srv.GetStatus(),
+		IPAddress: srv.GetIpAddress(),
+		CreatedAt: srv.GetCreatedAt().AsTime(),
+	}
+}
+
+// fromProtoForward converts a forward of the control API
+func fromProtoForward(forward *controlv1.Forward) Forward {
+	return Forward{
+		ID:               forward.GetId(),
+		Type:             forward.GetType(),
+		ContainerID:      forward.GetContainerId(),
+		ContainerName:    forward.GetContainerName(),
+		Project:          forward.GetProject(),
+		Service:          forward.GetService(),
+		LocalPort:        int(forward.GetLocalPort()),
+		RemotePort:       int(forward.GetRemotePort()),
+		LocalSocket:      forward.GetLocalSocket(),
+		RemoteSocket:     forward.GetRemoteSocket(),
+		BindAddress:      forward.GetBindAddress(),
+		Status:           forward.GetStatus(),
+		BytesTransferred: forward.GetBytesTransferred(),
+		CreatedAt:        forward.GetCreatedAt().AsTime(),
+	}
+}
+
+// fromProtoEvent converts a lifecycle event of the control API
+func fromProtoEvent(event *controlv1.Event) Event {
+	return Event{
+		Type:    event.GetType(),
+		Time:    event.GetTime().AsTime(),
+		Context: event.GetContext(),
+		Data:    event.GetData(),
+	}
+}
@@ -0,0 +1,220 @@
+// Package dockbridge is the supported Go API of DockBridge. It drives the running
+// client daemon through its control API, so tools such as IDE plugins and CI
+// orchestrators can provision servers, follow lifecycle events and reach the remote
+// Docker daemon without running the CLI.
+package dockbridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/control"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/localsocket"
+	controlv1 "github.com/dockbridge/dockbridge/shared/api/control/v1"
+	dockerclient "github.com/docker/docker/client"
+	"google.golang.org/grpc"
+)
+
+// Server is a remote server provisioned by DockBridge
+type Server struct {
+	ID        int64
+	Name      string
+	Status    string
+	IPAddress string
+	CreatedAt time.Time
+}
+
+// Status is the state of the daemon of a context
+type Status struct {
+	// Context is the name of the context; empty for the default context
+	Context string
+	// SocketPath is the local Docker socket or named pipe served by the daemon
+	SocketPath string
+	Running    bool
+	// Server is the server the daemon is connected to; nil when none is running
+	Server *Server
+}
+
+// Forward is a port or Unix socket forward of a container
+type Forward struct {
+	ID string
+	// Type is "tcp" or "unix"
+	Type          string
+	ContainerID   string
+	ContainerName string
+	// Project and Service name the docker compose service of the container
+	Project          string
+	Service          string
+	LocalPort        int
+	RemotePort       int
+	LocalSocket      string
+	RemoteSocket     string
+	BindAddress      string
+	Status           string
+	BytesTransferred int64
+	CreatedAt        time.Time
+}
+
+// Event is a lifecycle event, such as "server_provisioned", with the payload delivered
+// to hooks
+type Event struct {
+	Type    string
+	Time    time.Time
+	Context string
+	Data    map[string]string
+}
+
+// Client controls the DockBridge daemon of one context
+type Client struct {
+	api     controlv1.ControlServiceClient
+	conn    *grpc.ClientConn
+	context string
+}
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	socketPath string
+	context    string
+}
+
+// WithSocketPath connects to the control API on socketPath instead of the default
+// ~/.dockbridge/control.sock
+func WithSocketPath(socketPath string) Option {
+	return func(o *options) { o.socketPath = socketPath }
+}
+
+// WithContext controls the daemon of the named DockBridge context instead of the
+// default context
+func WithContext(name string) Option {
+	return func(o *options) { o.context = name }
+}
+
+// New returns a client of the running DockBridge daemon. The connection is made
+// lazily, so New succeeds even when the daemon is not running yet.
+func New(opts ...Option) (*Client, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.socketPath == "" {
+		var err error
+		if o.socketPath, err = control.DefaultSocketPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	api, conn, err := control.Dial(o.socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{api: api, conn: conn, context: o.context}, nil
+}
+
+// Close closes the connection to the daemon
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Status reports the daemon and its server
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	resp, err := c.api.GetStatus(ctx, &controlv1.GetStatusRequest{Context: c.context})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	if len(resp.GetContexts()) == 0 {
+		return nil, fmt.Errorf("daemon reported no status for context %q", c.context)
+	}
+
+	status := resp.GetContexts()[0]
+	return &Status{
+		Context:    status.GetName(),
+		SocketPath: status.GetSocketPath(),
+		Running:    status.GetRunning(),
+		Server:     fromProtoServer(status.GetServer()),
+	}, nil
+}
+
+// EnsureServer returns the running server, provisioning one first if none is running
+func (c *Client) EnsureServer(ctx context.Context) (*Server, error) {
+	resp, err := c.api.Provision(ctx, &controlv1.ProvisionRequest{Context: c.context})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure server: %w", err)
+	}
+	return fromProtoServer(resp.GetServer()), nil
+}
+
+// Destroy destroys the running server; the Docker data volume is kept
+func (c *Client) Destroy(ctx context.Context) error {
+	if _, err := c.api.Destroy(ctx, &controlv1.DestroyRequest{Context: c.context}); err != nil {
+		return fmt.Errorf("failed to destroy server: %w", err)
+	}
+	return nil
+}
+
+// PortForwards lists the active port and socket forwards
+func (c *Client) PortForwards(ctx context.Context) ([]Forward, error) {
+	resp, err := c.api.ListForwards(ctx, &controlv1.ListForwardsRequest{Context: c.context})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port forwards: %w", err)
+	}
+
+	forwards := make([]Forward, 0, len(resp.GetForwards()))
+	for _, forward := range resp.GetForwards() {
+		forwards = append(forwards, fromProtoForward(forward))
+	}
+	return forwards, nil
+}
+
+// Events streams lifecycle events of the given types, or of all types when none are
+// given. The channel is closed when ctx is canceled or the daemon stops.
+func (c *Client) Events(ctx context.Context, types ...string) (<-chan Event, error) {
+	for _, t := range types {
+		if !hooks.IsKnownEvent(t) {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+	}
+
+	stream, err := c.api.StreamEvents(ctx, &controlv1.StreamEventsRequest{Context: c.context, Types: types})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream events: %w", err)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case events <- fromProtoEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// DockerClient returns a Docker API client of the remote Docker daemon, connected
+// through the local socket of the DockBridge daemon. Callers close the client.
+func (c *Client) DockerClient(ctx context.Context) (*dockerclient.Client, error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docker, err := dockerclient.NewClientWithOpts(
+		dockerclient.WithHost(localsocket.DockerHost(status.SocketPath)),
+		dockerclient.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	return docker, nil
+}
@@ -0,0 +1,179 @@
+package dockbridge
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dockbridge/dockbridge/client/control"
+	"github.com/dockbridge/dockbridge/client/hooks"
+	"github.com/dockbridge/dockbridge/client/portforward"
+	"github.com/dockbridge/dockbridge/client/provider"
+	"github.com/dockbridge/dockbridge/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCreatedAt = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// fakeDaemon is an in-memory control.Daemon
+type fakeDaemon struct {
+	mu        sync.Mutex
+	name      string
+	server    *provider.Server
+	forwards  []*portforward.PortForward
+	listeners []hooks.Listener
+}
+
+func (d *fakeDaemon) ContextName() string { return d.name }
+func (d *fakeDaemon) SocketPath() string  { return "/tmp/" + d.name + "docker.sock" }
+func (d *fakeDaemon) IsRunning() bool     { return true }
+func (d *fakeDaemon) CurrentServer() *provider.Server {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.server
+}
+func (d *fakeDaemon) PortForwards() []*portforward.PortForward         { return d.forwards }
+func (d *fakeDaemon) CloseProjectForwards(project string) (int, error) { return 0, nil }
+func (d *fakeDaemon) AddForward(containerID string, localPort, remotePort int, protocol string) (*portforward.PortForward, error) {
+	return nil, errors.New("not supported")
+}
+func (d *fakeDaemon) RemoveForward(id string) error { return errors.New("not supported") }
+func (d *fakeDaemon) SubscribeEvents(listener hooks.Listener) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
+func (d *fakeDaemon) publish(event hooks.Event) {
+	d.mu.Lock()
+	listeners := append([]hooks.Listener(nil), d.listeners...)
+	d.mu.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func (d *fakeDaemon) Provision(ctx context.Context) (*provider.Server, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.server == nil {
+		d.server = &provider.Server{ID: 7, Name: "dockbridge-7", IPAddress: "10.0.0.7", Status: "running", CreatedAt: testCreatedAt}
+	}
+	return d.server, nil
+}
+
+func (d *fakeDaemon) Destroy(reason string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.server = nil
+	return nil
+}
+
+func startTestClient(t *testing.T, daemon *fakeDaemon, opts ...Option) *Client {
+	t.Helper()
+
+	server := control.NewServer([]control.Daemon{daemon}, logger.NewDefault())
+	socketPath := filepath.Join(t.TempDir(), "control.sock")
+	require.NoError(t, server.Start(socketPath))
+	t.Cleanup(server.Stop)
+
+	client, err := New(append([]Option{WithSocketPath(socketPath)}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestClientServerLifecycle(t *testing.T) {
+	daemon := &fakeDaemon{name: "ci"}
+	client := startTestClient(t, daemon, WithContext("ci"))
+	ctx := context.Background()
+
+	status, err := client.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", status.Context)
+	assert.True(t, status.Running)
+	assert.Nil(t, status.Server)
+
+	srv, err := client.EnsureServer(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Server{ID: 7, Name: "dockbridge-7", Status: "running", IPAddress: "10.0.0.7", CreatedAt: testCreatedAt}, srv)
+
+	// A running server is reused
+	again, err := client.EnsureServer(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, srv.ID, again.ID)
+
+	require.NoError(t, client.Destroy(ctx))
+	status, err = client.Status(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, status.Server)
+
+	docker, err := client.DockerClient(ctx)
+	require.NoError(t, err)
+	defer docker.Close()
+	assert.Equal(t, "unix:///tmp/cidocker.sock", docker.DaemonHost())
+}
+
+func TestClientUnknownContext(t *testing.T) {
+	client := startTestClient(t, &fakeDaemon{}, WithContext("missing"))
+
+	_, err := client.Status(context.Background())
+	assert.ErrorContains(t, err, `unknown context "missing"`)
+}
+
+func TestClientPortForwards(t *testing.T) {
+	daemon := &fakeDaemon{forwards: []*portforward.PortForward{{
+		ID:         "f1",
+		Type:       portforward.ForwardTypeTCP,
+		LocalPort:  8080,
+		RemotePort: 80,
+		Project:    "web",
+	}}}
+	client := startTestClient(t, daemon)
+
+	forwards, err := client.PortForwards(context.Background())
+	require.NoError(t, err)
+	require.Len(t, forwards, 1)
+	assert.Equal(t, "f1", forwards[0].ID)
+	assert.Equal(t, "tcp", forwards[0].Type)
+	assert.Equal(t, 8080, forwards[0].LocalPort)
+	assert.Equal(t, "web", forwards[0].Project)
+}
+
+func TestClientEvents(t *testing.T) {
+	daemon := &fakeDaemon{}
+	client := startTestClient(t, daemon)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := client.Events(ctx, "server_exploded")
+	assert.ErrorContains(t, err, "unknown event type")
+
+	events, err := client.Events(ctx, string(hooks.EventServerProvisioned))
+	require.NoError(t, err)
+
+	// Publish until the stream is subscribed on the daemon side
+	go func() {
+		for ctx.Err() == nil {
+			daemon.publish(hooks.Event{Type: hooks.EventForwardAdded})
+			daemon.publish(hooks.Event{Type: hooks.EventServerProvisioned, Data: map[string]string{"server_id": "7"}})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, string(hooks.EventServerProvisioned), event.Type)
+		assert.Equal(t, "7", event.Data["server_id"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,80 @@
+package errors
+
+// Exit codes of the CLI, so that scripts can tell failures apart. They are part of the
+// command-line interface: existing values must not change.
+const (
+	ExitOK              = 0
+	ExitFailure         = 1 // any error not covered below
+	ExitConfig          = 3
+	ExitMissingAPIToken = 4
+	ExitAuth            = 5
+	ExitQuotaExceeded   = 6
+	ExitRateLimited     = 7
+	ExitProvisioning    = 8
+	ExitTunnel          = 9
+	ExitDaemon          = 10
+	ExitNetwork         = 11
+)
+
+// exitCodesByCode take precedence over exitCodesByCategory
+var exitCodesByCode = map[string]int{
+	ErrCodeMissingAPIToken: ExitMissingAPIToken,
+	ErrCodeQuotaExceeded:   ExitQuotaExceeded,
+	ErrCodeRateLimited:     ExitRateLimited,
+	ErrCodeUnauthorized:    ExitAuth,
+	ErrCodeForbidden:       ExitAuth,
+}
+
+var exitCodesByCategory = map[ErrorCategory]int{
+	ErrCategoryConfig:       ExitConfig,
+	ErrCategoryAuth:         ExitAuth,
+	ErrCategoryQuota:        ExitQuotaExceeded,
+	ErrCategoryProvisioning: ExitProvisioning,
+	ErrCategoryTunnel:       ExitTunnel,
+	ErrCategorySSH:          ExitTunnel,
+	ErrCategoryDaemon:       ExitDaemon,
+	ErrCategoryNetwork:      ExitNetwork,
+}
+
+// ExitCode returns the CLI exit code of err. The most specific DockBridgeError in the
+// chain decides, so a quota error wrapped as a provisioning error exits as
+// ExitQuotaExceeded.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, dockErr := range chain(err) {
+		if code, ok := exitCodesByCode[dockErr.Code]; ok {
+			return code
+		}
+		if code, ok := exitCodesByCategory[dockErr.Category]; ok {
+			return code
+		}
+	}
+	return ExitFailure
+}
+
+// Hint returns the remediation hint of the most specific DockBridgeError in the chain
+// of err that has one, or ""
+func Hint(err error) string {
+	for _, dockErr := range chain(err) {
+		if dockErr.Hint != "" {
+			return dockErr.Hint
+		}
+	}
+	return ""
+}
+
+// chain returns the DockBridgeErrors wrapped by err, the innermost first
+func chain(err error) []*DockBridgeError {
+	var errs []*DockBridgeError
+	for err != nil {
+		var dockErr *DockBridgeError
+		if !As(err, &dockErr) {
+			break
+		}
+		errs = append([]*DockBridgeError{dockErr}, errs...)
+		err = dockErr.Cause
+	}
+	return errs
+}
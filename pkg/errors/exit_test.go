@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	missingToken := NewConfigError(ErrCodeMissingAPIToken, "Hetzner API token is missing", nil)
+	quota := NewQuotaExceededError("Hetzner server limit reached", errors.New("resource_limit_exceeded"))
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain error", errors.New("boom"), ExitFailure},
+		{"config", NewConfigError(ErrCodeInvalidConfig, "Invalid config", nil), ExitConfig},
+		{"missing API token", missingToken, ExitMissingAPIToken},
+		{"missing API token wrapped as config error",
+			NewConfigError(ErrCodeInvalidConfig, "Failed to load configuration", fmt.Errorf("validation failed: %w", missingToken)),
+			ExitMissingAPIToken},
+		{"quota wrapped as provisioning error",
+			NewProvisioningError(ErrCodeProvisionFailed, "Failed to provision server", quota, false),
+			ExitQuotaExceeded},
+		{"rate limited", NewRateLimitError("Rate limit exceeded", nil), ExitRateLimited},
+		{"tunnel", NewTunnelError(ErrCodeTunnelFailed, "Tunnel failed", nil), ExitTunnel},
+		{"daemon", NewDaemonError(ErrCodeDaemonNotRunning, "Daemon not running", nil), ExitDaemon},
+		{"unmapped category falls back to outer error",
+			NewNetworkError("API_ERROR", "Failed to list servers", NewError(ErrCategoryDocker, "X", "x", nil, false), true),
+			ExitNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestHint(t *testing.T) {
+	inner := NewQuotaExceededError("Server limit reached", nil).WithHint("Request a limit increase")
+	outer := NewProvisioningError(ErrCodeProvisionFailed, "Failed to provision server", inner, false).WithHint("Retry later")
+
+	assert.Equal(t, "Request a limit increase", Hint(outer))
+	assert.Equal(t, "Retry later", Hint(NewProvisioningError(ErrCodeProvisionFailed, "Failed", errors.New("x"), false).WithHint("Retry later")))
+	assert.Empty(t, Hint(errors.New("plain")))
+
+	// Wrap keeps the hint
+	assert.Equal(t, "Request a limit increase", Hint(Wrap(inner, "context")))
+}
@@ -19,6 +19,11 @@ const (
 	ErrCategoryInternal   ErrorCategory = "internal"
 	ErrCategoryResource   ErrorCategory = "resource"
 	ErrCategoryAuth       ErrorCategory = "auth"
+
+	ErrCategoryProvisioning ErrorCategory = "provisioning"
+	ErrCategoryTunnel       ErrorCategory = "tunnel"
+	ErrCategoryDaemon       ErrorCategory = "daemon"
+	ErrCategoryQuota        ErrorCategory = "quota"
 )
 
 // Common error codes
@@ -29,8 +34,9 @@ const (
 	ErrCodeDNSFailure     = "DNS_FAILURE"
 
 	// Config error codes
-	ErrCodeInvalidConfig = "INVALID_CONFIG"
-	ErrCodeMissingConfig = "MISSING_CONFIG"
+	ErrCodeInvalidConfig   = "INVALID_CONFIG"
+	ErrCodeMissingConfig   = "MISSING_CONFIG"
+	ErrCodeMissingAPIToken = "MISSING_API_TOKEN"
 
 	// Resource error codes
 	ErrCodeNotFound      = "NOT_FOUND"
@@ -41,6 +47,23 @@ const (
 	ErrCodeUnauthorized = "UNAUTHORIZED"
 	ErrCodeForbidden    = "FORBIDDEN"
 
+	// Provisioning error codes
+	ErrCodeProvisionFailed = "PROVISION_FAILED"
+	ErrCodeServerNotReady  = "SERVER_NOT_READY"
+
+	// Quota error codes
+	ErrCodeQuotaExceeded = "QUOTA_EXCEEDED"
+	ErrCodeRateLimited   = "RATE_LIMITED"
+
+	// Tunnel error codes
+	ErrCodeSSHConnect   = "SSH_CONNECT_FAILED"
+	ErrCodeTunnelFailed = "TUNNEL_FAILED"
+
+	// Daemon error codes
+	ErrCodeDaemonNotRunning  = "DAEMON_NOT_RUNNING"
+	ErrCodeDaemonStartFailed = "DAEMON_START_FAILED"
+	ErrCodeDaemonStopFailed  = "DAEMON_STOP_FAILED"
+
 	// Internal error codes
 	ErrCodeInternal = "INTERNAL_ERROR"
 )
@@ -53,6 +76,9 @@ type DockBridgeError struct {
 	Cause     error         `json:"cause,omitempty"`
 	Retryable bool          `json:"retryable"`
 	Timestamp time.Time     `json:"timestamp"`
+
+	// Hint tells the user how to remedy the error
+	Hint string `json:"hint,omitempty"`
 }
 
 // Error implements the error interface
@@ -73,6 +99,12 @@ func (e *DockBridgeError) IsRetryable() bool {
 	return e.Retryable
 }
 
+// WithHint sets the remediation hint shown to the user and returns the error
+func (e *DockBridgeError) WithHint(hint string) *DockBridgeError {
+	e.Hint = hint
+	return e
+}
+
 // NewError creates a new DockBridgeError
 func NewError(category ErrorCategory, code, message string, cause error, retryable bool) *DockBridgeError {
 	return &DockBridgeError{
@@ -117,6 +149,34 @@ func NewNotFoundError(message string, cause error) *DockBridgeError {
 	return NewError(ErrCategoryResource, ErrCodeNotFound, message, cause, false)
 }
 
+// NewProvisioningError creates a new error of creating or starting a server
+func NewProvisioningError(code, message string, cause error, retryable bool) *DockBridgeError {
+	return NewError(ErrCategoryProvisioning, code, message, cause, retryable)
+}
+
+// NewQuotaExceededError creates a new error of a cloud project limit being reached (not
+// retryable until the limit is raised or resources are freed)
+func NewQuotaExceededError(message string, cause error) *DockBridgeError {
+	return NewError(ErrCategoryQuota, ErrCodeQuotaExceeded, message, cause, false)
+}
+
+// NewRateLimitError creates a new error of the cloud API rate limit being exceeded
+// (always retryable)
+func NewRateLimitError(message string, cause error) *DockBridgeError {
+	return NewError(ErrCategoryQuota, ErrCodeRateLimited, message, cause, true)
+}
+
+// NewTunnelError creates a new error of the SSH connection or tunnel to the server
+// (always retryable)
+func NewTunnelError(code, message string, cause error) *DockBridgeError {
+	return NewError(ErrCategoryTunnel, code, message, cause, true)
+}
+
+// NewDaemonError creates a new error of reaching the local DockBridge daemon
+func NewDaemonError(code, message string, cause error) *DockBridgeError {
+	return NewError(ErrCategoryDaemon, code, message, cause, false)
+}
+
 // NewInternalError creates a new internal error (not retryable by default)
 func NewInternalError(message string, cause error) *DockBridgeError {
 	return NewError(ErrCategoryInternal, ErrCodeInternal, message, cause, false)
@@ -136,7 +196,7 @@ func Wrap(err error, message string) error {
 			fmt.Sprintf("%s: %s", message, dockErr.Message),
 			dockErr.Cause,
 			dockErr.Retryable,
-		)
+		).WithHint(dockErr.Hint)
 	}
 
 	return fmt.Errorf("%s: %w", message, err)
@@ -46,6 +46,11 @@ var levelNames = map[Level]string{
 	Fatal: "FATAL",
 }
 
+// String returns the name of the level as written to the log, e.g. "INFO"
+func (l Level) String() string {
+	return levelNames[l]
+}
+
 var levelColors = map[Level]*color.Color{
 	Debug: color.New(color.FgCyan),
 	Info:  color.New(color.FgGreen),
@@ -164,13 +169,14 @@ func (l *Logger) WithFields(fields map[string]any) *Logger {
 
 // log logs a message at the specified level
 func (l *Logger) log(level Level, msg string, args ...any) {
+	// The level may be changed while logging, e.g. on a configuration reload
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if level < l.level {
 		return
 	}
 
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
 	// Format the message if args are provided
 	if len(args) > 0 {
 		msg = fmt.Sprintf(msg, args...)
@@ -0,0 +1,78 @@
+// Package version holds the DockBridge release version, shown by the CLI and recorded
+// on the cloud resources DockBridge creates.
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is the DockBridge release version, without the leading "v" of its tag.
+// Release builds set it with
+// -ldflags "-X github.com/dockbridge/dockbridge/pkg/version.Version=1.2.3".
+var Version = "0.1.0"
+
+// Tag returns the release tag of Version, e.g. v1.2.3
+func Tag() string {
+	return "v" + strings.TrimPrefix(Version, "v")
+}
+
+// Compare compares two versions such as 1.2.3 or v1.3.0-rc.1 and returns -1, 0 or +1.
+// A pre-release sorts before its release, and pre-releases compare as strings.
+// Versions that do not parse sort before those that do.
+func Compare(a, b string) int {
+	va, okA := parse(a)
+	vb, okB := parse(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(a, b)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := range va.numbers {
+		if va.numbers[i] != vb.numbers[i] {
+			if va.numbers[i] < vb.numbers[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	}
+	return strings.Compare(va.pre, vb.pre)
+}
+
+// semver is a parsed major.minor.patch[-pre] version; build metadata is ignored
+type semver struct {
+	numbers [3]int
+	pre     string
+}
+
+func parse(value string) (semver, bool) {
+	var v semver
+	value = strings.TrimPrefix(value, "v")
+	value, _, _ = strings.Cut(value, "+")
+	value, v.pre, _ = strings.Cut(value, "-")
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
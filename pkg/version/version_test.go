@@ -0,0 +1,36 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTag(t *testing.T) {
+	saved := Version
+	defer func() { Version = saved }()
+
+	Version = "1.2.3"
+	assert.Equal(t, "v1.2.3", Tag())
+	Version = "v1.2.3"
+	assert.Equal(t, "v1.2.3", Tag())
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "v1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.3.0-rc.1", "1.3.0", -1},
+		{"1.3.0-rc.2", "1.3.0-rc.1", 1},
+		{"1.3.0+dirty", "1.3.0", 0},
+		{"dev", "0.0.1", -1},
+		{"0.0.1", "dev", 1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Compare(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
@@ -1,6 +1,10 @@
 package server
 
-import "context"
+import (
+	"context"
+
+	"github.com/dockbridge/dockbridge/client/provider"
+)
 
 // ServerManager defines the interface for server lifecycle management with enhanced volume support
 type ServerManager interface {
@@ -10,6 +14,9 @@ type ServerManager interface {
 	// DestroyServer destroys a server while preserving the volume for future use
 	DestroyServer(ctx context.Context, serverID string) error
 
+	// StopServer powers a server off, keeping its disk and volume attached for a fast resume
+	StopServer(ctx context.Context, serverID string) error
+
 	// GetServerStatus retrieves the current status of the active server
 	GetServerStatus(ctx context.Context) (*ServerStatus, error)
 
@@ -18,4 +25,8 @@ type ServerManager interface {
 
 	// EnsureVolume ensures a Docker data volume exists and is available
 	EnsureVolume(ctx context.Context) (*VolumeInfo, error)
+
+	// SetProvisioningObserver sets the observer notified of EnsureServer's provisioning
+	// phases; nil disables progress reporting
+	SetProvisioningObserver(observer provider.ProvisioningObserver)
 }
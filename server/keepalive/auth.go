@@ -0,0 +1,113 @@
+package keepalive
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimestampHeader carries the Unix time in nanoseconds at which a request was signed.
+	TimestampHeader = "X-DockBridge-Timestamp"
+
+	// SignatureHeader carries the hex HMAC-SHA256 signature of a request.
+	SignatureHeader = "X-DockBridge-Signature"
+
+	// MaxClockSkew is how far the timestamp of a signed request may differ from the
+	// server's clock.
+	MaxClockSkew = 5 * time.Minute
+
+	// maxSignedBody limits the body read to verify a signature.
+	maxSignedBody = 64 << 10
+)
+
+// GenerateToken returns a random shared secret for signing keep-alive requests.
+func GenerateToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate keep-alive token: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the signature of a request: the HMAC-SHA256, keyed with token, of its
+// method, path, timestamp and the SHA-256 of its body.
+func Sign(token, method, path string, timestamp int64, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(token))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%x", method, path, timestamp, bodyHash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest adds the timestamp and signature headers to req.
+func signRequest(req *http.Request, token string, body []byte) {
+	timestamp := time.Now().UnixNano()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(token, req.Method, req.URL.Path, timestamp, body))
+}
+
+// authenticate rejects requests that are not signed with the configured token. Each
+// signature is accepted once within the clock skew window, so captured requests cannot
+// be replayed. Without a token all requests are accepted.
+func (m *Monitor) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if m.config.AuthToken == "" {
+			next(w, r)
+			return
+		}
+
+		if err := m.verifyRequest(r); err != nil {
+			m.logger.Warn("Rejected unauthenticated request", "path", r.URL.Path, "remote", r.RemoteAddr, "error", err)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// verifyRequest checks the signature of r and restores its body for the handler.
+func (m *Monitor) verifyRequest(r *http.Request) error {
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid timestamp")
+	}
+	skew := time.Since(time.Unix(0, timestamp))
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		return fmt.Errorf("timestamp outside the allowed clock skew")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody))
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected := Sign(m.config.AuthToken, r.Method, r.URL.Path, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader))) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.seenSignatures == nil {
+		m.seenSignatures = make(map[string]time.Time)
+	}
+	// Signatures older than the skew window are rejected by their timestamp anyway
+	for signature, signedAt := range m.seenSignatures {
+		if time.Since(signedAt) > 2*MaxClockSkew {
+			delete(m.seenSignatures, signature)
+		}
+	}
+	if _, seen := m.seenSignatures[expected]; seen {
+		return fmt.Errorf("replayed request")
+	}
+	m.seenSignatures[expected] = time.Unix(0, timestamp)
+	return nil
+}
@@ -0,0 +1,149 @@
+package keepalive
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultClientID identifies heartbeats that carry no client ID, e.g. from clients
+// predating client IDs.
+const DefaultClientID = "default"
+
+const (
+	// maxClientIDLength bounds client IDs
+	maxClientIDLength = 255
+
+	// maxClients bounds the clients tracked; beyond it the client heard from least
+	// recently is forgotten
+	maxClients = 64
+)
+
+// clientHeartbeat is the last heartbeat of one client.
+type clientHeartbeat struct {
+	LastHeartbeat time.Time     `json:"last_heartbeat"`
+	SleepHint     time.Duration `json:"sleep_hint"`
+}
+
+// deadline returns when the client's heartbeats time out, before any postponement.
+func (c clientHeartbeat) deadline(timeout time.Duration) time.Time {
+	return c.LastHeartbeat.Add(timeout + c.SleepHint)
+}
+
+// ClientStatus is the heartbeat state of one client in the /status response.
+type ClientStatus struct {
+	ClientID           string `json:"client_id"`
+	LastHeartbeat      string `json:"last_heartbeat"`
+	TimeSinceHeartbeat string `json:"time_since_heartbeat"`
+	SleepHint          string `json:"sleep_hint"`
+	IsTimedOut         bool   `json:"is_timed_out"`
+}
+
+// validClientID reports whether id can identify a client: printable ASCII without
+// spaces, so that it cannot garble logs.
+func validClientID(id string) bool {
+	if len(id) > maxClientIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeClientID makes id, e.g. a host name, a valid client ID: characters other than
+// printable ASCII become '-' and the ID is cut to its maximum length. An ID with nothing
+// usable left is DefaultClientID.
+func SanitizeClientID(id string) string {
+	id = strings.Map(func(c rune) rune {
+		if c <= ' ' || c > '~' {
+			return '-'
+		}
+		return c
+	}, id)
+	if len(id) > maxClientIDLength {
+		id = id[:maxClientIDLength]
+	}
+	if strings.Trim(id, "-") == "" {
+		return DefaultClientID
+	}
+	return id
+}
+
+// recordClient records a heartbeat of clientID and makes the client whose heartbeats
+// time out last decide the monitor's timeout, so that the server only destroys itself
+// once every client has timed out. m.mu must be held.
+func (m *Monitor) recordClient(clientID string, at time.Time, sleepHint time.Duration) {
+	if clientID == "" {
+		clientID = DefaultClientID
+	}
+	if _, known := m.clients[clientID]; !known {
+		if len(m.clients) >= maxClients {
+			delete(m.clients, m.leastRecentClient())
+		}
+		m.logger.Info("New keep-alive client", "client_id", clientID, "clients", len(m.clients)+1)
+	}
+	m.clients[clientID] = clientHeartbeat{LastHeartbeat: at, SleepHint: sleepHint}
+	m.updateDeadline()
+}
+
+// updateDeadline sets the last heartbeat and sleep hint to those of the client whose
+// heartbeats time out last. m.mu must be held.
+func (m *Monitor) updateDeadline() {
+	if len(m.clients) == 0 {
+		return
+	}
+	var latest clientHeartbeat
+	for _, client := range m.clients {
+		if client.deadline(m.config.Timeout).After(latest.deadline(m.config.Timeout)) {
+			latest = client
+		}
+	}
+	m.lastHeartbeat = latest.LastHeartbeat
+	m.sleepHint = latest.SleepHint
+}
+
+// leastRecentClient returns the client heard from least recently. m.mu must be held.
+func (m *Monitor) leastRecentClient() string {
+	var oldest string
+	for id, client := range m.clients {
+		if oldest == "" || client.LastHeartbeat.Before(m.clients[oldest].LastHeartbeat) {
+			oldest = id
+		}
+	}
+	return oldest
+}
+
+// GetClients returns the heartbeat state of every client heard from, ordered by ID.
+func (m *Monitor) GetClients() []ClientStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]ClientStatus, 0, len(m.clients))
+	for _, id := range slices.Sorted(maps.Keys(m.clients)) {
+		client := m.clients[id]
+		statuses = append(statuses, ClientStatus{
+			ClientID:           id,
+			LastHeartbeat:      client.LastHeartbeat.Format(time.RFC3339),
+			TimeSinceHeartbeat: now.Sub(client.LastHeartbeat).String(),
+			SleepHint:          client.SleepHint.String(),
+			IsTimedOut:         now.After(client.deadline(m.config.Timeout)),
+		})
+	}
+	return statuses
+}
+
+// activeClients returns how many clients have not timed out.
+func (m *Monitor) activeClients() int {
+	active := 0
+	for _, client := range m.GetClients() {
+		if !client.IsTimedOut {
+			active++
+		}
+	}
+	return active
+}
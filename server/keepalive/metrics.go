@@ -0,0 +1,85 @@
+package keepalive
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// monitorMetrics are the Prometheus metrics served on /metrics when Config.Metrics is set.
+type monitorMetrics struct {
+	registry   *prometheus.Registry
+	heartbeats prometheus.Counter
+	warnings   prometheus.Counter
+}
+
+// newMonitorMetrics creates the metrics of m; gauges are computed on every scrape.
+func newMonitorMetrics(m *Monitor) *monitorMetrics {
+	metrics := &monitorMetrics{
+		registry: prometheus.NewRegistry(),
+		heartbeats: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "heartbeats_total",
+			Help:      "Heartbeats received from clients.",
+		}),
+		warnings: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "warnings_total",
+			Help:      "Warnings sent before self-destruction.",
+		}),
+	}
+
+	metrics.registry.MustRegister(
+		metrics.heartbeats,
+		metrics.warnings,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "seconds_since_heartbeat",
+			Help:      "Seconds since the last heartbeat.",
+		}, func() float64 {
+			return m.GetTimeSinceLastHeartbeat().Seconds()
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "seconds_until_self_destruct",
+			Help:      "Seconds until the server destroys itself without another heartbeat, including the grace period.",
+		}, func() float64 {
+			return max(m.timeUntilSelfDestruct().Seconds(), 0)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "active_clients",
+			Help:      "Clients whose heartbeats have not timed out.",
+		}, func() float64 {
+			return float64(m.activeClients())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "restarts",
+			Help:      "Restarts of the monitor that kept the heartbeat state of an earlier run.",
+		}, func() float64 {
+			return float64(m.GetRestarts())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "dockbridge",
+			Subsystem: "keepalive",
+			Name:      "timeout_seconds",
+			Help:      "Heartbeat timeout, including the sleep hint of the last heartbeat.",
+		}, func() float64 {
+			return m.effectiveTimeout().Seconds()
+		}),
+	)
+	return metrics
+}
+
+// handler serves the metrics in the Prometheus exposition format.
+func (mm *monitorMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(mm.registry, promhttp.HandlerOpts{})
+}
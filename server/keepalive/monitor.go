@@ -4,11 +4,15 @@
 package keepalive
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -33,28 +37,108 @@ type Config struct {
 
 	// HetznerAPIToken is the API token for Hetzner Cloud operations.
 	HetznerAPIToken string `json:"hetzner_api_token" yaml:"hetzner_api_token"`
+
+	// MaxSleepHint caps the extra time granted when a client announces that it
+	// is about to suspend. Zero disables sleep hints.
+	MaxSleepHint time.Duration `json:"max_sleep_hint" yaml:"max_sleep_hint"`
+
+	// Metrics serves Prometheus metrics on /metrics.
+	Metrics bool `json:"metrics" yaml:"metrics"`
+
+	// LoopbackOnly listens on the loopback interface only, for clients that deliver
+	// heartbeats through their SSH connection.
+	LoopbackOnly bool `json:"loopback_only" yaml:"loopback_only"`
+
+	// AuthToken is the shared secret heartbeat and status requests must be signed
+	// with. Empty accepts unauthenticated requests.
+	AuthToken string `json:"auth_token" yaml:"auth_token"`
+
+	// WarnBefore lists how long before self-destruction warnings are sent, e.g. 2m
+	// and 30s. Each warning is written to logged-in users with wall and posted to
+	// WarningWebhook.
+	WarnBefore []time.Duration `json:"warn_before" yaml:"warn_before"`
+
+	// WarningWebhook is the URL warnings are posted to as JSON (optional).
+	WarningWebhook string `json:"warning_webhook" yaml:"warning_webhook"`
+
+	// PreserveState stops running containers, commits the ones labelled with
+	// CommitLabel and syncs SyncPath before the server is deleted.
+	PreserveState bool `json:"preserve_state" yaml:"preserve_state"`
+
+	// StopTimeout is how long containers get to exit when stopped.
+	StopTimeout time.Duration `json:"stop_timeout" yaml:"stop_timeout"`
+
+	// CommitLabel marks containers to commit; the label's value names the image.
+	CommitLabel string `json:"commit_label" yaml:"commit_label"`
+
+	// SyncPath is a path on the persistent volume whose filesystem is synced.
+	SyncPath string `json:"sync_path" yaml:"sync_path"`
+
+	// PostponeBy is the extra timeout granted once per missed-heartbeat period by a
+	// signed POST to /postpone. Zero, or no AuthToken, disables postponing.
+	PostponeBy time.Duration `json:"postpone_by" yaml:"postpone_by"`
+
+	// StateFile keeps the heartbeat state across restarts of the monitor, so that a
+	// restart does not extend the life of a server whose client is gone. Empty keeps
+	// the state in memory only.
+	StateFile string `json:"state_file" yaml:"state_file"`
 }
 
 // DefaultConfig returns the default keep-alive configuration.
 func DefaultConfig() *Config {
 	return &Config{
-		Port:        8080,
-		Timeout:     5 * time.Minute,
-		GracePeriod: 30 * time.Second,
+		Port:         8080,
+		Timeout:      5 * time.Minute,
+		GracePeriod:  30 * time.Second,
+		MaxSleepHint: 15 * time.Minute,
+		WarnBefore:   []time.Duration{2 * time.Minute, 30 * time.Second},
+		PostponeBy:   15 * time.Minute,
+		StopTimeout:  20 * time.Second,
+		CommitLabel:  "dockbridge.commit",
+		SyncPath:     "/var/lib/docker",
 	}
 }
 
+// HeartbeatRequest is the optional JSON body of a heartbeat.
+type HeartbeatRequest struct {
+	// ExpectedSleep is sent by a client about to suspend (e.g. "10m"). The monitor
+	// extends the timeout for this heartbeat by up to Config.MaxSleepHint.
+	ExpectedSleep string `json:"expected_sleep,omitempty"`
+
+	// ClientID identifies the client among several keeping the server alive, e.g. a
+	// laptop and a CI runner. The server only destroys itself once all of them timed
+	// out. Empty is DefaultClientID.
+	ClientID string `json:"client_id,omitempty"`
+}
+
 // Monitor handles keep-alive heartbeat monitoring and server self-destruction.
 type Monitor struct {
 	config        *Config
 	logger        logger.LoggerInterface
-	lastHeartbeat time.Time
+	clients       map[string]clientHeartbeat // last heartbeat of each client by ID
+	lastHeartbeat time.Time                  // last heartbeat of the client timing out last
+	sleepHint     time.Duration              // extra timeout granted by that heartbeat
+	postponement  time.Duration              // extra timeout granted by /postpone since the last heartbeat
+	warningsSent  int                        // warnings of the sequence sent since the last heartbeat or postponement
+	graceStarted  time.Time                  // start of the grace period before self-destruction, zero outside it
+	restarts      int                        // starts that restored the state of an earlier run
 	mu            sync.RWMutex
+	stateMu       sync.Mutex // serializes writes of Config.StateFile
 	server        *http.Server
 	ctx           context.Context
 	cancel        context.CancelFunc
 	running       bool
 	shutdownCh    chan struct{}
+	metrics       *monitorMetrics
+	// seenSignatures holds the signatures accepted within the clock skew window, against replays
+	seenSignatures map[string]time.Time
+	// broadcast writes a warning to logged-in users
+	broadcast func(message string) error
+	// run runs a command on the server, for state preservation
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+	// bootID identifies the current boot of the server, so that saved state is only
+	// restored after a restart within the same boot
+	bootID func() string
 }
 
 // NewMonitor creates a new keep-alive monitor.
@@ -66,12 +150,18 @@ func NewMonitor(config *Config, log logger.LoggerInterface) *Monitor {
 		log = logger.NewDefault()
 	}
 
-	return &Monitor{
+	m := &Monitor{
 		config:        config,
 		logger:        log,
+		clients:       make(map[string]clientHeartbeat),
 		lastHeartbeat: time.Now(),
 		shutdownCh:    make(chan struct{}),
+		broadcast:     wallMessage,
+		run:           runCommand,
+		bootID:        readBootID,
 	}
+	m.metrics = newMonitorMetrics(m)
+	return m
 }
 
 // Start begins the keep-alive monitoring service.
@@ -83,17 +173,32 @@ func (m *Monitor) Start(ctx context.Context) error {
 	}
 	m.running = true
 	m.ctx, m.cancel = context.WithCancel(ctx)
-	m.lastHeartbeat = time.Now()
 	m.mu.Unlock()
 
+	// A restart continues from the saved heartbeat time rather than granting a new timeout
+	if !m.restoreState() {
+		m.mu.Lock()
+		m.lastHeartbeat = time.Now()
+		m.mu.Unlock()
+	}
+	m.saveState()
+
 	// Setup HTTP server for heartbeat endpoint
 	mux := http.NewServeMux()
-	mux.HandleFunc("/heartbeat", m.handleHeartbeat)
-	mux.HandleFunc("/status", m.handleStatus)
+	mux.HandleFunc("/heartbeat", m.authenticate(m.handleHeartbeat))
+	mux.HandleFunc("/status", m.authenticate(m.handleStatus))
+	mux.HandleFunc("/postpone", m.authenticate(m.handlePostpone))
 	mux.HandleFunc("/health", m.handleHealth)
+	if m.config.Metrics {
+		mux.Handle("/metrics", m.metrics.handler())
+	}
 
+	host := ""
+	if m.config.LoopbackOnly {
+		host = "127.0.0.1"
+	}
 	m.server = &http.Server{
-		Addr:         fmt.Sprintf(":%d", m.config.Port),
+		Addr:         net.JoinHostPort(host, strconv.Itoa(m.config.Port)),
 		Handler:      mux,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -114,7 +219,13 @@ func (m *Monitor) Start(ctx context.Context) error {
 		"port", m.config.Port,
 		"timeout", m.config.Timeout,
 		"grace_period", m.config.GracePeriod,
+		"authenticated", m.config.AuthToken != "",
+		"loopback_only", m.config.LoopbackOnly,
+		"restarts", m.GetRestarts(),
 	)
+	if m.config.AuthToken == "" {
+		m.logger.Warn("No auth token configured - anyone reaching the port can send heartbeats")
+	}
 
 	return nil
 }
@@ -151,10 +262,58 @@ func (m *Monitor) Stop() error {
 
 // RecordHeartbeat records a heartbeat from the client.
 func (m *Monitor) RecordHeartbeat() {
+	m.RecordHeartbeatWithSleepHint(0)
+}
+
+// RecordHeartbeatWithSleepHint records a heartbeat from a client that expects to
+// be suspended for up to expectedSleep. The hint is capped by Config.MaxSleepHint
+// and only applies until the next heartbeat.
+func (m *Monitor) RecordHeartbeatWithSleepHint(expectedSleep time.Duration) {
+	m.RecordClientHeartbeat(DefaultClientID, expectedSleep)
+}
+
+// RecordClientHeartbeat records a heartbeat from the client clientID, which expects
+// to be suspended for up to expectedSleep. Each client's heartbeats time out on their
+// own; the server only destroys itself once all clients timed out.
+func (m *Monitor) RecordClientHeartbeat(clientID string, expectedSleep time.Duration) {
+	m.metrics.heartbeats.Inc()
+	defer m.saveState()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.lastHeartbeat = time.Now()
-	m.logger.Debug("Heartbeat recorded", "time", m.lastHeartbeat)
+	now := time.Now()
+	sleepHint := min(max(expectedSleep, 0), m.config.MaxSleepHint)
+	m.recordClient(clientID, now, sleepHint)
+	if m.warningsSent > 0 {
+		m.logger.Info("Heartbeat resumed, self-destruction averted", "client_id", clientID)
+	}
+	m.postponement = 0
+	m.warningsSent = 0
+	m.graceStarted = time.Time{}
+	if sleepHint > 0 {
+		m.logger.Info("Heartbeat recorded with sleep hint", "client_id", clientID, "time", now, "sleep_hint", sleepHint)
+		return
+	}
+	m.logger.Debug("Heartbeat recorded", "client_id", clientID, "time", now)
+}
+
+// GetSleepHint returns the extra timeout granted by the last heartbeat.
+func (m *Monitor) GetSleepHint() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sleepHint
+}
+
+// effectiveTimeout returns the configured timeout plus any active sleep hint and
+// postponement.
+func (m *Monitor) effectiveTimeout() time.Duration {
+	return m.config.Timeout + m.GetSleepHint() + m.getPostponement()
+}
+
+// timeUntilSelfDestruct returns how long until the server destroys itself without
+// another heartbeat; it is negative once the grace period has passed.
+func (m *Monitor) timeUntilSelfDestruct() time.Duration {
+	return m.effectiveTimeout() + m.config.GracePeriod - m.GetTimeSinceLastHeartbeat()
 }
 
 // GetLastHeartbeat returns the timestamp of the last heartbeat.
@@ -173,7 +332,7 @@ func (m *Monitor) GetTimeSinceLastHeartbeat() time.Duration {
 
 // IsTimedOut returns true if the timeout has been exceeded.
 func (m *Monitor) IsTimedOut() bool {
-	return m.GetTimeSinceLastHeartbeat() > m.config.Timeout
+	return m.GetTimeSinceLastHeartbeat() > m.effectiveTimeout()
 }
 
 // handleHeartbeat processes incoming heartbeat requests.
@@ -183,12 +342,39 @@ func (m *Monitor) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	m.RecordHeartbeat()
+	var expectedSleep time.Duration
+	clientID := DefaultClientID
+	if r.ContentLength != 0 {
+		var req HeartbeatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid heartbeat body", http.StatusBadRequest)
+			return
+		}
+		if req.ClientID != "" {
+			if !validClientID(req.ClientID) {
+				http.Error(w, "Invalid client_id", http.StatusBadRequest)
+				return
+			}
+			clientID = req.ClientID
+		}
+		if req.ExpectedSleep != "" {
+			d, err := time.ParseDuration(req.ExpectedSleep)
+			if err != nil {
+				http.Error(w, "Invalid expected_sleep duration", http.StatusBadRequest)
+				return
+			}
+			expectedSleep = d
+		}
+	}
+
+	m.RecordClientHeartbeat(clientID, expectedSleep)
 
 	response := map[string]any{
 		"status":              "ok",
+		"client_id":           clientID,
 		"last_heartbeat":      m.GetLastHeartbeat().Format(time.RFC3339),
-		"time_until_shutdown": (m.config.Timeout - m.GetTimeSinceLastHeartbeat()).String(),
+		"time_until_shutdown": (m.effectiveTimeout() - m.GetTimeSinceLastHeartbeat()).String(),
+		"sleep_hint":          m.GetSleepHint().String(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -203,7 +389,7 @@ func (m *Monitor) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	timeSinceLast := m.GetTimeSinceLastHeartbeat()
-	timeUntilShutdown := m.config.Timeout - timeSinceLast
+	timeUntilShutdown := m.effectiveTimeout() - timeSinceLast
 
 	response := map[string]any{
 		"server_id":            m.config.ServerID,
@@ -212,8 +398,12 @@ func (m *Monitor) handleStatus(w http.ResponseWriter, r *http.Request) {
 		"time_until_shutdown":  timeUntilShutdown.String(),
 		"timeout":              m.config.Timeout.String(),
 		"grace_period":         m.config.GracePeriod.String(),
+		"sleep_hint":           m.GetSleepHint().String(),
+		"postponed":            m.getPostponement() > 0,
 		"is_timed_out":         m.IsTimedOut(),
 		"running":              m.running,
+		"restarts":             m.GetRestarts(),
+		"clients":              m.GetClients(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -240,14 +430,16 @@ func (m *Monitor) monitorTimeout() {
 			if m.IsTimedOut() {
 				m.logger.Warn("Keep-alive timeout exceeded, initiating self-destruction",
 					"last_heartbeat", m.GetLastHeartbeat(),
-					"timeout", m.config.Timeout,
+					"timeout", m.effectiveTimeout(),
 				)
 				m.initiateShutdown()
 				return
 			}
 
+			m.checkWarnings()
+
 			// Log status periodically
-			remaining := m.config.Timeout - m.GetTimeSinceLastHeartbeat()
+			remaining := m.effectiveTimeout() - m.GetTimeSinceLastHeartbeat()
 			m.logger.Debug("Keep-alive status",
 				"time_since_last_heartbeat", m.GetTimeSinceLastHeartbeat(),
 				"time_remaining", remaining,
@@ -262,24 +454,38 @@ func (m *Monitor) initiateShutdown() {
 		"grace_period", m.config.GracePeriod,
 	)
 
-	// Wait for grace period
-	select {
-	case <-time.After(m.config.GracePeriod):
-		// No heartbeat received during grace period, proceed with shutdown
-		break
-	case <-m.ctx.Done():
-		m.logger.Info("Shutdown cancelled by context")
-		return
+	m.checkWarnings()
+
+	// Wait for grace period, sending the warnings that come due in it. After a restart
+	// during the grace period only its remainder is waited for.
+	grace := time.NewTimer(m.startGracePeriod())
+	defer grace.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+wait:
+	for {
+		select {
+		case <-grace.C:
+			// No heartbeat received during grace period, proceed with shutdown
+			break wait
+		case <-ticker.C:
+			m.checkWarnings()
+		case <-m.ctx.Done():
+			m.logger.Info("Shutdown cancelled by context")
+			return
+		}
 	}
 
 	// Check one more time if heartbeat was received during grace period
 	if !m.IsTimedOut() {
 		m.logger.Info("Heartbeat received during grace period, cancelling shutdown")
+		m.endGracePeriod()
 		go m.monitorTimeout() // Restart monitoring
 		return
 	}
 
 	m.logger.Error("No heartbeat received during grace period, proceeding with self-destruction")
+	m.preserveState()
 	m.selfDestruct()
 }
 
@@ -358,6 +564,8 @@ func (m *Monitor) systemShutdown() {
 type HeartbeatClient struct {
 	serverURL string
 	client    *http.Client
+	authToken string
+	clientID  string
 }
 
 // NewHeartbeatClient creates a new heartbeat client.
@@ -370,10 +578,67 @@ func NewHeartbeatClient(serverURL string) *HeartbeatClient {
 	}
 }
 
+// WithAuthToken makes the client sign its requests with token; empty sends them unsigned.
+func (c *HeartbeatClient) WithAuthToken(token string) *HeartbeatClient {
+	c.authToken = token
+	return c
+}
+
+// WithClientID makes the client identify its heartbeats with id, so that the server
+// tracks it separately from other clients; empty is DefaultClientID.
+func (c *HeartbeatClient) WithClientID(id string) *HeartbeatClient {
+	c.clientID = id
+	return c
+}
+
+// WithDialer makes the client connect through dial, such as an SSH connection to the
+// server, instead of dialing the server directly.
+func (c *HeartbeatClient) WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *HeartbeatClient {
+	c.client.Transport = &http.Transport{DialContext: dial}
+	return c
+}
+
+// newRequest creates a request to the monitor, signed when an auth token is set.
+func (c *HeartbeatClient) newRequest(method, path string, payload []byte) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, c.serverURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.authToken != "" {
+		signRequest(req, c.authToken, payload)
+	}
+	return req, nil
+}
+
 // SendHeartbeat sends a heartbeat to the server.
 func (c *HeartbeatClient) SendHeartbeat() error {
-	url := fmt.Sprintf("%s/heartbeat", c.serverURL)
-	req, err := http.NewRequest(http.MethodPost, url, nil)
+	return c.SendHeartbeatWithSleepHint(0)
+}
+
+// SendHeartbeatWithSleepHint sends a heartbeat announcing that the client expects
+// to be suspended for up to expectedSleep.
+func (c *HeartbeatClient) SendHeartbeatWithSleepHint(expectedSleep time.Duration) error {
+	var payload []byte
+	if expectedSleep > 0 || c.clientID != "" {
+		req := HeartbeatRequest{ClientID: c.clientID}
+		if expectedSleep > 0 {
+			req.ExpectedSleep = expectedSleep.String()
+		}
+		var err error
+		payload, err = json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to encode heartbeat request: %w", err)
+		}
+	}
+
+	req, err := c.newRequest(http.MethodPost, "/heartbeat", payload)
 	if err != nil {
 		return fmt.Errorf("failed to create heartbeat request: %w", err)
 	}
@@ -393,13 +658,21 @@ func (c *HeartbeatClient) SendHeartbeat() error {
 
 // GetStatus retrieves the current monitor status.
 func (c *HeartbeatClient) GetStatus() (*MonitorStatus, error) {
-	url := fmt.Sprintf("%s/status", c.serverURL)
-	resp, err := c.client.Get(url)
+	req, err := c.newRequest(http.MethodGet, "/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status request failed with status: %d", resp.StatusCode)
+	}
+
 	var status MonitorStatus
 	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
 		return nil, fmt.Errorf("failed to decode status: %w", err)
@@ -416,6 +689,12 @@ type MonitorStatus struct {
 	TimeUntilShutdown  string `json:"time_until_shutdown"`
 	Timeout            string `json:"timeout"`
 	GracePeriod        string `json:"grace_period"`
+	SleepHint          string `json:"sleep_hint"`
+	Postponed          bool   `json:"postponed"`
 	IsTimedOut         bool   `json:"is_timed_out"`
 	Running            bool   `json:"running"`
+	// Restarts counts restarts of dockbridge-server that kept the heartbeat state
+	Restarts int `json:"restarts"`
+	// Clients lists the last heartbeat of each client keeping the server alive
+	Clients []ClientStatus `json:"clients"`
 }
@@ -3,8 +3,13 @@ package keepalive
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -173,6 +178,22 @@ func TestMonitor_HandleHealth(t *testing.T) {
 	assert.Equal(t, "healthy", response["status"])
 }
 
+func TestMonitor_Metrics(t *testing.T) {
+	m := NewMonitor(&Config{Timeout: 5 * time.Minute, GracePeriod: 30 * time.Second}, nil)
+	m.RecordHeartbeat()
+	m.RecordHeartbeat()
+
+	rec := httptest.NewRecorder()
+	m.metrics.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "dockbridge_keepalive_heartbeats_total 2")
+	assert.Contains(t, body, "dockbridge_keepalive_seconds_since_heartbeat")
+	assert.Contains(t, body, "dockbridge_keepalive_timeout_seconds 300")
+	assert.Contains(t, body, "dockbridge_keepalive_seconds_until_self_destruct 329.")
+}
+
 func TestMonitor_StartStop(t *testing.T) {
 	config := &Config{
 		Port:    18080, // Use high port to avoid conflicts
@@ -241,6 +262,23 @@ func TestHeartbeatClient_SendHeartbeat(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestHeartbeatClient_WithDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+	}))
+	defer server.Close()
+
+	// The URL names the server's loopback address, as seen through an SSH connection
+	var dialed string
+	client := NewHeartbeatClient("http://127.0.0.1:8080").WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		return net.Dial(network, server.Listener.Addr().String())
+	})
+
+	require.NoError(t, client.SendHeartbeat())
+	assert.Equal(t, "127.0.0.1:8080", dialed)
+}
+
 func TestHeartbeatClient_GetStatus(t *testing.T) {
 	// Setup mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -295,3 +333,413 @@ func TestMonitor_HeartbeatResetsTimer(t *testing.T) {
 	time.Sleep(150 * time.Millisecond)
 	assert.True(t, m.IsTimedOut(), "Should be timed out now")
 }
+
+func TestMonitor_SleepHint(t *testing.T) {
+	config := &Config{
+		Port:         8080,
+		Timeout:      100 * time.Millisecond,
+		GracePeriod:  10 * time.Millisecond,
+		MaxSleepHint: 200 * time.Millisecond,
+	}
+	m := NewMonitor(config, nil)
+
+	t.Run("hint extends timeout up to the cap", func(t *testing.T) {
+		m.RecordHeartbeatWithSleepHint(time.Hour)
+		assert.Equal(t, 200*time.Millisecond, m.GetSleepHint())
+
+		time.Sleep(150 * time.Millisecond)
+		assert.False(t, m.IsTimedOut())
+	})
+
+	t.Run("plain heartbeat clears hint", func(t *testing.T) {
+		m.RecordHeartbeat()
+		assert.Equal(t, time.Duration(0), m.GetSleepHint())
+	})
+
+	t.Run("heartbeat body carries hint", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/heartbeat", strings.NewReader(`{"expected_sleep":"50ms"}`))
+		rec := httptest.NewRecorder()
+
+		m.handleHeartbeat(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 50*time.Millisecond, m.GetSleepHint())
+	})
+
+	t.Run("invalid hint is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/heartbeat", strings.NewReader(`{"expected_sleep":"soon"}`))
+		rec := httptest.NewRecorder()
+
+		m.handleHeartbeat(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHeartbeatClient_SendHeartbeatWithSleepHint(t *testing.T) {
+	var received HeartbeatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHeartbeatClient(server.URL)
+
+	require.NoError(t, client.SendHeartbeatWithSleepHint(10*time.Minute))
+	assert.Equal(t, "10m0s", received.ExpectedSleep)
+}
+
+func TestMonitor_Authentication(t *testing.T) {
+	config := DefaultConfig()
+	config.AuthToken = "secret"
+	m := NewMonitor(config, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/heartbeat", m.authenticate(m.handleHeartbeat))
+	mux.HandleFunc("/status", m.authenticate(m.handleStatus))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("signed requests are accepted", func(t *testing.T) {
+		client := NewHeartbeatClient(server.URL).WithAuthToken("secret")
+		require.NoError(t, client.SendHeartbeat())
+		require.NoError(t, client.SendHeartbeatWithSleepHint(time.Minute))
+
+		_, err := client.GetStatus()
+		require.NoError(t, err)
+	})
+
+	t.Run("unsigned and wrongly signed requests are rejected", func(t *testing.T) {
+		assert.ErrorContains(t, NewHeartbeatClient(server.URL).SendHeartbeat(), "401")
+		assert.ErrorContains(t, NewHeartbeatClient(server.URL).WithAuthToken("guess").SendHeartbeat(), "401")
+
+		_, err := NewHeartbeatClient(server.URL).GetStatus()
+		assert.Error(t, err)
+	})
+
+	t.Run("replayed and stale requests are rejected", func(t *testing.T) {
+		req, err := NewHeartbeatClient(server.URL).WithAuthToken("secret").newRequest(http.MethodPost, "/heartbeat", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		replay := req.Clone(context.Background())
+		resp, err = http.DefaultClient.Do(replay)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		stale := time.Now().Add(-2 * MaxClockSkew).UnixNano()
+		req, err = http.NewRequest(http.MethodPost, server.URL+"/heartbeat", nil)
+		require.NoError(t, err)
+		req.Header.Set(TimestampHeader, strconv.FormatInt(stale, 10))
+		req.Header.Set(SignatureHeader, Sign("secret", http.MethodPost, "/heartbeat", stale, nil))
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestMonitor_Warnings(t *testing.T) {
+	received := make(chan Warning, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var warning Warning
+		json.NewDecoder(r.Body).Decode(&warning)
+		received <- warning
+	}))
+	defer webhook.Close()
+
+	config := DefaultConfig()
+	config.Timeout = time.Minute
+	config.GracePeriod = 0
+	config.WarnBefore = []time.Duration{10 * time.Second, 30 * time.Second}
+	config.WarningWebhook = webhook.URL
+	config.AuthToken = "secret"
+	m := NewMonitor(config, nil)
+
+	var broadcasts []string
+	m.broadcast = func(message string) error {
+		broadcasts = append(broadcasts, message)
+		return nil
+	}
+
+	m.checkWarnings()
+	assert.Empty(t, broadcasts, "no warning is due right after a heartbeat")
+
+	m.mu.Lock()
+	m.lastHeartbeat = time.Now().Add(-35 * time.Second)
+	m.mu.Unlock()
+	m.checkWarnings()
+	m.checkWarnings()
+	require.Len(t, broadcasts, 1, "each warning is sent once")
+	assert.Contains(t, broadcasts[0], "unless postponed")
+
+	select {
+	case warning := <-received:
+		assert.True(t, warning.CanPostpone)
+		assert.Contains(t, warning.Message, "destroy itself")
+	case <-time.After(5 * time.Second):
+		t.Fatal("warning not posted to the webhook")
+	}
+
+	m.mu.Lock()
+	m.lastHeartbeat = time.Now().Add(-55 * time.Second)
+	m.mu.Unlock()
+	m.checkWarnings()
+	assert.Len(t, broadcasts, 2)
+	<-received
+
+	m.RecordHeartbeat()
+	m.mu.Lock()
+	m.lastHeartbeat = time.Now().Add(-55 * time.Second)
+	m.mu.Unlock()
+	m.checkWarnings()
+	assert.Len(t, broadcasts, 3, "a heartbeat restarts the sequence, sending only the latest due warning")
+}
+
+func TestMonitor_Postpone(t *testing.T) {
+	config := DefaultConfig()
+	config.AuthToken = "secret"
+	config.Timeout = time.Minute
+	m := NewMonitor(config, nil)
+	m.mu.Lock()
+	m.lastHeartbeat = time.Now().Add(-2 * time.Minute)
+	m.mu.Unlock()
+	require.True(t, m.IsTimedOut())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/postpone", m.authenticate(m.handlePostpone))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	assert.ErrorContains(t, NewHeartbeatClient(server.URL).Postpone(), "401")
+
+	client := NewHeartbeatClient(server.URL).WithAuthToken("secret")
+	require.NoError(t, client.Postpone())
+	assert.False(t, m.IsTimedOut())
+	assert.ErrorContains(t, client.Postpone(), "409", "postponing works once")
+
+	m.RecordHeartbeat()
+	require.NoError(t, client.Postpone(), "a heartbeat allows postponing again")
+
+	t.Run("disabled without an auth token", func(t *testing.T) {
+		m := NewMonitor(DefaultConfig(), nil)
+		rec := httptest.NewRecorder()
+		m.handlePostpone(rec, httptest.NewRequest(http.MethodPost, "/postpone", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestMonitor_PreserveState(t *testing.T) {
+	config := DefaultConfig()
+	config.PreserveState = true
+	m := NewMonitor(config, nil)
+
+	var commands []string
+	m.run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		commands = append(commands, command)
+		switch {
+		case strings.HasPrefix(command, "docker ps -q"):
+			return []byte("aaa\nbbb\n"), nil
+		case strings.HasPrefix(command, "docker ps -a"):
+			return []byte("bbb\tregistry.example.com/app:snapshot\nccc\t\n"), nil
+		}
+		return nil, nil
+	}
+
+	m.preserveState()
+	assert.Equal(t, []string{
+		"docker ps -q",
+		"docker stop -t 20 aaa bbb",
+		`docker ps -a --filter label=dockbridge.commit --format {{.ID}}\t{{.Label "dockbridge.commit"}}`,
+		"docker commit bbb registry.example.com/app:snapshot",
+		"sync -f /var/lib/docker",
+	}, commands)
+
+	t.Run("disabled", func(t *testing.T) {
+		m := NewMonitor(DefaultConfig(), nil)
+		m.run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			t.Fatalf("unexpected command %s", name)
+			return nil, nil
+		}
+		m.preserveState()
+	})
+}
+
+func TestMonitor_StatePersistsAcrossRestarts(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = time.Minute
+	config.StateFile = filepath.Join(t.TempDir(), "keepalive-state.json")
+
+	first := NewMonitor(config, nil)
+	assert.False(t, first.restoreState(), "nothing to restore on the first start")
+	first.RecordHeartbeatWithSleepHint(5 * time.Minute)
+	first.mu.Lock()
+	first.clients[DefaultClientID] = clientHeartbeat{LastHeartbeat: time.Now().Add(-40 * time.Second), SleepHint: 5 * time.Minute}
+	first.updateDeadline()
+	first.mu.Unlock()
+	first.saveState()
+
+	second := NewMonitor(config, nil)
+	require.True(t, second.restoreState())
+	assert.InDelta(t, 40, second.GetTimeSinceLastHeartbeat().Seconds(), 1, "a restart does not reset the heartbeat time")
+	assert.Equal(t, 5*time.Minute, second.GetSleepHint())
+	assert.Equal(t, 1, second.GetRestarts())
+
+	// A restart during the grace period only waits for its remainder
+	second.mu.Lock()
+	second.graceStarted = time.Now().Add(-20 * time.Second)
+	second.mu.Unlock()
+	second.saveState()
+
+	third := NewMonitor(config, nil)
+	require.True(t, third.restoreState())
+	assert.Equal(t, 2, third.GetRestarts())
+	assert.InDelta(t, 10, third.startGracePeriod().Seconds(), 1)
+
+	rec := httptest.NewRecorder()
+	third.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status MonitorStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	assert.Equal(t, 2, status.Restarts)
+
+	// A heartbeat ends the grace period for later restarts too
+	third.RecordHeartbeat()
+	fourth := NewMonitor(config, nil)
+	require.True(t, fourth.restoreState())
+	assert.Equal(t, 30*time.Second, fourth.startGracePeriod().Round(time.Second))
+}
+
+func TestMonitor_InvalidStateIsIgnored(t *testing.T) {
+	config := DefaultConfig()
+	config.StateFile = filepath.Join(t.TempDir(), "keepalive-state.json")
+	require.NoError(t, os.WriteFile(config.StateFile, []byte("{"), 0644))
+
+	m := NewMonitor(config, nil)
+	assert.False(t, m.restoreState())
+	assert.Equal(t, 0, m.GetRestarts())
+}
+
+func TestMonitor_StateOfAnotherBootOrServerIsDiscarded(t *testing.T) {
+	newMonitor := func(statePath, serverID, bootID string) *Monitor {
+		config := DefaultConfig()
+		config.ServerID = serverID
+		config.StateFile = statePath
+		m := NewMonitor(config, nil)
+		m.bootID = func() string { return bootID }
+		return m
+	}
+
+	// An hours-old heartbeat, as on a server powered off by the lifecycle policy
+	statePath := filepath.Join(t.TempDir(), "keepalive-state.json")
+	saved := newMonitor(statePath, "42", "boot-1")
+	saved.mu.Lock()
+	saved.clients[DefaultClientID] = clientHeartbeat{LastHeartbeat: time.Now().Add(-3 * time.Hour)}
+	saved.updateDeadline()
+	saved.mu.Unlock()
+	saved.saveState()
+
+	t.Run("same boot", func(t *testing.T) {
+		assert.True(t, newMonitor(statePath, "42", "boot-1").restoreState())
+	})
+
+	t.Run("stale boot", func(t *testing.T) {
+		m := newMonitor(statePath, "42", "boot-2")
+		assert.False(t, m.restoreState())
+		assert.Equal(t, 0, m.GetRestarts())
+	})
+
+	t.Run("foreign server", func(t *testing.T) {
+		// e.g. a server booted from a golden image taken of server 42
+		m := newMonitor(statePath, "43", "boot-1")
+		assert.False(t, m.restoreState())
+		assert.Equal(t, 0, m.GetRestarts())
+	})
+
+	t.Run("unknown boot", func(t *testing.T) {
+		assert.False(t, newMonitor(statePath, "42", "").restoreState())
+	})
+}
+
+func TestMonitor_MultipleClients(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = time.Minute
+	config.StateFile = filepath.Join(t.TempDir(), "keepalive-state.json")
+	m := NewMonitor(config, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/heartbeat", m.handleHeartbeat)
+	mux.HandleFunc("/status", m.handleStatus)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	require.NoError(t, NewHeartbeatClient(server.URL).WithClientID("laptop").SendHeartbeat())
+	require.NoError(t, NewHeartbeatClient(server.URL).WithClientID("desktop").SendHeartbeatWithSleepHint(10*time.Minute))
+
+	// The laptop went silent long ago; the desktop announced a suspend recently
+	m.mu.Lock()
+	m.clients["laptop"] = clientHeartbeat{LastHeartbeat: time.Now().Add(-time.Hour)}
+	m.clients["desktop"] = clientHeartbeat{LastHeartbeat: time.Now().Add(-5 * time.Minute), SleepHint: 10 * time.Minute}
+	m.updateDeadline()
+	m.mu.Unlock()
+	assert.False(t, m.IsTimedOut(), "one live client keeps the server alive")
+
+	status, err := NewHeartbeatClient(server.URL).GetStatus()
+	require.NoError(t, err)
+	require.Len(t, status.Clients, 2)
+	assert.Equal(t, "desktop", status.Clients[0].ClientID)
+	assert.False(t, status.Clients[0].IsTimedOut)
+	assert.Equal(t, "laptop", status.Clients[1].ClientID)
+	assert.True(t, status.Clients[1].IsTimedOut)
+	assert.Equal(t, 1, m.activeClients())
+
+	// Clients are tracked across restarts
+	m.saveState()
+	restarted := NewMonitor(config, nil)
+	require.True(t, restarted.restoreState())
+	assert.Len(t, restarted.GetClients(), 2)
+	assert.False(t, restarted.IsTimedOut())
+
+	m.mu.Lock()
+	m.clients["desktop"] = clientHeartbeat{LastHeartbeat: time.Now().Add(-20 * time.Minute), SleepHint: 10 * time.Minute}
+	m.updateDeadline()
+	m.mu.Unlock()
+	assert.True(t, m.IsTimedOut(), "the server times out once every client did")
+
+	t.Run("invalid client ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.handleHeartbeat(rec, httptest.NewRequest(http.MethodPost, "/heartbeat", strings.NewReader(`{"client_id":"my laptop"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("heartbeats without an ID", func(t *testing.T) {
+		m.RecordHeartbeat()
+		clients := m.GetClients()
+		assert.Contains(t, []string{clients[0].ClientID, clients[1].ClientID, clients[2].ClientID}, DefaultClientID)
+		assert.False(t, m.IsTimedOut())
+	})
+}
+
+func TestSanitizeClientID(t *testing.T) {
+	for hostname, want := range map[string]string{
+		"build-01.example.com": "build-01.example.com",
+		"Jürgens MacBook":      "J-rgens-MacBook",
+		"":                     DefaultClientID,
+		"   ":                  DefaultClientID,
+		"ноутбук":              DefaultClientID,
+	} {
+		got := SanitizeClientID(hostname)
+		assert.Equal(t, want, got, hostname)
+		assert.True(t, validClientID(got), hostname)
+	}
+
+	long := SanitizeClientID(strings.Repeat("a", 300))
+	assert.Len(t, long, maxClientIDLength)
+	assert.True(t, validClientID(long))
+}
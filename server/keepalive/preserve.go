@@ -0,0 +1,109 @@
+package keepalive
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// preserveOverhead bounds the parts of state preservation other than stopping
+// containers: listing, committing and syncing.
+const preserveOverhead = 2 * time.Minute
+
+// runCommand runs name with args and returns its combined output.
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// preserveState prepares the server for deletion so that no state is lost from the
+// persistent volume: running containers are stopped with Config.StopTimeout so their
+// writes are flushed, containers labelled with Config.CommitLabel are committed to the
+// image named by the label, and Config.SyncPath is synced. Failures are logged and do
+// not prevent self-destruction.
+func (m *Monitor) preserveState() {
+	if !m.config.PreserveState {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.StopTimeout+preserveOverhead)
+	defer cancel()
+
+	m.logger.Warn("Preserving state before self-destruction", "stop_timeout", m.config.StopTimeout)
+
+	if err := m.stopContainers(ctx); err != nil {
+		m.logger.Error("Failed to stop containers", "error", err)
+	}
+	if err := m.commitContainers(ctx); err != nil {
+		m.logger.Error("Failed to commit containers", "error", err)
+	}
+	if err := m.syncVolume(ctx); err != nil {
+		m.logger.Error("Failed to sync volume", "error", err)
+	}
+}
+
+// stopContainers stops all running containers, giving each StopTimeout to exit.
+func (m *Monitor) stopContainers(ctx context.Context) error {
+	output, err := m.run(ctx, "docker", "ps", "-q")
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	ids := strings.Fields(string(output))
+	if len(ids) == 0 {
+		return nil
+	}
+
+	seconds := strconv.Itoa(int(m.config.StopTimeout.Seconds()))
+	args := append([]string{"stop", "-t", seconds}, ids...)
+	if output, err := m.run(ctx, "docker", args...); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	m.logger.Info("Stopped containers", "count", len(ids))
+	return nil
+}
+
+// commitContainers commits each container labelled with CommitLabel to the image
+// named by the label's value.
+func (m *Monitor) commitContainers(ctx context.Context) error {
+	if m.config.CommitLabel == "" {
+		return nil
+	}
+
+	format := fmt.Sprintf(`{{.ID}}\t{{.Label %q}}`, m.config.CommitLabel)
+	output, err := m.run(ctx, "docker", "ps", "-a", "--filter", "label="+m.config.CommitLabel, "--format", format)
+	if err != nil {
+		return fmt.Errorf("failed to list labelled containers: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	var failed int
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		id, image, ok := strings.Cut(line, "\t")
+		if !ok || id == "" || image == "" {
+			continue
+		}
+		if output, err := m.run(ctx, "docker", "commit", id, image); err != nil {
+			m.logger.Error("Failed to commit container", "container", id, "image", image, "error", err, "output", strings.TrimSpace(string(output)))
+			failed++
+			continue
+		}
+		m.logger.Info("Committed container", "container", id, "image", image)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d container(s) could not be committed", failed)
+	}
+	return nil
+}
+
+// syncVolume flushes the filesystem holding SyncPath to disk.
+func (m *Monitor) syncVolume(ctx context.Context) error {
+	if m.config.SyncPath == "" {
+		return nil
+	}
+	if output, err := m.run(ctx, "sync", "-f", m.config.SyncPath); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	m.logger.Info("Synced volume", "path", m.config.SyncPath)
+	return nil
+}
@@ -0,0 +1,206 @@
+package keepalive
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// bootIDPath holds a random ID the kernel generates on every boot
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// persistedState is the part of the monitor's state kept in Config.StateFile, so that a
+// restart of dockbridge-server neither forgets how long the client has been silent nor
+// begins the grace period before self-destruction again.
+type persistedState struct {
+	// ServerID and BootID tie the state to the run of the server that saved it. A
+	// server powered on again or booted from a snapshot of it must not inherit the
+	// heartbeat time, or it would destroy itself before its client can send one.
+	ServerID string `json:"server_id"`
+	BootID   string `json:"boot_id"`
+
+	// Clients holds the last heartbeat of each client; states saved before client IDs
+	// only have LastHeartbeat and SleepHint
+	Clients map[string]clientHeartbeat `json:"clients,omitempty"`
+
+	LastHeartbeat time.Time     `json:"last_heartbeat"`
+	SleepHint     time.Duration `json:"sleep_hint"`
+	Postponement  time.Duration `json:"postponement"`
+	WarningsSent  int           `json:"warnings_sent"`
+
+	// GraceStarted is when the grace period began; zero while heartbeats are awaited.
+	GraceStarted time.Time `json:"grace_started,omitzero"`
+
+	// Restarts counts the starts of the monitor that found earlier state.
+	Restarts int `json:"restarts"`
+}
+
+// restoreState loads the state saved by an earlier run, reporting whether there was
+// one. State saved by another server or before the last boot is discarded. A heartbeat
+// time in the future, e.g. after the clock was corrected, counts as now.
+func (m *Monitor) restoreState() bool {
+	if m.config.StateFile == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(m.config.StateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			m.logger.Warn("Failed to read keep-alive state", "path", m.config.StateFile, "error", err)
+		}
+		return false
+	}
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil || state.LastHeartbeat.IsZero() {
+		m.logger.Warn("Ignoring invalid keep-alive state", "path", m.config.StateFile)
+		return false
+	}
+	if bootID := m.bootID(); bootID == "" || state.BootID != bootID || state.ServerID != m.config.ServerID {
+		m.logger.Info("Discarding keep-alive state of another boot or server",
+			"path", m.config.StateFile,
+			"saved_server_id", state.ServerID,
+			"saved_boot_id", state.BootID,
+		)
+		return false
+	}
+
+	if len(state.Clients) == 0 {
+		state.Clients = map[string]clientHeartbeat{
+			DefaultClientID: {LastHeartbeat: state.LastHeartbeat, SleepHint: state.SleepHint},
+		}
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	m.lastHeartbeat = state.LastHeartbeat
+	if m.lastHeartbeat.After(now) {
+		m.lastHeartbeat = now
+	}
+	m.sleepHint = min(max(state.SleepHint, 0), m.config.MaxSleepHint)
+	clear(m.clients)
+	for id, client := range state.Clients {
+		if client.LastHeartbeat.IsZero() || !validClientID(id) {
+			continue
+		}
+		if client.LastHeartbeat.After(now) {
+			client.LastHeartbeat = now
+		}
+		client.SleepHint = min(max(client.SleepHint, 0), m.config.MaxSleepHint)
+		m.clients[id] = client
+	}
+	m.updateDeadline()
+	m.postponement = min(max(state.Postponement, 0), m.config.PostponeBy)
+	m.warningsSent = state.WarningsSent
+	m.graceStarted = state.GraceStarted
+	m.restarts = state.Restarts + 1
+	m.mu.Unlock()
+
+	m.logger.Info("Restored keep-alive state",
+		"last_heartbeat", state.LastHeartbeat,
+		"in_grace_period", !state.GraceStarted.IsZero(),
+		"restarts", m.restarts,
+	)
+	return true
+}
+
+// saveState writes the state to Config.StateFile. The file is replaced atomically, so
+// that a crash while saving leaves the previous state.
+func (m *Monitor) saveState() {
+	if m.config.StateFile == "" {
+		return
+	}
+
+	// Saves are serialized so that an older snapshot cannot overwrite a newer one
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	m.mu.RLock()
+	state := persistedState{
+		ServerID:      m.config.ServerID,
+		BootID:        m.bootID(),
+		Clients:       maps.Clone(m.clients),
+		LastHeartbeat: m.lastHeartbeat,
+		SleepHint:     m.sleepHint,
+		Postponement:  m.postponement,
+		WarningsSent:  m.warningsSent,
+		GraceStarted:  m.graceStarted,
+		Restarts:      m.restarts,
+	}
+	m.mu.RUnlock()
+
+	if err := writeStateFile(m.config.StateFile, state); err != nil {
+		m.logger.Warn("Failed to save keep-alive state", "path", m.config.StateFile, "error", err)
+	}
+}
+
+// readBootID returns the ID of the current boot, or "" where the kernel provides none
+func readBootID() string {
+	data, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// writeStateFile writes state to path through a temporary file renamed over it
+func writeStateFile(path string, state persistedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keepalive-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GetRestarts returns how many times the monitor was started again with the state of
+// an earlier run, e.g. after systemd restarted dockbridge-server.
+func (m *Monitor) GetRestarts() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.restarts
+}
+
+// startGracePeriod records the start of the grace period, unless a run before a
+// restart began it already, and returns how much of it is left.
+func (m *Monitor) startGracePeriod() time.Duration {
+	m.mu.Lock()
+	if m.graceStarted.IsZero() {
+		m.graceStarted = time.Now()
+	}
+	remaining := m.config.GracePeriod - time.Since(m.graceStarted)
+	m.mu.Unlock()
+
+	m.saveState()
+	return max(remaining, 0)
+}
+
+// endGracePeriod records that the grace period ended without self-destruction.
+func (m *Monitor) endGracePeriod() {
+	m.mu.Lock()
+	m.graceStarted = time.Time{}
+	m.mu.Unlock()
+	m.saveState()
+}
@@ -0,0 +1,201 @@
+package keepalive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"time"
+)
+
+// Warning is posted to Config.WarningWebhook before the server destroys itself.
+type Warning struct {
+	ServerID              string    `json:"server_id"`
+	Message               string    `json:"message"`
+	TimeSinceHeartbeat    string    `json:"time_since_heartbeat"`
+	TimeUntilSelfDestruct string    `json:"time_until_self_destruct"`
+	SelfDestructAt        time.Time `json:"self_destruct_at"`
+	// CanPostpone is true while a signed POST to /postpone would still be accepted.
+	CanPostpone bool `json:"can_postpone"`
+}
+
+// warningOffsets returns the configured warning offsets, longest first.
+func (m *Monitor) warningOffsets() []time.Duration {
+	offsets := slices.Clone(m.config.WarnBefore)
+	slices.Sort(offsets)
+	slices.Reverse(offsets)
+	return offsets
+}
+
+// checkWarnings emits the latest warning of the sequence that has come due and not
+// been sent since the last heartbeat or postponement.
+func (m *Monitor) checkWarnings() {
+	remaining := m.timeUntilSelfDestruct()
+	offsets := m.warningOffsets()
+
+	m.mu.Lock()
+	due := false
+	for m.warningsSent < len(offsets) && remaining <= offsets[m.warningsSent] {
+		m.warningsSent++
+		due = true
+	}
+	m.mu.Unlock()
+
+	if due {
+		m.saveState()
+		m.warn(max(remaining, 0))
+	}
+}
+
+// warn announces the upcoming self-destruction in the server log, to logged-in users
+// with wall and to the warning webhook.
+func (m *Monitor) warn(remaining time.Duration) {
+	canPostpone := m.canPostpone()
+	since := m.GetTimeSinceLastHeartbeat()
+	message := fmt.Sprintf("DockBridge: no heartbeat from the client for %s, this server will destroy itself in %s",
+		since.Round(time.Second), remaining.Round(time.Second))
+	if canPostpone {
+		message += fmt.Sprintf(" unless postponed by %s", m.config.PostponeBy)
+	}
+
+	m.metrics.warnings.Inc()
+	m.logger.Warn("Self-destruction warning", "time_until_self_destruct", remaining, "can_postpone", canPostpone)
+
+	if err := m.broadcast(message); err != nil {
+		m.logger.Debug("Failed to broadcast warning", "error", err)
+	}
+
+	if m.config.WarningWebhook != "" {
+		warning := Warning{
+			ServerID:              m.config.ServerID,
+			Message:               message,
+			TimeSinceHeartbeat:    since.String(),
+			TimeUntilSelfDestruct: remaining.String(),
+			SelfDestructAt:        time.Now().Add(remaining),
+			CanPostpone:           canPostpone,
+		}
+		go func() {
+			if err := m.postWarning(warning); err != nil {
+				m.logger.Warn("Failed to deliver warning to webhook", "error", err)
+			}
+		}()
+	}
+}
+
+// postWarning posts warning to the webhook, signed like heartbeats when an auth token
+// is set so the receiver can verify it.
+func (m *Monitor) postWarning(warning Warning) error {
+	payload, err := json.Marshal(warning)
+	if err != nil {
+		return fmt.Errorf("failed to encode warning: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.config.WarningWebhook, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.config.AuthToken != "" {
+		signRequest(req, m.config.AuthToken, payload)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post warning: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// wallMessage writes message to the terminals of all logged-in users.
+func wallMessage(message string) error {
+	path, err := exec.LookPath("wall")
+	if err != nil {
+		return err
+	}
+	return exec.Command(path, message).Run()
+}
+
+// canPostpone reports whether the timeout can still be extended.
+func (m *Monitor) canPostpone() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.PostponeBy > 0 && m.config.AuthToken != "" && m.postponement == 0
+}
+
+// Postpone extends the timeout by Config.PostponeBy. It succeeds once until the next
+// heartbeat.
+func (m *Monitor) Postpone() bool {
+	m.mu.Lock()
+	if m.config.PostponeBy <= 0 || m.postponement > 0 {
+		m.mu.Unlock()
+		return false
+	}
+	m.postponement = m.config.PostponeBy
+	m.warningsSent = 0
+	m.graceStarted = time.Time{}
+	m.mu.Unlock()
+
+	m.saveState()
+	m.logger.Warn("Self-destruction postponed", "postponed_by", m.config.PostponeBy)
+	return true
+}
+
+// getPostponement returns the extra timeout granted by Postpone.
+func (m *Monitor) getPostponement() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.postponement
+}
+
+// handlePostpone extends the timeout once. Postponing requires an auth token, so only
+// the holder of the server's secret can keep it alive without heartbeats.
+func (m *Monitor) handlePostpone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if m.config.AuthToken == "" || m.config.PostponeBy <= 0 {
+		http.Error(w, "Postponing is disabled", http.StatusForbidden)
+		return
+	}
+	if !m.Postpone() {
+		http.Error(w, "Already postponed since the last heartbeat", http.StatusConflict)
+		return
+	}
+
+	response := map[string]any{
+		"status":                   "postponed",
+		"postponed_by":             m.config.PostponeBy.String(),
+		"time_until_self_destruct": m.timeUntilSelfDestruct().String(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Postpone asks the monitor to extend its timeout once; it requires an auth token.
+func (c *HeartbeatClient) Postpone() error {
+	req, err := c.newRequest(http.MethodPost, "/postpone", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create postpone request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to postpone: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("postpone failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,25 +3,34 @@ package server
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
-	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/provider"
 	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/pkg/errors"
 )
 
 // Manager handles server lifecycle operations with enhanced volume management
 type Manager struct {
-	hetznerClient hetzner.HetznerClient
+	cloudProvider provider.CloudProvider
 	config        *config.HetznerConfig
+	contextName   string
+	owner         provider.Owner
+	observer      provider.ProvisioningObserver
 }
 
 // NewManager creates a new server manager
-func NewManager(hetznerClient hetzner.HetznerClient, config *config.HetznerConfig) *Manager {
+func NewManager(cloudProvider provider.CloudProvider, config *config.HetznerConfig) *Manager {
+	return NewManagerForContext(cloudProvider, config, "")
+}
+
+// NewManagerForContext creates a server manager that only manages servers of the named context
+func NewManagerForContext(cloudProvider provider.CloudProvider, config *config.HetznerConfig, contextName string) *Manager {
 	return &Manager{
-		hetznerClient: hetznerClient,
+		cloudProvider: cloudProvider,
 		config:        config,
+		contextName:   contextName,
+		owner:         provider.LocalOwner(),
 	}
 }
 
@@ -54,6 +63,7 @@ const (
 	StatusRunning      ServerStatus = "running"
 	StatusShuttingDown ServerStatus = "shutting_down"
 	StatusTerminated   ServerStatus = "terminated"
+	StatusOff          ServerStatus = provider.StatusOff
 )
 
 // VolumeStatus represents the status of a volume
@@ -86,8 +96,15 @@ func (m *Manager) EnsureServer(ctx context.Context) (*ServerInfo, error) {
 }
 
 // provisionServerWithVolume provisions a new server with enhanced volume management
-func (m *Manager) provisionServerWithVolume(ctx context.Context) (*ServerInfo, error) {
+func (m *Manager) provisionServerWithVolume(ctx context.Context) (info *ServerInfo, err error) {
+	defer func() {
+		if err != nil {
+			m.reportPhase(provider.PhaseFailed, err.Error(), 100)
+		}
+	}()
+
 	// Step 1: Find or create a Docker data volume
+	m.reportPhase(provider.PhaseEnsuringVolume, "Preparing Docker data volume", 5)
 	volume, err := m.EnsureVolume(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to ensure volume")
@@ -100,40 +117,44 @@ func (m *Manager) provisionServerWithVolume(ctx context.Context) (*ServerInfo, e
 	// based on the actual image selected (Docker CE or Ubuntu fallback)
 
 	// Step 4: Provision server with volume attached
-	serverName := fmt.Sprintf("dockbridge-%d", time.Now().Unix())
-	serverConfig := &hetzner.ServerConfig{
+	serverName := provider.NewServerName(m.contextName)
+	serverConfig := &provider.ServerConfig{
 		Name:       serverName,
 		ServerType: m.config.ServerType,
 		Location:   m.config.Location,
 		VolumeID:   volume.ID,
+		Labels:     m.owner.ServerLabels(m.contextName),
 		UserData:   "", // Will be generated by Hetzner client based on selected image
 		// SSHKeyID will be set by the caller
 		// ImageName will be set by the Hetzner client during provisioning
+
+		Network: m.config.Network,
+		// SSH and the keep-alive monitor are the only ports reached over the network
+		Firewall:       provider.NewFirewall(m.config.Firewall, 22, 8080),
+		PlacementGroup: m.config.PlacementGroup,
+		PublicNetwork:  m.config.PublicNetwork,
 	}
 
-	server, err := m.hetznerClient.ProvisionServer(ctx, serverConfig)
+	m.reportPhase(provider.PhaseCreatingServer, fmt.Sprintf("Creating %s server in %s", m.config.ServerType, m.config.Location), 15)
+	server, err := m.cloudProvider.ProvisionServer(ctx, serverConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to provision server")
 	}
 
 	// Step 5: Wait for server to be ready with enhanced volume setup
+	m.reportPhase(provider.PhaseMountingVolume, "Waiting for cloud-init to install Docker and mount the volume", 50)
 	err = m.waitForServerReady(ctx, fmt.Sprintf("%d", server.ID), 10*time.Minute)
 	if err != nil {
 		// Cleanup server if it fails to become ready
-		_ = m.hetznerClient.DestroyServer(ctx, fmt.Sprintf("%d", server.ID))
+		_ = m.cloudProvider.DestroyServer(ctx, fmt.Sprintf("%d", server.ID))
 		return nil, errors.Wrap(err, "server failed to become ready")
 	}
 
-	// Convert VolumeInfo back to hetzner.Volume for the conversion function
-	var hetznerVolume *hetzner.Volume
+	// Convert VolumeInfo back to provider.Volume for the conversion function
+	var providerVolume *provider.Volume
 	if volume != nil {
-		// Parse the volume ID from string to int64
-		volumeIDInt, err := strconv.ParseInt(volume.ID, 10, 64)
-		if err != nil {
-			volumeIDInt = 0
-		}
-		hetznerVolume = &hetzner.Volume{
-			ID:       volumeIDInt,
+		providerVolume = &provider.Volume{
+			ID:       volume.ID,
 			Name:     volume.Name,
 			Size:     volume.Size,
 			Location: "", // We don't have location in VolumeInfo
@@ -141,13 +162,26 @@ func (m *Manager) provisionServerWithVolume(ctx context.Context) (*ServerInfo, e
 		}
 	}
 
-	return convertToServerInfo(server, hetznerVolume), nil
+	m.reportPhase(provider.PhaseReady, "Server ready", 100)
+	return convertToServerInfo(server, providerVolume), nil
+}
+
+// SetProvisioningObserver sets the observer notified of provisioning phases
+func (m *Manager) SetProvisioningObserver(observer provider.ProvisioningObserver) {
+	m.observer = observer
+}
+
+// reportPhase notifies the provisioning observer, if any
+func (m *Manager) reportPhase(phase provider.ProvisioningPhase, message string, percent int) {
+	if m.observer != nil {
+		m.observer.OnPhase(phase, message, percent)
+	}
 }
 
 // EnsureVolume ensures a Docker data volume exists and is available
 func (m *Manager) EnsureVolume(ctx context.Context) (*VolumeInfo, error) {
 	// Try to find an existing Docker data volume
-	volume, err := m.hetznerClient.FindOrCreateDockerVolume(ctx, m.config.Location)
+	volume, err := m.cloudProvider.FindOrCreateDockerVolume(ctx, m.config.Location)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to find or create Docker volume")
 	}
@@ -155,17 +189,21 @@ func (m *Manager) EnsureVolume(ctx context.Context) (*VolumeInfo, error) {
 	return convertToVolumeInfo(volume), nil
 }
 
-// DestroyServer destroys a server while preserving the volume for future use
+// DestroyServer destroys a server while preserving the volume for future use. Servers
+// not created by this client are refused with a provider.ForeignResourceError.
 func (m *Manager) DestroyServer(ctx context.Context, serverID string) error {
 	// Get server details to find associated volume
-	server, err := m.hetznerClient.GetServer(ctx, serverID)
+	server, err := m.cloudProvider.GetServer(ctx, serverID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get server details")
 	}
+	if err := provider.CheckOwned(m.owner, server); err != nil {
+		return err
+	}
 
 	// Detach volume before destroying server (to preserve Docker state)
 	if server.VolumeID != "" {
-		err = m.hetznerClient.DetachVolume(ctx, server.VolumeID)
+		err = m.cloudProvider.DetachVolume(ctx, server.VolumeID)
 		if err != nil {
 			// Log warning but continue with server destruction
 			fmt.Printf("Warning: failed to detach volume %s: %v\n", server.VolumeID, err)
@@ -173,7 +211,7 @@ func (m *Manager) DestroyServer(ctx context.Context, serverID string) error {
 	}
 
 	// Destroy the server
-	err = m.hetznerClient.DestroyServer(ctx, serverID)
+	err = m.cloudProvider.DestroyServer(ctx, serverID)
 	if err != nil {
 		return errors.Wrap(err, "failed to destroy server")
 	}
@@ -181,6 +219,20 @@ func (m *Manager) DestroyServer(ctx context.Context, serverID string) error {
 	return nil
 }
 
+// StopServer powers a server off, keeping its disk and volume attached for a fast resume.
+// It fails if the provider cannot power servers off.
+func (m *Manager) StopServer(ctx context.Context, serverID string) error {
+	power, ok := m.cloudProvider.(provider.PowerController)
+	if !ok {
+		return errors.New("provider does not support stopping servers")
+	}
+
+	if err := power.PowerOffServer(ctx, serverID); err != nil {
+		return errors.Wrap(err, "failed to stop server")
+	}
+	return nil
+}
+
 // GetServerStatus retrieves the current status of a server
 func (m *Manager) GetServerStatus(ctx context.Context) (*ServerStatus, error) {
 	servers, err := m.ListServers(ctx)
@@ -204,21 +256,18 @@ func (m *Manager) GetServerStatus(ctx context.Context) (*ServerStatus, error) {
 	return &latestServer.Status, nil
 }
 
-// ListServers retrieves all DockBridge servers
+// ListServers retrieves the DockBridge servers of the context created by this client
 func (m *Manager) ListServers(ctx context.Context) ([]*ServerInfo, error) {
-	servers, err := m.hetznerClient.ListServers(ctx)
+	servers, err := provider.NewServerRegistryForOwner(m.cloudProvider, m.owner).ForContext(ctx, m.contextName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list servers")
 	}
 
 	var dockbridgeServers []*ServerInfo
 	for _, server := range servers {
-		// Filter for DockBridge servers (by name pattern)
-		if isDockBridgeServer(server.Name) {
-			volume, _ := m.hetznerClient.GetVolume(ctx, server.VolumeID)
-			serverInfo := convertToServerInfo(server, volume)
-			dockbridgeServers = append(dockbridgeServers, serverInfo)
-		}
+		volume, _ := m.cloudProvider.GetVolume(ctx, server.VolumeID)
+		serverInfo := convertToServerInfo(server, volume)
+		dockbridgeServers = append(dockbridgeServers, serverInfo)
 	}
 
 	return dockbridgeServers, nil
@@ -237,7 +286,7 @@ func (m *Manager) waitForServerReady(ctx context.Context, serverID string, timeo
 		case <-ctx.Done():
 			return errors.New("timeout waiting for server to be ready")
 		case <-ticker.C:
-			server, err := m.hetznerClient.GetServer(ctx, serverID)
+			server, err := m.cloudProvider.GetServer(ctx, serverID)
 			if err != nil {
 				continue // Keep trying
 			}
@@ -254,13 +303,8 @@ func (m *Manager) waitForServerReady(ctx context.Context, serverID string, timeo
 
 // Helper functions
 
-// isDockBridgeServer checks if a server name indicates it's a DockBridge server
-func isDockBridgeServer(name string) bool {
-	return len(name) > 10 && name[:10] == "dockbridge"
-}
-
-// convertToServerInfo converts hetzner.Server to ServerInfo
-func convertToServerInfo(server *hetzner.Server, volume *hetzner.Volume) *ServerInfo {
+// convertToServerInfo converts provider.Server to ServerInfo
+func convertToServerInfo(server *provider.Server, volume *provider.Volume) *ServerInfo {
 	serverInfo := &ServerInfo{
 		ID:        fmt.Sprintf("%d", server.ID),
 		Name:      server.Name,
@@ -282,10 +326,10 @@ func convertToServerInfo(server *hetzner.Server, volume *hetzner.Volume) *Server
 	return serverInfo
 }
 
-// convertToVolumeInfo converts hetzner.Volume to VolumeInfo
-func convertToVolumeInfo(volume *hetzner.Volume) *VolumeInfo {
+// convertToVolumeInfo converts provider.Volume to VolumeInfo
+func convertToVolumeInfo(volume *provider.Volume) *VolumeInfo {
 	return &VolumeInfo{
-		ID:        fmt.Sprintf("%d", volume.ID),
+		ID:        volume.ID,
 		Name:      volume.Name,
 		Size:      volume.Size,
 		Status:    VolumeStatus(volume.Status),
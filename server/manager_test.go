@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/dockbridge/dockbridge/client/hetzner"
+	"github.com/dockbridge/dockbridge/client/provider"
 	"github.com/dockbridge/dockbridge/shared/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -84,6 +85,7 @@ func TestManager_EnsureServer(t *testing.T) {
 			existingServer: &hetzner.Server{
 				ID:        123,
 				Name:      "dockbridge-existing",
+				Labels:    provider.LocalOwner().ServerLabels(""),
 				Status:    "running",
 				IPAddress: "1.2.3.4",
 				VolumeID:  "456",
@@ -111,11 +113,16 @@ func TestManager_EnsureServer(t *testing.T) {
 
 			manager := NewManager(mockClient, config)
 
+			var phases []provider.ProvisioningPhase
+			manager.SetProvisioningObserver(provider.ProvisioningObserverFunc(func(phase provider.ProvisioningPhase, message string, percent int) {
+				phases = append(phases, phase)
+			}))
+
 			// Setup mock expectations
 			if tt.existingServer != nil {
 				mockClient.On("ListServers", mock.Anything).Return([]*hetzner.Server{tt.existingServer}, nil)
 				mockClient.On("GetVolume", mock.Anything, "456").Return(&hetzner.Volume{
-					ID:       456,
+					ID:       "456",
 					Name:     "test-volume",
 					Size:     10,
 					Location: "fsn1",
@@ -124,13 +131,13 @@ func TestManager_EnsureServer(t *testing.T) {
 			} else {
 				mockClient.On("ListServers", mock.Anything).Return([]*hetzner.Server{}, nil)
 				mockClient.On("FindOrCreateDockerVolume", mock.Anything, "fsn1").Return(&hetzner.Volume{
-					ID:       789,
+					ID:       "789",
 					Name:     "dockbridge-docker-data-123",
 					Size:     10,
 					Location: "fsn1",
 					Status:   "available",
 				}, nil)
-				mockClient.On("ProvisionServer", mock.Anything, mock.AnythingOfType("*hetzner.ServerConfig")).Return(&hetzner.Server{
+				mockClient.On("ProvisionServer", mock.Anything, mock.AnythingOfType("*provider.ServerConfig")).Return(&hetzner.Server{
 					ID:        999,
 					Name:      "dockbridge-new",
 					Status:    "running",
@@ -165,6 +172,18 @@ func TestManager_EnsureServer(t *testing.T) {
 				assert.Equal(t, "/var/lib/docker", server.Metadata["docker_data_dir"])
 			}
 
+			// Progress is only reported when a server is provisioned
+			if tt.expectNewServer {
+				assert.Equal(t, []provider.ProvisioningPhase{
+					provider.PhaseEnsuringVolume,
+					provider.PhaseCreatingServer,
+					provider.PhaseMountingVolume,
+					provider.PhaseReady,
+				}, phases)
+			} else {
+				assert.Empty(t, phases)
+			}
+
 			mockClient.AssertExpectations(t)
 		})
 	}
@@ -181,7 +200,7 @@ func TestManager_EnsureVolume(t *testing.T) {
 
 	// Setup mock expectations
 	expectedVolume := &hetzner.Volume{
-		ID:       123,
+		ID:       "123",
 		Name:     "dockbridge-docker-data-456",
 		Size:     10,
 		Location: "fsn1",
@@ -218,6 +237,7 @@ func TestManager_DestroyServer(t *testing.T) {
 	mockClient.On("GetServer", mock.Anything, serverID).Return(&hetzner.Server{
 		ID:        123,
 		Name:      "dockbridge-test",
+		Labels:    provider.LocalOwner().ServerLabels(""),
 		Status:    "running",
 		IPAddress: "1.2.3.4",
 		VolumeID:  volumeID,
@@ -235,6 +255,26 @@ func TestManager_DestroyServer(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestManager_DestroyForeignServer(t *testing.T) {
+	mockClient := &MockHetznerClient{}
+	manager := NewManager(mockClient, &config.HetznerConfig{})
+
+	// A server of another client sharing the project is never destroyed
+	mockClient.On("GetServer", mock.Anything, "123").Return(&hetzner.Server{
+		ID:     123,
+		Name:   "dockbridge-1700000000",
+		Status: "running",
+		Labels: provider.Owner{ID: "teammate"}.ServerLabels(""),
+	}, nil)
+
+	err := manager.DestroyServer(context.Background(), "123")
+
+	var foreign *provider.ForeignResourceError
+	require.ErrorAs(t, err, &foreign)
+	assert.Equal(t, "teammate", foreign.Owner)
+	mockClient.AssertNotCalled(t, "DestroyServer", mock.Anything, mock.Anything)
+}
+
 func TestManager_ListServers(t *testing.T) {
 	mockClient := &MockHetznerClient{}
 	config := &config.HetznerConfig{}
@@ -246,6 +286,7 @@ func TestManager_ListServers(t *testing.T) {
 		{
 			ID:        123,
 			Name:      "dockbridge-server1",
+			Labels:    provider.LocalOwner().ServerLabels(""),
 			Status:    "running",
 			IPAddress: "1.2.3.4",
 			VolumeID:  "456",
@@ -263,7 +304,7 @@ func TestManager_ListServers(t *testing.T) {
 
 	mockClient.On("ListServers", mock.Anything).Return(hetznerServers, nil)
 	mockClient.On("GetVolume", mock.Anything, "456").Return(&hetzner.Volume{
-		ID:       456,
+		ID:       "456",
 		Name:     "test-volume",
 		Size:     10,
 		Location: "fsn1",
@@ -294,7 +335,7 @@ func TestConvertToServerInfo(t *testing.T) {
 	}
 
 	volume := &hetzner.Volume{
-		ID:       456,
+		ID:       "456",
 		Name:     "test-volume",
 		Size:     20,
 		Location: "fsn1",
@@ -318,7 +359,7 @@ func TestConvertToServerInfo(t *testing.T) {
 
 func TestConvertToVolumeInfo(t *testing.T) {
 	volume := &hetzner.Volume{
-		ID:       123,
+		ID:       "123",
 		Name:     "test-volume",
 		Size:     15,
 		Location: "fsn1",
@@ -382,7 +423,7 @@ func BenchmarkManager_EnsureServer(b *testing.B) {
 
 	mockClient.On("ListServers", mock.Anything).Return([]*hetzner.Server{existingServer}, nil)
 	mockClient.On("GetVolume", mock.Anything, "456").Return(&hetzner.Volume{
-		ID:       456,
+		ID:       "456",
 		Name:     "test-volume",
 		Size:     10,
 		Location: "fsn1",
@@ -182,7 +182,8 @@ func TestCloudInitScriptGeneration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			script := hetzner.GenerateCloudInitScript(tt.config)
+			script, err := hetzner.GenerateCloudInitScript(tt.config)
+			require.NoError(t, err)
 
 			// Verify script contains essential components
 			assert.Contains(t, script, "#cloud-config")
@@ -232,6 +233,8 @@ func BenchmarkCloudInitGeneration(b *testing.B) {
 	}
 
 	for b.Loop() {
-		_ = hetzner.GenerateCloudInitScript(config)
+		if _, err := hetzner.GenerateCloudInitScript(config); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
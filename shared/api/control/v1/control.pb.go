@@ -0,0 +1,1551 @@
+// Versioned control API of the DockBridge client daemon. Editors, tray apps and
+// CI tooling use it to query status, manage the remote server and follow events.
+//
+// Regenerate the Go code with `task proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: control/v1/control.proto
+
+package controlv1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Server describes a remote Hetzner server
+type Server struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,4,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server) Reset() {
+	*x = Server{}
+	mi := &file_control_v1_control_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server) ProtoMessage() {}
+
+func (x *Server) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server.ProtoReflect.Descriptor instead.
+func (*Server) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Server) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Server) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Server) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Server) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *Server) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// ContextStatus describes the daemon of one context
+type ContextStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the context; empty for the default context
+	Name       string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SocketPath string `protobuf:"bytes,2,opt,name=socket_path,json=socketPath,proto3" json:"socket_path,omitempty"`
+	Running    bool   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	// Server the daemon is connected to; unset when none is connected
+	Server        *Server `protobuf:"bytes,4,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContextStatus) Reset() {
+	*x = ContextStatus{}
+	mi := &file_control_v1_control_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextStatus) ProtoMessage() {}
+
+func (x *ContextStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextStatus.ProtoReflect.Descriptor instead.
+func (*ContextStatus) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ContextStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ContextStatus) GetSocketPath() string {
+	if x != nil {
+		return x.SocketPath
+	}
+	return ""
+}
+
+func (x *ContextStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ContextStatus) GetServer() *Server {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+type GetStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Context to report; all contexts are reported when empty and all is set
+	Context       string `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	All           bool   `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetStatusRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *GetStatusRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contexts      []*ContextStatus       `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetStatusResponse) GetContexts() []*ContextStatus {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+type ProvisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Context       string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisionRequest) Reset() {
+	*x = ProvisionRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisionRequest) ProtoMessage() {}
+
+func (x *ProvisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisionRequest.ProtoReflect.Descriptor instead.
+func (*ProvisionRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProvisionRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+type ProvisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Server        *Server                `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvisionResponse) Reset() {
+	*x = ProvisionResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvisionResponse) ProtoMessage() {}
+
+func (x *ProvisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvisionResponse.ProtoReflect.Descriptor instead.
+func (*ProvisionResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProvisionResponse) GetServer() *Server {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+type DestroyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Context       string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyRequest) Reset() {
+	*x = DestroyRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyRequest) ProtoMessage() {}
+
+func (x *DestroyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyRequest.ProtoReflect.Descriptor instead.
+func (*DestroyRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DestroyRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+type DestroyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestroyResponse) Reset() {
+	*x = DestroyResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestroyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestroyResponse) ProtoMessage() {}
+
+func (x *DestroyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestroyResponse.ProtoReflect.Descriptor instead.
+func (*DestroyResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{7}
+}
+
+// Forward describes a port or Unix socket forward
+type Forward struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Forward type: "tcp" or "unix"
+	Type             string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	ContainerId      string                 `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	ContainerName    string                 `protobuf:"bytes,4,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	LocalPort        int32                  `protobuf:"varint,5,opt,name=local_port,json=localPort,proto3" json:"local_port,omitempty"`
+	RemotePort       int32                  `protobuf:"varint,6,opt,name=remote_port,json=remotePort,proto3" json:"remote_port,omitempty"`
+	LocalSocket      string                 `protobuf:"bytes,7,opt,name=local_socket,json=localSocket,proto3" json:"local_socket,omitempty"`
+	RemoteSocket     string                 `protobuf:"bytes,8,opt,name=remote_socket,json=remoteSocket,proto3" json:"remote_socket,omitempty"`
+	BindAddress      string                 `protobuf:"bytes,9,opt,name=bind_address,json=bindAddress,proto3" json:"bind_address,omitempty"`
+	Status           string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	BytesTransferred int64                  `protobuf:"varint,11,opt,name=bytes_transferred,json=bytesTransferred,proto3" json:"bytes_transferred,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Compose project and service of the container; empty outside docker compose
+	Project       string `protobuf:"bytes,13,opt,name=project,proto3" json:"project,omitempty"`
+	Service       string `protobuf:"bytes,14,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Forward) Reset() {
+	*x = Forward{}
+	mi := &file_control_v1_control_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Forward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Forward) ProtoMessage() {}
+
+func (x *Forward) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Forward.ProtoReflect.Descriptor instead.
+func (*Forward) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Forward) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Forward) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Forward) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *Forward) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *Forward) GetLocalPort() int32 {
+	if x != nil {
+		return x.LocalPort
+	}
+	return 0
+}
+
+func (x *Forward) GetRemotePort() int32 {
+	if x != nil {
+		return x.RemotePort
+	}
+	return 0
+}
+
+func (x *Forward) GetLocalSocket() string {
+	if x != nil {
+		return x.LocalSocket
+	}
+	return ""
+}
+
+func (x *Forward) GetRemoteSocket() string {
+	if x != nil {
+		return x.RemoteSocket
+	}
+	return ""
+}
+
+func (x *Forward) GetBindAddress() string {
+	if x != nil {
+		return x.BindAddress
+	}
+	return ""
+}
+
+func (x *Forward) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Forward) GetBytesTransferred() int64 {
+	if x != nil {
+		return x.BytesTransferred
+	}
+	return 0
+}
+
+func (x *Forward) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Forward) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Forward) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+type ListForwardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Context       string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListForwardsRequest) Reset() {
+	*x = ListForwardsRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListForwardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListForwardsRequest) ProtoMessage() {}
+
+func (x *ListForwardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListForwardsRequest.ProtoReflect.Descriptor instead.
+func (*ListForwardsRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListForwardsRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+type ListForwardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Forwards      []*Forward             `protobuf:"bytes,1,rep,name=forwards,proto3" json:"forwards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListForwardsResponse) Reset() {
+	*x = ListForwardsResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListForwardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListForwardsResponse) ProtoMessage() {}
+
+func (x *ListForwardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListForwardsResponse.ProtoReflect.Descriptor instead.
+func (*ListForwardsResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListForwardsResponse) GetForwards() []*Forward {
+	if x != nil {
+		return x.Forwards
+	}
+	return nil
+}
+
+type CreateForwardRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Context     string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	ContainerId string                 `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	// Port on the local machine; 0 uses the container port
+	LocalPort  int32 `protobuf:"varint,3,opt,name=local_port,json=localPort,proto3" json:"local_port,omitempty"`
+	RemotePort int32 `protobuf:"varint,4,opt,name=remote_port,json=remotePort,proto3" json:"remote_port,omitempty"`
+	// Protocol: "tcp" (default) or "udp"
+	Protocol      string `protobuf:"bytes,5,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateForwardRequest) Reset() {
+	*x = CreateForwardRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateForwardRequest) ProtoMessage() {}
+
+func (x *CreateForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateForwardRequest.ProtoReflect.Descriptor instead.
+func (*CreateForwardRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateForwardRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *CreateForwardRequest) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *CreateForwardRequest) GetLocalPort() int32 {
+	if x != nil {
+		return x.LocalPort
+	}
+	return 0
+}
+
+func (x *CreateForwardRequest) GetRemotePort() int32 {
+	if x != nil {
+		return x.RemotePort
+	}
+	return 0
+}
+
+func (x *CreateForwardRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+type CreateForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Forward       *Forward               `protobuf:"bytes,1,opt,name=forward,proto3" json:"forward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateForwardResponse) Reset() {
+	*x = CreateForwardResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateForwardResponse) ProtoMessage() {}
+
+func (x *CreateForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateForwardResponse.ProtoReflect.Descriptor instead.
+func (*CreateForwardResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateForwardResponse) GetForward() *Forward {
+	if x != nil {
+		return x.Forward
+	}
+	return nil
+}
+
+type DeleteForwardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Context       string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteForwardRequest) Reset() {
+	*x = DeleteForwardRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteForwardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteForwardRequest) ProtoMessage() {}
+
+func (x *DeleteForwardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteForwardRequest.ProtoReflect.Descriptor instead.
+func (*DeleteForwardRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteForwardRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *DeleteForwardRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteForwardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteForwardResponse) Reset() {
+	*x = DeleteForwardResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteForwardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteForwardResponse) ProtoMessage() {}
+
+func (x *DeleteForwardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteForwardResponse.ProtoReflect.Descriptor instead.
+func (*DeleteForwardResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{14}
+}
+
+type CloseProjectForwardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Context       string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	Project       string                 `protobuf:"bytes,2,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseProjectForwardsRequest) Reset() {
+	*x = CloseProjectForwardsRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseProjectForwardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseProjectForwardsRequest) ProtoMessage() {}
+
+func (x *CloseProjectForwardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseProjectForwardsRequest.ProtoReflect.Descriptor instead.
+func (*CloseProjectForwardsRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CloseProjectForwardsRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *CloseProjectForwardsRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+type CloseProjectForwardsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of forwards closed
+	Closed        int32 `protobuf:"varint,1,opt,name=closed,proto3" json:"closed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseProjectForwardsResponse) Reset() {
+	*x = CloseProjectForwardsResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseProjectForwardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseProjectForwardsResponse) ProtoMessage() {}
+
+func (x *CloseProjectForwardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseProjectForwardsResponse.ProtoReflect.Descriptor instead.
+func (*CloseProjectForwardsResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CloseProjectForwardsResponse) GetClosed() int32 {
+	if x != nil {
+		return x.Closed
+	}
+	return 0
+}
+
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Context to follow; events of all contexts are streamed when empty and all is set
+	Context string `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	All     bool   `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	// Event types to stream (e.g. "server_provisioned"); all types when empty
+	Types         []string `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StreamEventsRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *StreamEventsRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *StreamEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// Event is a lifecycle event, the same payload delivered to hooks
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Context       string                 `protobuf:"bytes,3,opt,name=context,proto3" json:"context,omitempty"`
+	Data          map[string]string      `protobuf:"bytes,4,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_control_v1_control_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *Event) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type StreamLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of recent entries to send first; 0 sends all buffered entries and a
+	// negative number none
+	Tail int32 `protobuf:"varint,1,opt,name=tail,proto3" json:"tail,omitempty"`
+	// Keep streaming new entries
+	Follow bool `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	// Minimum level: debug, info, warn, error or fatal; empty sends all levels
+	Level         string `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLogsRequest) Reset() {
+	*x = StreamLogsRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLogsRequest) ProtoMessage() {}
+
+func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamLogsRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *StreamLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *StreamLogsRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+// LogEntry is one line written by the daemon's logger
+type LogEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Level string                 `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	// Line is the log line without terminal colors
+	Line          string `protobuf:"bytes,3,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_control_v1_control_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LogEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *LogEntry) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogEntry) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+type ReloadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadRequest) Reset() {
+	*x = ReloadRequest{}
+	mi := &file_control_v1_control_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadRequest) ProtoMessage() {}
+
+func (x *ReloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadRequest.ProtoReflect.Descriptor instead.
+func (*ReloadRequest) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{21}
+}
+
+type ReloadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Configuration keys whose new values were applied
+	Applied []string `protobuf:"bytes,1,rep,name=applied,proto3" json:"applied,omitempty"`
+	// Configuration keys that changed but take effect on the next start
+	Deferred      []string `protobuf:"bytes,2,rep,name=deferred,proto3" json:"deferred,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadResponse) Reset() {
+	*x = ReloadResponse{}
+	mi := &file_control_v1_control_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadResponse) ProtoMessage() {}
+
+func (x *ReloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_control_v1_control_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadResponse.ProtoReflect.Descriptor instead.
+func (*ReloadResponse) Descriptor() ([]byte, []int) {
+	return file_control_v1_control_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReloadResponse) GetApplied() []string {
+	if x != nil {
+		return x.Applied
+	}
+	return nil
+}
+
+func (x *ReloadResponse) GetDeferred() []string {
+	if x != nil {
+		return x.Deferred
+	}
+	return nil
+}
+
+var File_control_v1_control_proto protoreflect.FileDescriptor
+
+const file_control_v1_control_proto_rawDesc = "" +
+	"\n" +
+	"\x18control/v1/control.proto\x12\x15dockbridge.control.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9e\x01\n" +
+	"\x06Server\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x04 \x01(\tR\tipAddress\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x95\x01\n" +
+	"\rContextStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\vsocket_path\x18\x02 \x01(\tR\n" +
+	"socketPath\x12\x18\n" +
+	"\arunning\x18\x03 \x01(\bR\arunning\x125\n" +
+	"\x06server\x18\x04 \x01(\v2\x1d.dockbridge.control.v1.ServerR\x06server\">\n" +
+	"\x10GetStatusRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x10\n" +
+	"\x03all\x18\x02 \x01(\bR\x03all\"U\n" +
+	"\x11GetStatusResponse\x12@\n" +
+	"\bcontexts\x18\x01 \x03(\v2$.dockbridge.control.v1.ContextStatusR\bcontexts\",\n" +
+	"\x10ProvisionRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\"J\n" +
+	"\x11ProvisionResponse\x125\n" +
+	"\x06server\x18\x01 \x01(\v2\x1d.dockbridge.control.v1.ServerR\x06server\"*\n" +
+	"\x0eDestroyRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\"\x11\n" +
+	"\x0fDestroyResponse\"\xd6\x03\n" +
+	"\aForward\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12!\n" +
+	"\fcontainer_id\x18\x03 \x01(\tR\vcontainerId\x12%\n" +
+	"\x0econtainer_name\x18\x04 \x01(\tR\rcontainerName\x12\x1d\n" +
+	"\n" +
+	"local_port\x18\x05 \x01(\x05R\tlocalPort\x12\x1f\n" +
+	"\vremote_port\x18\x06 \x01(\x05R\n" +
+	"remotePort\x12!\n" +
+	"\flocal_socket\x18\a \x01(\tR\vlocalSocket\x12#\n" +
+	"\rremote_socket\x18\b \x01(\tR\fremoteSocket\x12!\n" +
+	"\fbind_address\x18\t \x01(\tR\vbindAddress\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12+\n" +
+	"\x11bytes_transferred\x18\v \x01(\x03R\x10bytesTransferred\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x18\n" +
+	"\aproject\x18\r \x01(\tR\aproject\x12\x18\n" +
+	"\aservice\x18\x0e \x01(\tR\aservice\"/\n" +
+	"\x13ListForwardsRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\"R\n" +
+	"\x14ListForwardsResponse\x12:\n" +
+	"\bforwards\x18\x01 \x03(\v2\x1e.dockbridge.control.v1.ForwardR\bforwards\"\xaf\x01\n" +
+	"\x14CreateForwardRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12!\n" +
+	"\fcontainer_id\x18\x02 \x01(\tR\vcontainerId\x12\x1d\n" +
+	"\n" +
+	"local_port\x18\x03 \x01(\x05R\tlocalPort\x12\x1f\n" +
+	"\vremote_port\x18\x04 \x01(\x05R\n" +
+	"remotePort\x12\x1a\n" +
+	"\bprotocol\x18\x05 \x01(\tR\bprotocol\"Q\n" +
+	"\x15CreateForwardResponse\x128\n" +
+	"\aforward\x18\x01 \x01(\v2\x1e.dockbridge.control.v1.ForwardR\aforward\"@\n" +
+	"\x14DeleteForwardRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteForwardResponse\"Q\n" +
+	"\x1bCloseProjectForwardsRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\tR\aproject\"6\n" +
+	"\x1cCloseProjectForwardsResponse\x12\x16\n" +
+	"\x06closed\x18\x01 \x01(\x05R\x06closed\"W\n" +
+	"\x13StreamEventsRequest\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x10\n" +
+	"\x03all\x18\x02 \x01(\bR\x03all\x12\x14\n" +
+	"\x05types\x18\x03 \x03(\tR\x05types\"\xda\x01\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x18\n" +
+	"\acontext\x18\x03 \x01(\tR\acontext\x12:\n" +
+	"\x04data\x18\x04 \x03(\v2&.dockbridge.control.v1.Event.DataEntryR\x04data\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"U\n" +
+	"\x11StreamLogsRequest\x12\x12\n" +
+	"\x04tail\x18\x01 \x01(\x05R\x04tail\x12\x16\n" +
+	"\x06follow\x18\x02 \x01(\bR\x06follow\x12\x14\n" +
+	"\x05level\x18\x03 \x01(\tR\x05level\"d\n" +
+	"\bLogEntry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x12\n" +
+	"\x04line\x18\x03 \x01(\tR\x04line\"\x0f\n" +
+	"\rReloadRequest\"F\n" +
+	"\x0eReloadResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x03(\tR\aapplied\x12\x1a\n" +
+	"\bdeferred\x18\x02 \x03(\tR\bdeferred2\xfe\t\n" +
+	"\x0eControlService\x12r\n" +
+	"\tGetStatus\x12'.dockbridge.control.v1.GetStatusRequest\x1a(.dockbridge.control.v1.GetStatusResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/status\x12\x7f\n" +
+	"\tProvision\x12'.dockbridge.control.v1.ProvisionRequest\x1a(.dockbridge.control.v1.ProvisionResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/server:provision\x12w\n" +
+	"\aDestroy\x12%.dockbridge.control.v1.DestroyRequest\x1a&.dockbridge.control.v1.DestroyResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/server:destroy\x12}\n" +
+	"\fListForwards\x12*.dockbridge.control.v1.ListForwardsRequest\x1a+.dockbridge.control.v1.ListForwardsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/forwards\x12\x83\x01\n" +
+	"\rCreateForward\x12+.dockbridge.control.v1.CreateForwardRequest\x1a,.dockbridge.control.v1.CreateForwardResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/forwards\x12\x85\x01\n" +
+	"\rDeleteForward\x12+.dockbridge.control.v1.DeleteForwardRequest\x1a,.dockbridge.control.v1.DeleteForwardResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/forwards/{id}\x12\xa5\x01\n" +
+	"\x14CloseProjectForwards\x122.dockbridge.control.v1.CloseProjectForwardsRequest\x1a3.dockbridge.control.v1.CloseProjectForwardsResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/forwards:closeProject\x12n\n" +
+	"\fStreamEvents\x12*.dockbridge.control.v1.StreamEventsRequest\x1a\x1c.dockbridge.control.v1.Event\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/events0\x01\x12k\n" +
+	"\n" +
+	"StreamLogs\x12(.dockbridge.control.v1.StreamLogsRequest\x1a\x1f.dockbridge.control.v1.LogEntry\"\x10\x82\xd3\xe4\x93\x02\n" +
+	"\x12\b/v1/logs0\x01\x12l\n" +
+	"\x06Reload\x12$.dockbridge.control.v1.ReloadRequest\x1a%.dockbridge.control.v1.ReloadResponse\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/reloadBBZ@github.com/dockbridge/dockbridge/shared/api/control/v1;controlv1b\x06proto3"
+
+var (
+	file_control_v1_control_proto_rawDescOnce sync.Once
+	file_control_v1_control_proto_rawDescData []byte
+)
+
+func file_control_v1_control_proto_rawDescGZIP() []byte {
+	file_control_v1_control_proto_rawDescOnce.Do(func() {
+		file_control_v1_control_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_control_v1_control_proto_rawDesc), len(file_control_v1_control_proto_rawDesc)))
+	})
+	return file_control_v1_control_proto_rawDescData
+}
+
+var file_control_v1_control_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_control_v1_control_proto_goTypes = []any{
+	(*Server)(nil),                       // 0: dockbridge.control.v1.Server
+	(*ContextStatus)(nil),                // 1: dockbridge.control.v1.ContextStatus
+	(*GetStatusRequest)(nil),             // 2: dockbridge.control.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),            // 3: dockbridge.control.v1.GetStatusResponse
+	(*ProvisionRequest)(nil),             // 4: dockbridge.control.v1.ProvisionRequest
+	(*ProvisionResponse)(nil),            // 5: dockbridge.control.v1.ProvisionResponse
+	(*DestroyRequest)(nil),               // 6: dockbridge.control.v1.DestroyRequest
+	(*DestroyResponse)(nil),              // 7: dockbridge.control.v1.DestroyResponse
+	(*Forward)(nil),                      // 8: dockbridge.control.v1.Forward
+	(*ListForwardsRequest)(nil),          // 9: dockbridge.control.v1.ListForwardsRequest
+	(*ListForwardsResponse)(nil),         // 10: dockbridge.control.v1.ListForwardsResponse
+	(*CreateForwardRequest)(nil),         // 11: dockbridge.control.v1.CreateForwardRequest
+	(*CreateForwardResponse)(nil),        // 12: dockbridge.control.v1.CreateForwardResponse
+	(*DeleteForwardRequest)(nil),         // 13: dockbridge.control.v1.DeleteForwardRequest
+	(*DeleteForwardResponse)(nil),        // 14: dockbridge.control.v1.DeleteForwardResponse
+	(*CloseProjectForwardsRequest)(nil),  // 15: dockbridge.control.v1.CloseProjectForwardsRequest
+	(*CloseProjectForwardsResponse)(nil), // 16: dockbridge.control.v1.CloseProjectForwardsResponse
+	(*StreamEventsRequest)(nil),          // 17: dockbridge.control.v1.StreamEventsRequest
+	(*Event)(nil),                        // 18: dockbridge.control.v1.Event
+	(*StreamLogsRequest)(nil),            // 19: dockbridge.control.v1.StreamLogsRequest
+	(*LogEntry)(nil),                     // 20: dockbridge.control.v1.LogEntry
+	(*ReloadRequest)(nil),                // 21: dockbridge.control.v1.ReloadRequest
+	(*ReloadResponse)(nil),               // 22: dockbridge.control.v1.ReloadResponse
+	nil,                                  // 23: dockbridge.control.v1.Event.DataEntry
+	(*timestamppb.Timestamp)(nil),        // 24: google.protobuf.Timestamp
+}
+var file_control_v1_control_proto_depIdxs = []int32{
+	24, // 0: dockbridge.control.v1.Server.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: dockbridge.control.v1.ContextStatus.server:type_name -> dockbridge.control.v1.Server
+	1,  // 2: dockbridge.control.v1.GetStatusResponse.contexts:type_name -> dockbridge.control.v1.ContextStatus
+	0,  // 3: dockbridge.control.v1.ProvisionResponse.server:type_name -> dockbridge.control.v1.Server
+	24, // 4: dockbridge.control.v1.Forward.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 5: dockbridge.control.v1.ListForwardsResponse.forwards:type_name -> dockbridge.control.v1.Forward
+	8,  // 6: dockbridge.control.v1.CreateForwardResponse.forward:type_name -> dockbridge.control.v1.Forward
+	24, // 7: dockbridge.control.v1.Event.time:type_name -> google.protobuf.Timestamp
+	23, // 8: dockbridge.control.v1.Event.data:type_name -> dockbridge.control.v1.Event.DataEntry
+	24, // 9: dockbridge.control.v1.LogEntry.time:type_name -> google.protobuf.Timestamp
+	2,  // 10: dockbridge.control.v1.ControlService.GetStatus:input_type -> dockbridge.control.v1.GetStatusRequest
+	4,  // 11: dockbridge.control.v1.ControlService.Provision:input_type -> dockbridge.control.v1.ProvisionRequest
+	6,  // 12: dockbridge.control.v1.ControlService.Destroy:input_type -> dockbridge.control.v1.DestroyRequest
+	9,  // 13: dockbridge.control.v1.ControlService.ListForwards:input_type -> dockbridge.control.v1.ListForwardsRequest
+	11, // 14: dockbridge.control.v1.ControlService.CreateForward:input_type -> dockbridge.control.v1.CreateForwardRequest
+	13, // 15: dockbridge.control.v1.ControlService.DeleteForward:input_type -> dockbridge.control.v1.DeleteForwardRequest
+	15, // 16: dockbridge.control.v1.ControlService.CloseProjectForwards:input_type -> dockbridge.control.v1.CloseProjectForwardsRequest
+	17, // 17: dockbridge.control.v1.ControlService.StreamEvents:input_type -> dockbridge.control.v1.StreamEventsRequest
+	19, // 18: dockbridge.control.v1.ControlService.StreamLogs:input_type -> dockbridge.control.v1.StreamLogsRequest
+	21, // 19: dockbridge.control.v1.ControlService.Reload:input_type -> dockbridge.control.v1.ReloadRequest
+	3,  // 20: dockbridge.control.v1.ControlService.GetStatus:output_type -> dockbridge.control.v1.GetStatusResponse
+	5,  // 21: dockbridge.control.v1.ControlService.Provision:output_type -> dockbridge.control.v1.ProvisionResponse
+	7,  // 22: dockbridge.control.v1.ControlService.Destroy:output_type -> dockbridge.control.v1.DestroyResponse
+	10, // 23: dockbridge.control.v1.ControlService.ListForwards:output_type -> dockbridge.control.v1.ListForwardsResponse
+	12, // 24: dockbridge.control.v1.ControlService.CreateForward:output_type -> dockbridge.control.v1.CreateForwardResponse
+	14, // 25: dockbridge.control.v1.ControlService.DeleteForward:output_type -> dockbridge.control.v1.DeleteForwardResponse
+	16, // 26: dockbridge.control.v1.ControlService.CloseProjectForwards:output_type -> dockbridge.control.v1.CloseProjectForwardsResponse
+	18, // 27: dockbridge.control.v1.ControlService.StreamEvents:output_type -> dockbridge.control.v1.Event
+	20, // 28: dockbridge.control.v1.ControlService.StreamLogs:output_type -> dockbridge.control.v1.LogEntry
+	22, // 29: dockbridge.control.v1.ControlService.Reload:output_type -> dockbridge.control.v1.ReloadResponse
+	20, // [20:30] is the sub-list for method output_type
+	10, // [10:20] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_control_v1_control_proto_init() }
+func file_control_v1_control_proto_init() {
+	if File_control_v1_control_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_control_v1_control_proto_rawDesc), len(file_control_v1_control_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_control_v1_control_proto_goTypes,
+		DependencyIndexes: file_control_v1_control_proto_depIdxs,
+		MessageInfos:      file_control_v1_control_proto_msgTypes,
+	}.Build()
+	File_control_v1_control_proto = out.File
+	file_control_v1_control_proto_goTypes = nil
+	file_control_v1_control_proto_depIdxs = nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ProxyEnvPrefix prefixes the environment variables read by ssh-docker-proxy; the rest
+// of each name is the upper-cased YAML key, e.g. SSH_DOCKER_PROXY_SSH_HOST
+const ProxyEnvPrefix = "SSH_DOCKER_PROXY_"
+
+// ProxyConfig is the configuration file format of ssh-docker-proxy. DockBridge writes it
+// with "dockbridge export proxy-config", so its keys must match the proxy's loader.
+type ProxyConfig struct {
+	// LocalSocket is the Unix socket or Windows named pipe the proxy listens on
+	LocalSocket string `yaml:"local_socket" mapstructure:"local_socket"`
+	SSHUser     string `yaml:"ssh_user" mapstructure:"ssh_user"`
+	// SSHHost is the server address with an optional port, e.g. "203.0.113.7:22"
+	SSHHost    string `yaml:"ssh_host" mapstructure:"ssh_host"`
+	SSHKeyPath string `yaml:"ssh_key_path" mapstructure:"ssh_key_path"`
+	// SSHAgent authenticates with ssh-agent keys, falling back to the key file
+	SSHAgent     bool          `yaml:"ssh_agent" mapstructure:"ssh_agent"`
+	SSHAuthSock  string        `yaml:"ssh_auth_sock,omitempty" mapstructure:"ssh_auth_sock"`
+	RemoteSocket string        `yaml:"remote_socket" mapstructure:"remote_socket" default:"/var/run/docker.sock"`
+	Timeout      time.Duration `yaml:"timeout" mapstructure:"timeout" default:"10s"`
+	// LogFormat is text or json
+	LogFormat string `yaml:"log_format,omitempty" mapstructure:"log_format" default:"text"`
+	LogLevel  string `yaml:"log_level,omitempty" mapstructure:"log_level" default:"info"`
+	// StatsListen serves connection stats as JSON on a TCP address or "unix:<path>"
+	StatsListen string `yaml:"stats_listen,omitempty" mapstructure:"stats_listen"`
+}
+
+// Environ returns the configuration as ssh-docker-proxy environment variables in
+// KEY=value form, skipping unset values
+func (c ProxyConfig) Environ() []string {
+	var env []string
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		env = append(env, fmt.Sprintf("%s%s=%v", ProxyEnvPrefix, strings.ToUpper(key), field.Interface()))
+	}
+	return env
+}
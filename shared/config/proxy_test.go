@@ -0,0 +1,45 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestProxyConfigMatchesProxyExample keeps the keys in sync with ssh-docker-proxy
+func TestProxyConfigMatchesProxyExample(t *testing.T) {
+	data, err := os.ReadFile("../../ssh-docker-proxy/config.example.yaml")
+	require.NoError(t, err)
+
+	var cfg ProxyConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	require.NoError(t, decoder.Decode(&cfg))
+	assert.Equal(t, "/tmp/docker.sock", cfg.LocalSocket)
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+}
+
+func TestProxyConfigEnviron(t *testing.T) {
+	cfg := ProxyConfig{
+		LocalSocket:  "/tmp/dockbridge-proxy.sock",
+		SSHUser:      "root",
+		SSHHost:      "203.0.113.7:22",
+		SSHKeyPath:   "~/.dockbridge/ssh/id_rsa",
+		RemoteSocket: "/var/run/docker.sock",
+		Timeout:      30 * time.Second,
+	}
+
+	assert.Equal(t, []string{
+		"SSH_DOCKER_PROXY_LOCAL_SOCKET=/tmp/dockbridge-proxy.sock",
+		"SSH_DOCKER_PROXY_SSH_USER=root",
+		"SSH_DOCKER_PROXY_SSH_HOST=203.0.113.7:22",
+		"SSH_DOCKER_PROXY_SSH_KEY_PATH=~/.dockbridge/ssh/id_rsa",
+		"SSH_DOCKER_PROXY_REMOTE_SOCKET=/var/run/docker.sock",
+		"SSH_DOCKER_PROXY_TIMEOUT=30s",
+	}, cfg.Environ())
+}
@@ -4,13 +4,337 @@ import "time"
 
 // ClientConfig represents the complete client configuration
 type ClientConfig struct {
-	Hetzner     HetznerConfig     `yaml:"hetzner" mapstructure:"hetzner"`
-	Docker      DockerConfig      `yaml:"docker" mapstructure:"docker"`
-	Activity    ActivityConfig    `yaml:"activity" mapstructure:"activity"`
-	KeepAlive   KeepAliveConfig   `yaml:"keepalive" mapstructure:"keepalive"`
-	SSH         SSHConfig         `yaml:"ssh" mapstructure:"ssh"`
-	Logging     LoggingConfig     `yaml:"logging" mapstructure:"logging"`
-	PortForward PortForwardConfig `yaml:"port_forward" mapstructure:"port_forward"`
+	// Provider selects the cloud backend that provisions servers
+	Provider     string             `yaml:"provider" mapstructure:"provider" default:"hetzner"`
+	Hetzner      HetznerConfig      `yaml:"hetzner" mapstructure:"hetzner"`
+	DigitalOcean DigitalOceanConfig `yaml:"digitalocean" mapstructure:"digitalocean"`
+	Docker       DockerConfig       `yaml:"docker" mapstructure:"docker"`
+	Activity     ActivityConfig     `yaml:"activity" mapstructure:"activity"`
+	KeepAlive    KeepAliveConfig    `yaml:"keepalive" mapstructure:"keepalive"`
+	SSH          SSHConfig          `yaml:"ssh" mapstructure:"ssh"`
+	Logging      LoggingConfig      `yaml:"logging" mapstructure:"logging"`
+	PortForward  PortForwardConfig  `yaml:"port_forward" mapstructure:"port_forward"`
+	Contexts     []ContextConfig    `yaml:"contexts" mapstructure:"contexts"`
+	Profiles     []ProfileConfig    `yaml:"profiles" mapstructure:"profiles"`
+	// CurrentContext is the context selected with "dockbridge context use"; empty is the default
+	CurrentContext string              `yaml:"current_context" mapstructure:"current_context"`
+	Notifications  NotificationsConfig `yaml:"notifications" mapstructure:"notifications"`
+	Traffic        TrafficConfig       `yaml:"traffic" mapstructure:"traffic"`
+	Hooks          []HookConfig        `yaml:"hooks" mapstructure:"hooks"`
+	Control        ControlConfig       `yaml:"control" mapstructure:"control"`
+	Metrics        MetricsConfig       `yaml:"metrics" mapstructure:"metrics"`
+	Telemetry      TelemetryConfig     `yaml:"telemetry" mapstructure:"telemetry"`
+	Budget         BudgetConfig        `yaml:"budget" mapstructure:"budget"`
+	Backup         BackupConfig        `yaml:"backup" mapstructure:"backup"`
+	Provisioning   ProvisioningConfig  `yaml:"provisioning" mapstructure:"provisioning"`
+	FileSync       FileSyncConfig      `yaml:"file_sync" mapstructure:"file_sync"`
+	Secrets        SecretsConfig       `yaml:"secrets" mapstructure:"secrets"`
+	Schedule       ScheduleConfig      `yaml:"schedule" mapstructure:"schedule"`
+	ServerBinary   ServerBinaryConfig  `yaml:"server_binary" mapstructure:"server_binary"`
+}
+
+// ServerBinaryConfig selects how new servers get the dockbridge-server binary. By
+// default cloud-init downloads it from GitHub; with the upload source the client sends
+// it over SSH once the server is up, so servers need no access to the internet.
+type ServerBinaryConfig struct {
+	// Source is "download" (cloud-init fetches the release) or "upload" (the client
+	// copies the binary to the server)
+	Source string `yaml:"source" mapstructure:"source" default:"download"`
+
+	// Path is a local linux dockbridge-server binary uploaded to servers; when empty, the
+	// release binary of this client's version is downloaded here and cached
+	Path string `yaml:"path" mapstructure:"path"`
+}
+
+// ScheduleConfig configures working hours: outside them the server is destroyed or
+// powered off once the client is idle, and shortly before they start it is provisioned
+// so that the first Docker command of the day does not wait for it
+type ScheduleConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	// Timezone is the IANA time zone of the working hours, e.g. "Europe/Berlin"; empty
+	// uses the local time zone
+	Timezone string `yaml:"timezone" mapstructure:"timezone"`
+	// WorkingHours is the daily range "HH:MM-HH:MM" the server is kept available
+	WorkingHours string `yaml:"working_hours" mapstructure:"working_hours" default:"09:00-18:00"`
+	// Days are the working days, as "mon" to "sun"
+	Days []string `yaml:"days" mapstructure:"days" default:"[\"mon\",\"tue\",\"wed\",\"thu\",\"fri\"]"`
+	// Prewarm is how long before the working hours the server is provisioned; 0 disables it
+	Prewarm time.Duration `yaml:"prewarm" mapstructure:"prewarm" default:"10m"`
+	// OffHoursIdle is how long the client must be idle outside the working hours before
+	// the server is shut down
+	OffHoursIdle time.Duration `yaml:"off_hours_idle" mapstructure:"off_hours_idle" default:"10m"`
+	// Action is what happens to the server outside the working hours: destroy it or power it off
+	Action LifecyclePolicy `yaml:"action" mapstructure:"action" default:"destroy"`
+	// Holidays are dates ("YYYY-MM-DD") without working hours
+	Holidays []string `yaml:"holidays" mapstructure:"holidays"`
+	// Exceptions replace the working hours of single dates, e.g. a working Saturday
+	Exceptions []ScheduleException `yaml:"exceptions" mapstructure:"exceptions"`
+}
+
+// ScheduleException sets the working hours of a single date
+type ScheduleException struct {
+	// Date is "YYYY-MM-DD"
+	Date string `yaml:"date" mapstructure:"date"`
+	// Hours is "HH:MM-HH:MM"
+	Hours string `yaml:"hours" mapstructure:"hours"`
+}
+
+// SecretsConfig selects where "dockbridge auth login" stores provider API tokens. A
+// token set in the configuration file or the environment takes precedence.
+type SecretsConfig struct {
+	// Backend is "keychain" (macOS), "secret-service" (Linux), "file" (encrypted with
+	// DOCKBRIDGE_SECRETS_PASSPHRASE), "none", or "auto" for the OS keyring if available
+	Backend string `yaml:"backend" mapstructure:"backend" default:"auto"`
+	// File is the encrypted file of the file backend
+	File string `yaml:"file" mapstructure:"file" default:"~/.dockbridge/secrets.enc"`
+}
+
+// FileSyncConfig configures the emulation of bind mounts of local directories: the
+// source of such a mount is replaced by a directory on the server kept in sync with the
+// local one in both directions
+type FileSyncConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	// Paths are the local directories whose bind mounts are synced, including their
+	// subdirectories; other bind mounts are passed to the server unchanged
+	Paths []string `yaml:"paths" mapstructure:"paths"`
+	// Exclude lists patterns of files not synced, like ".git" or "node_modules"
+	Exclude  []string      `yaml:"exclude" mapstructure:"exclude" default:"[\".git\"]"`
+	Interval time.Duration `yaml:"interval" mapstructure:"interval" default:"2s"`
+	// RemoteRoot is the directory on the server holding the synced copies
+	RemoteRoot string `yaml:"remote_root" mapstructure:"remote_root" default:"/var/lib/docker/dockbridge-sync"`
+}
+
+// ProvisioningConfig declares extra steps and files merged into the setup of every new
+// server, e.g. to install company CA certificates or a monitoring agent
+type ProvisioningConfig struct {
+	// PreDocker steps run before Docker is installed and configured
+	PreDocker []ProvisioningStep `yaml:"pre_docker" mapstructure:"pre_docker"`
+	// PostDocker steps run once Docker is running
+	PostDocker []ProvisioningStep `yaml:"post_docker" mapstructure:"post_docker"`
+	// WriteFiles are written before any step runs
+	WriteFiles []ProvisioningFile `yaml:"write_files" mapstructure:"write_files"`
+}
+
+// ProvisioningStep is an inline command or a local script run as root on the server;
+// exactly one of Run and Script is set. A failing step fails the server setup.
+type ProvisioningStep struct {
+	// Run holds shell commands, run with bash
+	Run string `yaml:"run" mapstructure:"run"`
+	// Script is the path of a local script, copied to the server and executed
+	Script string `yaml:"script" mapstructure:"script"`
+}
+
+// ProvisioningFile is a file written on the server; exactly one of Content and
+// Source is set
+type ProvisioningFile struct {
+	// Path is the absolute path of the file on the server
+	Path    string `yaml:"path" mapstructure:"path"`
+	Content string `yaml:"content" mapstructure:"content"`
+	// Source is the path of a local file to copy
+	Source string `yaml:"source" mapstructure:"source"`
+	// Permissions in octal, e.g. "0644" (default)
+	Permissions string `yaml:"permissions" mapstructure:"permissions"`
+	// Owner as user:group (default root:root)
+	Owner string `yaml:"owner" mapstructure:"owner"`
+}
+
+// BackupConfig configures scheduled restic backups of Docker volumes on the server to
+// S3-compatible or Backblaze B2 storage
+type BackupConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	// Repository is a restic repository: "s3:<endpoint>/<bucket>/<path>" or "b2:<bucket>:<path>"
+	Repository string `yaml:"repository" mapstructure:"repository"`
+	// Password encrypts the repository; backups cannot be restored without it
+	Password        string `yaml:"password" mapstructure:"password"`
+	AccessKeyID     string `yaml:"access_key_id" mapstructure:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" mapstructure:"secret_access_key"`
+	// Paths on the server to back up
+	Paths       []string      `yaml:"paths" mapstructure:"paths" default:"[\"/var/lib/docker/volumes\"]"`
+	Interval    time.Duration `yaml:"interval" mapstructure:"interval" default:"6h"`
+	KeepDaily   int           `yaml:"keep_daily" mapstructure:"keep_daily" default:"7"`
+	KeepWeekly  int           `yaml:"keep_weekly" mapstructure:"keep_weekly" default:"4"`
+	KeepMonthly int           `yaml:"keep_monthly" mapstructure:"keep_monthly" default:"6"`
+}
+
+// BudgetConfig configures the monthly spending limit on estimated server costs
+type BudgetConfig struct {
+	// Monthly is the limit in the provider's currency (EUR for Hetzner); 0 disables it
+	Monthly     float64 `yaml:"monthly" mapstructure:"monthly" default:"0"`
+	WarnPercent int     `yaml:"warn_percent" mapstructure:"warn_percent" default:"80"`
+	// Action taken once the budget is exceeded: "warn" or "block" new provisioning
+	Action string `yaml:"action" mapstructure:"action" default:"warn"`
+}
+
+// TelemetryConfig configures OpenTelemetry tracing of the client daemon
+type TelemetryConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	// Endpoint is the OTLP/HTTP collector as host:port; empty uses
+	// OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	// Insecure sends spans over plain HTTP
+	Insecure    bool    `yaml:"insecure" mapstructure:"insecure" default:"true"`
+	ServiceName string  `yaml:"service_name" mapstructure:"service_name" default:"dockbridge"`
+	SampleRatio float64 `yaml:"sample_ratio" mapstructure:"sample_ratio" default:"1"`
+}
+
+// MetricsConfig configures the Prometheus metrics endpoint of the client daemon
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	// Listen is the address serving /metrics
+	Listen string `yaml:"listen" mapstructure:"listen" default:"127.0.0.1:9466"`
+}
+
+// ControlConfig configures the gRPC control API served by the client daemon
+type ControlConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"true"`
+	// SocketPath of the API's Unix socket; empty uses ~/.dockbridge/control.sock
+	SocketPath string `yaml:"socket_path" mapstructure:"socket_path"`
+	// HTTPListen is a loopback host:port serving the API as REST/JSON; empty disables it
+	HTTPListen string `yaml:"http_listen" mapstructure:"http_listen"`
+}
+
+// HookConfig configures a lifecycle hook; exactly one of Command and URL is set
+type HookConfig struct {
+	Name    string            `yaml:"name" mapstructure:"name"`
+	Events  []string          `yaml:"events" mapstructure:"events"`
+	Command []string          `yaml:"command" mapstructure:"command"`
+	URL     string            `yaml:"url" mapstructure:"url"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Timeout time.Duration     `yaml:"timeout" mapstructure:"timeout" default:"30s"`
+}
+
+// TrafficConfig contains thresholds for Hetzner included-traffic warnings
+type TrafficConfig struct {
+	WarnPercent     int `yaml:"warn_percent" mapstructure:"warn_percent" default:"80"`
+	CriticalPercent int `yaml:"critical_percent" mapstructure:"critical_percent" default:"95"`
+}
+
+// ContextConfig describes an additional remote daemon exposed on its own local socket.
+// Each context gets its own server, keep-alive and lifecycle; unset fields fall back
+// to the top-level hetzner section.
+type ContextConfig struct {
+	Name       string `yaml:"name" mapstructure:"name"`
+	SocketPath string `yaml:"socket_path" mapstructure:"socket_path"`
+	ServerType string `yaml:"server_type" mapstructure:"server_type"`
+	Location   string `yaml:"location" mapstructure:"location"`
+	VolumeSize int    `yaml:"volume_size" mapstructure:"volume_size"`
+}
+
+// ServerSettings is the provider-neutral shape of the servers DockBridge provisions.
+// Every provider maps its own config section onto it, and contexts override it field
+// by field.
+type ServerSettings struct {
+	APIToken   string
+	ServerType string
+	Location   string
+	VolumeSize int
+}
+
+// ProfileConfig is a named set of server settings selected with --profile or
+// DOCKBRIDGE_PROFILE, e.g. another Hetzner project or a larger instance for heavyweight
+// builds. Unset fields are inherited from the Inherits profile, if any, and otherwise
+// keep the configured value.
+type ProfileConfig struct {
+	Name string `yaml:"name" mapstructure:"name"`
+	// Inherits names the profile whose settings are used for fields left unset
+	Inherits string `yaml:"inherits" mapstructure:"inherits"`
+	// APIToken is the provider API token, selecting another project or account
+	APIToken   string `yaml:"api_token" mapstructure:"api_token"`
+	ServerType string `yaml:"server_type" mapstructure:"server_type"`
+	Location   string `yaml:"location" mapstructure:"location"`
+	VolumeSize int    `yaml:"volume_size" mapstructure:"volume_size"`
+	// Schedule replaces the working hours schedule, e.g. for another time zone
+	Schedule *ScheduleConfig `yaml:"schedule" mapstructure:"schedule"`
+}
+
+// DigitalOceanConfig configures the DigitalOcean provider (provider: digitalocean)
+type DigitalOceanConfig struct {
+	APIToken   string `yaml:"api_token" mapstructure:"api_token"`
+	Region     string `yaml:"region" mapstructure:"region" default:"fra1"`
+	Size       string `yaml:"size" mapstructure:"size" default:"s-2vcpu-4gb"`
+	VolumeSize int    `yaml:"volume_size" mapstructure:"volume_size" default:"10"`
+	Image      string `yaml:"image" mapstructure:"image" default:"ubuntu-24-04-x64"`
+}
+
+// ServerSettings returns the provider-neutral part of the DigitalOcean configuration
+func (c DigitalOceanConfig) ServerSettings() ServerSettings {
+	return ServerSettings{
+		APIToken:   c.APIToken,
+		ServerType: c.Size,
+		Location:   c.Region,
+		VolumeSize: c.VolumeSize,
+	}
+}
+
+// ServerSettings returns the server settings of the selected provider
+func (c *ClientConfig) ServerSettings() ServerSettings {
+	if c.Provider == "digitalocean" {
+		return c.DigitalOcean.ServerSettings()
+	}
+	return c.Hetzner.ServerSettings()
+}
+
+// SetServerSettings stores settings in the section of the selected provider; it is the
+// inverse of ServerSettings
+func (c *ClientConfig) SetServerSettings(settings ServerSettings) {
+	if c.Provider != "digitalocean" {
+		c.Hetzner = c.Hetzner.WithServerSettings(settings)
+		return
+	}
+
+	c.DigitalOcean.APIToken = settings.APIToken
+	c.DigitalOcean.Size = settings.ServerType
+	c.DigitalOcean.Region = settings.Location
+	c.DigitalOcean.VolumeSize = settings.VolumeSize
+}
+
+// SettingsFor returns the server settings for the context, inheriting unset fields from base
+func (c ContextConfig) SettingsFor(base ServerSettings) ServerSettings {
+	merged := base
+	if c.ServerType != "" {
+		merged.ServerType = c.ServerType
+	}
+	if c.Location != "" {
+		merged.Location = c.Location
+	}
+	if c.VolumeSize != 0 {
+		merged.VolumeSize = c.VolumeSize
+	}
+	return merged
+}
+
+// SettingsFor returns the server settings with the profile applied on top of base
+func (p ProfileConfig) SettingsFor(base ServerSettings) ServerSettings {
+	merged := ContextConfig{
+		ServerType: p.ServerType,
+		Location:   p.Location,
+		VolumeSize: p.VolumeSize,
+	}.SettingsFor(base)
+	if p.APIToken != "" {
+		merged.APIToken = p.APIToken
+	}
+	return merged
+}
+
+// Inherit returns the profile with its unset fields taken from parent
+func (p ProfileConfig) Inherit(parent ProfileConfig) ProfileConfig {
+	merged := p
+	if merged.APIToken == "" {
+		merged.APIToken = parent.APIToken
+	}
+	if merged.ServerType == "" {
+		merged.ServerType = parent.ServerType
+	}
+	if merged.Location == "" {
+		merged.Location = parent.Location
+	}
+	if merged.VolumeSize == 0 {
+		merged.VolumeSize = parent.VolumeSize
+	}
+	if merged.Schedule == nil {
+		merged.Schedule = parent.Schedule
+	}
+	return merged
 }
 
 // ServerConfig represents the complete server configuration
@@ -22,17 +346,246 @@ type ServerConfig struct {
 
 // HetznerConfig contains Hetzner Cloud API configuration
 type HetznerConfig struct {
-	APIToken        string   `yaml:"api_token" mapstructure:"api_token" env:"HETZNER_API_TOKEN"`
-	ServerType      string   `yaml:"server_type" mapstructure:"server_type" default:"cpx21"`
-	Location        string   `yaml:"location" mapstructure:"location" default:"fsn1"`
-	VolumeSize      int      `yaml:"volume_size" mapstructure:"volume_size" default:"10"`
-	PreferredImages []string `yaml:"preferred_images" mapstructure:"preferred_images" default:"[\"docker-ce\", \"ubuntu-22.04\"]"`
+	APIToken        string            `yaml:"api_token" mapstructure:"api_token" env:"HETZNER_API_TOKEN"`
+	ServerType      string            `yaml:"server_type" mapstructure:"server_type" default:"cpx21"`
+	Location        string            `yaml:"location" mapstructure:"location" default:"fsn1"`
+	VolumeSize      int               `yaml:"volume_size" mapstructure:"volume_size" default:"10"`
+	PreferredImages []string          `yaml:"preferred_images" mapstructure:"preferred_images" default:"[\"docker-ce\", \"ubuntu-22.04\"]"`
+	OSUpdates       OSUpdatesConfig   `yaml:"os_updates" mapstructure:"os_updates"`
+	GoldenImage     GoldenImageConfig `yaml:"golden_image" mapstructure:"golden_image"`
+
+	// Volumes are the persistent volumes attached to every server. When empty, a single
+	// Docker data volume of VolumeSize is mounted at DockerDataMount.
+	Volumes []VolumeConfig `yaml:"volumes" mapstructure:"volumes"`
+
+	// EncryptVolumes encrypts the volumes with LUKS. Their keys stay on the client and
+	// are handed to the server over SSH to unlock them; requires Volumes.
+	EncryptVolumes bool `yaml:"encrypt_volumes" mapstructure:"encrypt_volumes"`
+
+	// Network attaches servers to an existing private network, by name or ID
+	Network string `yaml:"network" mapstructure:"network"`
+
+	// Firewall applies a Hetzner Cloud firewall to servers in front of UFW
+	Firewall FirewallConfig `yaml:"firewall" mapstructure:"firewall"`
+
+	// PlacementGroup adds servers to a spread placement group of this name, created
+	// if missing
+	PlacementGroup string `yaml:"placement_group" mapstructure:"placement_group"`
+
+	// PublicNetwork selects the public addresses of servers: dual (IPv4 and IPv6), ipv6
+	// (no billed IPv4) or none (private network only, reached through ssh.jump_host)
+	PublicNetwork string `yaml:"public_network" mapstructure:"public_network" default:"dual"`
+}
+
+// FirewallConfig selects the cloud firewall applied to servers. An existing firewall
+// of the name is applied as it is; a missing one is created and managed by DockBridge,
+// allowing inbound traffic only to the ports the client uses.
+type FirewallConfig struct {
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// SourceIPs restrict the inbound rules of a created firewall to these CIDRs
+	// (default: anywhere)
+	SourceIPs []string `yaml:"source_ips" mapstructure:"source_ips"`
+}
+
+// Defaults of the implicit Docker data volume
+const (
+	DockerDataVolume = "docker-data"
+	DockerDataMount  = "/var/lib/docker"
+)
+
+// VolumeConfig is a named persistent volume and where servers mount it. Volumes are
+// reused by name across servers, so their data survives server replacement.
+type VolumeConfig struct {
+	Name  string `yaml:"name" mapstructure:"name"`
+	Size  int    `yaml:"size" mapstructure:"size"`
+	Mount string `yaml:"mount" mapstructure:"mount"`
+}
+
+// DataVolumes returns the persistent volumes of a server: the configured volumes, or
+// a single Docker data volume of VolumeSize
+func (h HetznerConfig) DataVolumes() []VolumeConfig {
+	if len(h.Volumes) > 0 {
+		return h.Volumes
+	}
+	return []VolumeConfig{{Name: DockerDataVolume, Size: h.VolumeSize, Mount: DockerDataMount}}
+}
+
+// DataVolumeSize returns the total size of the server's persistent volumes in GB
+func (h HetznerConfig) DataVolumeSize() int {
+	total := 0
+	for _, volume := range h.DataVolumes() {
+		total += volume.Size
+	}
+	return total
+}
+
+// GoldenImageConfig controls snapshot-based golden images: after the first server is
+// set up, it is snapshotted and later servers boot from the snapshot with Docker
+// preinstalled instead of running the full installation
+type GoldenImageConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+}
+
+// ServerSettings returns the provider-neutral part of the Hetzner configuration
+func (c HetznerConfig) ServerSettings() ServerSettings {
+	return ServerSettings{
+		APIToken:   c.APIToken,
+		ServerType: c.ServerType,
+		Location:   c.Location,
+		VolumeSize: c.VolumeSize,
+	}
+}
+
+// WithServerSettings returns the configuration with its server shape replaced by settings,
+// keeping the remaining server options
+func (c HetznerConfig) WithServerSettings(settings ServerSettings) HetznerConfig {
+	c.APIToken = settings.APIToken
+	c.ServerType = settings.ServerType
+	c.Location = settings.Location
+	c.VolumeSize = settings.VolumeSize
+	return c
+}
+
+// OSUpdatesConfig controls managed unattended OS upgrades for long-lived servers
+type OSUpdatesConfig struct {
+	Enabled      bool   `yaml:"enabled" mapstructure:"enabled" default:"false"`
+	SecurityOnly bool   `yaml:"security_only" mapstructure:"security_only" default:"true"`
+	AutoReboot   bool   `yaml:"auto_reboot" mapstructure:"auto_reboot" default:"true"`
+	RebootWindow string `yaml:"reboot_window" mapstructure:"reboot_window" default:"03:00-05:00"`
 }
 
 // DockerConfig contains Docker-related configuration
 type DockerConfig struct {
-	SocketPath string `yaml:"socket_path" mapstructure:"socket_path" default:"/var/run/docker.sock"`
-	ProxyPort  int    `yaml:"proxy_port" mapstructure:"proxy_port" default:"2376"`
+	SocketPath string          `yaml:"socket_path" mapstructure:"socket_path" default:"/var/run/docker.sock"`
+	ProxyPort  int             `yaml:"proxy_port" mapstructure:"proxy_port" default:"2376"`
+	CacheTTL   time.Duration   `yaml:"cache_ttl" mapstructure:"cache_ttl" default:"2s"`
+	TLS        DockerTLSConfig `yaml:"tls" mapstructure:"tls"`
+
+	// RemoteTransport is how the remote Docker API is reached over SSH: "tcp" (dockerd
+	// also listens on TCP 2376) or "unix" (dockerd only listens on /var/run/docker.sock)
+	RemoteTransport string `yaml:"remote_transport" mapstructure:"remote_transport" default:"tcp"`
+
+	// Context registers the local sockets as Docker CLI contexts while the client runs
+	Context DockerContextConfig `yaml:"context" mapstructure:"context"`
+
+	// RequestQueue holds Docker API requests while the server is provisioned or reconnected
+	RequestQueue RequestQueueConfig `yaml:"request_queue" mapstructure:"request_queue"`
+
+	// RegistryAuth lists the registries whose credentials are copied to the server's
+	// Docker config on every connection
+	RegistryAuth []RegistryAuthConfig `yaml:"registry_auth" mapstructure:"registry_auth"`
+
+	// BuildContextSync sends the context of classic builds by content, so files the
+	// server received with earlier builds are not sent again
+	BuildContextSync bool `yaml:"build_context_sync" mapstructure:"build_context_sync" default:"false"`
+
+	// Compression compresses bulk transfers through the tunnel
+	Compression CompressionConfig `yaml:"compression" mapstructure:"compression"`
+
+	// RegistryMirror runs a pull-through cache of Docker Hub on the server
+	RegistryMirror RegistryMirrorConfig `yaml:"registry_mirror" mapstructure:"registry_mirror"`
+
+	// LocalSemantics decides what happens to containers relying on this machine, through
+	// bind mounts of local paths or host networking, which behave differently on the server
+	LocalSemantics LocalSemanticsPolicy `yaml:"local_semantics" mapstructure:"local_semantics" default:"warn"`
+
+	// TCPListener also serves the Docker API on a local TCP address, for tools that only
+	// support tcp:// Docker hosts
+	TCPListener TCPListenerConfig `yaml:"tcp_listener" mapstructure:"tcp_listener"`
+}
+
+// TCPListenerConfig configures the optional TCP listener of the default daemon
+type TCPListenerConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+
+	// Listen is the address of the listener. Addresses other than loopback require
+	// client certificates.
+	Listen string `yaml:"listen" mapstructure:"listen" default:"127.0.0.1:2375"`
+
+	// CertFile and KeyFile serve the listener over TLS
+	CertFile string `yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile  string `yaml:"key_file" mapstructure:"key_file"`
+
+	// ClientCAFile requires clients to present a certificate signed by this CA
+	ClientCAFile string `yaml:"client_ca_file" mapstructure:"client_ca_file"`
+}
+
+// LocalSemanticsPolicy defines how containers relying on the local machine are handled
+type LocalSemanticsPolicy string
+
+const (
+	LocalSemanticsWarn   LocalSemanticsPolicy = "warn"   // Create the container with warnings explaining the difference
+	LocalSemanticsBlock  LocalSemanticsPolicy = "block"  // Reject the container with a Docker error
+	LocalSemanticsIgnore LocalSemanticsPolicy = "ignore" // Create the container without checks
+)
+
+// RegistryMirrorConfig controls a pull-through cache of Docker Hub on the server. The
+// server's dockerd pulls Docker Hub images through it, and its storage lives on the
+// Docker data volume, so images are not downloaded again after the server is recreated.
+type RegistryMirrorConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+
+	// Port is where the cache listens on the server's loopback interface
+	Port int `yaml:"port" mapstructure:"port" default:"5000"`
+
+	// Image is the registry image run in pull-through mode
+	Image string `yaml:"image" mapstructure:"image" default:"registry:2"`
+}
+
+// CompressionConfig controls compression of archives sent through the tunnel: build
+// contexts, image loads and saves, container exports and docker cp. Data that is
+// compressed already, such as compressed image layers, is sent as is.
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"false"`
+
+	// Level is the compression level, from 1 (fastest) to 9 (smallest)
+	Level int `yaml:"level" mapstructure:"level" default:"1"`
+}
+
+// RegistryAuthConfig selects a registry whose credentials are forwarded to the server.
+// Without a username, they are taken from the local Docker config, including its
+// credential helpers.
+type RegistryAuthConfig struct {
+	// Registry is the registry host, e.g. "registry.example.com" or "docker.io"
+	Registry string `yaml:"registry" mapstructure:"registry"`
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
+}
+
+// RequestQueueConfig bounds the Docker API requests held while the daemon connects to,
+// provisions or resumes a server; they are relayed once the connection is up
+type RequestQueueConfig struct {
+	// Depth is the number of requests held at once; further requests are answered with
+	// 503 right away. Zero disables queuing.
+	Depth int `yaml:"depth" mapstructure:"depth" default:"64"`
+
+	// MaxWait is how long a request is held before it is answered with 503
+	MaxWait time.Duration `yaml:"max_wait" mapstructure:"max_wait" default:"10m"`
+}
+
+// DockerContextConfig configures the Docker CLI contexts registered for the local
+// sockets: "dockbridge" for the default daemon and "dockbridge-<name>" per context
+type DockerContextConfig struct {
+	// Register creates or updates the contexts when the client starts
+	Register bool `yaml:"register" mapstructure:"register" default:"true"`
+
+	// Use switches the Docker CLI to the context of current_context on start and
+	// back to the previous context on shutdown
+	Use bool `yaml:"use" mapstructure:"use" default:"false"`
+
+	// RemoveOnExit removes the contexts again on shutdown
+	RemoveOnExit bool `yaml:"remove_on_exit" mapstructure:"remove_on_exit" default:"true"`
+}
+
+// DockerTLSConfig configures how the client authenticates to the remote Docker daemon
+type DockerTLSConfig struct {
+	// Mode is "mtls" (certificates generated during provisioning, plaintext over the
+	// tunnel only for servers without certificates) or "off" (always plaintext over the tunnel)
+	Mode string `yaml:"mode" mapstructure:"mode" default:"mtls"`
+
+	// CertDir holds the client certificates of each server
+	CertDir string `yaml:"cert_dir" mapstructure:"cert_dir" default:"~/.dockbridge/certs"`
 }
 
 // KeepAliveConfig contains keep-alive mechanism configuration
@@ -41,14 +594,70 @@ type KeepAliveConfig struct {
 	Timeout       time.Duration `yaml:"timeout" mapstructure:"timeout" default:"5m"`
 	RetryInterval time.Duration `yaml:"retry_interval" mapstructure:"retry_interval" default:"5s"`
 	MaxRetries    int           `yaml:"max_retries" mapstructure:"max_retries" default:"3"`
+	SleepHint     time.Duration `yaml:"sleep_hint" mapstructure:"sleep_hint" default:"10m"`
+	// Transport delivers heartbeats to the server's public keep-alive port ("http") or
+	// through the SSH connection to its loopback interface ("ssh")
+	Transport string `yaml:"transport" mapstructure:"transport" default:"http"`
+	// ReprovisionOnWake replaces a server that destroyed itself while the client was
+	// suspended as soon as the client resumes, instead of on the next Docker command
+	ReprovisionOnWake bool `yaml:"reprovision_on_wake" mapstructure:"reprovision_on_wake" default:"true"`
+	// ClientID identifies this client's heartbeats when several clients keep one server
+	// alive; the server only destroys itself once all of them are gone. Empty is the
+	// host name.
+	ClientID string `yaml:"client_id" mapstructure:"client_id"`
 }
 
 // SSHConfig contains SSH connection configuration
 type SSHConfig struct {
 	KeyPath   string        `yaml:"key_path" mapstructure:"key_path" default:"~/.dockbridge/ssh/id_rsa"`
+	KeyType   string        `yaml:"key_type" mapstructure:"key_type" default:"ed25519"` // ed25519 or rsa, used when generating a missing key
 	Port      int           `yaml:"port" mapstructure:"port" default:"22"`
 	Timeout   time.Duration `yaml:"timeout" mapstructure:"timeout" default:"30s"`
 	KeepAlive time.Duration `yaml:"keep_alive" mapstructure:"keep_alive" default:"30s"`
+
+	// UseAgent authenticates with ssh-agent keys before falling back to the key file
+	UseAgent bool `yaml:"use_agent" mapstructure:"use_agent" default:"true"`
+
+	// AgentSocket overrides SSH_AUTH_SOCK
+	AgentSocket string `yaml:"agent_socket" mapstructure:"agent_socket"`
+
+	// KnownHostsPath stores the host keys of provisioned servers
+	KnownHostsPath string `yaml:"known_hosts_path" mapstructure:"known_hosts_path" default:"~/.dockbridge/known_hosts"`
+
+	// HostKeyChecking is tofu (trust on first use), strict or off
+	HostKeyChecking string `yaml:"host_key_checking" mapstructure:"host_key_checking" default:"tofu"`
+
+	// Reconnect re-establishes dropped SSH connections found by keep-alive probes
+	Reconnect SSHReconnectConfig `yaml:"reconnect" mapstructure:"reconnect"`
+
+	// ChannelPool spreads concurrent Docker API connections over several SSH connections
+	ChannelPool SSHChannelPoolConfig `yaml:"channel_pool" mapstructure:"channel_pool"`
+
+	// JumpHost ([user@]host[:port]) relays SSH connections to servers, e.g. servers
+	// without public addresses; its host key is checked like the servers'
+	JumpHost string `yaml:"jump_host" mapstructure:"jump_host"`
+}
+
+// SSHChannelPoolConfig bounds the SSH channels concurrent Docker API connections use
+type SSHChannelPoolConfig struct {
+	// Connections is the maximum number of SSH connections to the server; extra ones are
+	// opened once the existing ones carry MaxChannels channels each
+	Connections int `yaml:"connections" mapstructure:"connections" default:"4"`
+	// MaxChannels is the maximum number of concurrent channels per connection (0 is unlimited)
+	MaxChannels int `yaml:"max_channels" mapstructure:"max_channels" default:"32"`
+	// AcquireTimeout bounds how long a Docker API connection waits for a free channel
+	AcquireTimeout time.Duration `yaml:"acquire_timeout" mapstructure:"acquire_timeout" default:"30s"`
+}
+
+// SSHReconnectConfig configures automatic reconnection of dropped SSH tunnels
+type SSHReconnectConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled" default:"true"`
+	// MaxMissed is the number of consecutive failed keep-alive probes after which the
+	// connection is considered dead
+	MaxMissed int `yaml:"max_missed" mapstructure:"max_missed" default:"2"`
+	// MaxAttempts bounds the reconnect attempts (0 is unlimited) before falling back to a
+	// full reconnect on the next Docker request
+	MaxAttempts int `yaml:"max_attempts" mapstructure:"max_attempts" default:"5"`
 }
 
 // ActivityConfig contains activity tracking and timeout configuration
@@ -56,8 +665,18 @@ type ActivityConfig struct {
 	IdleTimeout       time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout" default:"5m"`
 	ConnectionTimeout time.Duration `yaml:"connection_timeout" mapstructure:"connection_timeout" default:"30m"`
 	GracePeriod       time.Duration `yaml:"grace_period" mapstructure:"grace_period" default:"30s"`
+	// LifecyclePolicy is what happens to an idle server: destroy it or power it off
+	LifecyclePolicy LifecyclePolicy `yaml:"lifecycle_policy" mapstructure:"lifecycle_policy" default:"destroy"`
 }
 
+// LifecyclePolicy defines what happens to a server once the client is idle
+type LifecyclePolicy string
+
+const (
+	LifecyclePolicyDestroy  LifecyclePolicy = "destroy"  // Delete the server, preserving the volume
+	LifecyclePolicyPowerOff LifecyclePolicy = "poweroff" // Power the server off; it is resumed on the next command
+)
+
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
 	Level  string `yaml:"level" mapstructure:"level" default:"info"`
@@ -68,14 +687,64 @@ type LoggingConfig struct {
 // PortForwardConfig contains port forwarding configuration
 type PortForwardConfig struct {
 	Enabled          bool             `yaml:"enabled" mapstructure:"enabled" default:"true"`
-	ConflictStrategy ConflictStrategy `yaml:"conflict_strategy" mapstructure:"conflict_strategy" default:"increment"`
+	ConflictStrategy ConflictStrategy `yaml:"conflict_strategy" mapstructure:"conflict_strategy" default:"auto"`
 	MonitorInterval  time.Duration    `yaml:"monitor_interval" mapstructure:"monitor_interval" default:"30s"`
+	SocketDir        string           `yaml:"socket_dir" mapstructure:"socket_dir" default:"~/.dockbridge/sockets"`
+	ProxyProtocol    bool             `yaml:"proxy_protocol" mapstructure:"proxy_protocol" default:"false"`
+	BindAddress      string           `yaml:"bind_address" mapstructure:"bind_address" default:"127.0.0.1"`
+	AllowExternal    bool             `yaml:"allow_external" mapstructure:"allow_external" default:"false"`
+
+	// Projects selects the docker compose projects whose ports are forwarded
+	Projects ProjectFilterConfig `yaml:"projects" mapstructure:"projects"`
+}
+
+// ProjectFilterConfig selects docker compose projects by name; entries are shell
+// patterns such as "shop-*". Containers outside compose are always forwarded.
+type ProjectFilterConfig struct {
+	// Allow, when set, forwards only the projects it matches
+	Allow []string `yaml:"allow" mapstructure:"allow"`
+
+	// Deny never forwards the projects it matches, even if they are allowed
+	Deny []string `yaml:"deny" mapstructure:"deny"`
+}
+
+// NotificationsConfig contains notification settings for lifecycle events
+type NotificationsConfig struct {
+	Desktop DesktopNotificationsConfig `yaml:"desktop" mapstructure:"desktop"`
+	// Sinks receive the lifecycle events of the daemon's event bus
+	Sinks []NotificationSinkConfig `yaml:"sinks" mapstructure:"sinks"`
+}
+
+// NotificationSinkConfig sends lifecycle events to the daemon log, a webhook, a Slack
+// channel or desktop notifications
+type NotificationSinkConfig struct {
+	Name string `yaml:"name" mapstructure:"name"`
+	// Type is log, webhook, slack or desktop
+	Type   string   `yaml:"type" mapstructure:"type"`
+	Events []string `yaml:"events" mapstructure:"events"`
+	// URL of the webhook, or the incoming webhook of a Slack channel
+	URL     string            `yaml:"url" mapstructure:"url"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Timeout time.Duration     `yaml:"timeout" mapstructure:"timeout" default:"30s"`
+}
+
+// DesktopNotificationsConfig enables native desktop notifications per event type
+type DesktopNotificationsConfig struct {
+	Enabled            bool `yaml:"enabled" mapstructure:"enabled" default:"true"`
+	ServerReady        bool `yaml:"server_ready" mapstructure:"server_ready" default:"true"`
+	ProvisioningFailed bool `yaml:"provisioning_failed" mapstructure:"provisioning_failed" default:"true"`
+	KeepAliveWarning   bool `yaml:"keepalive_warning" mapstructure:"keepalive_warning" default:"true"`
+	SelfDestruct       bool `yaml:"self_destruct" mapstructure:"self_destruct" default:"true"`
+	BudgetThreshold    bool `yaml:"budget_threshold" mapstructure:"budget_threshold" default:"true"`
+	TrafficWarning     bool `yaml:"traffic_warning" mapstructure:"traffic_warning" default:"true"`
 }
 
 // ConflictStrategy defines how to handle port conflicts
 type ConflictStrategy string
 
 const (
-	ConflictStrategyIncrement ConflictStrategy = "increment" // Find next available port
+	ConflictStrategyAuto      ConflictStrategy = "auto"      // Pick a free port and report it in Docker API responses
+	ConflictStrategyIncrement ConflictStrategy = "increment" // Same as auto; kept for existing configs
 	ConflictStrategyFail      ConflictStrategy = "fail"      // Return Docker error
+	ConflictStrategyReplace   ConflictStrategy = "replace"   // Evict the older forward holding the port
 )
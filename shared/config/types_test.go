@@ -30,3 +30,45 @@ func TestKeepAliveConfigDefaults(t *testing.T) {
 	assert.Equal(t, time.Duration(0), config.Timeout)
 	assert.Equal(t, 0, config.MaxRetries)
 }
+
+func TestContextConfigSettingsFor(t *testing.T) {
+	base := ServerSettings{APIToken: "token", ServerType: "cpx21", Location: "fsn1", VolumeSize: 10}
+
+	settings := ContextConfig{Name: "gpu", ServerType: "cpx51"}.SettingsFor(base)
+	assert.Equal(t, ServerSettings{APIToken: "token", ServerType: "cpx51", Location: "fsn1", VolumeSize: 10}, settings)
+}
+
+func TestHetznerConfigWithServerSettings(t *testing.T) {
+	hetzner := HetznerConfig{APIToken: "token", ServerType: "cpx21", PreferredImages: []string{"docker-ce"}}
+
+	merged := hetzner.WithServerSettings(ServerSettings{APIToken: "token", ServerType: "cpx51", Location: "hel1", VolumeSize: 50})
+	assert.Equal(t, "cpx51", merged.ServerType)
+	assert.Equal(t, "hel1", merged.Location)
+	assert.Equal(t, 50, merged.VolumeSize)
+	assert.Equal(t, []string{"docker-ce"}, merged.PreferredImages)
+	assert.Equal(t, hetzner.ServerSettings(), ServerSettings{APIToken: "token", ServerType: "cpx21"})
+}
+
+func TestHetznerConfigDataVolumes(t *testing.T) {
+	hetzner := HetznerConfig{VolumeSize: 20}
+	assert.Equal(t, []VolumeConfig{{Name: DockerDataVolume, Size: 20, Mount: DockerDataMount}}, hetzner.DataVolumes())
+	assert.Equal(t, 20, hetzner.DataVolumeSize())
+
+	hetzner.Volumes = []VolumeConfig{
+		{Name: "docker-data", Size: 40, Mount: "/var/lib/docker"},
+		{Name: "data", Size: 100, Mount: "/data"},
+	}
+	assert.Equal(t, hetzner.Volumes, hetzner.DataVolumes())
+	assert.Equal(t, 140, hetzner.DataVolumeSize())
+}
+
+func TestClientConfigSetServerSettings(t *testing.T) {
+	config := ClientConfig{Provider: "digitalocean", DigitalOcean: DigitalOceanConfig{Image: "ubuntu-24-04-x64"}}
+	config.SetServerSettings(ServerSettings{APIToken: "token", ServerType: "s-4vcpu-8gb", Location: "ams3", VolumeSize: 20})
+	assert.Equal(t, DigitalOceanConfig{APIToken: "token", Size: "s-4vcpu-8gb", Region: "ams3", VolumeSize: 20, Image: "ubuntu-24-04-x64"}, config.DigitalOcean)
+	assert.Equal(t, "s-4vcpu-8gb", config.ServerSettings().ServerType)
+
+	config = ClientConfig{Hetzner: HetznerConfig{PreferredImages: []string{"docker-ce"}}}
+	config.SetServerSettings(ServerSettings{APIToken: "token", ServerType: "cpx31"})
+	assert.Equal(t, HetznerConfig{APIToken: "token", ServerType: "cpx31", PreferredImages: []string{"docker-ce"}}, config.Hetzner, "other Hetzner options are kept")
+}
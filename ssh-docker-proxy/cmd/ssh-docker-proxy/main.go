@@ -21,8 +21,12 @@ func main() {
 
 	go func() {
 		<-sigChan
-		fmt.Println("\nReceived shutdown signal, stopping proxy...")
+		fmt.Println("\nReceived shutdown signal, waiting for in-flight connections (signal again to exit now)...")
 		cancel()
+
+		<-sigChan
+		fmt.Println("Received second shutdown signal, exiting")
+		os.Exit(1)
 	}()
 
 	// Create and run CLI
@@ -3,6 +3,7 @@ module ssh-docker-proxy
 go 1.24.2
 
 require (
+	github.com/Microsoft/go-winio v0.6.2
 	github.com/cucumber/godog v0.15.1
 	github.com/docker/docker v28.3.3+incompatible
 	github.com/stretchr/testify v1.10.0
@@ -14,7 +15,6 @@ require (
 require (
 	dario.cat/mergo v1.0.1 // indirect
 	github.com/Azure/go-ansiterm v0.0.0-20210617225240-d185dfc1b5a1 // indirect
-	github.com/Microsoft/go-winio v0.6.2 // indirect
 	github.com/cenkalti/backoff/v4 v4.2.1 // indirect
 	github.com/containerd/errdefs v1.0.0 // indirect
 	github.com/containerd/errdefs/pkg v0.3.0 // indirect
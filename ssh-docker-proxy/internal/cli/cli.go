@@ -3,23 +3,22 @@ package cli
 import (
 	"context"
 	"fmt"
-	"log"
+	"os"
 
 	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
 	"ssh-docker-proxy/internal/proxy"
 )
 
 // CLI provides command-line interface for the proxy
 type CLI struct {
 	config *config.Config
-	logger *log.Logger
+	logger logging.Logger
 }
 
 // NewCLI creates a new CLI instance
 func NewCLI() *CLI {
-	return &CLI{
-		logger: log.New(log.Writer(), "[ssh-docker-proxy] ", log.LstdFlags),
-	}
+	return &CLI{}
 }
 
 // Execute runs the CLI with the given arguments
@@ -51,7 +50,7 @@ func (c *CLI) Execute(ctx context.Context, args []string) error {
 		configPath = config.FindConfigFile()
 	}
 
-	// Load configuration with precedence: flags > file > defaults
+	// Load configuration with precedence: flags > environment > file > defaults
 	var err error
 	c.config, err = config.LoadConfig(configPath, args)
 	if err != nil {
@@ -63,16 +62,22 @@ func (c *CLI) Execute(ctx context.Context, args []string) error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	c.logger, err = logging.New(os.Stderr, c.config.LogFormat, c.config.LogLevel)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+
 	// Create and start proxy
 	p, err := proxy.NewProxy(c.config, c.logger)
 	if err != nil {
 		return fmt.Errorf("failed to create proxy: %w", err)
 	}
 
-	c.logger.Printf("Starting SSH Docker proxy...")
-	c.logger.Printf("Local socket: %s", c.config.LocalSocket)
-	c.logger.Printf("SSH target: %s@%s", c.config.SSHUser, c.config.SSHHost)
-	c.logger.Printf("Remote socket: %s", c.config.RemoteSocket)
+	c.logger.Info("Starting SSH Docker proxy", logging.Fields{
+		"local_socket":  c.config.LocalSocket,
+		"ssh_target":    c.config.SSHUser + "@" + c.config.SSHHost,
+		"remote_socket": c.config.RemoteSocket,
+	})
 
 	return p.Start(ctx)
 }
@@ -89,15 +94,41 @@ func (c *CLI) showHelp() {
 	fmt.Println("  -local-socket string")
 	fmt.Println("        Local Unix socket path (required)")
 	fmt.Println("  -ssh-user string")
-	fmt.Println("        SSH username (required)")
+	fmt.Println("        SSH username (default: User from ~/.ssh/config, or the local user)")
 	fmt.Println("  -ssh-host string")
-	fmt.Println("        SSH hostname with optional port (required)")
+	fmt.Println("        SSH hostname with optional port, or a Host alias of ~/.ssh/config (required)")
 	fmt.Println("  -ssh-key string")
-	fmt.Println("        Path to SSH private key file (required)")
+	fmt.Println("        Path to SSH private key file (default: IdentityFile from ~/.ssh/config, or ~/.ssh/id_*)")
+	fmt.Println("  -ssh-agent")
+	fmt.Println("        Authenticate with keys from ssh-agent, falling back to -ssh-key")
+	fmt.Println("  -ssh-auth-sock string")
+	fmt.Println("        ssh-agent socket path (default $SSH_AUTH_SOCK)")
+	fmt.Println("  -ssh-jump string")
+	fmt.Println("        Comma-separated jump hosts [user@]host[:port] to connect through, like ssh -J")
+	fmt.Println("  -ssh-proxy-command string")
+	fmt.Printf("        Command connecting to the first SSH server, like ssh's ProxyCommand (%%h, %%p, %%r are expanded)\n")
+	fmt.Println("  -ssh-config string")
+	fmt.Println("        OpenSSH client config filling unset SSH settings, or \"none\" (default ~/.ssh/config)")
+	fmt.Println("  -ssh-keepalive duration")
+	fmt.Println("        Interval of SSH keep-alive requests, like ServerAliveInterval (default disabled)")
 	fmt.Println("  -remote-socket string")
 	fmt.Println("        Remote Docker socket path (default \"/var/run/docker.sock\")")
 	fmt.Println("  -timeout duration")
 	fmt.Println("        SSH connection timeout (default 10s)")
+	fmt.Println("  -log-format string")
+	fmt.Println("        Log output format: text or json (default \"text\")")
+	fmt.Println("  -log-level string")
+	fmt.Println("        Minimum log level: debug, info, warn or error (default \"info\")")
+	fmt.Println("  -stats-listen string")
+	fmt.Println("        Serve connection stats as JSON at /stats on this TCP address or unix:<path>")
+	fmt.Println("  -supervise")
+	fmt.Println("        Keep the local socket up and reconnect when the SSH connection is lost")
+	fmt.Println("  -reconnect-max-backoff duration")
+	fmt.Println("        Maximum delay between reconnection attempts (default 30s)")
+	fmt.Println("  -queue-timeout duration")
+	fmt.Println("        How long connections wait for a reconnection before failing (default 30s)")
+	fmt.Println("  -drain-timeout duration")
+	fmt.Println("        How long in-flight connections may finish on shutdown before they are closed (default 30s)")
 	fmt.Println("  -help")
 	fmt.Println("        Show help message")
 	fmt.Println()
@@ -107,7 +138,9 @@ func (c *CLI) showHelp() {
 	fmt.Println("  - ~/.ssh-docker-proxy.yaml (home directory)")
 	fmt.Println("  - ~/.config/ssh-docker-proxy/config.yaml (XDG config directory)")
 	fmt.Println()
-	fmt.Println("  Command-line flags override configuration file values.")
+	fmt.Println("  Each key can also be set with an SSH_DOCKER_PROXY_<KEY> environment variable,")
+	fmt.Println("  e.g. SSH_DOCKER_PROXY_SSH_HOST. Command-line flags override environment")
+	fmt.Println("  variables, which override configuration file values.")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  # Using command-line flags")
@@ -116,6 +149,12 @@ func (c *CLI) showHelp() {
 	fmt.Println("  # Using configuration file")
 	fmt.Println("  ssh-docker-proxy -config=my-config.yaml")
 	fmt.Println()
+	fmt.Println("  # Using a Host alias of ~/.ssh/config, like ssh mybox")
+	fmt.Println("  ssh-docker-proxy -ssh-host=mybox -local-socket=/tmp/docker.sock")
+	fmt.Println()
+	fmt.Println("  # Through a bastion host")
+	fmt.Println("  ssh-docker-proxy -ssh-user=ubuntu -ssh-host=10.0.0.5 -ssh-key=~/.ssh/id_rsa -ssh-jump=ops@bastion.example.com -local-socket=/tmp/docker.sock")
+	fmt.Println()
 	fmt.Println("  # Mixed: config file with flag overrides")
 	fmt.Println("  ssh-docker-proxy -config=my-config.yaml -ssh-host=different-host")
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,68 +15,156 @@ import (
 
 // Config represents the proxy configuration
 type Config struct {
-	LocalSocket  string        `yaml:"local_socket"`  // Local Unix socket path (e.g., /tmp/docker.sock)
+	LocalSocket  string        `yaml:"local_socket"`  // Local Unix socket path (e.g., /tmp/docker.sock) or Windows named pipe (e.g., //./pipe/docker-proxy)
 	SSHUser      string        `yaml:"ssh_user"`      // SSH username
 	SSHHost      string        `yaml:"ssh_host"`      // SSH hostname with optional port
 	SSHKeyPath   string        `yaml:"ssh_key_path"`  // Path to SSH private key file
+	SSHAgent     bool          `yaml:"ssh_agent"`     // Authenticate with ssh-agent keys, falling back to the key file
+	SSHAuthSock  string        `yaml:"ssh_auth_sock"` // ssh-agent socket (default: $SSH_AUTH_SOCK)
 	RemoteSocket string        `yaml:"remote_socket"` // Remote Docker socket path (default: /var/run/docker.sock)
 	Timeout      time.Duration `yaml:"timeout"`       // SSH connection timeout
+	LogFormat    string        `yaml:"log_format"`    // Log output format: text or json (default: text)
+	LogLevel     string        `yaml:"log_level"`     // Minimum log level: debug, info, warn or error (default: info)
+	StatsListen  string        `yaml:"stats_listen"`  // Address of the JSON stats endpoint, e.g. 127.0.0.1:9180 or unix:/tmp/proxy-stats.sock (default: disabled)
+
+	SSHJumpHosts    []JumpHost `yaml:"ssh_jump_hosts"`    // Jump hosts (bastions) connected through in order, like ssh -J
+	SSHProxyCommand string     `yaml:"ssh_proxy_command"` // Command whose stdin/stdout reach the first SSH server, like ssh's ProxyCommand
+
+	SSHConfigFile string        `yaml:"ssh_config_file"` // OpenSSH client config filling unset SSH settings (default: ~/.ssh/config, "none" to disable)
+	SSHKeepAlive  time.Duration `yaml:"ssh_keepalive"`   // Interval of SSH keep-alive requests, like ServerAliveInterval (default: disabled)
+
+	Supervise           bool          `yaml:"supervise"`             // Keep the local socket up and reconnect when the SSH connection is lost
+	ReconnectMaxBackoff time.Duration `yaml:"reconnect_max_backoff"` // Maximum delay between reconnection attempts (default: 30s)
+	QueueTimeout        time.Duration `yaml:"queue_timeout"`         // How long connections wait for a reconnection before failing (default: 30s)
+
+	DrainTimeout time.Duration `yaml:"drain_timeout"` // How long in-flight connections may finish on shutdown before they are closed (default: 30s)
 }
 
+// Log formats and levels accepted by Validate
+var (
+	logFormats = []string{"text", "json"}
+	logLevels  = []string{"debug", "info", "warn", "error"}
+)
+
 // Validate ensures configuration is complete and valid
 func (c *Config) Validate() error {
-	if c.SSHUser == "" {
+	return c.ValidateFor(true, true)
+}
+
+// ValidateFor validates the configuration of a proxy that makes its own SSH connections
+// if ssh is set and listens on LocalSocket if localSocket is set; an embedding program
+// that provides the connection or the listener may leave those settings empty
+func (c *Config) ValidateFor(ssh, localSocket bool) error {
+	if ssh {
+		if err := c.validateSSH(); err != nil {
+			return err
+		}
+	}
+
+	if localSocket && c.LocalSocket == "" {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  "SSH user is required",
+			Message:  "Local socket path is required",
 		}
 	}
 
-	if c.SSHHost == "" {
+	if c.RemoteSocket == "" {
+		c.RemoteSocket = "/var/run/docker.sock"
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+
+	if c.ReconnectMaxBackoff <= 0 {
+		c.ReconnectMaxBackoff = 30 * time.Second
+	}
+
+	if c.QueueTimeout <= 0 {
+		c.QueueTimeout = 30 * time.Second
+	}
+
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = 30 * time.Second
+	}
+
+	if c.LogFormat == "" {
+		c.LogFormat = "text"
+	}
+	if !slices.Contains(logFormats, c.LogFormat) {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  "SSH host is required",
+			Message:  fmt.Sprintf("Invalid log format %q, must be one of: %s", c.LogFormat, strings.Join(logFormats, ", ")),
 		}
 	}
 
-	if c.SSHKeyPath == "" {
+	if c.LogLevel == "" {
+		c.LogLevel = "info"
+	}
+	if !slices.Contains(logLevels, c.LogLevel) {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  "SSH key path is required",
+			Message:  fmt.Sprintf("Invalid log level %q, must be one of: %s", c.LogLevel, strings.Join(logLevels, ", ")),
 		}
 	}
 
-	// Expand and check if SSH key file exists
-	expandedKeyPath, err := expandPath(c.SSHKeyPath)
-	if err != nil {
+	return nil
+}
+
+// validateSSH checks the settings of the SSH connection
+func (c *Config) validateSSH() error {
+	if c.SSHUser == "" {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  fmt.Sprintf("failed to expand SSH key path: %s", c.SSHKeyPath),
-			Cause:    err,
+			Message:  "SSH user is required",
 		}
 	}
 
-	if _, err := os.Stat(expandedKeyPath); os.IsNotExist(err) {
+	if c.SSHHost == "" {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  fmt.Sprintf("SSH key file does not exist: %s", expandedKeyPath),
-			Cause:    err,
+			Message:  "SSH host is required",
 		}
 	}
 
-	if c.LocalSocket == "" {
+	if c.SSHKeyPath == "" && !c.SSHAgent {
 		return &ProxyError{
 			Category: ErrorCategoryConfig,
-			Message:  "Local socket path is required",
+			Message:  "SSH key path is required unless the SSH agent is enabled",
 		}
 	}
 
-	if c.RemoteSocket == "" {
-		c.RemoteSocket = "/var/run/docker.sock"
+	if c.SSHKeyPath != "" {
+		if err := checkKeyPath(c.SSHKeyPath, c.SSHAgent); err != nil {
+			return err
+		}
 	}
 
-	if c.Timeout <= 0 {
-		c.Timeout = 10 * time.Second
+	return c.validateJumpHosts()
+}
+
+// checkKeyPath checks that the SSH key exists. With the SSH agent enabled the public
+// key alone is enough, since it selects the matching agent key.
+func checkKeyPath(keyPath string, sshAgent bool) error {
+	expandedKeyPath, err := expandPath(keyPath)
+	if err != nil {
+		return &ProxyError{
+			Category: ErrorCategoryConfig,
+			Message:  fmt.Sprintf("failed to expand SSH key path: %s", keyPath),
+			Cause:    err,
+		}
+	}
+
+	_, err = os.Stat(expandedKeyPath)
+	if os.IsNotExist(err) && sshAgent {
+		_, err = os.Stat(expandedKeyPath + ".pub")
+	}
+	if os.IsNotExist(err) {
+		return &ProxyError{
+			Category: ErrorCategoryConfig,
+			Message:  fmt.Sprintf("SSH key file does not exist: %s", expandedKeyPath),
+			Cause:    err,
+		}
 	}
 
 	return nil
@@ -154,12 +244,25 @@ func LoadFromFlags(args []string) (*Config, error) {
 
 	// Define flags (including config flag which we'll ignore here)
 	_ = fs.String("config", "", "Path to configuration file (optional)")
-	localSocket := fs.String("local-socket", "", "Local Unix socket path (required)")
+	localSocket := fs.String("local-socket", "", "Local Unix socket path or Windows named pipe (required)")
 	sshUser := fs.String("ssh-user", "", "SSH username (required)")
 	sshHost := fs.String("ssh-host", "", "SSH hostname with optional port (required)")
-	sshKeyPath := fs.String("ssh-key", "", "Path to SSH private key file (required)")
+	sshKeyPath := fs.String("ssh-key", "", "Path to SSH private key file (required unless -ssh-agent is set)")
+	sshAgent := fs.Bool("ssh-agent", false, "Authenticate with keys from ssh-agent, falling back to -ssh-key")
+	sshAuthSock := fs.String("ssh-auth-sock", "", "ssh-agent socket path (default $SSH_AUTH_SOCK)")
 	remoteSocket := fs.String("remote-socket", config.RemoteSocket, "Remote Docker socket path")
 	timeout := fs.Duration("timeout", config.Timeout, "SSH connection timeout")
+	logFormat := fs.String("log-format", "", "Log output format: text or json")
+	logLevel := fs.String("log-level", "", "Minimum log level: debug, info, warn or error")
+	statsListen := fs.String("stats-listen", "", "Address of the JSON stats endpoint, e.g. 127.0.0.1:9180 or unix:/path")
+	sshJump := fs.String("ssh-jump", "", "Comma-separated jump hosts [user@]host[:port], like ssh -J")
+	sshProxyCommand := fs.String("ssh-proxy-command", "", "Command connecting to the first SSH server, like ssh's ProxyCommand")
+	sshConfigFile := fs.String("ssh-config", "", "OpenSSH client config file, or \"none\" (default ~/.ssh/config)")
+	sshKeepAlive := fs.Duration("ssh-keepalive", 0, "Interval of SSH keep-alive requests (default disabled)")
+	supervise := fs.Bool("supervise", false, "Keep the local socket up and reconnect when the SSH connection is lost")
+	reconnectMaxBackoff := fs.Duration("reconnect-max-backoff", 0, "Maximum delay between reconnection attempts (default 30s)")
+	queueTimeout := fs.Duration("queue-timeout", 0, "How long connections wait for a reconnection before failing (default 30s)")
+	drainTimeout := fs.Duration("drain-timeout", 0, "How long in-flight connections may finish on shutdown (default 30s)")
 
 	// Parse flags
 	if err := fs.Parse(args); err != nil {
@@ -183,17 +286,139 @@ func LoadFromFlags(args []string) (*Config, error) {
 	if *sshKeyPath != "" {
 		config.SSHKeyPath = *sshKeyPath
 	}
+	if *sshAgent {
+		config.SSHAgent = true
+	}
+	if *sshAuthSock != "" {
+		config.SSHAuthSock = *sshAuthSock
+	}
 	if *remoteSocket != config.RemoteSocket {
 		config.RemoteSocket = *remoteSocket
 	}
 	if *timeout != config.Timeout {
 		config.Timeout = *timeout
 	}
+	if *logFormat != "" {
+		config.LogFormat = *logFormat
+	}
+	if *logLevel != "" {
+		config.LogLevel = *logLevel
+	}
+	if *statsListen != "" {
+		config.StatsListen = *statsListen
+	}
+	if *sshJump != "" {
+		jumpHosts, err := ParseJumpHosts(*sshJump)
+		if err != nil {
+			return nil, err
+		}
+		config.SSHJumpHosts = jumpHosts
+	}
+	if *sshProxyCommand != "" {
+		config.SSHProxyCommand = *sshProxyCommand
+	}
+	if *sshConfigFile != "" {
+		config.SSHConfigFile = *sshConfigFile
+	}
+	if *sshKeepAlive != 0 {
+		config.SSHKeepAlive = *sshKeepAlive
+	}
+	if *supervise {
+		config.Supervise = true
+	}
+	if *reconnectMaxBackoff != 0 {
+		config.ReconnectMaxBackoff = *reconnectMaxBackoff
+	}
+	if *queueTimeout != 0 {
+		config.QueueTimeout = *queueTimeout
+	}
+	if *drainTimeout != 0 {
+		config.DrainTimeout = *drainTimeout
+	}
 
 	return config, nil
 }
 
-// LoadConfig loads configuration with precedence: flags > file > defaults
+// EnvPrefix prefixes the environment variables read by LoadConfig; the rest of each
+// name is the upper-cased YAML key, e.g. SSH_DOCKER_PROXY_SSH_HOST
+const EnvPrefix = "SSH_DOCKER_PROXY_"
+
+// LoadFromEnv applies the SSH_DOCKER_PROXY_* environment variables that are set to config
+func LoadFromEnv(config *Config, lookup func(key string) (string, bool)) error {
+	fields := map[string]*string{
+		"local_socket":  &config.LocalSocket,
+		"ssh_user":      &config.SSHUser,
+		"ssh_host":      &config.SSHHost,
+		"ssh_key_path":  &config.SSHKeyPath,
+		"ssh_auth_sock": &config.SSHAuthSock,
+		"remote_socket": &config.RemoteSocket,
+		"log_format":    &config.LogFormat,
+		"log_level":     &config.LogLevel,
+		"stats_listen":  &config.StatsListen,
+
+		"ssh_proxy_command": &config.SSHProxyCommand,
+		"ssh_config_file":   &config.SSHConfigFile,
+	}
+	for key, field := range fields {
+		if value, ok := lookup(envName(key)); ok {
+			*field = value
+		}
+	}
+
+	for key, field := range map[string]*bool{"ssh_agent": &config.SSHAgent, "supervise": &config.Supervise} {
+		value, ok := lookup(envName(key))
+		if !ok {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ProxyError{
+				Category: ErrorCategoryConfig,
+				Message:  fmt.Sprintf("Invalid %s: %q", envName(key), value),
+				Cause:    err,
+			}
+		}
+		*field = enabled
+	}
+	if value, ok := lookup(envName("ssh_jump_hosts")); ok {
+		jumpHosts, err := ParseJumpHosts(value)
+		if err != nil {
+			return err
+		}
+		config.SSHJumpHosts = jumpHosts
+	}
+	durations := map[string]*time.Duration{
+		"timeout":               &config.Timeout,
+		"ssh_keepalive":         &config.SSHKeepAlive,
+		"reconnect_max_backoff": &config.ReconnectMaxBackoff,
+		"queue_timeout":         &config.QueueTimeout,
+		"drain_timeout":         &config.DrainTimeout,
+	}
+	for key, field := range durations {
+		value, ok := lookup(envName(key))
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return &ProxyError{
+				Category: ErrorCategoryConfig,
+				Message:  fmt.Sprintf("Invalid %s: %q", envName(key), value),
+				Cause:    err,
+			}
+		}
+		*field = duration
+	}
+
+	return nil
+}
+
+// envName returns the environment variable of a YAML key
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// LoadConfig loads configuration with precedence: flags > environment > file > defaults
 func LoadConfig(configFile string, args []string) (*Config, error) {
 	var config *Config
 	var err error
@@ -212,6 +437,11 @@ func LoadConfig(configFile string, args []string) (*Config, error) {
 		}
 	}
 
+	// Override with environment variables
+	if err := LoadFromEnv(config, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
 	// Override with flags
 	flagConfig, err := LoadFromFlags(args)
 	if err != nil {
@@ -223,6 +453,11 @@ func LoadConfig(configFile string, args []string) (*Config, error) {
 		return nil, err
 	}
 
+	// Fill unset SSH settings from ~/.ssh/config
+	if err := applySSHConfig(config); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -236,8 +471,21 @@ func applyFlagOverrides(config, flagConfig *Config, args []string) error {
 	sshUser := fs.String("ssh-user", "", "")
 	sshHost := fs.String("ssh-host", "", "")
 	sshKeyPath := fs.String("ssh-key", "", "")
+	sshAgent := fs.Bool("ssh-agent", false, "")
+	sshAuthSock := fs.String("ssh-auth-sock", "", "")
 	remoteSocket := fs.String("remote-socket", config.RemoteSocket, "")
 	timeout := fs.Duration("timeout", config.Timeout, "")
+	logFormat := fs.String("log-format", "", "")
+	logLevel := fs.String("log-level", "", "")
+	statsListen := fs.String("stats-listen", "", "")
+	sshJump := fs.String("ssh-jump", "", "")
+	sshProxyCommand := fs.String("ssh-proxy-command", "", "")
+	sshConfigFile := fs.String("ssh-config", "", "")
+	sshKeepAlive := fs.Duration("ssh-keepalive", 0, "")
+	supervise := fs.Bool("supervise", false, "")
+	reconnectMaxBackoff := fs.Duration("reconnect-max-backoff", 0, "")
+	queueTimeout := fs.Duration("queue-timeout", 0, "")
+	drainTimeout := fs.Duration("drain-timeout", 0, "")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -262,12 +510,55 @@ func applyFlagOverrides(config, flagConfig *Config, args []string) error {
 	if flagsSet["ssh-key"] {
 		config.SSHKeyPath = *sshKeyPath
 	}
+	if flagsSet["ssh-agent"] {
+		config.SSHAgent = *sshAgent
+	}
+	if flagsSet["ssh-auth-sock"] {
+		config.SSHAuthSock = *sshAuthSock
+	}
 	if flagsSet["remote-socket"] {
 		config.RemoteSocket = *remoteSocket
 	}
 	if flagsSet["timeout"] {
 		config.Timeout = *timeout
 	}
+	if flagsSet["log-format"] {
+		config.LogFormat = *logFormat
+	}
+	if flagsSet["log-level"] {
+		config.LogLevel = *logLevel
+	}
+	if flagsSet["stats-listen"] {
+		config.StatsListen = *statsListen
+	}
+	if flagsSet["ssh-jump"] {
+		jumpHosts, err := ParseJumpHosts(*sshJump)
+		if err != nil {
+			return err
+		}
+		config.SSHJumpHosts = jumpHosts
+	}
+	if flagsSet["ssh-proxy-command"] {
+		config.SSHProxyCommand = *sshProxyCommand
+	}
+	if flagsSet["ssh-config"] {
+		config.SSHConfigFile = *sshConfigFile
+	}
+	if flagsSet["ssh-keepalive"] {
+		config.SSHKeepAlive = *sshKeepAlive
+	}
+	if flagsSet["supervise"] {
+		config.Supervise = *supervise
+	}
+	if flagsSet["reconnect-max-backoff"] {
+		config.ReconnectMaxBackoff = *reconnectMaxBackoff
+	}
+	if flagsSet["queue-timeout"] {
+		config.QueueTimeout = *queueTimeout
+	}
+	if flagsSet["drain-timeout"] {
+		config.DrainTimeout = *drainTimeout
+	}
 
 	return nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -101,6 +102,28 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "agent without key path",
+			config: Config{
+				LocalSocket: "/tmp/test.sock",
+				SSHUser:     "testuser",
+				SSHHost:     "testhost",
+				SSHAgent:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "agent with missing key files",
+			config: Config{
+				LocalSocket: "/tmp/test.sock",
+				SSHUser:     "testuser",
+				SSHHost:     "testhost",
+				SSHKeyPath:  "/non/existent/key",
+				SSHAgent:    true,
+			},
+			wantErr: true,
+			errType: ErrorCategoryConfig,
+		},
 		{
 			name: "default timeout",
 			config: Config{
@@ -149,6 +172,27 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateLogging(t *testing.T) {
+	cfg := Config{LocalSocket: "/tmp/test.sock", SSHUser: "testuser", SSHHost: "testhost", SSHAgent: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if cfg.LogFormat != "text" || cfg.LogLevel != "info" {
+		t.Errorf("Validate() should default to text logs at info level, got %q and %q", cfg.LogFormat, cfg.LogLevel)
+	}
+
+	cfg.LogFormat = "xml"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "log format") {
+		t.Errorf("Validate() expected log format error, got %v", err)
+	}
+
+	cfg.LogFormat = "json"
+	cfg.LogLevel = "verbose"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "log level") {
+		t.Errorf("Validate() expected log level error, got %v", err)
+	}
+}
+
 func TestProxyError_Error(t *testing.T) {
 	tests := []struct {
 		name string
@@ -611,6 +655,59 @@ timeout: 20s
 	}
 }
 
+func TestLoadConfigEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+local_socket: /tmp/file.sock
+ssh_user: fileuser
+ssh_host: filehost
+timeout: 20s
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("SSH_DOCKER_PROXY_SSH_HOST", "envhost:2222")
+	t.Setenv("SSH_DOCKER_PROXY_SSH_USER", "envuser")
+	t.Setenv("SSH_DOCKER_PROXY_SSH_AGENT", "true")
+	t.Setenv("SSH_DOCKER_PROXY_TIMEOUT", "5s")
+	t.Setenv("SSH_DOCKER_PROXY_SUPERVISE", "1")
+	t.Setenv("SSH_DOCKER_PROXY_QUEUE_TIMEOUT", "1m")
+
+	got, err := LoadConfig(configFile, []string{"-ssh-user", "flaguser", "-queue-timeout", "5s"})
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+
+	if got.LocalSocket != "/tmp/file.sock" {
+		t.Errorf("LoadConfig() LocalSocket = %v, want value from file", got.LocalSocket)
+	}
+	if got.SSHHost != "envhost:2222" {
+		t.Errorf("LoadConfig() SSHHost = %v, want value from environment", got.SSHHost)
+	}
+	if got.SSHUser != "flaguser" {
+		t.Errorf("LoadConfig() SSHUser = %v, want value from flag", got.SSHUser)
+	}
+	if !got.SSHAgent {
+		t.Errorf("LoadConfig() SSHAgent = false, want true from environment")
+	}
+	if got.Timeout != 5*time.Second {
+		t.Errorf("LoadConfig() Timeout = %v, want 5s from environment", got.Timeout)
+	}
+	if !got.Supervise {
+		t.Errorf("LoadConfig() Supervise = false, want true from environment")
+	}
+	if got.QueueTimeout != 5*time.Second {
+		t.Errorf("LoadConfig() QueueTimeout = %v, want 5s from flag", got.QueueTimeout)
+	}
+
+	t.Setenv("SSH_DOCKER_PROXY_TIMEOUT", "soon")
+	if _, err := LoadConfig(configFile, nil); err == nil {
+		t.Errorf("LoadConfig() expected error for invalid timeout, got nil")
+	}
+}
+
 func TestFindConfigFile(t *testing.T) {
 	// Create a temporary directory
 	tmpDir, err := os.MkdirTemp("", "config_test")
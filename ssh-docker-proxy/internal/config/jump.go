@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JumpHost is an SSH server the proxy connects through to reach the next hop, like a
+// host of ssh -J. User and KeyPath default to those of the target host, so each hop can
+// have its own key.
+type JumpHost struct {
+	Host    string `yaml:"host"`     // Hostname with optional port
+	User    string `yaml:"user"`     // SSH username (default: ssh_user)
+	KeyPath string `yaml:"key_path"` // Path to SSH private key file (default: ssh_key_path)
+}
+
+// String formats the jump host as [user@]host[:port]
+func (j JumpHost) String() string {
+	if j.User == "" {
+		return j.Host
+	}
+	return j.User + "@" + j.Host
+}
+
+// UnmarshalYAML accepts a jump host as a mapping or as a "[user@]host[:port]" string
+func (j *JumpHost) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		jumpHost, err := ParseJumpHost(node.Value)
+		if err != nil {
+			return err
+		}
+		*j = jumpHost
+		return nil
+	}
+
+	type plain JumpHost
+	return node.Decode((*plain)(j))
+}
+
+// ParseJumpHost parses a jump host written as [user@]host[:port]
+func ParseJumpHost(s string) (JumpHost, error) {
+	spec := strings.TrimSpace(s)
+	jumpHost := JumpHost{Host: spec}
+	user, host, hasUser := strings.Cut(spec, "@")
+	if hasUser {
+		jumpHost = JumpHost{Host: host, User: user}
+	}
+
+	if jumpHost.Host == "" || (hasUser && user == "") {
+		return JumpHost{}, &ProxyError{
+			Category: ErrorCategoryConfig,
+			Message:  fmt.Sprintf("Invalid jump host %q, expected [user@]host[:port]", spec),
+		}
+	}
+	return jumpHost, nil
+}
+
+// ParseJumpHosts parses a comma-separated list of jump hosts, as given to ssh -J
+func ParseJumpHosts(s string) ([]JumpHost, error) {
+	var jumpHosts []JumpHost
+	for _, part := range strings.Split(s, ",") {
+		jumpHost, err := ParseJumpHost(part)
+		if err != nil {
+			return nil, err
+		}
+		jumpHosts = append(jumpHosts, jumpHost)
+	}
+	return jumpHosts, nil
+}
+
+// validateJumpHosts checks that every jump host has a host and that its key exists
+func (c *Config) validateJumpHosts() error {
+	for i, jumpHost := range c.SSHJumpHosts {
+		if jumpHost.Host == "" {
+			return &ProxyError{
+				Category: ErrorCategoryConfig,
+				Message:  fmt.Sprintf("Jump host %d: host is required", i+1),
+			}
+		}
+		if jumpHost.KeyPath != "" {
+			if err := checkKeyPath(jumpHost.KeyPath, c.SSHAgent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
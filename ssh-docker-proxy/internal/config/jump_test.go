@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseJumpHosts(t *testing.T) {
+	got, err := ParseJumpHosts("ops@bastion.example.com:2222, inner")
+	if err != nil {
+		t.Fatalf("ParseJumpHosts() unexpected error: %v", err)
+	}
+	want := []JumpHost{
+		{Host: "bastion.example.com:2222", User: "ops"},
+		{Host: "inner"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseJumpHosts() = %+v, want %+v", got, want)
+	}
+
+	for _, invalid := range []string{"", "bastion,", "@bastion", "ops@"} {
+		if _, err := ParseJumpHosts(invalid); err == nil {
+			t.Errorf("ParseJumpHosts(%q) expected error, got nil", invalid)
+		}
+	}
+}
+
+func TestLoadConfigJumpHosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyFile := filepath.Join(tmpDir, "bastion_key")
+	if err := os.WriteFile(keyFile, []byte("test key content"), 0600); err != nil {
+		t.Fatalf("Failed to create key file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+ssh_jump_hosts:
+  - ops@bastion.example.com
+  - host: inner.example.com:2222
+    user: jump
+    key_path: ` + keyFile + `
+ssh_proxy_command: corkscrew proxy.example.com 8080 %h %p
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	got, err := LoadConfig(configFile, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	want := []JumpHost{
+		{Host: "bastion.example.com", User: "ops"},
+		{Host: "inner.example.com:2222", User: "jump", KeyPath: keyFile},
+	}
+	if !reflect.DeepEqual(got.SSHJumpHosts, want) {
+		t.Errorf("LoadConfig() SSHJumpHosts = %+v, want %+v", got.SSHJumpHosts, want)
+	}
+	if got.SSHProxyCommand != "corkscrew proxy.example.com 8080 %h %p" {
+		t.Errorf("LoadConfig() SSHProxyCommand = %q", got.SSHProxyCommand)
+	}
+
+	// The flag replaces the file's jump hosts, like ssh -J
+	got, err = LoadConfig(configFile, []string{"-ssh-jump", "other@gateway:22"})
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if want := []JumpHost{{Host: "gateway:22", User: "other"}}; !reflect.DeepEqual(got.SSHJumpHosts, want) {
+		t.Errorf("LoadConfig() SSHJumpHosts = %+v, want %+v", got.SSHJumpHosts, want)
+	}
+
+	got.SSHUser, got.SSHHost, got.SSHAgent, got.LocalSocket = "docker", "target", true, "/tmp/test.sock"
+	got.SSHJumpHosts = []JumpHost{{Host: "gateway", KeyPath: filepath.Join(tmpDir, "missing")}}
+	if err := got.Validate(); err == nil {
+		t.Error("Validate() expected error for missing jump host key, got nil")
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ssh-docker-proxy/internal/sshconfig"
+)
+
+// DefaultSSHConfigFile is the OpenSSH client configuration read unless ssh_config_file
+// selects another one, or "none"
+const DefaultSSHConfigFile = "~/.ssh/config"
+
+// defaultIdentityFiles are the keys ssh tries when no IdentityFile is configured
+var defaultIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// applySSHConfig fills the SSH settings that are not set from the user's OpenSSH
+// configuration, so that ssh_host can be a Host alias and reaches the host like ssh
+// does. Explicit settings take precedence, like command-line options of ssh.
+func applySSHConfig(c *Config) error {
+	if c.SSHHost == "" || c.SSHConfigFile == "none" {
+		return nil
+	}
+
+	sshCfg, err := loadSSHConfig(c.SSHConfigFile)
+	if err != nil {
+		return err
+	}
+
+	host := resolveHost(sshCfg, c.SSHHost)
+	c.SSHHost = host.addr
+	if c.SSHUser == "" {
+		c.SSHUser = host.User
+	}
+	if c.SSHUser == "" {
+		c.SSHUser = localUser()
+	}
+	if c.SSHKeyPath == "" {
+		c.SSHKeyPath = host.keyPath()
+	}
+	if c.SSHKeepAlive == 0 {
+		c.SSHKeepAlive = host.ServerAliveInterval
+	}
+
+	if len(c.SSHJumpHosts) == 0 && c.SSHProxyCommand == "" {
+		switch {
+		case host.ProxyJump != "" && host.ProxyJump != "none":
+			jumpHosts, err := ParseJumpHosts(strings.ReplaceAll(host.ProxyJump, "ssh://", ""))
+			if err != nil {
+				return err
+			}
+			c.SSHJumpHosts = jumpHosts
+		case host.ProxyCommand != "" && host.ProxyCommand != "none":
+			c.SSHProxyCommand = host.ProxyCommand
+		}
+	}
+
+	// Jump hosts can be aliases too
+	for i, jumpHost := range c.SSHJumpHosts {
+		resolved := resolveHost(sshCfg, jumpHost.Host)
+		c.SSHJumpHosts[i].Host = resolved.addr
+		if jumpHost.User == "" {
+			c.SSHJumpHosts[i].User = resolved.User
+		}
+		if jumpHost.KeyPath == "" && len(resolved.IdentityFiles) > 0 {
+			c.SSHJumpHosts[i].KeyPath = resolved.IdentityFiles[0]
+		}
+	}
+
+	return nil
+}
+
+// loadSSHConfig reads the OpenSSH configuration at path; the default file may be missing
+func loadSSHConfig(path string) (*sshconfig.Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultSSHConfigFile
+	}
+
+	expanded, err := expandPath(path)
+	if err == nil {
+		var sshCfg *sshconfig.Config
+		if sshCfg, err = sshconfig.Load(expanded); err == nil {
+			return sshCfg, nil
+		}
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return sshconfig.Parse(strings.NewReader(""))
+		}
+	}
+	return nil, &ProxyError{
+		Category: ErrorCategoryConfig,
+		Message:  fmt.Sprintf("Failed to read SSH config file: %s", path),
+		Cause:    err,
+	}
+}
+
+// resolvedHost is the OpenSSH configuration of a host with its address resolved
+type resolvedHost struct {
+	sshconfig.Host
+	addr string
+}
+
+// resolveHost looks up the host of addr, which may have a port, in the OpenSSH
+// configuration and returns its real address. A port in addr takes precedence.
+func resolveHost(sshCfg *sshconfig.Config, addr string) resolvedHost {
+	name, port := addr, ""
+	if host, p, err := net.SplitHostPort(addr); err == nil {
+		name, port = host, p
+	}
+
+	host := resolvedHost{Host: sshCfg.Lookup(name)}
+	if host.HostName != "" {
+		name = host.HostName
+	}
+	if port == "" && host.Port != 0 {
+		port = strconv.Itoa(host.Port)
+	}
+
+	host.addr = name
+	if port != "" {
+		host.addr = net.JoinHostPort(name, port)
+	}
+	return host
+}
+
+// keyPath returns the first configured identity file, or the first of ssh's default
+// keys that exists
+func (h resolvedHost) keyPath() string {
+	if len(h.IdentityFiles) > 0 {
+		return h.IdentityFiles[0]
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range defaultIdentityFiles {
+		path := filepath.Join(homeDir, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// localUser returns the name of the local user, which ssh logs in as by default
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
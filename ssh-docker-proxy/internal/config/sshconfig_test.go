@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigSSHConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	sshConfigFile := filepath.Join(tmpDir, "ssh_config")
+	sshConfig := `
+Host mybox
+    HostName 10.0.0.5
+    User deploy
+    Port 2222
+    IdentityFile ` + filepath.Join(tmpDir, "mybox_key") + `
+    ProxyJump bastion
+    ServerAliveInterval 15
+
+Host bastion
+    HostName bastion.example.com
+    User ops
+    IdentityFile ` + filepath.Join(tmpDir, "bastion_key") + `
+`
+	if err := os.WriteFile(sshConfigFile, []byte(sshConfig), 0600); err != nil {
+		t.Fatalf("Failed to create SSH config file: %v", err)
+	}
+
+	got, err := LoadConfig("", []string{"-ssh-host", "mybox", "-ssh-config", sshConfigFile})
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if got.SSHHost != "10.0.0.5:2222" || got.SSHUser != "deploy" || got.SSHKeyPath != filepath.Join(tmpDir, "mybox_key") {
+		t.Errorf("LoadConfig() SSH target = %s@%s with key %s", got.SSHUser, got.SSHHost, got.SSHKeyPath)
+	}
+	if got.SSHKeepAlive != 15*time.Second {
+		t.Errorf("LoadConfig() SSHKeepAlive = %v, want 15s", got.SSHKeepAlive)
+	}
+	wantJumps := []JumpHost{{Host: "bastion.example.com", User: "ops", KeyPath: filepath.Join(tmpDir, "bastion_key")}}
+	if !reflect.DeepEqual(got.SSHJumpHosts, wantJumps) {
+		t.Errorf("LoadConfig() SSHJumpHosts = %+v, want %+v", got.SSHJumpHosts, wantJumps)
+	}
+
+	// Explicit settings win over the SSH config
+	got, err = LoadConfig("", []string{"-ssh-host", "mybox:22", "-ssh-user", "root", "-ssh-config", sshConfigFile})
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if got.SSHHost != "10.0.0.5:22" || got.SSHUser != "root" {
+		t.Errorf("LoadConfig() SSH target = %s@%s, want root@10.0.0.5:22", got.SSHUser, got.SSHHost)
+	}
+
+	// The SSH config can be turned off
+	got, err = LoadConfig("", []string{"-ssh-host", "mybox", "-ssh-user", "root", "-ssh-config", "none"})
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if got.SSHHost != "mybox" || len(got.SSHJumpHosts) != 0 {
+		t.Errorf("LoadConfig() should ignore the SSH config, got host %s and jump hosts %+v", got.SSHHost, got.SSHJumpHosts)
+	}
+
+	// A missing SSH config is only an error when chosen explicitly
+	if _, err := LoadConfig("", []string{"-ssh-host", "mybox", "-ssh-config", filepath.Join(tmpDir, "missing")}); err == nil {
+		t.Error("LoadConfig() expected error for missing SSH config file")
+	}
+}
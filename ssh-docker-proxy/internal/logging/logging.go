@@ -0,0 +1,127 @@
+// Package logging provides the structured logger of the proxy
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"slices"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Fields are the structured fields of a log entry
+type Fields map[string]any
+
+// Logger writes structured log entries
+type Logger interface {
+	Debug(msg string, fields Fields)
+	Info(msg string, fields Fields)
+	Warn(msg string, fields Fields)
+	Error(msg string, fields Fields)
+	// With returns a logger adding fields to every entry, e.g. a connection ID
+	With(fields Fields) Logger
+}
+
+// New returns a logger writing entries at or above level (debug, info, warn or error)
+// to out in the given format, text or json
+func New(out io.Writer, format, level string) (Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q, must be debug, info, warn or error", level)
+	}
+	opts := &slog.HandlerOptions{Level: minLevel}
+
+	switch format {
+	case "", FormatText:
+		return &slogLogger{slog.New(slog.NewTextHandler(out, opts))}, nil
+	case FormatJSON:
+		return &slogLogger{slog.New(slog.NewJSONHandler(out, opts))}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, must be text or json", format)
+	}
+}
+
+// FromStd returns a logger writing text entries through a standard logger, which adds
+// its own prefix and timestamp
+func FromStd(logger *log.Logger) Logger {
+	return FromPrintf(logger.Printf)
+}
+
+// FromPrintf returns a logger writing one text entry per printf call, for loggers with
+// only a Printf method. Every level is written, debug included.
+func FromPrintf(printf func(format string, v ...any)) Logger {
+	opts := &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			// The wrapped logger adds its own timestamp
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}
+	return &slogLogger{slog.New(slog.NewTextHandler(&printfWriter{printf: printf}, opts))}
+}
+
+// Discard returns a logger dropping every entry
+func Discard() Logger {
+	return &slogLogger{slog.New(slog.DiscardHandler)}
+}
+
+// slogLogger implements Logger with log/slog
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogLogger) Debug(msg string, fields Fields) { l.log(slog.LevelDebug, msg, fields) }
+
+func (l *slogLogger) Info(msg string, fields Fields) { l.log(slog.LevelInfo, msg, fields) }
+
+func (l *slogLogger) Warn(msg string, fields Fields) { l.log(slog.LevelWarn, msg, fields) }
+
+func (l *slogLogger) Error(msg string, fields Fields) { l.log(slog.LevelError, msg, fields) }
+
+func (l *slogLogger) With(fields Fields) Logger {
+	return &slogLogger{l.logger.With(attrs(fields)...)}
+}
+
+func (l *slogLogger) log(level slog.Level, msg string, fields Fields) {
+	l.logger.Log(context.Background(), level, msg, attrs(fields)...)
+}
+
+// attrs converts fields to slog attributes, sorted by key so text entries are stable
+func attrs(fields Fields) []any {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	args := make([]any, 0, len(fields))
+	for _, key := range keys {
+		value := fields[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		args = append(args, slog.Any(key, value))
+	}
+	return args
+}
+
+// printfWriter passes each entry written by a slog handler to printf
+type printfWriter struct {
+	printf func(format string, v ...any)
+}
+
+func (w *printfWriter) Write(p []byte) (int, error) {
+	w.printf("%s", bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
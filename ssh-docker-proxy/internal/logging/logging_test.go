@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewJSON(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, FormatJSON, "info")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	logger.Debug("hidden", nil)
+	logger.With(Fields{"conn": "c1"}).Info("Connection terminated", Fields{"bytes_sent": 42, "error": errors.New("boom")})
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON entry, got %q: %v", out.String(), err)
+	}
+	if entry["msg"] != "Connection terminated" || entry["level"] != "INFO" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+	if entry["conn"] != "c1" || entry["bytes_sent"] != float64(42) || entry["error"] != "boom" {
+		t.Errorf("expected structured fields, got %v", entry)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", "info"); err == nil {
+		t.Error("New() expected error for unknown format")
+	}
+	if _, err := New(&bytes.Buffer{}, FormatText, "loud"); err == nil {
+		t.Error("New() expected error for unknown level")
+	}
+}
+
+func TestFromPrintf(t *testing.T) {
+	var lines []string
+	logger := FromPrintf(func(format string, v ...any) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	})
+
+	logger.With(Fields{"conn": "c2"}).Debug("Local->Remote copy completed", Fields{"bytes": 7})
+
+	if len(lines) != 1 {
+		t.Fatalf("expected one line, got %q", lines)
+	}
+	if want := `level=DEBUG msg="Local->Remote copy completed" conn=c2 bytes=7`; lines[0] != want {
+		t.Errorf("FromPrintf() line = %q, want %q", lines[0], want)
+	}
+	if strings.Contains(lines[0], "\n") {
+		t.Error("FromPrintf() line should not end with a newline")
+	}
+}
@@ -0,0 +1,52 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"ssh-docker-proxy/internal/config"
+)
+
+// isNamedPipe reports whether path names a Windows named pipe, written as
+// //./pipe/<name> or \\.\pipe\<name>
+func isNamedPipe(path string) bool {
+	return strings.HasPrefix(path, "//./pipe/") || strings.HasPrefix(path, `\\.\pipe\`)
+}
+
+// listen creates the local listener: a named pipe when LocalSocket names one, a Unix
+// domain socket otherwise
+func (p *Proxy) listen() (net.Listener, error) {
+	if isNamedPipe(p.config.LocalSocket) {
+		listener, err := listenPipe(p.config.LocalSocket)
+		if err != nil {
+			return nil, &config.ProxyError{
+				Category: config.ErrorCategoryRuntime,
+				Message:  fmt.Sprintf("failed to create named pipe listener: %s", p.config.LocalSocket),
+				Cause:    err,
+			}
+		}
+		return listener, nil
+	}
+
+	// Remove existing socket file if it exists
+	if err := os.RemoveAll(p.config.LocalSocket); err != nil {
+		return nil, &config.ProxyError{
+			Category: config.ErrorCategoryRuntime,
+			Message:  fmt.Sprintf("failed to remove existing socket file: %s", p.config.LocalSocket),
+			Cause:    err,
+		}
+	}
+
+	// Create Unix domain socket listener
+	listener, err := net.Listen("unix", p.config.LocalSocket)
+	if err != nil {
+		return nil, &config.ProxyError{
+			Category: config.ErrorCategoryRuntime,
+			Message:  fmt.Sprintf("failed to create Unix socket listener: %s", p.config.LocalSocket),
+			Cause:    err,
+		}
+	}
+	return listener, nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package proxy
+
+import (
+	"errors"
+	"net"
+)
+
+// listenPipe is not supported on this platform
+func listenPipe(string) (net.Listener, error) {
+	return nil, errors.New("named pipes are only supported on Windows")
+}
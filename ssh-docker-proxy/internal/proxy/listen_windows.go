@@ -0,0 +1,27 @@
+//go:build windows
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"os/user"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// listenPipe listens on a named pipe that only the current user, administrators and
+// the system can open
+func listenPipe(path string) (net.Listener, error) {
+	current, err := user.Current()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the current user: %w", err)
+	}
+
+	// On Windows the user ID is the user's security identifier
+	return winio.ListenPipe(path, &winio.PipeConfig{
+		SecurityDescriptor: fmt.Sprintf("D:P(A;;GA;;;BA)(A;;GA;;;SY)(A;;GA;;;%s)", current.Uid),
+		InputBufferSize:    65536,
+		OutputBufferSize:   65536,
+	})
+}
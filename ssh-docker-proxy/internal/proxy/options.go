@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"context"
+	"net"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/ssh"
+)
+
+// Dialer connects to the remote Docker socket; each Dial returns a connection of its own
+type Dialer interface {
+	Dial() (net.Conn, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// Metrics receives the lifecycle of proxied connections, e.g. to export them to a
+// monitoring system. Methods are called from the connections' goroutines.
+type Metrics interface {
+	ConnectionOpened(conn ConnectionSnapshot)
+	// ConnectionClosed reports the bytes a connection carried; failed connections never
+	// reached the remote Docker daemon
+	ConnectionClosed(conn ConnectionSnapshot, failed bool)
+}
+
+// Option customizes a proxy created by NewProxy
+type Option func(*options)
+
+// options are the settings of a proxy that replace parts of its configuration
+type options struct {
+	dialer      Dialer
+	sshClient   *gossh.Client
+	listener    net.Listener
+	connWrapper func(net.Conn) net.Conn
+	metrics     Metrics
+}
+
+// WithDialer connects to the remote Docker socket through dialer instead of SSH
+// connections made from the configuration
+func WithDialer(dialer Dialer) Option {
+	return func(o *options) { o.dialer = dialer }
+}
+
+// WithSSHClient connects to the remote Docker socket through an established SSH client,
+// which the proxy leaves open when it stops
+func WithSSHClient(client *gossh.Client) Option {
+	return func(o *options) { o.sshClient = client }
+}
+
+// WithListener accepts Docker clients on listener instead of the configured local
+// socket; the proxy closes it when it stops
+func WithListener(listener net.Listener) Option {
+	return func(o *options) { o.listener = listener }
+}
+
+// WithConnWrapper wraps every accepted client connection before it is relayed, e.g. to
+// inspect or throttle the traffic
+func WithConnWrapper(wrap func(net.Conn) net.Conn) Option {
+	return func(o *options) { o.connWrapper = wrap }
+}
+
+// WithMetrics reports the lifecycle of every proxied connection to metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) { o.metrics = metrics }
+}
+
+// newOptions applies opts to empty options
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ValidateConfig validates cfg for a proxy created with opts: a dialer or SSH client
+// makes the SSH settings optional and a listener the local socket
+func ValidateConfig(cfg *config.Config, opts ...Option) error {
+	o := newOptions(opts)
+	return cfg.ValidateFor(o.dialer == nil && o.sshClient == nil, o.listener == nil)
+}
+
+// newDialer returns the dialer of the remote Docker socket chosen by the options
+func (o *options) newDialer(cfg *config.Config) (Dialer, error) {
+	switch {
+	case o.dialer != nil:
+		return o.dialer, nil
+	case o.sshClient != nil:
+		return ssh.NewClientDialer(o.sshClient, cfg.RemoteSocket), nil
+	}
+	return ssh.NewSSHDialer(cfg)
+}
@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
+)
+
+// echoDialer connects to an in-memory Docker daemon that echoes what it receives
+type echoDialer struct{}
+
+func (echoDialer) Dial() (net.Conn, error) {
+	local, remote := net.Pipe()
+	go func() {
+		defer remote.Close()
+		_, _ = io.Copy(remote, remote)
+	}()
+	return local, nil
+}
+
+func (echoDialer) HealthCheck(ctx context.Context) error { return nil }
+
+// recordingMetrics records the connection events it receives
+type recordingMetrics struct {
+	mu     sync.Mutex
+	opened []string
+	closed []ConnectionSnapshot
+}
+
+func (m *recordingMetrics) ConnectionOpened(conn ConnectionSnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.opened = append(m.opened, conn.ID)
+}
+
+func (m *recordingMetrics) ConnectionClosed(conn ConnectionSnapshot, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = append(m.closed, conn)
+}
+
+func TestProxyOptions(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	metrics := &recordingMetrics{}
+	var wrapped atomic.Int32
+
+	cfg := &config.Config{DrainTimeout: time.Second}
+	opts := []Option{
+		WithDialer(echoDialer{}),
+		WithListener(listener),
+		WithMetrics(metrics),
+		WithConnWrapper(func(conn net.Conn) net.Conn {
+			wrapped.Add(1)
+			return conn
+		}),
+	}
+	if err := ValidateConfig(cfg, opts...); err != nil {
+		t.Fatalf("ValidateConfig() without SSH settings and socket: %v", err)
+	}
+	p, err := NewProxy(cfg, logging.Discard(), opts...)
+	if err != nil {
+		t.Fatalf("NewProxy() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Start(ctx) }()
+	select {
+	case <-p.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("proxy did not become ready")
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Fatalf("expected echo, got %q, %v", reply, err)
+	}
+	conn.Close()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start() returned %v", err)
+	}
+
+	if got := wrapped.Load(); got != 1 {
+		t.Errorf("connection wrapped %d times, want 1", got)
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.opened) != 1 || len(metrics.closed) != 1 {
+		t.Fatalf("expected one opened and closed connection, got %v and %v", metrics.opened, metrics.closed)
+	}
+	if metrics.closed[0].ID != metrics.opened[0] || metrics.closed[0].BytesSent != 4 || metrics.closed[0].BytesReceived != 4 {
+		t.Errorf("unexpected closed connection %+v", metrics.closed[0])
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := ValidateConfig(&config.Config{}); err == nil {
+		t.Error("expected SSH settings to be required without a dialer")
+	}
+	if err := ValidateConfig(&config.Config{}, WithDialer(echoDialer{})); err == nil {
+		t.Error("expected the local socket to be required without a listener")
+	}
+	if err := ValidateConfig(&config.Config{LocalSocket: "/tmp/docker.sock"}, WithDialer(echoDialer{})); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -2,80 +2,122 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
+	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	"ssh-docker-proxy/internal/config"
-	"ssh-docker-proxy/internal/ssh"
+	"ssh-docker-proxy/internal/logging"
 )
 
 // Proxy represents the main proxy server
 type Proxy struct {
-	config   *config.Config
-	dialer   *ssh.SSHDialer
-	listener net.Listener
-	logger   *log.Logger
-	ctx      context.Context
-	cancel   context.CancelFunc
+	config      *config.Config
+	dialer      Dialer
+	listener    net.Listener
+	logger      logging.Logger
+	connWrapper func(net.Conn) net.Conn
+	metrics     Metrics
+	stats       *Stats
+	statsServer *http.Server
+	supervisor  *supervisor
+	ready       chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	// ownsSocket is set when the proxy created the local socket, which it removes on Stop
+	ownsSocket bool
+
+	// conns are the accepted client connections still being served
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
 }
 
 // NewProxy creates a new proxy instance
-func NewProxy(cfg *config.Config, logger *log.Logger) (*Proxy, error) {
-	// Create SSH dialer
-	dialer, err := ssh.NewSSHDialer(cfg)
+func NewProxy(cfg *config.Config, logger logging.Logger, opts ...Option) (*Proxy, error) {
+	o := newOptions(opts)
+
+	// Create SSH dialer unless the caller brings its own connection
+	dialer, err := o.newDialer(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SSH dialer: %w", err)
 	}
 
 	return &Proxy{
-		config: cfg,
-		dialer: dialer,
-		logger: logger,
+		config:      cfg,
+		dialer:      dialer,
+		listener:    o.listener,
+		logger:      logger,
+		connWrapper: o.connWrapper,
+		metrics:     o.metrics,
+		stats:       newStats(),
+		ready:       make(chan struct{}),
+		conns:       make(map[net.Conn]struct{}),
 	}, nil
 }
 
+// Stats returns the connection statistics of the proxy
+func (p *Proxy) Stats() *Stats {
+	return p.stats
+}
+
+// Ready returns a channel that is closed once the proxy accepts connections
+func (p *Proxy) Ready() <-chan struct{} {
+	return p.ready
+}
+
 // Start begins listening for connections and serving requests
 func (p *Proxy) Start(ctx context.Context) error {
 	// Store context for graceful shutdown
 	p.ctx, p.cancel = context.WithCancel(ctx)
 
-	// Perform health check first
-	p.logger.Printf("Performing health check...")
+	if p.config.Supervise {
+		p.supervisor = newSupervisor(p.dialer.Dial, p.dialer.HealthCheck, p.logger, p.config.ReconnectMaxBackoff, p.config.QueueTimeout)
+	}
+
+	// Perform health check first; a supervised proxy starts anyway and keeps retrying
+	p.logger.Info("Performing health check", nil)
 	if err := p.dialer.HealthCheck(p.ctx); err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		if p.supervisor == nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+		p.supervisor.markDown(p.ctx, err)
+	} else {
+		p.logger.Info("Health check passed, remote Docker daemon is accessible", nil)
 	}
-	p.logger.Printf("Health check passed - remote Docker daemon is accessible")
 
-	// Remove existing socket file if it exists
-	if err := os.RemoveAll(p.config.LocalSocket); err != nil {
-		return &config.ProxyError{
-			Category: config.ErrorCategoryRuntime,
-			Message:  fmt.Sprintf("failed to remove existing socket file: %s", p.config.LocalSocket),
-			Cause:    err,
+	// Listen on the local Unix socket or named pipe, unless given a listener
+	listener := p.listener
+	if listener == nil {
+		var err error
+		if listener, err = p.listen(); err != nil {
+			return err
 		}
+		p.listener = listener
+		p.ownsSocket = true
 	}
 
-	// Create Unix domain socket listener
-	listener, err := net.Listen("unix", p.config.LocalSocket)
-	if err != nil {
-		return &config.ProxyError{
-			Category: config.ErrorCategoryRuntime,
-			Message:  fmt.Sprintf("failed to create Unix socket listener: %s", p.config.LocalSocket),
-			Cause:    err,
+	if p.config.StatsListen != "" {
+		if err := p.serveStats(); err != nil {
+			listener.Close()
+			return err
 		}
 	}
-	p.listener = listener
 
-	p.logger.Printf("Proxy started successfully, listening on %s", p.config.LocalSocket)
+	p.logger.Info("Proxy started", logging.Fields{"socket": listener.Addr().String()})
+	close(p.ready)
 
-	// Handle graceful shutdown
+	// Handle graceful shutdown: stop accepting, then drain the accepted connections
 	go func() {
 		<-p.ctx.Done()
-		p.logger.Printf("Shutting down proxy...")
-		p.Stop()
+		p.logger.Info("Shutting down proxy", nil)
+		listener.Close()
 	}()
 
 	// Accept and handle connections
@@ -84,19 +126,85 @@ func (p *Proxy) Start(ctx context.Context) error {
 		if err != nil {
 			select {
 			case <-p.ctx.Done():
-				return nil // Graceful shutdown
+				p.drain()
+				return p.Stop() // Graceful shutdown
 			default:
-				p.logger.Printf("Failed to accept connection: %v", err)
+				p.logger.Error("Failed to accept connection", logging.Fields{"error": err})
 				continue
 			}
 		}
 
 		// Handle connection in goroutine
-		go p.handleConnection(conn)
+		p.track(conn)
+		go func() {
+			defer p.untrack(conn)
+			p.handleConnection(conn)
+		}()
+	}
+}
+
+// track registers an accepted connection until untrack
+func (p *Proxy) track(conn net.Conn) {
+	p.wg.Add(1)
+	p.mu.Lock()
+	p.conns[conn] = struct{}{}
+	p.mu.Unlock()
+}
+
+// untrack unregisters a connection whose handler finished
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	delete(p.conns, conn)
+	p.mu.Unlock()
+	p.wg.Done()
+}
+
+// drain waits up to the drain timeout for in-flight connections, such as builds and
+// attach sessions, to finish and then closes the remaining ones. It returns how many
+// connections were closed.
+func (p *Proxy) drain() int {
+	p.mu.Lock()
+	inFlight := len(p.conns)
+	p.mu.Unlock()
+	if inFlight == 0 {
+		return 0
+	}
+
+	p.logger.Info("Waiting for in-flight connections to finish", logging.Fields{
+		"connections": inFlight,
+		"timeout":     p.config.DrainTimeout.String(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.logger.Info("All connections finished", logging.Fields{"connections": inFlight})
+		return 0
+	case <-time.After(p.config.DrainTimeout):
+	}
+
+	p.mu.Lock()
+	closed := len(p.conns)
+	for conn := range p.conns {
+		conn.Close()
 	}
+	p.mu.Unlock()
+	<-done
+
+	p.logger.Warn("Drain timeout expired, closed in-flight connections", logging.Fields{
+		"drained": inFlight - closed,
+		"closed":  closed,
+	})
+	return closed
 }
 
-// Stop gracefully shuts down the proxy
+// Stop stops accepting connections and removes the socket; Start then drains the
+// in-flight connections and returns
 func (p *Proxy) Stop() error {
 	// Cancel context to signal shutdown
 	if p.cancel != nil {
@@ -107,58 +215,128 @@ func (p *Proxy) Stop() error {
 		p.listener.Close()
 	}
 
-	// Clean up socket file
-	if err := os.RemoveAll(p.config.LocalSocket); err != nil {
-		p.logger.Printf("Warning: failed to remove socket file: %v", err)
+	if p.statsServer != nil {
+		p.statsServer.Close()
+	}
+
+	// Clean up socket files; a named pipe goes away with its listener
+	if p.ownsSocket && !isNamedPipe(p.config.LocalSocket) {
+		if err := os.RemoveAll(p.config.LocalSocket); err != nil {
+			p.logger.Warn("Failed to remove socket file", logging.Fields{"socket": p.config.LocalSocket, "error": err})
+		}
+	}
+	if path, ok := statsSocketPath(p.config.StatsListen); ok {
+		_ = os.RemoveAll(path)
+	}
+
+	return nil
+}
+
+// serveStats serves the connection statistics as JSON on the configured address
+func (p *Proxy) serveStats() error {
+	if path, ok := statsSocketPath(p.config.StatsListen); ok {
+		if err := os.RemoveAll(path); err != nil {
+			return &config.ProxyError{
+				Category: config.ErrorCategoryRuntime,
+				Message:  fmt.Sprintf("failed to remove existing stats socket: %s", path),
+				Cause:    err,
+			}
+		}
+	}
+
+	listener, err := statsListener(p.config.StatsListen)
+	if err != nil {
+		return &config.ProxyError{
+			Category: config.ErrorCategoryRuntime,
+			Message:  fmt.Sprintf("failed to listen for stats requests on %s", p.config.StatsListen),
+			Cause:    err,
+		}
 	}
 
+	mux := http.NewServeMux()
+	mux.Handle("GET /stats", p.stats)
+	p.statsServer = &http.Server{Handler: mux, ReadHeaderTimeout: statsReadHeaderTimeout}
+	go func() {
+		if err := p.statsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("Stats endpoint stopped", logging.Fields{"error": err})
+		}
+	}()
+
+	p.logger.Info("Serving connection stats", logging.Fields{"address": p.config.StatsListen, "path": "/stats"})
 	return nil
 }
 
 // handleConnection processes a single client connection with proper lifecycle management
 func (p *Proxy) handleConnection(localConn net.Conn) {
-	// Generate connection ID for logging
-	connID := fmt.Sprintf("%p", localConn)
+	conn := p.stats.open()
+	logger := p.logger.With(logging.Fields{"conn": conn.id})
+	failed := true
+	if p.metrics != nil {
+		p.metrics.ConnectionOpened(conn.snapshot())
+	}
 
 	defer func() {
 		localConn.Close()
-		p.logger.Printf("[%s] Connection cleanup completed", connID)
+		p.stats.close(conn, failed)
+		if p.metrics != nil {
+			p.metrics.ConnectionClosed(conn.snapshot(), failed)
+		}
+		logger.Debug("Connection cleanup completed", nil)
 	}()
 
-	p.logger.Printf("[%s] New connection established from client %s", connID, localConn.RemoteAddr())
+	if p.connWrapper != nil {
+		localConn = p.connWrapper(localConn)
+	}
+
+	logger.Info("New connection", logging.Fields{"client": localConn.RemoteAddr().String()})
 
 	// Create fresh SSH connection for this client (per-connection SSH stream for isolation)
-	remoteConn, err := p.dialer.Dial()
+	remoteConn, err := p.dial()
 	if err != nil {
-		p.logger.Printf("[%s] Failed to establish SSH connection: %v", connID, err)
+		logger.Error("Failed to establish SSH connection", logging.Fields{"error": err})
+		if errors.Is(err, errReconnecting) {
+			rejectRequest(localConn, err)
+		}
 		return
 	}
+	failed = false
 	defer func() {
 		remoteConn.Close()
-		p.logger.Printf("[%s] SSH connection closed", connID)
+		logger.Debug("SSH connection closed", nil)
 	}()
 
-	p.logger.Printf("[%s] SSH connection established to remote Docker daemon", connID)
+	logger.Debug("SSH connection established to remote Docker daemon", nil)
+
+	// Relay traffic bidirectionally using pure byte copying, counting the bytes
+	relayTraffic(&countingConn{Conn: localConn, stats: p.stats, conn: conn}, remoteConn, logger)
 
-	// Relay traffic bidirectionally using pure byte copying
-	relayTraffic(localConn, remoteConn, p.logger)
+	logger.Info("Connection terminated", logging.Fields{
+		"bytes_sent":     conn.sent.Load(),
+		"bytes_received": conn.received.Load(),
+		"duration":       time.Since(conn.started).Round(time.Millisecond).String(),
+	})
+}
 
-	p.logger.Printf("[%s] Connection terminated", connID)
+// dial connects to the remote Docker socket, through the supervisor in supervised mode
+func (p *Proxy) dial() (net.Conn, error) {
+	if p.supervisor != nil {
+		return p.supervisor.Dial(p.ctx)
+	}
+	return p.dialer.Dial()
 }
 
 // relayTraffic performs bidirectional byte copying between connections
-func relayTraffic(local, remote net.Conn, logger *log.Logger) {
+func relayTraffic(local, remote net.Conn, logger logging.Logger) {
 	done := make(chan struct{}, 2)
-	connID := fmt.Sprintf("%p", local)
 
 	// Copy from local to remote
 	go func() {
 		defer func() { done <- struct{}{} }()
 		bytes, err := io.Copy(remote, local)
 		if err != nil && err != io.EOF {
-			logger.Printf("[%s] Local->Remote copy ended with error after %d bytes: %v", connID, bytes, err)
+			logger.Warn("Local->Remote copy ended with error", logging.Fields{"bytes": bytes, "error": err})
 		} else {
-			logger.Printf("[%s] Local->Remote copy completed, %d bytes transferred", connID, bytes)
+			logger.Debug("Local->Remote copy completed", logging.Fields{"bytes": bytes})
 		}
 	}()
 
@@ -167,13 +345,13 @@ func relayTraffic(local, remote net.Conn, logger *log.Logger) {
 		defer func() { done <- struct{}{} }()
 		bytes, err := io.Copy(local, remote)
 		if err != nil && err != io.EOF {
-			logger.Printf("[%s] Remote->Local copy ended with error after %d bytes: %v", connID, bytes, err)
+			logger.Warn("Remote->Local copy ended with error", logging.Fields{"bytes": bytes, "error": err})
 		} else {
-			logger.Printf("[%s] Remote->Local copy completed, %d bytes transferred", connID, bytes)
+			logger.Debug("Remote->Local copy completed", logging.Fields{"bytes": bytes})
 		}
 	}()
 
 	// Wait for either direction to complete
 	<-done
-	logger.Printf("[%s] Traffic relay completed", connID)
+	logger.Debug("Traffic relay completed", nil)
 }
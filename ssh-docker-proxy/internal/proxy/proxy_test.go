@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
 )
 
 // mockConn implements net.Conn for testing
@@ -112,7 +113,7 @@ func TestRelayTraffic(t *testing.T) {
 			remoteConn := newMockConn()
 
 			// Create a logger that discards output for testing
-			logger := log.New(io.Discard, "", 0)
+			logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 			// Write test data to connections
 			localConn.writeData(tt.localData)
@@ -181,7 +182,7 @@ NhAAAAAwEAAQAAAQEA1234567890abcdef
 		Timeout:      10 * time.Second,
 	}
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Test proxy creation - this should fail because we can't actually connect to SSH
 	// but it should validate the configuration and create the proxy struct
@@ -279,7 +280,7 @@ func TestRelayTrafficConcurrency(t *testing.T) {
 	// Test that relayTraffic can handle concurrent read/write operations
 	localConn := newMockConn()
 	remoteConn := newMockConn()
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Test data for concurrent operations
 	testData1 := []byte("First message")
@@ -341,7 +342,7 @@ func TestConcurrentConnections(t *testing.T) {
 	numConnections := 5
 	connections := make([]*mockConn, numConnections*2) // local and remote pairs
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Create connection pairs
 	for i := 0; i < numConnections; i++ {
@@ -412,7 +413,7 @@ func TestConnectionLifecycleManagement(t *testing.T) {
 
 	// Capture log output to verify lifecycle logging
 	var logBuffer bytes.Buffer
-	logger := log.New(&logBuffer, "", 0)
+	logger := logging.FromStd(log.New(&logBuffer, "", 0))
 
 	// Write test data
 	testData := []byte("Lifecycle test data")
@@ -458,7 +459,7 @@ func TestConnectionIsolation(t *testing.T) {
 	conn2Local := newMockConn()
 	conn2Remote := newMockConn()
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Different data for each connection
 	data1 := []byte("Connection 1 exclusive data")
@@ -521,3 +522,63 @@ func TestConnectionIsolation(t *testing.T) {
 		t.Error("Connection 2 did not receive its correct data")
 	}
 }
+
+func TestIsNamedPipe(t *testing.T) {
+	tests := map[string]bool{
+		"//./pipe/docker-proxy":  true,
+		`\\.\pipe\docker-proxy`:  true,
+		"/tmp/docker.sock":       false,
+		"./pipe/docker-proxy":    false,
+		"//server/pipe/whatever": false,
+	}
+	for path, want := range tests {
+		if got := isNamedPipe(path); got != want {
+			t.Errorf("isNamedPipe(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	p := &Proxy{
+		config: &config.Config{DrainTimeout: 50 * time.Millisecond},
+		logger: logging.Discard(),
+		conns:  make(map[net.Conn]struct{}),
+	}
+
+	// serve reads from conn until it is closed, or until finish is closed
+	serve := func(conn net.Conn, finish chan struct{}) {
+		p.track(conn)
+		go func() {
+			defer p.untrack(conn)
+			go func() {
+				<-finish
+				conn.Close()
+			}()
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+	}
+
+	finished, finishedPeer := net.Pipe()
+	defer finishedPeer.Close()
+	finish := make(chan struct{})
+	serve(finished, finish)
+	hung, hungPeer := net.Pipe()
+	defer hungPeer.Close()
+	serve(hung, make(chan struct{}))
+
+	time.AfterFunc(10*time.Millisecond, func() { close(finish) })
+	start := time.Now()
+	if closed := p.drain(); closed != 1 {
+		t.Errorf("drain() closed %d connections, want 1", closed)
+	}
+	if elapsed := time.Since(start); elapsed < p.config.DrainTimeout {
+		t.Errorf("drain() returned after %v, before the drain timeout", elapsed)
+	}
+	if _, err := hungPeer.Write([]byte("x")); err == nil {
+		t.Error("expected the hung connection to be closed")
+	}
+
+	if closed := p.drain(); closed != 0 {
+		t.Errorf("drain() without connections closed %d, want 0", closed)
+	}
+}
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts the connections of a proxy and the bytes they carry
+type Stats struct {
+	started time.Time
+
+	nextID   atomic.Uint64
+	total    atomic.Int64
+	failed   atomic.Int64
+	sent     atomic.Int64
+	received atomic.Int64
+
+	mu     sync.Mutex
+	active map[string]*connStats
+}
+
+// connStats counts the bytes of one connection
+type connStats struct {
+	id       string
+	started  time.Time
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// Snapshot is the state of the proxy's connections at one point in time
+type Snapshot struct {
+	Uptime            string               `json:"uptime"`
+	ActiveConnections int                  `json:"active_connections"`
+	TotalConnections  int64                `json:"total_connections"`
+	FailedConnections int64                `json:"failed_connections"`
+	BytesSent         int64                `json:"bytes_sent"`
+	BytesReceived     int64                `json:"bytes_received"`
+	Connections       []ConnectionSnapshot `json:"connections"`
+}
+
+// ConnectionSnapshot is the state of one active connection. Sent bytes go from the
+// local client to the remote Docker daemon, received bytes the other way.
+type ConnectionSnapshot struct {
+	ID            string    `json:"id"`
+	StartedAt     time.Time `json:"started_at"`
+	BytesSent     int64     `json:"bytes_sent"`
+	BytesReceived int64     `json:"bytes_received"`
+}
+
+// newStats returns empty statistics
+func newStats() *Stats {
+	return &Stats{started: time.Now(), active: make(map[string]*connStats)}
+}
+
+// open registers a new connection and returns its counters
+func (s *Stats) open() *connStats {
+	conn := &connStats{
+		id:      "c" + strconv.FormatUint(s.nextID.Add(1), 10),
+		started: time.Now(),
+	}
+	s.total.Add(1)
+
+	s.mu.Lock()
+	s.active[conn.id] = conn
+	s.mu.Unlock()
+	return conn
+}
+
+// close unregisters a connection; failed connections never reached the remote daemon
+func (s *Stats) close(conn *connStats, failed bool) {
+	if failed {
+		s.failed.Add(1)
+	}
+	s.mu.Lock()
+	delete(s.active, conn.id)
+	s.mu.Unlock()
+}
+
+// Snapshot returns the current statistics, active connections oldest first
+func (s *Stats) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		Uptime:            time.Since(s.started).Round(time.Second).String(),
+		TotalConnections:  s.total.Load(),
+		FailedConnections: s.failed.Load(),
+		BytesSent:         s.sent.Load(),
+		BytesReceived:     s.received.Load(),
+		Connections:       []ConnectionSnapshot{},
+	}
+
+	s.mu.Lock()
+	for _, conn := range s.active {
+		snapshot.Connections = append(snapshot.Connections, conn.snapshot())
+	}
+	s.mu.Unlock()
+
+	snapshot.ActiveConnections = len(snapshot.Connections)
+	slices.SortFunc(snapshot.Connections, func(a, b ConnectionSnapshot) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return snapshot
+}
+
+// snapshot returns the current state of the connection
+func (c *connStats) snapshot() ConnectionSnapshot {
+	return ConnectionSnapshot{
+		ID:            c.id,
+		StartedAt:     c.started,
+		BytesSent:     c.sent.Load(),
+		BytesReceived: c.received.Load(),
+	}
+}
+
+// ServeHTTP serves the snapshot as JSON
+func (s *Stats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.Snapshot())
+}
+
+// countingConn counts the bytes read from and written to the local connection into the
+// connection's and the proxy's counters
+type countingConn struct {
+	net.Conn
+	stats *Stats
+	conn  *connStats
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.conn.sent.Add(int64(n))
+	c.stats.sent.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.conn.received.Add(int64(n))
+	c.stats.received.Add(int64(n))
+	return n, err
+}
+
+// statsReadHeaderTimeout bounds slow stats requests
+const statsReadHeaderTimeout = 5 * time.Second
+
+// statsListener returns the listener of the stats endpoint: a Unix socket when addr is
+// "unix:<path>" or an absolute path, TCP otherwise
+func statsListener(addr string) (net.Listener, error) {
+	if path, ok := statsSocketPath(addr); ok {
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
+}
+
+// statsSocketPath returns the Unix socket path of a stats address, if it names one
+func statsSocketPath(addr string) (string, bool) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return path, true
+	}
+	return addr, strings.HasPrefix(addr, "/")
+}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ssh-docker-proxy/internal/logging"
+)
+
+func TestStatsCountsRelayedBytes(t *testing.T) {
+	stats := newStats()
+	conn := stats.open()
+	failedConn := stats.open()
+	stats.close(failedConn, true)
+
+	localConn := newMockConn()
+	remoteConn := newMockConn()
+	localConn.writeData([]byte("GET /_ping"))
+	remoteConn.writeData([]byte("OK"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relayTraffic(&countingConn{Conn: localConn, stats: stats, conn: conn}, remoteConn, logging.Discard())
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	snapshot := stats.Snapshot()
+	if snapshot.ActiveConnections != 1 || snapshot.TotalConnections != 2 || snapshot.FailedConnections != 1 {
+		t.Errorf("unexpected connection counts: %+v", snapshot)
+	}
+	if snapshot.BytesSent != 10 || snapshot.BytesReceived != 2 {
+		t.Errorf("expected 10 bytes sent and 2 received, got %d and %d", snapshot.BytesSent, snapshot.BytesReceived)
+	}
+	if len(snapshot.Connections) != 1 || snapshot.Connections[0].ID != conn.id || snapshot.Connections[0].BytesSent != 10 {
+		t.Errorf("unexpected active connections: %+v", snapshot.Connections)
+	}
+
+	localConn.Close()
+	remoteConn.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Relay did not complete within timeout")
+	}
+	stats.close(conn, false)
+
+	if snapshot := stats.Snapshot(); snapshot.ActiveConnections != 0 || snapshot.BytesSent != 10 {
+		t.Errorf("expected totals to outlive the connection: %+v", snapshot)
+	}
+}
+
+func TestStatsServeHTTP(t *testing.T) {
+	stats := newStats()
+	stats.open()
+
+	recorder := httptest.NewRecorder()
+	stats.ServeHTTP(recorder, httptest.NewRequest("GET", "/stats", nil))
+
+	if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var snapshot Snapshot
+	if err := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes())).Decode(&snapshot); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", recorder.Body.String(), err)
+	}
+	if snapshot.ActiveConnections != 1 || snapshot.Connections[0].ID != "c1" {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+}
+
+func TestStatsSocketPath(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		ok   bool
+	}{
+		"unix:/tmp/stats.sock": {"/tmp/stats.sock", true},
+		"/tmp/stats.sock":      {"/tmp/stats.sock", true},
+		"127.0.0.1:9180":       {"127.0.0.1:9180", false},
+	}
+	for addr, want := range tests {
+		path, ok := statsSocketPath(addr)
+		if ok != want.ok || (ok && path != want.path) {
+			t.Errorf("statsSocketPath(%q) = %q, %v, want %q, %v", addr, path, ok, want.path, want.ok)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"ssh-docker-proxy/internal/logging"
 )
 
 // streamingPipe creates a pair of connected pipes for streaming tests
@@ -51,7 +53,7 @@ func TestStreamingOperationsSupport(t *testing.T) {
 		writer: remoteToLocalPipe.writer,
 	}
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Start relay
 	done := make(chan struct{})
@@ -163,7 +165,7 @@ func TestLargeDataTransfer(t *testing.T) {
 		writer: remoteToLocalPipe.writer,
 	}
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 
 	// Start relay
 	done := make(chan struct{})
@@ -239,7 +241,7 @@ func TestConcurrentStreamingConnections(t *testing.T) {
 	// Test multiple concurrent streaming connections
 	numConnections := 3
 
-	logger := log.New(io.Discard, "", 0)
+	logger := logging.FromStd(log.New(io.Discard, "", 0))
 	done := make(chan struct{}, numConnections)
 
 	for i := 0; i < numConnections; i++ {
@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
+)
+
+// initialBackoff is the first delay between reconnection attempts; it doubles up to the
+// configured maximum
+const initialBackoff = time.Second
+
+// errReconnecting is returned for connections that waited too long for the SSH
+// connection to come back
+var errReconnecting = errors.New("SSH connection to the remote Docker host is down, reconnecting")
+
+// supervisor keeps the proxy serving while the SSH connection is down: it probes the
+// remote host with exponential backoff until it is reachable again and holds incoming
+// connections in the meantime, failing them after the queue timeout
+type supervisor struct {
+	dial         func() (net.Conn, error)
+	probe        func(ctx context.Context) error
+	logger       logging.Logger
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	queueTimeout time.Duration
+
+	mu sync.Mutex
+	// up is closed while the remote host is reachable
+	up           chan struct{}
+	reconnecting bool
+}
+
+// newSupervisor returns a supervisor of a reachable remote host
+func newSupervisor(dial func() (net.Conn, error), probe func(ctx context.Context) error, logger logging.Logger, maxBackoff, queueTimeout time.Duration) *supervisor {
+	up := make(chan struct{})
+	close(up)
+	return &supervisor{
+		dial:         dial,
+		probe:        probe,
+		logger:       logger,
+		backoff:      initialBackoff,
+		maxBackoff:   maxBackoff,
+		queueTimeout: queueTimeout,
+		up:           up,
+	}
+}
+
+// Dial connects to the remote Docker socket, waiting up to the queue timeout for the SSH
+// connection to come back when it is down
+func (s *supervisor) Dial(ctx context.Context) (net.Conn, error) {
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.upChan():
+		case <-timer.C:
+			return nil, errReconnecting
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		conn, err := s.dial()
+		if err == nil || !isSSHError(err) {
+			return conn, err
+		}
+		s.markDown(ctx, err)
+	}
+}
+
+// upChan returns the channel closed while the remote host is reachable
+func (s *supervisor) upChan() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.up
+}
+
+// markDown records that the SSH connection failed and starts reconnecting, unless a
+// reconnection is already running
+func (s *supervisor) markDown(ctx context.Context, cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reconnecting {
+		return
+	}
+	s.reconnecting = true
+	s.up = make(chan struct{})
+
+	s.logger.Warn("SSH connection lost, reconnecting", logging.Fields{"error": cause})
+	go s.reconnect(ctx)
+}
+
+// reconnect probes the remote host with exponential backoff until it is reachable
+func (s *supervisor) reconnect(ctx context.Context) {
+	backoff := s.backoff
+	for attempt := 1; ; attempt++ {
+		err := s.probe(ctx)
+		if err == nil {
+			s.mu.Lock()
+			s.reconnecting = false
+			close(s.up)
+			s.mu.Unlock()
+			s.logger.Info("SSH connection restored", logging.Fields{"attempts": attempt})
+			return
+		}
+
+		s.logger.Warn("Reconnection attempt failed", logging.Fields{"attempt": attempt, "retry_in": backoff.String(), "error": err})
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(2*backoff, s.maxBackoff)
+	}
+}
+
+// isSSHError reports whether err means the SSH connection, not the remote Docker
+// socket, failed
+func isSSHError(err error) bool {
+	var proxyErr *config.ProxyError
+	return errors.As(err, &proxyErr) && proxyErr.Category == config.ErrorCategorySSH
+}
+
+// rejectRequest answers the first request of a Docker client with 503 Service
+// Unavailable and the reason, which the Docker CLI shows as the error message
+func rejectRequest(conn net.Conn, reason error) {
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := http.ReadRequest(bufio.NewReader(conn)); err != nil {
+		return
+	}
+
+	body := fmt.Sprintf("{\"message\":%q}\n", "ssh-docker-proxy: "+reason.Error())
+	response := strings.Join([]string{
+		"HTTP/1.1 503 Service Unavailable",
+		"Content-Type: application/json",
+		fmt.Sprintf("Content-Length: %d", len(body)),
+		"Connection: close",
+		"",
+		body,
+	}, "\r\n")
+	_, _ = conn.Write([]byte(response))
+}
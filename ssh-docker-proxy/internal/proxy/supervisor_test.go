@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
+)
+
+var errSSHDown = &config.ProxyError{Category: config.ErrorCategorySSH, Message: "failed to connect to SSH server"}
+
+func TestSupervisorReconnects(t *testing.T) {
+	var reachable atomic.Bool
+	var probes atomic.Int32
+	dial := func() (net.Conn, error) {
+		if !reachable.Load() {
+			return nil, errSSHDown
+		}
+		local, remote := net.Pipe()
+		remote.Close()
+		return local, nil
+	}
+	probe := func(ctx context.Context) error {
+		if probes.Add(1) < 3 {
+			return errSSHDown
+		}
+		reachable.Store(true)
+		return nil
+	}
+
+	s := newSupervisor(dial, probe, logging.Discard(), 4*time.Millisecond, time.Second)
+	s.backoff = time.Millisecond
+
+	conn, err := s.Dial(context.Background())
+	if err != nil {
+		t.Fatalf("Dial() should wait for the reconnection, got %v", err)
+	}
+	conn.Close()
+	if got := probes.Load(); got != 3 {
+		t.Errorf("expected 3 probes, got %d", got)
+	}
+	if s.reconnecting {
+		t.Error("supervisor should be up after reconnecting")
+	}
+}
+
+func TestSupervisorQueueTimeout(t *testing.T) {
+	dial := func() (net.Conn, error) { return nil, errSSHDown }
+	probe := func(ctx context.Context) error { return errSSHDown }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := newSupervisor(dial, probe, logging.Discard(), time.Millisecond, 20*time.Millisecond)
+	s.backoff = time.Millisecond
+
+	start := time.Now()
+	if _, err := s.Dial(ctx); !errors.Is(err, errReconnecting) {
+		t.Fatalf("expected errReconnecting, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Dial() failed after %v, before the queue timeout", elapsed)
+	}
+}
+
+func TestSupervisorPassesDockerErrors(t *testing.T) {
+	dockerErr := &config.ProxyError{Category: config.ErrorCategoryDocker, Message: "failed to connect to Docker socket"}
+	dial := func() (net.Conn, error) { return nil, dockerErr }
+	probe := func(ctx context.Context) error {
+		t.Error("a Docker error should not trigger a reconnection")
+		return nil
+	}
+
+	s := newSupervisor(dial, probe, logging.Discard(), time.Second, time.Second)
+	if _, err := s.Dial(context.Background()); !errors.Is(err, dockerErr) {
+		t.Errorf("expected the Docker error, got %v", err)
+	}
+}
+
+func TestRejectRequest(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+		rejectRequest(server, errReconnecting)
+	}()
+
+	request, _ := http.NewRequest("GET", "http://docker/_ping", nil)
+	if err := request.Write(client); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+	response, err := http.ReadResponse(bufio.NewReader(client), request)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", response.StatusCode)
+	}
+	body, _ := io.ReadAll(response.Body)
+	if !strings.Contains(string(body), `"message":"ssh-docker-proxy: SSH connection`) {
+		t.Errorf("unexpected body %q", body)
+	}
+}
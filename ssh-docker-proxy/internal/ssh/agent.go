@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AgentSocketEnv is the environment variable that points at the local ssh-agent
+const AgentSocketEnv = "SSH_AUTH_SOCK"
+
+// keyAuth supplies the proxy's public key signers: keys from the ssh-agent, then the
+// private key file. The agent connection stays open across SSH connections and is
+// re-dialed when it breaks, e.g. after the agent restarted.
+type keyAuth struct {
+	useAgent    bool
+	agentSocket string
+	preferred   ssh.PublicKey
+	fileSigner  ssh.Signer
+
+	mu        sync.Mutex
+	agentConn net.Conn
+	agent     agent.ExtendedAgent
+}
+
+// signers returns the signers to offer, in order. It is used as an
+// ssh.PublicKeysCallback and therefore runs on every new SSH connection.
+func (a *keyAuth) signers() ([]ssh.Signer, error) {
+	var (
+		signers  []ssh.Signer
+		agentErr error
+	)
+
+	if a.useAgent {
+		signers, agentErr = a.agentSigners()
+	}
+
+	if a.fileSigner != nil && !containsKey(signers, a.fileSigner.PublicKey()) {
+		signers = append(signers, a.fileSigner)
+	}
+
+	if len(signers) == 0 {
+		if agentErr != nil {
+			return nil, agentErr
+		}
+		return nil, fmt.Errorf("no SSH keys available")
+	}
+	return signers, nil
+}
+
+// agentSigners lists the agent's signers, reconnecting once if the agent connection broke
+func (a *keyAuth) agentSigners() ([]ssh.Signer, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if a.agent == nil {
+			if err := a.connectAgent(); err != nil {
+				return nil, err
+			}
+		}
+
+		signers, err := a.agent.Signers()
+		if err != nil {
+			a.agentConn.Close()
+			a.agent = nil
+			continue
+		}
+
+		// Offer just the configured key when the agent has it; each extra key counts
+		// against the server's authentication attempt limit
+		if a.preferred != nil {
+			for _, signer := range signers {
+				if bytes.Equal(signer.PublicKey().Marshal(), a.preferred.Marshal()) {
+					return []ssh.Signer{signer}, nil
+				}
+			}
+		}
+		return signers, nil
+	}
+
+	return nil, fmt.Errorf("failed to list SSH agent keys")
+}
+
+// connectAgent dials the agent socket
+func (a *keyAuth) connectAgent() error {
+	socket := a.agentSocket
+	if socket == "" {
+		socket = os.Getenv(AgentSocketEnv)
+	}
+	if socket == "" {
+		return fmt.Errorf("SSH agent requested but %s is not set", AgentSocketEnv)
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SSH agent: %w", err)
+	}
+
+	a.agentConn = conn
+	a.agent = agent.NewClient(conn)
+	return nil
+}
+
+// containsKey reports whether any signer has the given public key
+func containsKey(signers []ssh.Signer, key ssh.PublicKey) bool {
+	for _, signer := range signers {
+		if bytes.Equal(signer.PublicKey().Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPublicKey reads the authorized_keys formatted public key at keyPath + ".pub"
+func loadPublicKey(keyPath string) ssh.PublicKey {
+	data, err := os.ReadFile(keyPath + ".pub") // #nosec G304
+	if err != nil {
+		return nil
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil
+	}
+	return publicKey
+}
@@ -0,0 +1,109 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"ssh-docker-proxy/internal/config"
+)
+
+// startTestAgent serves an in-memory keyring holding keys on a Unix socket
+func startTestAgent(t *testing.T, keys ...ed25519.PrivateKey) string {
+	t.Helper()
+
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	}
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	return socket
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func TestNewSSHDialer_AgentOnlyKey(t *testing.T) {
+	other, configured := newEd25519Key(t), newEd25519Key(t)
+	socket := startTestAgent(t, other, configured)
+
+	// Only the public half is on disk, as for keys on a hardware token
+	publicKey, err := ssh.NewPublicKey(configured.Public())
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath+".pub", ssh.MarshalAuthorizedKey(publicKey), 0600))
+
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:     "testuser",
+		SSHHost:     "testhost:22",
+		SSHKeyPath:  keyPath,
+		SSHAgent:    true,
+		SSHAuthSock: socket,
+		Timeout:     time.Second,
+	})
+	require.NoError(t, err)
+	require.Len(t, dialer.sshConfig.Auth, 1)
+
+	signers, err := dialer.auth.signers()
+	require.NoError(t, err)
+	require.Len(t, signers, 1)
+	assert.Equal(t, publicKey.Marshal(), signers[0].PublicKey().Marshal())
+}
+
+func TestNewSSHDialer_AgentFallsBackToKeyFile(t *testing.T) {
+	keyPath := generateTestSSHKey(t)
+
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:     "testuser",
+		SSHHost:     "testhost:22",
+		SSHKeyPath:  keyPath,
+		SSHAgent:    true,
+		SSHAuthSock: filepath.Join(t.TempDir(), "missing.sock"),
+		Timeout:     time.Second,
+	})
+	require.NoError(t, err)
+
+	signers, err := dialer.auth.signers()
+	require.NoError(t, err)
+	assert.Len(t, signers, 1)
+}
+
+func TestNewSSHDialer_NoKeyWithoutAgent(t *testing.T) {
+	_, err := NewSSHDialer(&config.Config{SSHUser: "testuser", SSHHost: "testhost"})
+	require.Error(t, err)
+
+	proxyErr, ok := err.(*config.ProxyError)
+	require.True(t, ok)
+	assert.Equal(t, config.ErrorCategorySSH, proxyErr.Category)
+}
@@ -0,0 +1,42 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+
+	"ssh-docker-proxy/internal/config"
+)
+
+// ClientDialer connects to the remote Docker socket through an SSH client established
+// by the caller, such as a program embedding the proxy that already holds a connection
+// to the host. The client stays open when the proxy stops.
+type ClientDialer struct {
+	client       *ssh.Client
+	remoteSocket string
+}
+
+// NewClientDialer returns a dialer of the Docker socket at remoteSocket through client
+func NewClientDialer(client *ssh.Client, remoteSocket string) *ClientDialer {
+	return &ClientDialer{client: client, remoteSocket: remoteSocket}
+}
+
+// Dial opens a new stream to the remote Docker socket over the SSH client
+func (d *ClientDialer) Dial() (net.Conn, error) {
+	conn, err := d.client.Dial("unix", d.remoteSocket)
+	if err != nil {
+		return nil, &config.ProxyError{
+			Category: config.ErrorCategoryDocker,
+			Message:  fmt.Sprintf("failed to connect to remote Docker socket %s", d.remoteSocket),
+			Cause:    err,
+		}
+	}
+	return conn, nil
+}
+
+// HealthCheck verifies the remote Docker daemon is accessible
+func (d *ClientDialer) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, d.Dial)
+}
@@ -0,0 +1,41 @@
+package ssh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"ssh-docker-proxy/internal/config"
+)
+
+func TestClientDialer_Dial(t *testing.T) {
+	keyPath := generateTestSSHKey(t)
+	server := startTestSSHServer(t, "docker", keyPath)
+	socketPath := startEchoSocket(t)
+
+	auth, err := newKeyAuth(&config.Config{}, keyPath)
+	require.NoError(t, err)
+	client, err := ssh.Dial("tcp", server.addr, newClientConfig("docker", auth, 5*time.Second))
+	require.NoError(t, err)
+	defer client.Close()
+
+	dialer := NewClientDialer(client, socketPath)
+	for range 2 {
+		conn, err := dialer.Dial()
+		require.NoError(t, err)
+		assertEcho(t, conn)
+		require.NoError(t, conn.Close())
+	}
+
+	// Closing the streams leaves the caller's client open
+	assert.Equal(t, int32(1), server.open.Load())
+
+	_, err = NewClientDialer(client, socketPath+".missing").Dial()
+	require.Error(t, err)
+	proxyErr, ok := err.(*config.ProxyError)
+	require.True(t, ok)
+	assert.Equal(t, config.ErrorCategoryDocker, proxyErr.Category)
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/client"
 	"golang.org/x/crypto/ssh"
@@ -18,23 +20,99 @@ import (
 type SSHDialer struct {
 	config    *config.Config
 	sshConfig *ssh.ClientConfig
+	auth      *keyAuth
+	// jumps are the jump hosts connected through, in order, before the target host
+	jumps []hop
+}
+
+// hop is an SSH server on the way to the remote Docker socket
+type hop struct {
+	addr      string
+	sshConfig *ssh.ClientConfig
 }
 
 // NewSSHDialer creates a new SSH dialer with the given configuration
 func NewSSHDialer(cfg *config.Config) (*SSHDialer, error) {
+	auth, err := newKeyAuth(cfg, cfg.SSHKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &SSHDialer{
+		config:    cfg,
+		sshConfig: newClientConfig(cfg.SSHUser, auth, cfg.Timeout),
+		auth:      auth,
+	}
+
+	// Jump hosts without a key of their own share the target's
+	for _, jumpHost := range cfg.SSHJumpHosts {
+		jumpAuth := auth
+		if jumpHost.KeyPath != "" {
+			if jumpAuth, err = newKeyAuth(cfg, jumpHost.KeyPath); err != nil {
+				return nil, err
+			}
+		}
+		user := jumpHost.User
+		if user == "" {
+			user = cfg.SSHUser
+		}
+		dialer.jumps = append(dialer.jumps, hop{
+			addr:      normalizeSSHHost(jumpHost.Host),
+			sshConfig: newClientConfig(user, jumpAuth, cfg.Timeout),
+		})
+	}
+
+	return dialer, nil
+}
+
+// newClientConfig returns the SSH client configuration of one hop
+func newClientConfig(user string, auth *keyAuth, timeout time.Duration) *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeysCallback(auth.signers),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106 // TODO: Implement proper host key verification
+		Timeout:         timeout,
+	}
+}
+
+// newKeyAuth loads the private key at keyPath and prepares ssh-agent authentication.
+// With the agent enabled the key file is optional: keys held only in the agent (or on
+// a hardware token) are selected by the public key next to the configured path.
+func newKeyAuth(cfg *config.Config, keyPath string) (*keyAuth, error) {
+	auth := &keyAuth{
+		useAgent:    cfg.SSHAgent,
+		agentSocket: cfg.SSHAuthSock,
+	}
+
+	if keyPath == "" {
+		if !cfg.SSHAgent {
+			return nil, &config.ProxyError{
+				Category: config.ErrorCategorySSH,
+				Message:  "no SSH key path configured and SSH agent disabled",
+			}
+		}
+		return auth, nil
+	}
+
 	// Expand SSH key path (handle ~ for home directory)
-	keyPath, err := expandPath(cfg.SSHKeyPath)
+	keyPath, err := expandPath(keyPath)
 	if err != nil {
 		return nil, &config.ProxyError{
 			Category: config.ErrorCategorySSH,
-			Message:  fmt.Sprintf("failed to expand SSH key path: %s", cfg.SSHKeyPath),
+			Message:  fmt.Sprintf("failed to expand SSH key path: %s", keyPath),
 			Cause:    err,
 		}
 	}
+	auth.preferred = loadPublicKey(keyPath)
 
 	// Load SSH private key
 	keyBytes, err := os.ReadFile(keyPath) // #nosec G304
 	if err != nil {
+		if cfg.SSHAgent {
+			return auth, nil
+		}
 		return nil, &config.ProxyError{
 			Category: config.ErrorCategorySSH,
 			Message:  fmt.Sprintf("failed to read SSH key file: %s", keyPath),
@@ -45,48 +123,36 @@ func NewSSHDialer(cfg *config.Config) (*SSHDialer, error) {
 	// Parse private key
 	signer, err := ssh.ParsePrivateKey(keyBytes)
 	if err != nil {
+		// Passphrase-protected keys can still be used through the agent
+		if cfg.SSHAgent {
+			return auth, nil
+		}
 		return nil, &config.ProxyError{
 			Category: config.ErrorCategorySSH,
 			Message:  "failed to parse SSH private key",
 			Cause:    err,
 		}
 	}
-
-	// Create SSH client configuration
-	sshConfig := &ssh.ClientConfig{
-		User: cfg.SSHUser,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106 // TODO: Implement proper host key verification
-		Timeout:         cfg.Timeout,
+	auth.fileSigner = signer
+	if auth.preferred == nil {
+		auth.preferred = signer.PublicKey()
 	}
 
-	return &SSHDialer{
-		config:    cfg,
-		sshConfig: sshConfig,
-	}, nil
+	return auth, nil
 }
 
-// Dial establishes a new SSH connection to the remote Docker socket
+// Dial establishes a new SSH connection to the remote Docker socket. Closing the
+// returned connection closes the SSH connections it was made through.
 func (d *SSHDialer) Dial() (net.Conn, error) {
-	// Ensure SSH host has a port
-	sshHost := normalizeSSHHost(d.config.SSHHost)
-
-	// Connect to SSH server
-	sshConn, err := ssh.Dial("tcp", sshHost, d.sshConfig)
+	clients, err := d.connect()
 	if err != nil {
-		return nil, &config.ProxyError{
-			Category: config.ErrorCategorySSH,
-			Message:  fmt.Sprintf("failed to connect to SSH server %s", sshHost),
-			Cause:    err,
-		}
+		return nil, err
 	}
 
 	// Connect to remote Docker socket
-	conn, err := sshConn.Dial("unix", d.config.RemoteSocket)
+	conn, err := clients[len(clients)-1].Dial("unix", d.config.RemoteSocket)
 	if err != nil {
-		sshConn.Close()
+		closeClients(clients)
 		return nil, &config.ProxyError{
 			Category: config.ErrorCategoryDocker,
 			Message:  fmt.Sprintf("failed to connect to remote Docker socket %s", d.config.RemoteSocket),
@@ -94,17 +160,144 @@ func (d *SSHDialer) Dial() (net.Conn, error) {
 		}
 	}
 
-	return conn, nil
+	if d.config.SSHKeepAlive > 0 {
+		go keepAlive(clients, d.config.SSHKeepAlive)
+	}
+
+	return &chainConn{Conn: conn, clients: clients}, nil
+}
+
+// keepAliveMaxMissed is the number of intervals a keep-alive reply may take, like
+// ssh's ServerAliveCountMax
+const keepAliveMaxMissed = 3
+
+// keepAlive sends a keep-alive request to the target host every interval and closes
+// the connections when one fails or goes unanswered, so dead connections are noticed
+func keepAlive(clients []*ssh.Client, interval time.Duration) {
+	target := clients[len(clients)-1]
+	closed := make(chan struct{})
+	go func() {
+		target.Wait()
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+
+		reply := make(chan error, 1)
+		go func() {
+			_, _, err := target.SendRequest("keepalive@openssh.com", true, nil)
+			reply <- err
+		}()
+
+		select {
+		case err := <-reply:
+			if err == nil {
+				continue
+			}
+		case <-time.After(keepAliveMaxMissed * interval):
+		case <-closed:
+			return
+		}
+		closeClients(clients)
+		return
+	}
+}
+
+// connect opens SSH connections to the jump hosts and the target host, each through the
+// previous one, and returns them in order. The first connection goes through the proxy
+// command if one is configured.
+func (d *SSHDialer) connect() ([]*ssh.Client, error) {
+	hops := append(append([]hop(nil), d.jumps...), hop{
+		addr:      normalizeSSHHost(d.config.SSHHost),
+		sshConfig: d.sshConfig,
+	})
+
+	var clients []*ssh.Client
+	for i, h := range hops {
+		var (
+			conn net.Conn
+			err  error
+		)
+		switch {
+		case i > 0:
+			conn, err = clients[i-1].Dial("tcp", h.addr)
+		case d.config.SSHProxyCommand != "":
+			conn, err = dialProxyCommand(d.config.SSHProxyCommand, h.addr, h.sshConfig.User)
+		default:
+			conn, err = net.DialTimeout("tcp", h.addr, d.config.Timeout)
+		}
+		if err == nil {
+			var client *ssh.Client
+			if client, err = newClient(conn, h); err == nil {
+				clients = append(clients, client)
+				continue
+			}
+		}
+
+		closeClients(clients)
+		message := fmt.Sprintf("failed to connect to SSH server %s", h.addr)
+		if i < len(hops)-1 {
+			message = fmt.Sprintf("failed to connect to jump host %s", h.addr)
+		}
+		return nil, &config.ProxyError{
+			Category: config.ErrorCategorySSH,
+			Message:  message,
+			Cause:    err,
+		}
+	}
+	return clients, nil
+}
+
+// newClient runs the SSH handshake of a hop over conn
+func newClient(conn net.Conn, h hop) (*ssh.Client, error) {
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, h.addr, h.sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// closeClients closes SSH connections, innermost first
+func closeClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
+	}
+}
+
+// chainConn is a connection through a chain of SSH connections, closed with it
+type chainConn struct {
+	net.Conn
+	clients []*ssh.Client
+	once    sync.Once
+}
+
+func (c *chainConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { closeClients(c.clients) })
+	return err
 }
 
 // HealthCheck verifies the remote Docker daemon is accessible
 func (d *SSHDialer) HealthCheck(ctx context.Context) error {
+	return healthCheck(ctx, d.Dial)
+}
+
+// healthCheck pings the Docker daemon reached through dial
+func healthCheck(ctx context.Context, dial func() (net.Conn, error)) error {
 	// Create Docker client with custom dialer
 	dockerClient, err := client.NewClientWithOpts(
 		client.WithHost("http://dummy"), // not used due to custom dialer
 		client.WithAPIVersionNegotiation(),
 		client.WithDialContext(func(ctx context.Context, _, _ string) (net.Conn, error) {
-			return d.Dial()
+			return dial()
 		}),
 	)
 	if err != nil {
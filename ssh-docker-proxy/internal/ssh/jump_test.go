@@ -0,0 +1,307 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"ssh-docker-proxy/internal/config"
+)
+
+// testSSHServer is an SSH server forwarding direct-tcpip channels to TCP addresses and
+// direct-streamlocal channels to Unix sockets, like sshd
+type testSSHServer struct {
+	addr string
+	open atomic.Int32
+}
+
+// startTestSSHServer starts an SSH server accepting the key at keyPath for user
+func startTestSSHServer(t *testing.T, user, keyPath string) *testSSHServer {
+	keyBytes, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	clientSigner, err := ssh.ParsePrivateKey(keyBytes)
+	require.NoError(t, err)
+	authorized := clientSigner.PublicKey().Marshal()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == user && string(key.Marshal()) == string(authorized) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unauthorized")
+		},
+	}
+	serverConfig.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testSSHServer{addr: listener.Addr().String()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, serverConfig)
+		}
+	}()
+	return server
+}
+
+func (s *testSSHServer) serve(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	s.open.Add(1)
+	defer s.open.Add(-1)
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		var network, address string
+		switch newChannel.ChannelType() {
+		case "direct-tcpip":
+			var payload struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			network, address = "tcp", net.JoinHostPort(payload.Host, fmt.Sprint(payload.Port))
+		case "direct-streamlocal@openssh.com":
+			var payload struct {
+				SocketPath string
+				Reserved0  string
+				Reserved1  uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			network, address = "unix", payload.SocketPath
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		target, err := net.Dial(network, address)
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelReqs, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelReqs)
+		go relay(channel, target)
+	}
+	sshConn.Wait()
+}
+
+// relay copies between a and b in both directions until either side closes
+func relay(a io.ReadWriteCloser, b io.ReadWriteCloser) {
+	var once sync.Once
+	closeBoth := func() {
+		a.Close()
+		b.Close()
+	}
+	go func() {
+		io.Copy(a, b)
+		once.Do(closeBoth)
+	}()
+	io.Copy(b, a)
+	once.Do(closeBoth)
+}
+
+// startEchoSocket starts a Unix socket server echoing what it receives, standing in for
+// the remote Docker socket
+func startEchoSocket(t *testing.T) string {
+	// Unix socket paths are short; t.TempDir may exceed the limit
+	dir, err := os.MkdirTemp("", "sdp")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return socketPath
+}
+
+// assertEcho checks that conn reaches the echo socket
+func assertEcho(t *testing.T, conn net.Conn) {
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(reply))
+}
+
+func TestSSHDialer_DialThroughJumpHosts(t *testing.T) {
+	bastionKey, targetKey := generateTestSSHKey(t), generateTestSSHKey(t)
+	outer := startTestSSHServer(t, "jump", bastionKey)
+	inner := startTestSSHServer(t, "jump", bastionKey)
+	target := startTestSSHServer(t, "docker", targetKey)
+	socketPath := startEchoSocket(t)
+
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:    "docker",
+		SSHHost:    target.addr,
+		SSHKeyPath: targetKey,
+		SSHJumpHosts: []config.JumpHost{
+			{Host: outer.addr, User: "jump", KeyPath: bastionKey},
+			{Host: inner.addr, User: "jump", KeyPath: bastionKey},
+		},
+		RemoteSocket: socketPath,
+		Timeout:      5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	conn, err := dialer.Dial()
+	require.NoError(t, err)
+	assertEcho(t, conn)
+	assert.Equal(t, int32(1), outer.open.Load())
+	assert.Equal(t, int32(1), inner.open.Load())
+	assert.Equal(t, int32(1), target.open.Load())
+
+	// Closing the connection closes every hop
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool {
+		return outer.open.Load() == 0 && inner.open.Load() == 0 && target.open.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSSHDialer_JumpHostFailure(t *testing.T) {
+	bastionKey, targetKey := generateTestSSHKey(t), generateTestSSHKey(t)
+	bastion := startTestSSHServer(t, "jump", bastionKey)
+
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:    "docker",
+		SSHHost:    "127.0.0.1:1",
+		SSHKeyPath: targetKey,
+		// The bastion does not accept the target's key
+		SSHJumpHosts: []config.JumpHost{{Host: bastion.addr, User: "jump"}},
+		Timeout:      5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	_, err = dialer.Dial()
+	require.Error(t, err)
+	proxyErr, ok := err.(*config.ProxyError)
+	require.True(t, ok)
+	assert.Equal(t, config.ErrorCategorySSH, proxyErr.Category)
+	assert.Contains(t, proxyErr.Message, "failed to connect to jump host "+bastion.addr)
+}
+
+func TestSSHDialer_DialThroughProxyCommand(t *testing.T) {
+	targetKey := generateTestSSHKey(t)
+	target := startTestSSHServer(t, "docker", targetKey)
+	socketPath := startEchoSocket(t)
+
+	// The test binary itself acts as the proxy command, see TestHelperProxyCommand
+	t.Setenv("SSH_DOCKER_PROXY_HELPER", "1")
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:         "docker",
+		SSHHost:         target.addr,
+		SSHKeyPath:      targetKey,
+		SSHProxyCommand: fmt.Sprintf("%s -test.run=TestHelperProxyCommand -- %%h %%p", os.Args[0]),
+		RemoteSocket:    socketPath,
+		Timeout:         5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	conn, err := dialer.Dial()
+	require.NoError(t, err)
+	defer conn.Close()
+	assertEcho(t, conn)
+}
+
+// TestHelperProxyCommand is run as a proxy command by TestSSHDialer_DialThroughProxyCommand;
+// it connects its standard input and output to the host and port given as arguments
+func TestHelperProxyCommand(t *testing.T) {
+	if os.Getenv("SSH_DOCKER_PROXY_HELPER") != "1" {
+		t.Skip("only run as a proxy command")
+	}
+	args := os.Args[len(os.Args)-2:]
+	conn, err := net.Dial("tcp", net.JoinHostPort(args[0], args[1]))
+	if err != nil {
+		os.Exit(1)
+	}
+	go func() {
+		io.Copy(conn, os.Stdin)
+		conn.Close()
+	}()
+	io.Copy(os.Stdout, conn)
+	os.Exit(0)
+}
+
+func TestExpandProxyCommand(t *testing.T) {
+	got := expandProxyCommand("ssh -W %h:%p -l %r bastion # 100%%", "10.0.0.5", "22", "root")
+	assert.Equal(t, "ssh -W 10.0.0.5:22 -l root bastion # 100%", got)
+}
+
+func TestSSHDialer_KeepAlive(t *testing.T) {
+	targetKey := generateTestSSHKey(t)
+	target := startTestSSHServer(t, "docker", targetKey)
+	socketPath := startEchoSocket(t)
+
+	dialer, err := NewSSHDialer(&config.Config{
+		SSHUser:      "docker",
+		SSHHost:      target.addr,
+		SSHKeyPath:   targetKey,
+		RemoteSocket: socketPath,
+		Timeout:      5 * time.Second,
+		SSHKeepAlive: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	conn, err := dialer.Dial()
+	require.NoError(t, err)
+
+	// Answered keep-alives keep the connection open
+	time.Sleep(100 * time.Millisecond)
+	assertEcho(t, conn)
+
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool { return target.open.Load() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
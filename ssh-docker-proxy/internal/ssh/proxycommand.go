@@ -0,0 +1,89 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// dialProxyCommand starts an OpenSSH-style ProxyCommand and returns a connection over
+// its standard input and output. %h, %p and %r in the command are replaced with the
+// host, port and user of the SSH server to reach, and %% with %.
+func dialProxyCommand(command, addr, user string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	command = expandProxyCommand(command, host, port, user)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command) // #nosec G204
+	} else {
+		cmd = exec.Command("sh", "-c", command) // #nosec G204
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout, addr: addr}, nil
+}
+
+// expandProxyCommand replaces the % tokens of a ProxyCommand
+func expandProxyCommand(command, host, port, user string) string {
+	return strings.NewReplacer("%%", "%", "%h", host, "%p", port, "%r", user).Replace(command)
+}
+
+// commandConn is a connection over the standard input and output of a command
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	addr   string
+}
+
+func (c *commandConn) Read(p []byte) (int, error) { return c.stdout.Read(p) }
+
+func (c *commandConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+// Close closes the input of the command and stops it
+func (c *commandConn) Close() error {
+	err := c.stdin.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+	return err
+}
+
+func (c *commandConn) LocalAddr() net.Addr { return commandAddr("proxy-command") }
+
+func (c *commandConn) RemoteAddr() net.Addr { return commandAddr(c.addr) }
+
+// Deadlines are not supported on pipes
+func (c *commandConn) SetDeadline(t time.Time) error { return nil }
+
+func (c *commandConn) SetReadDeadline(t time.Time) error { return nil }
+
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// commandAddr is the address of either end of a command connection
+type commandAddr string
+
+func (a commandAddr) Network() string { return "proxy-command" }
+
+func (a commandAddr) String() string { return string(a) }
@@ -0,0 +1,278 @@
+// Package sshconfig reads the host settings of OpenSSH client configuration files, such
+// as ~/.ssh/config, so that hosts are reached the same way as with ssh
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIncludeDepth bounds nested Include directives, as OpenSSH does
+const maxIncludeDepth = 16
+
+// Config is a parsed OpenSSH client configuration
+type Config struct {
+	blocks []block
+}
+
+// block holds the options of a Host or Match section; options before the first
+// section apply to every host
+type block struct {
+	patterns []string
+	// never is set for Match sections, which are not supported and never apply
+	never   bool
+	options []option
+}
+
+// option is a keyword, lower-cased, with its value
+type option struct {
+	key   string
+	value string
+}
+
+// Host is the configuration of one host. Empty fields are not configured.
+type Host struct {
+	HostName            string
+	User                string
+	Port                int
+	IdentityFiles       []string
+	ProxyJump           string
+	ProxyCommand        string
+	ServerAliveInterval time.Duration
+}
+
+// Load parses the configuration file at path, following its Include directives
+func Load(path string) (*Config, error) {
+	config := &Config{blocks: []block{{patterns: []string{"*"}}}}
+	if err := config.load(path, 0); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Parse parses a configuration; Include directives are ignored
+func Parse(r io.Reader) (*Config, error) {
+	config := &Config{blocks: []block{{patterns: []string{"*"}}}}
+	if err := config.parse(r, "", -1); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *Config) load(path string, depth int) error {
+	file, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return c.parse(file, path, depth)
+}
+
+// parse adds the sections of r to c; a negative depth ignores Include directives
+func (c *Config) parse(r io.Reader, path string, depth int) error {
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		key, value, ok := splitLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "host":
+			c.blocks = append(c.blocks, block{patterns: strings.Fields(value)})
+		case "match":
+			c.blocks = append(c.blocks, block{never: true})
+		case "include":
+			if depth < 0 {
+				continue
+			}
+			if depth >= maxIncludeDepth {
+				return fmt.Errorf("%s:%d: too many nested includes", path, lineNumber)
+			}
+			if err := c.include(value, depth); err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+		default:
+			last := &c.blocks[len(c.blocks)-1]
+			last.options = append(last.options, option{key: key, value: value})
+		}
+	}
+	return scanner.Err()
+}
+
+// include parses the files matching the patterns of an Include directive; relative
+// patterns are relative to ~/.ssh
+func (c *Config) include(value string, depth int) error {
+	for _, pattern := range strings.Fields(value) {
+		pattern = expandHome(unquote(pattern))
+		if !filepath.IsAbs(pattern) {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			pattern = filepath.Join(homeDir, ".ssh", pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if err := c.load(match, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitLine splits a configuration line into its lower-cased keyword and value, which
+// are separated by whitespace or "="
+func splitLine(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), "", true
+	}
+	key, value = line[:end], strings.TrimSpace(line[end:])
+	if strings.HasPrefix(value, "=") {
+		value = strings.TrimSpace(value[1:])
+	}
+	return strings.ToLower(key), value, true
+}
+
+// Lookup returns the configuration of alias. As with ssh, the first value found for
+// an option wins, except identity files, which accumulate.
+func (c *Config) Lookup(alias string) Host {
+	var (
+		host Host
+		seen = make(map[string]bool)
+	)
+	for _, b := range c.blocks {
+		if b.never || !matchHost(b.patterns, alias) {
+			continue
+		}
+		for _, opt := range b.options {
+			if opt.key == "identityfile" {
+				host.IdentityFiles = append(host.IdentityFiles, expandTokens(unquote(opt.value), alias))
+				continue
+			}
+			if seen[opt.key] {
+				continue
+			}
+			seen[opt.key] = true
+			host.set(opt, alias)
+		}
+	}
+	return host
+}
+
+// set applies a single-valued option
+func (h *Host) set(opt option, alias string) {
+	switch opt.key {
+	case "hostname":
+		h.HostName = expandTokens(unquote(opt.value), alias)
+	case "user":
+		h.User = unquote(opt.value)
+	case "port":
+		h.Port, _ = strconv.Atoi(unquote(opt.value))
+	case "proxyjump":
+		h.ProxyJump = unquote(opt.value)
+	case "proxycommand":
+		// The command is passed to the shell as written
+		h.ProxyCommand = opt.value
+	case "serveraliveinterval":
+		h.ServerAliveInterval = parseInterval(unquote(opt.value))
+	}
+}
+
+// parseInterval parses seconds, or a duration such as "1m"
+func parseInterval(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	interval, _ := time.ParseDuration(value)
+	return interval
+}
+
+// matchHost reports whether alias matches the patterns of a Host line: at least one
+// pattern matches and no negated pattern does
+func matchHost(patterns []string, alias string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchPattern(negated, alias) {
+				return false
+			}
+			continue
+		}
+		if matchPattern(pattern, alias) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern matches s against a pattern of "*" (any characters) and "?" (one
+// character) wildcards, case-insensitively
+func matchPattern(pattern, s string) bool {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if matchPattern(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// expandTokens expands ~ and the %h (host alias), %d (home directory) and %% tokens
+func expandTokens(value, alias string) string {
+	homeDir, _ := os.UserHomeDir()
+	value = strings.NewReplacer("%%", "%", "%h", alias, "%d", homeDir).Replace(value)
+	return expandHome(value)
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// unquote removes the double quotes around a value
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
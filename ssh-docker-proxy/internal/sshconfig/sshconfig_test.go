@@ -0,0 +1,117 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+# As with ssh, the first value found wins, so the defaults of "Host *" go last
+Host mybox
+    HostName 10.0.0.5
+    User deploy
+    Port 2222
+    IdentityFile ~/.ssh/mybox
+    ProxyJump bastion
+    ServerAliveInterval 15
+
+Host *.internal !secret.internal
+    HostName %h.example.com
+    User=ops
+    ProxyCommand ssh -W %h:%p gateway
+
+Match host legacy
+    User ignored
+
+Host *
+    User fallback
+    Port 22
+    IdentityFile "~/.ssh/id_ed25519"
+`
+
+func TestLookup(t *testing.T) {
+	config, err := Parse(strings.NewReader(testConfig))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+
+	got := config.Lookup("mybox")
+	want := Host{
+		HostName:            "10.0.0.5",
+		User:                "deploy",
+		Port:                2222,
+		IdentityFiles:       []string{filepath.Join(home, ".ssh/mybox"), filepath.Join(home, ".ssh/id_ed25519")},
+		ProxyJump:           "bastion",
+		ServerAliveInterval: 15 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup(mybox) = %+v, want %+v", got, want)
+	}
+
+	got = config.Lookup("db.internal")
+	if got.HostName != "db.internal.example.com" || got.User != "ops" || got.Port != 22 {
+		t.Errorf("Lookup(db.internal) = %+v", got)
+	}
+	if got.ProxyCommand != "ssh -W %h:%p gateway" {
+		t.Errorf("Lookup(db.internal) ProxyCommand = %q", got.ProxyCommand)
+	}
+
+	// Negated patterns exclude a host, Match sections never apply
+	if got := config.Lookup("secret.internal"); got.User != "fallback" || got.HostName != "" {
+		t.Errorf("Lookup(secret.internal) = %+v", got)
+	}
+	if got := config.Lookup("legacy"); got.User != "fallback" {
+		t.Errorf("Lookup(legacy) = %+v", got)
+	}
+}
+
+func TestLoadInclude(t *testing.T) {
+	dir := t.TempDir()
+	included := filepath.Join(dir, "hosts.conf")
+	if err := os.WriteFile(included, []byte("Host included\n  HostName 192.0.2.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(dir, "config")
+	if err := os.WriteFile(main, []byte("Include "+filepath.Join(dir, "*.conf")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load(main)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if got := config.Lookup("included").HostName; got != "192.0.2.1" {
+		t.Errorf("Lookup(included) HostName = %q, want 192.0.2.1", got)
+	}
+
+	// A file including itself is rejected instead of recursing forever
+	if err := os.WriteFile(main, []byte("Include "+main+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(main); err == nil {
+		t.Error("Load() expected error for recursive include")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "anything", true},
+		{"web-?", "web-1", true},
+		{"web-?", "web-10", false},
+		{"*.EXAMPLE.com", "db.example.com", true},
+		{"db", "db2", false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
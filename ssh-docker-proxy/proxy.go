@@ -5,26 +5,42 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"time"
 
+	"golang.org/x/crypto/ssh"
+
 	"ssh-docker-proxy/internal/config"
+	"ssh-docker-proxy/internal/logging"
 	"ssh-docker-proxy/internal/proxy"
 )
 
 // ProxyConfig represents the configuration for the SSH Docker proxy
 type ProxyConfig struct {
-	LocalSocket  string // Local Unix socket path (e.g., /tmp/docker.sock)
+	LocalSocket  string // Local Unix socket path (e.g., /tmp/docker.sock) or Windows named pipe (e.g., //./pipe/docker-proxy)
 	SSHUser      string // SSH username
 	SSHHost      string // SSH hostname with optional port
 	SSHKeyPath   string // Path to SSH private key file
+	SSHAgent     bool   // Authenticate with ssh-agent keys, falling back to SSHKeyPath
+	SSHAuthSock  string // ssh-agent socket (default: $SSH_AUTH_SOCK)
 	RemoteSocket string // Remote Docker socket path (default: /var/run/docker.sock)
 	Timeout      string // SSH connection timeout (e.g., "10s")
+
+	SSHJumpHosts    []JumpHost // Jump hosts (bastions) connected through in order, like ssh -J
+	SSHProxyCommand string     // Command whose stdin/stdout reach the first SSH server, like ssh's ProxyCommand
+
+	Supervise    bool   // Keep the local socket up and reconnect when the SSH connection is lost
+	DrainTimeout string // How long in-flight connections may finish on shutdown (e.g., "30s")
 }
 
+// JumpHost is an SSH server connected through on the way to SSHHost; User and KeyPath
+// default to SSHUser and SSHKeyPath
+type JumpHost = config.JumpHost
+
 // Proxy represents a running SSH Docker proxy instance
 type Proxy struct {
 	proxy  *proxy.Proxy
-	logger *log.Logger
+	logger logging.Logger
 }
 
 // Logger interface allows custom logging implementations
@@ -32,15 +48,73 @@ type Logger interface {
 	Printf(format string, v ...interface{})
 }
 
+// StructuredLogger receives log entries with structured fields, such as the "conn" ID of
+// each proxied connection. A Logger that also implements it is used as one.
+type StructuredLogger = logging.Logger
+
+// Fields are the structured fields of a log entry
+type Fields = logging.Fields
+
+// Stats are the connection statistics of a proxy
+type Stats = proxy.Snapshot
+
+// Option customizes a proxy created by NewProxy
+type Option = proxy.Option
+
+// Dialer connects to the remote Docker socket in place of the proxy's own SSH
+// connections; each Dial returns a connection of its own
+type Dialer = proxy.Dialer
+
+// Metrics receives the lifecycle of proxied connections
+type Metrics = proxy.Metrics
+
+// ConnectionStats are the statistics of one proxied connection
+type ConnectionStats = proxy.ConnectionSnapshot
+
+// WithDialer connects to the remote Docker socket through dialer; the SSH settings of
+// the configuration are then optional
+func WithDialer(dialer Dialer) Option {
+	return proxy.WithDialer(dialer)
+}
+
+// WithSSHClient connects to the remote Docker socket through an established SSH client,
+// which stays open when the proxy stops; the SSH settings of the configuration are then
+// optional
+func WithSSHClient(client *ssh.Client) Option {
+	return proxy.WithSSHClient(client)
+}
+
+// WithListener accepts Docker clients on listener instead of LocalSocket, which is then
+// optional; the proxy closes it when it stops
+func WithListener(listener net.Listener) Option {
+	return proxy.WithListener(listener)
+}
+
+// WithConnWrapper wraps every accepted client connection before it is relayed
+func WithConnWrapper(wrap func(net.Conn) net.Conn) Option {
+	return proxy.WithConnWrapper(wrap)
+}
+
+// WithMetrics reports the lifecycle of every proxied connection to metrics
+func WithMetrics(metrics Metrics) Option {
+	return proxy.WithMetrics(metrics)
+}
+
 // NewProxy creates a new SSH Docker proxy instance
-func NewProxy(cfg *ProxyConfig, logger Logger) (*Proxy, error) {
+func NewProxy(cfg *ProxyConfig, logger Logger, opts ...Option) (*Proxy, error) {
 	// Convert public config to internal config
 	internalConfig := &config.Config{
 		LocalSocket:  cfg.LocalSocket,
 		SSHUser:      cfg.SSHUser,
 		SSHHost:      cfg.SSHHost,
 		SSHKeyPath:   cfg.SSHKeyPath,
+		SSHAgent:     cfg.SSHAgent,
+		SSHAuthSock:  cfg.SSHAuthSock,
 		RemoteSocket: cfg.RemoteSocket,
+
+		SSHJumpHosts:    cfg.SSHJumpHosts,
+		SSHProxyCommand: cfg.SSHProxyCommand,
+		Supervise:       cfg.Supervise,
 	}
 
 	// Set default remote socket if not specified
@@ -57,21 +131,25 @@ func NewProxy(cfg *ProxyConfig, logger Logger) (*Proxy, error) {
 		internalConfig.Timeout = timeout
 	}
 
+	// Parse drain timeout if provided
+	if cfg.DrainTimeout != "" {
+		drainTimeout, err := time.ParseDuration(cfg.DrainTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drain timeout format: %s", err)
+		}
+		internalConfig.DrainTimeout = drainTimeout
+	}
+
 	// Validate configuration
-	if err := internalConfig.Validate(); err != nil {
+	if err := proxy.ValidateConfig(internalConfig, opts...); err != nil {
 		return nil, err
 	}
 
 	// Create logger wrapper
-	var internalLogger *log.Logger
-	if logger != nil {
-		internalLogger = log.New(&loggerWriter{logger: logger}, "", 0)
-	} else {
-		internalLogger = log.New(log.Writer(), "[ssh-docker-proxy] ", log.LstdFlags)
-	}
+	internalLogger := structuredLogger(logger)
 
 	// Create internal proxy
-	internalProxy, err := proxy.NewProxy(internalConfig, internalLogger)
+	internalProxy, err := proxy.NewProxy(internalConfig, internalLogger, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,17 +165,31 @@ func (p *Proxy) Start(ctx context.Context) error {
 	return p.proxy.Start(ctx)
 }
 
-// Stop gracefully shuts down the proxy
+// Ready returns a channel that is closed once the proxy accepts connections
+func (p *Proxy) Ready() <-chan struct{} {
+	return p.proxy.Ready()
+}
+
+// Stop gracefully shuts down the proxy; Start returns once in-flight connections have
+// finished or the drain timeout closed them
 func (p *Proxy) Stop() error {
 	return p.proxy.Stop()
 }
 
-// loggerWriter wraps a Logger interface to implement io.Writer
-type loggerWriter struct {
-	logger Logger
+// Stats returns the current connection statistics: active connections and the bytes
+// they carried
+func (p *Proxy) Stats() Stats {
+	return p.proxy.Stats().Snapshot()
 }
 
-func (w *loggerWriter) Write(p []byte) (n int, err error) {
-	w.logger.Printf("%s", string(p))
-	return len(p), nil
+// structuredLogger returns the structured logger of logger, writing through its Printf
+// unless it is structured itself
+func structuredLogger(logger Logger) logging.Logger {
+	if structured, ok := logger.(StructuredLogger); ok {
+		return structured
+	}
+	if logger != nil {
+		return logging.FromPrintf(logger.Printf)
+	}
+	return logging.FromStd(log.New(log.Writer(), "[ssh-docker-proxy] ", log.LstdFlags))
 }